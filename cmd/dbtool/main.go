@@ -0,0 +1,158 @@
+// Package main lists and restores cache backups taken by the server
+// (NTPU_BACKUP_DIR or NTPU_BACKUP_S3_PREFIX), and checks the cache for
+// anomalous rows. Stop the server before restoring or repairing.
+//
+// Usage:
+//
+//	dbtool list                          # backups in $NTPU_BACKUP_DIR or under $NTPU_BACKUP_S3_PREFIX
+//	dbtool restore                       # newest backup into $NTPU_DATA_DIR/cache.db
+//	dbtool restore -name cache-20250301T040000Z.db.zst -dir /backups
+//	dbtool check                         # count anomalies in $NTPU_DATA_DIR/cache.db
+//	dbtool check -repair                 # and delete the repairable ones
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/backup"
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
+	"github.com/garyellow/ntpu-linebot-go/internal/s3client"
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+)
+
+// toolTimeout bounds the whole run; a restore downloads and checks the full cache.
+const toolTimeout = 30 * time.Minute
+
+const usage = "usage: dbtool list|restore|check [flags]"
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "dbtool: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+	cmd, args := args[0], args[1:]
+
+	dataDir := os.Getenv(config.EnvDataDir)
+	if dataDir == "" {
+		dataDir = "/data"
+	}
+
+	fs := flag.NewFlagSet("dbtool "+cmd, flag.ContinueOnError)
+	dir := fs.String("dir", os.Getenv(config.EnvBackupDir), "local backup directory")
+	s3Prefix := fs.String("s3-prefix", os.Getenv(config.EnvBackupS3Prefix), "backup key prefix in the NTPU_S3_* bucket")
+	var name, dbPath *string
+	var repair *bool
+	switch cmd {
+	case "restore":
+		name = fs.String("name", "", "backup to restore (default: newest)")
+		dbPath = fs.String("db", filepath.Join(dataDir, "cache.db"), "cache database path to replace")
+	case "check":
+		dbPath = fs.String("db", filepath.Join(dataDir, "cache.db"), "cache database path to check")
+		repair = fs.Bool("repair", false, "delete repairable anomalous rows")
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), toolTimeout)
+	defer cancel()
+
+	if cmd == "check" {
+		return check(ctx, out, *dbPath, *repair)
+	}
+
+	store, err := openStore(ctx, *dir, *s3Prefix)
+	if err != nil {
+		return err
+	}
+
+	switch cmd {
+	case "list":
+		names, err := backup.List(ctx, store)
+		if err != nil {
+			return err
+		}
+		for _, n := range names {
+			fmt.Fprintln(out, n)
+		}
+		return nil
+	case "restore":
+		restored, err := backup.Restore(ctx, store, *name, *dbPath)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "restored %s to %s\n", restored, *dbPath)
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q; %s", cmd, usage)
+	}
+}
+
+// check verifies the cache file at dbPath and prints its anomaly counts,
+// repairing first when asked. The check never creates an empty cache.
+func check(ctx context.Context, out io.Writer, dbPath string, repair bool) error {
+	if _, err := os.Stat(dbPath); err != nil {
+		return err
+	}
+	db, err := storage.New(ctx, dbPath, 0)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Close(ctx) }()
+	db.SetTenant(os.Getenv(config.EnvTenant))
+	if err := db.CheckIntegrity(ctx); err != nil {
+		return err
+	}
+
+	report, err := db.FindAnomalies(ctx, repair)
+	if err != nil {
+		return err
+	}
+	verb := "found"
+	if repair {
+		verb = "found/repaired"
+	}
+	for _, name := range storage.AnomalyChecks {
+		fmt.Fprintf(out, "%-20s %s %d\n", name, verb, report[name])
+	}
+	return nil
+}
+
+// openStore returns the backup store for -dir or -s3-prefix. S3 credentials
+// come from the same NTPU_S3_* variables as the server.
+func openStore(ctx context.Context, dir, s3Prefix string) (backup.Store, error) {
+	switch {
+	case dir != "" && s3Prefix != "":
+		return nil, errors.New("set only one of -dir and -s3-prefix")
+	case dir != "":
+		return backup.NewDirStore(dir), nil
+	case s3Prefix != "":
+		client, err := s3client.New(ctx, s3client.Config{
+			Endpoint:    os.Getenv(config.EnvS3Endpoint),
+			Region:      os.Getenv(config.EnvS3Region),
+			AccessKeyID: os.Getenv(config.EnvS3AccessKeyID),
+			SecretKey:   os.Getenv(config.EnvS3SecretAccessKey),
+			BucketName:  os.Getenv(config.EnvS3BucketName),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return backup.NewS3Store(client, strings.Trim(s3Prefix, "/")), nil
+	default:
+		return nil, errors.New("-dir or -s3-prefix is required (or set NTPU_BACKUP_DIR / NTPU_BACKUP_S3_PREFIX)")
+	}
+}
@@ -0,0 +1,328 @@
+// Package main replays webhook events recorded with NTPU_WEBHOOK_RECORD_FILE
+// against a local instance and compares its replies with the recorded ones,
+// for validating refactors of the dispatch layer.
+//
+// The tool serves a fake LINE Messaging API that captures the replies. Start
+// the instance with the same channel secret and the LINE API pointed at it:
+//
+//	NTPU_LINE_API_BASE_URL=http://localhost:18080 task dev
+//	replay -file webhook.jsonl
+//	replay -file webhook.jsonl -v   # also print differing replies
+//
+// Events are replayed one at a time in recorded order, each with a fresh
+// timestamp, event ID, and reply token. Sender icons are random stickers, so
+// they are ignored when comparing. The exit status is 1 if any reply differs.
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
+	"github.com/garyellow/ntpu-linebot-go/internal/webhook"
+)
+
+// noReplyWait is how long to wait for a reply to an event that was recorded
+// without one before counting it as matching.
+const noReplyWait = 3 * time.Second
+
+// requestTimeout bounds each webhook POST; the instance answers before processing.
+const requestTimeout = 10 * time.Second
+
+// errDifferent is returned when at least one reply differs from the recording.
+var errDifferent = errors.New("replies differ from the recording")
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "replay: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, out io.Writer) error {
+	port := os.Getenv(config.EnvPort)
+	if port == "" {
+		port = "10000"
+	}
+
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	file := fs.String("file", "", "recorded events (NTPU_WEBHOOK_RECORD_FILE)")
+	target := fs.String("target", "http://localhost:"+port+"/webhook", "webhook URL of the instance under test")
+	listen := fs.String("listen", "localhost:18080", "address of the fake LINE API (NTPU_LINE_API_BASE_URL of the instance)")
+	secret := fs.String("secret", os.Getenv(config.EnvLineChannelSecret), "channel secret used to sign the events")
+	timeout := fs.Duration("timeout", 30*time.Second, "how long to wait for each reply")
+	verbose := fs.Bool("v", false, "print the recorded and replayed messages of differing replies")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return errors.New("-file is required")
+	}
+	if *secret == "" {
+		return fmt.Errorf("-secret or %s is required", config.EnvLineChannelSecret)
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		return err
+	}
+	recs, err := webhook.ReadRecordings(f)
+	_ = f.Close()
+	if err != nil {
+		return fmt.Errorf("read %s: %w", *file, err)
+	}
+
+	api := newFakeAPI()
+	ln, err := net.Listen("tcp", *listen)
+	if err != nil {
+		return fmt.Errorf("fake LINE API: %w", err)
+	}
+	server := &http.Server{Handler: api, ReadHeaderTimeout: requestTimeout}
+	go func() { _ = server.Serve(ln) }()
+	defer func() { _ = server.Close() }()
+
+	var counts [resultCount]int
+	for i, rec := range recs {
+		res, got, err := replayOne(i, rec, api, *target, *secret, *timeout)
+		if err != nil {
+			return fmt.Errorf("event %d: %w", i+1, err)
+		}
+		counts[res]++
+		if res == resultSame {
+			continue
+		}
+		fmt.Fprintf(out, "event %d (%s): %s\n", i+1, rec.Module, res)
+		if *verbose && res != resultSkipped {
+			fmt.Fprintf(out, "  recorded: %s\n  replayed: %s\n", rec.Messages, got)
+		}
+	}
+	fmt.Fprintf(out, "%d events: %d same, %d different, %d missing, %d unexpected, %d skipped\n",
+		len(recs), counts[resultSame], counts[resultDifferent], counts[resultMissing], counts[resultUnexpected], counts[resultSkipped])
+
+	if counts[resultDifferent]+counts[resultMissing]+counts[resultUnexpected] > 0 {
+		return errDifferent
+	}
+	return nil
+}
+
+// result is the outcome of replaying one event.
+type result int
+
+const (
+	resultSame       result = iota
+	resultDifferent         // Both replied, with different messages
+	resultMissing           // Recorded a reply, replay got none
+	resultUnexpected        // Recorded no reply, replay got one
+	resultSkipped           // Event without a reply token
+	resultCount
+)
+
+func (r result) String() string {
+	return [...]string{"same", "different", "missing reply", "unexpected reply", "skipped"}[r]
+}
+
+// replayOne posts one recorded event and compares the reply with the recording.
+func replayOne(i int, rec webhook.Recording, api *fakeAPI, target, secret string, timeout time.Duration) (result, json.RawMessage, error) {
+	// Unique per event and longer than the 10 characters the handler requires
+	replyToken := fmt.Sprintf("replay%026d", i)
+	body, ok, err := prepareEvent(rec.Event, replyToken, time.Now())
+	if err != nil {
+		return 0, nil, err
+	}
+	if !ok {
+		return resultSkipped, nil, nil
+	}
+
+	replies := api.expect(replyToken)
+	defer api.forget(replyToken)
+	if err := post(target, secret, body); err != nil {
+		return 0, nil, err
+	}
+
+	recorded := !isNull(rec.Messages)
+	wait := timeout
+	if !recorded {
+		wait = min(timeout, noReplyWait)
+	}
+	select {
+	case got := <-replies:
+		if !recorded {
+			return resultUnexpected, got, nil
+		}
+		same, err := sameMessages(rec.Messages, got)
+		if err != nil {
+			return 0, nil, err
+		}
+		if !same {
+			return resultDifferent, got, nil
+		}
+		return resultSame, got, nil
+	case <-time.After(wait):
+		if recorded {
+			return resultMissing, nil, nil
+		}
+		return resultSame, nil, nil
+	}
+}
+
+// prepareEvent wraps a recorded event in a webhook body with a new reply
+// token, event ID, and the current timestamp, so the handler neither treats
+// the token as expired nor skips the event as a redelivery of an earlier
+// replay. It reports false for events without a reply token.
+func prepareEvent(raw json.RawMessage, replyToken string, now time.Time) ([]byte, bool, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber() // Keep IDs and timestamps exact
+	var event map[string]any
+	if err := dec.Decode(&event); err != nil {
+		return nil, false, fmt.Errorf("decode event: %w", err)
+	}
+	if _, ok := event["replyToken"]; !ok {
+		return nil, false, nil
+	}
+	event["replyToken"] = replyToken
+	event["timestamp"] = now.UnixMilli()
+	event["webhookEventId"] = fmt.Sprintf("replay%d", now.UnixNano())
+
+	body, err := json.Marshal(map[string]any{"destination": "replay", "events": []any{event}})
+	if err != nil {
+		return nil, false, err
+	}
+	return body, true, nil
+}
+
+// post sends a signed webhook body to target.
+func post(target, secret string, body []byte) error {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(body)
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Line-Signature", base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+
+	res, err := http.DefaultClient.Do(req) //nolint:gosec // G704: target is the instance under test given on the command line
+	if err != nil {
+		return err
+	}
+	_ = res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("webhook returned %s (channel secret mismatch?)", res.Status)
+	}
+	return nil
+}
+
+// sameMessages compares two message arrays by their JSON content, ignoring
+// sender icons.
+func sameMessages(a, b json.RawMessage) (bool, error) {
+	na, err := normalize(a)
+	if err != nil {
+		return false, err
+	}
+	nb, err := normalize(b)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(na, nb), nil
+}
+
+// normalize re-encodes JSON with sorted keys and without sender icons.
+func normalize(data json.RawMessage) ([]byte, error) {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	dropSenderIcons(v)
+	return json.Marshal(v)
+}
+
+func dropSenderIcons(v any) {
+	switch v := v.(type) {
+	case map[string]any:
+		if sender, ok := v["sender"].(map[string]any); ok {
+			delete(sender, "iconUrl")
+		}
+		for _, child := range v {
+			dropSenderIcons(child)
+		}
+	case []any:
+		for _, child := range v {
+			dropSenderIcons(child)
+		}
+	}
+}
+
+func isNull(data json.RawMessage) bool {
+	return len(bytes.TrimSpace(data)) == 0 || string(bytes.TrimSpace(data)) == "null"
+}
+
+// fakeAPI is a LINE Messaging API stand-in that hands reply messages to the
+// event waiting for their reply token and accepts every other call.
+type fakeAPI struct {
+	mu      sync.Mutex
+	waiters map[string]chan json.RawMessage
+}
+
+func newFakeAPI() *fakeAPI {
+	return &fakeAPI{waiters: make(map[string]chan json.RawMessage)}
+}
+
+// expect returns the channel that receives the reply for replyToken.
+func (f *fakeAPI) expect(replyToken string) <-chan json.RawMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ch := make(chan json.RawMessage, 1)
+	f.waiters[replyToken] = ch
+	return ch
+}
+
+func (f *fakeAPI) forget(replyToken string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.waiters, replyToken)
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	switch r.URL.Path {
+	case "/v2/bot/message/reply":
+		var req struct {
+			ReplyToken string          `json:"replyToken"`
+			Messages   json.RawMessage `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"message":"invalid body"}`))
+			return
+		}
+		f.mu.Lock()
+		if ch, ok := f.waiters[req.ReplyToken]; ok {
+			select {
+			case ch <- req.Messages:
+			default: // A second reply to the same token; LINE would reject it
+			}
+		}
+		f.mu.Unlock()
+		_, _ = w.Write([]byte(`{"sentMessages":[]}`))
+	case "/v2/bot/message/quota":
+		_, _ = w.Write([]byte(`{"type":"none"}`))
+	default:
+		_, _ = w.Write([]byte(`{}`))
+	}
+}
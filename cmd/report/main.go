@@ -0,0 +1,129 @@
+// Package main prints a summary of analytics rollups (NTPU_ANALYTICS_ENABLED).
+//
+// Usage:
+//
+//	report                      # last 7 days from $NTPU_DATA_DIR/analytics.db
+//	report -days 30 -end 2025-03-01
+//	report -format csv > rollups.csv  # daily rows, e.g. for a BigQuery load job
+//	report intents -days 30           # keyword routing overlaps and reformulations
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/analytics"
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+)
+
+// reportTimeout bounds the whole report run (open, query, print).
+const reportTimeout = 30 * time.Second
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "report: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, out io.Writer) error {
+	dataDir := os.Getenv(config.EnvDataDir)
+	if dataDir == "" {
+		dataDir = "/data"
+	}
+
+	// The intents view covers only the intent telemetry rollups
+	intents := len(args) > 0 && args[0] == "intents"
+	if intents {
+		args = args[1:]
+	}
+
+	fs := flag.NewFlagSet("report", flag.ContinueOnError)
+	dbPath := fs.String("db", filepath.Join(dataDir, "analytics.db"), "analytics database path")
+	days := fs.Int("days", 7, "number of days to include")
+	endDay := fs.String("end", "", "last day to include (YYYY-MM-DD, default: today in Asia/Taipei)")
+	format := fs.String("format", "text", "output format: text or csv")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *days < 1 {
+		return errors.New("-days must be at least 1")
+	}
+
+	end := time.Now().In(lineutil.GetTaipeiLocation())
+	if *endDay != "" {
+		parsed, err := time.ParseInLocation(analytics.DayFormat, *endDay, lineutil.GetTaipeiLocation())
+		if err != nil {
+			return fmt.Errorf("invalid -end: %w", err)
+		}
+		end = parsed
+	}
+
+	if _, err := os.Stat(*dbPath); err != nil {
+		return fmt.Errorf("analytics database: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), reportTimeout)
+	defer cancel()
+
+	store, err := analytics.Open(ctx, *dbPath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	switch *format {
+	case "text":
+		if intents {
+			report, err := analytics.BuildIntentReport(ctx, store, end, *days)
+			if err != nil {
+				return err
+			}
+			return report.Write(out)
+		}
+		report, err := analytics.BuildReport(ctx, store, end, *days)
+		if err != nil {
+			return err
+		}
+		return report.Write(out)
+	case "csv":
+		from, to := analytics.DayRange(end, *days)
+		rows, err := store.Rows(ctx, from, to)
+		if err != nil {
+			return err
+		}
+		if intents {
+			rows = slices.DeleteFunc(rows, func(r analytics.Row) bool {
+				return r.Metric != analytics.MetricIntentRouting && r.Metric != analytics.MetricIntentReformulations
+			})
+		}
+		return writeCSV(out, rows)
+	default:
+		return fmt.Errorf("unknown -format %q", *format)
+	}
+}
+
+func writeCSV(out io.Writer, rows []analytics.Row) error {
+	w := csv.NewWriter(out)
+	if err := w.Write([]string{"day", "metric", "module", "label", "value"}); err != nil {
+		return err
+	}
+	for _, r := range rows {
+		value := strconv.FormatFloat(r.Value, 'f', -1, 64)
+		if err := w.Write([]string{r.Day, r.Metric, r.Module, r.Label, value}); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,193 @@
+// Package main scrapes a single target and prints the parsed records as JSON,
+// for diagnosing parser breakage when the school changes its HTML.
+//
+// Usage:
+//
+//	scrape -student 412345678
+//	scrape -course 1131U0001
+//	scrape -semester 113-1 [-title 程式設計]
+//	scrape -contact 資訊工程
+//	scrape -course 1131U0001 -save   # also upsert into $NTPU_DATA_DIR/cache.db
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
+	"github.com/garyellow/ntpu-linebot-go/internal/scraper"
+	"github.com/garyellow/ntpu-linebot-go/internal/scraper/ntpu"
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+)
+
+// scrapeTimeout bounds the whole run; a full semester takes one request per
+// education code, each retried with backoff.
+const scrapeTimeout = 10 * time.Minute
+
+// saveCacheTTL is passed to storage.New with -save. It only affects reads,
+// which this tool does not do.
+const saveCacheTTL = 168 * time.Hour
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "scrape: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, out io.Writer) error {
+	dataDir := os.Getenv(config.EnvDataDir)
+	if dataDir == "" {
+		dataDir = "/data"
+	}
+
+	fs := flag.NewFlagSet("scrape", flag.ContinueOnError)
+	studentID := fs.String("student", "", "student ID to scrape")
+	courseUID := fs.String("course", "", "course UID to scrape (e.g., 1131U0001)")
+	semester := fs.String("semester", "", "semester to scrape (YEAR-TERM, e.g., 113-1)")
+	title := fs.String("title", "", "course title filter for -semester")
+	contact := fs.String("contact", "", "contact search term to scrape")
+	save := fs.Bool("save", false, "also save the results to the cache database")
+	dbPath := fs.String("db", filepath.Join(dataDir, "cache.db"), "cache database path for -save")
+	timeout := fs.Duration("timeout", config.ScraperRequest, "per-request HTTP timeout")
+	retries := fs.Int("retries", 3, "max retry attempts per request")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	targets := 0
+	for _, v := range []string{*studentID, *courseUID, *semester, *contact} {
+		if v != "" {
+			targets++
+		}
+	}
+	if targets != 1 {
+		return errors.New("exactly one of -student, -course, -semester or -contact is required")
+	}
+	if *title != "" && *semester == "" {
+		return errors.New("-title requires -semester")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), scrapeTimeout)
+	defer cancel()
+
+	client := scraper.NewClient(*timeout, *retries, config.DefaultScraperBaseURLs())
+
+	var (
+		result any
+		saveFn func(context.Context, *storage.DB) error
+	)
+	switch {
+	case *studentID != "":
+		student, err := ntpu.ScrapeStudentByID(ctx, client, *studentID)
+		if err != nil {
+			return err
+		}
+		result = student
+		saveFn = func(ctx context.Context, db *storage.DB) error {
+			if err := ntpu.ValidateStudent(student); err != nil {
+				return err
+			}
+			return db.SaveStudent(ctx, student)
+		}
+	case *courseUID != "":
+		course, err := ntpu.ScrapeCourseByUID(ctx, client, strings.ToUpper(*courseUID))
+		if err != nil {
+			return err
+		}
+		result = course
+		saveFn = func(ctx context.Context, db *storage.DB) error {
+			return saveCourses(ctx, db, []*storage.Course{course})
+		}
+	case *semester != "":
+		year, term, err := parseSemester(*semester)
+		if err != nil {
+			return err
+		}
+		courses, err := ntpu.ScrapeCourses(ctx, client, year, term, *title)
+		if err != nil {
+			return err
+		}
+		result = courses
+		saveFn = func(ctx context.Context, db *storage.DB) error {
+			return saveCourses(ctx, db, courses)
+		}
+	default:
+		contacts, err := ntpu.ScrapeContacts(ctx, client, *contact)
+		if err != nil {
+			return err
+		}
+		result = contacts
+		saveFn = func(ctx context.Context, db *storage.DB) error {
+			return db.SaveContactsBatch(ctx, dropInvalid(contacts, ntpu.ValidateContact))
+		}
+	}
+
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(result); err != nil {
+		return err
+	}
+
+	if !*save {
+		return nil
+	}
+	db, err := storage.New(ctx, *dbPath, saveCacheTTL)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Close(ctx) }()
+	db.SetTenant(os.Getenv(config.EnvTenant))
+	if err := saveFn(ctx, db); err != nil {
+		return fmt.Errorf("save: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "scrape: saved to %s\n", *dbPath)
+	return nil
+}
+
+// saveCourses stores courses the way the warmup refresh does, including
+// the course_majors rows used by department filters, in one transaction.
+func saveCourses(ctx context.Context, db *storage.DB, courses []*storage.Course) error {
+	courses = dropInvalid(courses, ntpu.ValidateCourse)
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		if err := db.SaveCoursesBatch(ctx, courses); err != nil {
+			return err
+		}
+		return db.SaveCourseMajorsBatch(ctx, courses)
+	})
+}
+
+// dropInvalid leaves out records that would not pass warmup validation,
+// reporting each one on stderr.
+func dropInvalid[T any](records []*T, validate func(*T) error) []*T {
+	valid, rejected := ntpu.FilterValid(records, validate)
+	for _, err := range rejected {
+		fmt.Fprintf(os.Stderr, "scrape: not saving: %v\n", err)
+	}
+	return valid
+}
+
+// parseSemester parses "113-1" into year 113 and term 1.
+func parseSemester(s string) (year, term int, err error) {
+	yearStr, termStr, ok := strings.Cut(s, "-")
+	if ok {
+		year, err = strconv.Atoi(yearStr)
+	}
+	if ok && err == nil {
+		term, err = strconv.Atoi(termStr)
+	}
+	if !ok || err != nil || term < 1 || term > 2 {
+		return 0, 0, fmt.Errorf("invalid -semester %q, want YEAR-TERM (e.g., 113-1)", s)
+	}
+	return year, term, nil
+}
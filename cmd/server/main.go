@@ -3,6 +3,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"os"
 
@@ -11,11 +12,40 @@ import (
 )
 
 func main() {
+	skipPreflight := flag.Bool("skip-preflight", false, "skip the startup checks of LINE, Gemini, scraper DNS, and the database (same as NTPU_SKIP_PREFLIGHT=true)")
+	printConfig := flag.Bool("print-config", false, "print the effective configuration as JSON, secrets masked, and exit")
+	printSchema := flag.Bool("print-config-schema", false, "print the schema of the configuration variables as JSON and exit")
+	flag.Parse()
+
+	if *printSchema {
+		if err := config.WriteSchema(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to print config schema: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if *printConfig {
+		// Print even an invalid configuration: that is when it is needed
+		cfg, err := config.Inspect()
+		if printErr := cfg.WriteSettings(os.Stdout); printErr != nil {
+			fmt.Fprintf(os.Stderr, "Failed to print config: %v\n", printErr)
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	cfg, err := config.Load()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\nRun with --print-config to see the values read, or see docs/configuration.md\n", err)
 		os.Exit(1)
 	}
+	if *skipPreflight {
+		cfg.SkipPreflight = true
+	}
 
 	application, err := app.Initialize(context.Background(), cfg)
 	if err != nil {
@@ -0,0 +1,160 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Rollup metric names stored in the metric column.
+const (
+	MetricModuleCalls     = "module_calls"     // label: success, empty, error
+	MetricCacheOps        = "cache_ops"        // label: hit, miss
+	MetricScraperRequests = "scraper_requests" // label: success, error, timeout, not_found
+
+	// Intent telemetry (see IntentReport)
+	MetricIntentRouting        = "intent_routing"        // module: chosen module or none; label: matched modules joined by "+", or none
+	MetricIntentReformulations = "intent_reformulations" // label: keyword, nlu
+)
+
+// source maps a Prometheus counter onto a rollup metric.
+// Other labels (e.g., kind on ntpu_module_total) are summed away.
+type source struct {
+	family      string
+	metric      string
+	moduleLabel string
+	outcome     string
+}
+
+var sources = []source{
+	{family: "ntpu_module_total", metric: MetricModuleCalls, moduleLabel: "module", outcome: "status"},
+	{family: "ntpu_cache_operations_total", metric: MetricCacheOps, moduleLabel: "module", outcome: "result"},
+	{family: "ntpu_scraper_total", metric: MetricScraperRequests, moduleLabel: "module", outcome: "status"},
+	{family: "ntpu_intent_routing_total", metric: MetricIntentRouting, moduleLabel: "chosen", outcome: "matched"},
+	{family: "ntpu_intent_reformulations_total", metric: MetricIntentReformulations, moduleLabel: "module", outcome: "source"},
+}
+
+type counterKey struct {
+	metric, module, label string
+}
+
+// Exporter periodically converts cumulative counters into daily deltas.
+//
+// Counters start at zero when the process starts, so the first flush records
+// everything counted since startup. Deltas are attributed to the day of the
+// flush; flushing every few minutes keeps day boundaries accurate enough for
+// weekly reports.
+type Exporter struct {
+	gatherer prometheus.Gatherer
+	store    *Store
+	logger   *logger.Logger
+	now      func() time.Time
+	last     map[counterKey]float64 // Cumulative values at the previous flush
+}
+
+// NewExporter creates an exporter reading counters from gatherer.
+func NewExporter(gatherer prometheus.Gatherer, store *Store, log *logger.Logger) *Exporter {
+	return &Exporter{
+		gatherer: gatherer,
+		store:    store,
+		logger:   log,
+		now:      time.Now,
+		last:     make(map[counterKey]float64),
+	}
+}
+
+// Flush writes the counter increase since the previous flush to the store.
+// Flush is not safe for concurrent use; Run calls it from a single goroutine.
+func (e *Exporter) Flush(ctx context.Context) error {
+	current, err := e.collect()
+	if err != nil {
+		return err
+	}
+
+	day := e.now().In(lineutil.GetTaipeiLocation()).Format(DayFormat)
+	rows := make([]Row, 0, len(current))
+	for key, value := range current {
+		delta := value - e.last[key]
+		if delta < 0 {
+			// Counter was reset (e.g., registry recreated); count from zero
+			delta = value
+		}
+		if delta > 0 {
+			rows = append(rows, Row{Day: day, Metric: key.metric, Module: key.module, Label: key.label, Value: delta})
+		}
+	}
+
+	if err := e.store.Add(ctx, rows); err != nil {
+		return err
+	}
+	e.last = current
+	return nil
+}
+
+// collect sums the tracked counters by (metric, module, outcome).
+func (e *Exporter) collect() (map[counterKey]float64, error) {
+	families, err := e.gatherer.Gather()
+	if err != nil {
+		return nil, fmt.Errorf("gather metrics: %w", err)
+	}
+
+	bySource := make(map[string]source, len(sources))
+	for _, s := range sources {
+		bySource[s.family] = s
+	}
+
+	current := make(map[counterKey]float64)
+	for _, mf := range families {
+		src, ok := bySource[mf.GetName()]
+		if !ok {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			key := counterKey{metric: src.metric}
+			for _, lp := range m.GetLabel() {
+				switch lp.GetName() {
+				case src.moduleLabel:
+					key.module = lp.GetValue()
+				case src.outcome:
+					key.label = lp.GetValue()
+				}
+			}
+			current[key] += m.GetCounter().GetValue()
+		}
+	}
+	return current, nil
+}
+
+// Run flushes on every interval tick and prunes rollups older than retention.
+// A final flush runs when ctx is canceled so shutdown doesn't lose the last interval.
+func (e *Exporter) Run(ctx context.Context, interval, retention time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), interval)
+			if err := e.Flush(flushCtx); err != nil {
+				e.logger.WithError(err).Warn("Final analytics flush failed")
+			}
+			cancel()
+			return
+		case <-ticker.C:
+			if err := e.Flush(ctx); err != nil {
+				e.logger.WithError(err).Warn("Analytics flush failed")
+				continue
+			}
+			cutoff := e.now().Add(-retention).In(lineutil.GetTaipeiLocation()).Format(DayFormat)
+			if n, err := e.store.Prune(ctx, cutoff); err != nil {
+				e.logger.WithError(err).Warn("Analytics prune failed")
+			} else if n > 0 {
+				e.logger.WithField("rows", n).Debug("Pruned old analytics rollups")
+			}
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package analytics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/metrics"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/moduletest"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestExporter_FlushDeltas(t *testing.T) {
+	t.Parallel()
+	registry := prometheus.NewRegistry()
+	m := metrics.New(registry)
+	store := moduletest.OpenStore(t, Open)
+	ctx := context.Background()
+
+	e := NewExporter(registry, store, logger.New("error"))
+	// 2025-03-01 23:00 in Taipei
+	e.now = func() time.Time { return time.Date(2025, 3, 1, 15, 0, 0, 0, time.UTC) }
+
+	m.RecordModuleCall("course", "message", "success", 0.1)
+	m.RecordModuleCall("course", "postback", "success", 0.1)
+	m.RecordModuleCall("course", "message", "error", 0.1)
+	m.RecordCacheHit(context.Background(), "courses")
+	if err := e.Flush(ctx); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	// Nothing new: second flush on the same day must not double count
+	if err := e.Flush(ctx); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	// Next day in Taipei
+	e.now = func() time.Time { return time.Date(2025, 3, 1, 17, 0, 0, 0, time.UTC) }
+	m.RecordModuleCall("course", "message", "success", 0.1)
+	if err := e.Flush(ctx); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	rows, err := store.Rows(ctx, "2025-03-01", "2025-03-02")
+	if err != nil {
+		t.Fatalf("Rows() error = %v", err)
+	}
+
+	want := map[Row]bool{
+		{Day: "2025-03-01", Metric: MetricCacheOps, Module: "courses", Label: "hit", Value: 1}:       true,
+		{Day: "2025-03-01", Metric: MetricModuleCalls, Module: "course", Label: "error", Value: 1}:   true,
+		{Day: "2025-03-01", Metric: MetricModuleCalls, Module: "course", Label: "success", Value: 2}: true,
+		{Day: "2025-03-02", Metric: MetricModuleCalls, Module: "course", Label: "success", Value: 1}: true,
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("Rows() = %+v, want %d rows", rows, len(want))
+	}
+	for _, r := range rows {
+		if !want[r] {
+			t.Errorf("unexpected row %+v", r)
+		}
+	}
+}
+
+func TestExporter_FlushIntentTelemetry(t *testing.T) {
+	t.Parallel()
+	registry := prometheus.NewRegistry()
+	m := metrics.New(registry)
+	store := moduletest.OpenStore(t, Open)
+	ctx := context.Background()
+
+	e := NewExporter(registry, store, logger.New("error"))
+	e.now = func() time.Time { return time.Date(2025, 3, 1, 3, 0, 0, 0, time.UTC) }
+
+	m.RecordIntentRouting([]string{"contact", "course"}, "contact")
+	m.RecordIntentRouting(nil, "")
+	m.RecordIntentReformulation("contact", "keyword")
+	if err := e.Flush(ctx); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	rows, err := store.Rows(ctx, "2025-03-01", "2025-03-01")
+	if err != nil {
+		t.Fatalf("Rows() error = %v", err)
+	}
+	want := map[Row]bool{
+		{Day: "2025-03-01", Metric: MetricIntentReformulations, Module: "contact", Label: "keyword", Value: 1}: true,
+		{Day: "2025-03-01", Metric: MetricIntentRouting, Module: "contact", Label: "contact+course", Value: 1}: true,
+		{Day: "2025-03-01", Metric: MetricIntentRouting, Module: "none", Label: "none", Value: 1}:              true,
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("Rows() = %+v, want %d rows", rows, len(want))
+	}
+	for _, r := range rows {
+		if !want[r] {
+			t.Errorf("unexpected row %+v", r)
+		}
+	}
+}
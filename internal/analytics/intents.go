@@ -0,0 +1,118 @@
+package analytics
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// IntentRoute counts text messages by the modules whose CanHandle matched
+// them and the module that replied: one cell of the routing confusion matrix.
+type IntentRoute struct {
+	Matched  string // Matching modules in registration order joined by "+", or "none"
+	Chosen   string // Replying module, or "none" (left to NLU or help)
+	Messages float64
+}
+
+// Contested reports whether more than one module matched.
+func (r IntentRoute) Contested() bool {
+	return strings.Contains(r.Matched, "+")
+}
+
+// IntentModuleStats summarizes routing and reformulations of one module.
+type IntentModuleStats struct {
+	Module          string
+	Replies         float64 // Keyword messages the module replied to
+	Contested       float64 // Of Replies, messages another module also matched
+	Reformulated    float64 // Keyword replies followed quickly by another message
+	NLUReformulated float64 // NLU replies followed quickly by another message
+}
+
+// IntentReport summarizes intent telemetry over an inclusive day range.
+type IntentReport struct {
+	From, To string
+	Routes   []IntentRoute       // Sorted by messages, descending
+	Modules  []IntentModuleStats // Sorted by replies, descending
+}
+
+// BuildIntentReport summarizes intent telemetry from end-days+1 through end (inclusive).
+func BuildIntentReport(ctx context.Context, store *Store, end time.Time, days int) (*IntentReport, error) {
+	from, to := DayRange(end, days)
+
+	totals, err := store.Totals(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	r := summarizeIntents(totals)
+	r.From, r.To = from, to
+	return r, nil
+}
+
+// summarizeIntents folds intent totals into routes and per-module statistics.
+func summarizeIntents(totals []Row) *IntentReport {
+	r := &IntentReport{}
+	modules := make(map[string]*IntentModuleStats)
+	newStats := func(module string) func() *IntentModuleStats {
+		return func() *IntentModuleStats { return &IntentModuleStats{Module: module} }
+	}
+
+	for _, t := range totals {
+		switch t.Metric {
+		case MetricIntentRouting:
+			route := IntentRoute{Matched: t.Label, Chosen: t.Module, Messages: t.Value}
+			r.Routes = append(r.Routes, route)
+			if route.Chosen == "none" {
+				continue
+			}
+			m := getOrCreate(modules, t.Module, newStats(t.Module))
+			m.Replies += t.Value
+			if route.Contested() {
+				m.Contested += t.Value
+			}
+		case MetricIntentReformulations:
+			m := getOrCreate(modules, t.Module, newStats(t.Module))
+			if t.Label == "keyword" {
+				m.Reformulated += t.Value
+			} else {
+				m.NLUReformulated += t.Value
+			}
+		}
+	}
+
+	r.Modules = collectValues(modules)
+	slices.SortFunc(r.Routes, func(a, b IntentRoute) int {
+		return cmp.Or(cmp.Compare(b.Messages, a.Messages), cmp.Compare(a.Matched, b.Matched), cmp.Compare(a.Chosen, b.Chosen))
+	})
+	slices.SortFunc(r.Modules, func(a, b IntentModuleStats) int {
+		return cmp.Or(cmp.Compare(b.Replies, a.Replies), cmp.Compare(a.Module, b.Module))
+	})
+	return r
+}
+
+// Write prints the intent report as aligned plain-text tables.
+func (r *IntentReport) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "NTPU LineBot intent report %s ~ %s\n\n", r.From, r.To)
+
+	fmt.Fprintln(tw, "Routing (modules whose CanHandle matched -> module that replied)")
+	fmt.Fprintln(tw, "MATCHED\tCHOSEN\tMESSAGES")
+	for _, route := range r.Routes {
+		fmt.Fprintf(tw, "%s\t%s\t%.0f\n", route.Matched, route.Chosen, route.Messages)
+	}
+
+	fmt.Fprintln(tw, "\nModules")
+	fmt.Fprintln(tw, "MODULE\tREPLIES\tCONTESTED\tREFORMULATED\tREFORMULATION RATE\tAFTER NLU")
+	for _, m := range r.Modules {
+		fmt.Fprintf(tw, "%s\t%.0f\t%.0f\t%.0f\t%s\t%.0f\n",
+			m.Module, m.Replies, m.Contested, m.Reformulated, percent(m.Reformulated, m.Replies), m.NLUReformulated)
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,58 @@
+package analytics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSummarizeIntents(t *testing.T) {
+	t.Parallel()
+	r := summarizeIntents([]Row{
+		{Metric: MetricIntentRouting, Module: "contact", Label: "contact+course", Value: 4},
+		{Metric: MetricIntentRouting, Module: "contact", Label: "contact", Value: 6},
+		{Metric: MetricIntentRouting, Module: "course", Label: "course", Value: 20},
+		{Metric: MetricIntentRouting, Module: "none", Label: "none", Value: 7},
+		{Metric: MetricIntentReformulations, Module: "contact", Label: "keyword", Value: 3},
+		{Metric: MetricIntentReformulations, Module: "program", Label: "nlu", Value: 2},
+		{Metric: MetricModuleCalls, Module: "course", Label: "success", Value: 9}, // Not intent telemetry
+	})
+
+	if len(r.Routes) != 4 || r.Routes[0].Chosen != "course" {
+		t.Fatalf("Routes = %+v, want 4 routes with course first (most messages)", r.Routes)
+	}
+	if len(r.Modules) != 3 || r.Modules[0].Module != "course" {
+		t.Fatalf("Modules = %+v, want course, contact, program", r.Modules)
+	}
+	if got := r.Modules[1]; got.Replies != 10 || got.Contested != 4 || got.Reformulated != 3 {
+		t.Errorf("contact stats = %+v, want 10 replies, 4 contested, 3 reformulated", got)
+	}
+	if got := r.Modules[2]; got.Replies != 0 || got.NLUReformulated != 2 {
+		t.Errorf("program stats = %+v, want 0 replies, 2 reformulated after NLU", got)
+	}
+}
+
+func TestIntentReport_Write(t *testing.T) {
+	t.Parallel()
+	r := summarizeIntents([]Row{
+		{Metric: MetricIntentRouting, Module: "contact", Label: "contact+course", Value: 4},
+		{Metric: MetricIntentReformulations, Module: "contact", Label: "keyword", Value: 1},
+		{Metric: MetricIntentReformulations, Module: "program", Label: "nlu", Value: 1},
+	})
+	r.From, r.To = "2025-02-23", "2025-03-01"
+
+	var buf bytes.Buffer
+	if err := r.Write(&buf); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{"2025-02-23 ~ 2025-03-01", "contact+course", "25.0%", "program"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Write() output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "NaN") {
+		t.Errorf("Write() output contains NaN:\n%s", out)
+	}
+}
@@ -0,0 +1,154 @@
+package analytics
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"text/tabwriter"
+	"time"
+)
+
+// ModuleStats summarizes module calls over a report period.
+type ModuleStats struct {
+	Module string
+	Calls  float64
+	Errors float64
+}
+
+// CacheStats summarizes cache lookups over a report period.
+type CacheStats struct {
+	Module string
+	Hits   float64
+	Misses float64
+}
+
+// ScraperStats summarizes scraper requests over a report period.
+type ScraperStats struct {
+	Module   string
+	Requests float64
+	Failures float64 // error + timeout (not_found is a valid empty result)
+}
+
+// Report is a summary of rollups over an inclusive day range.
+type Report struct {
+	From, To string
+	Modules  []ModuleStats  // Sorted by calls, descending
+	Caches   []CacheStats   // Sorted by module name
+	Scrapers []ScraperStats // Sorted by module name
+}
+
+// DayRange returns the inclusive day range covering days days ending at end.
+func DayRange(end time.Time, days int) (from, to string) {
+	return end.AddDate(0, 0, -(days - 1)).Format(DayFormat), end.Format(DayFormat)
+}
+
+// BuildReport summarizes the days from end-days+1 through end (inclusive).
+func BuildReport(ctx context.Context, store *Store, end time.Time, days int) (*Report, error) {
+	from, to := DayRange(end, days)
+
+	totals, err := store.Totals(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	r := summarize(totals)
+	r.From, r.To = from, to
+	return r, nil
+}
+
+// summarize folds totals into per-module statistics.
+func summarize(totals []Row) *Report {
+	modules := make(map[string]*ModuleStats)
+	caches := make(map[string]*CacheStats)
+	scrapers := make(map[string]*ScraperStats)
+
+	for _, t := range totals {
+		switch t.Metric {
+		case MetricModuleCalls:
+			m := getOrCreate(modules, t.Module, func() *ModuleStats { return &ModuleStats{Module: t.Module} })
+			m.Calls += t.Value
+			if t.Label == "error" {
+				m.Errors += t.Value
+			}
+		case MetricCacheOps:
+			c := getOrCreate(caches, t.Module, func() *CacheStats { return &CacheStats{Module: t.Module} })
+			switch t.Label {
+			case "hit":
+				c.Hits += t.Value
+			case "miss":
+				c.Misses += t.Value
+			}
+		case MetricScraperRequests:
+			s := getOrCreate(scrapers, t.Module, func() *ScraperStats { return &ScraperStats{Module: t.Module} })
+			s.Requests += t.Value
+			if t.Label == "error" || t.Label == "timeout" {
+				s.Failures += t.Value
+			}
+		}
+	}
+
+	r := &Report{
+		Modules:  collectValues(modules),
+		Caches:   collectValues(caches),
+		Scrapers: collectValues(scrapers),
+	}
+	slices.SortFunc(r.Modules, func(a, b ModuleStats) int {
+		return cmp.Or(cmp.Compare(b.Calls, a.Calls), cmp.Compare(a.Module, b.Module))
+	})
+	slices.SortFunc(r.Caches, func(a, b CacheStats) int { return cmp.Compare(a.Module, b.Module) })
+	slices.SortFunc(r.Scrapers, func(a, b ScraperStats) int { return cmp.Compare(a.Module, b.Module) })
+	return r
+}
+
+// Write prints the report as aligned plain-text tables.
+func (r *Report) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "NTPU LineBot report %s ~ %s\n\n", r.From, r.To)
+
+	fmt.Fprintln(tw, "Module queries")
+	fmt.Fprintln(tw, "MODULE\tCALLS\tERRORS\tERROR RATE")
+	for _, m := range r.Modules {
+		fmt.Fprintf(tw, "%s\t%.0f\t%.0f\t%s\n", m.Module, m.Calls, m.Errors, percent(m.Errors, m.Calls))
+	}
+
+	fmt.Fprintln(tw, "\nCache")
+	fmt.Fprintln(tw, "MODULE\tHITS\tMISSES\tHIT RATE")
+	for _, c := range r.Caches {
+		fmt.Fprintf(tw, "%s\t%.0f\t%.0f\t%s\n", c.Module, c.Hits, c.Misses, percent(c.Hits, c.Hits+c.Misses))
+	}
+
+	fmt.Fprintln(tw, "\nScraper")
+	fmt.Fprintln(tw, "MODULE\tREQUESTS\tFAILURES\tERROR RATE")
+	for _, s := range r.Scrapers {
+		fmt.Fprintf(tw, "%s\t%.0f\t%.0f\t%s\n", s.Module, s.Requests, s.Failures, percent(s.Failures, s.Requests))
+	}
+
+	return tw.Flush()
+}
+
+func percent(part, total float64) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", part/total*100)
+}
+
+func getOrCreate[T any](m map[string]*T, key string, create func() *T) *T {
+	v, ok := m[key]
+	if !ok {
+		v = create()
+		m[key] = v
+	}
+	return v
+}
+
+func collectValues[T any](m map[string]*T) []T {
+	values := make([]T, 0, len(m))
+	for _, v := range m {
+		values = append(values, *v)
+	}
+	return values
+}
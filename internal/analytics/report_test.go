@@ -0,0 +1,61 @@
+package analytics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSummarize(t *testing.T) {
+	t.Parallel()
+	r := summarize([]Row{
+		{Metric: MetricModuleCalls, Module: "id", Label: "success", Value: 5},
+		{Metric: MetricModuleCalls, Module: "course", Label: "success", Value: 8},
+		{Metric: MetricModuleCalls, Module: "course", Label: "error", Value: 2},
+		{Metric: MetricCacheOps, Module: "courses", Label: "hit", Value: 3},
+		{Metric: MetricCacheOps, Module: "courses", Label: "miss", Value: 1},
+		{Metric: MetricScraperRequests, Module: "course", Label: "success", Value: 6},
+		{Metric: MetricScraperRequests, Module: "course", Label: "not_found", Value: 2},
+		{Metric: MetricScraperRequests, Module: "course", Label: "timeout", Value: 2},
+	})
+
+	if len(r.Modules) != 2 || r.Modules[0].Module != "course" {
+		t.Fatalf("Modules = %+v, want course first (most calls)", r.Modules)
+	}
+	if got := r.Modules[0]; got.Calls != 10 || got.Errors != 2 {
+		t.Errorf("course stats = %+v, want 10 calls, 2 errors", got)
+	}
+	if got := r.Caches[0]; got.Hits != 3 || got.Misses != 1 {
+		t.Errorf("cache stats = %+v, want 3 hits, 1 miss", got)
+	}
+	// not_found is a valid empty result, not a failure
+	if got := r.Scrapers[0]; got.Requests != 10 || got.Failures != 2 {
+		t.Errorf("scraper stats = %+v, want 10 requests, 2 failures", got)
+	}
+}
+
+func TestReport_Write(t *testing.T) {
+	t.Parallel()
+	r := summarize([]Row{
+		{Metric: MetricModuleCalls, Module: "course", Label: "success", Value: 3},
+		{Metric: MetricModuleCalls, Module: "course", Label: "error", Value: 1},
+		{Metric: MetricCacheOps, Module: "students", Label: "miss", Value: 0},
+	})
+	r.From, r.To = "2025-02-23", "2025-03-01"
+
+	var buf bytes.Buffer
+	if err := r.Write(&buf); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{"2025-02-23 ~ 2025-03-01", "course", "25.0%", "students"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Write() output missing %q:\n%s", want, out)
+		}
+	}
+	// Zero denominators render as "-" rather than NaN
+	if strings.Contains(out, "NaN") {
+		t.Errorf("Write() output contains NaN:\n%s", out)
+	}
+}
@@ -0,0 +1,177 @@
+// Package analytics aggregates Prometheus counters into daily rollups and
+// stores them in a dedicated SQLite database for weekly reporting.
+//
+// The rollups live in their own file (not the cache DB) so they survive
+// snapshot hot-swaps and cache rebuilds. The schema is a single fact table
+// keyed by (day, metric, module, label), which loads directly into
+// BigQuery or any other warehouse as a star-schema fact table.
+package analytics
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+)
+
+// DayFormat is the layout of the day column (Asia/Taipei calendar day).
+const DayFormat = "2006-01-02"
+
+// Row is a single daily rollup value.
+type Row struct {
+	Day    string  // Calendar day in DayFormat
+	Metric string  // MetricModuleCalls, MetricCacheOps, MetricScraperRequests, MetricIntent*
+	Module string  // Module or cache namespace (e.g., "course", "students")
+	Label  string  // Outcome label (e.g., "success", "hit")
+	Value  float64 // Count for the day
+}
+
+// Store persists daily rollups in SQLite.
+type Store struct {
+	*storage.AuxStore
+}
+
+// Open opens (or creates) the analytics database at path.
+func Open(ctx context.Context, path string) (*Store, error) {
+	db, err := storage.OpenAux(ctx, path, "analytics", initSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Store{AuxStore: storage.NewAuxStore(db)}, nil
+}
+
+func initSchema(ctx context.Context, db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS daily_rollups (
+		day TEXT NOT NULL,
+		metric TEXT NOT NULL,
+		module TEXT NOT NULL,
+		label TEXT NOT NULL,
+		value REAL NOT NULL,
+		PRIMARY KEY (day, metric, module, label)
+	) STRICT;
+	CREATE INDEX IF NOT EXISTS idx_daily_rollups_metric_day ON daily_rollups(metric, day);
+	`
+
+	if _, err := db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("create daily_rollups table: %w", err)
+	}
+
+	return nil
+}
+
+// Add accumulates rows into their daily totals in a single transaction.
+func (s *Store) Add(ctx context.Context, rows []Row) error {
+	db, err := s.Conn()
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO daily_rollups (day, metric, module, label, value)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(day, metric, module, label) DO UPDATE SET value = value + excluded.value
+	`)
+	if err != nil {
+		return fmt.Errorf("prepare upsert: %w", err)
+	}
+	defer func() { _ = stmt.Close() }()
+
+	for _, r := range rows {
+		if _, err := stmt.ExecContext(ctx, r.Day, r.Metric, r.Module, r.Label, r.Value); err != nil {
+			return fmt.Errorf("upsert rollup %s/%s/%s: %w", r.Metric, r.Module, r.Label, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit rollups: %w", err)
+	}
+	return nil
+}
+
+// Totals returns values summed over the inclusive day range [from, to],
+// grouped by metric, module and label. The Day field of returned rows is empty.
+func (s *Store) Totals(ctx context.Context, from, to string) ([]Row, error) {
+	db, err := s.Conn()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT metric, module, label, SUM(value)
+		FROM daily_rollups
+		WHERE day BETWEEN ? AND ?
+		GROUP BY metric, module, label
+		ORDER BY metric, module, label
+	`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("query totals: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var totals []Row
+	for rows.Next() {
+		var r Row
+		if err := rows.Scan(&r.Metric, &r.Module, &r.Label, &r.Value); err != nil {
+			return nil, fmt.Errorf("scan total: %w", err)
+		}
+		totals = append(totals, r)
+	}
+	return totals, rows.Err()
+}
+
+// Rows returns daily rollups in the inclusive day range [from, to],
+// ordered by day, metric, module and label.
+func (s *Store) Rows(ctx context.Context, from, to string) ([]Row, error) {
+	db, err := s.Conn()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT day, metric, module, label, value
+		FROM daily_rollups
+		WHERE day BETWEEN ? AND ?
+		ORDER BY day, metric, module, label
+	`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("query rollups: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var result []Row
+	for rows.Next() {
+		var r Row
+		if err := rows.Scan(&r.Day, &r.Metric, &r.Module, &r.Label, &r.Value); err != nil {
+			return nil, fmt.Errorf("scan rollup: %w", err)
+		}
+		result = append(result, r)
+	}
+	return result, rows.Err()
+}
+
+// Prune deletes rollups for days before the given day.
+// Returns the number of rows deleted.
+func (s *Store) Prune(ctx context.Context, before string) (int64, error) {
+	db, err := s.Conn()
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := db.ExecContext(ctx, "DELETE FROM daily_rollups WHERE day < ?", before)
+	if err != nil {
+		return 0, fmt.Errorf("prune rollups: %w", err)
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,77 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/moduletest"
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+)
+
+func TestStore_AddAccumulates(t *testing.T) {
+	t.Parallel()
+	store := moduletest.OpenStore(t, Open)
+	ctx := context.Background()
+
+	batches := [][]Row{
+		{{Day: "2025-03-01", Metric: MetricModuleCalls, Module: "course", Label: "success", Value: 3}},
+		{
+			{Day: "2025-03-01", Metric: MetricModuleCalls, Module: "course", Label: "success", Value: 2},
+			{Day: "2025-03-02", Metric: MetricModuleCalls, Module: "course", Label: "success", Value: 10},
+		},
+	}
+	for _, batch := range batches {
+		if err := store.Add(ctx, batch); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+
+	rows, err := store.Rows(ctx, "2025-03-01", "2025-03-01")
+	if err != nil {
+		t.Fatalf("Rows() error = %v", err)
+	}
+	if len(rows) != 1 || rows[0].Value != 5 {
+		t.Errorf("Rows(2025-03-01) = %+v, want single row with value 5", rows)
+	}
+
+	totals, err := store.Totals(ctx, "2025-03-01", "2025-03-07")
+	if err != nil {
+		t.Fatalf("Totals() error = %v", err)
+	}
+	if len(totals) != 1 || totals[0].Value != 15 {
+		t.Errorf("Totals() = %+v, want single row with value 15", totals)
+	}
+}
+
+func TestStore_Prune(t *testing.T) {
+	t.Parallel()
+	store := moduletest.OpenStore(t, Open)
+	ctx := context.Background()
+
+	if err := store.Add(ctx, []Row{
+		{Day: "2024-01-01", Metric: MetricCacheOps, Module: "courses", Label: "hit", Value: 1},
+		{Day: "2025-01-01", Metric: MetricCacheOps, Module: "courses", Label: "hit", Value: 1},
+	}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	n, err := store.Prune(ctx, "2025-01-01")
+	if err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	if n != 1 {
+		t.Errorf("Prune() deleted %d rows, want 1", n)
+	}
+}
+
+func TestStore_Closed(t *testing.T) {
+	t.Parallel()
+	store := moduletest.OpenStore(t, Open)
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := store.Add(context.Background(), []Row{{Day: "2025-01-01"}}); !errors.Is(err, storage.ErrDatabaseClosed) {
+		t.Errorf("Add() after Close error = %v, want storage.ErrDatabaseClosed", err)
+	}
+}
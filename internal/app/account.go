@@ -0,0 +1,111 @@
+package app
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/account"
+	"github.com/gin-gonic/gin"
+)
+
+// maxLinkParamLength caps the link token, state, and code query parameters.
+const maxLinkParamLength = 512
+
+// registerAccountRoutes mounts the account link pages. Both are opened in the
+// user's browser, so they answer with short plain-text pages on failure; the
+// link token, state, and nonce are single-use and expire (see account.Linker).
+//
+//	GET  /account/link?linkToken=…      redirect to the school SSO login
+//	GET  /account/callback?state=…&code=… SSO redirect URI; redirects to LINE
+//	POST /account/liff                   LIFF app start: {"url": link page}; needs
+//	                                     "Authorization: Bearer {LIFF access token}"
+//
+// The LIFF start is only mounted when the LIFF app is enabled too.
+func (a *Application) registerAccountRoutes(router gin.IRouter) {
+	group := router.Group("/account")
+	group.GET("/link", a.accountLink)
+	group.GET("/callback", a.accountCallback)
+	if a.accountLIFF != nil {
+		group.POST("/liff", a.accountLIFFStart)
+	}
+}
+
+// accountLIFFStart issues a link page URL for the LINE user behind the LIFF
+// access token, so linking can start from the LIFF app as from 綁定帳號.
+func (a *Application) accountLIFFStart(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	accessToken, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok || accessToken == "" || len(accessToken) > maxLinkParamLength {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "LIFF access token required"})
+		return
+	}
+
+	userID, err := a.accountLIFF.UserID(c.Request.Context(), accessToken)
+	switch {
+	case errors.Is(err, account.ErrLIFFToken):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid LIFF access token"})
+		return
+	case err != nil:
+		a.logger.WithError(err).Error("Account link LIFF verification failed")
+		c.JSON(http.StatusBadGateway, gin.H{"error": "LINE verification unavailable"})
+		return
+	}
+
+	startURL, err := a.accountLinker.StartURL(c.Request.Context(), userID)
+	if err != nil {
+		a.logger.WithError(err).Error("Account link LIFF start failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "link unavailable"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"url": startURL})
+}
+
+func (a *Application) accountLink(c *gin.Context) {
+	linkToken := c.Query("linkToken")
+	if linkToken == "" || len(linkToken) > maxLinkParamLength {
+		accountPage(c, http.StatusBadRequest, "連結無效，請回到 LINE 重新輸入「綁定帳號」")
+		return
+	}
+
+	loginURL, err := a.accountLinker.Begin(c.Request.Context(), linkToken)
+	if err != nil {
+		a.logger.WithError(err).Error("Account link start failed")
+		accountPage(c, http.StatusInternalServerError, "暫時無法綁定，請稍後再試")
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, loginURL)
+}
+
+func (a *Application) accountCallback(c *gin.Context) {
+	// The SSO reports a canceled or denied login with ?error=
+	if c.Query("error") != "" {
+		accountPage(c, http.StatusOK, "已取消登入，帳號未綁定")
+		return
+	}
+	state, code := c.Query("state"), c.Query("code")
+	if state == "" || code == "" || len(state) > maxLinkParamLength || len(code) > maxLinkParamLength {
+		accountPage(c, http.StatusBadRequest, "連結無效，請回到 LINE 重新輸入「綁定帳號」")
+		return
+	}
+
+	confirmURL, err := a.accountLinker.Callback(c.Request.Context(), state, code)
+	switch {
+	case errors.Is(err, account.ErrLinkExpired):
+		accountPage(c, http.StatusBadRequest, "連結已過期，請回到 LINE 重新輸入「綁定帳號」")
+		return
+	case err != nil:
+		a.logger.WithError(err).Error("Account link callback failed")
+		accountPage(c, http.StatusBadGateway, "學校登入驗證失敗，請稍後再試")
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, confirmURL)
+}
+
+// accountPage answers with a plain-text message for the browser.
+func accountPage(c *gin.Context, status int, message string) {
+	c.Header("Cache-Control", "no-store")
+	c.String(status, message)
+}
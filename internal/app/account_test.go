@@ -0,0 +1,73 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/account"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountRoutes(t *testing.T) {
+	t.Parallel()
+
+	store, err := account.Open(context.Background(), filepath.Join(t.TempDir(), "account.db"), strings.Repeat("k", 32))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	sso := account.NewSSO(account.SSOConfig{
+		AuthURL:     "https://sso.example.edu/authorize",
+		ClientID:    "client",
+		RedirectURL: "https://bot.example.com/account/callback",
+	})
+	app := &Application{
+		logger:        logger.New("error"),
+		accountLinker: account.NewLinker(store, sso, nil, "https://bot.example.com"),
+		accountLIFF:   account.NewLIFFUsers("1234567890-AbcdEfgh"),
+	}
+	router := gin.New()
+	app.registerAccountRoutes(router)
+
+	tests := []struct {
+		name     string
+		path     string
+		want     int
+		location string
+	}{
+		{"link redirects to SSO", "/account/link?linkToken=lt", http.StatusFound, "https://sso.example.edu/authorize?"},
+		{"link without token", "/account/link", http.StatusBadRequest, ""},
+		{"callback canceled", "/account/callback?error=access_denied", http.StatusOK, ""},
+		{"callback without code", "/account/callback?state=s", http.StatusBadRequest, ""},
+		{"callback unknown state", "/account/callback?state=s&code=c", http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.want, w.Code)
+			assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+			if tt.location != "" {
+				assert.True(t, strings.HasPrefix(w.Header().Get("Location"), tt.location), w.Header().Get("Location"))
+			}
+		})
+	}
+
+	// The LIFF start needs a LIFF access token before anything is asked of LINE
+	for _, auth := range []string{"", "Basic abc", "Bearer "} {
+		req := httptest.NewRequestWithContext(context.Background(), http.MethodPost, "/account/liff", nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, "Authorization %q", auth)
+	}
+}
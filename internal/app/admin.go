@@ -0,0 +1,446 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/bot"
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
+	domerrors "github.com/garyellow/ntpu-linebot-go/internal/errors"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/bugreport"
+	"github.com/gin-gonic/gin"
+)
+
+// moduleResponse is the JSON shape of a module in admin responses.
+type moduleResponse struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
+	// CanaryPercent is the share of chats routed to the canary (only for modules with one)
+	CanaryPercent *int `json:"canary_percent,omitempty"`
+}
+
+// setModuleRequest is the body of PUT /admin/modules/:name.
+type setModuleRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+// setCanaryRequest is the body of PUT /admin/modules/:name/canary.
+type setCanaryRequest struct {
+	Percent *int `json:"percent"`
+}
+
+// setLogLevelRequest is the body of PUT /admin/log-levels/:module.
+type setLogLevelRequest struct {
+	Level string `json:"level"`
+}
+
+// setLLMOutputRequest is the body of PUT /admin/llm-output.
+type setLLMOutputRequest struct {
+	Blocked *bool `json:"blocked"`
+}
+
+// setAssetRequest is the body of PUT /admin/assets/:key.
+type setAssetRequest struct {
+	URL string `json:"url"`
+}
+
+// bugReportResponse is the JSON shape of a bug report in admin responses.
+type bugReportResponse struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Note      string    `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+	// Exchange is the reported query and reply (omitted when the user had none)
+	Exchange *exchangeResponse `json:"exchange,omitempty"`
+}
+
+// exchangeResponse is the JSON shape of a reported exchange.
+type exchangeResponse struct {
+	Query      string    `json:"query"`
+	Module     string    `json:"module"`
+	Source     string    `json:"source"`
+	Reply      string    `json:"reply"`
+	DurationMs int64     `json:"duration_ms"`
+	RequestID  string    `json:"request_id"`
+	Time       time.Time `json:"time"`
+}
+
+// Bug report list size: default and cap of ?limit=.
+const (
+	defaultBugReportLimit = 20
+	maxBugReportLimit     = 100
+)
+
+func toBugReportResponse(r bugreport.Report) bugReportResponse {
+	resp := bugReportResponse{
+		ID:        r.ID,
+		UserID:    r.UserID,
+		Note:      r.Note,
+		CreatedAt: r.CreatedAt,
+	}
+	if r.HasExchange() {
+		resp.Exchange = &exchangeResponse{
+			Query:      r.Exchange.Query,
+			Module:     r.Exchange.Module,
+			Source:     r.Exchange.Source,
+			Reply:      r.Exchange.Reply,
+			DurationMs: r.Exchange.Duration.Milliseconds(),
+			RequestID:  r.Exchange.RequestID,
+			Time:       r.Exchange.Time,
+		}
+	}
+	return resp
+}
+
+func toModuleResponse(s bot.ModuleStatus) moduleResponse {
+	resp := moduleResponse{
+		Name:        s.Name,
+		DisplayName: s.DisplayName,
+		Description: s.Description,
+		Enabled:     s.Enabled,
+	}
+	if s.CanaryPercent >= 0 {
+		resp.CanaryPercent = &s.CanaryPercent
+	}
+	return resp
+}
+
+// registerAdminRoutes mounts the admin API behind Bearer token auth.
+//
+//	GET /admin/modules                list modules and their state
+//	PUT /admin/modules/:name          {"enabled": false} disables a module at runtime
+//	PUT /admin/modules/:name/canary   {"percent": 10} routes 10% of chats to the module's canary
+//	GET /admin/exports                signed export links (only when course export is enabled)
+//	GET /admin/log-levels             base log level and per-module overrides
+//	PUT /admin/log-levels/:module     {"level": "debug"} overrides the level of a module
+//	DELETE /admin/log-levels/:module  drops the override
+//	GET /admin/console                live log records as Server-Sent Events (?level=debug&module=course)
+//	GET /admin/assets                 template image URLs and their defaults
+//	PUT /admin/assets/:key            {"url": "https://..."} replaces an image until restart
+//	DELETE /admin/assets/:key         restores the default image
+//	GET /admin/expansion-prompts      query expansion prompt versions in use (only with LLM features)
+//	POST /admin/expansion-prompts/reload  re-reads NTPU_QUERY_EXPANSION_PROMPTS
+//	GET /admin/llm-output             state of the LLM output kill switch (only with LLM features)
+//	PUT /admin/llm-output             {"blocked": true} stops model text from reaching users until restart
+//	GET /admin/bug-reports            latest 回報問題 reports (?limit=20; only when bug reports are enabled)
+//	GET /admin/bug-reports/:id        one report by the ID the user and admins were given
+func (a *Application) registerAdminRoutes(router gin.IRouter) {
+	admin := router.Group("/admin", adminAuthMiddleware(a.cfg.AdminToken))
+	admin.GET("/modules", a.listModules)
+	admin.PUT("/modules/:name", a.setModuleEnabled)
+	admin.PUT("/modules/:name/canary", a.setModuleCanary)
+	admin.GET("/log-levels", a.listLogLevels)
+	admin.PUT("/log-levels/:module", a.setLogLevel)
+	admin.DELETE("/log-levels/:module", a.resetLogLevel)
+	admin.GET("/assets", a.listAssets)
+	admin.PUT("/assets/:key", a.setAsset)
+	admin.DELETE("/assets/:key", a.resetAsset)
+	if a.logStream != nil {
+		admin.GET("/console", a.streamConsole)
+	}
+	if a.outputGuard != nil {
+		admin.GET("/llm-output", a.getLLMOutput)
+		admin.PUT("/llm-output", a.setLLMOutput)
+	}
+	if a.prompts != nil {
+		admin.GET("/expansion-prompts", a.listExpansionPrompts)
+		admin.POST("/expansion-prompts/reload", a.reloadExpansionPrompts)
+	}
+	if a.bugReports != nil {
+		admin.GET("/bug-reports", a.listBugReports)
+		admin.GET("/bug-reports/:id", a.getBugReport)
+	}
+	if a.exportSigner != nil {
+		admin.GET("/exports", a.exportLinks)
+	}
+}
+
+func (a *Application) listModules(c *gin.Context) {
+	statuses := a.botRegistry.Modules()
+	modules := make([]moduleResponse, len(statuses))
+	for i, s := range statuses {
+		modules[i] = toModuleResponse(s)
+	}
+	c.JSON(http.StatusOK, gin.H{"modules": modules})
+}
+
+func (a *Application) setModuleEnabled(c *gin.Context) {
+	name := c.Param("name")
+
+	var req setModuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Enabled == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": `body must be {"enabled": true|false}`})
+		return
+	}
+
+	if err := a.botRegistry.SetEnabled(name, *req.Enabled); err != nil {
+		if errors.Is(err, bot.ErrModuleNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "module not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	a.logger.WithModule(name).
+		WithField("enabled", *req.Enabled).
+		WithField("client_ip", c.ClientIP()).
+		Info("Module toggled via admin API")
+	a.writeModule(c, name)
+}
+
+func (a *Application) setModuleCanary(c *gin.Context) {
+	name := c.Param("name")
+
+	var req setCanaryRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Percent == nil || *req.Percent < 0 || *req.Percent > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": `body must be {"percent": 0-100}`})
+		return
+	}
+
+	if err := a.botRegistry.SetCanaryPercent(name, *req.Percent); err != nil {
+		switch {
+		case errors.Is(err, bot.ErrModuleNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "module not found"})
+		case errors.Is(err, bot.ErrNoCanary):
+			c.JSON(http.StatusConflict, gin.H{"error": "module has no canary"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	a.logger.WithModule(name).
+		WithField("percent", *req.Percent).
+		WithField("client_ip", c.ClientIP()).
+		Info("Canary share changed via admin API")
+	a.writeModule(c, name)
+}
+
+// writeModule responds with the status of module name.
+func (a *Application) writeModule(c *gin.Context, name string) {
+	for _, s := range a.botRegistry.Modules() {
+		if s.Name == name {
+			c.JSON(http.StatusOK, toModuleResponse(s))
+			return
+		}
+	}
+}
+
+func (a *Application) listLogLevels(c *gin.Context) {
+	levels := a.logger.Levels()
+	c.JSON(http.StatusOK, gin.H{"level": levels.Base(), "modules": levels.Modules()})
+}
+
+func (a *Application) setLogLevel(c *gin.Context) {
+	module := c.Param("module")
+
+	var req setLogLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": `body must be {"level": "debug"|"info"|"warn"|"error"}`})
+		return
+	}
+	if err := a.logger.Levels().SetModule(module, req.Level); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	a.logger.WithModule(module).
+		WithField("level", req.Level).
+		WithField("client_ip", c.ClientIP()).
+		Info("Log level overridden via admin API")
+	a.listLogLevels(c)
+}
+
+func (a *Application) resetLogLevel(c *gin.Context) {
+	module := c.Param("module")
+	a.logger.Levels().ResetModule(module)
+
+	a.logger.WithModule(module).
+		WithField("client_ip", c.ClientIP()).
+		Info("Log level override removed via admin API")
+	a.listLogLevels(c)
+}
+
+func (a *Application) listAssets(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"assets": a.assets.List()})
+}
+
+func (a *Application) setAsset(c *gin.Context) {
+	key := c.Param("key")
+
+	var req setAssetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": `body must be {"url": "https://..."}`})
+		return
+	}
+	if err := a.assets.Set(key, req.URL); err != nil {
+		writeAssetError(c, err)
+		return
+	}
+
+	a.logger.WithField("asset", key).
+		WithField("url", req.URL).
+		WithField("client_ip", c.ClientIP()).
+		Info("Asset overridden via admin API")
+	a.listAssets(c)
+}
+
+func (a *Application) getLLMOutput(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"blocked": a.outputGuard.Blocked()})
+}
+
+func (a *Application) setLLMOutput(c *gin.Context) {
+	var req setLLMOutputRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Blocked == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": `body must be {"blocked": true|false}`})
+		return
+	}
+
+	a.outputGuard.SetBlocked(*req.Blocked)
+	a.logger.WithField("blocked", *req.Blocked).
+		WithField("client_ip", c.ClientIP()).
+		Warn("LLM output kill switch toggled via admin API")
+	a.getLLMOutput(c)
+}
+
+func (a *Application) listExpansionPrompts(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"versions": a.prompts.Versions()})
+}
+
+func (a *Application) reloadExpansionPrompts(c *gin.Context) {
+	versions, err := a.prompts.Reload()
+	if err != nil {
+		// The versions already loaded keep serving
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	a.logger.WithField("versions", versions).
+		WithField("client_ip", c.ClientIP()).
+		Info("Query expansion prompts reloaded via admin API")
+	c.JSON(http.StatusOK, gin.H{"versions": versions})
+}
+
+func (a *Application) listBugReports(c *gin.Context) {
+	limit := defaultBugReportLimit
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxBugReportLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("limit must be between 1 and %d", maxBugReportLimit)})
+			return
+		}
+		limit = n
+	}
+
+	reports, err := a.bugReports.Recent(c.Request.Context(), limit)
+	if err != nil {
+		a.logger.WithError(err).Error("Failed to list bug reports")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list bug reports"})
+		return
+	}
+	resp := make([]bugReportResponse, len(reports))
+	for i, r := range reports {
+		resp[i] = toBugReportResponse(r)
+	}
+	c.JSON(http.StatusOK, gin.H{"reports": resp})
+}
+
+func (a *Application) getBugReport(c *gin.Context) {
+	report, ok, err := a.bugReports.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		a.logger.WithError(err).Error("Failed to get bug report")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get bug report"})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown bug report"})
+		return
+	}
+	c.JSON(http.StatusOK, toBugReportResponse(report))
+}
+
+func (a *Application) resetAsset(c *gin.Context) {
+	key := c.Param("key")
+	if err := a.assets.Reset(key); err != nil {
+		writeAssetError(c, err)
+		return
+	}
+
+	a.logger.WithField("asset", key).
+		WithField("client_ip", c.ClientIP()).
+		Info("Asset override removed via admin API")
+	a.listAssets(c)
+}
+
+func writeAssetError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domerrors.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "asset not found"})
+	case errors.Is(err, domerrors.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// streamConsole streams log records to an operator as Server-Sent Events:
+// "log" events carry one JSON record, "dropped" events the number of records
+// skipped because the client fell behind. Records below the configured log
+// levels are included, so debug traces of queries, module routing and
+// scrapes can be watched without changing the container logs.
+func (a *Application) streamConsole(c *gin.Context) {
+	level, err := logger.ParseLevel(c.DefaultQuery("level", "debug"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	module := c.Query("module")
+
+	// The server write timeout is sized for webhooks, not long-lived streams
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		a.logger.WithError(err).Debug("Failed to clear console write deadline")
+	}
+
+	sub := a.logStream.Subscribe(logger.SubscribeOptions{Level: level, Module: module})
+	defer sub.Close()
+	a.logger.WithField("level", c.DefaultQuery("level", "debug")).
+		WithField("filter_module", module).
+		WithField("client_ip", c.ClientIP()).
+		Info("Console attached via admin API")
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no") // Disable nginx response buffering
+	c.Status(http.StatusOK)
+	c.Writer.Flush() // Let the client know it is attached before the first record
+	keepalive := time.NewTicker(config.ConsoleKeepalive)
+	defer keepalive.Stop()
+
+	ctx := c.Request.Context()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case line, ok := <-sub.C:
+			if !ok {
+				return false
+			}
+			if n := sub.TakeDropped(); n > 0 {
+				c.SSEvent("dropped", n)
+			}
+			c.SSEvent("log", string(line))
+			return true
+		case <-keepalive.C:
+			_, err := io.WriteString(w, ": keepalive\n\n")
+			return err == nil
+		}
+	})
+}
@@ -0,0 +1,357 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/bot"
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
+	"github.com/garyellow/ntpu-linebot-go/internal/data"
+	"github.com/garyellow/ntpu-linebot-go/internal/genai"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/bugreport"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/usage"
+	"github.com/garyellow/ntpu-linebot-go/internal/sticker"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAdminToken = "0123456789abcdef"
+
+func setupAdminRouter(t *testing.T) (*gin.Engine, *bot.Registry) {
+	t.Helper()
+	log := logger.New("error")
+	registry := bot.NewRegistry()
+	registry.RegisterModule(usage.NewHandler(nil, nil, sticker.NewManager(nil, nil, log)), bot.ModuleInfo{DisplayName: "配額查詢"})
+
+	app := &Application{
+		cfg:         &config.Config{AdminEnabled: true, AdminToken: testAdminToken},
+		logger:      log,
+		botRegistry: registry,
+		assets:      data.NewAssetRegistry(data.Campus),
+	}
+	router := gin.New()
+	app.registerAdminRoutes(router)
+	return router, registry
+}
+
+func adminRequest(method, path, body string) *http.Request {
+	req := httptest.NewRequestWithContext(context.Background(), method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestAdminModules_RequiresToken(t *testing.T) {
+	t.Parallel()
+	router, _ := setupAdminRouter(t)
+
+	for _, header := range []string{"", "Bearer wrong-token", "Basic " + testAdminToken} {
+		req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/admin/modules", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, "Authorization: %q", header)
+	}
+}
+
+func TestAdminModules_Toggle(t *testing.T) {
+	t.Parallel()
+	router, registry := setupAdminRouter(t)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, adminRequest(http.MethodPut, "/admin/modules/usage", `{"enabled": false}`))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, registry.IsEnabled("usage"))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, adminRequest(http.MethodGet, "/admin/modules", ""))
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Modules []moduleResponse `json:"modules"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Modules, 1)
+	assert.Equal(t, moduleResponse{Name: "usage", DisplayName: "配額查詢", Enabled: false}, resp.Modules[0])
+}
+
+func TestAdminModules_Errors(t *testing.T) {
+	t.Parallel()
+	router, _ := setupAdminRouter(t)
+
+	tests := []struct {
+		name string
+		path string
+		body string
+		want int
+	}{
+		{"Unknown module", "/admin/modules/unknown", `{"enabled": false}`, http.StatusNotFound},
+		{"Missing enabled", "/admin/modules/usage", `{}`, http.StatusBadRequest},
+		{"Malformed body", "/admin/modules/usage", `not json`, http.StatusBadRequest},
+		{"Canary of unknown module", "/admin/modules/unknown/canary", `{"percent": 10}`, http.StatusNotFound},
+		{"Module without canary", "/admin/modules/usage/canary", `{"percent": 10}`, http.StatusConflict},
+		{"Canary percent out of range", "/admin/modules/usage/canary", `{"percent": 101}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, adminRequest(http.MethodPut, tt.path, tt.body))
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
+
+func TestAdminLogLevels(t *testing.T) {
+	t.Parallel()
+	router, _ := setupAdminRouter(t)
+	type levelsResponse struct {
+		Level   string            `json:"level"`
+		Modules map[string]string `json:"modules"`
+	}
+	do := func(method, path, body string) (int, levelsResponse) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, adminRequest(method, path, body))
+		var resp levelsResponse
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		}
+		return w.Code, resp
+	}
+
+	code, resp := do(http.MethodPut, "/admin/log-levels/course", `{"level": "debug"}`)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, levelsResponse{Level: "error", Modules: map[string]string{"course": "debug"}}, resp)
+
+	code, _ = do(http.MethodPut, "/admin/log-levels/course", `{"level": "verbose"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = do(http.MethodPut, "/admin/log-levels/course", `not json`)
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, resp = do(http.MethodDelete, "/admin/log-levels/course", "")
+	require.Equal(t, http.StatusOK, code)
+	assert.Empty(t, resp.Modules)
+
+	code, resp = do(http.MethodGet, "/admin/log-levels", "")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "error", resp.Level)
+}
+
+func TestAdminAssets(t *testing.T) {
+	t.Parallel()
+	router, _ := setupAdminRouter(t)
+	do := func(method, path, body string) (int, []data.Asset) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, adminRequest(method, path, body))
+		var resp struct {
+			Assets []data.Asset `json:"assets"`
+		}
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		}
+		return w.Code, resp.Assets
+	}
+	find := func(assets []data.Asset, key string) data.Asset {
+		for _, a := range assets {
+			if a.Key == key {
+				return a
+			}
+		}
+		t.Fatalf("asset %s missing from %+v", key, assets)
+		return data.Asset{}
+	}
+
+	code, assets := do(http.MethodPut, "/admin/assets/"+data.AssetLogo, `{"url": "https://cdn.example.com/logo.png"}`)
+	require.Equal(t, http.StatusOK, code)
+	logo := find(assets, data.AssetLogo)
+	assert.True(t, logo.Overridden)
+	assert.Equal(t, "https://cdn.example.com/logo.png", logo.URL)
+
+	code, _ = do(http.MethodPut, "/admin/assets/"+data.AssetLogo, `{"url": "http://cdn.example.com/logo.png"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = do(http.MethodPut, "/admin/assets/unknown", `{"url": "https://cdn.example.com/logo.png"}`)
+	assert.Equal(t, http.StatusNotFound, code)
+
+	code, assets = do(http.MethodDelete, "/admin/assets/"+data.AssetLogo, "")
+	require.Equal(t, http.StatusOK, code)
+	logo = find(assets, data.AssetLogo)
+	assert.False(t, logo.Overridden)
+	assert.Equal(t, logo.DefaultURL, logo.URL)
+}
+
+func TestAdminConsole(t *testing.T) {
+	t.Parallel()
+	stream := logger.NewStream()
+	log := logger.NewWithOptions("error", io.Discard, logger.Options{Stream: stream})
+	app := &Application{
+		cfg:         &config.Config{AdminEnabled: true, AdminToken: testAdminToken},
+		logger:      log,
+		logStream:   stream,
+		botRegistry: bot.NewRegistry(),
+	}
+	router := gin.New()
+	app.registerAdminRoutes(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/admin/console?module=course", http.NoBody)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer func() { _ = res.Body.Close() }()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+	// The handler is subscribed once the headers are flushed
+	log.WithModule("id").Debug("Student cache miss")
+	log.WithModule("course").Debug("Course cache miss")
+
+	scanner := bufio.NewScanner(res.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "data:") {
+			assert.Contains(t, line, "Course cache miss", "the first record matches the module filter")
+			return
+		}
+	}
+	t.Fatalf("stream ended without a record: %v", scanner.Err())
+}
+
+func TestAdminConsole_InvalidLevel(t *testing.T) {
+	t.Parallel()
+	stream := logger.NewStream()
+	app := &Application{
+		cfg:         &config.Config{AdminEnabled: true, AdminToken: testAdminToken},
+		logger:      logger.NewWithOptions("error", io.Discard, logger.Options{Stream: stream}),
+		logStream:   stream,
+		botRegistry: bot.NewRegistry(),
+	}
+	router := gin.New()
+	app.registerAdminRoutes(router)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, adminRequest(http.MethodGet, "/admin/console?level=verbose", ""))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAdminExpansionPrompts(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "prompts.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"variants": [{"version": "v1"}]}`), 0o600))
+	prompts, err := genai.LoadExpansionPrompts(path)
+	require.NoError(t, err)
+
+	app := &Application{
+		cfg:         &config.Config{AdminEnabled: true, AdminToken: testAdminToken},
+		logger:      logger.New("error"),
+		botRegistry: bot.NewRegistry(),
+		prompts:     prompts,
+	}
+	router := gin.New()
+	app.registerAdminRoutes(router)
+	do := func(method, path string) (int, string) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, adminRequest(method, path, ""))
+		return w.Code, w.Body.String()
+	}
+
+	code, body := do(http.MethodGet, "/admin/expansion-prompts")
+	require.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{"versions": ["v1"]}`, body)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"variants": [{"version": "v1"}, {"version": "v2", "examples": []}]}`), 0o600))
+	code, body = do(http.MethodPost, "/admin/expansion-prompts/reload")
+	require.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{"versions": ["v1", "v2"]}`, body)
+
+	// A broken file is rejected and the loaded versions keep serving
+	require.NoError(t, os.WriteFile(path, []byte(`{"variants": [`), 0o600))
+	code, _ = do(http.MethodPost, "/admin/expansion-prompts/reload")
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+	assert.Equal(t, []string{"v1", "v2"}, prompts.Versions())
+}
+
+func TestAdminLLMOutput(t *testing.T) {
+	t.Parallel()
+	guard := genai.NewOutputGuard(false)
+	app := &Application{
+		cfg:         &config.Config{AdminEnabled: true, AdminToken: testAdminToken},
+		logger:      logger.New("error"),
+		botRegistry: bot.NewRegistry(),
+		outputGuard: guard,
+	}
+	router := gin.New()
+	app.registerAdminRoutes(router)
+	do := func(method, body string) (int, string) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, adminRequest(method, "/admin/llm-output", body))
+		return w.Code, w.Body.String()
+	}
+
+	code, body := do(http.MethodGet, "")
+	require.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{"blocked": false}`, body)
+
+	code, body = do(http.MethodPut, `{"blocked": true}`)
+	require.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{"blocked": true}`, body)
+	assert.True(t, guard.Blocked())
+
+	code, _ = do(http.MethodPut, `{}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestAdminBugReports(t *testing.T) {
+	t.Parallel()
+	store, err := bugreport.Open(context.Background(), filepath.Join(t.TempDir(), "bugreports.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	require.NoError(t, store.RecordExchange(context.Background(), "U1", bot.Exchange{
+		Query: "課程 微積分", Module: "course", Source: "keyword", Reply: "[Flex] 課程列表",
+		Duration: 1500 * time.Millisecond, RequestID: "req-1", Time: time.Now(),
+	}))
+	report, err := store.File(context.Background(), "U1", "時間不對")
+	require.NoError(t, err)
+
+	app := &Application{
+		cfg:         &config.Config{AdminEnabled: true, AdminToken: testAdminToken},
+		logger:      logger.New("error"),
+		botRegistry: bot.NewRegistry(),
+		bugReports:  store,
+	}
+	router := gin.New()
+	app.registerAdminRoutes(router)
+	get := func(path string) (int, string) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, adminRequest(http.MethodGet, path, ""))
+		return w.Code, w.Body.String()
+	}
+
+	code, body := get("/admin/bug-reports")
+	require.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, report.ID)
+
+	code, body = get("/admin/bug-reports/" + report.ID)
+	require.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, `"request_id":"req-1"`)
+	assert.Contains(t, body, `"duration_ms":1500`)
+
+	code, _ = get("/admin/bug-reports/000000-AAAAAA")
+	assert.Equal(t, http.StatusNotFound, code)
+	code, _ = get("/admin/bug-reports?limit=0")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
@@ -0,0 +1,200 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/metrics"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+// errorRule is an error rate that alerts chat admins when it stays above its
+// threshold over config.ErrorAlertWindow.
+type errorRule struct {
+	name      string  // Key for cooldowns and logs
+	title     string  // Alert message title
+	hint      string  // Where to look first
+	threshold float64 // Error ratio (errors / total) that fires
+	minEvents float64 // Windows with fewer events never fire
+	countOnly bool    // No total: fires on minEvents errors in the window
+	// count returns the cumulative errors and total events of the rule.
+	count func(counters map[string][]metrics.CounterSample) (errors, total float64)
+}
+
+// errorRules are the error rates watched by errorAlerter.
+var errorRules = []errorRule{
+	{
+		name:      "scraper",
+		title:     "爬蟲錯誤率偏高",
+		hint:      "學校網站可能無法連線或封鎖了來源 IP。",
+		threshold: 0.3,
+		minEvents: 20,
+		count: func(c map[string][]metrics.CounterSample) (float64, float64) {
+			errors := sumCounter(c, "ntpu_scraper_total", func(l map[string]string) bool {
+				return l["status"] == "error" || l["status"] == "timeout"
+			})
+			return errors, sumCounter(c, "ntpu_scraper_total", nil)
+		},
+	},
+	{
+		name:      "webhook_5xx",
+		title:     "Webhook 5xx 偏高",
+		hint:      "LINE 會重送失敗的事件；請檢查服務日誌。",
+		threshold: 0.05,
+		minEvents: 20,
+		count: func(c map[string][]metrics.CounterSample) (float64, float64) {
+			webhook := func(l map[string]string) bool { return l["route"] == "/webhook" }
+			errors := sumCounter(c, "ntpu_http_server_requests_total", func(l map[string]string) bool {
+				return webhook(l) && strings.HasPrefix(l["status_code"], "5")
+			})
+			return errors, sumCounter(c, "ntpu_http_server_requests_total", webhook)
+		},
+	},
+	{
+		name:      "db",
+		title:     "資料庫查詢持續失敗",
+		hint:      "可能是磁碟已滿或資料庫損毀；可用 cmd/dbtool check 檢查。",
+		minEvents: 10,
+		countOnly: true,
+		count: func(c map[string][]metrics.CounterSample) (float64, float64) {
+			return sumCounter(c, "ntpu_db_errors_total", nil), 0
+		},
+	},
+}
+
+// sumCounter adds up the series of counter name whose labels match (nil matches all).
+func sumCounter(counters map[string][]metrics.CounterSample, name string, match func(labels map[string]string) bool) float64 {
+	var sum float64
+	for _, sample := range counters[name] {
+		if match == nil || match(sample.Labels) {
+			sum += sample.Value
+		}
+	}
+	return sum
+}
+
+// errorSample is a rule's cumulative counts at one check.
+type errorSample struct {
+	at     time.Time
+	errors float64
+	total  float64
+}
+
+// errorAlerter samples error counters every config.ErrorAlertCheckInterval
+// and pushes an alert to chat admins (NTPU_ADMIN_USER_IDS) when a rule stays
+// above its threshold over config.ErrorAlertWindow, at most once per rule per
+// config.ErrorAlertCooldown. Run it from a single goroutine.
+type errorAlerter struct {
+	metrics  *metrics.Metrics
+	pusher   adminPusher
+	logger   *logger.Logger
+	adminIDs []string
+	rules    []errorRule
+
+	samples  map[string][]errorSample
+	lastSent map[string]time.Time
+	now      func() time.Time
+}
+
+// newErrorAlerter creates an alerter for errorRules.
+func newErrorAlerter(m *metrics.Metrics, pusher adminPusher, log *logger.Logger, adminIDs []string) *errorAlerter {
+	return &errorAlerter{
+		metrics:  m,
+		pusher:   pusher,
+		logger:   log,
+		adminIDs: adminIDs,
+		rules:    errorRules,
+		samples:  make(map[string][]errorSample),
+		lastSent: make(map[string]time.Time),
+		now:      time.Now,
+	}
+}
+
+// Run checks the rules until ctx is done.
+func (a *errorAlerter) Run(ctx context.Context) {
+	ticker := time.NewTicker(config.ErrorAlertCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.check(ctx)
+		}
+	}
+}
+
+// check samples every rule and alerts on those over threshold.
+func (a *errorAlerter) check(ctx context.Context) {
+	counters, err := a.metrics.Counters()
+	if err != nil {
+		a.logger.WithError(err).Warn("Failed to gather metrics for error alerts")
+		return
+	}
+
+	now := a.now()
+	for _, rule := range a.rules {
+		errs, total := rule.count(counters)
+		samples := append(a.samples[rule.name], errorSample{at: now, errors: errs, total: total})
+		// Keep the newest sample at or before the window start as the baseline
+		for len(samples) > 1 && now.Sub(samples[1].at) >= config.ErrorAlertWindow {
+			samples = samples[1:]
+		}
+		a.samples[rule.name] = samples
+
+		base := samples[0]
+		if now.Sub(base.at) < config.ErrorAlertWindow {
+			continue // Not sampled for a whole window yet
+		}
+		windowErrors, windowTotal := errs-base.errors, total-base.total
+		if !rule.fires(windowErrors, windowTotal) {
+			continue
+		}
+		if last, ok := a.lastSent[rule.name]; ok && now.Sub(last) < config.ErrorAlertCooldown {
+			continue
+		}
+		a.lastSent[rule.name] = now
+		a.alert(ctx, rule, windowErrors, windowTotal)
+	}
+}
+
+// fires reports whether a window with errors out of total events crosses the rule.
+func (r errorRule) fires(errors, total float64) bool {
+	if r.countOnly {
+		return errors >= r.minEvents
+	}
+	return total >= r.minEvents && errors/total >= r.threshold
+}
+
+// alert pushes the alert for rule to every admin.
+func (a *errorAlerter) alert(ctx context.Context, rule errorRule, errors, total float64) {
+	window := int(config.ErrorAlertWindow.Minutes())
+	detail := fmt.Sprintf("近 %d 分鐘失敗 %.0f 次", window, errors)
+	if !rule.countOnly {
+		detail = fmt.Sprintf("近 %d 分鐘失敗 %.0f / %.0f 次（%.0f%%，門檻 %.0f%%）",
+			window, errors, total, 100*errors/total, 100*rule.threshold)
+	}
+	log := a.logger.WithField("rule", rule.name).
+		WithField("errors", errors).
+		WithField("total", total)
+	log.Warn("Sustained error rate, alerting admins")
+
+	pushCtx, cancel := context.WithTimeout(ctx, config.ErrorAlertTimeout)
+	defer cancel()
+
+	msg := lineutil.NewTextMessage(fmt.Sprintf("⚠️ %s\n\n%s\n\n%s", rule.title, detail, rule.hint))
+	for _, id := range a.adminIDs {
+		if err := a.pusher.Push(pushCtx, &messaging_api.PushMessageRequest{
+			To:       id,
+			Messages: []messaging_api.MessageInterface{msg},
+		}); err != nil {
+			log.WithError(err).Warn("Failed to push error rate alert")
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorAlerter_SustainedScraperErrors(t *testing.T) {
+	t.Parallel()
+	m := metrics.New(prometheus.NewRegistry())
+	pusher := &fakeAdminPusher{}
+	alerter := newErrorAlerter(m, pusher, logger.New("error"), []string{"Uadmin"})
+	now := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	alerter.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	// Errors from before the first sample are not part of any window
+	for range 50 {
+		m.RecordScraper("course", "error", 1)
+	}
+	alerter.check(ctx)
+
+	scrape := func(errors, successes int) {
+		for range errors {
+			m.RecordScraper("course", "error", 1)
+		}
+		for range successes {
+			m.RecordScraper("course", "success", 1)
+		}
+	}
+
+	scrape(15, 15)
+	now = now.Add(config.ErrorAlertWindow / 2)
+	alerter.check(ctx)
+	assert.Empty(t, pusher.tos, "no alert before a whole window is sampled")
+
+	now = now.Add(config.ErrorAlertWindow / 2)
+	alerter.check(ctx)
+	assert.Equal(t, []string{"Uadmin"}, pusher.tos, "50% errors over the window alerts")
+
+	scrape(15, 15)
+	now = now.Add(config.ErrorAlertCheckInterval)
+	alerter.check(ctx)
+	assert.Len(t, pusher.tos, 1, "cooldown holds repeat alerts")
+
+	scrape(1, 40)
+	now = now.Add(config.ErrorAlertCooldown)
+	alerter.check(ctx)
+	assert.Len(t, pusher.tos, 1, "a recovered rate does not alert after the cooldown")
+}
+
+func TestErrorRule_Fires(t *testing.T) {
+	t.Parallel()
+	ratio := errorRule{threshold: 0.3, minEvents: 20}
+	count := errorRule{minEvents: 10, countOnly: true}
+
+	tests := []struct {
+		name   string
+		rule   errorRule
+		errors float64
+		total  float64
+		want   bool
+	}{
+		{"ratio over threshold", ratio, 9, 20, true},
+		{"ratio under threshold", ratio, 5, 20, false},
+		{"too few events", ratio, 10, 10, false},
+		{"count reached", count, 10, 0, true},
+		{"count short", count, 9, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, tt.rule.fires(tt.errors, tt.total))
+		})
+	}
+}
@@ -3,12 +3,16 @@ package app
 
 import (
 	"context"
+	"crypto/rand"
 	"errors"
 	"fmt"
 	"log/slog"
+	"math/big"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"runtime"
 	"runtime/debug"
 	"strings"
 	"sync"
@@ -16,20 +20,45 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/garyellow/ntpu-linebot-go/internal/analytics"
+	"github.com/garyellow/ntpu-linebot-go/internal/backup"
 	"github.com/garyellow/ntpu-linebot-go/internal/bot"
 	"github.com/garyellow/ntpu-linebot-go/internal/buildinfo"
+	"github.com/garyellow/ntpu-linebot-go/internal/buzz"
 	"github.com/garyellow/ntpu-linebot-go/internal/config"
 	"github.com/garyellow/ntpu-linebot-go/internal/ctxutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/data"
+	"github.com/garyellow/ntpu-linebot-go/internal/degraded"
 	"github.com/garyellow/ntpu-linebot-go/internal/delta"
+	"github.com/garyellow/ntpu-linebot-go/internal/export"
+	"github.com/garyellow/ntpu-linebot-go/internal/faults"
 	"github.com/garyellow/ntpu-linebot-go/internal/genai"
+	"github.com/garyellow/ntpu-linebot-go/internal/imageproxy"
+	"github.com/garyellow/ntpu-linebot-go/internal/jobs"
+	"github.com/garyellow/ntpu-linebot-go/internal/liff"
+	"github.com/garyellow/ntpu-linebot-go/internal/lineapi"
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
 	"github.com/garyellow/ntpu-linebot-go/internal/logger"
 	"github.com/garyellow/ntpu-linebot-go/internal/maintenance"
+	"github.com/garyellow/ntpu-linebot-go/internal/membudget"
 	"github.com/garyellow/ntpu-linebot-go/internal/metrics"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/account"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/bugreport"
 	"github.com/garyellow/ntpu-linebot-go/internal/modules/contact"
 	"github.com/garyellow/ntpu-linebot-go/internal/modules/course"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/dept"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/history"
 	"github.com/garyellow/ntpu-linebot-go/internal/modules/id"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/leaderboard"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/news"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/prefix"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/privacy"
 	"github.com/garyellow/ntpu-linebot-go/internal/modules/program"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/role"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/share"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/timetable"
 	"github.com/garyellow/ntpu-linebot-go/internal/modules/usage"
+	"github.com/garyellow/ntpu-linebot-go/internal/msgtmpl"
 	"github.com/garyellow/ntpu-linebot-go/internal/rag"
 	"github.com/garyellow/ntpu-linebot-go/internal/ratelimit"
 	"github.com/garyellow/ntpu-linebot-go/internal/s3client"
@@ -44,6 +73,7 @@ import (
 	"github.com/garyellow/ntpu-linebot-go/internal/webhook"
 	sentrygin "github.com/getsentry/sentry-go/gin"
 	"github.com/gin-gonic/gin"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -53,10 +83,12 @@ import (
 type Application struct {
 	cfg            *config.Config
 	logger         *logger.Logger
+	logStream      *logger.Stream // Live log records for GET /admin/console; nil when the admin API is disabled
 	db             *storage.DB
 	hotSwapDB      *storage.HotSwapDB // Used when S3 snapshot sync is enabled
 	snapshotMgr    *snapshot.Manager  // S3 snapshot manager (nil if disabled)
 	snapshotReady  *atomic.Bool       // True if a snapshot was successfully downloaded/applied
+	replicaLoaded  bool               // True if Litestream mode started with a cached database
 	deltaLog       *delta.S3Log       // S3 delta log (nil if disabled)
 	scheduleStore  *maintenance.S3ScheduleStore
 	metrics        *metrics.Metrics
@@ -64,13 +96,43 @@ type Application struct {
 	scraperClient  *scraper.Client
 	stickerManager *sticker.Manager
 	webhookHandler *webhook.Handler
+	lineClient     *lineapi.Client
+	jobRunner      *jobs.Runner
+	errorAlerter   *errorAlerter       // nil without chat admins
+	analytics      *analytics.Exporter // nil when analytics rollups are disabled
+	analyticsStore *analytics.Store
+	exportSigner   *export.Signer      // nil when course export is disabled
+	timetable      *timetable.Handler  // nil when timetable images are disabled
+	share          *share.Handler      // nil when share links are disabled
+	imageProxy     *imageproxy.Proxy   // nil when the image proxy is disabled
+	assets         *data.AssetRegistry // Template image URLs; overridable via the admin API
+	liffCatalog    *liff.Catalog       // nil when the course filter LIFF app is disabled
+	liffPage       []byte              // rendered LIFF page HTML
+	historyStore   *history.Store      // nil when query history is disabled
+	leaderboard    *leaderboard.Poster // nil when the group leaderboard is disabled
+	leaderboardDB  *leaderboard.Store
+	prefixStore    *prefix.Store      // nil when group trigger prefixes are disabled
+	accountLinker  *account.Linker    // nil when account linking is disabled
+	accountLIFF    *account.LIFFUsers // nil unless both account linking and the LIFF app are enabled
+	roleStore      *role.Store        // nil when staff roles are disabled
+	bugReports     *bugreport.Store   // nil when bug reports are disabled
+	courseBuzz     *buzz.Enricher     // nil when course buzz is disabled
+	buzzStore      *buzz.Store
+	backupMgr      *backup.Manager    // nil when cache backups are disabled
+	degradedMode   bool               // True when serving the degraded snapshot; data jobs stay off
+	degradedExport *degraded.Exporter // nil when degraded mode is disabled or active
 	server         *http.Server
 	bm25Index      *rag.BM25Index
-	intentParser   genai.IntentParser  // Interface type for multi-provider support
-	queryExpander  genai.QueryExpander // Interface type for multi-provider support
+	intentParser   genai.IntentParser      // Interface type for multi-provider support
+	queryExpander  genai.QueryExpander     // Interface type for multi-provider support
+	prompts        *genai.ExpansionPrompts // nil when LLM features are disabled; reloadable via the admin API
+	outputGuard    *genai.OutputGuard      // nil when LLM features are disabled; kill switch toggled via the admin API
 	llmLimiter     *ratelimit.KeyedLimiter
 	userLimiter    *ratelimit.KeyedLimiter
+	moduleLimiter  *ratelimit.KeyedLimiter // nil when per-module rate limiting is disabled
+	botRegistry    *bot.Registry           // Module registry (runtime enable/disable via admin API)
 	sessionStore   *session.Store
+	groupAnswers   *session.Answers
 	semesterCache  *course.SemesterCache  // Shared cache for semester data (updated by refresh task)
 	readinessState *warmup.ReadinessState // Tracks initial refresh completion for readiness
 	wg             sync.WaitGroup         // Track background goroutines for graceful shutdown
@@ -79,10 +141,18 @@ type Application struct {
 // Initialize creates and initializes a new application with all dependencies.
 func Initialize(ctx context.Context, cfg *config.Config) (*Application, error) {
 	version := resolveLogVersion(cfg)
+	var logStream *logger.Stream
+	if cfg.IsAdminEnabled() {
+		logStream = logger.NewStream()
+	}
 	log := logger.NewWithOptions(cfg.LogLevel, os.Stdout, logger.Options{
 		BetterStackToken:    cfg.BetterStackToken,
 		BetterStackEndpoint: cfg.BetterStackEndpoint,
 		Version:             version,
+		ModuleLevels:        cfg.LogModuleLevels,
+		Sampling:            cfg.LogSampling,
+		RedactPII:           cfg.LogRedactPII,
+		Stream:              logStream,
 	})
 
 	readinessState := warmup.NewReadinessState(cfg.WarmupMaxWait)
@@ -104,6 +174,24 @@ func Initialize(ctx context.Context, cfg *config.Config) (*Application, error) {
 		WithField("s3_snapshot", cfg.IsS3Enabled()).
 		WithField("llm_features", cfg.IsLLMEnabled()).
 		WithField("metrics_auth", cfg.IsMetricsAuthEnabled()).
+		WithField("admin_api", cfg.IsAdminEnabled()).
+		WithField("admin_chat_users", len(cfg.AdminUserIDs)).
+		WithField("analytics", cfg.IsAnalyticsEnabled()).
+		WithField("export", cfg.IsExportEnabled()).
+		WithField("timetable", cfg.IsTimetableEnabled()).
+		WithField("share", cfg.IsShareEnabled()).
+		WithField("liff", cfg.IsLIFFEnabled()).
+		WithField("query_history", cfg.IsQueryHistoryEnabled()).
+		WithField("group_leaderboard", cfg.IsGroupLeaderboardEnabled()).
+		WithField("course_buzz", cfg.IsCourseBuzzEnabled()).
+		WithField("group_prefix", cfg.IsGroupPrefixEnabled()).
+		WithField("account_link", cfg.IsAccountLinkEnabled()).
+		WithField("staff_roles", cfg.IsStaffRolesEnabled()).
+		WithField("dept_news", cfg.IsDeptNewsEnabled()).
+		WithField("image_proxy", cfg.IsImageProxyEnabled()).
+		WithField("fault_injection", cfg.IsFaultInjectionEnabled()).
+		WithField("memory_budget", cfg.IsMemoryBudgetEnabled()).
+		WithField("bug_reports", cfg.IsBugReportsEnabled()).
 		Info("Feature status")
 
 	// Warn on ignored credentials when feature flags are disabled
@@ -122,6 +210,20 @@ func Initialize(ctx context.Context, cfg *config.Config) (*Application, error) {
 	if !cfg.IsMetricsAuthEnabled() && cfg.MetricsPassword != "" {
 		log.Warn("Metrics password provided but NTPU_METRICS_AUTH_ENABLED=false, metrics auth is disabled")
 	}
+	if !cfg.IsAdminEnabled() && cfg.AdminToken != "" {
+		log.Warn("Admin token provided but NTPU_ADMIN_ENABLED=false, admin API is disabled")
+	}
+
+	// 23. Fault Injection (resilience testing; fails requests on purpose)
+	var faultInjector *faults.Injector
+	if cfg.IsFaultInjectionEnabled() {
+		var faultErr error
+		faultInjector, faultErr = faults.New(cfg.Faults)
+		if faultErr != nil {
+			return nil, fmt.Errorf("%s: %w", config.EnvFaults, faultErr)
+		}
+		log.WithField("rates", faultInjector.Rates()).Warn("Fault injection enabled, scraper, storage, and LLM calls will fail on purpose")
+	}
 
 	// 1. Better Stack Logging
 	if cfg.IsBetterStackEnabled() {
@@ -157,6 +259,7 @@ func Initialize(ctx context.Context, cfg *config.Config) (*Application, error) {
 	var snapshotMgr *snapshot.Manager
 	var deltaLog *delta.S3Log
 	var scheduleStore *maintenance.S3ScheduleStore
+	var backupStore backup.Store
 	useLocalDB := true // Flag to track if we should use local DB
 
 	snapshotReady := &atomic.Bool{}
@@ -173,6 +276,9 @@ func Initialize(ctx context.Context, cfg *config.Config) (*Application, error) {
 		if clientErr != nil {
 			log.WithError(clientErr).Warn("S3 client initialization failed, falling back to local database")
 		} else {
+			if cfg.BackupS3Prefix != "" {
+				backupStore = backup.NewS3Store(objectClient, cfg.BackupS3Prefix)
+			}
 			snapshotMgr = snapshot.New(objectClient, snapshot.Config{
 				SnapshotKey:    cfg.S3SnapshotKey,
 				LockKey:        cfg.S3LockKey,
@@ -229,16 +335,67 @@ func Initialize(ctx context.Context, cfg *config.Config) (*Application, error) {
 	}
 
 	// Fallback to local database if S3 snapshot sync is disabled or failed
+	var degradedSnap *degraded.Snapshot // 20. Degraded Mode: set when serving the JSON snapshot
 	if useLocalDB {
 		var dbErr error
 		db, dbErr = storage.New(ctx, cfg.SQLitePath(), cfg.CacheTTL)
-		if dbErr != nil {
+		switch {
+		case dbErr == nil:
+			log.WithField("path", cfg.SQLitePath()).
+				WithField("cache_ttl", cfg.CacheTTL).
+				WithField("db_mode", "local").
+				Info("Database connected")
+		case cfg.IsDegradedModeEnabled():
+			var loadErr error
+			db, degradedSnap, loadErr = degraded.Load(ctx, cfg.DegradedSnapshotPath(), ":memory:", cfg.CacheTTL)
+			if loadErr != nil {
+				return nil, fmt.Errorf("database: %w", errors.Join(dbErr, loadErr))
+			}
+			log.WithError(dbErr).
+				WithField("path", cfg.DegradedSnapshotPath()).
+				WithField("exported_at", degradedSnap.ExportedAt).
+				WithField("db_mode", "degraded").
+				Error("Database failed to open, serving the degraded snapshot read-only")
+		default:
 			return nil, fmt.Errorf("database: %w", dbErr)
 		}
-		log.WithField("path", cfg.SQLitePath()).
-			WithField("cache_ttl", cfg.CacheTTL).
-			WithField("db_mode", "local").
-			Info("Database connected")
+	}
+	db.SetTenant(cfg.Tenant)
+	db.SetSyllabusCompression(cfg.SyllabusCompression)
+	db.SetQueryAnalysis(cfg.DBQueryAnalysis)
+	db.SetTTLs(cfg.CacheTTLs)
+	db.SetTTLOverride(course.EnrollmentTTLOverride)
+	db.SetFaults(faultInjector)
+
+	// 16. Litestream: a cache restored from the replica serves right away
+	replicaLoaded := false
+	if cfg.IsLitestreamEnabled() && degradedSnap == nil {
+		db.SetReplicated(true)
+		if count, err := db.CountCourses(ctx); err == nil && count > 0 {
+			replicaLoaded = true
+		}
+		log.WithField("restored", replicaLoaded).Info("Litestream replication mode enabled")
+	}
+
+	// 15. Cache Backups (rotated copies for cmd/dbtool restore)
+	if cfg.BackupDir != "" {
+		backupStore = backup.NewDirStore(cfg.BackupDir)
+	}
+	var backupMgr *backup.Manager
+	switch {
+	case degradedSnap != nil:
+		// Backing up the snapshot would rotate out the good backups operators restore from
+	case backupStore != nil:
+		backupMgr = backup.New(db, backupStore, backup.Config{
+			Interval:  cfg.BackupInterval,
+			Retention: cfg.BackupRetention,
+			TempDir:   cfg.DataDir,
+		}, log)
+		log.WithField("interval", cfg.BackupInterval).
+			WithField("retention", cfg.BackupRetention).
+			Info("Cache backups enabled")
+	case cfg.IsBackupEnabled():
+		log.Warn("S3 client unavailable, cache backups disabled")
 	}
 
 	registry := prometheus.NewRegistry()
@@ -248,17 +405,58 @@ func Initialize(ctx context.Context, cfg *config.Config) (*Application, error) {
 		collectors.NewBuildInfoCollector(),
 	)
 	m := metrics.New(registry)
+	m.SetBuildInfo(firstNonEmpty(version, "unknown"), firstNonEmpty(buildinfo.Commit, "unknown"), runtime.Version())
+	m.SetConfigFingerprint(cfg.Fingerprint())
+	db.SetErrorReporter(m.RecordDBError)
 
 	// Initialize global metrics for genai package
 	metrics.InitGlobal(m)
 
-	scraperClient := scraper.NewClient(cfg.ScraperTimeout, cfg.ScraperMaxRetries, cfg.ScraperBaseURLs)
+	// Shared by the BM25 index and the fuzzy-match list caches; 0 MiB measures without evicting
+	memBudget := membudget.New(int64(cfg.MemoryBudgetMB) << 20)
+	memBudget.SetMetrics(m)
+
+	scraperClient, err := scraper.NewClientWithOptions(cfg.ScraperTimeout, cfg.ScraperMaxRetries, cfg.ScraperBaseURLs, scraper.Options{
+		ProxyURL:        cfg.ScraperProxyURL,
+		SourceProxyURLs: cfg.ScraperSourceProxies,
+		LocalAddr:       cfg.ScraperBindAddr,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scraper client: %w", err)
+	}
+	scraperClient.SetUserAgents(cfg.ScraperUserAgents)
+	scraperClient.SetFaults(faultInjector)
 	stickerMgr := sticker.NewManager(db, scraperClient, log)
 
+	// Image asset overrides replace campus.json URLs before handlers build replies
+	for key, assetURL := range cfg.AssetURLs {
+		if err := data.Assets.Set(key, assetURL); err != nil {
+			return nil, fmt.Errorf("%s: %w", config.EnvAssetURLs, err)
+		}
+	}
+
+	// 22. Image Proxy (template and quick reply images served from /img).
+	// Set before any handler precomputes messages with image URLs.
+	var imageProxy *imageproxy.Proxy
+	if cfg.IsImageProxyEnabled() {
+		// Own client like course buzz: the upstreams are not NTPU sources
+		var imageClient *scraper.Client
+		imageClient, err = scraper.NewClientWithOptions(config.ImageProxyRequestTimeout, 1, imageproxy.BaseURLs(), scraper.Options{
+			ProxyURL:  cfg.ScraperProxyURL,
+			LocalAddr: cfg.ScraperBindAddr,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("image proxy client: %w", err)
+		}
+		imageProxy = imageproxy.NewProxy(imageClient, imageproxy.BaseURLs(), log)
+		lineutil.UseImageProxy(cfg.PublicBaseURL+"/img", imageproxy.Hosts())
+	}
+
 	// Shared Chinese word segmenter for BM25 + suggest features
 	seg := stringutil.NewSegmenter()
 
 	bm25Index := rag.NewBM25Index(log, seg)
+	bm25Index.SetBudget(memBudget)
 	if err := bm25Index.Initialize(ctx, db); err != nil {
 		log.WithError(err).Warn("BM25 initialization failed")
 	}
@@ -266,8 +464,23 @@ func Initialize(ctx context.Context, cfg *config.Config) (*Application, error) {
 	// 4. LLM Initialization
 	var intentParser genai.IntentParser
 	var queryExpander genai.QueryExpander
+	var expansionPrompts *genai.ExpansionPrompts
+	var outputGuard *genai.OutputGuard
 	if cfg.IsLLMEnabled() {
 		llmCfg := buildLLMConfig(cfg)
+		llmCfg.Faults = faultInjector
+		// Model text is checked before it can reach users
+		outputGuard = genai.NewOutputGuard(cfg.LLMOutputBlocked)
+		llmCfg.Guard = outputGuard
+
+		// A bad prompt file fails startup like a bad template override
+		expansionPrompts, err = genai.LoadExpansionPrompts(cfg.QueryExpansionPrompts)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", config.EnvQueryExpansionPrompts, err)
+		}
+		if cfg.QueryExpansionPrompts != "" {
+			log.WithField("versions", expansionPrompts.Versions()).Info("Query expansion prompts loaded")
+		}
 
 		var ipErr, qeErr error
 		intentParser, ipErr = genai.CreateIntentParser(ctx, llmCfg)
@@ -308,26 +521,357 @@ func Initialize(ctx context.Context, cfg *config.Config) (*Application, error) {
 		MetricType:    ratelimit.MetricTypeUser,
 	})
 
-	idHandler := id.NewHandler(db, scraperClient, m, log, stickerMgr, deltaLog)
+	texts, err := msgtmpl.Load(cfg.Bot.TemplateDir)
+	if err != nil {
+		return nil, fmt.Errorf("message templates: %w", err)
+	}
+	if cfg.Bot.TemplateDir != "" {
+		log.WithField("dir", cfg.Bot.TemplateDir).Info("Message template overrides loaded")
+	}
+
+	// 10. Share Links (course and program bubbles get a 分享 button)
+	var shareLinker *share.Linker
+	var shareHandler *share.Handler
+	if cfg.IsShareEnabled() {
+		shareLinker = share.NewLinker(cfg.LineBotID)
+		shareHandler = share.NewHandler(shareLinker, cfg.PublicBaseURL, stickerMgr)
+	}
+
+	// 14. Course Buzz (討論熱度 on course details; counts are fetched in the background)
+	var buzzStore *buzz.Store
+	var buzzEnricher *buzz.Enricher
+	var buzzLookup course.BuzzLookup // stays a nil interface when disabled
+	if cfg.IsCourseBuzzEnabled() {
+		buzzStore, err = buzz.Open(ctx, cfg.CourseBuzzDBPath())
+		if err != nil {
+			return nil, fmt.Errorf("course buzz: %w", err)
+		}
+		// Own client: third-party sites get their own rate limits and a single retry.
+		// They are not NTPU sources, so only the global proxy and bind address apply.
+		var buzzClient *scraper.Client
+		buzzClient, err = scraper.NewClientWithOptions(config.CourseBuzzRequestTimeout, 1, buzz.BaseURLs(), scraper.Options{
+			ProxyURL:  cfg.ScraperProxyURL,
+			LocalAddr: cfg.ScraperBindAddr,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("course buzz client: %w", err)
+		}
+		buzzEnricher = buzz.NewEnricher(buzzStore, buzz.NewFetcher(buzzClient), m, log, cfg.CourseBuzzTTL)
+		buzzLookup = buzzEnricher
+		log.WithField("path", cfg.CourseBuzzDBPath()).
+			WithField("ttl", cfg.CourseBuzzTTL).
+			Info("Course buzz enabled")
+	}
+
+	// 19. Staff Roles (admins verify staff; contact hides staff-only fields from others)
+	var roleStore *role.Store
+	var roleHandler *role.Handler
+	var roleLookup contact.RoleLookup // stays a nil interface when disabled
+	if cfg.IsStaffRolesEnabled() {
+		roleStore, err = role.Open(ctx, cfg.RolesDBPath())
+		if err != nil {
+			return nil, fmt.Errorf("staff roles: %w", err)
+		}
+		roleHandler = role.NewHandler(roleStore, cfg.AdminUserIDs, stickerMgr)
+		roleLookup = roleStore
+		log.WithField("path", cfg.RolesDBPath()).Info("Staff roles enabled")
+	}
+
+	// 21. Department News (📰 系上公告; id department replies link to it)
+	var newsHandler *news.Handler
+	if cfg.IsDeptNewsEnabled() {
+		newsHandler = news.NewHandler(db, scraperClient, cfg.DeptNewsURLs, stickerMgr)
+		log.WithField("departments", len(cfg.DeptNewsURLs)).Info("Department news enabled")
+	}
+
+	idHandler := id.NewHandler(db, scraperClient, m, log, stickerMgr, deltaLog, texts, newsHandler != nil)
+
+	var lineOptions []messaging_api.MessagingApiAPIOption
+	if cfg.Bot.LineAPIBaseURL != "" {
+		lineOptions = append(lineOptions, messaging_api.WithEndpoint(cfg.Bot.LineAPIBaseURL))
+	}
+	lineClient, err := lineapi.New(lineapi.Config{
+		ChannelToken: cfg.LineChannelToken,
+		Metrics:      m,
+		Logger:       log,
+		DryRun:       cfg.Bot.WebhookDryRun,
+		Options:      lineOptions,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("line client: %w", err)
+	}
+
+	// Preflight: a bad token, key, or hostname fails the start, not the first request
+	if cfg.SkipPreflight {
+		log.Warn("Startup preflight checks skipped")
+	} else {
+		checks := buildPreflightChecks(cfg, lineClient, db, degradedSnap == nil, net.DefaultResolver)
+		if err := runPreflight(ctx, checks); err != nil {
+			return nil, fmt.Errorf("preflight failed (set NTPU_SKIP_PREFLIGHT=true or --skip-preflight to start anyway): %w", err)
+		}
+		log.WithField("checks", len(checks)).Info("Startup preflight checks passed")
+	}
+
+	// Parsers report page structure drift (metric + admin push alert) instead of caching empty results
+	scraperClient.SetDriftReporter(newDriftAlerter(m, lineClient, log, cfg.AdminUserIDs))
+
+	// Sustained scraper, webhook and DB error rates page the same admins
+	var errAlerter *errorAlerter
+	if len(cfg.AdminUserIDs) > 0 {
+		errAlerter = newErrorAlerter(m, lineClient, log, cfg.AdminUserIDs)
+	}
 
-	// Create shared semester cache for course and program handlers
+	// Background jobs (course deep search) push their results when done
+	jobRunner := jobs.NewRunner(lineClient, m, log, jobs.DefaultConcurrency)
+
+	// Create shared semester cache for course, program, and dept handlers
 	semesterCache := course.NewSemesterCache()
 	refreshSemesterCacheFromDB(ctx, db, semesterCache, log, "startup")
-	courseHandler := course.NewHandler(db, scraperClient, m, log, stickerMgr, deltaLog, bm25Index, queryExpander, llmLimiter, semesterCache, seg)
+	courseHandler := course.NewHandler(db, scraperClient, m, log, stickerMgr, deltaLog, bm25Index, queryExpander, llmLimiter, semesterCache, seg, texts, shareLinker, jobRunner, buzzLookup)
+	courseHandler.SetBudget(memBudget)
+	courseHandler.SetExpansionPrompts(expansionPrompts)
+
+	contactHandler := contact.NewHandler(db, scraperClient, m, log, stickerMgr, cfg.Bot.MaxContactsPerSearch, deltaLog, seg, roleLookup)
+	programHandler := program.NewHandler(db, m, log, stickerMgr, semesterCache, shareLinker)
+	programHandler.SetBudget(memBudget)
+	usageHandler := usage.NewHandler(userLimiter, llmLimiter, stickerMgr)
+	// 系所總覽 aggregates the contact, course, and student caches
+	deptHandler := dept.NewHandler(db, semesterCache, stickerMgr)
+
+	// 9. Timetable Images
+	var timetableHandler *timetable.Handler
+	if cfg.IsTimetableEnabled() {
+		font, err := timetable.LoadFont(cfg.TimetableFontPath)
+		if err != nil {
+			return nil, fmt.Errorf("timetable font: %w", err)
+		}
+		timetableHandler = timetable.NewHandler(db, timetable.NewRenderer(font), cfg.PublicBaseURL, stickerMgr)
+		log.WithField("font", cfg.TimetableFontPath).Info("Timetable images enabled")
+	}
 
-	contactHandler := contact.NewHandler(db, scraperClient, m, log, stickerMgr, cfg.Bot.MaxContactsPerSearch, deltaLog, seg)
-	programHandler := program.NewHandler(db, m, log, stickerMgr, semesterCache)
-	usageHandler := usage.NewHandler(userLimiter, llmLimiter, log, stickerMgr)
+	// 11. Course Filter LIFF
+	var liffCatalog *liff.Catalog
+	var liffPage []byte
+	if cfg.IsLIFFEnabled() {
+		liffPage, err = liff.RenderPage(cfg.LIFFID, cfg.IsAccountLinkEnabled())
+		if err != nil {
+			return nil, fmt.Errorf("liff page: %w", err)
+		}
+		liffCatalog = liff.NewCatalog(db, semesterCache)
+	}
 
-	botRegistry := bot.NewRegistry()
-	botRegistry.Register(contactHandler)
-	botRegistry.Register(courseHandler)
-	botRegistry.Register(idHandler)
-	botRegistry.Register(programHandler)
-	botRegistry.Register(usageHandler)
+	// 12. Query History (separate DB so it survives snapshot hot-swaps)
+	var historyStore *history.Store
+	var historyHandler *history.Handler
+	var historyRecorder bot.QueryRecorder // stays a nil interface when disabled
+	if cfg.IsQueryHistoryEnabled() {
+		historyStore, err = history.Open(ctx, cfg.QueryHistoryDBPath())
+		if err != nil {
+			return nil, fmt.Errorf("query history: %w", err)
+		}
+		historyHandler = history.NewHandler(historyStore, stickerMgr)
+		historyRecorder = historyStore
+		log.WithField("path", cfg.QueryHistoryDBPath()).Info("Query history enabled")
+	}
+
+	// 25. Bug Reports (the processor keeps each user's last exchange; 回報問題 files it)
+	var bugReportStore *bugreport.Store
+	var bugReportHandler *bugreport.Handler
+	var exchangeRecorder bot.ExchangeRecorder // stays a nil interface when disabled
+	if cfg.IsBugReportsEnabled() {
+		bugReportStore, err = bugreport.Open(ctx, cfg.BugReportsDBPath())
+		if err != nil {
+			return nil, fmt.Errorf("bug reports: %w", err)
+		}
+		bugReportHandler = bugreport.NewHandler(bugReportStore, lineClient, cfg.AdminUserIDs, stickerMgr)
+		exchangeRecorder = bugReportStore
+		log.WithField("path", cfg.BugReportsDBPath()).Info("Bug reports enabled")
+	}
+
+	// 13. Group Leaderboard (groups opt in; the weekly post is pushed via lineClient)
+	var leaderboardStore *leaderboard.Store
+	var leaderboardHandler *leaderboard.Handler
+	var groupRecorder bot.GroupQueryRecorder // stays a nil interface when disabled
+	if cfg.IsGroupLeaderboardEnabled() {
+		leaderboardStore, err = leaderboard.Open(ctx, cfg.LeaderboardDBPath())
+		if err != nil {
+			return nil, fmt.Errorf("group leaderboard: %w", err)
+		}
+		leaderboardHandler = leaderboard.NewHandler(leaderboardStore, log, stickerMgr)
+		groupRecorder = leaderboardStore
+		log.WithField("path", cfg.LeaderboardDBPath()).Info("Group leaderboard enabled")
+	}
+
+	// 17. Group Trigger Prefix (groups set one; the processor skips unprefixed chatter)
+	var prefixStore *prefix.Store
+	var prefixHandler *prefix.Handler
+	var triggerPrefixes bot.TriggerPrefixes // stays a nil interface when disabled
+	if cfg.IsGroupPrefixEnabled() {
+		prefixStore, err = prefix.Open(ctx, cfg.GroupPrefixDBPath())
+		if err != nil {
+			return nil, fmt.Errorf("group trigger prefix: %w", err)
+		}
+		prefixHandler = prefix.NewHandler(prefixStore, log, stickerMgr)
+		triggerPrefixes = prefixStore
+		log.WithField("path", cfg.GroupPrefixDBPath()).Info("Group trigger prefix enabled")
+	}
+
+	// 18. Account Linking (LINE account link + school SSO; tokens encrypted at rest)
+	var accountLinker *account.Linker
+	var accountLIFF *account.LIFFUsers
+	var accountHandler *account.Handler
+	var accountLinks bot.AccountLinkHandler // stays a nil interface when disabled
+	if cfg.IsAccountLinkEnabled() {
+		accountStore, err := account.Open(ctx, cfg.AccountDBPath(), cfg.AccountTokenKey)
+		if err != nil {
+			return nil, fmt.Errorf("account link: %w", err)
+		}
+		sso := account.NewSSO(account.SSOConfig{
+			AuthURL:      cfg.SSOAuthURL,
+			TokenURL:     cfg.SSOTokenURL,
+			UserInfoURL:  cfg.SSOUserInfoURL,
+			ClientID:     cfg.SSOClientID,
+			ClientSecret: cfg.SSOClientSecret,
+			Scopes:       cfg.SSOScopes,
+			RedirectURL:  strings.TrimSuffix(cfg.PublicBaseURL, "/") + "/account/callback",
+		})
+		accountLinker = account.NewLinker(accountStore, sso, lineClient, cfg.PublicBaseURL)
+		accountHandler = account.NewHandler(accountLinker, stickerMgr)
+		accountLinks = accountHandler
+		if cfg.IsLIFFEnabled() {
+			// The LIFF page can start linking; LINE vouches for who opened it
+			accountLIFF = account.NewLIFFUsers(cfg.LIFFID)
+		}
+		// Linked student IDs tell smart search which education level to favor
+		courseHandler.SetStudentIDLookup(accountStore)
+		log.WithField("path", cfg.AccountDBPath()).Info("Account linking enabled")
+	}
 
 	// Create session store for lightweight per-user conversation context (3 intents, 5 min TTL)
 	sessionStore := session.NewStore(3, config.SessionContextTTL)
+	// Remember group answers briefly so a question repeated by another member gets a pointer
+	groupAnswers := session.NewAnswers(config.GroupAnswerDedupWindow)
+
+	// Personal data deletion cascades over every enabled store keyed by user ID
+	eraseTargets := []privacy.Target{{Label: "對話脈絡", Eraser: sessionStore}}
+	if historyStore != nil {
+		eraseTargets = append(eraseTargets, privacy.Target{Label: "查詢紀錄", Eraser: historyStore})
+	}
+	if accountLinker != nil {
+		eraseTargets = append(eraseTargets, privacy.Target{Label: "帳號綁定", Eraser: accountLinker.Store()})
+	}
+	if roleStore != nil {
+		eraseTargets = append(eraseTargets, privacy.Target{Label: "教職員身分", Eraser: roleStore})
+	}
+	if bugReportStore != nil {
+		eraseTargets = append(eraseTargets, privacy.Target{Label: "問題回報", Eraser: bugReportStore})
+	}
+	eraseCascade := privacy.NewCascade(eraseTargets...)
+	privacyHandler := privacy.NewHandler(eraseCascade, stickerMgr)
+
+	// Cross-cutting module concerns, outermost first: recover wraps everything
+	// so a panicking module still gets logged, timed, and answered.
+	middlewares := []bot.Middleware{
+		bot.Recover(log, stickerMgr),
+		bot.Metrics(m),
+		bot.Logging(log),
+	}
+	var moduleLimiter *ratelimit.KeyedLimiter
+	if cfg.Bot.IsModuleRateLimitEnabled() {
+		moduleLimiter = ratelimit.NewKeyedLimiter(ratelimit.KeyedConfig{
+			Name:          "module",
+			Burst:         cfg.Bot.ModuleRateBurst,
+			RefillRate:    cfg.Bot.ModuleRateRefill,
+			CleanupPeriod: config.RateLimiterCleanupInterval,
+			Metrics:       m,
+			MetricType:    ratelimit.MetricTypeNone,
+		})
+		middlewares = append(middlewares, bot.RateLimit(moduleLimiter, stickerMgr))
+	}
+
+	botRegistry := bot.NewRegistry()
+	botRegistry.SetDisabledReply(bot.ModuleDisabledReply(stickerMgr))
+	botRegistry.SetMetrics(m)
+	// timetable goes first: course would otherwise claim "課表 <UID>" through its UID pattern
+	if timetableHandler != nil {
+		botRegistry.RegisterModule(bot.Wrap(timetableHandler, middlewares...), bot.ModuleInfo{
+			DisplayName: "課表圖片", Description: "Weekly timetable image for a list of course UIDs",
+		})
+	}
+	if newsHandler != nil {
+		botRegistry.RegisterModule(bot.Wrap(newsHandler, middlewares...), bot.ModuleInfo{
+			DisplayName: "系上公告", Description: "Latest announcements from department websites",
+			TypedPostbacks: true,
+		})
+	}
+	botRegistry.RegisterModule(bot.Wrap(deptHandler, middlewares...), bot.ModuleInfo{
+		DisplayName: "系所總覽", Description: "One carousel with a department's office contact, course count, and latest cohort",
+	})
+	botRegistry.RegisterModule(bot.Wrap(contactHandler, middlewares...), bot.ModuleInfo{
+		DisplayName: "聯絡資訊", Description: "Campus units, phones, emails, and emergency contacts",
+		LegacyActions: []string{"members", "教師聯繫"},
+	})
+	botRegistry.RegisterModule(bot.Wrap(courseHandler, middlewares...), bot.ModuleInfo{
+		DisplayName: "課程查詢", Description: "Course, teacher, and UID search",
+		LegacyActions: []string{"授課課程"}, TypedPostbacks: true,
+	})
+	botRegistry.RegisterModule(bot.Wrap(idHandler, middlewares...), bot.ModuleInfo{
+		DisplayName: "學號查詢", Description: "Student ID, name, and department lookup",
+		TypedPostbacks: true,
+	})
+	botRegistry.RegisterModule(bot.Wrap(programHandler, middlewares...), bot.ModuleInfo{
+		DisplayName: "學程查詢", Description: "Academic programs and their courses",
+	})
+	if shareHandler != nil {
+		botRegistry.RegisterModule(bot.Wrap(shareHandler, middlewares...), bot.ModuleInfo{
+			DisplayName: "分享連結", Description: "Deep links and QR codes that reopen a query in the bot",
+		})
+	}
+	if historyHandler != nil {
+		botRegistry.RegisterModule(bot.Wrap(historyHandler, middlewares...), bot.ModuleInfo{
+			DisplayName: "查詢紀錄", Description: "Per-user 最近查過 list with replay, clear, and opt-out",
+		})
+	}
+	if leaderboardHandler != nil {
+		botRegistry.RegisterModule(bot.Wrap(leaderboardHandler, middlewares...), bot.ModuleInfo{
+			DisplayName: "群組排行榜", Description: "Opt-in weekly post of a group's most-queried courses",
+		})
+	}
+	if prefixHandler != nil {
+		botRegistry.RegisterModule(bot.Wrap(prefixHandler, middlewares...), bot.ModuleInfo{
+			DisplayName: "觸發前綴", Description: "Per-group prefix required before keyword commands",
+		})
+	}
+	if accountHandler != nil {
+		botRegistry.RegisterModule(bot.Wrap(accountHandler, middlewares...), bot.ModuleInfo{
+			DisplayName: "帳號綁定", Description: "Link, show, and unlink the user's school SSO account",
+		})
+	}
+	if roleHandler != nil {
+		botRegistry.RegisterModule(bot.Wrap(roleHandler, middlewares...), bot.ModuleInfo{
+			DisplayName: "身分驗證", Description: "Staff verification requests and admin grants for staff-only contact fields",
+		})
+	}
+	if bugReportHandler != nil {
+		botRegistry.RegisterModule(bot.Wrap(bugReportHandler, middlewares...), bot.ModuleInfo{
+			DisplayName: "問題回報", Description: "Files the user's last exchange as a bug report and notifies chat admins",
+		})
+	}
+	botRegistry.RegisterModule(bot.Wrap(privacyHandler, middlewares...), bot.ModuleInfo{
+		DisplayName: "刪除資料", Description: "Erase everything the bot stores about the user, after confirmation",
+		TypedPostbacks: true,
+	})
+	// usage reports limiter state and must stay reachable when modules are throttled
+	botRegistry.RegisterModule(bot.Wrap(usageHandler, middlewares[:3]...), bot.ModuleInfo{
+		DisplayName: "配額查詢", Description: "Per-user message and AI quota",
+	})
+	for _, name := range cfg.Bot.DisabledModules {
+		if err := botRegistry.SetEnabled(name, false); err != nil {
+			log.WithError(err).Warn("Unknown module in NTPU_DISABLED_MODULES, ignoring")
+			continue
+		}
+		log.WithModule(name).Info("Module disabled by configuration")
+	}
 
 	processor := bot.NewProcessor(bot.ProcessorConfig{
 		Registry:       botRegistry,
@@ -338,9 +882,35 @@ func Initialize(ctx context.Context, cfg *config.Config) (*Application, error) {
 		Logger:         log,
 		Metrics:        m,
 		SessionStore:   sessionStore,
+		GroupAnswers:   groupAnswers,
 		BotConfig:      &cfg.Bot,
+		AdminUserIDs:   cfg.AdminUserIDs,
+		SelfChecks:     buildSelfChecks(db, scraperClient, bm25Index, intentParser),
+		History:        historyRecorder,
+		GroupQueries:   groupRecorder,
+		Prefixes:       triggerPrefixes,
+		AccountLinks:   accountLinks,
+		Exchanges:      exchangeRecorder,
+		Texts:          texts,
 	})
 
+	var leaderboardPoster *leaderboard.Poster
+	if leaderboardStore != nil {
+		leaderboardPoster = leaderboard.NewPoster(leaderboardStore, lineClient, log, stickerMgr)
+	}
+
+	// 20. Degraded Mode: export the snapshot daily, or serve it with a banner
+	var degradedExporter *degraded.Exporter
+	var banner string
+	switch {
+	case degradedSnap != nil:
+		banner = degraded.Banner(degradedSnap)
+		readinessState.MarkReady() // No refresh runs against the snapshot
+	case cfg.IsDegradedModeEnabled():
+		degradedExporter = degraded.NewExporter(db, cfg.DegradedSnapshotPath(), readinessState.WarmupCompleted, log)
+		log.WithField("path", cfg.DegradedSnapshotPath()).Info("Degraded mode snapshot export enabled")
+	}
+
 	webhookHandler, err := webhook.NewHandler(webhook.HandlerConfig{
 		ChannelSecret:  cfg.LineChannelSecret,
 		ChannelToken:   cfg.LineChannelToken,
@@ -349,10 +919,41 @@ func Initialize(ctx context.Context, cfg *config.Config) (*Application, error) {
 		Logger:         log,
 		Processor:      processor,
 		StickerManager: stickerMgr,
+		LineClient:     lineClient,
+		RecordFile:     cfg.Bot.WebhookRecord,
+		Banner:         banner,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("webhook: %w", err)
 	}
+	// Messages held in memory and recorded events carry user IDs too
+	eraseCascade.Add(privacy.Target{Label: "近期訊息暫存", Eraser: processor})
+	if recorder := webhookHandler.Recorder(); recorder != nil {
+		eraseCascade.Add(privacy.Target{Label: "除錯錄製紀錄", Eraser: recorder})
+	}
+
+	// 7. Analytics Rollups
+	var analyticsStore *analytics.Store
+	var analyticsExporter *analytics.Exporter
+	if cfg.IsAnalyticsEnabled() {
+		analyticsStore, err = analytics.Open(ctx, cfg.AnalyticsDBPath())
+		if err != nil {
+			return nil, fmt.Errorf("analytics: %w", err)
+		}
+		analyticsExporter = analytics.NewExporter(registry, analyticsStore, log)
+		log.WithField("path", cfg.AnalyticsDBPath()).Info("Analytics rollups enabled")
+	}
+
+	// 8. Course Export
+	var exportSigner *export.Signer
+	if cfg.IsExportEnabled() {
+		exportSigner = export.NewSigner(cfg.ExportSecret)
+		log.Info("Course export endpoints enabled")
+		// Calendar apps fetch the feed themselves, so the link needs the public origin
+		if cfg.PublicBaseURL != "" {
+			courseHandler.SetCalendarLinks(export.NewLinks(cfg.PublicBaseURL, exportSigner))
+		}
+	}
 
 	gin.SetMode(gin.ReleaseMode)
 	router := gin.New()
@@ -373,10 +974,12 @@ func Initialize(ctx context.Context, cfg *config.Config) (*Application, error) {
 	app := &Application{
 		cfg:            cfg,
 		logger:         log,
+		logStream:      logStream,
 		db:             db,
 		hotSwapDB:      hotSwapDB,
 		snapshotMgr:    snapshotMgr,
 		snapshotReady:  snapshotReady,
+		replicaLoaded:  replicaLoaded,
 		deltaLog:       deltaLog,
 		scheduleStore:  scheduleStore,
 		metrics:        m,
@@ -384,12 +987,42 @@ func Initialize(ctx context.Context, cfg *config.Config) (*Application, error) {
 		scraperClient:  scraperClient,
 		stickerManager: stickerMgr,
 		webhookHandler: webhookHandler,
+		lineClient:     lineClient,
+		jobRunner:      jobRunner,
+		errorAlerter:   errAlerter,
+		analytics:      analyticsExporter,
+		analyticsStore: analyticsStore,
+		exportSigner:   exportSigner,
+		timetable:      timetableHandler,
+		share:          shareHandler,
+		imageProxy:     imageProxy,
+		assets:         data.Assets,
+		liffCatalog:    liffCatalog,
+		liffPage:       liffPage,
+		historyStore:   historyStore,
+		leaderboard:    leaderboardPoster,
+		leaderboardDB:  leaderboardStore,
+		prefixStore:    prefixStore,
+		accountLinker:  accountLinker,
+		accountLIFF:    accountLIFF,
+		roleStore:      roleStore,
+		bugReports:     bugReportStore,
+		courseBuzz:     buzzEnricher,
+		buzzStore:      buzzStore,
+		backupMgr:      backupMgr,
+		degradedMode:   degradedSnap != nil,
+		degradedExport: degradedExporter,
 		bm25Index:      bm25Index,
 		intentParser:   intentParser,
 		queryExpander:  queryExpander,
+		prompts:        expansionPrompts,
+		outputGuard:    outputGuard,
 		llmLimiter:     llmLimiter,
 		userLimiter:    userLimiter,
+		moduleLimiter:  moduleLimiter,
+		botRegistry:    botRegistry,
 		sessionStore:   sessionStore,
+		groupAnswers:   groupAnswers,
 		semesterCache:  semesterCache,
 		readinessState: readinessState,
 	}
@@ -404,6 +1037,34 @@ func Initialize(ctx context.Context, cfg *config.Config) (*Application, error) {
 		// 5. Metrics Authentication
 		metricsAuthMiddleware(cfg.IsMetricsAuthEnabled(), cfg.MetricsUsername, cfg.MetricsPassword),
 		gin.WrapH(promhttp.HandlerFor(registry, promhttp.HandlerOpts{})))
+	// 6. Admin API
+	if cfg.IsAdminEnabled() {
+		app.registerAdminRoutes(router)
+	}
+	// 8. Course Export
+	if exportSigner != nil {
+		app.registerExportRoutes(router)
+	}
+	// 9. Timetable Images
+	if timetableHandler != nil {
+		app.registerTimetableRoutes(router)
+	}
+	// 10. Share Links (QR images need a public URL for LINE to fetch)
+	if shareHandler != nil && cfg.PublicBaseURL != "" {
+		app.registerShareRoutes(router)
+	}
+	// 11. Course Filter LIFF
+	if liffCatalog != nil {
+		app.registerLIFFRoutes(router)
+	}
+	// 18. Account Linking
+	if accountLinker != nil {
+		app.registerAccountRoutes(router)
+	}
+	// 22. Image Proxy
+	if imageProxy != nil {
+		app.registerImageRoutes(router)
+	}
 
 	app.server = &http.Server{
 		Addr:              ":" + cfg.Port,
@@ -627,6 +1288,7 @@ func (a *Application) getFeatures() map[string]bool {
 		"bm25_search":     a.bm25Index != nil && a.bm25Index.IsEnabled(),
 		"nlu":             a.intentParser != nil && a.intentParser.IsEnabled(),
 		"query_expansion": a.queryExpander != nil,
+		"degraded_mode":   a.degradedMode,
 	}
 }
 
@@ -736,9 +1398,12 @@ func (a *Application) Run() error {
 
 // startBackgroundJobs starts all background goroutines tracked by WaitGroup.
 func (a *Application) startBackgroundJobs(ctx context.Context) {
-	a.wg.Go(func() {
-		a.maintenanceLoop(ctx)
-	})
+	// Refresh and cleanup would scrape into or expire the snapshot being served
+	if !a.degradedMode {
+		a.wg.Go(func() {
+			a.maintenanceLoop(ctx)
+		})
+	}
 	a.wg.Go(func() {
 		a.updateCacheSizeMetrics(ctx)
 	})
@@ -748,9 +1413,53 @@ func (a *Application) startBackgroundJobs(ctx context.Context) {
 	a.wg.Go(func() {
 		a.refreshStickers(ctx)
 	})
+	a.wg.Go(func() {
+		a.lineClient.RunQuotaRefresh(ctx, config.LINEQuotaRefreshInterval)
+	})
+	if a.analytics != nil {
+		a.wg.Go(func() {
+			a.analytics.Run(ctx, config.AnalyticsFlushInterval, config.AnalyticsRetention)
+		})
+	}
+	if a.historyStore != nil {
+		a.wg.Go(func() {
+			a.pruneQueryHistory(ctx)
+		})
+	}
+	if a.bugReports != nil {
+		a.wg.Go(func() {
+			a.pruneBugReports(ctx)
+		})
+	}
+	if a.leaderboard != nil {
+		a.wg.Go(func() {
+			a.leaderboard.Run(ctx, config.LeaderboardCheckInterval)
+		})
+	}
+	if a.courseBuzz != nil {
+		a.wg.Go(func() {
+			a.courseBuzz.Run(ctx)
+		})
+	}
+	if a.backupMgr != nil {
+		a.wg.Go(func() {
+			a.backupMgr.Run(ctx)
+		})
+	}
+	if a.degradedExport != nil {
+		a.wg.Go(func() {
+			a.degradedExport.Run(ctx)
+		})
+	}
+	if a.errorAlerter != nil {
+		a.wg.Go(func() {
+			a.errorAlerter.Run(ctx)
+		})
+	}
 }
 
-// cleanupSessionStore periodically removes expired in-memory session entries.
+// cleanupSessionStore periodically removes expired in-memory session entries
+// and group answers.
 func (a *Application) cleanupSessionStore(ctx context.Context) {
 	if a.sessionStore == nil {
 		return
@@ -765,6 +1474,53 @@ func (a *Application) cleanupSessionStore(ctx context.Context) {
 			return
 		case <-ticker.C:
 			a.sessionStore.Cleanup()
+			a.groupAnswers.Cleanup()
+		}
+	}
+}
+
+// pruneQueryHistory periodically deletes query history older than the retention window.
+func (a *Application) pruneQueryHistory(ctx context.Context) {
+	ticker := time.NewTicker(config.QueryHistoryPruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := a.historyStore.Prune(ctx, time.Now().Add(-config.QueryHistoryRetention))
+			if err != nil {
+				a.logger.WithError(err).Warn("Failed to prune query history")
+				continue
+			}
+			if n > 0 {
+				a.logger.WithField("deleted", n).Debug("Pruned query history")
+			}
+		}
+	}
+}
+
+// pruneBugReports periodically deletes last exchanges and bug reports older
+// than their retention windows.
+func (a *Application) pruneBugReports(ctx context.Context) {
+	ticker := time.NewTicker(config.BugReportPruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			now := time.Now()
+			n, err := a.bugReports.Prune(ctx, now.Add(-config.BugReportExchangeRetention), now.Add(-config.BugReportRetention))
+			if err != nil {
+				a.logger.WithError(err).Warn("Failed to prune bug reports")
+				continue
+			}
+			if n > 0 {
+				a.logger.WithField("deleted", n).Debug("Pruned bug reports")
+			}
 		}
 	}
 }
@@ -806,6 +1562,11 @@ func (a *Application) shutdown() error {
 		a.logger.WithError(err).Warn("Webhook handler shutdown timeout")
 	}
 
+	a.logger.Info("Canceling background jobs")
+	if err := a.jobRunner.Shutdown(shutdownCtx); err != nil {
+		a.logger.WithError(err).Warn("Background job shutdown timeout")
+	}
+
 	a.logger.Info("Closing resources")
 
 	if a.queryExpander != nil {
@@ -837,12 +1598,63 @@ func (a *Application) shutdown() error {
 		}
 	}
 
+	if a.analyticsStore != nil {
+		if err := a.analyticsStore.Close(); err != nil {
+			a.logger.WithError(err).WithField("component", "analytics").Error("Component close error")
+		}
+	}
+
+	if a.historyStore != nil {
+		if err := a.historyStore.Close(); err != nil {
+			a.logger.WithError(err).WithField("component", "query_history").Error("Component close error")
+		}
+	}
+
+	if a.leaderboardDB != nil {
+		if err := a.leaderboardDB.Close(); err != nil {
+			a.logger.WithError(err).WithField("component", "leaderboard").Error("Component close error")
+		}
+	}
+
+	if a.prefixStore != nil {
+		if err := a.prefixStore.Close(); err != nil {
+			a.logger.WithError(err).WithField("component", "group_prefix").Error("Component close error")
+		}
+	}
+
+	if a.accountLinker != nil {
+		if err := a.accountLinker.Store().Close(); err != nil {
+			a.logger.WithError(err).WithField("component", "account_link").Error("Component close error")
+		}
+	}
+
+	if a.roleStore != nil {
+		if err := a.roleStore.Close(); err != nil {
+			a.logger.WithError(err).WithField("component", "staff_roles").Error("Component close error")
+		}
+	}
+
+	if a.bugReports != nil {
+		if err := a.bugReports.Close(); err != nil {
+			a.logger.WithError(err).WithField("component", "bug_reports").Error("Component close error")
+		}
+	}
+
+	if a.buzzStore != nil {
+		if err := a.buzzStore.Close(); err != nil {
+			a.logger.WithError(err).WithField("component", "course_buzz").Error("Component close error")
+		}
+	}
+
 	if a.llmLimiter != nil {
 		a.llmLimiter.Stop()
 	}
 	if a.userLimiter != nil {
 		a.userLimiter.Stop()
 	}
+	if a.moduleLimiter != nil {
+		a.moduleLimiter.Stop()
+	}
 
 	// Flush Sentry events
 	if internalSentry.IsEnabled() {
@@ -891,49 +1703,21 @@ func (a *Application) runDataCleanup(ctx context.Context) (bool, error) {
 		}
 	}
 
+	// Each table expires with its own TTL (config.TTLPolicy)
 	var totalDeleted int64
 	var cleanupErr error
 
-	if deleted, err := a.db.DeleteExpiredContacts(workCtx, a.cfg.CacheTTL); err != nil {
-		a.logger.WithError(err).Error("Failed to cleanup expired contacts")
-		cleanupErr = errors.Join(cleanupErr, err)
-	} else {
-		totalDeleted += deleted
-	}
-
-	if deleted, err := a.db.DeleteExpiredCourses(workCtx, a.cfg.CacheTTL); err != nil {
-		a.logger.WithError(err).Error("Failed to cleanup expired courses")
-		cleanupErr = errors.Join(cleanupErr, err)
-	} else {
-		totalDeleted += deleted
-	}
-
-	if deleted, err := a.db.DeleteExpiredHistoricalCourses(workCtx, a.cfg.CacheTTL); err != nil {
-		a.logger.WithError(err).Error("Failed to cleanup expired historical courses")
-		cleanupErr = errors.Join(cleanupErr, err)
-	} else {
-		totalDeleted += deleted
-	}
-
-	if deleted, err := a.db.DeleteExpiredCoursePrograms(workCtx, a.cfg.CacheTTL); err != nil {
-		a.logger.WithError(err).Error("Failed to cleanup expired course programs")
-		cleanupErr = errors.Join(cleanupErr, err)
-	} else {
-		totalDeleted += deleted
-	}
-
-	if deleted, err := a.db.DeleteExpiredPrograms(workCtx, a.cfg.CacheTTL); err != nil {
-		a.logger.WithError(err).Error("Failed to cleanup expired programs")
-		cleanupErr = errors.Join(cleanupErr, err)
-	} else {
-		totalDeleted += deleted
-	}
-
-	if deleted, err := a.db.DeleteExpiredSyllabi(workCtx, a.cfg.CacheTTL); err != nil {
-		a.logger.WithError(err).Error("Failed to cleanup expired syllabi")
-		cleanupErr = errors.Join(cleanupErr, err)
-	} else {
+	for _, e := range a.expiringTables() {
+		deleted, err := e.deleteExpired(workCtx, a.db.TableTTL(e.table))
+		if err != nil {
+			a.logger.WithError(err).WithField("table", e.table).Error("Failed to cleanup expired rows")
+			cleanupErr = errors.Join(cleanupErr, err)
+			continue
+		}
 		totalDeleted += deleted
+		if a.metrics != nil {
+			a.metrics.RecordCleanupDeleted(e.table, deleted)
+		}
 	}
 
 	// Prune syllabus token cache rows whose content_hash no longer matches the
@@ -943,18 +1727,37 @@ func (a *Application) runDataCleanup(ctx context.Context) (bool, error) {
 		cleanupErr = errors.Join(cleanupErr, err)
 	} else {
 		totalDeleted += deleted
+		if a.metrics != nil {
+			a.metrics.RecordCleanupDeleted("syllabus_tokens", deleted)
+		}
 		if deleted > 0 {
 			a.logger.WithField("deleted", deleted).Debug("Cleaned up stale syllabus tokens")
 		}
 	}
 
-	if _, err := a.db.Writer().ExecContext(workCtx, "VACUUM"); err != nil {
-		a.logger.WithError(err).Warn("Failed to VACUUM database")
+	if err := a.checkCacheAnomalies(workCtx); err != nil {
+		a.logger.WithError(err).Error("Failed to check cache anomalies")
 		cleanupErr = errors.Join(cleanupErr, err)
 	}
-	if _, err := a.db.Writer().ExecContext(workCtx, "PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
-		a.logger.WithError(err).Warn("Failed to checkpoint WAL after VACUUM")
+
+	// Convert syllabi cached before NTPU_SYLLABUS_COMPRESSION changed, so VACUUM reclaims the space
+	if rewritten, err := a.db.SyncSyllabusCompression(workCtx); err != nil {
+		a.logger.WithError(err).Error("Failed to sync syllabus compression")
 		cleanupErr = errors.Join(cleanupErr, err)
+	} else if rewritten > 0 {
+		a.logger.WithField("rewritten", rewritten).
+			WithField("compressed", a.cfg.SyllabusCompression).
+			Info("Rewrote syllabi for compression setting")
+	}
+
+	if stats, err := a.db.Vacuum(workCtx); err != nil {
+		a.logger.WithError(err).Warn("Failed to VACUUM database")
+		cleanupErr = errors.Join(cleanupErr, err)
+	} else {
+		a.logger.WithField("bytes_before", stats.BytesBefore).
+			WithField("bytes_after", stats.BytesAfter).
+			WithField("reclaimed_bytes", stats.BytesBefore-stats.BytesAfter).
+			Info("Database vacuumed")
 	}
 	if _, err := a.db.Writer().ExecContext(workCtx, "PRAGMA optimize"); err != nil {
 		a.logger.WithError(err).Warn("Failed to optimize database")
@@ -1151,6 +1954,7 @@ func (a *Application) runDataRefresh(ctx context.Context, includeID bool) (bool,
 	logEntry := a.logger.WithField("contacts", stats.Contacts.Load()).
 		WithField("courses", stats.Courses.Load()).
 		WithField("syllabi", stats.Syllabi.Load()).
+		WithField("rejected", stats.Rejected.Load()).
 		WithField("duration_ms", time.Since(startTime).Milliseconds()).
 		WithField("initial_refresh", includeID)
 
@@ -1315,8 +2119,8 @@ func (a *Application) maintenanceLoop(ctx context.Context) {
 		}
 
 		state, useLocal := resolveState(ctx)
-		snapshotReady := a.snapshotMgr != nil && a.snapshotReady.Load()
-		if snapshotReady && state.LastRefresh == 0 {
+		warmStart := (a.snapshotMgr != nil && a.snapshotReady.Load()) || a.replicaLoaded
+		if warmStart && state.LastRefresh == 0 {
 			completedAt := time.Now().UTC()
 			if useLocal || !updateRemote(ctx, func(s *maintenance.State) {
 				s.LastRefresh = completedAt.Unix()
@@ -1327,7 +2131,7 @@ func (a *Application) maintenanceLoop(ctx context.Context) {
 			}
 			if !a.readinessState.WarmupCompleted() {
 				a.readinessState.MarkReady()
-				a.logger.Info("Initial refresh skipped due to snapshot, service marked ready")
+				a.logger.Info("Initial refresh skipped due to snapshot or restored replica, service marked ready")
 			}
 			return
 		}
@@ -1389,15 +2193,25 @@ func (a *Application) maintenanceLoop(ctx context.Context) {
 		}
 	}
 
-	// Run once immediately on startup
-	now := time.Now().UTC()
-	runRefreshIfDue(now)
-	runCleanupIfDue(now)
+	// Refresh once immediately on startup; the first cleanup waits a random
+	// jitter (config.MaintenanceCleanupJitter) after it
+	runRefreshIfDue(time.Now().UTC())
+	var startupCleanup <-chan time.Time
+	if cleanupInterval > 0 {
+		delay := startupJitter(config.MaintenanceCleanupJitter)
+		a.logger.WithField("delay", delay.String()).Debug("Startup cleanup scheduled")
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		startupCleanup = timer.C
+	}
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
+		case <-startupCleanup:
+			startupCleanup = nil
+			runCleanupIfDue(time.Now().UTC())
 		case <-tickerChannel(refreshTicker):
 			runRefreshIfDue(time.Now().UTC())
 		case <-tickerChannel(cleanupTicker):
@@ -1406,6 +2220,45 @@ func (a *Application) maintenanceLoop(ctx context.Context) {
 	}
 }
 
+// expiringTable pairs a cached table with its DeleteExpired method.
+type expiringTable struct {
+	table         string
+	deleteExpired func(ctx context.Context, ttl time.Duration) (int64, error)
+}
+
+// expiringTables lists the tables the cleanup task deletes expired rows from.
+// Students and stickers never expire.
+func (a *Application) expiringTables() []expiringTable {
+	return []expiringTable{
+		{storage.TableContacts, a.db.DeleteExpiredContacts},
+		{storage.TableCourses, a.db.DeleteExpiredCourses},
+		{storage.TableHistoricalCourses, a.db.DeleteExpiredHistoricalCourses},
+		{storage.TableCoursePrograms, a.db.DeleteExpiredCoursePrograms},
+		{storage.TableCourseMajors, a.db.DeleteExpiredCourseMajors},
+		{storage.TableTeachers, a.db.DeleteExpiredTeachers},
+		{storage.TableCourseSections, a.db.DeleteExpiredCourseSections},
+		{storage.TableCourseFlags, a.db.DeleteExpiredCourseFlags},
+		{storage.TableCoursePrerequisites, a.db.DeleteExpiredCoursePrerequisites},
+		{storage.TablePrograms, a.db.DeleteExpiredPrograms},
+		{storage.TableSyllabi, a.db.DeleteExpiredSyllabi},
+		{storage.TableLookupMisses, a.db.DeleteExpiredLookupMisses},
+		{storage.TableDepartmentNews, a.db.DeleteExpiredDepartmentNews},
+	}
+}
+
+// startupJitter returns a random delay in [0, maxDelay), so instances
+// restarted together do not all run the first cleanup (and its VACUUM) at once.
+func startupJitter(maxDelay time.Duration) time.Duration {
+	if maxDelay <= 0 {
+		return 0
+	}
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(maxDelay)))
+	if err != nil {
+		return 0
+	}
+	return time.Duration(n.Int64())
+}
+
 func tickerChannel(ticker *time.Ticker) <-chan time.Time {
 	if ticker == nil {
 		return nil
@@ -1424,6 +2277,30 @@ func isMaintenanceDue(lastUnix int64, interval time.Duration, now time.Time) boo
 	return now.Sub(last) >= interval
 }
 
+// checkCacheAnomalies counts anomalous cache rows (see storage.FindAnomalies),
+// deleting the repairable ones when NTPU_INTEGRITY_REPAIR is set, and
+// records the counts as metrics.
+func (a *Application) checkCacheAnomalies(ctx context.Context) error {
+	report, err := a.db.FindAnomalies(ctx, a.cfg.IntegrityRepair)
+	if err != nil {
+		return err
+	}
+
+	logger := a.logger.WithField("repair", a.cfg.IntegrityRepair)
+	for _, check := range storage.AnomalyChecks {
+		if a.metrics != nil {
+			a.metrics.SetIntegrityIssues(check, report[check])
+		}
+		logger = logger.WithField(check, report[check])
+	}
+	if report.Total() > 0 {
+		logger.Warn("Cache integrity issues found")
+	} else {
+		logger.Debug("Cache integrity check passed")
+	}
+	return nil
+}
+
 // updateCacheSizeMetrics periodically records cache size to Prometheus.
 func (a *Application) updateCacheSizeMetrics(ctx context.Context) {
 	a.logger.Debug("Cache metrics job started")
@@ -53,3 +53,16 @@ func TestIsMaintenanceDue(t *testing.T) {
 		})
 	}
 }
+
+func TestStartupJitter(t *testing.T) {
+	t.Parallel()
+
+	if got := startupJitter(0); got != 0 {
+		t.Fatalf("startupJitter(0)=%v, want 0", got)
+	}
+	for range 100 {
+		if got := startupJitter(time.Minute); got < 0 || got >= time.Minute {
+			t.Fatalf("startupJitter(1m)=%v, want [0, 1m)", got)
+		}
+	}
+}
@@ -403,6 +403,10 @@ func TestGetFeatures(t *testing.T) {
 	if queryExpansion := features["query_expansion"]; queryExpansion {
 		t.Errorf("Expected query_expansion=false, got %v", queryExpansion)
 	}
+
+	if degradedMode := features["degraded_mode"]; degradedMode {
+		t.Errorf("Expected degraded_mode=false, got %v", degradedMode)
+	}
 }
 
 func TestReadinessCheckDuringRefresh(t *testing.T) {
@@ -3,6 +3,7 @@ package app
 import (
 	"crypto/subtle"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -37,3 +38,18 @@ func metricsAuthMiddleware(enabled bool, username, password string) gin.HandlerF
 		c.Next()
 	}
 }
+
+// adminAuthMiddleware returns a Gin middleware that enforces a Bearer token for /admin.
+func adminAuthMiddleware(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		got, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		// Constant-time comparison to prevent timing attacks
+		if !ok || token == "" || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			c.Header("WWW-Authenticate", `Bearer realm="admin"`)
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		c.Next()
+	}
+}
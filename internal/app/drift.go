@@ -0,0 +1,87 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/metrics"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+// adminPusher sends push messages (implemented by *lineapi.Client).
+type adminPusher interface {
+	Push(ctx context.Context, req *messaging_api.PushMessageRequest) error
+}
+
+// driftAlerter implements scraper.DriftReporter: every drift is counted and
+// logged, and chat admins (NTPU_ADMIN_USER_IDS) get a push alert at most once
+// per parser per config.ScraperDriftAlertInterval.
+type driftAlerter struct {
+	metrics  *metrics.Metrics
+	pusher   adminPusher
+	logger   *logger.Logger
+	adminIDs []string
+
+	mu       sync.Mutex
+	lastSent map[string]time.Time
+	now      func() time.Time
+}
+
+// newDriftAlerter creates a drift reporter. pusher may be nil (no alerts).
+func newDriftAlerter(m *metrics.Metrics, pusher adminPusher, log *logger.Logger, adminIDs []string) *driftAlerter {
+	return &driftAlerter{
+		metrics:  m,
+		pusher:   pusher,
+		logger:   log,
+		adminIDs: adminIDs,
+		lastSent: make(map[string]time.Time),
+		now:      time.Now,
+	}
+}
+
+// ReportDrift records drift detected by parser and alerts admins if due.
+func (d *driftAlerter) ReportDrift(ctx context.Context, parser, detail string) {
+	if d.metrics != nil {
+		d.metrics.RecordScraperDrift(parser)
+	}
+	log := d.logger.WithField("parser", parser).WithField("detail", detail)
+	log.WarnContext(ctx, "Scraped page no longer matches parser")
+
+	if d.pusher == nil || len(d.adminIDs) == 0 || !d.due(parser) {
+		return
+	}
+
+	// The scrape may belong to a request that is about to end
+	pushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), config.ScraperDriftAlertTimeout)
+	defer cancel()
+
+	msg := lineutil.NewTextMessage(fmt.Sprintf(
+		"⚠️ 網頁結構異常\n\n解析器：%s\n狀況：%s\n\n學校網頁可能已改版，這次爬取的結果未寫入快取。\n可用 cmd/scrape 重現問題。",
+		parser, detail))
+	for _, id := range d.adminIDs {
+		if err := d.pusher.Push(pushCtx, &messaging_api.PushMessageRequest{
+			To:       id,
+			Messages: []messaging_api.MessageInterface{msg},
+		}); err != nil {
+			log.WithError(err).WarnContext(ctx, "Failed to push drift alert")
+		}
+	}
+}
+
+// due reports whether an alert for parser may be sent now, and if so marks it sent.
+func (d *driftAlerter) due(parser string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	if last, ok := d.lastSent[parser]; ok && now.Sub(last) < config.ScraperDriftAlertInterval {
+		return false
+	}
+	d.lastSent[parser] = now
+	return true
+}
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/metrics"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeAdminPusher struct {
+	mu  sync.Mutex
+	tos []string
+}
+
+func (p *fakeAdminPusher) Push(_ context.Context, req *messaging_api.PushMessageRequest) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tos = append(p.tos, req.To)
+	return nil
+}
+
+func TestDriftAlerter_ThrottlesPerParser(t *testing.T) {
+	t.Parallel()
+	pusher := &fakeAdminPusher{}
+	alerter := newDriftAlerter(metrics.New(prometheus.NewRegistry()), pusher, logger.New("error"), []string{"Uadmin1", "Uadmin2"})
+	now := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	alerter.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	alerter.ReportDrift(ctx, "course_list", "no course titles in 3 rows")
+	assert.Equal(t, []string{"Uadmin1", "Uadmin2"}, pusher.tos, "first drift alerts every admin")
+
+	alerter.ReportDrift(ctx, "course_list", "no course titles in 3 rows")
+	assert.Len(t, pusher.tos, 2, "repeat drift within the interval is not pushed")
+
+	alerter.ReportDrift(ctx, "contact_directory", "no department sections")
+	assert.Len(t, pusher.tos, 4, "another parser alerts on its own")
+
+	now = now.Add(config.ScraperDriftAlertInterval)
+	alerter.ReportDrift(ctx, "course_list", "no course titles in 3 rows")
+	assert.Len(t, pusher.tos, 6, "drift alerts again after the interval")
+}
+
+func TestDriftAlerter_NoAdmins(t *testing.T) {
+	t.Parallel()
+	pusher := &fakeAdminPusher{}
+	alerter := newDriftAlerter(nil, pusher, logger.New("error"), nil)
+
+	alerter.ReportDrift(context.Background(), "program_list", "no programs in 8 folders")
+	assert.Empty(t, pusher.tos)
+}
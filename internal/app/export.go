@@ -0,0 +1,135 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/export"
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// exportCacheControl lets calendar apps and proxies reuse a feed between cache refreshes.
+const exportCacheControl = "public, max-age=3600"
+
+// registerExportRoutes mounts the public export endpoints (token-authenticated).
+//
+//	GET /export/departments/:department/courses.csv?token=…  current-semester courses
+//	GET /export/courses/:uid/calendar.ics?token=…            weekly meetings as iCal
+func (a *Application) registerExportRoutes(router gin.IRouter) {
+	group := router.Group("/export")
+	group.GET("/departments/:department/courses.csv", a.exportDepartmentCSV)
+	group.GET("/courses/:uid/calendar.ics", a.exportCourseCalendar)
+}
+
+// exportLinks returns the signed export paths for a department and/or course.
+//
+//	GET /admin/exports?department=資工系&uid=1131U0001
+func (a *Application) exportLinks(c *gin.Context) {
+	department := strings.TrimSpace(c.Query("department"))
+	uid := strings.ToUpper(strings.TrimSpace(c.Query("uid")))
+	if department == "" && uid == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "department or uid is required"})
+		return
+	}
+
+	links := gin.H{}
+	if department != "" {
+		links["department_csv"] = a.exportSigner.DepartmentCSVPath(department)
+	}
+	if uid != "" {
+		links["course_ics"] = a.exportSigner.CourseCalendarPath(uid)
+	}
+	c.JSON(http.StatusOK, links)
+}
+
+func (a *Application) exportDepartmentCSV(c *gin.Context) {
+	department := c.Param("department")
+	if !a.exportSigner.Verify(export.KindDepartmentCSV, department, c.Query("token")) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "invalid token"})
+		return
+	}
+
+	years, terms := a.semesterCache.GetRecentSemesters()
+	if len(years) == 0 {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "no semester data"})
+		return
+	}
+
+	filename := fmt.Sprintf("%d-%d-courses.csv", years[0], terms[0])
+	c.Header("Cache-Control", exportCacheControl)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"; filename*=UTF-8''%s`,
+		filename, url.PathEscape(department+"-"+filename)))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+
+	// Rows stream from the database into the response; the writer buffers
+	// the first few KB, so an early failure can still answer with an error
+	ctx := c.Request.Context()
+	majors, err := a.departmentMajors(ctx, years[0], terms[0], department)
+	var w *export.CourseCSVWriter
+	if err == nil {
+		w, err = export.NewCourseCSVWriter(c.Writer)
+	}
+	if err == nil && len(majors) > 0 {
+		err = a.db.ForEachCourse(ctx, storage.CourseFilter{Year: years[0], Term: terms[0], Majors: majors}, w.Write)
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		a.logger.WithError(err).WithField("department", department).Error("Department CSV export failed")
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Disposition")
+			c.Writer.Header().Del("Cache-Control")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "export failed"})
+		}
+	}
+}
+
+// departmentMajors returns the 應修系級 of a semester that are department plus
+// a grade (資工系1–4). A prefix match would also export 資工系碩1 courses.
+func (a *Application) departmentMajors(ctx context.Context, year, term int, department string) ([]string, error) {
+	all, err := a.db.GetMajorsBySemester(ctx, year, term)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(all, func(m string) bool {
+		return strings.TrimRightFunc(m, unicode.IsDigit) != department
+	}), nil
+}
+
+func (a *Application) exportCourseCalendar(c *gin.Context) {
+	uid := c.Param("uid")
+	if !a.exportSigner.Verify(export.KindCourseCalendar, uid, c.Query("token")) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "invalid token"})
+		return
+	}
+
+	course, err := a.db.GetCourseByUID(c.Request.Context(), uid)
+	if err != nil {
+		a.logger.WithError(err).WithField("uid", uid).Error("Course calendar export failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "export failed"})
+		return
+	}
+	if course == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "course not found"})
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := export.WriteCourseCalendar(&buf, course); err != nil {
+		a.logger.WithError(err).WithField("uid", uid).Error("Course calendar export failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "export failed"})
+		return
+	}
+
+	c.Header("Cache-Control", exportCacheControl)
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s.ics"`, uid))
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", buf.Bytes())
+}
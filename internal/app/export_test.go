@@ -0,0 +1,104 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/bot"
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
+	"github.com/garyellow/ntpu-linebot-go/internal/export"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/course"
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupExportRouter(t *testing.T) (*gin.Engine, *export.Signer) {
+	t.Helper()
+	signer := export.NewSigner("0123456789abcdef")
+	app := &Application{
+		cfg:          &config.Config{AdminEnabled: true, AdminToken: testAdminToken},
+		logger:       logger.New("error"),
+		botRegistry:  bot.NewRegistry(),
+		exportSigner: signer,
+	}
+	router := gin.New()
+	app.registerAdminRoutes(router)
+	app.registerExportRoutes(router)
+	return router, signer
+}
+
+func TestExport_RejectsInvalidToken(t *testing.T) {
+	t.Parallel()
+	router, signer := setupExportRouter(t)
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{"CSV without token", "/export/departments/" + url.PathEscape("資工系") + "/courses.csv"},
+		{"CSV with token for other department", "/export/departments/" + url.PathEscape("資工系") + "/courses.csv?token=" + signer.Token(export.KindDepartmentCSV, "經濟系")},
+		{"Calendar with CSV token", "/export/courses/1131U0001/calendar.ics?token=" + signer.Token(export.KindDepartmentCSV, "1131U0001")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusForbidden, w.Code)
+		})
+	}
+}
+
+func TestAdminExports_Links(t *testing.T) {
+	t.Parallel()
+	router, signer := setupExportRouter(t)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, adminRequest(http.MethodGet, "/admin/exports?department="+url.QueryEscape("資工系")+"&uid=1131u0001", ""))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var links map[string]string
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &links))
+	assert.Equal(t, "/export/departments/"+url.PathEscape("資工系")+"/courses.csv?token="+signer.Token(export.KindDepartmentCSV, "資工系"), links["department_csv"])
+	assert.Equal(t, "/export/courses/1131U0001/calendar.ics?token="+signer.Token(export.KindCourseCalendar, "1131U0001"), links["course_ics"])
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, adminRequest(http.MethodGet, "/admin/exports", ""))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestExportDepartmentCSV_ExcludesGraduateMajors(t *testing.T) {
+	t.Parallel()
+	app := setupTestApp(t)
+	app.exportSigner = export.NewSigner("0123456789abcdef")
+	app.semesterCache = course.NewSemesterCache()
+	app.semesterCache.Update([]course.Semester{{Year: 113, Term: 1}})
+
+	ctx := context.Background()
+	courses := []*storage.Course{
+		{UID: "1131U0001", Year: 113, Term: 1, No: "U0001", Title: "程式設計",
+			RawProgramReqs: []storage.RawProgramReq{{Name: "資工系1", CourseType: "必"}}},
+		{UID: "1131U0002", Year: 113, Term: 1, No: "U0002", Title: "機器學習",
+			RawProgramReqs: []storage.RawProgramReq{{Name: "資工系碩1", CourseType: "選"}}},
+	}
+	require.NoError(t, app.db.SaveCoursesBatch(ctx, courses))
+	require.NoError(t, app.db.SaveCourseMajorsBatch(ctx, courses))
+
+	router := gin.New()
+	app.registerExportRoutes(router)
+	path := "/export/departments/" + url.PathEscape("資工系") + "/courses.csv?token=" + app.exportSigner.Token(export.KindDepartmentCSV, "資工系")
+	req := httptest.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "1131U0001")
+	assert.NotContains(t, w.Body.String(), "1131U0002")
+}
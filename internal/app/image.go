@@ -0,0 +1,49 @@
+package app
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/data"
+	domerrors "github.com/garyellow/ntpu-linebot-go/internal/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// Proxied images are refetched daily; the placeholder is kept briefly so
+// LINE asks again once the upstream is back.
+const (
+	imageCacheControl            = "public, max-age=86400"
+	imagePlaceholderCacheControl = "public, max-age=600"
+)
+
+// registerImageRoutes mounts the public image proxy endpoint. LINE fetches
+// template and quick reply images anonymously, so the route is
+// unauthenticated; only the images of the asset registry (data.Assets) are
+// served, so the route can't fetch arbitrary files on imageproxy.BaseURLs
+// or flush the proxy cache with them.
+//
+//	GET /img?u=https%3A%2F%2Fwalkinto.in%2Fupload%2F...
+func (a *Application) registerImageRoutes(router gin.IRouter) {
+	router.GET("/img", a.proxyImage)
+}
+
+func (a *Application) proxyImage(c *gin.Context) {
+	src := c.Query("u")
+	if !data.Assets.HasURL(src) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "image not proxied"})
+		return
+	}
+
+	img, err := a.imageProxy.Get(c.Request.Context(), src)
+	if errors.Is(err, domerrors.ErrInvalidInput) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "image not proxied"})
+		return
+	}
+
+	if img.Fallback {
+		c.Header("Cache-Control", imagePlaceholderCacheControl)
+	} else {
+		c.Header("Cache-Control", imageCacheControl)
+	}
+	c.Data(http.StatusOK, img.ContentType, img.Data)
+}
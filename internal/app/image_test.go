@@ -0,0 +1,34 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProxyImageRejectsUnregisteredURLs(t *testing.T) {
+	t.Parallel()
+
+	// Rejected before the proxy is asked, so none is set up
+	app := &Application{logger: logger.New("error")}
+	router := gin.New()
+	app.registerImageRoutes(router)
+
+	for _, src := range []string{
+		"", // No image
+		"https://raw.githubusercontent.com/someone/repo/main/big.png", // Proxied host, not an asset
+		"https://walkinto.in/upload/any.jpg",
+		"https://example.com/logo.png",
+	} {
+		req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/img?u="+url.QueryEscape(src), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, "GET /img?u=%s", src)
+	}
+}
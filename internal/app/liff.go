@@ -0,0 +1,78 @@
+package app
+
+import (
+	"errors"
+	"net/http"
+
+	domerrors "github.com/garyellow/ntpu-linebot-go/internal/errors"
+	"github.com/garyellow/ntpu-linebot-go/internal/liff"
+	"github.com/gin-gonic/gin"
+)
+
+// liffAssetCacheControl keeps the page script and styles briefly; they change only on deploy.
+const liffAssetCacheControl = "public, max-age=3600"
+
+// liffOptionsCacheControl lets the page reuse form options briefly;
+// departments only change when warmup refreshes the course cache.
+const liffOptionsCacheControl = "public, max-age=300"
+
+// registerLIFFRoutes mounts the course filter LIFF page and its JSON API.
+// Course data is public, so the API is unauthenticated like the chat bot itself.
+//
+//	GET /liff/courses                 LIFF endpoint URL (HTML page)
+//	GET /liff/static/:file            page script and styles
+//	GET /liff/api/options             semesters, departments, levels
+//	GET /liff/api/courses?semester=…  one page of filtered courses (see liff.ParseFilter)
+func (a *Application) registerLIFFRoutes(router gin.IRouter) {
+	group := router.Group("/liff")
+	group.GET("/courses", a.liffPageHandler)
+	group.GET("/static/:file", a.liffAsset)
+	group.GET("/api/options", a.liffOptions)
+	group.GET("/api/courses", a.liffCourses)
+}
+
+func (a *Application) liffPageHandler(c *gin.Context) {
+	c.Header("Content-Security-Policy", liff.ContentSecurityPolicy)
+	c.Data(http.StatusOK, "text/html; charset=utf-8", a.liffPage)
+}
+
+func (a *Application) liffAsset(c *gin.Context) {
+	data, contentType, ok := liff.Asset(c.Param("file"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	}
+	c.Header("Cache-Control", liffAssetCacheControl)
+	c.Data(http.StatusOK, contentType, data)
+}
+
+func (a *Application) liffOptions(c *gin.Context) {
+	opts, err := a.liffCatalog.Options(c.Request.Context())
+	if err != nil {
+		a.logger.WithError(err).Error("LIFF options failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "options unavailable"})
+		return
+	}
+	c.Header("Cache-Control", liffOptionsCacheControl)
+	c.JSON(http.StatusOK, opts)
+}
+
+func (a *Application) liffCourses(c *gin.Context) {
+	filter, err := liff.ParseFilter(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid filter"})
+		return
+	}
+
+	result, err := a.liffCatalog.Search(c.Request.Context(), filter)
+	switch {
+	case errors.Is(err, domerrors.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown semester or invalid cursor"})
+		return
+	case err != nil:
+		a.logger.WithError(err).Error("LIFF course search failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "search failed"})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/liff"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLIFFRoutes(t *testing.T) {
+	t.Parallel()
+
+	page, err := liff.RenderPage("1234567890-AbcdEfgh", false)
+	require.NoError(t, err)
+	app := &Application{
+		logger:   logger.New("error"),
+		liffPage: page,
+	}
+	router := gin.New()
+	router.Use(securityHeadersMiddleware())
+	app.registerLIFFRoutes(router)
+
+	tests := []struct {
+		name     string
+		path     string
+		want     int
+		wantType string
+	}{
+		{"page", "/liff/courses", http.StatusOK, "text/html"},
+		{"script", "/liff/static/app.js", http.StatusOK, "text/javascript"},
+		{"template is not an asset", "/liff/static/index.html", http.StatusNotFound, ""},
+		{"invalid weekday", "/liff/api/courses?weekday=9", http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.want, w.Code)
+			if tt.wantType != "" {
+				assert.True(t, strings.HasPrefix(w.Header().Get("Content-Type"), tt.wantType), w.Header().Get("Content-Type"))
+			}
+		})
+	}
+
+	// The page must relax the default-src 'none' policy for its own assets and the LIFF SDK
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/liff/courses", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, liff.ContentSecurityPolicy, w.Header().Get("Content-Security-Policy"))
+}
@@ -0,0 +1,123 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"sync"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
+	"github.com/garyellow/ntpu-linebot-go/internal/genai"
+	"github.com/garyellow/ntpu-linebot-go/internal/lineapi"
+	"github.com/garyellow/ntpu-linebot-go/internal/scraper"
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+)
+
+// preflightCheck verifies one dependency at startup. Unlike the 健康檢查
+// self-checks it only reports failures: a misconfigured token or key stops
+// the server before it takes traffic instead of failing on first use.
+type preflightCheck struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// hostResolver resolves host names; net.DefaultResolver in production.
+type hostResolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// buildPreflightChecks returns the startup checks of the dependencies cfg
+// enables. writable is false when the database is the read-only degraded
+// snapshot, which skips the write check.
+func buildPreflightChecks(cfg *config.Config, lineClient *lineapi.Client, db *storage.DB, writable bool, resolver hostResolver) []preflightCheck {
+	checks := []preflightCheck{
+		{
+			name: "LINE channel token",
+			run: func(ctx context.Context) error {
+				_, err := lineClient.BotInfo(ctx)
+				return err
+			},
+		},
+	}
+	if cfg.IsLLMEnabled() && cfg.GeminiAPIKey != "" {
+		llmCfg := buildLLMConfig(cfg)
+		checks = append(checks, preflightCheck{
+			name: "Gemini API key",
+			run:  func(ctx context.Context) error { return genai.CheckGemini(ctx, llmCfg) },
+		})
+	}
+	checks = append(checks, preflightCheck{
+		name: "scraper DNS",
+		run: func(ctx context.Context) error {
+			return resolveScraperHosts(ctx, resolver, directBaseURLs(cfg))
+		},
+	})
+	if writable {
+		checks = append(checks, preflightCheck{name: "database", run: db.CheckWritable})
+	}
+	return checks
+}
+
+// directBaseURLs returns the scraper base URLs fetched without a proxy; a
+// proxy resolves the hosts of the others, so they may not resolve locally.
+func directBaseURLs(cfg *config.Config) map[string][]string {
+	direct := make(map[string][]string, len(cfg.ScraperBaseURLs))
+	for source, urls := range cfg.ScraperBaseURLs {
+		proxy, ok := cfg.ScraperSourceProxies[source]
+		if !ok {
+			proxy = cfg.ScraperProxyURL
+		}
+		if proxy == "" || proxy == scraper.ProxyDirect {
+			direct[source] = urls
+		}
+	}
+	return direct
+}
+
+// resolveScraperHosts checks that each source has a base URL whose host
+// resolves; the scraper fails over between them, so one is enough.
+func resolveScraperHosts(ctx context.Context, resolver hostResolver, baseURLs map[string][]string) error {
+	var errs []error
+	for _, source := range slices.Sorted(maps.Keys(baseURLs)) {
+		var lookupErr error
+		resolved := false
+		for _, raw := range baseURLs[source] {
+			u, err := url.Parse(raw)
+			if err != nil {
+				lookupErr = fmt.Errorf("parse %q: %w", raw, err)
+				continue
+			}
+			if _, err := resolver.LookupHost(ctx, u.Hostname()); err != nil {
+				lookupErr = err
+				continue
+			}
+			resolved = true
+			break
+		}
+		if !resolved && lookupErr != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", source, lookupErr))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runPreflight runs checks concurrently, each within config.PreflightTimeout,
+// and joins the failures in check order so one restart shows every problem.
+func runPreflight(ctx context.Context, checks []preflightCheck) error {
+	errs := make([]error, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Go(func() {
+			checkCtx, cancel := context.WithTimeout(ctx, config.PreflightTimeout)
+			defer cancel()
+			if err := check.run(checkCtx); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", check.name, err)
+			}
+		})
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
@@ -0,0 +1,84 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeResolver resolves the hosts in ok and fails every other host.
+type fakeResolver map[string]bool
+
+func (r fakeResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	if r[host] {
+		return []string{"192.0.2.1"}, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+func TestRunPreflight_JoinsFailuresInOrder(t *testing.T) {
+	t.Parallel()
+	errToken := errors.New("401 invalid token")
+	errDisk := errors.New("read-only file system")
+	checks := []preflightCheck{
+		{name: "LINE channel token", run: func(context.Context) error { return errToken }},
+		{name: "scraper DNS", run: func(context.Context) error { return nil }},
+		{name: "database", run: func(context.Context) error { return errDisk }},
+	}
+
+	err := runPreflight(context.Background(), checks)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, errToken)
+	assert.ErrorIs(t, err, errDisk)
+	assert.Equal(t, "LINE channel token: 401 invalid token\ndatabase: read-only file system", err.Error())
+
+	assert.NoError(t, runPreflight(context.Background(), checks[1:2]))
+}
+
+func TestResolveScraperHosts(t *testing.T) {
+	t.Parallel()
+	resolver := fakeResolver{"lms2.ntpu.edu.tw": true}
+
+	tests := []struct {
+		name     string
+		baseURLs map[string][]string
+		wantErr  string
+	}{
+		{"failover host resolves", map[string][]string{"lms": {"http://lms.ntpu.edu.tw", "http://lms2.ntpu.edu.tw"}}, ""},
+		{"no host resolves", map[string][]string{"sea": {"https://sea.ntpu.edu.tw"}}, "sea: lookup sea.ntpu.edu.tw: no such host"},
+		{"no sources", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := resolveScraperHosts(context.Background(), resolver, tt.baseURLs)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestDirectBaseURLs(t *testing.T) {
+	t.Parallel()
+	cfg := &config.Config{
+		ScraperBaseURLs: map[string][]string{
+			"lms": {"http://lms.ntpu.edu.tw"},
+			"sea": {"https://sea.ntpu.edu.tw"},
+		},
+		ScraperProxyURL:      "http://proxy.example.com:3128",
+		ScraperSourceProxies: map[string]string{"lms": "direct"},
+	}
+	assert.Equal(t, map[string][]string{"lms": {"http://lms.ntpu.edu.tw"}}, directBaseURLs(cfg))
+
+	cfg.ScraperProxyURL = ""
+	cfg.ScraperSourceProxies = nil
+	assert.Len(t, directBaseURLs(cfg), 2)
+}
@@ -0,0 +1,106 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/bot"
+	"github.com/garyellow/ntpu-linebot-go/internal/genai"
+	"github.com/garyellow/ntpu-linebot-go/internal/rag"
+	"github.com/garyellow/ntpu-linebot-go/internal/scraper"
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+)
+
+// selfTestScraperDomains are pinged by the 健康檢查 command (HEAD request per domain).
+var selfTestScraperDomains = []string{"lms", "sea"}
+
+// buildSelfChecks returns the diagnostics run by the admin 健康檢查 chat command.
+// intentParser and bm25Index may be nil when the corresponding feature is disabled.
+func buildSelfChecks(db *storage.DB, scraperClient *scraper.Client, bm25Index *rag.BM25Index, intentParser genai.IntentParser) []bot.SelfCheck {
+	return []bot.SelfCheck{
+		{
+			Name: "資料庫",
+			Run: func(ctx context.Context) (string, error) {
+				if err := db.Ping(ctx); err != nil {
+					return "", err
+				}
+				return "連線正常", nil
+			},
+		},
+		{
+			Name: "快取資料",
+			Run: func(ctx context.Context) (string, error) {
+				counts := []struct {
+					label string
+					count func(context.Context) (int, error)
+				}{
+					{"學生", db.CountStudents},
+					{"聯絡", db.CountContacts},
+					{"課程", db.CountCourses},
+					{"大綱", db.CountSyllabi},
+					{"學程", db.CountPrograms},
+				}
+				parts := make([]string, 0, len(counts))
+				var empty []string
+				for _, c := range counts {
+					n, err := c.count(ctx)
+					if err != nil {
+						return "", fmt.Errorf("count %s: %w", c.label, err)
+					}
+					parts = append(parts, fmt.Sprintf("%s %d", c.label, n))
+					if n == 0 {
+						empty = append(empty, c.label)
+					}
+				}
+				detail := strings.Join(parts, "・")
+				if len(empty) > 0 {
+					return "", fmt.Errorf("%s (empty: %s)", detail, strings.Join(empty, ", "))
+				}
+				return detail, nil
+			},
+		},
+		{
+			Name: "爬蟲連線",
+			Run: func(ctx context.Context) (string, error) {
+				parts := make([]string, 0, len(selfTestScraperDomains))
+				var errs []error
+				for _, domain := range selfTestScraperDomains {
+					url, err := scraperClient.TryFailoverURLs(ctx, domain)
+					if err != nil {
+						errs = append(errs, err)
+						continue
+					}
+					parts = append(parts, fmt.Sprintf("%s → %s", domain, url))
+				}
+				if err := errors.Join(errs...); err != nil {
+					return "", err
+				}
+				return strings.Join(parts, "\n"), nil
+			},
+		},
+		{
+			Name: "BM25 索引",
+			Run: func(context.Context) (string, error) {
+				if !bm25Index.IsEnabled() {
+					return "", errors.New("index not loaded")
+				}
+				return fmt.Sprintf("%d 份文件", bm25Index.Count()), nil
+			},
+		},
+		{
+			Name: "LLM",
+			Run: func(ctx context.Context) (string, error) {
+				if intentParser == nil || !intentParser.IsEnabled() {
+					return "未啟用", nil
+				}
+				// One real parse call; admins run this rarely, so the token cost is negligible
+				if _, err := intentParser.Parse(ctx, "使用說明"); err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("%s (%s)", intentParser.Model(), intentParser.Provider()), nil
+			},
+		},
+	}
+}
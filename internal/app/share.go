@@ -0,0 +1,38 @@
+package app
+
+import (
+	"errors"
+	"net/http"
+
+	domerrors "github.com/garyellow/ntpu-linebot-go/internal/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// shareCacheControl lets LINE's image proxy and printers keep a QR code;
+// the image is a pure function of the query.
+const shareCacheControl = "public, max-age=86400"
+
+// registerShareRoutes mounts the public share QR code endpoint.
+// LINE fetches image messages anonymously, so the route is unauthenticated;
+// queries are length-capped by share.ValidateQuery.
+//
+//	GET /share/qr.png?q=課程%201131U0001
+func (a *Application) registerShareRoutes(router gin.IRouter) {
+	router.GET("/share/qr.png", a.shareQRCode)
+}
+
+func (a *Application) shareQRCode(c *gin.Context) {
+	img, err := a.share.QRCode(c.Query("q"))
+	switch {
+	case errors.Is(err, domerrors.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query"})
+		return
+	case err != nil:
+		a.logger.WithError(err).Error("Share QR code failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "render failed"})
+		return
+	}
+
+	c.Header("Cache-Control", shareCacheControl)
+	c.Data(http.StatusOK, "image/png", img)
+}
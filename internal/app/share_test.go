@@ -0,0 +1,47 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/share"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShareQRCode(t *testing.T) {
+	t.Parallel()
+
+	log := logger.New("error")
+	app := &Application{
+		logger: log,
+		share:  share.NewHandler(share.NewLinker("@123abcde"), "https://bot.example.com", nil),
+	}
+	router := gin.New()
+	app.registerShareRoutes(router)
+
+	tests := []struct {
+		name string
+		path string
+		want int
+	}{
+		{"valid query", "/share/qr.png?q=%E8%AA%B2%E7%A8%8B%201131U0001", http.StatusOK},
+		{"missing query", "/share/qr.png", http.StatusBadRequest},
+		{"control character", "/share/qr.png?q=a%0Ab", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.want, w.Code)
+			if tt.want == http.StatusOK {
+				assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+				assert.Equal(t, shareCacheControl, w.Header().Get("Cache-Control"))
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package app
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	domerrors "github.com/garyellow/ntpu-linebot-go/internal/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// timetableCacheControl lets LINE's image proxy and clients keep a rendered
+// timetable; the content hash in the path changes whenever the courses do.
+const timetableCacheControl = "public, max-age=86400"
+
+// registerTimetableRoutes mounts the public timetable image endpoint.
+// LINE fetches image messages anonymously, so the route is unauthenticated;
+// it only renders courses already in the cache and caps the UID count.
+//
+//	GET /timetable/:key.png?uids=1131U0001,1131U0002
+func (a *Application) registerTimetableRoutes(router gin.IRouter) {
+	router.GET("/timetable/:file", a.timetableImage)
+}
+
+func (a *Application) timetableImage(c *gin.Context) {
+	key, ok := strings.CutSuffix(c.Param("file"), ".png")
+	if !ok || key == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	}
+
+	var uids []string
+	if raw := c.Query("uids"); raw != "" {
+		uids = strings.Split(raw, ",")
+	}
+
+	img, err := a.timetable.ImagePNG(c.Request.Context(), key, uids)
+	switch {
+	case errors.Is(err, domerrors.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid course list"})
+		return
+	case errors.Is(err, domerrors.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	case err != nil:
+		a.logger.WithError(err).WithField("key", key).Error("Timetable image failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "render failed"})
+		return
+	}
+
+	c.Header("Cache-Control", timetableCacheControl)
+	c.Data(http.StatusOK, "image/png", img)
+}
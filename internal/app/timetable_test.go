@@ -0,0 +1,47 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/timetable"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func TestTimetableImage_RejectsBadRequests(t *testing.T) {
+	t.Parallel()
+
+	font, err := timetable.ParseFont(goregular.TTF)
+	require.NoError(t, err)
+	log := logger.New("error")
+	app := &Application{
+		logger:    log,
+		timetable: timetable.NewHandler(nil, timetable.NewRenderer(font), "https://bot.example.com", nil),
+	}
+	router := gin.New()
+	app.registerTimetableRoutes(router)
+
+	tests := []struct {
+		name string
+		path string
+		want int
+	}{
+		{"missing png suffix", "/timetable/abc?uids=1131U0001", http.StatusNotFound},
+		{"no course list", "/timetable/abc.png", http.StatusBadRequest},
+		{"malformed UID", "/timetable/abc.png?uids=1131U0001,DROP", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
@@ -0,0 +1,239 @@
+// Package backup keeps rotated, zstd-compressed copies of the SQLite cache in
+// a local directory or an S3-compatible bucket, so a lost cache can be
+// restored (cmd/dbtool restore) instead of rebuilt by hours of scraping.
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/s3client"
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+)
+
+// ErrNotFound is returned when a backup (or any backup) does not exist.
+var ErrNotFound = errors.New("backup not found")
+
+// Backup names are "cache-<UTC time>.db.zst"; the fixed-width time makes
+// lexical order chronological.
+const (
+	namePrefix = "cache-"
+	nameSuffix = ".db.zst"
+	timeLayout = "20060102T150405Z"
+)
+
+// backupName returns the name of a backup taken at t.
+func backupName(t time.Time) string {
+	return namePrefix + t.UTC().Format(timeLayout) + nameSuffix
+}
+
+// isBackupName reports whether name was produced by backupName.
+func isBackupName(name string) bool {
+	ts, ok := strings.CutPrefix(name, namePrefix)
+	if !ok {
+		return false
+	}
+	ts, ok = strings.CutSuffix(ts, nameSuffix)
+	if !ok {
+		return false
+	}
+	_, err := time.Parse(timeLayout, ts)
+	return err == nil
+}
+
+// List returns the backup names in store, newest first.
+func List(ctx context.Context, store Store) ([]string, error) {
+	names, err := store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(names)
+	slices.Reverse(names)
+	return names, nil
+}
+
+// Config holds backup manager configuration.
+type Config struct {
+	Interval  time.Duration // How often Run takes a backup
+	Retention int           // Newest backups kept; older ones are deleted after each backup
+	TempDir   string        // Directory for the uncompressed copy (same filesystem as the cache is best)
+}
+
+// Manager takes scheduled backups of a database.
+type Manager struct {
+	db     *storage.DB
+	store  Store
+	config Config
+	logger *logger.Logger
+	now    func() time.Time
+}
+
+// New creates a backup manager.
+func New(db *storage.DB, store Store, cfg Config, log *logger.Logger) *Manager {
+	if cfg.TempDir == "" {
+		cfg.TempDir = os.TempDir()
+	}
+	return &Manager{
+		db:     db,
+		store:  store,
+		config: cfg,
+		logger: log,
+		now:    time.Now,
+	}
+}
+
+// Run takes a backup every config.Interval until ctx is done.
+// Failures are logged; the next tick tries again.
+func (m *Manager) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			backupCtx, cancel := context.WithTimeout(ctx, config.BackupTimeout)
+			name, err := m.Backup(backupCtx)
+			cancel()
+			if err != nil {
+				m.logger.WithError(err).Error("Cache backup failed")
+				continue
+			}
+			m.logger.WithField("name", name).Info("Cache backup completed")
+		}
+	}
+}
+
+// Backup stores a consistent, compressed copy of the database and deletes
+// backups beyond the retention count. Returns the new backup's name.
+func (m *Manager) Backup(ctx context.Context) (string, error) {
+	if err := os.MkdirAll(m.config.TempDir, 0o750); err != nil {
+		return "", fmt.Errorf("backup: create temp dir: %w", err)
+	}
+
+	name := backupName(m.now())
+	copyPath := filepath.Join(m.config.TempDir, fmt.Sprintf("backup_%d.db", m.now().UnixNano()))
+	if err := m.db.CreateSnapshot(ctx, copyPath); err != nil {
+		return "", fmt.Errorf("backup: copy database: %w", err)
+	}
+	defer func() { _ = os.Remove(copyPath) }()
+
+	compressedPath := copyPath + ".zst"
+	if err := s3client.CompressFile(copyPath, compressedPath); err != nil {
+		return "", fmt.Errorf("backup: %w", err)
+	}
+	defer func() { _ = os.Remove(compressedPath) }()
+
+	f, err := os.Open(compressedPath) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		return "", fmt.Errorf("backup: open compressed copy: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if err := m.store.Put(ctx, name, f); err != nil {
+		return "", err
+	}
+
+	if err := m.prune(ctx); err != nil {
+		// The new backup is stored; old ones are retried next time
+		m.logger.WithError(err).Warn("Failed to prune old cache backups")
+	}
+	return name, nil
+}
+
+// prune deletes all but the newest config.Retention backups.
+func (m *Manager) prune(ctx context.Context) error {
+	names, err := List(ctx, m.store)
+	if err != nil {
+		return err
+	}
+	if len(names) <= m.config.Retention {
+		return nil
+	}
+	var errs []error
+	for _, name := range names[m.config.Retention:] {
+		if err := m.store.Delete(ctx, name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Restore replaces the database at dbPath with a backup. An empty name
+// restores the newest backup. The backup is decompressed next to dbPath and
+// passes an integrity check before it replaces the file; stale -wal and -shm
+// files are removed. The server must not be running. Returns the restored
+// backup's name.
+func Restore(ctx context.Context, store Store, name, dbPath string) (string, error) {
+	if name == "" {
+		names, err := List(ctx, store)
+		if err != nil {
+			return "", err
+		}
+		if len(names) == 0 {
+			return "", ErrNotFound
+		}
+		name = names[0]
+	}
+
+	body, err := store.Get(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = body.Close() }()
+
+	dir := filepath.Dir(dbPath)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", fmt.Errorf("restore: create dir: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, "restore_*.db")
+	if err != nil {
+		return "", fmt.Errorf("restore: create temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	_ = tmp.Close()
+	defer func() {
+		_ = os.Remove(tmpPath)
+		_ = os.Remove(tmpPath + "-wal")
+		_ = os.Remove(tmpPath + "-shm")
+	}()
+
+	if err := s3client.DecompressStream(body, tmpPath); err != nil {
+		return "", fmt.Errorf("restore %s: %w", name, err)
+	}
+	if err := checkDatabase(ctx, tmpPath); err != nil {
+		return "", fmt.Errorf("restore %s: %w", name, err)
+	}
+
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(dbPath + suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("restore: remove %s: %w", suffix, err)
+		}
+	}
+	if err := os.Rename(tmpPath, dbPath); err != nil { //nolint:gosec // G703: dbPath is an operator-supplied flag
+		return "", fmt.Errorf("restore: replace database: %w", err)
+	}
+	return name, nil
+}
+
+// checkDatabase opens the file at path and runs a quick integrity check.
+func checkDatabase(ctx context.Context, path string) error {
+	db, err := storage.New(ctx, path, time.Hour) // TTL is unused by the check
+	if err != nil {
+		return fmt.Errorf("open backup: %w", err)
+	}
+	checkErr := db.CheckIntegrity(ctx)
+	if err := db.Close(ctx); err != nil && checkErr == nil {
+		return fmt.Errorf("close backup: %w", err)
+	}
+	return checkErr
+}
@@ -0,0 +1,214 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/s3client"
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+)
+
+func TestIsBackupName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{backupName(time.Date(2025, 3, 1, 4, 0, 0, 0, time.UTC)), true},
+		{"cache-20250301T040000Z.db.zst", true},
+		{"cache-2025-03-01.db.zst", false},
+		{"cache-20250301T040000Z.db", false},
+		{".tmp_123", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := isBackupName(tt.name); got != tt.want {
+				t.Errorf("isBackupName(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDirStore(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := NewDirStore(filepath.Join(t.TempDir(), "backups"))
+
+	// A missing directory has no backups
+	if names, err := store.List(ctx); err != nil || len(names) != 0 {
+		t.Fatalf("List() = %v, %v; want empty", names, err)
+	}
+
+	older, newer := "cache-20250301T040000Z.db.zst", "cache-20250302T040000Z.db.zst"
+	for _, name := range []string{newer, older} {
+		if err := store.Put(ctx, name, strings.NewReader(name)); err != nil {
+			t.Fatalf("Put(%s) error = %v", name, err)
+		}
+	}
+
+	names, err := List(ctx, store)
+	if err != nil || !slices.Equal(names, []string{newer, older}) {
+		t.Fatalf("List() = %v, %v; want newest first", names, err)
+	}
+
+	body, err := store.Get(ctx, older)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	data, _ := io.ReadAll(body)
+	_ = body.Close()
+	if string(data) != older {
+		t.Errorf("Get() = %q, want %q", data, older)
+	}
+
+	if err := store.Delete(ctx, older); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := store.Get(ctx, older); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() after Delete error = %v, want ErrNotFound", err)
+	}
+}
+
+// fakeObjectClient is an in-memory objectClient.
+type fakeObjectClient struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeObjectClient) Upload(_ context.Context, key string, body io.Reader, _ string) (string, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.objects[key] = data
+	return "etag", nil
+}
+
+func (f *fakeObjectClient) Download(_ context.Context, key string) (io.ReadCloser, string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, ok := f.objects[key]
+	if !ok {
+		return nil, "", s3client.ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), "etag", nil
+}
+
+func (f *fakeObjectClient) ListObjects(_ context.Context, prefix string) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var keys []string
+	for key := range f.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (f *fakeObjectClient) DeleteObject(_ context.Context, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.objects, key)
+	return nil
+}
+
+func TestS3Store(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	client := &fakeObjectClient{objects: map[string][]byte{
+		"backups/notes.txt":          []byte("ignored"),
+		"snapshots/cache.db.zst":     []byte("ignored"),
+		"backups/cache-x.db.zst.bak": []byte("ignored"),
+	}}
+	store := &S3Store{client: client, prefix: "backups"}
+
+	name := "cache-20250301T040000Z.db.zst"
+	if err := store.Put(ctx, name, strings.NewReader("data")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if _, ok := client.objects["backups/"+name]; !ok {
+		t.Errorf("Put() did not store under the prefix: %v", client.objects)
+	}
+
+	names, err := store.List(ctx)
+	if err != nil || !slices.Equal(names, []string{name}) {
+		t.Errorf("List() = %v, %v; want [%s]", names, err, name)
+	}
+
+	if _, err := store.Get(ctx, "cache-20240101T000000Z.db.zst"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() missing error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestBackupAndRestore(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	db, err := storage.New(ctx, filepath.Join(dir, "cache.db"), time.Hour)
+	if err != nil {
+		t.Fatalf("storage.New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close(ctx) })
+	if err := db.SaveContact(ctx, &storage.Contact{UID: "c1", Type: "individual", Name: "王小明"}); err != nil {
+		t.Fatalf("SaveContact() error = %v", err)
+	}
+
+	store := NewDirStore(filepath.Join(dir, "backups"))
+	m := New(db, store, Config{Interval: time.Hour, Retention: 2, TempDir: dir}, logger.New("error"))
+
+	// Three backups a day apart leave the newest two
+	start := time.Date(2025, 3, 1, 4, 0, 0, 0, time.UTC)
+	for i := range 3 {
+		m.now = func() time.Time { return start.AddDate(0, 0, i) }
+		if _, err := m.Backup(ctx); err != nil {
+			t.Fatalf("Backup() #%d error = %v", i, err)
+		}
+	}
+	names, err := List(ctx, store)
+	want := []string{backupName(start.AddDate(0, 0, 2)), backupName(start.AddDate(0, 0, 1))}
+	if err != nil || !slices.Equal(names, want) {
+		t.Fatalf("List() = %v, %v; want %v", names, err, want)
+	}
+
+	restorePath := filepath.Join(dir, "restored", "cache.db")
+	restored, err := Restore(ctx, store, "", restorePath)
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if restored != want[0] {
+		t.Errorf("Restore() restored %s, want newest %s", restored, want[0])
+	}
+
+	restoredDB, err := storage.New(ctx, restorePath, time.Hour)
+	if err != nil {
+		t.Fatalf("open restored db: %v", err)
+	}
+	defer func() { _ = restoredDB.Close(ctx) }()
+	if got, err := restoredDB.CountContacts(ctx); err != nil || got != 1 {
+		t.Errorf("restored CountContacts() = %d, %v; want 1", got, err)
+	}
+}
+
+func TestRestore_NoBackups(t *testing.T) {
+	t.Parallel()
+
+	store := NewDirStore(t.TempDir())
+	if _, err := Restore(context.Background(), store, "", filepath.Join(t.TempDir(), "cache.db")); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Restore() error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,162 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/s3client"
+)
+
+// Store holds backup files by name (see backupName).
+type Store interface {
+	Put(ctx context.Context, name string, body io.Reader) error
+	Get(ctx context.Context, name string) (io.ReadCloser, error)
+	// List returns the names of all backups, in any order.
+	List(ctx context.Context) ([]string, error)
+	Delete(ctx context.Context, name string) error
+}
+
+// DirStore keeps backups in a local directory.
+type DirStore struct {
+	dir string
+}
+
+// NewDirStore creates a store in dir, which is created on first Put.
+func NewDirStore(dir string) *DirStore {
+	return &DirStore{dir: dir}
+}
+
+// Put writes body to a temporary file and renames it into place, so a
+// half-written backup is never listed.
+func (s *DirStore) Put(_ context.Context, name string, body io.Reader) error {
+	if err := os.MkdirAll(s.dir, 0o750); err != nil {
+		return fmt.Errorf("backup: create dir: %w", err)
+	}
+	tmp, err := os.CreateTemp(s.dir, ".tmp_*")
+	if err != nil {
+		return fmt.Errorf("backup: create temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("backup: write %s: %w", name, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("backup: close %s: %w", name, err)
+	}
+	if err := os.Rename(tmpPath, filepath.Join(s.dir, name)); err != nil { //nolint:gosec // G703: name comes from backupName
+		return fmt.Errorf("backup: rename %s: %w", name, err)
+	}
+	return nil
+}
+
+// Get opens a backup. Returns ErrNotFound if it does not exist.
+func (s *DirStore) Get(_ context.Context, name string) (io.ReadCloser, error) {
+	f, err := os.Open(filepath.Join(s.dir, filepath.Base(name)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("backup: open %s: %w", name, err)
+	}
+	return f, nil
+}
+
+// List returns the backup names in the directory. A missing directory has none.
+func (s *DirStore) List(_ context.Context) ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("backup: list dir: %w", err)
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && isBackupName(e.Name()) {
+			names = append(names, e.Name())
+		}
+	}
+	return names, nil
+}
+
+// Delete removes a backup.
+func (s *DirStore) Delete(_ context.Context, name string) error {
+	if err := os.Remove(filepath.Join(s.dir, filepath.Base(name))); err != nil {
+		return fmt.Errorf("backup: delete %s: %w", name, err)
+	}
+	return nil
+}
+
+// objectClient is the subset of *s3client.Client used by S3Store.
+type objectClient interface {
+	Upload(ctx context.Context, key string, body io.Reader, contentType string) (string, error)
+	Download(ctx context.Context, key string) (io.ReadCloser, string, error)
+	ListObjects(ctx context.Context, prefix string) ([]string, error)
+	DeleteObject(ctx context.Context, key string) error
+}
+
+// S3Store keeps backups under a key prefix in an S3-compatible bucket.
+type S3Store struct {
+	client objectClient
+	prefix string
+}
+
+// NewS3Store creates a store for keys under prefix (e.g., "backups").
+func NewS3Store(client *s3client.Client, prefix string) *S3Store {
+	return &S3Store{client: client, prefix: strings.Trim(prefix, "/")}
+}
+
+func (s *S3Store) key(name string) string {
+	return s.prefix + "/" + name
+}
+
+// Put uploads a backup.
+func (s *S3Store) Put(ctx context.Context, name string, body io.Reader) error {
+	if _, err := s.client.Upload(ctx, s.key(name), body, "application/zstd"); err != nil {
+		return fmt.Errorf("backup: upload %s: %w", name, err)
+	}
+	return nil
+}
+
+// Get downloads a backup. Returns ErrNotFound if it does not exist.
+func (s *S3Store) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	body, _, err := s.client.Download(ctx, s.key(name))
+	if errors.Is(err, s3client.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("backup: download %s: %w", name, err)
+	}
+	return body, nil
+}
+
+// List returns the backup names under the prefix.
+func (s *S3Store) List(ctx context.Context) ([]string, error) {
+	keys, err := s.client.ListObjects(ctx, s.prefix+"/")
+	if err != nil {
+		return nil, fmt.Errorf("backup: list: %w", err)
+	}
+	var names []string
+	for _, key := range keys {
+		if name := strings.TrimPrefix(key, s.prefix+"/"); isBackupName(name) {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// Delete removes a backup.
+func (s *S3Store) Delete(ctx context.Context, name string) error {
+	if err := s.client.DeleteObject(ctx, s.key(name)); err != nil {
+		return fmt.Errorf("backup: delete %s: %w", name, err)
+	}
+	return nil
+}
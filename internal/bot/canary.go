@@ -0,0 +1,201 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync/atomic"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/ctxutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/metrics"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+// ErrNoCanary is returned when setting the canary share of a module that has
+// no canary implementation.
+var ErrNoCanary = errors.New("module has no canary")
+
+// Canary arms reported by metrics.
+const (
+	ArmStable = "stable"
+	ArmCanary = "canary"
+)
+
+// Canary routes a share of a module's traffic to a second implementation,
+// e.g., a search rewrite, so it can be rolled out gradually.
+//
+// Routing is sticky per chat: a chat is either in the canary share or not, so
+// follow-up postbacks reach the implementation that rendered the buttons.
+// Calls without a chat ID always go to stable. For every canary call, stable
+// also runs on the same input and the two replies are compared
+// (ntpu_canary_diff_total); the canary reply is served. Both implementations
+// must therefore tolerate running side by side (reads and idempotent cache
+// writes are fine).
+type Canary struct {
+	stable  Handler
+	canary  Handler
+	percent atomic.Int32
+	metrics *metrics.Metrics
+}
+
+// NewCanary creates a Canary sending percent (0-100) of chats to canary.
+// Name and CanHandle come from stable. The result implements NLUHandler if
+// both implementations do. Wrap the result, not the implementations, with
+// middleware so each call is timed and logged once.
+func NewCanary(stable, canary Handler, percent int, m *metrics.Metrics) Handler {
+	c := &Canary{stable: stable, canary: canary, metrics: m}
+	c.SetPercent(percent)
+	stableNLU, stableOK := stable.(NLUHandler)
+	canaryNLU, canaryOK := canary.(NLUHandler)
+	if stableOK && canaryOK {
+		return &canaryNLUHandler{Canary: c, stableNLU: stableNLU, canaryNLU: canaryNLU}
+	}
+	return c
+}
+
+// Name returns the stable module name.
+func (c *Canary) Name() string {
+	return c.stable.Name()
+}
+
+// CanHandle uses stable's matching so both arms see the same traffic.
+func (c *Canary) CanHandle(text string) bool {
+	return c.stable.CanHandle(text)
+}
+
+// SetPercent sets the share of chats routed to the canary, clamped to 0-100.
+func (c *Canary) SetPercent(percent int) {
+	c.percent.Store(int32(min(max(percent, 0), 100))) //nolint:gosec // Clamped to 0-100
+}
+
+// Percent returns the share of chats routed to the canary.
+func (c *Canary) Percent() int {
+	return int(c.percent.Load())
+}
+
+// useCanary reports whether the chat in ctx falls in the canary share.
+func (c *Canary) useCanary(ctx context.Context) bool {
+	chatID := ctxutil.GetChatID(ctx)
+	percent := c.percent.Load()
+	if chatID == "" || percent == 0 {
+		return false
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(c.Name() + ":" + chatID))
+	return int32(h.Sum32()%100) < percent //nolint:gosec // Below 100
+}
+
+// HandleMessage serves the message from the arm of the chat.
+func (c *Canary) HandleMessage(ctx context.Context, text string) []messaging_api.MessageInterface {
+	msgs, _ := c.route(ctx, KindMessage, func(h Handler) ([]messaging_api.MessageInterface, error) {
+		return h.HandleMessage(ctx, text), nil
+	})
+	return msgs
+}
+
+// HandlePostback serves the postback from the arm of the chat.
+func (c *Canary) HandlePostback(ctx context.Context, data string) []messaging_api.MessageInterface {
+	msgs, _ := c.route(ctx, KindPostback, func(h Handler) ([]messaging_api.MessageInterface, error) {
+		return h.HandlePostback(ctx, data), nil
+	})
+	return msgs
+}
+
+// route runs call on the arm of the chat and, for canary calls, compares
+// the reply with stable's.
+func (c *Canary) route(ctx context.Context, kind string, call func(Handler) ([]messaging_api.MessageInterface, error)) ([]messaging_api.MessageInterface, error) {
+	if !c.useCanary(ctx) {
+		c.metrics.RecordCanaryCall(c.Name(), ArmStable)
+		return call(c.stable)
+	}
+	c.metrics.RecordCanaryCall(c.Name(), ArmCanary)
+
+	type result struct {
+		msgs []messaging_api.MessageInterface
+		err  error
+	}
+	stableDone := make(chan result, 1)
+	go func() {
+		// A stable panic must not take down the canary reply
+		defer func() {
+			if r := recover(); r != nil {
+				stableDone <- result{err: fmt.Errorf("%w: %v", ErrHandlerPanic, r)}
+			}
+		}()
+		msgs, err := call(c.stable)
+		stableDone <- result{msgs: msgs, err: err}
+	}()
+
+	msgs, err := call(c.canary)
+	stable := <-stableDone
+	diff := compareReplies(msgs, err, stable.msgs, stable.err)
+	c.metrics.RecordCanaryDiff(c.Name(), diff)
+	if diff != "same" {
+		logger.FromContext(ctx).
+			WithField("kind", kind).
+			WithField("diff", diff).
+			WithField("canary_messages", len(msgs)).
+			WithField("stable_messages", len(stable.msgs)).
+			InfoContext(ctx, "Canary reply differs from stable")
+	}
+	return msgs, err
+}
+
+// compareReplies classifies a canary reply against the stable reply:
+// same, different, canary_empty, stable_empty, canary_error or stable_error.
+// Replies are compared by their JSON encoding.
+func compareReplies(canary []messaging_api.MessageInterface, canaryErr error, stable []messaging_api.MessageInterface, stableErr error) string {
+	switch {
+	case canaryErr != nil && stableErr == nil:
+		return "canary_error"
+	case stableErr != nil && canaryErr == nil:
+		return "stable_error"
+	case len(canary) == 0 && len(stable) > 0:
+		return "canary_empty"
+	case len(stable) == 0 && len(canary) > 0:
+		return "stable_empty"
+	}
+	canaryJSON, err1 := json.Marshal(canary)
+	stableJSON, err2 := json.Marshal(stable)
+	if err1 != nil || err2 != nil || !bytes.Equal(canaryJSON, stableJSON) {
+		return "different"
+	}
+	return "same"
+}
+
+// canaryNLUHandler adds DispatchIntent to Canary.
+type canaryNLUHandler struct {
+	*Canary
+	stableNLU NLUHandler
+	canaryNLU NLUHandler
+}
+
+func (c *canaryNLUHandler) DispatchIntent(ctx context.Context, intent string, params map[string]string) ([]messaging_api.MessageInterface, error) {
+	return c.route(ctx, KindIntent, func(h Handler) ([]messaging_api.MessageInterface, error) {
+		if h == c.stable {
+			return c.stableNLU.DispatchIntent(ctx, intent, params)
+		}
+		return c.canaryNLU.DispatchIntent(ctx, intent, params)
+	})
+}
+
+// canaryOf returns the Canary inside h (unwrapping middleware), or nil.
+func canaryOf(h Handler) *Canary {
+	for h != nil {
+		switch v := h.(type) {
+		case *Canary:
+			return v
+		case *canaryNLUHandler:
+			return v.Canary
+		case interface{ Unwrap() Handler }:
+			h = v.Unwrap()
+		default:
+			return nil
+		}
+	}
+	return nil
+}
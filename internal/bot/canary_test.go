@@ -0,0 +1,150 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/ctxutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/metrics"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// prefixHandler replies with its prefix and the input text.
+type prefixHandler struct {
+	stubNLUHandler
+	prefix string
+}
+
+func (p *prefixHandler) HandleMessage(_ context.Context, text string) []messaging_api.MessageInterface {
+	if p.panic {
+		panic("boom")
+	}
+	return []messaging_api.MessageInterface{&messaging_api.TextMessage{Text: p.prefix + text}}
+}
+
+// canaryCount returns the course series of a canary counter with the given
+// arm or result.
+func canaryCount(t *testing.T, m *metrics.Metrics, name, value string) float64 {
+	t.Helper()
+	counters, err := m.Counters()
+	if err != nil {
+		t.Fatalf("Counters() error = %v", err)
+	}
+	for _, sample := range counters[name] {
+		if sample.Labels["module"] == "course" && (sample.Labels["arm"] == value || sample.Labels["result"] == value) {
+			return sample.Value
+		}
+	}
+	return 0
+}
+
+func replyText(msgs []messaging_api.MessageInterface) string {
+	if len(msgs) != 1 {
+		return ""
+	}
+	return msgs[0].(*messaging_api.TextMessage).Text
+}
+
+func TestCanary_Routing(t *testing.T) {
+	t.Parallel()
+	m := metrics.New(prometheus.NewRegistry())
+	stable := &prefixHandler{stubNLUHandler: stubNLUHandler{stubHandler{name: "course"}}, prefix: "old:"}
+	canary := &prefixHandler{stubNLUHandler: stubNLUHandler{stubHandler{name: "course-fts"}}, prefix: "new:"}
+	h := NewCanary(stable, canary, 0, m)
+
+	if h.Name() != "course" {
+		t.Errorf("Name() = %q, want stable name", h.Name())
+	}
+	if _, ok := h.(NLUHandler); !ok {
+		t.Error("NewCanary() of two NLU handlers should implement NLUHandler")
+	}
+
+	ctx := ctxutil.WithChatID(context.Background(), "C1")
+	if got := replyText(h.HandleMessage(ctx, "x")); got != "old:x" {
+		t.Errorf("HandleMessage() at 0%% = %q, want stable reply", got)
+	}
+
+	c := canaryOf(Wrap(h))
+	if c == nil {
+		t.Fatal("canaryOf() should unwrap middleware")
+	}
+	c.SetPercent(100)
+	if got := replyText(h.HandleMessage(ctx, "x")); got != "new:x" {
+		t.Errorf("HandleMessage() at 100%% = %q, want canary reply", got)
+	}
+	if got := replyText(h.HandleMessage(context.Background(), "x")); got != "old:x" {
+		t.Errorf("HandleMessage() without chat = %q, want stable reply", got)
+	}
+	if got := canaryCount(t, m, "ntpu_canary_diff_total", "different"); got != 1 {
+		t.Errorf("different diffs = %v, want 1", got)
+	}
+	if got := canaryCount(t, m, "ntpu_canary_total", ArmStable); got != 2 {
+		t.Errorf("stable calls = %v, want 2", got)
+	}
+
+	// Postbacks reply identically in both arms
+	h.HandlePostback(ctx, "course:1")
+	if got := canaryCount(t, m, "ntpu_canary_diff_total", "same"); got != 1 {
+		t.Errorf("same diffs = %v, want 1", got)
+	}
+}
+
+func TestCanary_StickyPerChat(t *testing.T) {
+	t.Parallel()
+	c := NewCanary(&stubHandler{name: "course"}, &stubHandler{name: "course"}, 30, metrics.New(prometheus.NewRegistry())).(*Canary)
+
+	inCanary := 0
+	for i := range 1000 {
+		ctx := ctxutil.WithChatID(context.Background(), fmt.Sprintf("U%d", i))
+		first := c.useCanary(ctx)
+		if c.useCanary(ctx) != first {
+			t.Fatalf("chat U%d switched arms", i)
+		}
+		if first {
+			inCanary++
+		}
+	}
+	if inCanary < 200 || inCanary > 400 {
+		t.Errorf("%d of 1000 chats in a 30%% canary", inCanary)
+	}
+}
+
+func TestCanary_StablePanic(t *testing.T) {
+	t.Parallel()
+	m := metrics.New(prometheus.NewRegistry())
+	stable := &prefixHandler{stubNLUHandler: stubNLUHandler{stubHandler{name: "course", panic: true}}, prefix: "old:"}
+	canary := &prefixHandler{stubNLUHandler: stubNLUHandler{stubHandler{name: "course"}}, prefix: "new:"}
+	h := NewCanary(stable, canary, 100, m)
+
+	ctx := ctxutil.WithChatID(context.Background(), "C1")
+	if got := replyText(h.HandleMessage(ctx, "x")); got != "new:x" {
+		t.Errorf("HandleMessage() = %q, want canary reply despite stable panic", got)
+	}
+	if got := canaryCount(t, m, "ntpu_canary_diff_total", "stable_error"); got != 1 {
+		t.Errorf("stable_error diffs = %v, want 1", got)
+	}
+}
+
+func TestRegistry_SetCanaryPercent(t *testing.T) {
+	t.Parallel()
+	r := newTestRegistry()
+	r.Register(Wrap(NewCanary(&stubHandler{name: "program"}, &stubHandler{name: "program"}, 5, metrics.New(prometheus.NewRegistry()))))
+
+	if err := r.SetCanaryPercent("program", 20); err != nil {
+		t.Fatalf("SetCanaryPercent() error = %v", err)
+	}
+	if err := r.SetCanaryPercent("course", 20); !errors.Is(err, ErrNoCanary) {
+		t.Errorf("SetCanaryPercent(course) error = %v, want ErrNoCanary", err)
+	}
+	if err := r.SetCanaryPercent("unknown", 20); !errors.Is(err, ErrModuleNotFound) {
+		t.Errorf("SetCanaryPercent(unknown) error = %v, want ErrModuleNotFound", err)
+	}
+
+	statuses := r.Modules()
+	if statuses[0].CanaryPercent != -1 || statuses[2].CanaryPercent != 20 {
+		t.Errorf("Modules() = %+v, want canary percent only for program", statuses)
+	}
+}
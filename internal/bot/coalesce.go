@@ -0,0 +1,75 @@
+package bot
+
+import (
+	"strings"
+	"sync"
+)
+
+// coalescer drops text messages that repeat one still in the pipeline:
+// users re-sending 「課程 微積分」 while the first copy is still scraping get
+// a single reply and a single scrape. Once the first copy is answered, a
+// repeat is handled like any other message.
+type coalescer struct {
+	mu      sync.Mutex
+	entries map[string]chan struct{} // Closed by end
+}
+
+func newCoalescer() *coalescer {
+	return &coalescer{entries: make(map[string]chan struct{})}
+}
+
+// coalesceKey keys a message per chat, ignoring case and spacing.
+func coalesceKey(chatID, text string) string {
+	return chatID + "\x00" + strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
+
+// begin reports whether the message should be processed. It returns false
+// while an identical message from the chat is in flight. Every true result
+// must be followed by end.
+func (c *coalescer) begin(chatID, text string) bool {
+	key := coalesceKey(chatID, text)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; ok {
+		return false
+	}
+	c.entries[key] = make(chan struct{})
+	return true
+}
+
+// wait returns a channel closed once the in-flight copy of the message is
+// processed, or nil when none is in flight.
+func (c *coalescer) wait(chatID, text string) <-chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.entries[coalesceKey(chatID, text)]
+}
+
+// erase drops the messages of the 1:1 chat with userID, releasing any
+// waiters, and returns how many were dropped. Group entries hold no user.
+func (c *coalescer) erase(userID string) int {
+	prefix := coalesceKey(userID, "")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	for k, done := range c.entries {
+		if strings.HasPrefix(k, prefix) {
+			close(done)
+			delete(c.entries, k)
+			n++
+		}
+	}
+	return n
+}
+
+// end marks the message as processed, releasing its waiters. A message
+// erased while in flight is already released.
+func (c *coalescer) end(chatID, text string) {
+	key := coalesceKey(chatID, text)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if done, ok := c.entries[key]; ok {
+		close(done)
+		delete(c.entries, key)
+	}
+}
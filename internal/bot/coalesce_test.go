@@ -0,0 +1,64 @@
+package bot
+
+import "testing"
+
+func TestCoalescer(t *testing.T) {
+	t.Parallel()
+	c := newCoalescer()
+
+	if !c.begin("U1", "課程 微積分") {
+		t.Fatal("begin(first) = false, want true")
+	}
+	if c.begin("U1", "課程  微積分") {
+		t.Error("begin(in flight) = true, want false")
+	}
+	if !c.begin("U2", "課程 微積分") {
+		t.Error("begin(other chat) = false, want true")
+	}
+	if !c.begin("U1", "課程 線性代數") {
+		t.Error("begin(other text) = false, want true")
+	}
+
+	done := c.wait("U1", "課程 微積分")
+	if done == nil {
+		t.Fatal("wait(in flight) = nil, want a channel")
+	}
+	c.end("U1", "課程 微積分")
+	select {
+	case <-done:
+	default:
+		t.Error("wait channel still open after end")
+	}
+	if c.wait("U1", "課程 微積分") != nil {
+		t.Error("wait(finished) != nil, want nil")
+	}
+	if !c.begin("U1", "課程 微積分") {
+		t.Error("begin(after end) = false, want true")
+	}
+}
+
+func TestCoalescer_Erase(t *testing.T) {
+	t.Parallel()
+	c := newCoalescer()
+	c.begin("U1", "課程 微積分")
+	c.begin("U1", "課程 線性代數")
+	c.begin("U12", "課程 微積分")
+	c.begin("C1", "課程 微積分")
+	done := c.wait("U1", "課程 微積分")
+
+	if n := c.erase("U1"); n != 2 {
+		t.Errorf("erase() = %d, want 2", n)
+	}
+	select {
+	case <-done:
+	default:
+		t.Error("erase() left a waiter blocked")
+	}
+	c.end("U1", "課程 微積分") // The erased message finishing must not restore it
+	if !c.begin("U1", "課程 微積分") {
+		t.Error("end() restored an erased message")
+	}
+	if c.begin("U12", "課程 微積分") || c.begin("C1", "課程 微積分") {
+		t.Error("erase() dropped another chat's message")
+	}
+}
@@ -0,0 +1,76 @@
+package bot
+
+import (
+	"regexp"
+	"unicode"
+)
+
+// englishRewrite maps a common English question to the keyword command
+// that answers it.
+type englishRewrite struct {
+	pattern *regexp.Regexp
+	replace string // regexp.Expand template, e.g. "課程 $1"
+}
+
+// englishRewrites cover the English questions exchange students ask most.
+// Patterns match sanitized text (no punctuation, so "what's" is "whats")
+// and are tried in order. Anything else is left to the keyword matchers,
+// which already accept English keywords (course, contact, student), and NLU.
+var englishRewrites = []englishRewrite{
+	// who teaches calculus / which professor teaches linear algebra
+	{regexp.MustCompile(`(?i)^(?:who|which\s+(?:teachers?|professors?))\s+(?:teach|teaches|is\s+teaching|taught)\s+(.+)$`), "課程 $1"},
+	// courses taught by 王小明 / what classes are offered by 王小明
+	{regexp.MustCompile(`(?i)^(?:(?:what|which)\s+)?(?:courses?|classes)\s+(?:(?:are|is)\s+)?(?:taught|offered)\s+by\s+(.+)$`), "課程 $1"},
+	// what courses does 王小明 teach
+	{regexp.MustCompile(`(?i)^(?:what|which)\s+(?:courses?|classes)\s+does\s+(.+?)\s+teach$`), "課程 $1"},
+	// find courses about finance / search for classes on statistics
+	{regexp.MustCompile(`(?i)^(?:find|search(?:\s+for)?|look\s+up|show(?:\s+me)?)\s+(?:courses?|classes)\s+(?:about|on|for|named|called)\s+(.+)$`), "課程 $1"},
+	// recommend a random course
+	{regexp.MustCompile(`(?i)^(?:recommend|suggest|pick)\s+(?:me\s+)?(?:(?:a|one|some)\s+)?(?:random\s+)?(?:courses?|class(?:es)?)$`), "隨機課程"},
+	// remote courses / online classes
+	{regexp.MustCompile(`(?i)^(?:remote|online)\s+(?:courses|classes)$`), "遠距課程"},
+	// phone number of the library / whats the email for 資工系
+	{regexp.MustCompile(`(?i)^(?:whats\s+)?(?:the\s+)?(?:phone(?:\s+number)?|extension|email|contact(?:\s+info(?:rmation)?)?)\s+(?:of|for)\s+(?:the\s+)?(.+)$`), "聯繫 $1"},
+	// how do i reach the library / call 學務處
+	{regexp.MustCompile(`(?i)^(?:how\s+(?:do|can)\s+i\s+)?(?:call|reach)\s+(?:the\s+)?(.+)$`), "聯繫 $1"},
+	// emergency numbers / campus security
+	{regexp.MustCompile(`(?i)^(?:campus\s+)?(?:emergency|security)(?:\s+(?:numbers?|phones?|contacts?|hotlines?))?$`), "緊急"},
+	// who is student 412345678 / student with id 412345678
+	{regexp.MustCompile(`(?i)^(?:who\s+is\s+)?(?:the\s+)?student\s+(?:with\s+)?(?:id\s+)?(\d{8,9})$`), "學號 $1"},
+}
+
+// IsEnglishText reports whether text reads as English: it has at least one
+// word of two or more Latin letters and no Han characters. Bare course
+// numbers and student IDs (digits with at most single letters) are not
+// English, so their replies stay in Chinese.
+func IsEnglishText(text string) bool {
+	letters, hasWord := 0, false
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			return false
+		case r <= unicode.MaxASCII && unicode.IsLetter(r):
+			letters++
+			if letters >= 2 {
+				hasWord = true
+			}
+		default:
+			letters = 0
+		}
+	}
+	return hasWord
+}
+
+// RewriteEnglishQuery rewrites a sanitized English question into the
+// keyword command that answers it, e.g. "who teaches calculus" →
+// "課程 calculus". It reports false when no rewrite applies.
+func RewriteEnglishQuery(text string) (string, bool) {
+	for _, rw := range englishRewrites {
+		match := rw.pattern.FindStringSubmatchIndex(text)
+		if match == nil {
+			continue
+		}
+		return string(rw.pattern.ExpandString(nil, rw.replace, text, match)), true
+	}
+	return "", false
+}
@@ -0,0 +1,63 @@
+package bot
+
+import "testing"
+
+func TestIsEnglishText(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"who teaches calculus", true},
+		{"help", true},
+		{"course 1131U0001", true},
+		{"課程 微積分", false},
+		{"teacher 王小明", false},
+		{"1131U0001", false},
+		{"412345678", false},
+		{"U0001", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			t.Parallel()
+			if got := IsEnglishText(tt.text); got != tt.want {
+				t.Errorf("IsEnglishText(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRewriteEnglishQuery(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		text   string
+		want   string
+		wantOK bool
+	}{
+		{"who teaches calculus", "課程 calculus", true},
+		{"Which professor teaches Linear Algebra", "課程 Linear Algebra", true},
+		{"courses taught by Wang", "課程 Wang", true},
+		{"what classes does Wang teach", "課程 Wang", true},
+		{"find courses about finance", "課程 finance", true},
+		{"recommend me a random course", "隨機課程", true},
+		{"online classes", "遠距課程", true},
+		{"whats the phone number of the library", "聯繫 library", true},
+		{"how do I reach the library", "聯繫 library", true},
+		{"emergency numbers", "緊急", true},
+		{"who is student 412345678", "學號 412345678", true},
+		{"course calculus", "", false}, // Already a keyword command
+		{"hello", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			t.Parallel()
+			got, ok := RewriteEnglishQuery(tt.text)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("RewriteEnglishQuery(%q) = (%q, %v), want (%q, %v)", tt.text, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
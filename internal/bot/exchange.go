@@ -0,0 +1,96 @@
+package bot
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/ctxutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+	"github.com/line/line-bot-sdk-go/v8/linebot/webhook"
+)
+
+// Exchange is one handled 1:1 text message: what the user sent and what the
+// bot answered. The latest one per user is kept so 回報問題 can attach it to
+// a bug report.
+type Exchange struct {
+	Query     string        // Sanitized message text
+	Module    string        // Module that answered ("" for help and fallback replies)
+	Source    string        // How it was routed: "keyword", "nlu", or "fallback"
+	Reply     string        // Reply summary (text and alt texts, see summarizeReply)
+	Duration  time.Duration // Time taken to build the reply
+	RequestID string        // Webhook request ID, for finding the logs
+	Time      time.Time     // When the reply was built
+}
+
+// ExchangeRecorder keeps each user's latest exchange.
+type ExchangeRecorder interface {
+	RecordExchange(ctx context.Context, userID string, ex Exchange) error
+}
+
+// exchangeSkipModules are modules whose exchanges are not kept: bugreport
+// would replace the exchange being reported, privacy would store data right
+// after its deletion, and role commands carry other users' IDs.
+var exchangeSkipModules = []string{"bugreport", "privacy", "role"}
+
+// maxReplySummaryRunes caps the reply summary kept with an exchange.
+const maxReplySummaryRunes = 300
+
+// exchangeNoteKey carries an *exchangeNote through the NLU path.
+type exchangeNoteKey struct{}
+
+// exchangeNote collects what the NLU path resolved, which ProcessMessage
+// only sees as the returned messages.
+type exchangeNote struct {
+	module string
+}
+
+// noteExchangeModule records the module the NLU path dispatched to.
+func noteExchangeModule(ctx context.Context, module string) {
+	if note, ok := ctx.Value(exchangeNoteKey{}).(*exchangeNote); ok {
+		note.module = module
+	}
+}
+
+// recordExchange keeps a 1:1 exchange for 回報問題. Failures are logged and
+// never affect the reply.
+func (p *Processor) recordExchange(ctx context.Context, source webhook.SourceInterface, ex Exchange, msgs []messaging_api.MessageInterface) {
+	userID := ctxutil.GetUserID(ctx)
+	if p.exchanges == nil || userID == "" || len(msgs) == 0 || !IsPersonalChat(source) ||
+		slices.Contains(exchangeSkipModules, ex.Module) {
+		return
+	}
+
+	ex.Reply = summarizeReply(msgs)
+	ex.Time = time.Now()
+	ex.RequestID, _ = ctxutil.GetRequestID(ctx)
+	if err := p.exchanges.RecordExchange(ctx, userID, ex); err != nil {
+		p.logger.WithError(err).WithField("module", ex.Module).WarnContext(ctx, "Failed to record exchange")
+	}
+}
+
+// summarizeReply describes a reply in one line: the text of text messages,
+// the alt text of Flex and template messages, and a placeholder for images.
+func summarizeReply(msgs []messaging_api.MessageInterface) string {
+	parts := make([]string, 0, len(msgs))
+	for _, msg := range msgs {
+		switch m := msg.(type) {
+		case *messaging_api.TextMessageV2:
+			parts = append(parts, m.Text)
+		case *messaging_api.TextMessage:
+			parts = append(parts, m.Text)
+		case *messaging_api.FlexMessage:
+			parts = append(parts, "[Flex] "+m.AltText)
+		case *messaging_api.TemplateMessage:
+			parts = append(parts, "[Template] "+m.AltText)
+		case *messaging_api.ImageMessage:
+			parts = append(parts, "[圖片]")
+		default:
+			parts = append(parts, "["+msg.GetType()+"]")
+		}
+	}
+	summary := strings.Join(strings.Fields(strings.Join(parts, " / ")), " ")
+	return lineutil.TruncateRunes(summary, maxReplySummaryRunes)
+}
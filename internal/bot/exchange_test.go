@@ -0,0 +1,90 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/ctxutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+	"github.com/line/line-bot-sdk-go/v8/linebot/webhook"
+)
+
+type fakeExchangeRecorder struct {
+	exchanges []Exchange
+}
+
+func (r *fakeExchangeRecorder) RecordExchange(_ context.Context, _ string, ex Exchange) error {
+	r.exchanges = append(r.exchanges, ex)
+	return nil
+}
+
+func TestProcessor_RecordExchange(t *testing.T) {
+	t.Parallel()
+
+	personal := webhook.UserSource{UserId: "U1"}
+	group := webhook.GroupSource{GroupId: "C1", UserId: "U1"}
+	reply := []messaging_api.MessageInterface{lineutil.NewTextMessage("找到 3 門課")}
+
+	tests := []struct {
+		name   string
+		source webhook.SourceInterface
+		module string
+		msgs   []messaging_api.MessageInterface
+		want   bool
+	}{
+		{"personal chat", personal, "course", reply, true},
+		{"help reply", personal, "", reply, true},
+		{"group chat", group, "course", reply, false},
+		{"bug report itself", personal, "bugreport", reply, false},
+		{"no reply", personal, "course", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rec := &fakeExchangeRecorder{}
+			p := &Processor{exchanges: rec, logger: logger.New("error")}
+			ctx := ctxutil.WithRequestID(ctxutil.WithUserID(context.Background(), "U1"), "req-1")
+
+			p.recordExchange(ctx, tt.source, Exchange{Query: "課程 微積分", Module: tt.module, Source: "keyword"}, tt.msgs)
+			if got := len(rec.exchanges) == 1; got != tt.want {
+				t.Fatalf("recorded %v, want %v", rec.exchanges, tt.want)
+			}
+			if tt.want {
+				ex := rec.exchanges[0]
+				if ex.Reply != "找到 3 門課" || ex.RequestID != "req-1" || ex.Time.IsZero() {
+					t.Errorf("exchange = %+v, want reply summary, request ID and time", ex)
+				}
+			}
+		})
+	}
+}
+
+func TestNoteExchangeModule(t *testing.T) {
+	t.Parallel()
+	note := &exchangeNote{}
+	noteExchangeModule(context.WithValue(context.Background(), exchangeNoteKey{}, note), "contact")
+	if note.module != "contact" {
+		t.Errorf("note.module = %q, want contact", note.module)
+	}
+	noteExchangeModule(context.Background(), "course") // No note: no-op
+}
+
+func TestSummarizeReply(t *testing.T) {
+	t.Parallel()
+	msgs := []messaging_api.MessageInterface{
+		lineutil.NewTextMessage("第一行\n第二行"),
+		lineutil.NewFlexMessage("課程列表", &messaging_api.FlexBubble{}),
+		lineutil.NewImageMessage("https://example.com/a.png", "https://example.com/a.png"),
+	}
+	if got, want := summarizeReply(msgs), "第一行 第二行 / [Flex] 課程列表 / [圖片]"; got != want {
+		t.Errorf("summarizeReply() = %q, want %q", got, want)
+	}
+
+	long := []messaging_api.MessageInterface{lineutil.NewTextMessage(strings.Repeat("字", 1000))}
+	if got := []rune(summarizeReply(long)); len(got) != maxReplySummaryRunes {
+		t.Errorf("summary length = %d runes, want %d", len(got), maxReplySummaryRunes)
+	}
+}
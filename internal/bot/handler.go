@@ -29,17 +29,27 @@ type Handler interface {
 	// The data parameter contains the PostbackData structure.
 	//
 	// Postback Format:
-	//   - String format: "module:action$param1$param2"
+	//   - Typed format: "module:action$v1$key=value" (see Postback)
+	//   - Legacy format: "module:action$param1$param2" (still decoded)
 	//   - Max 300 bytes per LINE API limit
-	//   - Use bot.PostbackSplitChar ("$") as parameter separator
 	//
 	// Example:
-	//   fmt.Sprintf("course:detail%s%s", bot.PostbackSplitChar, courseUID)
+	//   bot.NewPostback("course", "uid").With("uid", courseUID).String()
 	//
+	// Modules typically delegate to a PostbackRouter.
 	// Returns a slice of LINE messages (max 5 messages per reply per LINE API).
 	HandlePostback(ctx context.Context, data string) []messaging_api.MessageInterface
 }
 
+// NoPostbacks provides the HandlePostback of a module whose commands are all
+// plain text. Embed it in the handler instead of an empty method.
+type NoPostbacks struct{}
+
+// HandlePostback returns no messages; the module issues no postbacks.
+func (NoPostbacks) HandlePostback(context.Context, string) []messaging_api.MessageInterface {
+	return []messaging_api.MessageInterface{}
+}
+
 // NLUHandler defines the interface for modules that support NLU intent dispatching.
 type NLUHandler interface {
 	Handler
@@ -4,8 +4,10 @@ package bot
 import (
 	"slices"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/line/line-bot-sdk-go/v8/linebot/webhook"
+	"golang.org/x/text/width"
 )
 
 // IsBotMentioned checks if the bot is mentioned in a text message.
@@ -91,3 +93,16 @@ func removeBotMentions(text string, mention *webhook.Mention) string {
 	// strings.Fields splits on any whitespace and Join with single space
 	return strings.Join(strings.Fields(string(runes)), " ")
 }
+
+// trimTriggerPrefix removes a group's trigger prefix from the start of text
+// and reports whether text had it. Full-width and half-width forms match
+// each other, so "！課程 微積分" has the prefix "!".
+func trimTriggerPrefix(text, prefix string) (string, bool) {
+	prefix = width.Fold.String(prefix)
+	runes := []rune(strings.TrimSpace(text))
+	n := utf8.RuneCountInString(prefix)
+	if n == 0 || len(runes) < n || width.Fold.String(string(runes[:n])) != prefix {
+		return text, false
+	}
+	return strings.TrimSpace(string(runes[n:])), true
+}
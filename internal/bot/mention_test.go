@@ -378,3 +378,35 @@ func TestRemoveBotMentions_BoundaryConditions(t *testing.T) {
 		})
 	}
 }
+
+func TestTrimTriggerPrefix(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		text   string
+		prefix string
+		want   string
+		wantOK bool
+	}{
+		{"prefixed", "!課程 微積分", "!", "課程 微積分", true},
+		{"space after prefix", "! 課程 微積分", "!", "課程 微積分", true},
+		{"full-width text", "！課程 微積分", "!", "課程 微積分", true},
+		{"full-width prefix", "!課程", "！", "課程", true},
+		{"multi-rune prefix", "//課程", "//", "課程", true},
+		{"missing prefix", "課程 微積分", "!", "課程 微積分", false},
+		{"prefix later in text", "課程 !微積分", "!", "課程 !微積分", false},
+		{"shorter than prefix", "/", "//", "/", false},
+		{"prefix only", "!", "!", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, ok := trimTriggerPrefix(tt.text, tt.prefix)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("trimTriggerPrefix(%q, %q) = (%q, %v), want (%q, %v)", tt.text, tt.prefix, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/ctxutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/metrics"
+	"github.com/garyellow/ntpu-linebot-go/internal/ratelimit"
+	"github.com/garyellow/ntpu-linebot-go/internal/sticker"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+// Invocation kinds passed to middleware.
+const (
+	KindMessage  = "message"
+	KindPostback = "postback"
+	KindIntent   = "intent"
+)
+
+// ErrHandlerPanic is returned by Recover when a module panics.
+var ErrHandlerPanic = errors.New("module handler panicked")
+
+// Invocation describes a single module call seen by middleware.
+type Invocation struct {
+	Module string // Handler name (e.g., "course")
+	Kind   string // KindMessage, KindPostback, or KindIntent
+	Intent string // NLU intent name (KindIntent only)
+}
+
+// Next invokes the next middleware in the chain, or the module itself.
+type Next func(ctx context.Context) ([]messaging_api.MessageInterface, error)
+
+// Middleware intercepts module calls for cross-cutting concerns
+// (timing, panic recovery, rate limiting, tracing).
+//
+// Handler methods without an error return (HandleMessage, HandlePostback)
+// receive nil messages when the chain returns an error.
+type Middleware func(ctx context.Context, inv Invocation, next Next) ([]messaging_api.MessageInterface, error)
+
+// Wrap composes middlewares around a handler. The first middleware is the outermost.
+// CanHandle and Name pass through untouched; NLU support is preserved, so the
+// result implements NLUHandler only if h does.
+func Wrap(h Handler, mws ...Middleware) Handler {
+	if len(mws) == 0 {
+		return h
+	}
+	w := &wrappedHandler{Handler: h, mws: mws}
+	if nlu, ok := h.(NLUHandler); ok {
+		return &wrappedNLUHandler{wrappedHandler: w, nlu: nlu}
+	}
+	return w
+}
+
+type wrappedHandler struct {
+	Handler
+	mws []Middleware
+}
+
+// run executes the middleware chain ending in final.
+func (w *wrappedHandler) run(ctx context.Context, inv Invocation, final Next) ([]messaging_api.MessageInterface, error) {
+	next := final
+	for i := len(w.mws) - 1; i >= 0; i-- {
+		mw, inner := w.mws[i], next
+		next = func(ctx context.Context) ([]messaging_api.MessageInterface, error) {
+			return mw(ctx, inv, inner)
+		}
+	}
+	return next(ctx)
+}
+
+func (w *wrappedHandler) HandleMessage(ctx context.Context, text string) []messaging_api.MessageInterface {
+	inv := Invocation{Module: w.Name(), Kind: KindMessage}
+	msgs, err := w.run(ctx, inv, func(ctx context.Context) ([]messaging_api.MessageInterface, error) {
+		return w.Handler.HandleMessage(ctx, text), nil
+	})
+	if err != nil {
+		return nil
+	}
+	return msgs
+}
+
+func (w *wrappedHandler) HandlePostback(ctx context.Context, data string) []messaging_api.MessageInterface {
+	inv := Invocation{Module: w.Name(), Kind: KindPostback}
+	msgs, err := w.run(ctx, inv, func(ctx context.Context) ([]messaging_api.MessageInterface, error) {
+		return w.Handler.HandlePostback(ctx, data), nil
+	})
+	if err != nil {
+		return nil
+	}
+	return msgs
+}
+
+// Unwrap returns the wrapped handler.
+func (w *wrappedHandler) Unwrap() Handler {
+	return w.Handler
+}
+
+type wrappedNLUHandler struct {
+	*wrappedHandler
+	nlu NLUHandler
+}
+
+func (w *wrappedNLUHandler) DispatchIntent(ctx context.Context, intent string, params map[string]string) ([]messaging_api.MessageInterface, error) {
+	inv := Invocation{Module: w.Name(), Kind: KindIntent, Intent: intent}
+	return w.run(ctx, inv, func(ctx context.Context) ([]messaging_api.MessageInterface, error) {
+		return w.nlu.DispatchIntent(ctx, intent, params)
+	})
+}
+
+// Recover converts module panics into a friendly system error reply.
+// The panic and stack trace are logged at error level; the webhook goroutine survives.
+func Recover(log *logger.Logger, stickerManager *sticker.Manager) Middleware {
+	return func(ctx context.Context, inv Invocation, next Next) (msgs []messaging_api.MessageInterface, err error) {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+			log.WithModule(inv.Module).
+				WithField("kind", inv.Kind).
+				WithField("panic", fmt.Sprint(r)).
+				WithField("stack", string(debug.Stack())).
+				ErrorContext(ctx, "Module handler panicked")
+
+			sender := lineutil.GetSender("NTPU 小工具", stickerManager)
+			msgs = []messaging_api.MessageInterface{lineutil.SystemErrorMessage("處理請求", sender)}
+			err = nil
+			if inv.Kind == KindIntent {
+				// Let the processor render its NLU fallback instead.
+				msgs, err = nil, fmt.Errorf("%w: %v", ErrHandlerPanic, r)
+			}
+		}()
+		return next(ctx)
+	}
+}
+
+// Logging puts a logger with the module and intent fields into the context
+// for the module to log through (logger.FromContext), and records module
+// calls at debug level with their duration and outcome.
+func Logging(log *logger.Logger) Middleware {
+	return func(ctx context.Context, inv Invocation, next Next) ([]messaging_api.MessageInterface, error) {
+		scoped := log.WithModule(inv.Module)
+		if inv.Intent != "" {
+			scoped = scoped.WithField("intent", inv.Intent)
+		}
+		start := time.Now()
+		msgs, err := next(logger.NewContext(ctx, scoped))
+		entry := scoped.WithField("kind", inv.Kind).
+			WithField("messages", len(msgs)).
+			WithField("duration_ms", time.Since(start).Milliseconds())
+		if err != nil {
+			entry.WithError(err).DebugContext(ctx, "Module call failed")
+			return msgs, err
+		}
+		entry.DebugContext(ctx, "Module call completed")
+		return msgs, nil
+	}
+}
+
+// Metrics records module call counts and durations, and names the module on
+// the webhook event trace for the end-to-end latency metric.
+// status: success, empty (no messages), error
+func Metrics(m *metrics.Metrics) Middleware {
+	return func(ctx context.Context, inv Invocation, next Next) ([]messaging_api.MessageInterface, error) {
+		ctxutil.GetEventTrace(ctx).SetModule(inv.Module, inv.Intent)
+		start := time.Now()
+		msgs, err := next(ctx)
+		status := "success"
+		switch {
+		case err != nil:
+			status = "error"
+		case len(msgs) == 0:
+			status = "empty"
+		}
+		m.RecordModuleCall(inv.Module, inv.Kind, status, time.Since(start).Seconds())
+		return msgs, err
+	}
+}
+
+// RateLimit applies a per-chat token bucket to a module, keyed by "{module}:{chatID}".
+// Calls without a chat ID in context (e.g., tests, internal dispatch) are not limited.
+func RateLimit(limiter *ratelimit.KeyedLimiter, stickerManager *sticker.Manager) Middleware {
+	return func(ctx context.Context, inv Invocation, next Next) ([]messaging_api.MessageInterface, error) {
+		chatID := ctxutil.GetChatID(ctx)
+		if chatID == "" || limiter.Allow(inv.Module+":"+chatID) {
+			return next(ctx)
+		}
+
+		sender := lineutil.GetSender("NTPU 小工具", stickerManager)
+		msg := lineutil.NewTextMessageWithConsistentSender(
+			"⏳ 此功能使用過於頻繁，請稍後再試\n💡 其他功能仍可正常使用",
+			sender,
+		)
+		msg.QuickReply = lineutil.NewQuickReply(lineutil.QuickReplyMainNavCompact())
+		return []messaging_api.MessageInterface{msg}, nil
+	}
+}
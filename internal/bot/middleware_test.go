@@ -0,0 +1,209 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/ctxutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/metrics"
+	"github.com/garyellow/ntpu-linebot-go/internal/ratelimit"
+	"github.com/garyellow/ntpu-linebot-go/internal/sticker"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// stubHandler is a minimal Handler for middleware tests.
+type stubHandler struct {
+	name  string
+	panic bool
+}
+
+func (s *stubHandler) Name() string            { return s.name }
+func (s *stubHandler) CanHandle(_ string) bool { return true }
+
+func (s *stubHandler) HandleMessage(_ context.Context, text string) []messaging_api.MessageInterface {
+	if s.panic {
+		panic("boom")
+	}
+	return []messaging_api.MessageInterface{&messaging_api.TextMessage{Text: text}}
+}
+
+func (s *stubHandler) HandlePostback(_ context.Context, data string) []messaging_api.MessageInterface {
+	return []messaging_api.MessageInterface{&messaging_api.TextMessage{Text: data}}
+}
+
+// stubNLUHandler adds DispatchIntent to stubHandler.
+type stubNLUHandler struct {
+	stubHandler
+}
+
+func (s *stubNLUHandler) DispatchIntent(_ context.Context, intent string, _ map[string]string) ([]messaging_api.MessageInterface, error) {
+	if s.panic {
+		panic("boom")
+	}
+	return []messaging_api.MessageInterface{&messaging_api.TextMessage{Text: intent}}, nil
+}
+
+func TestWrap_PreservesNLUSupport(t *testing.T) {
+	t.Parallel()
+	noop := func(ctx context.Context, _ Invocation, next Next) ([]messaging_api.MessageInterface, error) {
+		return next(ctx)
+	}
+
+	if _, ok := Wrap(&stubHandler{name: "plain"}, noop).(NLUHandler); ok {
+		t.Error("Wrap() of a plain handler should not implement NLUHandler")
+	}
+
+	wrapped, ok := Wrap(&stubNLUHandler{stubHandler{name: "nlu"}}, noop).(NLUHandler)
+	if !ok {
+		t.Fatal("Wrap() of an NLU handler should implement NLUHandler")
+	}
+	if wrapped.Name() != "nlu" {
+		t.Errorf("Name() = %q, want %q", wrapped.Name(), "nlu")
+	}
+	msgs, err := wrapped.DispatchIntent(context.Background(), "search", nil)
+	if err != nil || len(msgs) != 1 {
+		t.Errorf("DispatchIntent() = %d messages, err %v; want 1 message", len(msgs), err)
+	}
+}
+
+func TestWrap_Order(t *testing.T) {
+	t.Parallel()
+	var calls []string
+	record := func(name string) Middleware {
+		return func(ctx context.Context, inv Invocation, next Next) ([]messaging_api.MessageInterface, error) {
+			calls = append(calls, name+":"+inv.Kind)
+			return next(ctx)
+		}
+	}
+
+	h := Wrap(&stubHandler{name: "test"}, record("outer"), record("inner"))
+	h.HandlePostback(context.Background(), "test:x")
+
+	want := []string{"outer:postback", "inner:postback"}
+	if len(calls) != len(want) {
+		t.Fatalf("calls = %v, want %v", calls, want)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Errorf("calls[%d] = %q, want %q", i, calls[i], want[i])
+		}
+	}
+}
+
+func TestRecover(t *testing.T) {
+	t.Parallel()
+	log := logger.New("error")
+	stickerMgr := sticker.NewManager(nil, nil, log)
+
+	h := Wrap(&stubNLUHandler{stubHandler{name: "test", panic: true}}, Recover(log, stickerMgr))
+
+	msgs := h.HandleMessage(context.Background(), "hello")
+	if len(msgs) != 1 {
+		t.Fatalf("HandleMessage() after panic returned %d messages, want 1 error reply", len(msgs))
+	}
+
+	_, err := h.(NLUHandler).DispatchIntent(context.Background(), "search", nil)
+	if !errors.Is(err, ErrHandlerPanic) {
+		t.Errorf("DispatchIntent() error = %v, want ErrHandlerPanic", err)
+	}
+}
+
+// ctxLogHandler logs through the context logger, as modules do.
+type ctxLogHandler struct {
+	stubNLUHandler
+}
+
+func (s *ctxLogHandler) DispatchIntent(ctx context.Context, intent string, _ map[string]string) ([]messaging_api.MessageInterface, error) {
+	logger.FromContext(ctx).InfoContext(ctx, "Handled")
+	return nil, nil
+}
+
+func TestLoggingMiddleware_ScopesContextLogger(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	log := logger.NewWithWriter("info", &buf)
+
+	h := Wrap(&ctxLogHandler{stubNLUHandler{stubHandler{name: "course"}}}, Logging(log))
+	ctx := ctxutil.WithRequestID(context.Background(), "req-1")
+	if _, err := h.(NLUHandler).DispatchIntent(ctx, "search", nil); err != nil {
+		t.Fatalf("DispatchIntent() error = %v", err)
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Failed to parse JSON log %q: %v", buf.String(), err)
+	}
+	for key, want := range map[string]string{"module": "course", "intent": "search", "request_id": "req-1"} {
+		if entry[key] != want {
+			t.Errorf("entry[%q] = %v, want %q", key, entry[key], want)
+		}
+	}
+}
+
+func TestMetricsMiddleware(t *testing.T) {
+	t.Parallel()
+	registry := prometheus.NewRegistry()
+	m := metrics.New(registry)
+
+	h := Wrap(&stubHandler{name: "test"}, Metrics(m))
+	h.HandleMessage(context.Background(), "hello")
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	for _, mf := range families {
+		if mf.GetName() != "ntpu_module_total" {
+			continue
+		}
+		for _, metric := range mf.GetMetric() {
+			labels := map[string]string{}
+			for _, lp := range metric.GetLabel() {
+				labels[lp.GetName()] = lp.GetValue()
+			}
+			if labels["module"] == "test" && labels["kind"] == KindMessage && labels["status"] == "success" {
+				if got := metric.GetCounter().GetValue(); got != 1 {
+					t.Errorf("ntpu_module_total = %v, want 1", got)
+				}
+				return
+			}
+		}
+	}
+	t.Error("ntpu_module_total{module=test,kind=message,status=success} not recorded")
+}
+
+func TestRateLimit(t *testing.T) {
+	t.Parallel()
+	log := logger.New("error")
+	limiter := ratelimit.NewKeyedLimiter(ratelimit.KeyedConfig{
+		Name:          "module",
+		Burst:         1,
+		RefillRate:    0.001,
+		CleanupPeriod: time.Minute,
+	})
+	t.Cleanup(limiter.Stop)
+
+	h := Wrap(&stubHandler{name: "test"}, RateLimit(limiter, sticker.NewManager(nil, nil, log)))
+	ctx := ctxutil.WithChatID(context.Background(), "U123")
+
+	first := h.HandleMessage(ctx, "hello")
+	if msg, ok := first[0].(*messaging_api.TextMessage); !ok || msg.Text != "hello" {
+		t.Errorf("first call should reach the handler, got %#v", first[0])
+	}
+
+	second := h.HandleMessage(ctx, "hello")
+	if _, ok := second[0].(*messaging_api.TextMessageV2); !ok {
+		t.Errorf("second call should be throttled, got %#v", second[0])
+	}
+
+	// Calls without a chat ID bypass the limiter
+	if msgs := h.HandleMessage(context.Background(), "hello"); len(msgs) != 1 {
+		t.Errorf("call without chat ID returned %d messages, want 1", len(msgs))
+	}
+}
@@ -2,7 +2,14 @@ package bot
 
 import (
 	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strconv"
 	"strings"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
 )
 
 // PostbackData represents structured postback payload.
@@ -36,3 +43,195 @@ func ParsePostback(data string) (*PostbackData, error) {
 		Params: actionAndParams[1:],
 	}, nil
 }
+
+// PostbackVersion is the current typed postback encoding version.
+// Bump when the wire format changes so handlers can keep decoding
+// buttons from chat history that were rendered by older releases.
+const PostbackVersion = 1
+
+// Typed postback errors.
+var (
+	// ErrPostbackInvalid indicates malformed postback data.
+	ErrPostbackInvalid = errors.New("invalid postback data")
+	// ErrPostbackTooLong indicates encoded data exceeds LINE's postback limit.
+	ErrPostbackTooLong = errors.New("postback data exceeds LINE limit")
+)
+
+// postbackEscaper escapes characters that carry meaning in the typed format.
+// Only reserved characters are escaped so CJK values stay compact
+// (percent-encoding every rune would triple their byte size).
+var postbackEscaper = strings.NewReplacer(
+	"%", "%25",
+	PostbackSplitChar, "%24",
+	"=", "%3D",
+)
+
+// Postback is a typed postback payload.
+//
+// Encoded format (v1): "module:action$v1$key=value$key=value"
+//   - Params are written in key order so encoding is deterministic
+//   - Reserved characters ("%", "$", "=") in keys and values are percent-escaped
+//
+// Legacy payloads without a version segment ("module:action$arg1$arg2")
+// decode with Version 0 and their positional parameters in Args.
+type Postback struct {
+	Module  string            // Module identifier (e.g., "course", "id")
+	Action  string            // Action identifier (e.g., "uid", "teacher")
+	Version int               // Encoding version (0 = legacy positional format)
+	Params  map[string]string // Named parameters (versioned format)
+	Args    []string          // Positional parameters (legacy format)
+}
+
+// NewPostback creates a typed postback for the given module and action
+// using the current encoding version.
+func NewPostback(module, action string) *Postback {
+	return &Postback{
+		Module:  module,
+		Action:  action,
+		Version: PostbackVersion,
+		Params:  make(map[string]string),
+	}
+}
+
+// With sets a named parameter and returns the postback for chaining.
+func (p *Postback) With(key, value string) *Postback {
+	if p.Params == nil {
+		p.Params = make(map[string]string)
+	}
+	p.Params[key] = value
+	return p
+}
+
+// Get returns a named parameter, or empty string if absent.
+func (p *Postback) Get(key string) string {
+	return p.Params[key]
+}
+
+// Arg returns a positional (legacy) parameter, or empty string if out of range.
+func (p *Postback) Arg(i int) string {
+	if i < 0 || i >= len(p.Args) {
+		return ""
+	}
+	return p.Args[i]
+}
+
+// Encode serializes the postback and validates it against LINE's
+// postback data limit (config.LINEMaxPostbackDataLength bytes).
+func (p *Postback) Encode() (string, error) {
+	if p.Module == "" || p.Action == "" {
+		return "", fmt.Errorf("%w: module and action are required", ErrPostbackInvalid)
+	}
+
+	var b strings.Builder
+	b.WriteString(p.Module)
+	b.WriteString(":")
+	b.WriteString(postbackEscaper.Replace(p.Action))
+	b.WriteString(PostbackSplitChar)
+	b.WriteString("v")
+	b.WriteString(strconv.Itoa(PostbackVersion))
+
+	for _, key := range slices.Sorted(maps.Keys(p.Params)) {
+		b.WriteString(PostbackSplitChar)
+		b.WriteString(postbackEscaper.Replace(key))
+		b.WriteString("=")
+		b.WriteString(postbackEscaper.Replace(p.Params[key]))
+	}
+
+	data := b.String()
+	if len(data) > config.LINEMaxPostbackDataLength {
+		return "", fmt.Errorf("%w: %d bytes (max %d)", ErrPostbackTooLong, len(data), config.LINEMaxPostbackDataLength)
+	}
+	return data, nil
+}
+
+// String returns the encoded postback, or empty string if validation fails.
+// Use for payloads built from bounded values (UIDs, years, codes);
+// call Encode when values come from scraped or user-provided text.
+func (p *Postback) String() string {
+	data, err := p.Encode()
+	if err != nil {
+		return ""
+	}
+	return data
+}
+
+// DecodePostback parses postback data into a typed Postback.
+// Both the versioned format and the legacy "module:action$arg" format are accepted.
+func DecodePostback(data string) (*Postback, error) {
+	if len(data) > config.LINEMaxPostbackDataLength {
+		return nil, fmt.Errorf("%w: %d bytes (max %d)", ErrPostbackTooLong, len(data), config.LINEMaxPostbackDataLength)
+	}
+
+	module, remainder, ok := strings.Cut(data, ":")
+	if !ok || module == "" {
+		return nil, fmt.Errorf("%w: missing ':' separator", ErrPostbackInvalid)
+	}
+
+	pb, err := decodePostbackBody(remainder)
+	if err != nil {
+		return nil, err
+	}
+	pb.Module = module
+	return pb, nil
+}
+
+// decodePostbackBody parses the "action$..." part of postback data.
+func decodePostbackBody(body string) (*Postback, error) {
+	segments := strings.Split(body, PostbackSplitChar)
+	if segments[0] == "" {
+		return nil, fmt.Errorf("%w: missing action", ErrPostbackInvalid)
+	}
+
+	version, versioned := parsePostbackVersion(segments)
+	if !versioned {
+		return &Postback{
+			Action: segments[0],
+			Args:   segments[1:],
+			Params: map[string]string{},
+		}, nil
+	}
+
+	action, err := url.PathUnescape(segments[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrPostbackInvalid, err)
+	}
+
+	params := make(map[string]string, len(segments)-2)
+	for _, seg := range segments[2:] {
+		rawKey, rawValue, ok := strings.Cut(seg, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: malformed param %q", ErrPostbackInvalid, seg)
+		}
+		key, err := url.PathUnescape(rawKey)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrPostbackInvalid, err)
+		}
+		value, err := url.PathUnescape(rawValue)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrPostbackInvalid, err)
+		}
+		params[key] = value
+	}
+
+	return &Postback{
+		Action:  action,
+		Version: version,
+		Params:  params,
+	}, nil
+}
+
+// parsePostbackVersion reports the version from a "vN" second segment.
+func parsePostbackVersion(segments []string) (int, bool) {
+	if len(segments) < 2 {
+		return 0, false
+	}
+	raw, ok := strings.CutPrefix(segments[1], "v")
+	if !ok {
+		return 0, false
+	}
+	version, err := strconv.Atoi(raw)
+	if err != nil || version < 1 {
+		return 0, false
+	}
+	return version, true
+}
@@ -0,0 +1,49 @@
+package bot
+
+import "strings"
+
+// Legacy postback formats, the format label of ntpu_postback_legacy_total.
+//
+// Buttons stay tappable in chat history long after a release changes what
+// they send, so a format is kept decodable for at least 90 days after the
+// last release that emitted it, and until the metric shows no more hits.
+const (
+	// LegacyPostbackUnprefixed is "action$arg" without the "module:" prefix,
+	// routed by the module's ModuleInfo.LegacyActions.
+	LegacyPostbackUnprefixed = "unprefixed"
+	// LegacyPostbackPositional is "module:action$arg1$arg2" from before
+	// PostbackVersion 1, reaching a module whose ModuleInfo.TypedPostbacks is set.
+	LegacyPostbackPositional = "positional"
+)
+
+// resolvePostback finds the module data is for. Unprefixed payloads of older
+// releases are prefixed with the module claiming their action. Returns the
+// module, the data to hand it, and the legacy format ("" for current data).
+func (r *Registry) resolvePostback(data string) (*module, string, string) {
+	if pb, err := ParsePostback(data); err == nil {
+		if m := r.lookup(pb.Module); m != nil {
+			return m, data, positionalFormat(m, data)
+		}
+	}
+
+	action, _, _ := strings.Cut(data, PostbackSplitChar)
+	r.mu.RLock()
+	m := r.legacyActions[action]
+	r.mu.RUnlock()
+	if m == nil {
+		return nil, "", ""
+	}
+	return m, m.info.Name + ":" + data, LegacyPostbackUnprefixed
+}
+
+// positionalFormat reports LegacyPostbackPositional for a positional payload
+// sent to a module that only emits typed postbacks.
+func positionalFormat(m *module, data string) string {
+	if !m.info.TypedPostbacks {
+		return ""
+	}
+	if pb, err := DecodePostback(data); err == nil && pb.Version == 0 {
+		return LegacyPostbackPositional
+	}
+	return ""
+}
@@ -0,0 +1,76 @@
+package bot
+
+import (
+	"context"
+	"strings"
+
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+// PostbackHandlerFunc handles a decoded postback action.
+type PostbackHandlerFunc func(ctx context.Context, pb *Postback) []messaging_api.MessageInterface
+
+// PostbackRouter dispatches a module's postback data to per-action handlers.
+// It replaces ad-hoc prefix checks inside HandlePostback implementations:
+//
+//	router := bot.NewPostbackRouter("course").
+//		Handle("uid", h.postbackUID).
+//		Handle("授課課程", h.postbackTeacherLegacy)
+//	return router.Dispatch(ctx, data)
+//
+// Both versioned and legacy payloads are routed by their action name.
+type PostbackRouter struct {
+	module   string
+	routes   map[string]PostbackHandlerFunc
+	fallback PostbackHandlerFunc
+}
+
+// NewPostbackRouter creates a router for the given module name.
+func NewPostbackRouter(module string) *PostbackRouter {
+	return &PostbackRouter{
+		module: module,
+		routes: make(map[string]PostbackHandlerFunc),
+	}
+}
+
+// Handle registers a handler for an action and returns the router for chaining.
+// Registering the same action twice replaces the previous handler.
+func (r *PostbackRouter) Handle(action string, fn PostbackHandlerFunc) *PostbackRouter {
+	r.routes[action] = fn
+	return r
+}
+
+// Fallback registers a handler for actions without an explicit route.
+// Useful for legacy payloads whose action segment is a value (e.g., "course:1131U0001").
+func (r *PostbackRouter) Fallback(fn PostbackHandlerFunc) *PostbackRouter {
+	r.fallback = fn
+	return r
+}
+
+// Dispatch decodes data and invokes the matching handler.
+// The "module:" prefix is optional since tests and older callers pass bare payloads.
+// Returns an empty slice when data is malformed or no route matches.
+func (r *PostbackRouter) Dispatch(ctx context.Context, data string) []messaging_api.MessageInterface {
+	pb, ok := r.Decode(data)
+	if !ok {
+		return []messaging_api.MessageInterface{}
+	}
+
+	if fn, ok := r.routes[pb.Action]; ok {
+		return fn(ctx, pb)
+	}
+	if r.fallback != nil {
+		return r.fallback(ctx, pb)
+	}
+	return []messaging_api.MessageInterface{}
+}
+
+// Decode parses data as a postback for this router's module.
+func (r *PostbackRouter) Decode(data string) (*Postback, bool) {
+	body := strings.TrimPrefix(data, r.module+":")
+	pb, err := DecodePostback(r.module + ":" + body)
+	if err != nil {
+		return nil, false
+	}
+	return pb, true
+}
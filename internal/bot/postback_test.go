@@ -0,0 +1,205 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+func TestPostbackEncodeDecode(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		pb     *Postback
+		want   string
+		params map[string]string
+	}{
+		{
+			name:   "No params",
+			pb:     NewPostback("id", "兇"),
+			want:   "id:兇$v1",
+			params: map[string]string{},
+		},
+		{
+			name:   "Params are sorted by key",
+			pb:     NewPostback("id", "dept").With("year", "113").With("code", "85"),
+			want:   "id:dept$v1$code=85$year=113",
+			params: map[string]string{"code": "85", "year": "113"},
+		},
+		{
+			name:   "Reserved characters are escaped",
+			pb:     NewPostback("course", "teacher").With("name", "A$B=C%D"),
+			want:   "course:teacher$v1$name=A%24B%3DC%25D",
+			params: map[string]string{"name": "A$B=C%D"},
+		},
+		{
+			name:   "CJK values are kept as-is",
+			pb:     NewPostback("course", "teacher").With("name", "王小明"),
+			want:   "course:teacher$v1$name=王小明",
+			params: map[string]string{"name": "王小明"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			data, err := tt.pb.Encode()
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if data != tt.want {
+				t.Errorf("Encode() = %q, want %q", data, tt.want)
+			}
+
+			got, err := DecodePostback(data)
+			if err != nil {
+				t.Fatalf("DecodePostback() error = %v", err)
+			}
+			if got.Module != tt.pb.Module || got.Action != tt.pb.Action {
+				t.Errorf("DecodePostback() = %s:%s, want %s:%s", got.Module, got.Action, tt.pb.Module, tt.pb.Action)
+			}
+			if got.Version != PostbackVersion {
+				t.Errorf("DecodePostback() version = %d, want %d", got.Version, PostbackVersion)
+			}
+			if len(got.Params) != len(tt.params) {
+				t.Fatalf("DecodePostback() params = %v, want %v", got.Params, tt.params)
+			}
+			for k, v := range tt.params {
+				if got.Get(k) != v {
+					t.Errorf("Get(%q) = %q, want %q", k, got.Get(k), v)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodePostback_Legacy(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		data   string
+		module string
+		action string
+		args   []string
+	}{
+		{"UID as action", "course:1131U0001", "course", "1131U0001", nil},
+		{"Teacher courses", "course:授課課程$王教授", "course", "授課課程", []string{"王教授"}},
+		{"Department with year", "id:85$113", "id", "85", []string{"113"}},
+		{"Extra args", "id:a$b$c", "id", "a", []string{"b", "c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			pb, err := DecodePostback(tt.data)
+			if err != nil {
+				t.Fatalf("DecodePostback(%q) error = %v", tt.data, err)
+			}
+			if pb.Version != 0 {
+				t.Errorf("Version = %d, want 0 for legacy payload", pb.Version)
+			}
+			if pb.Module != tt.module || pb.Action != tt.action {
+				t.Errorf("got %s:%s, want %s:%s", pb.Module, pb.Action, tt.module, tt.action)
+			}
+			if len(pb.Args) != len(tt.args) {
+				t.Fatalf("Args = %v, want %v", pb.Args, tt.args)
+			}
+			for i, want := range tt.args {
+				if pb.Arg(i) != want {
+					t.Errorf("Arg(%d) = %q, want %q", i, pb.Arg(i), want)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodePostback_Invalid(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		data    string
+		wantErr error
+	}{
+		{"Missing separator", "invalid", ErrPostbackInvalid},
+		{"Missing module", ":action", ErrPostbackInvalid},
+		{"Missing action", "course:", ErrPostbackInvalid},
+		{"Malformed param", "course:uid$v1$oops", ErrPostbackInvalid},
+		{"Bad escape", "course:uid$v1$uid=%zz", ErrPostbackInvalid},
+		{"Too long", "course:" + strings.Repeat("a", config.LINEMaxPostbackDataLength), ErrPostbackTooLong},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := DecodePostback(tt.data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("DecodePostback(%q) error = %v, want %v", tt.data, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPostbackEncode_TooLong(t *testing.T) {
+	t.Parallel()
+	pb := NewPostback("course", "teacher").With("name", strings.Repeat("王", 100))
+
+	if _, err := pb.Encode(); !errors.Is(err, ErrPostbackTooLong) {
+		t.Errorf("Encode() error = %v, want ErrPostbackTooLong", err)
+	}
+	if got := pb.String(); got != "" {
+		t.Errorf("String() = %q, want empty string for oversized payload", got)
+	}
+}
+
+func TestPostbackRouter_Dispatch(t *testing.T) {
+	t.Parallel()
+
+	reply := func(text string) []messaging_api.MessageInterface {
+		return []messaging_api.MessageInterface{&messaging_api.TextMessage{Text: text}}
+	}
+	router := NewPostbackRouter("course").
+		Handle("uid", func(_ context.Context, pb *Postback) []messaging_api.MessageInterface {
+			return reply("uid:" + pb.Get("uid"))
+		}).
+		Handle("授課課程", func(_ context.Context, pb *Postback) []messaging_api.MessageInterface {
+			return reply("teacher:" + pb.Arg(0))
+		}).
+		Fallback(func(_ context.Context, pb *Postback) []messaging_api.MessageInterface {
+			return reply("fallback:" + pb.Action)
+		})
+
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"Typed payload", NewPostback("course", "uid").With("uid", "1131U0001").String(), "uid:1131U0001"},
+		{"Legacy payload", "course:授課課程$王教授", "teacher:王教授"},
+		{"Without module prefix", "授課課程$王教授", "teacher:王教授"},
+		{"Fallback", "course:1131U0001", "fallback:1131U0001"},
+		{"Malformed", "course:", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			msgs := router.Dispatch(context.Background(), tt.data)
+			if tt.want == "" {
+				if len(msgs) != 0 {
+					t.Errorf("Dispatch(%q) returned %d messages, want none", tt.data, len(msgs))
+				}
+				return
+			}
+			if len(msgs) != 1 {
+				t.Fatalf("Dispatch(%q) returned %d messages, want 1", tt.data, len(msgs))
+			}
+			if got := msgs[0].(*messaging_api.TextMessage).Text; got != tt.want {
+				t.Errorf("Dispatch(%q) = %q, want %q", tt.data, got, tt.want)
+			}
+		})
+	}
+}
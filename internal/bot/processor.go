@@ -14,6 +14,7 @@ import (
 	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
 	"github.com/garyellow/ntpu-linebot-go/internal/logger"
 	"github.com/garyellow/ntpu-linebot-go/internal/metrics"
+	"github.com/garyellow/ntpu-linebot-go/internal/msgtmpl"
 	"github.com/garyellow/ntpu-linebot-go/internal/ratelimit"
 	"github.com/garyellow/ntpu-linebot-go/internal/session"
 	"github.com/garyellow/ntpu-linebot-go/internal/sticker"
@@ -35,7 +36,17 @@ type Processor struct {
 	stickerManager *sticker.Manager
 	logger         *logger.Logger
 	metrics        *metrics.Metrics
-	sessionStore   *session.Store // Lightweight per-user conversation context
+	sessionStore   *session.Store   // Lightweight per-user conversation context
+	groupAnswers   *session.Answers // Recent keyword answers per group chat
+	adminUserIDs   map[string]bool
+	selfChecks     []SelfCheck        // Run by the admin 健康檢查 command
+	history        QueryRecorder      // Optional: per-user 最近查過 list
+	groupQueries   GroupQueryRecorder // Optional: per-group leaderboard counts
+	prefixes       TriggerPrefixes    // Optional: per-group keyword trigger prefixes
+	accountLinks   AccountLinkHandler // Optional: school account linking
+	exchanges      ExchangeRecorder   // Optional: each user's last exchange for 回報問題
+	inFlight       *coalescer         // Drops re-sent copies of a message being handled
+	texts          *msgtmpl.Store     // Message copy templates (English help)
 
 	// Configuration
 	webhookTimeout time.Duration
@@ -63,10 +74,51 @@ type ProcessorConfig struct {
 	StickerManager *sticker.Manager
 	Logger         *logger.Logger
 	Metrics        *metrics.Metrics
-	SessionStore   *session.Store // Optional: per-user conversation context
+	SessionStore   *session.Store   // Optional: per-user conversation context
+	GroupAnswers   *session.Answers // Optional: suppresses repeated group questions
 	BotConfig      *config.BotConfig
+	AdminUserIDs   []string           // Optional: LINE user IDs allowed to run chat admin commands
+	SelfChecks     []SelfCheck        // Optional: diagnostics for the 健康檢查 command (disabled if empty)
+	History        QueryRecorder      // Optional: records keyword queries from 1:1 chats
+	GroupQueries   GroupQueryRecorder // Optional: counts keyword queries from group chats
+	Prefixes       TriggerPrefixes    // Optional: lets groups require a prefix before keywords
+	AccountLinks   AccountLinkHandler // Optional: finishes school account linking
+	Exchanges      ExchangeRecorder   // Optional: keeps each user's last exchange for 回報問題
+	Texts          *msgtmpl.Store     // Optional: message templates (nil = embedded defaults)
 }
 
+// QueryRecorder stores a user's keyword queries so they can be re-run later.
+type QueryRecorder interface {
+	RecordQuery(ctx context.Context, userID, module, query string) error
+}
+
+// GroupQueryRecorder counts keyword queries per group chat (or room).
+type GroupQueryRecorder interface {
+	RecordGroupQuery(ctx context.Context, groupID, module, query string) error
+}
+
+// TriggerPrefixes looks up the prefix a group chat (or room) requires before
+// keyword commands. An empty prefix means none is required.
+type TriggerPrefixes interface {
+	TriggerPrefix(ctx context.Context, groupID string) (string, error)
+}
+
+// AccountLinkHandler finishes account linking when LINE reports that the
+// user confirmed (or failed) a link.
+type AccountLinkHandler interface {
+	HandleAccountLink(ctx context.Context, userID string, event webhook.AccountLinkEvent) []messaging_api.MessageInterface
+}
+
+// recordSkipModules are modules whose queries are not worth recording
+// (or, for "history" itself, would only echo the history commands). Account
+// and role commands are settings, and role grants carry other users' IDs.
+// "privacy" would otherwise record 刪除我的資料 right after the deletion.
+var recordSkipModules = []string{"usage", "history", "account", "role", "privacy"}
+
+// dedupSkipModules are modules whose group replies differ per asker or change
+// settings, so repeating the command must run it again.
+var dedupSkipModules = []string{"usage", "history", "leaderboard", "prefix"}
+
 // isNLUEnabled returns true if NLU intent parser is available.
 func (p *Processor) isNLUEnabled() bool {
 	return p.intentParser != nil && p.intentParser.IsEnabled()
@@ -83,8 +135,24 @@ func NewProcessor(cfg ProcessorConfig) *Processor {
 		logger:         cfg.Logger,
 		metrics:        cfg.Metrics,
 		sessionStore:   cfg.SessionStore,
+		groupAnswers:   cfg.GroupAnswers,
+		selfChecks:     cfg.SelfChecks,
+		history:        cfg.History,
+		groupQueries:   cfg.GroupQueries,
+		prefixes:       cfg.Prefixes,
+		accountLinks:   cfg.AccountLinks,
+		exchanges:      cfg.Exchanges,
+		adminUserIDs:   make(map[string]bool, len(cfg.AdminUserIDs)),
+		inFlight:       newCoalescer(),
+		texts:          cfg.Texts,
 		webhookTimeout: cfg.BotConfig.WebhookTimeout,
 	}
+	if p.texts == nil {
+		p.texts = msgtmpl.Default()
+	}
+	for _, id := range cfg.AdminUserIDs {
+		p.adminUserIDs[id] = true
+	}
 	p.initPrebuiltContent()
 	return p
 }
@@ -102,11 +170,11 @@ func (p *Processor) initPrebuiltContent() {
 		FallbackDispatchFailed: p.buildHelpBubble(FallbackDispatchFailed, nluEnabled),
 		FallbackUnknownModule:  p.buildHelpBubble(FallbackUnknownModule, nluEnabled),
 	}
-	p.prebuiltHelpQR = lineutil.NewQuickReply(lineutil.QuickReplyMainNav())
+	p.prebuiltHelpQR = lineutil.NewQuickReply(p.mainNavItems())
 
 	// Welcome
 	p.prebuiltWelcomeBubble = p.buildWelcomeBubble(nluEnabled)
-	p.prebuiltWelcomeQR = lineutil.NewQuickReply(lineutil.QuickReplyMainNav())
+	p.prebuiltWelcomeQR = lineutil.NewQuickReply(p.mainNavItems())
 
 	// LLM rate limit
 	p.prebuiltLLMRateLimitBubble = p.buildLLMRateLimitBubble()
@@ -114,12 +182,21 @@ func (p *Processor) initPrebuiltContent() {
 
 	// Instruction bubbles
 	p.prebuiltAIModeBubble = p.buildAIModeBubble()
-	p.prebuiltKeywordModeBubble = p.buildKeywordModeBubble(nluEnabled)
+	p.prebuiltKeywordModeBubble = p.buildKeywordModeBubble(nluEnabled, nil)
 	p.prebuiltTipsBubble = p.buildTipsBubble(nluEnabled)
 	p.prebuiltDataSourceBubble = p.buildDataSourceBubble()
 	p.prebuiltInstructionQR = lineutil.NewQuickReply(lineutil.QuickReplyMainFeatures())
 }
 
+// mainNavItems returns the main navigation, led by 最近查過 when query history is enabled.
+func (p *Processor) mainNavItems() []lineutil.QuickReplyItem {
+	items := lineutil.QuickReplyMainNav()
+	if p.history != nil {
+		items = append([]lineutil.QuickReplyItem{lineutil.QuickReplyHistoryAction()}, items...)
+	}
+	return items
+}
+
 // buildHelpBubble builds the FlexBubble for a help/fallback message given context.
 func (p *Processor) buildHelpBubble(ctx FallbackContext, nluEnabled bool) *messaging_api.FlexBubble {
 	var heroTitle, heroSubtext string
@@ -295,8 +372,46 @@ func (p *Processor) buildAIModeBubble() *messaging_api.FlexBubble {
 	return lineutil.NewFlexBubble(hero, nil, body, nil).FlexBubble
 }
 
+// keywordModeSection is one module's entry in the keyword mode instructions.
+type keywordModeSection struct {
+	module string
+	title  string
+	lines  []string
+}
+
+// keywordModeSections lists keyword examples per module, in display order.
+var keywordModeSections = []keywordModeSection{
+	{"course", "📚 課程查詢", []string{
+		"• 精確：課程 微積分 / 課程 王教授",
+		"• 智慧：找課 我想學程式語言",
+		"• 課號：U0001 或 1131U0001",
+	}},
+	{"program", "🧭 學程查詢", []string{
+		"• 列表：學程 或 所有學程",
+		"• 搜尋：學程 人工智慧",
+	}},
+	{"id", "🎓 學號查詢", []string{
+		"• 姓名：學號 王小明",
+		"• 科系：系 資工 / 系代碼 87",
+		"• 學年：學年 112",
+		"• 系代碼：學士班系代碼 / 碩士班系代碼",
+		"• 直接輸入：412345678",
+	}},
+	{"contact", "📞 聯絡資訊", []string{
+		"• 單位：聯絡 資工系",
+		"• 電話：電話 圖書館",
+		"• 信箱：信箱 教務處",
+		"• 緊急：緊急",
+	}},
+	{"usage", "📊 配額查詢", []string{
+		"• 查詢：配額 / 用量 / 額度",
+		"• 顯示：訊息額度與 AI 額度",
+	}},
+}
+
 // buildKeywordModeBubble builds the FlexBubble for keyword mode instruction.
-func (p *Processor) buildKeywordModeBubble(nluEnabled bool) *messaging_api.FlexBubble {
+// Sections of disabled modules are replaced by a maintenance notice.
+func (p *Processor) buildKeywordModeBubble(nluEnabled bool, disabled []ModuleInfo) *messaging_api.FlexBubble {
 	titleText := "📖 關鍵字模式"
 	if !nluEnabled {
 		titleText = "📖 使用說明"
@@ -306,33 +421,41 @@ func (p *Processor) buildKeywordModeBubble(nluEnabled bool) *messaging_api.FlexB
 		lineutil.NewFlexText("使用關鍵字進行查詢").WithSize("md").WithColor(lineutil.ColorHeroText).WithMargin("sm").FlexText,
 	).WithBackgroundColor(lineutil.ColorHeaderPrimary).WithPaddingAll("xl").WithPaddingBottom("lg")
 
-	body := lineutil.NewFlexBox("vertical",
-		lineutil.NewFlexText("📚 課程查詢").WithWeight("bold").WithColor(lineutil.ColorText).WithSize("sm").WithMargin("none").FlexText,
-		lineutil.NewFlexText("• 精確：課程 微積分 / 課程 王教授").WithSize("xs").WithColor(lineutil.ColorSubtext).WithMargin("sm").WithWrap(true).FlexText,
-		lineutil.NewFlexText("• 智慧：找課 我想學程式語言").WithSize("xs").WithColor(lineutil.ColorSubtext).WithMargin("xs").WithWrap(true).FlexText,
-		lineutil.NewFlexText("• 課號：U0001 或 1131U0001").WithSize("xs").WithColor(lineutil.ColorSubtext).WithMargin("xs").WithWrap(true).FlexText,
-		lineutil.NewFlexSeparator().WithMargin("md").FlexSeparator,
-		lineutil.NewFlexText("🧭 學程查詢").WithWeight("bold").WithColor(lineutil.ColorText).WithSize("sm").WithMargin("md").FlexText,
-		lineutil.NewFlexText("• 列表：學程 或 所有學程").WithSize("xs").WithColor(lineutil.ColorSubtext).WithMargin("sm").WithWrap(true).FlexText,
-		lineutil.NewFlexText("• 搜尋：學程 人工智慧").WithSize("xs").WithColor(lineutil.ColorSubtext).WithMargin("xs").WithWrap(true).FlexText,
-		lineutil.NewFlexSeparator().WithMargin("md").FlexSeparator,
-		lineutil.NewFlexText("🎓 學號查詢").WithWeight("bold").WithColor(lineutil.ColorText).WithSize("sm").WithMargin("md").FlexText,
-		lineutil.NewFlexText("• 姓名：學號 王小明").WithSize("xs").WithColor(lineutil.ColorSubtext).WithMargin("sm").WithWrap(true).FlexText,
-		lineutil.NewFlexText("• 科系：系 資工 / 系代碼 87").WithSize("xs").WithColor(lineutil.ColorSubtext).WithMargin("xs").WithWrap(true).FlexText,
-		lineutil.NewFlexText("• 學年：學年 112").WithSize("xs").WithColor(lineutil.ColorSubtext).WithMargin("xs").WithWrap(true).FlexText,
-		lineutil.NewFlexText("• 系代碼：學士班系代碼 / 碩士班系代碼").WithSize("xs").WithColor(lineutil.ColorSubtext).WithMargin("xs").WithWrap(true).FlexText,
-		lineutil.NewFlexText("• 直接輸入：412345678").WithSize("xs").WithColor(lineutil.ColorSubtext).WithMargin("xs").WithWrap(true).FlexText,
-		lineutil.NewFlexSeparator().WithMargin("md").FlexSeparator,
-		lineutil.NewFlexText("📞 聯絡資訊").WithWeight("bold").WithColor(lineutil.ColorText).WithSize("sm").WithMargin("md").FlexText,
-		lineutil.NewFlexText("• 單位：聯絡 資工系").WithSize("xs").WithColor(lineutil.ColorSubtext).WithMargin("sm").WithWrap(true).FlexText,
-		lineutil.NewFlexText("• 電話：電話 圖書館").WithSize("xs").WithColor(lineutil.ColorSubtext).WithMargin("xs").WithWrap(true).FlexText,
-		lineutil.NewFlexText("• 信箱：信箱 教務處").WithSize("xs").WithColor(lineutil.ColorSubtext).WithMargin("xs").WithWrap(true).FlexText,
-		lineutil.NewFlexText("• 緊急：緊急").WithSize("xs").WithColor(lineutil.ColorSubtext).WithMargin("xs").WithWrap(true).FlexText,
-		lineutil.NewFlexSeparator().WithMargin("md").FlexSeparator,
-		lineutil.NewFlexText("📊 配額查詢").WithWeight("bold").WithColor(lineutil.ColorText).WithSize("sm").WithMargin("md").FlexText,
-		lineutil.NewFlexText("• 查詢：配額 / 用量 / 額度").WithSize("xs").WithColor(lineutil.ColorSubtext).WithMargin("sm").WithWrap(true).FlexText,
-		lineutil.NewFlexText("• 顯示：訊息額度與 AI 額度").WithSize("xs").WithColor(lineutil.ColorSubtext).WithMargin("xs").WithWrap(true).FlexText,
-	).WithSpacing("none")
+	var contents []messaging_api.FlexComponentInterface
+	for _, section := range keywordModeSections {
+		if slices.ContainsFunc(disabled, func(m ModuleInfo) bool { return m.Name == section.module }) {
+			continue
+		}
+		titleMargin := "md"
+		if len(contents) == 0 {
+			titleMargin = "none"
+		} else {
+			contents = append(contents, lineutil.NewFlexSeparator().WithMargin("md").FlexSeparator)
+		}
+		contents = append(contents,
+			lineutil.NewFlexText(section.title).WithWeight("bold").WithColor(lineutil.ColorText).WithSize("sm").WithMargin(titleMargin).FlexText)
+		for i, line := range section.lines {
+			margin := "xs"
+			if i == 0 {
+				margin = "sm"
+			}
+			contents = append(contents,
+				lineutil.NewFlexText(line).WithSize("xs").WithColor(lineutil.ColorSubtext).WithMargin(margin).WithWrap(true).FlexText)
+		}
+	}
+
+	if len(disabled) > 0 {
+		names := make([]string, len(disabled))
+		for i, m := range disabled {
+			names[i] = m.DisplayName
+		}
+		contents = append(contents,
+			lineutil.NewFlexSeparator().WithMargin("md").FlexSeparator,
+			lineutil.NewFlexText("🚧 暫停服務："+strings.Join(names, "、")).WithSize("xs").WithColor(lineutil.ColorSubtext).WithMargin("md").WithWrap(true).FlexText,
+		)
+	}
+
+	body := lineutil.NewFlexBox("vertical", contents...).WithSpacing("none")
 
 	return lineutil.NewFlexBubble(hero, nil, body, nil).FlexBubble
 }
@@ -432,6 +555,7 @@ func (p *Processor) injectContextValues(ctx context.Context, source webhook.Sour
 
 // ProcessMessage handles a text message event.
 func (p *Processor) ProcessMessage(ctx context.Context, event webhook.MessageEvent) ([]messaging_api.MessageInterface, error) {
+	start := time.Now()
 	// Inject context values for tracing and logging
 	ctx = p.injectContextValues(ctx, event.Source)
 
@@ -502,18 +626,82 @@ func (p *Processor) ProcessMessage(ctx context.Context, event webhook.MessageEve
 		return []messaging_api.MessageInterface{msg}, nil
 	}
 
+	// Strip @Bot mentions (and the group's trigger prefix) before routing, so
+	// "@北大小幫手 課程 微積分" matches like "課程 微積分".
+	// Keep the raw text for handlers that parse symbols (e.g., course filters)
+	rawText := removeBotMentions(text, textMsg.Mention)
+	if !IsPersonalChat(event.Source) && !IsBotMentioned(textMsg) {
+		var ok bool
+		if rawText, ok = p.applyTriggerPrefix(ctx, GetChatID(event.Source), rawText); !ok {
+			return nil, nil // Group conversation without the trigger prefix
+		}
+	}
+
 	// Sanitize input: normalize whitespace, remove punctuation
-	text = stringutil.SanitizeText(text)
+	text = stringutil.SanitizeText(rawText)
 	if len(text) == 0 {
+		if IsBotMentioned(textMsg) {
+			// Bare @Bot mention
+			msgs := p.getHelpMessage(FallbackGeneric)
+			lineutil.SetQuoteTokenToFirst(msgs, ctxutil.GetQuoteToken(ctx))
+			return msgs, nil
+		}
 		return nil, nil // Empty after sanitization
 	}
 
+	// English messages get English copy; common English questions are
+	// rewritten into the keyword command that answers them
+	if IsEnglishText(text) {
+		ctx = ctxutil.WithLang(ctx, msgtmpl.English)
+		if rewritten, ok := RewriteEnglishQuery(text); ok {
+			p.logger.WithField("rewritten", rewritten).DebugContext(ctx, "Rewrote English query")
+			text, rawText = rewritten, rewritten
+		}
+	}
+
+	// The same question asked again in a group moments later gets a pointer
+	if msgs := p.duplicateAnswer(ctx, event.Source, text); msgs != nil {
+		return msgs, nil
+	}
+
+	// A copy re-sent while the first is still being handled gets no reply of
+	// its own, so slow scrapes aren't repeated. In a group the copy may come
+	// from another member, who waits for the first answer and is pointed to
+	// it, or gets a reply of their own when that answer is not shared.
+	chatID := GetChatID(event.Source)
+	if p.inFlight != nil {
+		for !p.inFlight.begin(chatID, text) {
+			if !p.awaitInFlight(ctx, event.Source, text) {
+				p.logger.DebugContext(ctx, "Dropping re-sent copy of a message being handled")
+				return nil, nil
+			}
+			if msgs := p.duplicateAnswer(ctx, event.Source, text); msgs != nil {
+				return msgs, nil
+			}
+		}
+		defer p.inFlight.end(chatID, text)
+	}
+	if IsPersonalChat(event.Source) {
+		p.recordReformulation(ctx)
+	}
+
 	// Check for help keywords FIRST (before dispatching to bot modules)
 	if slices.ContainsFunc(helpKeywords, func(k string) bool {
 		return strings.EqualFold(text, k)
 	}) {
 		p.logger.DebugContext(ctx, "User requested help/instruction")
 		msgs := p.getDetailedInstructionMessages()
+		if ctxutil.GetLang(ctx) == msgtmpl.English {
+			// The instruction bubbles are Chinese; lead with the English summary
+			msgs = append(p.englishHelpMessages(), msgs...)
+		}
+		lineutil.SetQuoteTokenToFirst(msgs, ctxutil.GetQuoteToken(ctx))
+		return msgs, nil
+	}
+
+	// Admin self-test; non-admins fall through so the command isn't revealed
+	if text == selfTestKeyword && len(p.selfChecks) > 0 && p.isAdmin(ctxutil.GetUserID(ctx)) {
+		msgs := p.handleSelfTest(ctx)
 		lineutil.SetQuoteTokenToFirst(msgs, ctxutil.GetQuoteToken(ctx))
 		return msgs, nil
 	}
@@ -524,7 +712,19 @@ func (p *Processor) ProcessMessage(ctx context.Context, event webhook.MessageEve
 	defer cancel()
 
 	// Dispatch to appropriate bot module based on CanHandle
-	if msgs, handlerName := p.registry.DispatchMessage(processCtx, text); len(msgs) > 0 {
+	var matched []string
+	if p.metrics != nil {
+		matched = p.registry.MatchingModules(text)
+	}
+	msgs, handlerName := p.registry.DispatchMessage(ctxutil.WithRawText(processCtx, rawText), text)
+	if len(msgs) == 0 {
+		handlerName = ""
+	}
+	// Group chatter that is neither a keyword nor addressed to the bot is not routed
+	if p.metrics != nil && (len(msgs) > 0 || IsPersonalChat(event.Source) || IsBotMentioned(textMsg)) {
+		p.metrics.RecordIntentRouting(matched, handlerName)
+	}
+	if len(msgs) > 0 {
 		if p.metrics != nil {
 			p.metrics.RecordIntent(handlerName, "", "keyword")
 		}
@@ -538,18 +738,181 @@ func (p *Processor) ProcessMessage(ctx context.Context, event webhook.MessageEve
 				Params: map[string]string{"query": text},
 			})
 		}
+		p.recordQuery(processCtx, event.Source, handlerName, text)
+		p.recordGroupAnswer(processCtx, event.Source, handlerName, text)
+		p.recordExchange(processCtx, event.Source, Exchange{
+			Query: text, Module: handlerName, Source: "keyword", Duration: time.Since(start),
+		}, msgs)
 		lineutil.SetQuoteTokenToFirst(msgs, ctxutil.GetQuoteToken(processCtx))
 		return msgs, nil
 	}
 
 	// No handler matched - try NLU if available
-	msgs, err := p.handleUnmatchedMessage(processCtx, event.Source, textMsg, text)
+	note := &exchangeNote{}
+	msgs, err := p.handleUnmatchedMessage(context.WithValue(processCtx, exchangeNoteKey{}, note), event.Source, textMsg, text)
 	if err == nil && len(msgs) > 0 {
+		routed := "fallback"
+		if p.isNLUEnabled() {
+			routed = "nlu"
+		}
+		p.recordExchange(processCtx, event.Source, Exchange{
+			Query: text, Module: note.module, Source: routed, Duration: time.Since(start),
+		}, msgs)
 		lineutil.SetQuoteTokenToFirst(msgs, ctxutil.GetQuoteToken(processCtx))
 	}
 	return msgs, err
 }
 
+// applyTriggerPrefix strips the group's trigger prefix from text. It reports
+// false when the group set a prefix and text does not start with it, so
+// ordinary group conversation is not matched against keywords. A failed
+// lookup is treated as no prefix.
+func (p *Processor) applyTriggerPrefix(ctx context.Context, groupID, text string) (string, bool) {
+	if p.prefixes == nil {
+		return text, true
+	}
+	prefix, err := p.prefixes.TriggerPrefix(ctx, groupID)
+	if err != nil {
+		p.logger.WithError(err).WarnContext(ctx, "Failed to look up group trigger prefix")
+		return text, true
+	}
+	if prefix == "" {
+		return text, true
+	}
+	return trimTriggerPrefix(text, prefix)
+}
+
+// duplicateAnswer returns a short 剛剛回答過囉 reply when text was answered in
+// the same group within config.GroupAnswerDedupWindow, or nil to dispatch it.
+// The reply quotes the first asker's message, which the full answer follows.
+func (p *Processor) duplicateAnswer(ctx context.Context, source webhook.SourceInterface, text string) []messaging_api.MessageInterface {
+	if p.groupAnswers == nil || IsPersonalChat(source) {
+		return nil
+	}
+	answer, ok := p.groupAnswers.Recent(GetChatID(source), text)
+	if !ok {
+		return nil
+	}
+
+	sender := lineutil.GetSender("NTPU 小工具", p.stickerManager)
+	msg := lineutil.NewTextMessageWithConsistentSender(
+		fmt.Sprintf("剛剛回答過囉 ⤴️\n\n往上看「%s」的結果", answer.Query),
+		sender,
+	)
+	quoteToken := answer.QuoteToken
+	if quoteToken == "" {
+		quoteToken = ctxutil.GetQuoteToken(ctx)
+	}
+	lineutil.SetQuoteToken(msg, quoteToken)
+	p.logger.WithModule(answer.Module).DebugContext(ctx, "Suppressed duplicate group answer")
+	return []messaging_api.MessageInterface{msg}
+}
+
+// awaitInFlight waits up to the webhook timeout for the in-flight copy of a
+// group message to be processed. It reports false in 1:1 chats, where the
+// first copy's reply answers the re-sent one, and when the wait ends early.
+func (p *Processor) awaitInFlight(ctx context.Context, source webhook.SourceInterface, text string) bool {
+	if IsPersonalChat(source) {
+		return false
+	}
+	done := p.inFlight.wait(GetChatID(source), text)
+	if done == nil {
+		return true
+	}
+	timer := time.NewTimer(p.webhookTimeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// recordGroupAnswer remembers a keyword answer in a group chat so repeats
+// within config.GroupAnswerDedupWindow can be pointed back to it.
+func (p *Processor) recordGroupAnswer(ctx context.Context, source webhook.SourceInterface, module, text string) {
+	if p.groupAnswers == nil || IsPersonalChat(source) || slices.Contains(dedupSkipModules, module) {
+		return
+	}
+	p.groupAnswers.Record(GetChatID(source), text, session.Answer{
+		Module:     module,
+		UserID:     ctxutil.GetUserID(ctx),
+		QuoteToken: ctxutil.GetQuoteToken(ctx),
+	})
+}
+
+// EraseUser drops the messages the processor holds in memory for userID:
+// re-sent copies being coalesced in the 1:1 chat and the group answers the
+// user asked for. It makes the processor a privacy.Eraser; ctx is unused.
+func (p *Processor) EraseUser(ctx context.Context, userID string) (int64, error) {
+	var n int64
+	if p.inFlight != nil {
+		n += int64(p.inFlight.erase(userID))
+	}
+	if p.groupAnswers != nil {
+		erased, err := p.groupAnswers.EraseUser(ctx, userID)
+		if err != nil {
+			return n, err
+		}
+		n += erased
+	}
+	return n, nil
+}
+
+// recordReformulation counts a 1:1 text message sent within
+// config.IntentReformulationWindow of the last handled one as the user
+// reformulating it, attributed to the module that replied. Only the module
+// name is recorded.
+func (p *Processor) recordReformulation(ctx context.Context) {
+	if p.metrics == nil || p.sessionStore == nil {
+		return
+	}
+	intents := p.sessionStore.GetRecentIntents(ctxutil.GetUserID(ctx))
+	if len(intents) == 0 {
+		return
+	}
+	last := intents[len(intents)-1]
+	if time.Since(last.Time) > config.IntentReformulationWindow {
+		return
+	}
+	source := "nlu"
+	if last.Action == "keyword" {
+		source = "keyword"
+	}
+	p.metrics.RecordIntentReformulation(last.Module, source)
+}
+
+// recordQuery adds a keyword query to the user's 最近查過 list (1:1 chats) or
+// the group's leaderboard counts (groups and rooms). Failures are logged and
+// never affect the reply.
+func (p *Processor) recordQuery(ctx context.Context, source webhook.SourceInterface, module, text string) {
+	if module == "" || slices.Contains(recordSkipModules, module) {
+		return
+	}
+
+	if IsPersonalChat(source) {
+		userID := ctxutil.GetUserID(ctx)
+		if p.history == nil || userID == "" {
+			return
+		}
+		if err := p.history.RecordQuery(ctx, userID, module, text); err != nil {
+			p.logger.WithError(err).WithField("module", module).WarnContext(ctx, "Failed to record query history")
+		}
+		return
+	}
+
+	groupID := GetChatID(source)
+	if p.groupQueries == nil || groupID == "" {
+		return
+	}
+	if err := p.groupQueries.RecordGroupQuery(ctx, groupID, module, text); err != nil {
+		p.logger.WithError(err).WithField("module", module).WarnContext(ctx, "Failed to record group query")
+	}
+}
+
 // ProcessPostback handles a postback event.
 func (p *Processor) ProcessPostback(ctx context.Context, event webhook.PostbackEvent) ([]messaging_api.MessageInterface, error) {
 	// Inject context values for tracing and logging
@@ -633,6 +996,25 @@ func (p *Processor) ProcessJoin(ctx context.Context, event webhook.JoinEvent) ([
 	return []messaging_api.MessageInterface{welcomeMsg}, nil
 }
 
+// ProcessAccountLink handles an account link event. Returns no messages
+// when account linking is disabled.
+func (p *Processor) ProcessAccountLink(ctx context.Context, event webhook.AccountLinkEvent) ([]messaging_api.MessageInterface, error) {
+	ctx = p.injectContextValues(ctx, event.Source)
+	if p.accountLinks == nil {
+		return nil, nil
+	}
+
+	source, ok := event.Source.(webhook.UserSource)
+	if !ok || source.UserId == "" {
+		return nil, nil
+	}
+	p.logger.InfoContext(ctx, "Account link event received")
+
+	processCtx, cancel := context.WithTimeout(ctxutil.PreserveTracing(ctx), p.webhookTimeout)
+	defer cancel()
+	return p.accountLinks.HandleAccountLink(processCtx, source.UserId, event), nil
+}
+
 // buildWelcomeFlexMessage creates a structured welcome message for new users.
 func (p *Processor) buildWelcomeFlexMessage(sender *messaging_api.Sender) messaging_api.MessageInterface {
 	msg := lineutil.NewFlexMessage("歡迎使用 NTPU 小工具", p.prebuiltWelcomeBubble)
@@ -652,18 +1034,7 @@ func (p *Processor) handleUnmatchedMessage(ctx context.Context, source webhook.S
 			// No @Bot mention in group - silently ignore
 			return nil, nil
 		}
-		// Remove @Bot mentions from ORIGINAL text for NLU processing
-		if textMsg.Mention != nil {
-			mentionlessText := removeBotMentions(textMsg.Text, textMsg.Mention)
-			if mentionlessText == "" {
-				return p.getHelpMessage(FallbackGeneric), nil
-			}
-			// Apply same sanitization as original text processing
-			sanitizedText = stringutil.SanitizeText(mentionlessText)
-			if sanitizedText == "" {
-				return p.getHelpMessage(FallbackGeneric), nil
-			}
-		}
+		// sanitizedText had the @Bot mentions removed in ProcessMessage
 	}
 
 	// Try NLU if available
@@ -673,6 +1044,9 @@ func (p *Processor) handleUnmatchedMessage(ctx context.Context, source webhook.S
 	}
 
 	// NLU not available - return help message with context
+	if ctxutil.GetLang(ctx) == msgtmpl.English {
+		return p.englishHelpMessages(), nil
+	}
 	return p.getHelpMessage(FallbackNLUDisabled), nil
 }
 
@@ -694,6 +1068,7 @@ func (p *Processor) handleWithNLU(ctx context.Context, text string, source webho
 		}
 	}
 
+	lineutil.ShowLoading(ctx) // LLM round trip takes a few seconds
 	result, err := p.intentParser.Parse(ctx, nluInput)
 
 	if err != nil {
@@ -736,6 +1111,7 @@ func (p *Processor) dispatchIntent(ctx context.Context, result *genai.ParseResul
 	if result.Module == "help" {
 		return p.getDetailedInstructionMessages(), nil
 	}
+	noteExchangeModule(ctx, result.Module)
 
 	// Handle direct_reply from NLU (used for greetings, clarifications, off-topic queries)
 	if result.Module == "direct_reply" {
@@ -750,6 +1126,10 @@ func (p *Processor) dispatchIntent(ctx context.Context, result *genai.ParseResul
 		return []messaging_api.MessageInterface{msg}, nil
 	}
 
+	if msgs := p.registry.DisabledReply(ctx, result.Module); len(msgs) > 0 {
+		return msgs, nil
+	}
+
 	handler := p.registry.GetHandler(result.Module)
 	if handler == nil {
 		p.logger.WithField("module", result.Module).WarnContext(ctx, "Unknown module from NLU")
@@ -856,6 +1236,18 @@ func (p *Processor) getHelpMessage(context FallbackContext) []messaging_api.Mess
 	return []messaging_api.MessageInterface{msg}
 }
 
+// englishHelpMessages returns the English usage summary, sent instead of
+// the Chinese fallback bubble when an English message matches nothing.
+func (p *Processor) englishHelpMessages() []messaging_api.MessageInterface {
+	sender := lineutil.GetSender("NTPU 小工具", p.stickerManager)
+	msg := lineutil.NewTextMessageWithConsistentSender(
+		p.texts.Text(msgtmpl.EnglishHelp, msgtmpl.Data{"NLU": p.isNLUEnabled()}),
+		sender,
+	)
+	msg.QuickReply = lineutil.NewQuickReply(lineutil.QuickReplyMainNavCompact())
+	return []messaging_api.MessageInterface{msg}
+}
+
 // getDetailedInstructionMessages returns detailed instruction messages
 // Total messages: 3 or 4 Flex Messages - within LINE's 5-message limit
 func (p *Processor) getDetailedInstructionMessages() []messaging_api.MessageInterface {
@@ -896,8 +1288,15 @@ func (p *Processor) buildAIModeFlexMessage(sender *messaging_api.Sender) messagi
 }
 
 // buildKeywordModeFlexMessage creates a Flex Message for keyword mode instructions.
+// The pre-built bubble is used unless some modules are disabled at runtime.
 func (p *Processor) buildKeywordModeFlexMessage(sender *messaging_api.Sender) messaging_api.MessageInterface {
-	msg := lineutil.NewFlexMessage("關鍵字模式說明", p.prebuiltKeywordModeBubble)
+	bubble := p.prebuiltKeywordModeBubble
+	if p.registry != nil {
+		if disabled := p.registry.DisabledModules(); len(disabled) > 0 {
+			bubble = p.buildKeywordModeBubble(p.isNLUEnabled(), disabled)
+		}
+	}
+	msg := lineutil.NewFlexMessage("關鍵字模式說明", bubble)
 	if sender != nil {
 		msg.Sender = sender
 	}
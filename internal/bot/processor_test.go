@@ -0,0 +1,275 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
+	"github.com/garyellow/ntpu-linebot-go/internal/ctxutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/metrics"
+	"github.com/garyellow/ntpu-linebot-go/internal/ratelimit"
+	"github.com/garyellow/ntpu-linebot-go/internal/session"
+	"github.com/garyellow/ntpu-linebot-go/internal/sticker"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+	"github.com/line/line-bot-sdk-go/v8/linebot/webhook"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type fakeRecorder struct {
+	queries []string // "user:query" or "group:query"
+}
+
+func (r *fakeRecorder) RecordQuery(_ context.Context, _, _, query string) error {
+	r.queries = append(r.queries, "user:"+query)
+	return nil
+}
+
+func (r *fakeRecorder) RecordGroupQuery(_ context.Context, _, _, query string) error {
+	r.queries = append(r.queries, "group:"+query)
+	return nil
+}
+
+func TestProcessor_RecordQuery(t *testing.T) {
+	t.Parallel()
+
+	personal := webhook.UserSource{UserId: "U1"}
+	group := webhook.GroupSource{GroupId: "C1", UserId: "U1"}
+
+	tests := []struct {
+		name   string
+		source webhook.SourceInterface
+		module string
+		want   []string
+	}{
+		{"personal chat", personal, "course", []string{"user:課程 微積分"}},
+		{"group chat", group, "course", []string{"group:課程 微積分"}},
+		{"usage module", personal, "usage", nil},
+		{"history module", personal, "history", nil},
+		{"no module", group, "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rec := &fakeRecorder{}
+			p := &Processor{history: rec, groupQueries: rec, logger: logger.New("error")}
+			ctx := ctxutil.WithUserID(context.Background(), GetUserID(tt.source))
+
+			p.recordQuery(ctx, tt.source, tt.module, "課程 微積分")
+			if !slices.Equal(rec.queries, tt.want) {
+				t.Errorf("recorded %v, want %v", rec.queries, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessor_RecordReformulation(t *testing.T) {
+	t.Parallel()
+	m := metrics.New(prometheus.NewRegistry())
+	store := session.NewStore(3, time.Minute)
+	p := &Processor{metrics: m, sessionStore: store}
+
+	p.recordReformulation(ctxutil.WithUserID(context.Background(), "U1")) // No previous message
+	store.Record("U1", session.Intent{Module: "course", Action: "keyword"})
+	p.recordReformulation(ctxutil.WithUserID(context.Background(), "U1"))
+
+	counters, err := m.Counters()
+	if err != nil {
+		t.Fatalf("Counters() error = %v", err)
+	}
+	samples := counters["ntpu_intent_reformulations_total"]
+	if len(samples) != 1 || samples[0].Labels["module"] != "course" || samples[0].Labels["source"] != "keyword" || samples[0].Value != 1 {
+		t.Errorf("ntpu_intent_reformulations_total = %+v, want course/keyword 1", samples)
+	}
+}
+
+func TestProcessor_DuplicateAnswer(t *testing.T) {
+	t.Parallel()
+	log := logger.New("error")
+	p := &Processor{
+		groupAnswers:   session.NewAnswers(time.Minute),
+		logger:         log,
+		stickerManager: sticker.NewManager(nil, nil, log),
+	}
+	group := webhook.GroupSource{GroupId: "C1", UserId: "U1"}
+	personal := webhook.UserSource{UserId: "U1"}
+	ctx := ctxutil.WithQuoteToken(context.Background(), "q2")
+
+	p.recordGroupAnswer(ctxutil.WithQuoteToken(context.Background(), "q1"), group, "course", "課程 微積分")
+	p.recordGroupAnswer(ctx, group, "usage", "配額")
+	p.recordGroupAnswer(ctx, personal, "course", "課程 線性代數")
+
+	msgs := p.duplicateAnswer(ctx, group, "課程 微積分")
+	if len(msgs) != 1 {
+		t.Fatalf("duplicateAnswer() = %d messages, want 1", len(msgs))
+	}
+	msg, ok := msgs[0].(*messaging_api.TextMessageV2)
+	if !ok {
+		t.Fatalf("message is %T, want *TextMessageV2", msgs[0])
+	}
+	if !strings.Contains(msg.Text, "剛剛回答過囉") || msg.QuoteToken != "q1" {
+		t.Errorf("reply = %q quoting %q, want 剛剛回答過囉 quoting q1", msg.Text, msg.QuoteToken)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		source webhook.SourceInterface
+		text   string
+	}{
+		{"per-user module", group, "配額"},
+		{"personal chat", personal, "課程 線性代數"},
+		{"other query", group, "課程 線性代數"},
+	} {
+		if msgs := p.duplicateAnswer(ctx, tt.source, tt.text); msgs != nil {
+			t.Errorf("%s: duplicateAnswer() = %v, want nil", tt.name, msgs)
+		}
+	}
+}
+
+// gatedHandler holds each message until release is closed, signalling
+// started when it begins.
+type gatedHandler struct {
+	stubHandler
+	started chan struct{}
+	release chan struct{}
+	calls   atomic.Int32
+}
+
+func (g *gatedHandler) HandleMessage(ctx context.Context, text string) []messaging_api.MessageInterface {
+	g.calls.Add(1)
+	g.started <- struct{}{}
+	<-g.release
+	return g.stubHandler.HandleMessage(ctx, text)
+}
+
+// newCoalesceProcessor returns a processor dispatching every message to h.
+func newCoalesceProcessor(t *testing.T, h Handler) *Processor {
+	t.Helper()
+	log := logger.New("error")
+	limiter := ratelimit.NewKeyedLimiter(ratelimit.KeyedConfig{Name: "user", Burst: 10, RefillRate: 1, CleanupPeriod: time.Minute})
+	t.Cleanup(limiter.Stop)
+	registry := NewRegistry()
+	registry.Register(h)
+	return NewProcessor(ProcessorConfig{
+		Registry:       registry,
+		UserLimiter:    limiter,
+		StickerManager: sticker.NewManager(nil, nil, log),
+		Logger:         log,
+		GroupAnswers:   session.NewAnswers(time.Minute),
+		BotConfig:      &config.BotConfig{WebhookTimeout: 5 * time.Second},
+	})
+}
+
+// askCourse sends 「課程 微積分」 from source to p.
+func askCourse(t *testing.T, p *Processor, source webhook.SourceInterface, quoteToken string) []messaging_api.MessageInterface {
+	t.Helper()
+	// Unmarshaled so the message reports its type, as in production
+	message, err := webhook.UnmarshalMessageContent(fmt.Appendf(nil, `{"type":"text","id":"1","text":"課程 微積分","quoteToken":%q}`, quoteToken))
+	if err != nil {
+		t.Errorf("UnmarshalMessageContent() error = %v", err)
+		return nil
+	}
+	msgs, err := p.ProcessMessage(context.Background(), webhook.MessageEvent{Source: source, Message: message})
+	if err != nil {
+		t.Errorf("ProcessMessage(%s) error = %v", quoteToken, err)
+	}
+	return msgs
+}
+
+func TestProcessor_GroupDuplicateWhileInFlight(t *testing.T) {
+	t.Parallel()
+	h := &gatedHandler{stubHandler: stubHandler{name: "course"}, started: make(chan struct{}, 1), release: make(chan struct{})}
+	p := newCoalesceProcessor(t, h)
+
+	ask := func(userID, quoteToken string) []messaging_api.MessageInterface {
+		return askCourse(t, p, webhook.GroupSource{GroupId: "C1", UserId: userID}, quoteToken)
+	}
+	wantPointer := func(name string, msgs []messaging_api.MessageInterface) {
+		t.Helper()
+		if len(msgs) != 1 {
+			t.Fatalf("%s: got %d messages, want the 剛剛回答過囉 pointer", name, len(msgs))
+		}
+		msg, ok := msgs[0].(*messaging_api.TextMessageV2)
+		if !ok || !strings.Contains(msg.Text, "剛剛回答過囉") || msg.QuoteToken != "q1" {
+			t.Errorf("%s: reply = %+v, want 剛剛回答過囉 quoting q1", name, msgs[0])
+		}
+	}
+
+	// A second member asks while the first answer is still being built
+	first := make(chan []messaging_api.MessageInterface, 1)
+	go func() { first <- ask("U1", "q1") }()
+	<-h.started
+	second := make(chan []messaging_api.MessageInterface, 1)
+	go func() { second <- ask("U2", "q2") }()
+	time.Sleep(50 * time.Millisecond)
+	close(h.release)
+
+	if msgs := <-first; len(msgs) != 1 {
+		t.Fatalf("first asker got %d messages, want the answer", len(msgs))
+	}
+	wantPointer("in flight", <-second)
+
+	// A third asks after the answer
+	wantPointer("just answered", ask("U3", "q3"))
+
+	if calls := h.calls.Load(); calls != 1 {
+		t.Errorf("handler ran %d times, want 1", calls)
+	}
+}
+
+func TestProcessor_GroupDuplicateOfUnsharedAnswer(t *testing.T) {
+	t.Parallel()
+	// Answers of dedupSkipModules differ per asker, so they are not pointed to
+	h := &gatedHandler{stubHandler: stubHandler{name: "usage"}, started: make(chan struct{}, 2), release: make(chan struct{})}
+	p := newCoalesceProcessor(t, h)
+
+	first := make(chan []messaging_api.MessageInterface, 1)
+	go func() { first <- askCourse(t, p, webhook.GroupSource{GroupId: "C1", UserId: "U1"}, "q1") }()
+	<-h.started
+	second := make(chan []messaging_api.MessageInterface, 1)
+	go func() { second <- askCourse(t, p, webhook.GroupSource{GroupId: "C1", UserId: "U2"}, "q2") }()
+	time.Sleep(50 * time.Millisecond)
+	close(h.release)
+
+	if msgs := <-first; len(msgs) != 1 {
+		t.Fatalf("first asker got %d messages, want the answer", len(msgs))
+	}
+	if msgs := <-second; len(msgs) != 1 || strings.Contains(fmt.Sprint(msgs[0]), "剛剛回答過囉") {
+		t.Errorf("second asker got %v, want an answer of their own", msgs)
+	}
+	if calls := h.calls.Load(); calls != 2 {
+		t.Errorf("handler ran %d times, want 2", calls)
+	}
+}
+
+func TestProcessor_PersonalResendAfterAnswer(t *testing.T) {
+	t.Parallel()
+	h := &gatedHandler{stubHandler: stubHandler{name: "course"}, started: make(chan struct{}, 2), release: make(chan struct{})}
+	close(h.release)
+	p := newCoalesceProcessor(t, h)
+	source := webhook.UserSource{UserId: "U1"}
+
+	for _, quoteToken := range []string{"q1", "q2"} {
+		if msgs := askCourse(t, p, source, quoteToken); len(msgs) != 1 {
+			t.Errorf("ask %s: got %d messages, want the answer", quoteToken, len(msgs))
+		}
+	}
+	if calls := h.calls.Load(); calls != 2 {
+		t.Errorf("handler ran %d times, want 2", calls)
+	}
+}
+
+func TestProcessor_MainNavItems(t *testing.T) {
+	t.Parallel()
+
+	without := (&Processor{}).mainNavItems()
+	with := (&Processor{history: &fakeRecorder{}}).mainNavItems()
+	if len(with) != len(without)+1 {
+		t.Fatalf("len(mainNavItems) = %d with history, want %d", len(with), len(without)+1)
+	}
+}
@@ -2,66 +2,286 @@ package bot
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
 
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/metrics"
+	"github.com/garyellow/ntpu-linebot-go/internal/sticker"
 	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
 )
 
+// ErrModuleNotFound is returned when toggling a module that is not registered.
+var ErrModuleNotFound = errors.New("module not found")
+
+// ModuleInfo is user-facing metadata for a registered module.
+type ModuleInfo struct {
+	Name        string // Handler name (filled from Handler.Name() on register)
+	DisplayName string // Shown to users (e.g., "課程查詢")
+	Description string // One-line summary for admin listings
+
+	// LegacyActions are postback actions older releases sent without the
+	// "module:" prefix (e.g., "授課課程$name"); the registry routes them here.
+	LegacyActions []string
+	// TypedPostbacks marks modules that only send typed postbacks (see
+	// Postback), so positional payloads reaching them are counted as legacy.
+	TypedPostbacks bool
+}
+
+// ModuleStatus is a snapshot of a module's metadata and runtime state.
+type ModuleStatus struct {
+	ModuleInfo
+	Enabled       bool
+	CanaryPercent int // Share of chats routed to the canary; -1 without one
+}
+
+// DisabledReplyFunc builds the reply sent when a user reaches a disabled module.
+type DisabledReplyFunc func(ctx context.Context, info ModuleInfo) []messaging_api.MessageInterface
+
+// module is a registered handler with its metadata and runtime toggle.
+type module struct {
+	handler Handler
+	info    ModuleInfo
+	enabled atomic.Bool
+}
+
 // Registry manages bot handlers and provides dispatching functionality.
 // Handlers are matched in registration order (first match wins).
+// Modules can be disabled at runtime; disabled modules still claim their
+// keywords so users get a maintenance notice instead of an NLU guess.
 type Registry struct {
-	handlers   []Handler
-	handlerMap map[string]Handler // Quick lookup by name
+	mu            sync.RWMutex
+	modules       []*module
+	moduleMap     map[string]*module // Quick lookup by name
+	legacyActions map[string]*module // Unprefixed legacy postback action -> module
+	disabledReply DisabledReplyFunc
+	metrics       *metrics.Metrics // Optional: counts legacy postbacks
 }
 
 // NewRegistry creates a new handler registry with pre-allocated capacity.
 func NewRegistry() *Registry {
 	return &Registry{
-		handlers:   make([]Handler, 0, 4), // Pre-allocate for current handlers (id, course, contact) + future growth
-		handlerMap: make(map[string]Handler, 4),
+		modules:       make([]*module, 0, 8), // Pre-allocate for current handlers (id, course, contact, program, usage) + future growth
+		moduleMap:     make(map[string]*module, 8),
+		legacyActions: make(map[string]*module),
 	}
 }
 
-// Register adds a handler to the registry.
+// Register adds a handler to the registry using its name as display name.
 // Handlers are matched in registration order for message/postback dispatch.
 func (r *Registry) Register(h Handler) {
-	r.handlers = append(r.handlers, h)
-	r.handlerMap[h.Name()] = h
+	r.RegisterModule(h, ModuleInfo{})
+}
+
+// RegisterModule adds a handler with metadata. The module starts enabled.
+func (r *Registry) RegisterModule(h Handler, info ModuleInfo) {
+	info.Name = h.Name()
+	if info.DisplayName == "" {
+		info.DisplayName = info.Name
+	}
+	m := &module{handler: h, info: info}
+	m.enabled.Store(true)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.modules = append(r.modules, m)
+	r.moduleMap[info.Name] = m
+	for _, action := range info.LegacyActions {
+		r.legacyActions[action] = m
+	}
+}
+
+// SetDisabledReply sets the reply builder for disabled modules.
+// Without one, disabled modules are skipped as if unregistered.
+func (r *Registry) SetDisabledReply(fn DisabledReplyFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.disabledReply = fn
+}
+
+// SetMetrics sets the metrics legacy postback hits are recorded to.
+func (r *Registry) SetMetrics(m *metrics.Metrics) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics = m
+}
+
+// SetEnabled enables or disables a module at runtime.
+// Returns ErrModuleNotFound if no module has that name.
+func (r *Registry) SetEnabled(name string, enabled bool) error {
+	m := r.lookup(name)
+	if m == nil {
+		return fmt.Errorf("%w: %s", ErrModuleNotFound, name)
+	}
+	m.enabled.Store(enabled)
+	return nil
+}
+
+// SetCanaryPercent sets the share of chats a module routes to its canary.
+func (r *Registry) SetCanaryPercent(name string, percent int) error {
+	m := r.lookup(name)
+	if m == nil {
+		return fmt.Errorf("%w: %s", ErrModuleNotFound, name)
+	}
+	c := canaryOf(m.handler)
+	if c == nil {
+		return fmt.Errorf("%w: %s", ErrNoCanary, name)
+	}
+	c.SetPercent(percent)
+	return nil
+}
+
+// IsEnabled reports whether a module is registered and enabled.
+func (r *Registry) IsEnabled(name string) bool {
+	m := r.lookup(name)
+	return m != nil && m.enabled.Load()
+}
+
+// Modules returns the status of all modules in registration order.
+func (r *Registry) Modules() []ModuleStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	statuses := make([]ModuleStatus, 0, len(r.modules))
+	for _, m := range r.modules {
+		status := ModuleStatus{ModuleInfo: m.info, Enabled: m.enabled.Load(), CanaryPercent: -1}
+		if c := canaryOf(m.handler); c != nil {
+			status.CanaryPercent = c.Percent()
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// DisabledModules returns metadata of modules that are currently disabled.
+func (r *Registry) DisabledModules() []ModuleInfo {
+	var disabled []ModuleInfo
+	for _, s := range r.Modules() {
+		if !s.Enabled {
+			disabled = append(disabled, s.ModuleInfo)
+		}
+	}
+	return disabled
 }
 
 // DispatchMessage dispatches a text message to the first handler that can handle it.
 // Returns nil messages and empty handler name if no handler matches.
 func (r *Registry) DispatchMessage(ctx context.Context, text string) ([]messaging_api.MessageInterface, string) {
-	for _, h := range r.handlers {
-		if h.CanHandle(text) {
-			return h.HandleMessage(ctx, text), h.Name()
+	for _, m := range r.snapshot() {
+		if !m.handler.CanHandle(text) {
+			continue
 		}
+		if !m.enabled.Load() {
+			if msgs := r.replyDisabled(ctx, m.info); len(msgs) > 0 {
+				return msgs, m.info.Name
+			}
+			continue
+		}
+		return m.handler.HandleMessage(ctx, text), m.info.Name
 	}
 	return nil, ""
 }
 
+// MatchingModules returns the names of all modules whose CanHandle accepts
+// text, in registration order, including disabled ones. DispatchMessage picks
+// the first; the rest show where keywords overlap (intent telemetry).
+func (r *Registry) MatchingModules(text string) []string {
+	var names []string
+	for _, m := range r.snapshot() {
+		if m.handler.CanHandle(text) {
+			names = append(names, m.info.Name)
+		}
+	}
+	return names
+}
+
 // DispatchPostback dispatches a postback event using structured data.
 // Parses PostbackData and routes to appropriate handler by module name.
+// Payloads in the formats of older releases are routed too and counted in
+// ntpu_postback_legacy_total (see LegacyPostbackUnprefixed).
 func (r *Registry) DispatchPostback(ctx context.Context, data string) []messaging_api.MessageInterface {
-	pb, err := ParsePostback(data)
-	if err != nil {
+	m, data, format := r.resolvePostback(data)
+	if m == nil {
 		return nil
 	}
-
-	h, ok := r.handlerMap[pb.Module]
-	if !ok {
-		return nil
+	if format != "" {
+		r.mu.RLock()
+		met := r.metrics
+		r.mu.RUnlock()
+		if met != nil {
+			met.RecordLegacyPostback(m.info.Name, format)
+		}
+	}
+	if !m.enabled.Load() {
+		return r.replyDisabled(ctx, m.info)
 	}
 
-	return h.HandlePostback(ctx, data)
+	return m.handler.HandlePostback(ctx, data)
 }
 
-// GetHandler returns a handler by name.
-// Returns nil if handler not found.
+// GetHandler returns an enabled handler by name.
+// Returns nil if handler not found or disabled.
 func (r *Registry) GetHandler(name string) Handler {
-	return r.handlerMap[name]
+	m := r.lookup(name)
+	if m == nil || !m.enabled.Load() {
+		return nil
+	}
+	return m.handler
 }
 
-// Handlers returns all registered handlers in registration order.
+// Handlers returns all registered handlers in registration order, including disabled ones.
 func (r *Registry) Handlers() []Handler {
-	return r.handlers
+	modules := r.snapshot()
+	handlers := make([]Handler, len(modules))
+	for i, m := range modules {
+		handlers[i] = m.handler
+	}
+	return handlers
+}
+
+// DisabledReply returns the disabled-module reply for name, or nil if the
+// module is enabled, unknown, or no reply builder is configured.
+func (r *Registry) DisabledReply(ctx context.Context, name string) []messaging_api.MessageInterface {
+	m := r.lookup(name)
+	if m == nil || m.enabled.Load() {
+		return nil
+	}
+	return r.replyDisabled(ctx, m.info)
+}
+
+func (r *Registry) lookup(name string) *module {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.moduleMap[name]
+}
+
+func (r *Registry) snapshot() []*module {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.modules
+}
+
+func (r *Registry) replyDisabled(ctx context.Context, info ModuleInfo) []messaging_api.MessageInterface {
+	r.mu.RLock()
+	fn := r.disabledReply
+	r.mu.RUnlock()
+	if fn == nil {
+		return nil
+	}
+	return fn(ctx, info)
+}
+
+// ModuleDisabledReply returns a DisabledReplyFunc that tells the user the
+// module is under maintenance.
+func ModuleDisabledReply(stickerManager *sticker.Manager) DisabledReplyFunc {
+	return func(_ context.Context, info ModuleInfo) []messaging_api.MessageInterface {
+		sender := lineutil.GetSender("NTPU 小工具", stickerManager)
+		msg := lineutil.NewTextMessageWithConsistentSender(
+			fmt.Sprintf("🚧 %s暫停服務中\n\n學校系統維護期間暫時無法使用，請稍後再試\n💡 其他功能仍可正常使用", info.DisplayName),
+			sender,
+		)
+		msg.QuickReply = lineutil.NewQuickReply(lineutil.QuickReplyMainNavCompact())
+		return []messaging_api.MessageInterface{msg}
+	}
 }
@@ -0,0 +1,163 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/metrics"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func newTestRegistry() *Registry {
+	r := NewRegistry()
+	r.SetDisabledReply(func(_ context.Context, info ModuleInfo) []messaging_api.MessageInterface {
+		return []messaging_api.MessageInterface{&messaging_api.TextMessage{Text: "disabled:" + info.Name}}
+	})
+	r.RegisterModule(&stubHandler{name: "course"}, ModuleInfo{DisplayName: "課程查詢"})
+	r.Register(&stubNLUHandler{stubHandler{name: "id"}})
+	return r
+}
+
+func TestRegistry_SetEnabled(t *testing.T) {
+	t.Parallel()
+	r := newTestRegistry()
+
+	if !r.IsEnabled("course") {
+		t.Fatal("modules should start enabled")
+	}
+	if err := r.SetEnabled("course", false); err != nil {
+		t.Fatalf("SetEnabled() error = %v", err)
+	}
+	if r.IsEnabled("course") {
+		t.Error("IsEnabled() = true after disabling")
+	}
+	if err := r.SetEnabled("unknown", false); !errors.Is(err, ErrModuleNotFound) {
+		t.Errorf("SetEnabled(unknown) error = %v, want ErrModuleNotFound", err)
+	}
+
+	disabled := r.DisabledModules()
+	if len(disabled) != 1 || disabled[0].Name != "course" || disabled[0].DisplayName != "課程查詢" {
+		t.Errorf("DisabledModules() = %+v, want [course]", disabled)
+	}
+
+	statuses := r.Modules()
+	if len(statuses) != 2 || statuses[1].DisplayName != "id" {
+		t.Errorf("Modules() = %+v, want display name to default to handler name", statuses)
+	}
+}
+
+func TestRegistry_DispatchDisabled(t *testing.T) {
+	t.Parallel()
+	r := newTestRegistry()
+	if err := r.SetEnabled("course", false); err != nil {
+		t.Fatalf("SetEnabled() error = %v", err)
+	}
+	ctx := context.Background()
+
+	msgs, name := r.DispatchMessage(ctx, "課程 微積分")
+	if name != "course" || len(msgs) != 1 || msgs[0].(*messaging_api.TextMessage).Text != "disabled:course" {
+		t.Errorf("DispatchMessage() = %v, %q; want disabled reply from course", msgs, name)
+	}
+
+	msgs = r.DispatchPostback(ctx, "course:uid$v1$uid=1131U0001")
+	if len(msgs) != 1 || msgs[0].(*messaging_api.TextMessage).Text != "disabled:course" {
+		t.Errorf("DispatchPostback() = %v, want disabled reply", msgs)
+	}
+
+	if h := r.GetHandler("course"); h != nil {
+		t.Error("GetHandler() should not return a disabled module")
+	}
+	if msgs := r.DisabledReply(ctx, "id"); msgs != nil {
+		t.Errorf("DisabledReply(enabled module) = %v, want nil", msgs)
+	}
+
+	// Re-enabling restores normal dispatch
+	if err := r.SetEnabled("course", true); err != nil {
+		t.Fatalf("SetEnabled() error = %v", err)
+	}
+	msgs, _ = r.DispatchMessage(ctx, "課程 微積分")
+	if len(msgs) != 1 || msgs[0].(*messaging_api.TextMessage).Text != "課程 微積分" {
+		t.Errorf("DispatchMessage() after re-enable = %v, want handler reply", msgs)
+	}
+}
+
+func TestRegistry_DisabledWithoutReplyFallsThrough(t *testing.T) {
+	t.Parallel()
+	r := NewRegistry()
+	r.Register(&stubHandler{name: "course"})
+	r.Register(&stubHandler{name: "contact"})
+	if err := r.SetEnabled("course", false); err != nil {
+		t.Fatalf("SetEnabled() error = %v", err)
+	}
+
+	if _, name := r.DispatchMessage(context.Background(), "hello"); name != "contact" {
+		t.Errorf("DispatchMessage() handler = %q, want next enabled module", name)
+	}
+}
+
+func TestRegistry_MatchingModules(t *testing.T) {
+	t.Parallel()
+	r := newTestRegistry()
+	if err := r.SetEnabled("course", false); err != nil {
+		t.Fatalf("SetEnabled() error = %v", err)
+	}
+
+	// Stub handlers accept any text; disabled modules still count as matches
+	got := r.MatchingModules("課程 微積分")
+	if len(got) != 2 || got[0] != "course" || got[1] != "id" {
+		t.Errorf("MatchingModules() = %v, want [course id]", got)
+	}
+}
+
+func TestRegistry_DispatchLegacyPostback(t *testing.T) {
+	t.Parallel()
+	m := metrics.New(prometheus.NewRegistry())
+	r := NewRegistry()
+	r.SetMetrics(m)
+	r.RegisterModule(&stubHandler{name: "course"}, ModuleInfo{LegacyActions: []string{"授課課程"}, TypedPostbacks: true})
+	r.Register(&stubHandler{name: "contact"})
+	ctx := context.Background()
+
+	tests := []struct {
+		data   string
+		want   string // Data the module receives; "" = not dispatched
+		format string // Legacy format counted; "" = none
+	}{
+		{"course:uid$v1$uid=1131U0001", "course:uid$v1$uid=1131U0001", ""},
+		{"course:1131U0001", "course:1131U0001", LegacyPostbackPositional},
+		{"授課課程$王小明", "course:授課課程$王小明", LegacyPostbackUnprefixed},
+		{"授課課程$Dr: Wang", "course:授課課程$Dr: Wang", LegacyPostbackUnprefixed},
+		{"contact:members$資工系", "contact:members$資工系", ""}, // contact still sends positional postbacks
+		{"unknown:action", "", ""},
+		{"members$資工系", "", ""},
+	}
+	counts := make(map[string]float64)
+	for _, tt := range tests {
+		got := replyText(r.DispatchPostback(ctx, tt.data))
+		if got != tt.want {
+			t.Errorf("DispatchPostback(%q) = %q, want %q", tt.data, got, tt.want)
+		}
+		if tt.format != "" {
+			counts[tt.format]++
+		}
+	}
+
+	counters, err := m.Counters()
+	if err != nil {
+		t.Fatalf("Counters() error = %v", err)
+	}
+	got := make(map[string]float64)
+	for _, sample := range counters["ntpu_postback_legacy_total"] {
+		if sample.Labels["module"] != "course" {
+			t.Errorf("legacy postback counted for module %q", sample.Labels["module"])
+		}
+		got[sample.Labels["format"]] = sample.Value
+	}
+	for format, want := range counts {
+		if got[format] != want {
+			t.Errorf("ntpu_postback_legacy_total{format=%q} = %v, want %v", format, got[format], want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+// selfTestKeyword triggers the admin-only self-test.
+// Only exact matches from users in AdminUserIDs run it; everyone else gets normal processing.
+const selfTestKeyword = "健康檢查"
+
+// SelfCheck is a single diagnostic run by the admin self-test command.
+// Run returns a short human-readable detail (e.g., "1234 筆") or an error.
+type SelfCheck struct {
+	Name string
+	Run  func(ctx context.Context) (string, error)
+}
+
+// SelfCheckResult is the outcome of a single SelfCheck.
+type SelfCheckResult struct {
+	Name     string
+	Detail   string
+	Err      error
+	Duration time.Duration
+}
+
+// runSelfChecks runs all checks concurrently under a shared timeout.
+// Results keep the order of checks so the status bubble layout is stable.
+func runSelfChecks(ctx context.Context, checks []SelfCheck, timeout time.Duration) []SelfCheckResult {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	results := make([]SelfCheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Go(func() {
+			start := time.Now()
+			detail, err := check.Run(ctx)
+			results[i] = SelfCheckResult{Name: check.Name, Detail: detail, Err: err, Duration: time.Since(start)}
+		})
+	}
+	wg.Wait()
+	return results
+}
+
+// isAdmin reports whether userID may run chat admin commands.
+func (p *Processor) isAdmin(userID string) bool {
+	return userID != "" && p.adminUserIDs[userID]
+}
+
+// handleSelfTest runs the configured self checks and returns a status bubble.
+func (p *Processor) handleSelfTest(ctx context.Context) []messaging_api.MessageInterface {
+	results := runSelfChecks(ctx, p.selfChecks, config.SelfTestTimeout)
+
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+			p.logger.WithError(r.Err).WithField("check", r.Name).WarnContext(ctx, "Self-test check failed")
+		}
+	}
+	p.logger.WithField("checks", len(results)).
+		WithField("failed", failed).
+		InfoContext(ctx, "Admin self-test completed")
+
+	msg := lineutil.NewFlexMessage("健康檢查結果", buildSelfTestBubble(results))
+	msg.Sender = lineutil.GetSender("NTPU 小工具", p.stickerManager)
+	return []messaging_api.MessageInterface{msg}
+}
+
+// buildSelfTestBubble renders check results as a single status bubble.
+// The header is green when every check passed and red otherwise.
+func buildSelfTestBubble(results []SelfCheckResult) *messaging_api.FlexBubble {
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+		}
+	}
+
+	title, color := "✅ 健康檢查：全部正常", lineutil.ColorSuccess
+	if failed > 0 {
+		title, color = fmt.Sprintf("⚠️ 健康檢查：%d 項異常", failed), lineutil.ColorDanger
+	}
+	header := lineutil.NewColoredHeader(lineutil.ColoredHeaderInfo{Title: title, Color: color})
+
+	contents := make([]messaging_api.FlexComponentInterface, 0, len(results)+2)
+	for i, r := range results {
+		icon, detail, detailColor := "✅", r.Detail, lineutil.ColorSubtext
+		if r.Err != nil {
+			icon, detail, detailColor = "❌", r.Err.Error(), lineutil.ColorDanger
+		}
+		if detail == "" {
+			detail = "OK"
+		}
+		row := lineutil.NewFlexBox("vertical",
+			lineutil.NewFlexBox("horizontal",
+				lineutil.NewFlexText(icon).WithSize("sm").WithFlex(0).FlexText,
+				lineutil.NewFlexText(r.Name).WithSize("sm").WithWeight("bold").WithColor(lineutil.ColorText).WithMargin("sm").FlexText,
+				lineutil.NewFlexText(fmt.Sprintf("%dms", r.Duration.Milliseconds())).WithSize("xs").WithColor(lineutil.ColorNote).WithAlign("end").FlexText,
+			).FlexBox,
+			lineutil.NewFlexText(lineutil.TruncateRunes(detail, 120)).WithSize("xs").WithColor(detailColor).WithWrap(true).WithMargin("xs").FlexText,
+		)
+		if i > 0 {
+			row = row.WithMargin("md")
+		}
+		contents = append(contents, row.FlexBox)
+	}
+	contents = append(contents,
+		lineutil.NewFlexSeparator().WithMargin("lg").FlexSeparator,
+		lineutil.NewFlexText(time.Now().In(lineutil.GetTaipeiLocation()).Format("2006-01-02 15:04:05")).
+			WithSize("xxs").WithColor(lineutil.ColorNote).WithAlign("end").WithMargin("sm").FlexText,
+	)
+
+	body := lineutil.NewFlexBox("vertical", contents...).WithSpacing("none")
+	return lineutil.NewFlexBubble(header, nil, body, nil).FlexBubble
+}
@@ -0,0 +1,112 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+func TestRunSelfChecks(t *testing.T) {
+	t.Parallel()
+
+	errDown := errors.New("down")
+	checks := []SelfCheck{
+		{Name: "ok", Run: func(context.Context) (string, error) { return "42 筆", nil }},
+		{Name: "fail", Run: func(context.Context) (string, error) { return "", errDown }},
+		{Name: "slow", Run: func(ctx context.Context) (string, error) {
+			<-ctx.Done()
+			return "", ctx.Err()
+		}},
+	}
+
+	results := runSelfChecks(context.Background(), checks, 50*time.Millisecond)
+	if len(results) != len(checks) {
+		t.Fatalf("got %d results, want %d", len(results), len(checks))
+	}
+
+	tests := []struct {
+		name    string
+		detail  string
+		wantErr error
+	}{
+		{"ok", "42 筆", nil},
+		{"fail", "", errDown},
+		{"slow", "", context.DeadlineExceeded},
+	}
+	for i, tt := range tests {
+		r := results[i]
+		if r.Name != tt.name {
+			t.Errorf("results[%d].Name = %q, want %q (order must follow checks)", i, r.Name, tt.name)
+		}
+		if r.Detail != tt.detail {
+			t.Errorf("results[%d].Detail = %q, want %q", i, r.Detail, tt.detail)
+		}
+		if !errors.Is(r.Err, tt.wantErr) {
+			t.Errorf("results[%d].Err = %v, want %v", i, r.Err, tt.wantErr)
+		}
+	}
+}
+
+func TestBuildSelfTestBubble(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		results   []SelfCheckResult
+		wantTitle string
+	}{
+		{
+			name:      "all passed",
+			results:   []SelfCheckResult{{Name: "資料庫", Detail: "OK"}, {Name: "BM25"}},
+			wantTitle: "✅ 健康檢查：全部正常",
+		},
+		{
+			name:      "some failed",
+			results:   []SelfCheckResult{{Name: "資料庫"}, {Name: "爬蟲", Err: errors.New("timeout")}},
+			wantTitle: "⚠️ 健康檢查：1 項異常",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			bubble := buildSelfTestBubble(tt.results)
+			if bubble.Header == nil || len(bubble.Header.Contents) == 0 {
+				t.Fatal("expected header with title")
+			}
+			title, ok := bubble.Header.Contents[0].(*messaging_api.FlexText)
+			if !ok {
+				t.Fatalf("header content is %T, want *FlexText", bubble.Header.Contents[0])
+			}
+			if title.Text != tt.wantTitle {
+				t.Errorf("title = %q, want %q", title.Text, tt.wantTitle)
+			}
+			// One row per result plus separator and timestamp
+			if got, want := len(bubble.Body.Contents), len(tt.results)+2; got != want {
+				t.Errorf("body has %d components, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestProcessorIsAdmin(t *testing.T) {
+	t.Parallel()
+
+	p := &Processor{adminUserIDs: map[string]bool{"Uadmin": true}}
+	tests := []struct {
+		userID string
+		want   bool
+	}{
+		{"Uadmin", true},
+		{"Uother", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := p.isAdmin(tt.userID); got != tt.want {
+			t.Errorf("isAdmin(%q) = %v, want %v", tt.userID, got, tt.want)
+		}
+	}
+}
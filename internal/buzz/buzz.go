@@ -0,0 +1,147 @@
+// Package buzz counts public discussion of a course on Dcard and 選課大全
+// (no21.ntpu.org) so the course detail page can show a 💬 討論熱度 hint
+// instead of only outbound search links.
+//
+// Counts are fetched in the background (see Enricher) and cached for a long
+// time in their own SQLite file; a lookup never waits on a third-party site.
+package buzz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/scraper"
+)
+
+// Source endpoints. Both return a JSON array of posts; only its length is used.
+const (
+	dcardSearchURL = "https://www.dcard.tw/service/api/v2/search/posts"
+	no21SearchURL  = "https://no21.ntpu.org/wp-json/wp/v2/posts"
+)
+
+// SearchLimit caps the posts requested per source; a count at the cap is shown as "100+".
+const SearchLimit = 100
+
+// Unavailable marks a source that could not be fetched.
+const Unavailable = -1
+
+// BaseURLs returns the scraper base URLs of the sources, so a scraper.Client
+// created with them rate-limits each site separately.
+func BaseURLs() map[string][]string {
+	return map[string][]string{
+		"dcard": {"https://www.dcard.tw"},
+		"no21":  {"https://no21.ntpu.org"},
+	}
+}
+
+// Counts is the number of posts mentioning a course on each source.
+type Counts struct {
+	Dcard     int // Posts in Dcard's NTPU forum, or Unavailable
+	No21      int // 選課大全 articles, or Unavailable
+	FetchedAt time.Time
+}
+
+// Total returns the number of posts across available sources.
+func (c Counts) Total() int {
+	return max(c.Dcard, 0) + max(c.No21, 0)
+}
+
+// Hint returns the 討論熱度 text (e.g., "🔥 Dcard 12 篇・選課大全 3 篇"),
+// or "" when no source was available.
+func (c Counts) Hint() string {
+	var parts []string
+	if c.Dcard != Unavailable {
+		parts = append(parts, "Dcard "+formatCount(c.Dcard)+" 篇")
+	}
+	if c.No21 != Unavailable {
+		parts = append(parts, "選課大全 "+formatCount(c.No21)+" 篇")
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	if c.Total() == 0 {
+		return "尚無討論"
+	}
+
+	text := strings.Join(parts, "・")
+	switch total := c.Total(); {
+	case total >= 30:
+		return "🔥🔥 " + text
+	case total >= 10:
+		return "🔥 " + text
+	}
+	return text
+}
+
+func formatCount(n int) string {
+	if n >= SearchLimit {
+		return fmt.Sprintf("%d+", SearchLimit)
+	}
+	return fmt.Sprintf("%d", n)
+}
+
+// Query returns the search query for a course, matching the detail page's
+// Dcard and 選課大全 buttons (teacher first, then title).
+func Query(title, teacher string) string {
+	return strings.TrimSpace(teacher + " " + title)
+}
+
+// Fetcher queries the sources.
+type Fetcher struct {
+	client   *scraper.Client
+	dcardURL string
+	no21URL  string
+}
+
+// NewFetcher creates a fetcher using client, which should be created with
+// BaseURLs and few retries: the sources are best-effort.
+func NewFetcher(client *scraper.Client) *Fetcher {
+	return &Fetcher{
+		client:   client,
+		dcardURL: dcardSearchURL,
+		no21URL:  no21SearchURL,
+	}
+}
+
+// Fetch counts posts for query on each source. A failing source is recorded
+// as Unavailable; an error is returned only when every source fails.
+func (f *Fetcher) Fetch(ctx context.Context, query string) (Counts, error) {
+	counts := Counts{Dcard: Unavailable, No21: Unavailable, FetchedAt: time.Now()}
+
+	dcardParams := url.Values{
+		"query": {query},
+		"forum": {"ntpu"},
+		"limit": {fmt.Sprint(SearchLimit)},
+	}
+	dcard, dcardErr := f.countPosts(ctx, f.dcardURL+"?"+dcardParams.Encode())
+	if dcardErr == nil {
+		counts.Dcard = dcard
+	}
+
+	no21Params := url.Values{
+		"search":   {query},
+		"per_page": {fmt.Sprint(SearchLimit)},
+		"_fields":  {"id"},
+	}
+	no21, no21Err := f.countPosts(ctx, f.no21URL+"?"+no21Params.Encode())
+	if no21Err == nil {
+		counts.No21 = no21
+	}
+
+	if dcardErr != nil && no21Err != nil {
+		return Counts{}, fmt.Errorf("fetch course buzz: %w", errors.Join(dcardErr, no21Err))
+	}
+	return counts, nil
+}
+
+func (f *Fetcher) countPosts(ctx context.Context, reqURL string) (int, error) {
+	var posts []struct{}
+	if err := f.client.GetJSON(ctx, reqURL, &posts); err != nil {
+		return 0, err
+	}
+	return len(posts), nil
+}
@@ -0,0 +1,91 @@
+package buzz
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/scraper"
+)
+
+func TestCounts_Hint(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		counts Counts
+		want   string
+	}{
+		{"both sources", Counts{Dcard: 3, No21: 2}, "Dcard 3 篇・選課大全 2 篇"},
+		{"warm", Counts{Dcard: 8, No21: 4}, "🔥 Dcard 8 篇・選課大全 4 篇"},
+		{"hot with cap", Counts{Dcard: SearchLimit, No21: 5}, "🔥🔥 Dcard 100+ 篇・選課大全 5 篇"},
+		{"one source unavailable", Counts{Dcard: Unavailable, No21: 1}, "選課大全 1 篇"},
+		{"no posts", Counts{Dcard: 0, No21: 0}, "尚無討論"},
+		{"no sources", Counts{Dcard: Unavailable, No21: Unavailable}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.counts.Hint(); got != tt.want {
+				t.Errorf("Hint() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuery(t *testing.T) {
+	t.Parallel()
+	if got := Query("微積分", "王小明"); got != "王小明 微積分" {
+		t.Errorf("Query() = %q, want %q", got, "王小明 微積分")
+	}
+	if got := Query("微積分", ""); got != "微積分" {
+		t.Errorf("Query() without teacher = %q, want %q", got, "微積分")
+	}
+}
+
+func TestFetcher_Fetch(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/dcard") && r.URL.Query().Get("forum") == "ntpu":
+			_, _ = fmt.Fprint(w, `[{"id":1},{"id":2},{"id":3}]`)
+		case strings.HasPrefix(r.URL.Path, "/no21") && r.URL.Query().Get("search") != "":
+			_, _ = fmt.Fprint(w, `[{"id":7}]`)
+		default:
+			http.Error(w, "forbidden", http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	client := scraper.NewClient(5*time.Second, 0, map[string][]string{})
+	f := &Fetcher{client: client, dcardURL: server.URL + "/dcard", no21URL: server.URL + "/no21"}
+
+	counts, err := f.Fetch(context.Background(), "王小明 微積分")
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if counts.Dcard != 3 || counts.No21 != 1 {
+		t.Errorf("Fetch() = %+v, want Dcard 3, No21 1", counts)
+	}
+
+	// One blocked source is recorded as unavailable
+	f.dcardURL = server.URL + "/blocked"
+	counts, err = f.Fetch(context.Background(), "王小明 微積分")
+	if err != nil {
+		t.Fatalf("Fetch() with one source blocked error = %v", err)
+	}
+	if counts.Dcard != Unavailable || counts.No21 != 1 {
+		t.Errorf("Fetch() = %+v, want Dcard unavailable, No21 1", counts)
+	}
+
+	// Every source blocked is an error
+	f.no21URL = server.URL + "/blocked"
+	if _, err := f.Fetch(context.Background(), "王小明 微積分"); err == nil {
+		t.Error("Fetch() with every source blocked should fail")
+	}
+}
@@ -0,0 +1,132 @@
+package buzz
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/metrics"
+)
+
+// queueSize bounds pending fetches; lookups beyond it are dropped and
+// retried on the next view of the course.
+const queueSize = 64
+
+// Enricher serves cached counts and refreshes them in the background.
+// Safe for concurrent use.
+type Enricher struct {
+	store   *Store
+	fetcher *Fetcher
+	metrics *metrics.Metrics
+	logger  *logger.Logger
+	ttl     time.Duration
+
+	queue   chan string
+	mu      sync.Mutex
+	pending map[string]bool
+}
+
+// NewEnricher creates an enricher that refetches counts older than ttl.
+// Call Run to process fetches.
+func NewEnricher(store *Store, fetcher *Fetcher, metrics *metrics.Metrics, logger *logger.Logger, ttl time.Duration) *Enricher {
+	return &Enricher{
+		store:   store,
+		fetcher: fetcher,
+		metrics: metrics,
+		logger:  logger,
+		ttl:     ttl,
+		queue:   make(chan string, queueSize),
+		pending: make(map[string]bool),
+	}
+}
+
+// Lookup returns the cached counts of a course and queues a fetch when they
+// are missing or older than the TTL. Stale counts are still returned; it
+// never waits on the network. Returns false when nothing is cached yet.
+func (e *Enricher) Lookup(ctx context.Context, title, teacher string) (Counts, bool) {
+	query := Query(title, teacher)
+	if query == "" {
+		return Counts{}, false
+	}
+
+	counts, found, err := e.store.Get(ctx, query)
+	if err != nil {
+		e.logger.WithError(err).WarnContext(ctx, "Failed to read course buzz")
+		return Counts{}, false
+	}
+	stale := found && time.Since(counts.FetchedAt) > e.ttl
+	if stale {
+		e.metrics.RecordStaleCacheServed("buzz")
+	}
+	if !found || stale {
+		e.enqueue(query)
+	}
+	return counts, found
+}
+
+// enqueue queues query unless it is already pending or the queue is full.
+func (e *Enricher) enqueue(query string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.pending[query] {
+		return
+	}
+	select {
+	case e.queue <- query:
+		e.pending[query] = true
+	default:
+		// Full: the next view of the course queues it again
+	}
+}
+
+// Run fetches queued queries one at a time, pacing requests by
+// config.CourseBuzzFetchInterval, and prunes long-stale counts daily.
+// It returns when ctx is done.
+func (e *Enricher) Run(ctx context.Context) {
+	pruneTicker := time.NewTicker(config.CourseBuzzPruneInterval)
+	defer pruneTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-pruneTicker.C:
+			// Counts this old belong to courses nobody viewed for a whole TTL
+			if _, err := e.store.Prune(ctx, time.Now().Add(-2*e.ttl)); err != nil {
+				e.logger.WithError(err).Warn("Failed to prune course buzz")
+			}
+		case query := <-e.queue:
+			e.fetch(ctx, query)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(config.CourseBuzzFetchInterval):
+			}
+		}
+	}
+}
+
+func (e *Enricher) fetch(ctx context.Context, query string) {
+	defer func() {
+		e.mu.Lock()
+		delete(e.pending, query)
+		e.mu.Unlock()
+	}()
+
+	fetchCtx, cancel := context.WithTimeout(ctx, 2*config.CourseBuzzRequestTimeout)
+	defer cancel()
+
+	log := e.logger.WithField("query", query)
+	counts, err := e.fetcher.Fetch(fetchCtx, query)
+	if err != nil {
+		log.WithError(err).Debug("Failed to fetch course buzz")
+		return
+	}
+	if err := e.store.Save(ctx, query, counts); err != nil {
+		log.WithError(err).Warn("Failed to save course buzz")
+		return
+	}
+	log.WithField("total", counts.Total()).Debug("Course buzz updated")
+}
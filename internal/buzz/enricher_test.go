@@ -0,0 +1,48 @@
+package buzz
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/metrics"
+	"github.com/garyellow/ntpu-linebot-go/internal/scraper"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestEnricher_Lookup(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprint(w, `[{"id":1},{"id":2}]`)
+	}))
+	defer server.Close()
+
+	store := openTestStore(t)
+	client := scraper.NewClient(5*time.Second, 0, map[string][]string{})
+	fetcher := &Fetcher{client: client, dcardURL: server.URL, no21URL: server.URL}
+	e := NewEnricher(store, fetcher, metrics.New(prometheus.NewRegistry()), logger.New("error"), time.Hour)
+	ctx := context.Background()
+
+	if _, found := e.Lookup(ctx, "微積分", "王小明"); found {
+		t.Fatal("Lookup() found counts before any fetch")
+	}
+	// A second miss while the first fetch is pending must not queue a duplicate
+	e.Lookup(ctx, "微積分", "王小明")
+	if len(e.queue) != 1 {
+		t.Fatalf("queue length = %d, want 1", len(e.queue))
+	}
+
+	e.fetch(ctx, <-e.queue)
+	counts, found := e.Lookup(ctx, "微積分", "王小明")
+	if !found || counts.Dcard != 2 || counts.No21 != 2 {
+		t.Errorf("Lookup() after fetch = %+v, %v, want 2 posts per source", counts, found)
+	}
+	if len(e.queue) != 0 {
+		t.Errorf("fresh counts queued a refetch (queue length %d)", len(e.queue))
+	}
+}
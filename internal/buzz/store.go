@@ -0,0 +1,104 @@
+package buzz
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+)
+
+// Store persists fetched counts in SQLite, keyed by Query.
+//
+// Counts live in their own file (not the cache DB) so they survive snapshot
+// hot-swaps and cache rebuilds; refetching them costs third-party requests.
+type Store struct {
+	*storage.AuxStore
+}
+
+// Open opens (or creates) the buzz database at path.
+func Open(ctx context.Context, path string) (*Store, error) {
+	db, err := storage.OpenAux(ctx, path, "buzz", initSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Store{AuxStore: storage.NewAuxStore(db)}, nil
+}
+
+func initSchema(ctx context.Context, db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS course_buzz (
+		query TEXT PRIMARY KEY,
+		dcard INTEGER NOT NULL,
+		no21 INTEGER NOT NULL,
+		fetched_at INTEGER NOT NULL
+	) STRICT;
+	CREATE INDEX IF NOT EXISTS idx_course_buzz_fetched_at ON course_buzz(fetched_at);
+	`
+
+	if _, err := db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("create buzz tables: %w", err)
+	}
+
+	return nil
+}
+
+// Get returns the stored counts for query, or false if none are stored.
+func (s *Store) Get(ctx context.Context, query string) (Counts, bool, error) {
+	db, err := s.Conn()
+	if err != nil {
+		return Counts{}, false, err
+	}
+
+	var c Counts
+	var fetchedAt int64
+	err = db.QueryRowContext(ctx,
+		"SELECT dcard, no21, fetched_at FROM course_buzz WHERE query = ?", query,
+	).Scan(&c.Dcard, &c.No21, &fetchedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Counts{}, false, nil
+	}
+	if err != nil {
+		return Counts{}, false, fmt.Errorf("get course buzz: %w", err)
+	}
+	c.FetchedAt = time.Unix(fetchedAt, 0)
+	return c, true, nil
+}
+
+// Save stores the counts for query, replacing older ones.
+func (s *Store) Save(ctx context.Context, query string, c Counts) error {
+	db, err := s.Conn()
+	if err != nil {
+		return err
+	}
+
+	if _, err := db.ExecContext(ctx, `
+		INSERT INTO course_buzz (query, dcard, no21, fetched_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(query) DO UPDATE SET
+			dcard = excluded.dcard,
+			no21 = excluded.no21,
+			fetched_at = excluded.fetched_at
+	`, query, c.Dcard, c.No21, c.FetchedAt.Unix()); err != nil {
+		return fmt.Errorf("save course buzz: %w", err)
+	}
+	return nil
+}
+
+// Prune deletes counts fetched before the given time.
+// Returns the number of rows deleted.
+func (s *Store) Prune(ctx context.Context, before time.Time) (int64, error) {
+	db, err := s.Conn()
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := db.ExecContext(ctx, "DELETE FROM course_buzz WHERE fetched_at < ?", before.Unix())
+	if err != nil {
+		return 0, fmt.Errorf("prune course buzz: %w", err)
+	}
+	return result.RowsAffected()
+}
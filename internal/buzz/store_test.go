@@ -0,0 +1,64 @@
+package buzz
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+)
+
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	store, err := Open(context.Background(), filepath.Join(t.TempDir(), "buzz.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+func TestStore_SaveGetPrune(t *testing.T) {
+	t.Parallel()
+	store := openTestStore(t)
+	ctx := context.Background()
+
+	if _, found, err := store.Get(ctx, "王小明 微積分"); err != nil || found {
+		t.Fatalf("Get() on empty store = found %v, err %v", found, err)
+	}
+
+	old := Counts{Dcard: 1, No21: Unavailable, FetchedAt: time.Now().Add(-48 * time.Hour)}
+	if err := store.Save(ctx, "王小明 微積分", old); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	fresh := Counts{Dcard: 4, No21: 2, FetchedAt: time.Now()}
+	if err := store.Save(ctx, "李大華 經濟學", fresh); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	got, found, err := store.Get(ctx, "王小明 微積分")
+	if err != nil || !found {
+		t.Fatalf("Get() = found %v, err %v", found, err)
+	}
+	if got.Dcard != 1 || got.No21 != Unavailable || got.FetchedAt.Unix() != old.FetchedAt.Unix() {
+		t.Errorf("Get() = %+v, want %+v", got, old)
+	}
+
+	n, err := store.Prune(ctx, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	if n != 1 {
+		t.Errorf("Prune() deleted %d rows, want 1", n)
+	}
+	if _, found, _ := store.Get(ctx, "李大華 經濟學"); !found {
+		t.Error("Prune() deleted fresh counts")
+	}
+
+	_ = store.Close()
+	if _, _, err := store.Get(ctx, "李大華 經濟學"); !errors.Is(err, storage.ErrDatabaseClosed) {
+		t.Errorf("Get() after Close error = %v, want storage.ErrDatabaseClosed", err)
+	}
+}
@@ -1,7 +1,10 @@
 // Package config provides centralized configuration management for bot modules.
 package config
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // LINE API constraints (https://developers.line.biz/en/docs/messaging-api/)
 const (
@@ -20,6 +23,10 @@ func (c *BotConfig) Validate() error {
 		return fmt.Errorf("webhook timeout must be positive, got %v", c.WebhookTimeout)
 	}
 
+	if c.LineAPIBaseURL != "" && !strings.HasPrefix(c.LineAPIBaseURL, "http://") && !strings.HasPrefix(c.LineAPIBaseURL, "https://") {
+		return fmt.Errorf("LINE API base URL must start with http:// or https://, got %q", c.LineAPIBaseURL)
+	}
+
 	if c.MaxMessagesPerReply < 1 || c.MaxMessagesPerReply > 5 {
 		return fmt.Errorf("max messages per reply must be 1-5 (LINE API limit), got %d", c.MaxMessagesPerReply)
 	}
@@ -49,6 +56,15 @@ func (c *BotConfig) Validate() error {
 		return fmt.Errorf("LLM rate daily must be non-negative, got %d", c.LLMRateDaily)
 	}
 
+	// ModuleRateBurst can be 0 (disabled)
+	if c.ModuleRateBurst < 0 {
+		return fmt.Errorf("module rate burst must be non-negative, got %f", c.ModuleRateBurst)
+	}
+
+	if c.ModuleRateBurst > 0 && c.ModuleRateRefill <= 0 {
+		return fmt.Errorf("module rate refill must be positive when module rate burst is set, got %f", c.ModuleRateRefill)
+	}
+
 	if c.GlobalRateRPS <= 0 {
 		return fmt.Errorf("global rate RPS must be positive, got %f", c.GlobalRateRPS)
 	}
@@ -67,3 +83,8 @@ func (c *BotConfig) Validate() error {
 
 	return nil
 }
+
+// IsModuleRateLimitEnabled returns true if per-module rate limiting is configured.
+func (c *BotConfig) IsModuleRateLimitEnabled() bool {
+	return c.ModuleRateBurst > 0
+}
@@ -136,6 +136,14 @@ func TestBotConfig_Validate(t *testing.T) {
 		}
 	})
 
+	t.Run("invalid LINE API base URL", func(t *testing.T) {
+		cfg := newTestBotConfig()
+		cfg.LineAPIBaseURL = "localhost:8080"
+		if err := cfg.Validate(); err == nil {
+			t.Error("expected validation error for LINE API base URL without scheme")
+		}
+	})
+
 	t.Run("invalid max messages per reply", func(t *testing.T) {
 		tests := []int{0, 6, 10}
 		for _, val := range tests {
@@ -4,11 +4,18 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
+	"regexp"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -23,19 +30,32 @@ type Config struct {
 	// ========================================================================
 
 	// LINE Bot Configuration
-	LineChannelToken  string
-	LineChannelSecret string
+	LineChannelToken  string `env:"NTPU_LINE_CHANNEL_ACCESS_TOKEN" example:"your_access_token" secret:"true"`
+	LineChannelSecret string `env:"NTPU_LINE_CHANNEL_SECRET" example:"your_channel_secret" secret:"true"`
 
 	// Server Configuration
-	Port            string
-	LogLevel        string
-	ShutdownTimeout time.Duration
-	ServerName      string
-	InstanceID      string
+	Port            string            `env:"NTPU_PORT" example:"8080"`
+	LogLevel        string            `env:"NTPU_LOG_LEVEL" format:"debug, info, warn, or error" example:"debug"`
+	LogModuleLevels map[string]string `env:"NTPU_LOG_MODULE_LEVELS" example:"course=debug,id=warn"` // Per-module level overrides (e.g., {"course": "debug"}); adjustable via the admin API
+	LogSampling     bool              `env:"NTPU_LOG_SAMPLING"`                                     // Sample repetitive debug/info logs (default: false)
+	LogRedactPII    bool              `env:"NTPU_LOG_REDACT_PII" example:"false"`                   // Mask student names and hash student IDs in logs (default: true)
+	ShutdownTimeout time.Duration     `env:"NTPU_SHUTDOWN_TIMEOUT" example:"45s"`
+	ServerName      string            `env:"NTPU_SERVER_NAME" example:"ntpu-linebot-go-01"`
+	InstanceID      string            `env:"NTPU_INSTANCE_ID" example:"ntpu-linebot-go-01-pod-abc123"`
+	PublicBaseURL   string            `env:"NTPU_PUBLIC_BASE_URL" format:"https:// origin without a path" example:"https://your-domain.com"` // Public HTTPS origin LINE fetches images from (e.g., https://bot.example.com); optional
+	SkipPreflight   bool              `env:"NTPU_SKIP_PREFLIGHT"`                                                                            // Skip the startup checks of LINE, Gemini, scraper DNS, and the database (default: false)
 
 	// Data Configuration
-	DataDir  string        // Data directory for SQLite database
-	CacheTTL time.Duration // TTL: absolute expiration for cache entries (default: 7 days)
+	DataDir             string        `env:"NTPU_DATA_DIR" example:"/var/lib/ntpu-linebot"`                                             // Data directory for SQLite database
+	CacheTTL            time.Duration `env:"NTPU_CACHE_TTL" example:"336h"`                                                             // TTL: absolute expiration for cache entries without a per-table TTL (default: 7 days)
+	SyllabusCompression bool          `env:"NTPU_SYLLABUS_COMPRESSION"`                                                                 // zstd-compress syllabus text columns (default: false)
+	Tenant              string        `env:"NTPU_TENANT" format:"lowercase letters, digits, and -, up to 32 characters" example:"nccu"` // Data source namespace: the cache rows this instance reads and writes (default: "ntpu")
+	IntegrityRepair     bool          `env:"NTPU_INTEGRITY_REPAIR"`                                                                     // Delete anomalous rows found by the cleanup integrity check (default: false)
+	DBQueryAnalysis     bool          `env:"NTPU_DB_QUERY_ANALYSIS"`                                                                    // Log EXPLAIN QUERY PLAN and index hints for slow queries (default: false)
+	CacheTTLs           TTLPolicy     // Per-table TTLs; zero fields fall back to CacheTTL
+
+	// Image Assets (template images; defaults in data.Assets)
+	AssetURLs map[string]string `env:"NTPU_ASSET_URLS" example:"logo=https://your-domain.com/logo.png"` // URL overrides by asset key (e.g., {"college.電資": "https://..."}); adjustable via the admin API
 
 	// ========================================================================
 	// Bot Business Logic Configuration
@@ -45,9 +65,13 @@ type Config struct {
 	Bot BotConfig
 
 	// Scraper Configuration
-	ScraperTimeout    time.Duration
-	ScraperMaxRetries int
-	ScraperBaseURLs   map[string][]string
+	ScraperTimeout       time.Duration `env:"NTPU_SCRAPER_TIMEOUT" example:"30s"`
+	ScraperMaxRetries    int           `env:"NTPU_SCRAPER_MAX_RETRIES" example:"5"`
+	ScraperBaseURLs      map[string][]string
+	ScraperUserAgents    []string          `env:"NTPU_SCRAPER_USER_AGENTS" sep:"|" example:"Mozilla/5.0 (Windows NT 10.0; Win64; x64)|Mozilla/5.0 (Macintosh; Intel Mac OS X 14_0)"` // Optional pool; empty = generated browser user agents
+	ScraperProxyURL      string            `env:"NTPU_SCRAPER_PROXY" format:"http://, https://, socks5://, or socks5h:// URL" example:"socks5://proxy:1080"`                         // Optional HTTP(S)/SOCKS5 proxy for all sources
+	ScraperSourceProxies map[string]string `env:"NTPU_SCRAPER_SOURCE_PROXIES" example:"lms=socks5://proxy:1080,sea=direct"`                                                          // Per-source proxy overrides ("direct" bypasses the proxy)
+	ScraperBindAddr      string            `env:"NTPU_SCRAPER_BIND_ADDR" format:"local IP address" example:"192.0.2.10"`                                                             // Optional source IP for outgoing connections
 
 	// Maintenance Scheduling
 	// NTPU_WARMUP_WAIT: if true, reject /webhook until warmup is ready (default: false)
@@ -56,10 +80,10 @@ type Config struct {
 	//   warmup completes or this duration elapses. Non-zero is an explicit escape hatch. (default: 0)
 	// NTPU_MAINTENANCE_REFRESH_INTERVAL: refresh interval (default: 24h)
 	// NTPU_MAINTENANCE_CLEANUP_INTERVAL: cleanup interval (default: 24h)
-	WaitForWarmup              bool          // If true, reject /webhook until warmup is ready
-	WarmupMaxWait              time.Duration // Max warmup wait; 0 = wait indefinitely (recommended). Governs /readyz (always) and /webhook (if WaitForWarmup). Non-zero is an escape hatch.
-	MaintenanceRefreshInterval time.Duration // Interval for refresh tasks
-	MaintenanceCleanupInterval time.Duration // Interval for cleanup tasks
+	WaitForWarmup              bool          `env:"NTPU_WARMUP_WAIT"`                                // If true, reject /webhook until warmup is ready
+	WarmupMaxWait              time.Duration `env:"NTPU_WARMUP_MAX_WAIT" example:"10m"`              // Max warmup wait; 0 = wait indefinitely (recommended). Governs /readyz (always) and /webhook (if WaitForWarmup). Non-zero is an escape hatch.
+	MaintenanceRefreshInterval time.Duration `env:"NTPU_MAINTENANCE_REFRESH_INTERVAL" example:"12h"` // Interval for refresh tasks
+	MaintenanceCleanupInterval time.Duration `env:"NTPU_MAINTENANCE_CLEANUP_INTERVAL" example:"12h"` // Interval for cleanup tasks
 
 	// ========================================================================
 	// Optional Features
@@ -67,81 +91,193 @@ type Config struct {
 
 	// 1. LLM Features (NLU, Query Expansion)
 	// Flag: NTPU_LLM_ENABLED
-	LLMEnabled   bool
-	LLMProviders []string // Ordered list of LLM providers for fallback
+	LLMEnabled   bool     `env:"NTPU_LLM_ENABLED"`
+	LLMProviders []string `env:"NTPU_LLM_PROVIDERS" format:"comma-separated gemini, groq, cerebras, or openai" example:"groq,gemini"` // Ordered list of LLM providers for fallback
 	// Gemini
-	GeminiAPIKey         string
-	GeminiIntentModels   []string
-	GeminiExpanderModels []string
+	GeminiAPIKey         string   `env:"NTPU_GEMINI_API_KEY" example:"your_gemini_api_key" secret:"true"`
+	GeminiIntentModels   []string `env:"NTPU_GEMINI_INTENT_MODELS" example:"gemini-2.5-flash,gemini-2.5-flash-lite"`
+	GeminiExpanderModels []string `env:"NTPU_GEMINI_EXPANDER_MODELS" example:"gemini-2.5-flash-lite"`
 	// Groq
-	GroqAPIKey         string
-	GroqIntentModels   []string
-	GroqExpanderModels []string
+	GroqAPIKey         string   `env:"NTPU_GROQ_API_KEY" example:"your_groq_api_key" secret:"true"`
+	GroqIntentModels   []string `env:"NTPU_GROQ_INTENT_MODELS" example:"llama-3.3-70b-versatile"`
+	GroqExpanderModels []string `env:"NTPU_GROQ_EXPANDER_MODELS" example:"llama-3.1-8b-instant"`
 	// Cerebras
-	CerebrasAPIKey         string
-	CerebrasIntentModels   []string
-	CerebrasExpanderModels []string
+	CerebrasAPIKey         string   `env:"NTPU_CEREBRAS_API_KEY" example:"your_cerebras_api_key" secret:"true"`
+	CerebrasIntentModels   []string `env:"NTPU_CEREBRAS_INTENT_MODELS" example:"llama-3.3-70b"`
+	CerebrasExpanderModels []string `env:"NTPU_CEREBRAS_EXPANDER_MODELS" example:"llama3.1-8b"`
 	// OpenAI-Compatible
-	OpenAIAPIKey         string
-	OpenAIEndpoint       string
-	OpenAIIntentModels   []string
-	OpenAIExpanderModels []string
+	OpenAIAPIKey         string   `env:"NTPU_OPENAI_API_KEY" example:"your_openai_api_key" secret:"true"`
+	OpenAIEndpoint       string   `env:"NTPU_OPENAI_ENDPOINT" example:"http://localhost:11434/v1"`
+	OpenAIIntentModels   []string `env:"NTPU_OPENAI_INTENT_MODELS" example:"qwen2.5:7b"`
+	OpenAIExpanderModels []string `env:"NTPU_OPENAI_EXPANDER_MODELS" example:"qwen2.5:3b"`
+	// Query expansion prompt versions (see genai.ExpansionPrompts)
+	QueryExpansionPrompts string `env:"NTPU_QUERY_EXPANSION_PROMPTS" example:"/data/expansion_prompts.json"` // JSON file of prompt versions and few-shot examples (default: "" = built-in prompt)
+	// LLM output kill switch (see genai.OutputGuard); toggled at runtime via the admin API
+	LLMOutputBlocked bool `env:"NTPU_LLM_OUTPUT_BLOCKED"` // Block free-form model text: direct replies and query expansions (default: false)
 
 	// 2. S3-Compatible Snapshot Sync (Distributed Warmup)
 	// Flag: NTPU_S3_ENABLED
 	// Enables distributed snapshot synchronization for multi-node deployments.
 	// Polling interval is configured via NTPU_S3_SNAPSHOT_POLL_INTERVAL (default: 15m)
-	S3Enabled              bool
-	S3EndpointURL          string        // S3-compatible endpoint URL
-	S3Region               string        // S3 signing region (default: us-east-1)
-	S3AccessKeyID          string        // S3-compatible access key ID
-	S3SecretKey            string        // S3-compatible secret access key
-	S3BucketName           string        // S3-compatible bucket name
-	S3SnapshotKey          string        // Object key for snapshot (default: snapshots/cache.db.zst)
-	S3LockKey              string        // Object key for leader lease lock (default: locks/leader.json)
-	S3LockTTL              time.Duration // TTL for leader lease lock (default: 1h)
-	S3SnapshotPollInterval time.Duration // Interval for polling S3 snapshots (default: 15m)
-	S3DeltaPrefix          string        // Prefix for delta logs (default: deltas)
-	S3ScheduleKey          string        // Object key for maintenance schedule state (default: schedules/maintenance.json)
+	S3Enabled              bool          `env:"NTPU_S3_ENABLED"`
+	S3EndpointURL          string        `env:"NTPU_S3_ENDPOINT" example:"https://s3.example.com"`                        // S3-compatible endpoint URL
+	S3Region               string        `env:"NTPU_S3_REGION" example:"auto"`                                            // S3 signing region (default: us-east-1)
+	S3AccessKeyID          string        `env:"NTPU_S3_ACCESS_KEY_ID" example:"your_access_key_id" secret:"true"`         // S3-compatible access key ID
+	S3SecretKey            string        `env:"NTPU_S3_SECRET_ACCESS_KEY" example:"your_secret_access_key" secret:"true"` // S3-compatible secret access key
+	S3BucketName           string        `env:"NTPU_S3_BUCKET_NAME" example:"ntpu-linebot"`                               // S3-compatible bucket name
+	S3SnapshotKey          string        `env:"NTPU_S3_SNAPSHOT_KEY" example:"prod/cache.db.zst"`                         // Object key for snapshot (default: snapshots/cache.db.zst)
+	S3LockKey              string        `env:"NTPU_S3_LOCK_KEY" example:"prod/leader.json"`                              // Object key for leader lease lock (default: locks/leader.json)
+	S3LockTTL              time.Duration `env:"NTPU_S3_LOCK_TTL" example:"30m"`                                           // TTL for leader lease lock (default: 1h)
+	S3SnapshotPollInterval time.Duration `env:"NTPU_S3_SNAPSHOT_POLL_INTERVAL" example:"5m"`                              // Interval for polling S3 snapshots (default: 15m)
+	S3DeltaPrefix          string        `env:"NTPU_S3_DELTA_PREFIX" example:"prod/deltas"`                               // Prefix for delta logs (default: deltas)
+	S3ScheduleKey          string        `env:"NTPU_S3_SCHEDULE_KEY" example:"prod/maintenance.json"`                     // Object key for maintenance schedule state (default: schedules/maintenance.json)
 
 	// 3. Sentry Error Tracking
 	// Flag: NTPU_SENTRY_ENABLED
-	SentryEnabled          bool
-	SentryDSN              string  // Sentry DSN (https://TOKEN@HOST/1)
-	SentryEnvironment      string  // Environment name (e.g., production, staging)
-	SentryRelease          string  // Release version (optional)
-	SentrySampleRate       float64 // Error sampling rate (0.0-1.0, default: 1.0)
-	SentryTracesSampleRate float64 // Traces sampling rate (0.0-1.0, default: 0.0 = disabled)
+	SentryEnabled          bool    `env:"NTPU_SENTRY_ENABLED"`
+	SentryDSN              string  `env:"NTPU_SENTRY_DSN" example:"https://token@o0.ingest.sentry.io/1" secret:"true"`                         // Sentry DSN (https://TOKEN@HOST/1)
+	SentryEnvironment      string  `env:"NTPU_SENTRY_ENVIRONMENT" format:"environment name; production refuses NTPU_FAULTS" example:"staging"` // Environment name (e.g., production, staging)
+	SentryRelease          string  `env:"NTPU_SENTRY_RELEASE" example:"v1.2.3"`                                                                // Release version (optional)
+	SentrySampleRate       float64 `env:"NTPU_SENTRY_SAMPLE_RATE" format:"number from 0 to 1" example:"0.5"`                                   // Error sampling rate (0.0-1.0, default: 1.0)
+	SentryTracesSampleRate float64 `env:"NTPU_SENTRY_TRACES_SAMPLE_RATE" format:"number from 0 to 1" example:"0.1"`                            // Traces sampling rate (0.0-1.0, default: 0.0 = disabled)
 
 	// 4. Better Stack Logging
 	// Flag: NTPU_BETTERSTACK_ENABLED
-	BetterStackEnabled  bool
-	BetterStackToken    string
-	BetterStackEndpoint string
+	BetterStackEnabled  bool   `env:"NTPU_BETTERSTACK_ENABLED"`
+	BetterStackToken    string `env:"NTPU_BETTERSTACK_TOKEN" example:"your_source_token" secret:"true"`
+	BetterStackEndpoint string `env:"NTPU_BETTERSTACK_ENDPOINT" example:"https://in.logs.betterstack.com"`
 
 	// 5. Metrics Authentication
 	// Flag: NTPU_METRICS_AUTH_ENABLED
-	MetricsAuthEnabled bool
-	MetricsUsername    string // Username for /metrics endpoint Basic Auth (default: "prometheus")
-	MetricsPassword    string // Password for /metrics Basic Auth
+	MetricsAuthEnabled bool   `env:"NTPU_METRICS_AUTH_ENABLED"`
+	MetricsUsername    string `env:"NTPU_METRICS_USERNAME" example:"grafana"`                             // Username for /metrics endpoint Basic Auth (default: "prometheus")
+	MetricsPassword    string `env:"NTPU_METRICS_PASSWORD" example:"your_metrics_password" secret:"true"` // Password for /metrics Basic Auth
+
+	// 6. Admin API (module toggles)
+	// Flag: NTPU_ADMIN_ENABLED
+	AdminEnabled bool     `env:"NTPU_ADMIN_ENABLED"`
+	AdminToken   string   `env:"NTPU_ADMIN_TOKEN" example:"your_admin_token" secret:"true"`       // Bearer token for /admin endpoints
+	AdminUserIDs []string `env:"NTPU_ADMIN_USER_IDS" example:"U1234567890abcdef1234567890abcdef"` // LINE user IDs allowed to run chat admin commands (健康檢查); independent of the flag
+
+	// 7. Analytics Rollups (daily counters in analytics.db, read by cmd/report)
+	// Flag: NTPU_ANALYTICS_ENABLED
+	AnalyticsEnabled bool `env:"NTPU_ANALYTICS_ENABLED"`
+
+	// 8. Course Export (department CSV, course iCal feeds)
+	// Flag: NTPU_EXPORT_ENABLED
+	ExportEnabled bool   `env:"NTPU_EXPORT_ENABLED"`
+	ExportSecret  string `env:"NTPU_EXPORT_SECRET" example:"your_export_secret" secret:"true"` // HMAC key for export URL tokens; rotating it revokes all links
+
+	// 9. Timetable Images (weekly grid PNGs for 課表 queries)
+	// Flag: NTPU_TIMETABLE_ENABLED
+	TimetableEnabled  bool   `env:"NTPU_TIMETABLE_ENABLED"`
+	TimetableFontPath string `env:"NTPU_TIMETABLE_FONT" example:"/usr/share/fonts/noto/NotoSansCJK-Regular.ttc"` // CJK-capable TTF/OTF/TTC used to draw course titles
+
+	// 10. Share Links (分享 button, oaMessage deep links, QR codes)
+	// Enabled when NTPU_LINE_BOT_ID is set; QR images also need NTPU_PUBLIC_BASE_URL
+	LineBotID string `env:"NTPU_LINE_BOT_ID" format:"@ followed by the bot basic ID" example:"@123abcde"` // Bot basic ID the deep links open (e.g., @123abcde)
+
+	// 11. Course Filter LIFF (multi-facet course search inside LINE)
+	// Enabled when NTPU_LIFF_ID is set; the LIFF app's endpoint is {public origin}/liff/courses
+	LIFFID string `env:"NTPU_LIFF_ID" format:"{channel ID}-{alphanumerics}" example:"1234567890-AbcdEfgh"` // LIFF app ID from the LINE Developers console (e.g., 1234567890-AbcdEfgh)
+
+	// 12. Query History (per-user 最近查過 list in history.db)
+	// Flag: NTPU_QUERY_HISTORY_ENABLED
+	QueryHistoryEnabled bool `env:"NTPU_QUERY_HISTORY_ENABLED"`
+
+	// 13. Group Leaderboard (opt-in weekly 本群最常查的課 post in leaderboard.db)
+	// Flag: NTPU_GROUP_LEADERBOARD_ENABLED; groups still opt in with 開啟排行榜
+	GroupLeaderboardEnabled bool `env:"NTPU_GROUP_LEADERBOARD_ENABLED"`
+
+	// 14. Course Buzz (💬 討論熱度 from Dcard/選課大全 mention counts in buzz.db)
+	// Flag: NTPU_COURSE_BUZZ_ENABLED
+	CourseBuzzEnabled bool          `env:"NTPU_COURSE_BUZZ_ENABLED"`
+	CourseBuzzTTL     time.Duration `env:"NTPU_COURSE_BUZZ_TTL" example:"168h"` // How long fetched counts are reused before a refetch
+
+	// 15. Cache Backups (rotated cache.db copies for cmd/dbtool restore)
+	// Enabled when NTPU_BACKUP_DIR or NTPU_BACKUP_S3_PREFIX is set (not both)
+	BackupDir       string        `env:"NTPU_BACKUP_DIR" example:"/data/backups"` // Local directory for backups
+	BackupS3Prefix  string        `env:"NTPU_BACKUP_S3_PREFIX" example:"backups"` // Key prefix in the NTPU_S3_BUCKET_NAME bucket; needs NTPU_S3_ENABLED
+	BackupInterval  time.Duration `env:"NTPU_BACKUP_INTERVAL" example:"6h"`       // How often a backup is taken (default: 24h)
+	BackupRetention int           `env:"NTPU_BACKUP_RETENTION" example:"14"`      // Newest backups kept (default: 7)
+
+	// 16. Litestream Replication (a Litestream sidecar streams the SQLite files)
+	// Flag: NTPU_LITESTREAM_ENABLED; cannot be combined with NTPU_S3_ENABLED
+	LitestreamEnabled bool `env:"NTPU_LITESTREAM_ENABLED"`
+
+	// 17. Group Trigger Prefix (groups can require e.g. 「!」 before keywords, in prefix.db)
+	// Flag: NTPU_GROUP_PREFIX_ENABLED; groups still set one with 設定前綴
+	GroupPrefixEnabled bool `env:"NTPU_GROUP_PREFIX_ENABLED"`
+
+	// 18. Account Linking (NTPU SSO accounts linked to LINE users, tokens in account.db)
+	// Flag: NTPU_ACCOUNT_LINK_ENABLED; the SSO redirects back to NTPU_PUBLIC_BASE_URL
+	AccountLinkEnabled bool     `env:"NTPU_ACCOUNT_LINK_ENABLED"`
+	AccountTokenKey    string   `env:"NTPU_ACCOUNT_TOKEN_KEY" example:"your_32_byte_or_longer_encryption_key" secret:"true"` // Encrypts stored SSO tokens; rotating it makes users link again
+	SSOAuthURL         string   `env:"NTPU_SSO_AUTH_URL" example:"https://sso.example.edu/oauth2/authorize"`                 // OAuth 2.0 authorization endpoint
+	SSOTokenURL        string   `env:"NTPU_SSO_TOKEN_URL" example:"https://sso.example.edu/oauth2/token"`                    // OAuth 2.0 token endpoint
+	SSOUserInfoURL     string   `env:"NTPU_SSO_USERINFO_URL" example:"https://sso.example.edu/oauth2/userinfo"`              // OpenID Connect userinfo endpoint; its "sub" identifies the account
+	SSOClientID        string   `env:"NTPU_SSO_CLIENT_ID" example:"ntpu-linebot"`                                            // OAuth client registered with the school
+	SSOClientSecret    string   `env:"NTPU_SSO_CLIENT_SECRET" example:"your_client_secret" secret:"true"`                    // OAuth client secret
+	SSOScopes          []string `env:"NTPU_SSO_SCOPES" sep:" " example:"openid profile email"`                               // Requested scopes (default: openid profile)
+
+	// 19. Staff Roles (admins verify staff, who may see staff-only contact fields; in roles.db)
+	// Flag: NTPU_STAFF_ROLES_ENABLED; roles are granted by NTPU_ADMIN_USER_IDS in chat
+	StaffRolesEnabled bool `env:"NTPU_STAFF_ROLES_ENABLED"`
+
+	// 20. Degraded Mode (serve a JSON snapshot of the cache when SQLite fails to open)
+	// Flag: NTPU_DEGRADED_MODE_ENABLED; the snapshot is exported daily to degraded-snapshot.json
+	DegradedModeEnabled bool `env:"NTPU_DEGRADED_MODE_ENABLED"`
+
+	// 21. Department News (📰 系上公告 from department website announcement lists)
+	// Enabled when NTPU_DEPT_NEWS_URLS is set; announcements are cached for NTPU_CACHE_TTL_NEWS
+	DeptNewsURLs map[string]string `env:"NTPU_DEPT_NEWS_URLS" example:"85=https://dept.example.edu/news,*=https://www.example.edu/{code}/news"` // Announcement list URL per department code; "*" is a template with {code} for the rest
+
+	// 22. Image Proxy (external template and quick reply images served from /img)
+	// Flag: NTPU_IMAGE_PROXY_ENABLED; requires NTPU_PUBLIC_BASE_URL
+	ImageProxyEnabled bool `env:"NTPU_IMAGE_PROXY_ENABLED"`
+
+	// 23. Fault Injection (test-only: scraper, storage, and LLM failures at set rates)
+	// Enabled when NTPU_FAULTS is set; refused when NTPU_SENTRY_ENVIRONMENT is production
+	Faults map[string]string `env:"NTPU_FAULTS" format:"comma-separated fault=rate pairs, rates from 0 to 1" example:"scraper_timeout=0.2,llm_error=0.1"` // Rate (0-1) per fault, e.g. {"scraper_timeout": "0.2"}; faults are listed in internal/faults
+
+	// 24. Memory Budget (BM25 index and fuzzy-match course and program lists)
+	// Usage is always measured; NTPU_MEMORY_BUDGET_MB caps it by evicting least recently used entries
+	MemoryBudgetMB int `env:"NTPU_MEMORY_BUDGET_MB" example:"256"` // Cap in MiB (default: 0, no cap)
+
+	// 25. Bug Reports (回報問題 saves the user's last exchange in bugreports.db)
+	// Flag: NTPU_BUG_REPORTS_ENABLED; NTPU_ADMIN_USER_IDS get a push for each report
+	BugReportsEnabled bool `env:"NTPU_BUG_REPORTS_ENABLED"`
 }
 
 // BotConfig holds bot-specific configuration (Webhook, Rate Limits, LINE API Constraints)
 type BotConfig struct {
 	// Webhook Configuration
-	WebhookTimeout time.Duration // Timeout for webhook bot processing (default: 60s)
+	WebhookTimeout time.Duration `env:"NTPU_WEBHOOK_TIMEOUT" example:"45s"`                                                      // Timeout for webhook bot processing (default: 60s)
+	WebhookDryRun  bool          `env:"NTPU_WEBHOOK_DRY_RUN"`                                                                    // Log replies and pushes instead of sending them, for shadow deployments (default: false)
+	WebhookRecord  string        `env:"NTPU_WEBHOOK_RECORD_FILE" example:"/data/webhook.jsonl"`                                  // Append received events and their replies to this JSON Lines file for cmd/replay (default: off)
+	LineAPIBaseURL string        `env:"NTPU_LINE_API_BASE_URL" format:"http:// or https:// URL" example:"http://localhost:8081"` // LINE Messaging API base URL override, e.g., the fake API of cmd/replay (default: LINE)
 
 	// Rate Limits - Per-User (Token Bucket Algorithm)
-	UserRateBurst  float64 // Burst capacity (default: 15)
-	UserRateRefill float64 // Refill rate per second (default: 0.1 = 1 per 10s)
+	UserRateBurst  float64 `env:"NTPU_USER_RATE_BURST" example:"20"`   // Burst capacity (default: 15)
+	UserRateRefill float64 `env:"NTPU_USER_RATE_REFILL" example:"0.2"` // Refill rate per second (default: 0.1 = 1 per 10s)
 
 	// Rate Limits - Per-User LLM (Multi-Layer: Hourly + Daily)
-	LLMRateBurst  float64 // Burst capacity for LLM (default: 60)
-	LLMRateRefill float64 // Refill rate per hour (default: 30)
-	LLMRateDaily  int     // Daily limit (default: 180, 0 = disabled)
+	LLMRateBurst  float64 `env:"NTPU_LLM_RATE_BURST" example:"40"`  // Burst capacity for LLM (default: 60)
+	LLMRateRefill float64 `env:"NTPU_LLM_RATE_REFILL" example:"20"` // Refill rate per hour (default: 30)
+	LLMRateDaily  int     `env:"NTPU_LLM_RATE_DAILY" example:"100"` // Daily limit (default: 180, 0 = disabled)
+
+	// Rate Limits - Per-User Per-Module (bot.RateLimit middleware)
+	ModuleRateBurst  float64 `env:"NTPU_MODULE_RATE_BURST" example:"5"`    // Burst capacity per module (default: 0 = disabled)
+	ModuleRateRefill float64 `env:"NTPU_MODULE_RATE_REFILL" example:"0.5"` // Refill rate per second (default: 0.2 = 1 per 5s)
 
 	// Rate Limits - Global
-	GlobalRateRPS float64 // Global rate limit in RPS (default: 100)
+	GlobalRateRPS float64 `env:"NTPU_GLOBAL_RATE_RPS" example:"50"` // Global rate limit in RPS (default: 100)
+
+	// Modules disabled at startup (can be re-enabled via admin API)
+	DisabledModules []string `env:"NTPU_DISABLED_MODULES" example:"course,program"` // Module names, e.g. ["course"] (default: none)
+
+	// Message template overrides (see internal/msgtmpl)
+	TemplateDir string `env:"NTPU_TEMPLATE_DIR" example:"/data/templates"` // Directory of *.tmpl files overriding embedded copy (default: "" = embedded only)
 
 	// LINE API Constraints (hard-coded, not configurable)
 	MaxMessagesPerReply int // LINE API limit: 5
@@ -161,6 +297,16 @@ type BotConfig struct {
 // Load reads configuration from environment variables
 // It attempts to load .env file first, then reads from env vars
 func Load() (*Config, error) {
+	cfg, err := Inspect()
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Inspect reads the configuration like Load, but also returns it when
+// validation fails, so --print-config can show what a broken deployment read.
+func Inspect() (*Config, error) {
 	// Try to load .env file (ignore error if file doesn't exist)
 	_ = godotenv.Load()
 
@@ -172,18 +318,38 @@ func Load() (*Config, error) {
 		// Server Configuration
 		Port:            getEnv(EnvPort, "10000"),
 		LogLevel:        getEnv(EnvLogLevel, "info"),
+		LogModuleLevels: getPairsEnv(EnvLogModuleLevels),
+		LogSampling:     getBoolEnv(EnvLogSampling, false),
+		LogRedactPII:    getBoolEnv(EnvLogRedactPII, true),
 		ShutdownTimeout: getDurationEnv(EnvShutdownTimeout, 30*time.Second),
 		ServerName:      getEnv(EnvServerName, ""),
 		InstanceID:      getEnv(EnvInstanceID, ""),
+		PublicBaseURL:   strings.TrimSuffix(getEnv(EnvPublicBaseURL, ""), "/"),
+		SkipPreflight:   getBoolEnv(EnvSkipPreflight, false),
 
 		// Data Configuration
-		DataDir:  getEnv(EnvDataDir, getDefaultDataDir()),
-		CacheTTL: getDurationEnv(EnvCacheTTL, 168*time.Hour), // 7 days
+		DataDir:             getEnv(EnvDataDir, getDefaultDataDir()),
+		CacheTTL:            getDurationEnv(EnvCacheTTL, 168*time.Hour), // 7 days
+		SyllabusCompression: getBoolEnv(EnvSyllabusCompression, false),
+		Tenant:              getEnv(EnvTenant, DefaultTenant),
+		IntegrityRepair:     getBoolEnv(EnvIntegrityRepair, false),
+		DBQueryAnalysis:     getBoolEnv(EnvDBQueryAnalysis, false),
+		CacheTTLs: TTLPolicy{
+			Contacts: getDurationEnv(EnvCacheTTLContacts, 720*time.Hour), // 30 days
+			Courses:  getDurationEnv(EnvCacheTTLCourses, 168*time.Hour),  // 7 days
+			Syllabi:  getDurationEnv(EnvCacheTTLSyllabi, 336*time.Hour),  // 14 days
+			Negative: getDurationEnv(EnvCacheTTLNegative, 10*time.Minute),
+			News:     getDurationEnv(EnvCacheTTLNews, 6*time.Hour),
+		},
+		AssetURLs: getPairsEnv(EnvAssetURLs),
 
 		// Bot Configuration (Webhook + Rate Limits + LINE API Constraints)
 		Bot: BotConfig{
 			// Webhook
 			WebhookTimeout: getDurationEnv(EnvWebhookTimeout, WebhookProcessing),
+			WebhookDryRun:  getBoolEnv(EnvWebhookDryRun, false),
+			WebhookRecord:  getEnv(EnvWebhookRecord, ""),
+			LineAPIBaseURL: strings.TrimSuffix(getEnv(EnvLineAPIBaseURL, ""), "/"),
 			// Rate Limits - Per-User
 			UserRateBurst:  getFloatEnv(EnvUserRateBurst, 15.0),
 			UserRateRefill: getFloatEnv(EnvUserRateRefill, 0.1),
@@ -191,8 +357,14 @@ func Load() (*Config, error) {
 			LLMRateBurst:  getFloatEnv(EnvLLMRateBurst, 60.0),
 			LLMRateRefill: getFloatEnv(EnvLLMRateRefill, 30.0),
 			LLMRateDaily:  getIntEnv(EnvLLMRateDaily, 180),
+			// Rate Limits - Per-Module
+			ModuleRateBurst:  getFloatEnv(EnvModuleRateBurst, 0),
+			ModuleRateRefill: getFloatEnv(EnvModuleRateRefill, 0.2),
 			// Rate Limits - Global
 			GlobalRateRPS: getFloatEnv(EnvGlobalRateRPS, 100.0),
+			// Modules
+			DisabledModules: getProvidersEnv(EnvDisabledModules, nil),
+			TemplateDir:     getEnv(EnvTemplateDir, ""),
 			// LINE API Constraints (hard-coded)
 			MaxMessagesPerReply: LINEMaxMessagesPerReply,
 			MaxEventsPerWebhook: 100,
@@ -208,20 +380,13 @@ func Load() (*Config, error) {
 		},
 
 		// Scraper Configuration
-		ScraperTimeout:    getDurationEnv(EnvScraperTimeout, ScraperRequest),
-		ScraperMaxRetries: getIntEnv(EnvScraperMaxRetries, 10),
-		ScraperBaseURLs: map[string][]string{
-			"lms": {
-				"http://120.126.197.52",
-				"https://120.126.197.52",
-				"https://lms.ntpu.edu.tw",
-			},
-			"sea": {
-				"http://120.126.197.7",
-				"https://120.126.197.7",
-				"https://sea.cc.ntpu.edu.tw",
-			},
-		},
+		ScraperTimeout:       getDurationEnv(EnvScraperTimeout, ScraperRequest),
+		ScraperMaxRetries:    getIntEnv(EnvScraperMaxRetries, 10),
+		ScraperBaseURLs:      DefaultScraperBaseURLs(),
+		ScraperUserAgents:    getUserAgentsEnv(EnvScraperUserAgents),
+		ScraperProxyURL:      getEnv(EnvScraperProxy, ""),
+		ScraperSourceProxies: getPairsEnv(EnvScraperSourceProxies),
+		ScraperBindAddr:      getEnv(EnvScraperBindAddr, ""),
 
 		// Maintenance Scheduling
 		WaitForWarmup:              getBoolEnv(EnvWarmupWait, false),
@@ -245,6 +410,8 @@ func Load() (*Config, error) {
 		OpenAIEndpoint:         getEnv(EnvOpenAIEndpoint, ""),
 		OpenAIIntentModels:     getModelsEnv(EnvOpenAIIntentModels),
 		OpenAIExpanderModels:   getModelsEnv(EnvOpenAIExpanderModels),
+		QueryExpansionPrompts:  getEnv(EnvQueryExpansionPrompts, ""),
+		LLMOutputBlocked:       getBoolEnv(EnvLLMOutputBlocked, false),
 
 		// 2. S3-Compatible Snapshot Storage
 		S3Enabled:              getBoolEnv(EnvS3Enabled, false),
@@ -277,16 +444,121 @@ func Load() (*Config, error) {
 		MetricsAuthEnabled: getBoolEnv(EnvMetricsAuthEnabled, false),
 		MetricsUsername:    getEnv(EnvMetricsUsername, "prometheus"),
 		MetricsPassword:    getEnv(EnvMetricsPassword, ""),
+
+		// 6. Admin API
+		AdminEnabled: getBoolEnv(EnvAdminEnabled, false),
+		AdminToken:   getEnv(EnvAdminToken, ""),
+		AdminUserIDs: getModelsEnv(EnvAdminUserIDs),
+
+		// 7. Analytics Rollups
+		AnalyticsEnabled: getBoolEnv(EnvAnalyticsEnabled, false),
+
+		// 8. Course Export
+		ExportEnabled: getBoolEnv(EnvExportEnabled, false),
+		ExportSecret:  getEnv(EnvExportSecret, ""),
+
+		// 9. Timetable Images
+		TimetableEnabled:  getBoolEnv(EnvTimetableEnabled, false),
+		TimetableFontPath: getEnv(EnvTimetableFont, ""),
+
+		// 10. Share Links
+		LineBotID: getEnv(EnvLineBotID, ""),
+
+		// 11. Course Filter LIFF
+		LIFFID: getEnv(EnvLIFFID, ""),
+
+		// 12. Query History
+		QueryHistoryEnabled: getBoolEnv(EnvQueryHistoryEnabled, false),
+
+		// 13. Group Leaderboard
+		GroupLeaderboardEnabled: getBoolEnv(EnvGroupLeaderboardEnabled, false),
+
+		// 14. Course Buzz
+		CourseBuzzEnabled: getBoolEnv(EnvCourseBuzzEnabled, false),
+		CourseBuzzTTL:     getDurationEnv(EnvCourseBuzzTTL, 30*24*time.Hour), // 30 days
+
+		// 15. Cache Backups
+		BackupDir:       getEnv(EnvBackupDir, ""),
+		BackupS3Prefix:  strings.Trim(getEnv(EnvBackupS3Prefix, ""), "/"),
+		BackupInterval:  getDurationEnv(EnvBackupInterval, BackupIntervalDefault),
+		BackupRetention: getIntEnv(EnvBackupRetention, 7),
+
+		// 16. Litestream Replication
+		LitestreamEnabled: getBoolEnv(EnvLitestreamEnabled, false),
+
+		// 17. Group Trigger Prefix
+		GroupPrefixEnabled: getBoolEnv(EnvGroupPrefixEnabled, false),
+
+		// 18. Account Linking
+		AccountLinkEnabled: getBoolEnv(EnvAccountLinkEnabled, false),
+		AccountTokenKey:    getEnv(EnvAccountTokenKey, ""),
+		SSOAuthURL:         getEnv(EnvSSOAuthURL, ""),
+		SSOTokenURL:        getEnv(EnvSSOTokenURL, ""),
+		SSOUserInfoURL:     getEnv(EnvSSOUserInfoURL, ""),
+		SSOClientID:        getEnv(EnvSSOClientID, ""),
+		SSOClientSecret:    getEnv(EnvSSOClientSecret, ""),
+		SSOScopes:          strings.Fields(getEnv(EnvSSOScopes, "openid profile")),
+
+		// 19. Staff Roles
+		StaffRolesEnabled: getBoolEnv(EnvStaffRolesEnabled, false),
+
+		// 20. Degraded Mode
+		DegradedModeEnabled: getBoolEnv(EnvDegradedModeEnabled, false),
+
+		// 21. Department News
+		DeptNewsURLs: getPairsEnv(EnvDeptNewsURLs),
+
+		// 22. Image Proxy
+		ImageProxyEnabled: getBoolEnv(EnvImageProxyEnabled, false),
+
+		// 23. Fault Injection
+		Faults: getPairsEnv(EnvFaults),
+
+		// 24. Memory Budget
+		MemoryBudgetMB: getIntEnv(EnvMemoryBudgetMB, 0),
+
+		// 25. Bug Reports
+		BugReportsEnabled: getBoolEnv(EnvBugReportsEnabled, false),
 	}
 
-	// Validate configuration
-	if err := cfg.Validate(); err != nil {
-		return nil, fmt.Errorf("config validation failed: %w", err)
+	// Validate configuration; malformed values are reported even though the
+	// getters fell back to defaults for them
+	if err := errors.Join(checkEnv(os.Getenv), cfg.Validate()); err != nil {
+		return cfg, fmt.Errorf("config validation failed: %w", err)
 	}
 
 	return cfg, nil
 }
 
+// minAdminTokenLength guards the admin API against trivially guessable tokens.
+const minAdminTokenLength = 16
+
+// minExportSecretLength guards export URL tokens against brute-forcing the HMAC key.
+const minExportSecretLength = 16
+
+// minAccountTokenKeyLength guards stored SSO tokens against brute-forcing the encryption key.
+const minAccountTokenKeyLength = 32
+
+// tenantPattern matches tenant names.
+var tenantPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// logLevels are the values accepted by NTPU_LOG_MODULE_LEVELS.
+var logLevels = []string{"debug", "info", "warn", "error"}
+
+// deptCodePattern matches department codes (e.g., "85", "712").
+var deptCodePattern = regexp.MustCompile(`^\d{2,3}$`)
+
+// NTPU_DEPT_NEWS_URLS keys: DeptNewsDefaultKey maps the departments without
+// their own entry to a URL template, where DeptNewsCodePlaceholder is
+// replaced by the department code.
+const (
+	DeptNewsDefaultKey      = "*"
+	DeptNewsCodePlaceholder = "{code}"
+)
+
+// liffIDPattern matches LIFF app IDs ("{channel ID}-{8 alphanumerics}").
+var liffIDPattern = regexp.MustCompile(`^\d+-[A-Za-z0-9]+$`)
+
 // Validate checks if required configuration values are set
 func (c *Config) Validate() error {
 	var errs []error
@@ -300,6 +572,11 @@ func (c *Config) Validate() error {
 	if c.Port == "" {
 		errs = append(errs, errors.New("NTPU_PORT is required"))
 	}
+	for module, level := range c.LogModuleLevels {
+		if !slices.Contains(logLevels, level) {
+			errs = append(errs, fmt.Errorf("NTPU_LOG_MODULE_LEVELS has invalid level %q for %q (want one of %v)", level, module, logLevels))
+		}
+	}
 	if err := c.Bot.Validate(); err != nil {
 		errs = append(errs, fmt.Errorf("bot config: %w", err))
 	}
@@ -309,6 +586,23 @@ func (c *Config) Validate() error {
 	if c.CacheTTL <= 0 {
 		errs = append(errs, fmt.Errorf("NTPU_CACHE_TTL must be positive, got %v", c.CacheTTL))
 	}
+	if err := c.CacheTTLs.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if c.Tenant != "" && !tenantPattern.MatchString(c.Tenant) {
+		errs = append(errs, fmt.Errorf("NTPU_TENANT must be 1-32 lowercase letters, digits or hyphens, got %q", c.Tenant))
+	}
+	// LINE only accepts HTTPS image URLs
+	if c.PublicBaseURL != "" {
+		if u, err := url.Parse(c.PublicBaseURL); err != nil || u.Scheme != "https" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("NTPU_PUBLIC_BASE_URL must be an https:// URL, got %q", c.PublicBaseURL))
+		}
+	}
+	for key, assetURL := range c.AssetURLs {
+		if u, err := url.Parse(assetURL); err != nil || u.Scheme != "https" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("NTPU_ASSET_URLS must map keys to https:// URLs, got %q for %q", assetURL, key))
+		}
+	}
 	if c.ScraperTimeout <= 0 {
 		errs = append(errs, fmt.Errorf("NTPU_SCRAPER_TIMEOUT must be positive, got %v", c.ScraperTimeout))
 	}
@@ -432,10 +726,137 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	// 6. Admin API Validation (only if enabled)
+	if c.IsAdminEnabled() && len(c.AdminToken) < minAdminTokenLength {
+		errs = append(errs, fmt.Errorf("NTPU_ADMIN_TOKEN must be at least %d characters when NTPU_ADMIN_ENABLED=true", minAdminTokenLength))
+	}
+
+	// 8. Course Export Validation (only if enabled)
+	if c.IsExportEnabled() && len(c.ExportSecret) < minExportSecretLength {
+		errs = append(errs, fmt.Errorf("NTPU_EXPORT_SECRET must be at least %d characters when NTPU_EXPORT_ENABLED=true", minExportSecretLength))
+	}
+
+	// 9. Timetable Images Validation (only if enabled)
+	if c.IsTimetableEnabled() {
+		if c.TimetableFontPath == "" {
+			errs = append(errs, errors.New("NTPU_TIMETABLE_FONT is required when NTPU_TIMETABLE_ENABLED=true"))
+		}
+		if c.PublicBaseURL == "" {
+			errs = append(errs, errors.New("NTPU_PUBLIC_BASE_URL is required when NTPU_TIMETABLE_ENABLED=true"))
+		}
+	}
+
+	// 10. Share Links Validation (only if enabled)
+	if c.IsShareEnabled() && !strings.HasPrefix(c.LineBotID, "@") {
+		errs = append(errs, fmt.Errorf("NTPU_LINE_BOT_ID must be the bot's basic ID starting with '@', got %q", c.LineBotID))
+	}
+
+	// 11. Course Filter LIFF Validation (only if enabled)
+	// The ID is embedded in the page script, so keep it to the console's {channel}-{suffix} form
+	if c.IsLIFFEnabled() && !liffIDPattern.MatchString(c.LIFFID) {
+		errs = append(errs, fmt.Errorf("NTPU_LIFF_ID must look like 1234567890-AbcdEfgh, got %q", c.LIFFID))
+	}
+
+	// 14. Course Buzz Validation (only if enabled)
+	if c.IsCourseBuzzEnabled() && c.CourseBuzzTTL <= 0 {
+		errs = append(errs, fmt.Errorf("NTPU_COURSE_BUZZ_TTL must be positive, got %v", c.CourseBuzzTTL))
+	}
+
+	// 15. Backup Validation (only if enabled)
+	if c.IsBackupEnabled() {
+		if c.BackupDir != "" && c.BackupS3Prefix != "" {
+			errs = append(errs, errors.New("set only one of NTPU_BACKUP_DIR and NTPU_BACKUP_S3_PREFIX"))
+		}
+		if c.BackupS3Prefix != "" && !c.IsS3Enabled() {
+			errs = append(errs, errors.New("NTPU_BACKUP_S3_PREFIX requires NTPU_S3_ENABLED=true"))
+		}
+		if c.BackupInterval <= 0 {
+			errs = append(errs, fmt.Errorf("NTPU_BACKUP_INTERVAL must be positive, got %v", c.BackupInterval))
+		}
+		if c.BackupRetention < 1 {
+			errs = append(errs, fmt.Errorf("NTPU_BACKUP_RETENTION must be at least 1, got %d", c.BackupRetention))
+		}
+	}
+
+	// 16. Litestream Validation: snapshot sync replaces cache.db, which a replica cannot follow
+	if c.IsLitestreamEnabled() && c.IsS3Enabled() {
+		errs = append(errs, errors.New("NTPU_LITESTREAM_ENABLED cannot be combined with NTPU_S3_ENABLED"))
+	}
+
+	// 18. Account Linking Validation (only if enabled)
+	if c.IsAccountLinkEnabled() {
+		if c.PublicBaseURL == "" {
+			errs = append(errs, errors.New("NTPU_PUBLIC_BASE_URL is required when NTPU_ACCOUNT_LINK_ENABLED=true"))
+		}
+		if len(c.AccountTokenKey) < minAccountTokenKeyLength {
+			errs = append(errs, fmt.Errorf("NTPU_ACCOUNT_TOKEN_KEY must be at least %d characters when NTPU_ACCOUNT_LINK_ENABLED=true", minAccountTokenKeyLength))
+		}
+		if c.SSOClientID == "" || c.SSOClientSecret == "" {
+			errs = append(errs, errors.New("NTPU_SSO_CLIENT_ID and NTPU_SSO_CLIENT_SECRET are required when NTPU_ACCOUNT_LINK_ENABLED=true"))
+		}
+		for _, endpoint := range []struct{ env, value string }{
+			{EnvSSOAuthURL, c.SSOAuthURL},
+			{EnvSSOTokenURL, c.SSOTokenURL},
+			{EnvSSOUserInfoURL, c.SSOUserInfoURL},
+		} {
+			if u, err := url.Parse(endpoint.value); err != nil || u.Scheme != "https" || u.Host == "" {
+				errs = append(errs, fmt.Errorf("%s must be an https:// URL when NTPU_ACCOUNT_LINK_ENABLED=true, got %q", endpoint.env, endpoint.value))
+			}
+		}
+	}
+
+	// 19. Staff Roles Validation: without chat admins nobody could grant a role
+	if c.IsStaffRolesEnabled() && len(c.AdminUserIDs) == 0 {
+		errs = append(errs, errors.New("NTPU_ADMIN_USER_IDS is required when NTPU_STAFF_ROLES_ENABLED=true"))
+	}
+
+	// 21. Department News Validation (only if enabled)
+	for code, pageURL := range c.DeptNewsURLs {
+		if code != DeptNewsDefaultKey && !deptCodePattern.MatchString(code) {
+			errs = append(errs, fmt.Errorf("NTPU_DEPT_NEWS_URLS has invalid department code %q", code))
+		}
+		if u, err := url.Parse(strings.ReplaceAll(pageURL, DeptNewsCodePlaceholder, "00")); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("NTPU_DEPT_NEWS_URLS must map codes to http(s) URLs, got %q for %q", pageURL, code))
+		}
+	}
+
+	// 22. Image Proxy Validation (only if enabled)
+	if c.IsImageProxyEnabled() && c.PublicBaseURL == "" {
+		errs = append(errs, errors.New("NTPU_PUBLIC_BASE_URL is required when NTPU_IMAGE_PROXY_ENABLED=true"))
+	}
+
+	// 23. Fault Injection Validation (only if enabled)
+	if c.IsFaultInjectionEnabled() && strings.EqualFold(c.SentryEnvironment, "production") {
+		errs = append(errs, errors.New("NTPU_FAULTS cannot be set when NTPU_SENTRY_ENVIRONMENT=production"))
+	}
+	for fault, rate := range c.Faults {
+		if r, err := strconv.ParseFloat(rate, 64); err != nil || r < 0 || r > 1 {
+			errs = append(errs, fmt.Errorf("NTPU_FAULTS rates must be between 0 and 1, got %q for %q", rate, fault))
+		}
+	}
+
+	// 24. Memory Budget Validation
+	if c.MemoryBudgetMB < 0 {
+		errs = append(errs, fmt.Errorf("NTPU_MEMORY_BUDGET_MB cannot be negative, got %d", c.MemoryBudgetMB))
+	}
+
+	// 25. Bug Reports Validation: reports would reach nobody without chat admins
+	if c.IsBugReportsEnabled() && len(c.AdminUserIDs) == 0 {
+		errs = append(errs, errors.New("NTPU_ADMIN_USER_IDS is required when NTPU_BUG_REPORTS_ENABLED=true"))
+	}
+
 	// Scraper internal validation
 	if c.ScraperMaxRetries < 0 {
 		errs = append(errs, fmt.Errorf("NTPU_SCRAPER_MAX_RETRIES cannot be negative, got %d", c.ScraperMaxRetries))
 	}
+	for source := range c.ScraperSourceProxies {
+		if _, ok := c.ScraperBaseURLs[source]; !ok {
+			errs = append(errs, fmt.Errorf("NTPU_SCRAPER_SOURCE_PROXIES has unknown source %q", source))
+		}
+	}
+	if c.ScraperBindAddr != "" && net.ParseIP(c.ScraperBindAddr) == nil {
+		errs = append(errs, fmt.Errorf("NTPU_SCRAPER_BIND_ADDR must be an IP address, got %q", c.ScraperBindAddr))
+	}
 	if c.WarmupMaxWait < 0 {
 		errs = append(errs, fmt.Errorf("NTPU_WARMUP_MAX_WAIT cannot be negative, got %v", c.WarmupMaxWait))
 	}
@@ -475,6 +896,110 @@ func (c *Config) IsMetricsAuthEnabled() bool {
 	return c.MetricsAuthEnabled
 }
 
+// IsAdminEnabled returns true if the admin API is enabled.
+func (c *Config) IsAdminEnabled() bool {
+	return c.AdminEnabled
+}
+
+// IsAnalyticsEnabled returns true if daily analytics rollups are enabled.
+func (c *Config) IsAnalyticsEnabled() bool {
+	return c.AnalyticsEnabled
+}
+
+// IsExportEnabled returns true if the course CSV/iCal export endpoints are enabled.
+func (c *Config) IsExportEnabled() bool {
+	return c.ExportEnabled
+}
+
+// IsTimetableEnabled returns true if timetable image rendering is enabled.
+func (c *Config) IsTimetableEnabled() bool {
+	return c.TimetableEnabled
+}
+
+// IsShareEnabled returns true if the 分享 button and deep links are enabled.
+func (c *Config) IsShareEnabled() bool {
+	return c.LineBotID != ""
+}
+
+// IsLIFFEnabled returns true if the course filter LIFF app is served.
+func (c *Config) IsLIFFEnabled() bool {
+	return c.LIFFID != ""
+}
+
+// IsQueryHistoryEnabled returns true if per-user query history is recorded.
+func (c *Config) IsQueryHistoryEnabled() bool {
+	return c.QueryHistoryEnabled
+}
+
+// IsGroupLeaderboardEnabled returns true if groups can opt in to the weekly leaderboard.
+func (c *Config) IsGroupLeaderboardEnabled() bool {
+	return c.GroupLeaderboardEnabled
+}
+
+// IsCourseBuzzEnabled returns true if course details show Dcard/選課大全 discussion counts.
+func (c *Config) IsCourseBuzzEnabled() bool {
+	return c.CourseBuzzEnabled
+}
+
+// IsBackupEnabled returns true if scheduled cache backups are configured.
+func (c *Config) IsBackupEnabled() bool {
+	return c.BackupDir != "" || c.BackupS3Prefix != ""
+}
+
+// IsLitestreamEnabled returns true if the SQLite files are streamed by a Litestream sidecar.
+func (c *Config) IsLitestreamEnabled() bool {
+	return c.LitestreamEnabled
+}
+
+// IsGroupPrefixEnabled returns true if groups can set a keyword trigger prefix.
+func (c *Config) IsGroupPrefixEnabled() bool {
+	return c.GroupPrefixEnabled
+}
+
+// IsAccountLinkEnabled returns true if users can link their NTPU SSO account.
+func (c *Config) IsAccountLinkEnabled() bool {
+	return c.AccountLinkEnabled
+}
+
+// IsStaffRolesEnabled returns true if staff-only contact fields are restricted
+// to users an admin verified.
+func (c *Config) IsStaffRolesEnabled() bool {
+	return c.StaffRolesEnabled
+}
+
+// IsDeptNewsEnabled returns true if department announcement pages are configured.
+func (c *Config) IsDeptNewsEnabled() bool {
+	return len(c.DeptNewsURLs) > 0
+}
+
+// IsImageProxyEnabled returns true if external images are served through /img.
+func (c *Config) IsImageProxyEnabled() bool {
+	return c.ImageProxyEnabled
+}
+
+// IsFaultInjectionEnabled returns true if scraper, storage, or LLM failures
+// are injected for resilience testing.
+func (c *Config) IsFaultInjectionEnabled() bool {
+	return len(c.Faults) > 0
+}
+
+// IsMemoryBudgetEnabled returns true if in-memory indexes and list caches
+// are capped at NTPU_MEMORY_BUDGET_MB.
+func (c *Config) IsMemoryBudgetEnabled() bool {
+	return c.MemoryBudgetMB > 0
+}
+
+// IsDegradedModeEnabled returns true if the cache is exported daily and the
+// export is served read-only when the database fails to open.
+func (c *Config) IsDegradedModeEnabled() bool {
+	return c.DegradedModeEnabled
+}
+
+// IsBugReportsEnabled returns true if users can report a wrong answer with 回報問題.
+func (c *Config) IsBugReportsEnabled() bool {
+	return c.BugReportsEnabled
+}
+
 // ----------------------------------------------------------------------------
 // Helper Methods
 // ----------------------------------------------------------------------------
@@ -537,7 +1062,7 @@ func getBoolEnv(key string, defaultValue bool) bool {
 	}
 }
 
-// getModelsEnv parses comma-separated model list from environment variable.
+// getModelsEnv parses comma-separated case-sensitive list (models, user IDs) from environment variable.
 // Returns nil if the environment variable is not set or empty.
 // Leading/trailing whitespace is trimmed from each model name.
 func getModelsEnv(key string) []string {
@@ -558,7 +1083,40 @@ func getModelsEnv(key string) []string {
 	return result
 }
 
-// getProvidersEnv parses comma-separated provider list from environment variable.
+// getUserAgentsEnv parses a "|"-separated user agent list from environment variable.
+// User agents contain commas and semicolons, so neither can separate them.
+// Returns nil if the environment variable is not set or empty.
+func getUserAgentsEnv(key string) []string {
+	var result []string
+	for agent := range strings.SplitSeq(os.Getenv(key), "|") {
+		if trimmed := strings.TrimSpace(agent); trimmed != "" {
+			result = append(result, trimmed)
+		}
+	}
+	return result
+}
+
+// getPairsEnv parses comma-separated name=value pairs
+// (e.g., "lms=socks5://proxy:1080,sea=direct") from environment variable.
+// Returns nil if the environment variable is not set or empty.
+// Pairs without "=" are skipped; names are lowercased.
+func getPairsEnv(key string) map[string]string {
+	var result map[string]string
+	for pair := range strings.SplitSeq(os.Getenv(key), ",") {
+		source, proxy, ok := strings.Cut(pair, "=")
+		source, proxy = strings.ToLower(strings.TrimSpace(source)), strings.TrimSpace(proxy)
+		if !ok || source == "" || proxy == "" {
+			continue
+		}
+		if result == nil {
+			result = make(map[string]string)
+		}
+		result[source] = proxy
+	}
+	return result
+}
+
+// getProvidersEnv parses comma-separated lowercase name list (providers, modules) from environment variable.
 // Returns defaultValue if the environment variable is not set or empty.
 // Leading/trailing whitespace is trimmed from each provider name.
 func getProvidersEnv(key string, defaultValue []string) []string {
@@ -587,12 +1145,103 @@ func getDefaultDataDir() string {
 	return "/data"
 }
 
+// DefaultScraperBaseURLs returns the NTPU endpoints tried in order by the
+// scraper client, keyed by system ("lms" for students, "sea" for courses and contacts).
+func DefaultScraperBaseURLs() map[string][]string {
+	return map[string][]string{
+		"lms": {
+			"http://120.126.197.52",
+			"https://120.126.197.52",
+			"https://lms.ntpu.edu.tw",
+		},
+		"sea": {
+			"http://120.126.197.7",
+			"https://120.126.197.7",
+			"https://sea.cc.ntpu.edu.tw",
+		},
+	}
+}
+
+// DefaultTenant is the tenant served when NTPU_TENANT is unset.
+const DefaultTenant = "ntpu"
+
 // SQLitePath returns the full path to the SQLite database file
 func (c *Config) SQLitePath() string {
 	return filepath.Join(c.DataDir, "cache.db")
 }
 
+// AnalyticsDBPath returns the full path to the analytics rollup database.
+// Kept separate from the cache DB so snapshot hot-swaps don't discard history.
+func (c *Config) AnalyticsDBPath() string {
+	return filepath.Join(c.DataDir, "analytics.db")
+}
+
+// QueryHistoryDBPath returns the full path to the per-user query history database.
+// Kept separate from the cache DB so snapshot hot-swaps don't discard history.
+func (c *Config) QueryHistoryDBPath() string {
+	return filepath.Join(c.DataDir, "history.db")
+}
+
+// LeaderboardDBPath returns the full path to the group leaderboard database.
+// Kept separate from the cache DB so snapshot hot-swaps don't discard counts.
+func (c *Config) LeaderboardDBPath() string {
+	return filepath.Join(c.DataDir, "leaderboard.db")
+}
+
+// CourseBuzzDBPath returns the full path to the course buzz database.
+// Kept separate from the cache DB so fetched counts outlive snapshot hot-swaps.
+func (c *Config) CourseBuzzDBPath() string {
+	return filepath.Join(c.DataDir, "buzz.db")
+}
+
+// GroupPrefixDBPath returns the full path to the group trigger prefix database.
+// Kept separate from the cache DB so snapshot hot-swaps don't discard settings.
+func (c *Config) GroupPrefixDBPath() string {
+	return filepath.Join(c.DataDir, "prefix.db")
+}
+
+// AccountDBPath returns the full path to the account link database.
+// Kept separate from the cache DB so snapshot hot-swaps don't discard links.
+func (c *Config) AccountDBPath() string {
+	return filepath.Join(c.DataDir, "account.db")
+}
+
+// RolesDBPath returns the full path to the staff roles database.
+// Kept separate from the cache DB so snapshot hot-swaps don't discard roles.
+func (c *Config) RolesDBPath() string {
+	return filepath.Join(c.DataDir, "roles.db")
+}
+
+// BugReportsDBPath returns the full path to the bug report database.
+// Kept separate from the cache DB so snapshot hot-swaps don't discard reports.
+func (c *Config) BugReportsDBPath() string {
+	return filepath.Join(c.DataDir, "bugreports.db")
+}
+
+// DegradedSnapshotPath returns the full path to the degraded mode snapshot.
+func (c *Config) DegradedSnapshotPath() string {
+	return filepath.Join(c.DataDir, "degraded-snapshot.json")
+}
+
 // S3Endpoint returns the configured S3-compatible endpoint URL.
 func (c *Config) S3Endpoint() string {
 	return c.S3EndpointURL
 }
+
+// Fingerprint returns a short hash of the effective configuration, so
+// dashboards can tell when a deploy changed settings. Secrets and the
+// per-node instance ID are left out: replicas with the same settings share
+// a fingerprint, and the hash cannot be used to guess a credential.
+func (c *Config) Fingerprint() string {
+	redacted := *c
+	redacted.InstanceID = ""
+	fields := reflect.ValueOf(&redacted).Elem()
+	for _, v := range schema() {
+		if secret := fields.FieldByIndex(v.index); v.Secret && secret.String() != "" {
+			secret.SetString("set") // Still fingerprints adding or removing a credential
+		}
+	}
+	// fmt prints map keys sorted, so equal configs hash equally
+	sum := sha256.Sum256(fmt.Appendf(nil, "%+v", redacted))
+	return hex.EncodeToString(sum[:6])
+}
@@ -10,20 +10,42 @@ const (
 	// Server
 	EnvPort            = "NTPU_PORT"
 	EnvLogLevel        = "NTPU_LOG_LEVEL"
+	EnvLogModuleLevels = "NTPU_LOG_MODULE_LEVELS"
+	EnvLogSampling     = "NTPU_LOG_SAMPLING"
+	EnvLogRedactPII    = "NTPU_LOG_REDACT_PII"
 	EnvShutdownTimeout = "NTPU_SHUTDOWN_TIMEOUT"
 	EnvServerName      = "NTPU_SERVER_NAME"
 	EnvInstanceID      = "NTPU_INSTANCE_ID"
+	EnvPublicBaseURL   = "NTPU_PUBLIC_BASE_URL"
+	EnvSkipPreflight   = "NTPU_SKIP_PREFLIGHT"
 
 	// Data
-	EnvDataDir  = "NTPU_DATA_DIR"
-	EnvCacheTTL = "NTPU_CACHE_TTL"
+	EnvDataDir             = "NTPU_DATA_DIR"
+	EnvCacheTTL            = "NTPU_CACHE_TTL"
+	EnvCacheTTLContacts    = "NTPU_CACHE_TTL_CONTACTS"
+	EnvCacheTTLCourses     = "NTPU_CACHE_TTL_COURSES"
+	EnvCacheTTLSyllabi     = "NTPU_CACHE_TTL_SYLLABI"
+	EnvCacheTTLNegative    = "NTPU_CACHE_TTL_NEGATIVE"
+	EnvCacheTTLNews        = "NTPU_CACHE_TTL_NEWS"
+	EnvSyllabusCompression = "NTPU_SYLLABUS_COMPRESSION"
+	EnvTenant              = "NTPU_TENANT"
+	EnvIntegrityRepair     = "NTPU_INTEGRITY_REPAIR"
+	EnvDBQueryAnalysis     = "NTPU_DB_QUERY_ANALYSIS"
+	EnvAssetURLs           = "NTPU_ASSET_URLS"
 
 	// Scraper
-	EnvScraperTimeout    = "NTPU_SCRAPER_TIMEOUT"
-	EnvScraperMaxRetries = "NTPU_SCRAPER_MAX_RETRIES"
+	EnvScraperTimeout       = "NTPU_SCRAPER_TIMEOUT"
+	EnvScraperMaxRetries    = "NTPU_SCRAPER_MAX_RETRIES"
+	EnvScraperUserAgents    = "NTPU_SCRAPER_USER_AGENTS"
+	EnvScraperProxy         = "NTPU_SCRAPER_PROXY"
+	EnvScraperSourceProxies = "NTPU_SCRAPER_SOURCE_PROXIES"
+	EnvScraperBindAddr      = "NTPU_SCRAPER_BIND_ADDR"
 
 	// Webhook
 	EnvWebhookTimeout = "NTPU_WEBHOOK_TIMEOUT"
+	EnvWebhookDryRun  = "NTPU_WEBHOOK_DRY_RUN"
+	EnvWebhookRecord  = "NTPU_WEBHOOK_RECORD_FILE"
+	EnvLineAPIBaseURL = "NTPU_LINE_API_BASE_URL"
 
 	// Rate Limits
 	EnvGlobalRateRPS  = "NTPU_GLOBAL_RATE_RPS"
@@ -33,6 +55,13 @@ const (
 	EnvLLMRateRefill  = "NTPU_LLM_RATE_REFILL"
 	EnvLLMRateDaily   = "NTPU_LLM_RATE_DAILY"
 
+	EnvModuleRateBurst  = "NTPU_MODULE_RATE_BURST"
+	EnvModuleRateRefill = "NTPU_MODULE_RATE_REFILL"
+
+	// Modules
+	EnvDisabledModules = "NTPU_DISABLED_MODULES"
+	EnvTemplateDir     = "NTPU_TEMPLATE_DIR"
+
 	// Maintenance Scheduling
 	EnvWarmupWait                 = "NTPU_WARMUP_WAIT"
 	EnvWarmupMaxWait              = "NTPU_WARMUP_MAX_WAIT"
@@ -59,6 +88,10 @@ const (
 	EnvOpenAIEndpoint       = "NTPU_OPENAI_ENDPOINT"
 	EnvOpenAIIntentModels   = "NTPU_OPENAI_INTENT_MODELS"
 	EnvOpenAIExpanderModels = "NTPU_OPENAI_EXPANDER_MODELS"
+	// Query Expansion Prompts
+	EnvQueryExpansionPrompts = "NTPU_QUERY_EXPANSION_PROMPTS"
+	// LLM Output Kill Switch
+	EnvLLMOutputBlocked = "NTPU_LLM_OUTPUT_BLOCKED"
 
 	// S3-Compatible Snapshot Feature
 	EnvS3Enabled              = "NTPU_S3_ENABLED"
@@ -91,4 +124,79 @@ const (
 	EnvMetricsAuthEnabled = "NTPU_METRICS_AUTH_ENABLED"
 	EnvMetricsUsername    = "NTPU_METRICS_USERNAME"
 	EnvMetricsPassword    = "NTPU_METRICS_PASSWORD"
+
+	// Admin API Feature
+	EnvAdminEnabled = "NTPU_ADMIN_ENABLED"
+	EnvAdminToken   = "NTPU_ADMIN_TOKEN"
+	EnvAdminUserIDs = "NTPU_ADMIN_USER_IDS"
+
+	// Analytics Feature
+	EnvAnalyticsEnabled = "NTPU_ANALYTICS_ENABLED"
+
+	// Export Feature
+	EnvExportEnabled = "NTPU_EXPORT_ENABLED"
+	EnvExportSecret  = "NTPU_EXPORT_SECRET"
+
+	// Timetable Image Feature
+	EnvTimetableEnabled = "NTPU_TIMETABLE_ENABLED"
+	EnvTimetableFont    = "NTPU_TIMETABLE_FONT"
+
+	// Share Link Feature
+	EnvLineBotID = "NTPU_LINE_BOT_ID"
+
+	// Course Filter LIFF Feature
+	EnvLIFFID = "NTPU_LIFF_ID"
+
+	// Query History Feature
+	EnvQueryHistoryEnabled = "NTPU_QUERY_HISTORY_ENABLED"
+
+	// Group Leaderboard Feature
+	EnvGroupLeaderboardEnabled = "NTPU_GROUP_LEADERBOARD_ENABLED"
+
+	// Course Buzz Feature
+	EnvCourseBuzzEnabled = "NTPU_COURSE_BUZZ_ENABLED"
+	EnvCourseBuzzTTL     = "NTPU_COURSE_BUZZ_TTL"
+
+	// Backup Feature
+	EnvBackupDir       = "NTPU_BACKUP_DIR"
+	EnvBackupS3Prefix  = "NTPU_BACKUP_S3_PREFIX"
+	EnvBackupInterval  = "NTPU_BACKUP_INTERVAL"
+	EnvBackupRetention = "NTPU_BACKUP_RETENTION"
+
+	// Litestream Replication Feature
+	EnvLitestreamEnabled = "NTPU_LITESTREAM_ENABLED"
+
+	// Group Trigger Prefix Feature
+	EnvGroupPrefixEnabled = "NTPU_GROUP_PREFIX_ENABLED"
+
+	// Account Linking Feature
+	EnvAccountLinkEnabled = "NTPU_ACCOUNT_LINK_ENABLED"
+	EnvAccountTokenKey    = "NTPU_ACCOUNT_TOKEN_KEY"
+	EnvSSOAuthURL         = "NTPU_SSO_AUTH_URL"
+	EnvSSOTokenURL        = "NTPU_SSO_TOKEN_URL"
+	EnvSSOUserInfoURL     = "NTPU_SSO_USERINFO_URL"
+	EnvSSOClientID        = "NTPU_SSO_CLIENT_ID"
+	EnvSSOClientSecret    = "NTPU_SSO_CLIENT_SECRET"
+	EnvSSOScopes          = "NTPU_SSO_SCOPES"
+
+	// Staff Roles Feature
+	EnvStaffRolesEnabled = "NTPU_STAFF_ROLES_ENABLED"
+
+	// Degraded Mode Feature
+	EnvDegradedModeEnabled = "NTPU_DEGRADED_MODE_ENABLED"
+
+	// Department News Feature
+	EnvDeptNewsURLs = "NTPU_DEPT_NEWS_URLS"
+
+	// Image Proxy Feature
+	EnvImageProxyEnabled = "NTPU_IMAGE_PROXY_ENABLED"
+
+	// Fault Injection (resilience testing only)
+	EnvFaults = "NTPU_FAULTS"
+
+	// Memory Budget (BM25 index and fuzzy-match list caches)
+	EnvMemoryBudgetMB = "NTPU_MEMORY_BUDGET_MB"
+
+	// Bug Report Feature
+	EnvBugReportsEnabled = "NTPU_BUG_REPORTS_ENABLED"
 )
@@ -0,0 +1,276 @@
+package config
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/url"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Variable types in the schema.
+const (
+	TypeString   = "string"
+	TypeBool     = "bool"
+	TypeInt      = "int"
+	TypeFloat    = "float"
+	TypeDuration = "duration"
+	TypeList     = "list"  // Separated values, comma-separated unless Variable.Separator says otherwise
+	TypePairs    = "pairs" // Comma-separated name=value pairs
+)
+
+// Variable describes one environment variable read by Load. The schema is
+// generated from the struct tags of Config fields:
+//
+//	env:"NTPU_X"        variable name (fields without it are not configurable)
+//	format:"..."        expected value, when the type alone does not say it
+//	sep:"|"             list separator other than a comma
+//	example:"..."       example value
+//	secret:"true"       masked by Settings and Fingerprint
+type Variable struct {
+	Name      string `json:"name"`
+	Field     string `json:"field"` // Config field, e.g., CacheTTLs.Contacts
+	Type      string `json:"type"`
+	Format    string `json:"format"`
+	Separator string `json:"separator,omitempty"`
+	Example   string `json:"example"`
+	Secret    bool   `json:"secret,omitempty"`
+
+	index []int // Field index in Config
+}
+
+// typeFormats describe the values each type accepts, for Variable.Format.
+var typeFormats = map[string]string{
+	TypeString:   "text",
+	TypeBool:     "true or false (also 1/0, yes/no)",
+	TypeInt:      "integer",
+	TypeFloat:    "number",
+	TypeDuration: "Go duration: numbers with units ns, us, ms, s, m, h (no d; 7 days is 168h)",
+	TypeList:     "comma-separated values",
+	TypePairs:    "comma-separated name=value pairs",
+}
+
+// typeExamples are the examples of variables without an example tag.
+var typeExamples = map[string]string{
+	TypeBool: "true",
+}
+
+var durationType = reflect.TypeFor[time.Duration]()
+
+// schema is built once; Config's tags do not change at runtime.
+var schema = sync.OnceValue(func() []Variable {
+	return schemaOf(reflect.TypeFor[Config](), "", nil)
+})
+
+// Schema returns every configurable environment variable, in Config field order.
+func Schema() []Variable {
+	return slices.Clone(schema())
+}
+
+// WriteSchema writes Schema as indented JSON, the format of docs/config-schema.json.
+func WriteSchema(w io.Writer) error {
+	return writeJSON(w, Schema())
+}
+
+// schemaOf collects the variables of struct t, recursing into untagged
+// struct fields (BotConfig, TTLPolicy).
+func schemaOf(t reflect.Type, prefix string, index []int) []Variable {
+	var vars []Variable
+	for i := range t.NumField() {
+		f := t.Field(i)
+		fieldIndex := append(slices.Clone(index), i)
+		name, ok := f.Tag.Lookup("env")
+		if !ok {
+			if f.Type.Kind() == reflect.Struct && f.Type != durationType {
+				vars = append(vars, schemaOf(f.Type, prefix+f.Name+".", fieldIndex)...)
+			}
+			continue
+		}
+		typ := typeOf(f.Type)
+		v := Variable{
+			Name:      name,
+			Field:     prefix + f.Name,
+			Type:      typ,
+			Format:    cmp.Or(f.Tag.Get("format"), typeFormats[typ]),
+			Separator: f.Tag.Get("sep"),
+			Example:   cmp.Or(f.Tag.Get("example"), typeExamples[typ]),
+			Secret:    f.Tag.Get("secret") == "true",
+			index:     fieldIndex,
+		}
+		if v.Separator != "" && f.Tag.Get("format") == "" {
+			v.Format = fmt.Sprintf("values separated by %q", v.Separator)
+		}
+		vars = append(vars, v)
+	}
+	return vars
+}
+
+// typeOf maps a Config field type to its schema type. Tagging a field of
+// any other type is a programming error caught by the schema tests.
+func typeOf(t reflect.Type) string {
+	switch {
+	case t == durationType:
+		return TypeDuration
+	case t.Kind() == reflect.String:
+		return TypeString
+	case t.Kind() == reflect.Bool:
+		return TypeBool
+	case t.Kind() == reflect.Int:
+		return TypeInt
+	case t.Kind() == reflect.Float64:
+		return TypeFloat
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.String:
+		return TypeList
+	case t.Kind() == reflect.Map && t.Key().Kind() == reflect.String && t.Elem().Kind() == reflect.String:
+		return TypePairs
+	default:
+		panic(fmt.Sprintf("config: unsupported type %s for an env tag", t))
+	}
+}
+
+// checkEnv reports the set variables whose values do not parse as their
+// type. The get*Env helpers fall back to the default for such values, which
+// would otherwise hide a typo like NTPU_CACHE_TTL=7d until much later.
+func checkEnv(getenv func(string) string) error {
+	var errs []error
+	for _, v := range schema() {
+		value := getenv(v.Name)
+		if value == "" {
+			continue
+		}
+		if err := v.check(value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// check validates value against the variable's type, with the expected
+// format and an example in the error.
+func (v Variable) check(value string) error {
+	var problem string
+	switch v.Type {
+	case TypeBool:
+		if !slices.Contains([]string{"true", "1", "yes", "false", "0", "no"}, strings.ToLower(value)) {
+			problem = "is not a boolean"
+		}
+	case TypeInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			problem = "is not an integer"
+		}
+	case TypeFloat:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			problem = "is not a number"
+		}
+	case TypeDuration:
+		if _, err := time.ParseDuration(value); err != nil {
+			problem = "is not a duration"
+		}
+	case TypePairs:
+		for pair := range strings.SplitSeq(value, ",") {
+			name, val, ok := strings.Cut(pair, "=")
+			if strings.TrimSpace(pair) != "" && (!ok || strings.TrimSpace(name) == "" || strings.TrimSpace(val) == "") {
+				problem = fmt.Sprintf("has %q, which is not a name=value pair", strings.TrimSpace(pair))
+				break
+			}
+		}
+	}
+	if problem == "" {
+		return nil
+	}
+	shown := strconv.Quote(value)
+	if v.Secret {
+		shown = "(secret)"
+	}
+	return fmt.Errorf("%s=%s %s; expected %s, e.g. %s=%s", v.Name, shown, problem, v.Format, v.Name, v.Example)
+}
+
+// Setting is the effective value of a Variable, as --print-config shows it.
+type Setting struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`  // Secrets masked, URL passwords redacted
+	Source string `json:"source"` // "env" or "default"
+}
+
+// maskedValue replaces set secrets in Settings.
+const maskedValue = "********"
+
+// Settings returns the value of every variable in c, in the format the
+// variable is set in. Secrets are masked and passwords in URLs (such as
+// proxy credentials) redacted, so the output is safe to paste into an issue.
+// Source tells whether the variable is set in the environment.
+func (c *Config) Settings() []Setting {
+	cfg := reflect.ValueOf(c).Elem()
+	vars := schema()
+	settings := make([]Setting, 0, len(vars))
+	for _, v := range vars {
+		s := Setting{Name: v.Name, Value: formatValue(v, cfg.FieldByIndex(v.index)), Source: "default"}
+		if os.Getenv(v.Name) != "" {
+			s.Source = "env"
+		}
+		if v.Secret && s.Value != "" {
+			s.Value = maskedValue
+		}
+		settings = append(settings, s)
+	}
+	return settings
+}
+
+// WriteSettings writes c.Settings as indented JSON.
+func (c *Config) WriteSettings(w io.Writer) error {
+	return writeJSON(w, c.Settings())
+}
+
+// formatValue formats a field value the way its variable is written.
+func formatValue(v Variable, field reflect.Value) string {
+	switch v.Type {
+	case TypeDuration:
+		return time.Duration(field.Int()).String()
+	case TypeBool:
+		return strconv.FormatBool(field.Bool())
+	case TypeInt:
+		return strconv.FormatInt(field.Int(), 10)
+	case TypeFloat:
+		return strconv.FormatFloat(field.Float(), 'g', -1, 64)
+	case TypeList:
+		return strings.Join(field.Interface().([]string), cmp.Or(v.Separator, ","))
+	case TypePairs:
+		pairs := field.Interface().(map[string]string)
+		parts := make([]string, 0, len(pairs))
+		for _, name := range slices.Sorted(maps.Keys(pairs)) {
+			parts = append(parts, name+"="+redactURL(pairs[name]))
+		}
+		return strings.Join(parts, ",")
+	default:
+		return redactURL(field.String())
+	}
+}
+
+// redactURL masks the password of a URL with credentials and returns
+// anything else unchanged.
+func redactURL(s string) string {
+	if !strings.Contains(s, "@") {
+		return s
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.User == nil {
+		return s
+	}
+	return u.Redacted()
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
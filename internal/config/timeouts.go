@@ -20,6 +20,103 @@ const (
 
 	// WebhookHTTPIdle is the HTTP server idle timeout for keep-alive connections.
 	WebhookHTTPIdle = 120 * time.Second
+
+	// ReplyTokenTTL is how long a reply token is trusted after its event timestamp.
+	// LINE only guarantees reply tokens for about one minute; 50s leaves headroom
+	// for the reply call itself. Older tokens fall back to push in 1:1 chats.
+	ReplyTokenTTL = 50 * time.Second
+
+	// WebhookEventDedupTTL is how long handled webhook event IDs are remembered,
+	// so a redelivery of an event that was already processed is skipped.
+	WebhookEventDedupTTL = time.Hour
+)
+
+// LINE Messaging API
+const (
+	// LINEAPIRetryInitial is the initial backoff for retrying 429/5xx LINE API responses (500ms -> 1s -> 2s).
+	LINEAPIRetryInitial = 500 * time.Millisecond
+
+	// LINEAPIMaxRetries is the maximum number of retries for a LINE API call.
+	// Kept small so replies still land well within the reply token lifetime.
+	LINEAPIMaxRetries = 3
+
+	// LINEQuotaRefreshInterval is how often the monthly message quota is re-read from LINE.
+	LINEQuotaRefreshInterval = 15 * time.Minute
+)
+
+// Analytics rollups
+const (
+	// AnalyticsFlushInterval is how often counter deltas are written to the analytics DB.
+	// Short enough that deltas land on the correct calendar day.
+	AnalyticsFlushInterval = 10 * time.Minute
+
+	// AnalyticsRetention is how long daily rollups are kept.
+	AnalyticsRetention = 400 * 24 * time.Hour
+)
+
+// Query history
+const (
+	// QueryHistoryRetention is how long an entry is kept; users inactive longer lose their history.
+	QueryHistoryRetention = 90 * 24 * time.Hour
+
+	// QueryHistoryPruneInterval is how often expired history entries are deleted.
+	QueryHistoryPruneInterval = 24 * time.Hour
+)
+
+// Bug reports
+const (
+	// BugReportExchangeRetention is how long a user's last exchange is kept
+	// for 回報問題; older exchanges can no longer be reported.
+	BugReportExchangeRetention = 7 * 24 * time.Hour
+
+	// BugReportRetention is how long filed bug reports are kept.
+	BugReportRetention = 180 * 24 * time.Hour
+
+	// BugReportPruneInterval is how often expired exchanges and reports are deleted.
+	BugReportPruneInterval = 24 * time.Hour
+
+	// BugReportNotifyTimeout bounds the admin push for one report.
+	BugReportNotifyTimeout = 10 * time.Second
+)
+
+// Group leaderboard
+const (
+	// LeaderboardCheckInterval is how often opted-in groups are checked for a due weekly post.
+	LeaderboardCheckInterval = time.Hour
+
+	// LeaderboardPostWeekday and LeaderboardPostHour set when the weekly post goes
+	// out (Asia/Taipei); it covers the previous Monday–Sunday.
+	LeaderboardPostWeekday = time.Monday
+	LeaderboardPostHour    = 9
+
+	// LeaderboardRetention is how long weekly counts are kept.
+	LeaderboardRetention = 8 * 7 * 24 * time.Hour
+)
+
+// Course buzz (Dcard/選課大全 discussion counts)
+const (
+	// CourseBuzzRequestTimeout bounds one request to a third-party source.
+	CourseBuzzRequestTimeout = 10 * time.Second
+
+	// CourseBuzzFetchInterval paces background fetches so third-party sites
+	// see at most one request per source every few seconds.
+	CourseBuzzFetchInterval = 3 * time.Second
+
+	// CourseBuzzPruneInterval is how often long-stale counts are deleted.
+	CourseBuzzPruneInterval = 24 * time.Hour
+)
+
+// Image proxy (GET /img)
+const (
+	// ImageProxyRequestTimeout bounds fetching one upstream image.
+	ImageProxyRequestTimeout = 10 * time.Second
+
+	// ImageProxyTTL is how long a fetched image is served before it is refetched.
+	ImageProxyTTL = 24 * time.Hour
+
+	// ImageProxyRetryAfter is how long a failed fetch is remembered; the stale
+	// image or the placeholder is served until the upstream is tried again.
+	ImageProxyRetryAfter = 10 * time.Minute
 )
 
 // Sentry timeouts
@@ -41,6 +138,14 @@ const (
 
 	// ScraperRateLimit is the minimum delay between consecutive requests.
 	ScraperRateLimit = 2 * time.Second
+
+	// ScraperDriftAlertInterval is the minimum gap between admin push alerts for
+	// the same drifting parser. Drift repeats on every scrape until the parser
+	// is fixed; the metric counts every occurrence.
+	ScraperDriftAlertInterval = 6 * time.Hour
+
+	// ScraperDriftAlertTimeout bounds pushing one drift alert to admins.
+	ScraperDriftAlertTimeout = 10 * time.Second
 )
 
 // Database timeouts
@@ -51,6 +156,10 @@ const (
 	// DatabaseConnMaxLifetime is the maximum lifetime of database connections.
 	DatabaseConnMaxLifetime = time.Hour
 
+	// SlowQueryThreshold is the duration above which a database query is
+	// logged as slow (and explained when NTPU_DB_QUERY_ANALYSIS is set).
+	SlowQueryThreshold = 100 * time.Millisecond
+
 	// HotSwapCloseGracePeriod is the delay before closing old SQLite connections
 	// after a hot-swap, giving in-flight queries time to finish.
 	HotSwapCloseGracePeriod = 5 * time.Second
@@ -61,6 +170,18 @@ const (
 	// S3RequestTimeout is the timeout for a single S3 request.
 	S3RequestTimeout = 60 * time.Second
 
+	// BackupTimeout bounds one cache backup (copy, compress, upload and pruning).
+	BackupTimeout = 10 * time.Minute
+
+	// DegradedExportInterval is the age at which the degraded mode snapshot is rewritten.
+	DegradedExportInterval = 24 * time.Hour
+
+	// DegradedExportCheckInterval is how often the snapshot age is checked.
+	DegradedExportCheckInterval = 10 * time.Minute
+
+	// DegradedExportTimeout bounds one degraded mode snapshot export.
+	DegradedExportTimeout = 5 * time.Minute
+
 	// S3LockMinimumTTL is the minimum safe TTL for the leader lease.
 	// The renew loop runs at TTL/3 with a 10s minimum interval, so values below
 	// 30s can expire before the first renewal attempt.
@@ -75,9 +196,16 @@ const (
 	// MaintenanceCleanupIntervalDefault is the default interval for cleanup tasks.
 	MaintenanceCleanupIntervalDefault = 24 * time.Hour
 
+	// MaintenanceCleanupJitter bounds the random delay before the first cleanup
+	// after startup, so instances restarted together do not VACUUM at once.
+	MaintenanceCleanupJitter = 5 * time.Minute
+
 	// S3SnapshotPollIntervalDefault is the default interval for polling S3 snapshots.
 	S3SnapshotPollIntervalDefault = 15 * time.Minute
 
+	// BackupIntervalDefault is the default interval for cache backups.
+	BackupIntervalDefault = 24 * time.Hour
+
 	// MetricsUpdateInterval is how often cache size metrics are updated.
 	MetricsUpdateInterval = 5 * time.Minute
 
@@ -88,6 +216,36 @@ const (
 	SessionCleanupInterval = 5 * time.Minute
 )
 
+// Error rate alerts (pushed to NTPU_ADMIN_USER_IDS)
+const (
+	// ErrorAlertWindow is the sliding window an error rate must stay above its
+	// threshold over before admins are alerted, so single bursts stay quiet.
+	ErrorAlertWindow = 10 * time.Minute
+
+	// ErrorAlertCheckInterval is how often error rates are sampled.
+	ErrorAlertCheckInterval = time.Minute
+
+	// ErrorAlertCooldown is the minimum gap between alerts for the same rule.
+	ErrorAlertCooldown = time.Hour
+
+	// ErrorAlertTimeout bounds pushing one alert to admins.
+	ErrorAlertTimeout = 10 * time.Second
+)
+
+// Admin console (GET /admin/console)
+const (
+	// ConsoleKeepalive is how often an idle console stream sends an SSE
+	// comment, so proxies do not close it.
+	ConsoleKeepalive = 15 * time.Second
+)
+
+// Startup preflight (skipped with NTPU_SKIP_PREFLIGHT)
+const (
+	// PreflightTimeout bounds each startup dependency check (LINE token,
+	// Gemini key, scraper DNS, database write). The checks run concurrently.
+	PreflightTimeout = 10 * time.Second
+)
+
 // Warmup timeouts
 const (
 	// WarmupStickerFetch is the timeout for fetching stickers from external sources.
@@ -131,6 +289,16 @@ const (
 	// Set to 3s to allow SQLite ping operations to complete while maintaining
 	// fast probe responses for Kubernetes orchestration.
 	ReadinessCheckTimeout = 3 * time.Second
+
+	// SelfTestTimeout bounds the admin 健康檢查 chat command.
+	// Checks run concurrently, so this is the budget for the slowest one
+	// (usually the scraper or LLM ping), well below the reply token TTL.
+	SelfTestTimeout = 10 * time.Second
+
+	// DeepSearchTimeout bounds the confirmed course deep search, which scrapes
+	// every course of the searched semesters to match teacher names. It runs
+	// as a background job after the reply, so it may exceed WebhookProcessing.
+	DeepSearchTimeout = 3 * time.Minute
 )
 
 // Session timeouts
@@ -139,6 +307,26 @@ const (
 	// After this duration, intents are considered stale and filtered out.
 	// The session store holds at most 3 intents per user.
 	SessionContextTTL = 5 * time.Minute
+
+	// IntentReformulationWindow is how soon another text message must follow
+	// a handled one to count as the user reformulating it (intent telemetry).
+	IntentReformulationWindow = 30 * time.Second
+
+	// GroupAnswerDedupWindow is how long an answered keyword query is remembered
+	// per group chat; repeating it within the window gets a short pointer back
+	// to the first answer instead of the same reply again.
+	GroupAnswerDedupWindow = time.Minute
+)
+
+// Account linking
+const (
+	// AccountLinkRequestTTL is how long a started link may take: from opening
+	// the link page through the SSO login to LINE confirming the link. It
+	// matches the lifetime of LINE link tokens.
+	AccountLinkRequestTTL = 10 * time.Minute
+
+	// SSORequestTimeout bounds each call to the school SSO token and userinfo endpoints.
+	SSORequestTimeout = 10 * time.Second
 )
 
 // Sticker & semester cache timeouts
@@ -151,6 +339,10 @@ const (
 	// SemesterCacheRefreshTimeout is the timeout for refreshing the in-memory semester
 	// cache from SQLite. This is a fast DB-only operation with no network calls.
 	SemesterCacheRefreshTimeout = 5 * time.Second
+
+	// EnrollmentCourseTTL caps the course cache TTL during 加退選, when times,
+	// rooms and sections change daily. Course lookups older than this re-scrape.
+	EnrollmentCourseTTL = 6 * time.Hour
 )
 
 // Graceful shutdown
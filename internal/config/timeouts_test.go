@@ -16,6 +16,7 @@ func TestWebhookTimeouts(t *testing.T) {
 		{"WebhookHTTPRead", WebhookHTTPRead, 10 * time.Second},
 		{"WebhookHTTPWrite", WebhookHTTPWrite, 65 * time.Second},
 		{"WebhookHTTPIdle", WebhookHTTPIdle, 120 * time.Second},
+		{"WebhookEventDedupTTL", WebhookEventDedupTTL, time.Hour},
 	}
 
 	for _, tt := range tests {
@@ -139,6 +140,54 @@ func TestWarmupTimeouts(t *testing.T) {
 	}
 }
 
+// TestCourseBuzzTimeouts verifies course buzz fetch constants
+func TestCourseBuzzTimeouts(t *testing.T) {
+	tests := []struct {
+		name     string
+		got      time.Duration
+		expected time.Duration
+	}{
+		{"CourseBuzzRequestTimeout", CourseBuzzRequestTimeout, 10 * time.Second},
+		{"CourseBuzzFetchInterval", CourseBuzzFetchInterval, 3 * time.Second},
+		{"CourseBuzzPruneInterval", CourseBuzzPruneInterval, 24 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.expected {
+				t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestImageProxyTimeouts verifies image proxy fetch and cache constants
+func TestPreflightTimeout(t *testing.T) {
+	if PreflightTimeout != 10*time.Second {
+		t.Errorf("PreflightTimeout = %v, want 10s", PreflightTimeout)
+	}
+}
+
+func TestImageProxyTimeouts(t *testing.T) {
+	tests := []struct {
+		name     string
+		got      time.Duration
+		expected time.Duration
+	}{
+		{"ImageProxyRequestTimeout", ImageProxyRequestTimeout, 10 * time.Second},
+		{"ImageProxyTTL", ImageProxyTTL, 24 * time.Hour},
+		{"ImageProxyRetryAfter", ImageProxyRetryAfter, 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.expected {
+				t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.expected)
+			}
+		})
+	}
+}
+
 // TestSmartSearchTimeouts verifies smart search and readiness timeout constants
 func TestSmartSearchTimeouts(t *testing.T) {
 	tests := []struct {
@@ -149,6 +198,7 @@ func TestSmartSearchTimeouts(t *testing.T) {
 		{"SmartSearchTimeout", SmartSearchTimeout, 30 * time.Second},
 		{"QueryExpansionTimeout", QueryExpansionTimeout, 8 * time.Second},
 		{"ReadinessCheckTimeout", ReadinessCheckTimeout, 3 * time.Second},
+		{"DeepSearchTimeout", DeepSearchTimeout, 3 * time.Minute},
 	}
 
 	for _, tt := range tests {
@@ -175,6 +225,10 @@ func TestSessionAndCacheTimeouts(t *testing.T) {
 		expected time.Duration
 	}{
 		{"SessionContextTTL", SessionContextTTL, 5 * time.Minute},
+		{"IntentReformulationWindow", IntentReformulationWindow, 30 * time.Second},
+		{"GroupAnswerDedupWindow", GroupAnswerDedupWindow, time.Minute},
+		{"AccountLinkRequestTTL", AccountLinkRequestTTL, 10 * time.Minute},
+		{"SSORequestTimeout", SSORequestTimeout, 10 * time.Second},
 		{"StickerLoadTimeout", StickerLoadTimeout, 5 * time.Minute},
 		{"SemesterCacheRefreshTimeout", SemesterCacheRefreshTimeout, 5 * time.Second},
 	}
@@ -220,4 +274,10 @@ func TestTimeoutRelationships(t *testing.T) {
 		t.Errorf("QueryExpansionTimeout (%v) should be < SmartSearchTimeout (%v)",
 			QueryExpansionTimeout, SmartSearchTimeout)
 	}
+
+	// Deep search runs after the reply and needs more than one all-course scrape
+	if DeepSearchTimeout <= ScraperRequest {
+		t.Errorf("DeepSearchTimeout (%v) should be > ScraperRequest (%v)",
+			DeepSearchTimeout, ScraperRequest)
+	}
 }
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// TTLPolicy holds the cache TTL of each kind of cached entity. Contacts
+// rarely change, while course data moves during the semester; a single TTL
+// would either serve stale courses or re-scrape contacts needlessly.
+// A zero field falls back to CacheTTL.
+type TTLPolicy struct {
+	Contacts time.Duration `env:"NTPU_CACHE_TTL_CONTACTS" example:"1440h"` // contacts (default: 30 days)
+	Courses  time.Duration `env:"NTPU_CACHE_TTL_COURSES" example:"72h"`    // courses, historical courses, teachers, sections, majors and program links (default: 7 days)
+	Syllabi  time.Duration `env:"NTPU_CACHE_TTL_SYLLABI" example:"720h"`   // syllabi and prerequisites (default: 14 days)
+	Negative time.Duration `env:"NTPU_CACHE_TTL_NEGATIVE" example:"30m"`   // lookups the source returned nothing for, e.g. an unknown course UID (default: 10 minutes)
+	News     time.Duration `env:"NTPU_CACHE_TTL_NEWS" example:"12h"`       // department website announcements (default: 6 hours)
+}
+
+// Validate checks that no TTL is negative.
+func (p TTLPolicy) Validate() error {
+	for _, f := range []struct {
+		env string
+		ttl time.Duration
+	}{
+		{EnvCacheTTLContacts, p.Contacts},
+		{EnvCacheTTLCourses, p.Courses},
+		{EnvCacheTTLSyllabi, p.Syllabi},
+		{EnvCacheTTLNegative, p.Negative},
+		{EnvCacheTTLNews, p.News},
+	} {
+		if f.ttl < 0 {
+			return fmt.Errorf("%s must not be negative, got %v", f.env, f.ttl)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTTLPolicy_Validate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		policy  TTLPolicy
+		wantErr bool
+	}{
+		{"defaults", TTLPolicy{Contacts: 720 * time.Hour, Courses: 168 * time.Hour, Syllabi: 336 * time.Hour, Negative: 10 * time.Minute}, false},
+		{"zero falls back", TTLPolicy{}, false},
+		{"negative courses", TTLPolicy{Courses: -time.Hour}, true},
+		{"negative miss TTL", TTLPolicy{Negative: -time.Minute}, true},
+		{"negative news TTL", TTLPolicy{News: -time.Hour}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if err := tt.policy.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_CacheTTLs(t *testing.T) {
+	t.Setenv(EnvLineChannelAccessToken, "test_token")
+	t.Setenv(EnvLineChannelSecret, "test_secret")
+	t.Setenv(EnvCacheTTLSyllabi, "48h")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	want := TTLPolicy{Contacts: 720 * time.Hour, Courses: 168 * time.Hour, Syllabi: 48 * time.Hour, Negative: 10 * time.Minute, News: 6 * time.Hour}
+	if cfg.CacheTTLs != want {
+		t.Errorf("CacheTTLs = %+v, want %+v", cfg.CacheTTLs, want)
+	}
+}
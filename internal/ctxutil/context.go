@@ -4,6 +4,7 @@ package ctxutil
 
 import (
 	"context"
+	"log/slog"
 )
 
 type contextKey string
@@ -15,6 +16,10 @@ const (
 	eventIDKey    contextKey = "ctxutil.eventID"
 	messageIDKey  contextKey = "ctxutil.messageID"
 	quoteTokenKey contextKey = "ctxutil.quoteToken" //nolint:gosec // G101: False positive - this is a context key name, not a credential
+	loadingKey    contextKey = "ctxutil.loading"
+	rawTextKey    contextKey = "ctxutil.rawText"
+	langKey       contextKey = "ctxutil.lang"
+	loggerKey     contextKey = "ctxutil.logger"
 )
 
 // WithUserID adds a user ID to the context.
@@ -159,6 +164,18 @@ func PreserveTracing(ctx context.Context) context.Context {
 	if quoteToken := GetQuoteToken(ctx); quoteToken != "" {
 		newCtx = WithQuoteToken(newCtx, quoteToken)
 	}
+	if show := GetLoadingIndicator(ctx); show != nil {
+		newCtx = WithLoadingIndicator(newCtx, show)
+	}
+	if rawText := GetRawText(ctx); rawText != "" {
+		newCtx = WithRawText(newCtx, rawText)
+	}
+	if lang := GetLang(ctx); lang != "" {
+		newCtx = WithLang(newCtx, lang)
+	}
+	if l := GetLogger(ctx); l != nil {
+		newCtx = WithLogger(newCtx, l)
+	}
 
 	return newCtx
 }
@@ -181,3 +198,69 @@ func GetQuoteToken(ctx context.Context) string {
 	}
 	return ""
 }
+
+// WithLoadingIndicator adds a function that shows the LINE chat loading
+// animation. The webhook sets it for 1:1 chats only (LINE rejects group IDs);
+// handlers trigger it through lineutil.ShowLoading before slow operations.
+// The function must be safe to call more than once.
+func WithLoadingIndicator(ctx context.Context, show func(context.Context)) context.Context {
+	return context.WithValue(ctx, loadingKey, show)
+}
+
+// GetLoadingIndicator retrieves the loading animation function from the context.
+// Returns nil if none was set.
+func GetLoadingIndicator(ctx context.Context) func(context.Context) {
+	if show, ok := ctx.Value(loadingKey).(func(context.Context)); ok {
+		return show
+	}
+	return nil
+}
+
+// WithRawText adds the user's message text before sanitization (bot mentions
+// removed). Handlers that parse symbols stripped by sanitization, such as the
+// course search filters (@系所 #學期 !教師), read it from here.
+func WithRawText(ctx context.Context, text string) context.Context {
+	return context.WithValue(ctx, rawTextKey, text)
+}
+
+// GetRawText retrieves the unsanitized message text from the context.
+// Returns empty string for postbacks, NLU dispatches, or if not set.
+func GetRawText(ctx context.Context) string {
+	if v := ctx.Value(rawTextKey); v != nil {
+		if text, ok := v.(string); ok {
+			return text
+		}
+	}
+	return ""
+}
+
+// WithLang adds the language to reply in (e.g. "en" for an English message).
+// Handlers pass it to msgtmpl.Store.TextLang to pick message copy.
+func WithLang(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, langKey, lang)
+}
+
+// GetLang retrieves the reply language from the context.
+// Returns empty string (the default Chinese copy) if not set.
+func GetLang(ctx context.Context) string {
+	if v := ctx.Value(langKey); v != nil {
+		if lang, ok := v.(string); ok {
+			return lang
+		}
+	}
+	return ""
+}
+
+// WithLogger adds a request-scoped logger carrying fields such as the module
+// and intent handling the request. Use logger.NewContext and
+// logger.FromContext rather than calling this directly.
+func WithLogger(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, l)
+}
+
+// GetLogger retrieves the request-scoped logger from the context.
+// Returns nil if none was set.
+func GetLogger(ctx context.Context) *slog.Logger {
+	l, _ := ctx.Value(loggerKey).(*slog.Logger)
+	return l
+}
@@ -2,6 +2,7 @@ package ctxutil
 
 import (
 	"context"
+	"log/slog"
 	"testing"
 )
 
@@ -268,3 +269,64 @@ func TestQuoteTokenContext(t *testing.T) {
 		}
 	})
 }
+
+func TestLoadingIndicatorContext(t *testing.T) {
+	t.Parallel()
+
+	if show := GetLoadingIndicator(context.Background()); show != nil {
+		t.Error("Expected nil loading indicator for empty context")
+	}
+
+	calls := 0
+	ctx := WithLoadingIndicator(context.Background(), func(context.Context) { calls++ })
+	detached := PreserveTracing(ctx)
+
+	show := GetLoadingIndicator(detached)
+	if show == nil {
+		t.Fatal("Expected loading indicator to survive PreserveTracing")
+	}
+	show(detached)
+	if calls != 1 {
+		t.Errorf("Expected 1 call, got %d", calls)
+	}
+}
+
+func TestRawTextContext(t *testing.T) {
+	t.Parallel()
+
+	if text := GetRawText(context.Background()); text != "" {
+		t.Errorf("Expected empty raw text, got %q", text)
+	}
+
+	ctx := WithRawText(context.Background(), "課程 微積分 @資工")
+	if text := GetRawText(PreserveTracing(ctx)); text != "課程 微積分 @資工" {
+		t.Errorf("Expected raw text to survive PreserveTracing, got %q", text)
+	}
+}
+
+func TestLangContext(t *testing.T) {
+	t.Parallel()
+
+	if lang := GetLang(context.Background()); lang != "" {
+		t.Errorf("Expected empty lang, got %q", lang)
+	}
+
+	ctx := WithLang(context.Background(), "en")
+	if lang := GetLang(PreserveTracing(ctx)); lang != "en" {
+		t.Errorf("Expected lang to survive PreserveTracing, got %q", lang)
+	}
+}
+
+func TestLoggerContext(t *testing.T) {
+	t.Parallel()
+
+	if l := GetLogger(context.Background()); l != nil {
+		t.Errorf("Expected nil logger, got %v", l)
+	}
+
+	l := slog.Default().With("module", "course")
+	ctx := WithLogger(context.Background(), l)
+	if got := GetLogger(PreserveTracing(ctx)); got != l {
+		t.Errorf("Expected logger to survive PreserveTracing, got %v", got)
+	}
+}
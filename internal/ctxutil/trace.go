@@ -0,0 +1,92 @@
+package ctxutil
+
+import (
+	"context"
+	"sync"
+)
+
+const eventTraceKey contextKey = "ctxutil.eventTrace"
+
+// Cache outcomes reported by EventTrace.Cache.
+const (
+	CacheNone = "none" // No cache lookup (e.g., help, follow events)
+	CacheHit  = "hit"  // Every lookup hit
+	CacheMiss = "miss" // At least one lookup missed and fell back to scraping
+)
+
+// EventTrace collects what happened while one webhook event was handled, for
+// the end-to-end latency metric: the module and intent that answered and
+// whether their cache lookups hit. Methods are safe on a nil *EventTrace, so
+// callers outside a webhook event need no checks.
+type EventTrace struct {
+	mu     sync.Mutex
+	module string
+	intent string
+	hits   int
+	misses int
+}
+
+// WithEventTrace adds a new EventTrace to the context.
+func WithEventTrace(ctx context.Context) context.Context {
+	return context.WithValue(ctx, eventTraceKey, &EventTrace{})
+}
+
+// GetEventTrace retrieves the EventTrace from the context, or nil.
+// It is not copied by PreserveTracing: work that outlives the event does not
+// belong to its latency.
+func GetEventTrace(ctx context.Context) *EventTrace {
+	trace, _ := ctx.Value(eventTraceKey).(*EventTrace)
+	return trace
+}
+
+// SetModule records the module (and NLU intent, if any) handling the event.
+// The last call wins, so a fallback module replaces the one it stood in for.
+func (t *EventTrace) SetModule(module, intent string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.module, t.intent = module, intent
+}
+
+// MarkCache records the outcome of one cache lookup.
+func (t *EventTrace) MarkCache(hit bool) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if hit {
+		t.hits++
+	} else {
+		t.misses++
+	}
+}
+
+// Module returns the module and intent set by SetModule; empty if none was.
+func (t *EventTrace) Module() (module, intent string) {
+	if t == nil {
+		return "", ""
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.module, t.intent
+}
+
+// Cache returns CacheMiss if any lookup missed, CacheHit if all hit, or CacheNone.
+func (t *EventTrace) Cache() string {
+	if t == nil {
+		return CacheNone
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch {
+	case t.misses > 0:
+		return CacheMiss
+	case t.hits > 0:
+		return CacheHit
+	default:
+		return CacheNone
+	}
+}
@@ -0,0 +1,58 @@
+package ctxutil
+
+import (
+	"context"
+	"testing"
+)
+
+func TestEventTrace(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		cache     []bool
+		wantCache string
+	}{
+		{name: "no lookups", wantCache: CacheNone},
+		{name: "all hits", cache: []bool{true, true}, wantCache: CacheHit},
+		{name: "any miss", cache: []bool{true, false, true}, wantCache: CacheMiss},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := WithEventTrace(context.Background())
+			trace := GetEventTrace(ctx)
+
+			trace.SetModule("course", "")
+			trace.SetModule("id", "search") // Last call wins
+			for _, hit := range tt.cache {
+				trace.MarkCache(hit)
+			}
+
+			if module, intent := trace.Module(); module != "id" || intent != "search" {
+				t.Errorf("Module() = %q, %q, want id, search", module, intent)
+			}
+			if got := trace.Cache(); got != tt.wantCache {
+				t.Errorf("Cache() = %q, want %q", got, tt.wantCache)
+			}
+		})
+	}
+}
+
+func TestEventTrace_Nil(t *testing.T) {
+	t.Parallel()
+
+	trace := GetEventTrace(context.Background())
+	if trace != nil {
+		t.Fatalf("GetEventTrace(empty) = %v, want nil", trace)
+	}
+	trace.SetModule("course", "")
+	trace.MarkCache(true)
+	if module, _ := trace.Module(); module != "" {
+		t.Errorf("Module() on nil = %q, want empty", module)
+	}
+	if got := trace.Cache(); got != CacheNone {
+		t.Errorf("Cache() on nil = %q, want %q", got, CacheNone)
+	}
+}
@@ -0,0 +1,128 @@
+package data
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"sync"
+
+	domerrors "github.com/garyellow/ntpu-linebot-go/internal/errors"
+)
+
+// Asset keys of the images outside the college list (CampusData.Images).
+const (
+	AssetLogo          = "logo"           // School logo on the year search template
+	AssetLMSDeprecated = "lms_deprecated" // Shown for student ID years the LMS no longer lists
+	AssetEmergency     = "emergency"      // Closes the emergency phone reply
+)
+
+// CollegeAsset returns the asset key of a college's department selection
+// image, e.g. "college.電資".
+func CollegeAsset(shortName string) string {
+	return "college." + shortName
+}
+
+// Asset is one image of the registry.
+type Asset struct {
+	Key        string `json:"key"`
+	URL        string `json:"url"`         // URL replies use
+	DefaultURL string `json:"default_url"` // URL from campus.json
+	Overridden bool   `json:"overridden"`
+}
+
+// AssetRegistry holds the image URLs replies show. Defaults come from the
+// campus dataset; operators override them with NTPU_ASSET_URLS or the admin
+// API without a rebuild. It is safe for concurrent use.
+type AssetRegistry struct {
+	defaults map[string]string
+
+	mu        sync.RWMutex
+	overrides map[string]string
+}
+
+// Assets is the registry of the embedded campus dataset.
+var Assets = NewAssetRegistry(Campus)
+
+// NewAssetRegistry creates a registry with the images of c as defaults.
+func NewAssetRegistry(c CampusData) *AssetRegistry {
+	defaults := maps.Clone(c.Images)
+	if defaults == nil {
+		defaults = make(map[string]string)
+	}
+	for _, college := range c.Colleges {
+		defaults[CollegeAsset(college.ShortName)] = college.ImageURL
+	}
+	return &AssetRegistry{defaults: defaults, overrides: make(map[string]string)}
+}
+
+// URL returns the image URL of key: its override, else its default, or ""
+// for an unknown key.
+func (r *AssetRegistry) URL(key string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if u, ok := r.overrides[key]; ok {
+		return u
+	}
+	return r.defaults[key]
+}
+
+// Set overrides the image URL of key. An unknown key is domerrors.ErrNotFound;
+// LINE only loads https images, so any other URL is domerrors.ErrInvalidInput.
+func (r *AssetRegistry) Set(key, rawURL string) error {
+	if _, ok := r.defaults[key]; !ok {
+		return fmt.Errorf("%w: asset %q", domerrors.ErrNotFound, key)
+	}
+	if u, err := url.Parse(rawURL); err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("%w: asset URL must be https, got %q", domerrors.ErrInvalidInput, rawURL)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.overrides[key] = rawURL
+	return nil
+}
+
+// Reset drops the override of key. An unknown key is domerrors.ErrNotFound.
+func (r *AssetRegistry) Reset(key string) error {
+	if _, ok := r.defaults[key]; !ok {
+		return fmt.Errorf("%w: asset %q", domerrors.ErrNotFound, key)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.overrides, key)
+	return nil
+}
+
+// HasURL reports whether rawURL is the default or current URL of an asset,
+// so the image proxy serves registry images only.
+func (r *AssetRegistry) HasURL(rawURL string) bool {
+	if rawURL == "" {
+		return false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, urls := range []map[string]string{r.defaults, r.overrides} {
+		for _, u := range urls {
+			if u == rawURL {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// List returns every asset, sorted by key.
+func (r *AssetRegistry) List() []Asset {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	assets := make([]Asset, 0, len(r.defaults))
+	for _, key := range slices.Sorted(maps.Keys(r.defaults)) {
+		override, ok := r.overrides[key]
+		a := Asset{Key: key, URL: r.defaults[key], DefaultURL: r.defaults[key], Overridden: ok}
+		if ok {
+			a.URL = override
+		}
+		assets = append(assets, a)
+	}
+	return assets
+}
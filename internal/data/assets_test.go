@@ -0,0 +1,77 @@
+package data
+
+import (
+	"errors"
+	"testing"
+
+	domerrors "github.com/garyellow/ntpu-linebot-go/internal/errors"
+)
+
+func TestAssets_Defaults(t *testing.T) {
+	t.Parallel()
+	for _, key := range []string{AssetLogo, AssetLMSDeprecated, AssetEmergency} {
+		if Assets.URL(key) == "" {
+			t.Errorf("Assets.URL(%q) is empty", key)
+		}
+	}
+	for _, c := range Campus.Colleges {
+		if got := Assets.URL(CollegeAsset(c.ShortName)); got != c.ImageURL {
+			t.Errorf("college %s asset = %q, want %q", c.Name, got, c.ImageURL)
+		}
+	}
+}
+
+func TestAssetRegistry_Override(t *testing.T) {
+	t.Parallel()
+	r := NewAssetRegistry(Campus)
+	key := CollegeAsset("電資")
+	def := r.URL(key)
+
+	if err := r.Set(key, "https://cdn.example.com/eecs.jpg"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if got := r.URL(key); got != "https://cdn.example.com/eecs.jpg" {
+		t.Errorf("URL() after Set = %q, want the override", got)
+	}
+	if Assets.URL(key) != def {
+		t.Error("Set() on a new registry changed the package registry")
+	}
+
+	var overridden int
+	for _, a := range r.List() {
+		if a.Overridden {
+			overridden++
+			if a.Key != key || a.DefaultURL != def {
+				t.Errorf("List() overridden asset = %+v, want %s with its default", a, key)
+			}
+		}
+	}
+	if overridden != 1 {
+		t.Errorf("List() has %d overridden assets, want 1", overridden)
+	}
+
+	for _, u := range []string{def, "https://cdn.example.com/eecs.jpg"} {
+		if !r.HasURL(u) {
+			t.Errorf("HasURL(%q) = false, want true for a default or override", u)
+		}
+	}
+	if r.HasURL("https://cdn.example.com/other.jpg") || r.HasURL("") {
+		t.Error("HasURL() = true for a URL outside the registry")
+	}
+
+	if err := r.Reset(key); err != nil || r.URL(key) != def {
+		t.Errorf("Reset() = %v, URL = %q, want the default back", err, r.URL(key))
+	}
+
+	if err := r.Set("college.魔法", "https://cdn.example.com/x.jpg"); !errors.Is(err, domerrors.ErrNotFound) {
+		t.Errorf("Set(unknown) error = %v, want ErrNotFound", err)
+	}
+	if err := r.Reset("college.魔法"); !errors.Is(err, domerrors.ErrNotFound) {
+		t.Errorf("Reset(unknown) error = %v, want ErrNotFound", err)
+	}
+	for _, bad := range []string{"http://cdn.example.com/x.jpg", "https://", "not a url"} {
+		if err := r.Set(key, bad); !errors.Is(err, domerrors.ErrInvalidInput) {
+			t.Errorf("Set(%q) error = %v, want ErrInvalidInput", bad, err)
+		}
+	}
+}
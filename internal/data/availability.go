@@ -0,0 +1,161 @@
+package data
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// availabilityJSON is the year coverage of each scraped data source. Handlers
+// consult it instead of hard-coding cutoff years, so a source that stops
+// listing a year (the LMS 2.0 shutdown hid every student from 114 on) or one
+// that comes back only needs a data change.
+//
+//go:embed availability.json
+var availabilityJSON []byte
+
+// Coverage is how much of a year a data source still lists.
+type Coverage string
+
+// Coverage levels of a YearPeriod.
+const (
+	CoverageComplete Coverage = "complete" // Every record of the year is listed
+	CoveragePartial  Coverage = "partial"  // Some records; lookups work, list views are not offered
+	CoverageSparse   Coverage = "sparse"   // Too few records to be worth querying
+	CoverageNone     Coverage = "none"     // The source no longer lists the year
+)
+
+// YearPeriod is a range of ROC academic years with the same coverage.
+type YearPeriod struct {
+	From     int      `json:"from"`
+	To       int      `json:"to"` // Inclusive; 0 for open-ended
+	Coverage Coverage `json:"coverage"`
+	Message  string   `json:"message"` // Reply to queries for the period; empty for the handler's default
+	Notice   string   `json:"notice"`  // Appended to empty results in the period
+	Image    string   `json:"image"`   // Asset key of an image sent after Message; empty for none
+}
+
+// Contains reports whether year lies in the period.
+func (p YearPeriod) Contains(year int) bool {
+	return year >= p.From && (p.To == 0 || year <= p.To)
+}
+
+// SourcePolicy is the year coverage of one data source.
+type SourcePolicy struct {
+	// NotFoundMessage replies to searches across all years that find nothing;
+	// %s is the search term. Empty for the handler's default.
+	NotFoundMessage string       `json:"not_found_message"`
+	Summary         string       `json:"summary"` // Coverage shown below search results; empty for none
+	Periods         []YearPeriod `json:"periods"` // Ascending and non-overlapping
+}
+
+// Period returns the period containing year, if any. Years before the first
+// period predate the source.
+func (s SourcePolicy) Period(year int) (YearPeriod, bool) {
+	for _, p := range s.Periods {
+		if p.Contains(year) {
+			return p, true
+		}
+	}
+	return YearPeriod{}, false
+}
+
+// Available reports whether the source lists any record of year.
+func (s SourcePolicy) Available(year int) bool {
+	p, ok := s.Period(year)
+	return ok && p.Coverage != CoverageNone
+}
+
+// FirstYear returns the earliest year the source lists records of, or 0 when
+// it lists none.
+func (s SourcePolicy) FirstYear() int {
+	for _, p := range s.Periods {
+		if p.Coverage != CoverageNone {
+			return p.From
+		}
+	}
+	return 0
+}
+
+// CompleteYears returns the newest period of complete coverage; to is 0 when
+// it is open-ended. Both are 0 when no year is complete.
+func (s SourcePolicy) CompleteYears() (from, to int) {
+	for _, p := range s.Periods {
+		if p.Coverage == CoverageComplete {
+			from, to = p.From, p.To
+		}
+	}
+	return from, to
+}
+
+// LatestCompleteYear returns the newest complete year up to currentYear, the
+// year quick replies suggest; 0 when no year is complete.
+func (s SourcePolicy) LatestCompleteYear(currentYear int) int {
+	from, to := s.CompleteYears()
+	if from == 0 || from > currentYear {
+		return 0
+	}
+	if to == 0 {
+		return currentYear
+	}
+	return min(to, currentYear)
+}
+
+// AvailabilityData is the embedded availability dataset.
+type AvailabilityData struct {
+	Students SourcePolicy `json:"students"` // Student IDs (LMS 2.0 listings)
+	Courses  SourcePolicy `json:"courses"`  // Course query system
+	Contacts SourcePolicy `json:"contacts"` // Campus directory
+}
+
+// Availability is the parsed embedded dataset. Like Campus, a broken
+// availability.json fails at startup rather than on a request.
+var Availability = mustParseAvailability(availabilityJSON)
+
+func mustParseAvailability(raw []byte) AvailabilityData {
+	a, err := parseAvailability(raw)
+	if err != nil {
+		panic(fmt.Sprintf("data: parse availability.json: %v", err))
+	}
+	return a
+}
+
+func parseAvailability(raw []byte) (AvailabilityData, error) {
+	var a AvailabilityData
+	if err := json.Unmarshal(raw, &a); err != nil {
+		return AvailabilityData{}, err
+	}
+	sources := map[string]SourcePolicy{"students": a.Students, "courses": a.Courses, "contacts": a.Contacts}
+	for name, s := range sources {
+		if err := s.validate(); err != nil {
+			return AvailabilityData{}, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return a, nil
+}
+
+// validate checks that periods are well-formed, ascending and
+// non-overlapping, and that only the last one is open-ended.
+func (s SourcePolicy) validate() error {
+	if len(s.Periods) == 0 {
+		return errors.New("no periods")
+	}
+	for i, p := range s.Periods {
+		switch p.Coverage {
+		case CoverageComplete, CoveragePartial, CoverageSparse, CoverageNone:
+		default:
+			return fmt.Errorf("period %d: unknown coverage %q", p.From, p.Coverage)
+		}
+		if p.From <= 0 || (p.To != 0 && p.To < p.From) {
+			return fmt.Errorf("period %d-%d: invalid range", p.From, p.To)
+		}
+		if p.To == 0 && i != len(s.Periods)-1 {
+			return fmt.Errorf("period %d: only the last period may be open-ended", p.From)
+		}
+		if i > 0 && p.From <= s.Periods[i-1].To {
+			return fmt.Errorf("period %d overlaps the previous one", p.From)
+		}
+	}
+	return nil
+}
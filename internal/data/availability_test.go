@@ -0,0 +1,109 @@
+package data
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
+)
+
+func TestAvailability_Students(t *testing.T) {
+	t.Parallel()
+	students := Availability.Students
+	tests := []struct {
+		year int
+		want Coverage
+		ok   bool
+	}{
+		{88, "", false},
+		{90, CoverageSparse, true},
+		{94, CoverageComplete, true},
+		{112, CoverageComplete, true},
+		{113, CoveragePartial, true},
+		{114, CoverageNone, true},
+		{150, CoverageNone, true},
+	}
+	for _, tt := range tests {
+		p, ok := students.Period(tt.year)
+		if ok != tt.ok || p.Coverage != tt.want {
+			t.Errorf("Period(%d) = (%q, %v), want (%q, %v)", tt.year, p.Coverage, ok, tt.want, tt.ok)
+		}
+	}
+
+	if from, to := students.CompleteYears(); from != 94 || to != 112 {
+		t.Errorf("CompleteYears() = %d-%d, want 94-112", from, to)
+	}
+	if got := students.LatestCompleteYear(115); got != 112 {
+		t.Errorf("LatestCompleteYear(115) = %d, want 112", got)
+	}
+	if students.Available(114) || !students.Available(113) {
+		t.Error("Available() disagrees with the 113/114 cutoff")
+	}
+	if !strings.Contains(students.NotFoundMessage, "%s") {
+		t.Errorf("students NotFoundMessage has no %q verb for the search term", "%s")
+	}
+	// Scraped students past config.IDDataCutoffYear fail validation
+	for _, p := range students.Periods {
+		if p.Coverage != CoverageNone && (p.To == 0 || p.To > config.IDDataCutoffYear) {
+			t.Errorf("students period %d-%d lists years after config.IDDataCutoffYear (%d)", p.From, p.To, config.IDDataCutoffYear)
+		}
+	}
+}
+
+func TestAvailability_OpenEnded(t *testing.T) {
+	t.Parallel()
+	courses := Availability.Courses
+	if got := courses.FirstYear(); got != 90 {
+		t.Errorf("courses FirstYear() = %d, want 90", got)
+	}
+	if got := courses.LatestCompleteYear(115); got != 115 {
+		t.Errorf("courses LatestCompleteYear(115) = %d, want 115", got)
+	}
+	if !Availability.Contacts.Available(115) {
+		t.Error("contacts are not available this year")
+	}
+}
+
+func TestAvailability_Replies(t *testing.T) {
+	t.Parallel()
+	sources := map[string]SourcePolicy{
+		"students": Availability.Students,
+		"courses":  Availability.Courses,
+		"contacts": Availability.Contacts,
+	}
+	for name, s := range sources {
+		for _, p := range s.Periods {
+			// Handlers have no default reply for years a source dropped
+			if p.Coverage == CoverageNone && p.Message == "" {
+				t.Errorf("%s period %d has no data but no message", name, p.From)
+			}
+			if p.Image != "" && Assets.URL(p.Image) == "" {
+				t.Errorf("%s period %d image %q is not an asset key", name, p.From, p.Image)
+			}
+		}
+	}
+}
+
+func TestParseAvailability_Invalid(t *testing.T) {
+	t.Parallel()
+	valid := `{"periods": [{"from": 90, "coverage": "complete"}]}`
+	tests := []struct {
+		name     string
+		students string
+	}{
+		{"No periods", `{"periods": []}`},
+		{"Unknown coverage", `{"periods": [{"from": 90, "coverage": "most"}]}`},
+		{"Reversed range", `{"periods": [{"from": 100, "to": 90, "coverage": "complete"}]}`},
+		{"Open-ended in the middle", `{"periods": [{"from": 90, "coverage": "complete"}, {"from": 100, "coverage": "none"}]}`},
+		{"Overlap", `{"periods": [{"from": 90, "to": 100, "coverage": "complete"}, {"from": 100, "coverage": "none"}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			raw := `{"students": ` + tt.students + `, "courses": ` + valid + `, "contacts": ` + valid + `}`
+			if _, err := parseAvailability([]byte(raw)); err == nil {
+				t.Error("parseAvailability() error = nil, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package data
+
+// EnrollmentPeriod is the 加退選 (add/drop) window of one semester.
+// Dates are Asia/Taipei calendar days in "2006-01-02" form, both inclusive.
+type EnrollmentPeriod struct {
+	Year  int    // ROC year (e.g., 115)
+	Term  int    // 1 (上學期) or 2 (下學期)
+	Start string // First day of 加退選
+	End   string // Last day of 加退選
+}
+
+// EnrollmentPeriods lists the 加退選 windows from the NTPU academic calendar
+// (教務處 行事曆), oldest first. Add the next semester when its calendar is
+// published; outside these windows the bot treats course data as stable.
+var EnrollmentPeriods = []EnrollmentPeriod{
+	{Year: 114, Term: 1, Start: "2025-09-08", End: "2025-09-19"},
+	{Year: 114, Term: 2, Start: "2026-02-23", End: "2026-03-06"},
+	{Year: 115, Term: 1, Start: "2026-09-14", End: "2026-09-25"},
+}
+
+// EnrollmentPeriodOn returns the enrollment period containing day
+// ("2006-01-02", Asia/Taipei), if any.
+func EnrollmentPeriodOn(day string) (EnrollmentPeriod, bool) {
+	for _, p := range EnrollmentPeriods {
+		if day >= p.Start && day <= p.End {
+			return p, true
+		}
+	}
+	return EnrollmentPeriod{}, false
+}
+
+// Holiday is a national holiday (國定假日, including 補假 and the weekends
+// inside long breaks) on which administrative offices are closed. Dates are
+// Asia/Taipei calendar days in "2006-01-02" form, both inclusive.
+type Holiday struct {
+	Name  string // e.g. "國慶日"
+	Start string
+	End   string
+}
+
+// Holidays lists the days off from the 人事行政總處 (DGPA) government
+// calendar, oldest first. Add the next year when DGPA publishes it.
+var Holidays = []Holiday{
+	{Name: "中秋節", Start: "2025-10-06", End: "2025-10-06"},
+	{Name: "國慶日", Start: "2025-10-10", End: "2025-10-10"},
+	{Name: "臺灣光復節", Start: "2025-10-24", End: "2025-10-24"},
+	{Name: "行憲紀念日", Start: "2025-12-25", End: "2025-12-25"},
+	{Name: "開國紀念日", Start: "2026-01-01", End: "2026-01-01"},
+	{Name: "春節", Start: "2026-02-14", End: "2026-02-22"},
+	{Name: "和平紀念日", Start: "2026-02-27", End: "2026-03-01"},
+	{Name: "兒童節及清明節", Start: "2026-04-03", End: "2026-04-06"},
+	{Name: "勞動節", Start: "2026-05-01", End: "2026-05-01"},
+	{Name: "端午節", Start: "2026-06-19", End: "2026-06-19"},
+	{Name: "中秋節", Start: "2026-09-25", End: "2026-09-25"},
+	{Name: "教師節", Start: "2026-09-28", End: "2026-09-28"},
+	{Name: "國慶日", Start: "2026-10-09", End: "2026-10-09"},
+	{Name: "臺灣光復節", Start: "2026-10-26", End: "2026-10-26"},
+	{Name: "行憲紀念日", Start: "2026-12-25", End: "2026-12-25"},
+}
+
+// HolidayOn returns the holiday containing day ("2006-01-02",
+// Asia/Taipei), if any.
+func HolidayOn(day string) (Holiday, bool) {
+	for _, h := range Holidays {
+		if day >= h.Start && day <= h.End {
+			return h, true
+		}
+	}
+	return Holiday{}, false
+}
+
+// Vacation is a 寒假 or 暑假 between two semesters. Dates are Asia/Taipei
+// calendar days in "2006-01-02" form, both inclusive.
+type Vacation struct {
+	Name  string // "寒假" or "暑假"
+	Year  int    // ROC year of the semester that just ended
+	Term  int    // Term of the semester that just ended
+	Start string
+	End   string
+}
+
+// Vacations lists the breaks from the NTPU academic calendar (教務處
+// 行事曆), oldest first. Each ends the day before the next semester's
+// classes, which start with its 加退選 window (EnrollmentPeriods).
+var Vacations = []Vacation{
+	{Name: "寒假", Year: 114, Term: 1, Start: "2026-01-17", End: "2026-02-22"},
+	{Name: "暑假", Year: 114, Term: 2, Start: "2026-06-27", End: "2026-09-13"},
+}
+
+// VacationOn returns the vacation containing day ("2006-01-02",
+// Asia/Taipei), if any.
+func VacationOn(day string) (Vacation, bool) {
+	for _, v := range Vacations {
+		if day >= v.Start && day <= v.End {
+			return v, true
+		}
+	}
+	return Vacation{}, false
+}
@@ -0,0 +1,90 @@
+package data
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEnrollmentPeriods(t *testing.T) {
+	t.Parallel()
+	var prevEnd string
+	for _, p := range EnrollmentPeriods {
+		start, err := time.Parse(time.DateOnly, p.Start)
+		if err != nil {
+			t.Errorf("%d-%d: bad start %q", p.Year, p.Term, p.Start)
+			continue
+		}
+		end, err := time.Parse(time.DateOnly, p.End)
+		if err != nil {
+			t.Errorf("%d-%d: bad end %q", p.Year, p.Term, p.End)
+			continue
+		}
+		if end.Before(start) || p.Start <= prevEnd {
+			t.Errorf("%d-%d: period %s ~ %s is reversed or out of order", p.Year, p.Term, p.Start, p.End)
+		}
+		if p.Term != 1 && p.Term != 2 {
+			t.Errorf("%d-%d: bad term", p.Year, p.Term)
+		}
+		prevEnd = p.End
+	}
+}
+
+func TestEnrollmentPeriodOn(t *testing.T) {
+	t.Parallel()
+	p := EnrollmentPeriods[0]
+	for _, day := range []string{p.Start, p.End} {
+		if got, ok := EnrollmentPeriodOn(day); !ok || got != p {
+			t.Errorf("EnrollmentPeriodOn(%s) = %+v, %v; want %+v", day, got, ok, p)
+		}
+	}
+	if _, ok := EnrollmentPeriodOn("2000-01-01"); ok {
+		t.Error("EnrollmentPeriodOn(2000-01-01) found a period")
+	}
+}
+
+func TestHolidaysAndVacations(t *testing.T) {
+	t.Parallel()
+	type span struct{ name, start, end string }
+	var holidays, vacations []span
+	for _, h := range Holidays {
+		holidays = append(holidays, span{h.Name, h.Start, h.End})
+	}
+	for _, v := range Vacations {
+		vacations = append(vacations, span{v.Name, v.Start, v.End})
+		if v.Term != 1 && v.Term != 2 {
+			t.Errorf("%s %d-%d: bad term", v.Name, v.Year, v.Term)
+		}
+	}
+
+	for _, spans := range [][]span{holidays, vacations} {
+		var prevEnd string
+		for _, s := range spans {
+			start, errStart := time.Parse(time.DateOnly, s.start)
+			end, errEnd := time.Parse(time.DateOnly, s.end)
+			if errStart != nil || errEnd != nil {
+				t.Errorf("%s: bad dates %q ~ %q", s.name, s.start, s.end)
+				continue
+			}
+			if end.Before(start) || s.start <= prevEnd {
+				t.Errorf("%s: %s ~ %s is reversed or out of order", s.name, s.start, s.end)
+			}
+			prevEnd = s.end
+		}
+	}
+}
+
+func TestHolidayOnAndVacationOn(t *testing.T) {
+	t.Parallel()
+	if h, ok := HolidayOn("2026-02-17"); !ok || h.Name != "春節" {
+		t.Errorf("HolidayOn(2026-02-17) = %+v, %v; want 春節", h, ok)
+	}
+	if _, ok := HolidayOn("2026-02-23"); ok {
+		t.Error("HolidayOn(2026-02-23) found a holiday on a school day")
+	}
+	if v, ok := VacationOn("2026-02-01"); !ok || v.Name != "寒假" || v.Term != 1 {
+		t.Errorf("VacationOn(2026-02-01) = %+v, %v; want 寒假 after term 1", v, ok)
+	}
+	if _, ok := VacationOn("2026-03-02"); ok {
+		t.Error("VacationOn(2026-03-02) found a vacation during the semester")
+	}
+}
@@ -0,0 +1,125 @@
+package data
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// campusJSON is the campus dataset compiled into the binary, so college
+// templates and emergency phones work on first boot with an empty database
+// and while the scrapers are unreachable. Department codes live next to the
+// scraper (ntpu.DepartmentCodes); college departments here use the same
+// short names.
+//
+//go:embed campus.json
+var campusJSON []byte
+
+// College is one college (學院) shown by the id module's year search.
+type College struct {
+	Name        string   `json:"name"`        // e.g. "電機資訊學院"
+	ShortName   string   `json:"short_name"`  // Used in the college list, e.g. "電資"
+	Emoji       string   `json:"emoji"`       // Shown before the name on buttons
+	Group       string   `json:"group"`       // College group: "文法商" or "公社電資"
+	ImageURL    string   `json:"image_url"`   // Department selection template image
+	Departments []string `json:"departments"` // Short names, keys of ntpu.DepartmentCodes
+	IsLaw       bool     `json:"is_law"`      // Departments are 組別 of the law department
+}
+
+// EmergencyPhone is one row of the emergency phone card.
+type EmergencyPhone struct {
+	Icon   string `json:"icon"`
+	Label  string `json:"label"`
+	Number string `json:"number"` // Without hyphens, for clipboard copy
+	Urgent bool   `json:"urgent"` // Highlighted in the danger color
+}
+
+// EmergencySection groups the emergency phones of one campus or of the
+// public emergency services.
+type EmergencySection struct {
+	Name      string           `json:"name"`       // e.g. "三峽校區"
+	ShortName string           `json:"short_name"` // Used on the hotline buttons, e.g. "三峽"
+	Hotline   string           `json:"hotline"`    // 24H hotline with call/copy buttons; empty for none
+	External  bool             `json:"external"`   // Public services rather than a campus
+	Phones    []EmergencyPhone `json:"phones"`
+}
+
+// CampusData is the embedded campus dataset.
+type CampusData struct {
+	Switchboard string             `json:"switchboard"` // Main number for dialing extensions
+	Images      map[string]string  `json:"images"`      // Image URLs outside the college list, by asset key (see Assets)
+	Colleges    []College          `json:"colleges"`
+	Emergency   []EmergencySection `json:"emergency"`
+}
+
+// Campus is the parsed embedded dataset. Parsing happens at init; a broken
+// campus.json fails every test and the binary at startup, never a request.
+var Campus = mustParseCampus(campusJSON)
+
+func mustParseCampus(raw []byte) CampusData {
+	var c CampusData
+	if err := json.Unmarshal(raw, &c); err != nil {
+		panic(fmt.Sprintf("data: parse campus.json: %v", err))
+	}
+	return c
+}
+
+// CollegeByName returns the college with the given name, if any.
+func (c CampusData) CollegeByName(name string) (College, bool) {
+	for _, college := range c.Colleges {
+		if college.Name == name {
+			return college, true
+		}
+	}
+	return College{}, false
+}
+
+// CollegesInGroup returns the colleges of a college group in dataset order.
+func (c CampusData) CollegesInGroup(group string) []College {
+	var colleges []College
+	for _, college := range c.Colleges {
+		if college.Group == group {
+			colleges = append(colleges, college)
+		}
+	}
+	return colleges
+}
+
+// FindCollege returns the college named by text: the full name ("商學院"),
+// the short name ("商學") or the short name plus 學院 ("電資學院").
+func (c CampusData) FindCollege(text string) (College, bool) {
+	for _, college := range c.Colleges {
+		if text == college.Name || text == college.ShortName || text == college.ShortName+"學院" {
+			return college, true
+		}
+	}
+	return College{}, false
+}
+
+// CollegeOfDepartment returns the college of a department, given by short
+// name ("資工") or as stored on student records ("資工系", "資工所"). "法律系"
+// is the law college, whose Departments are the law department's 組別.
+func (c CampusData) CollegeOfDepartment(dept string) (College, bool) {
+	short := dept
+	if i := strings.IndexAny(dept, "系所"); i > 0 {
+		short = dept[:i]
+	}
+	for _, college := range c.Colleges {
+		if (college.IsLaw && short == "法律") || slices.Contains(college.Departments, short) {
+			return college, true
+		}
+	}
+	return College{}, false
+}
+
+// CourseDepartments returns the department names the course system uses in
+// 應修系級 for the college (資工 for 資工系1). The law college's 組別 share the
+// law department's courses (法律系).
+func (c College) CourseDepartments() []string {
+	if c.IsLaw {
+		return []string{"法律"}
+	}
+	return c.Departments
+}
@@ -0,0 +1,81 @@
+package data
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/scraper/ntpu"
+)
+
+func TestCampus_Colleges(t *testing.T) {
+	t.Parallel()
+	if len(Campus.Colleges) == 0 {
+		t.Fatal("Campus.Colleges is empty")
+	}
+	for _, c := range Campus.Colleges {
+		if c.Name == "" || c.ShortName == "" || c.ImageURL == "" {
+			t.Errorf("college %+v is missing a name, short name or image", c)
+		}
+		if c.Group != "文法商" && c.Group != "公社電資" {
+			t.Errorf("college %s group = %q, want 文法商 or 公社電資", c.Name, c.Group)
+		}
+		for _, dept := range c.Departments {
+			// The id module turns these into postbacks through the code map
+			if _, ok := ntpu.DepartmentCodes[dept]; !ok {
+				t.Errorf("college %s department %q has no code in ntpu.DepartmentCodes", c.Name, dept)
+			}
+		}
+	}
+}
+
+func TestCampus_Lookups(t *testing.T) {
+	t.Parallel()
+	c, ok := Campus.CollegeByName("法律學院")
+	if !ok || !c.IsLaw {
+		t.Errorf("CollegeByName(法律學院) = (%+v, %v), want the law college", c, ok)
+	}
+	if _, ok := Campus.CollegeByName("魔法學院"); ok {
+		t.Error("CollegeByName(魔法學院) found a college, want none")
+	}
+	for _, name := range []string{"商學院", "商學", "電資學院"} {
+		if _, ok := Campus.FindCollege(name); !ok {
+			t.Errorf("FindCollege(%s) found no college", name)
+		}
+	}
+	if c, ok := Campus.CollegeOfDepartment("資工所"); !ok || c.Name != "電機資訊學院" {
+		t.Errorf("CollegeOfDepartment(資工所) = (%+v, %v), want 電機資訊學院", c, ok)
+	}
+	if c, ok := Campus.CollegeOfDepartment("法律系"); !ok || !c.IsLaw || !slices.Equal(c.CourseDepartments(), []string{"法律"}) {
+		t.Errorf("CollegeOfDepartment(法律系) = (%+v, %v), want the law college with course department 法律", c, ok)
+	}
+	if got := len(Campus.CollegesInGroup("公社電資")) + len(Campus.CollegesInGroup("文法商")); got != len(Campus.Colleges) {
+		t.Errorf("colleges in both groups = %d, want %d", got, len(Campus.Colleges))
+	}
+}
+
+func TestCampus_Emergency(t *testing.T) {
+	t.Parallel()
+	if Campus.Switchboard == "" {
+		t.Error("Campus.Switchboard is empty")
+	}
+	hotlines := 0
+	for _, sec := range Campus.Emergency {
+		if len(sec.Phones) == 0 {
+			t.Errorf("emergency section %s has no phones", sec.Name)
+		}
+		if sec.Hotline != "" {
+			hotlines++
+			if sec.ShortName == "" {
+				t.Errorf("emergency section %s has a hotline but no short name", sec.Name)
+			}
+		}
+		for _, p := range sec.Phones {
+			if p.Number == "" || p.Label == "" {
+				t.Errorf("emergency section %s has an incomplete phone %+v", sec.Name, p)
+			}
+		}
+	}
+	if hotlines == 0 {
+		t.Error("no emergency section has a hotline")
+	}
+}
@@ -0,0 +1,203 @@
+// Package degraded keeps the bot answering when the SQLite cache cannot be
+// opened at startup. An Exporter writes the most-queried data (recent
+// courses, contacts and students) to a JSON snapshot every day; when the
+// database fails to open, Load imports that snapshot into an in-memory
+// database so basic lookups keep working while operators repair the file.
+// Replies carry a 降級模式 banner (see Banner) for as long as the bot runs
+// this way.
+package degraded
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+)
+
+// SnapshotVersion is the snapshot format version; Load rejects other versions.
+const SnapshotVersion = 1
+
+// ErrSnapshotVersion is returned by Load for a snapshot of another format version.
+var ErrSnapshotVersion = errors.New("unsupported degraded snapshot version")
+
+// Snapshot is the JSON document written by Export.
+type Snapshot struct {
+	Version    int               `json:"version"`
+	ExportedAt time.Time         `json:"exported_at"`
+	Courses    []storage.Course  `json:"courses"`
+	Contacts   []storage.Contact `json:"contacts"`
+	Students   []storage.Student `json:"students"`
+}
+
+// ExportCounts reports the rows written by Export.
+type ExportCounts struct {
+	Courses  int
+	Contacts int
+	Students int
+}
+
+// Export streams the snapshot data from db to path, one row at a time, so
+// the student table never has to fit in memory. The file is replaced
+// atomically, so a crash mid-export keeps the previous snapshot.
+func Export(ctx context.Context, db *storage.DB, path string) (ExportCounts, error) {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return ExportCounts{}, fmt.Errorf("export: create dir: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, "degraded_*.json")
+	if err != nil {
+		return ExportCounts{}, fmt.Errorf("export: create temp file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	counts, err := writeSnapshot(ctx, db, tmp)
+	if err != nil {
+		_ = tmp.Close()
+		return ExportCounts{}, err
+	}
+	if err := tmp.Close(); err != nil {
+		return ExportCounts{}, fmt.Errorf("export: close temp file: %w", err)
+	}
+	if counts.Courses+counts.Contacts+counts.Students == 0 {
+		// An empty cache (e.g. right after a rebuild) must not replace a useful snapshot
+		return ExportCounts{}, errors.New("export: cache is empty")
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return ExportCounts{}, fmt.Errorf("export: replace snapshot: %w", err)
+	}
+	return counts, nil
+}
+
+// writeSnapshot writes a Snapshot document to w, encoding the row arrays
+// element by element as db iterates them.
+func writeSnapshot(ctx context.Context, db *storage.DB, w io.Writer) (ExportCounts, error) {
+	var counts ExportCounts
+	bw := bufio.NewWriter(w)
+	header, err := json.Marshal(struct {
+		Version    int       `json:"version"`
+		ExportedAt time.Time `json:"exported_at"`
+	}{SnapshotVersion, time.Now().UTC()})
+	if err != nil {
+		return counts, fmt.Errorf("export: encode snapshot: %w", err)
+	}
+	// Reopen the header object to append the arrays
+	_, _ = bw.Write(header[:len(header)-1])
+
+	counts.Courses, err = writeArray(bw, "courses", func(fn func(*storage.Course) error) error {
+		return db.ForEachCourse(ctx, storage.CourseFilter{}, fn)
+	})
+	if err != nil {
+		return counts, fmt.Errorf("export courses: %w", err)
+	}
+	counts.Contacts, err = writeArray(bw, "contacts", func(fn func(*storage.Contact) error) error {
+		return db.ForEachContact(ctx, fn)
+	})
+	if err != nil {
+		return counts, fmt.Errorf("export contacts: %w", err)
+	}
+	counts.Students, err = writeArray(bw, "students", func(fn func(*storage.Student) error) error {
+		return db.ForEachStudent(ctx, fn)
+	})
+	if err != nil {
+		return counts, fmt.Errorf("export students: %w", err)
+	}
+
+	_, _ = bw.WriteString("}\n")
+	if err := bw.Flush(); err != nil {
+		return counts, fmt.Errorf("export: write snapshot: %w", err)
+	}
+	return counts, nil
+}
+
+// writeArray writes `,"name":[...]` with the rows visited by forEach and
+// returns how many there were. Write errors surface from the final Flush.
+func writeArray[T any](w *bufio.Writer, name string, forEach func(func(*T) error) error) (int, error) {
+	_, _ = w.WriteString(`,"` + name + `":[`)
+	n := 0
+	err := forEach(func(row *T) error {
+		data, err := json.Marshal(row)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			_ = w.WriteByte(',')
+		}
+		_, _ = w.Write(data)
+		n++
+		return nil
+	})
+	_ = w.WriteByte(']')
+	return n, err
+}
+
+// Load reads the snapshot at path and imports it into a new database at
+// dbPath (":memory:" in production). Rows are stamped with the load time, so
+// the TTL filters of the lookups treat the whole snapshot as fresh.
+func Load(ctx context.Context, path, dbPath string, cacheTTL time.Duration) (*storage.DB, *Snapshot, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path comes from configuration
+	if err != nil {
+		return nil, nil, fmt.Errorf("read degraded snapshot: %w", err)
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, nil, fmt.Errorf("decode degraded snapshot: %w", err)
+	}
+	if snap.Version != SnapshotVersion {
+		return nil, nil, fmt.Errorf("%w: %d", ErrSnapshotVersion, snap.Version)
+	}
+
+	db, err := storage.New(ctx, dbPath, cacheTTL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open degraded database: %w", err)
+	}
+	if err := importSnapshot(ctx, db, &snap); err != nil {
+		_ = db.Close(ctx)
+		return nil, nil, err
+	}
+	return db, &snap, nil
+}
+
+// importSnapshot saves the snapshot rows with a zero cached_at (now).
+func importSnapshot(ctx context.Context, db *storage.DB, snap *Snapshot) error {
+	courses := make([]*storage.Course, len(snap.Courses))
+	for i := range snap.Courses {
+		snap.Courses[i].CachedAt = 0
+		courses[i] = &snap.Courses[i]
+	}
+	if err := db.SaveCoursesBatch(ctx, courses); err != nil {
+		return fmt.Errorf("import courses: %w", err)
+	}
+
+	contacts := make([]*storage.Contact, len(snap.Contacts))
+	for i := range snap.Contacts {
+		snap.Contacts[i].CachedAt = 0
+		contacts[i] = &snap.Contacts[i]
+	}
+	if err := db.SaveContactsBatch(ctx, contacts); err != nil {
+		return fmt.Errorf("import contacts: %w", err)
+	}
+
+	students := make([]*storage.Student, len(snap.Students))
+	for i := range snap.Students {
+		snap.Students[i].CachedAt = 0
+		students[i] = &snap.Students[i]
+	}
+	if err := db.SaveStudentsBatch(ctx, students); err != nil {
+		return fmt.Errorf("import students: %w", err)
+	}
+	return nil
+}
+
+// Banner returns the notice shown with every reply while serving snap.
+func Banner(snap *Snapshot) string {
+	return "⚠️ 降級模式\n資料庫維修中，目前僅提供 " + lineutil.FormatCacheTime(snap.ExportedAt.Unix()) +
+		" 的備份資料，查詢結果可能不是最新"
+}
@@ -0,0 +1,124 @@
+package degraded
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+)
+
+func newTestDB(t *testing.T, name string) *storage.DB {
+	t.Helper()
+	db, err := storage.New(context.Background(), filepath.Join(t.TempDir(), name), 168*time.Hour)
+	if err != nil {
+		t.Fatalf("storage.New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close(context.Background()) })
+	return db
+}
+
+func TestExportAndLoad(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := newTestDB(t, "cache.db")
+	if err := db.SaveCoursesBatch(ctx, []*storage.Course{
+		{UID: "1131U0001", Year: 113, Term: 1, No: "U0001", Title: "微積分", Teachers: []string{"王老師"}},
+	}); err != nil {
+		t.Fatalf("SaveCoursesBatch() error = %v", err)
+	}
+	if err := db.SaveContactsBatch(ctx, []*storage.Contact{{UID: "85", Type: "individual", Name: "陳大華"}}); err != nil {
+		t.Fatalf("SaveContactsBatch() error = %v", err)
+	}
+	if err := db.SaveStudentsBatch(ctx, []*storage.Student{{ID: "41247001", Name: "王小明", Department: "資工系", Year: 112}}); err != nil {
+		t.Fatalf("SaveStudentsBatch() error = %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "degraded-snapshot.json")
+	counts, err := Export(ctx, db, path)
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if want := (ExportCounts{Courses: 1, Contacts: 1, Students: 1}); counts != want {
+		t.Errorf("Export() = %+v, want %+v", counts, want)
+	}
+
+	loaded, snap, err := Load(ctx, path, filepath.Join(t.TempDir(), "degraded.db"), 168*time.Hour)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	t.Cleanup(func() { _ = loaded.Close(ctx) })
+
+	if snap.ExportedAt.IsZero() || len(snap.Courses) != 1 {
+		t.Errorf("snapshot = %+v, want export time and one course", snap)
+	}
+	if courses, err := loaded.SearchCoursesByTitle(ctx, "微積分"); err != nil || len(courses) != 1 {
+		t.Errorf("SearchCoursesByTitle() = (%v, %v), want the exported course", courses, err)
+	}
+	if contact, _ := loaded.GetContactByUID(ctx, "85"); contact == nil || contact.Name != "陳大華" {
+		t.Errorf("GetContactByUID() = %+v, want the exported contact", contact)
+	}
+	if student, _ := loaded.GetStudentByID(ctx, "41247001"); student == nil || student.Name != "王小明" {
+		t.Errorf("GetStudentByID() = %+v, want the exported student", student)
+	}
+	if banner := Banner(snap); !strings.Contains(banner, "降級模式") {
+		t.Errorf("Banner() = %q, want the degraded mode notice", banner)
+	}
+}
+
+func TestExport_EmptyCacheKeepsSnapshot(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "degraded-snapshot.json")
+	if err := os.WriteFile(path, []byte(`{"version":1}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Export(context.Background(), newTestDB(t, "empty.db"), path); err == nil {
+		t.Fatal("Export() of an empty cache succeeded, want error")
+	}
+	if data, _ := os.ReadFile(path); string(data) != `{"version":1}` {
+		t.Errorf("snapshot replaced by an empty export: %s", data)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	future := filepath.Join(dir, "future.json")
+	if err := os.WriteFile(future, []byte(`{"version":99}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := Load(context.Background(), future, filepath.Join(dir, "a.db"), time.Hour); !errors.Is(err, ErrSnapshotVersion) {
+		t.Errorf("Load(version 99) error = %v, want ErrSnapshotVersion", err)
+	}
+	if _, _, err := Load(context.Background(), filepath.Join(dir, "missing.json"), filepath.Join(dir, "b.db"), time.Hour); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Load(missing) error = %v, want os.ErrNotExist", err)
+	}
+}
+
+func TestExporter_Stale(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "degraded-snapshot.json")
+	e := NewExporter(nil, path, func() bool { return true }, logger.New("error"))
+
+	if !e.stale() {
+		t.Error("stale() = false for a missing snapshot, want true")
+	}
+	if err := os.WriteFile(path, []byte("{}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if e.stale() {
+		t.Error("stale() = true for a fresh snapshot, want false")
+	}
+	e.now = func() time.Time { return time.Now().Add(config.DegradedExportInterval) }
+	if !e.stale() {
+		t.Error("stale() = false for a day-old snapshot, want true")
+	}
+}
@@ -0,0 +1,76 @@
+package degraded
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+)
+
+// Exporter rewrites the snapshot once it is older than
+// config.DegradedExportInterval.
+type Exporter struct {
+	db     *storage.DB
+	path   string
+	ready  func() bool // Reports whether the initial refresh finished
+	logger *logger.Logger
+	now    func() time.Time
+}
+
+// NewExporter creates an exporter that writes db's snapshot to path. ready
+// gates every export, so a cache still warming up never replaces a full
+// snapshot.
+func NewExporter(db *storage.DB, path string, ready func() bool, log *logger.Logger) *Exporter {
+	return &Exporter{
+		db:     db,
+		path:   path,
+		ready:  ready,
+		logger: log,
+		now:    time.Now,
+	}
+}
+
+// Run checks the snapshot every config.DegradedExportCheckInterval until ctx
+// is done and exports when it is missing or outdated. Checking the file age
+// instead of exporting on a daily ticker keeps a snapshot on instances that
+// restart more often than daily. Failures are logged; the next check retries.
+func (e *Exporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(config.DegradedExportCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if e.ready() && e.stale() {
+				e.export(ctx)
+			}
+		}
+	}
+}
+
+// stale reports whether the snapshot is missing or older than the export interval.
+func (e *Exporter) stale() bool {
+	info, err := os.Stat(e.path)
+	return err != nil || e.now().Sub(info.ModTime()) >= config.DegradedExportInterval
+}
+
+func (e *Exporter) export(ctx context.Context) {
+	exportCtx, cancel := context.WithTimeout(ctx, config.DegradedExportTimeout)
+	defer cancel()
+
+	counts, err := Export(exportCtx, e.db, e.path)
+	if err != nil {
+		e.logger.WithError(err).Warn("Degraded snapshot export failed")
+		return
+	}
+	e.logger.WithField("path", e.path).
+		WithField("courses", counts.Courses).
+		WithField("contacts", counts.Contacts).
+		WithField("students", counts.Students).
+		Info("Degraded snapshot exported")
+}
@@ -0,0 +1,79 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+)
+
+// utf8BOM makes Excel detect UTF-8; without it Chinese text opens garbled.
+const utf8BOM = "\ufeff"
+
+// listSeparator joins multi-valued fields (teachers, times, locations) within a cell.
+const listSeparator = "; "
+
+var courseCSVHeader = []string{"uid", "year", "term", "no", "title", "teachers", "times", "locations", "detail_url", "note"}
+
+// CourseCSVWriter writes courses as CSV one row at a time, so an export can
+// stream rows from storage.DB.ForEachCourse. Output is buffered: nothing
+// reaches the underlying writer until the buffer fills or Flush is called.
+type CourseCSVWriter struct {
+	buf *bufio.Writer
+	csv *csv.Writer
+}
+
+// NewCourseCSVWriter returns a writer that has buffered the BOM and header row.
+func NewCourseCSVWriter(w io.Writer) (*CourseCSVWriter, error) {
+	buf := bufio.NewWriter(w)
+	if _, err := buf.WriteString(utf8BOM); err != nil {
+		return nil, err
+	}
+	cw := csv.NewWriter(buf)
+	if err := cw.Write(courseCSVHeader); err != nil {
+		return nil, err
+	}
+	return &CourseCSVWriter{buf: buf, csv: cw}, nil
+}
+
+// Write writes one course row.
+func (w *CourseCSVWriter) Write(c *storage.Course) error {
+	return w.csv.Write([]string{
+		c.UID,
+		strconv.Itoa(c.Year),
+		strconv.Itoa(c.Term),
+		c.No,
+		c.Title,
+		strings.Join(c.Teachers, listSeparator),
+		strings.Join(c.Times, listSeparator),
+		strings.Join(c.Locations, listSeparator),
+		c.DetailURL,
+		c.Note,
+	})
+}
+
+// Flush writes any buffered rows to the underlying writer.
+func (w *CourseCSVWriter) Flush() error {
+	w.csv.Flush()
+	if err := w.csv.Error(); err != nil {
+		return err
+	}
+	return w.buf.Flush()
+}
+
+// WriteCoursesCSV writes courses as CSV with a header row.
+func WriteCoursesCSV(w io.Writer, courses []storage.Course) error {
+	cw, err := NewCourseCSVWriter(w)
+	if err != nil {
+		return err
+	}
+	for i := range courses {
+		if err := cw.Write(&courses[i]); err != nil {
+			return err
+		}
+	}
+	return cw.Flush()
+}
@@ -0,0 +1,55 @@
+package export
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+)
+
+func TestWriteCoursesCSV(t *testing.T) {
+	t.Parallel()
+
+	courses := []storage.Course{
+		{
+			UID: "1131U0001", Year: 113, Term: 1, No: "U0001", Title: "程式設計, 一",
+			Teachers:  []string{"王老師", "李老師"},
+			Times:     []string{"每週一3~4", "每週三5~6"},
+			Locations: []string{"電4F01", "電4F02"},
+		},
+	}
+
+	var b strings.Builder
+	if err := WriteCoursesCSV(&b, courses); err != nil {
+		t.Fatalf("WriteCoursesCSV() error: %v", err)
+	}
+
+	out := b.String()
+	if !strings.HasPrefix(out, utf8BOM) {
+		t.Error("output should start with a UTF-8 BOM")
+	}
+
+	records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(out, utf8BOM))).ReadAll()
+	if err != nil {
+		t.Fatalf("output is not valid CSV: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want header + 1", len(records))
+	}
+
+	row := records[1]
+	want := map[int]string{
+		0: "1131U0001",
+		1: "113",
+		4: "程式設計, 一",
+		5: "王老師; 李老師",
+		6: "每週一3~4; 每週三5~6",
+		7: "電4F01; 電4F02",
+	}
+	for col, v := range want {
+		if row[col] != v {
+			t.Errorf("column %s = %q, want %q", courseCSVHeader[col], row[col], v)
+		}
+	}
+}
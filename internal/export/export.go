@@ -0,0 +1,78 @@
+// Package export renders course data for external consumers: a department's
+// course list as CSV and a course's weekly meetings as an iCalendar feed.
+//
+// Export URLs are public (calendar apps cannot send auth headers), so each one
+// carries a token derived from NTPU_EXPORT_SECRET with HMAC-SHA256. Tokens are
+// stable for a given export, so a subscribed calendar keeps working across
+// restarts and instances, and rotating the secret revokes every link at once.
+package export
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+)
+
+// Export kinds, mixed into the token so a CSV token can't be reused for a calendar.
+const (
+	KindDepartmentCSV  = "department_csv"
+	KindCourseCalendar = "course_ics"
+)
+
+// tokenBytes is the truncated HMAC length (128 bits is plenty for URL tokens).
+const tokenBytes = 16
+
+// Signer issues and verifies export tokens.
+type Signer struct {
+	secret []byte
+}
+
+// NewSigner creates a signer keyed by secret.
+func NewSigner(secret string) *Signer {
+	return &Signer{secret: []byte(secret)}
+}
+
+// Token returns the stable token for an export of kind identified by key
+// (department name or course UID).
+func (s *Signer) Token(kind, key string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(kind))
+	mac.Write([]byte{0})
+	mac.Write([]byte(key))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:tokenBytes])
+}
+
+// Verify reports whether token is valid for the export in constant time.
+func (s *Signer) Verify(kind, key, token string) bool {
+	return hmac.Equal([]byte(s.Token(kind, key)), []byte(token))
+}
+
+// DepartmentCSVPath returns the signed path of a department's course list.
+func (s *Signer) DepartmentCSVPath(department string) string {
+	return fmt.Sprintf("/export/departments/%s/courses.csv?token=%s",
+		url.PathEscape(department), s.Token(KindDepartmentCSV, department))
+}
+
+// CourseCalendarPath returns the signed path of a course's calendar feed.
+func (s *Signer) CourseCalendarPath(uid string) string {
+	return fmt.Sprintf("/export/courses/%s/calendar.ics?token=%s",
+		url.PathEscape(uid), s.Token(KindCourseCalendar, uid))
+}
+
+// Links builds the absolute export URLs users open from chat replies.
+type Links struct {
+	baseURL string
+	signer  *Signer
+}
+
+// NewLinks creates links on baseURL, the public origin serving /export.
+func NewLinks(baseURL string, signer *Signer) *Links {
+	return &Links{baseURL: baseURL, signer: signer}
+}
+
+// CourseCalendarURL returns the calendar feed URL a calendar app subscribes to.
+func (l *Links) CourseCalendarURL(uid string) string {
+	return l.baseURL + l.signer.CourseCalendarPath(uid)
+}
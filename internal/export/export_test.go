@@ -0,0 +1,55 @@
+package export
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSigner(t *testing.T) {
+	t.Parallel()
+
+	s := NewSigner("0123456789abcdef")
+	token := s.Token(KindDepartmentCSV, "資工系")
+
+	if token != s.Token(KindDepartmentCSV, "資工系") {
+		t.Fatal("token must be stable for the same export")
+	}
+
+	tests := []struct {
+		name   string
+		signer *Signer
+		kind   string
+		key    string
+		token  string
+		want   bool
+	}{
+		{"valid", s, KindDepartmentCSV, "資工系", token, true},
+		{"other key", s, KindDepartmentCSV, "經濟系", token, false},
+		{"other kind", s, KindCourseCalendar, "資工系", token, false},
+		{"other secret", NewSigner("fedcba9876543210"), KindDepartmentCSV, "資工系", token, false},
+		{"empty token", s, KindDepartmentCSV, "資工系", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.signer.Verify(tt.kind, tt.key, tt.token); got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinks(t *testing.T) {
+	t.Parallel()
+
+	s := NewSigner("0123456789abcdef")
+	got := NewLinks("https://bot.example.com", s).CourseCalendarURL("1131U0001")
+	want := "https://bot.example.com/export/courses/1131U0001/calendar.ics?token=" + s.Token(KindCourseCalendar, "1131U0001")
+	if got != want {
+		t.Errorf("CourseCalendarURL() = %q, want %q", got, want)
+	}
+	if path := s.DepartmentCSVPath("資工系"); !strings.HasPrefix(path, "/export/departments/%E8%B3%87%E5%B7%A5%E7%B3%BB/courses.csv?token=") {
+		t.Errorf("DepartmentCSVPath() = %q, want the escaped department", path)
+	}
+}
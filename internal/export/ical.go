@@ -0,0 +1,154 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+)
+
+// semesterWeeks is the number of teaching weeks in an NTPU semester.
+const semesterWeeks = 18
+
+// Meeting is a single weekly class meeting.
+type Meeting struct {
+	Weekday  time.Weekday
+	Start    string // HH:MM
+	End      string // HH:MM
+	Location string
+}
+
+// ParseMeetings extracts weekly meetings from a course's time strings.
+// Entries that don't name a weekday and period range (e.g., "每週未維護") are skipped.
+func ParseMeetings(course *storage.Course) []Meeting {
+	meetings := make([]Meeting, 0, len(course.Times))
+	for i, t := range course.Times {
+		cm, ok := lineutil.ParseCourseMeeting(t)
+		if !ok {
+			continue
+		}
+		start, end := lineutil.GetPeriodRangeTime(cm.StartPeriod, cm.EndPeriod)
+		meeting := Meeting{Weekday: cm.Weekday, Start: start, End: end}
+		if i < len(course.Locations) {
+			meeting.Location = course.Locations[i]
+		}
+		meetings = append(meetings, meeting)
+	}
+	return meetings
+}
+
+// SemesterStart estimates the Monday of the first teaching week.
+// The school calendar is not scraped; fall semesters start on the Monday on or
+// after September 8 and spring semesters on the Monday on or after February 15,
+// which matches recent NTPU calendars.
+func SemesterStart(year, term int) time.Time {
+	loc := lineutil.GetTaipeiLocation()
+	day := time.Date(year+1911, time.September, 8, 0, 0, 0, 0, loc)
+	if term == 2 {
+		day = time.Date(year+1912, time.February, 15, 0, 0, 0, 0, loc)
+	}
+	for day.Weekday() != time.Monday {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day
+}
+
+// WriteCourseCalendar writes an iCalendar (RFC 5545) feed with one weekly
+// recurring event per meeting, repeating for the whole semester.
+func WriteCourseCalendar(w io.Writer, course *storage.Course) error {
+	stamp := time.Now().UTC()
+	if course.CachedAt > 0 {
+		stamp = time.Unix(course.CachedAt, 0).UTC()
+	}
+	weekStart := SemesterStart(course.Year, course.Term)
+
+	cw := &calendarWriter{w: w}
+	cw.line("BEGIN:VCALENDAR")
+	cw.line("VERSION:2.0")
+	cw.line("PRODID:-//ntpu-linebot-go//course export//ZH-TW")
+	cw.line("CALSCALE:GREGORIAN")
+	cw.line("METHOD:PUBLISH")
+	cw.prop("X-WR-CALNAME", course.Title)
+	cw.line("X-WR-TIMEZONE:Asia/Taipei")
+	// Taiwan has no DST, so a single STANDARD block fully describes the zone
+	cw.line("BEGIN:VTIMEZONE")
+	cw.line("TZID:Asia/Taipei")
+	cw.line("BEGIN:STANDARD")
+	cw.line("DTSTART:19700101T000000")
+	cw.line("TZOFFSETFROM:+0800")
+	cw.line("TZOFFSETTO:+0800")
+	cw.line("TZNAME:CST")
+	cw.line("END:STANDARD")
+	cw.line("END:VTIMEZONE")
+
+	var details []string
+	for _, d := range []string{course.No, strings.Join(course.Teachers, "、"), course.Note} {
+		if d != "" {
+			details = append(details, d)
+		}
+	}
+	description := strings.Join(details, "\n")
+	for i, m := range ParseMeetings(course) {
+		offset := (int(m.Weekday) - int(time.Monday) + 7) % 7
+		day := weekStart.AddDate(0, 0, offset).Format("20060102")
+
+		cw.line("BEGIN:VEVENT")
+		cw.line(fmt.Sprintf("UID:%s-%d@ntpu-linebot", course.UID, i))
+		cw.line("DTSTAMP:" + stamp.Format("20060102T150405Z"))
+		cw.line("DTSTART;TZID=Asia/Taipei:" + day + "T" + strings.ReplaceAll(m.Start, ":", "") + "00")
+		cw.line("DTEND;TZID=Asia/Taipei:" + day + "T" + strings.ReplaceAll(m.End, ":", "") + "00")
+		cw.line(fmt.Sprintf("RRULE:FREQ=WEEKLY;COUNT=%d", semesterWeeks))
+		cw.prop("SUMMARY", course.Title)
+		if m.Location != "" {
+			cw.prop("LOCATION", m.Location)
+		}
+		cw.prop("DESCRIPTION", description)
+		if course.DetailURL != "" {
+			cw.line("URL:" + course.DetailURL)
+		}
+		cw.line("END:VEVENT")
+	}
+
+	cw.line("END:VCALENDAR")
+	return cw.err
+}
+
+// calendarWriter writes CRLF-terminated content lines folded at 75 octets.
+type calendarWriter struct {
+	w   io.Writer
+	err error
+}
+
+// prop writes a text property, escaping its value.
+func (c *calendarWriter) prop(name, value string) {
+	c.line(name + ":" + escapeText(value))
+}
+
+func (c *calendarWriter) line(s string) {
+	if c.err != nil {
+		return
+	}
+	var b strings.Builder
+	width := 0
+	for _, r := range s {
+		size := len(string(r))
+		if width+size > 75 {
+			// Continuation lines start with a space, which counts toward the limit
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	b.WriteString("\r\n")
+	_, c.err = io.WriteString(c.w, b.String())
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
@@ -0,0 +1,122 @@
+package export
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+)
+
+func TestParseMeetings(t *testing.T) {
+	t.Parallel()
+
+	course := &storage.Course{
+		Times:     []string{"每週二3~4", "每週未維護", "每週五10~11"},
+		Locations: []string{"商3F09", "", "法1F01"},
+	}
+
+	got := ParseMeetings(course)
+	want := []Meeting{
+		{Weekday: time.Tuesday, Start: "10:10", End: "12:00", Location: "商3F09"},
+		{Weekday: time.Friday, Start: "18:30", End: "20:15", Location: "法1F01"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d meetings, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("meeting[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestSemesterStart(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		year, term int
+		want       string
+	}{
+		{113, 1, "2024-09-09"}, // Sep 8 2024 is a Sunday
+		{114, 1, "2025-09-08"}, // Sep 8 2025 is already a Monday
+		{113, 2, "2025-02-17"},
+		{114, 2, "2026-02-16"},
+	}
+
+	for _, tt := range tests {
+		got := SemesterStart(tt.year, tt.term)
+		if got.Format("2006-01-02") != tt.want {
+			t.Errorf("SemesterStart(%d, %d) = %s, want %s", tt.year, tt.term, got.Format("2006-01-02"), tt.want)
+		}
+		if got.Weekday() != time.Monday {
+			t.Errorf("SemesterStart(%d, %d) is a %s, want Monday", tt.year, tt.term, got.Weekday())
+		}
+	}
+}
+
+func TestWriteCourseCalendar(t *testing.T) {
+	t.Parallel()
+
+	course := &storage.Course{
+		UID: "1131U0001", Year: 113, Term: 1, No: "U0001",
+		Title:     "程式設計; 進階",
+		Teachers:  []string{"王老師"},
+		Times:     []string{"每週三5~6"},
+		Locations: []string{"電4F01"},
+		DetailURL: "https://example.com/syllabus",
+		CachedAt:  1725000000,
+	}
+
+	var b strings.Builder
+	if err := WriteCourseCalendar(&b, course); err != nil {
+		t.Fatalf("WriteCourseCalendar() error: %v", err)
+	}
+	out := b.String()
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"UID:1131U0001-0@ntpu-linebot\r\n",
+		"DTSTART;TZID=Asia/Taipei:20240911T131000\r\n", // Wednesday of the first week
+		"DTEND;TZID=Asia/Taipei:20240911T150000\r\n",
+		"RRULE:FREQ=WEEKLY;COUNT=18\r\n",
+		`SUMMARY:程式設計\; 進階`,
+		"LOCATION:電4F01\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("calendar missing %q", want)
+		}
+	}
+
+	for line := range strings.SplitSeq(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line exceeds 75 octets (%d): %q", len(line), line)
+		}
+	}
+}
+
+func TestCalendarWriterFolding(t *testing.T) {
+	t.Parallel()
+
+	var b strings.Builder
+	cw := &calendarWriter{w: &b}
+	cw.prop("DESCRIPTION", strings.Repeat("課", 40)) // 120 octets of multi-byte text
+
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n")
+	if len(lines) < 2 {
+		t.Fatalf("expected folded output, got %q", b.String())
+	}
+	for i, line := range lines {
+		if len(line) > 75 {
+			t.Errorf("line %d exceeds 75 octets: %d", i, len(line))
+		}
+		if i > 0 && !strings.HasPrefix(line, " ") {
+			t.Errorf("continuation line %d must start with a space", i)
+		}
+	}
+	unfolded := strings.ReplaceAll(b.String(), "\r\n ", "")
+	if unfolded != "DESCRIPTION:"+strings.Repeat("課", 40)+"\r\n" {
+		t.Errorf("unfolding changed the content: %q", unfolded)
+	}
+}
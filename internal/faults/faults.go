@@ -0,0 +1,122 @@
+// Package faults injects failures for resilience testing: scraper timeouts
+// and garbled pages, SQLite busy errors, and LLM errors, each at a rate set
+// with NTPU_FAULTS. It lets a staging bot exercise the degradation paths
+// (stale cache serving, BM25 fallback when query expansion fails, LLM model
+// cooldowns) end to end. Never enable it in production.
+package faults
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Faults, the keys of NTPU_FAULTS.
+const (
+	ScraperTimeout = "scraper_timeout" // Scraper requests fail as network timeouts
+	ScraperGarbled = "scraper_garbled" // Scraper requests return an unparsable page
+	StorageBusy    = "storage_busy"    // Cache reads and writes fail with SQLITE_BUSY
+	LLMError       = "llm_error"       // Each LLM model call fails with a 503
+)
+
+// known lists the faults New accepts.
+var known = []string{ScraperTimeout, ScraperGarbled, StorageBusy, LLMError}
+
+// ErrInjected is wrapped by every injected failure, so logs and tests can
+// tell them from real ones.
+var ErrInjected = errors.New("injected fault")
+
+// garbledPage stands in for a page whose markup the parsers no longer match.
+const garbledPage = "<html><body>\x00��<div class=\"<<<\"><table><tr><td>�</body>"
+
+// Injector decides when each fault fires. A nil Injector never fires, so
+// components hold one unconditionally. It is safe for concurrent use.
+type Injector struct {
+	rates map[string]float64 // Fault -> probability in (0, 1]
+}
+
+// New creates an injector from NTPU_FAULTS pairs of fault and rate
+// (0 to 1, e.g., {"scraper_timeout": "0.2"}). Returns an error for an
+// unknown fault or invalid rate.
+func New(rates map[string]string) (*Injector, error) {
+	inj := &Injector{rates: make(map[string]float64, len(rates))}
+	for _, fault := range slices.Sorted(maps.Keys(rates)) {
+		if !slices.Contains(known, fault) {
+			return nil, fmt.Errorf("unknown fault %q (want one of %s)", fault, strings.Join(known, ", "))
+		}
+		rate, err := strconv.ParseFloat(rates[fault], 64)
+		if err != nil || rate < 0 || rate > 1 {
+			return nil, fmt.Errorf("fault %q rate must be between 0 and 1, got %q", fault, rates[fault])
+		}
+		if rate > 0 {
+			inj.rates[fault] = rate
+		}
+	}
+	return inj, nil
+}
+
+// Rates returns the rate of every enabled fault, for the startup log.
+func (i *Injector) Rates() map[string]float64 {
+	if i == nil {
+		return nil
+	}
+	return maps.Clone(i.rates)
+}
+
+// Fire reports whether fault happens on this call.
+func (i *Injector) Fire(fault string) bool {
+	if i == nil {
+		return false
+	}
+	rate := i.rates[fault]
+	return rate > 0 && rand.Float64() < rate //nolint:gosec // Fault sampling needs no crypto randomness
+}
+
+// Transport wraps next so requests fail with ScraperTimeout and
+// ScraperGarbled. Garbled requests never reach the upstream.
+func (i *Injector) Transport(next http.RoundTripper) http.RoundTripper {
+	if i == nil {
+		return next
+	}
+	return &transport{next: next, faults: i}
+}
+
+type transport struct {
+	next   http.RoundTripper
+	faults *Injector
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.faults.Fire(ScraperTimeout) {
+		return nil, timeoutError{}
+	}
+	if t.faults.Fire(ScraperGarbled) {
+		return &http.Response{
+			Status:        "200 OK",
+			StatusCode:    http.StatusOK,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{"Content-Type": {"text/html; charset=utf-8"}},
+			Body:          io.NopCloser(strings.NewReader(garbledPage)),
+			ContentLength: int64(len(garbledPage)),
+			Request:       req,
+		}, nil
+	}
+	return t.next.RoundTrip(req)
+}
+
+// timeoutError is an injected network timeout; it satisfies net.Error so
+// callers retry it like a real one.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return ErrInjected.Error() + ": i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+func (timeoutError) Unwrap() error   { return ErrInjected }
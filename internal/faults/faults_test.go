@@ -0,0 +1,101 @@
+package faults
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		rates   map[string]string
+		wantErr bool
+	}{
+		{"empty", nil, false},
+		{"valid", map[string]string{ScraperTimeout: "0.2", LLMError: "1"}, false},
+		{"zero rate", map[string]string{StorageBusy: "0"}, false},
+		{"unknown fault", map[string]string{"disk_full": "0.5"}, true},
+		{"rate above one", map[string]string{LLMError: "1.5"}, true},
+		{"negative rate", map[string]string{LLMError: "-0.1"}, true},
+		{"not a number", map[string]string{LLMError: "half"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := New(tt.rates)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New(%v) error = %v, wantErr %v", tt.rates, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFire(t *testing.T) {
+	t.Parallel()
+
+	var nilInjector *Injector
+	if nilInjector.Fire(LLMError) {
+		t.Error("nil injector fired")
+	}
+
+	inj, err := New(map[string]string{LLMError: "1", StorageBusy: "0"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	for range 100 {
+		if !inj.Fire(LLMError) {
+			t.Fatal("fault with rate 1 did not fire")
+		}
+		if inj.Fire(StorageBusy) || inj.Fire(ScraperTimeout) {
+			t.Fatal("disabled fault fired")
+		}
+	}
+	if got := inj.Rates(); len(got) != 1 || got[LLMError] != 1 {
+		t.Errorf("Rates() = %v, want only %s", got, LLMError)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	t.Parallel()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("<html>ok</html>"))
+	}))
+	t.Cleanup(upstream.Close)
+
+	get := func(t *testing.T, rates map[string]string) (string, error) {
+		t.Helper()
+		inj, err := New(rates)
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		client := &http.Client{Transport: inj.Transport(http.DefaultTransport)}
+		resp, err := client.Get(upstream.URL)
+		if err != nil {
+			return "", err
+		}
+		defer func() { _ = resp.Body.Close() }()
+		body, err := io.ReadAll(resp.Body)
+		return string(body), err
+	}
+
+	if body, err := get(t, nil); err != nil || body != "<html>ok</html>" {
+		t.Errorf("without faults = %q, %v; want the upstream page", body, err)
+	}
+
+	_, err := get(t, map[string]string{ScraperTimeout: "1"})
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() || !errors.Is(err, ErrInjected) {
+		t.Errorf("scraper_timeout error = %v, want an injected net timeout", err)
+	}
+
+	if body, err := get(t, map[string]string{ScraperGarbled: "1"}); err != nil || body != garbledPage {
+		t.Errorf("scraper_garbled = %q, %v; want the garbled page", body, err)
+	}
+}
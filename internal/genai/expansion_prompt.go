@@ -0,0 +1,233 @@
+package genai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+// DefaultExpansionPromptVersion labels the built-in query expansion prompt.
+const DefaultExpansionPromptVersion = "builtin"
+
+// ErrInvalidExpansionPrompts is returned when a prompt file cannot be used.
+var ErrInvalidExpansionPrompts = errors.New("invalid query expansion prompts")
+
+// versionRegex limits versions to short tokens: they are metric labels and
+// travel inside postback data.
+var versionRegex = regexp.MustCompile(`^[A-Za-z0-9._-]{1,32}$`)
+
+// ExpansionExample is one few-shot example of the query expansion prompt.
+type ExpansionExample struct {
+	Input    string `json:"input"`
+	Analysis string `json:"analysis"`
+	Keywords string `json:"keywords"`
+}
+
+// ExpansionPrompt is one version of the query expansion prompt: task
+// instructions followed by few-shot examples and the user query.
+type ExpansionPrompt struct {
+	Version      string
+	Weight       int // Share of chats served when several versions run as an A/B test
+	Instructions string
+	Examples     []ExpansionExample
+}
+
+// defaultExpansionPrompt is the prompt used without a prompt file.
+var defaultExpansionPrompt = &ExpansionPrompt{
+	Version:      DefaultExpansionPromptVersion,
+	Weight:       1,
+	Instructions: queryExpansionInstructions,
+	Examples:     queryExpansionExamples,
+}
+
+// Render builds the prompt text for query. The output format it asks for is
+// the one ParseExpandedOutput expects.
+func (p *ExpansionPrompt) Render(query string) string {
+	var sb strings.Builder
+	sb.WriteString(p.Instructions)
+	if len(p.Examples) > 0 {
+		sb.WriteString("\n\n## 範例")
+		for _, ex := range p.Examples {
+			sb.WriteString("\n\n輸入：" + ex.Input)
+			sb.WriteString("\n分析：" + ex.Analysis)
+			sb.WriteString("\n關鍵詞：" + ex.Keywords)
+		}
+	}
+	sb.WriteString("\n\n## 使用者查詢\n" + query + "\n")
+	return sb.String()
+}
+
+// promptFile is the JSON layout of NTPU_QUERY_EXPANSION_PROMPTS.
+// Omitting instructions or examples reuses the built-in ones, so a variant
+// that only changes the examples stays short; "examples": [] drops them.
+type promptFile struct {
+	Variants []struct {
+		Version      string              `json:"version"`
+		Weight       int                 `json:"weight"`
+		Instructions string              `json:"instructions"`
+		Examples     *[]ExpansionExample `json:"examples"`
+	} `json:"variants"`
+}
+
+// promptSet is an immutable list of weighted prompt versions.
+type promptSet struct {
+	variants []*ExpansionPrompt
+	total    int
+}
+
+// ExpansionPrompts holds the query expansion prompt versions in use and
+// splits chats between them by weight. Prompts are read from a JSON file at
+// startup and can be re-read with Reload, so prompt changes ship without a
+// rebuild. A nil *ExpansionPrompts serves the built-in prompt.
+// It is safe for concurrent use.
+type ExpansionPrompts struct {
+	path string
+	set  atomic.Pointer[promptSet]
+}
+
+// LoadExpansionPrompts reads prompt versions from the JSON file at path.
+// An empty path serves the built-in prompt only.
+func LoadExpansionPrompts(path string) (*ExpansionPrompts, error) {
+	p := &ExpansionPrompts{path: path}
+	if _, err := p.Reload(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Reload re-reads the prompt file and returns the versions now served.
+// On error the versions already loaded stay in use.
+func (p *ExpansionPrompts) Reload() ([]string, error) {
+	set := &promptSet{variants: []*ExpansionPrompt{defaultExpansionPrompt}, total: 1}
+	if p.path != "" {
+		var err error
+		if set, err = readPromptFile(p.path); err != nil {
+			return nil, err
+		}
+	}
+	p.set.Store(set)
+	return set.versions(), nil
+}
+
+func readPromptFile(path string) (*promptSet, error) {
+	raw, err := os.ReadFile(path) //nolint:gosec // Operator-supplied config path
+	if err != nil {
+		return nil, fmt.Errorf("read query expansion prompts: %w", err)
+	}
+	var file promptFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidExpansionPrompts, path, err)
+	}
+	if len(file.Variants) == 0 {
+		return nil, fmt.Errorf("%w: %s: no variants", ErrInvalidExpansionPrompts, path)
+	}
+
+	set := &promptSet{}
+	seen := make(map[string]bool, len(file.Variants))
+	for _, v := range file.Variants {
+		if !versionRegex.MatchString(v.Version) {
+			return nil, fmt.Errorf("%w: version %q must be 1-32 letters, digits, '.', '_' or '-'", ErrInvalidExpansionPrompts, v.Version)
+		}
+		if seen[v.Version] {
+			return nil, fmt.Errorf("%w: duplicate version %q", ErrInvalidExpansionPrompts, v.Version)
+		}
+		seen[v.Version] = true
+		if v.Weight < 0 {
+			return nil, fmt.Errorf("%w: version %q has a negative weight", ErrInvalidExpansionPrompts, v.Version)
+		}
+
+		prompt := &ExpansionPrompt{
+			Version:      v.Version,
+			Weight:       v.Weight,
+			Instructions: strings.TrimSpace(v.Instructions),
+			Examples:     queryExpansionExamples,
+		}
+		if prompt.Weight == 0 {
+			prompt.Weight = 1
+		}
+		if prompt.Instructions == "" {
+			prompt.Instructions = queryExpansionInstructions
+		}
+		if v.Examples != nil {
+			prompt.Examples = *v.Examples
+		}
+		set.variants = append(set.variants, prompt)
+		set.total += prompt.Weight
+	}
+	return set, nil
+}
+
+func (s *promptSet) versions() []string {
+	versions := make([]string, len(s.variants))
+	for i, v := range s.variants {
+		versions[i] = v.Version
+	}
+	return versions
+}
+
+// Pick returns the prompt version for key (a chat ID), so the same chat
+// always gets the same version while the weights are unchanged. An empty
+// key gets the first version.
+func (p *ExpansionPrompts) Pick(key string) *ExpansionPrompt {
+	if p == nil {
+		return defaultExpansionPrompt
+	}
+	set := p.set.Load()
+	if key == "" || len(set.variants) == 1 {
+		return set.variants[0]
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte("expansion:" + key))
+	n := int(h.Sum32() % uint32(set.total)) //nolint:gosec // total is a small positive sum of weights
+	for _, v := range set.variants {
+		if n < v.Weight {
+			return v
+		}
+		n -= v.Weight
+	}
+	return set.variants[0]
+}
+
+// Has reports whether version is one of the versions currently served.
+func (p *ExpansionPrompts) Has(version string) bool {
+	if p == nil {
+		return version == DefaultExpansionPromptVersion
+	}
+	for _, v := range p.set.Load().variants {
+		if v.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+// Versions returns the versions currently served, in file order.
+func (p *ExpansionPrompts) Versions() []string {
+	if p == nil {
+		return []string{DefaultExpansionPromptVersion}
+	}
+	return p.set.Load().versions()
+}
+
+type expansionPromptKey struct{}
+
+// WithExpansionPrompt makes expanders called with ctx use prompt instead of
+// the built-in one.
+func WithExpansionPrompt(ctx context.Context, prompt *ExpansionPrompt) context.Context {
+	return context.WithValue(ctx, expansionPromptKey{}, prompt)
+}
+
+// expansionPromptFromContext returns the prompt set by WithExpansionPrompt,
+// or the built-in prompt.
+func expansionPromptFromContext(ctx context.Context) *ExpansionPrompt {
+	if p, ok := ctx.Value(expansionPromptKey{}).(*ExpansionPrompt); ok && p != nil {
+		return p
+	}
+	return defaultExpansionPrompt
+}
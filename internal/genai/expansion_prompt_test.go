@@ -0,0 +1,123 @@
+package genai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writePromptFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "prompts.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	return path
+}
+
+func TestExpansionPrompt_Render(t *testing.T) {
+	t.Parallel()
+	p := &ExpansionPrompt{
+		Instructions: "指示",
+		Examples:     []ExpansionExample{{Input: "統計", Analysis: "想學統計", Keywords: "統計 statistics"}},
+	}
+	want := "指示\n\n## 範例\n\n輸入：統計\n分析：想學統計\n關鍵詞：統計 statistics\n\n## 使用者查詢\nAWS\n"
+	if got := p.Render("AWS"); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+
+	p.Examples = nil
+	if got := p.Render("AWS"); strings.Contains(got, "## 範例") {
+		t.Errorf("Render() without examples = %q, want no example section", got)
+	}
+}
+
+func TestLoadExpansionPrompts(t *testing.T) {
+	t.Parallel()
+
+	builtin, err := LoadExpansionPrompts("")
+	if err != nil {
+		t.Fatalf("LoadExpansionPrompts(\"\") error = %v", err)
+	}
+	if got := builtin.Pick("U1"); got != defaultExpansionPrompt {
+		t.Errorf("Pick() without a file = %q, want the built-in prompt", got.Version)
+	}
+
+	path := writePromptFile(t, `{"variants": [
+		{"version": "v1"},
+		{"version": "v2", "weight": 3, "instructions": "新指示", "examples": []}
+	]}`)
+	prompts, err := LoadExpansionPrompts(path)
+	if err != nil {
+		t.Fatalf("LoadExpansionPrompts() error = %v", err)
+	}
+	if got := prompts.Versions(); len(got) != 2 || got[0] != "v1" || got[1] != "v2" {
+		t.Fatalf("Versions() = %v, want [v1 v2]", got)
+	}
+	if !prompts.Has("v2") || prompts.Has(DefaultExpansionPromptVersion) {
+		t.Error("Has() should only report versions in the file")
+	}
+
+	// Omitted fields reuse the built-in instructions and examples
+	counts := map[string]int{}
+	for i := range 400 {
+		p := prompts.Pick(fmt.Sprintf("U%04d", i))
+		counts[p.Version]++
+		switch p.Version {
+		case "v1":
+			if p.Instructions != queryExpansionInstructions || len(p.Examples) != len(queryExpansionExamples) {
+				t.Fatal("v1 should use the built-in instructions and examples")
+			}
+		case "v2":
+			if p.Instructions != "新指示" || len(p.Examples) != 0 {
+				t.Fatal("v2 should use its own instructions and no examples")
+			}
+		}
+	}
+	if counts["v2"] <= counts["v1"] {
+		t.Errorf("Pick() split = %v, want v2 (weight 3) to get most chats", counts)
+	}
+	if prompts.Pick("U1") != prompts.Pick("U1") {
+		t.Error("Pick() should be stable for a chat")
+	}
+	if got := prompts.Pick(""); got.Version != "v1" {
+		t.Errorf("Pick(\"\") = %q, want the first version", got.Version)
+	}
+}
+
+func TestLoadExpansionPrompts_Invalid(t *testing.T) {
+	t.Parallel()
+	tests := map[string]string{
+		"no variants":     `{"variants": []}`,
+		"bad version":     `{"variants": [{"version": "v 1"}]}`,
+		"missing version": `{"variants": [{"weight": 1}]}`,
+		"duplicate":       `{"variants": [{"version": "v1"}, {"version": "v1"}]}`,
+		"negative weight": `{"variants": [{"version": "v1", "weight": -1}]}`,
+		"malformed":       `{"variants": [`,
+	}
+	for name, content := range tests {
+		if _, err := LoadExpansionPrompts(writePromptFile(t, content)); !errors.Is(err, ErrInvalidExpansionPrompts) {
+			t.Errorf("%s: error = %v, want ErrInvalidExpansionPrompts", name, err)
+		}
+	}
+}
+
+func TestExpansionPromptFromContext(t *testing.T) {
+	t.Parallel()
+	if got := expansionPromptFromContext(context.Background()); got != defaultExpansionPrompt {
+		t.Error("expansionPromptFromContext() without a prompt should return the built-in prompt")
+	}
+	p := &ExpansionPrompt{Version: "v2"}
+	if got := expansionPromptFromContext(WithExpansionPrompt(context.Background(), p)); got != p {
+		t.Errorf("expansionPromptFromContext() = %q, want v2", got.Version)
+	}
+
+	var nilSet *ExpansionPrompts
+	if nilSet.Pick("U1") != defaultExpansionPrompt || !nilSet.Has(DefaultExpansionPromptVersion) {
+		t.Error("a nil *ExpansionPrompts should serve the built-in prompt")
+	}
+}
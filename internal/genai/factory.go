@@ -27,7 +27,7 @@ func CreateIntentParser(ctx context.Context, cfg LLMConfig) (IntentParser, error
 			continue
 		}
 		if p != nil {
-			parsers = append(parsers, p)
+			parsers = append(parsers, withIntentFaults(p, cfg.Faults))
 		}
 	}
 
@@ -42,7 +42,7 @@ func CreateIntentParser(ctx context.Context, cfg LLMConfig) (IntentParser, error
 		"primary", parsers[0].Provider(),
 		"chainSize", len(parsers))
 
-	return NewFallbackIntentParser(cfg.RetryConfig, parsers...), nil
+	return withIntentGuard(NewFallbackIntentParser(cfg.RetryConfig, parsers...), cfg.Guard), nil
 }
 
 // createIntentParserForProvider creates an IntentParser for a specific provider.
@@ -81,7 +81,7 @@ func CreateQueryExpander(ctx context.Context, cfg LLMConfig) (QueryExpander, err
 			continue
 		}
 		if e != nil {
-			expanders = append(expanders, e)
+			expanders = append(expanders, withExpanderFaults(e, cfg.Faults))
 		}
 	}
 
@@ -95,7 +95,7 @@ func CreateQueryExpander(ctx context.Context, cfg LLMConfig) (QueryExpander, err
 		"primary", expanders[0].Provider(),
 		"chainSize", len(expanders))
 
-	return NewFallbackQueryExpander(cfg.RetryConfig, expanders...), nil
+	return withExpanderGuard(NewFallbackQueryExpander(cfg.RetryConfig, expanders...), cfg.Guard), nil
 }
 
 type modelSpec struct {
@@ -0,0 +1,56 @@
+package genai
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/faults"
+)
+
+// injectedLLMError is the failure of a fired llm_error fault: a 503 from the
+// model's provider, so the fallback chain moves on as it would in an outage.
+func injectedLLMError(provider Provider) error {
+	return &LLMError{Err: faults.ErrInjected, StatusCode: http.StatusServiceUnavailable, Provider: provider}
+}
+
+// faultyIntentParser fails Parse when the llm_error fault fires.
+type faultyIntentParser struct {
+	IntentParser
+	faults *faults.Injector
+}
+
+// withIntentFaults wraps p with inj's llm_error fault, or returns p for a nil inj.
+func withIntentFaults(p IntentParser, inj *faults.Injector) IntentParser {
+	if inj == nil {
+		return p
+	}
+	return &faultyIntentParser{IntentParser: p, faults: inj}
+}
+
+func (p *faultyIntentParser) Parse(ctx context.Context, text string) (*ParseResult, error) {
+	if p.faults.Fire(faults.LLMError) {
+		return nil, injectedLLMError(p.Provider())
+	}
+	return p.IntentParser.Parse(ctx, text)
+}
+
+// faultyQueryExpander fails Expand when the llm_error fault fires.
+type faultyQueryExpander struct {
+	QueryExpander
+	faults *faults.Injector
+}
+
+// withExpanderFaults wraps e with inj's llm_error fault, or returns e for a nil inj.
+func withExpanderFaults(e QueryExpander, inj *faults.Injector) QueryExpander {
+	if inj == nil {
+		return e
+	}
+	return &faultyQueryExpander{QueryExpander: e, faults: inj}
+}
+
+func (e *faultyQueryExpander) Expand(ctx context.Context, query string) (string, error) {
+	if e.faults.Fire(faults.LLMError) {
+		return "", injectedLLMError(e.Provider())
+	}
+	return e.QueryExpander.Expand(ctx, query)
+}
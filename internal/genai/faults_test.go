@@ -0,0 +1,56 @@
+package genai
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/faults"
+)
+
+func TestFaults_FallBackToNextModel(t *testing.T) {
+	t.Parallel()
+	inj, err := faults.New(map[string]string{faults.LLMError: "1"})
+	if err != nil {
+		t.Fatalf("faults.New() error = %v", err)
+	}
+
+	primaryCalls := 0
+	primary := withIntentFaults(&mockIntentParser{
+		parseFunc: func(_ context.Context, _ string) (*ParseResult, error) {
+			primaryCalls++
+			return &ParseResult{Module: "primary"}, nil
+		},
+		provider: ProviderGemini,
+		model:    "primary-model",
+		enabled:  true,
+	}, inj)
+	fallback := &mockIntentParser{
+		parseFunc: func(_ context.Context, _ string) (*ParseResult, error) {
+			return &ParseResult{Module: "fallback"}, nil
+		},
+		provider: ProviderGroq,
+		model:    "fallback-model",
+		enabled:  true,
+	}
+
+	parser := newFallbackIntentParserWithCooldowns(DefaultRetryConfig(), newModelCooldownStore(), primary, fallback)
+	result, err := parser.Parse(context.Background(), "微積分")
+	if err != nil || result.Module != "fallback" {
+		t.Errorf("Parse() = %+v, %v; want the fallback model's result", result, err)
+	}
+	if primaryCalls != 0 {
+		t.Errorf("primary model called %d times, want the injected fault to fail it first", primaryCalls)
+	}
+
+	expander := withExpanderFaults(&mockQueryExpander{provider: ProviderGemini}, inj)
+	_, err = expander.Expand(context.Background(), "AI")
+	var llmErr *LLMError
+	if !errors.As(err, &llmErr) || !errors.Is(err, faults.ErrInjected) || ClassifyError(err) != ActionRetry {
+		t.Errorf("Expand() error = %v, want a retryable injected LLMError", err)
+	}
+
+	if withIntentFaults(fallback, nil) != IntentParser(fallback) {
+		t.Error("withIntentFaults(nil) should return the parser unchanged")
+	}
+}
@@ -17,9 +17,9 @@
 // JSON Schema spec ("string" not "STRING"). See buildGroqTools() in groq_intent.go for example.
 //
 // Module Organization:
-// - Course Module: course_search, course_smart, course_uid, course_extended, course_historical
+// - Course Module: course_search, course_smart, course_uid, course_extended, course_historical, course_random, course_remote
 // - ID Module: id_search, id_student_id, id_department, id_year, id_dept_codes
-// - Contact Module: contact_search, contact_emergency
+// - Contact Module: contact_search, contact_emergency, contact_organization, contact_extension
 // - Program Module: program_list, program_search, program_courses
 // - Usage Module: usage_query
 // - Help: help
@@ -31,7 +31,7 @@ import "google.golang.org/genai"
 // BuildIntentFunctions returns the function declarations for NLU intent parsing.
 // Model selects the appropriate function based on description match.
 //
-// Total: 18 functions across 7 modules
+// Total: 22 functions across 7 modules
 func BuildIntentFunctions() []*genai.FunctionDeclaration {
 	return []*genai.FunctionDeclaration{
 		// ============================================
@@ -153,6 +153,51 @@ func BuildIntentFunctions() []*genai.FunctionDeclaration {
 			},
 		},
 
+		// Random course discovery
+		{
+			Name: "course_random",
+			Description: `隨機推薦一門本學期的課程，鼓勵探索。
+
+觸發條件：要求隨機推薦、抽一門課，沒有指定課名或學習需求
+範例：隨機推薦一門課、抽一門碩士班的課、隨便推薦一門資工系的課
+
+注意：若使用者描述學習目標或條件（如「想學 AI」），請使用 course_smart`,
+			Parameters: &genai.Schema{
+				Type: genai.TypeObject,
+				Properties: map[string]*genai.Schema{
+					"degree": {
+						Type:        genai.TypeString,
+						Description: "學制：bachelor（大學部）、master（碩士班）、inservice（碩士在職專班）、phd（博士班）",
+						Enum:        []string{"bachelor", "master", "inservice", "phd"},
+					},
+					"department": {
+						Type:        genai.TypeString,
+						Description: "系所簡稱（如：資工、法律），未指定則省略",
+					},
+				},
+			},
+		},
+
+		// Remote (distance-learning) courses
+		{
+			Name: "course_remote",
+			Description: `搜尋最近學期的遠距（線上）課程。
+
+觸發條件：要找遠距、線上、不用到校上課的課程，可附帶課名或教師
+範例：有哪些遠距課程、線上上課的通識、有沒有遠距的管理課 → keyword=管理
+
+注意：只問課名而未提遠距/線上，請使用 course_search`,
+			Parameters: &genai.Schema{
+				Type: genai.TypeObject,
+				Properties: map[string]*genai.Schema{
+					"keyword": {
+						Type:        genai.TypeString,
+						Description: "課程名稱或教師姓名（不含遠距、線上等詞），未指定則省略",
+					},
+				},
+			},
+		},
+
 		// ============================================
 		// 2. ID Module (學生查詢)
 		// ============================================
@@ -289,6 +334,44 @@ func BuildIntentFunctions() []*genai.FunctionDeclaration {
 			},
 		},
 
+		// Organization members
+		{
+			Name: "contact_organization",
+			Description: `列出校內某單位的所有成員聯絡方式。
+
+觸發條件：詢問某單位「有哪些人」、成員名單
+範例：資工系有哪些老師、學務處成員、圖書館的人員`,
+			Parameters: &genai.Schema{
+				Type: genai.TypeObject,
+				Properties: map[string]*genai.Schema{
+					"organization": {
+						Type:        genai.TypeString,
+						Description: "單位全名或常用名稱（如：資訊工程學系、學生事務處）",
+					},
+				},
+				Required: []string{"organization"},
+			},
+		},
+
+		// Extension reverse lookup
+		{
+			Name: "contact_extension",
+			Description: `由分機號碼反查所屬單位或人員。
+
+觸發條件：提供分機號碼，詢問是誰或哪個單位
+範例：分機66223是哪裡、67890是誰的分機`,
+			Parameters: &genai.Schema{
+				Type: genai.TypeObject,
+				Properties: map[string]*genai.Schema{
+					"extension": {
+						Type:        genai.TypeString,
+						Description: "4-5 位數分機號碼，僅數字（如：66223）",
+					},
+				},
+				Required: []string{"extension"},
+			},
+		},
+
 		// ============================================
 		// 4. Program Module (學程查詢)
 		// ============================================
@@ -415,6 +498,8 @@ var IntentModuleMap = map[string][2]string{
 	"course_uid":        {"course", "uid"},
 	"course_extended":   {"course", "extended"},
 	"course_historical": {"course", "historical"},
+	"course_random":     {"course", "random"},
+	"course_remote":     {"course", "remote"},
 	// ID Module
 	"id_search":     {"id", "search"},
 	"id_student_id": {"id", "student_id"},
@@ -422,8 +507,10 @@ var IntentModuleMap = map[string][2]string{
 	"id_year":       {"id", "year"},
 	"id_dept_codes": {"id", "dept_codes"},
 	// Contact Module
-	"contact_search":    {"contact", "search"},
-	"contact_emergency": {"contact", "emergency"},
+	"contact_search":       {"contact", "search"},
+	"contact_emergency":    {"contact", "emergency"},
+	"contact_organization": {"contact", "organization"},
+	"contact_extension":    {"contact", "extension"},
 	// Program Module
 	"program_list":    {"program", "list"},
 	"program_search":  {"program", "search"},
@@ -447,7 +534,9 @@ var ParamKeysMap = map[string][]string{
 	"course_smart":      {"query"},
 	"course_uid":        {"uid"},
 	"course_extended":   {"keyword"},
-	"course_historical": {"year", "keyword"}, // Multi-param: both are required
+	"course_historical": {"year", "keyword"},      // Multi-param: both are required
+	"course_random":     {"degree", "department"}, // Optional params, handler picks from any
+	"course_remote":     {"keyword"},              // Optional param, handler lists all remote courses
 	// ID Module
 	"id_search":     {"name"},
 	"id_student_id": {"student_id"},
@@ -455,7 +544,9 @@ var ParamKeysMap = map[string][]string{
 	"id_year":       {"year"},
 	"id_dept_codes": {"degree"}, // Optional param, handler has default value
 	// Contact Module
-	"contact_search": {"query"},
+	"contact_search":       {"query"},
+	"contact_organization": {"organization"},
+	"contact_extension":    {"extension"},
 	// Program Module
 	"program_search":  {"query"},
 	"program_courses": {"programName"},
@@ -80,7 +80,7 @@ func (e *geminiQueryExpander) Expand(ctx context.Context, query string) (string,
 	// 2. Add synonyms and related terms
 	// 3. Clean up verbose queries to extract key concepts
 	// 4. Handle mixed Chinese/English with different information density
-	prompt := QueryExpansionPrompt(query)
+	prompt := expansionPromptFromContext(ctx).Render(query)
 
 	config := &genai.GenerateContentConfig{
 		Temperature:    genai.Ptr[float32](0.2), // Lower temperature reduces lexical drift for BM25
@@ -18,6 +18,8 @@ func TestIntentModuleMap(t *testing.T) {
 		"course_uid",
 		"course_extended",
 		"course_historical",
+		"course_random",
+		"course_remote",
 		// ID module
 		"id_search",
 		"id_student_id",
@@ -27,6 +29,8 @@ func TestIntentModuleMap(t *testing.T) {
 		// Contact module
 		"contact_search",
 		"contact_emergency",
+		"contact_organization",
+		"contact_extension",
 		// Program module
 		"program_list",
 		"program_search",
@@ -65,6 +69,8 @@ func TestParamKeysMap(t *testing.T) {
 		{"course_uid", []string{"uid"}, true},
 		{"course_extended", []string{"keyword"}, true},
 		{"course_historical", []string{"year", "keyword"}, true}, // Multi-param
+		{"course_random", []string{"degree", "department"}, true},
+		{"course_remote", []string{"keyword"}, true},
 		// ID module
 		{"id_search", []string{"name"}, true},
 		{"id_student_id", []string{"student_id"}, true},
@@ -74,6 +80,8 @@ func TestParamKeysMap(t *testing.T) {
 		// Contact module
 		{"contact_search", []string{"query"}, true},
 		{"contact_emergency", nil, false}, // No parameters
+		{"contact_organization", []string{"organization"}, true},
+		{"contact_extension", []string{"extension"}, true},
 		// Program module
 		{"program_list", nil, false}, // No parameters
 		{"program_search", []string{"query"}, true},
@@ -0,0 +1,247 @@
+package genai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"unicode"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/metrics"
+)
+
+// ErrUnsafeOutput is returned when model output fails the output guard and
+// must not reach users.
+var ErrUnsafeOutput = errors.New("unsafe LLM output")
+
+// Output limits, in runes.
+const (
+	maxReplyRunes     = 500 // direct_reply messages (a clarification, not an essay)
+	maxParamRunes     = 100 // NLU parameters such as keywords and names
+	maxExpansionRunes = 300 // query expansion keyword lines
+)
+
+// directReplyModule is the NLU module whose message is sent to users verbatim.
+const directReplyModule = "direct_reply"
+
+var (
+	// urlRegex finds links and bare domains the model could slip into a reply.
+	// Bare domains match in lowercase only so terms like ASP.NET pass.
+	urlRegex = regexp.MustCompile(`(?i:[a-z][a-z0-9+.-]*://|www\.)|\b[a-z0-9-]+\.(?:com|net|org|edu|gov|tw|io|me|ly|gl|app|xyz|cc|co|info|top|link)\b`)
+	// piiRegexes find personal data a reply must not contain: email
+	// addresses, phone numbers, national IDs and student-ID-like digit runs.
+	piiRegexes = []*regexp.Regexp{
+		regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+		regexp.MustCompile(`09\d{2}[-\s]?\d{3}[-\s]?\d{3}`),
+		regexp.MustCompile(`\(?0\d{1,2}\)?[-\s]?\d{3,4}[-\s]?\d{4}`),
+		regexp.MustCompile(`\b[A-Z][12]\d{8}\b`),
+		regexp.MustCompile(`\d{8,}`),
+	}
+)
+
+// allowedScripts are the writing systems replies and parameters may use:
+// Chinese (with Bopomofo), English, Japanese kana for course titles, and the
+// shared punctuation, digits and emoji (Common, Inherited).
+var allowedScripts = []*unicode.RangeTable{
+	unicode.Han, unicode.Bopomofo, unicode.Latin,
+	unicode.Hiragana, unicode.Katakana,
+	unicode.Common, unicode.Inherited,
+}
+
+// OutputGuard checks model output before it can reach users: NLU direct
+// replies and parameters, and query expansions. Text is cleaned of
+// invisible characters and capped in length; output with links, personal
+// data or characters outside the allowed scripts is rejected.
+//
+// The kill switch (SetBlocked) stops free-form model text entirely:
+// direct replies are rejected and expansions fall back to the original
+// query, while NLU routing with checked parameters keeps working.
+// It is safe for concurrent use.
+type OutputGuard struct {
+	blocked atomic.Bool
+}
+
+// NewOutputGuard creates a guard with the kill switch set to blocked.
+func NewOutputGuard(blocked bool) *OutputGuard {
+	g := &OutputGuard{}
+	g.blocked.Store(blocked)
+	return g
+}
+
+// SetBlocked turns the kill switch on or off.
+func (g *OutputGuard) SetBlocked(blocked bool) {
+	g.blocked.Store(blocked)
+}
+
+// Blocked reports whether the kill switch is on.
+func (g *OutputGuard) Blocked() bool {
+	return g.blocked.Load()
+}
+
+// CheckParse validates a parse result in place. It returns ErrUnsafeOutput
+// when the direct reply or a parameter cannot be sent to users.
+func (g *OutputGuard) CheckParse(result *ParseResult) error {
+	if result == nil {
+		return nil
+	}
+	if result.Module == directReplyModule && g.Blocked() {
+		recordOutputFiltered(operationNLU, "kill_switch")
+		return fmt.Errorf("%w: direct replies are blocked", ErrUnsafeOutput)
+	}
+
+	for key, value := range result.Params {
+		limit := maxParamRunes
+		if result.Module == directReplyModule {
+			limit = maxReplyRunes
+		}
+		clean, reason := checkText(value, limit, result.Module == directReplyModule)
+		if reason != "" {
+			recordOutputFiltered(operationNLU, reason)
+			return fmt.Errorf("%w: %s in %s", ErrUnsafeOutput, reason, key)
+		}
+		if clean != value {
+			recordOutputFiltered(operationNLU, "sanitized")
+		}
+		result.Params[key] = clean
+	}
+	return nil
+}
+
+// CheckExpansion validates an expanded query. With the kill switch on it
+// returns the original query; on failure it returns the original query and
+// ErrUnsafeOutput.
+func (g *OutputGuard) CheckExpansion(query, expanded string) (string, error) {
+	if g.Blocked() {
+		recordOutputFiltered(operationExpander, "kill_switch")
+		return query, nil
+	}
+	// Expansions are matched against syllabi, never shown: drop the
+	// offending terms instead of the whole expansion
+	terms := strings.Fields(stripInvisible(expanded))
+	kept := terms[:0]
+	for _, term := range terms {
+		if urlRegex.MatchString(term) || !inAllowedScripts(term) {
+			continue
+		}
+		kept = append(kept, term)
+	}
+	clean := truncateRunes(strings.Join(kept, " "), maxExpansionRunes)
+	if clean == "" {
+		recordOutputFiltered(operationExpander, "empty")
+		return query, fmt.Errorf("%w: no usable expansion terms", ErrUnsafeOutput)
+	}
+	if clean != expanded {
+		recordOutputFiltered(operationExpander, "sanitized")
+	}
+	return clean, nil
+}
+
+// checkText cleans s and caps it at limit runes. reason is non-empty when s
+// must be rejected: "url", "pii" (only when checkPII) or "charset".
+func checkText(s string, limit int, checkPII bool) (clean, reason string) {
+	clean = truncateRunes(strings.TrimSpace(stripInvisible(s)), limit)
+	if urlRegex.MatchString(clean) {
+		return "", "url"
+	}
+	if checkPII {
+		for _, re := range piiRegexes {
+			if re.MatchString(clean) {
+				return "", "pii"
+			}
+		}
+	}
+	if !inAllowedScripts(clean) {
+		return "", "charset"
+	}
+	return clean, ""
+}
+
+// stripInvisible removes control and format characters (bidi overrides,
+// zero-width spaces) that can disguise text, keeping newlines, tabs and the
+// zero-width joiner that emoji sequences need.
+func stripInvisible(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' || r == '\t' || r == '\u200d':
+			return r
+		case unicode.Is(unicode.Cc, r), unicode.Is(unicode.Cf, r), unicode.Is(unicode.Co, r):
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// inAllowedScripts reports whether every character of s is in allowedScripts.
+func inAllowedScripts(s string) bool {
+	for _, r := range s {
+		if !unicode.In(r, allowedScripts...) {
+			return false
+		}
+	}
+	return true
+}
+
+// truncateRunes cuts s to at most n runes, ending with "…" when cut.
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return strings.TrimSpace(string(runes[:n-1])) + "…"
+}
+
+func recordOutputFiltered(operation, reason string) {
+	if metrics.LLMOutputFiltered == nil {
+		return
+	}
+	metrics.LLMOutputFiltered.WithLabelValues(operation, reason).Inc()
+}
+
+// guardedIntentParser checks Parse results with an output guard.
+type guardedIntentParser struct {
+	IntentParser
+	guard *OutputGuard
+}
+
+// withIntentGuard wraps p with g, or returns p for a nil g.
+func withIntentGuard(p IntentParser, g *OutputGuard) IntentParser {
+	if g == nil || p == nil {
+		return p
+	}
+	return &guardedIntentParser{IntentParser: p, guard: g}
+}
+
+func (p *guardedIntentParser) Parse(ctx context.Context, text string) (*ParseResult, error) {
+	result, err := p.IntentParser.Parse(ctx, text)
+	if err != nil {
+		return result, err
+	}
+	if err := p.guard.CheckParse(result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// guardedQueryExpander checks Expand results with an output guard.
+type guardedQueryExpander struct {
+	QueryExpander
+	guard *OutputGuard
+}
+
+// withExpanderGuard wraps e with g, or returns e for a nil g.
+func withExpanderGuard(e QueryExpander, g *OutputGuard) QueryExpander {
+	if g == nil || e == nil {
+		return e
+	}
+	return &guardedQueryExpander{QueryExpander: e, guard: g}
+}
+
+func (e *guardedQueryExpander) Expand(ctx context.Context, query string) (string, error) {
+	expanded, err := e.QueryExpander.Expand(ctx, query)
+	if err != nil || expanded == query {
+		return expanded, err
+	}
+	return e.guard.CheckExpansion(query, expanded)
+}
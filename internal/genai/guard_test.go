@@ -0,0 +1,132 @@
+package genai
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestOutputGuard_CheckParse(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		result  *ParseResult
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:   "clarification passes",
+			result: &ParseResult{Module: "direct_reply", Params: map[string]string{"message": "請問您是想查詢：\n1️⃣ 王小明老師的課程？\n2️⃣ 王小明的聯絡方式？"}},
+			want:   map[string]string{"message": "請問您是想查詢：\n1️⃣ 王小明老師的課程？\n2️⃣ 王小明的聯絡方式？"},
+		},
+		{
+			name:   "invisible characters are stripped",
+			result: &ParseResult{Module: "course", Params: map[string]string{"keyword": " 微\u200b積分\u202e "}},
+			want:   map[string]string{"keyword": "微積分"},
+		},
+		{
+			name:   "student ID parameter passes",
+			result: &ParseResult{Module: "id", Params: map[string]string{"student_id": "412345678"}},
+			want:   map[string]string{"student_id": "412345678"},
+		},
+		{
+			name:   "ASP.NET keyword passes",
+			result: &ParseResult{Module: "course", Params: map[string]string{"keyword": "ASP.NET"}},
+			want:   map[string]string{"keyword": "ASP.NET"},
+		},
+		{name: "link in reply", result: &ParseResult{Module: "direct_reply", Params: map[string]string{"message": "請看 https://evil.example"}}, wantErr: true},
+		{name: "bare domain in reply", result: &ParseResult{Module: "direct_reply", Params: map[string]string{"message": "請到 ntpu-login.com 登入"}}, wantErr: true},
+		{name: "phone in reply", result: &ParseResult{Module: "direct_reply", Params: map[string]string{"message": "請撥 0912-345-678"}}, wantErr: true},
+		{name: "email in reply", result: &ParseResult{Module: "direct_reply", Params: map[string]string{"message": "寄信到 a.b@gmail.com"}}, wantErr: true},
+		{name: "student ID in reply", result: &ParseResult{Module: "direct_reply", Params: map[string]string{"message": "他的學號是 412345678"}}, wantErr: true},
+		{name: "disallowed script", result: &ParseResult{Module: "course", Params: map[string]string{"keyword": "аpple"}}, wantErr: true},
+		{name: "link in keyword", result: &ParseResult{Module: "course", Params: map[string]string{"keyword": "www.example.org"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := NewOutputGuard(false).CheckParse(tt.result)
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnsafeOutput) {
+					t.Errorf("CheckParse() error = %v, want ErrUnsafeOutput", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CheckParse() error = %v", err)
+			}
+			for k, v := range tt.want {
+				if got := tt.result.Params[k]; got != v {
+					t.Errorf("Params[%s] = %q, want %q", k, got, v)
+				}
+			}
+		})
+	}
+}
+
+func TestOutputGuard_Caps(t *testing.T) {
+	t.Parallel()
+	result := &ParseResult{Module: "direct_reply", Params: map[string]string{"message": strings.Repeat("好", 600)}}
+	if err := NewOutputGuard(false).CheckParse(result); err != nil {
+		t.Fatalf("CheckParse() error = %v", err)
+	}
+	if got := []rune(result.Params["message"]); len(got) != maxReplyRunes || got[len(got)-1] != '…' {
+		t.Errorf("message has %d runes, want %d ending in …", len(got), maxReplyRunes)
+	}
+}
+
+func TestOutputGuard_KillSwitch(t *testing.T) {
+	t.Parallel()
+	g := NewOutputGuard(true)
+
+	reply := &ParseResult{Module: "direct_reply", Params: map[string]string{"message": "你好"}}
+	if err := g.CheckParse(reply); !errors.Is(err, ErrUnsafeOutput) {
+		t.Errorf("CheckParse() direct_reply error = %v, want ErrUnsafeOutput", err)
+	}
+	search := &ParseResult{Module: "course", Intent: "search", Params: map[string]string{"keyword": "微積分"}}
+	if err := g.CheckParse(search); err != nil {
+		t.Errorf("CheckParse() routing error = %v, want nil", err)
+	}
+	if got, err := g.CheckExpansion("AWS", "AWS 雲端運算"); err != nil || got != "AWS" {
+		t.Errorf("CheckExpansion() = (%q, %v), want the original query", got, err)
+	}
+
+	g.SetBlocked(false)
+	if err := g.CheckParse(reply); err != nil {
+		t.Errorf("CheckParse() after unblocking error = %v", err)
+	}
+}
+
+func TestOutputGuard_CheckExpansion(t *testing.T) {
+	t.Parallel()
+	g := NewOutputGuard(false)
+
+	got, err := g.CheckExpansion("AWS", "AWS 雲端運算 https://aws.amazon.com cloud\u200b computing")
+	if err != nil || got != "AWS 雲端運算 cloud computing" {
+		t.Errorf("CheckExpansion() = (%q, %v), want offending terms dropped", got, err)
+	}
+	if got, err := g.CheckExpansion("AWS", "www.aws.com"); !errors.Is(err, ErrUnsafeOutput) || got != "AWS" {
+		t.Errorf("CheckExpansion() = (%q, %v), want the original query and ErrUnsafeOutput", got, err)
+	}
+}
+
+type stubIntentParser struct {
+	IntentParser
+	result *ParseResult
+}
+
+func (p stubIntentParser) Parse(context.Context, string) (*ParseResult, error) {
+	return p.result, nil
+}
+
+func TestWithIntentGuard(t *testing.T) {
+	t.Parallel()
+	inner := stubIntentParser{result: &ParseResult{Module: "direct_reply", Params: map[string]string{"message": "https://evil.example"}}}
+	if p := withIntentGuard(inner, nil); p != IntentParser(inner) {
+		t.Error("withIntentGuard() with a nil guard should return the parser unchanged")
+	}
+	if _, err := withIntentGuard(inner, NewOutputGuard(false)).Parse(context.Background(), "hi"); !errors.Is(err, ErrUnsafeOutput) {
+		t.Errorf("Parse() error = %v, want ErrUnsafeOutput", err)
+	}
+}
@@ -125,7 +125,7 @@ func (e *openaiQueryExpander) Expand(ctx context.Context, query string) (string,
 	// 2. Add synonyms and related terms
 	// 3. Clean up verbose queries to extract key concepts
 	// 4. Handle mixed Chinese/English with different information density
-	prompt := QueryExpansionPrompt(query)
+	prompt := expansionPromptFromContext(ctx).Render(query)
 
 	params := openai.ChatCompletionNewParams{
 		Model: e.model,
@@ -0,0 +1,33 @@
+package genai
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/genai"
+)
+
+// CheckGemini verifies the Gemini API key of cfg by looking up its first
+// intent model, so a revoked key or a retired model shows at startup instead
+// of on the first NLU call. It does nothing without a Gemini key.
+func CheckGemini(ctx context.Context, cfg LLMConfig) error {
+	if cfg.Gemini.APIKey == "" {
+		return nil
+	}
+	models := cfg.Gemini.IntentModels
+	if len(models) == 0 {
+		models = getDefaultIntentModels(ProviderGemini)
+	}
+	if len(models) == 0 {
+		return nil
+	}
+
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{APIKey: cfg.Gemini.APIKey})
+	if err != nil {
+		return fmt.Errorf("failed to create genai client: %w", err)
+	}
+	if _, err := client.Models.Get(ctx, models[0], nil); err != nil {
+		return fmt.Errorf("get model %s: %w", models[0], normalizeProviderError(err, ProviderGemini))
+	}
+	return nil
+}
@@ -30,6 +30,7 @@ const IntentParserSystemPrompt = `你是 NTPU 小工具的意圖分類助手。
 2. 西元年需轉換為民國年：西元年 - 1911（例：2024→113, 2025→114）
 3. course_smart 的 query 參數**必須保留使用者完整原文**，包含背景、條件與目標，不可簡化
 4. 區分「具體課名」（→ course_search）和「學習需求描述」（→ course_smart）
+5. 英文輸入（交換學生）：完整英文句子同樣依上述規則分類。課名可保留英文（課程收錄英文名稱），教師、單位名稱若為英文請譯為中文（library→圖書館）；direct_reply 以英文回覆
 
 ## 關鍵區分
 | 輸入 | 函式 | 原因 |
@@ -40,10 +41,14 @@ const IntentParserSystemPrompt = `你是 NTPU 小工具的意圖分類助手。
 | 我是資工的，想學金融 | course_smart | 跨領域需求（完整保留） |
 | 好過的課 | course_smart | 條件式描述 |
 | 學完 X 還能學什麼 | course_smart | 學習路徑探索 |
+| 隨機推薦一門課 | course_random | 無指定課名或需求 |
+| 有沒有遠距的通識 | course_remote | 遠距/線上上課 |
 | 王老師的電話 | contact_search | 聯絡查詢 |
 | 王小明（無上下文）| direct_reply | 身份不明，需澄清 |
 | 112學年微積分 | course_historical | 指定年份+課程 |
 | 112學年學生 | id_year | 指定年份+學生 |
+| who teaches calculus | course_search | 英文具體課名 |
+| phone number of the library | contact_search | 英文聯絡查詢 |
 
 ## 對話上下文
 使用者輸入永遠包在 <query>...</query> 標籤中。
@@ -81,6 +86,14 @@ const IntentParserSystemPrompt = `你是 NTPU 小工具的意圖分類助手。
 呼叫：course_historical(year="113", keyword="線性代數")
 原因：含年份+課程，西元2024→民國113
 
+輸入：<query>who teaches calculus</query>
+呼叫：course_search(keyword="calculus")
+原因：英文具體課名，保留英文（課程收錄英文名稱）
+
+輸入：<query>Wang</query>
+呼叫：direct_reply(message="Are you looking for:\n1️⃣ Courses taught by Wang?\n2️⃣ Wang's contact information?\nPlease include the name in Chinese if you know it.")
+原因：英文輸入需以英文澄清
+
 輸入：<query>有什麼學程可以修</query>
 呼叫：program_list()
 原因：詢問所有學程列表
@@ -90,8 +103,9 @@ const IntentParserSystemPrompt = `你是 NTPU 小工具的意圖分類助手。
 呼叫：course_search(keyword="王小明")
 原因：前文為課程搜尋，推測王小明是教師名`
 
-// QueryExpansionPrompt creates the prompt for query expansion.
-// This prompt is shared between Gemini and OpenAI-compatible expanders.
+// queryExpansionInstructions are the built-in task instructions of the query
+// expansion prompt, shared between Gemini and OpenAI-compatible expanders.
+// ExpansionPrompt.Render appends the few-shot examples and the user query.
 //
 // Uses a high-precision Think-then-Expand pattern:
 // 1. Analysis phase: infer the user's actual intent and search facets
@@ -115,8 +129,7 @@ const IntentParserSystemPrompt = `你是 NTPU 小工具的意圖分類助手。
 // The caller ensures original query signal is preserved as lexical terms rather than
 // a full natural-language sentence. This maintains query signal strength while
 // avoiding conversational filler noise in BM25.
-func QueryExpansionPrompt(query string) string {
-	return `你是課程搜尋意圖分析與關鍵詞擴展器。
+const queryExpansionInstructions = `你是課程搜尋意圖分析與關鍵詞擴展器。
 
 ## 任務
 1. 先分析使用者的真正學習目標
@@ -159,53 +172,66 @@ func QueryExpansionPrompt(query string) string {
 1. 關鍵詞行只能輸出搜尋詞，不可重複整句使用者原文；若原文有重要片段，請拆成詞保留
 2. 不可輸出完整自然語言句子、解釋、編號、項目符號、JSON
 3. 若原查詢本身很口語，請提煉成詞，不要照抄原句
-4. 可以有少量創造性補充，但每個新增詞都必須能為課程檢索提供明確價值
-
-## 範例
-
-輸入：統計
-分析：使用者想學統計學相關知識
-關鍵詞：統計 statistics 統計學 機率 probability 迴歸分析 regression 假設檢定 hypothesis testing 推論統計
-
-輸入：Python 入門
-分析：使用者想學 Python 程式語言基礎
-關鍵詞：Python 程式設計 programming 程式語言 fundamentals 變數 variable 函式 function 迴圈 loop
-
-輸入：我想學投資理財
-分析：使用者想學投資與財務管理
-關鍵詞：投資 investment 理財 財務管理 financial management 股票 stock 基金 fund 風險管理 risk management
-
-輸入：學完微積分可以學什麼
-分析：已修完微積分，想找進階銜接的數學或應用課程
-關鍵詞：工程數學 微分方程 differential equations 線性代數 linear algebra 數值分析 numerical analysis 最佳化 optimization
-
-輸入：經濟系想學程式
-分析：經濟系學生想學程式，應找適合非資工背景的程式與數據分析課程
-關鍵詞：程式設計 programming Python R 資料分析 data analysis 計量經濟 econometrics 數據處理 data processing
-
-輸入：我是資工系的，但我對金融領域有興趣，可以修什麼課
-分析：資工背景想跨入金融，應找金融相關且偏重量化分析與程式應用的課程
-關鍵詞：金融科技 FinTech 量化分析 quantitative analysis 財務工程 financial engineering 投資學 investment 金融 finance 程式交易 algorithmic trading
-
-輸入：想了解人的心理和行為
-分析：對人類心理與行為科學有興趣
-關鍵詞：心理學 psychology 認知心理 cognitive psychology 行為科學 behavioral science 社會心理 social psychology
-
-輸入：我是中文系的，最近想學一些數據分析的技能，聽說做文本分析很有趣
-分析：中文系學生想學數據分析，特別是文本分析方向，找數位人文與 NLP 相關課程
-關鍵詞：文本分析 text analysis 自然語言處理 NLP Python 程式設計 programming 數位人文 digital humanities 文本探勘 text mining
-
-輸入：對設計有興趣但沒基礎
-分析：想學設計但無基礎，需要入門級設計課程
-關鍵詞：設計 design 平面設計 graphic design 視覺設計 visual design 設計基礎 色彩學 color theory 排版 typography
-
-輸入：我想找資安方面的進階課，之前學過網路概論跟作業系統
-分析：有網路和作業系統基礎的學生想深入資訊安全領域
-關鍵詞：資訊安全 information security 網路安全 network security 密碼學 cryptography 滲透測試 penetration testing 系統安全 system security
+4. 可以有少量創造性補充，但每個新增詞都必須能為課程檢索提供明確價值`
+
+// queryExpansionExamples are the built-in few-shot examples of the query
+// expansion prompt; a prompt file variant may replace them.
+var queryExpansionExamples = []ExpansionExample{
+	{
+		Input:    "統計",
+		Analysis: "使用者想學統計學相關知識",
+		Keywords: "統計 statistics 統計學 機率 probability 迴歸分析 regression 假設檢定 hypothesis testing 推論統計",
+	},
+	{
+		Input:    "Python 入門",
+		Analysis: "使用者想學 Python 程式語言基礎",
+		Keywords: "Python 程式設計 programming 程式語言 fundamentals 變數 variable 函式 function 迴圈 loop",
+	},
+	{
+		Input:    "我想學投資理財",
+		Analysis: "使用者想學投資與財務管理",
+		Keywords: "投資 investment 理財 財務管理 financial management 股票 stock 基金 fund 風險管理 risk management",
+	},
+	{
+		Input:    "學完微積分可以學什麼",
+		Analysis: "已修完微積分，想找進階銜接的數學或應用課程",
+		Keywords: "工程數學 微分方程 differential equations 線性代數 linear algebra 數值分析 numerical analysis 最佳化 optimization",
+	},
+	{
+		Input:    "經濟系想學程式",
+		Analysis: "經濟系學生想學程式，應找適合非資工背景的程式與數據分析課程",
+		Keywords: "程式設計 programming Python R 資料分析 data analysis 計量經濟 econometrics 數據處理 data processing",
+	},
+	{
+		Input:    "我是資工系的，但我對金融領域有興趣，可以修什麼課",
+		Analysis: "資工背景想跨入金融，應找金融相關且偏重量化分析與程式應用的課程",
+		Keywords: "金融科技 FinTech 量化分析 quantitative analysis 財務工程 financial engineering 投資學 investment 金融 finance 程式交易 algorithmic trading",
+	},
+	{
+		Input:    "想了解人的心理和行為",
+		Analysis: "對人類心理與行為科學有興趣",
+		Keywords: "心理學 psychology 認知心理 cognitive psychology 行為科學 behavioral science 社會心理 social psychology",
+	},
+	{
+		Input:    "我是中文系的，最近想學一些數據分析的技能，聽說做文本分析很有趣",
+		Analysis: "中文系學生想學數據分析，特別是文本分析方向，找數位人文與 NLP 相關課程",
+		Keywords: "文本分析 text analysis 自然語言處理 NLP Python 程式設計 programming 數位人文 digital humanities 文本探勘 text mining",
+	},
+	{
+		Input:    "對設計有興趣但沒基礎",
+		Analysis: "想學設計但無基礎，需要入門級設計課程",
+		Keywords: "設計 design 平面設計 graphic design 視覺設計 visual design 設計基礎 色彩學 color theory 排版 typography",
+	},
+	{
+		Input:    "我想找資安方面的進階課，之前學過網路概論跟作業系統",
+		Analysis: "有網路和作業系統基礎的學生想深入資訊安全領域",
+		Keywords: "資訊安全 information security 網路安全 network security 密碼學 cryptography 滲透測試 penetration testing 系統安全 system security",
+	},
+}
 
-## 使用者查詢
-` + query + `
-`
+// QueryExpansionPrompt creates the built-in prompt for query expansion.
+func QueryExpansionPrompt(query string) string {
+	return defaultExpansionPrompt.Render(query)
 }
 
 // stripThinkingBlocks removes <think>...</think> reasoning blocks from LLM output.
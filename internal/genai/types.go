@@ -15,6 +15,8 @@ package genai
 import (
 	"context"
 	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/faults"
 )
 
 // Provider represents an LLM provider.
@@ -161,6 +163,14 @@ type LLMConfig struct {
 
 	// RetryConfig for retry behavior
 	RetryConfig RetryConfig
+
+	// Faults fails model calls with its llm_error fault, for resilience
+	// testing (default: nil)
+	Faults *faults.Injector
+
+	// Guard checks parse results and expansions before they reach users
+	// (default: nil = unchecked)
+	Guard *OutputGuard
 }
 
 // Default model configurations.
@@ -0,0 +1,210 @@
+// Package imageproxy serves the external images shown in templates and quick
+// replies (college photos on walkinto.in, the school logo, repository assets)
+// from the bot's own domain. LINE fetches these images when a message is
+// displayed, so a slow or missing upstream used to break the template; the
+// proxy caches each image, keeps serving the last good copy while the
+// upstream fails, and falls back to a placeholder for images never fetched.
+package imageproxy
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
+	domerrors "github.com/garyellow/ntpu-linebot-go/internal/errors"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/scraper"
+	"golang.org/x/sync/singleflight"
+)
+
+// Proxy limits. LINE accepts template thumbnails up to 10 MB, but the
+// proxied images are photos and logos well below MaxImageBytes.
+const (
+	MaxImageBytes = 5 << 20
+	MaxEntries    = 64
+)
+
+// BaseURLs returns the upstreams the proxy serves in production, for
+// NewProxy and the scraper client's per-host rate limits.
+func BaseURLs() map[string][]string {
+	return map[string][]string{
+		"walkinto": {"https://walkinto.in"},
+		"ntpu":     {"https://new.ntpu.edu.tw"},
+		"github":   {"https://raw.githubusercontent.com"},
+	}
+}
+
+// Hosts returns the host names of BaseURLs, for lineutil.UseImageProxy.
+func Hosts() []string {
+	return slices.Sorted(maps.Keys(upstreams(BaseURLs())))
+}
+
+// upstreams maps the host of each base URL to its scheme.
+func upstreams(baseURLs map[string][]string) map[string]string {
+	hosts := make(map[string]string)
+	for _, urls := range baseURLs {
+		for _, raw := range urls {
+			if u, err := url.Parse(raw); err == nil {
+				hosts[u.Hostname()] = u.Scheme
+			}
+		}
+	}
+	return hosts
+}
+
+// Image is a proxied image.
+type Image struct {
+	Data        []byte
+	ContentType string // Sniffed from Data, e.g., image/jpeg
+	Fallback    bool   // The placeholder, served when the upstream never answered
+}
+
+// Proxy fetches and caches upstream images. It is safe for concurrent use.
+type Proxy struct {
+	client      *scraper.Client
+	logger      *logger.Logger
+	hosts       map[string]string // Proxied host -> scheme
+	placeholder Image
+	group       singleflight.Group
+
+	mu      sync.Mutex
+	order   *list.List // front = most recently used
+	entries map[string]*list.Element
+}
+
+type cacheEntry struct {
+	src     string
+	img     Image
+	expires time.Time // When the upstream is tried again
+}
+
+// NewProxy creates a proxy for the images on baseURLs (usually BaseURLs),
+// fetching through client, which should be created with the same base URLs
+// and few retries: a template waits on the proxy's answer.
+func NewProxy(client *scraper.Client, baseURLs map[string][]string, log *logger.Logger) *Proxy {
+	return &Proxy{
+		client:      client,
+		logger:      log,
+		hosts:       upstreams(baseURLs),
+		placeholder: placeholder(),
+		order:       list.New(),
+		entries:     make(map[string]*list.Element),
+	}
+}
+
+// Get returns the image at src: the cached copy while it is fresh, else a
+// new fetch. When the fetch fails, the last good copy is served, or the
+// placeholder if there is none, and the upstream is left alone for
+// config.ImageProxyRetryAfter. A src outside the proxied base URLs is
+// domerrors.ErrInvalidInput.
+func (p *Proxy) Get(ctx context.Context, src string) (Image, error) {
+	u, err := url.Parse(src)
+	if err != nil {
+		return Image{}, fmt.Errorf("%w: invalid image URL", domerrors.ErrInvalidInput)
+	}
+	if scheme, ok := p.hosts[u.Hostname()]; !ok || u.Scheme != scheme {
+		return Image{}, fmt.Errorf("%w: image %q is not on a proxied host", domerrors.ErrInvalidInput, src)
+	}
+
+	if e, ok := p.lookup(src); ok && time.Now().Before(e.expires) {
+		return e.img, nil
+	}
+
+	// Concurrent requests for one image share a fetch; it outlives a
+	// cancelled caller so the others still get the image.
+	v, _, _ := p.group.Do(src, func() (any, error) {
+		fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), config.ImageProxyRequestTimeout)
+		defer cancel()
+		return p.refresh(fetchCtx, src), nil
+	})
+	return v.(Image), nil
+}
+
+// refresh fetches src and caches the result, or the fallback for a failure.
+func (p *Proxy) refresh(ctx context.Context, src string) Image {
+	img, err := p.fetch(ctx, src)
+	if err == nil {
+		p.store(src, img, config.ImageProxyTTL)
+		return img
+	}
+
+	stale, ok := p.lookup(src)
+	p.logger.WithError(err).
+		WithField("url", src).
+		WithField("stale", ok && !stale.img.Fallback).
+		WarnContext(ctx, "Image proxy fetch failed")
+	img = p.placeholder
+	if ok && !stale.img.Fallback {
+		img = stale.img
+	}
+	p.store(src, img, config.ImageProxyRetryAfter)
+	return img
+}
+
+// fetch downloads src and checks that it is an image; upstreams answer
+// missing files with HTML pages.
+func (p *Proxy) fetch(ctx context.Context, src string) (Image, error) {
+	data, err := p.client.GetBytes(ctx, src, MaxImageBytes)
+	if err != nil {
+		return Image{}, err
+	}
+	contentType := http.DetectContentType(data)
+	if !strings.HasPrefix(contentType, "image/") {
+		return Image{}, fmt.Errorf("upstream returned %s, not an image", contentType)
+	}
+	return Image{Data: data, ContentType: contentType}, nil
+}
+
+// lookup returns the cache entry of src and marks it as recently used.
+func (p *Proxy) lookup(src string) (cacheEntry, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	el, ok := p.entries[src]
+	if !ok {
+		return cacheEntry{}, false
+	}
+	p.order.MoveToFront(el)
+	return *el.Value.(*cacheEntry), true
+}
+
+// store caches img for src until ttl passes, evicting the least recently
+// used entry when full.
+func (p *Proxy) store(src string, img Image, ttl time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	entry := &cacheEntry{src: src, img: img, expires: time.Now().Add(ttl)}
+	if el, ok := p.entries[src]; ok {
+		el.Value = entry
+		p.order.MoveToFront(el)
+		return
+	}
+	p.entries[src] = p.order.PushFront(entry)
+	if p.order.Len() > MaxEntries {
+		oldest := p.order.Back()
+		p.order.Remove(oldest)
+		delete(p.entries, oldest.Value.(*cacheEntry).src)
+	}
+}
+
+// placeholder renders the fallback image: a plain light gray card in the
+// default template thumbnail ratio (1.51:1).
+func placeholder() Image {
+	img := image.NewRGBA(image.Rect(0, 0, 302, 200))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.RGBA{R: 0xEE, G: 0xEE, B: 0xEE, A: 0xFF}), image.Point{}, draw.Src)
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, img) // Encoding an in-memory RGBA image does not fail
+	return Image{Data: buf.Bytes(), ContentType: "image/png", Fallback: true}
+}
@@ -0,0 +1,110 @@
+package imageproxy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	domerrors "github.com/garyellow/ntpu-linebot-go/internal/errors"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/scraper"
+)
+
+func newTestProxy(t *testing.T, handler http.HandlerFunc) (*Proxy, *httptest.Server) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	baseURLs := map[string][]string{"test": {server.URL}}
+	client := scraper.NewClient(5*time.Second, 0, baseURLs)
+	return NewProxy(client, baseURLs, logger.New("error")), server
+}
+
+func pngBytes(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestGet(t *testing.T) {
+	t.Parallel()
+	logo := pngBytes(t)
+	var hits atomic.Int32
+	p, server := newTestProxy(t, func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		switch r.URL.Path {
+		case "/logo.png":
+			_, _ = w.Write(logo)
+		case "/moved.png":
+			_, _ = w.Write([]byte("<html><body>Not here</body></html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	ctx := context.Background()
+
+	img, err := p.Get(ctx, server.URL+"/logo.png")
+	if err != nil || img.Fallback || img.ContentType != "image/png" || !bytes.Equal(img.Data, logo) {
+		t.Fatalf("Get(logo) = %+v, %v, want the upstream PNG", img, err)
+	}
+	if _, err := p.Get(ctx, server.URL+"/logo.png"); err != nil || hits.Load() != 1 {
+		t.Errorf("second Get(logo) made %d upstream requests, want 1 (cached)", hits.Load())
+	}
+
+	for _, path := range []string{"/missing.png", "/moved.png"} {
+		img, err := p.Get(ctx, server.URL+path)
+		if err != nil || !img.Fallback || img.ContentType != "image/png" {
+			t.Errorf("Get(%s) = %+v, %v, want the placeholder", path, img, err)
+		}
+	}
+
+	for _, src := range []string{"https://example.com/logo.png", "ftp://" + server.Listener.Addr().String() + "/logo.png", "%zz"} {
+		if _, err := p.Get(ctx, src); !errors.Is(err, domerrors.ErrInvalidInput) {
+			t.Errorf("Get(%q) error = %v, want ErrInvalidInput", src, err)
+		}
+	}
+}
+
+func TestGetServesStaleCopy(t *testing.T) {
+	t.Parallel()
+	logo := pngBytes(t)
+	var down atomic.Bool
+	p, server := newTestProxy(t, func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(logo)
+	})
+	ctx := context.Background()
+	src := server.URL + "/logo.png"
+
+	if _, err := p.Get(ctx, src); err != nil {
+		t.Fatal(err)
+	}
+	down.Store(true)
+	p.store(src, Image{Data: logo, ContentType: "image/png"}, -time.Second) // Expire the cached copy
+
+	img, err := p.Get(ctx, src)
+	if err != nil || img.Fallback || !bytes.Equal(img.Data, logo) {
+		t.Errorf("Get() with the upstream down = %+v, %v, want the last good copy", img, err)
+	}
+}
+
+func TestHosts(t *testing.T) {
+	t.Parallel()
+	want := []string{"new.ntpu.edu.tw", "raw.githubusercontent.com", "walkinto.in"}
+	if got := Hosts(); !slices.Equal(got, want) {
+		t.Errorf("Hosts() = %v, want %v", got, want)
+	}
+}
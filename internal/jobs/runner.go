@@ -0,0 +1,174 @@
+// Package jobs runs slow, user-confirmed work in the background and delivers
+// the result by push message. The webhook replies right away ("已開始…"), so
+// jobs such as the deep course search are not bound by the reply token or the
+// 60s webhook deadline.
+//
+// Each job has a key (usually the user ID); a key can only have one job
+// running at a time, and the runner caps how many jobs run concurrently.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/metrics"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+// poolName labels the runner's goroutines in pool metrics.
+const poolName = "jobs"
+
+// DefaultConcurrency is the number of jobs that may run at once. Jobs are
+// scraper-heavy and the scraper is rate limited, so more would only queue.
+const DefaultConcurrency = 2
+
+// pushTimeout bounds delivering a finished job's messages.
+const pushTimeout = 30 * time.Second
+
+// Sentinel errors returned by Submit.
+var (
+	// ErrBusy means a job with the same key is still running.
+	ErrBusy = errors.New("job already running for key")
+	// ErrFull means the concurrency limit is reached.
+	ErrFull = errors.New("job runner is full")
+	// ErrStopped means the runner is shutting down.
+	ErrStopped = errors.New("job runner stopped")
+)
+
+// Pusher sends push messages (implemented by *lineapi.Client).
+type Pusher interface {
+	Push(ctx context.Context, req *messaging_api.PushMessageRequest) error
+}
+
+// Job is a unit of background work whose messages are pushed to To.
+type Job struct {
+	Name    string        // For logs (e.g., "course_deep_search")
+	Key     string        // At most one running job per key
+	To      string        // Push target: a user ID
+	Timeout time.Duration // Upper bound for Run
+	// Run does the work and returns up to 5 messages to push.
+	// It should return promptly once ctx is canceled.
+	Run func(ctx context.Context) []messaging_api.MessageInterface
+}
+
+// Runner executes jobs in background goroutines. Safe for concurrent use.
+type Runner struct {
+	pusher  Pusher
+	metrics *metrics.Metrics
+	logger  *logger.Logger
+	limit   int
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	running map[string]bool
+	stopped bool
+}
+
+// NewRunner creates a runner that pushes results with pusher.
+// A concurrency below 1 uses DefaultConcurrency.
+func NewRunner(pusher Pusher, metrics *metrics.Metrics, logger *logger.Logger, concurrency int) *Runner {
+	if concurrency < 1 {
+		concurrency = DefaultConcurrency
+	}
+	metrics.SetPoolCapacity(poolName, concurrency)
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Runner{
+		pusher:  pusher,
+		metrics: metrics,
+		logger:  logger,
+		limit:   concurrency,
+		ctx:     ctx,
+		cancel:  cancel,
+		running: make(map[string]bool),
+	}
+}
+
+// Submit starts job in the background. It returns ErrBusy, ErrFull, or
+// ErrStopped without running the job.
+func (r *Runner) Submit(job Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch {
+	case r.stopped:
+		return ErrStopped
+	case r.running[job.Key]:
+		return fmt.Errorf("%w: %s", ErrBusy, job.Name)
+	case len(r.running) >= r.limit:
+		return fmt.Errorf("%w: %s", ErrFull, job.Name)
+	}
+	r.running[job.Key] = true
+	r.metrics.PoolStarted(poolName)
+	r.wg.Go(func() { r.run(job) })
+	return nil
+}
+
+// Running reports whether a job with key is in progress.
+func (r *Runner) Running(key string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.running[key]
+}
+
+func (r *Runner) run(job Job) {
+	log := r.logger.WithField("job", job.Name)
+	start := time.Now()
+	defer func() {
+		if rec := recover(); rec != nil {
+			log.WithField("panic", rec).Error("Background job panicked")
+		}
+		r.mu.Lock()
+		delete(r.running, job.Key)
+		r.mu.Unlock()
+		r.metrics.PoolFinished(poolName)
+	}()
+
+	ctx, cancel := context.WithTimeout(r.ctx, job.Timeout)
+	msgs := job.Run(ctx)
+	cancel()
+	log = log.WithField("duration_ms", time.Since(start).Milliseconds())
+
+	if len(msgs) == 0 || r.ctx.Err() != nil {
+		log.Debug("Background job finished without messages")
+		return
+	}
+
+	pushCtx, pushCancel := context.WithTimeout(context.Background(), pushTimeout)
+	defer pushCancel()
+	if err := r.pusher.Push(pushCtx, &messaging_api.PushMessageRequest{
+		To:       job.To,
+		Messages: msgs,
+	}); err != nil {
+		log.WithError(err).Warn("Failed to push background job result")
+		return
+	}
+	log.Info("Background job result delivered")
+}
+
+// Shutdown cancels running jobs and waits for them to exit or ctx to end.
+// Results of canceled jobs are not pushed.
+func (r *Runner) Shutdown(ctx context.Context) error {
+	r.mu.Lock()
+	r.stopped = true
+	r.mu.Unlock()
+	r.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.wg.Wait()
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("wait for background jobs: %w", ctx.Err())
+	}
+}
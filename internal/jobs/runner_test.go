@@ -0,0 +1,91 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/metrics"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type fakePusher struct {
+	mu   sync.Mutex
+	sent []*messaging_api.PushMessageRequest
+}
+
+func (p *fakePusher) Push(_ context.Context, req *messaging_api.PushMessageRequest) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sent = append(p.sent, req)
+	return nil
+}
+
+func textJob(key string, release <-chan struct{}) Job {
+	return Job{
+		Name:    "test",
+		Key:     key,
+		To:      key,
+		Timeout: time.Minute,
+		Run: func(ctx context.Context) []messaging_api.MessageInterface {
+			select {
+			case <-release:
+			case <-ctx.Done():
+				return nil
+			}
+			return []messaging_api.MessageInterface{&messaging_api.TextMessageV2{Text: "done"}}
+		},
+	}
+}
+
+func TestRunner_Submit(t *testing.T) {
+	t.Parallel()
+	pusher := &fakePusher{}
+	r := NewRunner(pusher, metrics.New(prometheus.NewRegistry()), logger.New("error"), 2)
+	release := make(chan struct{})
+
+	if err := r.Submit(textJob("U1", release)); err != nil {
+		t.Fatalf("Submit(U1) error = %v", err)
+	}
+	if err := r.Submit(textJob("U1", release)); !errors.Is(err, ErrBusy) {
+		t.Errorf("second Submit(U1) error = %v, want ErrBusy", err)
+	}
+	if err := r.Submit(textJob("U2", release)); err != nil {
+		t.Fatalf("Submit(U2) error = %v", err)
+	}
+	if err := r.Submit(textJob("U3", release)); !errors.Is(err, ErrFull) {
+		t.Errorf("Submit(U3) error = %v, want ErrFull", err)
+	}
+
+	close(release)
+	r.wg.Wait()
+	if len(pusher.sent) != 2 {
+		t.Fatalf("pushed %d results, want 2", len(pusher.sent))
+	}
+	if r.Running("U1") {
+		t.Error("Running(U1) = true after the job finished")
+	}
+}
+
+func TestRunner_Shutdown(t *testing.T) {
+	t.Parallel()
+	pusher := &fakePusher{}
+	r := NewRunner(pusher, metrics.New(prometheus.NewRegistry()), logger.New("error"), 1)
+
+	if err := r.Submit(textJob("U1", make(chan struct{}))); err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	if err := r.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if len(pusher.sent) != 0 {
+		t.Errorf("pushed %d results for a canceled job, want 0", len(pusher.sent))
+	}
+	if err := r.Submit(textJob("U2", nil)); !errors.Is(err, ErrStopped) {
+		t.Errorf("Submit() after Shutdown error = %v, want ErrStopped", err)
+	}
+}
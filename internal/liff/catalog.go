@@ -0,0 +1,179 @@
+package liff
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	domerrors "github.com/garyellow/ntpu-linebot-go/internal/errors"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/course"
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+)
+
+// Catalog answers filter queries from the cached course lists of recent semesters.
+type Catalog struct {
+	db        *storage.DB
+	semesters *course.SemesterCache
+	courses   *course.SemesterCourseCache
+}
+
+// NewCatalog creates a catalog. semesters should be the cache shared with the
+// course module so the page offers the same semesters as chat queries.
+func NewCatalog(db *storage.DB, semesters *course.SemesterCache) *Catalog {
+	return &Catalog{
+		db:        db,
+		semesters: semesters,
+		courses:   course.NewSemesterCourseCache(0),
+	}
+}
+
+// SemesterOption is a selectable semester.
+type SemesterOption struct {
+	Value string `json:"value"` // "113-1"
+	Label string `json:"label"` // "113 學年度第 1 學期"
+}
+
+// Options lists the values the filter form offers.
+type Options struct {
+	Semesters   []SemesterOption `json:"semesters"` // newest first
+	Departments []string         `json:"departments"`
+	Levels      []Level          `json:"levels"`
+}
+
+// CourseItem is a search result row.
+type CourseItem struct {
+	UID       string   `json:"uid"`
+	Title     string   `json:"title"`
+	Teachers  []string `json:"teachers"`
+	Times     []string `json:"times"`
+	Locations []string `json:"locations"`
+	Level     string   `json:"level"`
+}
+
+// Result is a page of matching courses.
+type Result struct {
+	Semester   string       `json:"semester"`
+	Total      int          `json:"total"` // matches across all pages
+	Courses    []CourseItem `json:"courses"`
+	NextCursor string       `json:"next_cursor,omitempty"` // cursor of the next page; omitted on the last
+}
+
+// Options returns the semesters and the departments of the newest one.
+func (c *Catalog) Options(ctx context.Context) (Options, error) {
+	opts := Options{Semesters: c.semesterOptions(), Departments: []string{}, Levels: Levels}
+	if len(opts.Semesters) == 0 {
+		return opts, nil
+	}
+
+	year, term := parseSemester(opts.Semesters[0].Value)
+	majors, err := c.db.GetMajorsBySemester(ctx, year, term)
+	if err != nil {
+		return Options{}, fmt.Errorf("load departments: %w", err)
+	}
+	opts.Departments = departments(majors)
+	return opts, nil
+}
+
+// Search returns the page f.Page of the courses of the selected semester that
+// match f. An unknown semester or a malformed cursor returns an error wrapping
+// domerrors.ErrInvalidInput.
+func (c *Catalog) Search(ctx context.Context, f Filter) (Result, error) {
+	options := c.semesterOptions()
+	semester := f.Semester
+	if semester == "" && len(options) > 0 {
+		semester = options[0].Value
+	}
+	if !slices.ContainsFunc(options, func(o SemesterOption) bool { return o.Value == semester }) {
+		return Result{}, fmt.Errorf("%w: semester %q", domerrors.ErrInvalidInput, f.Semester)
+	}
+	year, term := parseSemester(semester)
+
+	var candidates []storage.Course
+	var err error
+	if f.Department != "" {
+		candidates, err = c.departmentCourses(ctx, year, term, f.Department)
+	} else {
+		candidates, err = c.courses.Get(ctx, c.db, year, term)
+	}
+	if err != nil {
+		return Result{}, fmt.Errorf("load courses: %w", err)
+	}
+
+	matched := slices.DeleteFunc(candidates, func(c storage.Course) bool { return !f.Match(&c) })
+	page, err := storage.PageOf(matched, f.Page)
+	if err != nil {
+		return Result{}, err
+	}
+
+	result := Result{
+		Semester:   semester,
+		Total:      page.TotalCount,
+		Courses:    make([]CourseItem, len(page.Items)),
+		NextCursor: page.NextCursor,
+	}
+	for i := range page.Items {
+		result.Courses[i] = newCourseItem(&page.Items[i])
+	}
+	return result, nil
+}
+
+func (c *Catalog) semesterOptions() []SemesterOption {
+	if !c.semesters.HasData() {
+		return nil
+	}
+	years, terms := c.semesters.GetAllSemesters()
+	opts := make([]SemesterOption, len(years))
+	for i := range years {
+		opts[i] = SemesterOption{
+			Value: fmt.Sprintf("%d-%d", years[i], terms[i]),
+			Label: fmt.Sprintf("%d 學年度第 %d 學期", years[i], terms[i]),
+		}
+	}
+	return opts
+}
+
+func newCourseItem(c *storage.Course) CourseItem {
+	return CourseItem{
+		UID:       c.UID,
+		Title:     c.Title,
+		Teachers:  c.Teachers,
+		Times:     c.Times,
+		Locations: c.Locations,
+		Level:     LevelCode(c.No),
+	}
+}
+
+// departments strips grade suffixes from 應修系級 values ("資工系1" → "資工系")
+// and returns the sorted, distinct results.
+func departments(majors []string) []string {
+	depts := make([]string, 0, len(majors))
+	for _, m := range majors {
+		if d := strings.TrimRightFunc(m, unicode.IsDigit); d != "" {
+			depts = append(depts, d)
+		}
+	}
+	slices.Sort(depts)
+	return slices.Compact(depts)
+}
+
+// departmentCourses returns the courses of a semester required by dept, a
+// value of Options.Departments. The 應修系級 must be dept plus a grade:
+// a prefix match would put 資工系碩1 courses under 資工系.
+func (c *Catalog) departmentCourses(ctx context.Context, year, term int, dept string) ([]storage.Course, error) {
+	all, err := c.db.GetMajorsBySemester(ctx, year, term)
+	if err != nil {
+		return nil, err
+	}
+	majors := slices.DeleteFunc(all, func(m string) bool {
+		return strings.TrimRightFunc(m, unicode.IsDigit) != dept
+	})
+	return c.db.GetCoursesByMajors(ctx, year, term, majors)
+}
+
+// parseSemester splits a SemesterOption value ("113-1") into year and term.
+func parseSemester(s string) (year, term int) {
+	_, _ = fmt.Sscanf(s, "%d-%d", &year, &term)
+	return year, term
+}
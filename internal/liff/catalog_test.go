@@ -0,0 +1,140 @@
+package liff
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	domerrors "github.com/garyellow/ntpu-linebot-go/internal/errors"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/course"
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+)
+
+func setupTestCatalog(t *testing.T) *Catalog {
+	t.Helper()
+
+	db, err := storage.New(context.Background(), filepath.Join(t.TempDir(), "test.db"), 168*time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close(context.Background()) })
+
+	courses := []*storage.Course{
+		{UID: "1131U0001", Year: 113, Term: 1, No: "U0001", Title: "程式設計", Teachers: []string{"王小明"},
+			Times: []string{"每週一3~4"}, RawProgramReqs: []storage.RawProgramReq{{Name: "資工系1", CourseType: "必"}}},
+		{UID: "1131U0002", Year: 113, Term: 1, No: "U0002", Title: "資料結構", Teachers: []string{"李大華"},
+			Times: []string{"每週三3~4"}, RawProgramReqs: []storage.RawProgramReq{{Name: "資工系2", CourseType: "必"}}},
+		{UID: "1131M0001", Year: 113, Term: 1, No: "M0001", Title: "機器學習", Teachers: []string{"王小明"},
+			Times: []string{"每週一6~8"}, RawProgramReqs: []storage.RawProgramReq{{Name: "資工系碩1", CourseType: "選"}}},
+		{UID: "1122U0001", Year: 112, Term: 2, No: "U0001", Title: "計算機概論",
+			Times: []string{"每週五1~2"}, RawProgramReqs: []storage.RawProgramReq{{Name: "經濟系1", CourseType: "必"}}},
+	}
+	if err := db.SaveCoursesBatch(context.Background(), courses); err != nil {
+		t.Fatalf("SaveCoursesBatch failed: %v", err)
+	}
+	if err := db.SaveCourseMajorsBatch(context.Background(), courses); err != nil {
+		t.Fatalf("SaveCourseMajorsBatch failed: %v", err)
+	}
+
+	semesters := course.NewSemesterCache()
+	semesters.Update([]course.Semester{{Year: 113, Term: 1}, {Year: 112, Term: 2}})
+	return NewCatalog(db, semesters)
+}
+
+func TestCatalogOptions(t *testing.T) {
+	t.Parallel()
+	c := setupTestCatalog(t)
+
+	opts, err := c.Options(context.Background())
+	if err != nil {
+		t.Fatalf("Options() error: %v", err)
+	}
+	if len(opts.Semesters) != 2 || opts.Semesters[0].Value != "113-1" {
+		t.Errorf("Semesters = %+v, want 113-1 first", opts.Semesters)
+	}
+	// Departments come from the newest semester only
+	if want := []string{"資工系", "資工系碩"}; !slices.Equal(opts.Departments, want) {
+		t.Errorf("Departments = %v, want %v", opts.Departments, want)
+	}
+	if len(opts.Levels) != len(Levels) {
+		t.Errorf("Levels = %v", opts.Levels)
+	}
+}
+
+func TestCatalogSearch(t *testing.T) {
+	t.Parallel()
+	c := setupTestCatalog(t)
+
+	tests := []struct {
+		name     string
+		filter   Filter
+		wantUIDs []string
+	}{
+		{"newest semester by default", Filter{}, []string{"1131M0001", "1131U0001", "1131U0002"}},
+		{"older semester", Filter{Semester: "112-2"}, []string{"1122U0001"}},
+		{"department excludes its graduate programs", Filter{Department: "資工系"}, []string{"1131U0001", "1131U0002"}},
+		{"weekday and teacher", Filter{Weekdays: []time.Weekday{time.Monday}, Keyword: "王小明"}, []string{"1131M0001", "1131U0001"}},
+		{"level", Filter{Levels: []string{"M"}}, []string{"1131M0001"}},
+		{"no match", Filter{Keyword: "微積分"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result, err := c.Search(context.Background(), tt.filter)
+			if err != nil {
+				t.Fatalf("Search() error: %v", err)
+			}
+			var uids []string
+			for _, item := range result.Courses {
+				uids = append(uids, item.UID)
+			}
+			slices.Sort(uids)
+			if !slices.Equal(uids, tt.wantUIDs) || result.Total != len(tt.wantUIDs) {
+				t.Errorf("Search() = %v (total %d), want %v", uids, result.Total, tt.wantUIDs)
+			}
+		})
+	}
+
+	if _, err := c.Search(context.Background(), Filter{Semester: "99-1"}); !errors.Is(err, domerrors.ErrInvalidInput) {
+		t.Errorf("Search(unknown semester) error = %v, want ErrInvalidInput", err)
+	}
+}
+
+func TestCatalogSearch_Pages(t *testing.T) {
+	t.Parallel()
+	c := setupTestCatalog(t)
+	ctx := context.Background()
+
+	var uids []string
+	filter := Filter{Page: storage.Page{Limit: 2}}
+	for pages := 1; ; pages++ {
+		result, err := c.Search(ctx, filter)
+		if err != nil {
+			t.Fatalf("Search() error: %v", err)
+		}
+		if result.Total != 3 {
+			t.Errorf("page %d Total = %d, want 3 across all pages", pages, result.Total)
+		}
+		for _, item := range result.Courses {
+			uids = append(uids, item.UID)
+		}
+		if result.NextCursor == "" {
+			break
+		}
+		if pages > 2 {
+			t.Fatal("cursor never ends")
+		}
+		filter.Page.Cursor = result.NextCursor
+	}
+	slices.Sort(uids)
+	if want := []string{"1131M0001", "1131U0001", "1131U0002"}; !slices.Equal(uids, want) {
+		t.Errorf("paged UIDs = %v, want %v", uids, want)
+	}
+
+	if _, err := c.Search(ctx, Filter{Page: storage.Page{Cursor: "not-a-cursor"}}); !errors.Is(err, domerrors.ErrInvalidInput) {
+		t.Errorf("Search(bad cursor) error = %v, want ErrInvalidInput", err)
+	}
+}
@@ -0,0 +1,142 @@
+package liff
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	domerrors "github.com/garyellow/ntpu-linebot-go/internal/errors"
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+)
+
+// maxFilterTextLength bounds the department and keyword facets (in runes).
+const maxFilterTextLength = 50
+
+// Level is a course level, identified by the letter that starts a course number.
+type Level struct {
+	Code string `json:"code"` // U, M, N, or P
+	Name string `json:"name"`
+}
+
+// Levels lists the course levels in display order.
+var Levels = []Level{
+	{Code: "U", Name: "學士班"},
+	{Code: "M", Name: "碩士班"},
+	{Code: "N", Name: "碩士在職專班"},
+	{Code: "P", Name: "博士班"},
+}
+
+// Filter is a multi-facet course query. Zero-valued facets match everything.
+type Filter struct {
+	Semester   string         // "113-1"; empty = newest cached semester
+	Department string         // 應修系級 prefix (e.g., 資工系 matches 資工系1–4)
+	Weekdays   []time.Weekday // at least one meeting on one of these days
+	Levels     []string       // level codes (see Levels)
+	Keyword    string         // case-insensitive substring of title or a teacher
+	Page       storage.Page   // limit and cursor; zero = first storage.DefaultSearchLimit matches
+}
+
+// ParseFilter reads a Filter from query parameters:
+//
+//	semester=113-1&department=資工系&weekday=1,3&level=U,M&q=程式&limit=20&cursor=…
+//
+// Weekdays are 1 (Monday) through 7 (Sunday). limit is 1 through
+// storage.MaxSearchLimit, and cursor is the next_cursor of the previous page.
+// Invalid values return an error wrapping domerrors.ErrInvalidInput.
+func ParseFilter(q url.Values) (Filter, error) {
+	f := Filter{
+		Semester:   strings.TrimSpace(q.Get("semester")),
+		Department: strings.TrimSpace(q.Get("department")),
+		Keyword:    strings.TrimSpace(q.Get("q")),
+		Page:       storage.Page{Cursor: q.Get("cursor")},
+	}
+	if utf8.RuneCountInString(f.Department) > maxFilterTextLength || utf8.RuneCountInString(f.Keyword) > maxFilterTextLength {
+		return Filter{}, fmt.Errorf("%w: filter text longer than %d characters", domerrors.ErrInvalidInput, maxFilterTextLength)
+	}
+	if len(f.Page.Cursor) > maxFilterTextLength {
+		return Filter{}, fmt.Errorf("%w: cursor longer than %d characters", domerrors.ErrInvalidInput, maxFilterTextLength)
+	}
+	if raw := q.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > storage.MaxSearchLimit {
+			return Filter{}, fmt.Errorf("%w: limit %q", domerrors.ErrInvalidInput, raw)
+		}
+		f.Page.Limit = n
+	}
+
+	for _, raw := range splitList(q.Get("weekday")) {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > 7 {
+			return Filter{}, fmt.Errorf("%w: weekday %q", domerrors.ErrInvalidInput, raw)
+		}
+		f.Weekdays = append(f.Weekdays, time.Weekday(n%7)) // 7 = Sunday = 0
+	}
+
+	for _, raw := range splitList(q.Get("level")) {
+		code := strings.ToUpper(raw)
+		if !slices.ContainsFunc(Levels, func(l Level) bool { return l.Code == code }) {
+			return Filter{}, fmt.Errorf("%w: level %q", domerrors.ErrInvalidInput, raw)
+		}
+		f.Levels = append(f.Levels, code)
+	}
+
+	return f, nil
+}
+
+// Match reports whether a course satisfies every facet except Semester and
+// Department, which select the candidate list (see Catalog.Search).
+func (f Filter) Match(course *storage.Course) bool {
+	if len(f.Levels) > 0 && !slices.Contains(f.Levels, LevelCode(course.No)) {
+		return false
+	}
+	if len(f.Weekdays) > 0 && !f.meetsOnWeekday(course) {
+		return false
+	}
+	if f.Keyword != "" && !matchesKeyword(course, f.Keyword) {
+		return false
+	}
+	return true
+}
+
+func (f Filter) meetsOnWeekday(course *storage.Course) bool {
+	for _, t := range course.Times {
+		if m, ok := lineutil.ParseCourseMeeting(t); ok && slices.Contains(f.Weekdays, m.Weekday) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesKeyword(course *storage.Course, keyword string) bool {
+	keyword = strings.ToLower(keyword)
+	if strings.Contains(strings.ToLower(course.Title), keyword) {
+		return true
+	}
+	return slices.ContainsFunc(course.Teachers, func(t string) bool {
+		return strings.Contains(strings.ToLower(t), keyword)
+	})
+}
+
+// LevelCode returns the level letter of a course number (e.g., "U" for U0001).
+func LevelCode(no string) string {
+	if no == "" {
+		return ""
+	}
+	return strings.ToUpper(no[:1])
+}
+
+// splitList splits a comma-separated parameter, dropping empty items.
+func splitList(raw string) []string {
+	var items []string
+	for item := range strings.SplitSeq(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,122 @@
+package liff
+
+import (
+	"errors"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	domerrors "github.com/garyellow/ntpu-linebot-go/internal/errors"
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+)
+
+func TestParseFilter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		query   string
+		want    Filter
+		wantErr bool
+	}{
+		{"empty", "", Filter{}, false},
+		{
+			"all facets",
+			"semester=113-1&department=+資工系+&weekday=1,7&level=u,M&q=程式",
+			Filter{
+				Semester:   "113-1",
+				Department: "資工系",
+				Weekdays:   []time.Weekday{time.Monday, time.Sunday},
+				Levels:     []string{"U", "M"},
+				Keyword:    "程式",
+			},
+			false,
+		},
+		{"empty list items", "weekday=,3,&level=", Filter{Weekdays: []time.Weekday{time.Wednesday}}, false},
+		{"weekday out of range", "weekday=8", Filter{}, true},
+		{"weekday not a number", "weekday=一", Filter{}, true},
+		{"unknown level", "level=X", Filter{}, true},
+		{"keyword too long", "q=" + url.QueryEscape(strings.Repeat("課", maxFilterTextLength+1)), Filter{}, true},
+		{"page", "limit=20&cursor=abc", Filter{Page: storage.Page{Limit: 20, Cursor: "abc"}}, false},
+		{"limit zero", "limit=0", Filter{}, true},
+		{"limit above max", "limit=" + strconv.Itoa(storage.MaxSearchLimit+1), Filter{}, true},
+		{"cursor too long", "cursor=" + strings.Repeat("a", maxFilterTextLength+1), Filter{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("ParseQuery: %v", err)
+			}
+			got, err := ParseFilter(values)
+			if tt.wantErr {
+				if !errors.Is(err, domerrors.ErrInvalidInput) {
+					t.Errorf("ParseFilter(%q) error = %v, want ErrInvalidInput", tt.query, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseFilter(%q) error: %v", tt.query, err)
+			}
+			if got.Semester != tt.want.Semester || got.Department != tt.want.Department || got.Keyword != tt.want.Keyword ||
+				!slices.Equal(got.Weekdays, tt.want.Weekdays) || !slices.Equal(got.Levels, tt.want.Levels) || got.Page != tt.want.Page {
+				t.Errorf("ParseFilter(%q) = %+v, want %+v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	t.Parallel()
+
+	course := &storage.Course{
+		UID:      "1131U0001",
+		No:       "U0001",
+		Title:    "Programming Design",
+		Teachers: []string{"王小明"},
+		Times:    []string{"每週二3~4", "每週四5~6"},
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bool
+	}{
+		{"no facets", Filter{}, true},
+		{"matching weekday", Filter{Weekdays: []time.Weekday{time.Thursday}}, true},
+		{"other weekday", Filter{Weekdays: []time.Weekday{time.Monday, time.Friday}}, false},
+		{"matching level", Filter{Levels: []string{"M", "U"}}, true},
+		{"other level", Filter{Levels: []string{"P"}}, false},
+		{"title keyword is case-insensitive", Filter{Keyword: "programming"}, true},
+		{"teacher keyword", Filter{Keyword: "王"}, true},
+		{"keyword mismatch", Filter{Keyword: "微積分"}, false},
+		{"all facets must match", Filter{Weekdays: []time.Weekday{time.Tuesday}, Keyword: "微積分"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.filter.Match(course); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	unscheduled := &storage.Course{No: "U0002", Times: []string{"每週未維護"}}
+	if (Filter{Weekdays: []time.Weekday{time.Monday}}).Match(unscheduled) {
+		t.Error("course without parseable meetings should not match a weekday facet")
+	}
+}
+
+func TestDepartments(t *testing.T) {
+	t.Parallel()
+
+	got := departments([]string{"資工系1", "資工系2", "通訊系1", "資工系碩1", "12", "法律系"})
+	want := []string{"法律系", "資工系", "資工系碩", "通訊系"}
+	if !slices.Equal(got, want) {
+		t.Errorf("departments() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,60 @@
+// Package liff serves the course filter LIFF app: a page opened inside LINE
+// that filters the cached course lists by semester, department, weekday, level,
+// and keyword, then sends "課程 {UID}" back to the chat for the usual detail
+// bubble. It covers combinations that chat keywords cannot express.
+package liff
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"mime"
+	"path"
+)
+
+// ContentSecurityPolicy allows the page's own assets, the LIFF SDK, and the
+// LINE APIs the SDK calls; everything else stays blocked as on other routes.
+const ContentSecurityPolicy = "default-src 'none'; " +
+	"script-src 'self' https://static.line-scdn.net; " +
+	"style-src 'self'; " +
+	"img-src 'self' data: https:; " +
+	"connect-src 'self' https://*.line.me https://*.line-scdn.net; " +
+	"base-uri 'none'; form-action 'self'"
+
+//go:embed static
+var staticFS embed.FS
+
+// RenderPage returns the LIFF page HTML for the given LIFF app ID.
+// The page loads its script and styles from /liff/static and calls the JSON
+// API under /liff/api on the same origin. accountLink adds a button that
+// starts school account linking through POST /account/liff.
+func RenderPage(liffID string, accountLink bool) ([]byte, error) {
+	tmpl, err := template.ParseFS(staticFS, "static/index.html")
+	if err != nil {
+		return nil, fmt.Errorf("parse liff page: %w", err)
+	}
+	data := struct {
+		LIFFID      string
+		AccountLink bool
+	}{liffID, accountLink}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("render liff page: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Asset returns a static script or stylesheet and its content type.
+// ok is false for unknown names and for the page template itself.
+func Asset(name string) (data []byte, contentType string, ok bool) {
+	ext := path.Ext(name)
+	if ext != ".js" && ext != ".css" {
+		return nil, "", false
+	}
+	data, err := staticFS.ReadFile("static/" + path.Base(name))
+	if err != nil {
+		return nil, "", false
+	}
+	return data, mime.TypeByExtension(ext), true
+}
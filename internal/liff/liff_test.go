@@ -0,0 +1,58 @@
+package liff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderPage(t *testing.T) {
+	t.Parallel()
+
+	page, err := RenderPage(`1234567890-AbcdEfgh"><script>`, false)
+	if err != nil {
+		t.Fatalf("RenderPage() error: %v", err)
+	}
+	html := string(page)
+	if !strings.Contains(html, `data-liff-id="1234567890-AbcdEfgh&#34;&gt;&lt;script&gt;"`) {
+		t.Error("LIFF ID should be HTML-escaped into the body data attribute")
+	}
+	if !strings.Contains(html, `src="/liff/static/app.js"`) {
+		t.Error("page should load its script from /liff/static")
+	}
+	if strings.Contains(html, `id="account-link"`) {
+		t.Error("page offers account linking while it is disabled")
+	}
+
+	page, err = RenderPage("1234567890-AbcdEfgh", true)
+	if err != nil || !strings.Contains(string(page), `id="account-link"`) {
+		t.Errorf("RenderPage(accountLink) = %v, want the account link button", err)
+	}
+}
+
+func TestAsset(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		wantOK   bool
+		wantType string
+	}{
+		{"app.js", true, "text/javascript"},
+		{"app.css", true, "text/css"},
+		{"index.html", false, ""},
+		{"../liff.go", false, ""},
+		{"missing.js", false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			data, contentType, ok := Asset(tt.name)
+			if ok != tt.wantOK {
+				t.Fatalf("Asset(%q) ok = %v, want %v", tt.name, ok, tt.wantOK)
+			}
+			if ok && (len(data) == 0 || !strings.HasPrefix(contentType, tt.wantType)) {
+				t.Errorf("Asset(%q) = %d bytes, %q", tt.name, len(data), contentType)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+(function () {
+  const liffId = document.body.dataset.liffId;
+  const weekdayNames = ["一", "二", "三", "四", "五", "六", "日"];
+  const form = document.getElementById("filter");
+  const results = document.getElementById("results");
+
+  function el(tag, props, text) {
+    const node = document.createElement(tag);
+    Object.assign(node, props || {});
+    if (text !== undefined) node.textContent = text;
+    return node;
+  }
+
+  function chip(name, value, label) {
+    const wrapper = el("label");
+    wrapper.append(el("input", { type: "checkbox", name: name, value: value }), label);
+    return wrapper;
+  }
+
+  function showMessage(text, isError) {
+    results.replaceChildren(el("p", { className: isError ? "error" : "summary" }, text));
+  }
+
+  async function getJSON(url) {
+    const res = await fetch(url);
+    if (!res.ok) throw new Error((await res.json().catch(() => ({}))).error || res.statusText);
+    return res.json();
+  }
+
+  async function loadOptions() {
+    const opts = await getJSON("/liff/api/options");
+    const semester = document.getElementById("semester");
+    opts.semesters.forEach((s) => semester.append(el("option", { value: s.value }, s.label)));
+    const departments = document.getElementById("departments");
+    opts.departments.forEach((d) => departments.append(el("option", { value: d })));
+    const weekdays = document.getElementById("weekdays");
+    weekdayNames.forEach((name, i) => weekdays.append(chip("weekday", String(i + 1), name)));
+    const levels = document.getElementById("levels");
+    opts.levels.forEach((l) => levels.append(chip("level", l.code, l.name)));
+    if (opts.semesters.length === 0) showMessage("課程資料尚未準備好，請稍後再試", true);
+  }
+
+  function buildQuery() {
+    const data = new FormData(form);
+    const params = new URLSearchParams();
+    ["semester", "department", "q"].forEach((key) => {
+      const value = (data.get(key) || "").trim();
+      if (value) params.set(key, value);
+    });
+    ["weekday", "level"].forEach((key) => {
+      const values = data.getAll(key);
+      if (values.length) params.set(key, values.join(","));
+    });
+    return params.toString();
+  }
+
+  async function sendCourse(uid) {
+    const text = "課程 " + uid;
+    if (!liff.isInClient()) {
+      showMessage("請在 LINE 中開啟此頁面，或直接傳送「" + text + "」給機器人", true);
+      return;
+    }
+    try {
+      await liff.sendMessages([{ type: "text", text: text }]);
+      liff.closeWindow();
+    } catch (err) {
+      showMessage("傳送失敗：" + err.message, true);
+    }
+  }
+
+  function courseCard(c) {
+    const card = el("div", { className: "card" });
+    card.append(el("h2", {}, c.title + "（" + c.uid + "）"));
+    if (c.teachers.length) card.append(el("p", {}, "👨‍🏫 " + c.teachers.join("、")));
+    if (c.times.length) card.append(el("p", {}, "⏰ " + c.times.join("、")));
+    if (c.locations.length) card.append(el("p", {}, "📍 " + c.locations.join("、")));
+    const send = el("button", { type: "button" }, "傳送到聊天室");
+    send.addEventListener("click", () => sendCourse(c.uid));
+    card.append(send);
+    return card;
+  }
+
+  // Appends one page of results; the first page replaces the previous search
+  function renderPage(query, result, first) {
+    if (first) results.replaceChildren(el("p", { className: "summary" }, "共 " + result.total + " 門"));
+    results.append(...result.courses.map(courseCard));
+    if (!result.next_cursor) return;
+    const more = el("button", { type: "button", className: "more" }, "載入更多");
+    more.addEventListener("click", async () => {
+      more.disabled = true;
+      try {
+        const params = new URLSearchParams(query);
+        params.set("cursor", result.next_cursor);
+        const next = await getJSON("/liff/api/courses?" + params.toString());
+        more.remove();
+        renderPage(query, next, false);
+      } catch (err) {
+        more.disabled = false;
+        more.textContent = "載入失敗，點此重試";
+      }
+    });
+    results.append(more);
+  }
+
+  form.addEventListener("submit", async (event) => {
+    event.preventDefault();
+    showMessage("搜尋中…");
+    const query = buildQuery();
+    try {
+      renderPage(query, await getJSON("/liff/api/courses?" + query), true);
+    } catch (err) {
+      showMessage("搜尋失敗：" + err.message, true);
+    }
+  });
+
+  // Linking starts on the server, which asks LINE who holds the access token
+  async function startAccountLink() {
+    if (!liff.isLoggedIn()) {
+      liff.login({ redirectUri: location.href });
+      return;
+    }
+    try {
+      const res = await fetch("/account/liff", {
+        method: "POST",
+        headers: { Authorization: "Bearer " + liff.getAccessToken() },
+      });
+      if (!res.ok) throw new Error((await res.json().catch(() => ({}))).error || res.statusText);
+      location.href = (await res.json()).url;
+    } catch (err) {
+      showMessage("無法開始綁定：" + err.message, true);
+    }
+  }
+
+  const accountLink = document.getElementById("account-link");
+  if (accountLink) accountLink.addEventListener("click", startAccountLink);
+
+  liff.init({ liffId: liffId })
+    .then(loadOptions)
+    .catch((err) => showMessage("初始化失敗：" + err.message, true));
+})();
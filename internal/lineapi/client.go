@@ -0,0 +1,451 @@
+// Package lineapi wraps the LINE Messaging API client with retries for
+// 429/5xx responses, monthly push quota tracking, and metrics.
+//
+// Reply messages are free and never blocked by the quota; push messages are
+// refused locally with ErrQuotaExhausted once the monthly quota is used up,
+// so callers can log and move on instead of failing the webhook.
+//
+// In dry-run mode, replies and pushes are logged as JSON instead of sent,
+// so a shadow deployment can process live traffic without answering users.
+package lineapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/metrics"
+	"github.com/google/uuid"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+// Operation names used for metrics and logging.
+const (
+	OpReply   = "reply"
+	OpPush    = "push"
+	OpLoading = "loading"
+	OpQuota   = "quota"
+	OpLink    = "link_token"
+	OpBotInfo = "bot_info"
+)
+
+// Sentinel errors returned (wrapped) by Client methods.
+var (
+	// ErrQuotaExhausted means the monthly push quota is used up.
+	ErrQuotaExhausted = errors.New("LINE monthly message quota exhausted")
+	// ErrRateLimited means LINE kept returning 429 after all retries.
+	ErrRateLimited = errors.New("LINE API rate limited")
+	// ErrInvalidReplyToken means the reply token was expired or already used.
+	ErrInvalidReplyToken = errors.New("LINE reply token invalid or expired")
+)
+
+// APIError is a non-2xx response from the LINE Messaging API.
+type APIError struct {
+	Operation  string
+	StatusCode int
+	Message    string // "message" field of LINE's error body
+	kind       error  // one of the sentinel errors, or nil
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("LINE %s API: status %d: %s", e.Operation, e.StatusCode, e.Message)
+}
+
+// Unwrap allows errors.Is(err, ErrRateLimited) and friends.
+func (e *APIError) Unwrap() error {
+	return e.kind
+}
+
+// retryable reports whether the response is worth retrying (429 rate limit or 5xx).
+// A 429 caused by the monthly limit is final until next month.
+func (e *APIError) retryable() bool {
+	if e.kind == ErrQuotaExhausted {
+		return false
+	}
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// Config configures a Client.
+type Config struct {
+	ChannelToken string
+	Metrics      *metrics.Metrics // Optional
+	Logger       *logger.Logger
+
+	MaxRetries   int           // Default: config.LINEAPIMaxRetries
+	RetryInitial time.Duration // Default: config.LINEAPIRetryInitial
+	DryRun       bool          // Log replies and pushes instead of sending them
+
+	// Options are passed to messaging_api.NewMessagingApiAPI (e.g., WithEndpoint in tests).
+	Options []messaging_api.MessagingApiAPIOption
+}
+
+// Client is a quota-aware LINE Messaging API client. Safe for concurrent use.
+type Client struct {
+	api          *messaging_api.MessagingApiAPI
+	metrics      *metrics.Metrics
+	logger       *logger.Logger
+	maxRetries   int
+	retryInitial time.Duration
+	dryRun       bool
+
+	mu    sync.Mutex
+	quota Quota
+}
+
+// Quota is a snapshot of the monthly message quota.
+type Quota struct {
+	Limit     int64     // Monthly limit; -1 = unlimited
+	Used      int64     // Messages sent this month (LINE's count plus local pushes since refresh)
+	Exhausted bool      // Set when LINE rejects a push for the monthly limit
+	UpdatedAt time.Time // Last successful RefreshQuota; zero if never refreshed
+}
+
+// Remaining returns the messages left this month, or -1 if unlimited.
+func (q Quota) Remaining() int64 {
+	if q.Limit < 0 {
+		return -1
+	}
+	return max(q.Limit-q.Used, 0)
+}
+
+// New creates a Client.
+func New(cfg Config) (*Client, error) {
+	api, err := messaging_api.NewMessagingApiAPI(cfg.ChannelToken, cfg.Options...)
+	if err != nil {
+		return nil, fmt.Errorf("create messaging API client: %w", err)
+	}
+
+	c := &Client{
+		api:          api,
+		metrics:      cfg.Metrics,
+		logger:       cfg.Logger,
+		maxRetries:   cfg.MaxRetries,
+		retryInitial: cfg.RetryInitial,
+		dryRun:       cfg.DryRun,
+		quota:        Quota{Limit: -1},
+	}
+	if c.maxRetries == 0 {
+		c.maxRetries = config.LINEAPIMaxRetries
+	}
+	if c.retryInitial == 0 {
+		c.retryInitial = config.LINEAPIRetryInitial
+	}
+	return c, nil
+}
+
+// Reply sends a reply message. Reply messages do not count toward the quota.
+func (c *Client) Reply(ctx context.Context, req *messaging_api.ReplyMessageRequest) error {
+	if c.dryRun {
+		c.logDryRun(ctx, OpReply, req)
+		return nil
+	}
+	return c.do(ctx, OpReply, c.maxRetries, func(api *messaging_api.MessagingApiAPI) (*http.Response, error) {
+		res, _, err := api.ReplyMessageWithHttpInfo(req)
+		return res, err
+	})
+}
+
+// Push sends a push message. Returns an error wrapping ErrQuotaExhausted without
+// calling LINE once the monthly quota is known to be used up.
+// A single retry key is used across retries so LINE never delivers twice.
+func (c *Client) Push(ctx context.Context, req *messaging_api.PushMessageRequest) error {
+	if q := c.Quota(); q.Exhausted || (q.Limit >= 0 && q.Remaining() == 0) {
+		c.record(OpPush, "quota_exhausted")
+		return fmt.Errorf("push to %s: %w", req.To, ErrQuotaExhausted)
+	}
+	if c.dryRun {
+		c.logDryRun(ctx, OpPush, req)
+		return nil
+	}
+
+	retryKey := uuid.NewString()
+	err := c.do(ctx, OpPush, c.maxRetries, func(api *messaging_api.MessagingApiAPI) (*http.Response, error) {
+		res, _, err := api.PushMessageWithHttpInfo(req, retryKey)
+		return res, err
+	})
+	switch {
+	case err == nil:
+		c.addUsage(1)
+	case errors.Is(err, ErrQuotaExhausted):
+		c.markExhausted()
+	}
+	return err
+}
+
+// ShowLoadingAnimation shows the loading indicator. It is cosmetic, so it is not retried.
+func (c *Client) ShowLoadingAnimation(ctx context.Context, req *messaging_api.ShowLoadingAnimationRequest) error {
+	if c.dryRun {
+		c.record(OpLoading, "dry_run")
+		return nil
+	}
+	return c.do(ctx, OpLoading, 0, func(api *messaging_api.MessagingApiAPI) (*http.Response, error) {
+		res, _, err := api.ShowLoadingAnimationWithHttpInfo(req)
+		return res, err
+	})
+}
+
+// IssueLinkToken issues a token for linking the user's LINE account to an
+// external account. The token is valid for 10 minutes and single-use.
+func (c *Client) IssueLinkToken(ctx context.Context, userID string) (string, error) {
+	var token *messaging_api.IssueLinkTokenResponse
+	if err := c.do(ctx, OpLink, c.maxRetries, func(api *messaging_api.MessagingApiAPI) (*http.Response, error) {
+		res, body, err := api.IssueLinkTokenWithHttpInfo(userID)
+		token = body
+		return res, err
+	}); err != nil {
+		return "", fmt.Errorf("issue link token: %w", err)
+	}
+	return token.LinkToken, nil
+}
+
+// BotInfo returns the bot's profile. LINE rejects an invalid channel token
+// with 401, so it doubles as a token check.
+func (c *Client) BotInfo(ctx context.Context) (*messaging_api.BotInfoResponse, error) {
+	var info *messaging_api.BotInfoResponse
+	if err := c.do(ctx, OpBotInfo, c.maxRetries, func(api *messaging_api.MessagingApiAPI) (*http.Response, error) {
+		res, body, err := api.GetBotInfoWithHttpInfo()
+		info = body
+		return res, err
+	}); err != nil {
+		return nil, fmt.Errorf("get bot info: %w", err)
+	}
+	return info, nil
+}
+
+// RefreshQuota reads the monthly limit and consumption from LINE and updates metrics.
+func (c *Client) RefreshQuota(ctx context.Context) error {
+	var quota *messaging_api.MessageQuotaResponse
+	if err := c.do(ctx, OpQuota, c.maxRetries, func(api *messaging_api.MessagingApiAPI) (*http.Response, error) {
+		res, body, err := api.GetMessageQuotaWithHttpInfo()
+		quota = body
+		return res, err
+	}); err != nil {
+		return fmt.Errorf("get message quota: %w", err)
+	}
+
+	var consumption *messaging_api.QuotaConsumptionResponse
+	if err := c.do(ctx, OpQuota, c.maxRetries, func(api *messaging_api.MessagingApiAPI) (*http.Response, error) {
+		res, body, err := api.GetMessageQuotaConsumptionWithHttpInfo()
+		consumption = body
+		return res, err
+	}); err != nil {
+		return fmt.Errorf("get message quota consumption: %w", err)
+	}
+
+	limit := int64(-1)
+	if quota.Type == messaging_api.QuotaType_LIMITED {
+		limit = quota.Value
+	}
+
+	c.mu.Lock()
+	c.quota = Quota{
+		Limit:     limit,
+		Used:      consumption.TotalUsage,
+		Exhausted: limit >= 0 && consumption.TotalUsage >= limit,
+		UpdatedAt: time.Now(),
+	}
+	q := c.quota
+	c.mu.Unlock()
+
+	c.updateQuotaMetrics(q)
+	return nil
+}
+
+// Quota returns the last known quota snapshot.
+func (c *Client) Quota() Quota {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.quota
+}
+
+// RunQuotaRefresh refreshes the quota immediately and then every interval until ctx is done.
+func (c *Client) RunQuotaRefresh(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.RefreshQuota(ctx); err != nil && ctx.Err() == nil {
+			c.logger.WithError(err).Warn("Failed to refresh LINE message quota")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Client) addUsage(n int64) {
+	c.mu.Lock()
+	c.quota.Used += n
+	q := c.quota
+	c.mu.Unlock()
+	c.updateQuotaMetrics(q)
+}
+
+func (c *Client) markExhausted() {
+	c.mu.Lock()
+	c.quota.Exhausted = true
+	if c.quota.Limit >= 0 {
+		c.quota.Used = max(c.quota.Used, c.quota.Limit)
+	}
+	q := c.quota
+	c.mu.Unlock()
+	c.updateQuotaMetrics(q)
+	c.logger.Warn("LINE monthly message quota exhausted, push messages disabled until quota resets")
+}
+
+func (c *Client) updateQuotaMetrics(q Quota) {
+	if c.metrics != nil {
+		c.metrics.SetLineQuota(q.Limit, q.Used)
+	}
+}
+
+// do runs call with retries on 429/5xx and network errors, and records the final outcome.
+func (c *Client) do(ctx context.Context, op string, maxRetries int, call func(*messaging_api.MessagingApiAPI) (*http.Response, error)) error {
+	// The SDK stores the context on the client, so each call gets its own shallow copy.
+	api := *c.api
+	api.WithContext(ctx)
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		var res *http.Response
+		res, err = call(&api)
+		if err == nil {
+			c.record(op, "success")
+			return nil
+		}
+
+		var apiErr *APIError
+		if res != nil {
+			apiErr = newAPIError(op, res)
+			err = apiErr
+		}
+		if (apiErr != nil && !apiErr.retryable()) || attempt >= maxRetries || ctx.Err() != nil {
+			break
+		}
+
+		c.recordRetry(op)
+		c.logger.WithField("operation", op).
+			WithField("attempt", attempt+1).
+			WithError(err).
+			DebugContext(ctx, "LINE API call failed, retrying")
+		if sleepErr := sleep(ctx, c.backoff(attempt, res)); sleepErr != nil {
+			break
+		}
+	}
+
+	c.record(op, statusOf(err))
+	return err
+}
+
+// backoff returns the delay before the next attempt: Retry-After if LINE sent one,
+// otherwise retryInitial * 2^attempt with ±25% jitter.
+func (c *Client) backoff(attempt int, res *http.Response) time.Duration {
+	if res != nil {
+		if d, err := time.ParseDuration(res.Header.Get("Retry-After") + "s"); err == nil && d > 0 {
+			return d
+		}
+	}
+	delay := c.retryInitial << attempt
+	half := int64(delay) / 2
+	if half <= 0 {
+		return delay
+	}
+	jitter, err := rand.Int(rand.Reader, big.NewInt(half))
+	if err != nil {
+		return delay
+	}
+	return delay - delay/4 + time.Duration(jitter.Int64())
+}
+
+// logDryRun logs the JSON of a request that dry-run mode did not send.
+func (c *Client) logDryRun(ctx context.Context, op string, req any) {
+	c.record(op, "dry_run")
+	log := c.logger.WithField("operation", op)
+	body, err := json.Marshal(req)
+	if err != nil {
+		log.WithError(err).WarnContext(ctx, "Dry run: failed to encode LINE request")
+		return
+	}
+	log.WithField("request", string(body)).InfoContext(ctx, "Dry run: LINE request not sent")
+}
+
+func (c *Client) record(op, status string) {
+	if c.metrics != nil {
+		c.metrics.RecordLineAPI(op, status)
+	}
+}
+
+func (c *Client) recordRetry(op string) {
+	if c.metrics != nil {
+		c.metrics.RecordLineAPIRetry(op)
+	}
+}
+
+// newAPIError builds an APIError from a non-2xx response. The SDK restores
+// the body after reading it, so it can be decoded here.
+func newAPIError(op string, res *http.Response) *APIError {
+	e := &APIError{Operation: op, StatusCode: res.StatusCode}
+	if res.Body != nil {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+		_ = res.Body.Close()
+		var payload struct {
+			Message string `json:"message"`
+		}
+		if json.NewDecoder(bytes.NewReader(body)).Decode(&payload) == nil {
+			e.Message = payload.Message
+		} else {
+			e.Message = strings.TrimSpace(string(body))
+		}
+	}
+
+	switch {
+	case res.StatusCode == http.StatusTooManyRequests && strings.Contains(strings.ToLower(e.Message), "monthly limit"):
+		e.kind = ErrQuotaExhausted
+	case res.StatusCode == http.StatusTooManyRequests:
+		e.kind = ErrRateLimited
+	case res.StatusCode == http.StatusBadRequest && strings.Contains(e.Message, "Invalid reply token"):
+		e.kind = ErrInvalidReplyToken
+	}
+	return e
+}
+
+// statusOf maps an error to a metrics status label.
+func statusOf(err error) string {
+	var apiErr *APIError
+	switch {
+	case errors.Is(err, ErrQuotaExhausted):
+		return "quota_exhausted"
+	case errors.Is(err, ErrRateLimited):
+		return "rate_limited"
+	case errors.Is(err, ErrInvalidReplyToken):
+		return "invalid_token"
+	case errors.As(err, &apiErr) && apiErr.StatusCode >= 500:
+		return "server_error"
+	default:
+		return "error"
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
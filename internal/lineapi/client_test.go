@@ -0,0 +1,298 @@
+package lineapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/metrics"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// fakeLINE replies to each request with the next scripted response and records
+// the requests it saw.
+type fakeLINE struct {
+	mu        sync.Mutex
+	responses []fakeResponse
+	requests  []*http.Request
+}
+
+type fakeResponse struct {
+	status int
+	body   string
+}
+
+func (f *fakeLINE) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r)
+	resp := fakeResponse{status: http.StatusOK, body: "{}"}
+	if len(f.responses) > 0 {
+		resp = f.responses[0]
+		f.responses = f.responses[1:]
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(resp.status)
+	_, _ = w.Write([]byte(resp.body))
+}
+
+func (f *fakeLINE) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.requests)
+}
+
+func newTestClient(t *testing.T, responses ...fakeResponse) (*Client, *fakeLINE) {
+	t.Helper()
+	fake := &fakeLINE{responses: responses}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	c, err := New(Config{
+		ChannelToken: "test_token",
+		Metrics:      metrics.New(prometheus.NewRegistry()),
+		Logger:       logger.New("error"),
+		MaxRetries:   2,
+		RetryInitial: time.Millisecond,
+		Options:      []messaging_api.MessagingApiAPIOption{messaging_api.WithEndpoint(server.URL)},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return c, fake
+}
+
+func replyRequest() *messaging_api.ReplyMessageRequest {
+	return &messaging_api.ReplyMessageRequest{
+		ReplyToken: "token",
+		Messages:   []messaging_api.MessageInterface{&messaging_api.TextMessage{Text: "hi"}},
+	}
+}
+
+func pushRequest() *messaging_api.PushMessageRequest {
+	return &messaging_api.PushMessageRequest{
+		To:       "U123",
+		Messages: []messaging_api.MessageInterface{&messaging_api.TextMessage{Text: "hi"}},
+	}
+}
+
+func TestReply_Retry(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		responses []fakeResponse
+		wantErr   error
+		wantCalls int
+	}{
+		{
+			name:      "Success after server error",
+			responses: []fakeResponse{{http.StatusInternalServerError, `{"message":"oops"}`}},
+			wantCalls: 2,
+		},
+		{
+			name: "Rate limited after all retries",
+			responses: []fakeResponse{
+				{http.StatusTooManyRequests, `{"message":"The API rate limit has been exceeded."}`},
+				{http.StatusTooManyRequests, `{"message":"The API rate limit has been exceeded."}`},
+				{http.StatusTooManyRequests, `{"message":"The API rate limit has been exceeded."}`},
+			},
+			wantErr:   ErrRateLimited,
+			wantCalls: 3,
+		},
+		{
+			name:      "Invalid reply token is not retried",
+			responses: []fakeResponse{{http.StatusBadRequest, `{"message":"Invalid reply token"}`}},
+			wantErr:   ErrInvalidReplyToken,
+			wantCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			c, fake := newTestClient(t, tt.responses...)
+
+			err := c.Reply(context.Background(), replyRequest())
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("Reply() error = %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Reply() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := fake.count(); got != tt.wantCalls {
+				t.Errorf("LINE calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestPush_RetryKeyStable(t *testing.T) {
+	t.Parallel()
+	c, fake := newTestClient(t, fakeResponse{http.StatusBadGateway, `{"message":"bad gateway"}`})
+
+	if err := c.Push(context.Background(), pushRequest()); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+	if fake.count() != 2 {
+		t.Fatalf("LINE calls = %d, want 2", fake.count())
+	}
+	first := fake.requests[0].Header.Get("X-Line-Retry-Key")
+	if first == "" || first != fake.requests[1].Header.Get("X-Line-Retry-Key") {
+		t.Errorf("retry keys = %q, %q; want the same non-empty key", first, fake.requests[1].Header.Get("X-Line-Retry-Key"))
+	}
+	if got := c.Quota().Used; got != 1 {
+		t.Errorf("Quota().Used = %d, want 1", got)
+	}
+}
+
+func TestPush_QuotaExhausted(t *testing.T) {
+	t.Parallel()
+	c, fake := newTestClient(t, fakeResponse{http.StatusTooManyRequests, `{"message":"You have reached your monthly limit."}`})
+
+	if err := c.Push(context.Background(), pushRequest()); !errors.Is(err, ErrQuotaExhausted) {
+		t.Fatalf("Push() error = %v, want ErrQuotaExhausted", err)
+	}
+	if !c.Quota().Exhausted {
+		t.Error("Quota().Exhausted = false after monthly limit response")
+	}
+
+	// Subsequent pushes fail locally without calling LINE
+	if err := c.Push(context.Background(), pushRequest()); !errors.Is(err, ErrQuotaExhausted) {
+		t.Errorf("Push() error = %v, want ErrQuotaExhausted", err)
+	}
+	if got := fake.count(); got != 1 {
+		t.Errorf("LINE calls = %d, want 1", got)
+	}
+
+	// Reply messages are free and unaffected
+	if err := c.Reply(context.Background(), replyRequest()); err != nil {
+		t.Errorf("Reply() error = %v, want nil while push quota is exhausted", err)
+	}
+}
+
+func TestRefreshQuota(t *testing.T) {
+	t.Parallel()
+	c, _ := newTestClient(t,
+		fakeResponse{http.StatusOK, `{"type":"limited","value":500}`},
+		fakeResponse{http.StatusOK, `{"totalUsage":480}`},
+	)
+
+	if err := c.RefreshQuota(context.Background()); err != nil {
+		t.Fatalf("RefreshQuota() error = %v", err)
+	}
+	q := c.Quota()
+	if q.Limit != 500 || q.Used != 480 || q.Remaining() != 20 || q.Exhausted {
+		t.Errorf("Quota() = %+v, want limit 500, used 480, remaining 20", q)
+	}
+	if q.UpdatedAt.IsZero() {
+		t.Error("Quota().UpdatedAt should be set after refresh")
+	}
+}
+
+func TestIssueLinkToken(t *testing.T) {
+	t.Parallel()
+	c, fake := newTestClient(t, fakeResponse{http.StatusOK, `{"linkToken":"NMZTNuVrPTqlr2IF8Bnymkb7rXfYv5EY"}`})
+
+	token, err := c.IssueLinkToken(context.Background(), "U123")
+	if err != nil {
+		t.Fatalf("IssueLinkToken() error = %v", err)
+	}
+	if token != "NMZTNuVrPTqlr2IF8Bnymkb7rXfYv5EY" {
+		t.Errorf("IssueLinkToken() = %q", token)
+	}
+	if path := fake.requests[0].URL.Path; path != "/v2/bot/user/U123/linkToken" {
+		t.Errorf("request path = %q, want /v2/bot/user/U123/linkToken", path)
+	}
+}
+
+func TestBotInfo(t *testing.T) {
+	t.Parallel()
+	c, fake := newTestClient(t,
+		fakeResponse{http.StatusOK, `{"userId":"Ub1","basicId":"@123abcde","displayName":"NTPU 小工具","chatMode":"bot","markAsReadMode":"auto"}`},
+		fakeResponse{http.StatusUnauthorized, `{"message":"Authentication failed"}`},
+	)
+
+	info, err := c.BotInfo(context.Background())
+	if err != nil {
+		t.Fatalf("BotInfo() error = %v", err)
+	}
+	if info.BasicId != "@123abcde" {
+		t.Errorf("BotInfo().BasicId = %q, want @123abcde", info.BasicId)
+	}
+	if path := fake.requests[0].URL.Path; path != "/v2/bot/info" {
+		t.Errorf("request path = %q, want /v2/bot/info", path)
+	}
+
+	_, err = c.BotInfo(context.Background())
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("BotInfo() with a bad token error = %v, want a 401 APIError", err)
+	}
+	if fake.count() != 2 {
+		t.Errorf("requests = %d, want 401 not retried", fake.count())
+	}
+}
+
+func TestQuota_Unlimited(t *testing.T) {
+	t.Parallel()
+	c, _ := newTestClient(t,
+		fakeResponse{http.StatusOK, `{"type":"none"}`},
+		fakeResponse{http.StatusOK, `{"totalUsage":12345}`},
+	)
+
+	if err := c.RefreshQuota(context.Background()); err != nil {
+		t.Fatalf("RefreshQuota() error = %v", err)
+	}
+	if got := c.Quota().Remaining(); got != -1 {
+		t.Errorf("Remaining() = %d, want -1 for unlimited plan", got)
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	t.Parallel()
+	fake := &fakeLINE{}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	var buf bytes.Buffer
+	c, err := New(Config{
+		ChannelToken: "test_token",
+		Logger:       logger.NewWithWriter("info", &buf),
+		DryRun:       true,
+		Options:      []messaging_api.MessagingApiAPIOption{messaging_api.WithEndpoint(server.URL)},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx := context.Background()
+	if err := c.Reply(ctx, replyRequest()); err != nil {
+		t.Errorf("Reply() error = %v", err)
+	}
+	if err := c.Push(ctx, pushRequest()); err != nil {
+		t.Errorf("Push() error = %v", err)
+	}
+	if err := c.ShowLoadingAnimation(ctx, &messaging_api.ShowLoadingAnimationRequest{ChatId: "U123"}); err != nil {
+		t.Errorf("ShowLoadingAnimation() error = %v", err)
+	}
+
+	if got := fake.count(); got != 0 {
+		t.Errorf("LINE calls = %d, want none in dry run", got)
+	}
+	if got := c.Quota().Used; got != 0 {
+		t.Errorf("Quota().Used = %d, want 0 in dry run", got)
+	}
+	for _, want := range []string{`"operation":"reply"`, `"operation":"push"`, `\"replyToken\":\"token\"`, `\"to\":\"U123\"`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("log missing %s:\n%s", want, buf.String())
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/garyellow/ntpu-linebot-go/internal/semester"
 	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
 )
 
@@ -55,11 +56,12 @@ type Action = messaging_api.ActionInterface
 
 // NewImageMessage creates an image message with the given URLs.
 // The originalContentURL is the full-size image URL, and previewImageURL is the thumbnail.
-// LINE API requires both URLs to be HTTPS.
+// LINE API requires both URLs to be HTTPS. External URLs go through the image
+// proxy when one is set (see UseImageProxy).
 func NewImageMessage(originalContentURL, previewImageURL string) messaging_api.MessageInterface {
 	return &messaging_api.ImageMessage{
-		OriginalContentUrl: originalContentURL,
-		PreviewImageUrl:    previewImageURL,
+		OriginalContentUrl: ProxyImageURL(originalContentURL),
+		PreviewImageUrl:    ProxyImageURL(previewImageURL),
 	}
 }
 
@@ -99,7 +101,7 @@ func NewCarouselTemplate(altText string, columns []CarouselColumn) messaging_api
 		}
 
 		if col.ThumbnailImageURL != "" {
-			column.ThumbnailImageUrl = col.ThumbnailImageURL
+			column.ThumbnailImageUrl = ProxyImageURL(col.ThumbnailImageURL)
 		}
 		if col.ImageBackgroundColor != "" {
 			column.ImageBackgroundColor = col.ImageBackgroundColor
@@ -166,7 +168,7 @@ func NewButtonsTemplateWithImage(altText, title, text, thumbnailImageURL string,
 	}
 
 	if thumbnailImageURL != "" {
-		template.ThumbnailImageUrl = thumbnailImageURL
+		template.ThumbnailImageUrl = ProxyImageURL(thumbnailImageURL)
 	}
 
 	return &messaging_api.TemplateMessage{
@@ -193,7 +195,7 @@ func NewQuickReply(items []QuickReplyItem) *messaging_api.QuickReply {
 		}
 
 		if item.ImageURL != "" {
-			qrItem.ImageUrl = item.ImageURL
+			qrItem.ImageUrl = ProxyImageURL(item.ImageURL)
 		}
 
 		quickReplyItems[i] = qrItem
@@ -458,11 +460,7 @@ func FormatLabel(prefix, content string, limit int) string {
 //
 // Returns: Formatted string like "113 學年度 上學期"
 func FormatSemester(year, term int) string {
-	termStr := "上學期"
-	if term == 2 {
-		termStr = "下學期"
-	}
-	return fmt.Sprintf("%d 學年度 %s", year, termStr)
+	return semester.Semester{Year: year, Term: term}.Label()
 }
 
 // FormatSemesterShort formats year and term into a compact semester string.
@@ -472,7 +470,7 @@ func FormatSemester(year, term int) string {
 //
 // Returns: Formatted string like "113-2" (year-term format)
 func FormatSemesterShort(year, term int) string {
-	return fmt.Sprintf("%d-%d", year, term)
+	return semester.Semester{Year: year, Term: term}.String()
 }
 
 // GetSemesterLabel returns label info based on the semester's position in the data.
@@ -555,17 +553,14 @@ func GetSemesterLabel(year, term int, dataSemesters []SemesterPair) BodyLabelInf
 // Returns: BodyLabelInfo with teacher name as label
 func GetTeacherLabel(teacherName string) BodyLabelInfo {
 	return BodyLabelInfo{
-		Emoji: "👨‍🏫",
+		Emoji: Icon(IconTeacher),
 		Label: teacherName,
 		Color: ColorHeaderCourse, // Use course color for consistency
 	}
 }
 
 // SemesterPair represents a year-term pair for semester comparison.
-type SemesterPair struct {
-	Year int // ROC year (e.g., 113)
-	Term int // 1 (Fall/上學期) or 2 (Spring/下學期)
-}
+type SemesterPair = semester.Semester
 
 // FormatTeachers formats teacher names with optional truncation.
 // If more than maxCount teachers, shows first maxCount names + "等 N 人".
@@ -668,6 +663,11 @@ func QuickReplySmartSearchAction() QuickReplyItem {
 	return QuickReplyItem{Action: NewMessageAction("🔮 找課", "找課")}
 }
 
+// QuickReplyRemoteCourseAction returns a "遠距課程" quick reply item
+func QuickReplyRemoteCourseAction() QuickReplyItem {
+	return QuickReplyItem{Action: NewMessageAction("💻 遠距課程", "遠距課程")}
+}
+
 // QuickReplyProgramAction returns a "學程" quick reply item
 func QuickReplyProgramAction() QuickReplyItem {
 	return QuickReplyItem{Action: NewMessageAction("🧭 學程", "學程")}
@@ -683,6 +683,11 @@ func QuickReplyUsageAction() QuickReplyItem {
 	return QuickReplyItem{Action: NewMessageAction("📊 配額", "配額")}
 }
 
+// QuickReplyHistoryAction returns a "最近查過" quick reply item
+func QuickReplyHistoryAction() QuickReplyItem {
+	return QuickReplyItem{Action: NewMessageAction("🕘 最近查過", "最近查過")}
+}
+
 // QuickReplyMoreCoursesCompact returns a compact "更多" quick reply item for course search results.
 // This provides a cleaner UX with a short label "📅 更多" while the message output
 // remains "更多學期 {keyword}" for consistent behavior.
@@ -766,10 +771,11 @@ func QuickReplyErrorRecovery(retryText string) []QuickReplyItem {
 
 // QuickReplyCourseNav returns quick reply items for course module navigation.
 // Use this after course-related responses.
-// Order: 📚 課程 → 🔮 找課 (if smartSearch) → 📖 說明
+// Order: 📚 課程 → 💻 遠距課程 → 🔮 找課 (if smartSearch) → 📖 說明
 func QuickReplyCourseNav(smartSearchEnabled bool) []QuickReplyItem {
 	items := []QuickReplyItem{
 		QuickReplyCourseAction(),
+		QuickReplyRemoteCourseAction(),
 	}
 	if smartSearchEnabled {
 		items = append(items, QuickReplySmartSearchAction())
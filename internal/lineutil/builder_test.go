@@ -658,6 +658,28 @@ func TestNewTextMessageWithConsistentSender(t *testing.T) {
 	}
 }
 
+func TestTextReply(t *testing.T) {
+	t.Parallel()
+	sender := &messaging_api.Sender{Name: "測試"}
+
+	msgs := TextReply(sender, "已設定")
+	if len(msgs) != 1 {
+		t.Fatalf("TextReply() = %d messages, want 1", len(msgs))
+	}
+	msg, ok := msgs[0].(*messaging_api.TextMessageV2)
+	if !ok || msg.Text != "已設定" || msg.Sender != sender {
+		t.Fatalf("TextReply() = %+v", msgs[0])
+	}
+	if got, want := len(msg.QuickReply.Items), len(QuickReplyMainNavCompact()); got != want {
+		t.Errorf("default quick reply has %d items, want the %d of the compact main navigation", got, want)
+	}
+
+	msg = TextReply(sender, "已設定", QuickReplyHelpAction())[0].(*messaging_api.TextMessageV2)
+	if len(msg.QuickReply.Items) != 1 {
+		t.Errorf("quick reply has %d items, want the 1 given", len(msg.QuickReply.Items))
+	}
+}
+
 func TestFormatTeachers(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
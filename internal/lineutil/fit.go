@@ -0,0 +1,254 @@
+package lineutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+// Rules reported in Adjustment.Rule.
+const (
+	RuleTextLength    = "text_length"    // Text truncated to MaxTextMessageLength
+	RuleAltText       = "alt_text"       // Alt text truncated to MaxAltTextLength
+	RuleBubbleSize    = "bubble_size"    // Bubble over MaxFlexBubbleSize dropped
+	RuleCarouselSplit = "carousel_split" // Carousel split by bubble count or size
+	RuleQuickReply    = "quick_reply"    // Quick reply items or labels trimmed
+	RuleActionData    = "action_data"    // Postback data or action text truncated
+)
+
+// carouselOverhead is reserved for the carousel's own JSON around its bubbles.
+const carouselOverhead = 64
+
+// Adjustment describes one change FitMessages made to a reply.
+type Adjustment struct {
+	Rule   string
+	Detail string
+}
+
+// FitMessages checks messages against the LINE payload limits and adjusts
+// them so the reply is not rejected as a whole: long texts, alt texts and
+// action data are truncated, quick replies trimmed, carousels over the bubble
+// count or size limit split into several messages, and oversized bubbles
+// dropped. A Flex message left without bubbles becomes a text message with
+// its alt text.
+//
+// The returned slice may hold more messages than msgs, so apply the reply's
+// message count limit afterwards. Messages within the limits are returned
+// unchanged and never written to, so shared prebuilt messages are safe.
+func FitMessages(msgs []messaging_api.MessageInterface) ([]messaging_api.MessageInterface, []Adjustment) {
+	f := &fitter{}
+	out := make([]messaging_api.MessageInterface, 0, len(msgs))
+	for _, msg := range msgs {
+		out = append(out, f.fitMessage(msg)...)
+	}
+	return out, f.adjustments
+}
+
+// fitter collects the adjustments of one FitMessages call.
+type fitter struct {
+	adjustments []Adjustment
+}
+
+func (f *fitter) note(rule, format string, args ...any) {
+	f.adjustments = append(f.adjustments, Adjustment{Rule: rule, Detail: fmt.Sprintf(format, args...)})
+}
+
+// trimRunes reports the truncated text if it exceeds limit runes.
+func (f *fitter) trimRunes(rule, field, text string, limit int) (string, bool) {
+	if n := utf8.RuneCountInString(text); n > limit {
+		f.note(rule, "%s: %d characters, limit %d", field, n, limit)
+		return TruncateRunes(text, limit), true
+	}
+	return text, false
+}
+
+func (f *fitter) fitMessage(msg messaging_api.MessageInterface) []messaging_api.MessageInterface {
+	switch m := msg.(type) {
+	case *messaging_api.TextMessageV2:
+		if text, ok := f.trimRunes(RuleTextLength, "text", m.Text, MaxTextMessageLength); ok {
+			m.Text = text
+		}
+		f.fitQuickReply(m.QuickReply)
+	case *messaging_api.TextMessage:
+		if text, ok := f.trimRunes(RuleTextLength, "text", m.Text, MaxTextMessageLength); ok {
+			m.Text = text
+		}
+		f.fitQuickReply(m.QuickReply)
+	case *messaging_api.TemplateMessage:
+		if alt, ok := f.trimRunes(RuleAltText, "altText", m.AltText, MaxAltTextLength); ok {
+			m.AltText = alt
+		}
+		f.fitQuickReply(m.QuickReply)
+	case *messaging_api.ImageMessage:
+		f.fitQuickReply(m.QuickReply)
+	case *messaging_api.FlexMessage:
+		if alt, ok := f.trimRunes(RuleAltText, "altText", m.AltText, MaxAltTextLength); ok {
+			m.AltText = alt
+		}
+		f.fitQuickReply(m.QuickReply)
+		return f.fitFlex(m)
+	}
+	return []messaging_api.MessageInterface{msg}
+}
+
+// fitFlex drops oversized bubbles and splits carousels over the count or
+// size limit. Split messages share the sender; the quick reply stays on the
+// last one, the only one LINE shows it for.
+func (f *fitter) fitFlex(m *messaging_api.FlexMessage) []messaging_api.MessageInterface {
+	switch c := m.Contents.(type) {
+	case *messaging_api.FlexBubble:
+		f.fitBubble(c)
+		if size := jsonSize(c); size > MaxFlexBubbleSize {
+			f.note(RuleBubbleSize, "bubble: %d bytes, limit %d", size, MaxFlexBubbleSize)
+			return []messaging_api.MessageInterface{f.altTextFallback(m)}
+		}
+	case *messaging_api.FlexCarousel:
+		bubbles := make([]messaging_api.FlexBubble, 0, len(c.Contents))
+		sizes := make([]int, 0, len(c.Contents))
+		for i := range c.Contents {
+			f.fitBubble(&c.Contents[i])
+			size := jsonSize(&c.Contents[i])
+			if size > MaxFlexBubbleSize {
+				f.note(RuleBubbleSize, "carousel bubble %d: %d bytes, limit %d", i+1, size, MaxFlexBubbleSize)
+				continue
+			}
+			bubbles = append(bubbles, c.Contents[i])
+			sizes = append(sizes, size)
+		}
+		if len(bubbles) == 0 {
+			return []messaging_api.MessageInterface{f.altTextFallback(m)}
+		}
+
+		chunks := splitBubbles(bubbles, sizes)
+		if len(chunks) == 1 && len(bubbles) == len(c.Contents) {
+			return []messaging_api.MessageInterface{m}
+		}
+		if len(chunks) > 1 {
+			f.note(RuleCarouselSplit, "%d bubbles split into %d carousels", len(bubbles), len(chunks))
+		}
+		msgs := make([]messaging_api.MessageInterface, len(chunks))
+		for i, chunk := range chunks {
+			part := *m
+			part.Contents = &messaging_api.FlexCarousel{Contents: chunk}
+			if i < len(chunks)-1 {
+				part.QuickReply = nil
+			}
+			msgs[i] = &part
+		}
+		return msgs
+	}
+	return []messaging_api.MessageInterface{m}
+}
+
+// splitBubbles groups bubbles into carousels within the count and size limits.
+func splitBubbles(bubbles []messaging_api.FlexBubble, sizes []int) [][]messaging_api.FlexBubble {
+	var chunks [][]messaging_api.FlexBubble
+	start, total := 0, carouselOverhead
+	for i, size := range sizes {
+		if i > start && (i-start == MaxFlexCarouselBubbleCount || total+size+1 > MaxFlexCarouselSize) {
+			chunks = append(chunks, bubbles[start:i])
+			start, total = i, carouselOverhead
+		}
+		total += size + 1 // Comma between bubbles
+	}
+	return append(chunks, bubbles[start:])
+}
+
+// altTextFallback replaces a Flex message that cannot be sent with its alt text.
+func (f *fitter) altTextFallback(m *messaging_api.FlexMessage) messaging_api.MessageInterface {
+	msg := NewTextMessageWithConsistentSender(m.AltText, m.Sender)
+	msg.QuickReply = m.QuickReply
+	return msg
+}
+
+func (f *fitter) fitBubble(b *messaging_api.FlexBubble) {
+	for _, box := range []*messaging_api.FlexBox{b.Header, b.Body, b.Footer} {
+		if box != nil {
+			f.fitComponent(box)
+		}
+	}
+	if b.Hero != nil {
+		f.fitComponent(b.Hero)
+	}
+	f.fitAction(b.Action)
+}
+
+func (f *fitter) fitComponent(c messaging_api.FlexComponentInterface) {
+	switch c := c.(type) {
+	case *messaging_api.FlexBox:
+		f.fitAction(c.Action)
+		for _, child := range c.Contents {
+			f.fitComponent(child)
+		}
+	case *messaging_api.FlexButton:
+		f.fitAction(c.Action)
+	case *messaging_api.FlexText:
+		f.fitAction(c.Action)
+	case *messaging_api.FlexImage:
+		f.fitAction(c.Action)
+	}
+}
+
+// fitAction truncates postback data and action texts. Truncated postback
+// data no longer parses, but one broken button beats a rejected reply.
+func (f *fitter) fitAction(a messaging_api.ActionInterface) {
+	switch a := a.(type) {
+	case *messaging_api.PostbackAction:
+		if len(a.Data) > MaxPostbackData {
+			f.note(RuleActionData, "postback data: %d bytes, limit %d", len(a.Data), MaxPostbackData)
+			a.Data = truncateBytes(a.Data, MaxPostbackData)
+		}
+		if text, ok := f.trimRunes(RuleActionData, "postback displayText", a.DisplayText, MaxActionText); ok {
+			a.DisplayText = text
+		}
+	case *messaging_api.MessageAction:
+		if text, ok := f.trimRunes(RuleActionData, "message action text", a.Text, MaxActionText); ok {
+			a.Text = text
+		}
+	}
+}
+
+func (f *fitter) fitQuickReply(qr *messaging_api.QuickReply) {
+	if qr == nil {
+		return
+	}
+	if n := len(qr.Items); n > MaxQuickReplyItemCount {
+		f.note(RuleQuickReply, "%d quick reply items, limit %d", n, MaxQuickReplyItemCount)
+		qr.Items = qr.Items[:MaxQuickReplyItemCount]
+	}
+	for _, item := range qr.Items {
+		switch a := item.Action.(type) {
+		case *messaging_api.MessageAction:
+			if label, ok := f.trimRunes(RuleQuickReply, "quick reply label", a.Label, MaxQuickReplyLabel); ok {
+				a.Label = label
+			}
+		case *messaging_api.PostbackAction:
+			if label, ok := f.trimRunes(RuleQuickReply, "quick reply label", a.Label, MaxQuickReplyLabel); ok {
+				a.Label = label
+			}
+		}
+		f.fitAction(item.Action)
+	}
+}
+
+// truncateBytes cuts s to at most n bytes without splitting a character.
+func truncateBytes(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// jsonSize returns the encoded size of v, as LINE measures the limits.
+func jsonSize(v any) int {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return 0 // Unencodable payloads fail in the SDK with a clearer error
+	}
+	return len(data)
+}
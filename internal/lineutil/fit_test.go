@@ -0,0 +1,202 @@
+package lineutil
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+// testBubble returns a bubble whose body text has n bytes of padding and
+// whose footer button posts data.
+func testBubble(n int, data string) messaging_api.FlexBubble {
+	body := NewFlexBox("vertical", NewFlexText(strings.Repeat("a", n)).FlexText)
+	footer := NewFlexBox("vertical", NewFlexButton(NewPostbackAction("查看", data)).FlexButton)
+	return *NewFlexBubble(nil, nil, body, footer).FlexBubble
+}
+
+func carouselMessage(bubbles []messaging_api.FlexBubble) *messaging_api.FlexMessage {
+	return &messaging_api.FlexMessage{
+		AltText:    "結果",
+		Contents:   &messaging_api.FlexCarousel{Contents: bubbles},
+		Sender:     &messaging_api.Sender{Name: "test"},
+		QuickReply: NewQuickReply([]QuickReplyItem{QuickReplyHelpAction()}),
+	}
+}
+
+func rules(adjustments []Adjustment) []string {
+	var out []string
+	for _, a := range adjustments {
+		out = append(out, a.Rule)
+	}
+	return out
+}
+
+func TestFitMessages_WithinLimits(t *testing.T) {
+	t.Parallel()
+	bubbles := []messaging_api.FlexBubble{testBubble(10, "course:1"), testBubble(10, "course:2")}
+	msgs := []messaging_api.MessageInterface{
+		NewTextMessageWithConsistentSender("hello", nil),
+		carouselMessage(bubbles),
+	}
+
+	got, adjustments := FitMessages(msgs)
+	if len(adjustments) != 0 {
+		t.Errorf("adjustments = %+v, want none", adjustments)
+	}
+	if len(got) != 2 || got[0] != msgs[0] || got[1] != msgs[1] {
+		t.Errorf("FitMessages() changed messages within limits: %+v", got)
+	}
+}
+
+func TestFitMessages_Truncation(t *testing.T) {
+	t.Parallel()
+	text := &messaging_api.TextMessageV2{Text: strings.Repeat("字", MaxTextMessageLength+1)}
+	items := make([]QuickReplyItem, MaxQuickReplyItemCount+2)
+	for i := range items {
+		items[i] = QuickReplyItem{Action: NewMessageAction(strings.Repeat("長", MaxQuickReplyLabel+5), strings.Repeat("x", MaxActionText+1))}
+	}
+	text.QuickReply = NewQuickReply(items)
+	text.QuickReply.Items = append(text.QuickReply.Items, text.QuickReply.Items[:2]...) // Past NewQuickReply's own cap
+
+	longData := strings.Repeat("資", MaxPostbackData) // 3 bytes each
+	flex := &messaging_api.FlexMessage{
+		AltText:  strings.Repeat("a", MaxAltTextLength+1),
+		Contents: NewFlexBubble(nil, nil, NewFlexBox("vertical", NewFlexButton(NewPostbackAction("看", longData)).FlexButton), nil).FlexBubble,
+	}
+
+	got, adjustments := FitMessages([]messaging_api.MessageInterface{text, flex})
+	if len(got) != 2 {
+		t.Fatalf("got %d messages, want 2", len(got))
+	}
+	if n := utf8.RuneCountInString(text.Text); n != MaxTextMessageLength {
+		t.Errorf("text length = %d, want %d", n, MaxTextMessageLength)
+	}
+	if n := len(text.QuickReply.Items); n != MaxQuickReplyItemCount {
+		t.Errorf("quick reply items = %d, want %d", n, MaxQuickReplyItemCount)
+	}
+	action := text.QuickReply.Items[0].Action.(*messaging_api.MessageAction)
+	if utf8.RuneCountInString(action.Label) > MaxQuickReplyLabel || utf8.RuneCountInString(action.Text) > MaxActionText {
+		t.Errorf("quick reply action not trimmed: label %d, text %d characters",
+			utf8.RuneCountInString(action.Label), utf8.RuneCountInString(action.Text))
+	}
+	if n := utf8.RuneCountInString(flex.AltText); n != MaxAltTextLength {
+		t.Errorf("alt text length = %d, want %d", n, MaxAltTextLength)
+	}
+	button := flex.Contents.(*messaging_api.FlexBubble).Body.Contents[0].(*messaging_api.FlexButton)
+	data := button.Action.(*messaging_api.PostbackAction).Data
+	if len(data) > MaxPostbackData || !utf8.ValidString(data) {
+		t.Errorf("postback data = %d bytes (valid UTF-8: %v), want at most %d", len(data), utf8.ValidString(data), MaxPostbackData)
+	}
+
+	for _, rule := range []string{RuleTextLength, RuleQuickReply, RuleActionData, RuleAltText} {
+		found := false
+		for _, a := range adjustments {
+			found = found || a.Rule == rule
+		}
+		if !found {
+			t.Errorf("no %s adjustment in %v", rule, rules(adjustments))
+		}
+	}
+}
+
+func TestFitMessages_CarouselSplit(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		bubbles    int
+		padding    int
+		wantCounts []int
+	}{
+		{"over bubble count", MaxFlexCarouselBubbleCount + 3, 10, []int{12, 3}},
+		{"over carousel size", 4, 20 * 1024, []int{2, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			bubbles := make([]messaging_api.FlexBubble, tt.bubbles)
+			for i := range bubbles {
+				bubbles[i] = testBubble(tt.padding, "course:1")
+			}
+			msg := carouselMessage(bubbles)
+
+			got, adjustments := FitMessages([]messaging_api.MessageInterface{msg})
+			if len(got) != len(tt.wantCounts) {
+				t.Fatalf("got %d messages, want %d", len(got), len(tt.wantCounts))
+			}
+			for i, m := range got {
+				flex := m.(*messaging_api.FlexMessage)
+				if n := len(flex.Contents.(*messaging_api.FlexCarousel).Contents); n != tt.wantCounts[i] {
+					t.Errorf("message %d has %d bubbles, want %d", i, n, tt.wantCounts[i])
+				}
+				if size := jsonSize(flex.Contents); size > MaxFlexCarouselSize {
+					t.Errorf("message %d carousel is %d bytes", i, size)
+				}
+				if flex.Sender != msg.Sender {
+					t.Errorf("message %d lost the sender", i)
+				}
+				if (flex.QuickReply != nil) != (i == len(got)-1) {
+					t.Errorf("message %d quick reply = %v, want only on the last message", i, flex.QuickReply)
+				}
+			}
+			if len(msg.Contents.(*messaging_api.FlexCarousel).Contents) != tt.bubbles {
+				t.Error("FitMessages() modified the original carousel")
+			}
+			if r := rules(adjustments); len(r) != 1 || r[0] != RuleCarouselSplit {
+				t.Errorf("adjustments = %v, want [%s]", r, RuleCarouselSplit)
+			}
+		})
+	}
+}
+
+func TestFitMessages_OversizedBubble(t *testing.T) {
+	t.Parallel()
+	t.Run("dropped from carousel", func(t *testing.T) {
+		t.Parallel()
+		msg := carouselMessage([]messaging_api.FlexBubble{
+			testBubble(10, "course:1"),
+			testBubble(MaxFlexBubbleSize, "course:2"),
+		})
+		got, adjustments := FitMessages([]messaging_api.MessageInterface{msg})
+		if len(got) != 1 {
+			t.Fatalf("got %d messages, want 1", len(got))
+		}
+		if n := len(got[0].(*messaging_api.FlexMessage).Contents.(*messaging_api.FlexCarousel).Contents); n != 1 {
+			t.Errorf("carousel has %d bubbles, want 1", n)
+		}
+		if r := rules(adjustments); len(r) != 1 || r[0] != RuleBubbleSize {
+			t.Errorf("adjustments = %v, want [%s]", r, RuleBubbleSize)
+		}
+	})
+
+	t.Run("single bubble falls back to alt text", func(t *testing.T) {
+		t.Parallel()
+		bubble := testBubble(MaxFlexBubbleSize, "course:1")
+		msg := &messaging_api.FlexMessage{AltText: "課程資訊", Contents: &bubble}
+		got, _ := FitMessages([]messaging_api.MessageInterface{msg})
+		text, ok := got[0].(*messaging_api.TextMessageV2)
+		if len(got) != 1 || !ok || text.Text != "課程資訊" {
+			t.Errorf("FitMessages() = %+v, want a text message with the alt text", got)
+		}
+	})
+}
+
+func TestTruncateBytes(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input string
+		n     int
+		want  string
+	}{
+		{"abc", 5, "abc"},
+		{"abcdef", 3, "abc"},
+		{"資料", 4, "資"}, // Does not split the second character
+		{"資料", 6, "資料"},
+	}
+	for _, tt := range tests {
+		if got := truncateBytes(tt.input, tt.n); got != tt.want {
+			t.Errorf("truncateBytes(%q, %d) = %q, want %q", tt.input, tt.n, got, tt.want)
+		}
+	}
+}
@@ -300,8 +300,9 @@ func TruncateRunes(text string, maxRunes int) string {
 // ColoredHeaderInfo contains display information for a colored header.
 // Used for carousel cards to show course title with colored background.
 type ColoredHeaderInfo struct {
-	Title string // Course title (e.g., "微積分 (1131U0001)")
-	Color string // Header background color (from ColorHeader* constants)
+	Title    string // Course title (e.g., "微積分 (1131U0001)")
+	Subtitle string // Optional secondary line (e.g., the English course title)
+	Color    string // Header background color (from ColorHeader* constants)
 }
 
 // NewColoredHeader creates a colored header for carousel cards.
@@ -311,6 +312,7 @@ type ColoredHeaderInfo struct {
 //
 //	┌──────────────────────────┐
 //	│   微積分 (1131U0001)     │  <- Colored header (Title)
+//	│   Calculus               │  <- Optional Subtitle (smaller)
 //	├──────────────────────────┤
 //	│ 🆕 最新學期              │  <- Body first row (Label)
 //	│ 📅 開課學期：113-1       │
@@ -331,7 +333,7 @@ func NewColoredHeader(info ColoredHeaderInfo) *FlexBox {
 	// This ensures consistent appearance across all header types
 	textColor := ColorHeroText // White text for all colored backgrounds
 
-	return NewFlexBox("vertical",
+	contents := []messaging_api.FlexComponentInterface{
 		NewFlexText(info.Title).
 			WithWeight("bold").
 			WithSize("md").
@@ -339,7 +341,15 @@ func NewColoredHeader(info ColoredHeaderInfo) *FlexBox {
 			WithWrap(true).
 			WithMaxLines(2).
 			WithLineSpacing(LineSpacingNormal).FlexText,
-	).WithBackgroundColor(info.Color).WithPaddingAll(SpacingL)
+	}
+	if info.Subtitle != "" {
+		contents = append(contents, NewFlexText(info.Subtitle).
+			WithSize("xs").
+			WithColor(textColor).
+			WithWrap(true).
+			WithMaxLines(2).FlexText)
+	}
+	return NewFlexBox("vertical", contents...).WithBackgroundColor(info.Color).WithPaddingAll(SpacingL)
 }
 
 // BodyLabelInfo contains display information for a body label.
@@ -388,6 +398,25 @@ func NewBodyLabel(info BodyLabelInfo) *FlexBox {
 	).WithMargin("sm")
 }
 
+// NewBadgeRow creates a row of small rounded chips (e.g., course flags 英語授課
+// and 遠距) with white text on color. Chips keep their text width instead of
+// stretching across the row. Returns nil if labels is empty.
+func NewBadgeRow(color string, labels ...string) *FlexBox {
+	if len(labels) == 0 {
+		return nil
+	}
+	chips := make([]messaging_api.FlexComponentInterface, 0, len(labels))
+	for _, label := range labels {
+		chip := NewFlexBox("vertical",
+			NewFlexText(label).WithSize("xxs").WithWeight("bold").WithColor(ColorHeroText).FlexText,
+		).WithBackgroundColor(color).WithCornerRadius("md")
+		chip.PaddingTop, chip.PaddingBottom = "2px", "2px"
+		chip.PaddingStart, chip.PaddingEnd = "8px", "8px"
+		chips = append(chips, chip.FlexBox)
+	}
+	return NewFlexBox("horizontal", chips...).WithSpacing("xs").WithMargin("sm")
+}
+
 // InfoRowStyle defines the visual style for an info row
 type InfoRowStyle struct {
 	ValueSize   string // Value text size: "xs", "sm", "md" (default: "sm")
@@ -463,10 +492,10 @@ func CarouselInfoRowStyleMultiLine() InfoRowStyle {
 //
 // Example usage:
 //
-//	NewInfoRow("👨‍🏫", "授課教師", "王教授、李教授", DefaultInfoRowStyle())
-//	NewInfoRow("☎️", "分機號碼", "12345", BoldInfoRowStyle())
-//	NewInfoRow("👨‍🏫", "授課教師", longTeacherList, CarouselInfoRowStyleMultiLine()) // important: 2-line wrap + shrink
-//	NewInfoRow("📍", "辦公位置", location, CarouselInfoRowStyle())                  // secondary: 1-line shrink
+//	NewInfoRow(Icon(IconTeacher), "授課教師", "王教授、李教授", DefaultInfoRowStyle())
+//	NewInfoRow(Icon(IconExtension), "分機號碼", "12345", BoldInfoRowStyle())
+//	NewInfoRow(Icon(IconTeacher), "授課教師", longTeacherList, CarouselInfoRowStyleMultiLine()) // important: 2-line wrap + shrink
+//	NewInfoRow(Icon(IconLocation), "辦公位置", location, CarouselInfoRowStyle())                 // secondary: 1-line shrink
 func NewInfoRow(emoji, label, value string, style InfoRowStyle) *FlexBox {
 	valueText := NewFlexText(value).WithColor(style.ValueColor).WithSize(style.ValueSize).WithMargin("sm")
 	if style.ValueWeight == "bold" {
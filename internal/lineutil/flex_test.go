@@ -290,6 +290,22 @@ func TestNewColoredHeader(t *testing.T) {
 	}
 }
 
+func TestNewColoredHeader_Subtitle(t *testing.T) {
+	t.Parallel()
+	header := NewColoredHeader(ColoredHeaderInfo{
+		Title:    "資料結構 (U0003)",
+		Subtitle: "Data Structures",
+		Color:    ColorHeaderCourse,
+	})
+	if len(header.Contents) != 2 {
+		t.Fatalf("Expected title and subtitle, got %d contents", len(header.Contents))
+	}
+	sub, ok := header.Contents[1].(*messaging_api.FlexText)
+	if !ok || sub.Text != "Data Structures" || sub.Size != "xs" {
+		t.Errorf("Subtitle = %+v, want the xs English title", header.Contents[1])
+	}
+}
+
 // TestNewBodyLabel tests body label creation for carousel cards
 func TestNewBodyLabel(t *testing.T) {
 	t.Parallel()
@@ -978,3 +994,30 @@ func TestBuildCarouselMessagesWithSender(t *testing.T) {
 		t.Errorf("Expected sender name '測試機器人', got %s", flexMsg.Sender.Name)
 	}
 }
+
+func TestNewBadgeRow(t *testing.T) {
+	t.Parallel()
+
+	if row := NewBadgeRow(ColorHeaderCourse); row != nil {
+		t.Errorf("NewBadgeRow() with no labels = %+v, want nil", row)
+	}
+
+	row := NewBadgeRow(ColorHeaderCourse, "英語授課", "遠距")
+	if row.Layout != "horizontal" {
+		t.Errorf("Expected horizontal layout, got %v", row.Layout)
+	}
+	if len(row.Contents) != 2 {
+		t.Fatalf("Expected 2 chips, got %d", len(row.Contents))
+	}
+	chip, ok := row.Contents[1].(*messaging_api.FlexBox)
+	if !ok {
+		t.Fatalf("Expected *messaging_api.FlexBox chip, got %T", row.Contents[1])
+	}
+	if chip.BackgroundColor != ColorHeaderCourse || chip.Flex != 0 {
+		t.Errorf("chip background = %q, flex = %d; want %q, 0", chip.BackgroundColor, chip.Flex, ColorHeaderCourse)
+	}
+	text, ok := chip.Contents[0].(*messaging_api.FlexText)
+	if !ok || text.Text != "遠距" {
+		t.Errorf("chip text = %+v, want 遠距", chip.Contents[0])
+	}
+}
@@ -0,0 +1,114 @@
+package lineutil
+
+import (
+	"maps"
+	"sync/atomic"
+)
+
+// IconName is the semantic name of an icon shown next to a label in replies.
+// Modules look icons up by name so the same field looks the same everywhere
+// and the icons can be changed in one place.
+type IconName string
+
+// Icon names, with their default emoji.
+const (
+	// Course fields
+	IconSemester     IconName = "semester"     // 📅 開課學期
+	IconTeacher      IconName = "teacher"      // 👨‍🏫 授課教師
+	IconTime         IconName = "time"         // ⏰ 上課時間
+	IconLocation     IconName = "location"     // 📍 上課地點, 辦公位置, 校區
+	IconCourse       IconName = "course"       // 📚 課程
+	IconNote         IconName = "note"         // 📝 備註, 說明
+	IconPrerequisite IconName = "prerequisite" // 🧭 先修課程
+	IconSections     IconName = "sections"     // 🧩 開課班次
+	IconDiscussion   IconName = "discussion"   // 💬 討論熱度
+
+	// Program fields
+	IconRequired IconName = "required" // ✅ 必修
+	IconElective IconName = "elective" // 📝 選修
+
+	// Student fields
+	IconStudentID  IconName = "student_id" // 🆔 學號
+	IconDepartment IconName = "department" // 🏫 系所
+	IconYear       IconName = "year"       // 📅 入學學年
+
+	// Contact fields
+	IconJobTitle     IconName = "job_title"    // 🏷️ 職稱
+	IconOrganization IconName = "organization" // 🏢 單位
+	IconPhone        IconName = "phone"        // 📞 電話
+	IconExtension    IconName = "extension"    // ☎️ 分機
+	IconEmail        IconName = "email"        // ✉️ 電子郵件
+
+	// General
+	IconSearch  IconName = "search"  // 🔍 查詢內容
+	IconLink    IconName = "link"    // 🔗 連結
+	IconTip     IconName = "tip"     // 💡 提示
+	IconWarning IconName = "warning" // ⚠️ 注意
+)
+
+// IconSet maps icon names to the strings shown for them.
+type IconSet map[IconName]string
+
+// plainIcon stands in for every icon in PlainIcons. Flex text components
+// must not be empty, so the plain set cannot simply drop the icons.
+const plainIcon = "•"
+
+var emojiIcons = IconSet{
+	IconSemester:     "📅",
+	IconTeacher:      "👨‍🏫",
+	IconTime:         "⏰",
+	IconLocation:     "📍",
+	IconCourse:       "📚",
+	IconNote:         "📝",
+	IconPrerequisite: "🧭",
+	IconSections:     "🧩",
+	IconDiscussion:   "💬",
+	IconRequired:     "✅",
+	IconElective:     "📝",
+	IconStudentID:    "🆔",
+	IconDepartment:   "🏫",
+	IconYear:         "📅",
+	IconJobTitle:     "🏷️",
+	IconOrganization: "🏢",
+	IconPhone:        "📞",
+	IconExtension:    "☎️",
+	IconEmail:        "✉️",
+	IconSearch:       "🔍",
+	IconLink:         "🔗",
+	IconTip:          "💡",
+	IconWarning:      "⚠️",
+}
+
+// activeIcons is the set Icon reads; nil means emojiIcons.
+var activeIcons atomic.Pointer[IconSet]
+
+// EmojiIcons returns a copy of the default emoji icon set.
+func EmojiIcons() IconSet {
+	return maps.Clone(emojiIcons)
+}
+
+// PlainIcons returns an icon set without emoji, for clients or channels that
+// render emoji poorly.
+func PlainIcons() IconSet {
+	set := make(IconSet, len(emojiIcons))
+	for name := range emojiIcons {
+		set[name] = plainIcon
+	}
+	return set
+}
+
+// UseIcons makes set the icons returned by Icon. Names missing from set keep
+// their emoji. Call it at startup, before replies are built.
+func UseIcons(set IconSet) {
+	merged := EmojiIcons()
+	maps.Copy(merged, set)
+	activeIcons.Store(&merged)
+}
+
+// Icon returns the icon for name, or "" for an unknown name.
+func Icon(name IconName) string {
+	if set := activeIcons.Load(); set != nil {
+		return (*set)[name]
+	}
+	return emojiIcons[name]
+}
@@ -0,0 +1,56 @@
+package lineutil
+
+import "testing"
+
+func TestIconSets(t *testing.T) {
+	t.Parallel()
+	emoji, plain := EmojiIcons(), PlainIcons()
+	if len(plain) != len(emoji) {
+		t.Errorf("plain set has %d icons, emoji set %d", len(plain), len(emoji))
+	}
+	for name, icon := range emoji {
+		if icon == "" {
+			t.Errorf("emoji icon %q is empty", name)
+		}
+		if plain[name] == "" {
+			t.Errorf("plain icon %q is empty; Flex text must not be empty", name)
+		}
+	}
+
+	// The returned sets are copies
+	emoji[IconTeacher] = "x"
+	if EmojiIcons()[IconTeacher] == "x" {
+		t.Error("EmojiIcons() returned the shared set")
+	}
+}
+
+// TestUseIcons changes the global icon set, so it does not run in parallel;
+// parallel tests only start after it has restored the emoji icons.
+func TestUseIcons(t *testing.T) {
+	t.Cleanup(func() { UseIcons(EmojiIcons()) })
+
+	if got := Icon(IconTeacher); got != "👨‍🏫" {
+		t.Errorf("Icon(IconTeacher) = %q, want 👨‍🏫", got)
+	}
+
+	UseIcons(PlainIcons())
+	if got := Icon(IconTeacher); got != plainIcon {
+		t.Errorf("Icon(IconTeacher) with plain icons = %q, want %q", got, plainIcon)
+	}
+	if got := GetTeacherLabel("王教授").Emoji; got != plainIcon {
+		t.Errorf("GetTeacherLabel().Emoji with plain icons = %q, want %q", got, plainIcon)
+	}
+
+	// Names missing from a partial set keep their emoji
+	UseIcons(IconSet{IconTime: "[時間]"})
+	if got := Icon(IconTime); got != "[時間]" {
+		t.Errorf("Icon(IconTime) = %q, want [時間]", got)
+	}
+	if got := Icon(IconLocation); got != "📍" {
+		t.Errorf("Icon(IconLocation) = %q, want 📍", got)
+	}
+
+	if got := Icon("unknown"); got != "" {
+		t.Errorf("Icon(unknown) = %q, want empty", got)
+	}
+}
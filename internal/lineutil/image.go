@@ -0,0 +1,49 @@
+package lineutil
+
+import (
+	"net/url"
+	"sync/atomic"
+)
+
+// imageProxy is the endpoint ProxyImageURL rewrites external images to.
+type imageProxy struct {
+	endpoint string              // e.g., https://bot.example.com/img
+	hosts    map[string]struct{} // Upstream hosts the proxy serves
+}
+
+// activeImageProxy is the proxy ProxyImageURL uses; nil leaves URLs as they are.
+var activeImageProxy atomic.Pointer[imageProxy]
+
+// UseImageProxy routes images on hosts through endpoint, which serves
+// GET {endpoint}?u={original URL}. Message builders taking image URLs
+// (template thumbnails, quick reply icons, image messages) rewrite them with
+// ProxyImageURL. Call it at startup, before replies are built; an empty
+// endpoint turns rewriting off.
+func UseImageProxy(endpoint string, hosts []string) {
+	if endpoint == "" {
+		activeImageProxy.Store(nil)
+		return
+	}
+	p := &imageProxy{endpoint: endpoint, hosts: make(map[string]struct{}, len(hosts))}
+	for _, host := range hosts {
+		p.hosts[host] = struct{}{}
+	}
+	activeImageProxy.Store(p)
+}
+
+// ProxyImageURL returns the proxied URL of an external image, or src itself
+// when no proxy is set or src is not on one of its hosts.
+func ProxyImageURL(src string) string {
+	p := activeImageProxy.Load()
+	if p == nil || src == "" {
+		return src
+	}
+	u, err := url.Parse(src)
+	if err != nil || u.Scheme != "https" {
+		return src
+	}
+	if _, ok := p.hosts[u.Hostname()]; !ok {
+		return src
+	}
+	return p.endpoint + "?" + url.Values{"u": {src}}.Encode()
+}
@@ -0,0 +1,40 @@
+package lineutil
+
+import (
+	"testing"
+
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+// TestUseImageProxy changes the global image proxy, so it does not run in
+// parallel; other tests see no proxy.
+func TestUseImageProxy(t *testing.T) {
+	t.Cleanup(func() { UseImageProxy("", nil) })
+
+	const logo = "https://new.ntpu.edu.tw/assets/logo/ntpu_logo.png"
+	if got := ProxyImageURL(logo); got != logo {
+		t.Errorf("ProxyImageURL() without a proxy = %q, want it unchanged", got)
+	}
+
+	UseImageProxy("https://bot.example.com/img", []string{"new.ntpu.edu.tw"})
+
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{logo, "https://bot.example.com/img?u=https%3A%2F%2Fnew.ntpu.edu.tw%2Fassets%2Flogo%2Fntpu_logo.png"},
+		{"https://bot.example.com/timetable/abc.png", "https://bot.example.com/timetable/abc.png"},
+		{"http://new.ntpu.edu.tw/logo.png", "http://new.ntpu.edu.tw/logo.png"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := ProxyImageURL(tt.src); got != tt.want {
+			t.Errorf("ProxyImageURL(%q) = %q, want %q", tt.src, got, tt.want)
+		}
+	}
+
+	msg := NewButtonsTemplateWithImage("alt", "title", "text", logo, nil).(*messaging_api.TemplateMessage)
+	if got := msg.Template.(*messaging_api.ButtonsTemplate).ThumbnailImageUrl; got != tests[0].want {
+		t.Errorf("buttons thumbnail = %q, want the proxied URL", got)
+	}
+}
@@ -6,6 +6,7 @@ const (
 	MaxTextMessageLength = 5000 // Text message max content length
 	MaxAltTextLength     = 400  // Template/Flex message alt text length
 	MaxPostbackData      = 300  // Postback action data length
+	MaxActionText        = 300  // Message action text and postback display text length
 
 	// Template Message Limits
 	MaxTemplateTitleLength   = 40  // Buttons/Carousel template title
@@ -16,7 +17,9 @@ const (
 	MaxTemplateActionCount   = 4   // Max actions per template column
 
 	// Flex Message Limits
-	MaxFlexCarouselBubbleCount = 12 // Max bubbles in a Flex carousel
+	MaxFlexCarouselBubbleCount = 12        // Max bubbles in a Flex carousel
+	MaxFlexBubbleSize          = 30 * 1024 // Max JSON size of a bubble (bytes)
+	MaxFlexCarouselSize        = 50 * 1024 // Max JSON size of a carousel (bytes)
 
 	// Quick Reply Limits
 	MaxQuickReplyItemCount = 13 // Max items in a quick reply
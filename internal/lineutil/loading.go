@@ -0,0 +1,17 @@
+package lineutil
+
+import (
+	"context"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/ctxutil"
+)
+
+// ShowLoading shows the chat loading animation if the webhook enabled it for
+// this request (1:1 chats only). Call it right before a slow path such as a
+// cache-miss scrape or an LLM request; fast paths should not call it.
+// Safe to call multiple times; the animation is requested at most once.
+func ShowLoading(ctx context.Context) {
+	if show := ctxutil.GetLoadingIndicator(ctx); show != nil {
+		show(ctx)
+	}
+}
@@ -0,0 +1,22 @@
+package lineutil
+
+import (
+	"context"
+	"testing"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/ctxutil"
+)
+
+func TestShowLoading(t *testing.T) {
+	t.Parallel()
+
+	// No indicator (group chat or disabled): no-op
+	ShowLoading(context.Background())
+
+	calls := 0
+	ctx := ctxutil.WithLoadingIndicator(context.Background(), func(context.Context) { calls++ })
+	ShowLoading(ctx)
+	if calls != 1 {
+		t.Errorf("ShowLoading() called indicator %d times, want 1", calls)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // PeriodTime represents a class period with start and end times.
@@ -37,6 +38,39 @@ var periodTimes = map[int]PeriodTime{
 // periodRegex matches patterns like "5~6", "1~2", "10~11" (period ranges)
 var periodRegex = regexp.MustCompile(`(\d{1,2})~(\d{1,2})`)
 
+// meetingRegex matches a weekly meeting such as "每週二3~4" (weekday, period range).
+var meetingRegex = regexp.MustCompile(`週([一二三四五六日])\s*(\d{1,2})~(\d{1,2})`)
+
+var chineseWeekdays = map[string]time.Weekday{
+	"日": time.Sunday, "一": time.Monday, "二": time.Tuesday, "三": time.Wednesday,
+	"四": time.Thursday, "五": time.Friday, "六": time.Saturday,
+}
+
+// CourseMeeting is a weekly class meeting parsed from a course time string.
+type CourseMeeting struct {
+	Weekday     time.Weekday
+	StartPeriod int
+	EndPeriod   int
+}
+
+// ParseCourseMeeting parses a course time string such as "每週二3~4".
+// Returns false for strings without a weekday and a valid period range
+// (e.g., "每週未維護").
+func ParseCourseMeeting(timeStr string) (CourseMeeting, bool) {
+	m := meetingRegex.FindStringSubmatch(timeStr)
+	if m == nil {
+		return CourseMeeting{}, false
+	}
+	startPeriod, _ := strconv.Atoi(m[2])
+	endPeriod, _ := strconv.Atoi(m[3])
+	_, okStart := periodTimes[startPeriod]
+	_, okEnd := periodTimes[endPeriod]
+	if !okStart || !okEnd || startPeriod > endPeriod {
+		return CourseMeeting{}, false
+	}
+	return CourseMeeting{Weekday: chineseWeekdays[m[1]], StartPeriod: startPeriod, EndPeriod: endPeriod}, true
+}
+
 // FormatCourseTime converts a course time string to include actual times.
 // Input format: "每週一5~6" or "每週三10~11"
 // Output format: "每週一 5~6 (13:10-15:00)" or "每週三 10~11 (18:30-20:15)"
@@ -2,6 +2,7 @@ package lineutil
 
 import (
 	"testing"
+	"time"
 )
 
 func TestFormatCourseTime(t *testing.T) {
@@ -246,3 +247,31 @@ func TestGetPeriodRangeTime(t *testing.T) {
 		})
 	}
 }
+
+func TestParseCourseMeeting(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		input  string
+		want   CourseMeeting
+		wantOK bool
+	}{
+		{"weekday with range", "每週二3~4", CourseMeeting{Weekday: time.Tuesday, StartPeriod: 3, EndPeriod: 4}, true},
+		{"evening periods", "每週五10~11", CourseMeeting{Weekday: time.Friday, StartPeriod: 10, EndPeriod: 11}, true},
+		{"sunday", "每週日1~2", CourseMeeting{Weekday: time.Sunday, StartPeriod: 1, EndPeriod: 2}, true},
+		{"not maintained", "每週未維護", CourseMeeting{}, false},
+		{"period out of range", "每週一13~14", CourseMeeting{}, false},
+		{"reversed range", "每週一4~3", CourseMeeting{}, false},
+		{"empty", "", CourseMeeting{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, ok := ParseCourseMeeting(tt.input)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("ParseCourseMeeting(%q) = %+v, %v; want %+v, %v", tt.input, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
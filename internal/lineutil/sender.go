@@ -34,6 +34,17 @@ func NewTextMessageWithConsistentSender(text string, sender *messaging_api.Sende
 	}
 }
 
+// TextReply builds a reply of a single text message from sender. items are
+// its quick reply; without any it offers the compact main navigation.
+func TextReply(sender *messaging_api.Sender, text string, items ...QuickReplyItem) []messaging_api.MessageInterface {
+	if len(items) == 0 {
+		items = QuickReplyMainNavCompact()
+	}
+	msg := NewTextMessageWithConsistentSender(text, sender)
+	msg.QuickReply = NewQuickReply(items)
+	return []messaging_api.MessageInterface{msg}
+}
+
 // ================================================
 // Common Error Message Helpers
 // ================================================
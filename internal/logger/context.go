@@ -0,0 +1,25 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/ctxutil"
+)
+
+// NewContext returns a context carrying l. Loggers derived from it with
+// FromContext keep its fields, so code handling a request logs the module
+// and intent set by the bot middleware without passing them around.
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return ctxutil.WithLogger(ctx, l.Logger)
+}
+
+// FromContext returns the logger stored by NewContext, or the default logger
+// (slog.Default) if there is none. Tracing values such as request_id and
+// chat_id are added by ContextHandler either way when logging with ctx.
+func FromContext(ctx context.Context) *Logger {
+	if l := ctxutil.GetLogger(ctx); l != nil {
+		return &Logger{Logger: l}
+	}
+	return &Logger{Logger: slog.Default()}
+}
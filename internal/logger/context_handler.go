@@ -3,6 +3,8 @@ package logger
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"log/slog"
 
 	"github.com/garyellow/ntpu-linebot-go/internal/ctxutil"
@@ -39,6 +41,7 @@ func (h *ContextHandler) Enabled(ctx context.Context, level slog.Level) bool {
 // Context values extracted:
 // - user_id: LINE user ID for user-specific operations and rate limiting
 // - chat_id: LINE chat ID (user, group, or room conversation)
+// - chat_hash: short hash of chat_id, for correlating a conversation in dashboards
 // - request_id: Request ID for log correlation and tracing
 // - event_id: LINE webhook event ID
 // - message_id: LINE message ID
@@ -53,7 +56,7 @@ func (h *ContextHandler) Handle(ctx context.Context, r slog.Record) error {
 
 	// Extract chatID from context
 	if chatID := ctxutil.GetChatID(ctx); chatID != "" {
-		r.AddAttrs(slog.String("chat_id", chatID))
+		r.AddAttrs(slog.String("chat_id", chatID), slog.String("chat_hash", shortHash(chatID)))
 	}
 
 	// Extract requestID from context
@@ -86,3 +89,10 @@ func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
 func (h *ContextHandler) WithGroup(name string) slog.Handler {
 	return &ContextHandler{handler: h.handler.WithGroup(name)}
 }
+
+// shortHash returns the first 8 hex digits of the SHA-256 of s: stable enough
+// to correlate log lines, too short to be worth reversing.
+func shortHash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:4])
+}
@@ -27,6 +27,7 @@ func TestContextHandler_Handle(t *testing.T) {
 			expectedFields: map[string]string{
 				"user_id":    "U12345",
 				"chat_id":    "C67890",
+				"chat_hash":  "66fedc06",
 				"request_id": "req-abc-123",
 			},
 		},
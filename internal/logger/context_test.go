@@ -0,0 +1,34 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/ctxutil"
+)
+
+func TestFromContext(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	log := NewWithWriter("info", &buf)
+
+	ctx := ctxutil.WithRequestID(context.Background(), "req-1")
+	ctx = NewContext(ctx, log.WithModule("course").WithField("intent", "search"))
+	FromContext(ctxutil.PreserveTracing(ctx)).InfoContext(ctx, "handled")
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Failed to parse JSON log: %v", err)
+	}
+	for key, want := range map[string]string{"module": "course", "intent": "search", "request_id": "req-1"} {
+		if entry[key] != want {
+			t.Errorf("entry[%q] = %v, want %q", key, entry[key], want)
+		}
+	}
+
+	if FromContext(context.Background()) == nil {
+		t.Error("FromContext() without a logger returned nil")
+	}
+}
@@ -0,0 +1,182 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"sync"
+)
+
+// ErrInvalidLevel is returned for level names other than debug, info, warn and error.
+var ErrInvalidLevel = errors.New("invalid log level")
+
+// ParseLevel parses a level name (debug, info, warn, error).
+// Unlike the lenient parsing of New, unknown names are an error.
+func ParseLevel(level string) (slog.Level, error) {
+	switch level {
+	case "debug", "info", "warn", "error":
+		return parseLevel(level), nil
+	default:
+		return 0, fmt.Errorf("%w: %q", ErrInvalidLevel, level)
+	}
+}
+
+// levelName is the inverse of ParseLevel.
+func levelName(level slog.Level) string {
+	switch {
+	case level < slog.LevelInfo:
+		return "debug"
+	case level < slog.LevelWarn:
+		return "info"
+	case level < slog.LevelError:
+		return "warn"
+	default:
+		return "error"
+	}
+}
+
+// Levels holds the minimum log level and its per-module overrides, shared by
+// a logger and every logger derived from it. Changes apply immediately.
+type Levels struct {
+	mu      sync.RWMutex
+	base    slog.Level
+	modules map[string]slog.Level
+	lowest  slog.Level // Lowest of base and the overrides
+}
+
+// NewLevels creates levels with base as the minimum level of every module.
+func NewLevels(base slog.Level) *Levels {
+	return &Levels{base: base, modules: make(map[string]slog.Level), lowest: base}
+}
+
+// Base returns the name of the level for modules without an override.
+func (l *Levels) Base() string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return levelName(l.base)
+}
+
+// SetModule overrides the minimum level of records with attribute module=name.
+func (l *Levels) SetModule(name, level string) error {
+	parsed, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.modules[name] = parsed
+	l.updateLowest()
+	return nil
+}
+
+// ResetModule removes the override of module name, if any.
+func (l *Levels) ResetModule(name string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.modules, name)
+	l.updateLowest()
+}
+
+// Modules returns the overridden modules and their level names.
+func (l *Levels) Modules() map[string]string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	result := make(map[string]string, len(l.modules))
+	for name, level := range l.modules {
+		result[name] = levelName(level)
+	}
+	return result
+}
+
+// level returns the minimum level of module ("" for records without one).
+func (l *Levels) level(module string) slog.Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if level, ok := l.modules[module]; ok && module != "" {
+		return level
+	}
+	return l.base
+}
+
+// lowestLevel returns the lowest level any module may log at.
+func (l *Levels) lowestLevel() slog.Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.lowest
+}
+
+// updateLowest recomputes lowest; the caller holds the write lock.
+func (l *Levels) updateLowest() {
+	l.lowest = l.base
+	for level := range maps.Values(l.modules) {
+		l.lowest = min(l.lowest, level)
+	}
+}
+
+// LevelHandler filters records by the level of their module attribute, so
+// one module can log at debug while the rest stay at info. The module comes
+// from WithAttrs (Logger.WithModule) or, failing that, from the record.
+type LevelHandler struct {
+	handler slog.Handler
+	levels  *Levels
+	module  string
+	grouped bool // Attributes added after WithGroup are not top-level
+}
+
+// NewLevelHandler creates a LevelHandler that wraps the provided handler.
+// The wrapped handler should accept every level; levels does the filtering.
+func NewLevelHandler(handler slog.Handler, levels *Levels) *LevelHandler {
+	return &LevelHandler{handler: handler, levels: levels}
+}
+
+// Enabled reports whether the handler handles records at the given level.
+// Without a known module, any level an override allows is enabled and
+// Handle drops the records of other modules.
+func (h *LevelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if h.module != "" {
+		return level >= h.levels.level(h.module) && h.handler.Enabled(ctx, level)
+	}
+	return level >= h.levels.lowestLevel() && h.handler.Enabled(ctx, level)
+}
+
+// Handle drops records below the level of their module.
+func (h *LevelHandler) Handle(ctx context.Context, r slog.Record) error {
+	module := h.module
+	if module == "" {
+		r.Attrs(func(a slog.Attr) bool {
+			if a.Key == "module" {
+				module = a.Value.String()
+				return false
+			}
+			return true
+		})
+	}
+	if r.Level < h.levels.level(module) {
+		return nil
+	}
+	return h.handler.Handle(ctx, r)
+}
+
+// WithAttrs returns a new LevelHandler that remembers a module attribute.
+func (h *LevelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.handler = h.handler.WithAttrs(attrs)
+	if !h.grouped {
+		for _, a := range attrs {
+			if a.Key == "module" {
+				clone.module = a.Value.String()
+			}
+		}
+	}
+	return &clone
+}
+
+// WithGroup returns a new LevelHandler with the given group name.
+func (h *LevelHandler) WithGroup(name string) slog.Handler {
+	clone := *h
+	clone.handler = h.handler.WithGroup(name)
+	clone.grouped = true
+	return &clone
+}
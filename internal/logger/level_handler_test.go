@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestModuleLevels(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	log := NewWithOptions("info", &buf, Options{ModuleLevels: map[string]string{"course": "debug", "id": "warn"}})
+
+	log.WithModule("course").Debug("course debug")
+	log.WithModule("id").Info("id info")
+	log.WithModule("id").Warn("id warn")
+	log.WithModule("contact").Debug("contact debug")
+	log.WithModule("contact").Info("contact info")
+	log.Debug("record debug", "module", "course")
+	log.Info("record info", "module", "id")
+	log.WithField("component", "x").Debug("plain debug")
+
+	got := buf.String()
+	for _, want := range []string{"course debug", "id warn", "contact info", "record debug"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in output:\n%s", want, got)
+		}
+	}
+	for _, unwanted := range []string{"id info", "contact debug", "record info", "plain debug"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("unexpected %q in output:\n%s", unwanted, got)
+		}
+	}
+}
+
+func TestLevels_RuntimeChanges(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	log := NewWithWriter("info", &buf)
+	course := log.WithModule("course")
+
+	course.Debug("before override")
+	if err := log.Levels().SetModule("course", "debug"); err != nil {
+		t.Fatalf("SetModule() error = %v", err)
+	}
+	course.Debug("during override")
+	log.Levels().ResetModule("course")
+	course.Debug("after reset")
+
+	got := buf.String()
+	if strings.Contains(got, "before override") || strings.Contains(got, "after reset") {
+		t.Errorf("debug logged without override:\n%s", got)
+	}
+	if !strings.Contains(got, "during override") {
+		t.Errorf("override not applied to an existing logger:\n%s", got)
+	}
+
+	if err := log.Levels().SetModule("course", "verbose"); !errors.Is(err, ErrInvalidLevel) {
+		t.Errorf("SetModule(verbose) error = %v, want ErrInvalidLevel", err)
+	}
+	if got := log.Levels().Modules(); len(got) != 0 {
+		t.Errorf("Modules() = %v, want none", got)
+	}
+	if got := log.Levels().Base(); got != "info" {
+		t.Errorf("Base() = %q, want info", got)
+	}
+}
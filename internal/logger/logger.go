@@ -24,6 +24,7 @@ import (
 //   - Error: request/task failures that could not be recovered within the current operation
 type Logger struct {
 	*slog.Logger
+	levels   *Levels
 	shutdown func(context.Context) error
 }
 
@@ -32,6 +33,16 @@ type Options struct {
 	BetterStackToken    string
 	BetterStackEndpoint string
 	Version             string
+	// ModuleLevels overrides the level per module (e.g., {"course": "debug"});
+	// invalid levels are skipped.
+	ModuleLevels map[string]string
+	// Sampling drops repetitive debug and info records (see SamplingHandler).
+	Sampling bool
+	// RedactPII masks student names and hashes student IDs (see RedactPII).
+	RedactPII bool
+	// Stream, when set, also receives every record for live subscribers,
+	// regardless of the levels (see Stream).
+	Stream *Stream
 }
 
 // New creates a new logger instance with JSON formatting
@@ -46,21 +57,31 @@ func NewWithWriter(level string, w io.Writer) *Logger {
 
 // NewWithOptions creates a new logger instance with configurable sinks.
 // When BetterStackToken is provided, logs are also sent to Better Stack.
+//
+// Records are filtered by Levels, which can be changed at runtime through
+// Logger.Levels; the sinks themselves accept every level.
 func NewWithOptions(level string, w io.Writer, opts Options) *Logger {
-	logLevel := parseLevel(level)
+	levels := NewLevels(parseLevel(level))
+	for module, moduleLevel := range opts.ModuleLevels {
+		_ = levels.SetModule(module, moduleLevel)
+	}
 	replaceAttr := replaceAttrFunc()
+	if opts.RedactPII {
+		replaceAttr = RedactPII(replaceAttr)
+	}
 
-	jsonHandler := slog.NewJSONHandler(w, &slog.HandlerOptions{
-		Level:       logLevel,
+	sinkOptions := &slog.HandlerOptions{
+		Level:       slog.LevelDebug,
 		AddSource:   true,
 		ReplaceAttr: replaceAttr,
-	})
+	}
+	jsonHandler := slog.NewJSONHandler(w, sinkOptions)
 
 	handlers := []slog.Handler{jsonHandler}
 	var asyncShutdown func(context.Context) error
 	if opts.BetterStackToken != "" {
 		bsOption := slogbetterstack.Option{
-			Level:       logLevel,
+			Level:       slog.LevelDebug,
 			Token:       opts.BetterStackToken,
 			Endpoint:    opts.BetterStackEndpoint,
 			Timeout:     5 * time.Second,
@@ -78,12 +99,21 @@ func NewWithOptions(level string, w io.Writer, opts Options) *Logger {
 		handler = NewMultiHandler(handlers...)
 	}
 
+	if opts.Sampling {
+		handler = NewSamplingHandler(handler, SamplingOptions{})
+	}
+
+	handler = NewLevelHandler(handler, levels)
+	if opts.Stream != nil {
+		handler = NewMultiHandler(handler, newStreamHandler(opts.Stream, sinkOptions))
+	}
+
 	contextHandler := NewContextHandler(handler)
 	baseLogger := slog.New(contextHandler)
 	if opts.Version != "" {
 		baseLogger = baseLogger.With("version", opts.Version)
 	}
-	return &Logger{Logger: baseLogger, shutdown: asyncShutdown}
+	return &Logger{Logger: baseLogger, levels: levels, shutdown: asyncShutdown}
 }
 
 func parseLevel(level string) slog.Level {
@@ -122,24 +152,35 @@ func replaceAttrFunc() func([]string, slog.Attr) slog.Attr {
 	}
 }
 
+// Levels returns the runtime-adjustable levels shared by this logger and
+// every logger derived from it; nil for loggers from FromContext.
+func (l *Logger) Levels() *Levels {
+	return l.levels
+}
+
+// with derives a logger that keeps the receiver's levels.
+func (l *Logger) with(args ...any) *Logger {
+	return &Logger{Logger: l.With(args...), levels: l.levels}
+}
+
 // WithModule creates a new entry with module field
 func (l *Logger) WithModule(module string) *Logger {
-	return &Logger{Logger: l.With("module", module)}
+	return l.with("module", module)
 }
 
 // WithRequestID creates a new entry with request ID field
 func (l *Logger) WithRequestID(requestID string) *Logger {
-	return &Logger{Logger: l.With("request_id", requestID)}
+	return l.with("request_id", requestID)
 }
 
 // WithError creates a new entry with error field
 func (l *Logger) WithError(err error) *Logger {
-	return &Logger{Logger: l.With("error", err)}
+	return l.with("error", err)
 }
 
 // WithField creates a new entry with a single field
 func (l *Logger) WithField(key string, value any) *Logger {
-	return &Logger{Logger: l.With(key, value)}
+	return l.with(key, value)
 }
 
 // WithFields creates a new entry with multiple fields
@@ -148,7 +189,7 @@ func (l *Logger) WithFields(fields map[string]any) *Logger {
 	for k, v := range fields {
 		args = append(args, k, v)
 	}
-	return &Logger{Logger: l.With(args...)}
+	return l.with(args...)
 }
 
 // Compatibility methods for logrus-style formatting
@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// Field keys redacted by RedactPII. Names are masked (王小明 → 王○明) and
+// student IDs are replaced with shortHash, so the same student still
+// correlates across log lines.
+var (
+	hashedKeys = map[string]bool{"student_id": true}
+	maskedKeys = map[string]bool{"student_name": true}
+	// Free text where student IDs are hashed in place (e.g., user messages)
+	textKeys = map[string]bool{"text": true}
+	// NLU params use the function argument names (see genai/functions.go)
+	paramAliases = map[string]string{"name": "student_name"}
+)
+
+// studentIDPattern matches 8-9 digit runs, the shape of NTPU student IDs.
+var studentIDPattern = regexp.MustCompile(`\b\d{8,9}\b`)
+
+// RedactPII wraps a ReplaceAttr function so it first redacts student names
+// and IDs in known field keys. Nested map[string]string values (e.g., NLU
+// params) are redacted by the same keys.
+func RedactPII(next func([]string, slog.Attr) slog.Attr) func([]string, slog.Attr) slog.Attr {
+	return func(groups []string, a slog.Attr) slog.Attr {
+		a = redactAttr(a)
+		if next == nil {
+			return a
+		}
+		return next(groups, a)
+	}
+}
+
+func redactAttr(a slog.Attr) slog.Attr {
+	switch {
+	case hashedKeys[a.Key], maskedKeys[a.Key], textKeys[a.Key]:
+		return slog.String(a.Key, redactValue(a.Key, a.Value.Resolve().String()))
+	case a.Value.Kind() == slog.KindAny:
+		if params, ok := a.Value.Any().(map[string]string); ok {
+			redacted := make(map[string]string, len(params))
+			for k, v := range params {
+				key := k
+				if alias, ok := paramAliases[k]; ok {
+					key = alias
+				}
+				redacted[k] = redactValue(key, v)
+			}
+			return slog.Any(a.Key, redacted)
+		}
+	}
+	return a
+}
+
+// redactValue redacts value as the field key calls for.
+func redactValue(key, value string) string {
+	switch {
+	case value == "":
+		return value
+	case hashedKeys[key]:
+		return shortHash(value)
+	case maskedKeys[key]:
+		return MaskName(value)
+	case textKeys[key]:
+		return studentIDPattern.ReplaceAllStringFunc(value, shortHash)
+	default:
+		return value
+	}
+}
+
+// MaskName keeps the first and last characters of a name and masks the rest
+// with ○: 王小明 → 王○明, 歐陽小明 → 歐○○明, 王明 → 王○.
+func MaskName(name string) string {
+	runes := []rune(strings.TrimSpace(name))
+	switch len(runes) {
+	case 0:
+		return ""
+	case 1:
+		return "○"
+	case 2:
+		return string(runes[0]) + "○"
+	default:
+		return string(runes[0]) + strings.Repeat("○", len(runes)-2) + string(runes[len(runes)-1])
+	}
+}
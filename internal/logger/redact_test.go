@@ -0,0 +1,69 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestMaskName(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"王小明", "王○明"},
+		{"歐陽小明", "歐○○明"},
+		{"王明", "王○"},
+		{"明", "○"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := MaskName(tt.name); got != tt.want {
+				t.Errorf("MaskName(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactPII(t *testing.T) {
+	t.Parallel()
+	logLine := func(redact bool) map[string]any {
+		var buf bytes.Buffer
+		log := NewWithOptions("info", &buf, Options{RedactPII: redact})
+		log.WithField("student_id", "412345678").
+			WithField("student_name", "王小明").
+			WithField("text", "學號 412345678 的資料").
+			WithField("params", map[string]string{"name": "王小明", "department": "資工"}).
+			WithField("name", "backup.db").
+			Info("lookup")
+		var entry map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+			t.Fatalf("Failed to parse JSON log: %v", err)
+		}
+		return entry
+	}
+
+	redacted := logLine(true)
+	hash := shortHash("412345678")
+	want := map[string]any{
+		"student_id":   hash,
+		"student_name": "王○明",
+		"text":         "學號 " + hash + " 的資料",
+		"params":       map[string]any{"name": "王○明", "department": "資工"},
+		"name":         "backup.db",
+	}
+	for key, value := range want {
+		got, _ := json.Marshal(redacted[key])
+		expected, _ := json.Marshal(value)
+		if string(got) != string(expected) {
+			t.Errorf("redacted[%q] = %s, want %s", key, got, expected)
+		}
+	}
+
+	if plain := logLine(false); plain["student_id"] != "412345678" || plain["student_name"] != "王小明" {
+		t.Errorf("without RedactPII fields changed: %v", plain)
+	}
+}
@@ -0,0 +1,111 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	defaultSamplingTick       = time.Second
+	defaultSamplingFirst      = 10
+	defaultSamplingThereafter = 100
+)
+
+// SamplingOptions configures log sampling. Within each Tick, the first
+// First records with the same level and message are logged, then every
+// Thereafter-th one.
+type SamplingOptions struct {
+	Tick       time.Duration
+	First      int
+	Thereafter int
+	// Now returns the current time (for tests); defaults to time.Now.
+	Now func() time.Time
+}
+
+// sampler counts records per level and message in the current tick. It is
+// shared by a SamplingHandler and the handlers derived from it.
+type sampler struct {
+	mu         sync.Mutex
+	tick       time.Duration
+	first      int
+	thereafter int
+	now        func() time.Time
+	start      time.Time
+	counts     map[sampleKey]int
+}
+
+type sampleKey struct {
+	level   slog.Level
+	message string
+}
+
+// allow reports whether the record should be logged.
+func (s *sampler) allow(r slog.Record) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now := s.now(); now.Sub(s.start) >= s.tick {
+		s.start = now
+		clear(s.counts)
+	}
+	key := sampleKey{level: r.Level, message: r.Message}
+	s.counts[key]++
+	n := s.counts[key]
+	return n <= s.first || (n-s.first)%s.thereafter == 0
+}
+
+// SamplingHandler drops repetitive records below warn level, such as cache
+// miss logs under heavy traffic. Warnings and errors are never sampled.
+type SamplingHandler struct {
+	handler slog.Handler
+	sampler *sampler
+}
+
+// NewSamplingHandler creates a SamplingHandler that wraps the provided handler.
+func NewSamplingHandler(handler slog.Handler, opts SamplingOptions) *SamplingHandler {
+	s := &sampler{
+		tick:       opts.Tick,
+		first:      opts.First,
+		thereafter: opts.Thereafter,
+		now:        opts.Now,
+		counts:     make(map[sampleKey]int),
+	}
+	if s.tick <= 0 {
+		s.tick = defaultSamplingTick
+	}
+	if s.first <= 0 {
+		s.first = defaultSamplingFirst
+	}
+	if s.thereafter <= 0 {
+		s.thereafter = defaultSamplingThereafter
+	}
+	if s.now == nil {
+		s.now = time.Now
+	}
+	return &SamplingHandler{handler: handler, sampler: s}
+}
+
+// Enabled delegates to the wrapped handler.
+func (h *SamplingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+// Handle passes the record on unless it is sampled out.
+func (h *SamplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < slog.LevelWarn && !h.sampler.allow(r) {
+		return nil
+	}
+	return h.handler.Handle(ctx, r)
+}
+
+// WithAttrs returns a new SamplingHandler sharing the receiver's counts.
+func (h *SamplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &SamplingHandler{handler: h.handler.WithAttrs(attrs), sampler: h.sampler}
+}
+
+// WithGroup returns a new SamplingHandler sharing the receiver's counts.
+func (h *SamplingHandler) WithGroup(name string) slog.Handler {
+	return &SamplingHandler{handler: h.handler.WithGroup(name), sampler: h.sampler}
+}
@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSamplingHandler(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	now := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	handler := NewSamplingHandler(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}), SamplingOptions{
+		Tick:       time.Second,
+		First:      2,
+		Thereafter: 3,
+		Now:        func() time.Time { return now },
+	})
+	log := slog.New(handler)
+	count := func(msg string) int {
+		return strings.Count(buf.String(), `"msg":"`+msg+`"`)
+	}
+
+	for range 8 {
+		log.Debug("cache miss")
+		log.With("module", "id").Debug("cache miss") // Derived loggers share counts
+		log.Warn("scrape failed")
+	}
+	// 16 misses: the first 2, then every 3rd (5, 8, 11, 14)
+	if got := count("cache miss"); got != 6 {
+		t.Errorf("cache miss logged %d times, want 6", got)
+	}
+	if got := count("scrape failed"); got != 8 {
+		t.Errorf("warnings logged %d times, want all 8", got)
+	}
+
+	now = now.Add(time.Second)
+	log.Debug("cache miss")
+	if got := count("cache miss"); got != 7 {
+		t.Errorf("cache miss logged %d times after a new tick, want 7", got)
+	}
+}
@@ -0,0 +1,147 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+// defaultStreamBuffer is the number of records a subscriber may fall behind
+// before records are dropped.
+const defaultStreamBuffer = 256
+
+// Stream broadcasts log records as JSON lines to live subscribers, such as
+// the admin console. It sees records before the configured levels filter
+// them, so a subscriber can watch debug records without changing what goes
+// to the log sinks. Records are only formatted while someone is subscribed,
+// and a subscriber that falls behind loses records instead of blocking the
+// logging goroutine.
+type Stream struct {
+	mu   sync.RWMutex
+	subs map[*Subscription]struct{}
+}
+
+// SubscribeOptions filters the records a Subscription receives.
+type SubscribeOptions struct {
+	Level  slog.Level // Minimum level
+	Module string     // Only records with this module attribute; "" for all
+	Buffer int        // Channel capacity; defaults to 256
+}
+
+// Subscription receives the JSON lines of matching records on C until closed.
+type Subscription struct {
+	C <-chan []byte
+
+	ch      chan []byte
+	opts    SubscribeOptions
+	dropped atomic.Int64
+	stream  *Stream
+}
+
+// NewStream creates a Stream without subscribers.
+func NewStream() *Stream {
+	return &Stream{subs: make(map[*Subscription]struct{})}
+}
+
+// Subscribe starts receiving records. Close the subscription when done.
+func (s *Stream) Subscribe(opts SubscribeOptions) *Subscription {
+	if opts.Buffer <= 0 {
+		opts.Buffer = defaultStreamBuffer
+	}
+	ch := make(chan []byte, opts.Buffer)
+	sub := &Subscription{C: ch, ch: ch, opts: opts, stream: s}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subs[sub] = struct{}{}
+	return sub
+}
+
+// Close stops the subscription and closes C.
+func (sub *Subscription) Close() {
+	s := sub.stream
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subs[sub]; ok {
+		delete(s.subs, sub)
+		close(sub.ch)
+	}
+}
+
+// TakeDropped returns the number of records dropped since the last call
+// because C was full.
+func (sub *Subscription) TakeDropped() int64 {
+	return sub.dropped.Swap(0)
+}
+
+// enabled reports whether any subscriber wants records at level.
+func (s *Stream) enabled(level slog.Level) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for sub := range s.subs {
+		if level >= sub.opts.Level {
+			return true
+		}
+	}
+	return false
+}
+
+// Write delivers one JSON line written by the stream's slog handler to the
+// matching subscribers.
+func (s *Stream) Write(p []byte) (int, error) {
+	var fields struct {
+		Level  string `json:"level"`
+		Module string `json:"module"`
+	}
+	_ = json.Unmarshal(p, &fields)
+	level, err := ParseLevel(fields.Level)
+	if fields.Level == "warning" {
+		level, err = slog.LevelWarn, nil
+	}
+	if err != nil {
+		level = slog.LevelInfo
+	}
+	line := bytes.TrimSpace(bytes.Clone(p))
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for sub := range s.subs {
+		if level < sub.opts.Level || (sub.opts.Module != "" && fields.Module != sub.opts.Module) {
+			continue
+		}
+		select {
+		case sub.ch <- line:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+	return len(p), nil
+}
+
+// streamHandler formats records for a Stream while it has subscribers.
+type streamHandler struct {
+	handler slog.Handler
+	stream  *Stream
+}
+
+func newStreamHandler(stream *Stream, opts *slog.HandlerOptions) *streamHandler {
+	return &streamHandler{handler: slog.NewJSONHandler(stream, opts), stream: stream}
+}
+
+func (h *streamHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.stream.enabled(level)
+}
+
+func (h *streamHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.handler.Handle(ctx, r)
+}
+
+func (h *streamHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &streamHandler{handler: h.handler.WithAttrs(attrs), stream: h.stream}
+}
+
+func (h *streamHandler) WithGroup(name string) slog.Handler {
+	return &streamHandler{handler: h.handler.WithGroup(name), stream: h.stream}
+}
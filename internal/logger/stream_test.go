@@ -0,0 +1,63 @@
+package logger
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestStream(t *testing.T) {
+	t.Parallel()
+	var sink bytes.Buffer
+	stream := NewStream()
+	log := NewWithOptions("info", &sink, Options{Stream: stream, RedactPII: true})
+
+	log.Debug("before subscribing")
+	all := stream.Subscribe(SubscribeOptions{Level: slog.LevelDebug})
+	defer all.Close()
+	course := stream.Subscribe(SubscribeOptions{Level: slog.LevelDebug, Module: "course"})
+	defer course.Close()
+
+	log.WithModule("course").Debug("Course cache miss")
+	log.WithModule("id").WithField("student_id", "412345678").Warn("Student scrape failed")
+
+	if len(all.C) != 2 {
+		t.Fatalf("all subscriber got %d records, want 2", len(all.C))
+	}
+	if line := string(<-all.C); !strings.Contains(line, `"message":"Course cache miss"`) {
+		t.Errorf("first record = %s", line)
+	}
+	if line := string(<-all.C); strings.Contains(line, "412345678") || !strings.Contains(line, `"level":"warning"`) {
+		t.Errorf("second record = %s, want redacted warning", line)
+	}
+	if len(course.C) != 1 {
+		t.Errorf("module subscriber got %d records, want 1", len(course.C))
+	}
+	if strings.Contains(sink.String(), "cache miss") {
+		t.Error("debug record reached the info-level sink")
+	}
+}
+
+func TestStream_DropsWhenFull(t *testing.T) {
+	t.Parallel()
+	stream := NewStream()
+	log := NewWithOptions("info", &bytes.Buffer{}, Options{Stream: stream})
+	sub := stream.Subscribe(SubscribeOptions{Level: slog.LevelInfo, Buffer: 2})
+
+	for range 5 {
+		log.Info("busy")
+	}
+	if got := sub.TakeDropped(); got != 3 {
+		t.Errorf("TakeDropped() = %d, want 3", got)
+	}
+	if got := sub.TakeDropped(); got != 0 {
+		t.Errorf("TakeDropped() after take = %d, want 0", got)
+	}
+
+	sub.Close()
+	sub.Close() // Idempotent
+	if stream.enabled(slog.LevelError) {
+		t.Error("stream enabled without subscribers")
+	}
+}
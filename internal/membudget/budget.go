@@ -0,0 +1,217 @@
+// Package membudget caps the memory of in-memory indexes and caches: the
+// BM25 index and the course and program lists loaded for fuzzy matching.
+// These grow with the cached data rather than with traffic, so on a small
+// instance they can take most of the RAM before anything else notices.
+//
+// Consumers charge the Budget with the estimated size of each entry (a BM25
+// semester, a semester's course list) and touch entries when they use them.
+// When the total goes over the limit, the least recently used entries of
+// any consumer are evicted through the consumer's evict function.
+package membudget
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/metrics"
+)
+
+// Consumers of the budget, the consumer label of the memory budget metrics.
+const (
+	ConsumerBM25         = "bm25"          // BM25 semester indexes
+	ConsumerCourseLists  = "course_lists"  // Semester course lists for fuzzy matching
+	ConsumerProgramLists = "program_lists" // Program lists for fuzzy matching
+)
+
+// Budget tracks the memory charged by consumers and evicts the least
+// recently used entries beyond its limit. A nil Budget tracks nothing, so
+// consumers hold one unconditionally. It is safe for concurrent use.
+//
+// Evict functions run without the Budget's lock held, but Charge may call
+// any consumer's evict function: consumers must not hold their own lock
+// when calling Charge.
+type Budget struct {
+	limit int64 // Bytes; 0 measures usage without evicting
+
+	mu       sync.Mutex
+	order    *list.List // front = most recently used
+	entries  map[entryKey]*list.Element
+	used     map[string]int64
+	evictors map[string]func(key string)
+	metrics  *metrics.Metrics
+}
+
+type entryKey struct {
+	consumer string
+	key      string
+}
+
+type entry struct {
+	entryKey
+	size int64
+}
+
+// New creates a budget of limit bytes; 0 means no limit.
+func New(limit int64) *Budget {
+	return &Budget{
+		limit:    max(limit, 0),
+		order:    list.New(),
+		entries:  make(map[entryKey]*list.Element),
+		used:     make(map[string]int64),
+		evictors: make(map[string]func(string)),
+	}
+}
+
+// SetMetrics publishes the limit and per-consumer usage to m.
+func (b *Budget) SetMetrics(m *metrics.Metrics) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.metrics = m
+	if m != nil {
+		m.SetMemoryBudgetLimit(b.limit)
+		for consumer, used := range b.used {
+			m.SetMemoryBudgetUsed(consumer, used)
+		}
+	}
+}
+
+// Register sets the function that drops an entry of consumer when it is
+// evicted. The entry is already released when evict runs.
+func (b *Budget) Register(consumer string, evict func(key string)) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.evictors[consumer] = evict
+}
+
+// Limit returns the budget in bytes, 0 when unlimited.
+func (b *Budget) Limit() int64 {
+	if b == nil {
+		return 0
+	}
+	return b.limit
+}
+
+// Used returns the bytes charged by consumer.
+func (b *Budget) Used(consumer string) int64 {
+	if b == nil {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.used[consumer]
+}
+
+// Charge records size bytes for an entry of consumer, replacing an earlier
+// charge for the same key, and marks it most recently used. If the total is
+// then over the limit, other entries are evicted oldest first; the charged
+// entry itself is kept even when it alone exceeds the limit, since its
+// consumer is about to use it.
+func (b *Budget) Charge(consumer, key string, size int64) {
+	if b == nil {
+		return
+	}
+	k := entryKey{consumer: consumer, key: key}
+
+	b.mu.Lock()
+	if el, ok := b.entries[k]; ok {
+		e := el.Value.(*entry)
+		b.used[consumer] += size - e.size
+		e.size = size
+		b.order.MoveToFront(el)
+	} else {
+		b.entries[k] = b.order.PushFront(&entry{entryKey: k, size: size})
+		b.used[consumer] += size
+	}
+	evicted := b.evictOverLimit(k)
+	evictors := b.evictors
+	m := b.metrics
+	b.mu.Unlock()
+
+	b.publish(m, consumer, evicted)
+	for _, e := range evicted {
+		if evict := evictors[e.consumer]; evict != nil {
+			evict(e.key)
+		}
+	}
+}
+
+// evictOverLimit releases the least recently used entries other than keep
+// until the total fits the limit. It must be called with b.mu held.
+func (b *Budget) evictOverLimit(keep entryKey) []*entry {
+	if b.limit == 0 {
+		return nil
+	}
+	var total int64
+	for _, used := range b.used {
+		total += used
+	}
+	var evicted []*entry
+	for el := b.order.Back(); el != nil && total > b.limit; {
+		prev := el.Prev()
+		if e := el.Value.(*entry); e.entryKey != keep {
+			b.remove(el)
+			total -= e.size
+			evicted = append(evicted, e)
+		}
+		el = prev
+	}
+	return evicted
+}
+
+// Touch marks an entry most recently used. Unknown entries are ignored.
+func (b *Budget) Touch(consumer, key string) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if el, ok := b.entries[entryKey{consumer: consumer, key: key}]; ok {
+		b.order.MoveToFront(el)
+	}
+}
+
+// Release drops the charge of an entry its consumer no longer holds.
+func (b *Budget) Release(consumer, key string) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	el, ok := b.entries[entryKey{consumer: consumer, key: key}]
+	if ok {
+		b.remove(el)
+	}
+	m := b.metrics
+	b.mu.Unlock()
+	if ok {
+		b.publish(m, consumer, nil)
+	}
+}
+
+// remove drops an entry and its charge. It must be called with b.mu held.
+func (b *Budget) remove(el *list.Element) {
+	e := b.order.Remove(el).(*entry)
+	delete(b.entries, e.entryKey)
+	b.used[e.consumer] -= e.size
+}
+
+// publish updates the usage gauges of consumer and the evicted entries'
+// consumers, and counts the evictions.
+func (b *Budget) publish(m *metrics.Metrics, consumer string, evicted []*entry) {
+	if m == nil {
+		return
+	}
+	consumers := map[string]bool{consumer: true}
+	for _, e := range evicted {
+		consumers[e.consumer] = true
+		m.RecordMemoryBudgetEviction(e.consumer)
+	}
+	for c := range consumers {
+		m.SetMemoryBudgetUsed(c, b.Used(c))
+	}
+}
@@ -0,0 +1,164 @@
+package membudget
+
+import (
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// gathered returns the value of the metric name whose labels include value,
+// or -1 when there is no such series.
+func gathered(t *testing.T, registry *prometheus.Registry, name, value string) float64 {
+	t.Helper()
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	for _, mf := range families {
+		if mf.GetName() != name {
+			continue
+		}
+		for _, series := range mf.GetMetric() {
+			if value != "" && (len(series.GetLabel()) == 0 || series.GetLabel()[0].GetValue() != value) {
+				continue
+			}
+			if series.GetCounter() != nil {
+				return series.GetCounter().GetValue()
+			}
+			return series.GetGauge().GetValue()
+		}
+	}
+	return -1
+}
+
+// evictLog records the keys a consumer was asked to evict.
+type evictLog struct {
+	mu   sync.Mutex
+	keys []string
+}
+
+func (l *evictLog) evict(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.keys = append(l.keys, key)
+}
+
+func TestBudget_EvictsLeastRecentlyUsed(t *testing.T) {
+	t.Parallel()
+	registry := prometheus.NewRegistry()
+	m := metrics.New(registry)
+	b := New(100)
+	b.SetMetrics(m)
+	var bm25, courses evictLog
+	b.Register(ConsumerBM25, bm25.evict)
+	b.Register(ConsumerCourseLists, courses.evict)
+
+	b.Charge(ConsumerBM25, "113-1", 40)
+	b.Charge(ConsumerBM25, "113-2", 40)
+	b.Charge(ConsumerCourseLists, "113-2", 10)
+	b.Touch(ConsumerBM25, "113-1") // 113-2 is now the oldest BM25 semester
+
+	b.Charge(ConsumerCourseLists, "114-1", 30)
+
+	if want := []string{"113-2"}; !slices.Equal(bm25.keys, want) {
+		t.Errorf("bm25 evicted %v, want %v", bm25.keys, want)
+	}
+	if len(courses.keys) != 0 {
+		t.Errorf("course lists evicted %v, want none", courses.keys)
+	}
+	if got := b.Used(ConsumerBM25); got != 40 {
+		t.Errorf("Used(bm25) = %d, want 40", got)
+	}
+	if got := gathered(t, registry, "ntpu_memory_budget_used_bytes", ConsumerBM25); got != 40 {
+		t.Errorf("ntpu_memory_budget_used_bytes{consumer=bm25} = %v, want 40", got)
+	}
+	if got := gathered(t, registry, "ntpu_memory_budget_evictions_total", ConsumerBM25); got != 1 {
+		t.Errorf("ntpu_memory_budget_evictions_total{consumer=bm25} = %v, want 1", got)
+	}
+	if got := gathered(t, registry, "ntpu_memory_budget_limit_bytes", ""); got != 100 {
+		t.Errorf("ntpu_memory_budget_limit_bytes = %v, want 100", got)
+	}
+}
+
+func TestBudget_KeepsOversizedEntry(t *testing.T) {
+	t.Parallel()
+	b := New(100)
+	var log evictLog
+	b.Register(ConsumerBM25, log.evict)
+
+	b.Charge(ConsumerBM25, "113-1", 50)
+	b.Charge(ConsumerBM25, "113-2", 150)
+
+	if want := []string{"113-1"}; !slices.Equal(log.keys, want) {
+		t.Errorf("evicted %v, want %v", log.keys, want)
+	}
+	if got := b.Used(ConsumerBM25); got != 150 {
+		t.Errorf("Used() = %d, want the oversized entry kept", got)
+	}
+}
+
+func TestBudget_ChargeReplacesAndRelease(t *testing.T) {
+	t.Parallel()
+	b := New(0)
+	var log evictLog
+	b.Register(ConsumerProgramLists, log.evict)
+
+	b.Charge(ConsumerProgramLists, "all", 500)
+	b.Charge(ConsumerProgramLists, "all", 300)
+	b.Charge(ConsumerProgramLists, "recent", 1<<30)
+	if got := b.Used(ConsumerProgramLists); got != 300+1<<30 {
+		t.Errorf("Used() = %d, want %d", got, 300+1<<30)
+	}
+	if len(log.keys) != 0 {
+		t.Errorf("unlimited budget evicted %v", log.keys)
+	}
+
+	b.Release(ConsumerProgramLists, "recent")
+	b.Release(ConsumerProgramLists, "unknown")
+	if got := b.Used(ConsumerProgramLists); got != 300 {
+		t.Errorf("Used() after Release = %d, want 300", got)
+	}
+}
+
+func TestBudget_Nil(t *testing.T) {
+	t.Parallel()
+	var b *Budget
+	b.Register(ConsumerBM25, func(string) { t.Error("nil budget evicted") })
+	b.SetMetrics(nil)
+	b.Charge(ConsumerBM25, "113-1", 1<<40)
+	b.Touch(ConsumerBM25, "113-1")
+	b.Release(ConsumerBM25, "113-1")
+	if b.Used(ConsumerBM25) != 0 || b.Limit() != 0 {
+		t.Error("nil budget reports usage")
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	t.Parallel()
+
+	type doc struct {
+		Title    string
+		Teachers []string
+		Scores   map[string]float64
+	}
+	small := Estimate(doc{Title: "a"})
+	large := Estimate(doc{
+		Title:    "線性代數與應用",
+		Teachers: []string{"王小明", "陳大文"},
+		Scores:   map[string]float64{"線性": 1.5, "代數": 2.5},
+	})
+	if small <= 0 || large <= small {
+		t.Errorf("Estimate() = %d (small), %d (large); want 0 < small < large", small, large)
+	}
+
+	shared := &doc{Title: "shared title"}
+	if once, twice := Estimate([]*doc{shared}), Estimate([]*doc{shared, shared}); twice-once > 16 {
+		t.Errorf("shared pointer counted twice: %d vs %d bytes", once, twice)
+	}
+	if got := Estimate(nil); got != 0 {
+		t.Errorf("Estimate(nil) = %d, want 0", got)
+	}
+}
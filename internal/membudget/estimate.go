@@ -0,0 +1,101 @@
+package membudget
+
+import "reflect"
+
+// mapEntryOverhead approximates the per-entry cost of a Go map beyond its
+// keys and values (bucket slots, tophash, and load factor headroom).
+const mapEntryOverhead = 16
+
+// Estimate returns the approximate heap bytes held by v: the value itself
+// and everything reachable through its pointers, slices, maps, and strings.
+// Shared pointers are counted once. It is meant for sizing cached entries,
+// not for exact accounting, and walks the whole value, so call it once per
+// entry rather than per request.
+func Estimate(v any) int64 {
+	if v == nil {
+		return 0
+	}
+	rv := reflect.ValueOf(v)
+	return int64(rv.Type().Size()) + int64(deepSize(rv, make(map[uintptr]bool)))
+}
+
+// deepSize returns the bytes v references beyond its own inline size.
+func deepSize(v reflect.Value, seen map[uintptr]bool) uintptr {
+	switch v.Kind() {
+	case reflect.String:
+		return uintptr(v.Len())
+	case reflect.Pointer:
+		if v.IsNil() || seen[v.Pointer()] {
+			return 0
+		}
+		seen[v.Pointer()] = true
+		return v.Type().Elem().Size() + deepSize(v.Elem(), seen)
+	case reflect.Interface:
+		if v.IsNil() {
+			return 0
+		}
+		elem := v.Elem()
+		return elem.Type().Size() + deepSize(elem, seen)
+	case reflect.Slice:
+		if v.IsNil() || seen[v.Pointer()] {
+			return 0
+		}
+		seen[v.Pointer()] = true
+		size := uintptr(v.Cap()) * v.Type().Elem().Size()
+		if hasReferences(v.Type().Elem()) {
+			for i := range v.Len() {
+				size += deepSize(v.Index(i), seen)
+			}
+		}
+		return size
+	case reflect.Array:
+		var size uintptr
+		if hasReferences(v.Type().Elem()) {
+			for i := range v.Len() {
+				size += deepSize(v.Index(i), seen)
+			}
+		}
+		return size
+	case reflect.Map:
+		if v.IsNil() {
+			return 0
+		}
+		t := v.Type()
+		size := uintptr(v.Len()) * (t.Key().Size() + t.Elem().Size() + mapEntryOverhead)
+		if hasReferences(t.Key()) || hasReferences(t.Elem()) {
+			iter := v.MapRange()
+			for iter.Next() {
+				size += deepSize(iter.Key(), seen) + deepSize(iter.Value(), seen)
+			}
+		}
+		return size
+	case reflect.Struct:
+		var size uintptr
+		for i := range v.NumField() {
+			size += deepSize(v.Field(i), seen)
+		}
+		return size
+	default:
+		return 0
+	}
+}
+
+// hasReferences reports whether values of t can reference heap memory, so
+// walking a large []int or map[string]float64's values can be skipped.
+func hasReferences(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Map:
+		return true
+	case reflect.Array:
+		return hasReferences(t.Elem())
+	case reflect.Struct:
+		for i := range t.NumField() {
+			if hasReferences(t.Field(i).Type) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
@@ -11,8 +11,12 @@
 package metrics
 
 import (
+	"context"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/garyellow/ntpu-linebot-go/internal/ctxutil"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 )
@@ -31,6 +35,9 @@ var (
 
 	// LLMCooldownTotal is the global LLM cooldown event counter.
 	LLMCooldownTotal *prometheus.CounterVec
+
+	// LLMOutputFiltered is the global LLM output guard counter.
+	LLMOutputFiltered *prometheus.CounterVec
 )
 
 // InitGlobal initializes the package-level metric variables.
@@ -40,6 +47,7 @@ func InitGlobal(m *Metrics) {
 	LLMDuration = m.LLMDuration
 	LLMFallbackTotal = m.LLMFallbackTotal
 	LLMCooldownTotal = m.LLMCooldownTotal
+	LLMOutputFiltered = m.LLMOutputFiltered
 }
 
 // Metrics holds all Prometheus metrics for the NTPU LineBot.
@@ -63,10 +71,20 @@ type Metrics struct {
 	// Rate: requests per second by event type
 	// Errors: tracked via status label (success/error)
 	// Duration: handler processing time before LINE reply API call
-	WebhookTotal      *prometheus.CounterVec
-	WebhookDuration   *prometheus.HistogramVec
-	LineReplyTotal    *prometheus.CounterVec
-	LineReplyDuration *prometheus.HistogramVec
+	WebhookTotal    *prometheus.CounterVec
+	WebhookDuration *prometheus.HistogramVec
+	// End to end: receipt to reply sent, by answering module and outcome
+	WebhookEventDuration *prometheus.HistogramVec
+	LineReplyTotal       *prometheus.CounterVec
+	LineReplyDuration    *prometheus.HistogramVec
+
+	// ============================================
+	// LINE Messaging API (lineapi client - RED/USE Method)
+	// Outbound calls and monthly push quota
+	// ============================================
+	LineAPITotal   *prometheus.CounterVec
+	LineAPIRetries *prometheus.CounterVec
+	LineQuota      *prometheus.GaugeVec // monthly message quota: limit, used, remaining
 
 	// ============================================
 	// Scraper (External HTTP Calls - RED Method)
@@ -74,22 +92,28 @@ type Metrics struct {
 	// ============================================
 	ScraperTotal    *prometheus.CounterVec
 	ScraperDuration *prometheus.HistogramVec
+	ScraperDrift    *prometheus.CounterVec // page structure mismatches by parser
 
 	// ============================================
 	// Cache (SQLite - USE Method)
 	// Local data cache layer
 	// ============================================
-	CacheOperations *prometheus.CounterVec // hit/miss by module
-	CacheSize       *prometheus.GaugeVec   // current entries by module
+	CacheOperations   *prometheus.CounterVec // hit/miss by module
+	CacheSize         *prometheus.GaugeVec   // current entries by module
+	SearchResultCache *prometheus.CounterVec // repeated searches answered from remembered results, hit/miss by module
+	IntegrityIssues   *prometheus.GaugeVec   // anomalous rows found by the last integrity check
+	CleanupDeleted    *prometheus.CounterVec // expired rows deleted by the cleanup task, by table
+	DBErrors          *prometheus.CounterVec // failed queries by operation
 
 	// ============================================
 	// LLM (Gemini/Groq/Cerebras API - RED Method)
 	// NLU intent parsing, Query Expansion
 	// ============================================
-	LLMTotal         *prometheus.CounterVec   // requests by provider, model, operation, and status
-	LLMDuration      *prometheus.HistogramVec // latency by provider, model, and operation
-	LLMFallbackTotal *prometheus.CounterVec   // fallback transitions by provider/model and operation
-	LLMCooldownTotal *prometheus.CounterVec   // cooldown events by provider, model, kind, and action
+	LLMTotal          *prometheus.CounterVec   // requests by provider, model, operation, and status
+	LLMDuration       *prometheus.HistogramVec // latency by provider, model, and operation
+	LLMFallbackTotal  *prometheus.CounterVec   // fallback transitions by provider/model and operation
+	LLMCooldownTotal  *prometheus.CounterVec   // cooldown events by provider, model, kind, and action
+	LLMOutputFiltered *prometheus.CounterVec   // model output rejected or cleaned by the output guard
 
 	// ============================================
 	// Smart Search (BM25 - RED Method)
@@ -102,11 +126,34 @@ type Metrics struct {
 	// Index sizes (Gauges - point-in-time values)
 	IndexSize *prometheus.GaugeVec // documents in BM25 index
 
+	// ============================================
+	// Business Signals (product decisions)
+	// What users got back, not how fast
+	// ============================================
+	SearchZeroResults     *prometheus.CounterVec // searches that found nothing, by module
+	ResultsTruncated      *prometheus.CounterVec // result sets cut to the display limit, by module
+	SearchFallback        *prometheus.CounterVec // smart searches that fell back to plain BM25, by reason
+	QueryExpansionSkipped *prometheus.CounterVec // smart searches that could not use LLM expansion, by reason
+	ExpansionPrompt       *prometheus.CounterVec // smart search results served and opened, by expansion prompt version
+	StaleCacheServed      *prometheus.CounterVec // cached data served past its TTL, by module
+
 	// ============================================
 	// Intent Distribution (NLU analysis)
 	// Tracks which intents are triggered and how
 	// ============================================
-	IntentTotal *prometheus.CounterVec // intent triggers by module, intent, source
+	IntentTotal          *prometheus.CounterVec // intent triggers by module, intent, source
+	IntentRouting        *prometheus.CounterVec // text messages by the modules whose CanHandle matched and the one chosen
+	IntentReformulations *prometheus.CounterVec // handled messages followed quickly by another text message, by module and source
+
+	// ============================================
+	// Module Calls (bot middleware - RED Method)
+	// Per-module handler invocations
+	// ============================================
+	ModuleTotal    *prometheus.CounterVec
+	ModuleDuration *prometheus.HistogramVec
+	CanaryTotal    *prometheus.CounterVec // canary rollout calls by arm
+	CanaryDiff     *prometheus.CounterVec // canary vs stable result comparisons
+	PostbackLegacy *prometheus.CounterVec // postbacks in formats of older releases
 
 	// ============================================
 	// Rate Limiter (USE Method)
@@ -122,6 +169,25 @@ type Metrics struct {
 	// ============================================
 	JobTotal    *prometheus.CounterVec
 	JobDuration *prometheus.HistogramVec
+
+	// ============================================
+	// Runtime Info (deploy correlation)
+	// Info metrics are constant 1; the labels carry the value
+	// ============================================
+	BuildInfo    *prometheus.GaugeVec // version, commit, go_version
+	ConfigInfo   *prometheus.GaugeVec // fingerprint of the effective configuration
+	StartTime    prometheus.Gauge     // unix seconds when metrics were created
+	Uptime       prometheus.GaugeFunc // seconds since StartTime
+	PoolActive   *prometheus.GaugeVec // running goroutines by pool
+	PoolCapacity *prometheus.GaugeVec // goroutine limit by pool (absent when unbounded)
+
+	// ============================================
+	// Memory Budget (USE Method)
+	// In-memory indexes and list caches (internal/membudget)
+	// ============================================
+	MemoryBudgetUsed      *prometheus.GaugeVec   // estimated bytes by consumer
+	MemoryBudgetLimit     prometheus.Gauge       // configured cap in bytes (0 = unlimited)
+	MemoryBudgetEvictions *prometheus.CounterVec // entries evicted to fit the cap, by consumer
 }
 
 // New creates a new Metrics instance with all metrics registered.
@@ -189,12 +255,28 @@ func New(registry *prometheus.Registry) *Metrics {
 			[]string{"event_type"},
 		),
 
+		WebhookEventDuration: promauto.With(registry).NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name: "ntpu_webhook_event_duration_seconds",
+				Help: "Time from webhook receipt to reply sent, by answering module",
+				// Extends past the reply token deadline (config.ReplyTokenTTL, 50s)
+				// so per-module p95 alerts can fire before replies turn into pushes
+				Buckets: []float64{0.25, 0.5, 1, 2, 5, 10, 20, 30, 40, 50, 60},
+			},
+			// module: handler name, or "none" (unhandled, follow/join)
+			// intent: NLU intent, or "" for keyword matches and postbacks
+			// cache: hit, miss, none
+			// messages: reply message count, "0" to "5"
+			[]string{"module", "intent", "cache", "messages"},
+		),
+
 		LineReplyTotal: promauto.With(registry).NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "ntpu_line_reply_total",
 				Help: "Total LINE reply outcomes",
 			},
-			// status: success, error, rate_limited, invalid_token, skipped_empty_token, skipped_invalid_token
+			// status: success, error, rate_limited, invalid_token, skipped_empty_token, skipped_invalid_token,
+			//         skipped_expired_token, push_fallback, push_error, push_quota_exhausted
 			[]string{"status"},
 		),
 
@@ -207,6 +289,37 @@ func New(registry *prometheus.Registry) *Metrics {
 			[]string{"status"},
 		),
 
+		// ============================================
+		// LINE Messaging API metrics
+		// ============================================
+		LineAPITotal: promauto.With(registry).NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "ntpu_line_api_total",
+				Help: "Total LINE Messaging API calls by final outcome",
+			},
+			// operation: reply, push, loading, quota
+			// status: success, error, rate_limited, server_error, invalid_token, quota_exhausted, dry_run
+			[]string{"operation", "status"},
+		),
+
+		LineAPIRetries: promauto.With(registry).NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "ntpu_line_api_retries_total",
+				Help: "Total LINE Messaging API retry attempts after 429/5xx",
+			},
+			// operation: reply, push, loading, quota
+			[]string{"operation"},
+		),
+
+		LineQuota: promauto.With(registry).NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "ntpu_line_quota_messages",
+				Help: "LINE monthly message quota (-1 limit/remaining = unlimited)",
+			},
+			// type: limit, used, remaining
+			[]string{"type"},
+		),
+
 		// ============================================
 		// Scraper metrics
 		// ============================================
@@ -232,6 +345,15 @@ func New(registry *prometheus.Registry) *Metrics {
 			[]string{"module"},
 		),
 
+		ScraperDrift: promauto.With(registry).NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "ntpu_scraper_drift_total",
+				Help: "Scraped pages whose structure no longer matches the parser",
+			},
+			// parser: course_list, student_list, contact_list, contact_directory, program_list
+			[]string{"parser"},
+		),
+
 		// ============================================
 		// Cache metrics
 		// ============================================
@@ -245,6 +367,16 @@ func New(registry *prometheus.Registry) *Metrics {
 			[]string{"module", "result"},
 		),
 
+		SearchResultCache: promauto.With(registry).NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "ntpu_search_result_cache_total",
+				Help: "Total keyword searches looked up in the search result cache",
+			},
+			// module: course
+			// result: hit, miss
+			[]string{"module", "result"},
+		),
+
 		CacheSize: promauto.With(registry).NewGaugeVec(
 			prometheus.GaugeOpts{
 				Name: "ntpu_cache_size",
@@ -253,6 +385,33 @@ func New(registry *prometheus.Registry) *Metrics {
 			[]string{"module"},
 		),
 
+		IntegrityIssues: promauto.With(registry).NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "ntpu_cache_integrity_issues",
+				Help: "Anomalous cache rows found by the last integrity check",
+			},
+			// check: course_no_teachers, student_bad_year, syllabus_orphan, historical_in_hot, orphan_link
+			[]string{"check"},
+		),
+
+		CleanupDeleted: promauto.With(registry).NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "ntpu_cache_cleanup_deleted_total",
+				Help: "Total expired cache rows deleted by the cleanup task",
+			},
+			// table: contacts, courses, syllabi, lookup_misses, ...
+			[]string{"table"},
+		),
+
+		DBErrors: promauto.With(registry).NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "ntpu_db_errors_total",
+				Help: "Total failed database queries",
+			},
+			// op: read, write
+			[]string{"op"},
+		),
+
 		// ============================================
 		// LLM metrics
 		// ============================================
@@ -307,6 +466,16 @@ func New(registry *prometheus.Registry) *Metrics {
 			[]string{"provider", "model", "kind", "action"},
 		),
 
+		LLMOutputFiltered: promauto.With(registry).NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "ntpu_llm_output_filtered_total",
+				Help: "Total LLM outputs rejected or cleaned before reaching users",
+			},
+			// operation: nlu, expander
+			// reason: kill_switch, url, pii, charset, empty, sanitized
+			[]string{"operation", "reason"},
+		),
+
 		// ============================================
 		// Smart Search metrics
 		// ============================================
@@ -352,6 +521,63 @@ func New(registry *prometheus.Registry) *Metrics {
 			[]string{"index"},
 		),
 
+		// ============================================
+		// Business signal metrics
+		// ============================================
+		SearchZeroResults: promauto.With(registry).NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "ntpu_search_zero_results_total",
+				Help: "Total searches that returned no results",
+			},
+			// module: course, id, contact, program
+			[]string{"module"},
+		),
+
+		ResultsTruncated: promauto.With(registry).NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "ntpu_results_truncated_total",
+				Help: "Total result sets truncated to the display limit",
+			},
+			// module: course, id, contact
+			[]string{"module"},
+		),
+
+		SearchFallback: promauto.With(registry).NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "ntpu_search_bm25_fallback_total",
+				Help: "Total smart searches that fell back to BM25 with the original query",
+			},
+			// reason: expansion_error
+			[]string{"reason"},
+		),
+
+		QueryExpansionSkipped: promauto.With(registry).NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "ntpu_query_expansion_skipped_total",
+				Help: "Total smart searches where LLM query expansion was skipped",
+			},
+			// reason: rate_limit
+			[]string{"reason"},
+		),
+
+		ExpansionPrompt: promauto.With(registry).NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "ntpu_query_expansion_prompt_total",
+				Help: "Total smart search result sets served and courses opened from them, by expansion prompt version",
+			},
+			// version: prompt version (builtin or NTPU_QUERY_EXPANSION_PROMPTS), event: served, click
+			[]string{"version", "event"},
+		),
+
+		StaleCacheServed: promauto.With(registry).NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "ntpu_cache_stale_served_total",
+				Help: "Total cache reads served after the entry's TTL expired",
+			},
+			// module: buzz
+			[]string{"module"},
+		),
+
 		// ============================================
 		// Intent Distribution metrics
 		// ============================================
@@ -365,6 +591,78 @@ func New(registry *prometheus.Registry) *Metrics {
 			// source: keyword (matched by CanHandle), nlu (matched by NLU intent parser)
 			[]string{"module", "intent", "source"},
 		),
+		IntentRouting: promauto.With(registry).NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "ntpu_intent_routing_total",
+				Help: "Total text messages by matching modules and chosen module",
+			},
+			// matched: modules whose CanHandle matched, in registration order, joined by "+" (e.g., "contact+course"), or "none"
+			// chosen: module that replied, or "none" (left to NLU or help)
+			[]string{"matched", "chosen"},
+		),
+		IntentReformulations: promauto.With(registry).NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "ntpu_intent_reformulations_total",
+				Help: "Total handled messages the user followed with another text message within the reformulation window",
+			},
+			// module: module that handled the reformulated message
+			// source: keyword (matched by CanHandle), nlu (matched by NLU intent parser)
+			[]string{"module", "source"},
+		),
+
+		// ============================================
+		// Module call metrics
+		// ============================================
+		ModuleTotal: promauto.With(registry).NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "ntpu_module_total",
+				Help: "Total module handler invocations",
+			},
+			// module: course, id, contact, program, usage
+			// kind: message, postback, intent
+			// status: success, empty, error
+			[]string{"module", "kind", "status"},
+		),
+
+		ModuleDuration: promauto.With(registry).NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name: "ntpu_module_duration_seconds",
+				Help: "Module handler duration in seconds",
+				// Cache hits: < 50ms, scraping: 1-10s, smart search with expansion: < 30s
+				Buckets: []float64{0.01, 0.05, 0.1, 0.5, 1, 2, 5, 10, 30},
+			},
+			[]string{"module", "kind"},
+		),
+
+		CanaryTotal: promauto.With(registry).NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "ntpu_canary_total",
+				Help: "Calls to modules under canary rollout by serving arm",
+			},
+			// module: module name
+			// arm: stable, canary
+			[]string{"module", "arm"},
+		),
+
+		CanaryDiff: promauto.With(registry).NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "ntpu_canary_diff_total",
+				Help: "Canary replies compared with the stable reply to the same call",
+			},
+			// module: module name
+			// result: same, different, canary_empty, stable_empty, canary_error, stable_error
+			[]string{"module", "result"},
+		),
+
+		PostbackLegacy: promauto.With(registry).NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "ntpu_postback_legacy_total",
+				Help: "Postbacks in formats of older releases, from buttons in chat history",
+			},
+			// module: module name
+			// format: unprefixed, positional
+			[]string{"module", "format"},
+		),
 
 		// ============================================
 		// Rate Limiter metrics
@@ -374,7 +672,7 @@ func New(registry *prometheus.Registry) *Metrics {
 				Name: "ntpu_rate_limiter_dropped_total",
 				Help: "Total requests dropped by rate limiter",
 			},
-			// limiter: user, global
+			// limiter: user, global, llm, module
 			[]string{"limiter"},
 		),
 
@@ -417,8 +715,87 @@ func New(registry *prometheus.Registry) *Metrics {
 			// module: id, contact, course, syllabus, total, all
 			[]string{"job", "module"},
 		),
+
+		// ============================================
+		// Runtime info metrics
+		// ============================================
+		BuildInfo: promauto.With(registry).NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "ntpu_build_info",
+				Help: "Build metadata of the running binary (always 1)",
+			},
+			[]string{"version", "commit", "go_version"},
+		),
+
+		ConfigInfo: promauto.With(registry).NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "ntpu_config_info",
+				Help: "Fingerprint of the effective configuration, secrets excluded (always 1)",
+			},
+			[]string{"fingerprint"},
+		),
+
+		StartTime: promauto.With(registry).NewGauge(
+			prometheus.GaugeOpts{
+				Name: "ntpu_start_time_seconds",
+				Help: "Unix time the application started",
+			},
+		),
+
+		PoolActive: promauto.With(registry).NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "ntpu_goroutine_pool_active",
+				Help: "Goroutines currently running in each pool",
+			},
+			// pool: webhook, jobs
+			[]string{"pool"},
+		),
+
+		PoolCapacity: promauto.With(registry).NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "ntpu_goroutine_pool_capacity",
+				Help: "Maximum goroutines each bounded pool runs at once",
+			},
+			// pool: jobs
+			[]string{"pool"},
+		),
+
+		MemoryBudgetUsed: promauto.With(registry).NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "ntpu_memory_budget_used_bytes",
+				Help: "Estimated bytes held by in-memory indexes and caches",
+			},
+			// consumer: bm25, course_lists, program_lists
+			[]string{"consumer"},
+		),
+
+		MemoryBudgetLimit: promauto.With(registry).NewGauge(
+			prometheus.GaugeOpts{
+				Name: "ntpu_memory_budget_limit_bytes",
+				Help: "Memory budget of in-memory indexes and caches (0 = unlimited)",
+			},
+		),
+
+		MemoryBudgetEvictions: promauto.With(registry).NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "ntpu_memory_budget_evictions_total",
+				Help: "Least recently used entries evicted to fit the memory budget",
+			},
+			// consumer: bm25, course_lists, program_lists
+			[]string{"consumer"},
+		),
 	}
 
+	start := time.Now()
+	m.StartTime.Set(float64(start.Unix()))
+	m.Uptime = promauto.With(registry).NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Name: "ntpu_uptime_seconds",
+			Help: "Seconds since the application started",
+		},
+		func() float64 { return time.Since(start).Seconds() },
+	)
+
 	return m
 }
 
@@ -451,12 +828,42 @@ func (m *Metrics) RecordWebhook(eventType, status string, duration float64) {
 	m.WebhookDuration.WithLabelValues(eventType).Observe(duration)
 }
 
+// RecordWebhookEvent records the end-to-end latency of one webhook event.
+// module is "none" when no module answered; cache is a ctxutil.Cache* value.
+func (m *Metrics) RecordWebhookEvent(module, intent, cache string, messages int, duration float64) {
+	m.WebhookEventDuration.WithLabelValues(module, intent, cache, strconv.Itoa(messages)).Observe(duration)
+}
+
 // RecordLineReply records a LINE reply API outcome.
 func (m *Metrics) RecordLineReply(status string, duration float64) {
 	m.LineReplyTotal.WithLabelValues(status).Inc()
 	m.LineReplyDuration.WithLabelValues(status).Observe(duration)
 }
 
+// RecordLineAPI records the final outcome of a LINE Messaging API call.
+// operation: reply, push, loading, quota
+// status: success, error, rate_limited, server_error, invalid_token, quota_exhausted
+func (m *Metrics) RecordLineAPI(operation, status string) {
+	m.LineAPITotal.WithLabelValues(operation, status).Inc()
+}
+
+// RecordLineAPIRetry records a retry attempt of a LINE Messaging API call.
+func (m *Metrics) RecordLineAPIRetry(operation string) {
+	m.LineAPIRetries.WithLabelValues(operation).Inc()
+}
+
+// SetLineQuota sets the monthly message quota gauges.
+// limit < 0 means unlimited; remaining is then reported as -1.
+func (m *Metrics) SetLineQuota(limit, used int64) {
+	remaining := int64(-1)
+	if limit >= 0 {
+		remaining = max(limit-used, 0)
+	}
+	m.LineQuota.WithLabelValues("limit").Set(float64(limit))
+	m.LineQuota.WithLabelValues("used").Set(float64(used))
+	m.LineQuota.WithLabelValues("remaining").Set(float64(remaining))
+}
+
 // ============================================
 // Scraper helpers
 // ============================================
@@ -469,18 +876,31 @@ func (m *Metrics) RecordScraper(module, status string, duration float64) {
 	m.ScraperDuration.WithLabelValues(module).Observe(duration)
 }
 
+// RecordScraperDrift records a page whose structure no longer matches its parser.
+func (m *Metrics) RecordScraperDrift(parser string) {
+	m.ScraperDrift.WithLabelValues(parser).Inc()
+}
+
 // ============================================
 // Cache helpers
 // ============================================
 
-// RecordCacheHit records a cache hit.
-func (m *Metrics) RecordCacheHit(module string) {
+// RecordCacheHit records a cache hit, and marks it on the webhook event in ctx.
+func (m *Metrics) RecordCacheHit(ctx context.Context, module string) {
 	m.CacheOperations.WithLabelValues(module, "hit").Inc()
+	ctxutil.GetEventTrace(ctx).MarkCache(true)
 }
 
-// RecordCacheMiss records a cache miss.
-func (m *Metrics) RecordCacheMiss(module string) {
+// RecordCacheMiss records a cache miss, and marks it on the webhook event in ctx.
+func (m *Metrics) RecordCacheMiss(ctx context.Context, module string) {
 	m.CacheOperations.WithLabelValues(module, "miss").Inc()
+	ctxutil.GetEventTrace(ctx).MarkCache(false)
+}
+
+// RecordSearchResultCache records a keyword search looked up in a search result cache.
+// result: hit, miss
+func (m *Metrics) RecordSearchResultCache(module, result string) {
+	m.SearchResultCache.WithLabelValues(module, result).Inc()
 }
 
 // SetCacheSize sets the current cache size for a module.
@@ -488,6 +908,22 @@ func (m *Metrics) SetCacheSize(module string, size int) {
 	m.CacheSize.WithLabelValues(module).Set(float64(size))
 }
 
+// SetIntegrityIssues sets the anomalous rows found by an integrity check.
+func (m *Metrics) SetIntegrityIssues(check string, count int64) {
+	m.IntegrityIssues.WithLabelValues(check).Set(float64(count))
+}
+
+// RecordCleanupDeleted records the expired rows of a table deleted by the cleanup task.
+func (m *Metrics) RecordCleanupDeleted(table string, deleted int64) {
+	m.CleanupDeleted.WithLabelValues(table).Add(float64(deleted))
+}
+
+// RecordDBError records a failed database query.
+// op: read, write
+func (m *Metrics) RecordDBError(op string) {
+	m.DBErrors.WithLabelValues(op).Inc()
+}
+
 // ============================================
 // LLM helpers
 // ============================================
@@ -525,6 +961,47 @@ func (m *Metrics) SetIndexSize(index string, count int) {
 	m.IndexSize.WithLabelValues(index).Set(float64(count))
 }
 
+// ============================================
+// Business signal helpers
+// ============================================
+
+// RecordZeroResults records a search that found nothing.
+// module: course, id, contact, program
+func (m *Metrics) RecordZeroResults(module string) {
+	m.SearchZeroResults.WithLabelValues(module).Inc()
+}
+
+// RecordTruncated records a result set cut to the display limit.
+// module: course, id, contact
+func (m *Metrics) RecordTruncated(module string) {
+	m.ResultsTruncated.WithLabelValues(module).Inc()
+}
+
+// RecordSearchFallback records a smart search that fell back to BM25 with the original query.
+// reason: expansion_error
+func (m *Metrics) RecordSearchFallback(reason string) {
+	m.SearchFallback.WithLabelValues(reason).Inc()
+}
+
+// RecordQueryExpansionSkipped records a smart search that could not use LLM query expansion.
+// reason: rate_limit
+func (m *Metrics) RecordQueryExpansionSkipped(reason string) {
+	m.QueryExpansionSkipped.WithLabelValues(reason).Inc()
+}
+
+// RecordExpansionPrompt records a smart search event for an expansion prompt
+// version; click/served per version is the click-through rate of an A/B test.
+// event: served, click
+func (m *Metrics) RecordExpansionPrompt(version, event string) {
+	m.ExpansionPrompt.WithLabelValues(version, event).Inc()
+}
+
+// RecordStaleCacheServed records cached data served after its TTL expired.
+// module: buzz
+func (m *Metrics) RecordStaleCacheServed(module string) {
+	m.StaleCacheServed.WithLabelValues(module).Inc()
+}
+
 // RecordIntent records an intent trigger.
 // module: course, id, contact, program, usage, help, direct_reply
 // intent: search, smart, uid, etc. (empty string for modules without sub-intents)
@@ -533,12 +1010,65 @@ func (m *Metrics) RecordIntent(module, intent, source string) {
 	m.IntentTotal.WithLabelValues(module, intent, source).Inc()
 }
 
+// RecordIntentRouting records which modules could handle a text message and
+// which one replied. No text or user is recorded.
+// matched: module names in registration order (none if empty)
+// chosen: replying module name, or "" when none replied
+func (m *Metrics) RecordIntentRouting(matched []string, chosen string) {
+	matchedLabel := strings.Join(matched, "+")
+	if matchedLabel == "" {
+		matchedLabel = "none"
+	}
+	if chosen == "" {
+		chosen = "none"
+	}
+	m.IntentRouting.WithLabelValues(matchedLabel, chosen).Inc()
+}
+
+// RecordIntentReformulation records that the user sent another text message
+// right after module handled their previous one.
+// source: keyword (matched by CanHandle), nlu (matched by NLU intent parser)
+func (m *Metrics) RecordIntentReformulation(module, source string) {
+	m.IntentReformulations.WithLabelValues(module, source).Inc()
+}
+
+// ============================================
+// Module helpers
+// ============================================
+
+// RecordModuleCall records a module handler invocation.
+// module: course, id, contact, program, usage
+// kind: message, postback, intent
+// status: success, empty, error
+func (m *Metrics) RecordModuleCall(module, kind, status string, duration float64) {
+	m.ModuleTotal.WithLabelValues(module, kind, status).Inc()
+	m.ModuleDuration.WithLabelValues(module, kind).Observe(duration)
+}
+
+// RecordCanaryCall records which arm served a call to a module under canary rollout.
+// arm: stable, canary
+func (m *Metrics) RecordCanaryCall(module, arm string) {
+	m.CanaryTotal.WithLabelValues(module, arm).Inc()
+}
+
+// RecordCanaryDiff records how a canary reply compared with the stable one.
+// result: same, different, canary_empty, stable_empty, canary_error, stable_error
+func (m *Metrics) RecordCanaryDiff(module, result string) {
+	m.CanaryDiff.WithLabelValues(module, result).Inc()
+}
+
+// RecordLegacyPostback records a postback in a format of an older release.
+// format: unprefixed, positional
+func (m *Metrics) RecordLegacyPostback(module, format string) {
+	m.PostbackLegacy.WithLabelValues(module, format).Inc()
+}
+
 // ============================================
 // Rate Limiter helpers
 // ============================================
 
 // RecordRateLimiterDrop records a dropped request.
-// limiter: user, global, llm
+// limiter: user, global, llm, module
 func (m *Metrics) RecordRateLimiterDrop(limiter string) {
 	m.RateLimiterDropped.WithLabelValues(limiter).Inc()
 }
@@ -565,7 +1095,7 @@ func (m *Metrics) RecordJob(job, module string, duration float64) {
 }
 
 // RecordLineReplySkipped records a skipped LINE reply outcome (no API call made).
-// Use this for skipped_empty_token and skipped_invalid_token statuses.
+// Use this for skipped_empty_token, skipped_invalid_token and skipped_expired_token statuses.
 func (m *Metrics) RecordLineReplySkipped(status string) {
 	m.LineReplyTotal.WithLabelValues(status).Inc()
 }
@@ -579,10 +1109,92 @@ func (m *Metrics) RecordJobRun(job, module, status string, duration float64) {
 	m.JobDuration.WithLabelValues(job, module).Observe(duration)
 }
 
+// ============================================
+// Runtime info helpers
+// ============================================
+
+// SetBuildInfo records the running binary's build metadata.
+func (m *Metrics) SetBuildInfo(version, commit, goVersion string) {
+	m.BuildInfo.Reset()
+	m.BuildInfo.WithLabelValues(version, commit, goVersion).Set(1)
+}
+
+// SetConfigFingerprint records the fingerprint from config.Config.Fingerprint.
+func (m *Metrics) SetConfigFingerprint(fingerprint string) {
+	m.ConfigInfo.Reset()
+	m.ConfigInfo.WithLabelValues(fingerprint).Set(1)
+}
+
+// SetPoolCapacity sets the goroutine limit of a bounded pool.
+// pool: jobs
+func (m *Metrics) SetPoolCapacity(pool string, capacity int) {
+	m.PoolCapacity.WithLabelValues(pool).Set(float64(capacity))
+}
+
+// PoolStarted marks a goroutine starting in pool. Pair with PoolFinished.
+// pool: webhook, jobs
+func (m *Metrics) PoolStarted(pool string) {
+	m.PoolActive.WithLabelValues(pool).Inc()
+}
+
+// PoolFinished marks a goroutine in pool as done.
+func (m *Metrics) PoolFinished(pool string) {
+	m.PoolActive.WithLabelValues(pool).Dec()
+}
+
+// ============================================
+// Memory budget helpers
+// ============================================
+
+// SetMemoryBudgetUsed sets the estimated bytes held by a consumer.
+// consumer: bm25, course_lists, program_lists
+func (m *Metrics) SetMemoryBudgetUsed(consumer string, bytes int64) {
+	m.MemoryBudgetUsed.WithLabelValues(consumer).Set(float64(bytes))
+}
+
+// SetMemoryBudgetLimit sets the memory budget in bytes (0 = unlimited).
+func (m *Metrics) SetMemoryBudgetLimit(bytes int64) {
+	m.MemoryBudgetLimit.Set(float64(bytes))
+}
+
+// RecordMemoryBudgetEviction records an entry evicted to fit the budget.
+func (m *Metrics) RecordMemoryBudgetEviction(consumer string) {
+	m.MemoryBudgetEvictions.WithLabelValues(consumer).Inc()
+}
+
 // ============================================
 // Registry access
 // ============================================
 
+// CounterSample is the value of one counter series.
+type CounterSample struct {
+	Labels map[string]string
+	Value  float64
+}
+
+// Counters returns every counter series in the registry keyed by metric
+// name, for in-process checks such as error rate alerts.
+func (m *Metrics) Counters() (map[string][]CounterSample, error) {
+	families, err := m.registry.Gather()
+	if err != nil {
+		return nil, err
+	}
+	counters := make(map[string][]CounterSample)
+	for _, mf := range families {
+		for _, metric := range mf.GetMetric() {
+			if metric.GetCounter() == nil {
+				continue
+			}
+			labels := make(map[string]string, len(metric.GetLabel()))
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			counters[mf.GetName()] = append(counters[mf.GetName()], CounterSample{Labels: labels, Value: metric.GetCounter().GetValue()})
+		}
+	}
+	return counters, nil
+}
+
 // Registry returns the custom Prometheus registry.
 // Use with promhttp.HandlerFor() for metrics endpoint.
 func (m *Metrics) Registry() *prometheus.Registry {
@@ -1,6 +1,7 @@
 package metrics
 
 import (
+	"context"
 	"slices"
 	"testing"
 
@@ -33,9 +34,15 @@ func TestNew(t *testing.T) {
 		{"LineReplyTotal", func() bool { return m.LineReplyTotal != nil }},
 		{"LineReplyDuration", func() bool { return m.LineReplyDuration != nil }},
 
+		// LINE Messaging API metrics
+		{"LineAPITotal", func() bool { return m.LineAPITotal != nil }},
+		{"LineAPIRetries", func() bool { return m.LineAPIRetries != nil }},
+		{"LineQuota", func() bool { return m.LineQuota != nil }},
+
 		// Scraper metrics
 		{"ScraperTotal", func() bool { return m.ScraperTotal != nil }},
 		{"ScraperDuration", func() bool { return m.ScraperDuration != nil }},
+		{"ScraperDrift", func() bool { return m.ScraperDrift != nil }},
 
 		// Cache metrics
 		{"CacheOperations", func() bool { return m.CacheOperations != nil }},
@@ -131,6 +138,49 @@ func TestRecordLineReply(t *testing.T) {
 	m.RecordLineReply("rate_limited", 1.0)
 }
 
+func TestSetLineQuota(t *testing.T) {
+	t.Parallel()
+	registry := prometheus.NewRegistry()
+	m := New(registry)
+
+	gauge := func(typ string) float64 {
+		families, err := registry.Gather()
+		if err != nil {
+			t.Fatalf("Gather() error = %v", err)
+		}
+		for _, mf := range families {
+			if mf.GetName() != "ntpu_line_quota_messages" {
+				continue
+			}
+			for _, metric := range mf.GetMetric() {
+				if metric.GetLabel()[0].GetValue() == typ {
+					return metric.GetGauge().GetValue()
+				}
+			}
+		}
+		t.Fatalf("ntpu_line_quota_messages{type=%q} not found", typ)
+		return 0
+	}
+
+	m.SetLineQuota(500, 120)
+	if got := gauge("remaining"); got != 380 {
+		t.Errorf("remaining = %v, want 380", got)
+	}
+
+	m.SetLineQuota(500, 600)
+	if got := gauge("remaining"); got != 0 {
+		t.Errorf("remaining = %v, want 0 when over quota", got)
+	}
+
+	m.SetLineQuota(-1, 42)
+	if got := gauge("remaining"); got != -1 {
+		t.Errorf("remaining = %v, want -1 for unlimited", got)
+	}
+
+	m.RecordLineAPI("push", "quota_exhausted")
+	m.RecordLineAPIRetry("reply")
+}
+
 func TestRecordWebhookBatch(t *testing.T) {
 	t.Parallel()
 	registry := prometheus.NewRegistry()
@@ -167,6 +217,17 @@ func TestRecordScraper(t *testing.T) {
 	}
 }
 
+func TestRecordScraperDrift(t *testing.T) {
+	t.Parallel()
+	registry := prometheus.NewRegistry()
+	m := New(registry)
+
+	parsers := []string{"course_list", "student_list", "contact_list", "contact_directory", "program_list"}
+	for _, parser := range parsers {
+		m.RecordScraperDrift(parser)
+	}
+}
+
 // ============================================
 // Cache metrics tests
 // ============================================
@@ -178,7 +239,7 @@ func TestRecordCacheHit(t *testing.T) {
 
 	modules := []string{"students", "courses", "contacts", "syllabi", "stickers"}
 	for _, module := range modules {
-		m.RecordCacheHit(module)
+		m.RecordCacheHit(context.Background(), module)
 	}
 }
 
@@ -189,7 +250,7 @@ func TestRecordCacheMiss(t *testing.T) {
 
 	modules := []string{"students", "courses", "contacts", "syllabi", "stickers"}
 	for _, module := range modules {
-		m.RecordCacheMiss(module)
+		m.RecordCacheMiss(context.Background(), module)
 	}
 }
 
@@ -214,6 +275,40 @@ func TestSetCacheSize(t *testing.T) {
 	}
 }
 
+func TestSetIntegrityIssues(t *testing.T) {
+	t.Parallel()
+	registry := prometheus.NewRegistry()
+	m := New(registry)
+
+	checks := []string{"course_no_teachers", "student_bad_year", "syllabus_orphan", "historical_in_hot", "orphan_link"}
+	for _, check := range checks {
+		m.SetIntegrityIssues(check, 3)
+		m.SetIntegrityIssues(check, 0) // Repaired on the next run
+	}
+}
+
+func TestRecordCleanupDeleted(t *testing.T) {
+	t.Parallel()
+	registry := prometheus.NewRegistry()
+	m := New(registry)
+
+	m.RecordCleanupDeleted("courses", 12)
+	m.RecordCleanupDeleted("courses", 3)
+	m.RecordCleanupDeleted("contacts", 0)
+
+	counters, err := m.Counters()
+	if err != nil {
+		t.Fatalf("Counters() error = %v", err)
+	}
+	got := map[string]float64{}
+	for _, s := range counters["ntpu_cache_cleanup_deleted_total"] {
+		got[s.Labels["table"]] = s.Value
+	}
+	if got["courses"] != 15 || got["contacts"] != 0 {
+		t.Errorf("ntpu_cache_cleanup_deleted_total = %v, want courses 15 and contacts 0", got)
+	}
+}
+
 // ============================================
 // LLM metrics tests
 // ============================================
@@ -284,6 +379,50 @@ func TestSetIndexSize(t *testing.T) {
 	m.SetIndexSize("bm25", 2000) // Update index size
 }
 
+func TestBusinessSignals(t *testing.T) {
+	t.Parallel()
+	registry := prometheus.NewRegistry()
+	m := New(registry)
+
+	m.RecordZeroResults("course")
+	m.RecordZeroResults("course")
+	m.RecordZeroResults("contact")
+	m.RecordTruncated("id")
+	m.RecordSearchFallback("expansion_error")
+	m.RecordQueryExpansionSkipped("rate_limit")
+	m.RecordStaleCacheServed("buzz")
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	counters := make(map[string]float64)
+	for _, mf := range families {
+		for _, metric := range mf.GetMetric() {
+			if labels := metric.GetLabel(); len(labels) == 1 {
+				counters[mf.GetName()+"{"+labels[0].GetValue()+"}"] = metric.GetCounter().GetValue()
+			}
+		}
+	}
+
+	tests := []struct {
+		series string
+		want   float64
+	}{
+		{"ntpu_search_zero_results_total{course}", 2},
+		{"ntpu_search_zero_results_total{contact}", 1},
+		{"ntpu_results_truncated_total{id}", 1},
+		{"ntpu_search_bm25_fallback_total{expansion_error}", 1},
+		{"ntpu_query_expansion_skipped_total{rate_limit}", 1},
+		{"ntpu_cache_stale_served_total{buzz}", 1},
+	}
+	for _, tt := range tests {
+		if got := counters[tt.series]; got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.series, got, tt.want)
+		}
+	}
+}
+
 // ============================================
 // Intent Distribution metrics tests
 // ============================================
@@ -413,19 +552,36 @@ func TestMetricNamesRegisteredAfterUse(t *testing.T) {
 	m.RecordWebhook("message", "success", 0.5)
 	m.RecordLineReply("success", 0.2)
 	m.RecordScraper("course", "success", 1.5)
-	m.RecordCacheHit("course")
+	m.RecordCacheHit(context.Background(), "course")
+	m.RecordSearchResultCache("course", "hit")
 	m.SetCacheSize("courses", 100)
 	m.RecordLLM("gemini", "gemma-4-31b-it", "nlu", "success", 0.5)
 	m.RecordLLMFallback("gemini", "gemma-4-31b-it", "groq", "openai/gpt-oss-120b", "nlu")
 	m.LLMCooldownTotal.WithLabelValues("gemini", "gemma-4-31b-it", "burst", "applied").Inc()
+	m.LLMOutputFiltered.WithLabelValues("nlu", "url").Inc()
 	m.RecordSearch("bm25", "success", 0.05)
 	m.RecordSearchResults("bm25", 3)
 	m.SetIndexSize("bm25", 1000)
+	m.RecordZeroResults("course")
+	m.RecordTruncated("contact")
+	m.RecordSearchFallback("expansion_error")
+	m.RecordQueryExpansionSkipped("rate_limit")
+	m.RecordExpansionPrompt("builtin", "served")
+	m.RecordStaleCacheServed("buzz")
 	m.RecordIntent("course", "smart", "nlu")
+	m.RecordCanaryCall("course", "canary")
+	m.RecordCanaryDiff("course", "same")
+	m.RecordLegacyPostback("course", "unprefixed")
 	m.RecordRateLimiterDrop("user")
 	m.SetRateLimiterUsers(10)
 	m.SetLLMRateLimiterUsers(2)
 	m.RecordJobRun("refresh", "total", "success", 120)
+	m.SetBuildInfo("v1.2.3", "abc1234", "go1.26.0")
+	m.SetConfigFingerprint("0123456789ab")
+	m.SetPoolCapacity("jobs", 2)
+	m.SetMemoryBudgetUsed("bm25", 1<<20)
+	m.RecordMemoryBudgetEviction("bm25")
+	m.PoolStarted("webhook")
 
 	families, err := registry.Gather()
 	if err != nil {
@@ -448,21 +604,41 @@ func TestMetricNamesRegisteredAfterUse(t *testing.T) {
 		"ntpu_scraper_total",
 		"ntpu_scraper_duration_seconds",
 		"ntpu_cache_operations_total",
+		"ntpu_search_result_cache_total",
 		"ntpu_cache_size",
 		"ntpu_llm_total",
 		"ntpu_llm_duration_seconds",
 		"ntpu_llm_fallback_total",
 		"ntpu_llm_cooldown_total",
+		"ntpu_llm_output_filtered_total",
 		"ntpu_search_total",
 		"ntpu_search_duration_seconds",
 		"ntpu_search_results",
 		"ntpu_index_size",
+		"ntpu_search_zero_results_total",
+		"ntpu_results_truncated_total",
+		"ntpu_search_bm25_fallback_total",
+		"ntpu_query_expansion_skipped_total",
+		"ntpu_query_expansion_prompt_total",
+		"ntpu_cache_stale_served_total",
 		"ntpu_intent_total",
+		"ntpu_canary_total",
+		"ntpu_canary_diff_total",
+		"ntpu_postback_legacy_total",
 		"ntpu_rate_limiter_dropped_total",
 		"ntpu_rate_limiter_users",
 		"ntpu_llm_rate_limiter_users",
 		"ntpu_job_total",
 		"ntpu_job_duration_seconds",
+		"ntpu_build_info",
+		"ntpu_config_info",
+		"ntpu_start_time_seconds",
+		"ntpu_uptime_seconds",
+		"ntpu_goroutine_pool_active",
+		"ntpu_goroutine_pool_capacity",
+		"ntpu_memory_budget_used_bytes",
+		"ntpu_memory_budget_limit_bytes",
+		"ntpu_memory_budget_evictions_total",
 	}
 
 	for _, name := range want {
@@ -471,3 +647,61 @@ func TestMetricNamesRegisteredAfterUse(t *testing.T) {
 		}
 	}
 }
+
+func TestRuntimeInfo(t *testing.T) {
+	t.Parallel()
+	registry := prometheus.NewRegistry()
+	m := New(registry)
+
+	// A second call replaces the series instead of adding one
+	m.SetConfigFingerprint("aaaaaaaaaaaa")
+	m.SetConfigFingerprint("bbbbbbbbbbbb")
+	m.PoolStarted("webhook")
+	m.PoolStarted("webhook")
+	m.PoolFinished("webhook")
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	for _, mf := range families {
+		switch mf.GetName() {
+		case "ntpu_config_info":
+			if got := len(mf.GetMetric()); got != 1 {
+				t.Errorf("ntpu_config_info has %d series, want 1", got)
+			} else if fp := mf.GetMetric()[0].GetLabel()[0].GetValue(); fp != "bbbbbbbbbbbb" {
+				t.Errorf("ntpu_config_info{fingerprint=%q}, want the latest", fp)
+			}
+		case "ntpu_goroutine_pool_active":
+			if got := mf.GetMetric()[0].GetGauge().GetValue(); got != 1 {
+				t.Errorf("ntpu_goroutine_pool_active{pool=webhook} = %v, want 1", got)
+			}
+		case "ntpu_uptime_seconds":
+			if got := mf.GetMetric()[0].GetGauge().GetValue(); got < 0 {
+				t.Errorf("ntpu_uptime_seconds = %v, want >= 0", got)
+			}
+		}
+	}
+}
+
+func TestRecordIntentRouting(t *testing.T) {
+	t.Parallel()
+	registry := prometheus.NewRegistry()
+	m := New(registry)
+
+	m.RecordIntentRouting([]string{"contact", "course"}, "contact")
+	m.RecordIntentRouting([]string{"contact", "course"}, "contact")
+	m.RecordIntentRouting(nil, "")
+
+	counters, err := m.Counters()
+	if err != nil {
+		t.Fatalf("Counters() error = %v", err)
+	}
+	got := map[string]float64{}
+	for _, s := range counters["ntpu_intent_routing_total"] {
+		got[s.Labels["matched"]+">"+s.Labels["chosen"]] = s.Value
+	}
+	if got["contact+course>contact"] != 2 || got["none>none"] != 1 {
+		t.Errorf("ntpu_intent_routing_total = %v, want contact+course>contact 2 and none>none 1", got)
+	}
+}
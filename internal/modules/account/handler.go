@@ -0,0 +1,148 @@
+// Package account implements optional linking of LINE accounts to school
+// SSO accounts. 綁定帳號 starts LINE's account link flow through the school
+// SSO login (see Linker); the SSO token is stored encrypted so personalized
+// features such as the timetable can later read the user's own enrollment.
+// 解除綁定 deletes the link and the stored token.
+package account
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/bot"
+	"github.com/garyellow/ntpu-linebot-go/internal/ctxutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/sticker"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+	"github.com/line/line-bot-sdk-go/v8/linebot/webhook"
+)
+
+// Module constants
+const (
+	ModuleName = "account"
+	senderName = "帳號小幫手"
+)
+
+// Commands (exact match after sanitization)
+const (
+	linkKeyword   = "綁定帳號"
+	unlinkKeyword = "解除綁定"
+	statusKeyword = "綁定狀態"
+)
+
+// Handler answers the account link commands and LINE's accountLink events.
+type Handler struct {
+	bot.NoPostbacks // Commands are plain text
+
+	linker         *Linker
+	stickerManager *sticker.Manager
+}
+
+// NewHandler creates a new account handler.
+func NewHandler(linker *Linker, stickerManager *sticker.Manager) *Handler {
+	return &Handler{
+		linker:         linker,
+		stickerManager: stickerManager,
+	}
+}
+
+// Name returns the module name
+func (h *Handler) Name() string {
+	return ModuleName
+}
+
+// CanHandle returns true for the account link commands.
+func (h *Handler) CanHandle(text string) bool {
+	text = strings.TrimSpace(text)
+	return text == linkKeyword || text == unlinkKeyword || text == statusKeyword
+}
+
+// HandleMessage runs an account command for the current user.
+func (h *Handler) HandleMessage(ctx context.Context, text string) []messaging_api.MessageInterface {
+	log := logger.FromContext(ctx)
+	sender := lineutil.GetSender(senderName, h.stickerManager)
+	text = strings.TrimSpace(text)
+
+	// Links are personal; never start or show one from a group chat
+	userID := ctxutil.GetUserID(ctx)
+	if userID == "" || ctxutil.GetChatID(ctx) != userID {
+		return lineutil.TextReply(sender, "🔒 帳號綁定僅限與本帳號的 1 對 1 聊天使用")
+	}
+
+	store := h.linker.Store()
+	link, err := store.Linked(ctx, userID)
+	if err != nil {
+		log.WithError(err).ErrorContext(ctx, "Failed to load account link")
+		return lineutil.TextReply(sender, "❌ 讀取綁定狀態失敗，請稍後再試")
+	}
+
+	switch text {
+	case unlinkKeyword:
+		unlinked, err := store.Unlink(ctx, userID)
+		if err != nil {
+			log.WithError(err).ErrorContext(ctx, "Failed to unlink account")
+			return lineutil.TextReply(sender, "❌ 解除綁定失敗，請稍後再試")
+		}
+		if !unlinked {
+			return lineutil.TextReply(sender, "ℹ️ 目前沒有綁定學校帳號")
+		}
+		return lineutil.TextReply(sender, "✅ 已解除綁定，並刪除儲存的登入憑證")
+
+	case statusKeyword:
+		if link == nil {
+			return lineutil.TextReply(sender, "ℹ️ 目前沒有綁定學校帳號\n\n輸入「"+linkKeyword+"」即可綁定")
+		}
+		return lineutil.TextReply(sender, linkedText(link))
+	}
+
+	// 綁定帳號
+	if link != nil {
+		return lineutil.TextReply(sender, linkedText(link))
+	}
+	startURL, err := h.linker.StartURL(ctx, userID)
+	if err != nil {
+		log.WithError(err).ErrorContext(ctx, "Failed to start account link")
+		return lineutil.TextReply(sender, "❌ 無法開始綁定，請稍後再試")
+	}
+	msg := lineutil.NewButtonsTemplate(
+		"綁定學校帳號",
+		"綁定學校帳號",
+		"登入學校 SSO 後即可綁定，連結 10 分鐘內有效\n\n本服務只會讀取您的帳號與修課資料，隨時可輸入「"+unlinkKeyword+"」解除",
+		[]lineutil.Action{lineutil.NewURIAction("🔑 前往登入", startURL)},
+	)
+	return []messaging_api.MessageInterface{lineutil.SetSender(msg, sender)}
+}
+
+// HandleAccountLink finishes linking when LINE reports the user confirmed it.
+// Failed results (e.g. an expired link token) need no reply; the user just
+// sends 綁定帳號 again.
+func (h *Handler) HandleAccountLink(ctx context.Context, userID string, event webhook.AccountLinkEvent) []messaging_api.MessageInterface {
+	if event.Link == nil || event.Link.Result != webhook.LinkContentRESULT_OK {
+		return nil
+	}
+
+	log := logger.FromContext(ctx)
+	sender := lineutil.GetSender(senderName, h.stickerManager)
+
+	link, err := h.linker.CompleteLink(ctx, event.Link.Nonce, userID)
+	switch {
+	case errors.Is(err, ErrLinkExpired):
+		return lineutil.TextReply(sender, "⌛ 綁定逾時，請重新輸入「"+linkKeyword+"」")
+	case err != nil:
+		log.WithError(err).ErrorContext(ctx, "Failed to complete account link")
+		return lineutil.TextReply(sender, "❌ 綁定失敗，請稍後再試")
+	}
+	return lineutil.TextReply(sender, "✅ 已綁定學校帳號 "+MaskSubject(link.Subject))
+}
+
+// linkedText describes an existing link.
+func linkedText(link *Link) string {
+	return "🔗 已綁定學校帳號 " + MaskSubject(link.Subject) + "\n" +
+		"綁定時間：" + lineutil.FormatCacheTime(link.LinkedAt.Unix()) + "\n\n" +
+		"要改綁其他帳號，請先輸入「" + unlinkKeyword + "」"
+}
+
+// Ensure Handler implements bot.Handler
+var _ bot.Handler = (*Handler)(nil)
@@ -0,0 +1,86 @@
+package account
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/moduletest"
+	"github.com/garyellow/ntpu-linebot-go/internal/sticker"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+	"github.com/line/line-bot-sdk-go/v8/linebot/webhook"
+)
+
+func newTestHandler(t *testing.T) *Handler {
+	t.Helper()
+	linker, _, _ := newTestLinker(t)
+	return NewHandler(linker, sticker.NewManager(nil, nil, logger.New("error")))
+}
+
+func TestHandler_CanHandle(t *testing.T) {
+	t.Parallel()
+	h := newTestHandler(t)
+
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"綁定帳號", true},
+		{"解除綁定", true},
+		{"綁定狀態", true},
+		{" 綁定帳號 ", true},
+		{"綁定", false},
+		{"帳號", false},
+	}
+	for _, tt := range tests {
+		if got := h.CanHandle(tt.input); got != tt.want {
+			t.Errorf("CanHandle(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestHandler_GroupRefused(t *testing.T) {
+	t.Parallel()
+	h := newTestHandler(t)
+
+	got := moduletest.Text(t, h.HandleMessage(moduletest.ChatContext("U1", "C1"), linkKeyword))
+	if !strings.Contains(got, "1 對 1") {
+		t.Errorf("group reply = %q, want 1:1 only notice", got)
+	}
+}
+
+func TestHandler_LinkLifecycle(t *testing.T) {
+	t.Parallel()
+	h := newTestHandler(t)
+	ctx := moduletest.ChatContext("U1", "U1")
+
+	msgs := h.HandleMessage(ctx, linkKeyword)
+	if _, ok := msgs[0].(*messaging_api.TemplateMessage); len(msgs) != 1 || !ok {
+		t.Fatalf("綁定帳號 reply = %#v, want one buttons template", msgs)
+	}
+
+	if err := h.linker.Store().SaveNonce(ctx, "n1", "411012345", Token{AccessToken: "at"}); err != nil {
+		t.Fatalf("SaveNonce() error = %v", err)
+	}
+	event := webhook.AccountLinkEvent{Link: &webhook.LinkContent{Result: webhook.LinkContentRESULT_OK, Nonce: "n1"}}
+	if got := moduletest.Text(t, h.HandleAccountLink(ctx, "U1", event)); !strings.Contains(got, "41*****45") {
+		t.Errorf("account link reply = %q, want masked subject", got)
+	}
+	if got := moduletest.Text(t, h.HandleAccountLink(ctx, "U1", event)); !strings.Contains(got, "逾時") {
+		t.Errorf("reused nonce reply = %q, want expired notice", got)
+	}
+	failed := webhook.AccountLinkEvent{Link: &webhook.LinkContent{Result: webhook.LinkContentRESULT_FAILED}}
+	if msgs := h.HandleAccountLink(ctx, "U1", failed); len(msgs) != 0 {
+		t.Errorf("failed result replied %d messages, want none", len(msgs))
+	}
+
+	if got := moduletest.Text(t, h.HandleMessage(ctx, statusKeyword)); !strings.Contains(got, "已綁定") {
+		t.Errorf("綁定狀態 reply = %q, want linked", got)
+	}
+	if got := moduletest.Text(t, h.HandleMessage(ctx, unlinkKeyword)); !strings.Contains(got, "已解除綁定") {
+		t.Errorf("解除綁定 reply = %q, want unlinked", got)
+	}
+	if got := moduletest.Text(t, h.HandleMessage(ctx, unlinkKeyword)); !strings.Contains(got, "沒有綁定") {
+		t.Errorf("second 解除綁定 reply = %q, want not linked", got)
+	}
+}
@@ -0,0 +1,81 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
+)
+
+// LINE Login endpoints that vouch for a LIFF access token.
+const (
+	lineVerifyURL  = "https://api.line.me/oauth2/v2.1/verify"
+	lineProfileURL = "https://api.line.me/v2/profile"
+)
+
+// ErrLIFFToken is returned when LINE rejects a LIFF access token or it was
+// issued to another channel.
+var ErrLIFFToken = errors.New("LIFF access token rejected")
+
+// LIFFUsers resolves the LINE user behind a LIFF access token, so the LIFF
+// app can start linking (Linker.StartURL) without trusting a user ID sent by
+// the page: like the chat flow, the user ID only ever comes from LINE.
+type LIFFUsers struct {
+	channelID  string
+	verifyURL  string
+	profileURL string
+	client     *http.Client
+}
+
+// NewLIFFUsers creates a LIFFUsers for the LIFF app liffID
+// ("{channel ID}-{alphanumerics}"); only its channel's tokens are accepted.
+func NewLIFFUsers(liffID string) *LIFFUsers {
+	channelID, _, _ := strings.Cut(liffID, "-")
+	return &LIFFUsers{
+		channelID:  channelID,
+		verifyURL:  lineVerifyURL,
+		profileURL: lineProfileURL,
+		client:     &http.Client{Timeout: config.SSORequestTimeout},
+	}
+}
+
+// UserID verifies accessToken with LINE and returns the user it belongs to.
+// Returns an error wrapping ErrLIFFToken if the token is invalid, expired, or
+// from another channel.
+func (u *LIFFUsers) UserID(ctx context.Context, accessToken string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		u.verifyURL+"?"+url.Values{"access_token": {accessToken}}.Encode(), nil)
+	if err != nil {
+		return "", fmt.Errorf("create verify request: %w", err)
+	}
+	var verified struct {
+		ClientID  string `json:"client_id"`
+		ExpiresIn int64  `json:"expires_in"`
+	}
+	if err := doJSON(u.client, req, &verified, ErrLIFFToken); err != nil {
+		return "", fmt.Errorf("verify LIFF token: %w", err)
+	}
+	if verified.ClientID != u.channelID || verified.ExpiresIn <= 0 {
+		return "", fmt.Errorf("verify LIFF token: %w: channel %q", ErrLIFFToken, verified.ClientID)
+	}
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, u.profileURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("create profile request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	var profile struct {
+		UserID string `json:"userId"`
+	}
+	if err := doJSON(u.client, req, &profile, ErrLIFFToken); err != nil {
+		return "", fmt.Errorf("get LIFF profile: %w", err)
+	}
+	if profile.UserID == "" {
+		return "", fmt.Errorf("get LIFF profile: %w: no userId", ErrLIFFToken)
+	}
+	return profile.UserID, nil
+}
@@ -0,0 +1,55 @@
+package account
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newFakeLINE serves LINE's verify and profile endpoints: token "good" belongs
+// to channel 1234567890 and user U1, token "other" to another channel.
+func newFakeLINE(t *testing.T) *LIFFUsers {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /verify", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("access_token") {
+		case "good":
+			_ = json.NewEncoder(w).Encode(map[string]any{"client_id": "1234567890", "expires_in": 3600})
+		case "other":
+			_ = json.NewEncoder(w).Encode(map[string]any{"client_id": "9999999999", "expires_in": 3600})
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	})
+	mux.HandleFunc("GET /profile", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer good" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"userId": "U1"})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	users := NewLIFFUsers("1234567890-AbcdEfgh")
+	users.verifyURL, users.profileURL = srv.URL+"/verify", srv.URL+"/profile"
+	return users
+}
+
+func TestLIFFUsers_UserID(t *testing.T) {
+	t.Parallel()
+	users := newFakeLINE(t)
+	ctx := context.Background()
+
+	if got, err := users.UserID(ctx, "good"); err != nil || got != "U1" {
+		t.Errorf("UserID(good) = (%q, %v), want U1", got, err)
+	}
+	for _, token := range []string{"other", "expired"} {
+		if _, err := users.UserID(ctx, token); !errors.Is(err, ErrLIFFToken) {
+			t.Errorf("UserID(%s) error = %v, want ErrLIFFToken", token, err)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package account
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// lineAccountLinkURL is where LINE takes the nonce and asks the user to
+// confirm the link; LINE then sends an accountLink webhook event.
+const lineAccountLinkURL = "https://access.line.me/dialog/bot/accountLink"
+
+// LinkTokenIssuer issues LINE account link tokens (satisfied by *lineapi.Client).
+type LinkTokenIssuer interface {
+	IssueLinkToken(ctx context.Context, userID string) (string, error)
+}
+
+// Linker runs the account link flow:
+//
+//  1. 綁定帳號 → StartURL issues a LINE link token and returns the link page URL
+//  2. GET /account/link → Begin saves a random state and PKCE verifier and
+//     redirects to the SSO login
+//  3. GET /account/callback → Callback exchanges the code, saves the account
+//     under a random nonce, and redirects to LINE with the link token and nonce
+//  4. accountLink webhook event → CompleteLink binds the nonce to the user
+//
+// The LINE user ID is only ever learned from LINE itself (steps 1 and 4), so
+// a forwarded link page cannot bind someone else's school account.
+type Linker struct {
+	store   *Store
+	sso     *SSO
+	issuer  LinkTokenIssuer
+	baseURL string
+}
+
+// NewLinker creates a Linker. baseURL is NTPU_PUBLIC_BASE_URL.
+func NewLinker(store *Store, sso *SSO, issuer LinkTokenIssuer, baseURL string) *Linker {
+	return &Linker{
+		store:   store,
+		sso:     sso,
+		issuer:  issuer,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// Store returns the link store.
+func (l *Linker) Store() *Store {
+	return l.store
+}
+
+// StartURL returns the URL that starts linking for userID.
+func (l *Linker) StartURL(ctx context.Context, userID string) (string, error) {
+	linkToken, err := l.issuer.IssueLinkToken(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	return l.baseURL + "/account/link?" + url.Values{"linkToken": {linkToken}}.Encode(), nil
+}
+
+// Begin remembers linkToken and returns the SSO login URL.
+func (l *Linker) Begin(ctx context.Context, linkToken string) (string, error) {
+	state, verifier := rand.Text(), NewVerifier()
+	if err := l.store.SaveState(ctx, state, linkToken, verifier); err != nil {
+		return "", err
+	}
+	return l.sso.AuthCodeURL(state, verifier), nil
+}
+
+// Callback completes the SSO login and returns the LINE URL that confirms
+// the link. Returns ErrLinkExpired for an unknown or expired state.
+func (l *Linker) Callback(ctx context.Context, state, code string) (string, error) {
+	linkToken, verifier, err := l.store.TakeState(ctx, state)
+	if err != nil {
+		return "", err
+	}
+
+	token, err := l.sso.Exchange(ctx, code, verifier)
+	if err != nil {
+		return "", err
+	}
+	subject, err := l.sso.Subject(ctx, token.AccessToken)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := rand.Text()
+	if err := l.store.SaveNonce(ctx, nonce, subject, token); err != nil {
+		return "", err
+	}
+	return lineAccountLinkURL + "?" + url.Values{"linkToken": {linkToken}, "nonce": {nonce}}.Encode(), nil
+}
+
+// CompleteLink binds the account behind nonce to userID.
+func (l *Linker) CompleteLink(ctx context.Context, nonce, userID string) (*Link, error) {
+	link, err := l.store.CompleteLink(ctx, nonce, userID)
+	if err != nil {
+		return nil, fmt.Errorf("complete account link: %w", err)
+	}
+	return link, nil
+}
+
+// MaskSubject hides the middle of an account ID for display, e.g.
+// "411012345" → "41*****45".
+func MaskSubject(subject string) string {
+	runes := []rune(subject)
+	if len(runes) <= 4 {
+		return strings.Repeat("*", len(runes))
+	}
+	return string(runes[:2]) + strings.Repeat("*", len(runes)-4) + string(runes[len(runes)-2:])
+}
@@ -0,0 +1,165 @@
+package account
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/moduletest"
+)
+
+type fakeIssuer struct{ userID string }
+
+func (f *fakeIssuer) IssueLinkToken(_ context.Context, userID string) (string, error) {
+	f.userID = userID
+	return "lt-" + userID, nil
+}
+
+// fakeSSO remembers the PKCE challenge of the last login page the test opened.
+type fakeSSO struct {
+	mu        sync.Mutex
+	challenge string
+}
+
+func (f *fakeSSO) login(loginURL string) url.Values {
+	u, _ := url.Parse(loginURL)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.challenge = u.Query().Get("code_challenge")
+	return u.Query()
+}
+
+func (f *fakeSSO) verified(verifier string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return verifier != "" && codeChallenge(verifier) == f.challenge
+}
+
+// newFakeSSO serves a token endpoint that accepts code "good" with the
+// verifier of the last login, and a userinfo endpoint that returns sub
+// "411012345".
+func newFakeSSO(t *testing.T) (*httptest.Server, *fakeSSO) {
+	t.Helper()
+	fake := &fakeSSO{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != "client" || secret != "secret" || r.PostFormValue("code") != "good" ||
+			!fake.verified(r.PostFormValue("code_verifier")) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "at", "refresh_token": "rt", "expires_in": 3600})
+	})
+	mux.HandleFunc("GET /userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer at" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"sub": "411012345"})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv, fake
+}
+
+func newTestLinker(t *testing.T) (*Linker, *fakeIssuer, *fakeSSO) {
+	t.Helper()
+	srv, fake := newFakeSSO(t)
+	sso := NewSSO(SSOConfig{
+		AuthURL:      "https://sso.example.edu/authorize",
+		TokenURL:     srv.URL + "/token",
+		UserInfoURL:  srv.URL + "/userinfo",
+		ClientID:     "client",
+		ClientSecret: "secret",
+		Scopes:       []string{"openid", "profile"},
+		RedirectURL:  "https://bot.example.com/account/callback",
+	})
+	issuer := &fakeIssuer{}
+	return NewLinker(moduletest.OpenStore(t, openTestStore), sso, issuer, "https://bot.example.com/"), issuer, fake
+}
+
+func TestLinker_Flow(t *testing.T) {
+	t.Parallel()
+	linker, issuer, fake := newTestLinker(t)
+	ctx := context.Background()
+
+	startURL, err := linker.StartURL(ctx, "U1")
+	if err != nil {
+		t.Fatalf("StartURL() error = %v", err)
+	}
+	if issuer.userID != "U1" || startURL != "https://bot.example.com/account/link?linkToken=lt-U1" {
+		t.Fatalf("StartURL() = %q (issued for %q)", startURL, issuer.userID)
+	}
+
+	loginURL, err := linker.Begin(ctx, "lt-U1")
+	if err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+	q := fake.login(loginURL)
+	if !strings.HasPrefix(loginURL, "https://sso.example.edu/authorize?") ||
+		q.Get("client_id") != "client" || q.Get("scope") != "openid profile" ||
+		q.Get("redirect_uri") != "https://bot.example.com/account/callback" || q.Get("state") == "" ||
+		q.Get("code_challenge_method") != "S256" || len(q.Get("code_challenge")) != 43 {
+		t.Fatalf("Begin() = %q", loginURL)
+	}
+
+	if _, err := linker.Callback(ctx, q.Get("state"), "bad"); !errors.Is(err, ErrSSO) {
+		t.Fatalf("Callback() with bad code error = %v, want ErrSSO", err)
+	}
+	// The failed callback used up the state
+	if _, err := linker.Callback(ctx, q.Get("state"), "good"); !errors.Is(err, ErrLinkExpired) {
+		t.Fatalf("Callback() reused state error = %v, want ErrLinkExpired", err)
+	}
+
+	// A code redeemed against another login's verifier is refused
+	first, _ := linker.Begin(ctx, "lt-U1")
+	firstLogin, _ := url.Parse(first)
+	second, _ := linker.Begin(ctx, "lt-U1")
+	q = fake.login(second)
+	if _, err := linker.Callback(ctx, firstLogin.Query().Get("state"), "good"); !errors.Is(err, ErrSSO) {
+		t.Fatalf("Callback() with another login's verifier error = %v, want ErrSSO", err)
+	}
+
+	confirmURL, err := linker.Callback(ctx, q.Get("state"), "good")
+	if err != nil {
+		t.Fatalf("Callback() error = %v", err)
+	}
+	confirm, _ := url.Parse(confirmURL)
+	if !strings.HasPrefix(confirmURL, lineAccountLinkURL+"?") || confirm.Query().Get("linkToken") != "lt-U1" {
+		t.Fatalf("Callback() = %q", confirmURL)
+	}
+
+	link, err := linker.CompleteLink(ctx, confirm.Query().Get("nonce"), "U1")
+	if err != nil {
+		t.Fatalf("CompleteLink() error = %v", err)
+	}
+	if link.Subject != "411012345" || link.Token.AccessToken != "at" || link.Token.Expiry.IsZero() {
+		t.Errorf("CompleteLink() = %+v", link)
+	}
+}
+
+func TestMaskSubject(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"411012345", "41*****45"},
+		{"abcde", "ab*de"},
+		{"abcd", "****"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := MaskSubject(tt.input); got != tt.want {
+			t.Errorf("MaskSubject(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package account
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+)
+
+// errUnseal is returned for ciphertext that was not sealed with the current key.
+var errUnseal = errors.New("stored token cannot be decrypted (NTPU_ACCOUNT_TOKEN_KEY changed?)")
+
+// sealer encrypts stored SSO tokens with AES-256-GCM, so a leaked account.db
+// does not leak working school credentials.
+type sealer struct {
+	aead cipher.AEAD
+}
+
+// newSealer derives the AES key from an arbitrary-length secret.
+func newSealer(secret string) (*sealer, error) {
+	key := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, fmt.Errorf("create token cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("create token cipher: %w", err)
+	}
+	return &sealer{aead: aead}, nil
+}
+
+// seal returns nonce || ciphertext.
+func (s *sealer) seal(plaintext []byte) []byte {
+	nonce := make([]byte, s.aead.NonceSize(), s.aead.NonceSize()+len(plaintext)+s.aead.Overhead())
+	_, _ = rand.Read(nonce) // crypto/rand.Read never fails
+	return s.aead.Seal(nonce, nonce, plaintext, nil)
+}
+
+// open reverses seal.
+func (s *sealer) open(sealed []byte) ([]byte, error) {
+	n := s.aead.NonceSize()
+	if len(sealed) < n {
+		return nil, errUnseal
+	}
+	plaintext, err := s.aead.Open(nil, sealed[:n], sealed[n:], nil)
+	if err != nil {
+		return nil, errUnseal
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,167 @@
+package account
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
+)
+
+// maxSSOResponseSize caps token, userinfo, and LINE verify responses (all small JSON).
+const maxSSOResponseSize = 64 << 10
+
+// ErrSSO is returned when the school SSO rejects a request or answers with
+// something unusable.
+var ErrSSO = errors.New("school SSO request failed")
+
+// SSOConfig holds the OAuth 2.0 authorization code settings of the school SSO.
+type SSOConfig struct {
+	AuthURL      string
+	TokenURL     string
+	UserInfoURL  string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	RedirectURL  string // {NTPU_PUBLIC_BASE_URL}/account/callback
+}
+
+// SSO is a minimal OAuth 2.0 authorization code client for the school SSO.
+// Every login uses PKCE (RFC 7636, S256), so an intercepted code is useless
+// without the verifier kept with its state.
+type SSO struct {
+	cfg    SSOConfig
+	client *http.Client
+}
+
+// NewSSO creates an SSO client.
+func NewSSO(cfg SSOConfig) *SSO {
+	return &SSO{
+		cfg:    cfg,
+		client: &http.Client{Timeout: config.SSORequestTimeout},
+	}
+}
+
+// NewVerifier returns a random PKCE code verifier (52 unreserved characters;
+// RFC 7636 asks for 43 to 128).
+func NewVerifier() string {
+	return rand.Text() + rand.Text()
+}
+
+// codeChallenge returns the S256 PKCE challenge of verifier.
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// AuthCodeURL returns the SSO login page URL for state, challenging with the
+// PKCE verifier that Exchange must later send.
+func (s *SSO) AuthCodeURL(state, verifier string) string {
+	q := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {s.cfg.ClientID},
+		"redirect_uri":          {s.cfg.RedirectURL},
+		"scope":                 {strings.Join(s.cfg.Scopes, " ")},
+		"state":                 {state},
+		"code_challenge":        {codeChallenge(verifier)},
+		"code_challenge_method": {"S256"},
+	}
+	sep := "?"
+	if strings.Contains(s.cfg.AuthURL, "?") {
+		sep = "&"
+	}
+	return s.cfg.AuthURL + sep + q.Encode()
+}
+
+// Exchange trades an authorization code for a token, proving the login was
+// started here with the PKCE verifier given to AuthCodeURL.
+func (s *SSO) Exchange(ctx context.Context, code, verifier string) (Token, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {s.cfg.RedirectURL},
+		"code_verifier": {verifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Token{}, fmt.Errorf("create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(s.cfg.ClientID), url.QueryEscape(s.cfg.ClientSecret))
+
+	var body struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    int64  `json:"expires_in"`
+	}
+	if err := s.do(req, &body); err != nil {
+		return Token{}, fmt.Errorf("exchange code: %w", err)
+	}
+	if body.AccessToken == "" {
+		return Token{}, fmt.Errorf("exchange code: %w: no access_token", ErrSSO)
+	}
+
+	token := Token{AccessToken: body.AccessToken, RefreshToken: body.RefreshToken}
+	if body.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(body.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+// Subject returns the account ID ("sub") the access token belongs to.
+func (s *SSO) Subject(ctx context.Context, accessToken string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.cfg.UserInfoURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("create userinfo request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
+
+	var body struct {
+		Sub string `json:"sub"`
+	}
+	if err := s.do(req, &body); err != nil {
+		return "", fmt.Errorf("get userinfo: %w", err)
+	}
+	if body.Sub == "" {
+		return "", fmt.Errorf("get userinfo: %w: no sub", ErrSSO)
+	}
+	return body.Sub, nil
+}
+
+// do sends req and decodes a 200 JSON response into v.
+func (s *SSO) do(req *http.Request, v any) error {
+	return doJSON(s.client, req, v, ErrSSO)
+}
+
+// doJSON sends req and decodes a 200 JSON response into v. A non-200 status
+// or undecodable body wraps rejected.
+func doJSON(client *http.Client, req *http.Request, v any, rejected error) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSSOResponseSize))
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: status %d", rejected, resp.StatusCode)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%w: decode response: %v", rejected, err)
+	}
+	return nil
+}
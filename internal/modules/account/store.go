@@ -0,0 +1,304 @@
+package account
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+)
+
+// ErrLinkExpired means a link state or nonce is unknown, already used, or
+// older than config.AccountLinkRequestTTL.
+var ErrLinkExpired = errors.New("account link request expired or unknown")
+
+// Token is the OAuth token the school SSO issued for a linked account.
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	Expiry       time.Time `json:"expiry,omitzero"` // Zero if the SSO did not say
+}
+
+// Link is a LINE user's linked school account.
+type Link struct {
+	UserID   string
+	Subject  string // SSO account ID (the userinfo "sub", e.g., a student ID)
+	Token    Token
+	LinkedAt time.Time
+}
+
+// Store persists account links and in-progress link requests in SQLite.
+// Tokens are encrypted at rest with NTPU_ACCOUNT_TOKEN_KEY.
+//
+// Links live in their own file (not the cache DB) so they survive snapshot
+// hot-swaps and cache rebuilds.
+type Store struct {
+	*storage.AuxStore
+	sealer *sealer
+}
+
+// Open opens (or creates) the account database at path. key encrypts the
+// stored SSO tokens.
+func Open(ctx context.Context, path, key string) (*Store, error) {
+	s, err := newSealer(key)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := storage.OpenAux(ctx, path, "account", initSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Store{AuxStore: storage.NewAuxStore(db), sealer: s}, nil
+}
+
+func initSchema(ctx context.Context, db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS account_link_states (
+		state TEXT PRIMARY KEY,
+		link_token TEXT NOT NULL,
+		code_verifier TEXT NOT NULL,
+		created_at INTEGER NOT NULL
+	) STRICT;
+	CREATE TABLE IF NOT EXISTS account_link_nonces (
+		nonce TEXT PRIMARY KEY,
+		subject TEXT NOT NULL,
+		token BLOB NOT NULL,
+		created_at INTEGER NOT NULL
+	) STRICT;
+	CREATE TABLE IF NOT EXISTS account_links (
+		user_id TEXT PRIMARY KEY,
+		subject TEXT NOT NULL,
+		token BLOB NOT NULL,
+		linked_at INTEGER NOT NULL
+	) STRICT;
+	`
+
+	if _, err := db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("create account tables: %w", err)
+	}
+
+	return nil
+}
+
+// SaveState records the OAuth state of a started link with its LINE link
+// token and PKCE code verifier. Expired states and nonces are pruned on the way.
+func (s *Store) SaveState(ctx context.Context, state, linkToken, verifier string) error {
+	db, err := s.Conn()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	cutoff := now.Add(-config.AccountLinkRequestTTL).Unix()
+	for _, query := range []string{
+		"DELETE FROM account_link_states WHERE created_at < ?",
+		"DELETE FROM account_link_nonces WHERE created_at < ?",
+	} {
+		if _, err := db.ExecContext(ctx, query, cutoff); err != nil {
+			return fmt.Errorf("prune link requests: %w", err)
+		}
+	}
+
+	if _, err := db.ExecContext(ctx,
+		"INSERT INTO account_link_states (state, link_token, code_verifier, created_at) VALUES (?, ?, ?, ?)",
+		state, linkToken, verifier, now.Unix(),
+	); err != nil {
+		return fmt.Errorf("save link state: %w", err)
+	}
+	return nil
+}
+
+// TakeState deletes the state and returns its link token and PKCE code
+// verifier. Returns ErrLinkExpired if the state is unknown, used, or expired.
+func (s *Store) TakeState(ctx context.Context, state string) (linkToken, verifier string, err error) {
+	db, err := s.Conn()
+	if err != nil {
+		return "", "", err
+	}
+
+	err = db.QueryRowContext(ctx,
+		"DELETE FROM account_link_states WHERE state = ? AND created_at >= ? RETURNING link_token, code_verifier",
+		state, time.Now().Add(-config.AccountLinkRequestTTL).Unix(),
+	).Scan(&linkToken, &verifier)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", "", ErrLinkExpired
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("take link state: %w", err)
+	}
+	return linkToken, verifier, nil
+}
+
+// SaveNonce records the account the SSO authenticated, waiting for LINE to
+// confirm which user the nonce belongs to.
+func (s *Store) SaveNonce(ctx context.Context, nonce, subject string, token Token) error {
+	db, err := s.Conn()
+	if err != nil {
+		return err
+	}
+
+	sealed, err := s.sealToken(token)
+	if err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx,
+		"INSERT INTO account_link_nonces (nonce, subject, token, created_at) VALUES (?, ?, ?, ?)",
+		nonce, subject, sealed, time.Now().Unix(),
+	); err != nil {
+		return fmt.Errorf("save link nonce: %w", err)
+	}
+	return nil
+}
+
+// CompleteLink links userID to the account behind nonce, replacing any
+// previous link of the user. Returns ErrLinkExpired if the nonce is unknown,
+// used, or expired.
+func (s *Store) CompleteLink(ctx context.Context, nonce, userID string) (*Link, error) {
+	db, err := s.Conn()
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var subject string
+	var sealed []byte
+	err = tx.QueryRowContext(ctx,
+		"DELETE FROM account_link_nonces WHERE nonce = ? AND created_at >= ? RETURNING subject, token",
+		nonce, time.Now().Add(-config.AccountLinkRequestTTL).Unix(),
+	).Scan(&subject, &sealed)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrLinkExpired
+	}
+	if err != nil {
+		return nil, fmt.Errorf("take link nonce: %w", err)
+	}
+
+	linkedAt := time.Now()
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO account_links (user_id, subject, token, linked_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(user_id) DO UPDATE SET subject = excluded.subject, token = excluded.token, linked_at = excluded.linked_at
+	`, userID, subject, sealed, linkedAt.Unix()); err != nil {
+		return nil, fmt.Errorf("save account link: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit account link: %w", err)
+	}
+
+	token, err := s.openToken(sealed)
+	if err != nil {
+		return nil, err
+	}
+	return &Link{UserID: userID, Subject: subject, Token: token, LinkedAt: time.Unix(linkedAt.Unix(), 0)}, nil
+}
+
+// Linked returns the user's link, or nil if the user has not linked an account.
+func (s *Store) Linked(ctx context.Context, userID string) (*Link, error) {
+	db, err := s.Conn()
+	if err != nil {
+		return nil, err
+	}
+
+	var subject string
+	var sealed []byte
+	var linkedAt int64
+	err = db.QueryRowContext(ctx,
+		"SELECT subject, token, linked_at FROM account_links WHERE user_id = ?", userID,
+	).Scan(&subject, &sealed, &linkedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get account link: %w", err)
+	}
+
+	token, err := s.openToken(sealed)
+	if err != nil {
+		return nil, err
+	}
+	return &Link{UserID: userID, Subject: subject, Token: token, LinkedAt: time.Unix(linkedAt, 0)}, nil
+}
+
+// StudentID returns the SSO subject (the student ID) the user linked, or ""
+// if the user has not linked an account. Unlike Linked it leaves the stored
+// tokens sealed.
+func (s *Store) StudentID(ctx context.Context, userID string) (string, error) {
+	db, err := s.Conn()
+	if err != nil {
+		return "", err
+	}
+
+	var subject string
+	err = db.QueryRowContext(ctx,
+		"SELECT subject FROM account_links WHERE user_id = ?", userID,
+	).Scan(&subject)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("get account link: %w", err)
+	}
+	return subject, nil
+}
+
+// Unlink deletes the user's link and stored tokens. Returns false if the
+// user had none.
+func (s *Store) Unlink(ctx context.Context, userID string) (bool, error) {
+	db, err := s.Conn()
+	if err != nil {
+		return false, err
+	}
+
+	result, err := db.ExecContext(ctx, "DELETE FROM account_links WHERE user_id = ?", userID)
+	if err != nil {
+		return false, fmt.Errorf("delete account link: %w", err)
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// EraseUser deletes the user's link and stored tokens.
+// Returns the number of rows deleted.
+func (s *Store) EraseUser(ctx context.Context, userID string) (int64, error) {
+	db, err := s.Conn()
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := db.ExecContext(ctx, "DELETE FROM account_links WHERE user_id = ?", userID)
+	if err != nil {
+		return 0, fmt.Errorf("erase account link: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+func (s *Store) sealToken(token Token) ([]byte, error) {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return nil, fmt.Errorf("encode token: %w", err)
+	}
+	return s.sealer.seal(data), nil
+}
+
+func (s *Store) openToken(sealed []byte) (Token, error) {
+	data, err := s.sealer.open(sealed)
+	if err != nil {
+		return Token{}, err
+	}
+	var token Token
+	if err := json.Unmarshal(data, &token); err != nil {
+		return Token{}, fmt.Errorf("decode token: %w", err)
+	}
+	return token, nil
+}
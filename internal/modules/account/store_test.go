@@ -0,0 +1,128 @@
+package account
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/moduletest"
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+)
+
+const testKey = "0123456789abcdef0123456789abcdef"
+
+// openTestStore opens a store with the test token key (see moduletest.OpenStore).
+func openTestStore(ctx context.Context, path string) (*Store, error) {
+	return Open(ctx, path, testKey)
+}
+
+func TestStore_State(t *testing.T) {
+	t.Parallel()
+	store := moduletest.OpenStore(t, openTestStore)
+	ctx := context.Background()
+
+	if err := store.SaveState(ctx, "s1", "lt1", "v1"); err != nil {
+		t.Fatalf("SaveState() error = %v", err)
+	}
+	linkToken, verifier, err := store.TakeState(ctx, "s1")
+	if err != nil || linkToken != "lt1" || verifier != "v1" {
+		t.Fatalf("TakeState() = (%q, %q, %v), want lt1, v1", linkToken, verifier, err)
+	}
+	// Single use
+	if _, _, err := store.TakeState(ctx, "s1"); !errors.Is(err, ErrLinkExpired) {
+		t.Errorf("TakeState() reuse error = %v, want ErrLinkExpired", err)
+	}
+	if _, _, err := store.TakeState(ctx, "unknown"); !errors.Is(err, ErrLinkExpired) {
+		t.Errorf("TakeState() unknown error = %v, want ErrLinkExpired", err)
+	}
+}
+
+func TestStore_LinkLifecycle(t *testing.T) {
+	t.Parallel()
+	store := moduletest.OpenStore(t, openTestStore)
+	ctx := context.Background()
+	token := Token{AccessToken: "at", RefreshToken: "rt", Expiry: time.Unix(2000000000, 0)}
+
+	if link, err := store.Linked(ctx, "U1"); err != nil || link != nil {
+		t.Fatalf("Linked() before link = (%v, %v), want nil", link, err)
+	}
+	if err := store.SaveNonce(ctx, "n1", "411012345", token); err != nil {
+		t.Fatalf("SaveNonce() error = %v", err)
+	}
+	if _, err := store.CompleteLink(ctx, "n1", "U1"); err != nil {
+		t.Fatalf("CompleteLink() error = %v", err)
+	}
+	if _, err := store.CompleteLink(ctx, "n1", "U2"); !errors.Is(err, ErrLinkExpired) {
+		t.Errorf("CompleteLink() reuse error = %v, want ErrLinkExpired", err)
+	}
+
+	link, err := store.Linked(ctx, "U1")
+	if err != nil || link == nil {
+		t.Fatalf("Linked() = (%v, %v), want link", link, err)
+	}
+	if link.Subject != "411012345" || link.Token.AccessToken != "at" || link.Token.RefreshToken != "rt" ||
+		!link.Token.Expiry.Equal(token.Expiry) {
+		t.Errorf("Linked() = %+v, want subject and token round-tripped", link)
+	}
+	if id, err := store.StudentID(ctx, "U1"); err != nil || id != "411012345" {
+		t.Errorf("StudentID() = (%q, %v), want 411012345", id, err)
+	}
+	if id, err := store.StudentID(ctx, "U2"); err != nil || id != "" {
+		t.Errorf("StudentID() unlinked = (%q, %v), want empty", id, err)
+	}
+
+	unlinked, err := store.Unlink(ctx, "U1")
+	if err != nil || !unlinked {
+		t.Fatalf("Unlink() = (%v, %v), want true", unlinked, err)
+	}
+	if unlinked, _ := store.Unlink(ctx, "U1"); unlinked {
+		t.Error("Unlink() twice = true, want false")
+	}
+	if link, _ := store.Linked(ctx, "U1"); link != nil {
+		t.Errorf("Linked() after unlink = %+v, want nil", link)
+	}
+}
+
+func TestStore_TokensEncrypted(t *testing.T) {
+	t.Parallel()
+	store := moduletest.OpenStore(t, openTestStore)
+	ctx := context.Background()
+
+	if err := store.SaveNonce(ctx, "n1", "sub", Token{AccessToken: "secret-access-token"}); err != nil {
+		t.Fatalf("SaveNonce() error = %v", err)
+	}
+	if _, err := store.CompleteLink(ctx, "n1", "U1"); err != nil {
+		t.Fatalf("CompleteLink() error = %v", err)
+	}
+
+	db, err := store.Conn()
+	if err != nil {
+		t.Fatalf("Conn() error = %v", err)
+	}
+	var raw []byte
+	if err := db.QueryRowContext(ctx, "SELECT token FROM account_links WHERE user_id = 'U1'").Scan(&raw); err != nil {
+		t.Fatalf("read raw token: %v", err)
+	}
+	if bytes.Contains(raw, []byte("secret-access-token")) {
+		t.Error("stored token is plaintext")
+	}
+
+	// A different key cannot read the token
+	store.sealer, _ = newSealer("another-key-another-key-another-k")
+	if _, err := store.Linked(ctx, "U1"); !errors.Is(err, errUnseal) {
+		t.Errorf("Linked() with wrong key error = %v, want errUnseal", err)
+	}
+}
+
+func TestStore_Closed(t *testing.T) {
+	t.Parallel()
+	store := moduletest.OpenStore(t, openTestStore)
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if _, err := store.Linked(context.Background(), "U1"); !errors.Is(err, storage.ErrDatabaseClosed) {
+		t.Errorf("Linked() after Close error = %v, want storage.ErrDatabaseClosed", err)
+	}
+}
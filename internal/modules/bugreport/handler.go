@@ -0,0 +1,185 @@
+// Package bugreport implements the 回報問題 command for the LINE bot. The
+// processor keeps each user's last 1:1 exchange (see bot.ExchangeRecorder);
+// 回報問題 files it as a bug report with a correlation ID and pushes it to
+// the chat admins, so "the bot answered wrong" reports come with the query,
+// the reply, and the request ID to look up in the logs.
+package bugreport
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/bot"
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
+	"github.com/garyellow/ntpu-linebot-go/internal/ctxutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/sticker"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+// Module constants
+const (
+	ModuleName = "bugreport"
+	senderName = "回報小幫手"
+)
+
+// reportKeyword files a report; text after it is the user's note.
+const reportKeyword = "回報問題"
+
+// reportKeywords are the commands that file a report.
+var reportKeywords = []string{reportKeyword, "回報錯誤", "問題回報"}
+
+// maxReportsPerDay limits how many reports one user can file in 24 hours,
+// since each one pushes to every admin.
+const maxReportsPerDay = 5
+
+// Pusher sends push messages (implemented by *lineapi.Client).
+type Pusher interface {
+	Push(ctx context.Context, req *messaging_api.PushMessageRequest) error
+}
+
+// Handler answers the 回報問題 command.
+type Handler struct {
+	bot.NoPostbacks // 回報問題 is plain text so it can be typed
+
+	store          *Store
+	pusher         Pusher
+	adminUserIDs   []string
+	stickerManager *sticker.Manager
+}
+
+// NewHandler creates a new bug report handler. Reports are pushed to
+// adminUserIDs through pusher.
+func NewHandler(store *Store, pusher Pusher, adminUserIDs []string, stickerManager *sticker.Manager) *Handler {
+	return &Handler{
+		store:          store,
+		pusher:         pusher,
+		adminUserIDs:   adminUserIDs,
+		stickerManager: stickerManager,
+	}
+}
+
+// Name returns the module name
+func (h *Handler) Name() string {
+	return ModuleName
+}
+
+// CanHandle returns true for 回報問題, with or without a note.
+func (h *Handler) CanHandle(text string) bool {
+	command, _ := splitCommand(text)
+	return slices.Contains(reportKeywords, command)
+}
+
+// HandleMessage files a report of the user's last exchange.
+func (h *Handler) HandleMessage(ctx context.Context, text string) []messaging_api.MessageInterface {
+	log := logger.FromContext(ctx)
+	sender := lineutil.GetSender(senderName, h.stickerManager)
+	_, note := splitCommand(text)
+
+	// The exchange is personal; group chats never record one
+	userID := ctxutil.GetUserID(ctx)
+	if userID == "" || ctxutil.GetChatID(ctx) != userID {
+		return lineutil.TextReply(sender, "🔒 回報問題僅限與本帳號的 1 對 1 聊天使用\n\n請私訊我重新查詢一次，再輸入「"+reportKeyword+"」")
+	}
+
+	n, err := h.store.CountSince(ctx, userID, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		log.WithError(err).ErrorContext(ctx, "Failed to count bug reports")
+		return lineutil.TextReply(sender, "❌ 回報失敗，請稍後再試")
+	}
+	if n >= maxReportsPerDay {
+		return lineutil.TextReply(sender, "⏳ 今天已回報多次，感謝協助！\n\n請明天再回報，或到 GitHub 開 issue 說明")
+	}
+
+	_, ok, err := h.store.LastExchange(ctx, userID)
+	if err != nil {
+		log.WithError(err).ErrorContext(ctx, "Failed to load last exchange")
+		return lineutil.TextReply(sender, "❌ 回報失敗，請稍後再試")
+	}
+	if !ok && note == "" {
+		return lineutil.TextReply(sender, "ℹ️ 找不到最近的查詢可以回報\n\n請重新查詢一次後輸入「"+reportKeyword+"」，或直接描述問題，例如「"+reportKeyword+" 微積分的上課時間不對」")
+	}
+
+	report, err := h.store.File(ctx, userID, note)
+	if err != nil {
+		log.WithError(err).ErrorContext(ctx, "Failed to file bug report")
+		return lineutil.TextReply(sender, "❌ 回報失敗，請稍後再試")
+	}
+	log.WithField("report_id", report.ID).
+		WithField("reported_request_id", report.Exchange.RequestID).
+		WithField("reported_module", report.Exchange.Module).
+		InfoContext(ctx, "Bug report filed")
+	h.notifyAdmins(ctx, report)
+
+	var b strings.Builder
+	b.WriteString("✅ 已收到回報，感謝協助改善！\n\n編號：" + report.ID)
+	if report.HasExchange() {
+		b.WriteString("\n回報的查詢：" + report.Exchange.Query)
+	}
+	if note == "" {
+		b.WriteString("\n\n💡 想補充哪裡有誤，可輸入「" + reportKeyword + " <說明>」")
+	}
+	return lineutil.TextReply(sender, b.String())
+}
+
+// notifyAdmins pushes the report to every chat admin. Failures are logged;
+// the report is already saved.
+func (h *Handler) notifyAdmins(ctx context.Context, report Report) {
+	if h.pusher == nil || len(h.adminUserIDs) == 0 {
+		return
+	}
+	// Don't let the reply deadline cut the push short
+	pushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), config.BugReportNotifyTimeout)
+	defer cancel()
+
+	msg := lineutil.NewTextMessage(formatAdminMessage(report))
+	for _, id := range h.adminUserIDs {
+		if err := h.pusher.Push(pushCtx, &messaging_api.PushMessageRequest{
+			To:       id,
+			Messages: []messaging_api.MessageInterface{msg},
+		}); err != nil {
+			logger.FromContext(ctx).WithError(err).WithField("report_id", report.ID).WarnContext(ctx, "Failed to push bug report")
+		}
+	}
+}
+
+// formatAdminMessage describes a report for the admins.
+func formatAdminMessage(r Report) string {
+	note := r.Note
+	if note == "" {
+		note = "（未填寫）"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "🐛 問題回報 %s\n\n使用者：%s\n說明：%s", r.ID, r.UserID, note)
+	if !r.HasExchange() {
+		b.WriteString("\n\n（沒有最近的查詢紀錄）")
+		return b.String()
+	}
+
+	ex := r.Exchange
+	module := ex.Module
+	if module == "" {
+		module = "說明訊息"
+	}
+	fmt.Fprintf(&b, "\n\n查詢：%s\n模組：%s（%s）\n回覆：%s\n耗時：%s\n時間：%s",
+		ex.Query, module, ex.Source, ex.Reply, ex.Duration.Round(time.Millisecond),
+		ex.Time.In(lineutil.GetTaipeiLocation()).Format("2006-01-02 15:04:05"))
+	if ex.RequestID != "" {
+		b.WriteString("\nrequest_id：" + ex.RequestID)
+	}
+	return b.String()
+}
+
+// splitCommand splits text into the command keyword and its trimmed argument.
+func splitCommand(text string) (string, string) {
+	command, arg, _ := strings.Cut(strings.TrimSpace(text), " ")
+	return command, strings.TrimSpace(arg)
+}
+
+// Ensure Handler implements bot.Handler
+var _ bot.Handler = (*Handler)(nil)
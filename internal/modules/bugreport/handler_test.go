@@ -0,0 +1,119 @@
+package bugreport
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/moduletest"
+	"github.com/garyellow/ntpu-linebot-go/internal/sticker"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+type fakePusher struct {
+	mu   sync.Mutex
+	reqs []*messaging_api.PushMessageRequest
+}
+
+func (p *fakePusher) Push(_ context.Context, req *messaging_api.PushMessageRequest) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.reqs = append(p.reqs, req)
+	return nil
+}
+
+func newTestHandler(t *testing.T) (*Handler, *fakePusher) {
+	t.Helper()
+	log := logger.New("error")
+	pusher := &fakePusher{}
+	return NewHandler(moduletest.OpenStore(t, Open), pusher, []string{"Uadmin"}, sticker.NewManager(nil, nil, log)), pusher
+}
+
+func TestHandler_CanHandle(t *testing.T) {
+	t.Parallel()
+	h, _ := newTestHandler(t)
+
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"回報問題", true},
+		{"回報問題 時間不對", true},
+		{" 回報錯誤 ", true},
+		{"問題回報", true},
+		{"回報", false},
+		{"課程 回報問題", false},
+	}
+	for _, tt := range tests {
+		if got := h.CanHandle(tt.input); got != tt.want {
+			t.Errorf("CanHandle(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestHandler_FilesAndNotifies(t *testing.T) {
+	t.Parallel()
+	h, pusher := newTestHandler(t)
+	ctx := moduletest.ChatContext("U1", "U1")
+
+	if err := h.store.RecordExchange(ctx, "U1", testExchange("課程 微積分")); err != nil {
+		t.Fatalf("RecordExchange() error = %v", err)
+	}
+
+	text := moduletest.Text(t, h.HandleMessage(ctx, "回報問題 時間不對"))
+	if !strings.Contains(text, "編號：") || !strings.Contains(text, "課程 微積分") {
+		t.Errorf("reply = %q, want the report ID and reported query", text)
+	}
+
+	reports, err := h.store.Recent(ctx, 10)
+	if err != nil || len(reports) != 1 {
+		t.Fatalf("Recent() = %d reports (err %v), want 1", len(reports), err)
+	}
+	if len(pusher.reqs) != 1 || pusher.reqs[0].To != "Uadmin" {
+		t.Fatalf("pushes = %+v, want one to the admin", pusher.reqs)
+	}
+	msg, ok := pusher.reqs[0].Messages[0].(*messaging_api.TextMessageV2)
+	if !ok {
+		t.Fatalf("pushed message is %T, want *TextMessageV2", pusher.reqs[0].Messages[0])
+	}
+	for _, want := range []string{reports[0].ID, "時間不對", "課程 微積分", "course（keyword）", "1.5s", "req-1"} {
+		if !strings.Contains(msg.Text, want) {
+			t.Errorf("admin message = %q, want it to contain %q", msg.Text, want)
+		}
+	}
+}
+
+func TestHandler_NothingToReport(t *testing.T) {
+	t.Parallel()
+	h, pusher := newTestHandler(t)
+
+	text := moduletest.Text(t, h.HandleMessage(moduletest.ChatContext("U1", "U1"), "回報問題"))
+	if !strings.Contains(text, "找不到最近的查詢") {
+		t.Errorf("reply = %q, want the no-exchange hint", text)
+	}
+	if len(pusher.reqs) != 0 {
+		t.Errorf("pushed %d messages, want none", len(pusher.reqs))
+	}
+}
+
+func TestHandler_RefusesGroupsAndLimitsReports(t *testing.T) {
+	t.Parallel()
+	h, pusher := newTestHandler(t)
+
+	if text := moduletest.Text(t, h.HandleMessage(moduletest.ChatContext("U1", "C1"), "回報問題 錯了")); !strings.Contains(text, "1 對 1") {
+		t.Errorf("group reply = %q, want the 1:1 notice", text)
+	}
+
+	ctx := moduletest.ChatContext("U1", "U1")
+	for range maxReportsPerDay {
+		h.HandleMessage(ctx, "回報問題 錯了")
+	}
+	if text := moduletest.Text(t, h.HandleMessage(ctx, "回報問題 錯了")); !strings.Contains(text, "今天已回報多次") {
+		t.Errorf("reply over the limit = %q, want the daily limit notice", text)
+	}
+	if len(pusher.reqs) != maxReportsPerDay {
+		t.Errorf("pushed %d messages, want %d", len(pusher.reqs), maxReportsPerDay)
+	}
+}
@@ -0,0 +1,314 @@
+package bugreport
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/bot"
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+)
+
+// maxNoteLength caps what the user writes about the problem.
+const maxNoteLength = 200
+
+// Report is a filed bug report: the user's note and a snapshot of the
+// exchange it is about.
+type Report struct {
+	ID        string // Correlation ID shown to the user and admins, e.g. "261016-K7Q2ZD"
+	UserID    string
+	Note      string       // What the user wrote after 回報問題 (may be empty)
+	Exchange  bot.Exchange // Zero when the user had no recent exchange
+	CreatedAt time.Time
+}
+
+// HasExchange reports whether the report carries an exchange snapshot.
+func (r Report) HasExchange() bool {
+	return r.Exchange.Query != ""
+}
+
+// Store persists each user's last exchange and filed bug reports in SQLite.
+//
+// Reports live in their own file (not the cache DB) so they survive snapshot
+// hot-swaps and cache rebuilds.
+type Store struct {
+	*storage.AuxStore
+}
+
+// Open opens (or creates) the bug report database at path.
+func Open(ctx context.Context, path string) (*Store, error) {
+	db, err := storage.OpenAux(ctx, path, "bug report", initSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Store{AuxStore: storage.NewAuxStore(db)}, nil
+}
+
+func initSchema(ctx context.Context, db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS last_exchanges (
+		user_id TEXT PRIMARY KEY,
+		query TEXT NOT NULL,
+		module TEXT NOT NULL,
+		source TEXT NOT NULL,
+		reply TEXT NOT NULL,
+		duration_ms INTEGER NOT NULL,
+		request_id TEXT NOT NULL,
+		created_at INTEGER NOT NULL
+	) STRICT;
+	CREATE TABLE IF NOT EXISTS bug_reports (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		note TEXT NOT NULL,
+		query TEXT NOT NULL,
+		module TEXT NOT NULL,
+		source TEXT NOT NULL,
+		reply TEXT NOT NULL,
+		duration_ms INTEGER NOT NULL,
+		request_id TEXT NOT NULL,
+		exchange_at INTEGER NOT NULL,
+		created_at INTEGER NOT NULL
+	) STRICT;
+	CREATE INDEX IF NOT EXISTS idx_bug_reports_user ON bug_reports(user_id, created_at);
+	CREATE INDEX IF NOT EXISTS idx_bug_reports_created ON bug_reports(created_at);
+	`
+
+	if _, err := db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("create bug report tables: %w", err)
+	}
+
+	return nil
+}
+
+// RecordExchange replaces the user's last exchange. It implements
+// bot.ExchangeRecorder.
+func (s *Store) RecordExchange(ctx context.Context, userID string, ex bot.Exchange) error {
+	db, err := s.Conn()
+	if err != nil {
+		return err
+	}
+
+	if _, err := db.ExecContext(ctx, `
+		INSERT INTO last_exchanges (user_id, query, module, source, reply, duration_ms, request_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(user_id) DO UPDATE SET
+			query = excluded.query, module = excluded.module, source = excluded.source,
+			reply = excluded.reply, duration_ms = excluded.duration_ms,
+			request_id = excluded.request_id, created_at = excluded.created_at
+	`, userID, ex.Query, ex.Module, ex.Source, ex.Reply, ex.Duration.Milliseconds(), ex.RequestID, ex.Time.Unix()); err != nil {
+		return fmt.Errorf("record exchange: %w", err)
+	}
+	return nil
+}
+
+// LastExchange returns the user's last exchange, or false if none was kept.
+func (s *Store) LastExchange(ctx context.Context, userID string) (bot.Exchange, bool, error) {
+	db, err := s.Conn()
+	if err != nil {
+		return bot.Exchange{}, false, err
+	}
+
+	var ex bot.Exchange
+	var durationMs, createdAt int64
+	err = db.QueryRowContext(ctx, `
+		SELECT query, module, source, reply, duration_ms, request_id, created_at
+		FROM last_exchanges WHERE user_id = ?
+	`, userID).Scan(&ex.Query, &ex.Module, &ex.Source, &ex.Reply, &durationMs, &ex.RequestID, &createdAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return bot.Exchange{}, false, nil
+	}
+	if err != nil {
+		return bot.Exchange{}, false, fmt.Errorf("get last exchange: %w", err)
+	}
+	ex.Duration = time.Duration(durationMs) * time.Millisecond
+	ex.Time = time.Unix(createdAt, 0)
+	return ex, true, nil
+}
+
+// File saves a report of the user's last exchange (if any) with note and
+// returns it. note is truncated to maxNoteLength characters.
+func (s *Store) File(ctx context.Context, userID, note string) (Report, error) {
+	db, err := s.Conn()
+	if err != nil {
+		return Report{}, err
+	}
+	if runes := []rune(note); len(runes) > maxNoteLength {
+		note = string(runes[:maxNoteLength])
+	}
+
+	ex, _, err := s.LastExchange(ctx, userID)
+	if err != nil {
+		return Report{}, err
+	}
+	now := time.Now()
+	report := Report{
+		ID:        newReportID(now),
+		UserID:    userID,
+		Note:      note,
+		Exchange:  ex,
+		CreatedAt: now,
+	}
+
+	var exchangeAt int64
+	if report.HasExchange() {
+		exchangeAt = ex.Time.Unix()
+	}
+	if _, err := db.ExecContext(ctx, `
+		INSERT INTO bug_reports (id, user_id, note, query, module, source, reply, duration_ms, request_id, exchange_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, report.ID, userID, note, ex.Query, ex.Module, ex.Source, ex.Reply, ex.Duration.Milliseconds(),
+		ex.RequestID, exchangeAt, report.CreatedAt.Unix()); err != nil {
+		return Report{}, fmt.Errorf("insert bug report: %w", err)
+	}
+	return report, nil
+}
+
+// CountSince returns how many reports the user filed at or after since.
+func (s *Store) CountSince(ctx context.Context, userID string, since time.Time) (int, error) {
+	db, err := s.Conn()
+	if err != nil {
+		return 0, err
+	}
+
+	var n int
+	if err := db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM bug_reports WHERE user_id = ? AND created_at >= ?", userID, since.Unix(),
+	).Scan(&n); err != nil {
+		return 0, fmt.Errorf("count bug reports: %w", err)
+	}
+	return n, nil
+}
+
+// Get returns the report with the given ID, or false if there is none.
+func (s *Store) Get(ctx context.Context, id string) (Report, bool, error) {
+	db, err := s.Conn()
+	if err != nil {
+		return Report{}, false, err
+	}
+
+	rows, err := db.QueryContext(ctx, selectReports+" WHERE id = ?", id)
+	if err != nil {
+		return Report{}, false, fmt.Errorf("get bug report: %w", err)
+	}
+	reports, err := scanReports(rows)
+	if err != nil || len(reports) == 0 {
+		return Report{}, false, err
+	}
+	return reports[0], true, nil
+}
+
+// Recent returns up to limit reports, newest first.
+func (s *Store) Recent(ctx context.Context, limit int) ([]Report, error) {
+	db, err := s.Conn()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, selectReports+" ORDER BY created_at DESC, id DESC LIMIT ?", limit)
+	if err != nil {
+		return nil, fmt.Errorf("list bug reports: %w", err)
+	}
+	return scanReports(rows)
+}
+
+const selectReports = `
+	SELECT id, user_id, note, query, module, source, reply, duration_ms, request_id, exchange_at, created_at
+	FROM bug_reports`
+
+func scanReports(rows *sql.Rows) ([]Report, error) {
+	defer func() { _ = rows.Close() }()
+
+	var reports []Report
+	for rows.Next() {
+		var r Report
+		var durationMs, exchangeAt, createdAt int64
+		if err := rows.Scan(&r.ID, &r.UserID, &r.Note, &r.Exchange.Query, &r.Exchange.Module, &r.Exchange.Source,
+			&r.Exchange.Reply, &durationMs, &r.Exchange.RequestID, &exchangeAt, &createdAt); err != nil {
+			return nil, fmt.Errorf("scan bug report: %w", err)
+		}
+		r.Exchange.Duration = time.Duration(durationMs) * time.Millisecond
+		if r.HasExchange() {
+			r.Exchange.Time = time.Unix(exchangeAt, 0)
+		}
+		r.CreatedAt = time.Unix(createdAt, 0)
+		reports = append(reports, r)
+	}
+	return reports, rows.Err()
+}
+
+// EraseUser deletes the user's last exchange and reports.
+// Returns the number of rows deleted.
+func (s *Store) EraseUser(ctx context.Context, userID string) (int64, error) {
+	db, err := s.Conn()
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var n int64
+	for _, query := range []string{
+		"DELETE FROM last_exchanges WHERE user_id = ?",
+		"DELETE FROM bug_reports WHERE user_id = ?",
+	} {
+		result, err := tx.ExecContext(ctx, query, userID)
+		if err != nil {
+			return 0, fmt.Errorf("erase bug reports: %w", err)
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("erase bug reports: %w", err)
+		}
+		n += affected
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit erase: %w", err)
+	}
+	return n, nil
+}
+
+// Prune deletes exchanges recorded before exchangesBefore and reports filed
+// before reportsBefore. Returns the number of rows deleted.
+func (s *Store) Prune(ctx context.Context, exchangesBefore, reportsBefore time.Time) (int64, error) {
+	db, err := s.Conn()
+	if err != nil {
+		return 0, err
+	}
+
+	var n int64
+	for _, prune := range []struct {
+		query  string
+		before time.Time
+	}{
+		{"DELETE FROM last_exchanges WHERE created_at < ?", exchangesBefore},
+		{"DELETE FROM bug_reports WHERE created_at < ?", reportsBefore},
+	} {
+		result, err := db.ExecContext(ctx, prune.query, prune.before.Unix())
+		if err != nil {
+			return n, fmt.Errorf("prune bug reports: %w", err)
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return n, fmt.Errorf("prune bug reports: %w", err)
+		}
+		n += affected
+	}
+	return n, nil
+}
+
+// newReportID returns a short correlation ID: the filing date (Asia/Taipei)
+// and six random characters, e.g. "261016-K7Q2ZD". Dates keep IDs readable
+// when quoted back in a chat or an issue.
+func newReportID(now time.Time) string {
+	return now.In(lineutil.GetTaipeiLocation()).Format("060102") + "-" + rand.Text()[:6]
+}
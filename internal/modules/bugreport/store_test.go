@@ -0,0 +1,120 @@
+package bugreport
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/bot"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/moduletest"
+)
+
+func testExchange(query string) bot.Exchange {
+	return bot.Exchange{
+		Query:     query,
+		Module:    "course",
+		Source:    "keyword",
+		Reply:     "[Flex] 課程列表",
+		Duration:  1500 * time.Millisecond,
+		RequestID: "req-1",
+		Time:      time.Now(),
+	}
+}
+
+func TestStore_LastExchange(t *testing.T) {
+	t.Parallel()
+	store := moduletest.OpenStore(t, Open)
+	ctx := context.Background()
+
+	if _, ok, err := store.LastExchange(ctx, "U1"); err != nil || ok {
+		t.Fatalf("LastExchange() before recording = (%v, %v), want (false, nil)", ok, err)
+	}
+	for _, q := range []string{"課程 微積分", "課程 線性代數"} {
+		if err := store.RecordExchange(ctx, "U1", testExchange(q)); err != nil {
+			t.Fatalf("RecordExchange(%q) error = %v", q, err)
+		}
+	}
+
+	ex, ok, err := store.LastExchange(ctx, "U1")
+	if err != nil || !ok {
+		t.Fatalf("LastExchange() = (%v, %v), want a kept exchange", ok, err)
+	}
+	if ex.Query != "課程 線性代數" || ex.Module != "course" || ex.Duration != 1500*time.Millisecond || ex.RequestID != "req-1" {
+		t.Errorf("LastExchange() = %+v, want the newest exchange", ex)
+	}
+}
+
+func TestStore_File(t *testing.T) {
+	t.Parallel()
+	store := moduletest.OpenStore(t, Open)
+	ctx := context.Background()
+
+	if err := store.RecordExchange(ctx, "U1", testExchange("課程 微積分")); err != nil {
+		t.Fatalf("RecordExchange() error = %v", err)
+	}
+	report, err := store.File(ctx, "U1", "時間不對")
+	if err != nil {
+		t.Fatalf("File() error = %v", err)
+	}
+	if !regexp.MustCompile(`^\d{6}-[A-Z2-7]{6}$`).MatchString(report.ID) {
+		t.Errorf("report ID = %q, want date-random", report.ID)
+	}
+
+	got, ok, err := store.Get(ctx, report.ID)
+	if err != nil || !ok {
+		t.Fatalf("Get() = (%v, %v), want the filed report", ok, err)
+	}
+	if got.Note != "時間不對" || got.Exchange.Query != "課程 微積分" || got.Exchange.Reply != "[Flex] 課程列表" {
+		t.Errorf("Get() = %+v, want the note and exchange snapshot", got)
+	}
+
+	// A note alone is still a report
+	noExchange, err := store.File(ctx, "U2", "學號查不到")
+	if err != nil {
+		t.Fatalf("File() without exchange error = %v", err)
+	}
+	if noExchange.HasExchange() {
+		t.Errorf("report without exchange = %+v, want no snapshot", noExchange)
+	}
+
+	recent, err := store.Recent(ctx, 10)
+	if err != nil || len(recent) != 2 {
+		t.Fatalf("Recent() = %d reports (err %v), want 2", len(recent), err)
+	}
+	if n, err := store.CountSince(ctx, "U1", time.Now().Add(-time.Hour)); err != nil || n != 1 {
+		t.Errorf("CountSince() = (%d, %v), want (1, nil)", n, err)
+	}
+}
+
+func TestStore_EraseUserAndPrune(t *testing.T) {
+	t.Parallel()
+	store := moduletest.OpenStore(t, Open)
+	ctx := context.Background()
+
+	for _, userID := range []string{"U1", "U2"} {
+		if err := store.RecordExchange(ctx, userID, testExchange("課程 微積分")); err != nil {
+			t.Fatalf("RecordExchange() error = %v", err)
+		}
+		if _, err := store.File(ctx, userID, ""); err != nil {
+			t.Fatalf("File() error = %v", err)
+		}
+	}
+
+	n, err := store.EraseUser(ctx, "U1")
+	if err != nil || n != 2 {
+		t.Fatalf("EraseUser() = (%d, %v), want (2, nil)", n, err)
+	}
+	if _, ok, _ := store.LastExchange(ctx, "U1"); ok {
+		t.Error("EraseUser() kept the last exchange")
+	}
+
+	// Exchanges expire before reports do
+	future := time.Now().Add(time.Hour)
+	if n, err := store.Prune(ctx, future, time.Now().Add(-time.Hour)); err != nil || n != 1 {
+		t.Fatalf("Prune() = (%d, %v), want (1, nil)", n, err)
+	}
+	if recent, _ := store.Recent(ctx, 10); len(recent) != 1 {
+		t.Errorf("Recent() after prune = %d reports, want 1", len(recent))
+	}
+}
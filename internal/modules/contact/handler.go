@@ -11,11 +11,13 @@ import (
 	"time"
 
 	"github.com/garyellow/ntpu-linebot-go/internal/bot"
+	"github.com/garyellow/ntpu-linebot-go/internal/data"
 	"github.com/garyellow/ntpu-linebot-go/internal/delta"
 	domerrors "github.com/garyellow/ntpu-linebot-go/internal/errors"
 	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
 	"github.com/garyellow/ntpu-linebot-go/internal/logger"
 	"github.com/garyellow/ntpu-linebot-go/internal/metrics"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/course"
 	"github.com/garyellow/ntpu-linebot-go/internal/scraper"
 	"github.com/garyellow/ntpu-linebot-go/internal/scraper/ntpu"
 	"github.com/garyellow/ntpu-linebot-go/internal/sliceutil"
@@ -36,7 +38,8 @@ type Handler struct {
 	maxContactsLimit int // Maximum contacts per search (from config)
 	deltaRecorder    delta.Recorder
 	seg              *stringutil.Segmenter
-	orgCache         *OrgCache // Short-TTL cache for org member lists
+	orgCache         *OrgCache  // Short-TTL cache for org member lists
+	roles            RoleLookup // nil when staff roles are disabled (all fields shown)
 
 	// matchers contains all pattern-handler pairs sorted by priority.
 	// Shared by CanHandle and HandleMessage for consistent routing.
@@ -56,32 +59,13 @@ func (h *Handler) Name() string {
 const (
 	ModuleName = "contact" // Module identifier for registration
 	senderName = "聯繫小幫手"
-
-	// Emergency phone numbers are hard-coded as constants for three critical reasons:
-	//   1. Availability: No external dependency (database, scraper) - instant access
-	//   2. Performance: Zero latency lookup for time-sensitive emergency situations
-	//   3. Reliability: Infrequent changes managed through code review process
-	//
-	// Design decision: Hard-coded constants over database/config for critical data
-	// Trade-off: Requires code deployment to update vs. runtime flexibility
-	//
-	// Emergency phone numbers (without hyphens for clipboard copy)
-	// 三峽校區
-	sanxiaNormalPhone    = "0286741111" // 總機
-	sanxia24HPhone       = "0226731949" // 24H緊急行政電話
-	sanxiaEmergencyPhone = "0226711234" // 24H急難救助電話(校安中心)
-	sanxiaGatePhone      = "0226733920" // 大門哨所
-	sanxiaDormPhone      = "0286716784" // 宿舍夜間緊急電話
-
-	// 臺北校區
-	taipeiNormalPhone    = "0225024654" // 總機
-	taipeiEmergencyPhone = "0225023671" // 24H急難救助電話
-
-	// 其他常用電話
-	policeStation = "0226730561" // 北大派出所
-	homHospital   = "0226723456" // 恩主公醫院
 )
 
+// contactSearchPage bounds each cache search of a contact query. The name and
+// fuzzy matches are merged and re-sorted before display, so both read the
+// largest page; a NextCursor means matches beyond it were left out.
+var contactSearchPage = storage.Page{Limit: storage.MaxSearchLimit}
+
 // Pattern priorities (lower = higher).
 const (
 	PriorityEmergency = 1 // Prefix "緊急"
@@ -118,6 +102,9 @@ var (
 	}
 
 	contactRegex = bot.BuildKeywordRegex(validContactKeywords)
+
+	// extensionRegex matches a campus extension number (NTPU uses 4-5 digits)
+	extensionRegex = regexp.MustCompile(`^\d{4,5}$`)
 )
 
 // NewHandler creates a new contact handler with required dependencies.
@@ -130,6 +117,7 @@ func NewHandler(
 	maxContactsLimit int,
 	deltaRecorder delta.Recorder,
 	seg *stringutil.Segmenter, // Shared segmenter for suggest (nil = disabled)
+	roles RoleLookup, // Viewer roles for staff-only fields (nil = disabled)
 ) *Handler {
 	h := &Handler{
 		db:               db,
@@ -140,6 +128,7 @@ func NewHandler(
 		maxContactsLimit: maxContactsLimit,
 		deltaRecorder:    deltaRecorder,
 		seg:              seg,
+		roles:            roles,
 		orgCache:         NewOrgCache(0),
 	}
 	h.initializeMatchers()
@@ -175,12 +164,22 @@ func (h *Handler) initializeMatchers() {
 
 // Intent names for NLU dispatcher
 const (
-	IntentSearch    = "search"    // Contact search by name/organization
-	IntentEmergency = "emergency" // Emergency phone numbers
+	IntentSearch       = "search"       // Contact search by name/organization
+	IntentEmergency    = "emergency"    // Emergency phone numbers
+	IntentOrganization = "organization" // Members of an organization
+	IntentExtension    = "extension"    // Reverse lookup by extension number
 )
 
 // DispatchIntent handles NLU-parsed intents for the contact module.
-// ... (DispatchIntent implementation remains the same)
+// It validates required parameters and calls the appropriate handler method.
+//
+// Supported intents:
+//   - "search": requires "query" param, calls handleContactSearch
+//   - "emergency": no params, calls handleEmergencyPhones
+//   - "organization": requires "organization" param, calls handleMembersQuery
+//   - "extension": requires a 4-5 digit "extension" param, calls handleExtensionQuery
+//
+// Returns error if intent is unknown or required parameters are missing or invalid.
 func (h *Handler) DispatchIntent(ctx context.Context, intent string, params map[string]string) ([]messaging_api.MessageInterface, error) {
 	// Validate parameters first (before logging) to support testing with nil dependencies
 	switch intent {
@@ -189,23 +188,39 @@ func (h *Handler) DispatchIntent(ctx context.Context, intent string, params map[
 		if !ok || query == "" {
 			return nil, fmt.Errorf("%w: query", domerrors.ErrMissingParameter)
 		}
-		if h.logger != nil {
-			h.logger.WithModule(ModuleName).
-				WithField("intent", intent).
-				WithField("query", query).
-				DebugContext(ctx, "Dispatching contact intent")
-		}
+		logger.FromContext(ctx).
+			WithField("query", query).
+			DebugContext(ctx, "Dispatching contact intent")
 		return h.handleContactSearch(ctx, query), nil
 
 	case IntentEmergency:
 		// Emergency intent doesn't require any parameters
-		if h.logger != nil {
-			h.logger.WithModule(ModuleName).
-				WithField("intent", IntentEmergency).
-				DebugContext(ctx, "Dispatching contact intent")
-		}
+		logger.FromContext(ctx).DebugContext(ctx, "Dispatching contact intent")
 		return h.handleEmergencyPhones(), nil
 
+	case IntentOrganization:
+		organization, ok := params["organization"]
+		if !ok || organization == "" {
+			return nil, fmt.Errorf("%w: organization", domerrors.ErrMissingParameter)
+		}
+		logger.FromContext(ctx).
+			WithField("organization", organization).
+			DebugContext(ctx, "Dispatching contact intent")
+		return h.handleMembersQuery(ctx, organization), nil
+
+	case IntentExtension:
+		extension, ok := params["extension"]
+		if !ok || extension == "" {
+			return nil, fmt.Errorf("%w: extension", domerrors.ErrMissingParameter)
+		}
+		if !extensionRegex.MatchString(extension) {
+			return nil, fmt.Errorf("%w: extension %q", domerrors.ErrInvalidInput, extension)
+		}
+		logger.FromContext(ctx).
+			WithField("extension", extension).
+			DebugContext(ctx, "Dispatching contact intent")
+		return h.handleExtensionQuery(ctx, extension), nil
+
 	default:
 		return nil, fmt.Errorf("%w: %s", domerrors.ErrUnknownIntent, intent)
 	}
@@ -239,7 +254,7 @@ func (h *Handler) CanHandle(text string) bool {
 
 // HandleMessage handles text messages for the contact module
 func (h *Handler) HandleMessage(ctx context.Context, text string) []messaging_api.MessageInterface {
-	log := h.logger.WithModule(ModuleName)
+	log := logger.FromContext(ctx)
 	text = strings.TrimSpace(text)
 
 	log.DebugContext(ctx, "Handling contact message")
@@ -287,7 +302,7 @@ func (h *Handler) handleEmptySearchTerm() []messaging_api.MessageInterface {
 
 // HandlePostback handles postback events for the contact module
 func (h *Handler) HandlePostback(ctx context.Context, data string) []messaging_api.MessageInterface {
-	log := h.logger.WithModule(ModuleName)
+	log := logger.FromContext(ctx)
 	log.DebugContext(ctx, "Handling contact postback")
 
 	// Strip module prefix if present (registry passes original data)
@@ -318,9 +333,6 @@ func (h *Handler) HandlePostback(ctx context.Context, data string) []messaging_a
 	return []messaging_api.MessageInterface{}
 }
 
-// emergencyImageURL is the static image URL shown at the end of emergency phone results.
-const emergencyImageURL = "https://raw.githubusercontent.com/garyellow/ntpu-linebot-go/main/assets/emergency.png"
-
 // precomputeEmergency builds the static emergency phones FlexBubble and QuickReply
 // once during handler construction, avoiding repeated allocations per request.
 func (h *Handler) precomputeEmergency() {
@@ -345,43 +357,45 @@ func (h *Handler) precomputeEmergency() {
 		Label: "校園緊急聯絡",
 		Color: lineutil.ColorHeaderEmergency,
 	})
-	sanxiaBox := lineutil.NewFlexBox("vertical",
-		lineutil.NewFlexText("📍 三峽校區").WithWeight("bold").WithSize("md").WithColor(lineutil.ColorText).WithMargin("lg").FlexText,
-		lineutil.NewFlexSeparator().WithMargin("sm").FlexSeparator,
-		createRow("📞", "總機", sanxiaNormalPhone, ""),
-		createRow("🏢", "24H緊急行政電話", sanxia24HPhone, ""),
-		createRow("🚨", "24H急難救助專線", sanxiaEmergencyPhone, lineutil.ColorDanger),
-		createRow("🚪", "大門哨所", sanxiaGatePhone, ""),
-		createRow("🏠", "宿舍夜間緊急電話", sanxiaDormPhone, ""),
-		createRow("📱", "遺失物諮詢(分機66223)", sanxiaNormalPhone, ""),
-	).WithSpacing("sm").WithMargin("sm").FlexBox
-	taipeiBox := lineutil.NewFlexBox("vertical",
-		lineutil.NewFlexText("📍 臺北校區").WithWeight("bold").WithSize("md").WithColor(lineutil.ColorText).WithMargin("lg").FlexText,
-		lineutil.NewFlexSeparator().WithMargin("sm").FlexSeparator,
-		createRow("📞", "總機", taipeiNormalPhone, ""),
-		createRow("🚨", "24H急難救助專線", taipeiEmergencyPhone, lineutil.ColorDanger),
-	).WithSpacing("sm").WithMargin("sm").FlexBox
-	externalBox := lineutil.NewFlexBox("vertical",
-		lineutil.NewFlexText("🚨 社會安全").WithWeight("bold").WithSize("md").WithColor(lineutil.ColorDanger).WithMargin("lg").FlexText,
-		lineutil.NewFlexSeparator().WithMargin("sm").FlexSeparator,
-		createRow("👮", "警察局", "110", lineutil.ColorDanger),
-		createRow("🚒", "消防/救護", "119", lineutil.ColorDanger),
-		createRow("📱", "緊急救難專線", "112", lineutil.ColorDanger),
-		createRow("🚔", "北大派出所", policeStation, ""),
-		createRow("🏥", "恩主公醫院", homHospital, ""),
-	).WithSpacing("sm").WithMargin("sm").FlexBox
-	footer := lineutil.NewFlexBox("vertical",
-		lineutil.NewFlexButton(lineutil.NewURIAction("🚨 撥打三峽專線", "tel:"+sanxiaEmergencyPhone)).WithStyle("primary").WithColor(lineutil.ColorButtonDanger).WithHeight("sm").FlexButton,
-		lineutil.NewFlexButton(lineutil.NewClipboardAction("📋 複製三峽專線", sanxiaEmergencyPhone)).WithStyle("secondary").WithHeight("sm").FlexButton,
-		lineutil.NewFlexButton(lineutil.NewURIAction("🚨 撥打臺北專線", "tel:"+taipeiEmergencyPhone)).WithStyle("primary").WithColor(lineutil.ColorButtonDanger).WithHeight("sm").FlexButton,
-		lineutil.NewFlexButton(lineutil.NewClipboardAction("📋 複製臺北專線", taipeiEmergencyPhone)).WithStyle("secondary").WithHeight("sm").FlexButton,
+	// Emergency phones come from the embedded campus dataset: no database or
+	// scraper dependency, so they answer instantly even on first boot.
+	sections := make([]messaging_api.FlexComponentInterface, 0, len(data.Campus.Emergency)+1)
+	sections = append(sections, bodyLabel.FlexBox)
+	var footerButtons []messaging_api.FlexComponentInterface
+	for _, sec := range data.Campus.Emergency {
+		title := lineutil.NewFlexText(lineutil.Icon(lineutil.IconLocation) + " " + sec.Name).WithColor(lineutil.ColorText)
+		if sec.External {
+			title = lineutil.NewFlexText("🚨 " + sec.Name).WithColor(lineutil.ColorDanger)
+		}
+		rows := []messaging_api.FlexComponentInterface{
+			title.WithWeight("bold").WithSize("md").WithMargin("lg").FlexText,
+			lineutil.NewFlexSeparator().WithMargin("sm").FlexSeparator,
+		}
+		for _, p := range sec.Phones {
+			color := ""
+			if p.Urgent {
+				color = lineutil.ColorDanger
+			}
+			rows = append(rows, createRow(p.Icon, p.Label, p.Number, color))
+		}
+		sections = append(sections, lineutil.NewFlexBox("vertical", rows...).WithSpacing("sm").WithMargin("sm").FlexBox)
+
+		if sec.Hotline != "" {
+			footerButtons = append(footerButtons,
+				lineutil.NewFlexButton(lineutil.NewURIAction("🚨 撥打"+sec.ShortName+"專線", "tel:"+sec.Hotline)).WithStyle("primary").WithColor(lineutil.ColorButtonDanger).WithHeight("sm").FlexButton,
+				lineutil.NewFlexButton(lineutil.NewClipboardAction("📋 複製"+sec.ShortName+"專線", sec.Hotline)).WithStyle("secondary").WithHeight("sm").FlexButton,
+			)
+		}
+	}
+	footerButtons = append(footerButtons,
 		lineutil.NewFlexButton(lineutil.NewURIAction("ℹ️ 查看更多", "https://new.ntpu.edu.tw/safety")).WithStyle("primary").WithColor(lineutil.ColorButtonExternal).WithHeight("sm").FlexButton,
-	).WithSpacing("sm")
+	)
+	footer := lineutil.NewFlexBox("vertical", footerButtons...).WithSpacing("sm")
 
 	bubble := lineutil.NewFlexBubble(
 		header,
 		nil,
-		lineutil.NewFlexBox("vertical", bodyLabel.FlexBox, sanxiaBox, taipeiBox, externalBox),
+		lineutil.NewFlexBox("vertical", sections...),
 		footer,
 	)
 	h.prebuiltEmergencyBubble = bubble.FlexBubble
@@ -398,9 +412,10 @@ func (h *Handler) handleEmergencyPhones() []messaging_api.MessageInterface {
 	flexMsg := lineutil.NewFlexMessage("緊急聯絡電話", h.prebuiltEmergencyBubble)
 	flexMsg.Sender = sender
 
+	imageURL := lineutil.ProxyImageURL(data.Assets.URL(data.AssetEmergency))
 	imgMsg := &messaging_api.ImageMessage{
-		OriginalContentUrl: emergencyImageURL,
-		PreviewImageUrl:    emergencyImageURL,
+		OriginalContentUrl: imageURL,
+		PreviewImageUrl:    imageURL,
 	}
 	imgMsg.Sender = sender
 	imgMsg.QuickReply = h.prebuiltEmergencyQR
@@ -435,7 +450,7 @@ func (h *Handler) handleEmergencyPhones() []messaging_api.MessageInterface {
 //   - SQL fuzzy search uses dynamic LIKE clauses for character-set matching (memory efficient)
 //   - Search variants only affect scraping, not cache lookups
 func (h *Handler) handleContactSearch(ctx context.Context, searchTerm string) []messaging_api.MessageInterface {
-	log := h.logger.WithModule(ModuleName)
+	log := logger.FromContext(ctx)
 	startTime := time.Now()
 	sender := lineutil.GetSender(senderName, h.stickerManager)
 
@@ -443,7 +458,7 @@ func (h *Handler) handleContactSearch(ctx context.Context, searchTerm string) []
 
 	// Step 1: Try SQL LIKE search first (fast path for exact substrings)
 	// SQL LIKE searches in: name, title
-	sqlContacts, err := h.db.SearchContactsByName(ctx, searchTerm)
+	sqlContacts, err := h.db.SearchContactsByNamePage(ctx, searchTerm, contactSearchPage)
 	if err != nil {
 		log.WithError(err).ErrorContext(ctx, "Failed to search contacts in cache")
 		h.metrics.RecordScraperRequest(ModuleName, "error", time.Since(startTime).Seconds())
@@ -451,14 +466,19 @@ func (h *Handler) handleContactSearch(ctx context.Context, searchTerm string) []
 			lineutil.ErrorMessageWithQuickReply("查詢聯絡資訊時發生問題", sender, "聯絡 "+searchTerm),
 		}
 	}
-	contacts = append(contacts, sqlContacts...)
+	contacts = append(contacts, sqlContacts.Items...)
 
 	// Step 2: SQL-based fuzzy character-set matching (memory efficient)
 	// Uses dynamic LIKE clauses instead of loading all contacts into memory
 	// Searches more fields: name, title, organization, superior
-	fuzzyContacts, err := h.db.SearchContactsFuzzy(ctx, searchTerm)
-	if err == nil && len(fuzzyContacts) > 0 {
-		contacts = append(contacts, fuzzyContacts...)
+	fuzzyContacts, err := h.db.SearchContactsFuzzyPage(ctx, searchTerm, contactSearchPage)
+	if err == nil && len(fuzzyContacts.Items) > 0 {
+		contacts = append(contacts, fuzzyContacts.Items...)
+	}
+	if sqlContacts.NextCursor != "" || fuzzyContacts.NextCursor != "" {
+		log.WithField("search_term", searchTerm).
+			WithField("total", max(sqlContacts.TotalCount, fuzzyContacts.TotalCount)).
+			DebugContext(ctx, "Contact search matched more than one page")
 	}
 
 	// Deduplicate results by UID (SQL LIKE and fuzzy may find overlapping results)
@@ -466,7 +486,7 @@ func (h *Handler) handleContactSearch(ctx context.Context, searchTerm string) []
 
 	// If found in cache, return results
 	if len(contacts) > 0 {
-		h.metrics.RecordCacheHit(ModuleName)
+		h.metrics.RecordCacheHit(ctx, ModuleName)
 		log.WithField("search_term", searchTerm).
 			WithField("count", len(contacts)).
 			DebugContext(ctx, "Contact search cache hit")
@@ -475,7 +495,8 @@ func (h *Handler) handleContactSearch(ctx context.Context, searchTerm string) []
 
 	// Cache miss - scrape from website
 	// Try multiple search variants to increase hit rate
-	h.metrics.RecordCacheMiss(ModuleName)
+	h.metrics.RecordCacheMiss(ctx, ModuleName)
+	lineutil.ShowLoading(ctx)
 	log.WithField("search_term", searchTerm).
 		DebugContext(ctx, "Contact search cache miss, scraping")
 
@@ -533,6 +554,7 @@ func (h *Handler) handleContactSearch(ctx context.Context, searchTerm string) []
 
 	if len(contacts) == 0 {
 		h.metrics.RecordScraperRequest(ModuleName, "not_found", time.Since(startTime).Seconds())
+		h.metrics.RecordZeroResults(ModuleName)
 
 		helpText := fmt.Sprintf(
 			"🔍 查無「%s」的聯絡資料\n\n💡 建議\n• 確認關鍵字拼寫是否正確\n• 嘗試使用單位全名或簡稱\n• 若查詢人名，可嘗試只輸入姓氏",
@@ -565,7 +587,7 @@ func (h *Handler) handleContactSearch(ctx context.Context, searchTerm string) []
 
 	// Save to cache
 	for i := range contacts {
-		if err := h.db.SaveContact(ctx, &contacts[i]); err != nil {
+		if err := h.saveContact(ctx, &contacts[i]); err != nil {
 			log.WithError(err).
 				WithField("contact_name", contacts[i].Name).
 				WarnContext(ctx, "Failed to save contact to cache")
@@ -576,11 +598,21 @@ func (h *Handler) handleContactSearch(ctx context.Context, searchTerm string) []
 	return h.formatContactResultsWithSearch(ctx, contacts, searchTerm)
 }
 
+// saveContact caches a scraped contact. Contacts that fail
+// ntpu.ValidateContact are not cached, so a bad parse is not served from the
+// cache until it expires.
+func (h *Handler) saveContact(ctx context.Context, contact *storage.Contact) error {
+	if err := ntpu.ValidateContact(contact); err != nil {
+		return err
+	}
+	return h.db.SaveContact(ctx, contact)
+}
+
 // handleMembersQuery handles queries for organization members
 // Uses cache first, falls back to scraping if not found
 // Returns all individuals belonging to the specified organization
 func (h *Handler) handleMembersQuery(ctx context.Context, orgName string) []messaging_api.MessageInterface {
-	log := h.logger.WithModule(ModuleName)
+	log := logger.FromContext(ctx)
 	startTime := time.Now()
 	sender := lineutil.GetSender(senderName, h.stickerManager)
 
@@ -589,7 +621,7 @@ func (h *Handler) handleMembersQuery(ctx context.Context, orgName string) []mess
 
 	// Step 1: Check short-TTL application cache
 	if cached, ok := h.orgCache.GetCached(orgName); ok {
-		h.metrics.RecordCacheHit(ModuleName)
+		h.metrics.RecordCacheHit(ctx, ModuleName)
 		log.WithField("organization", orgName).
 			WithField("count", len(cached)).
 			DebugContext(ctx, "Organization members application cache hit")
@@ -618,7 +650,7 @@ func (h *Handler) handleMembersQuery(ctx context.Context, orgName string) []mess
 	}
 
 	if len(individuals) > 0 {
-		h.metrics.RecordCacheHit(ModuleName)
+		h.metrics.RecordCacheHit(ctx, ModuleName)
 		log.WithField("organization", orgName).
 			WithField("count", len(individuals)).
 			DebugContext(ctx, "Organization members cache hit")
@@ -627,7 +659,8 @@ func (h *Handler) handleMembersQuery(ctx context.Context, orgName string) []mess
 	}
 
 	// Step 3: Cache miss - try scraping
-	h.metrics.RecordCacheMiss(ModuleName)
+	h.metrics.RecordCacheMiss(ctx, ModuleName)
+	lineutil.ShowLoading(ctx)
 	log.WithField("organization", orgName).
 		DebugContext(ctx, "Organization members cache miss, scraping")
 
@@ -657,7 +690,7 @@ func (h *Handler) handleMembersQuery(ctx context.Context, orgName string) []mess
 	// Save to cache and filter individuals
 	individuals = make([]storage.Contact, 0)
 	for _, c := range scrapedContacts {
-		if err := h.db.SaveContact(ctx, c); err != nil {
+		if err := h.saveContact(ctx, c); err != nil {
 			log.WithError(err).
 				WithField("contact_name", c.Name).
 				WarnContext(ctx, "Failed to save contact to cache")
@@ -670,6 +703,7 @@ func (h *Handler) handleMembersQuery(ctx context.Context, orgName string) []mess
 
 	if len(individuals) == 0 {
 		h.metrics.RecordScraperRequest(ModuleName, "not_found", time.Since(startTime).Seconds())
+		h.metrics.RecordZeroResults(ModuleName)
 		msg := lineutil.NewTextMessageWithConsistentSender(
 			fmt.Sprintf("🔍 查無「%s」的成員資料\n\n💡 該單位可能尚未建立成員資訊", orgName),
 			sender,
@@ -683,6 +717,38 @@ func (h *Handler) handleMembersQuery(ctx context.Context, orgName string) []mess
 	return h.formatContactResults(ctx, individuals)
 }
 
+// handleExtensionQuery looks up who owns an extension number.
+// Only the cache is searched: the directory website cannot be queried by extension,
+// so numbers of units nobody has looked up yet are not found.
+func (h *Handler) handleExtensionQuery(ctx context.Context, extension string) []messaging_api.MessageInterface {
+	log := logger.FromContext(ctx)
+	sender := lineutil.GetSender(senderName, h.stickerManager)
+
+	res, err := h.db.SearchContactsByExtensionPage(ctx, extension, contactSearchPage)
+	if err != nil {
+		log.WithError(err).ErrorContext(ctx, "Failed to search contacts by extension")
+		return []messaging_api.MessageInterface{
+			lineutil.ErrorMessageWithQuickReply("查詢分機時發生問題", sender, "分機 "+extension),
+		}
+	}
+	contacts := res.Items
+
+	if len(contacts) == 0 {
+		h.metrics.RecordZeroResults(ModuleName)
+		msg := lineutil.NewTextMessageWithConsistentSender(
+			fmt.Sprintf("🔍 查無分機「%s」的聯絡資料\n\n💡 可改用單位或姓名查詢，例如：聯絡 資工系", extension),
+			sender,
+		)
+		msg.QuickReply = lineutil.NewQuickReply(lineutil.QuickReplyContactNav())
+		return []messaging_api.MessageInterface{msg}
+	}
+
+	log.WithField("extension", extension).
+		WithField("count", len(contacts)).
+		DebugContext(ctx, "Extension query found contacts")
+	return h.formatContactResults(ctx, contacts)
+}
+
 // formatContactResults formats contact results as LINE messages
 func (h *Handler) formatContactResults(ctx context.Context, contacts []storage.Contact) []messaging_api.MessageInterface {
 	return h.formatContactResultsWithSearch(ctx, contacts, "")
@@ -763,20 +829,31 @@ func (h *Handler) formatContactResultsWithSearch(ctx context.Context, contacts [
 		return 0
 	})
 
+	contacts = h.applyVisibility(ctx, contacts)
+
 	sender := lineutil.GetSender(senderName, h.stickerManager)
 	var messages []messaging_api.MessageInterface
 
-	// Track if we hit the limit (likely more results available) - warning added at end
-	truncated := h.maxContactsLimit > 0 && len(contacts) >= h.maxContactsLimit
+	// Notices added at the end: 國定假日 note, and a warning if we hit the
+	// limit (likely more results available)
+	var notices []string
+	if notice := holidayNotice(time.Now()); notice != "" {
+		notices = append(notices, "📅 "+notice)
+	}
+	if h.maxContactsLimit > 0 && len(contacts) >= h.maxContactsLimit {
+		h.metrics.RecordTruncated(ModuleName)
+		notices = append(notices,
+			fmt.Sprintf("⚠️ 搜尋結果達到上限 %d 筆\n可能有更多結果未顯示，建議使用更精確的關鍵字搜尋", h.maxContactsLimit))
+	}
 
-	// Reserve 1 message slot for warning if truncated (LINE API: max 5 messages)
+	// Reserve 1 message slot for notices (LINE API: max 5 messages)
 	maxMessages := 5
-	if truncated {
+	if len(notices) > 0 {
 		maxMessages = 4
 	}
 
 	for i := 0; i < len(contacts); i += lineutil.MaxBubblesPerCarousel {
-		// Limit to maxMessages (LINE reply limit, minus 1 if truncated for warning)
+		// Limit to maxMessages (LINE reply limit, minus 1 for notices)
 		if len(messages) >= maxMessages {
 			break
 		}
@@ -824,28 +901,28 @@ func (h *Handler) formatContactResultsWithSearch(ctx context.Context, contacts [
 			body.AddComponent(lineutil.NewBodyLabel(bodyLabel).FlexBox)
 
 			// Add Title if available (secondary field, single-line)
-			if c.Title != "" && c.Type != "organization" {
-				titleRow := lineutil.NewInfoRow("🏷️", "職稱", c.Title, lineutil.CarouselInfoRowStyle())
+			if title := contactTitle(c); title != "" && c.Type != "organization" {
+				titleRow := lineutil.NewInfoRow(lineutil.Icon(lineutil.IconJobTitle), "職稱", title, lineutil.CarouselInfoRowStyle())
 				body.AddComponent(titleRow.FlexBox)
 			}
 
 			// Organization / Superior - use multi-line style for potentially long org names
 			if c.Type == "organization" && c.Superior != "" {
-				body.AddInfoRow("🏢", "上級單位", c.Superior, lineutil.CarouselInfoRowStyleMultiLine())
+				body.AddInfoRow(lineutil.Icon(lineutil.IconOrganization), "上級單位", c.Superior, lineutil.CarouselInfoRowStyleMultiLine())
 			} else if c.Organization != "" {
-				body.AddInfoRow("🏢", "所屬單位", c.Organization, lineutil.CarouselInfoRowStyleMultiLine())
+				body.AddInfoRow(lineutil.Icon(lineutil.IconOrganization), "所屬單位", c.Organization, lineutil.CarouselInfoRowStyleMultiLine())
 			}
 
 			// Contact Info - Display full phone OR just extension (important, keep bold)
 			if c.Phone != "" {
-				body.AddInfoRow("📞", "聯絡電話", c.Phone, lineutil.BoldInfoRowStyle())
+				body.AddInfoRow(lineutil.Icon(lineutil.IconPhone), "聯絡電話", c.Phone, lineutil.BoldInfoRowStyle())
 			} else if c.Extension != "" {
-				body.AddInfoRow("☎️", "分機號碼", c.Extension, lineutil.BoldInfoRowStyle())
+				body.AddInfoRow(lineutil.Icon(lineutil.IconExtension), "分機號碼", c.Extension, lineutil.BoldInfoRowStyle())
 			}
 
 			// Contact Info - Location and Email (secondary fields, single-line)
-			body.AddInfoRowIf("📍", "辦公位置", c.Location, lineutil.CarouselInfoRowStyle())
-			body.AddInfoRowIf("✉️", "電子郵件", c.Email, lineutil.CarouselInfoRowStyle())
+			body.AddInfoRowIf(lineutil.Icon(lineutil.IconLocation), "辦公位置", c.Location, lineutil.CarouselInfoRowStyle())
+			body.AddInfoRowIf(lineutil.Icon(lineutil.IconEmail), "電子郵件", c.Email, lineutil.CarouselInfoRowStyle())
 
 			// Add cache time hint (unobtrusive, right-aligned)
 			if hint := lineutil.NewCacheTimeHint(c.CachedAt); hint != nil {
@@ -874,8 +951,10 @@ func (h *Handler) formatContactResultsWithSearch(ctx context.Context, contacts [
 			// For individuals, check if they have matching courses (skip for organizations)
 			if c.Type == "individual" && c.Name != "" {
 				// Query courses by teacher name to check if this person teaches any courses
-				matchingCourses, err := h.db.SearchCoursesByTeacher(ctx, c.Name)
-				if err == nil && len(matchingCourses) > 0 {
+				// One row is enough: only the match count is needed
+				matchingCourses, err := h.db.SearchCoursesByTeacherPage(ctx, c.Name, storage.Page{Limit: 1})
+				teacherPostback, pbErr := course.TeacherPostback(c.Name)
+				if err == nil && pbErr == nil && matchingCourses.TotalCount > 0 {
 					// Add 授課課程 button
 					// DisplayText: 查看 {Name} 授課課程 (declarative style)
 					displayText := "查看 " + c.Name + " 授課課程"
@@ -886,7 +965,7 @@ func (h *Handler) formatContactResultsWithSearch(ctx context.Context, contacts [
 					}
 					row0Buttons = append(row0Buttons,
 						lineutil.NewFlexButton(
-							lineutil.NewPostbackActionWithDisplayText("📚 授課課程", displayText, fmt.Sprintf("course:授課課程%s%s", bot.PostbackSplitChar, c.Name)),
+							lineutil.NewPostbackActionWithDisplayText("📚 授課課程", displayText, teacherPostback),
 						).WithStyle("primary").WithColor(lineutil.ColorButtonInternal).WithHeight("sm"))
 				}
 			}
@@ -909,7 +988,7 @@ func (h *Handler) formatContactResultsWithSearch(ctx context.Context, contacts [
 					lineutil.NewFlexButton(lineutil.NewClipboardAction("📋 複製電話", c.Phone)).WithStyle("secondary").WithHeight("sm"))
 			} else if c.Extension != "" {
 				// Only short extension (< 5 digits), can still dial via main + extension
-				telURI := lineutil.BuildTelURI(sanxiaNormalPhone, c.Extension)
+				telURI := lineutil.BuildTelURI(data.Campus.Switchboard, c.Extension)
 				row1Buttons = append(row1Buttons,
 					lineutil.NewFlexButton(lineutil.NewURIAction("📞 撥打電話", telURI)).WithStyle("primary").WithColor(lineutil.ColorButtonAction).WithHeight("sm"))
 				row1Buttons = append(row1Buttons,
@@ -980,13 +1059,8 @@ func (h *Handler) formatContactResultsWithSearch(ctx context.Context, contacts [
 		messages = append(messages, msg)
 	}
 
-	// Append warning message at the end if results were truncated
-	if truncated {
-		warningMsg := lineutil.NewTextMessageWithConsistentSender(
-			fmt.Sprintf("⚠️ 搜尋結果達到上限 %d 筆\n可能有更多結果未顯示，建議使用更精確的關鍵字搜尋", h.maxContactsLimit),
-			sender,
-		)
-		messages = append(messages, warningMsg)
+	if len(notices) > 0 {
+		messages = append(messages, lineutil.NewTextMessageWithConsistentSender(strings.Join(notices, "\n\n"), sender))
 	}
 
 	// Add Quick Reply to the last message
@@ -1085,6 +1159,18 @@ func (h *Handler) buildSearchVariants(searchTerm string) []string {
 	return uniqueVariants
 }
 
+// contactTitle returns the job title to display, marking entries from the
+// part-time faculty listing as 兼任 when the title does not already say so.
+func contactTitle(c storage.Contact) string {
+	if !c.PartTime || strings.Contains(c.Title, "兼任") {
+		return c.Title
+	}
+	if c.Title == "" {
+		return "兼任教師"
+	}
+	return "兼任" + c.Title
+}
+
 // countMatchRunes counts how many runes from searchTerm appear in the contact's fields.
 // Used for sorting individuals by relevance - higher match count = more relevant.
 // Fields checked: name, title, organization, superior
@@ -1138,12 +1224,12 @@ func (h *Handler) suggestSimilarContacts(ctx context.Context, keyword string, ma
 		if tried > 3 {
 			break // Bound DB queries to avoid excessive scanning
 		}
-		contacts, err := h.db.SearchContactsByName(ctx, word)
+		res, err := h.db.SearchContactsByNamePage(ctx, word, storage.Page{})
 		if err != nil {
 			continue
 		}
 
-		for _, c := range contacts {
+		for _, c := range res.Items {
 			label := c.Name
 			if c.Type == "organization" && c.Organization != "" {
 				label = c.Organization
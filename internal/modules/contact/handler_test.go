@@ -3,47 +3,21 @@ package contact
 import (
 	"context"
 	"fmt"
-	"path/filepath"
 	"strings"
 	"testing"
-	"time"
 
 	"github.com/garyellow/ntpu-linebot-go/internal/bot"
-	"github.com/garyellow/ntpu-linebot-go/internal/logger"
-	"github.com/garyellow/ntpu-linebot-go/internal/metrics"
-	"github.com/garyellow/ntpu-linebot-go/internal/scraper"
-	"github.com/garyellow/ntpu-linebot-go/internal/sticker"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/moduletest"
 	"github.com/garyellow/ntpu-linebot-go/internal/storage"
 	"github.com/garyellow/ntpu-linebot-go/internal/stringutil"
 	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
-	"github.com/prometheus/client_golang/prometheus"
 )
 
 func setupTestHandler(t *testing.T) *Handler {
 	t.Helper()
 
-	// Use a unique temp file database for each test to avoid shared memory conflicts
-	// when running t.Parallel() tests. The temp directory is automatically cleaned up.
-	tmpDir := t.TempDir()
-	dbPath := filepath.Join(tmpDir, "test.db")
-	db, err := storage.New(context.Background(), dbPath, 168*time.Hour)
-	if err != nil {
-		t.Fatalf("Failed to create test database: %v", err)
-	}
-	t.Cleanup(func() { _ = db.Close(context.Background()) })
-
-	// Create dependencies
-	baseURLs := map[string][]string{
-		"lms": {"https://lms.ntpu.edu.tw"},
-		"sea": {"https://sea.cc.ntpu.edu.tw"},
-	}
-	scraperClient := scraper.NewClient(30*time.Second, 3, baseURLs)
-	registry := prometheus.NewRegistry()
-	m := metrics.New(registry)
-	log := logger.New("info")
-	stickerMgr := sticker.NewManager(db, scraperClient, log)
-
-	return NewHandler(db, scraperClient, m, log, stickerMgr, 100, nil, nil)
+	kit := moduletest.New(t, moduletest.Options{})
+	return NewHandler(kit.DB, kit.Scraper, kit.Metrics, kit.Logger, kit.Stickers, 100, nil, nil, nil)
 }
 
 func TestCanHandle(t *testing.T) {
@@ -299,6 +273,30 @@ func TestDispatchIntent_ParamValidation(t *testing.T) {
 			params:      map[string]string{"query": ""},
 			errContains: "missing required parameter: query",
 		},
+		{
+			name:        "organization intent missing organization",
+			intent:      IntentOrganization,
+			params:      map[string]string{},
+			errContains: "missing required parameter: organization",
+		},
+		{
+			name:        "extension intent missing extension",
+			intent:      IntentExtension,
+			params:      map[string]string{"extension": ""},
+			errContains: "missing required parameter: extension",
+		},
+		{
+			name:        "extension intent not digits",
+			intent:      IntentExtension,
+			params:      map[string]string{"extension": "分機66223"},
+			errContains: "invalid input",
+		},
+		{
+			name:        "extension intent too long",
+			intent:      IntentExtension,
+			params:      map[string]string{"extension": "0286741111"},
+			errContains: "invalid input",
+		},
 		{
 			name:        "unknown intent",
 			intent:      "unknown",
@@ -350,6 +348,18 @@ func TestDispatchIntent_Integration(t *testing.T) {
 			params:       map[string]string{"query": "王教授"},
 			wantMessages: true,
 		},
+		{
+			name:         "organization intent",
+			intent:       IntentOrganization,
+			params:       map[string]string{"organization": "資訊工程學系"},
+			wantMessages: true,
+		},
+		{
+			name:         "extension intent",
+			intent:       IntentExtension,
+			params:       map[string]string{"extension": "66223"},
+			wantMessages: true,
+		},
 		{
 			name:         "emergency intent (no params)",
 			intent:       IntentEmergency,
@@ -524,26 +534,12 @@ func TestSuggestSimilarContacts(t *testing.T) {
 	ctx := context.Background()
 
 	// Create handler with segmenter injected
-	tmpDir := t.TempDir()
-	dbPath := filepath.Join(tmpDir, "test.db")
-	db, err := storage.New(ctx, dbPath, 168*time.Hour)
-	if err != nil {
-		t.Fatalf("Failed to create test database: %v", err)
-	}
-	t.Cleanup(func() { _ = db.Close(context.Background()) })
-
-	baseURLs := map[string][]string{
-		"lms": {"https://lms.ntpu.edu.tw"},
-		"sea": {"https://sea.cc.ntpu.edu.tw"},
-	}
-	scraperClient := scraper.NewClient(30*time.Second, 3, baseURLs)
-	registry := prometheus.NewRegistry()
-	m := metrics.New(registry)
-	log := logger.New("info")
-	stickerMgr := sticker.NewManager(db, scraperClient, log)
+	kit := moduletest.New(t, moduletest.Options{})
+	db := kit.DB
+
 	seg := stringutil.NewSegmenter()
 
-	h := NewHandler(db, scraperClient, m, log, stickerMgr, 100, nil, seg)
+	h := NewHandler(db, kit.Scraper, kit.Metrics, kit.Logger, kit.Stickers, 100, nil, seg, nil)
 
 	// Seed DB with contacts
 	contacts := []*storage.Contact{
@@ -567,7 +563,7 @@ func TestSuggestSimilarContacts(t *testing.T) {
 	})
 
 	t.Run("nil segmenter returns nil", func(t *testing.T) {
-		hNoSeg := NewHandler(db, scraperClient, m, log, stickerMgr, 100, nil, nil)
+		hNoSeg := NewHandler(db, kit.Scraper, kit.Metrics, kit.Logger, kit.Stickers, 100, nil, nil, nil)
 		suggestions := hNoSeg.suggestSimilarContacts(ctx, "資訊工程研究所", 3)
 		if suggestions != nil {
 			t.Errorf("Expected nil with no segmenter, got %v", suggestions)
@@ -581,3 +577,21 @@ func TestSuggestSimilarContacts(t *testing.T) {
 		}
 	})
 }
+
+func TestContactTitle(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		contact storage.Contact
+		want    string
+	}{
+		{storage.Contact{Title: "教授"}, "教授"},
+		{storage.Contact{Title: "助理教授", PartTime: true}, "兼任助理教授"},
+		{storage.Contact{Title: "兼任講師", PartTime: true}, "兼任講師"},
+		{storage.Contact{PartTime: true}, "兼任教師"},
+	}
+	for _, tt := range tests {
+		if got := contactTitle(tt.contact); got != tt.want {
+			t.Errorf("contactTitle(%+v) = %q, want %q", tt.contact, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package contact
+
+import (
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/data"
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+)
+
+// holidayNotice returns the note shown on contact results on national
+// holidays (data.Holidays), when office phones go unanswered, e.g.
+// "今天是國定假日（國慶日），行政單位不上班". It returns "" on other days.
+func holidayNotice(now time.Time) string {
+	h, ok := data.HolidayOn(now.In(lineutil.GetTaipeiLocation()).Format(time.DateOnly))
+	if !ok {
+		return ""
+	}
+	return "今天是國定假日（" + h.Name + "），行政單位不上班"
+}
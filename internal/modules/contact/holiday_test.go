@@ -0,0 +1,25 @@
+package contact
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/data"
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+)
+
+func TestHolidayNotice(t *testing.T) {
+	t.Parallel()
+	loc := lineutil.GetTaipeiLocation()
+	h := data.Holidays[0]
+	day, _ := time.ParseInLocation(time.DateOnly, h.Start, loc)
+
+	// 01:00 in Taipei is still the previous day in UTC
+	if got := holidayNotice(day.Add(time.Hour).UTC()); !strings.Contains(got, h.Name) || !strings.Contains(got, "行政單位不上班") {
+		t.Errorf("holidayNotice(%s) = %q, want the holiday note", h.Start, got)
+	}
+	if got := holidayNotice(day.Add(-time.Hour)); got != "" {
+		t.Errorf("holidayNotice(day before %s) = %q, want empty", h.Start, got)
+	}
+}
@@ -0,0 +1,102 @@
+package contact
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/ctxutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/role"
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+)
+
+// RoleLookup returns a user's verified role (satisfied by *role.Store).
+type RoleLookup interface {
+	Role(ctx context.Context, userID string) (role.Role, error)
+}
+
+// fieldRule restricts a contact field to viewers holding a role.
+type fieldRule struct {
+	role    role.Role                     // Role required to see the field
+	applies func(c *storage.Contact) bool // Whether c's field is restricted
+	redact  func(c *storage.Contact)      // Clears the field
+}
+
+// fieldRules are the per-field visibility rules, applied only when staff
+// roles are enabled (the handler has a RoleLookup).
+var fieldRules = []fieldRule{
+	{
+		// Personal mobile numbers; office lines and extensions stay public
+		role:    role.Staff,
+		applies: func(c *storage.Contact) bool { return isMobileNumber(c.Phone) },
+		redact:  func(c *storage.Contact) { c.Phone = "" },
+	},
+}
+
+// isMobileNumber reports whether phone is a Taiwan mobile number (09xx-xxx-xxx).
+func isMobileNumber(phone string) bool {
+	if strings.ContainsRune(phone, ',') {
+		return false // "main,extension" is an office line
+	}
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, phone)
+	if rest, ok := strings.CutPrefix(digits, "886"); ok {
+		digits = "0" + rest
+	}
+	return len(digits) == 10 && strings.HasPrefix(digits, "09")
+}
+
+// applyVisibility returns contacts with the fields the viewer may not see
+// cleared. Restricted fields are only shown in 1:1 chats, so a staff member
+// asking in a group does not reveal them to the group. The input slice is
+// never modified; it may be shared with the org member cache.
+func (h *Handler) applyVisibility(ctx context.Context, contacts []storage.Contact) []storage.Contact {
+	if h.roles == nil {
+		return contacts
+	}
+
+	var viewer role.Role
+	resolved := false // Resolved lazily: most results have no restricted field
+	var result []storage.Contact
+	for i := range contacts {
+		for _, rule := range fieldRules {
+			if !rule.applies(&contacts[i]) {
+				continue
+			}
+			if !resolved {
+				viewer, resolved = h.viewerRole(ctx), true
+			}
+			if viewer == rule.role {
+				continue
+			}
+			if result == nil {
+				result = slices.Clone(contacts)
+			}
+			rule.redact(&result[i])
+		}
+	}
+	if result == nil {
+		return contacts
+	}
+	return result
+}
+
+// viewerRole returns the role of the user in a 1:1 chat, or "" otherwise.
+func (h *Handler) viewerRole(ctx context.Context) role.Role {
+	userID := ctxutil.GetUserID(ctx)
+	if userID == "" || ctxutil.GetChatID(ctx) != userID {
+		return ""
+	}
+	r, err := h.roles.Role(ctx, userID)
+	if err != nil {
+		// Fail closed: hide restricted fields rather than fail the search
+		logger.FromContext(ctx).WithError(err).WarnContext(ctx, "Failed to load viewer role; hiding restricted contact fields")
+		return ""
+	}
+	return r
+}
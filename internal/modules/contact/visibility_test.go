@@ -0,0 +1,87 @@
+package contact
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/ctxutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/role"
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+)
+
+type fakeRoles map[string]role.Role
+
+func (f fakeRoles) Role(_ context.Context, userID string) (role.Role, error) {
+	if userID == "Uerr" {
+		return "", errors.New("db down")
+	}
+	return f[userID], nil
+}
+
+func TestIsMobileNumber(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		phone string
+		want  bool
+	}{
+		{"0912345678", true},
+		{"0912-345-678", true},
+		{"+886 912 345 678", true},
+		{"0286741111", false},
+		{"0286741111,67114", false},
+		{"67114", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := isMobileNumber(tt.phone); got != tt.want {
+			t.Errorf("isMobileNumber(%q) = %v, want %v", tt.phone, got, tt.want)
+		}
+	}
+}
+
+func TestApplyVisibility(t *testing.T) {
+	t.Parallel()
+
+	contacts := []storage.Contact{
+		{Name: "王小明", Phone: "0912345678", Extension: "67114"},
+		{Name: "資工系", Phone: "0286741111,67114"},
+	}
+	h := &Handler{roles: fakeRoles{"Ustaff": role.Staff}}
+
+	tests := []struct {
+		name      string
+		userID    string
+		chatID    string
+		wantPhone string
+	}{
+		{"staff in 1:1 chat", "Ustaff", "Ustaff", "0912345678"},
+		{"staff in group", "Ustaff", "C1", ""},
+		{"regular user", "Uuser", "Uuser", ""},
+		{"role lookup fails", "Uerr", "Uerr", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := ctxutil.WithChatID(ctxutil.WithUserID(context.Background(), tt.userID), tt.chatID)
+			got := h.applyVisibility(ctx, contacts)
+			if got[0].Phone != tt.wantPhone {
+				t.Errorf("mobile = %q, want %q", got[0].Phone, tt.wantPhone)
+			}
+			if got[0].Extension != "67114" || got[1].Phone != "0286741111,67114" {
+				t.Errorf("public fields changed: %+v", got)
+			}
+		})
+	}
+
+	if contacts[0].Phone != "0912345678" {
+		t.Error("applyVisibility modified its input")
+	}
+
+	// Without a RoleLookup (staff roles disabled) every field is shown
+	disabled := (&Handler{}).applyVisibility(context.Background(), contacts)
+	if disabled[0].Phone != "0912345678" {
+		t.Errorf("disabled mobile = %q, want shown", disabled[0].Phone)
+	}
+}
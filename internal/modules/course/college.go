@@ -0,0 +1,138 @@
+package course
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/data"
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+// collegeCourseTypes maps the requirement word of a college query to the
+// course_majors course type ("" = both).
+var collegeCourseTypes = map[string]string{
+	"必修": "必",
+	"選修": "選",
+	"課程": "",
+}
+
+// collegeCourseRegex matches college course queries such as 「商學院 必修」,
+// 「電資 選修」 or 「法律學院課程」. Groups: [1]=college, [2]=requirement word.
+var collegeCourseRegex = buildCollegeCourseRegex(data.Campus.Colleges)
+
+// buildCollegeCourseRegex builds the college query pattern from every name
+// data.CampusData.FindCollege accepts, longest first so 「商學院」 is not read
+// as 「商學」 followed by 院.
+func buildCollegeCourseRegex(colleges []data.College) *regexp.Regexp {
+	var names []string
+	for _, c := range colleges {
+		for _, name := range []string{c.Name, c.ShortName, c.ShortName + "學院"} {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	slices.SortFunc(names, func(a, b string) int {
+		return cmp.Compare(len(b), len(a))
+	})
+	for i, name := range names {
+		names[i] = regexp.QuoteMeta(name)
+	}
+	return regexp.MustCompile(`^(` + strings.Join(names, "|") + `)\s*(必修|選修|課程)$`)
+}
+
+// handleCollegePattern processes college course queries (e.g., 商學院 必修).
+func (h *Handler) handleCollegePattern(ctx context.Context, text string, matches []string) []messaging_api.MessageInterface {
+	college, _ := data.Campus.FindCollege(matches[1])
+	return h.handleCollegeCourses(ctx, college, matches[2])
+}
+
+// handleCollegeCourses lists the newest cached semester's courses offered to
+// the college's departments, optionally only required (必修) or elective
+// (選修) ones.
+func (h *Handler) handleCollegeCourses(ctx context.Context, college data.College, kind string) []messaging_api.MessageInterface {
+	log := logger.FromContext(ctx)
+	sender := lineutil.GetSender(senderName, h.stickerManager)
+	query := college.Name + " " + kind
+
+	years, terms := h.semesterCache.GetRecentSemesters()
+	if len(years) == 0 {
+		return []messaging_api.MessageInterface{
+			lineutil.ErrorMessageWithQuickReply("目前沒有可查詢的課程資料", sender, query),
+		}
+	}
+	year, term := years[0], terms[0]
+
+	courses, err := h.db.GetCoursesByDepartments(ctx, year, term, college.CourseDepartments(), collegeCourseTypes[kind])
+	if err != nil {
+		log.WithError(err).
+			WithField("college", college.Name).
+			ErrorContext(ctx, "Failed to load college courses")
+		return []messaging_api.MessageInterface{
+			lineutil.ErrorMessageWithQuickReply("查詢學院課程時發生問題", sender, query),
+		}
+	}
+	if len(courses) == 0 {
+		msg := lineutil.NewTextMessageWithConsistentSender(
+			fmt.Sprintf("🔍 %s 查無%s%s的%s\n\n💡 可改用「課程 [課名] @%s」搜尋",
+				lineutil.FormatSemester(year, term), college.Emoji, college.Name, kind, college.ShortName),
+			sender,
+		)
+		msg.QuickReply = lineutil.NewQuickReply(lineutil.QuickReplyCourseNav(h.IsBM25SearchEnabled()))
+		return []messaging_api.MessageInterface{msg}
+	}
+
+	log.WithField("college", college.Name).
+		WithField("kind", kind).
+		WithField("count", len(courses)).
+		DebugContext(ctx, "College courses listed")
+
+	return h.formatCourseListResponseWithOptions(courses, FormatOptions{GroupSections: true})
+}
+
+// handleDepartmentCourses lists the newest cached semester's courses offered
+// to a department, given its short name (e.g. 資工).
+func (h *Handler) handleDepartmentCourses(ctx context.Context, dept string) []messaging_api.MessageInterface {
+	log := logger.FromContext(ctx)
+	sender := lineutil.GetSender(senderName, h.stickerManager)
+	name := dept + "系"
+
+	years, terms := h.semesterCache.GetRecentSemesters()
+	if len(years) == 0 {
+		return []messaging_api.MessageInterface{
+			lineutil.ErrorMessageWithQuickReply("目前沒有可查詢的課程資料", sender, name+"總覽"),
+		}
+	}
+	year, term := years[0], terms[0]
+
+	courses, err := h.db.GetCoursesByDepartments(ctx, year, term, []string{dept}, "")
+	if err != nil {
+		log.WithError(err).
+			WithField("department", dept).
+			ErrorContext(ctx, "Failed to load department courses")
+		return []messaging_api.MessageInterface{
+			lineutil.ErrorMessageWithQuickReply("查詢系所課程時發生問題", sender, name+"總覽"),
+		}
+	}
+	if len(courses) == 0 {
+		msg := lineutil.NewTextMessageWithConsistentSender(
+			fmt.Sprintf("🔍 %s 查無%s的課程\n\n💡 可改用「課程 [課名] @%s」搜尋",
+				lineutil.FormatSemester(year, term), name, dept),
+			sender,
+		)
+		msg.QuickReply = lineutil.NewQuickReply(lineutil.QuickReplyCourseNav(h.IsBM25SearchEnabled()))
+		return []messaging_api.MessageInterface{msg}
+	}
+
+	log.WithField("department", dept).
+		WithField("count", len(courses)).
+		DebugContext(ctx, "Department courses listed")
+
+	return h.formatCourseListResponseWithOptions(courses, FormatOptions{GroupSections: true})
+}
@@ -0,0 +1,184 @@
+package course
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
+	"github.com/garyellow/ntpu-linebot-go/internal/ctxutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/jobs"
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/scraper/ntpu"
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+	"github.com/garyellow/ntpu-linebot-go/internal/stringutil"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+// deepSearchJobName identifies deep search jobs in logs.
+const deepSearchJobName = "course_deep_search"
+
+// maxPushMessages is LINE's limit of messages per push request.
+const maxPushMessages = 5
+
+// deepSearchConfirm returns the 深度搜尋 confirm template offered after a
+// title search finds nothing, or nil when it cannot be offered: no job
+// runner, a group chat (results are pushed to a user), or a keyword too
+// long for the postback payload.
+func (h *Handler) deepSearchConfirm(ctx context.Context, keyword string, extended bool, sender *messaging_api.Sender) *messaging_api.TemplateMessage {
+	if h.jobs == nil {
+		return nil
+	}
+	userID := ctxutil.GetUserID(ctx)
+	if userID == "" || ctxutil.GetChatID(ctx) != userID {
+		return nil
+	}
+	data, err := DeepSearchPostback(keyword, extended)
+	if err != nil {
+		return nil
+	}
+
+	confirm := lineutil.NewConfirmTemplate(
+		"需要深度搜尋嗎？",
+		"需要深度搜尋（逐一比對所有課程的授課教師），約需 30 秒，要繼續嗎?",
+		lineutil.NewPostbackActionWithDisplayText("深度搜尋", "深度搜尋 "+keyword, data),
+		lineutil.NewPostbackActionWithDisplayText("先不用", "先不用", DeepSearchCancelPostback()),
+	)
+	msg, ok := lineutil.SetSender(confirm, sender).(*messaging_api.TemplateMessage)
+	if !ok {
+		return nil
+	}
+	return msg
+}
+
+// startDeepSearch submits a confirmed deep search and replies right away;
+// the result is pushed when the job finishes.
+func (h *Handler) startDeepSearch(ctx context.Context, keyword string, extended bool) []messaging_api.MessageInterface {
+	keyword = strings.TrimSpace(keyword)
+	userID := ctxutil.GetUserID(ctx)
+	if keyword == "" || h.jobs == nil || userID == "" {
+		return []messaging_api.MessageInterface{}
+	}
+	scoped := logger.FromContext(ctx)
+	log := scoped.WithField("search_term", keyword)
+	sender := lineutil.GetSender(senderName, h.stickerManager)
+
+	err := h.jobs.Submit(jobs.Job{
+		Name:    deepSearchJobName,
+		Key:     userID,
+		To:      userID,
+		Timeout: config.DeepSearchTimeout,
+		Run: func(jobCtx context.Context) []messaging_api.MessageInterface {
+			// The job outlives the request; keep logging with its module fields
+			return h.deepSearch(logger.NewContext(ctxutil.WithUserID(jobCtx, userID), scoped), keyword, extended)
+		},
+	})
+
+	var text string
+	switch {
+	case err == nil:
+		log.InfoContext(ctx, "Deep course search started")
+		text = fmt.Sprintf("🔎 已開始深度搜尋「%s」\n\n約需 30 秒，完成後會主動傳給你", keyword)
+	case errors.Is(err, jobs.ErrBusy):
+		text = "⏳ 你的深度搜尋還在進行中\n\n完成後會主動傳給你，請稍候"
+	default:
+		log.WithError(err).WarnContext(ctx, "Deep course search rejected")
+		text = "😵 目前深度搜尋的人太多\n\n請稍後再試，或改用其他關鍵字"
+	}
+	msg := lineutil.NewTextMessageWithConsistentSender(text, sender)
+	msg.QuickReply = lineutil.NewQuickReply(lineutil.QuickReplyCourseNav(h.IsBM25SearchEnabled()))
+	return []messaging_api.MessageInterface{msg}
+}
+
+// deepSearch scrapes every course of the searched semesters and keeps those
+// whose title or teachers contain all runes of keyword. The school system has
+// no teacher search, so this iterates all education codes (U/M/N/P) per
+// semester and may take tens of seconds.
+func (h *Handler) deepSearch(ctx context.Context, keyword string, extended bool) []messaging_api.MessageInterface {
+	log := logger.FromContext(ctx).WithField("search_term", keyword)
+	startTime := time.Now()
+
+	var searchYears, searchTerms []int
+	if extended {
+		searchYears, searchTerms = h.semesterCache.GetExtendedSemesters()
+	} else {
+		searchYears, searchTerms = h.semesterCache.GetRecentSemesters()
+	}
+
+	var found []storage.Course
+	existingUIDs := make(map[string]bool)
+	query := stringutil.NewRuneQuery(keyword)
+	for i := range searchYears {
+		year, term := searchYears[i], searchTerms[i]
+
+		// Scrape all courses for this semester (empty search term)
+		scrapedCourses, err := ntpu.ScrapeCourses(ctx, h.scraper, year, term, "")
+		if err != nil {
+			log.WithError(err).WithField("year", year).WithField("term", term).
+				WarnContext(ctx, "Failed to scrape all courses for year/term")
+			continue
+		}
+		if h.deltaRecorder != nil && len(scrapedCourses) > 0 {
+			if err := h.deltaRecorder.RecordCourses(ctx, scrapedCourses); err != nil {
+				log.WithError(err).WarnContext(ctx, "Failed to record course delta log")
+			}
+		}
+
+		for _, course := range scrapedCourses {
+			// Save all courses for future queries
+			if err := h.saveCourse(ctx, course, false); err != nil {
+				log.WithError(err).WarnContext(ctx, "Failed to save course to cache")
+			}
+			if existingUIDs[course.UID] || !matchesKeyword(course, query) {
+				continue
+			}
+			existingUIDs[course.UID] = true
+			found = append(found, *course)
+		}
+	}
+
+	status := "success"
+	if len(found) == 0 {
+		status = "not_found"
+	}
+	h.metrics.RecordScraperRequest(ModuleName, status, time.Since(startTime).Seconds())
+	log.WithField("count", len(found)).InfoContext(ctx, "Deep course search finished")
+
+	sender := lineutil.GetSender(senderName, h.stickerManager)
+	if len(found) == 0 {
+		text := fmt.Sprintf("🔍 深度搜尋完成，仍查無「%s」的課程\n\n💡 建議\n• 確認課名或教師姓名是否正確\n• 查詢教師資訊：「聯絡 %s」", keyword, keyword)
+		if ctx.Err() != nil {
+			text = fmt.Sprintf("⌛ 深度搜尋「%s」逾時\n\n學校系統回應較慢，請稍後再試", keyword)
+		}
+		msg := lineutil.NewTextMessageWithConsistentSender(text, sender)
+		msg.QuickReply = lineutil.NewQuickReply(lineutil.QuickReplyCourseNav(h.IsBM25SearchEnabled()))
+		return []messaging_api.MessageInterface{msg}
+	}
+
+	msgs := h.formatCourseListResponseWithOptions(found, FormatOptions{
+		SearchKeyword:    keyword,
+		IsExtendedSearch: extended,
+		GroupSections:    true,
+	})
+	if len(msgs) > maxPushMessages {
+		msgs = msgs[:maxPushMessages]
+	}
+	return msgs
+}
+
+// matchesKeyword reports whether the course title or any teacher contains
+// all runes of the keyword query (same fuzzy rule as the cached search).
+func matchesKeyword(c *storage.Course, q stringutil.RuneQuery) bool {
+	if q.Match(c.Title) {
+		return true
+	}
+	for _, teacher := range c.Teachers {
+		if q.Match(teacher) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package course
+
+import (
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
+	"github.com/garyellow/ntpu-linebot-go/internal/data"
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+)
+
+// enrollmentNotice is shown on course results during 加退選.
+const enrollmentNotice = "目前為加退選期間，課程資訊可能頻繁異動"
+
+// inEnrollmentPeriod reports whether now falls in a 加退選 window of the
+// academic calendar (data.EnrollmentPeriods).
+func inEnrollmentPeriod(now time.Time) bool {
+	_, ok := data.EnrollmentPeriodOn(now.In(lineutil.GetTaipeiLocation()).Format(time.DateOnly))
+	return ok
+}
+
+// EnrollmentTTLOverride is a storage.TTLOverride that shortens the course cache
+// TTL to config.EnrollmentCourseTTL during 加退選, so course lookups re-scrape
+// while course data changes often.
+func EnrollmentTTLOverride(table string, now time.Time, ttl time.Duration) time.Duration {
+	if table == storage.TableCourses && inEnrollmentPeriod(now) {
+		return min(ttl, config.EnrollmentCourseTTL)
+	}
+	return ttl
+}
+
+var _ storage.TTLOverride = EnrollmentTTLOverride
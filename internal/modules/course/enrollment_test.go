@@ -0,0 +1,45 @@
+package course
+
+import (
+	"testing"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
+	"github.com/garyellow/ntpu-linebot-go/internal/data"
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+)
+
+func TestEnrollmentTTLOverride(t *testing.T) {
+	t.Parallel()
+	period := data.EnrollmentPeriods[0]
+	loc := lineutil.GetTaipeiLocation()
+	during, _ := time.ParseInLocation(time.DateOnly, period.Start, loc)
+	during = during.Add(10 * time.Hour)
+	before := during.AddDate(0, 0, -1)
+	ttl := 168 * time.Hour
+
+	tests := []struct {
+		name  string
+		table string
+		now   time.Time
+		want  time.Duration
+	}{
+		{"courses during enrollment", storage.TableCourses, during, config.EnrollmentCourseTTL},
+		{"courses outside enrollment", storage.TableCourses, before, ttl},
+		{"other table during enrollment", "contacts", during, ttl},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := EnrollmentTTLOverride(tt.table, tt.now, ttl); got != tt.want {
+				t.Errorf("EnrollmentTTLOverride(%s, %s) = %v, want %v", tt.table, tt.now, got, tt.want)
+			}
+		})
+	}
+
+	// A configured TTL shorter than the cap is kept
+	if got := EnrollmentTTLOverride(storage.TableCourses, during, time.Hour); got != time.Hour {
+		t.Errorf("EnrollmentTTLOverride() with 1h TTL = %v, want 1h", got)
+	}
+}
@@ -0,0 +1,344 @@
+package course
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/ctxutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/data"
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+	"github.com/garyellow/ntpu-linebot-go/internal/stringutil"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+// Inline filter token prefixes (half- and full-width), e.g.
+// "課程 微積分 @資工 #下學期 !王". Course flag words (英語授課, 遠距,
+// 限本系) filter without a prefix.
+const (
+	departmentPrefixes = "@＠"
+	termPrefixes       = "#＃"
+	teacherPrefixes    = "!！"
+)
+
+// Filter parse errors. Any error falls back to a plain keyword search.
+var (
+	errEmptyFilter     = errors.New("empty filter value")
+	errDuplicateFilter = errors.New("duplicate filter")
+	errUnknownTerm     = errors.New("unknown term filter")
+	errFilterMismatch  = errors.New("filter not found in search term")
+	errNoKeyword       = errors.New("no keyword besides filters")
+)
+
+// searchFilter holds the structured filters parsed from inline tokens.
+// Zero values mean "any".
+type searchFilter struct {
+	Department string   // @資工 — a department's 應修系級 (資工 matches 資工系1–4, not 資工系碩1); @商學院 — a college's departments
+	Year       int      // #113 or #113-1 — ROC academic year
+	Term       int      // #上學期 / #下學期 / #113-1 — 1 or 2
+	Teacher    string   // !王 — substring of a teacher name
+	Flags      []string // 英語授課 / 遠距 / 限本系 — storage.CourseFlag* parsed from the note
+
+	tokens []string // original tokens, for display
+}
+
+// IsZero reports whether no filter is set.
+func (f searchFilter) IsZero() bool {
+	return f.Department == "" && f.Year == 0 && f.Term == 0 && f.Teacher == "" && len(f.Flags) == 0
+}
+
+// String returns the filter tokens as the user typed them.
+func (f searchFilter) String() string {
+	return strings.Join(f.tokens, " ")
+}
+
+// parseSearchFilters extracts the filter tokens from the raw (unsanitized)
+// message text. A token is a whitespace-separated word starting with one of
+// the prefixes above. It returns the filter and each token's value, in order.
+func parseSearchFilters(raw string) (searchFilter, []string, error) {
+	var f searchFilter
+	var values []string
+	for _, word := range strings.Fields(raw) {
+		prefix, _ := utf8.DecodeRuneInString(word)
+		if !strings.ContainsRune(departmentPrefixes+termPrefixes+teacherPrefixes, prefix) {
+			continue
+		}
+		value := stringutil.SanitizeText(strings.TrimPrefix(word, string(prefix)))
+		if value == "" || strings.Contains(value, " ") {
+			return searchFilter{}, nil, fmt.Errorf("%w: %q", errEmptyFilter, word)
+		}
+
+		switch {
+		case strings.ContainsRune(departmentPrefixes, prefix):
+			if f.Department != "" {
+				return searchFilter{}, nil, fmt.Errorf("%w: %q", errDuplicateFilter, word)
+			}
+			f.Department = value
+		case strings.ContainsRune(termPrefixes, prefix):
+			if f.Year != 0 || f.Term != 0 {
+				return searchFilter{}, nil, fmt.Errorf("%w: %q", errDuplicateFilter, word)
+			}
+			year, term, ok := parseTermFilter(value)
+			if !ok {
+				return searchFilter{}, nil, fmt.Errorf("%w: %q", errUnknownTerm, word)
+			}
+			f.Year, f.Term = year, term
+		default:
+			if f.Teacher != "" {
+				return searchFilter{}, nil, fmt.Errorf("%w: %q", errDuplicateFilter, word)
+			}
+			f.Teacher = value
+		}
+		f.tokens = append(f.tokens, string(prefix)+value)
+		values = append(values, value)
+	}
+	return f, values, nil
+}
+
+// parseTermFilter parses a #term value: 上學期/下學期 (term only),
+// 113 (year only), or 113-1 / 1131 (sanitized to "1131"; year and term).
+func parseTermFilter(value string) (year, term int, ok bool) {
+	switch value {
+	case "上", "上學期", "第一學期":
+		return 0, 1, true
+	case "下", "下學期", "第二學期":
+		return 0, 2, true
+	}
+	if !stringutil.IsNumeric(value) {
+		return 0, 0, false
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, 0, false
+	}
+	switch {
+	case len(value) <= 3:
+		return n, 0, n > 0
+	case len(value) == 4 && (n%10 == 1 || n%10 == 2):
+		return n / 10, n % 10, true
+	}
+	return 0, 0, false
+}
+
+// splitSearchFilters separates the inline filters from a sanitized search
+// term, using the raw text to recover the prefixes removed by sanitization.
+// Each token value must appear as a word of searchTerm, which guarantees the
+// raw text belongs to this search; flag words are taken from searchTerm
+// itself, and the remaining words form the keyword. A filter with only a
+// teacher uses the teacher as the keyword; one with flags may have no keyword.
+func splitSearchFilters(searchTerm, raw string) (string, searchFilter, error) {
+	f, values, err := parseSearchFilters(raw)
+	if err != nil {
+		return searchTerm, searchFilter{}, err
+	}
+
+	words := strings.Fields(searchTerm)
+	for _, v := range values {
+		i := slices.Index(words, v)
+		if i < 0 {
+			return searchTerm, searchFilter{}, fmt.Errorf("%w: %q", errFilterMismatch, v)
+		}
+		words = slices.Delete(words, i, i+1)
+	}
+	words = f.takeFlags(words)
+	if f.IsZero() {
+		return searchTerm, searchFilter{}, nil
+	}
+
+	keyword := strings.Join(words, " ")
+	if keyword == "" {
+		switch {
+		case f.Teacher != "":
+			keyword = f.Teacher
+		case len(f.Flags) == 0:
+			return searchTerm, searchFilter{}, errNoKeyword
+		}
+	}
+	return keyword, f, nil
+}
+
+// takeFlags moves the course flag words of words into f.Flags (each flag
+// once) and returns the other words.
+func (f *searchFilter) takeFlags(words []string) []string {
+	rest := words[:0:0]
+	for _, w := range words {
+		flag, ok := storage.CourseFlagForKeyword(w)
+		if !ok {
+			rest = append(rest, w)
+			continue
+		}
+		if !slices.Contains(f.Flags, flag) {
+			f.Flags = append(f.Flags, flag)
+			f.tokens = append(f.tokens, w)
+		}
+	}
+	return rest
+}
+
+// parseInlineFilters returns the keyword and filters of a course search.
+// Without valid filters it returns searchTerm unchanged (plain search).
+// The keyword is empty for a flag-only search (e.g., 「課程 英語授課」).
+func (h *Handler) parseInlineFilters(ctx context.Context, searchTerm string) (string, searchFilter) {
+	keyword, f, err := splitSearchFilters(searchTerm, ctxutil.GetRawText(ctx))
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).
+			DebugContext(ctx, "Ignoring inline search filters")
+		return searchTerm, searchFilter{}
+	}
+	return keyword, f
+}
+
+// applySearchFilter keeps the courses matching every set filter.
+// The department filter uses the cached 應修系級 of each course's semester;
+// a lookup failure drops that filter rather than the results.
+func (h *Handler) applySearchFilter(ctx context.Context, courses []storage.Course, f searchFilter) []storage.Course {
+	var deptUIDs map[string]bool
+	if f.Department != "" {
+		deptUIDs = h.departmentCourseUIDs(ctx, courses, f.Department)
+	}
+
+	filtered := make([]storage.Course, 0, len(courses))
+	for _, c := range courses {
+		if f.matches(&c) && (deptUIDs == nil || deptUIDs[c.UID]) {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
+
+// matches checks the filters that need no database lookup.
+func (f searchFilter) matches(c *storage.Course) bool {
+	if f.Year != 0 && c.Year != f.Year {
+		return false
+	}
+	if f.Term != 0 && c.Term != f.Term {
+		return false
+	}
+	if f.Teacher != "" && !slices.ContainsFunc(c.Teachers, func(t string) bool {
+		return strings.Contains(t, f.Teacher)
+	}) {
+		return false
+	}
+	if len(f.Flags) > 0 {
+		flags := storage.CourseNoteFlags(c.Note)
+		for _, flag := range f.Flags {
+			if !slices.Contains(flags, flag) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// searchCoursesByFlags lists the cached courses of the searched semesters
+// that have every flag of f, for a search with flags but no keyword
+// (e.g., 「課程 英語授課 @資工」). The other filters still apply.
+func (h *Handler) searchCoursesByFlags(ctx context.Context, f searchFilter, years, terms []int, extended bool) []messaging_api.MessageInterface {
+	var courses []storage.Course
+	for i := range years {
+		semesterCourses, err := h.db.GetCoursesByFlags(ctx, years[i], terms[i], f.Flags)
+		if err != nil {
+			logger.FromContext(ctx).WithError(err).
+				WithField("year", years[i]).
+				WithField("term", terms[i]).
+				WarnContext(ctx, "Failed to load flagged courses for semester")
+			continue
+		}
+		courses = append(courses, semesterCourses...)
+	}
+	if len(courses) > 0 {
+		courses = h.applySearchFilter(ctx, courses, f)
+	}
+
+	if len(courses) == 0 {
+		h.metrics.RecordZeroResults(ModuleName)
+		sender := lineutil.GetSender(senderName, h.stickerManager)
+		msg := lineutil.NewTextMessageWithConsistentSender(
+			fmt.Sprintf("🔍 查無符合「%s」的課程\n\n💡 備註篩選只涵蓋已快取的學期課程，可加上課名縮小範圍，例如「課程 %s 管理」", f, f),
+			sender,
+		)
+		msg.QuickReply = lineutil.NewQuickReply(lineutil.QuickReplyCourseNav(h.bm25Index != nil && h.bm25Index.IsEnabled()))
+		return []messaging_api.MessageInterface{msg}
+	}
+	return h.formatCourseListResponseWithOptions(courses, FormatOptions{
+		SearchKeyword:    f.String(),
+		IsExtendedSearch: extended,
+		GroupSections:    true,
+	})
+}
+
+// departmentCourseUIDs returns the UIDs of courses required by the department
+// in each semester present in courses, or nil if the lookup fails.
+// A college name (@商學院) stands for all of its departments.
+func (h *Handler) departmentCourseUIDs(ctx context.Context, courses []storage.Course, department string) map[string]bool {
+	college, isCollege := data.Campus.FindCollege(department)
+	uids := make(map[string]bool)
+	seen := make(map[[2]int]bool)
+	for _, c := range courses {
+		key := [2]int{c.Year, c.Term}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		var deptCourses []storage.Course
+		var err error
+		if isCollege {
+			deptCourses, err = h.db.GetCoursesByDepartments(ctx, c.Year, c.Term, college.CourseDepartments(), "")
+		} else {
+			deptCourses, err = h.departmentCourses(ctx, c.Year, c.Term, department)
+		}
+		if err != nil {
+			logger.FromContext(ctx).WithError(err).
+				WarnContext(ctx, "Failed to load department courses for filter")
+			return nil
+		}
+		for _, dc := range deptCourses {
+			uids[dc.UID] = true
+		}
+	}
+	return uids
+}
+
+// departmentCourses returns the courses of a semester required by department,
+// a 應修系級 ("資工系2") or a department with any grade ("資工" or "資工系"
+// match 資工系1–4). Graduate programs are other departments: a prefix match
+// would put 資工系碩1 courses under @資工.
+func (h *Handler) departmentCourses(ctx context.Context, year, term int, department string) ([]storage.Course, error) {
+	all, err := h.db.GetMajorsBySemester(ctx, year, term)
+	if err != nil {
+		return nil, err
+	}
+	majors := slices.DeleteFunc(all, func(m string) bool {
+		dept := strings.TrimRightFunc(m, unicode.IsDigit)
+		return m != department && dept != department && dept != department+"系"
+	})
+	return h.db.GetCoursesByMajors(ctx, year, term, majors)
+}
+
+// filteredNotFoundResponse explains that the keyword matched courses but none
+// passed the filters, offering the same search without them.
+func (h *Handler) filteredNotFoundResponse(keyword string, f searchFilter, extended bool) []messaging_api.MessageInterface {
+	sender := lineutil.GetSender(senderName, h.stickerManager)
+	msg := lineutil.NewTextMessageWithConsistentSender(
+		fmt.Sprintf("🔍 有「%s」的課程，但沒有符合篩選條件的結果\n\n🏷️ 篩選：%s\n\n💡 篩選語法\n• @系所：@資工、@商學院\n• #學期：#上學期、#下學期、#113-1\n• !教師：!王\n• 備註：英語授課、遠距、限本系", keyword, f),
+		sender,
+	)
+
+	prefix := "課程 "
+	if extended {
+		prefix = "更多學期 "
+	}
+	msg.QuickReply = lineutil.NewQuickReply([]lineutil.QuickReplyItem{
+		{Action: lineutil.NewMessageAction("🧹 取消篩選", prefix+keyword)},
+		lineutil.QuickReplyCourseAction(),
+		lineutil.QuickReplyHelpAction(),
+	})
+	return []messaging_api.MessageInterface{msg}
+}
@@ -0,0 +1,195 @@
+package course
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+)
+
+func TestSplitSearchFilters(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		searchTerm  string // sanitized, after the 課程 keyword
+		raw         string
+		wantKeyword string
+		wantFilter  searchFilter
+		wantErr     error
+	}{
+		{
+			name:        "department and term",
+			searchTerm:  "微積分 資工 下學期",
+			raw:         "課程 微積分 @資工 #下學期",
+			wantKeyword: "微積分",
+			wantFilter:  searchFilter{Department: "資工", Term: 2},
+		},
+		{
+			name:        "full-width prefixes and semester",
+			searchTerm:  "程式設計 1131 王",
+			raw:         "課程 程式設計 ＃113-1 ！王",
+			wantKeyword: "程式設計",
+			wantFilter:  searchFilter{Year: 113, Term: 1, Teacher: "王"},
+		},
+		{
+			name:        "teacher only becomes keyword",
+			searchTerm:  "王小明",
+			raw:         "課程 !王小明",
+			wantKeyword: "王小明",
+			wantFilter:  searchFilter{Teacher: "王小明"},
+		},
+		{
+			name:        "no tokens",
+			searchTerm:  "微積分",
+			raw:         "課程 微積分",
+			wantKeyword: "微積分",
+		},
+		{
+			name:        "unknown term degrades",
+			searchTerm:  "微積分 明年",
+			raw:         "課程 微積分 #明年",
+			wantKeyword: "微積分 明年",
+			wantErr:     errUnknownTerm,
+		},
+		{
+			name:        "duplicate department degrades",
+			searchTerm:  "微積分 資工 通訊",
+			raw:         "課程 微積分 @資工 @通訊",
+			wantKeyword: "微積分 資工 通訊",
+			wantErr:     errDuplicateFilter,
+		},
+		{
+			name:        "empty token degrades",
+			searchTerm:  "微積分",
+			raw:         "課程 微積分 @",
+			wantKeyword: "微積分",
+			wantErr:     errEmptyFilter,
+		},
+		{
+			name:        "raw text from another search",
+			searchTerm:  "微積分",
+			raw:         "找課 @資工",
+			wantKeyword: "微積分",
+			wantErr:     errFilterMismatch,
+		},
+		{
+			name:        "flag words",
+			searchTerm:  "英語授課 管理 全英語",
+			raw:         "課程 英語授課 管理 全英語",
+			wantKeyword: "管理",
+			wantFilter:  searchFilter{Flags: []string{storage.CourseFlagEnglish}},
+		},
+		{
+			name:        "flags without keyword",
+			searchTerm:  "遠距 資工",
+			raw:         "課程 遠距 @資工",
+			wantKeyword: "",
+			wantFilter:  searchFilter{Department: "資工", Flags: []string{storage.CourseFlagRemote}},
+		},
+		{
+			name:        "flag without raw text",
+			searchTerm:  "限本系 會計",
+			wantKeyword: "會計",
+			wantFilter:  searchFilter{Flags: []string{storage.CourseFlagDeptOnly}},
+		},
+		{
+			name:        "filters without keyword",
+			searchTerm:  "資工",
+			raw:         "課程 @資工",
+			wantKeyword: "資工",
+			wantErr:     errNoKeyword,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			keyword, f, err := splitSearchFilters(tt.searchTerm, tt.raw)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("splitSearchFilters() error = %v, want %v", err, tt.wantErr)
+			}
+			if keyword != tt.wantKeyword {
+				t.Errorf("keyword = %q, want %q", keyword, tt.wantKeyword)
+			}
+			f.tokens = nil
+			if f.Department != tt.wantFilter.Department || f.Year != tt.wantFilter.Year ||
+				f.Term != tt.wantFilter.Term || f.Teacher != tt.wantFilter.Teacher ||
+				!slices.Equal(f.Flags, tt.wantFilter.Flags) {
+				t.Errorf("filter = %+v, want %+v", f, tt.wantFilter)
+			}
+		})
+	}
+}
+
+func TestSearchFilter_Matches(t *testing.T) {
+	t.Parallel()
+
+	course := &storage.Course{Year: 113, Term: 2, Teachers: []string{"王小明", "李大華"}, Note: "英語授課；限本系"}
+	tests := []struct {
+		name   string
+		filter searchFilter
+		want   bool
+	}{
+		{"no filter", searchFilter{}, true},
+		{"term match", searchFilter{Term: 2}, true},
+		{"term mismatch", searchFilter{Term: 1}, false},
+		{"year mismatch", searchFilter{Year: 112}, false},
+		{"teacher surname", searchFilter{Teacher: "李"}, true},
+		{"teacher mismatch", searchFilter{Teacher: "陳"}, false},
+		{"flags match", searchFilter{Flags: []string{storage.CourseFlagEnglish, storage.CourseFlagDeptOnly}}, true},
+		{"flag mismatch", searchFilter{Flags: []string{storage.CourseFlagRemote}}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.filter.matches(course); got != tt.want {
+			t.Errorf("%s: matches() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDepartmentCourseUIDs(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	h := setupTestHandlerWithSemesters(t, []struct{ year, term int }{{113, 1}})
+
+	courses := []*storage.Course{
+		{UID: "1131U0001", Year: 113, Term: 1, No: "U0001", Title: "程式設計",
+			RawProgramReqs: []storage.RawProgramReq{{Name: "資工系1", CourseType: "必"}}},
+		{UID: "1131U0002", Year: 113, Term: 1, No: "U0002", Title: "資料結構",
+			RawProgramReqs: []storage.RawProgramReq{{Name: "資工系2", CourseType: "必"}}},
+		{UID: "1131M0001", Year: 113, Term: 1, No: "M0001", Title: "機器學習",
+			RawProgramReqs: []storage.RawProgramReq{{Name: "資工系碩1", CourseType: "選"}}},
+	}
+	for _, c := range courses {
+		if err := h.db.SaveCourse(ctx, c); err != nil {
+			t.Fatalf("SaveCourse failed: %v", err)
+		}
+	}
+	if err := h.db.SaveCourseMajorsBatch(ctx, courses); err != nil {
+		t.Fatalf("SaveCourseMajorsBatch failed: %v", err)
+	}
+	candidates := []storage.Course{{UID: "1131U0001", Year: 113, Term: 1}}
+
+	tests := []struct {
+		department string
+		want       []string
+	}{
+		{"資工", []string{"1131U0001", "1131U0002"}}, // Graduate courses are excluded
+		{"資工系", []string{"1131U0001", "1131U0002"}},
+		{"資工系2", []string{"1131U0002"}},
+		{"資工系碩", []string{"1131M0001"}},
+		{"資", nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for uid := range h.departmentCourseUIDs(ctx, candidates, tt.department) {
+			got = append(got, uid)
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("departmentCourseUIDs(@%s) = %v, want %v", tt.department, got, tt.want)
+		}
+	}
+}
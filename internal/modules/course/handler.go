@@ -4,6 +4,7 @@ package course
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/url"
 	"regexp"
@@ -13,18 +14,25 @@ import (
 	"time"
 
 	"github.com/garyellow/ntpu-linebot-go/internal/bot"
+	"github.com/garyellow/ntpu-linebot-go/internal/buzz"
 	"github.com/garyellow/ntpu-linebot-go/internal/config"
 	"github.com/garyellow/ntpu-linebot-go/internal/ctxutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/data"
 	"github.com/garyellow/ntpu-linebot-go/internal/delta"
 	domerrors "github.com/garyellow/ntpu-linebot-go/internal/errors"
 	"github.com/garyellow/ntpu-linebot-go/internal/genai"
+	"github.com/garyellow/ntpu-linebot-go/internal/jobs"
 	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
 	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/membudget"
 	"github.com/garyellow/ntpu-linebot-go/internal/metrics"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/share"
+	"github.com/garyellow/ntpu-linebot-go/internal/msgtmpl"
 	"github.com/garyellow/ntpu-linebot-go/internal/rag"
 	"github.com/garyellow/ntpu-linebot-go/internal/ratelimit"
 	"github.com/garyellow/ntpu-linebot-go/internal/scraper"
 	"github.com/garyellow/ntpu-linebot-go/internal/scraper/ntpu"
+	"github.com/garyellow/ntpu-linebot-go/internal/semester"
 	"github.com/garyellow/ntpu-linebot-go/internal/sliceutil"
 	"github.com/garyellow/ntpu-linebot-go/internal/sticker"
 	"github.com/garyellow/ntpu-linebot-go/internal/storage"
@@ -49,11 +57,28 @@ type Handler struct {
 	llmRateLimiter *ratelimit.KeyedLimiter
 	semesterCache  *SemesterCache       // Shared cache updated by warmup
 	courseCache    *SemesterCourseCache // Short-lived in-memory cache for hot semester course lists
+	searchResults  *SearchResultCache   // UIDs found by recent keyword searches
 	seg            *stringutil.Segmenter
+	texts          *msgtmpl.Store          // Message copy templates
+	sharer         *share.Linker           // 分享 button links (nil = disabled)
+	jobs           *jobs.Runner            // Confirmed deep searches (nil = disabled)
+	buzz           BuzzLookup              // 💬 討論熱度 counts (nil = disabled)
+	studentIDs     StudentIDLookup         // Smart search level preference (nil = disabled)
+	calendars      CalendarLinker          // 訂閱行事曆 button links (nil = disabled)
+	prompts        *genai.ExpansionPrompts // Query expansion prompt versions (nil = built-in)
 
 	// matchers contains all pattern-handler pairs sorted by priority.
 	// Shared by CanHandle and HandleMessage for consistent routing.
 	matchers []PatternMatcher
+
+	// postbacks routes decoded postback actions to handler methods.
+	postbacks *bot.PostbackRouter
+}
+
+// BuzzLookup returns cached discussion counts of a course without waiting on
+// the network (implemented by *buzz.Enricher).
+type BuzzLookup interface {
+	Lookup(ctx context.Context, title, teacher string) (buzz.Counts, bool)
 }
 
 // Name returns the module name
@@ -67,8 +92,15 @@ const (
 	senderName          = "課程小幫手"
 	MaxCoursesPerSearch = 40 // 4 carousels @ 10 bubbles, +1 slot for warning (LINE max: 5 messages)
 
+	maxPrerequisiteRunes = 80 // 先修課程 row length on the detail page
+
 )
 
+// courseSearchPage bounds each SQL search of a keyword or teacher query. The
+// results are merged, filtered by semester and re-ranked before display, so
+// every search reads the largest page.
+var courseSearchPage = storage.Page{Limit: storage.MaxSearchLimit}
+
 // Pattern priorities (lower = higher).
 const (
 	PriorityUID        = 1 // Full UID (e.g., 1131U0001)
@@ -77,6 +109,9 @@ const (
 	PrioritySmart      = 4 // Smart (找課)
 	PriorityExtended   = 5 // Extended (更多學期)
 	PriorityRegular    = 6 // Regular (課程/老師)
+	PriorityRandom     = 7 // Random pick (隨機課程)
+	PriorityRemote     = 8 // Remote courses (遠距課程)
+	PriorityCollege    = 9 // College courses (商學院 必修)
 )
 
 // PatternHandler processes a matched pattern and returns LINE messages.
@@ -117,11 +152,25 @@ var (
 		"更多學期", "更多課程", "歷史課程",
 	}
 
+	// validRandomKeywords: random course pick of the newest semester.
+	// The first keyword is echoed in 再抽一門 quick replies.
+	validRandomKeywords = []string{
+		"隨機課程", "隨機推薦", "隨機推薦一門課", "推薦一門課", "抽課", "抽一門課",
+	}
+
+	// validRemoteKeywords: courses whose note marks them as 遠距 (remote),
+	// semesters 1-2. The first keyword is the 💻 遠距課程 quick reply text.
+	validRemoteKeywords = []string{
+		"遠距課程", "線上課程", "遠距教學",
+	}
+
 	courseRegex            = bot.BuildKeywordRegex(validCourseKeywords)
 	smartSearchCourseRegex = bot.BuildKeywordRegex(validSmartSearchKeywords)
 	extendedSearchRegex    = bot.BuildKeywordRegex(validExtendedSearchKeywords)
-	// Full UID: {year}{term}{no} = 3-4 digits + [UMNP] + 4 digits (e.g., 1131U0001, 991U0001)
-	uidRegex = regexp.MustCompile(`(?i)\d{3,4}[umnp]\d{4}`)
+	randomCourseRegex      = bot.BuildKeywordRegex(validRandomKeywords)
+	remoteCourseRegex      = bot.BuildKeywordRegex(validRemoteKeywords)
+	// Full UID: {year}{term}{no} (e.g., 1131U0001, 991U0001); see ntpu.ParseUID
+	uidRegex = ntpu.UIDRegex
 	// Course number: [UMNP] + 4 digits (e.g., U0001, M0002)
 	courseNoRegex = regexp.MustCompile(`(?i)^[umnp]\d{4}$`)
 	// Historical: "課程 {year} {keyword}" where year = ROC (2-3 digits) or Western (4 digits)
@@ -130,7 +179,7 @@ var (
 )
 
 // NewHandler creates a new course handler.
-// Optional: bm25Index, queryExpander, llmRateLimiter, semesterCache (pass nil if unused).
+// Optional: bm25Index, queryExpander, llmRateLimiter, semesterCache, texts, sharer, jobRunner (pass nil if unused).
 // Initializes and sorts matchers by priority during construction.
 // semesterCache should be shared with warmup module for coordinated updates.
 func NewHandler(
@@ -145,11 +194,18 @@ func NewHandler(
 	llmRateLimiter *ratelimit.KeyedLimiter,
 	semesterCache *SemesterCache, // Shared cache (nil = create new)
 	seg *stringutil.Segmenter, // Shared segmenter for suggest (nil = disabled)
+	texts *msgtmpl.Store, // Message templates (nil = embedded defaults)
+	sharer *share.Linker, // Share links (nil = no 分享 button)
+	jobRunner *jobs.Runner, // Background deep search (nil = no 深度搜尋 offer)
+	buzz BuzzLookup, // Discussion counts (nil = no 討論熱度 row)
 ) *Handler {
 	// Use provided cache or create new one
 	if semesterCache == nil {
 		semesterCache = NewSemesterCache()
 	}
+	if texts == nil {
+		texts = msgtmpl.Default()
+	}
 
 	h := &Handler{
 		db:             db,
@@ -163,11 +219,17 @@ func NewHandler(
 		llmRateLimiter: llmRateLimiter,
 		semesterCache:  semesterCache,
 		courseCache:    NewSemesterCourseCache(defaultSemesterCourseCacheTTL),
+		searchResults:  NewSearchResultCache(defaultSearchResultCacheTTL),
 		seg:            seg,
+		texts:          texts,
+		sharer:         sharer,
+		jobs:           jobRunner,
+		buzz:           buzz,
 	}
 
 	// Initialize Pattern-Action Table
 	h.initializeMatchers()
+	h.postbacks = h.newPostbackRouter()
 
 	return h
 }
@@ -213,6 +275,24 @@ func (h *Handler) initializeMatchers() {
 			handler:  h.handleRegularPattern,
 			name:     "Regular",
 		},
+		{
+			pattern:  randomCourseRegex,
+			priority: PriorityRandom,
+			handler:  h.handleRandomPattern,
+			name:     "Random",
+		},
+		{
+			pattern:  remoteCourseRegex,
+			priority: PriorityRemote,
+			handler:  h.handleRemotePattern,
+			name:     "Remote",
+		},
+		{
+			pattern:  collegeCourseRegex,
+			priority: PriorityCollege,
+			handler:  h.handleCollegePattern,
+			name:     "College",
+		},
 	}
 
 	// Sort by priority (lower number = higher priority)
@@ -232,6 +312,35 @@ func (h *Handler) GetSemesterCache() *SemesterCache {
 	return h.semesterCache
 }
 
+// SetBudget charges the semester course lists cached for fuzzy matching to b.
+func (h *Handler) SetBudget(b *membudget.Budget) {
+	h.courseCache.SetBudget(b)
+}
+
+// SetStudentIDLookup lets smart search favor courses of the education level
+// implied by the student ID a user linked (大學部, 碩士班 or 博士班).
+func (h *Handler) SetStudentIDLookup(l StudentIDLookup) {
+	h.studentIDs = l
+}
+
+// CalendarLinker returns the public URL of a course's signed iCal feed
+// (implemented by *export.Links).
+type CalendarLinker interface {
+	CourseCalendarURL(uid string) string
+}
+
+// SetCalendarLinks adds a 訂閱行事曆 button to course details, linking the
+// course's weekly meetings as a calendar feed.
+func (h *Handler) SetCalendarLinks(l CalendarLinker) {
+	h.calendars = l
+}
+
+// SetExpansionPrompts sets the query expansion prompt versions smart search
+// splits chats between.
+func (h *Handler) SetExpansionPrompts(p *genai.ExpansionPrompts) {
+	h.prompts = p
+}
+
 // hasQueryExpander returns true if query expander is available.
 func (h *Handler) hasQueryExpander() bool {
 	return h.queryExpander != nil
@@ -244,10 +353,12 @@ const (
 	IntentUID        = "uid"        // Direct course UID lookup
 	IntentExtended   = "extended"   // Extended search (more semesters)
 	IntentHistorical = "historical" // Historical year search
+	IntentRandom     = "random"     // Random course pick (optional degree, department)
+	IntentRemote     = "remote"     // Remote (遠距) courses (optional keyword)
 )
 
 // DispatchIntent handles NLU-parsed intents.
-// Intents: "search", "smart", "uid", "extended", "historical".
+// Intents: "search", "smart", "uid", "extended", "historical", "random", "remote".
 // Returns error if intent unknown or required params missing.
 func (h *Handler) DispatchIntent(ctx context.Context, intent string, params map[string]string) ([]messaging_api.MessageInterface, error) {
 	// Validate parameters first (before logging) to support testing with nil dependencies
@@ -257,12 +368,9 @@ func (h *Handler) DispatchIntent(ctx context.Context, intent string, params map[
 		if !ok || keyword == "" {
 			return nil, fmt.Errorf("%w: keyword", domerrors.ErrMissingParameter)
 		}
-		if h.logger != nil {
-			h.logger.WithModule(ModuleName).
-				WithField("intent", intent).
-				WithField("keyword", keyword).
-				DebugContext(ctx, "Dispatching course intent")
-		}
+		logger.FromContext(ctx).
+			WithField("keyword", keyword).
+			DebugContext(ctx, "Dispatching course intent")
 		return h.handleUnifiedCourseSearch(ctx, keyword), nil
 
 	case IntentSmart:
@@ -270,12 +378,9 @@ func (h *Handler) DispatchIntent(ctx context.Context, intent string, params map[
 		if !ok || query == "" {
 			return nil, fmt.Errorf("%w: query", domerrors.ErrMissingParameter)
 		}
-		if h.logger != nil {
-			h.logger.WithModule(ModuleName).
-				WithField("intent", intent).
-				WithField("query", query).
-				DebugContext(ctx, "Dispatching course intent")
-		}
+		logger.FromContext(ctx).
+			WithField("query", query).
+			DebugContext(ctx, "Dispatching course intent")
 		return h.handleSmartSearch(ctx, query), nil
 
 	case IntentUID:
@@ -283,12 +388,13 @@ func (h *Handler) DispatchIntent(ctx context.Context, intent string, params map[
 		if !ok || uid == "" {
 			return nil, fmt.Errorf("%w: uid", domerrors.ErrMissingParameter)
 		}
-		if h.logger != nil {
-			h.logger.WithModule(ModuleName).
-				WithField("intent", intent).
-				WithField("uid", uid).
-				DebugContext(ctx, "Dispatching course intent")
+		uid, err := ntpu.NormalizeUID(uid)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", domerrors.ErrInvalidInput, err)
 		}
+		logger.FromContext(ctx).
+			WithField("uid", uid).
+			DebugContext(ctx, "Dispatching course intent")
 		return h.handleCourseUIDQuery(ctx, uid), nil
 
 	case IntentExtended:
@@ -296,12 +402,9 @@ func (h *Handler) DispatchIntent(ctx context.Context, intent string, params map[
 		if !ok || keyword == "" {
 			return nil, fmt.Errorf("%w: keyword", domerrors.ErrMissingParameter)
 		}
-		if h.logger != nil {
-			h.logger.WithModule(ModuleName).
-				WithField("intent", intent).
-				WithField("keyword", keyword).
-				DebugContext(ctx, "Dispatching course intent")
-		}
+		logger.FromContext(ctx).
+			WithField("keyword", keyword).
+			DebugContext(ctx, "Dispatching course intent")
 		return h.handleExtendedCourseSearch(ctx, keyword), nil
 
 	case IntentHistorical:
@@ -320,15 +423,30 @@ func (h *Handler) DispatchIntent(ctx context.Context, intent string, params map[
 			return nil, fmt.Errorf("invalid year format: %s", yearStr)
 		}
 
-		if h.logger != nil {
-			h.logger.WithModule(ModuleName).
-				WithField("intent", intent).
-				WithField("year", year).
-				WithField("keyword", keyword).
-				DebugContext(ctx, "Dispatching course intent")
-		}
+		logger.FromContext(ctx).
+			WithField("year", year).
+			WithField("keyword", keyword).
+			DebugContext(ctx, "Dispatching course intent")
 		return h.handleHistoricalCourseSearch(ctx, year, keyword), nil
 
+	case IntentRandom:
+		// Both params are optional; an unknown degree means any level
+		eduCode := randomDegrees[params["degree"]]
+		department := strings.TrimSpace(params["department"])
+		logger.FromContext(ctx).
+			WithField("edu_code", eduCode).
+			WithField("department", department).
+			DebugContext(ctx, "Dispatching course intent")
+		return h.handleRandomCourse(ctx, eduCode, department), nil
+
+	case IntentRemote:
+		// The keyword is optional; without one every remote course is listed
+		keyword := strings.TrimSpace(params["keyword"])
+		logger.FromContext(ctx).
+			WithField("keyword", keyword).
+			DebugContext(ctx, "Dispatching course intent")
+		return h.handleRemoteCourseSearch(ctx, keyword), nil
+
 	default:
 		return nil, fmt.Errorf("%w: %s", domerrors.ErrUnknownIntent, intent)
 	}
@@ -354,7 +472,7 @@ func (h *Handler) CanHandle(text string) bool {
 // HandleMessage finds the matching pattern and executes its handler.
 // Returns empty slice if no pattern matches (fallback to NLU).
 func (h *Handler) HandleMessage(ctx context.Context, text string) []messaging_api.MessageInterface {
-	log := h.logger.WithModule(ModuleName)
+	log := logger.FromContext(ctx)
 	text = strings.TrimSpace(text)
 
 	log.DebugContext(ctx, "Handling course message")
@@ -417,7 +535,7 @@ func (h *Handler) handleCourseNoPattern(ctx context.Context, text string, matche
 func (h *Handler) handleHistoricalPattern(ctx context.Context, text string, matches []string) []messaging_api.MessageInterface {
 	// Defensive validation (should not happen if regex is correct)
 	if len(matches) < 4 {
-		log := h.logger.WithModule(ModuleName)
+		log := logger.FromContext(ctx)
 		log.WithField("group_count", len(matches)).
 			WithField("expected", 4).
 			ErrorContext(ctx, "Historical pattern match returned insufficient groups")
@@ -449,20 +567,20 @@ func (h *Handler) handleHistoricalPattern(ctx context.Context, text string, matc
 	// ROC year 0 = 1911 AD, so 2021 AD = 110 ROC
 	if year >= 1911 {
 		year = year - 1911
-		log := h.logger.WithModule(ModuleName)
+		log := logger.FromContext(ctx)
 		log.WithField("input_year", yearStr).
 			WithField("roc_year", year).
 			DebugContext(ctx, "Converted Western year to ROC")
 	}
 
 	// Validate year is within reasonable range
-	if year < config.CourseSystemLaunchYear {
+	if launchYear := data.Availability.Courses.FirstYear(); year < launchYear {
 		sender := lineutil.GetSender(senderName, h.stickerManager)
 		msg := lineutil.NewTextMessageWithConsistentSender(
 			fmt.Sprintf("⚠️ 年份過早\n\n課程系統於民國 %d 年才啟用\n請輸入 %d 年（西元 %d 年）之後的課程",
-				config.CourseSystemLaunchYear,
-				config.CourseSystemLaunchYear,
-				config.CourseSystemLaunchYear+1911),
+				launchYear,
+				launchYear,
+				launchYear+1911),
 			sender,
 		)
 		msg.QuickReply = lineutil.NewQuickReply(lineutil.QuickReplyCourseNav(h.IsBM25SearchEnabled()))
@@ -481,22 +599,7 @@ func (h *Handler) handleSmartPattern(ctx context.Context, text string, matches [
 	if searchTerm == "" {
 		// Return help message
 		sender := lineutil.GetSender(senderName, h.stickerManager)
-		var helpText string
-		if h.bm25Index != nil && h.bm25Index.IsEnabled() {
-			helpText = "🔮 智慧搜尋說明\n\n" +
-				"請描述您想找的課程內容：\n" +
-				"• 找課 想學資料分析\n" +
-				"• 找課 Python 機器學習\n" +
-				"• 找課 商業管理相關\n\n" +
-				"💡 提示\n" +
-				"• 根據課程大綱內容智慧匹配\n" +
-				"• 若知道課名，建議用「課程 名稱」"
-		} else {
-			helpText = "⚠️ 智慧搜尋目前未啟用\n\n" +
-				"請使用精確搜尋：\n" +
-				"• 課程 微積分\n" +
-				"• 課程 王小明"
-		}
+		helpText := h.texts.Text(msgtmpl.CourseSmartHelp, msgtmpl.Data{"SmartSearch": h.IsBM25SearchEnabled()})
 		msg := lineutil.NewTextMessageWithConsistentSender(helpText, sender)
 		msg.QuickReply = lineutil.NewQuickReply(lineutil.QuickReplyCourseNav(h.IsBM25SearchEnabled()))
 		return []messaging_api.MessageInterface{msg}
@@ -514,14 +617,7 @@ func (h *Handler) handleExtendedPattern(ctx context.Context, text string, matche
 	if searchTerm == "" {
 		// Return help message
 		sender := lineutil.GetSender(senderName, h.stickerManager)
-		helpText := "📅 更多學期搜尋說明\n\n" +
-			"🔍 搜尋範圍：額外 2 個歷史學期（第 3-4 學期）\n" +
-			"（精確搜尋僅搜尋近 2 學期＝最新第 1-2 學期）\n\n" +
-			"用法範例：\n" +
-			"• 更多學期 微積分\n" +
-			"• 更多學期 王小明\n\n" +
-			"📆 需要指定年份？\n" +
-			"使用：「課程 110 微積分」或「課程 2021 微積分」"
+		helpText := h.texts.Text(msgtmpl.CourseExtendedHelp, nil)
 		msg := lineutil.NewTextMessageWithConsistentSender(helpText, sender)
 		msg.QuickReply = lineutil.NewQuickReply(lineutil.QuickReplyCourseNav(h.IsBM25SearchEnabled()))
 		return []messaging_api.MessageInterface{msg}
@@ -530,6 +626,12 @@ func (h *Handler) handleExtendedPattern(ctx context.Context, text string, matche
 	return h.handleExtendedCourseSearch(ctx, searchTerm)
 }
 
+// handleRemotePattern processes remote course queries (e.g., 遠距課程, 遠距課程 管理).
+// Inline filters work as in 課程 searches (遠距課程 @資工).
+func (h *Handler) handleRemotePattern(ctx context.Context, text string, matches []string) []messaging_api.MessageInterface {
+	return h.handleRemoteCourseSearch(ctx, bot.ExtractSearchTerm(text, matches[1]))
+}
+
 // handleRegularPattern processes regular course/teacher queries (e.g., 課程 微積分).
 func (h *Handler) handleRegularPattern(ctx context.Context, text string, matches []string) []messaging_api.MessageInterface {
 	// Use matches[1] to get the keyword without trailing space
@@ -539,40 +641,9 @@ func (h *Handler) handleRegularPattern(ctx context.Context, text string, matches
 	if searchTerm == "" {
 		// Return help message with all options
 		sender := lineutil.GetSender(senderName, h.stickerManager)
-		var helpText string
-		var quickReplyItems []lineutil.QuickReplyItem
-		if h.bm25Index != nil && h.bm25Index.IsEnabled() {
-			helpText = "📚 課程查詢方式\n\n" +
-				"🔍 精確搜尋（近 2 學期）\n" +
-				"• 課程 微積分\n" +
-				"• 課程 王小明\n" +
-				"• 課程 線代 王\n\n" +
-				"🔮 智慧搜尋（近 2 學期）\n" +
-				"• 找課 想學資料分析\n" +
-				"• 找課 Python 入門\n\n" +
-				"📅 更多學期（第 3-4 學期）\n" +
-				"• 更多學期 微積分\n\n" +
-				"📆 指定年份\n" +
-				"• 課程 110 微積分（民國年）\n" +
-				"• 課程 2021 微積分（西元年）\n\n" +
-				"💡 直接輸入課號（如 U0001）\n" +
-				"   或完整編號（如 1131U0001）"
-			quickReplyItems = lineutil.QuickReplyCourseNav(true)
-		} else {
-			helpText = "📚 課程查詢方式\n\n" +
-				"🔍 精確搜尋（近 2 學期）\n" +
-				"• 課程 微積分\n" +
-				"• 課程 王小明\n" +
-				"• 課程 線代 王\n\n" +
-				"📅 更多學期（第 3-4 學期）\n" +
-				"• 更多學期 微積分\n\n" +
-				"📆 指定年份\n" +
-				"• 課程 110 微積分（民國年）\n" +
-				"• 課程 2021 微積分（西元年）\n\n" +
-				"💡 直接輸入課號（如 U0001）\n" +
-				"   或完整編號（如 1131U0001）"
-			quickReplyItems = lineutil.QuickReplyCourseNav(false)
-		}
+		smartSearch := h.IsBM25SearchEnabled()
+		helpText := h.texts.TextLang(msgtmpl.CourseHelp, ctxutil.GetLang(ctx), msgtmpl.Data{"SmartSearch": smartSearch})
+		quickReplyItems := lineutil.QuickReplyCourseNav(smartSearch)
 		msg := lineutil.NewTextMessageWithConsistentSender(helpText, sender)
 		msg.QuickReply = lineutil.NewQuickReply(quickReplyItems)
 		return []messaging_api.MessageInterface{msg}
@@ -583,36 +654,15 @@ func (h *Handler) handleRegularPattern(ctx context.Context, text string, matches
 
 // HandlePostback handles postback events for the course module
 func (h *Handler) HandlePostback(ctx context.Context, data string) []messaging_api.MessageInterface {
-	log := h.logger.WithModule(ModuleName)
+	log := logger.FromContext(ctx)
 	log.DebugContext(ctx, "Handling course postback")
 
-	// Strip module prefix if present (registry passes original data)
-	data = strings.TrimPrefix(data, "course:")
-
-	// Handle "授課課程" postback FIRST (before UID check, since teacher name might contain numbers)
-	if strings.HasPrefix(data, "授課課程") {
-		parts := strings.Split(data, bot.PostbackSplitChar)
-		if len(parts) >= 2 {
-			teacherName := parts[1]
-			log.WithField("teacher_name", teacherName).
-				DebugContext(ctx, "Handling teacher courses postback")
-			return h.handleTeacherCourseSearch(ctx, teacherName)
-		}
-	}
-
-	// Check for course UID in postback (with or without prefix)
-	// Extract the actual UID from data (e.g., "1132U2236")
-	if uidRegex.MatchString(data) {
-		uid := uidRegex.FindString(data)
-		return h.handleCourseUIDQuery(ctx, uid)
-	}
-
-	return []messaging_api.MessageInterface{}
+	return h.postbacks.Dispatch(ctx, data)
 }
 
 // handleCourseUIDQuery handles course UID queries
 func (h *Handler) handleCourseUIDQuery(ctx context.Context, uid string) []messaging_api.MessageInterface {
-	log := h.logger.WithModule(ModuleName)
+	log := logger.FromContext(ctx)
 	startTime := time.Now()
 	sender := lineutil.GetSender(senderName, h.stickerManager)
 
@@ -631,14 +681,24 @@ func (h *Handler) handleCourseUIDQuery(ctx context.Context, uid string) []messag
 
 	if course != nil {
 		// Cache hit
-		h.metrics.RecordCacheHit(ModuleName)
+		h.metrics.RecordCacheHit(ctx, ModuleName)
 		log.WithField("uid", uid).
 			DebugContext(ctx, "Course cache hit")
 		return h.formatCourseResponseWithContext(ctx, course)
 	}
 
-	// Cache miss - scrape from website
-	h.metrics.RecordCacheMiss(ModuleName)
+	// Cache miss - answer recent misses from the negative cache
+	h.metrics.RecordCacheMiss(ctx, ModuleName)
+	if miss, err := h.db.IsLookupMiss(ctx, storage.LookupMissCourseUID, uid); err != nil {
+		log.WithError(err).WarnContext(ctx, "Failed to query lookup miss cache")
+	} else if miss {
+		log.WithField("uid", uid).
+			DebugContext(ctx, "Course UID is a recent miss, skipping scrape")
+		return h.courseNotFoundMessage(uid, sender)
+	}
+
+	// Scrape from website
+	lineutil.ShowLoading(ctx)
 	log.WithField("uid", uid).
 		DebugContext(ctx, "Course cache miss, scraping course")
 
@@ -668,12 +728,10 @@ func (h *Handler) handleCourseUIDQuery(ctx context.Context, uid string) []messag
 		log.WithField("uid", uid).
 			DebugContext(ctx, "Course not found after scraping")
 		h.metrics.RecordScraperRequest(ModuleName, "not_found", time.Since(startTime).Seconds())
-		msg := lineutil.NewTextMessageWithConsistentSender(
-			fmt.Sprintf("🔍 查無課程編號 %s\n\n💡 建議\n• 確認課程編號是否正確\n• 該課程是否有開設", uid),
-			sender,
-		)
-		msg.QuickReply = lineutil.NewQuickReply(lineutil.QuickReplyCourseNav(h.IsBM25SearchEnabled()))
-		return []messaging_api.MessageInterface{msg}
+		if err := h.db.SaveLookupMiss(ctx, storage.LookupMissCourseUID, uid); err != nil {
+			log.WithError(err).WarnContext(ctx, "Failed to save lookup miss")
+		}
+		return h.courseNotFoundMessage(uid, sender)
 	}
 
 	// Save to cache
@@ -682,7 +740,7 @@ func (h *Handler) handleCourseUIDQuery(ctx context.Context, uid string) []messag
 			log.WithError(err).WarnContext(ctx, "Failed to record course delta log")
 		}
 	}
-	if err := h.db.SaveCourse(ctx, course); err != nil {
+	if err := h.saveCourse(ctx, course, false); err != nil {
 		log.WithError(err).WarnContext(ctx, "Failed to save course to cache")
 	}
 
@@ -690,10 +748,33 @@ func (h *Handler) handleCourseUIDQuery(ctx context.Context, uid string) []messag
 	return h.formatCourseResponseWithContext(ctx, course)
 }
 
+// courseNotFoundMessage is the reply for a UID the course system has no course for.
+func (h *Handler) courseNotFoundMessage(uid string, sender *messaging_api.Sender) []messaging_api.MessageInterface {
+	msg := lineutil.NewTextMessageWithConsistentSender(
+		fmt.Sprintf("🔍 查無課程編號 %s\n\n💡 建議\n• 確認課程編號是否正確\n• 該課程是否有開設", uid),
+		sender,
+	)
+	msg.QuickReply = lineutil.NewQuickReply(lineutil.QuickReplyCourseNav(h.IsBM25SearchEnabled()))
+	return []messaging_api.MessageInterface{msg}
+}
+
+// saveCourse caches a scraped course in the hot or historical table. Courses
+// that fail ntpu.ValidateCourse are not cached, so a bad parse is not served
+// from the cache until it expires.
+func (h *Handler) saveCourse(ctx context.Context, course *storage.Course, historical bool) error {
+	if err := ntpu.ValidateCourse(course); err != nil {
+		return err
+	}
+	if historical {
+		return h.db.SaveHistoricalCourse(ctx, course)
+	}
+	return h.db.SaveCourse(ctx, course)
+}
+
 // handleCourseNoQuery handles course number only queries (e.g., U0001, M0002)
 // It searches in current and previous semester to find the course
 func (h *Handler) handleCourseNoQuery(ctx context.Context, courseNo string) []messaging_api.MessageInterface {
-	log := h.logger.WithModule(ModuleName)
+	log := logger.FromContext(ctx)
 	startTime := time.Now()
 	sender := lineutil.GetSender(senderName, h.stickerManager)
 
@@ -710,7 +791,7 @@ func (h *Handler) handleCourseNoQuery(ctx context.Context, courseNo string) []me
 	for i := range searchYears {
 		year := searchYears[i]
 		term := searchTerms[i]
-		uid := fmt.Sprintf("%d%d%s", year, term, courseNo)
+		uid := ntpu.FormatUID(year, term, courseNo)
 
 		course, err := h.db.GetCourseByUID(ctx, uid)
 		if err != nil {
@@ -721,7 +802,7 @@ func (h *Handler) handleCourseNoQuery(ctx context.Context, courseNo string) []me
 		}
 
 		if course != nil {
-			h.metrics.RecordCacheHit(ModuleName)
+			h.metrics.RecordCacheHit(ctx, ModuleName)
 			log.WithField("uid", uid).
 				WithField("course_no", courseNo).
 				DebugContext(ctx, "Course cache hit")
@@ -730,14 +811,15 @@ func (h *Handler) handleCourseNoQuery(ctx context.Context, courseNo string) []me
 	}
 
 	// Cache miss - try scraping from each semester
-	h.metrics.RecordCacheMiss(ModuleName)
+	h.metrics.RecordCacheMiss(ctx, ModuleName)
+	lineutil.ShowLoading(ctx)
 	log.WithField("course_no", courseNo).
 		DebugContext(ctx, "Course cache miss, scraping by course number")
 
 	for i := range searchYears {
 		year := searchYears[i]
 		term := searchTerms[i]
-		uid := fmt.Sprintf("%d%d%s", year, term, courseNo)
+		uid := ntpu.FormatUID(year, term, courseNo)
 
 		course, err := ntpu.ScrapeCourseByUID(ctx, h.scraper, uid)
 		if err != nil {
@@ -754,7 +836,7 @@ func (h *Handler) handleCourseNoQuery(ctx context.Context, courseNo string) []me
 					log.WithError(err).WarnContext(ctx, "Failed to record course delta log")
 				}
 			}
-			if err := h.db.SaveCourse(ctx, course); err != nil {
+			if err := h.saveCourse(ctx, course, false); err != nil {
 				log.WithError(err).WarnContext(ctx, "Failed to save course to cache")
 			}
 
@@ -796,7 +878,10 @@ func (h *Handler) handleCourseNoQuery(ctx context.Context, courseNo string) []me
 //
 //     Results from both strategies are merged and deduplicated by UID.
 //
-//  3. Web scraping (external fallback): If cache has no results, scrape from website.
+//  3. Web scraping (external fallback): If cache has no results, scrape from website by title.
+//
+//  4. Deep search (1:1 chats only): If scraping also finds nothing, offer a confirm
+//     template; the all-course scrape for teacher names then runs as a background job.
 //
 // Multi-word search: "微積分 王" will find courses where title contains "微積分王"
 // OR where all characters exist in title+teachers combined.
@@ -804,6 +889,14 @@ func (h *Handler) handleUnifiedCourseSearch(ctx context.Context, searchTerm stri
 	return h.searchCoursesByKeyword(ctx, searchTerm, false)
 }
 
+// handleRemoteCourseSearch searches the recent semesters for courses flagged
+// 遠距 in their note, narrowed by keyword if given; an empty keyword lists
+// every remote course (see searchCoursesByFlags).
+func (h *Handler) handleRemoteCourseSearch(ctx context.Context, keyword string) []messaging_api.MessageInterface {
+	searchTerm := strings.TrimSpace(courseFlagLabels[storage.CourseFlagRemote] + " " + keyword)
+	return h.searchCoursesByKeyword(ctx, searchTerm, false)
+}
+
 // handleExtendedCourseSearch handles extended course search (3rd and 4th semesters).
 // This is triggered by "課程歷史" or "更多學期" keywords, typically from Quick Reply.
 // Search range: 2 additional historical semesters (excludes the 2 most recent).
@@ -817,17 +910,56 @@ func (h *Handler) handleExtendedCourseSearch(ctx context.Context, searchTerm str
 //   - "教師課程" button in course detail page
 //   - "授課課程" button in contact page (via postback)
 //
+// When several teachers share the name (e.g., 「王小明」 in two departments),
+// it asks which one is meant; a unique teacher lists that teacher's courses.
 // Shows teacher name as label and skips redundant teacher info row.
 // Uses standard 2-semester range search (recent semesters).
 func (h *Handler) handleTeacherCourseSearch(ctx context.Context, teacherName string) []messaging_api.MessageInterface {
-	log := h.logger.WithModule(ModuleName)
+	log := logger.FromContext(ctx)
 	log.WithField("teacher_name", teacherName).
 		DebugContext(ctx, "Searching courses for teacher")
 
+	teachers, err := h.db.GetTeachersByName(ctx, teacherName)
+	if err != nil {
+		log.WithError(err).WarnContext(ctx, "Failed to look up teachers by name")
+	}
+	if len(teachers) > 1 {
+		return h.teacherDisambiguationResponse(teacherName, teachers)
+	}
+
 	// Use existing search infrastructure to find courses by teacher
 	courses := h.searchCoursesForTeacher(ctx, teacherName)
+	return h.teacherCoursesResponse(teacherName, courses)
+}
 
+// handleTeacherIDCourses lists the courses of one teacher identity (newest
+// semester first). It falls back to the name search when the teacher has no
+// cached courses, e.g. after the teacher tables expired.
+func (h *Handler) handleTeacherIDCourses(ctx context.Context, id, teacherName string) []messaging_api.MessageInterface {
+	log := logger.FromContext(ctx).WithField("teacher_id", id)
+
+	courses, err := h.db.GetCoursesByTeacherID(ctx, id)
+	if err != nil {
+		log.WithError(err).WarnContext(ctx, "Failed to get courses by teacher ID")
+	}
+	if teacherName == "" {
+		if t, err := h.db.GetTeacherByID(ctx, id); err == nil && t != nil {
+			teacherName = t.Name
+		}
+	}
+	if len(courses) == 0 && teacherName != "" {
+		courses = h.searchCoursesForTeacher(ctx, teacherName)
+	}
+	if teacherName == "" {
+		return []messaging_api.MessageInterface{}
+	}
+	return h.teacherCoursesResponse(teacherName, courses)
+}
+
+// teacherCoursesResponse formats a teacher's courses, or a not-found message.
+func (h *Handler) teacherCoursesResponse(teacherName string, courses []storage.Course) []messaging_api.MessageInterface {
 	if len(courses) == 0 {
+		h.metrics.RecordZeroResults(ModuleName)
 		sender := lineutil.GetSender(senderName, h.stickerManager)
 		msg := lineutil.NewTextMessageWithConsistentSender(
 			fmt.Sprintf("🔍 查無「%s」的近期課程\n\n💡 建議嘗試\n• 確認教師姓名是否正確\n• 使用「📅 更多學期」搜尋更多歷史課程", teacherName),
@@ -844,35 +976,84 @@ func (h *Handler) handleTeacherCourseSearch(ctx context.Context, teacherName str
 	return h.formatCourseListResponseWithOptions(courses, FormatOptions{
 		TeacherName:   teacherName,
 		SearchKeyword: teacherName,
+		GroupSections: true,
 	})
 }
 
+// teacherDisambiguationResponse asks which of the teachers sharing a name is
+// meant. Each teacher is described by department and contact title when known.
+func (h *Handler) teacherDisambiguationResponse(teacherName string, teachers []storage.Teacher) []messaging_api.MessageInterface {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "👥 有 %d 位老師名為「%s」\n請選擇要查看的老師：\n", len(teachers), teacherName)
+
+	items := make([]lineutil.QuickReplyItem, 0, len(teachers))
+	for i, t := range teachers {
+		desc := teacherDescription(t)
+		fmt.Fprintf(&builder, "\n%d. %s（%s）", i+1, t.Name, desc)
+
+		data, err := TeacherIDPostback(t.ID, t.Name)
+		if err != nil || len(items) >= lineutil.MaxQuickReplyItemCount {
+			continue
+		}
+		label := lineutil.TruncateRunes(fmt.Sprintf("%d. %s", i+1, desc), lineutil.MaxQuickReplyLabel)
+		displayText := lineutil.TruncateRunes(fmt.Sprintf("查看 %s（%s）的課程", t.Name, desc), 40)
+		items = append(items, lineutil.QuickReplyItem{
+			Action: lineutil.NewPostbackActionWithDisplayText(label, displayText, data),
+		})
+	}
+
+	sender := lineutil.GetSender(senderName, h.stickerManager)
+	msg := lineutil.NewTextMessageWithConsistentSender(builder.String(), sender)
+	msg.QuickReply = lineutil.NewQuickReply(items)
+	return []messaging_api.MessageInterface{msg}
+}
+
+// teacherDescription returns a short label that tells same-name teachers apart.
+func teacherDescription(t storage.Teacher) string {
+	parts := make([]string, 0, 2)
+	if t.Department != "" {
+		parts = append(parts, t.Department)
+	} else if t.Profile != nil && t.Profile.Organization != "" {
+		parts = append(parts, t.Profile.Organization)
+	}
+	if t.Profile != nil && t.Profile.Title != "" {
+		parts = append(parts, t.Profile.Title)
+	}
+	if t.Profile != nil && t.Profile.PartTime && !strings.Contains(t.Profile.Title, "兼任") {
+		parts = append(parts, "兼任")
+	}
+	if len(parts) == 0 {
+		return "系所未知"
+	}
+	return strings.Join(parts, " ")
+}
+
 // searchCoursesForTeacher searches courses by teacher name using cache.
 // This is a simplified version of searchCoursesByKeyword focused on teacher search.
 // Uses SQL-level fuzzy matching for efficiency (no Go-level iteration needed).
 // Returns deduplicated courses from recent semesters.
 func (h *Handler) searchCoursesForTeacher(ctx context.Context, teacherName string) []storage.Course {
-	log := h.logger.WithModule(ModuleName)
+	log := logger.FromContext(ctx)
 	var courses []storage.Course
 
 	// Get recent semesters from data-driven detection
 	searchYears, searchTerms := h.semesterCache.GetRecentSemesters()
 
 	// Step 1: SQL LIKE search for exact teacher name match
-	teacherCourses, err := h.db.SearchCoursesByTeacher(ctx, teacherName)
+	teacherCourses, err := h.db.SearchCoursesByTeacherPage(ctx, teacherName, courseSearchPage)
 	if err != nil {
 		log.WithError(err).WarnContext(ctx, "Failed to search courses by teacher in cache")
 	} else {
-		courses = append(courses, teacherCourses...)
+		courses = append(courses, teacherCourses.Items...)
 	}
 
 	// Step 2: SQL-level fuzzy search for partial/abbreviated teacher names
 	// This replaces the inefficient Go-level iteration over all courses
-	fuzzyCourses, err := h.db.SearchCoursesByTeacherFuzzy(ctx, teacherName)
+	fuzzyCourses, err := h.db.SearchCoursesByTeacherFuzzyPage(ctx, teacherName, courseSearchPage)
 	if err != nil {
 		log.WithError(err).WarnContext(ctx, "Failed to fuzzy search courses by teacher")
 	} else {
-		courses = append(courses, fuzzyCourses...)
+		courses = append(courses, fuzzyCourses.Items...)
 	}
 
 	// Filter by semester scope
@@ -882,12 +1063,140 @@ func (h *Handler) searchCoursesForTeacher(ctx context.Context, teacherName strin
 	return sliceutil.Deduplicate(courses, func(c storage.Course) string { return c.UID })
 }
 
-func (h *Handler) getSemesterCourses(ctx context.Context, year, term int) ([]storage.Course, error) {
-	if h.courseCache == nil {
-		return h.db.GetCoursesByYearTerm(ctx, year, term)
+// matchHistoricalCourses returns up to MaxCoursesPerSearch cached historical
+// courses of year that match query by title or teacher. The year is read page
+// by page, following NextCursor, so a busy year is never cut off before its
+// matches; reading stops once enough courses match.
+func (h *Handler) matchHistoricalCourses(ctx context.Context, year int, query stringutil.RuneQuery) ([]storage.Course, error) {
+	var matched []storage.Course
+	page := storage.Page{Limit: storage.MaxSearchLimit}
+	for {
+		res, err := h.db.SearchHistoricalCoursesByYearPage(ctx, year, page)
+		if err != nil {
+			return matched, err
+		}
+		for i := range res.Items {
+			if matchesKeyword(&res.Items[i], query) {
+				matched = append(matched, res.Items[i])
+				if len(matched) == MaxCoursesPerSearch {
+					return matched, nil
+				}
+			}
+		}
+		if res.NextCursor == "" {
+			return matched, nil
+		}
+		page.Cursor = res.NextCursor
+	}
+}
+
+// searchSemesterCourses returns the courses of a semester matching q by title
+// or teacher, plus those extra accepts (nil for none).
+func (h *Handler) searchSemesterCourses(ctx context.Context, year, term int, q stringutil.RuneQuery, extra func(*storage.Course) bool) ([]storage.Course, error) {
+	if h.courseCache != nil {
+		return h.courseCache.Search(ctx, h.db, year, term, q, extra)
+	}
+
+	courses, err := h.db.GetCoursesByYearTerm(ctx, year, term)
+	if err != nil {
+		return nil, err
+	}
+	var matched []storage.Course
+	for i := range courses {
+		if matchesKeyword(&courses[i], q) || (extra != nil && extra(&courses[i])) {
+			matched = append(matched, courses[i])
+		}
+	}
+	return matched, nil
+}
+
+// findCachedCourses returns the cached courses of the given semesters that
+// match searchTerm by title or teacher. An identical search within the
+// search result cache's TTL reuses the UIDs found then, unless courses were
+// written since. The error is that of the title query; the other queries
+// only log theirs.
+func (h *Handler) findCachedCourses(ctx context.Context, searchTerm string, searchYears, searchTerms []int) ([]storage.Course, error) {
+	log := logger.FromContext(ctx)
+	key := searchResultCacheKey(searchTerm, searchYears, searchTerms)
+	writes := h.db.CourseWrites()
+
+	if uids, ok := h.searchResults.Get(key, writes); ok {
+		courses, err := h.db.GetCoursesByUIDs(ctx, uids)
+		if err == nil {
+			h.metrics.RecordSearchResultCache(ModuleName, "hit")
+			return courses, nil
+		}
+		log.WithError(err).WarnContext(ctx, "Failed to load remembered course search results")
 	}
+	h.metrics.RecordSearchResultCache(ModuleName, "miss")
 
-	return h.courseCache.Get(ctx, h.db, year, term)
+	courses, err := h.matchCachedCourses(ctx, searchTerm, searchYears, searchTerms)
+	if err != nil {
+		return nil, err
+	}
+	if len(courses) > 0 {
+		uids := make([]string, len(courses))
+		for i := range courses {
+			uids[i] = courses[i].UID
+		}
+		h.searchResults.Put(key, uids, writes)
+	}
+	return courses, nil
+}
+
+// matchCachedCourses runs the SQL LIKE and fuzzy matching of a keyword search.
+func (h *Handler) matchCachedCourses(ctx context.Context, searchTerm string, searchYears, searchTerms []int) ([]storage.Course, error) {
+	log := logger.FromContext(ctx)
+
+	// Step 1: Try SQL LIKE search for title first
+	titleCourses, err := h.db.SearchCoursesByTitlePage(ctx, searchTerm, courseSearchPage)
+	if err != nil {
+		return nil, err
+	}
+	courses := titleCourses.Items
+
+	// Step 1b: Also try SQL LIKE search for teacher
+	teacherCourses, err := h.db.SearchCoursesByTeacherPage(ctx, searchTerm, courseSearchPage)
+	if err != nil {
+		log.WithError(err).WarnContext(ctx, "Failed to search courses by teacher in cache")
+		// Don't return error, continue with title results
+	} else {
+		// Merge results, avoiding duplicates
+		courses = append(courses, teacherCourses.Items...)
+	}
+
+	// Filter SQL results by semester scope to ensure consistency
+	courses = filterCoursesBySemesters(courses, searchYears, searchTerms)
+
+	// Step 2: ALWAYS try fuzzy character-set matching to find additional results
+	// This catches cases like "線代" -> "線性代數" that SQL LIKE misses
+	// SQL LIKE only finds consecutive substrings, but fuzzy matching finds scattered characters
+
+	// Fuzzy match against all courses of the specified semesters in the cache:
+	// searchTerm matches the title OR any teacher by character set; English
+	// titles match as a case-insensitive substring instead, since every
+	// English word shares most of its letters with other titles
+	query := stringutil.NewRuneQuery(searchTerm)
+	lowerTerm := strings.ToLower(searchTerm)
+	englishTitleMatch := func(c *storage.Course) bool {
+		return c.TitleEn != "" && strings.Contains(strings.ToLower(c.TitleEn), lowerTerm)
+	}
+	for i := range searchYears {
+		year := searchYears[i]
+		term := searchTerms[i]
+		matched, err := h.searchSemesterCourses(ctx, year, term, query, englishTitleMatch)
+		if err != nil {
+			log.WithError(err).
+				WithField("year", year).
+				WithField("term", term).
+				WarnContext(ctx, "Failed to load courses for semester")
+			continue
+		}
+		courses = append(courses, matched...)
+	}
+
+	// Deduplicate results by UID (SQL LIKE and fuzzy may find overlapping results)
+	return sliceutil.Deduplicate(courses, func(c storage.Course) string { return c.UID }), nil
 }
 
 // searchCoursesByKeyword is the core keyword search implementation used by both unified and extended search.
@@ -898,12 +1207,14 @@ func (h *Handler) getSemesterCourses(ctx context.Context, year, term int) ([]sto
 //
 // Search flow:
 //  1. SQL LIKE search (title + teacher) in cache
-//  2. Fuzzy character-set matching (respects extended flag for semester range)
-//  3. Web scraping from NTPU website (if cache miss)
+//  2. Fuzzy character-set matching (respects extended flag for semester range);
+//     steps 1-2 are skipped for a search repeated within 5 minutes (SearchResultCache)
+//  3. Web scraping from NTPU website by title (if cache miss)
+//  4. Offer a confirmed deep search (background job, see deep_search.go) if still nothing
 //
 // Note: Smart search (BM25) is completely separate and triggered by "找課" keyword only.
 func (h *Handler) searchCoursesByKeyword(ctx context.Context, searchTerm string, extended bool) []messaging_api.MessageInterface {
-	log := h.logger.WithModule(ModuleName)
+	log := logger.FromContext(ctx)
 	startTime := time.Now()
 	sender := lineutil.GetSender(senderName, h.stickerManager)
 
@@ -911,13 +1222,17 @@ func (h *Handler) searchCoursesByKeyword(ctx context.Context, searchTerm string,
 	if extended {
 		semesterType = "過去 2 學期"
 	}
+
+	// Inline filters (@系所 #學期 !教師 英語授課) narrow the results; on a
+	// parse problem the whole term is searched as plain text.
+	searchTerm, filter := h.parseInlineFilters(ctx, searchTerm)
+
 	log.WithField("semester_type", semesterType).
 		WithField("search_term", searchTerm).
+		WithField("filter", filter.String()).
 		WithField("extended", extended).
 		DebugContext(ctx, "Handling course search")
 
-	var courses []storage.Course
-
 	// Get courses based on search range (2 or 4 semesters) - data-driven
 	var searchYears, searchTerms []int
 	if extended {
@@ -926,8 +1241,14 @@ func (h *Handler) searchCoursesByKeyword(ctx context.Context, searchTerm string,
 		searchYears, searchTerms = h.semesterCache.GetRecentSemesters()
 	}
 
-	// Step 1: Try SQL LIKE search for title first
-	titleCourses, err := h.db.SearchCoursesByTitle(ctx, searchTerm)
+	// Flags without a keyword (「課程 英語授課」) list the flagged courses
+	if searchTerm == "" {
+		return h.searchCoursesByFlags(ctx, filter, searchYears, searchTerms, extended)
+	}
+
+	// Steps 1-2: SQL LIKE and fuzzy matching in the cache, or the UIDs an
+	// identical search found within the last few minutes
+	courses, err := h.findCachedCourses(ctx, searchTerm, searchYears, searchTerms)
 	if err != nil {
 		log.WithError(err).ErrorContext(ctx, "Failed to search courses by title in cache")
 		h.metrics.RecordScraperRequest(ModuleName, "error", time.Since(startTime).Seconds())
@@ -942,66 +1263,23 @@ func (h *Handler) searchCoursesByKeyword(ctx context.Context, searchTerm string,
 			lineutil.ErrorMessageWithQuickReply("搜尋課程時發生問題", sender, retryText),
 		}
 	}
-	courses = append(courses, titleCourses...)
-
-	// Step 1b: Also try SQL LIKE search for teacher
-	teacherCourses, err := h.db.SearchCoursesByTeacher(ctx, searchTerm)
-	if err != nil {
-		log.WithError(err).WarnContext(ctx, "Failed to search courses by teacher in cache")
-		// Don't return error, continue with title results
-	} else {
-		// Merge results, avoiding duplicates
-		courses = append(courses, teacherCourses...)
-	}
-
-	// Filter SQL results by semester scope to ensure consistency
-	courses = filterCoursesBySemesters(courses, searchYears, searchTerms)
-
-	// Step 2: ALWAYS try fuzzy character-set matching to find additional results
-	// This catches cases like "線代" -> "線性代數" that SQL LIKE misses
-	// SQL LIKE only finds consecutive substrings, but fuzzy matching finds scattered characters
-
-	// Get all courses for the specified semesters from cache
-	for i := range searchYears {
-		year := searchYears[i]
-		term := searchTerms[i]
-		semesterCourses, err := h.getSemesterCourses(ctx, year, term)
-		if err != nil {
-			log.WithError(err).
-				WithField("year", year).
-				WithField("term", term).
-				WarnContext(ctx, "Failed to load courses for semester")
-			continue
-		}
-
-		// Fuzzy match against all courses in this semester
-		for _, c := range semesterCourses {
-			// Check if searchTerm matches title OR any teacher using fuzzy matching
-			titleMatch := stringutil.ContainsAllRunes(c.Title, searchTerm)
-			teacherMatch := false
-			for _, teacher := range c.Teachers {
-				if stringutil.ContainsAllRunes(teacher, searchTerm) {
-					teacherMatch = true
-					break
-				}
-			}
-			if titleMatch || teacherMatch {
-				courses = append(courses, c)
-			}
-		}
-	}
-
-	// Deduplicate results by UID (SQL LIKE and fuzzy may find overlapping results)
-	courses = sliceutil.Deduplicate(courses, func(c storage.Course) string { return c.UID })
 
 	if len(courses) > 0 {
-		h.metrics.RecordCacheHit(ModuleName)
+		h.metrics.RecordCacheHit(ctx, ModuleName)
 		log.WithField("count", len(courses)).
 			WithField("search_term", searchTerm).
 			DebugContext(ctx, "Course search cache hit")
+		if !filter.IsZero() {
+			// The keyword matched; scraping cannot add courses the filters would keep
+			if courses = h.applySearchFilter(ctx, courses, filter); len(courses) == 0 {
+				h.metrics.RecordZeroResults(ModuleName)
+				return h.filteredNotFoundResponse(searchTerm, filter, extended)
+			}
+		}
 		return h.formatCourseListResponseWithOptions(courses, FormatOptions{
 			SearchKeyword:    searchTerm,
 			IsExtendedSearch: extended,
+			GroupSections:    true,
 		})
 	}
 
@@ -1009,7 +1287,8 @@ func (h *Handler) searchCoursesByKeyword(ctx context.Context, searchTerm string,
 	log.WithField("search_term", searchTerm).
 		WithField("semester_type", semesterType).
 		DebugContext(ctx, "Course search cache miss, scraping")
-	h.metrics.RecordCacheMiss(ModuleName)
+	h.metrics.RecordCacheMiss(ctx, ModuleName)
+	lineutil.ShowLoading(ctx)
 
 	// Search courses from multiple semesters
 	foundCourses := make([]*storage.Course, 0)
@@ -1034,7 +1313,7 @@ func (h *Handler) searchCoursesByKeyword(ctx context.Context, searchTerm string,
 
 		// Save courses to cache and collect results
 		for _, course := range scrapedCourses {
-			if err := h.db.SaveCourse(ctx, course); err != nil {
+			if err := h.saveCourse(ctx, course, false); err != nil {
 				log.WithError(err).WarnContext(ctx, "Failed to save course to cache")
 			}
 			if !existingUIDs[course.UID] {
@@ -1044,54 +1323,6 @@ func (h *Handler) searchCoursesByKeyword(ctx context.Context, searchTerm string,
 		}
 	}
 
-	// Also scrape all courses to find by teacher name (if no results yet)
-	// WARNING: This is a heavy operation that scrapes all courses for each semester.
-	// It iterates through all education codes (U/M/N/P) since the school system
-	// doesn't support direct teacher search via URL parameters.
-	// This may take significant time and could approach the 60s webhook deadline.
-	if len(foundCourses) == 0 {
-		for i := range searchYears {
-			year := searchYears[i]
-			term := searchTerms[i]
-
-			// Scrape all courses for this semester (empty search term)
-			scrapedCourses, err := ntpu.ScrapeCourses(ctx, h.scraper, year, term, "")
-			if err != nil {
-				log.WithError(err).WithField("year", year).WithField("term", term).
-					DebugContext(ctx, "Failed to scrape all courses for year/term")
-				continue
-			}
-			if h.deltaRecorder != nil && len(scrapedCourses) > 0 {
-				if err := h.deltaRecorder.RecordCourses(ctx, scrapedCourses); err != nil {
-					log.WithError(err).WarnContext(ctx, "Failed to record course delta log")
-				}
-			}
-
-			// Filter by searchTerm (title or teacher) using fuzzy matching
-			for _, course := range scrapedCourses {
-				// Save all courses for future queries
-				if err := h.db.SaveCourse(ctx, course); err != nil {
-					log.WithError(err).WarnContext(ctx, "Failed to save course to cache")
-				}
-
-				// Check if matches title or teacher
-				titleMatch := stringutil.ContainsAllRunes(course.Title, searchTerm)
-				teacherMatch := false
-				for _, teacher := range course.Teachers {
-					if stringutil.ContainsAllRunes(teacher, searchTerm) {
-						teacherMatch = true
-						break
-					}
-				}
-
-				if (titleMatch || teacherMatch) && !existingUIDs[course.UID] {
-					foundCourses = append(foundCourses, course)
-					existingUIDs[course.UID] = true
-				}
-			}
-		}
-	}
-
 	if len(foundCourses) > 0 {
 		h.metrics.RecordScraperRequest(ModuleName, "success", time.Since(startTime).Seconds())
 		// Convert []*storage.Course to []storage.Course
@@ -1099,14 +1330,22 @@ func (h *Handler) searchCoursesByKeyword(ctx context.Context, searchTerm string,
 		for i, c := range foundCourses {
 			courses[i] = *c
 		}
+		if !filter.IsZero() {
+			if courses = h.applySearchFilter(ctx, courses, filter); len(courses) == 0 {
+				h.metrics.RecordZeroResults(ModuleName)
+				return h.filteredNotFoundResponse(searchTerm, filter, extended)
+			}
+		}
 		return h.formatCourseListResponseWithOptions(courses, FormatOptions{
 			SearchKeyword:    searchTerm,
 			IsExtendedSearch: extended,
+			GroupSections:    true,
 		})
 	}
 
 	// No results found even after scraping
 	h.metrics.RecordScraperRequest(ModuleName, "not_found", time.Since(startTime).Seconds())
+	h.metrics.RecordZeroResults(ModuleName)
 
 	// Build help message with suggestions (different for extended vs regular search)
 	var helpText string
@@ -1129,6 +1368,17 @@ func (h *Handler) searchCoursesByKeyword(ctx context.Context, searchTerm string,
 		helpText += "\n• 智慧搜尋：「找課 " + searchTerm + "」"
 	}
 
+	// Heuristic rewrites of the query (spaces, 課 suffix, multi-word, surname)
+	retries := retrySuggestions(searchTerm)
+	if len(retries) > 0 {
+		var sb strings.Builder
+		sb.WriteString("\n\n🔁 換個方式查：")
+		for _, r := range retries {
+			sb.WriteString("\n• 「" + r.Query + "」（" + r.Hint + "）")
+		}
+		helpText += sb.String()
+	}
+
 	// Try to find similar courses as suggestions
 	suggestions := h.suggestSimilarCourses(ctx, searchTerm, 3)
 	if len(suggestions) > 0 {
@@ -1145,7 +1395,12 @@ func (h *Handler) searchCoursesByKeyword(ctx context.Context, searchTerm string,
 	// Build quick reply items (consistent order as search results)
 	var quickReplyItems []lineutil.QuickReplyItem
 
-	// Add suggestion quick replies FIRST for easy tap
+	// Add retry and suggestion quick replies FIRST for easy tap
+	for _, r := range retries {
+		quickReplyItems = append(quickReplyItems,
+			lineutil.QuickReplyItem{Action: lineutil.NewMessageAction("🔁 "+lineutil.TruncateRunes(r.Label, 17), "課程 "+r.Query)},
+		)
+	}
 	for _, s := range suggestions {
 		quickReplyItems = append(quickReplyItems,
 			lineutil.QuickReplyItem{Action: lineutil.NewMessageAction("📚 "+lineutil.TruncateRunes(s, 17), "課程 "+s)},
@@ -1165,6 +1420,13 @@ func (h *Handler) searchCoursesByKeyword(ctx context.Context, searchTerm string,
 	}
 	quickReplyItems = append(quickReplyItems, lineutil.QuickReplyHelpAction())
 	msg.QuickReply = lineutil.NewQuickReply(quickReplyItems)
+
+	// The title search found nothing; offer the heavy all-course scrape
+	// (finds teacher names) as a background job instead of running it here.
+	if confirm := h.deepSearchConfirm(ctx, searchTerm, extended, sender); confirm != nil {
+		confirm.QuickReply, msg.QuickReply = msg.QuickReply, nil // LINE shows the last message's quick reply
+		return []messaging_api.MessageInterface{msg, confirm}
+	}
 	return []messaging_api.MessageInterface{msg}
 }
 
@@ -1173,16 +1435,21 @@ func (h *Handler) searchCoursesByKeyword(ctx context.Context, searchTerm string,
 // This function is called for courses older than the regular warmup range (4 semesters)
 // Supports real-time scraping for any academic year since NTPU was founded
 func (h *Handler) handleHistoricalCourseSearch(ctx context.Context, year int, keyword string) []messaging_api.MessageInterface {
-	log := h.logger.WithModule(ModuleName)
+	log := logger.FromContext(ctx)
 	startTime := time.Now()
 	sender := lineutil.GetSender(senderName, h.stickerManager)
 
 	// Validate year range: Course system launch year to current year
-	// Course system supports real-time scraping from year 90 onwards
+	// data.Availability.Courses lists the years the course system still serves
 	currentYear := time.Now().Year() - 1911
-	if year < config.CourseSystemLaunchYear || year > currentYear {
+	if period, ok := data.Availability.Courses.Period(year); ok && period.Coverage == data.CoverageNone && period.Message != "" {
+		msg := lineutil.NewTextMessageWithConsistentSender(period.Message, sender)
+		msg.QuickReply = lineutil.NewQuickReply(lineutil.QuickReplyCourseNav(h.bm25Index != nil && h.bm25Index.IsEnabled()))
+		return []messaging_api.MessageInterface{msg}
+	}
+	if !data.Availability.Courses.Available(year) || year > currentYear {
 		msg := lineutil.NewTextMessageWithConsistentSender(
-			fmt.Sprintf("❌ 無效的學年度：%d\n\n📅 可搜尋範圍：%d-%d 學年度\n（民國 %d-%d 年 = 西元 %d-%d 年）\n\n範例：\n• 課程 110 微積分\n• 課 108 線性代數", year, config.CourseSystemLaunchYear, currentYear, config.CourseSystemLaunchYear, currentYear, config.CourseSystemLaunchYear+1911, currentYear+1911),
+			h.texts.Text(msgtmpl.CourseInvalidYear, msgtmpl.Data{"Year": year}),
 			sender,
 		)
 		msg.QuickReply = lineutil.NewQuickReply(lineutil.QuickReplyCourseNav(h.bm25Index != nil && h.bm25Index.IsEnabled()))
@@ -1193,6 +1460,8 @@ func (h *Handler) handleHistoricalCourseSearch(ctx context.Context, year int, ke
 		WithField("keyword", keyword).
 		DebugContext(ctx, "Handling historical course search")
 
+	query := stringutil.NewRuneQuery(keyword)
+
 	// Check if the requested year is in the recent/active semesters (Hot Data).
 	// If so, we query the 'courses' table instead of 'historical_courses' to use the pre-warmed cache.
 	isRecent := false
@@ -1223,7 +1492,8 @@ func (h *Handler) handleHistoricalCourseSearch(ctx context.Context, year int, ke
 		// Reuse the logic from handleRegularPattern but filtered by year
 		var courses []storage.Course
 		for _, term := range []int{1, 2} {
-			termCourses, err := h.getSemesterCourses(ctx, year, term)
+			// Filter by keyword using fuzzy matching (Title or Teacher)
+			termCourses, err := h.searchSemesterCourses(ctx, year, term, query, nil)
 			if err != nil {
 				log.WithError(err).
 					WithField("year", year).
@@ -1231,27 +1501,11 @@ func (h *Handler) handleHistoricalCourseSearch(ctx context.Context, year int, ke
 					WarnContext(ctx, "Failed to load courses for semester")
 				continue
 			}
-			// Filter by keyword using fuzzy matching (Title or Teacher)
-			for _, c := range termCourses {
-				matched := stringutil.ContainsAllRunes(c.Title, keyword)
-				if !matched {
-					// Check teachers
-					for _, teacher := range c.Teachers {
-						if stringutil.ContainsAllRunes(teacher, keyword) {
-							matched = true
-							break
-						}
-					}
-				}
-
-				if matched {
-					courses = append(courses, c)
-				}
-			}
+			courses = append(courses, termCourses...)
 		}
 
 		if len(courses) > 0 {
-			h.metrics.RecordCacheHit(ModuleName)
+			h.metrics.RecordCacheHit(ctx, ModuleName)
 			// Limit results
 			if len(courses) > MaxCoursesPerSearch {
 				courses = courses[:MaxCoursesPerSearch]
@@ -1263,39 +1517,46 @@ func (h *Handler) handleHistoricalCourseSearch(ctx context.Context, year int, ke
 		// based on the logic later in this function.
 	}
 
-	// Search in historical_courses cache first
-	// Search by year (returns both semesters) from historical_courses table
-	cachedCourses, err := h.db.SearchHistoricalCoursesByYear(ctx, year)
+	// Archived catalogs (frozen after 加退選) are the official final state,
+	// so they win over the historical cache and the live site
+	archivedCourses, err := h.db.GetArchivedCoursesByYear(ctx, year)
 	if err != nil {
 		log.WithError(err).
 			WithField("year", year).
-			WarnContext(ctx, "Failed to load historical courses from cache")
+			WarnContext(ctx, "Failed to load archived courses")
 	}
-
-	var courses []storage.Course
-	// Filter by keyword using fuzzy matching (Title OR Teacher)
-	for _, c := range cachedCourses {
-		// Check title
-		if stringutil.ContainsAllRunes(c.Title, keyword) {
-			courses = append(courses, c)
-			continue
-		}
-
-		// Check teachers
-		teacherMatch := false
-		for _, teacher := range c.Teachers {
-			if stringutil.ContainsAllRunes(teacher, keyword) {
-				teacherMatch = true
-				break
-			}
+	var archived []storage.Course
+	archivedTerms := make(map[int]bool)
+	for _, c := range archivedCourses {
+		archivedTerms[c.Term] = true
+		if matchesKeyword(&c, query) {
+			archived = append(archived, c)
 		}
-		if teacherMatch {
-			courses = append(courses, c)
+	}
+	// With both terms archived, a miss is final; the live site is not asked
+	if len(archived) > 0 || len(archivedTerms) == 2 {
+		h.metrics.RecordCacheHit(ctx, ModuleName)
+		log.WithField("count", len(archived)).
+			WithField("year", year).
+			WithField("keyword", keyword).
+			DebugContext(ctx, "Archived course catalog hit")
+		if len(archived) > MaxCoursesPerSearch {
+			archived = archived[:MaxCoursesPerSearch]
 		}
+		return h.formatCourseListResponseForHistorical(archived)
+	}
+
+	// Search in historical_courses cache next
+	// Search by year (returns both semesters) from historical_courses table
+	courses, err := h.matchHistoricalCourses(ctx, year, query)
+	if err != nil {
+		log.WithError(err).
+			WithField("year", year).
+			WarnContext(ctx, "Failed to load historical courses from cache")
 	}
 
 	if len(courses) > 0 {
-		h.metrics.RecordCacheHit(ModuleName)
+		h.metrics.RecordCacheHit(ctx, ModuleName)
 		log.WithField("count", len(courses)).
 			WithField("year", year).
 			WithField("keyword", keyword).
@@ -1308,7 +1569,8 @@ func (h *Handler) handleHistoricalCourseSearch(ctx context.Context, year int, ke
 	}
 
 	// Cache miss - scrape from historical course system
-	h.metrics.RecordCacheMiss(ModuleName)
+	h.metrics.RecordCacheMiss(ctx, ModuleName)
+	lineutil.ShowLoading(ctx)
 	log.WithField("year", year).
 		WithField("keyword", keyword).
 		DebugContext(ctx, "Historical course cache miss, scraping")
@@ -1376,14 +1638,7 @@ func (h *Handler) handleHistoricalCourseSearch(ctx context.Context, year int, ke
 
 	// Save courses to correct table based on recency (Hot vs Cold)
 	for _, course := range scrapedCourses {
-		var err error
-		if isRecent {
-			err = h.db.SaveCourse(ctx, course)
-		} else {
-			err = h.db.SaveHistoricalCourse(ctx, course)
-		}
-
-		if err != nil {
+		if err := h.saveCourse(ctx, course, !isRecent); err != nil {
 			log.WithError(err).WithField("is_recent", isRecent).WarnContext(ctx, "Failed to save course to cache")
 		}
 	}
@@ -1400,6 +1655,7 @@ func (h *Handler) handleHistoricalCourseSearch(ctx context.Context, year int, ke
 
 	// No results found
 	h.metrics.RecordScraperRequest(ModuleName, "not_found", time.Since(startTime).Seconds())
+	h.metrics.RecordZeroResults(ModuleName)
 	msg := lineutil.NewTextMessageWithConsistentSender(
 		fmt.Sprintf("🔍 查無 %d 學年度「%s」的課程\n\n請確認\n• 學年度和課程名稱是否正確\n• 該課程是否有開設", year, keyword),
 		sender,
@@ -1416,8 +1672,9 @@ func (h *Handler) handleHistoricalCourseSearch(ctx context.Context, year int, ke
 func (h *Handler) formatCourseResponseWithContext(ctx context.Context, course *storage.Course) []messaging_api.MessageInterface {
 	// Header: Course title with colored background (detail page style)
 	header := lineutil.NewColoredHeader(lineutil.ColoredHeaderInfo{
-		Title: lineutil.FormatCourseTitleWithUID(course.Title, course.UID),
-		Color: lineutil.ColorHeaderCourse,
+		Title:    lineutil.FormatCourseTitleWithUID(course.Title, course.UID),
+		Subtitle: course.TitleEn,
+		Color:    lineutil.ColorHeaderCourse,
 	})
 
 	// Build body contents using BodyContentBuilder for cleaner code
@@ -1429,16 +1686,17 @@ func (h *Handler) formatCourseResponseWithContext(ctx context.Context, course *s
 		Label: "課程資訊",
 		Color: lineutil.ColorHeaderCourse,
 	}).FlexBox)
+	addCourseFlagBadges(body, course, lineutil.ColorHeaderCourse)
 
 	// 學期 info - first row (no separator between label and first row)
 	semesterText := lineutil.FormatSemester(course.Year, course.Term)
-	firstInfoRow := lineutil.NewInfoRow("📅", "開課學期", semesterText, lineutil.DefaultInfoRowStyle())
+	firstInfoRow := lineutil.NewInfoRow(lineutil.Icon(lineutil.IconSemester), "開課學期", semesterText, lineutil.DefaultInfoRowStyle())
 	body.AddComponent(firstInfoRow.FlexBox)
 
 	// 教師 info
 	if len(course.Teachers) > 0 {
 		teacherNames := strings.Join(course.Teachers, "、")
-		body.AddInfoRow("👨‍🏫", "授課教師", teacherNames, lineutil.DefaultInfoRowStyle())
+		body.AddInfoRow(lineutil.Icon(lineutil.IconTeacher), "授課教師", teacherNames, lineutil.DefaultInfoRowStyle())
 	}
 
 	// 時間 info - 轉換節次為實際時間 (課程詳細使用 wrap=true 以完整顯示所有時間)
@@ -1447,13 +1705,13 @@ func (h *Handler) formatCourseResponseWithContext(ctx context.Context, course *s
 		timeStr := strings.Join(formattedTimes, "、")
 		timeStyle := lineutil.DefaultInfoRowStyle()
 		timeStyle.Wrap = true // Full display in course detail page
-		body.AddInfoRow("⏰", "上課時間", timeStr, timeStyle)
+		body.AddInfoRow(lineutil.Icon(lineutil.IconTime), "上課時間", timeStr, timeStyle)
 	}
 
 	// 地點 info
 	if len(course.Locations) > 0 {
 		locationStr := strings.Join(course.Locations, "、")
-		body.AddInfoRow("📍", "上課地點", locationStr, lineutil.DefaultInfoRowStyle())
+		body.AddInfoRow(lineutil.Icon(lineutil.IconLocation), "上課地點", locationStr, lineutil.DefaultInfoRowStyle())
 	}
 
 	// 備註 info (課程詳細使用 wrap=true 允許較長備註顯示)
@@ -1462,7 +1720,47 @@ func (h *Handler) formatCourseResponseWithContext(ctx context.Context, course *s
 		noteStyle.ValueSize = "xs"
 		noteStyle.ValueColor = lineutil.ColorLabel // Use semantic color constant
 		noteStyle.Wrap = true                      // Allow note to wrap in detail page
-		body.AddInfoRow("📝", "備註", course.Note, noteStyle)
+		body.AddInfoRow(lineutil.Icon(lineutil.IconNote), "備註", course.Note, noteStyle)
+	}
+
+	// 先修課程 info (from the syllabus; only courses with a scraped syllabus have one)
+	prereqs, err := h.db.GetCoursePrerequisites(ctx, course.UID)
+	if err != nil {
+		logger.FromContext(ctx).
+			WithError(err).
+			WithField("uid", course.UID).
+			WarnContext(ctx, "Failed to load prerequisites for course")
+	}
+	if prereqs != nil {
+		prereqStyle := lineutil.DefaultInfoRowStyle()
+		prereqStyle.Wrap = true
+		body.AddInfoRow(lineutil.Icon(lineutil.IconPrerequisite), "先修課程", lineutil.TruncateRunes(prereqs.Statement, maxPrerequisiteRunes), prereqStyle)
+	}
+
+	// 討論熱度 info (cached Dcard/選課大全 counts; fetched in background on first view)
+	if h.buzz != nil && len(course.Teachers) > 0 {
+		if counts, ok := h.buzz.Lookup(ctx, course.Title, course.Teachers[0]); ok {
+			if hint := counts.Hint(); hint != "" {
+				body.AddInfoRow(lineutil.Icon(lineutil.IconDiscussion), "討論熱度", hint, lineutil.DefaultInfoRowStyle())
+			}
+		}
+	}
+
+	// 加退選 banner: course data may change before the next refresh
+	if inEnrollmentPeriod(time.Now()) {
+		body.AddComponent(lineutil.NewFlexText(lineutil.Icon(lineutil.IconWarning) + " " + enrollmentNotice).
+			WithSize("xs").
+			WithColor(lineutil.ColorWarning).
+			WithWrap(true).
+			WithMargin("md").FlexText)
+	}
+	// 寒暑假 note: the newest course data is the semester that just ended
+	if notice := vacationNotice(time.Now()); notice != "" {
+		body.AddComponent(lineutil.NewFlexText("📅 " + notice).
+			WithSize("xs").
+			WithColor(lineutil.ColorSubtext).
+			WithWrap(true).
+			WithMargin("md").FlexText)
 	}
 
 	// Add cache time hint (unobtrusive, right-aligned)
@@ -1478,7 +1776,7 @@ func (h *Handler) formatCourseResponseWithContext(ctx context.Context, course *s
 	// Query course programs first to determine available buttons
 	programs, err := h.db.GetCoursePrograms(ctx, course.UID)
 	if err != nil {
-		h.logger.WithModule(ModuleName).
+		logger.FromContext(ctx).
 			WithError(err).
 			WithField("uid", course.UID).
 			WarnContext(ctx, "Failed to load programs for course")
@@ -1493,8 +1791,9 @@ func (h *Handler) formatCourseResponseWithContext(ctx context.Context, course *s
 	var teacherName string
 	if len(course.Teachers) > 0 {
 		teacherName = course.Teachers[0]
-		matchingContacts, err := h.db.SearchContactsByName(ctx, teacherName)
-		if err == nil && len(matchingContacts) > 0 {
+		// One row is enough: only the match count is needed
+		matchingContacts, err := h.db.SearchContactsByNamePage(ctx, teacherName, storage.Page{Limit: 1})
+		if err == nil && matchingContacts.TotalCount > 0 {
 			hasMatchingContacts = true
 		}
 	}
@@ -1512,6 +1811,13 @@ func (h *Handler) formatCourseResponseWithContext(ctx context.Context, course *s
 		).WithStyle("primary").WithColor(lineutil.ColorButtonExternal).WithHeight("sm"))
 	}
 
+	// Button 2b: 訂閱行事曆 (if export links are configured and the course meets weekly)
+	if h.calendars != nil && len(course.Times) > 0 {
+		allButtons = append(allButtons, lineutil.NewFlexButton(
+			lineutil.NewURIAction("📅 訂閱行事曆", h.calendars.CourseCalendarURL(course.UID)),
+		).WithStyle("primary").WithColor(lineutil.ColorButtonExternal).WithHeight("sm"))
+	}
+
 	// Button 3: 相關學程 (if course has programs)
 	if len(programs) > 0 {
 		// DisplayText format: 查看 {CourseName} 相關學程 (consistent with other patterns)
@@ -1530,6 +1836,33 @@ func (h *Handler) formatCourseResponseWithContext(ctx context.Context, course *s
 		).WithStyle("primary").WithColor(lineutil.ColorButtonInternal).WithHeight("sm"))
 	}
 
+	// Button 3b: 查先修 (if prerequisite titles were parsed)
+	if prereqs != nil && len(prereqs.Titles) > 0 {
+		allButtons = append(allButtons, lineutil.NewFlexButton(
+			lineutil.NewPostbackActionWithDisplayText(
+				"🧭 查先修",
+				"查看 "+lineutil.TruncateRunes(course.Title, 32)+" 先修課程",
+				PrerequisitesPostback(course.UID),
+			),
+		).WithStyle("primary").WithColor(lineutil.ColorButtonInternal).WithHeight("sm"))
+	}
+
+	// Button 3c: 大綱差異 (if an earlier semester's syllabus of the same course number is cached)
+	if _, err := h.db.GetPreviousSyllabus(ctx, course.No, course.Year, course.Term); err == nil {
+		allButtons = append(allButtons, lineutil.NewFlexButton(
+			lineutil.NewPostbackActionWithDisplayText(
+				"🔀 大綱差異",
+				"比較與上學期大綱差異",
+				SyllabusDiffPostback(course.UID),
+			),
+		).WithStyle("primary").WithColor(lineutil.ColorButtonInternal).WithHeight("sm"))
+	} else if !errors.Is(err, domerrors.ErrNotFound) {
+		logger.FromContext(ctx).
+			WithError(err).
+			WithField("uid", course.UID).
+			WarnContext(ctx, "Failed to look up previous syllabus for course")
+	}
+
 	// Button 4: 聯繫教師 (if teacher has matching contacts)
 	if hasMatchingContacts && teacherName != "" {
 		displayText := "查看 " + teacherName + " 聯繫方式"
@@ -1554,19 +1887,26 @@ func (h *Handler) formatCourseResponseWithContext(ctx context.Context, course *s
 			).WithStyle("primary").WithColor(lineutil.ColorButtonExternal).WithHeight("sm"))
 		}
 
-		// Button 6: 教師課程
-		displayText := "查看 " + teacherName + " 其他課程"
-		if len([]rune(displayText)) > 40 {
-			safeName := lineutil.TruncateRunes(teacherName, 34)
-			displayText = "查看 " + safeName + " 其他課程"
+		// Button 6: 教師課程 (skipped if the teacher name overflows the postback limit)
+		// Links the exact teacher so a shared name does not mix in another teacher's courses
+		var teacherURL string
+		if len(course.TeacherURLs) == len(course.Teachers) {
+			teacherURL = course.TeacherURLs[0]
+		}
+		if teacherPostback, err := TeacherIDPostback(storage.TeacherID(teacherName, teacherURL), teacherName); err == nil {
+			displayText := "查看 " + teacherName + " 其他課程"
+			if len([]rune(displayText)) > 40 {
+				safeName := lineutil.TruncateRunes(teacherName, 34)
+				displayText = "查看 " + safeName + " 其他課程"
+			}
+			allButtons = append(allButtons, lineutil.NewFlexButton(
+				lineutil.NewPostbackActionWithDisplayText(
+					"👨‍🏫 教師課程",
+					displayText,
+					teacherPostback,
+				),
+			).WithStyle("primary").WithColor(lineutil.ColorButtonInternal).WithHeight("sm"))
 		}
-		allButtons = append(allButtons, lineutil.NewFlexButton(
-			lineutil.NewPostbackActionWithDisplayText(
-				"👨‍🏫 教師課程",
-				displayText,
-				fmt.Sprintf("course:授課課程%s%s", bot.PostbackSplitChar, teacherName),
-			),
-		).WithStyle("primary").WithColor(lineutil.ColorButtonInternal).WithHeight("sm"))
 
 		// Button 7: Dcard
 		dcardQuery := fmt.Sprintf("%s %s site:dcard.tw/f/ntpu", teacherName, course.Title)
@@ -1583,6 +1923,11 @@ func (h *Handler) formatCourseResponseWithContext(ctx context.Context, course *s
 		).WithStyle("primary").WithColor(lineutil.ColorButtonExternal).WithHeight("sm"))
 	}
 
+	// Button 9: 分享 (only when share links are configured)
+	if btn := h.sharer.Button("課程 " + course.UID); btn != nil {
+		allButtons = append(allButtons, btn)
+	}
+
 	// Use LayoutButtonsWithPattern to arrange buttons into rows
 	footerRows := lineutil.LayoutButtonsWithPattern(allButtons)
 	footer := lineutil.NewButtonFooter(footerRows...)
@@ -1609,7 +1954,7 @@ func (h *Handler) formatCourseResponseWithContext(ctx context.Context, course *s
 		// Add option to search for more courses by the same teacher
 		teacherName := course.Teachers[0]
 		quickReplyItems = append(quickReplyItems,
-			lineutil.QuickReplyItem{Action: lineutil.NewMessageAction("👨‍🏫 "+teacherName+"的課程", "課程 "+teacherName)},
+			lineutil.QuickReplyItem{Action: lineutil.NewMessageAction(lineutil.Icon(lineutil.IconTeacher)+" "+teacherName+"的課程", "課程 "+teacherName)},
 		)
 	}
 	quickReplyItems = append(quickReplyItems, lineutil.QuickReplyHelpAction())
@@ -1625,17 +1970,15 @@ func filterCoursesBySemesters(courses []storage.Course, years, terms []int) []st
 		return courses
 	}
 
-	// Create a map for O(1) lookup: "year-term" -> bool
-	allowed := make(map[string]bool)
+	// Create a map for O(1) lookup
+	allowed := make(map[Semester]bool, len(years))
 	for i := range years {
-		key := fmt.Sprintf("%d-%d", years[i], terms[i])
-		allowed[key] = true
+		allowed[Semester{Year: years[i], Term: terms[i]}] = true
 	}
 
 	filtered := make([]storage.Course, 0, len(courses))
 	for _, c := range courses {
-		key := fmt.Sprintf("%d-%d", c.Year, c.Term)
-		if allowed[key] {
+		if allowed[Semester{Year: c.Year, Term: c.Term}] {
 			filtered = append(filtered, c)
 		}
 	}
@@ -1652,28 +1995,18 @@ func filterCoursesBySemesters(courses []storage.Course, years, terms []int) []st
 // - Index 1: 上個學期 (second newest)
 // - Index 2+: 過去學期 (older semesters)
 func extractUniqueSemesters(courses []storage.Course) []lineutil.SemesterPair {
-	seen := make(map[string]bool)
+	seen := make(map[Semester]bool)
 	var semesters []lineutil.SemesterPair
 
 	for _, c := range courses {
-		key := fmt.Sprintf("%d-%d", c.Year, c.Term)
-		if !seen[key] {
-			seen[key] = true
-			semesters = append(semesters, lineutil.SemesterPair{
-				Year: c.Year,
-				Term: c.Term,
-			})
+		sem := Semester{Year: c.Year, Term: c.Term}
+		if !seen[sem] {
+			seen[sem] = true
+			semesters = append(semesters, sem)
 		}
 	}
 
-	// Sort by year descending, then term descending (newest first)
-	slices.SortFunc(semesters, func(a, b lineutil.SemesterPair) int {
-		if a.Year != b.Year {
-			return b.Year - a.Year // Descending
-		}
-		return b.Term - a.Term // Descending
-	})
-
+	semester.SortNewestFirst(semesters)
 	return semesters
 }
 
@@ -1682,6 +2015,7 @@ type FormatOptions struct {
 	SearchKeyword    string // Original search keyword (for "more semesters" Quick Reply)
 	IsExtendedSearch bool   // True if this is already an extended (4-semester) search (controls quick reply)
 	TeacherName      string // If non-empty, shows teacher name as label and skips teacher info row
+	GroupSections    bool   // Collapse sections of the same course into one bubble with a 其他班次 button
 }
 
 // formatCourseListResponse formats a list of courses as LINE messages with semester labels.
@@ -1733,16 +2067,21 @@ func (h *Handler) formatCourseListResponseWithOptions(courses []storage.Course,
 	sender := lineutil.GetSender(senderName, h.stickerManager)
 	var messages []messaging_api.MessageInterface
 
+	// Sections of the same course share one bubble, so the limit counts groups
+	groups := groupSections(courses, opts.GroupSections)
+
 	// Limit to 40 courses - track if truncated for warning message
-	originalCount := len(courses)
-	truncated := len(courses) > MaxCoursesPerSearch
+	originalCount := len(groups)
+	truncated := len(groups) > MaxCoursesPerSearch
 	if truncated {
-		courses = courses[:MaxCoursesPerSearch]
+		h.metrics.RecordTruncated(ModuleName)
+		groups = groups[:MaxCoursesPerSearch]
 	}
 
 	// Create bubbles for carousel (LINE API limit: max 10 bubbles per Flex Carousel)
-	bubbles := make([]messaging_api.FlexBubble, 0, len(courses))
-	for _, course := range courses {
+	bubbles := make([]messaging_api.FlexBubble, 0, len(groups))
+	for _, group := range groups {
+		course := group.course
 		// Determine display mode and get appropriate label info
 		// Three modes:
 		// 1. Teacher mode: Shows teacher name as label, skips teacher info row
@@ -1764,8 +2103,9 @@ func (h *Handler) formatCourseListResponseWithOptions(courses []storage.Course,
 		// Colored header with course title
 		heroTitle := lineutil.FormatCourseTitleWithUID(course.Title, course.UID)
 		header := lineutil.NewColoredHeader(lineutil.ColoredHeaderInfo{
-			Title: heroTitle,
-			Color: labelInfo.Color,
+			Title:    heroTitle,
+			Subtitle: course.TitleEn,
+			Color:    labelInfo.Color,
 		})
 
 		// Build body contents using BodyContentBuilder for cleaner code
@@ -1775,23 +2115,28 @@ func (h *Handler) formatCourseListResponseWithOptions(courses []storage.Course,
 		if !skipLabelRow {
 			body.AddComponent(lineutil.NewBodyLabel(labelInfo).FlexBox)
 		}
+		addCourseFlagBadges(body, &course, labelInfo.Color)
 
 		// Always show semester info row (provides essential context)
 		semesterText := lineutil.FormatSemester(course.Year, course.Term)
-		firstInfoRow := lineutil.NewInfoRow("📅", "開課學期", semesterText, lineutil.DefaultInfoRowStyle())
+		firstInfoRow := lineutil.NewInfoRow(lineutil.Icon(lineutil.IconSemester), "開課學期", semesterText, lineutil.DefaultInfoRowStyle())
 		body.AddComponent(firstInfoRow.FlexBox)
 
 		// 授課教師 - use multi-line style for better readability
 		if len(course.Teachers) > 0 && !skipTeacherRow {
 			teacherNames := strings.Join(course.Teachers, "、")
-			body.AddInfoRow("👨‍🏫", "授課教師", teacherNames, lineutil.CarouselInfoRowStyleMultiLine())
+			body.AddInfoRow(lineutil.Icon(lineutil.IconTeacher), "授課教師", teacherNames, lineutil.CarouselInfoRowStyleMultiLine())
 		}
 
 		// 上課時間 - use multi-line style for better readability
 		if len(course.Times) > 0 {
 			formattedTimes := lineutil.FormatCourseTimes(course.Times)
 			timeStr := strings.Join(formattedTimes, "、")
-			body.AddInfoRow("⏰", "上課時間", timeStr, lineutil.CarouselInfoRowStyleMultiLine())
+			body.AddInfoRow(lineutil.Icon(lineutil.IconTime), "上課時間", timeStr, lineutil.CarouselInfoRowStyleMultiLine())
+		}
+
+		if group.sections > 1 {
+			body.AddInfoRow(lineutil.Icon(lineutil.IconSections), "開課班次", fmt.Sprintf("共 %d 班", group.sections), lineutil.CarouselInfoRowStyleMultiLine())
 		}
 
 		// Footer with "View Detail" button - displayText shows declarative action
@@ -1800,12 +2145,19 @@ func (h *Handler) formatCourseListResponseWithOptions(courses []storage.Course,
 		if len([]rune(displayText)) > 40 {
 			displayText = "查看 " + lineutil.TruncateRunes(course.Title, 33) + " 詳細資訊"
 		}
-		// Use course: prefix for proper postback routing
-		footer := lineutil.NewFlexBox("vertical",
+		buttons := []messaging_api.FlexComponentInterface{
 			lineutil.NewFlexButton(
-				lineutil.NewPostbackActionWithDisplayText("ℹ️ 詳細資訊", displayText, "course:"+course.UID),
+				lineutil.NewPostbackActionWithDisplayText("ℹ️ 詳細資訊", displayText, UIDPostback(course.UID)),
 			).WithStyle("primary").WithColor(labelInfo.Color).WithHeight("sm").FlexButton,
-		).WithSpacing("sm")
+		}
+		// 其他班次 expands every section of this course in the semester
+		if group.sections > 1 {
+			sectionsData := SectionsPostback(course.Year, course.Term, storage.SectionKey(course.Title, course.Teachers))
+			buttons = append(buttons, lineutil.NewFlexButton(
+				lineutil.NewPostbackActionWithDisplayText("🧩 其他班次", "查看 "+lineutil.TruncateRunes(course.Title, 30)+" 所有班次", sectionsData),
+			).WithStyle("secondary").WithHeight("sm").FlexButton)
+		}
+		footer := lineutil.NewFlexBox("vertical", buttons...).WithSpacing("sm")
 
 		bubble := lineutil.NewFlexBubble(
 			header,
@@ -1843,13 +2195,20 @@ func (h *Handler) formatCourseListResponseWithOptions(courses []storage.Course,
 		messages = append(messages, msg)
 	}
 
-	// Append warning message at the end if results were truncated
+	// Append notices at the end: 加退選 banner, 寒暑假 note, and a warning if results were truncated
+	var notices []string
+	if inEnrollmentPeriod(time.Now()) {
+		notices = append(notices, "⚠️ "+enrollmentNotice)
+	}
+	if notice := vacationNotice(time.Now()); notice != "" {
+		notices = append(notices, "📅 "+notice)
+	}
 	if truncated {
-		warningMsg := lineutil.NewTextMessageWithConsistentSender(
-			fmt.Sprintf("⚠️ 搜尋結果共 %d 門課程，僅顯示前 %d 門\n建議使用更精確的搜尋條件以縮小範圍", originalCount, MaxCoursesPerSearch),
-			sender,
-		)
-		messages = append(messages, warningMsg)
+		notices = append(notices,
+			fmt.Sprintf("⚠️ 搜尋結果共 %d 門課程，僅顯示前 %d 門\n建議使用更精確的搜尋條件以縮小範圍", originalCount, MaxCoursesPerSearch))
+	}
+	if len(notices) > 0 {
+		messages = append(messages, lineutil.NewTextMessageWithConsistentSender(strings.Join(notices, "\n\n"), sender))
 	}
 
 	// Build Quick Reply items based on context
@@ -1895,7 +2254,7 @@ func (h *Handler) formatCourseListResponseWithOptions(courses []storage.Course,
 // Total operation is bounded by SmartSearchTimeout (30s), well within
 // the 60s webhook limit. Reply token remains valid for ~20 minutes.
 func (h *Handler) handleSmartSearch(ctx context.Context, query string) []messaging_api.MessageInterface {
-	log := h.logger.WithModule(ModuleName)
+	log := logger.FromContext(ctx)
 	startTime := time.Now()
 
 	// Check if BM25 search is enabled
@@ -1916,6 +2275,7 @@ func (h *Handler) handleSmartSearch(ctx context.Context, query string) []messagi
 	}
 
 	searchType := "bm25"
+	lineutil.ShowLoading(ctx) // Query expansion may call the LLM
 
 	// Use detached context for API calls (Query Expansion LLM + BM25 search).
 	// PreserveTracing() preserves tracing values (request ID, user ID, chat ID)
@@ -1925,6 +2285,12 @@ func (h *Handler) handleSmartSearch(ctx context.Context, query string) []messagi
 	searchCtx, cancel := context.WithTimeout(ctxutil.PreserveTracing(ctx), config.SmartSearchTimeout)
 	defer cancel()
 
+	// A leading level word (碩士 機器學習) overrides the linked account's level
+	searchQuery, level := splitSearchLevel(query)
+	if level == "" {
+		level = h.preferredLevel(searchCtx)
+	}
+
 	// Expand query for better search results (adds synonyms, translations, related terms)
 	// Examples: "AWS" → "AWS Amazon Web Services 雲端服務 雲端運算 cloud computing"
 	//
@@ -1934,7 +2300,8 @@ func (h *Handler) handleSmartSearch(ctx context.Context, query string) []messagi
 	//
 	// This design maintains low coupling - the course handler doesn't need to know
 	// about webhook sources or user sessions, it just uses the chatID from context.
-	expandedQuery := query
+	expandedQuery := searchQuery
+	promptVersion := "" // Set when the expansion prompt shaped the results
 	if h.hasQueryExpander() {
 		chatID := ctxutil.GetChatID(ctx)
 		sender := lineutil.GetSender(senderName, h.stickerManager)
@@ -1942,6 +2309,7 @@ func (h *Handler) handleSmartSearch(ctx context.Context, query string) []messagi
 		if h.llmRateLimiter != nil && chatID != "" && !h.llmRateLimiter.Allow(chatID) {
 			log.WarnContext(searchCtx, "LLM rate limit exceeded for query expansion")
 			h.metrics.RecordSearch(searchType, "rate_limited", time.Since(startTime).Seconds())
+			h.metrics.RecordQueryExpansionSkipped("rate_limit")
 			retryQRs := append([]lineutil.QuickReplyItem{lineutil.QuickReplyRetryAction("課程 " + query)}, lineutil.QuickReplyCourseNav(false)...)
 			return []messaging_api.MessageInterface{
 				lineutil.ErrorMessageWithQuickReply(
@@ -1953,15 +2321,20 @@ func (h *Handler) handleSmartSearch(ctx context.Context, query string) []messagi
 			}
 		}
 
-		expansionCtx, cancelExpansion := context.WithTimeout(searchCtx, config.QueryExpansionTimeout)
-		expanded, err := h.queryExpander.Expand(expansionCtx, query)
+		prompt := h.prompts.Pick(chatID)
+		expansionCtx, cancelExpansion := context.WithTimeout(genai.WithExpansionPrompt(searchCtx, prompt), config.QueryExpansionTimeout)
+		expanded, err := h.queryExpander.Expand(expansionCtx, searchQuery)
 		cancelExpansion()
 		if err != nil {
 			log.WithError(err).WarnContext(searchCtx, "Query expansion failed, continuing smart search with original query")
-		} else if expanded != query {
+			h.metrics.RecordSearchFallback("expansion_error")
+		} else {
+			promptVersion = prompt.Version
+		}
+		if err == nil && expanded != searchQuery {
 			expandedQuery = expanded
 			log.WithFields(map[string]any{
-				"original": query,
+				"original": searchQuery,
 				"expanded": expandedQuery,
 			}).DebugContext(searchCtx, "Query expanded")
 		}
@@ -1971,18 +2344,28 @@ func (h *Handler) handleSmartSearch(ctx context.Context, query string) []messagi
 		"type":                searchType,
 		"original":            query,
 		"expanded":            expandedQuery,
-		"used_query_expander": expandedQuery != query,
+		"used_query_expander": expandedQuery != searchQuery,
+		"prompt_version":      promptVersion,
+		"level":               level,
 	}).DebugContext(searchCtx, "Smart search query prepared")
 
 	log.WithFields(map[string]any{
 		"type":                  searchType,
 		"query_length":          len(query),
 		"expanded_query_length": len(expandedQuery),
-		"used_query_expander":   expandedQuery != query,
+		"used_query_expander":   expandedQuery != searchQuery,
 	}).DebugContext(searchCtx, "Performing smart search")
 
-	// Perform BM25 search
-	results, err := h.bm25Index.SearchCourses(searchCtx, expandedQuery, 10)
+	// Perform BM25 search. With a level preference, fetch a deeper pool so
+	// same-level courses just below the cut can move up after re-ranking.
+	topN := smartSearchPerSemester
+	if level != "" {
+		topN *= 2
+	}
+	results, err := h.bm25Index.SearchCourses(searchCtx, expandedQuery, topN)
+	if err == nil && level != "" {
+		results = rankByLevel(results, level, smartSearchPerSemester)
+	}
 
 	if err != nil {
 		log.WithError(err).WarnContext(searchCtx, "Smart search failed")
@@ -2001,6 +2384,7 @@ func (h *Handler) handleSmartSearch(ctx context.Context, query string) []messagi
 	if len(results) == 0 {
 		log.DebugContext(searchCtx, "No smart search results found")
 		h.metrics.RecordSearch(searchType, "no_results", time.Since(startTime).Seconds())
+		h.metrics.RecordZeroResults(ModuleName)
 		h.metrics.RecordSearchResults(searchType, len(results))
 		sender := lineutil.GetSender(senderName, h.stickerManager)
 
@@ -2056,15 +2440,20 @@ func (h *Handler) handleSmartSearch(ctx context.Context, query string) []messagi
 	// Record successful smart search metrics
 	h.metrics.RecordSearch(searchType, "success", time.Since(startTime).Seconds())
 	h.metrics.RecordSearchResults(searchType, len(results))
+	if promptVersion != "" {
+		h.metrics.RecordExpansionPrompt(promptVersion, "served")
+	}
 
 	// Format response with confidence labels
-	return h.formatSmartSearchResponse(courses, results)
+	return h.formatSmartSearchResponse(courses, results, promptVersion)
 }
 
 // formatSmartSearchResponse formats smart search results grouped by semester.
 // Results are separated into newest and previous semester groups (10 each max).
 // Each semester gets its own carousel row for clear visual separation.
-func (h *Handler) formatSmartSearchResponse(courses []storage.Course, results []rag.SearchResult) []messaging_api.MessageInterface {
+// promptVersion tags the detail buttons so opened courses count toward the
+// click-through rate of the expansion prompt ("" when expansion was not used).
+func (h *Handler) formatSmartSearchResponse(courses []storage.Course, results []rag.SearchResult, promptVersion string) []messaging_api.MessageInterface {
 	if len(courses) == 0 {
 		sender := lineutil.GetSender(senderName, h.stickerManager)
 		msg := lineutil.NewTextMessageWithConsistentSender("🔍 未找到相關課程\n\n💡 建議嘗試\n• 換個描述方式或關鍵字\n• 使用精確搜尋：「課程 課名」\n\n👨‍🏫 查詢教師資訊？\n請使用：「聯絡 教師名」或「教授 教師名」", sender)
@@ -2133,7 +2522,7 @@ func (h *Handler) formatSmartSearchResponse(courses []storage.Course, results []
 		var bubbles []messaging_api.FlexBubble
 		for _, course := range semCourses {
 			confidence := confidenceMap[course.UID]
-			bubble := h.buildSmartCourseBubble(course, confidence)
+			bubble := h.buildSmartCourseBubble(course, confidence, promptVersion)
 			bubbles = append(bubbles, *bubble.FlexBubble)
 		}
 
@@ -2161,17 +2550,38 @@ func (h *Handler) formatSmartSearchResponse(courses []storage.Course, results []
 	return messages
 }
 
+// courseFlagLabels are the badge texts of the storage.CourseFlag* values.
+var courseFlagLabels = map[string]string{
+	storage.CourseFlagEnglish:  "英語授課",
+	storage.CourseFlagRemote:   "遠距",
+	storage.CourseFlagDeptOnly: "限本系",
+}
+
+// addCourseFlagBadges adds a badge row for the flags in the course note, if any.
+func addCourseFlagBadges(body *lineutil.BodyContentBuilder, course *storage.Course, color string) {
+	flags := storage.CourseNoteFlags(course.Note)
+	if len(flags) == 0 {
+		return
+	}
+	labels := make([]string, len(flags))
+	for i, flag := range flags {
+		labels[i] = courseFlagLabels[flag]
+	}
+	body.AddComponent(lineutil.NewBadgeRow(color, labels...).FlexBox)
+}
+
 // buildSmartCourseBubble creates a Flex Message bubble for smart search with relevance labels.
 // Uses getRelevanceLabel for confidence-based tags (green/teal gradient for relevance).
-func (h *Handler) buildSmartCourseBubble(course storage.Course, confidence float32) *lineutil.FlexBubble {
+func (h *Handler) buildSmartCourseBubble(course storage.Course, confidence float32, promptVersion string) *lineutil.FlexBubble {
 	// Get relevance label info (based on BM25 confidence)
 	labelInfo := getRelevanceLabel(confidence)
 
 	// Colored header with course title
 	heroTitle := lineutil.FormatCourseTitleWithUID(course.Title, course.UID)
 	header := lineutil.NewColoredHeader(lineutil.ColoredHeaderInfo{
-		Title: heroTitle,
-		Color: labelInfo.Color,
+		Title:    heroTitle,
+		Subtitle: course.TitleEn,
+		Color:    labelInfo.Color,
 	})
 
 	// Build body contents using BodyContentBuilder
@@ -2180,18 +2590,19 @@ func (h *Handler) buildSmartCourseBubble(course storage.Course, confidence float
 	// First row is relevance label (🎯最佳匹配/✨高度相關/📋部分相關)
 	// Note: Semester info is already in the header text message, so we don't repeat it here
 	body.AddComponent(lineutil.NewBodyLabel(labelInfo).FlexBox)
+	addCourseFlagBadges(body, &course, labelInfo.Color)
 
 	// 授課教師 - use multi-line style for better readability
 	if len(course.Teachers) > 0 {
 		teacherNames := strings.Join(course.Teachers, "、")
-		body.AddInfoRow("👨‍🏫", "授課教師", teacherNames, lineutil.CarouselInfoRowStyleMultiLine())
+		body.AddInfoRow(lineutil.Icon(lineutil.IconTeacher), "授課教師", teacherNames, lineutil.CarouselInfoRowStyleMultiLine())
 	}
 
 	// 上課時間 - use multi-line style for better readability
 	if len(course.Times) > 0 {
 		formattedTimes := lineutil.FormatCourseTimes(course.Times)
 		timeStr := strings.Join(formattedTimes, "、")
-		body.AddInfoRow("⏰", "上課時間", timeStr, lineutil.CarouselInfoRowStyleMultiLine())
+		body.AddInfoRow(lineutil.Icon(lineutil.IconTime), "上課時間", timeStr, lineutil.CarouselInfoRowStyleMultiLine())
 	}
 
 	// Footer with "View Detail" button - displayText shows declarative action
@@ -2202,7 +2613,7 @@ func (h *Handler) buildSmartCourseBubble(course storage.Course, confidence float
 	}
 	footer := lineutil.NewFlexBox("vertical",
 		lineutil.NewFlexButton(
-			lineutil.NewPostbackActionWithDisplayText("ℹ️ 詳細資訊", displayText, "course:"+course.UID),
+			lineutil.NewPostbackActionWithDisplayText("ℹ️ 詳細資訊", displayText, SmartUIDPostback(course.UID, promptVersion)),
 		).WithStyle("primary").WithColor(labelInfo.Color).WithHeight("sm").FlexButton,
 	).WithSpacing("sm")
 
@@ -2292,12 +2703,12 @@ func (h *Handler) suggestSimilarCourses(ctx context.Context, keyword string, max
 		if tried > 3 {
 			break // Bound DB queries to avoid excessive scanning
 		}
-		courses, err := h.db.SearchCoursesByTitle(ctx, word)
+		res, err := h.db.SearchCoursesByTitlePage(ctx, word, storage.Page{})
 		if err != nil {
 			continue
 		}
 
-		for _, c := range courses {
+		for _, c := range res.Items {
 			if !seen[c.Title] {
 				seen[c.Title] = true
 				suggestions = append(suggestions, c.Title)
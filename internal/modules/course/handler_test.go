@@ -2,9 +2,9 @@ package course
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -13,16 +13,12 @@ import (
 	"github.com/garyellow/ntpu-linebot-go/internal/bot"
 	"github.com/garyellow/ntpu-linebot-go/internal/ctxutil"
 	"github.com/garyellow/ntpu-linebot-go/internal/genai"
-	"github.com/garyellow/ntpu-linebot-go/internal/logger"
-	"github.com/garyellow/ntpu-linebot-go/internal/metrics"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/moduletest"
 	"github.com/garyellow/ntpu-linebot-go/internal/rag"
 	"github.com/garyellow/ntpu-linebot-go/internal/ratelimit"
-	"github.com/garyellow/ntpu-linebot-go/internal/scraper"
-	"github.com/garyellow/ntpu-linebot-go/internal/sticker"
 	"github.com/garyellow/ntpu-linebot-go/internal/storage"
 	"github.com/garyellow/ntpu-linebot-go/internal/stringutil"
 	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
-	"github.com/prometheus/client_golang/prometheus"
 )
 
 // sharedTestSegmenter is initialized once at package init to avoid concurrent
@@ -32,28 +28,8 @@ var sharedTestSegmenter = stringutil.NewSegmenter()
 func setupTestHandler(t *testing.T) *Handler {
 	t.Helper()
 
-	// Use a unique temp file database for each test to avoid shared memory conflicts
-	// when running t.Parallel() tests. The temp directory is automatically cleaned up.
-	tmpDir := t.TempDir()
-	dbPath := filepath.Join(tmpDir, "test.db")
-	db, err := storage.New(context.Background(), dbPath, 168*time.Hour)
-	if err != nil {
-		t.Fatalf("Failed to create test database: %v", err)
-	}
-	t.Cleanup(func() { _ = db.Close(context.Background()) })
-
-	// Create dependencies
-	baseURLs := map[string][]string{
-		"lms": {"https://lms.ntpu.edu.tw"},
-		"sea": {"https://sea.cc.ntpu.edu.tw"},
-	}
-	scraperClient := scraper.NewClient(30*time.Second, 3, baseURLs)
-	registry := prometheus.NewRegistry()
-	m := metrics.New(registry)
-	log := logger.New("info")
-	stickerMgr := sticker.NewManager(db, scraperClient, log)
-
-	return NewHandler(db, scraperClient, m, log, stickerMgr, nil, nil, nil, nil, nil, nil)
+	kit := moduletest.New(t, moduletest.Options{})
+	return NewHandler(kit.DB, kit.Scraper, kit.Metrics, kit.Logger, kit.Stickers, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 }
 
 // setupTestHandlerWithSemesters creates a handler with a pre-configured semester cache.
@@ -73,26 +49,8 @@ func setupTestHandlerWithSemesters(t *testing.T, semesters []struct{ year, term
 	semesterCache.Update(semesterList)
 
 	// Create handler with the pre-configured cache
-	tmpDir := t.TempDir()
-	dbPath := filepath.Join(tmpDir, "test.db")
-	db, err := storage.New(context.Background(), dbPath, 168*time.Hour)
-	if err != nil {
-		t.Fatalf("Failed to create test database: %v", err)
-	}
-	t.Cleanup(func() { _ = db.Close(context.Background()) })
-
-	// Create dependencies
-	baseURLs := map[string][]string{
-		"lms": {"https://lms.ntpu.edu.tw"},
-		"sea": {"https://sea.cc.ntpu.edu.tw"},
-	}
-	scraperClient := scraper.NewClient(30*time.Second, 3, baseURLs)
-	registry := prometheus.NewRegistry()
-	m := metrics.New(registry)
-	log := logger.New("info")
-	stickerMgr := sticker.NewManager(db, scraperClient, log)
-
-	return NewHandler(db, scraperClient, m, log, stickerMgr, nil, nil, nil, nil, semesterCache, nil)
+	kit := moduletest.New(t, moduletest.Options{})
+	return NewHandler(kit.DB, kit.Scraper, kit.Metrics, kit.Logger, kit.Stickers, nil, nil, nil, nil, semesterCache, nil, nil, nil, nil, nil)
 }
 
 func TestCanHandle(t *testing.T) {
@@ -345,6 +303,74 @@ func TestFormatCourseResponse_NoDetailURL(t *testing.T) {
 	}
 }
 
+// fakeCalendarLinks links every course to a fixed feed origin.
+type fakeCalendarLinks struct{}
+
+func (fakeCalendarLinks) CourseCalendarURL(uid string) string {
+	return "https://bot.example.com/export/courses/" + uid + "/calendar.ics?token=t"
+}
+
+func TestFormatCourseResponse_CalendarButton(t *testing.T) {
+	t.Parallel()
+	h := setupTestHandler(t)
+	ctx := context.Background()
+
+	course := &storage.Course{
+		UID: "1141U0001", Year: 114, Term: 1, No: "U0001", Title: "資料結構",
+		Teachers: []string{"王教授"}, Times: []string{"星期二 3-4"},
+	}
+	if err := h.db.SaveCourse(ctx, course); err != nil {
+		t.Fatalf("Failed to save test course: %v", err)
+	}
+
+	detailJSON := func() string {
+		t.Helper()
+		b, err := json.Marshal(h.formatCourseResponseWithContext(ctx, course))
+		if err != nil {
+			t.Fatalf("marshal: %v", err)
+		}
+		return string(b)
+	}
+
+	if got := detailJSON(); strings.Contains(got, "訂閱行事曆") {
+		t.Error("calendar button shown without export links")
+	}
+	h.SetCalendarLinks(fakeCalendarLinks{})
+	got := detailJSON()
+	if !strings.Contains(got, "訂閱行事曆") || !strings.Contains(got, "/export/courses/1141U0001/calendar.ics?token=t") {
+		t.Errorf("course detail = %s, want a 訂閱行事曆 button with the feed URL", got)
+	}
+
+	// A course without meeting times has nothing to subscribe to
+	course.Times = nil
+	if got := detailJSON(); strings.Contains(got, "訂閱行事曆") {
+		t.Error("calendar button shown for a course without meeting times")
+	}
+}
+
+func TestMatchHistoricalCourses_FollowsCursor(t *testing.T) {
+	t.Parallel()
+	h := setupTestHandler(t)
+	ctx := context.Background()
+
+	// A full page of other courses sorts ahead of the one searched for
+	courses := make([]*storage.Course, 0, storage.MaxSearchLimit+1)
+	for i := range storage.MaxSearchLimit {
+		courses = append(courses, &storage.Course{
+			UID: fmt.Sprintf("1001U%04d", i), Year: 100, Term: 1, No: fmt.Sprintf("U%04d", i), Title: fmt.Sprintf("概論%03d", i),
+		})
+	}
+	courses = append(courses, &storage.Course{UID: "1001U9999", Year: 100, Term: 1, No: "U9999", Title: "資料結構", Teachers: []string{"王教授"}})
+	if err := h.db.SaveHistoricalCoursesBatch(ctx, courses); err != nil {
+		t.Fatalf("SaveHistoricalCoursesBatch failed: %v", err)
+	}
+
+	got, err := h.matchHistoricalCourses(ctx, 100, stringutil.NewRuneQuery("資料結構"))
+	if err != nil || len(got) != 1 || got[0].UID != "1001U9999" {
+		t.Errorf("matchHistoricalCourses() = %d courses, %v; want the course on the second page", len(got), err)
+	}
+}
+
 func TestFormatCourseListResponse_Empty(t *testing.T) {
 	t.Parallel()
 	h := setupTestHandler(t)
@@ -430,6 +456,34 @@ func TestFormatCourseListResponse_Sorting(t *testing.T) {
 	}
 }
 
+func TestGroupSections(t *testing.T) {
+	t.Parallel()
+
+	courses := []storage.Course{
+		{UID: "1141U0001", Year: 114, Term: 1, Title: "微積分", Teachers: []string{"王教授"}},
+		{UID: "1141U0002", Year: 114, Term: 1, Title: "資料結構", Teachers: []string{"王教授"}},
+		{UID: "1141U0003", Year: 114, Term: 1, Title: "微積分", Teachers: []string{"王教授"}},
+		{UID: "1141U0004", Year: 114, Term: 1, Title: "微積分", Teachers: []string{"李教授"}},
+		{UID: "1132U0001", Year: 113, Term: 2, Title: "微積分", Teachers: []string{"王教授"}},
+	}
+
+	groups := groupSections(courses, true)
+	wantUIDs := []string{"1141U0001", "1141U0002", "1141U0004", "1132U0001"}
+	wantSections := []int{2, 1, 1, 1}
+	if len(groups) != len(wantUIDs) {
+		t.Fatalf("groupSections returned %d groups, want %d", len(groups), len(wantUIDs))
+	}
+	for i, g := range groups {
+		if g.course.UID != wantUIDs[i] || g.sections != wantSections[i] {
+			t.Errorf("group[%d] = %s x%d, want %s x%d", i, g.course.UID, g.sections, wantUIDs[i], wantSections[i])
+		}
+	}
+
+	if ungrouped := groupSections(courses, false); len(ungrouped) != len(courses) {
+		t.Errorf("groupSections(group=false) returned %d groups, want %d", len(ungrouped), len(courses))
+	}
+}
+
 func TestHandlePostback_InvalidData(t *testing.T) {
 	t.Parallel()
 	h := setupTestHandler(t)
@@ -558,6 +612,117 @@ func TestCanHandle_ExtendedKeywords(t *testing.T) {
 	}
 }
 
+func TestCanHandle_RandomKeywords(t *testing.T) {
+	t.Parallel()
+	h := setupTestHandler(t)
+
+	tests := []struct {
+		name  string
+		input string
+		want  bool
+	}{
+		{"隨機課程 alone", "隨機課程", true},
+		{"隨機課程 with filters", "隨機課程 碩士 資工", true},
+		{"推薦一門課", "推薦一門課", true},
+		{"抽課 with department", "抽課 法律", true},
+
+		// Should not match if not at start
+		{"隨機課程 not at start", "幫我隨機課程", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := h.CanHandle(tt.input)
+			if got != tt.want {
+				t.Errorf("CanHandle(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanHandle_RemoteKeywords(t *testing.T) {
+	t.Parallel()
+	h := setupTestHandler(t)
+
+	tests := []struct {
+		name  string
+		input string
+		want  bool
+	}{
+		{"遠距課程 alone", "遠距課程", true},
+		{"遠距課程 with keyword", "遠距課程 管理", true},
+		{"線上課程 with filter", "線上課程 @資工", true},
+
+		// Should not match if not at start
+		{"遠距課程 not at start", "有沒有遠距課程", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := h.CanHandle(tt.input)
+			if got != tt.want {
+				t.Errorf("CanHandle(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanHandle_CollegeCourses(t *testing.T) {
+	t.Parallel()
+	h := setupTestHandler(t)
+
+	tests := []struct {
+		name  string
+		input string
+		want  bool
+	}{
+		{"full name", "商學院 必修", true},
+		{"short name", "電資 選修", true},
+		{"short name with 學院", "社科學院必修", true},
+		{"all courses", "法律學院 課程", true},
+
+		{"unknown college", "醫學院 必修", false},
+		{"department is not a college", "資工 必修", false},
+		{"trailing text", "商學院 必修 統計", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := h.CanHandle(tt.input)
+			if got != tt.want {
+				t.Errorf("CanHandle(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRandomArgs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		args     string
+		wantCode string
+		wantDept string
+	}{
+		{"", "", ""},
+		{"碩士", "M", ""},
+		{"資工", "", "資工"},
+		{"大學部 資工系", "U", "資工系"},
+		{"法律 博士班", "P", "法律"},
+		{"在職 企管", "N", "企管"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.args, func(t *testing.T) {
+			t.Parallel()
+			code, dept := parseRandomArgs(tt.args)
+			if code != tt.wantCode || dept != tt.wantDept {
+				t.Errorf("parseRandomArgs(%q) = %q, %q, want %q, %q", tt.args, code, dept, tt.wantCode, tt.wantDept)
+			}
+		})
+	}
+}
+
 func TestCanHandle_HistoricalKeywords(t *testing.T) {
 	t.Parallel()
 	h := setupTestHandler(t)
@@ -753,13 +918,8 @@ func (m *mockQueryExpander) Model() string            { return "mock-model" }
 func setupTestHandlerWithSmartSearch(t *testing.T, expander *mockQueryExpander, limiter *ratelimit.KeyedLimiter) *Handler {
 	t.Helper()
 
-	tmpDir := t.TempDir()
-	dbPath := filepath.Join(tmpDir, "test.db")
-	db, err := storage.New(context.Background(), dbPath, 168*time.Hour)
-	if err != nil {
-		t.Fatalf("Failed to create test database: %v", err)
-	}
-	t.Cleanup(func() { _ = db.Close(context.Background()) })
+	kit := moduletest.New(t, moduletest.Options{})
+	db := kit.DB
 
 	// Seed a single syllabus so BM25 IsEnabled() = true after Initialize.
 	// IsEnabled() requires len(semesterIndexes) > 0, which needs at least one document.
@@ -777,17 +937,7 @@ func setupTestHandlerWithSmartSearch(t *testing.T, expander *mockQueryExpander,
 		t.Fatalf("Failed to seed test syllabus: %v", saveErr)
 	}
 
-	baseURLs := map[string][]string{
-		"lms": {"https://lms.ntpu.edu.tw"},
-		"sea": {"https://sea.cc.ntpu.edu.tw"},
-	}
-	scraperClient := scraper.NewClient(30*time.Second, 3, baseURLs)
-	registry := prometheus.NewRegistry()
-	m := metrics.New(registry)
-	log := logger.New("info")
-	stickerMgr := sticker.NewManager(db, scraperClient, log)
-
-	bm25 := rag.NewBM25Index(log, sharedTestSegmenter)
+	bm25 := rag.NewBM25Index(kit.Logger, sharedTestSegmenter)
 	if initErr := bm25.Initialize(context.Background(), db); initErr != nil {
 		t.Fatalf("Failed to initialize BM25 index: %v", initErr)
 	}
@@ -795,7 +945,7 @@ func setupTestHandlerWithSmartSearch(t *testing.T, expander *mockQueryExpander,
 		t.Fatal("BM25 index not enabled after Initialize with seeded data")
 	}
 
-	return NewHandler(db, scraperClient, m, log, stickerMgr, nil, bm25, expander, limiter, nil, sharedTestSegmenter)
+	return NewHandler(db, kit.Scraper, kit.Metrics, kit.Logger, kit.Stickers, nil, bm25, expander, limiter, nil, sharedTestSegmenter, nil, nil, nil, nil)
 }
 
 func TestHandleSmartSearch_RateLimited(t *testing.T) {
@@ -1020,6 +1170,12 @@ func TestDispatchIntent_ParamValidation(t *testing.T) {
 			params:      map[string]string{"uid": ""},
 			errContains: "missing required parameter: uid",
 		},
+		{
+			name:        "uid intent malformed uid",
+			intent:      IntentUID,
+			params:      map[string]string{"uid": "1133U0001"},
+			errContains: "invalid course UID",
+		},
 		{
 			name:        "extended intent missing keyword",
 			intent:      IntentExtended,
@@ -1122,6 +1278,18 @@ func TestDispatchIntent_Integration(t *testing.T) {
 			params:       map[string]string{"year": "110", "keyword": "微積分"},
 			wantMessages: true,
 		},
+		{
+			name:         "random intent without filters",
+			intent:       IntentRandom,
+			params:       map[string]string{},
+			wantMessages: true,
+		},
+		{
+			name:         "remote intent without keyword",
+			intent:       IntentRemote,
+			params:       map[string]string{},
+			wantMessages: true,
+		},
 		// Smart search requires BM25Index setup, tested separately
 	}
 
@@ -1542,6 +1710,87 @@ func TestHandlePostback_TeacherCourse(t *testing.T) {
 	if len(msgs) == 0 {
 		t.Error("Expected messages for teacher course postback, got none")
 	}
+
+	// Test: Typed postback should route to the same handler
+	data, err := TeacherPostback("王教授")
+	if err != nil {
+		t.Fatalf("TeacherPostback failed: %v", err)
+	}
+	msgs = h.HandlePostback(ctx, data)
+	if len(msgs) == 0 {
+		t.Error("Expected messages for typed teacher course postback, got none")
+	}
+}
+
+func TestHandlePostback_Prerequisites(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	h := setupTestHandler(t)
+
+	if err := h.db.SaveCoursePrerequisites(ctx, "1131U0002", "微積分、線性代數", []string{"微積分", "線性代數"}); err != nil {
+		t.Fatalf("SaveCoursePrerequisites failed: %v", err)
+	}
+
+	// Several titles: one text message offering each title as a quick reply
+	msgs := h.HandlePostback(ctx, PrerequisitesPostback("1131U0002"))
+	if len(msgs) != 1 {
+		t.Fatalf("Expected 1 message, got %d", len(msgs))
+	}
+	msg, ok := msgs[0].(*messaging_api.TextMessageV2)
+	if !ok {
+		t.Fatalf("Expected text message, got %T", msgs[0])
+	}
+	if msg.QuickReply == nil || len(msg.QuickReply.Items) != 3 {
+		t.Fatalf("Expected 2 title quick replies + help, got %+v", msg.QuickReply)
+	}
+	if action, ok := msg.QuickReply.Items[0].Action.(*messaging_api.MessageAction); !ok || action.Text != "課程 微積分" {
+		t.Errorf("first quick reply = %+v, want 課程 微積分", msg.QuickReply.Items[0].Action)
+	}
+
+	// No statement stored
+	msgs = h.HandlePostback(ctx, PrerequisitesPostback("1131U0099"))
+	if len(msgs) != 1 {
+		t.Errorf("Expected 1 not-found message, got %d", len(msgs))
+	}
+}
+
+func TestHandlePostback_Department(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	h := setupTestHandlerWithSemesters(t, []struct{ year, term int }{{113, 1}})
+
+	course := &storage.Course{
+		UID: "1131U0001", Year: 113, Term: 1, No: "U0001", Title: "程式設計", Teachers: []string{"王教授"},
+		RawProgramReqs: []storage.RawProgramReq{{Name: "資工系1", CourseType: "必"}},
+	}
+	if err := h.db.SaveCourse(ctx, course); err != nil {
+		t.Fatalf("SaveCourse failed: %v", err)
+	}
+	if err := h.db.SaveCourseMajorsBatch(ctx, []*storage.Course{course}); err != nil {
+		t.Fatalf("SaveCourseMajorsBatch failed: %v", err)
+	}
+
+	msgs := h.HandlePostback(ctx, DepartmentPostback("資工"))
+	if len(msgs) == 0 {
+		t.Fatal("Expected course list for department postback, got none")
+	}
+	if _, ok := msgs[0].(*messaging_api.FlexMessage); !ok {
+		t.Errorf("Expected flex course list, got %T", msgs[0])
+	}
+
+	// A department without courses gets the search hint
+	msgs = h.HandlePostback(ctx, DepartmentPostback("電機"))
+	if len(msgs) != 1 {
+		t.Fatalf("Expected 1 not-found message, got %d", len(msgs))
+	}
+	if msg, ok := msgs[0].(*messaging_api.TextMessageV2); !ok || !strings.Contains(msg.Text, "查無電機系的課程") {
+		t.Errorf("Expected not-found text, got %+v", msgs[0])
+	}
+
+	// Unknown departments are ignored
+	if msgs := h.HandlePostback(ctx, DepartmentPostback("不存在")); len(msgs) != 0 {
+		t.Errorf("Expected no messages for unknown department, got %d", len(msgs))
+	}
 }
 
 // TestSuggestSimilarCourses tests that suggestSimilarCourses returns partial matches
@@ -1551,25 +1800,10 @@ func TestSuggestSimilarCourses(t *testing.T) {
 	ctx := context.Background()
 
 	// Create handler with segmenter injected
-	tmpDir := t.TempDir()
-	dbPath := filepath.Join(tmpDir, "test.db")
-	db, err := storage.New(ctx, dbPath, 168*time.Hour)
-	if err != nil {
-		t.Fatalf("Failed to create test database: %v", err)
-	}
-	t.Cleanup(func() { _ = db.Close(context.Background()) })
-
-	baseURLs := map[string][]string{
-		"lms": {"https://lms.ntpu.edu.tw"},
-		"sea": {"https://sea.cc.ntpu.edu.tw"},
-	}
-	scraperClient := scraper.NewClient(30*time.Second, 3, baseURLs)
-	registry := prometheus.NewRegistry()
-	m := metrics.New(registry)
-	log := logger.New("info")
-	stickerMgr := sticker.NewManager(db, scraperClient, log)
+	kit := moduletest.New(t, moduletest.Options{})
+	db := kit.DB
 
-	h := NewHandler(db, scraperClient, m, log, stickerMgr, nil, nil, nil, nil, nil, sharedTestSegmenter)
+	h := NewHandler(db, kit.Scraper, kit.Metrics, kit.Logger, kit.Stickers, nil, nil, nil, nil, nil, sharedTestSegmenter, nil, nil, nil, nil)
 
 	// Seed DB with courses
 	courses := []*storage.Course{
@@ -1604,7 +1838,7 @@ func TestSuggestSimilarCourses(t *testing.T) {
 	})
 
 	t.Run("nil segmenter returns nil", func(t *testing.T) {
-		hNoSeg := NewHandler(db, scraperClient, m, log, stickerMgr, nil, nil, nil, nil, nil, nil)
+		hNoSeg := NewHandler(db, kit.Scraper, kit.Metrics, kit.Logger, kit.Stickers, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 		suggestions := hNoSeg.suggestSimilarCourses(ctx, "線性代數進階", 3)
 		if suggestions != nil {
 			t.Errorf("Expected nil with no segmenter, got %v", suggestions)
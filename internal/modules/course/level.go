@@ -0,0 +1,112 @@
+package course
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/ctxutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/rag"
+	"github.com/garyellow/ntpu-linebot-go/internal/scraper/ntpu"
+)
+
+// StudentIDLookup returns the student ID a LINE user linked through school
+// SSO, or "" when the user has not linked one (implemented by *account.Store).
+type StudentIDLookup interface {
+	StudentID(ctx context.Context, userID string) (string, error)
+}
+
+// smartSearchPerSemester is how many smart search results are shown per
+// semester.
+const smartSearchPerSemester = 10
+
+// levelMismatchPenalty scales the confidence of smart search results whose
+// education level differs from the preferred one. Strong matches from
+// another level still surface; they just no longer crowd out the user's own.
+const levelMismatchPenalty = 0.6
+
+// studentLevels maps a student ID prefix to the course number prefix of the
+// courses that student usually takes.
+var studentLevels = map[string]string{
+	ntpu.StudentTypeContinuing: "U",
+	ntpu.StudentTypeUndergrad:  "U",
+	ntpu.StudentTypeMaster:     "M",
+	ntpu.StudentTypePhD:        "P",
+}
+
+// levelOfStudentID returns the course level (U/M/P) of a student ID, or ""
+// for IDs of unknown degree types.
+func levelOfStudentID(studentID string) string {
+	if studentID == "" {
+		return ""
+	}
+	return studentLevels[studentID[:1]]
+}
+
+// splitSearchLevel removes a leading education level word from a smart
+// search query and returns it as a course level code
+// (e.g., "碩士 機器學習" → "機器學習", "M"). Queries without one are
+// returned unchanged with an empty code.
+func splitSearchLevel(query string) (rest, level string) {
+	word, after, _ := strings.Cut(strings.TrimSpace(query), " ")
+	code, ok := randomLevels[word]
+	after = strings.TrimSpace(after)
+	if !ok || after == "" {
+		return query, ""
+	}
+	return after, code
+}
+
+// preferredLevel infers the course level of the current user from the
+// student ID linked to their LINE account. It returns "" when no lookup is
+// configured, the user is unlinked, or the lookup fails.
+func (h *Handler) preferredLevel(ctx context.Context) string {
+	userID := ctxutil.GetUserID(ctx)
+	if h.studentIDs == nil || userID == "" {
+		return ""
+	}
+	studentID, err := h.studentIDs.StudentID(ctx, userID)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).WarnContext(ctx, "Failed to look up linked student ID")
+		return ""
+	}
+	return levelOfStudentID(studentID)
+}
+
+// rankByLevel scales down the confidence of results whose course number
+// does not start with level, re-sorts each semester by the adjusted
+// confidence and keeps the best perSemester of each. Semesters keep their
+// newest-first order.
+func rankByLevel(results []rag.SearchResult, level string, perSemester int) []rag.SearchResult {
+	ranked := slices.Clone(results)
+	for i := range ranked {
+		_, _, no, err := ntpu.ParseUID(ranked[i].UID)
+		if err == nil && !strings.HasPrefix(no, level) {
+			ranked[i].Confidence *= levelMismatchPenalty
+		}
+	}
+
+	slices.SortStableFunc(ranked, func(a, b rag.SearchResult) int {
+		if c := cmp.Compare(b.Year, a.Year); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(b.Term, a.Term); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.Confidence, a.Confidence)
+	})
+
+	kept := ranked[:0]
+	count := make(map[[2]int]int)
+	for _, r := range ranked {
+		key := [2]int{r.Year, r.Term}
+		if count[key] >= perSemester {
+			continue
+		}
+		count[key]++
+		kept = append(kept, r)
+	}
+	return kept
+}
@@ -0,0 +1,74 @@
+package course
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/rag"
+)
+
+func TestSplitSearchLevel(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		query     string
+		wantRest  string
+		wantLevel string
+	}{
+		{"碩士 機器學習", "機器學習", "M"},
+		{"大學部  資料結構", "資料結構", "U"},
+		{"機器學習", "機器學習", ""},
+		{"碩士", "碩士", ""},           // A lone level word is the topic itself
+		{"機器學習 碩士", "機器學習 碩士", ""}, // Only a leading word counts
+	}
+	for _, tt := range tests {
+		rest, level := splitSearchLevel(tt.query)
+		if rest != tt.wantRest || level != tt.wantLevel {
+			t.Errorf("splitSearchLevel(%q) = (%q, %q), want (%q, %q)", tt.query, rest, level, tt.wantRest, tt.wantLevel)
+		}
+	}
+}
+
+func TestLevelOfStudentID(t *testing.T) {
+	t.Parallel()
+	tests := map[string]string{
+		"411012345": "U",
+		"311012345": "U",
+		"711012345": "M",
+		"811012345": "P",
+		"511012345": "",
+		"":          "",
+	}
+	for id, want := range tests {
+		if got := levelOfStudentID(id); got != want {
+			t.Errorf("levelOfStudentID(%q) = %q, want %q", id, got, want)
+		}
+	}
+}
+
+func TestRankByLevel(t *testing.T) {
+	t.Parallel()
+	results := []rag.SearchResult{
+		{UID: "1132U0001", Year: 113, Term: 2, Confidence: 1.0},
+		{UID: "1132M0001", Year: 113, Term: 2, Confidence: 0.9},
+		{UID: "1132U0002", Year: 113, Term: 2, Confidence: 0.5},
+		{UID: "1131U0003", Year: 113, Term: 1, Confidence: 1.0},
+		{UID: "1131M0002", Year: 113, Term: 1, Confidence: 0.7},
+	}
+
+	got := rankByLevel(results, "M", 2)
+	var uids []string
+	for _, r := range got {
+		uids = append(uids, r.UID)
+	}
+	// U courses drop by the penalty; each semester keeps its top 2
+	want := []string{"1132M0001", "1132U0001", "1131M0002", "1131U0003"}
+	if !slices.Equal(uids, want) {
+		t.Errorf("rankByLevel() = %v, want %v", uids, want)
+	}
+	if got[1].Confidence != 1.0*levelMismatchPenalty {
+		t.Errorf("penalized confidence = %v, want %v", got[1].Confidence, 1.0*levelMismatchPenalty)
+	}
+	if results[0].Confidence != 1.0 {
+		t.Error("rankByLevel() modified its input")
+	}
+}
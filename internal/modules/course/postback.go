@@ -0,0 +1,182 @@
+package course
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/bot"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/scraper/ntpu"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+// Postback actions for the course module.
+const (
+	// PostbackActionUID shows a single course by UID. Params: uid, pv (optional
+	// expansion prompt version of the smart search result it came from).
+	PostbackActionUID = "uid"
+	// PostbackActionTeacher lists courses taught by a teacher. Params: name, id (optional teacher ID).
+	PostbackActionTeacher = "teacher"
+	// PostbackActionDeepSearch starts a confirmed deep search. Params: q, ext ("1" = extended).
+	PostbackActionDeepSearch = "deep"
+	// PostbackActionDeepSearchCancel declines the deep search offer. No params.
+	PostbackActionDeepSearchCancel = "deep_cancel"
+	// PostbackActionSections lists every section of a course. Params: year, term, key.
+	PostbackActionSections = "sections"
+	// PostbackActionPrerequisites searches the 先修課程 of a course. Params: uid.
+	PostbackActionPrerequisites = "prereq"
+	// PostbackActionDepartment lists the newest semester's courses offered to
+	// a department. Params: dept (short name, e.g. 資工).
+	PostbackActionDepartment = "dept"
+	// PostbackActionSyllabusDiff compares a course's syllabus with the previous semester's. Params: uid.
+	PostbackActionSyllabusDiff = "syllabus_diff"
+
+	// postbackActionTeacherLegacy is the pre-v1 "授課課程$name" action,
+	// kept so buttons already sent to users continue to work.
+	postbackActionTeacherLegacy = "授課課程"
+)
+
+// UIDPostback returns postback data that opens the course detail for uid.
+func UIDPostback(uid string) string {
+	return bot.NewPostback(ModuleName, PostbackActionUID).With("uid", uid).String()
+}
+
+// SmartUIDPostback returns postback data that opens the course detail for uid
+// from a smart search result, tagged with the expansion prompt version that
+// produced it. An empty version gives plain UIDPostback data.
+func SmartUIDPostback(uid, promptVersion string) string {
+	if promptVersion == "" {
+		return UIDPostback(uid)
+	}
+	return bot.NewPostback(ModuleName, PostbackActionUID).With("uid", uid).With("pv", promptVersion).String()
+}
+
+// TeacherPostback returns postback data that lists a teacher's courses.
+// Returns an error when the teacher name makes the payload exceed LINE's limit.
+func TeacherPostback(name string) (string, error) {
+	return bot.NewPostback(ModuleName, PostbackActionTeacher).With("name", name).Encode()
+}
+
+// TeacherIDPostback returns postback data that lists the courses of one teacher
+// identity, for names shared by several teachers.
+// Returns an error when the teacher name makes the payload exceed LINE's limit.
+func TeacherIDPostback(id, name string) (string, error) {
+	return bot.NewPostback(ModuleName, PostbackActionTeacher).With("name", name).With("id", id).Encode()
+}
+
+// SectionsPostback returns postback data that lists every section sharing
+// key (storage.SectionKey) in a semester.
+func SectionsPostback(year, term int, key string) string {
+	return bot.NewPostback(ModuleName, PostbackActionSections).
+		With("year", strconv.Itoa(year)).
+		With("term", strconv.Itoa(term)).
+		With("key", key).
+		String()
+}
+
+// PrerequisitesPostback returns postback data that searches the 先修課程 of uid.
+func PrerequisitesPostback(uid string) string {
+	return bot.NewPostback(ModuleName, PostbackActionPrerequisites).With("uid", uid).String()
+}
+
+// SyllabusDiffPostback returns postback data that compares the syllabus of uid
+// with the same course's syllabus from the previous semester.
+func SyllabusDiffPostback(uid string) string {
+	return bot.NewPostback(ModuleName, PostbackActionSyllabusDiff).With("uid", uid).String()
+}
+
+// DepartmentPostback returns postback data that lists the newest semester's
+// courses offered to a department, given its short name (e.g. 資工).
+func DepartmentPostback(dept string) string {
+	return bot.NewPostback(ModuleName, PostbackActionDepartment).With("dept", dept).String()
+}
+
+// DeepSearchPostback returns postback data that starts a deep search for keyword.
+// Returns an error when the keyword makes the payload exceed LINE's limit.
+func DeepSearchPostback(keyword string, extended bool) (string, error) {
+	pb := bot.NewPostback(ModuleName, PostbackActionDeepSearch).With("q", keyword)
+	if extended {
+		pb = pb.With("ext", "1")
+	}
+	return pb.Encode()
+}
+
+// DeepSearchCancelPostback returns postback data that declines the deep search.
+func DeepSearchCancelPostback() string {
+	return bot.NewPostback(ModuleName, PostbackActionDeepSearchCancel).String()
+}
+
+// newPostbackRouter wires course postback actions to handler methods.
+func (h *Handler) newPostbackRouter() *bot.PostbackRouter {
+	return bot.NewPostbackRouter(ModuleName).
+		Handle(PostbackActionUID, func(ctx context.Context, pb *bot.Postback) []messaging_api.MessageInterface {
+			uid := pb.Get("uid")
+			if !uidRegex.MatchString(uid) {
+				return []messaging_api.MessageInterface{}
+			}
+			// Only versions still served become metric labels
+			if pv := pb.Get("pv"); pv != "" && h.prompts.Has(pv) {
+				h.metrics.RecordExpansionPrompt(pv, "click")
+			}
+			return h.handleCourseUIDQuery(ctx, uidRegex.FindString(uid))
+		}).
+		Handle(PostbackActionTeacher, func(ctx context.Context, pb *bot.Postback) []messaging_api.MessageInterface {
+			if id := pb.Get("id"); id != "" {
+				return h.handleTeacherIDCourses(ctx, id, strings.TrimSpace(pb.Get("name")))
+			}
+			return h.postbackTeacherCourses(ctx, pb.Get("name"))
+		}).
+		Handle(PostbackActionDeepSearch, func(ctx context.Context, pb *bot.Postback) []messaging_api.MessageInterface {
+			return h.startDeepSearch(ctx, pb.Get("q"), pb.Get("ext") == "1")
+		}).
+		Handle(PostbackActionSections, func(ctx context.Context, pb *bot.Postback) []messaging_api.MessageInterface {
+			return h.handleCourseSections(ctx, pb.Get("year"), pb.Get("term"), pb.Get("key"))
+		}).
+		Handle(PostbackActionPrerequisites, func(ctx context.Context, pb *bot.Postback) []messaging_api.MessageInterface {
+			uid := pb.Get("uid")
+			if !uidRegex.MatchString(uid) {
+				return []messaging_api.MessageInterface{}
+			}
+			return h.handlePrerequisiteSearch(ctx, strings.ToUpper(uidRegex.FindString(uid)))
+		}).
+		Handle(PostbackActionSyllabusDiff, func(ctx context.Context, pb *bot.Postback) []messaging_api.MessageInterface {
+			uid := pb.Get("uid")
+			if !uidRegex.MatchString(uid) {
+				return []messaging_api.MessageInterface{}
+			}
+			return h.handleSyllabusDiff(ctx, strings.ToUpper(uidRegex.FindString(uid)))
+		}).
+		Handle(PostbackActionDepartment, func(ctx context.Context, pb *bot.Postback) []messaging_api.MessageInterface {
+			dept := pb.Get("dept")
+			if _, ok := ntpu.DepartmentCodes[dept]; !ok {
+				return []messaging_api.MessageInterface{}
+			}
+			return h.handleDepartmentCourses(ctx, dept)
+		}).
+		Handle(PostbackActionDeepSearchCancel, func(context.Context, *bot.Postback) []messaging_api.MessageInterface {
+			return []messaging_api.MessageInterface{} // The display text "先不用" is enough
+		}).
+		Handle(postbackActionTeacherLegacy, func(ctx context.Context, pb *bot.Postback) []messaging_api.MessageInterface {
+			return h.postbackTeacherCourses(ctx, pb.Arg(0))
+		}).
+		// Legacy "course:{UID}" payloads carry the UID as the action segment.
+		Fallback(func(ctx context.Context, pb *bot.Postback) []messaging_api.MessageInterface {
+			if !uidRegex.MatchString(pb.Action) {
+				return []messaging_api.MessageInterface{}
+			}
+			return h.handleCourseUIDQuery(ctx, uidRegex.FindString(pb.Action))
+		})
+}
+
+// postbackTeacherCourses handles the teacher courses postback.
+func (h *Handler) postbackTeacherCourses(ctx context.Context, teacherName string) []messaging_api.MessageInterface {
+	teacherName = strings.TrimSpace(teacherName)
+	if teacherName == "" {
+		return []messaging_api.MessageInterface{}
+	}
+	logger.FromContext(ctx).
+		WithField("teacher_name", teacherName).
+		DebugContext(ctx, "Handling teacher courses postback")
+	return h.handleTeacherCourseSearch(ctx, teacherName)
+}
@@ -0,0 +1,54 @@
+package course
+
+import (
+	"context"
+	"strings"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+// handlePrerequisiteSearch searches the 先修課程 titles of a course: a single
+// title is searched directly; several are offered as quick replies.
+func (h *Handler) handlePrerequisiteSearch(ctx context.Context, uid string) []messaging_api.MessageInterface {
+	log := logger.FromContext(ctx)
+	sender := lineutil.GetSender(senderName, h.stickerManager)
+
+	prereqs, err := h.db.GetCoursePrerequisites(ctx, uid)
+	if err != nil {
+		log.WithError(err).WithField("uid", uid).ErrorContext(ctx, "Failed to get course prerequisites")
+		return []messaging_api.MessageInterface{
+			lineutil.ErrorMessageWithQuickReply("查詢先修課程時發生問題", sender, "課程 "+uid),
+		}
+	}
+	if prereqs == nil || len(prereqs.Titles) == 0 {
+		msg := lineutil.NewTextMessageWithConsistentSender("🔍 查無先修課程資料\n\n💡 課程大綱可能已更新，請重新查詢課程", sender)
+		msg.QuickReply = lineutil.NewQuickReply(lineutil.QuickReplyCourseNav(h.IsBM25SearchEnabled()))
+		return []messaging_api.MessageInterface{msg}
+	}
+
+	log.WithField("uid", uid).
+		WithField("titles", prereqs.Titles).
+		DebugContext(ctx, "Searching prerequisite courses")
+
+	if len(prereqs.Titles) == 1 {
+		return h.handleUnifiedCourseSearch(ctx, prereqs.Titles[0])
+	}
+
+	var text strings.Builder
+	text.WriteString("🧭 先修課程\n\n")
+	text.WriteString(prereqs.Statement)
+	text.WriteString("\n\n💡 點選下方課程名稱搜尋")
+	items := make([]lineutil.QuickReplyItem, 0, len(prereqs.Titles)+1)
+	for _, title := range prereqs.Titles {
+		items = append(items, lineutil.QuickReplyItem{
+			Action: lineutil.NewMessageAction(lineutil.TruncateRunes("📚 "+title, 20), "課程 "+title),
+		})
+	}
+	items = append(items, lineutil.QuickReplyHelpAction())
+
+	msg := lineutil.NewTextMessageWithConsistentSender(text.String(), sender)
+	msg.QuickReply = lineutil.NewQuickReply(items)
+	return []messaging_api.MessageInterface{msg}
+}
@@ -0,0 +1,119 @@
+package course
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+// randomLevels maps education level words to course number prefixes
+// (U=大學部, M=碩士班, N=碩士在職專班, P=博士班).
+var randomLevels = map[string]string{
+	"大學部": "U", "大學": "U", "學士": "U", "學士班": "U",
+	"碩士": "M", "碩士班": "M", "研究所": "M",
+	"碩專": "N", "在職": "N", "在職專班": "N", "碩士在職專班": "N",
+	"博士": "P", "博士班": "P",
+}
+
+// randomDegrees maps the NLU degree enum to course number prefixes.
+var randomDegrees = map[string]string{
+	"bachelor":  "U",
+	"master":    "M",
+	"inservice": "N",
+	"phd":       "P",
+}
+
+// parseRandomArgs splits the text after a random keyword into an education
+// code and a department (e.g., "碩士 資工" → "M", "資工").
+// Words other than a level form the department.
+func parseRandomArgs(args string) (eduCode, department string) {
+	var dept []string
+	for field := range strings.FieldsSeq(args) {
+		if code, ok := randomLevels[field]; ok && eduCode == "" {
+			eduCode = code
+			continue
+		}
+		dept = append(dept, field)
+	}
+	return eduCode, strings.Join(dept, "")
+}
+
+// randomCommand returns the text that draws again with the same filters.
+func randomCommand(eduCode, department string) string {
+	parts := []string{validRandomKeywords[0]}
+	if level, ok := randomLevelNames[eduCode]; ok {
+		parts = append(parts, level)
+	}
+	if department != "" {
+		parts = append(parts, department)
+	}
+	return strings.Join(parts, " ")
+}
+
+// randomLevelNames is the canonical level word of each education code,
+// used to echo filters back in 再抽一門.
+var randomLevelNames = map[string]string{
+	"U": "大學部", "M": "碩士班", "N": "碩專", "P": "博士班",
+}
+
+// handleRandomPattern processes random course picks (e.g., 隨機課程 碩士 資工).
+func (h *Handler) handleRandomPattern(ctx context.Context, text string, matches []string) []messaging_api.MessageInterface {
+	eduCode, department := parseRandomArgs(strings.TrimSpace(text[len(matches[0]):]))
+	return h.handleRandomCourse(ctx, eduCode, department)
+}
+
+// handleRandomCourse picks a random course of the newest cached semester,
+// favoring courses with rich syllabi, and offers to draw again.
+// eduCode and department are optional filters ("" = any).
+func (h *Handler) handleRandomCourse(ctx context.Context, eduCode, department string) []messaging_api.MessageInterface {
+	log := logger.FromContext(ctx)
+	sender := lineutil.GetSender(senderName, h.stickerManager)
+
+	years, terms := h.semesterCache.GetRecentSemesters()
+	if len(years) == 0 {
+		return []messaging_api.MessageInterface{
+			lineutil.ErrorMessageWithQuickReply("目前沒有可推薦的課程", sender, validRandomKeywords[0]),
+		}
+	}
+	year, term := years[0], terms[0]
+
+	course, err := h.db.GetRandomCourse(ctx, year, term, eduCode, department)
+	if err != nil {
+		log.WithError(err).ErrorContext(ctx, "Failed to pick random course")
+		return []messaging_api.MessageInterface{
+			lineutil.ErrorMessageWithQuickReply("推薦課程時發生問題", sender, randomCommand(eduCode, department)),
+		}
+	}
+	if course == nil {
+		msg := lineutil.NewTextMessageWithConsistentSender(
+			fmt.Sprintf("🎲 %s 沒有符合條件的課程\n\n💡 建議\n• 換個系所名稱（例如：資工、法律）\n• 拿掉學制條件再試一次",
+				lineutil.FormatSemester(year, term)),
+			sender,
+		)
+		msg.QuickReply = lineutil.NewQuickReply([]lineutil.QuickReplyItem{
+			{Action: lineutil.NewMessageAction("🎲 隨機課程", validRandomKeywords[0])},
+			lineutil.QuickReplyCourseAction(),
+			lineutil.QuickReplyHelpAction(),
+		})
+		return []messaging_api.MessageInterface{msg}
+	}
+
+	log.WithField("uid", course.UID).
+		WithField("edu_code", eduCode).
+		WithField("department", department).
+		DebugContext(ctx, "Random course picked")
+
+	messages := h.formatCourseResponseWithContext(ctx, course)
+	// Put 再抽一門 first so the next draw is one tap away
+	if msg, ok := messages[0].(*messaging_api.FlexMessage); ok && msg.QuickReply != nil {
+		again := lineutil.NewQuickReply([]lineutil.QuickReplyItem{
+			{Action: lineutil.NewMessageAction("🎲 再抽一門", randomCommand(eduCode, department))},
+		})
+		msg.QuickReply.Items = append(again.Items, msg.QuickReply.Items...)
+	}
+	return messages
+}
@@ -0,0 +1,109 @@
+package course
+
+import (
+	"strings"
+	"unicode"
+)
+
+// maxRetrySuggestions bounds the retry Quick Reply items so that, together with
+// similar-course suggestions and navigation, the reply stays under LINE's 13.
+const maxRetrySuggestions = 4
+
+// retrySuggestion is an alternative query proposed after a search finds nothing.
+type retrySuggestion struct {
+	Label string // Quick Reply label (without the leading emoji)
+	Query string // Search term to retry with
+	Hint  string // Why this alternative might work, shown in the reply text
+}
+
+// courseSuffixes are trailing words users add that never appear in titles
+// (e.g., "微積分課", "程式設計課程").
+var courseSuffixes = []string{"課程", "課"}
+
+// compoundSurnames are two-character surnames; other names use the first rune.
+var compoundSurnames = []string{"歐陽", "司馬", "上官", "諸葛", "東方", "皇甫", "張簡", "范姜"}
+
+// commonSurnames are frequent single-character surnames in Taiwan. Only
+// queries starting with one of these are treated as possible teacher names,
+// so course titles like "微積分" are not reduced to a single character.
+const commonSurnames = "陳林黃張李王吳劉蔡楊許鄭謝郭洪曾邱廖賴周徐蘇葉莊呂江何蕭羅高簡朱鍾施游詹沈彭胡余盧潘顏梁趙柯翁魏方孫戴范宋鄧杜侯曹薛傅丁溫紀蔣歐藍連唐馬董石卓程姚康馮古姜湯汪白田涂鄒巫尤鐘龔嚴韓袁黎金阮陸倪夏童邵柳錢"
+
+// retrySuggestions runs lightweight in-process heuristics on a search term
+// that returned nothing and proposes alternative queries, one per heuristic:
+//   - strip spaces ("資料 結構" → "資料結構")
+//   - remove the 課/課程 suffix ("微積分課" → "微積分")
+//   - split a multi-word query ("資料結構 王小明" → "資料結構", "王小明")
+//   - simplify a teacher name to the surname ("王小明" → "王")
+//
+// Results are deduplicated, never repeat the original term, and are capped at
+// maxRetrySuggestions.
+func retrySuggestions(term string) []retrySuggestion {
+	term = strings.TrimSpace(term)
+	words := strings.Fields(term)
+	compact := strings.Join(words, "")
+
+	var out []retrySuggestion
+	seen := map[string]bool{term: true}
+	add := func(label, query, hint string) {
+		if query == "" || seen[query] || len(out) >= maxRetrySuggestions {
+			return
+		}
+		seen[query] = true
+		out = append(out, retrySuggestion{Label: label, Query: query, Hint: hint})
+	}
+
+	if len(words) > 1 {
+		add(compact, compact, "去除空白")
+	}
+
+	if trimmed := trimCourseSuffix(compact); trimmed != compact {
+		add(trimmed, trimmed, "去掉「課」字")
+	}
+
+	if len(words) > 1 {
+		for _, w := range words {
+			if w = trimCourseSuffix(w); len([]rune(w)) >= 2 {
+				add(w, w, "只查其中一個詞")
+			}
+		}
+	}
+
+	if surname := teacherSurname(compact); surname != "" {
+		add(surname+"老師", surname, "只用教師姓氏")
+	}
+
+	return out
+}
+
+// trimCourseSuffix removes a trailing 課/課程 while keeping at least two runes.
+func trimCourseSuffix(s string) string {
+	for _, suffix := range courseSuffixes {
+		if trimmed, ok := strings.CutSuffix(s, suffix); ok && len([]rune(trimmed)) >= 2 {
+			return trimmed
+		}
+	}
+	return s
+}
+
+// teacherSurname returns the surname if s looks like a Chinese personal name
+// (2-4 Han characters starting with a known surname), or "" otherwise.
+func teacherSurname(s string) string {
+	runes := []rune(s)
+	if len(runes) < 2 || len(runes) > 4 {
+		return ""
+	}
+	for _, r := range runes {
+		if !unicode.Is(unicode.Han, r) {
+			return ""
+		}
+	}
+	for _, compound := range compoundSurnames {
+		if strings.HasPrefix(s, compound) && len(runes) >= 3 {
+			return compound
+		}
+	}
+	if len(runes) <= 3 && strings.ContainsRune(commonSurnames, runes[0]) {
+		return string(runes[0])
+	}
+	return ""
+}
@@ -0,0 +1,40 @@
+package course
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRetrySuggestions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		term string
+		want []string // suggested queries, in order
+	}{
+		{"spaces", "資料 結構", []string{"資料結構", "資料", "結構"}},
+		{"course suffix", "微積分課", []string{"微積分"}},
+		{"course suffix long", "程式設計課程", []string{"程式設計"}},
+		{"multi word", "資料結構 王小明", []string{"資料結構王小明", "資料結構", "王小明"}},
+		{"teacher name", "王小明", []string{"王"}},
+		{"compound surname", "歐陽小明", []string{"歐陽"}},
+		{"plain title", "微積分", nil},
+		{"english", "python", nil},
+		{"suffix only", "課", nil},
+		{"capped", "甲乙 丙丁 戊己 庚辛 壬癸", []string{"甲乙丙丁戊己庚辛壬癸", "甲乙", "丙丁", "戊己"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var got []string
+			for _, s := range retrySuggestions(tt.term) {
+				got = append(got, s.Query)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("retrySuggestions(%q) = %v, want %v", tt.term, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package course
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/stringutil"
+)
+
+const (
+	defaultSearchResultCacheTTL = 5 * time.Minute
+	maxSearchResultCacheEntries = 512
+)
+
+type searchResultCacheEntry struct {
+	uids     []string
+	storedAt time.Time
+}
+
+// SearchResultCache remembers the course UIDs a keyword search found, keyed
+// by the normalized search term and semester range, so an identical search
+// within the TTL skips the SQL LIKE queries and the fuzzy scans.
+// Entries are computed at a storage.DB.CourseWrites count; any write to the
+// courses table drops them all.
+type SearchResultCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	writes  uint64 // CourseWrites count the entries were computed at
+	entries map[string]searchResultCacheEntry
+}
+
+// NewSearchResultCache creates a cache of keyword search results.
+func NewSearchResultCache(ttl time.Duration) *SearchResultCache {
+	if ttl <= 0 {
+		ttl = defaultSearchResultCacheTTL
+	}
+
+	return &SearchResultCache{
+		ttl:     ttl,
+		entries: make(map[string]searchResultCacheEntry),
+	}
+}
+
+// searchResultCacheKey normalizes a search the way the matching treats it:
+// ASCII case and repeated whitespace do not change the results.
+func searchResultCacheKey(searchTerm string, years, terms []int) string {
+	return fmt.Sprintf("%s|%v|%v", strings.ToLower(stringutil.NormalizeWhitespace(searchTerm)), years, terms)
+}
+
+// Get returns the UIDs remembered for key when fresh and computed at the
+// current writes count. Callers must not modify the returned slice.
+func (c *SearchResultCache) Get(key string, writes uint64) ([]string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if writes != c.writes {
+		clear(c.entries)
+		c.writes = writes
+		return nil, false
+	}
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Since(entry.storedAt) >= c.ttl {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.uids, true
+}
+
+// Put remembers the UIDs found for key. writes must be read before the
+// search ran: results of a search that overlapped a write are not kept.
+func (c *SearchResultCache) Put(key string, uids []string, writes uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if writes < c.writes {
+		return
+	}
+	if writes > c.writes {
+		clear(c.entries)
+		c.writes = writes
+	}
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= maxSearchResultCacheEntries {
+		c.evictOldest()
+	}
+	c.entries[key] = searchResultCacheEntry{uids: uids, storedAt: time.Now()}
+}
+
+// evictOldest drops expired entries, or the oldest one if none has expired.
+// It must be called with c.mu held.
+func (c *SearchResultCache) evictOldest() {
+	var oldestKey string
+	var oldest time.Time
+	for key, entry := range c.entries {
+		if time.Since(entry.storedAt) >= c.ttl {
+			delete(c.entries, key)
+			continue
+		}
+		if oldestKey == "" || entry.storedAt.Before(oldest) {
+			oldestKey, oldest = key, entry.storedAt
+		}
+	}
+	if len(c.entries) >= maxSearchResultCacheEntries {
+		delete(c.entries, oldestKey)
+	}
+}
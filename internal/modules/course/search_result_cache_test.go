@@ -0,0 +1,79 @@
+package course
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSearchResultCacheKeyNormalizes(t *testing.T) {
+	t.Parallel()
+
+	years, terms := []int{114, 114}, []int{2, 1}
+	want := searchResultCacheKey("machine learning", years, terms)
+	if got := searchResultCacheKey("  Machine   LEARNING ", years, terms); got != want {
+		t.Errorf("key = %q, want %q", got, want)
+	}
+	if got := searchResultCacheKey("machine learning", []int{113, 113}, []int{2, 1}); got == want {
+		t.Error("key ignores the semester range")
+	}
+}
+
+func TestSearchResultCacheHitAndExpiry(t *testing.T) {
+	t.Parallel()
+
+	cache := NewSearchResultCache(time.Minute)
+	cache.Put("線代", []string{"1142U0001"}, 0)
+
+	uids, ok := cache.Get("線代", 0)
+	if !ok || !slices.Equal(uids, []string{"1142U0001"}) {
+		t.Fatalf("Get() = %v, %v; want the stored UIDs", uids, ok)
+	}
+	if _, ok := cache.Get("微積分", 0); ok {
+		t.Error("Get() hit for a term never stored")
+	}
+
+	cache.mu.Lock()
+	entry := cache.entries["線代"]
+	entry.storedAt = time.Now().Add(-2 * time.Minute)
+	cache.entries["線代"] = entry
+	cache.mu.Unlock()
+	if _, ok := cache.Get("線代", 0); ok {
+		t.Error("Get() hit after the TTL")
+	}
+}
+
+func TestSearchResultCacheInvalidatedByCourseWrites(t *testing.T) {
+	t.Parallel()
+
+	cache := NewSearchResultCache(time.Minute)
+	cache.Put("線代", []string{"1142U0001"}, 3)
+
+	if _, ok := cache.Get("線代", 4); ok {
+		t.Error("Get() hit after a course write")
+	}
+	// A search that started before the write must not be remembered
+	cache.Put("線代", []string{"1142U0001"}, 3)
+	if _, ok := cache.Get("線代", 4); ok {
+		t.Error("Get() hit for results computed before the write")
+	}
+}
+
+func TestSearchResultCacheBounded(t *testing.T) {
+	t.Parallel()
+
+	cache := NewSearchResultCache(time.Minute)
+	for i := range maxSearchResultCacheEntries + 10 {
+		cache.Put(fmt.Sprintf("term-%d", i), []string{"uid"}, 0)
+	}
+	cache.mu.Lock()
+	size := len(cache.entries)
+	cache.mu.Unlock()
+	if size != maxSearchResultCacheEntries {
+		t.Errorf("cache holds %d entries, want %d", size, maxSearchResultCacheEntries)
+	}
+	if _, ok := cache.Get(fmt.Sprintf("term-%d", maxSearchResultCacheEntries+9), 0); !ok {
+		t.Error("newest entry was evicted")
+	}
+}
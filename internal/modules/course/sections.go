@@ -0,0 +1,75 @@
+package course
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+// courseGroup is one bubble of a course list: a course and how many
+// sections of it (storage.SectionKey) the results contain.
+type courseGroup struct {
+	course   storage.Course
+	sections int
+}
+
+// groupSections collapses courses sharing a semester and section key into the
+// first of them, keeping result order. With group false, every course is its
+// own group.
+func groupSections(courses []storage.Course, group bool) []courseGroup {
+	groups := make([]courseGroup, 0, len(courses))
+	if !group {
+		for _, c := range courses {
+			groups = append(groups, courseGroup{course: c, sections: 1})
+		}
+		return groups
+	}
+
+	index := make(map[string]int, len(courses))
+	for _, c := range courses {
+		key := fmt.Sprintf("%d-%d-%s", c.Year, c.Term, storage.SectionKey(c.Title, c.Teachers))
+		if i, ok := index[key]; ok {
+			groups[i].sections++
+			continue
+		}
+		index[key] = len(groups)
+		groups = append(groups, courseGroup{course: c, sections: 1})
+	}
+	return groups
+}
+
+// handleCourseSections lists every section of a course in one semester,
+// one bubble per section.
+func (h *Handler) handleCourseSections(ctx context.Context, yearStr, termStr, key string) []messaging_api.MessageInterface {
+	year, errYear := strconv.Atoi(yearStr)
+	term, errTerm := strconv.Atoi(termStr)
+	if errYear != nil || errTerm != nil || key == "" {
+		return []messaging_api.MessageInterface{}
+	}
+
+	log := logger.FromContext(ctx)
+	sender := lineutil.GetSender(senderName, h.stickerManager)
+
+	sections, err := h.db.GetCourseSections(ctx, year, term, key)
+	if err != nil {
+		log.WithError(err).ErrorContext(ctx, "Failed to get course sections")
+		return []messaging_api.MessageInterface{
+			lineutil.ErrorMessageWithQuickReply("查詢班次時發生問題", sender, "課程"),
+		}
+	}
+	if len(sections) == 0 {
+		msg := lineutil.NewTextMessageWithConsistentSender("🔍 查無班次資料\n\n💡 課程資料可能已更新，請重新搜尋", sender)
+		msg.QuickReply = lineutil.NewQuickReply(lineutil.QuickReplyCourseNav(h.IsBM25SearchEnabled()))
+		return []messaging_api.MessageInterface{msg}
+	}
+
+	log.WithField("section_key", key).
+		WithField("count", len(sections)).
+		DebugContext(ctx, "Listing course sections")
+	return h.formatCourseListResponseWithOptions(sections, FormatOptions{})
+}
@@ -6,14 +6,12 @@ import (
 	"sync"
 	"time"
 
+	"github.com/garyellow/ntpu-linebot-go/internal/semester"
 	"github.com/garyellow/ntpu-linebot-go/internal/storage"
 )
 
 // Semester represents an academic semester with year and term.
-type Semester struct {
-	Year int // ROC year (e.g., 113)
-	Term int // 1 (Fall) or 2 (Spring)
-}
+type Semester = semester.Semester
 
 // SemesterCache stores detected semesters for user queries.
 // Updated by warmup, used by handlers for course searches.
@@ -113,13 +111,7 @@ func (c *SemesterCache) GetExtendedSemesters() ([]int, []int) {
 			[]int{c.semesters[2].Term, c.semesters[3].Term}
 	}
 	if len(c.semesters) > 2 {
-		years := make([]int, len(c.semesters)-2)
-		terms := make([]int, len(c.semesters)-2)
-		for i := 2; i < len(c.semesters); i++ {
-			years[i-2] = c.semesters[i].Year
-			terms[i-2] = c.semesters[i].Term
-		}
-		return years, terms
+		return semester.Split(c.semesters[2:])
 	}
 	// Fallback: no extended semesters available (cache has less than 3 semesters)
 	return []int{}, []int{}
@@ -131,13 +123,7 @@ func (c *SemesterCache) GetAllSemesters() ([]int, []int) {
 	defer c.mu.RUnlock()
 
 	if len(c.semesters) > 0 {
-		years := make([]int, len(c.semesters))
-		terms := make([]int, len(c.semesters))
-		for i, sem := range c.semesters {
-			years[i] = sem.Year
-			terms[i] = sem.Term
-		}
-		return years, terms
+		return semester.Split(c.semesters)
 	}
 	// Fallback to calendar-based
 	return getCalendarBasedSemesters(4)
@@ -152,62 +138,16 @@ func (c *SemesterCache) HasData() bool {
 
 // getCalendarBasedSemesters returns n semesters based on current date.
 // This is a fallback for when no cached data is available.
-// Uses Taiwan academic calendar logic:
-// - Semester 1 (Fall): September - January
-// - Semester 2 (Spring): February - June
-// - Summer Break: July - August
+// See semester.Current for the Taiwan academic calendar logic.
 func getCalendarBasedSemesters(count int) ([]int, []int) {
-	now := time.Now()
-	currentYear := now.Year() - 1911 // Convert AD to ROC year
-	currentMonth := int(now.Month())
-
-	var startYear, startTerm int
-
-	switch {
-	case currentMonth >= 2 && currentMonth <= 8:
-		// Spring semester or summer break (Feb-Aug)
-		// Current academic year = previous calendar year
-		startYear = currentYear - 1
-		startTerm = 2
-
-	case currentMonth >= 9:
-		// Fall semester (Sep-Dec)
-		startYear = currentYear
-		startTerm = 1
-
-	default:
-		// January - winter break, fall semester just ended
-		startYear = currentYear - 1
-		startTerm = 1
-	}
-
-	return generateSemestersBackward(startYear, startTerm, count)
+	current := semester.Current(time.Now())
+	return generateSemestersBackward(current.Year, current.Term, count)
 }
 
 // generateSemestersBackward generates n semesters going backwards from the given start point.
 // Term alternates: 2 → 1 (same year) → 2 (prev year) → 1 → ...
 func generateSemestersBackward(startYear, startTerm, count int) ([]int, []int) {
-	years := make([]int, count)
-	terms := make([]int, count)
-
-	year := startYear
-	term := startTerm
-
-	for i := range count {
-		years[i] = year
-		terms[i] = term
-
-		// Go to previous semester
-		if term == 1 {
-			term = 2
-			year--
-		} else {
-			term = 1
-			// year stays the same (term 2 → term 1 of same year)
-		}
-	}
-
-	return years, terms
+	return semester.Split(Semester{Year: startYear, Term: startTerm}.RangeBack(count))
 }
 
 // GetWarmupProbeStart returns the starting semester for warmup probing.
@@ -219,31 +159,13 @@ func generateSemestersBackward(startYear, startTerm, count int) ([]int, []int) {
 // Warmup will then probe: 115-2 → 115-1 → 114-2 → 114-1 → ... until 4 found.
 // If 115-2 has no data, it continues to find the next available semester.
 func GetWarmupProbeStart() (year, term int) {
-	now := time.Now()
-	currentYear := now.Year() - 1911 // Convert AD to ROC year
-	return currentYear, 2
+	start := semester.ProbeStart(time.Now())
+	return start.Year, start.Term
 }
 
 // GenerateProbeSequence generates a sequence of semesters for probing.
 // Starts from the given semester and goes backwards.
 // Used by warmup to determine which semesters to check for data.
 func GenerateProbeSequence(startYear, startTerm, maxCount int) []Semester {
-	semesters := make([]Semester, maxCount)
-
-	year := startYear
-	term := startTerm
-
-	for i := range maxCount {
-		semesters[i] = Semester{Year: year, Term: term}
-
-		// Go to previous semester
-		if term == 1 {
-			term = 2
-			year--
-		} else {
-			term = 1
-		}
-	}
-
-	return semesters
+	return Semester{Year: startYear, Term: startTerm}.RangeBack(maxCount)
 }
@@ -3,17 +3,42 @@ package course
 import (
 	"context"
 	"errors"
+	"slices"
 	"sync"
 	"time"
 
+	"github.com/garyellow/ntpu-linebot-go/internal/membudget"
 	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+	"github.com/garyellow/ntpu-linebot-go/internal/stringutil"
 )
 
 const defaultSemesterCourseCacheTTL = 30 * time.Second
 
 type semesterCourseCacheEntry struct {
-	courses   []storage.Course
-	fetchedAt time.Time
+	courses    []storage.Course
+	signatures []courseSignature // Parallel to courses
+	fetchedAt  time.Time
+}
+
+// courseSignature holds the rune signatures of a course's title and of all
+// its teachers combined, computed once when the semester list is loaded.
+type courseSignature struct {
+	title    stringutil.RuneSignature
+	teachers stringutil.RuneSignature
+}
+
+func newCourseSignature(c *storage.Course) courseSignature {
+	sig := courseSignature{title: stringutil.NewRuneSignature(c.Title)}
+	for _, teacher := range c.Teachers {
+		sig.teachers |= stringutil.NewRuneSignature(teacher)
+	}
+	return sig
+}
+
+// mayMatch reports whether the title or a teacher may contain every rune of
+// q; false rules the course out without reading its strings.
+func (s courseSignature) mayMatch(q stringutil.RuneQuery) bool {
+	return s.title.Covers(q.Signature()) || s.teachers.Covers(q.Signature())
 }
 
 // SemesterCourseCache holds recent semester course lists in memory for a short time.
@@ -22,6 +47,7 @@ type SemesterCourseCache struct {
 	mu      sync.RWMutex
 	ttl     time.Duration
 	entries map[Semester]semesterCourseCacheEntry
+	budget  *membudget.Budget // Optional: charged per semester list
 }
 
 // NewSemesterCourseCache creates a short-lived in-memory cache for semester course lists.
@@ -36,11 +62,60 @@ func NewSemesterCourseCache(ttl time.Duration) *SemesterCourseCache {
 	}
 }
 
+// SetBudget charges each cached semester list to b, which drops the least
+// recently used lists when over its limit.
+func (c *SemesterCourseCache) SetBudget(b *membudget.Budget) {
+	c.budget = b
+	b.Register(membudget.ConsumerCourseLists, c.evict)
+}
+
+// evict drops a semester list evicted by the memory budget; the next Get
+// reloads it from SQLite.
+func (c *SemesterCourseCache) evict(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for sem := range c.entries {
+		if sem.String() == key {
+			delete(c.entries, sem)
+			return
+		}
+	}
+}
+
 // Get returns courses for a semester from memory when fresh, otherwise reloads from SQLite.
-// Expired entries are lazily deleted on read to keep the map bounded.
 func (c *SemesterCourseCache) Get(ctx context.Context, db *storage.DB, year, term int) ([]storage.Course, error) {
+	entry, err := c.load(ctx, db, year, term)
+	if err != nil {
+		return nil, err
+	}
+	return cloneCourses(entry.courses), nil
+}
+
+// Search returns the courses of a semester whose title or a teacher contains
+// every rune of q (see matchesKeyword), plus those extra accepts; extra may be
+// nil. The signatures computed at load time skip most courses without
+// matching their strings.
+func (c *SemesterCourseCache) Search(ctx context.Context, db *storage.DB, year, term int, q stringutil.RuneQuery, extra func(*storage.Course) bool) ([]storage.Course, error) {
+	entry, err := c.load(ctx, db, year, term)
+	if err != nil {
+		return nil, err
+	}
+	var matched []storage.Course
+	for i := range entry.courses {
+		course := &entry.courses[i]
+		if (entry.signatures[i].mayMatch(q) && matchesKeyword(course, q)) || (extra != nil && extra(course)) {
+			matched = append(matched, *course)
+		}
+	}
+	return cloneCourses(matched), nil
+}
+
+// load returns the cached entry of a semester when fresh, otherwise reloads it from SQLite.
+// Expired entries are lazily deleted on read to keep the map bounded.
+// The returned entry is shared: callers must copy courses before handing them out.
+func (c *SemesterCourseCache) load(ctx context.Context, db *storage.DB, year, term int) (semesterCourseCacheEntry, error) {
 	if db == nil {
-		return nil, errors.New("semester course cache: db is nil")
+		return semesterCourseCacheEntry{}, errors.New("semester course cache: db is nil")
 	}
 
 	key := Semester{Year: year, Term: term}
@@ -50,31 +125,47 @@ func (c *SemesterCourseCache) Get(ctx context.Context, db *storage.DB, year, ter
 	c.mu.RUnlock()
 	if ok {
 		if time.Since(entry.fetchedAt) < c.ttl {
-			return cloneCourses(entry.courses), nil
+			c.budget.Touch(membudget.ConsumerCourseLists, key.String())
+			return entry, nil
 		}
 		// Lazy eviction: re-check under write lock to avoid deleting a freshly refreshed entry.
 		c.mu.Lock()
-		if e, still := c.entries[key]; still && time.Since(e.fetchedAt) >= c.ttl {
+		e, still := c.entries[key]
+		expired := still && time.Since(e.fetchedAt) >= c.ttl
+		if expired {
 			delete(c.entries, key)
 		}
 		c.mu.Unlock()
+		if expired {
+			c.budget.Release(membudget.ConsumerCourseLists, key.String())
+		}
 	}
 
 	courses, err := db.GetCoursesByYearTerm(ctx, year, term)
 	if err != nil {
-		return nil, err
+		return semesterCourseCacheEntry{}, err
 	}
 
-	c.mu.Lock()
-	c.entries[key] = semesterCourseCacheEntry{
-		courses:   courses, // store original; Get always returns a defensive copy
-		fetchedAt: time.Now(),
+	entry = semesterCourseCacheEntry{
+		courses:    courses, // store original; callers copy before returning
+		signatures: make([]courseSignature, len(courses)),
+		fetchedAt:  time.Now(),
 	}
+	for i := range courses {
+		entry.signatures[i] = newCourseSignature(&courses[i])
+	}
+
+	c.mu.Lock()
+	c.entries[key] = entry
 	c.mu.Unlock()
+	// Charged after unlocking: the budget may call back into evict.
+	c.budget.Charge(membudget.ConsumerCourseLists, key.String(), membudget.Estimate(courses)+membudget.Estimate(entry.signatures))
 
-	return cloneCourses(courses), nil
+	return entry, nil
 }
 
+// cloneCourses copies courses along with their slices, so callers may edit
+// the result (e.g., trim teachers for display) without touching the cache.
 func cloneCourses(courses []storage.Course) []storage.Course {
 	if len(courses) == 0 {
 		return nil
@@ -82,5 +173,13 @@ func cloneCourses(courses []storage.Course) []storage.Course {
 
 	cloned := make([]storage.Course, len(courses))
 	copy(cloned, courses)
+	for i := range cloned {
+		c := &cloned[i]
+		c.Teachers = slices.Clone(c.Teachers)
+		c.TeacherURLs = slices.Clone(c.TeacherURLs)
+		c.Times = slices.Clone(c.Times)
+		c.Locations = slices.Clone(c.Locations)
+		c.RawProgramReqs = slices.Clone(c.RawProgramReqs)
+	}
 	return cloned
 }
@@ -2,10 +2,13 @@ package course
 
 import (
 	"context"
+	"slices"
 	"testing"
 	"time"
 
+	"github.com/garyellow/ntpu-linebot-go/internal/membudget"
 	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+	"github.com/garyellow/ntpu-linebot-go/internal/stringutil"
 )
 
 func TestSemesterCourseCacheLoadsFromDB(t *testing.T) {
@@ -102,3 +105,121 @@ func TestSemesterCourseCacheNilDB(t *testing.T) {
 		t.Fatal("expected error when db is nil")
 	}
 }
+
+func TestSemesterCourseCacheEvictsOverBudget(t *testing.T) {
+	t.Parallel()
+
+	db := setupSemesterTestDB(t)
+	ctx := context.Background()
+	cache := NewSemesterCourseCache(time.Minute)
+	budget := membudget.New(1) // Holds only the most recently loaded list
+	cache.SetBudget(budget)
+
+	for _, c := range []*storage.Course{
+		{UID: "1141U0001", Year: 114, Term: 1, No: "U0001", Title: "資料結構"},
+		{UID: "1142U0001", Year: 114, Term: 2, No: "U0001", Title: "演算法"},
+	} {
+		if err := db.SaveCourse(ctx, c); err != nil {
+			t.Fatalf("SaveCourse failed: %v", err)
+		}
+	}
+
+	if _, err := cache.Get(ctx, db, 114, 1); err != nil {
+		t.Fatalf("Get(114, 1) failed: %v", err)
+	}
+	if _, err := cache.Get(ctx, db, 114, 2); err != nil {
+		t.Fatalf("Get(114, 2) failed: %v", err)
+	}
+
+	cache.mu.RLock()
+	_, hasOld := cache.entries[Semester{Year: 114, Term: 1}]
+	_, hasNew := cache.entries[Semester{Year: 114, Term: 2}]
+	cache.mu.RUnlock()
+	if hasOld || !hasNew {
+		t.Errorf("cached 114-1 = %v, 114-2 = %v; want only 114-2", hasOld, hasNew)
+	}
+	if budget.Used(membudget.ConsumerCourseLists) <= 0 {
+		t.Error("cached list is not charged to the budget")
+	}
+}
+
+func TestSemesterCourseCacheSearch(t *testing.T) {
+	t.Parallel()
+
+	db := setupSemesterTestDB(t)
+	ctx := context.Background()
+	cache := NewSemesterCourseCache(time.Minute)
+
+	for _, c := range []*storage.Course{
+		{UID: "1142U0001", Year: 114, Term: 2, No: "U0001", Title: "線性代數", Teachers: []string{"王小明"}},
+		{UID: "1142U0002", Year: 114, Term: 2, No: "U0002", Title: "資料結構", Teachers: []string{"陳大文"}},
+		{UID: "1142U0003", Year: 114, Term: 2, No: "U0003", Title: "微積分", Teachers: []string{"林明華"}, TitleEn: "Calculus"},
+	} {
+		if err := db.SaveCourse(ctx, c); err != nil {
+			t.Fatalf("SaveCourse failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		keyword string
+		extra   func(*storage.Course) bool
+		want    []string
+	}{
+		{"title runes", "線代", nil, []string{"1142U0001"}},
+		{"teacher runes", "明", nil, []string{"1142U0001", "1142U0003"}},
+		{"no match", "經濟", nil, nil},
+		{"extra match", "calc", func(c *storage.Course) bool { return c.TitleEn == "Calculus" }, []string{"1142U0003"}},
+	}
+
+	for _, tt := range tests {
+		courses, err := cache.Search(ctx, db, 114, 2, stringutil.NewRuneQuery(tt.keyword), tt.extra)
+		if err != nil {
+			t.Fatalf("%s: Search failed: %v", tt.name, err)
+		}
+		var got []string
+		for _, c := range courses {
+			got = append(got, c.UID)
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: Search(%q) = %v, want %v", tt.name, tt.keyword, got, tt.want)
+		}
+	}
+}
+
+func TestSemesterCourseCacheResultsAreCopies(t *testing.T) {
+	t.Parallel()
+
+	db := setupSemesterTestDB(t)
+	ctx := context.Background()
+	cache := NewSemesterCourseCache(time.Minute)
+
+	if err := db.SaveCourse(ctx, &storage.Course{UID: "1142U0001", Year: 114, Term: 2, No: "U0001", Title: "線性代數",
+		Teachers: []string{"王小明"}, Times: []string{"每週二3~4"}}); err != nil {
+		t.Fatalf("SaveCourse failed: %v", err)
+	}
+
+	q := stringutil.NewRuneQuery("線代")
+	found, err := cache.Search(ctx, db, 114, 2, q, nil)
+	if err != nil || len(found) != 1 {
+		t.Fatalf("Search = %v, %v; want 1 course", found, err)
+	}
+	found[0].Title = "改過"
+	found[0].Teachers[0] = "改過"
+	found[0].Times[0] = "改過"
+
+	listed, err := cache.Get(ctx, db, 114, 2)
+	if err != nil || len(listed) != 1 {
+		t.Fatalf("Get = %v, %v; want 1 course", listed, err)
+	}
+	listed[0].Teachers[0] = "也改過"
+
+	again, err := cache.Search(ctx, db, 114, 2, q, nil)
+	if err != nil || len(again) != 1 {
+		t.Fatalf("second Search = %v, %v; want 1 course", again, err)
+	}
+	if c := again[0]; c.Title != "線性代數" || c.Teachers[0] != "王小明" || c.Times[0] != "每週二3~4" {
+		t.Errorf("cached course = %+v, want it unchanged by edits to earlier results", c)
+	}
+}
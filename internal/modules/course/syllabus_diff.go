@@ -0,0 +1,210 @@
+package course
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	domerrors "github.com/garyellow/ntpu-linebot-go/internal/errors"
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/scraper/ntpu"
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+const (
+	maxDiffLines     = 3  // Added/removed lines shown per syllabus section
+	maxDiffLineRunes = 60 // Length of one shown line
+)
+
+// sectionDiff is the line-level change of one syllabus section.
+type sectionDiff struct {
+	label   string // e.g. "教學目標"
+	added   []string
+	removed []string
+}
+
+// syllabusDiff summarizes how a course's syllabus changed between two semesters.
+type syllabusDiff struct {
+	prev, cur       *storage.Syllabus
+	teachersAdded   []string
+	teachersRemoved []string
+	sections        []sectionDiff // Only the sections that changed
+}
+
+// changed reports whether anything the diff view shows differs.
+func (d syllabusDiff) changed() bool {
+	return len(d.teachersAdded)+len(d.teachersRemoved)+len(d.sections) > 0
+}
+
+// diffSyllabi compares the teachers, objectives and outline of prev and cur.
+func diffSyllabi(prev, cur *storage.Syllabus) syllabusDiff {
+	d := syllabusDiff{prev: prev, cur: cur}
+	d.teachersAdded, d.teachersRemoved = diffSets(prev.Teachers, cur.Teachers)
+	for _, s := range []struct{ label, prev, cur string }{
+		{"教學目標", prev.Objectives, cur.Objectives},
+		{"內容綱要", prev.Outline, cur.Outline},
+	} {
+		added, removed := diffSets(syllabusLines(s.prev), syllabusLines(s.cur))
+		if len(added)+len(removed) > 0 {
+			d.sections = append(d.sections, sectionDiff{label: s.label, added: added, removed: removed})
+		}
+	}
+	return d
+}
+
+// syllabusLines splits syllabus text into trimmed, non-empty lines.
+func syllabusLines(text string) []string {
+	var lines []string
+	for line := range strings.SplitSeq(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// diffSets returns the items only in cur (added) and only in prev (removed),
+// each in its original order. Reordering alone is not a change.
+func diffSets(prev, cur []string) (added, removed []string) {
+	for _, s := range cur {
+		if !slices.Contains(prev, s) && !slices.Contains(added, s) {
+			added = append(added, s)
+		}
+	}
+	for _, s := range prev {
+		if !slices.Contains(cur, s) && !slices.Contains(removed, s) {
+			removed = append(removed, s)
+		}
+	}
+	return added, removed
+}
+
+// handleSyllabusDiff compares the syllabus of uid with the same course
+// number's syllabus from the closest earlier semester.
+func (h *Handler) handleSyllabusDiff(ctx context.Context, uid string) []messaging_api.MessageInterface {
+	log := logger.FromContext(ctx).WithField("uid", uid)
+	sender := lineutil.GetSender(senderName, h.stickerManager)
+
+	notFound := func(text string) []messaging_api.MessageInterface {
+		msg := lineutil.NewTextMessageWithConsistentSender(text, sender)
+		msg.QuickReply = lineutil.NewQuickReply(lineutil.QuickReplyCourseNav(h.IsBM25SearchEnabled()))
+		return []messaging_api.MessageInterface{msg}
+	}
+
+	year, term, no, err := ntpu.ParseUID(uid)
+	if err != nil {
+		return []messaging_api.MessageInterface{}
+	}
+	cur, err := h.db.GetSyllabusByUID(ctx, uid)
+	if errors.Is(err, domerrors.ErrNotFound) {
+		return notFound("🔍 查無本學期課程大綱\n\n💡 大綱資料每日更新，請稍後再試")
+	}
+	if err != nil {
+		log.WithError(err).ErrorContext(ctx, "Failed to get syllabus")
+		return []messaging_api.MessageInterface{
+			lineutil.ErrorMessageWithQuickReply("查詢課程大綱時發生問題", sender, "課程 "+uid),
+		}
+	}
+	prev, err := h.db.GetPreviousSyllabus(ctx, no, year, term)
+	if errors.Is(err, domerrors.ErrNotFound) {
+		return notFound("🔍 查無先前學期的課程大綱\n\n💡 僅能比較相同課號且已收錄大綱的學期")
+	}
+	if err != nil {
+		log.WithError(err).ErrorContext(ctx, "Failed to get previous syllabus")
+		return []messaging_api.MessageInterface{
+			lineutil.ErrorMessageWithQuickReply("查詢課程大綱時發生問題", sender, "課程 "+uid),
+		}
+	}
+
+	msg := buildSyllabusDiffMessage(diffSyllabi(prev, cur))
+	msg.Sender = sender
+	msg.QuickReply = lineutil.NewQuickReply(lineutil.QuickReplyCourseNav(h.IsBM25SearchEnabled()))
+	return []messaging_api.MessageInterface{msg}
+}
+
+// buildSyllabusDiffMessage renders the changes bubble: semesters compared,
+// teacher changes, and a few added/removed lines per changed section.
+func buildSyllabusDiffMessage(d syllabusDiff) *messaging_api.FlexMessage {
+	header := lineutil.NewColoredHeader(lineutil.ColoredHeaderInfo{
+		Title: lineutil.FormatCourseTitleWithUID(d.cur.Title, d.cur.UID),
+		Color: lineutil.ColorHeaderCourse,
+	})
+
+	body := lineutil.NewBodyContentBuilder()
+	body.AddComponent(lineutil.NewBodyLabel(lineutil.BodyLabelInfo{
+		Emoji: "🔀",
+		Label: "大綱差異",
+		Color: lineutil.ColorHeaderCourse,
+	}).FlexBox)
+	semesters := lineutil.FormatSemesterShort(d.prev.Year, d.prev.Term) + " → " + lineutil.FormatSemesterShort(d.cur.Year, d.cur.Term)
+	body.AddComponent(lineutil.NewInfoRow(lineutil.Icon(lineutil.IconSemester), "比較學期", semesters, lineutil.DefaultInfoRowStyle()).FlexBox)
+
+	wrapStyle := lineutil.DefaultInfoRowStyle()
+	wrapStyle.Wrap = true
+	if len(d.teachersAdded)+len(d.teachersRemoved) > 0 {
+		var parts []string
+		if len(d.teachersAdded) > 0 {
+			parts = append(parts, "新任 "+strings.Join(d.teachersAdded, "、"))
+		}
+		if len(d.teachersRemoved) > 0 {
+			parts = append(parts, "原任 "+strings.Join(d.teachersRemoved, "、"))
+		}
+		body.AddInfoRow(lineutil.Icon(lineutil.IconTeacher), "教師異動", strings.Join(parts, "；"), wrapStyle)
+	} else {
+		body.AddInfoRow(lineutil.Icon(lineutil.IconTeacher), "教師異動", "無", lineutil.DefaultInfoRowStyle())
+	}
+
+	for _, s := range d.sections {
+		body.AddInfoRow(lineutil.Icon(lineutil.IconNote), s.label, fmt.Sprintf("新增 %d 項・移除 %d 項", len(s.added), len(s.removed)), lineutil.DefaultInfoRowStyle())
+		body.AddComponent(diffLinesText(s).FlexText)
+	}
+	if len(d.sections) == 0 {
+		body.AddComponent(lineutil.NewFlexText("📄 教學目標與內容綱要皆無變動").
+			WithSize("sm").
+			WithColor(lineutil.ColorSubtext).
+			WithWrap(true).
+			WithMargin("md").FlexText)
+	}
+
+	footer := lineutil.NewButtonFooter(lineutil.LayoutButtonsWithPattern([]*lineutil.FlexButton{
+		lineutil.NewFlexButton(lineutil.NewPostbackActionWithDisplayText(
+			"📚 本學期課程",
+			"查看 "+lineutil.TruncateRunes(d.cur.Title, 32)+" 課程",
+			UIDPostback(d.cur.UID),
+		)).WithStyle("primary").WithColor(lineutil.ColorButtonInternal).WithHeight("sm"),
+		lineutil.NewFlexButton(lineutil.NewPostbackActionWithDisplayText(
+			"🕘 "+lineutil.FormatSemesterShort(d.prev.Year, d.prev.Term)+" 課程",
+			"查看 "+lineutil.TruncateRunes(d.prev.Title, 32)+" 課程",
+			UIDPostback(d.prev.UID),
+		)).WithStyle("secondary").WithHeight("sm"),
+	})...)
+
+	bubble := lineutil.NewFlexBubble(header, nil, body.Build(), footer)
+	altText := lineutil.FormatLabel("大綱差異", d.cur.Title, 400)
+	return lineutil.NewFlexMessage(altText, bubble.FlexBubble)
+}
+
+// diffLinesText lists up to maxDiffLines added ("＋") and removed ("－")
+// lines of a section.
+func diffLinesText(s sectionDiff) *lineutil.FlexText {
+	var lines []string
+	appendLines := func(mark string, items []string) {
+		for i, item := range items {
+			if i == maxDiffLines {
+				lines = append(lines, fmt.Sprintf("%s …另 %d 項", mark, len(items)-maxDiffLines))
+				break
+			}
+			lines = append(lines, mark+" "+lineutil.TruncateRunes(item, maxDiffLineRunes))
+		}
+	}
+	appendLines("＋", s.added)
+	appendLines("－", s.removed)
+	return lineutil.NewFlexText(strings.Join(lines, "\n")).
+		WithSize("xs").
+		WithColor(lineutil.ColorSubtext).
+		WithWrap(true)
+}
@@ -0,0 +1,64 @@
+package course
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+)
+
+func TestDiffSyllabi(t *testing.T) {
+	t.Parallel()
+
+	prev := &storage.Syllabus{
+		UID: "1131U0001", Year: 113, Term: 1, Title: "程式設計",
+		Teachers:   []string{"王小明", "李小華"},
+		Objectives: "培養程式設計能力\nDevelop programming skills",
+		Outline:    "變數\n迴圈\n函式",
+	}
+	cur := &storage.Syllabus{
+		UID: "1132U0001", Year: 113, Term: 2, Title: "程式設計",
+		Teachers:   []string{"李小華", "張大同"},
+		Objectives: "Develop programming skills\n 培養程式設計能力 ",
+		Outline:    "變數\n函式\n物件導向",
+	}
+
+	d := diffSyllabi(prev, cur)
+	if !slices.Equal(d.teachersAdded, []string{"張大同"}) || !slices.Equal(d.teachersRemoved, []string{"王小明"}) {
+		t.Errorf("teachers added/removed = %v/%v, want [張大同]/[王小明]", d.teachersAdded, d.teachersRemoved)
+	}
+	if len(d.sections) != 1 {
+		t.Fatalf("sections = %+v, want only 內容綱要 (objectives only reordered)", d.sections)
+	}
+	if s := d.sections[0]; s.label != "內容綱要" || !slices.Equal(s.added, []string{"物件導向"}) || !slices.Equal(s.removed, []string{"迴圈"}) {
+		t.Errorf("outline diff = %+v, want +物件導向 -迴圈", s)
+	}
+	if !d.changed() {
+		t.Error("changed() = false, want true")
+	}
+
+	if same := diffSyllabi(prev, prev); same.changed() {
+		t.Errorf("diffSyllabi(prev, prev) = %+v, want no changes", same)
+	}
+}
+
+func TestDiffLinesText(t *testing.T) {
+	t.Parallel()
+
+	s := sectionDiff{
+		label:   "教學目標",
+		added:   []string{"一", "二", "三", "四", "五"},
+		removed: []string{strings.Repeat("長", maxDiffLineRunes+10)},
+	}
+	text := diffLinesText(s).Text
+	if !strings.Contains(text, "＋ …另 2 項") {
+		t.Errorf("text = %q, want the overflow count of added lines", text)
+	}
+	if strings.Contains(text, "四") {
+		t.Errorf("text = %q, want at most %d added lines", text, maxDiffLines)
+	}
+	if strings.Contains(text, strings.Repeat("長", maxDiffLineRunes+1)) {
+		t.Errorf("text = %q, want long lines truncated", text)
+	}
+}
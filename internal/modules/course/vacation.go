@@ -0,0 +1,24 @@
+package course
+
+import (
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/data"
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+)
+
+// vacationNotice returns the note shown on course results during 寒假/暑假,
+// when the newest course data is still the semester that just ended, e.g.
+// "寒假期間課程資料為上學期". It returns "" outside the academic calendar's
+// vacations (data.Vacations).
+func vacationNotice(now time.Time) string {
+	v, ok := data.VacationOn(now.In(lineutil.GetTaipeiLocation()).Format(time.DateOnly))
+	if !ok {
+		return ""
+	}
+	term := "上學期"
+	if v.Term == 2 {
+		term = "下學期"
+	}
+	return v.Name + "期間課程資料為" + term
+}
@@ -0,0 +1,29 @@
+package course
+
+import (
+	"testing"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/data"
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+)
+
+func TestVacationNotice(t *testing.T) {
+	t.Parallel()
+	loc := lineutil.GetTaipeiLocation()
+
+	for _, v := range data.Vacations {
+		// 01:00 in Taipei is still the previous day in UTC
+		start, _ := time.ParseInLocation(time.DateOnly, v.Start, loc)
+		want := v.Name + "期間課程資料為上學期"
+		if v.Term == 2 {
+			want = v.Name + "期間課程資料為下學期"
+		}
+		if got := vacationNotice(start.Add(time.Hour).UTC()); got != want {
+			t.Errorf("vacationNotice(%s) = %q, want %q", v.Start, got, want)
+		}
+		if got := vacationNotice(start.Add(-time.Hour)); got != "" {
+			t.Errorf("vacationNotice(day before %s) = %q, want empty", v.Start, got)
+		}
+	}
+}
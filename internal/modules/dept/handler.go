@@ -0,0 +1,336 @@
+// Package dept implements the department overview module for the LINE bot.
+// 「資工系總覽」 replies with one carousel that aggregates what the other
+// modules cache about a department: the office contact (contacts), the
+// newest semester's course count (courses), and the latest cohort size
+// (students). Each card links to the module that shows the full list.
+package dept
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/bot"
+	"github.com/garyellow/ntpu-linebot-go/internal/data"
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/course"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/id"
+	"github.com/garyellow/ntpu-linebot-go/internal/scraper/ntpu"
+	"github.com/garyellow/ntpu-linebot-go/internal/sticker"
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+// Module constants
+const (
+	ModuleName = "dept"
+	senderName = "系所小幫手"
+)
+
+// overviewRegex matches "{department}總覽", e.g. "資工系總覽", "資訊工程學系總覽" or "85總覽".
+var overviewRegex = regexp.MustCompile(`^(\S+)總覽$`)
+
+// Handler handles department overview requests.
+type Handler struct {
+	bot.NoPostbacks // Every card links to the module that owns the data
+
+	db             *storage.DB
+	semesterCache  *course.SemesterCache
+	stickerManager *sticker.Manager
+}
+
+// NewHandler creates a new department overview handler. semesterCache is
+// the course module's cache of the newest semesters.
+func NewHandler(db *storage.DB, semesterCache *course.SemesterCache, stickerManager *sticker.Manager) *Handler {
+	return &Handler{
+		db:             db,
+		semesterCache:  semesterCache,
+		stickerManager: stickerManager,
+	}
+}
+
+// Name returns the module name
+func (h *Handler) Name() string {
+	return ModuleName
+}
+
+// CanHandle returns true for "{department}總覽" naming a known department.
+func (h *Handler) CanHandle(text string) bool {
+	_, ok := parseDepartment(strings.TrimSpace(text))
+	return ok
+}
+
+// HandleMessage replies with the department overview carousel.
+//
+//	資工系總覽
+func (h *Handler) HandleMessage(ctx context.Context, text string) []messaging_api.MessageInterface {
+	code, ok := parseDepartment(strings.TrimSpace(text))
+	if !ok {
+		return []messaging_api.MessageInterface{}
+	}
+	return h.handleOverview(ctx, code)
+}
+
+// overview is what the carousel shows about one department. A card whose
+// lookup failed shows a notice instead; the other cards are unaffected.
+type overview struct {
+	code     string // Undergraduate department code, e.g. "85"
+	short    string // Short name, e.g. "資工"
+	fullName string // Full name, e.g. "資訊工程學系"
+
+	office    *storage.Contact // nil when not cached
+	officeErr bool
+
+	year, term int // Newest cached semester (0 when none)
+	courses    int
+	required   int
+	coursesErr bool
+	cohortYear int
+	cohortSize int
+	cohortErr  bool
+}
+
+// name returns the display name, e.g. "資工系".
+func (o overview) name() string {
+	return o.short + "系"
+}
+
+// handleOverview gathers the three cards and renders the carousel.
+func (h *Handler) handleOverview(ctx context.Context, code string) []messaging_api.MessageInterface {
+	log := logger.FromContext(ctx).WithField("dept_code", code)
+	o := overview{
+		code:     code,
+		short:    ntpu.DepartmentNames[code],
+		fullName: ntpu.FullDepartmentNames[code],
+	}
+
+	office, err := h.officeContact(ctx, o.fullName)
+	if err != nil {
+		log.WithError(err).WarnContext(ctx, "Failed to load department office contact")
+		o.officeErr = true
+	}
+	o.office = office
+
+	if years, terms := h.semesterCache.GetRecentSemesters(); len(years) > 0 {
+		o.year, o.term = years[0], terms[0]
+		o.courses, o.required, err = h.countCourses(ctx, o.short, o.year, o.term)
+		if err != nil {
+			log.WithError(err).WarnContext(ctx, "Failed to count department courses")
+			o.coursesErr = true
+		}
+	}
+
+	o.cohortYear = data.Availability.Students.LatestCompleteYear(time.Now().Year() - 1911)
+	students, err := h.db.GetStudentsByDepartment(ctx, o.name(), o.cohortYear)
+	if err != nil {
+		log.WithError(err).WarnContext(ctx, "Failed to count department students")
+		o.cohortErr = true
+	}
+	o.cohortSize = len(students)
+
+	msg := buildOverviewMessage(o)
+	msg.Sender = lineutil.GetSender(senderName, h.stickerManager)
+	msg.QuickReply = lineutil.NewQuickReply(lineutil.QuickReplyMainNavCompact())
+	return []messaging_api.MessageInterface{msg}
+}
+
+// officeContact returns the cached organization entry named fullName, or nil
+// when the contact directory has not cached it.
+func (h *Handler) officeContact(ctx context.Context, fullName string) (*storage.Contact, error) {
+	contacts, err := h.db.SearchContactsByName(ctx, fullName)
+	if err != nil {
+		return nil, err
+	}
+	var match *storage.Contact
+	for i, c := range contacts {
+		if c.Type != "organization" {
+			continue
+		}
+		if c.Name == fullName {
+			return &contacts[i], nil
+		}
+		if match == nil {
+			match = &contacts[i]
+		}
+	}
+	return match, nil
+}
+
+// countCourses returns how many of the semester's courses are offered to
+// the department, and how many of them are required (必修) for it.
+func (h *Handler) countCourses(ctx context.Context, short string, year, term int) (total, required int, err error) {
+	all, err := h.db.GetCoursesByDepartments(ctx, year, term, []string{short}, "")
+	if err != nil {
+		return 0, 0, err
+	}
+	req, err := h.db.GetCoursesByDepartments(ctx, year, term, []string{short}, "必")
+	if err != nil {
+		return 0, 0, err
+	}
+	return len(all), len(req), nil
+}
+
+// parseDepartment returns the department code named by "{department}總覽".
+// Short names (資工), full names (資訊工程學系) and codes (85) are accepted;
+// law groups map to the law department.
+func parseDepartment(text string) (string, bool) {
+	m := overviewRegex.FindStringSubmatch(text)
+	if m == nil {
+		return "", false
+	}
+	code, ok := ntpu.LookupDepartment(m[1])
+	if !ok {
+		return "", false
+	}
+	return ntpu.BaseDepartmentCode(code), true
+}
+
+// buildOverviewMessage renders the carousel: office contact, courses, and
+// students, in that order.
+func buildOverviewMessage(o overview) *messaging_api.FlexMessage {
+	bubbles := []messaging_api.FlexBubble{
+		*buildContactBubble(o).FlexBubble,
+		*buildCoursesBubble(o).FlexBubble,
+		*buildStudentsBubble(o).FlexBubble,
+	}
+	return lineutil.NewFlexMessage(overviewAltText(o), lineutil.NewFlexCarousel(bubbles))
+}
+
+// overviewAltText summarizes the counts for notifications and chat lists.
+func overviewAltText(o overview) string {
+	parts := []string{o.name() + "總覽"}
+	if o.year > 0 && !o.coursesErr {
+		parts = append(parts, fmt.Sprintf("本學期 %d 門課", o.courses))
+	}
+	if !o.cohortErr && o.cohortSize > 0 {
+		parts = append(parts, fmt.Sprintf("%d 學年度入學 %d 位", o.cohortYear, o.cohortSize))
+	}
+	return lineutil.TruncateRunes(strings.Join(parts, "｜"), 400)
+}
+
+// buildContactBubble renders the department office card.
+func buildContactBubble(o overview) *lineutil.FlexBubble {
+	header := lineutil.NewColoredHeader(lineutil.ColoredHeaderInfo{
+		Title:    "🏢 " + o.fullName,
+		Subtitle: "系辦聯絡資訊",
+		Color:    lineutil.ColorHeaderContact,
+	})
+
+	body := lineutil.NewBodyContentBuilder()
+	searchButton := lineutil.NewFlexButton(lineutil.NewMessageAction("🔍 查詢聯絡資訊", "聯絡 "+o.name())).
+		WithStyle("secondary").WithHeight("sm")
+	var buttons []*lineutil.FlexButton
+
+	switch c := o.office; {
+	case o.officeErr:
+		body.AddComponent(noticeText("⚠️ 暫時無法取得聯絡資訊"))
+	case c == nil:
+		body.AddComponent(noticeText("尚未快取系辦聯絡資訊，可點下方按鈕查詢"))
+	default:
+		body.AddInfoRowIf(lineutil.Icon(lineutil.IconPhone), "聯絡電話", c.Phone, lineutil.BoldInfoRowStyle())
+		body.AddInfoRowIf(lineutil.Icon(lineutil.IconExtension), "分機號碼", c.Extension, lineutil.BoldInfoRowStyle())
+		body.AddInfoRowIf(lineutil.Icon(lineutil.IconLocation), "辦公位置", c.Location, lineutil.CarouselInfoRowStyle())
+		body.AddInfoRowIf(lineutil.Icon(lineutil.IconEmail), "電子郵件", c.Email, lineutil.CarouselInfoRowStyle())
+		if len(body.Contents()) == 0 {
+			body.AddComponent(noticeText("系辦沒有公開的聯絡方式"))
+		}
+		if tel := telURI(c); tel != "" {
+			buttons = append(buttons, lineutil.NewFlexButton(lineutil.NewURIAction("📞 撥打電話", tel)).
+				WithStyle("primary").WithColor(lineutil.ColorButtonAction).WithHeight("sm"))
+		}
+		if c.Website != "" {
+			buttons = append(buttons, lineutil.NewFlexButton(lineutil.NewURIAction("🌐 開啟網站", c.Website)).
+				WithStyle("primary").WithColor(lineutil.ColorButtonExternal).WithHeight("sm"))
+		}
+	}
+	buttons = append(buttons, searchButton)
+
+	return lineutil.NewFlexBubble(header, nil, body.Build(), lineutil.NewButtonFooter(lineutil.LayoutButtonsWithPattern(buttons)...))
+}
+
+// buildCoursesBubble renders the newest semester's course count card.
+func buildCoursesBubble(o overview) *lineutil.FlexBubble {
+	header := lineutil.NewColoredHeader(lineutil.ColoredHeaderInfo{
+		Title:    lineutil.Icon(lineutil.IconCourse) + " " + o.name() + "課程",
+		Subtitle: "應修系級含本系的課程",
+		Color:    lineutil.ColorHeaderCourse,
+	})
+
+	body := lineutil.NewBodyContentBuilder()
+	var footer *lineutil.FlexBox
+	switch {
+	case o.year == 0:
+		body.AddComponent(noticeText("目前沒有可查詢的課程資料"))
+	case o.coursesErr:
+		body.AddComponent(noticeText("⚠️ 暫時無法取得課程資料"))
+	default:
+		body.AddInfoRow(lineutil.Icon(lineutil.IconSemester), "開課學期", lineutil.FormatSemester(o.year, o.term), lineutil.DefaultInfoRowStyle())
+		body.AddComponent(lineutil.NewInfoRowWithMargin(lineutil.Icon(lineutil.IconCourse), "開課數",
+			fmt.Sprintf("%d 門", o.courses), lineutil.BoldInfoRowStyle(), "md"))
+		body.AddComponent(lineutil.NewInfoRowWithMargin(lineutil.Icon(lineutil.IconRequired), "其中必修",
+			fmt.Sprintf("%d 門", o.required), lineutil.DefaultInfoRowStyle(), "md"))
+		if o.courses > 0 {
+			footer = lineutil.NewButtonFooter([]*lineutil.FlexButton{
+				lineutil.NewFlexButton(lineutil.NewPostbackActionWithDisplayText(
+					"📚 瀏覽課程", "瀏覽"+o.name()+"課程", course.DepartmentPostback(o.short))).
+					WithStyle("primary").WithColor(lineutil.ColorButtonInternal).WithHeight("sm"),
+			})
+		}
+	}
+
+	return lineutil.NewFlexBubble(header, nil, body.Build(), footer)
+}
+
+// buildStudentsBubble renders the latest cohort card.
+func buildStudentsBubble(o overview) *lineutil.FlexBubble {
+	header := lineutil.NewColoredHeader(lineutil.ColoredHeaderInfo{
+		Title:    "🎓 " + o.name() + "學生",
+		Subtitle: "大學部最新一屆",
+		Color:    lineutil.ColorHeaderStudent,
+	})
+
+	body := lineutil.NewBodyContentBuilder()
+	body.AddInfoRow(lineutil.Icon(lineutil.IconYear), "入學學年", fmt.Sprintf("%d 學年度", o.cohortYear), lineutil.DefaultInfoRowStyle())
+	switch {
+	case o.cohortErr:
+		body.AddComponent(noticeText("⚠️ 暫時無法取得學生資料"))
+	case o.cohortSize == 0:
+		body.AddComponent(noticeText("尚未快取這屆的學生名單，可點下方按鈕查詢"))
+	default:
+		body.AddComponent(lineutil.NewInfoRowWithMargin("👥", "人數",
+			fmt.Sprintf("%d 位", o.cohortSize), lineutil.BoldInfoRowStyle(), "md"))
+	}
+
+	footer := lineutil.NewButtonFooter([]*lineutil.FlexButton{
+		lineutil.NewFlexButton(lineutil.NewPostbackActionWithDisplayText(
+			"👥 查看名單", fmt.Sprintf("查詢 %d 學年度%s", o.cohortYear, o.name()), id.StudentsPostback(o.code, o.cohortYear))).
+			WithStyle("primary").WithColor(lineutil.ColorButtonInternal).WithHeight("sm"),
+	})
+
+	return lineutil.NewFlexBubble(header, nil, body.Build(), footer)
+}
+
+// noticeText is a wrapped gray line for a card without data.
+func noticeText(text string) messaging_api.FlexComponentInterface {
+	return lineutil.NewFlexText(text).WithSize("sm").WithColor(lineutil.ColorSubtext).WithWrap(true).FlexText
+}
+
+// telURI returns the dial URI of a contact: its phone ("main,extension" or
+// standalone), else the switchboard plus its extension. Empty when neither
+// is known.
+func telURI(c *storage.Contact) string {
+	switch {
+	case c.Phone != "":
+		mainPhone, ext, _ := strings.Cut(c.Phone, ",")
+		return lineutil.BuildTelURI(mainPhone, ext)
+	case c.Extension != "":
+		return lineutil.BuildTelURI(data.Campus.Switchboard, c.Extension)
+	}
+	return ""
+}
+
+// Ensure Handler implements bot.Handler
+var _ bot.Handler = (*Handler)(nil)
@@ -0,0 +1,134 @@
+package dept
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/data"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/course"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/moduletest"
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+func newTestHandler(t *testing.T) *Handler {
+	t.Helper()
+	kit := moduletest.New(t, moduletest.Options{})
+	semesters := course.NewSemesterCache()
+	semesters.Update([]course.Semester{{Year: 113, Term: 1}})
+	return NewHandler(kit.DB, semesters, kit.Stickers)
+}
+
+// overviewJSON returns the overview reply as JSON for content checks.
+func overviewJSON(t *testing.T, msgs []messaging_api.MessageInterface) string {
+	t.Helper()
+	if len(msgs) != 1 {
+		t.Fatalf("got %d messages, want 1", len(msgs))
+	}
+	msg, ok := msgs[0].(*messaging_api.FlexMessage)
+	if !ok {
+		t.Fatalf("message is %T, want *FlexMessage", msgs[0])
+	}
+	carousel, ok := msg.Contents.(*messaging_api.FlexCarousel)
+	if !ok || len(carousel.Contents) != 3 {
+		t.Fatalf("contents = %T, want a carousel of 3 bubbles", msg.Contents)
+	}
+	b, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	return string(b)
+}
+
+func TestCanHandle(t *testing.T) {
+	t.Parallel()
+	h := NewHandler(nil, nil, nil)
+
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"資工系總覽", true},
+		{"資工總覽", true},
+		{"資訊工程學系總覽", true},
+		{"85總覽", true},
+		{" 法學系總覽 ", true},
+		{"外星系總覽", false},
+		{"總覽", false},
+		{"資工系 總覽", false},
+	}
+	for _, tt := range tests {
+		if got := h.CanHandle(tt.input); got != tt.want {
+			t.Errorf("CanHandle(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+
+	// Law groups open the law department overview
+	if code, _ := parseDepartment("法學系總覽"); code != "71" {
+		t.Errorf("parseDepartment(法學系總覽) = %q, want 71", code)
+	}
+}
+
+func TestHandleMessage_Overview(t *testing.T) {
+	t.Parallel()
+	h := newTestHandler(t)
+	ctx := context.Background()
+
+	if err := h.db.SaveContact(ctx, &storage.Contact{
+		UID: "org-85", Type: "organization", Name: "資訊工程學系", Phone: "0286741111,66666", Location: "電資大樓",
+	}); err != nil {
+		t.Fatalf("SaveContact failed: %v", err)
+	}
+	courses := []*storage.Course{
+		{UID: "1131U0001", Year: 113, Term: 1, No: "U0001", Title: "程式設計",
+			RawProgramReqs: []storage.RawProgramReq{{Name: "資工系1", CourseType: "必"}}},
+		{UID: "1131U0002", Year: 113, Term: 1, No: "U0002", Title: "機器學習",
+			RawProgramReqs: []storage.RawProgramReq{{Name: "資工系3", CourseType: "選"}}},
+		{UID: "1131U0003", Year: 113, Term: 1, No: "U0003", Title: "會計學",
+			RawProgramReqs: []storage.RawProgramReq{{Name: "會計系1", CourseType: "必"}}},
+	}
+	for _, c := range courses {
+		if err := h.db.SaveCourse(ctx, c); err != nil {
+			t.Fatalf("SaveCourse failed: %v", err)
+		}
+	}
+	if err := h.db.SaveCourseMajorsBatch(ctx, courses); err != nil {
+		t.Fatalf("SaveCourseMajorsBatch failed: %v", err)
+	}
+	cohort := data.Availability.Students.LatestCompleteYear(time.Now().Year() - 1911)
+	for _, id := range []string{"411285001", "411285002"} {
+		if err := h.db.SaveStudent(ctx, &storage.Student{ID: id, Name: "王小明", Year: cohort, Department: "資工系"}); err != nil {
+			t.Fatalf("SaveStudent failed: %v", err)
+		}
+	}
+
+	got := overviewJSON(t, h.HandleMessage(ctx, "資工系總覽"))
+	for _, want := range []string{
+		"資訊工程學系", "電資大樓", "tel:+886286741111,66666", // Office card
+		"2 門", "1 門", "瀏覽資工系課程", // Courses card: total and required
+		"2 位", "查看名單", // Students card
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("overview = %s, want it to contain %q", got, want)
+		}
+	}
+}
+
+func TestHandleMessage_OverviewWithoutData(t *testing.T) {
+	t.Parallel()
+	h := newTestHandler(t)
+
+	// Every card still renders, pointing to the modules that can fetch the data
+	got := overviewJSON(t, h.HandleMessage(context.Background(), "電機系總覽"))
+	for _, want := range []string{"尚未快取系辦聯絡資訊", "聯絡 電機系", "0 門", "尚未快取這屆的學生名單"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("overview = %s, want it to contain %q", got, want)
+		}
+	}
+	if strings.Contains(got, "瀏覽電機系課程") {
+		t.Error("overview offers to browse courses when there are none")
+	}
+}
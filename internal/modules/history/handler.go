@@ -0,0 +1,159 @@
+// Package history implements the query history module for the LINE bot.
+// Keyword queries sent in 1:1 chats are recorded per user (see Store); the
+// 最近查過 command lists them with Quick Reply buttons that re-run each one.
+// Users can clear their history or turn recording off at any time.
+package history
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/bot"
+	"github.com/garyellow/ntpu-linebot-go/internal/ctxutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/sticker"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+// Module constants
+const (
+	ModuleName = "history"
+	senderName = "紀錄小幫手"
+)
+
+// replayItems is the number of recent queries offered as Quick Reply buttons,
+// leaving room for the 清除 and 停止 items within LINE's 13-item limit.
+const replayItems = 11
+
+// Commands (exact match after sanitization)
+const (
+	clearKeyword  = "清除我的紀錄"
+	optOutKeyword = "停止紀錄"
+	optInKeyword  = "開啟紀錄"
+)
+
+var (
+	listKeywords  = []string{"最近查過", "查詢紀錄", "我的查詢紀錄"}
+	clearKeywords = []string{clearKeyword, "清除紀錄"}
+)
+
+// Handler answers the query history commands.
+type Handler struct {
+	bot.NoPostbacks // Commands are plain text so they work from Quick Reply buttons and can be typed
+
+	store          *Store
+	stickerManager *sticker.Manager
+}
+
+// NewHandler creates a new history handler.
+func NewHandler(store *Store, stickerManager *sticker.Manager) *Handler {
+	return &Handler{
+		store:          store,
+		stickerManager: stickerManager,
+	}
+}
+
+// Name returns the module name
+func (h *Handler) Name() string {
+	return ModuleName
+}
+
+// CanHandle returns true for the history commands.
+func (h *Handler) CanHandle(text string) bool {
+	text = strings.TrimSpace(text)
+	return slices.Contains(listKeywords, text) || slices.Contains(clearKeywords, text) ||
+		text == optOutKeyword || text == optInKeyword
+}
+
+// HandleMessage runs a history command for the current user.
+func (h *Handler) HandleMessage(ctx context.Context, text string) []messaging_api.MessageInterface {
+	log := logger.FromContext(ctx)
+	sender := lineutil.GetSender(senderName, h.stickerManager)
+	text = strings.TrimSpace(text)
+
+	// History is personal; never show or change it from a group chat
+	userID := ctxutil.GetUserID(ctx)
+	if userID == "" || ctxutil.GetChatID(ctx) != userID {
+		return lineutil.TextReply(sender, "🔒 查詢紀錄僅限與本帳號的 1 對 1 聊天使用")
+	}
+
+	switch {
+	case slices.Contains(clearKeywords, text):
+		n, err := h.store.Clear(ctx, userID)
+		if err != nil {
+			log.WithError(err).ErrorContext(ctx, "Failed to clear query history")
+			return lineutil.TextReply(sender, "❌ 清除紀錄失敗，請稍後再試")
+		}
+		return lineutil.TextReply(sender, fmt.Sprintf("🗑️ 已清除 %d 筆查詢紀錄", n))
+
+	case text == optOutKeyword:
+		if err := h.store.SetOptOut(ctx, userID, true); err != nil {
+			log.WithError(err).ErrorContext(ctx, "Failed to opt out of query history")
+			return lineutil.TextReply(sender, "❌ 設定失敗，請稍後再試")
+		}
+		return lineutil.TextReply(sender, "⏸️ 已停止記錄查詢，並清除既有紀錄\n\n想恢復時請輸入「"+optInKeyword+"」",
+			lineutil.QuickReplyItem{Action: lineutil.NewMessageAction("▶️ 開啟紀錄", optInKeyword)})
+
+	case text == optInKeyword:
+		if err := h.store.SetOptOut(ctx, userID, false); err != nil {
+			log.WithError(err).ErrorContext(ctx, "Failed to opt in to query history")
+			return lineutil.TextReply(sender, "❌ 設定失敗，請稍後再試")
+		}
+		return lineutil.TextReply(sender, "▶️ 已開啟查詢紀錄，之後的查詢會出現在「最近查過」")
+	}
+
+	return h.handleList(ctx, userID, sender)
+}
+
+// handleList replies with the user's recent queries.
+func (h *Handler) handleList(ctx context.Context, userID string, sender *messaging_api.Sender) []messaging_api.MessageInterface {
+	log := logger.FromContext(ctx)
+
+	optedOut, err := h.store.OptedOut(ctx, userID)
+	if err != nil {
+		log.WithError(err).ErrorContext(ctx, "Failed to check query history opt-out")
+		return lineutil.TextReply(sender, "❌ 讀取紀錄失敗，請稍後再試")
+	}
+	if optedOut {
+		return lineutil.TextReply(sender, "⏸️ 你已停止記錄查詢\n\n想恢復時請輸入「"+optInKeyword+"」",
+			lineutil.QuickReplyItem{Action: lineutil.NewMessageAction("▶️ 開啟紀錄", optInKeyword)})
+	}
+
+	entries, err := h.store.Recent(ctx, userID, MaxEntries)
+	if err != nil {
+		log.WithError(err).ErrorContext(ctx, "Failed to load query history")
+		return lineutil.TextReply(sender, "❌ 讀取紀錄失敗，請稍後再試")
+	}
+	if len(entries) == 0 {
+		return lineutil.TextReply(sender, "🕘 目前沒有查詢紀錄\n\n查詢課程、學號或聯絡資訊後，就能在這裡快速重新查詢")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "🕘 最近查過（%d 筆）\n", len(entries))
+	for i, e := range entries {
+		fmt.Fprintf(&b, "\n%d. %s（%s）", i+1, e.Query, lineutil.FormatCacheTime(e.CreatedAt.Unix()))
+	}
+	b.WriteString("\n\n💡 點下方按鈕即可重新查詢\n🔒 輸入「" + clearKeyword + "」可清除，「" + optOutKeyword + "」可停止記錄")
+
+	items := make([]lineutil.QuickReplyItem, 0, replayItems+2)
+	for _, e := range entries[:min(len(entries), replayItems)] {
+		items = append(items, lineutil.QuickReplyItem{
+			Action: lineutil.NewMessageAction(lineutil.TruncateRunes(e.Query, 20), e.Query),
+		})
+	}
+	items = append(items,
+		lineutil.QuickReplyItem{Action: lineutil.NewMessageAction("🗑️ 清除紀錄", clearKeyword)},
+		lineutil.QuickReplyItem{Action: lineutil.NewMessageAction("⏸️ 停止紀錄", optOutKeyword)},
+	)
+
+	log.WithField("entries", len(entries)).DebugContext(ctx, "Listed query history")
+	msg := lineutil.NewTextMessageWithConsistentSender(b.String(), sender)
+	msg.QuickReply = lineutil.NewQuickReply(items)
+	return []messaging_api.MessageInterface{msg}
+}
+
+// Ensure Handler implements bot.Handler
+var _ bot.Handler = (*Handler)(nil)
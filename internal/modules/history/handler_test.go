@@ -0,0 +1,98 @@
+package history
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/moduletest"
+	"github.com/garyellow/ntpu-linebot-go/internal/sticker"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+func newTestHandler(t *testing.T) *Handler {
+	t.Helper()
+	log := logger.New("error")
+	return NewHandler(moduletest.OpenStore(t, Open), sticker.NewManager(nil, nil, log))
+}
+
+func TestHandler_CanHandle(t *testing.T) {
+	t.Parallel()
+	h := newTestHandler(t)
+
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"最近查過", true},
+		{"我的查詢紀錄", true},
+		{"清除我的紀錄", true},
+		{"停止紀錄", true},
+		{"開啟紀錄", true},
+		{" 最近查過 ", true},
+		{"最近查過 課程", false},
+		{"課程 紀錄", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := h.CanHandle(tt.input); got != tt.want {
+			t.Errorf("CanHandle(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestHandler_ListReplaysQueries(t *testing.T) {
+	t.Parallel()
+	h := newTestHandler(t)
+	ctx := moduletest.ChatContext("U1", "U1")
+
+	for _, q := range []string{"課程 微積分", "學號 412345678"} {
+		if err := h.store.RecordQuery(ctx, "U1", "course", q); err != nil {
+			t.Fatalf("RecordQuery() error = %v", err)
+		}
+	}
+
+	msgs := h.HandleMessage(ctx, "最近查過")
+	if text := moduletest.Text(t, msgs); !strings.Contains(text, "1. 學號 412345678") || !strings.Contains(text, "2. 課程 微積分") {
+		t.Errorf("list text = %q, want newest first", text)
+	}
+	labels := moduletest.QuickReplyLabels(t, msgs)
+	if len(labels) != 4 {
+		t.Fatalf("quick reply = %q, want 2 replays + 清除 + 停止", labels)
+	}
+	action, ok := msgs[0].(*messaging_api.TextMessageV2).QuickReply.Items[0].Action.(*messaging_api.MessageAction)
+	if !ok || action.Text != "學號 412345678" {
+		t.Errorf("first quick reply = %q, want replay of newest query", labels[0])
+	}
+}
+
+func TestHandler_ClearAndOptOut(t *testing.T) {
+	t.Parallel()
+	h := newTestHandler(t)
+	ctx := moduletest.ChatContext("U1", "U1")
+
+	if err := h.store.RecordQuery(ctx, "U1", "course", "課程 微積分"); err != nil {
+		t.Fatalf("RecordQuery() error = %v", err)
+	}
+	if text := moduletest.Text(t, h.HandleMessage(ctx, "清除我的紀錄")); !strings.Contains(text, "已清除 1 筆") {
+		t.Errorf("clear reply = %q", text)
+	}
+
+	moduletest.Text(t, h.HandleMessage(ctx, "停止紀錄"))
+	if text := moduletest.Text(t, h.HandleMessage(ctx, "最近查過")); !strings.Contains(text, "開啟紀錄") {
+		t.Errorf("list after opt-out = %q, want hint to turn recording back on", text)
+	}
+}
+
+func TestHandler_RefusesGroupChat(t *testing.T) {
+	t.Parallel()
+	h := newTestHandler(t)
+
+	if err := h.store.RecordQuery(context.Background(), "U1", "course", "課程 微積分"); err != nil {
+		t.Fatalf("RecordQuery() error = %v", err)
+	}
+	if text := moduletest.Text(t, h.HandleMessage(moduletest.ChatContext("U1", "C123"), "最近查過")); strings.Contains(text, "微積分") {
+		t.Errorf("group reply leaked history: %q", text)
+	}
+}
@@ -0,0 +1,275 @@
+package history
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+)
+
+const (
+	// MaxEntries is the number of queries kept per user; older ones are
+	// dropped as new ones arrive.
+	MaxEntries = 20
+
+	// MaxQueryLength bounds recorded queries (in runes). Longer messages are
+	// free-form questions rather than searches worth re-running.
+	MaxQueryLength = 100
+)
+
+// Entry is one recorded query.
+type Entry struct {
+	Module    string    // Handler that answered the query (e.g., "course")
+	Query     string    // Sanitized message text, replayable as-is
+	CreatedAt time.Time // When the query was last sent
+}
+
+// Store persists per-user query history in SQLite.
+//
+// History lives in its own file (not the cache DB) so it survives snapshot
+// hot-swaps and cache rebuilds.
+type Store struct {
+	*storage.AuxStore
+}
+
+// Open opens (or creates) the history database at path.
+func Open(ctx context.Context, path string) (*Store, error) {
+	db, err := storage.OpenAux(ctx, path, "history", initSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Store{AuxStore: storage.NewAuxStore(db)}, nil
+}
+
+func initSchema(ctx context.Context, db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS query_history (
+		id INTEGER PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		module TEXT NOT NULL,
+		query TEXT NOT NULL,
+		created_at INTEGER NOT NULL
+	) STRICT;
+	CREATE INDEX IF NOT EXISTS idx_query_history_user ON query_history(user_id, created_at);
+	CREATE TABLE IF NOT EXISTS query_history_optout (
+		user_id TEXT PRIMARY KEY,
+		created_at INTEGER NOT NULL
+	) STRICT;
+	`
+
+	if _, err := db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("create query_history tables: %w", err)
+	}
+
+	return nil
+}
+
+// RecordQuery stores a query for userID. Repeating a query moves it to the
+// top instead of adding a duplicate. Does nothing if the user opted out or
+// the query is longer than MaxQueryLength.
+func (s *Store) RecordQuery(ctx context.Context, userID, module, query string) error {
+	db, err := s.Conn()
+	if err != nil {
+		return err
+	}
+	if utf8.RuneCountInString(query) > MaxQueryLength {
+		return nil
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var optedOut bool
+	if err := tx.QueryRowContext(ctx,
+		"SELECT EXISTS(SELECT 1 FROM query_history_optout WHERE user_id = ?)", userID,
+	).Scan(&optedOut); err != nil {
+		return fmt.Errorf("check opt-out: %w", err)
+	}
+	if optedOut {
+		return nil
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"DELETE FROM query_history WHERE user_id = ? AND query = ?", userID, query,
+	); err != nil {
+		return fmt.Errorf("remove duplicate query: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO query_history (user_id, module, query, created_at) VALUES (?, ?, ?, ?)",
+		userID, module, query, time.Now().Unix(),
+	); err != nil {
+		return fmt.Errorf("insert query: %w", err)
+	}
+	// Keep only the newest MaxEntries; id breaks ties within the same second
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM query_history
+		WHERE user_id = ? AND id NOT IN (
+			SELECT id FROM query_history WHERE user_id = ?
+			ORDER BY created_at DESC, id DESC LIMIT ?
+		)
+	`, userID, userID, MaxEntries); err != nil {
+		return fmt.Errorf("trim history: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit query: %w", err)
+	}
+	return nil
+}
+
+// Recent returns up to limit of the user's queries, newest first.
+func (s *Store) Recent(ctx context.Context, userID string, limit int) ([]Entry, error) {
+	db, err := s.Conn()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT module, query, created_at
+		FROM query_history
+		WHERE user_id = ?
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?
+	`, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query history: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var entries []Entry
+	for rows.Next() {
+		var e Entry
+		var createdAt int64
+		if err := rows.Scan(&e.Module, &e.Query, &createdAt); err != nil {
+			return nil, fmt.Errorf("scan history: %w", err)
+		}
+		e.CreatedAt = time.Unix(createdAt, 0)
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// Clear deletes all of the user's queries.
+// Returns the number of entries deleted.
+func (s *Store) Clear(ctx context.Context, userID string) (int64, error) {
+	db, err := s.Conn()
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := db.ExecContext(ctx, "DELETE FROM query_history WHERE user_id = ?", userID)
+	if err != nil {
+		return 0, fmt.Errorf("clear history: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// SetOptOut turns recording off (optOut=true) or back on for the user.
+// Opting out also deletes the existing history.
+func (s *Store) SetOptOut(ctx context.Context, userID string, optOut bool) error {
+	db, err := s.Conn()
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if optOut {
+		if _, err := tx.ExecContext(ctx,
+			"INSERT OR IGNORE INTO query_history_optout (user_id, created_at) VALUES (?, ?)",
+			userID, time.Now().Unix(),
+		); err != nil {
+			return fmt.Errorf("insert opt-out: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM query_history WHERE user_id = ?", userID); err != nil {
+			return fmt.Errorf("clear history: %w", err)
+		}
+	} else {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM query_history_optout WHERE user_id = ?", userID); err != nil {
+			return fmt.Errorf("delete opt-out: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit opt-out: %w", err)
+	}
+	return nil
+}
+
+// OptedOut reports whether the user turned recording off.
+func (s *Store) OptedOut(ctx context.Context, userID string) (bool, error) {
+	db, err := s.Conn()
+	if err != nil {
+		return false, err
+	}
+
+	var optedOut bool
+	if err := db.QueryRowContext(ctx,
+		"SELECT EXISTS(SELECT 1 FROM query_history_optout WHERE user_id = ?)", userID,
+	).Scan(&optedOut); err != nil {
+		return false, fmt.Errorf("check opt-out: %w", err)
+	}
+	return optedOut, nil
+}
+
+// EraseUser deletes the user's queries and opt-out flag, so recording
+// returns to the default. Returns the number of rows deleted.
+func (s *Store) EraseUser(ctx context.Context, userID string) (int64, error) {
+	db, err := s.Conn()
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var n int64
+	for _, query := range []string{
+		"DELETE FROM query_history WHERE user_id = ?",
+		"DELETE FROM query_history_optout WHERE user_id = ?",
+	} {
+		result, err := tx.ExecContext(ctx, query, userID)
+		if err != nil {
+			return 0, fmt.Errorf("erase history: %w", err)
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("erase history: %w", err)
+		}
+		n += affected
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit erase: %w", err)
+	}
+	return n, nil
+}
+
+// Prune deletes queries recorded before the given time.
+// Returns the number of entries deleted.
+func (s *Store) Prune(ctx context.Context, before time.Time) (int64, error) {
+	db, err := s.Conn()
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := db.ExecContext(ctx, "DELETE FROM query_history WHERE created_at < ?", before.Unix())
+	if err != nil {
+		return 0, fmt.Errorf("prune history: %w", err)
+	}
+	return result.RowsAffected()
+}
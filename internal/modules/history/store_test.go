@@ -0,0 +1,173 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/moduletest"
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+)
+
+func queries(entries []Entry) []string {
+	out := make([]string, len(entries))
+	for i, e := range entries {
+		out[i] = e.Query
+	}
+	return out
+}
+
+func TestStore_RecordQuery(t *testing.T) {
+	t.Parallel()
+	store := moduletest.OpenStore(t, Open)
+	ctx := context.Background()
+
+	for _, q := range []string{"課程 微積分", "學號 412345678", "課程 微積分", "聯絡 資工"} {
+		if err := store.RecordQuery(ctx, "U1", "course", q); err != nil {
+			t.Fatalf("RecordQuery(%q) error = %v", q, err)
+		}
+	}
+	if err := store.RecordQuery(ctx, "U2", "course", "課程 線性代數"); err != nil {
+		t.Fatalf("RecordQuery() error = %v", err)
+	}
+
+	entries, err := store.Recent(ctx, "U1", MaxEntries)
+	if err != nil {
+		t.Fatalf("Recent() error = %v", err)
+	}
+	// Repeated query moves to the top instead of duplicating
+	want := []string{"聯絡 資工", "課程 微積分", "學號 412345678"}
+	if got := queries(entries); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Recent(U1) = %v, want %v", got, want)
+	}
+}
+
+func TestStore_RecordQuery_KeepsNewest(t *testing.T) {
+	t.Parallel()
+	store := moduletest.OpenStore(t, Open)
+	ctx := context.Background()
+
+	for i := range MaxEntries + 5 {
+		if err := store.RecordQuery(ctx, "U1", "course", fmt.Sprintf("課程 %d", i)); err != nil {
+			t.Fatalf("RecordQuery() error = %v", err)
+		}
+	}
+	if err := store.RecordQuery(ctx, "U1", "course", strings.Repeat("長", MaxQueryLength+1)); err != nil {
+		t.Fatalf("RecordQuery(long) error = %v", err)
+	}
+
+	entries, err := store.Recent(ctx, "U1", 100)
+	if err != nil {
+		t.Fatalf("Recent() error = %v", err)
+	}
+	if len(entries) != MaxEntries {
+		t.Fatalf("Recent() returned %d entries, want %d", len(entries), MaxEntries)
+	}
+	if first, last := entries[0].Query, entries[MaxEntries-1].Query; first != "課程 24" || last != "課程 5" {
+		t.Errorf("Recent() spans %q..%q, want 課程 24..課程 5", first, last)
+	}
+}
+
+func TestStore_OptOut(t *testing.T) {
+	t.Parallel()
+	store := moduletest.OpenStore(t, Open)
+	ctx := context.Background()
+
+	if err := store.RecordQuery(ctx, "U1", "course", "課程 微積分"); err != nil {
+		t.Fatalf("RecordQuery() error = %v", err)
+	}
+	if err := store.SetOptOut(ctx, "U1", true); err != nil {
+		t.Fatalf("SetOptOut(true) error = %v", err)
+	}
+	if err := store.RecordQuery(ctx, "U1", "course", "課程 線性代數"); err != nil {
+		t.Fatalf("RecordQuery() error = %v", err)
+	}
+
+	entries, err := store.Recent(ctx, "U1", MaxEntries)
+	if err != nil {
+		t.Fatalf("Recent() error = %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Recent() after opt-out = %v, want empty (existing history cleared, new queries ignored)", queries(entries))
+	}
+	if optedOut, _ := store.OptedOut(ctx, "U1"); !optedOut {
+		t.Error("OptedOut() = false, want true")
+	}
+
+	if err := store.SetOptOut(ctx, "U1", false); err != nil {
+		t.Fatalf("SetOptOut(false) error = %v", err)
+	}
+	if err := store.RecordQuery(ctx, "U1", "course", "課程 線性代數"); err != nil {
+		t.Fatalf("RecordQuery() error = %v", err)
+	}
+	if entries, _ := store.Recent(ctx, "U1", MaxEntries); len(entries) != 1 {
+		t.Errorf("Recent() after opt-in returned %d entries, want 1", len(entries))
+	}
+}
+
+func TestStore_ClearAndPrune(t *testing.T) {
+	t.Parallel()
+	store := moduletest.OpenStore(t, Open)
+	ctx := context.Background()
+
+	for _, user := range []string{"U1", "U2"} {
+		if err := store.RecordQuery(ctx, user, "course", "課程 微積分"); err != nil {
+			t.Fatalf("RecordQuery() error = %v", err)
+		}
+	}
+
+	n, err := store.Clear(ctx, "U1")
+	if err != nil || n != 1 {
+		t.Fatalf("Clear(U1) = %d, %v; want 1, nil", n, err)
+	}
+	if entries, _ := store.Recent(ctx, "U2", MaxEntries); len(entries) != 1 {
+		t.Errorf("Clear(U1) affected U2: %d entries left, want 1", len(entries))
+	}
+
+	if n, err := store.Prune(ctx, time.Now().Add(-time.Hour)); err != nil || n != 0 {
+		t.Errorf("Prune(1h ago) = %d, %v; want 0, nil", n, err)
+	}
+	if n, err := store.Prune(ctx, time.Now().Add(time.Hour)); err != nil || n != 1 {
+		t.Errorf("Prune(1h ahead) = %d, %v; want 1, nil", n, err)
+	}
+}
+
+func TestStore_EraseUser(t *testing.T) {
+	t.Parallel()
+	store := moduletest.OpenStore(t, Open)
+	ctx := context.Background()
+
+	for _, user := range []string{"U1", "U2"} {
+		if err := store.RecordQuery(ctx, user, "course", "課程 微積分"); err != nil {
+			t.Fatalf("RecordQuery() error = %v", err)
+		}
+	}
+	if err := store.SetOptOut(ctx, "U2", true); err != nil {
+		t.Fatalf("SetOptOut() error = %v", err)
+	}
+
+	if n, err := store.EraseUser(ctx, "U1"); err != nil || n != 1 {
+		t.Errorf("EraseUser(U1) = %d, %v; want 1, nil", n, err)
+	}
+	// U2's history went with the opt-out; only the flag is left to erase
+	if n, err := store.EraseUser(ctx, "U2"); err != nil || n != 1 {
+		t.Errorf("EraseUser(U2) = %d, %v; want 1, nil", n, err)
+	}
+	if optedOut, _ := store.OptedOut(ctx, "U2"); optedOut {
+		t.Error("OptedOut() after erase = true, want false")
+	}
+}
+
+func TestStore_Closed(t *testing.T) {
+	t.Parallel()
+	store := moduletest.OpenStore(t, Open)
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := store.RecordQuery(context.Background(), "U1", "course", "課程"); !errors.Is(err, storage.ErrDatabaseClosed) {
+		t.Errorf("RecordQuery() after Close = %v, want storage.ErrDatabaseClosed", err)
+	}
+}
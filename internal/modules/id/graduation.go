@@ -0,0 +1,56 @@
+package id
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/scraper/ntpu"
+)
+
+// programYears is the standard length of study (修業年限) of each degree
+// type, keyed by student ID prefix. Students often take longer (延畢,
+// 休學) or leave early, so anything derived from it is an estimate.
+var programYears = map[string]int{
+	ntpu.StudentTypeContinuing: 4,
+	ntpu.StudentTypeUndergrad:  4,
+	ntpu.StudentTypeMaster:     2,
+	ntpu.StudentTypePhD:        4,
+}
+
+// graduationMonth is when commencement (畢業典禮) ends the academic year.
+const graduationMonth = time.June
+
+// graduationDisclaimer explains that the status line is an estimate.
+const graduationDisclaimer = "⚠️ 畢業年份依入學學年與修業年限推算，未計延畢、休學或轉學"
+
+// inferGraduation estimates the Western year a student graduates in from the
+// degree type (student ID prefix) and the ROC entry year, and whether that
+// graduation has passed at now. ok is false when the degree type is unknown
+// or the entry year is missing.
+func inferGraduation(studentID string, entryYear int, now time.Time) (year int, graduated, ok bool) {
+	if studentID == "" || entryYear <= 0 {
+		return 0, false, false
+	}
+	length, known := programYears[studentID[:1]]
+	if !known {
+		return 0, false, false
+	}
+
+	// ROC academic year Y starts in August of Y+1911 and ends in June of Y+1912
+	year = entryYear + 1911 + length
+	graduated = now.After(time.Date(year, graduationMonth+1, 1, 0, 0, 0, 0, now.Location()))
+	return year, graduated, true
+}
+
+// graduationStatus returns the inferred status line shown on a student card,
+// e.g. "推測已畢業（約 2019）", or "" when it cannot be inferred.
+func graduationStatus(studentID string, entryYear int, now time.Time) string {
+	year, graduated, ok := inferGraduation(studentID, entryYear, now)
+	if !ok {
+		return ""
+	}
+	if graduated {
+		return fmt.Sprintf("推測已畢業（約 %d）", year)
+	}
+	return fmt.Sprintf("推測在學中（預計約 %d 畢業）", year)
+}
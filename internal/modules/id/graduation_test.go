@@ -0,0 +1,45 @@
+package id
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGraduationStatus(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2026, time.October, 16, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		studentID string
+		entryYear int
+		want      string
+	}{
+		{"undergrad of an old cohort", "410412345", 104, "推測已畢業（約 2019）"},
+		{"master's", "710912345", 109, "推測已畢業（約 2022）"},
+		{"undergrad still enrolled", "411312345", 113, "推測在學中（預計約 2028 畢業）"},
+		{"graduating this June", "411112345", 111, "推測已畢業（約 2026）"},
+		{"unknown degree type", "912345678", 112, ""},
+		{"missing entry year", "410412345", 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := graduationStatus(tt.studentID, tt.entryYear, now); got != tt.want {
+				t.Errorf("graduationStatus(%q, %d) = %q, want %q", tt.studentID, tt.entryYear, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInferGraduation_CommencementBoundary(t *testing.T) {
+	t.Parallel()
+	june := time.Date(2019, time.June, 20, 0, 0, 0, 0, time.UTC)
+	if _, graduated, _ := inferGraduation("410412345", 104, june); graduated {
+		t.Error("inferGraduation() reports graduated before the end of June")
+	}
+	july := time.Date(2019, time.July, 2, 0, 0, 0, 0, time.UTC)
+	if year, graduated, ok := inferGraduation("410412345", 104, july); !ok || !graduated || year != 2019 {
+		t.Errorf("inferGraduation() = %d, %v, %v, want 2019, true, true", year, graduated, ok)
+	}
+}
@@ -14,11 +14,14 @@ import (
 
 	"github.com/garyellow/ntpu-linebot-go/internal/bot"
 	"github.com/garyellow/ntpu-linebot-go/internal/config"
+	"github.com/garyellow/ntpu-linebot-go/internal/data"
 	"github.com/garyellow/ntpu-linebot-go/internal/delta"
 	domerrors "github.com/garyellow/ntpu-linebot-go/internal/errors"
 	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
 	"github.com/garyellow/ntpu-linebot-go/internal/logger"
 	"github.com/garyellow/ntpu-linebot-go/internal/metrics"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/news"
+	"github.com/garyellow/ntpu-linebot-go/internal/msgtmpl"
 	"github.com/garyellow/ntpu-linebot-go/internal/scraper"
 	"github.com/garyellow/ntpu-linebot-go/internal/scraper/ntpu"
 	"github.com/garyellow/ntpu-linebot-go/internal/sticker"
@@ -39,6 +42,8 @@ type Handler struct {
 	logger         *logger.Logger
 	stickerManager *sticker.Manager
 	deltaRecorder  delta.Recorder
+	texts          *msgtmpl.Store // Message copy templates
+	deptNews       bool           // Department replies link to the news module (📰 系上公告)
 
 	// matchers contains all pattern-handler pairs sorted by priority.
 	// Shared by CanHandle and HandleMessage for consistent routing.
@@ -48,6 +53,9 @@ type Handler struct {
 	// Index by degreeIndex(DegreeType): 0=Bachelor, 1=Master, 2=PhD, 3=default.
 	prebuiltDeptCodeTexts [4]string
 	prebuiltDeptCodeQRs   [4]*messaging_api.QuickReply
+
+	// postbacks routes decoded postback actions to handler methods.
+	postbacks *bot.PostbackRouter
 }
 
 // Name returns the module name
@@ -130,7 +138,9 @@ var (
 )
 
 // NewHandler creates a new ID handler with required dependencies.
-// All parameters are mandatory for proper handler operation.
+// All parameters are mandatory except texts (nil = embedded message templates).
+// deptNews adds a 📰 系上公告 button to department replies; set it when the
+// news module is registered.
 // Initializes and sorts matchers by priority during construction.
 func NewHandler(
 	db *storage.DB,
@@ -139,7 +149,13 @@ func NewHandler(
 	logger *logger.Logger,
 	stickerManager *sticker.Manager,
 	deltaRecorder delta.Recorder,
+	texts *msgtmpl.Store, // Message templates (nil = embedded defaults)
+	deptNews bool,
 ) *Handler {
+	if texts == nil {
+		texts = msgtmpl.Default()
+	}
+
 	h := &Handler{
 		db:             db,
 		scraper:        scraper,
@@ -147,6 +163,8 @@ func NewHandler(
 		logger:         logger,
 		stickerManager: stickerManager,
 		deltaRecorder:  deltaRecorder,
+		texts:          texts,
+		deptNews:       deptNews,
 	}
 
 	// Initialize Pattern-Action Table
@@ -155,6 +173,8 @@ func NewHandler(
 	// Pre-compute static department code messages (text + QR)
 	h.precomputeDeptCodes()
 
+	h.postbacks = h.newPostbackRouter()
+
 	return h
 }
 
@@ -270,12 +290,9 @@ func (h *Handler) DispatchIntent(ctx context.Context, intent string, params map[
 		if !ok || name == "" {
 			return nil, fmt.Errorf("%w: name", domerrors.ErrMissingParameter)
 		}
-		if h.logger != nil {
-			h.logger.WithModule(ModuleName).
-				WithField("intent", intent).
-				WithField("name", name).
-				DebugContext(ctx, "Dispatching ID intent")
-		}
+		logger.FromContext(ctx).
+			WithField("student_name", name).
+			DebugContext(ctx, "Dispatching ID intent")
 		return h.handleStudentNameQuery(ctx, name), nil
 
 	case IntentStudentID:
@@ -283,12 +300,9 @@ func (h *Handler) DispatchIntent(ctx context.Context, intent string, params map[
 		if !ok || studentID == "" {
 			return nil, fmt.Errorf("%w: student_id", domerrors.ErrMissingParameter)
 		}
-		if h.logger != nil {
-			h.logger.WithModule(ModuleName).
-				WithField("intent", intent).
-				WithField("student_id", studentID).
-				DebugContext(ctx, "Dispatching ID intent")
-		}
+		logger.FromContext(ctx).
+			WithField("student_id", studentID).
+			DebugContext(ctx, "Dispatching ID intent")
 		return h.handleStudentIDQuery(ctx, studentID), nil
 
 	case IntentDepartment:
@@ -296,12 +310,9 @@ func (h *Handler) DispatchIntent(ctx context.Context, intent string, params map[
 		if !ok || department == "" {
 			return nil, fmt.Errorf("%w: department", domerrors.ErrMissingParameter)
 		}
-		if h.logger != nil {
-			h.logger.WithModule(ModuleName).
-				WithField("intent", intent).
-				WithField("department", department).
-				DebugContext(ctx, "Dispatching ID intent")
-		}
+		logger.FromContext(ctx).
+			WithField("department", department).
+			DebugContext(ctx, "Dispatching ID intent")
 
 		return h.handleUnifiedDepartmentQuery(department), nil
 
@@ -310,12 +321,9 @@ func (h *Handler) DispatchIntent(ctx context.Context, intent string, params map[
 		if !ok || year == "" {
 			return nil, fmt.Errorf("%w: year", domerrors.ErrMissingParameter)
 		}
-		if h.logger != nil {
-			h.logger.WithModule(ModuleName).
-				WithField("intent", intent).
-				WithField("year", year).
-				DebugContext(ctx, "Dispatching ID intent")
-		}
+		logger.FromContext(ctx).
+			WithField("year", year).
+			DebugContext(ctx, "Dispatching ID intent")
 		return h.handleYearQuery(year), nil
 
 	case IntentDeptCodes:
@@ -329,12 +337,9 @@ func (h *Handler) DispatchIntent(ctx context.Context, intent string, params map[
 		default:
 			degree = DegreeBachelor
 		}
-		if h.logger != nil {
-			h.logger.WithModule(ModuleName).
-				WithField("intent", intent).
-				WithField("degree", degree).
-				DebugContext(ctx, "Dispatching ID intent")
-		}
+		logger.FromContext(ctx).
+			WithField("degree", degree).
+			DebugContext(ctx, "Dispatching ID intent")
 		return h.handleDepartmentCodesByDegree(degree), nil
 
 	default:
@@ -368,7 +373,7 @@ func (h *Handler) CanHandle(text string) bool {
 // HandleMessage finds the matching pattern and executes its handler.
 // Returns empty slice if no pattern matches (fallback to NLU).
 func (h *Handler) HandleMessage(ctx context.Context, text string) []messaging_api.MessageInterface {
-	log := h.logger.WithModule(ModuleName)
+	log := logger.FromContext(ctx)
 	text = strings.TrimSpace(text)
 
 	log.DebugContext(ctx, "Handling ID message")
@@ -483,15 +488,16 @@ func (h *Handler) handleYearPattern(ctx context.Context, text string, matches []
 
 	// No year provided - show guidance message
 	sender := lineutil.GetSender(senderName, h.stickerManager)
+	latest := latestStudentYear()
 	msg := lineutil.NewTextMessageWithConsistentSender(
-		"📅 按學年度查詢學生\n\n請輸入學年度進行查詢\n例如：學年 112、學年 110\n\n📋 查詢流程：\n1️⃣ 選擇學院群（文法商/公社電資）\n2️⃣ 選擇學院\n3️⃣ 選擇系所\n4️⃣ 查看該系所所有學生\n\n⚠️ 僅提供 94-112 學年度完整資料\n（113 年不完整、114 年起無資料）",
+		h.texts.Text(msgtmpl.IDYearHelp, nil),
 		sender,
 	)
 	msg.QuickReply = lineutil.NewQuickReply([]lineutil.QuickReplyItem{
-		// Use IDDataYearEnd from config to ensure we don't suggest years that have no data
-		{Action: lineutil.NewMessageAction(fmt.Sprintf("📅 查詢 %d 學年度", config.IDDataYearEnd), fmt.Sprintf("學年 %d", config.IDDataYearEnd))},
-		{Action: lineutil.NewMessageAction(fmt.Sprintf("📅 查詢 %d 學年度", config.IDDataYearEnd-1), fmt.Sprintf("學年 %d", config.IDDataYearEnd-1))},
-		{Action: lineutil.NewMessageAction(fmt.Sprintf("📅 查詢 %d 學年度", config.IDDataYearEnd-2), fmt.Sprintf("學年 %d", config.IDDataYearEnd-2))},
+		// Suggest the newest complete years so we don't suggest years that have no data
+		yearQueryAction(latest),
+		yearQueryAction(latest - 1),
+		yearQueryAction(latest - 2),
 		lineutil.QuickReplyHelpAction(),
 	})
 	return []messaging_api.MessageInterface{msg}
@@ -524,67 +530,10 @@ func (h *Handler) handleStudentPattern(ctx context.Context, text string, matches
 
 // HandlePostback handles postback events for the ID module
 func (h *Handler) HandlePostback(ctx context.Context, data string) []messaging_api.MessageInterface {
-	log := h.logger.WithModule(ModuleName)
+	log := logger.FromContext(ctx)
 	log.DebugContext(ctx, "Handling ID postback")
 
-	// Strip module prefix if present (registry passes original data)
-	data = strings.TrimPrefix(data, "id:")
-
-	// Handle "兇" (easter egg)
-	if data == "兇" {
-		sender := lineutil.GetSender(senderName, h.stickerManager)
-		return []messaging_api.MessageInterface{
-			lineutil.NewTextMessageWithConsistentSender("泥好兇喔～～(⊙﹏⊙)", sender),
-		}
-	}
-
-	// Handle year search postback
-	if strings.Contains(data, bot.PostbackSplitChar) {
-		parts := strings.Split(data, bot.PostbackSplitChar)
-		if len(parts) != 2 {
-			return []messaging_api.MessageInterface{}
-		}
-
-		action := parts[0]
-		year := parts[1]
-
-		switch action {
-		case "搜尋全系":
-			return h.handleYearSearchConfirm(year)
-		case "文法商", "公社電資":
-			return h.handleCollegeGroupSelection(action, year)
-		case "人文學院", "法律學院", "商學院", "公共事務學院", "社會科學學院", "電機資訊學院":
-			return h.handleCollegeSelection(action, year)
-		default:
-			// Validate department code format (1-3 digits) before lookup
-			if len(action) > 3 || len(action) == 0 {
-				sender := lineutil.GetSender(senderName, h.stickerManager)
-				msg := lineutil.NewTextMessageWithConsistentSender(
-					"❌ 無效的系代碼格式\n\n系代碼應為 1-3 位數字",
-					sender,
-				)
-				msg.QuickReply = lineutil.NewQuickReply(lineutil.QuickReplyStudentNav())
-				return []messaging_api.MessageInterface{msg}
-			}
-
-			// Verify department code contains only digits
-			if _, err := strconv.Atoi(action); err != nil {
-				sender := lineutil.GetSender(senderName, h.stickerManager)
-				msg := lineutil.NewTextMessageWithConsistentSender(
-					"❌ 無效的系代碼格式\n\n系代碼應為 1-3 位數字",
-					sender,
-				)
-				msg.QuickReply = lineutil.NewQuickReply(lineutil.QuickReplyStudentNav())
-				return []messaging_api.MessageInterface{msg}
-			}
-
-			if _, ok := ntpu.DepartmentNames[action]; ok {
-				return h.handleDepartmentSelection(ctx, action, year)
-			}
-		}
-	}
-
-	return []messaging_api.MessageInterface{}
+	return h.postbacks.Dispatch(ctx, data)
 }
 
 // degreeIndex maps a DegreeType to an array index for pre-built content lookups.
@@ -753,18 +702,21 @@ func (h *Handler) handleDepartmentCodesByDegree(degree DegreeType) []messaging_a
 	return []messaging_api.MessageInterface{msg}
 }
 
-// RIP image URL for LMS 2.0 deprecation (year 114+)
-const lmsDeprecatedImageURL = "https://raw.githubusercontent.com/garyellow/ntpu-linebot-go/main/assets/rip.png"
-
-// buildLMSDeprecatedResponse builds a response for year 114+ (NO data at all).
-// Returns text message + RIP image with quick reply.
-func (h *Handler) buildLMSDeprecatedResponse(message string, sender *messaging_api.Sender, quickReplyItems []lineutil.QuickReplyItem) []messaging_api.MessageInterface {
-	textMsg := lineutil.NewTextMessageWithConsistentSender(message, sender)
+// buildUnavailableResponse builds a response for a year the student source
+// no longer lists (114+ since the LMS 2.0 shutdown): the period's message,
+// followed by its image when it has one, with quick reply on the last message.
+func (h *Handler) buildUnavailableResponse(period data.YearPeriod, sender *messaging_api.Sender, quickReplyItems []lineutil.QuickReplyItem) []messaging_api.MessageInterface {
+	textMsg := lineutil.NewTextMessageWithConsistentSender(period.Message, sender)
+	if period.Image == "" {
+		textMsg.QuickReply = lineutil.NewQuickReply(quickReplyItems)
+		return []messaging_api.MessageInterface{textMsg}
+	}
 
 	// Image message with quick reply (must be on last message)
+	imageURL := lineutil.ProxyImageURL(data.Assets.URL(period.Image))
 	imgMsg := &messaging_api.ImageMessage{
-		OriginalContentUrl: lmsDeprecatedImageURL,
-		PreviewImageUrl:    lmsDeprecatedImageURL,
+		OriginalContentUrl: imageURL,
+		PreviewImageUrl:    imageURL,
 	}
 	imgMsg.Sender = sender
 	imgMsg.QuickReply = lineutil.NewQuickReply(quickReplyItems)
@@ -810,7 +762,7 @@ func (h *Handler) handleDepartmentNameQuery(deptName string) []messaging_api.Mes
 			fmt.Sprintf("🔍「%s」→ %s（%s）\n\n系代碼是：%s", deptName, matches[0].name, matches[0].degree, matches[0].code),
 			sender,
 		)
-		msg.QuickReply = lineutil.NewQuickReply(lineutil.QuickReplyStudentNav())
+		msg.QuickReply = lineutil.NewQuickReply(h.departmentQuickReply(matches[0].code))
 		return []messaging_api.MessageInterface{msg}
 	}
 
@@ -821,6 +773,7 @@ func (h *Handler) handleDepartmentNameQuery(deptName string) []messaging_api.Mes
 
 		// Group by degree for clearer display
 		degreeOrder := []string{"學士班", "碩士班", "博士班"}
+		var bachelorCodes []string
 		for _, deg := range degreeOrder {
 			var degMatches []deptMatch
 			for _, m := range matches {
@@ -832,11 +785,19 @@ func (h *Handler) handleDepartmentNameQuery(deptName string) []messaging_api.Mes
 				fmt.Fprintf(&builder, "\n🎓 %s\n", deg)
 				for _, m := range degMatches {
 					fmt.Fprintf(&builder, "  • %s → %s\n", m.name, m.code)
+					if deg == "學士班" {
+						bachelorCodes = append(bachelorCodes, m.code)
+					}
 				}
 			}
 		}
 		msg := lineutil.NewTextMessageWithConsistentSender(builder.String(), sender)
-		msg.QuickReply = lineutil.NewQuickReply(lineutil.QuickReplyStudentNav())
+		// e.g. 資工 → 學士班 and 碩士班 of one department: link its announcements
+		if len(bachelorCodes) == 1 {
+			msg.QuickReply = lineutil.NewQuickReply(h.departmentQuickReply(bachelorCodes[0]))
+		} else {
+			msg.QuickReply = lineutil.NewQuickReply(lineutil.QuickReplyStudentNav())
+		}
 		return []messaging_api.MessageInterface{msg}
 	}
 
@@ -845,6 +806,21 @@ func (h *Handler) handleDepartmentNameQuery(deptName string) []messaging_api.Mes
 	return []messaging_api.MessageInterface{msg}
 }
 
+// departmentQuickReply returns the student navigation, led by a 📰 系上公告
+// button when news is enabled and code is an undergraduate department
+// (department websites are listed by their undergraduate code).
+func (h *Handler) departmentQuickReply(code string) []lineutil.QuickReplyItem {
+	items := lineutil.QuickReplyStudentNav()
+	name, ok := ntpu.DepartmentNames[code]
+	if !h.deptNews || !ok {
+		return items
+	}
+	item := lineutil.QuickReplyItem{Action: lineutil.NewPostbackActionWithDisplayText(
+		"📰 系上公告", name+"系公告", news.DeptPostback(code),
+	)}
+	return append([]lineutil.QuickReplyItem{item}, items...)
+}
+
 // handleUnifiedDepartmentQuery handles both code (numeric) and name (text) queries for departments.
 // It acts as a smart router to unify the search logic.
 func (h *Handler) handleUnifiedDepartmentQuery(query string) []messaging_api.MessageInterface {
@@ -887,7 +863,7 @@ func (h *Handler) handleDepartmentCodeQuery(code string) []messaging_api.Message
 			fmt.Sprintf("🎓 系代碼 %s 是：%s（%s）", code, matches[0].name, matches[0].degree),
 			sender,
 		)
-		msg.QuickReply = lineutil.NewQuickReply(lineutil.QuickReplyStudentNav())
+		msg.QuickReply = lineutil.NewQuickReply(h.departmentQuickReply(code))
 		return []messaging_api.MessageInterface{msg}
 	}
 
@@ -899,7 +875,7 @@ func (h *Handler) handleDepartmentCodeQuery(code string) []messaging_api.Message
 			fmt.Fprintf(&builder, "\n• %s（%s）", m.name, m.degree)
 		}
 		msg := lineutil.NewTextMessageWithConsistentSender(builder.String(), sender)
-		msg.QuickReply = lineutil.NewQuickReply(lineutil.QuickReplyStudentNav())
+		msg.QuickReply = lineutil.NewQuickReply(h.departmentQuickReply(code))
 		return []messaging_api.MessageInterface{msg}
 	}
 
@@ -917,70 +893,66 @@ func (h *Handler) handleYearQuery(yearStr string) []messaging_api.MessageInterfa
 	if err != nil {
 		msg := lineutil.NewTextMessageWithConsistentSender("📅 年份格式不正確\n\n請輸入 2-4 位數字\n例如：112 或 2023", sender)
 		msg.QuickReply = lineutil.NewQuickReply([]lineutil.QuickReplyItem{
-			{Action: lineutil.NewMessageAction(fmt.Sprintf("📅 查詢 %d 學年度", config.IDDataYearEnd), fmt.Sprintf("學年 %d", config.IDDataYearEnd))},
+			yearQueryAction(latestStudentYear()),
 			lineutil.QuickReplyHelpAction(),
 		})
 		return []messaging_api.MessageInterface{msg}
 	}
 
 	currentYear := time.Now().Year() - 1911
+	students := data.Availability.Students
+	latest := students.LatestCompleteYear(currentYear)
 
 	// Validate year - order matters for proper responses!
 	// 1. Check future year first
 	if year > currentYear {
 		msg := lineutil.NewTextMessageWithConsistentSender(config.IDYearFutureMessage, sender)
 		msg.QuickReply = lineutil.NewQuickReply([]lineutil.QuickReplyItem{
-			{Action: lineutil.NewMessageAction(fmt.Sprintf("📅 查詢 %d 學年度", min(currentYear, config.IDDataYearEnd)), fmt.Sprintf("學年 %d", min(currentYear, config.IDDataYearEnd)))},
+			yearQueryAction(latest),
 			lineutil.QuickReplyStudentAction(),
 			lineutil.QuickReplyHelpAction(),
 		})
 		return []messaging_api.MessageInterface{msg}
 	}
 
-	// 2. Check for year 114+ (NO data at all) - reject with RIP image
-	if year > config.IDDataYearEnd+1 {
-		return h.buildLMSDeprecatedResponse(
-			config.IDLMSDeprecatedMessage,
-			sender,
-			[]lineutil.QuickReplyItem{
-				{Action: lineutil.NewMessageAction(fmt.Sprintf("📅 查詢 %d 學年度", config.IDDataYearEnd), fmt.Sprintf("學年 %d", config.IDDataYearEnd))},
-				lineutil.QuickReplyYearAction(),
-				lineutil.QuickReplyHelpAction(),
-			},
-		)
-	}
-
-	// 3. Check for year 113 (sparse data) - reject query
-	if year == config.IDDataYearEnd+1 {
-		// Reject 113 queries as data is too sparse for list view
-		msg := lineutil.NewTextMessageWithConsistentSender(
-			"⚠️ 113 學年度資料不完整\n\n因僅少數學生有資料，故不開放「學年」列表查詢。\n\n💡 若已知學號或姓名，請改用「學號」或「姓名」功能查詢。",
-			sender,
-		)
+	// 2. Check if year is before NTPU was founded (no source lists it)
+	period, ok := students.Period(year)
+	if !ok {
+		msg := lineutil.NewTextMessageWithConsistentSender(config.IDYearBeforeNTPUMessage, sender)
 		msg.QuickReply = lineutil.NewQuickReply([]lineutil.QuickReplyItem{
+			yearQueryAction(firstCompleteStudentYear()),
 			lineutil.QuickReplyStudentAction(),
-			{Action: lineutil.NewMessageAction(fmt.Sprintf("📅 改查 %d 學年度", config.IDDataYearEnd), fmt.Sprintf("學年 %d", config.IDDataYearEnd))},
 			lineutil.QuickReplyHelpAction(),
 		})
 		return []messaging_api.MessageInterface{msg}
 	}
 
-	// 4. Check if year is before NTPU was founded
-	if year < config.NTPUFoundedYear {
-		msg := lineutil.NewTextMessageWithConsistentSender(config.IDYearBeforeNTPUMessage, sender)
+	// 3. Check the year's coverage in data.Availability
+	switch period.Coverage {
+	case data.CoverageNone:
+		// NO data at all (114+) - reject with the period's message and image
+		return h.buildUnavailableResponse(period, sender, []lineutil.QuickReplyItem{
+			yearQueryAction(latest),
+			lineutil.QuickReplyYearAction(),
+			lineutil.QuickReplyHelpAction(),
+		})
+	case data.CoveragePartial:
+		// Reject partial years (113) as data is too sparse for list view
+		msg := lineutil.NewTextMessageWithConsistentSender(
+			h.texts.Text(msgtmpl.IDYearIncomplete, nil),
+			sender,
+		)
 		msg.QuickReply = lineutil.NewQuickReply([]lineutil.QuickReplyItem{
-			{Action: lineutil.NewMessageAction("📅 查詢 94 學年度", "學年 94")},
 			lineutil.QuickReplyStudentAction(),
+			{Action: lineutil.NewMessageAction(fmt.Sprintf("📅 改查 %d 學年度", latest), fmt.Sprintf("學年 %d", latest))},
 			lineutil.QuickReplyHelpAction(),
 		})
 		return []messaging_api.MessageInterface{msg}
-	}
-
-	// 5. Check if year is before LMS has complete data (90-93 have sparse data)
-	if year < config.LMSLaunchYear {
-		msg := lineutil.NewTextMessageWithConsistentSender(config.IDYearTooOldMessage, sender)
+	case data.CoverageSparse:
+		// Years before LMS has complete data (89-93)
+		msg := lineutil.NewTextMessageWithConsistentSender(period.Message, sender)
 		msg.QuickReply = lineutil.NewQuickReply([]lineutil.QuickReplyItem{
-			{Action: lineutil.NewMessageAction("📅 查詢 94 學年度", "學年 94")},
+			yearQueryAction(firstCompleteStudentYear()),
 			lineutil.QuickReplyStudentAction(),
 			lineutil.QuickReplyHelpAction(),
 		})
@@ -992,8 +964,8 @@ func (h *Handler) handleYearQuery(yearStr string) []messaging_api.MessageInterfa
 	confirmMsg := lineutil.NewConfirmTemplate(
 		"確認學年度",
 		confirmText,
-		lineutil.NewPostbackActionWithDisplayText("哪次不是", "哪次不是", fmt.Sprintf("id:搜尋全系%s%d", bot.PostbackSplitChar, year)),
-		lineutil.NewPostbackActionWithDisplayText("我在想想", "再啦乾ಠ_ಠ", "id:兇"),
+		lineutil.NewPostbackActionWithDisplayText("哪次不是", "哪次不是", yearAllPostback(strconv.Itoa(year))),
+		lineutil.NewPostbackActionWithDisplayText("我在想想", "再啦乾ಠ_ಠ", scoldPostback()),
 	)
 	return []messaging_api.MessageInterface{
 		lineutil.SetSender(confirmMsg, sender),
@@ -1006,15 +978,15 @@ func (h *Handler) handleYearSearchConfirm(yearStr string) []messaging_api.Messag
 
 	// Create college group selection template with clear guidance
 	actions := []messaging_api.ActionInterface{
-		lineutil.NewPostbackActionWithDisplayText("文法商", fmt.Sprintf("查詢 %s 學年度文法商", yearStr), fmt.Sprintf("id:文法商%s%s", bot.PostbackSplitChar, yearStr)),
-		lineutil.NewPostbackActionWithDisplayText("公社電資", fmt.Sprintf("查詢 %s 學年度公社電資", yearStr), fmt.Sprintf("id:公社電資%s%s", bot.PostbackSplitChar, yearStr)),
+		lineutil.NewPostbackActionWithDisplayText("文法商", fmt.Sprintf("查詢 %s 學年度文法商", yearStr), collegeGroupPostback("文法商", yearStr)),
+		lineutil.NewPostbackActionWithDisplayText("公社電資", fmt.Sprintf("查詢 %s 學年度公社電資", yearStr), collegeGroupPostback("公社電資", yearStr)),
 	}
 
 	msg := lineutil.NewButtonsTemplateWithImage(
 		fmt.Sprintf("%s 學年度學生查詢", yearStr),
 		fmt.Sprintf("%s 學年度", yearStr),
 		"請選擇科系所屬學院群\n\n📚 文法商：人文、法律、商學院\n🏛️ 公社電資：公共、社科、電資學院",
-		"https://new.ntpu.edu.tw/assets/logo/ntpu_logo.png",
+		data.Assets.URL(data.AssetLogo),
 		actions,
 	)
 
@@ -1025,7 +997,7 @@ func (h *Handler) handleYearSearchConfirm(yearStr string) []messaging_api.Messag
 
 // handleStudentIDQuery handles student ID queries
 func (h *Handler) handleStudentIDQuery(ctx context.Context, studentID string) []messaging_api.MessageInterface {
-	log := h.logger.WithModule(ModuleName)
+	log := logger.FromContext(ctx)
 	startTime := time.Now()
 	sender := lineutil.GetSender(senderName, h.stickerManager)
 
@@ -1045,17 +1017,14 @@ func (h *Handler) handleStudentIDQuery(ctx context.Context, studentID string) []
 	// Extract year for validation (keep in scope for later error handling)
 	year := ntpu.ExtractYear(studentID)
 
-	// Check year before querying - reject 114+ immediately
-	if year > config.IDDataYearEnd+1 {
-		return h.buildLMSDeprecatedResponse(
-			config.IDLMSDeprecatedMessage,
-			sender,
-			[]lineutil.QuickReplyItem{
-				lineutil.QuickReplyYearAction(),
-				lineutil.QuickReplyStudentAction(),
-				lineutil.QuickReplyHelpAction(),
-			},
-		)
+	// Check year before querying - reject years without data (114+) immediately
+	period, _ := data.Availability.Students.Period(year)
+	if period.Coverage == data.CoverageNone && period.Message != "" {
+		return h.buildUnavailableResponse(period, sender, []lineutil.QuickReplyItem{
+			lineutil.QuickReplyYearAction(),
+			lineutil.QuickReplyStudentAction(),
+			lineutil.QuickReplyHelpAction(),
+		})
 	}
 
 	// Check cache first
@@ -1070,14 +1039,15 @@ func (h *Handler) handleStudentIDQuery(ctx context.Context, studentID string) []
 
 	if student != nil {
 		// Cache hit
-		h.metrics.RecordCacheHit(ModuleName)
+		h.metrics.RecordCacheHit(ctx, ModuleName)
 		log.WithField("student_id", studentID).
 			DebugContext(ctx, "Student cache hit")
 		return h.formatStudentResponse(student)
 	}
 
 	// Cache miss - scrape from website
-	h.metrics.RecordCacheMiss(ModuleName)
+	h.metrics.RecordCacheMiss(ctx, ModuleName)
+	lineutil.ShowLoading(ctx)
 	log.WithField("student_id", studentID).
 		DebugContext(ctx, "Student cache miss, scraping")
 
@@ -1088,14 +1058,11 @@ func (h *Handler) handleStudentIDQuery(ctx context.Context, studentID string) []
 			ErrorContext(ctx, "Failed to scrape student by ID")
 		h.metrics.RecordScraperRequest(ModuleName, "error", time.Since(startTime).Seconds())
 
-		// Check if the student ID belongs to year 113 (incomplete data)
-		// Year 114+ would have been rejected earlier, so this is only for 113
-		if year == config.IDDataYearEnd+1 {
+		// Check if the student ID belongs to a year with a notice (113, incomplete data)
+		// Years without data would have been rejected earlier
+		if period.Notice != "" {
 			msg := lineutil.NewTextMessageWithConsistentSender(
-				fmt.Sprintf("🔍 查無學號 %s 的資料\n\n"+
-					"⚠️ 113 學年度資料不完整\n"+
-					"📅 完整資料範圍：94-112 學年度",
-					studentID),
+				fmt.Sprintf("🔍 查無學號 %s 的資料\n\n%s", studentID, period.Notice),
 				sender,
 			)
 			msg.QuickReply = lineutil.NewQuickReply([]lineutil.QuickReplyItem{
@@ -1119,7 +1086,7 @@ func (h *Handler) handleStudentIDQuery(ctx context.Context, studentID string) []
 	}
 
 	// Save to cache
-	if err := h.db.SaveStudent(ctx, student); err != nil {
+	if err := h.saveStudent(ctx, student); err != nil {
 		log.WithError(err).WarnContext(ctx, "Failed to save student to cache")
 	}
 
@@ -1127,6 +1094,16 @@ func (h *Handler) handleStudentIDQuery(ctx context.Context, studentID string) []
 	return h.formatStudentResponse(student)
 }
 
+// saveStudent caches a scraped student. Students that fail
+// ntpu.ValidateStudent are not cached, so a bad parse is not served from the
+// cache until it expires.
+func (h *Handler) saveStudent(ctx context.Context, student *storage.Student) error {
+	if err := ntpu.ValidateStudent(student); err != nil {
+		return err
+	}
+	return h.db.SaveStudent(ctx, student)
+}
+
 // handleStudentNameQuery handles student name queries with application-layer character-set matching.
 //
 // Search Strategy:
@@ -1134,31 +1111,39 @@ func (h *Handler) handleStudentIDQuery(ctx context.Context, studentID string) []
 //  1. Loads all cached students from SQLite (fast with WAL mode).
 //  2. Filters using stringutil.ContainsAllRunes() for non-contiguous character matching.
 //     Example: "王明" and "明王" both match "王小明" because all characters exist in the name.
-//  3. Returns both the total count of matches and the first 400 results.
+//  3. Returns both the total count of matches and one page of up to 400 results;
+//     a 下一頁 Quick Reply carries the cursor of the next page (see handleStudentNamePage).
 //
 // This approach supports flexible matching that SQL LIKE cannot provide, such as:
 // - Non-contiguous characters: "王明" → "王小明"
 // - Reversed order: "明王" → "王小明"
 // - Character-set membership: "資工" → "資訊工程"
 func (h *Handler) handleStudentNameQuery(ctx context.Context, name string) []messaging_api.MessageInterface {
-	log := h.logger.WithModule(ModuleName)
+	return h.handleStudentNamePage(ctx, name, "")
+}
+
+// handleStudentNamePage lists the page of a student name search that starts
+// at cursor ("" for the first page).
+func (h *Handler) handleStudentNamePage(ctx context.Context, name, cursor string) []messaging_api.MessageInterface {
+	log := logger.FromContext(ctx)
 	sender := lineutil.GetSender(senderName, h.stickerManager)
 
 	// Search using character-set matching (application layer)
 	// Supports non-contiguous character matching: "王明" matches "王小明"
-	// Returns total count and limited results (up to 400)
-	result, err := h.db.SearchStudentsByName(ctx, name)
+	// Returns total count and one page of results (up to 400)
+	result, err := h.db.SearchStudentsByNamePage(ctx, name, storage.Page{Limit: storage.StudentSearchLimit, Cursor: cursor})
 	if err != nil {
 		log.WithError(err).ErrorContext(ctx, "Failed to search students by name")
 		return []messaging_api.MessageInterface{
 			lineutil.ErrorMessageWithQuickReply("搜尋姓名時發生問題", sender, "學號 "+name),
 		}
 	}
-	students := result.Students
+	students := result.Items
 	totalCount := result.TotalCount
 
 	if len(students) == 0 {
-		msg := lineutil.NewTextMessageWithConsistentSender(fmt.Sprintf(config.IDNotFoundWithCutoffHint, name), sender)
+		h.metrics.RecordZeroResults(ModuleName)
+		msg := lineutil.NewTextMessageWithConsistentSender(fmt.Sprintf(data.Availability.Students.NotFoundMessage, name), sender)
 		msg.QuickReply = lineutil.NewQuickReply([]lineutil.QuickReplyItem{
 			lineutil.QuickReplyStudentAction(),
 			lineutil.QuickReplyYearAction(),
@@ -1170,7 +1155,7 @@ func (h *Handler) handleStudentNameQuery(ctx context.Context, name string) []mes
 	// Character-set matching strategy (application layer):
 	// 1. Search all students using ContainsAllRunes (supports "王明" → "王小明")
 	// 2. Get accurate total count of all matches
-	// 3. Return first 400 students (sorted by year DESC, id DESC)
+	// 3. Return a page of up to 400 students (sorted by year DESC, id DESC)
 	// 4. Display all returned students (4 messages × 100 students), reserve 5th message for meta info
 
 	// Format student list - up to 4 messages (100 students per message)
@@ -1186,7 +1171,7 @@ func (h *Handler) handleStudentNameQuery(ctx context.Context, name string) []mes
 		end := min(i+studentsPerMessage, displayCount)
 
 		var builder strings.Builder
-		fmt.Fprintf(&builder, "📋 搜尋結果（第 %d-%d 筆，共 %d 筆）\n\n", i+1, end, totalCount)
+		fmt.Fprintf(&builder, "📋 搜尋結果（第 %d-%d 筆，共 %d 筆）\n\n", result.Offset+i+1, result.Offset+end, totalCount)
 
 		for j := i; j < end; j++ {
 			student := students[j]
@@ -1216,10 +1201,17 @@ func (h *Handler) handleStudentNameQuery(ctx context.Context, name string) []mes
 	// 5th message: Always add disclaimer, with optional warning if results exceed display limit
 	var infoBuilder strings.Builder
 
-	// Add warning if we have more results than displayed
-	if totalCount > maxDisplayStudents {
-		infoBuilder.WriteString("⚠️ 搜尋結果達到顯示上限\n\n")
-		fmt.Fprintf(&infoBuilder, "已顯示前 %d 筆結果（共找到 %d 筆），建議：\n", maxDisplayStudents, totalCount)
+	// Point to the next page if the results did not fit
+	var nextPage *lineutil.QuickReplyItem
+	if result.NextCursor != "" {
+		h.metrics.RecordTruncated(ModuleName)
+		fmt.Fprintf(&infoBuilder, "📄 已顯示第 %d-%d 筆（共找到 %d 筆）\n\n", result.Offset+1, result.Offset+displayCount, totalCount)
+		if pb, err := namePagePostback(name, result.NextCursor); err == nil {
+			nextPage = &lineutil.QuickReplyItem{Action: lineutil.NewPostbackActionWithDisplayText("➡️ 下一頁", "查看「"+name+"」下一頁", pb)}
+			infoBuilder.WriteString("點「➡️ 下一頁」看後續結果，或：\n")
+		} else {
+			infoBuilder.WriteString("建議：\n")
+		}
 		infoBuilder.WriteString("• 輸入更完整的姓名\n")
 		infoBuilder.WriteString("• 使用「學年」功能按年度查詢\n\n")
 		infoBuilder.WriteString("────────────────\n\n")
@@ -1228,17 +1220,20 @@ func (h *Handler) handleStudentNameQuery(ctx context.Context, name string) []mes
 	// Always add department inference disclaimer
 	infoBuilder.WriteString("ℹ️ 系所資訊說明\n")
 	infoBuilder.WriteString("系所資訊由學號推測，可能與實際不符。\n\n")
-	infoBuilder.WriteString("📊 姓名查詢範圍\n")
-	infoBuilder.WriteString("• 學士班/碩博士班：101-112 學年度\n")
-	infoBuilder.WriteString("• 113 學年度資料不完整\n")
-	infoBuilder.WriteString("• 114 學年度起無資料\n\n")
+	if summary := data.Availability.Students.Summary; summary != "" {
+		infoBuilder.WriteString(summary + "\n\n")
+	}
 	infoBuilder.WriteString("💡 若找不到學生，可使用「學年」功能按年度查詢")
 
 	infoMsg := lineutil.NewTextMessageWithConsistentSender(infoBuilder.String(), sender)
 	messages = append(messages, infoMsg)
 
 	// Add Quick Reply to the last message (5th message)
-	lineutil.AddQuickReplyToMessages(messages, lineutil.QuickReplyStudentNav()...)
+	items := lineutil.QuickReplyStudentNav()
+	if nextPage != nil {
+		items = append([]lineutil.QuickReplyItem{*nextPage}, items...)
+	}
+	lineutil.AddQuickReplyToMessages(messages, items...)
 
 	return messages
 }
@@ -1266,10 +1261,17 @@ func (h *Handler) formatStudentResponse(student *storage.Student) []messaging_ap
 	}).FlexBox)
 
 	// 學號 info - first row (no separator so it flows directly after the label)
-	firstInfoRow := lineutil.NewInfoRow("🆔", "學號", student.ID, lineutil.BoldInfoRowStyle())
+	firstInfoRow := lineutil.NewInfoRow(lineutil.Icon(lineutil.IconStudentID), "學號", student.ID, lineutil.BoldInfoRowStyle())
 	body.AddComponent(firstInfoRow.FlexBox)
-	body.AddInfoRow("🏫", "系所", student.Department, lineutil.BoldInfoRowStyle())
-	body.AddInfoRow("📅", "入學學年", fmt.Sprintf("%d 學年度", student.Year), lineutil.BoldInfoRowStyle())
+	body.AddInfoRow(lineutil.Icon(lineutil.IconDepartment), "系所", student.Department, lineutil.BoldInfoRowStyle())
+	if college, ok := data.Campus.CollegeOfDepartment(student.Department); ok {
+		body.AddInfoRow(lineutil.Icon(lineutil.IconDepartment), "學院", college.Name, lineutil.BoldInfoRowStyle())
+	}
+	body.AddInfoRow(lineutil.Icon(lineutil.IconYear), "入學學年", fmt.Sprintf("%d 學年度", student.Year), lineutil.BoldInfoRowStyle())
+	status := graduationStatus(student.ID, student.Year, time.Now())
+	if status != "" {
+		body.AddInfoRow(lineutil.Icon(lineutil.IconNote), "狀態", status, lineutil.BoldInfoRowStyle())
+	}
 
 	// Add department inference note (transparency about data limitations)
 	body.AddComponent(lineutil.NewFlexText("⚠️ 系所由學號推測，可能與實際不符").
@@ -1277,6 +1279,12 @@ func (h *Handler) formatStudentResponse(student *storage.Student) []messaging_ap
 		WithColor(lineutil.ColorNote).
 		WithWrap(true).
 		WithMargin("md").FlexText)
+	if status != "" {
+		body.AddComponent(lineutil.NewFlexText(graduationDisclaimer).
+			WithSize("xs").
+			WithColor(lineutil.ColorNote).
+			WithWrap(true).FlexText)
+	}
 
 	// Add cache time hint (unobtrusive, right-aligned)
 	if hint := lineutil.NewCacheTimeHint(student.CachedAt); hint != nil {
@@ -1314,6 +1322,23 @@ func (h *Handler) formatStudentResponse(student *storage.Student) []messaging_ap
 // Helper functions
 // Note: isNumeric has been moved to internal/stringutil package
 
+// latestStudentYear returns the newest year with complete student data,
+// the year quick replies suggest.
+func latestStudentYear() int {
+	return data.Availability.Students.LatestCompleteYear(time.Now().Year() - 1911)
+}
+
+// firstCompleteStudentYear returns the oldest year with complete student data.
+func firstCompleteStudentYear() int {
+	from, _ := data.Availability.Students.CompleteYears()
+	return from
+}
+
+// yearQueryAction returns a quick reply item that queries the students of year.
+func yearQueryAction(year int) lineutil.QuickReplyItem {
+	return lineutil.QuickReplyItem{Action: lineutil.NewMessageAction(fmt.Sprintf("📅 查詢 %d 學年度", year), fmt.Sprintf("學年 %d", year))}
+}
+
 // parseYear parses a year string (2-4 digits) to ROC year
 // Only validates format, not range (range validation is done in handleYearQuery for proper error messages)
 func parseYear(yearStr string) (int, error) {
@@ -1337,29 +1362,23 @@ func parseYear(yearStr string) (int, error) {
 // handleCollegeGroupSelection handles college group selection (文法商 or 公社電資)
 func (h *Handler) handleCollegeGroupSelection(group, year string) []messaging_api.MessageInterface {
 	sender := lineutil.GetSender(senderName, h.stickerManager)
-	var actions []messaging_api.ActionInterface
-	var collegeList string
-
-	if group == "文法商" {
-		collegeList = "📖 人文：中文、應外、歷史\n⚖️ 法律：法學、司法、財法\n💼 商學：企管、金融、會計、統計、休運"
-		actions = []messaging_api.ActionInterface{
-			lineutil.NewPostbackActionWithDisplayText("📖 人文學院", fmt.Sprintf("查詢 %s 學年度人文學院", year), fmt.Sprintf("id:人文學院%s%s", bot.PostbackSplitChar, year)),
-			lineutil.NewPostbackActionWithDisplayText("⚖️ 法律學院", fmt.Sprintf("查詢 %s 學年度法律學院", year), fmt.Sprintf("id:法律學院%s%s", bot.PostbackSplitChar, year)),
-			lineutil.NewPostbackActionWithDisplayText("💼 商學院", fmt.Sprintf("查詢 %s 學年度商學院", year), fmt.Sprintf("id:商學院%s%s", bot.PostbackSplitChar, year)),
-		}
-	} else { // 公社電資
-		collegeList = "🏛️ 公共事務：公行、不動、財政\n👥 社科：經濟、社學、社工\n💻 電資：電機、資工、通訊"
-		actions = []messaging_api.ActionInterface{
-			lineutil.NewPostbackActionWithDisplayText("🏛️ 公共事務學院", fmt.Sprintf("查詢 %s 學年度公共事務學院", year), fmt.Sprintf("id:公共事務學院%s%s", bot.PostbackSplitChar, year)),
-			lineutil.NewPostbackActionWithDisplayText("👥 社會科學學院", fmt.Sprintf("查詢 %s 學年度社會科學學院", year), fmt.Sprintf("id:社會科學學院%s%s", bot.PostbackSplitChar, year)),
-			lineutil.NewPostbackActionWithDisplayText("💻 電機資訊學院", fmt.Sprintf("查詢 %s 學年度電機資訊學院", year), fmt.Sprintf("id:電機資訊學院%s%s", bot.PostbackSplitChar, year)),
-		}
+	groupColleges := data.Campus.CollegesInGroup(group)
+
+	lines := make([]string, 0, len(groupColleges))
+	actions := make([]messaging_api.ActionInterface, 0, len(groupColleges))
+	for _, c := range groupColleges {
+		lines = append(lines, fmt.Sprintf("%s %s：%s", c.Emoji, c.ShortName, strings.Join(c.Departments, "、")))
+		actions = append(actions, lineutil.NewPostbackActionWithDisplayText(
+			c.Emoji+" "+c.Name,
+			fmt.Sprintf("查詢 %s 學年度%s", year, c.Name),
+			collegePostback(c.Name, year),
+		))
 	}
 
 	msg := lineutil.NewButtonsTemplate(
 		fmt.Sprintf("%s 學年度 %s", year, group),
 		fmt.Sprintf("%s 學年度・%s", year, group),
-		fmt.Sprintf("請選擇學院\n\n%s", collegeList),
+		fmt.Sprintf("請選擇學院\n\n%s", strings.Join(lines, "\n")),
 		actions,
 	)
 
@@ -1370,42 +1389,7 @@ func (h *Handler) handleCollegeGroupSelection(group, year string) []messaging_ap
 
 // handleCollegeSelection handles specific college selection
 func (h *Handler) handleCollegeSelection(college, year string) []messaging_api.MessageInterface {
-	// College to departments mapping
-	collegeMap := map[string]struct {
-		imageURL    string
-		departments []string
-		isLaw       bool
-	}{
-		"人文學院": {
-			imageURL:    "https://walkinto.in/upload/-192z7YDP8-JlchfXtDvI.JPG",
-			departments: []string{"中文", "應外", "歷史"},
-			isLaw:       false,
-		},
-		"法律學院": {
-			imageURL:    "https://walkinto.in/upload/byupdk9PvIZyxupOy9Dw8.JPG",
-			departments: []string{"法學", "司法", "財法"},
-			isLaw:       true,
-		},
-		"商學院": {
-			imageURL:    "https://walkinto.in/upload/ZJum7EYwPUZkedmXNtvPL.JPG",
-			departments: []string{"企管", "金融", "會計", "統計", "休運"},
-		},
-		"公共事務學院": {
-			imageURL:    "https://walkinto.in/upload/ZJhs4wEaDIWklhiVwV6DI.jpg",
-			departments: []string{"公行", "不動", "財政"},
-		},
-		"社會科學學院": {
-			imageURL:    "https://walkinto.in/upload/WyPbshN6DIZ1gvZo2NTvU.JPG",
-			departments: []string{"經濟", "社學", "社工"},
-			isLaw:       false,
-		},
-		"電機資訊學院": {
-			imageURL:    "https://walkinto.in/upload/bJ9zWWHaPLWJg9fW-STD8.png",
-			departments: []string{"電機", "資工", "通訊"},
-		},
-	}
-
-	info, ok := collegeMap[college]
+	info, ok := data.Campus.CollegeByName(college)
 	if !ok {
 		sender := lineutil.GetSender(senderName, h.stickerManager)
 		msg := lineutil.NewTextMessageWithConsistentSender("❌ 無效的學院選擇\n\n請重新選擇學年度後操作", sender)
@@ -1417,7 +1401,7 @@ func (h *Handler) handleCollegeSelection(college, year string) []messaging_api.M
 		return []messaging_api.MessageInterface{msg}
 	}
 
-	return h.buildDepartmentSelectionTemplate(year, info.imageURL, info.departments, info.isLaw)
+	return h.buildDepartmentSelectionTemplate(year, data.Assets.URL(data.CollegeAsset(info.ShortName)), info.Departments, info.IsLaw)
 }
 
 // buildDepartmentSelectionTemplate creates department selection template
@@ -1457,7 +1441,7 @@ func (h *Handler) buildDepartmentSelectionTemplate(year, imageURL string, depart
 		actions = append(actions, lineutil.NewPostbackActionWithDisplayText(
 			label,
 			displayText,
-			fmt.Sprintf("id:%s%s%s", deptCode, bot.PostbackSplitChar, year),
+			departmentPostback(deptCode, year),
 		))
 	}
 
@@ -1508,7 +1492,7 @@ func (h *Handler) buildDepartmentSelectionTemplate(year, imageURL string, depart
 
 // handleDepartmentSelection handles final department selection and queries the database
 func (h *Handler) handleDepartmentSelection(ctx context.Context, deptCode, yearStr string) []messaging_api.MessageInterface {
-	log := h.logger.WithModule(ModuleName)
+	log := logger.FromContext(ctx)
 	sender := lineutil.GetSender(senderName, h.stickerManager)
 
 	year, err := strconv.Atoi(yearStr)
@@ -1564,7 +1548,8 @@ func (h *Handler) handleDepartmentSelection(ctx context.Context, deptCode, yearS
 		log.WithField("year", year).
 			WithField("dept_code", deptCode).
 			DebugContext(ctx, "Department selection cache miss, scraping")
-		h.metrics.RecordCacheMiss(ModuleName)
+		h.metrics.RecordCacheMiss(ctx, ModuleName)
+		lineutil.ShowLoading(ctx)
 		startTime := time.Now()
 
 		scrapedStudents, err := ntpu.ScrapeStudentsByYear(ctx, h.scraper, year, deptCode, ntpu.StudentTypeUndergrad)
@@ -1594,7 +1579,7 @@ func (h *Handler) handleDepartmentSelection(ctx context.Context, deptCode, yearS
 			h.metrics.RecordScraperRequest(ModuleName, "success", time.Since(startTime).Seconds())
 			// Save to cache and convert to value slice
 			for _, s := range scrapedStudents {
-				if err := h.db.SaveStudent(ctx, s); err != nil {
+				if err := h.saveStudent(ctx, s); err != nil {
 					log.WithError(err).WarnContext(ctx, "Failed to save student to cache")
 				}
 				students = append(students, *s)
@@ -1603,23 +1588,24 @@ func (h *Handler) handleDepartmentSelection(ctx context.Context, deptCode, yearS
 			h.metrics.RecordScraperRequest(ModuleName, "not_found", time.Since(startTime).Seconds())
 		}
 	} else {
-		h.metrics.RecordCacheHit(ModuleName)
+		h.metrics.RecordCacheHit(ctx, ModuleName)
 	}
 
 	if len(students) == 0 {
+		h.metrics.RecordZeroResults(ModuleName)
 		departmentType := "系"
 		if ntpu.IsLawDepartment(deptCode) {
 			departmentType = "組"
 		}
-		// Special message for year 113 (incomplete data)
-		// Year 114+ would have been rejected in handleYearQuery
-		if year == config.IDDataYearEnd+1 {
+		// Special message for years with a notice (113, incomplete data)
+		// Years without data would have been rejected in handleYearQuery
+		if period, _ := data.Availability.Students.Period(year); period.Notice != "" {
 			msg := lineutil.NewTextMessageWithConsistentSender(
-				fmt.Sprintf(config.ID113YearEmptyMessage, deptName+departmentType),
+				fmt.Sprintf("🔍 查無 %d 學年度「%s」的學生資料\n\n%s", year, deptName+departmentType, period.Notice),
 				sender,
 			)
 			msg.QuickReply = lineutil.NewQuickReply([]lineutil.QuickReplyItem{
-				{Action: lineutil.NewMessageAction(fmt.Sprintf("📅 查詢 %d 學年度", config.IDDataYearEnd), fmt.Sprintf("學年 %d", config.IDDataYearEnd))},
+				yearQueryAction(latestStudentYear()),
 				lineutil.QuickReplyYearAction(),
 				lineutil.QuickReplyHelpAction(),
 			})
@@ -2,45 +2,21 @@ package id
 
 import (
 	"context"
-	"path/filepath"
+	"fmt"
+	"slices"
 	"strings"
 	"testing"
-	"time"
 
-	"github.com/garyellow/ntpu-linebot-go/internal/logger"
-	"github.com/garyellow/ntpu-linebot-go/internal/metrics"
-	"github.com/garyellow/ntpu-linebot-go/internal/scraper"
-	"github.com/garyellow/ntpu-linebot-go/internal/sticker"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/moduletest"
 	"github.com/garyellow/ntpu-linebot-go/internal/storage"
 	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
-	"github.com/prometheus/client_golang/prometheus"
 )
 
 func setupTestHandler(t *testing.T) *Handler {
 	t.Helper()
 
-	// Use a unique temp file database for each test to avoid shared memory conflicts
-	// when running t.Parallel() tests. The temp directory is automatically cleaned up.
-	tmpDir := t.TempDir()
-	dbPath := filepath.Join(tmpDir, "test.db")
-	db, err := storage.New(context.Background(), dbPath, 168*time.Hour)
-	if err != nil {
-		t.Fatalf("Failed to create test database: %v", err)
-	}
-	t.Cleanup(func() { _ = db.Close(context.Background()) })
-
-	baseURLs := map[string][]string{
-		"lms": {"https://lms.ntpu.edu.tw"},
-		"sea": {"https://sea.cc.ntpu.edu.tw"},
-	}
-	scraperClient := scraper.NewClient(30*time.Second, 3, baseURLs)
-
-	registry := prometheus.NewRegistry()
-	m := metrics.New(registry)
-	log := logger.New("info")
-	stickerManager := sticker.NewManager(db, scraperClient, log)
-
-	return NewHandler(db, scraperClient, m, log, stickerManager, nil)
+	kit := moduletest.New(t, moduletest.Options{})
+	return NewHandler(kit.DB, kit.Scraper, kit.Metrics, kit.Logger, kit.Stickers, nil, nil, false)
 }
 
 func TestCanHandle(t *testing.T) {
@@ -342,14 +318,7 @@ func TestHandleDepartmentCodesByDegree(t *testing.T) {
 			if len(msgs) == 0 {
 				t.Fatal("Expected response message")
 			}
-			// Verify text message contains expected title
-			textMsg, ok := msgs[0].(*messaging_api.TextMessageV2)
-			if !ok {
-				t.Fatal("Expected TextMessageV2")
-			}
-			if !strings.Contains(textMsg.Text, tt.wantTitle) {
-				t.Errorf("Response should contain %q, got: %s", tt.wantTitle, textMsg.Text)
-			}
+			moduletest.AssertTextContains(t, msgs, tt.wantTitle)
 		})
 	}
 }
@@ -583,3 +552,84 @@ func TestHandleYearQuery_ADtoROC(t *testing.T) {
 		})
 	}
 }
+
+func TestDepartmentQuickReply_DeptNews(t *testing.T) {
+	t.Parallel()
+	kit := moduletest.New(t, moduletest.Options{})
+	h := NewHandler(kit.DB, kit.Scraper, kit.Metrics, kit.Logger, kit.Stickers, nil, nil, true)
+	ctx := context.Background()
+
+	for _, input := range []string{"系代碼 85", "系 資工"} {
+		labels := moduletest.QuickReplyLabels(t, h.HandleMessage(ctx, input))
+		if len(labels) == 0 || labels[0] != "📰 系上公告" {
+			t.Errorf("%q quick replies = %v, want 📰 系上公告 first", input, labels)
+		}
+	}
+
+	// Master's programs have no department website entry of their own
+	if labels := moduletest.QuickReplyLabels(t, h.HandleMessage(ctx, "系代碼 31")); slices.Contains(labels, "📰 系上公告") {
+		t.Errorf("系代碼 31 quick replies = %v, want no 系上公告", labels)
+	}
+	// Disabled: the default test handler has no button
+	if labels := moduletest.QuickReplyLabels(t, setupTestHandler(t).HandleMessage(ctx, "系代碼 85")); slices.Contains(labels, "📰 系上公告") {
+		t.Errorf("quick replies = %v, want no 系上公告 when news is disabled", labels)
+	}
+}
+
+func TestStudentsPostback(t *testing.T) {
+	t.Parallel()
+	h := setupTestHandler(t)
+	ctx := context.Background()
+
+	if err := h.db.SaveStudent(ctx, &storage.Student{ID: "411285001", Name: "王小明", Year: 112, Department: "資工系"}); err != nil {
+		t.Fatalf("SaveStudent failed: %v", err)
+	}
+	if msgs := h.HandlePostback(ctx, StudentsPostback("85", 112)); len(msgs) == 0 {
+		t.Error("Expected the student list for a department postback")
+	}
+
+	// The law department opens its group selection
+	msgs := h.HandlePostback(ctx, StudentsPostback("71", 112))
+	if len(msgs) != 1 {
+		t.Fatalf("Expected 1 message for the law department, got %d", len(msgs))
+	}
+	if _, ok := msgs[0].(*messaging_api.TemplateMessage); !ok {
+		t.Errorf("Expected the group selection template, got %T", msgs[0])
+	}
+}
+
+func TestHandleStudentNameQuery_NextPage(t *testing.T) {
+	t.Parallel()
+	h := setupTestHandler(t)
+	ctx := context.Background()
+
+	students := make([]*storage.Student, storage.StudentSearchLimit+5)
+	for i := range students {
+		students[i] = &storage.Student{ID: fmt.Sprintf("41128%04d", i), Name: "王小明", Year: 112, Department: "資工系"}
+	}
+	if err := h.db.SaveStudentsBatch(ctx, students); err != nil {
+		t.Fatalf("SaveStudentsBatch failed: %v", err)
+	}
+
+	// The first page ends with a 下一頁 Quick Reply carrying the cursor
+	msgs := h.handleStudentNameQuery(ctx, "王小明")
+	last, ok := msgs[len(msgs)-1].(*messaging_api.TextMessageV2)
+	if !ok || last.QuickReply == nil {
+		t.Fatalf("last message = %T without Quick Reply, want the info message", msgs[len(msgs)-1])
+	}
+	action, ok := last.QuickReply.Items[0].Action.(*messaging_api.PostbackAction)
+	if !ok || !strings.Contains(action.Label, "下一頁") {
+		t.Fatalf("first Quick Reply = %+v, want 下一頁", last.QuickReply.Items[0].Action)
+	}
+
+	// The next page lists the remaining students and offers no further page
+	next := h.HandlePostback(ctx, action.Data)
+	first, ok := next[0].(*messaging_api.TextMessageV2)
+	if !ok || !strings.Contains(first.Text, "第 401-405 筆，共 405 筆") {
+		t.Errorf("next page = %+v, want students 401-405", next[0])
+	}
+	info := next[len(next)-1].(*messaging_api.TextMessageV2)
+	if strings.Contains(info.Text, "下一頁") {
+		t.Error("last page still offers 下一頁")
+	}
+}
@@ -0,0 +1,212 @@
+package id
+
+import (
+	"context"
+	"slices"
+	"strconv"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/bot"
+	"github.com/garyellow/ntpu-linebot-go/internal/data"
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/scraper/ntpu"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+// Postback actions for the ID module.
+// The scold and year actions keep their legacy names; college and department
+// payloads used the value itself as the action and are still routed below.
+const (
+	// PostbackActionScold is the "我在想想" easter egg on the year confirmation.
+	PostbackActionScold = "兇"
+	// PostbackActionYearAll starts the college/department flow. Params: year.
+	PostbackActionYearAll = "搜尋全系"
+	// PostbackActionCollegeGroup selects a college group. Params: group, year.
+	PostbackActionCollegeGroup = "college_group"
+	// PostbackActionCollege selects a college. Params: college, year.
+	PostbackActionCollege = "college"
+	// PostbackActionDepartment lists students of a department. Params: code, year.
+	PostbackActionDepartment = "dept"
+	// PostbackActionNamePage lists the next page of a name search. Params: name, cursor.
+	PostbackActionNamePage = "name_page"
+)
+
+// collegeGroups are the valid college group names (also legacy action names).
+var collegeGroups = []string{"文法商", "公社電資"}
+
+// colleges are the valid college names (also legacy action names), taken
+// from the embedded campus dataset.
+var colleges = collegeNames()
+
+func collegeNames() []string {
+	names := make([]string, len(data.Campus.Colleges))
+	for i, c := range data.Campus.Colleges {
+		names[i] = c.Name
+	}
+	return names
+}
+
+func scoldPostback() string {
+	return bot.NewPostback(ModuleName, PostbackActionScold).String()
+}
+
+func yearAllPostback(year string) string {
+	return bot.NewPostback(ModuleName, PostbackActionYearAll).With("year", year).String()
+}
+
+func collegeGroupPostback(group, year string) string {
+	return bot.NewPostback(ModuleName, PostbackActionCollegeGroup).
+		With("group", group).
+		With("year", year).
+		String()
+}
+
+func collegePostback(college, year string) string {
+	return bot.NewPostback(ModuleName, PostbackActionCollege).
+		With("college", college).
+		With("year", year).
+		String()
+}
+
+func departmentPostback(deptCode, year string) string {
+	return bot.NewPostback(ModuleName, PostbackActionDepartment).
+		With("code", deptCode).
+		With("year", year).
+		String()
+}
+
+// namePagePostback returns postback data for the page of a student name
+// search at cursor. It fails when the name overflows the postback limit.
+func namePagePostback(name, cursor string) (string, error) {
+	return bot.NewPostback(ModuleName, PostbackActionNamePage).
+		With("name", name).
+		With("cursor", cursor).
+		Encode()
+}
+
+// StudentsPostback returns postback data that lists the students of a
+// department who entered in year. The law department (71) opens its group
+// selection instead, since students are listed per group.
+func StudentsPostback(deptCode string, year int) string {
+	y := strconv.Itoa(year)
+	if deptCode == ntpu.DepartmentCodes["法律"] {
+		for _, c := range data.Campus.Colleges {
+			if c.IsLaw {
+				return collegePostback(c.Name, y)
+			}
+		}
+	}
+	return departmentPostback(deptCode, y)
+}
+
+// newPostbackRouter wires ID postback actions to handler methods.
+func (h *Handler) newPostbackRouter() *bot.PostbackRouter {
+	r := bot.NewPostbackRouter(ModuleName).
+		Handle(PostbackActionScold, func(_ context.Context, _ *bot.Postback) []messaging_api.MessageInterface {
+			sender := lineutil.GetSender(senderName, h.stickerManager)
+			return []messaging_api.MessageInterface{
+				lineutil.NewTextMessageWithConsistentSender("泥好兇喔～～(⊙﹏⊙)", sender),
+			}
+		}).
+		Handle(PostbackActionYearAll, func(_ context.Context, pb *bot.Postback) []messaging_api.MessageInterface {
+			year, ok := postbackYear(pb)
+			if !ok {
+				return []messaging_api.MessageInterface{}
+			}
+			return h.handleYearSearchConfirm(year)
+		}).
+		Handle(PostbackActionCollegeGroup, func(_ context.Context, pb *bot.Postback) []messaging_api.MessageInterface {
+			group := pb.Get("group")
+			if !slices.Contains(collegeGroups, group) {
+				return []messaging_api.MessageInterface{}
+			}
+			return h.handleCollegeGroupSelection(group, pb.Get("year"))
+		}).
+		Handle(PostbackActionCollege, func(_ context.Context, pb *bot.Postback) []messaging_api.MessageInterface {
+			college := pb.Get("college")
+			if !slices.Contains(colleges, college) {
+				return []messaging_api.MessageInterface{}
+			}
+			return h.handleCollegeSelection(college, pb.Get("year"))
+		}).
+		Handle(PostbackActionDepartment, func(ctx context.Context, pb *bot.Postback) []messaging_api.MessageInterface {
+			return h.postbackDepartment(ctx, pb.Get("code"), pb.Get("year"))
+		}).
+		Handle(PostbackActionNamePage, func(ctx context.Context, pb *bot.Postback) []messaging_api.MessageInterface {
+			name, cursor := pb.Get("name"), pb.Get("cursor")
+			if name == "" || cursor == "" {
+				return []messaging_api.MessageInterface{}
+			}
+			return h.handleStudentNamePage(ctx, name, cursor)
+		}).
+		// Legacy "id:{deptCode}${year}" payloads carry the code as the action segment.
+		Fallback(func(ctx context.Context, pb *bot.Postback) []messaging_api.MessageInterface {
+			year, ok := postbackYear(pb)
+			if !ok {
+				return []messaging_api.MessageInterface{}
+			}
+			return h.postbackDepartment(ctx, pb.Action, year)
+		})
+
+	// Legacy college group / college payloads used the name as the action.
+	for _, group := range collegeGroups {
+		r.Handle(group, func(_ context.Context, pb *bot.Postback) []messaging_api.MessageInterface {
+			year, ok := postbackYear(pb)
+			if !ok {
+				return []messaging_api.MessageInterface{}
+			}
+			return h.handleCollegeGroupSelection(group, year)
+		})
+	}
+	for _, college := range colleges {
+		r.Handle(college, func(_ context.Context, pb *bot.Postback) []messaging_api.MessageInterface {
+			year, ok := postbackYear(pb)
+			if !ok {
+				return []messaging_api.MessageInterface{}
+			}
+			return h.handleCollegeSelection(college, year)
+		})
+	}
+
+	return r
+}
+
+// postbackYear returns the year parameter from a typed or legacy payload.
+// Legacy payloads must carry exactly one positional parameter.
+func postbackYear(pb *bot.Postback) (string, bool) {
+	if pb.Version > 0 {
+		year := pb.Get("year")
+		return year, year != ""
+	}
+	if len(pb.Args) != 1 {
+		return "", false
+	}
+	return pb.Args[0], true
+}
+
+// postbackDepartment validates a department code before listing its students.
+func (h *Handler) postbackDepartment(ctx context.Context, deptCode, year string) []messaging_api.MessageInterface {
+	// Validate department code format (1-3 digits) before lookup
+	if len(deptCode) > 3 || len(deptCode) == 0 {
+		return h.invalidDeptCodeMessage()
+	}
+
+	// Verify department code contains only digits
+	if _, err := strconv.Atoi(deptCode); err != nil {
+		return h.invalidDeptCodeMessage()
+	}
+
+	if _, ok := ntpu.DepartmentNames[deptCode]; ok {
+		return h.handleDepartmentSelection(ctx, deptCode, year)
+	}
+	return []messaging_api.MessageInterface{}
+}
+
+func (h *Handler) invalidDeptCodeMessage() []messaging_api.MessageInterface {
+	sender := lineutil.GetSender(senderName, h.stickerManager)
+	msg := lineutil.NewTextMessageWithConsistentSender(
+		"❌ 無效的系代碼格式\n\n系代碼應為 1-3 位數字",
+		sender,
+	)
+	msg.QuickReply = lineutil.NewQuickReply(lineutil.QuickReplyStudentNav())
+	return []messaging_api.MessageInterface{msg}
+}
@@ -0,0 +1,121 @@
+// Package leaderboard implements the group leaderboard module for the LINE bot.
+// Groups that opt in with 開啟排行榜 have their course queries counted per
+// week, and every Monday morning the bot posts the most-queried courses and
+// teachers (本群最常查的課). 關閉排行榜 stops the posts and deletes the counts.
+package leaderboard
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/bot"
+	"github.com/garyellow/ntpu-linebot-go/internal/ctxutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/sticker"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+// Module constants
+const (
+	ModuleName = "leaderboard"
+	senderName = "排行榜小幫手"
+)
+
+// Commands (exact match after sanitization and mention removal)
+const (
+	enableKeyword  = "開啟排行榜"
+	disableKeyword = "關閉排行榜"
+)
+
+var showKeywords = []string{"排行榜", "本群排行榜"}
+
+// Handler answers the leaderboard commands in group chats.
+type Handler struct {
+	bot.NoPostbacks // Commands are plain text
+
+	store          *Store
+	logger         *logger.Logger
+	stickerManager *sticker.Manager
+}
+
+// NewHandler creates a new leaderboard handler.
+func NewHandler(store *Store, logger *logger.Logger, stickerManager *sticker.Manager) *Handler {
+	return &Handler{
+		store:          store,
+		logger:         logger,
+		stickerManager: stickerManager,
+	}
+}
+
+// Name returns the module name
+func (h *Handler) Name() string {
+	return ModuleName
+}
+
+// CanHandle returns true for the leaderboard commands.
+func (h *Handler) CanHandle(text string) bool {
+	text = strings.TrimSpace(text)
+	return text == enableKeyword || text == disableKeyword || slices.Contains(showKeywords, text)
+}
+
+// HandleMessage runs a leaderboard command for the current group.
+func (h *Handler) HandleMessage(ctx context.Context, text string) []messaging_api.MessageInterface {
+	log := logger.FromContext(ctx)
+	sender := lineutil.GetSender(senderName, h.stickerManager)
+	text = strings.TrimSpace(text)
+
+	// Personal chats have chat ID == user ID; rooms count as groups
+	groupID := ctxutil.GetChatID(ctx)
+	if groupID == "" || groupID == ctxutil.GetUserID(ctx) {
+		return lineutil.TextReply(sender, "👥 排行榜僅限群組使用\n\n把我加入群組後，標記我並輸入「"+enableKeyword+"」即可開啟")
+	}
+
+	switch text {
+	case enableKeyword:
+		added, err := h.store.Enable(ctx, groupID)
+		if err != nil {
+			log.WithError(err).ErrorContext(ctx, "Failed to enable group leaderboard")
+			return lineutil.TextReply(sender, "❌ 開啟排行榜失敗，請稍後再試")
+		}
+		if !added {
+			return lineutil.TextReply(sender, "📊 本群已開啟排行榜\n\n標記我並輸入「排行榜」可查看本週統計")
+		}
+		return lineutil.TextReply(sender, "📊 已開啟本群排行榜\n\n"+
+			"• 統計本群的課程查詢（課名或老師）\n"+
+			"• 每週一早上 9 點公布上週最常查的課\n"+
+			"• 只記錄查詢內容與次數，不記錄是誰查的\n\n"+
+			"標記我並輸入「"+disableKeyword+"」可關閉並刪除統計")
+
+	case disableKeyword:
+		if err := h.store.Disable(ctx, groupID); err != nil {
+			log.WithError(err).ErrorContext(ctx, "Failed to disable group leaderboard")
+			return lineutil.TextReply(sender, "❌ 關閉排行榜失敗，請稍後再試")
+		}
+		return lineutil.TextReply(sender, "🔕 已關閉本群排行榜並刪除統計")
+	}
+
+	enabled, err := h.store.Enabled(ctx, groupID)
+	if err != nil {
+		log.WithError(err).ErrorContext(ctx, "Failed to check group leaderboard")
+		return lineutil.TextReply(sender, "❌ 讀取排行榜失敗，請稍後再試")
+	}
+	if !enabled {
+		return lineutil.TextReply(sender, "📊 本群尚未開啟排行榜\n\n標記我並輸入「"+enableKeyword+"」即可開啟")
+	}
+
+	top, err := h.store.Top(ctx, groupID, WeekKey(time.Now()), TopN)
+	if err != nil {
+		log.WithError(err).ErrorContext(ctx, "Failed to load group leaderboard")
+		return lineutil.TextReply(sender, "❌ 讀取排行榜失敗，請稍後再試")
+	}
+	if len(top) == 0 {
+		return lineutil.TextReply(sender, "📊 本週還沒有人查課\n\n例如標記我並輸入「課程 資料結構」")
+	}
+	return lineutil.TextReply(sender, formatRanking("📊 本群本週最常查的課", top))
+}
+
+// Ensure Handler implements bot.Handler
+var _ bot.Handler = (*Handler)(nil)
@@ -0,0 +1,80 @@
+package leaderboard
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/ctxutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/moduletest"
+	"github.com/garyellow/ntpu-linebot-go/internal/sticker"
+)
+
+func newTestHandler(t *testing.T) *Handler {
+	t.Helper()
+	log := logger.New("error")
+	return NewHandler(moduletest.OpenStore(t, Open), log, sticker.NewManager(nil, nil, log))
+}
+
+func groupContext(userID, chatID string) context.Context {
+	ctx := ctxutil.WithUserID(context.Background(), userID)
+	return ctxutil.WithChatID(ctx, chatID)
+}
+
+func TestHandler_CanHandle(t *testing.T) {
+	t.Parallel()
+	h := newTestHandler(t)
+
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"開啟排行榜", true},
+		{"關閉排行榜", true},
+		{"排行榜", true},
+		{"本群排行榜", true},
+		{"排行榜 課程", false},
+		{"課程 排行榜", false},
+	}
+	for _, tt := range tests {
+		if got := h.CanHandle(tt.input); got != tt.want {
+			t.Errorf("CanHandle(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestHandler_GroupFlow(t *testing.T) {
+	t.Parallel()
+	h := newTestHandler(t)
+	ctx := groupContext("U1", "C1")
+
+	if text := moduletest.Text(t, h.HandleMessage(ctx, "排行榜")); !strings.Contains(text, "尚未開啟") {
+		t.Errorf("before enable = %q", text)
+	}
+	if text := moduletest.Text(t, h.HandleMessage(ctx, "開啟排行榜")); !strings.Contains(text, "已開啟本群排行榜") {
+		t.Errorf("enable = %q", text)
+	}
+	if err := h.store.RecordGroupQuery(ctx, "C1", "course", "課程 資料結構"); err != nil {
+		t.Fatalf("RecordGroupQuery() error = %v", err)
+	}
+	if text := moduletest.Text(t, h.HandleMessage(ctx, "排行榜")); !strings.Contains(text, "1. 資料結構（1 次）") {
+		t.Errorf("ranking = %q", text)
+	}
+	if text := moduletest.Text(t, h.HandleMessage(ctx, "關閉排行榜")); !strings.Contains(text, "已關閉") {
+		t.Errorf("disable = %q", text)
+	}
+}
+
+func TestHandler_RefusesPersonalChat(t *testing.T) {
+	t.Parallel()
+	h := newTestHandler(t)
+
+	text := moduletest.Text(t, h.HandleMessage(groupContext("U1", "U1"), "開啟排行榜"))
+	if !strings.Contains(text, "僅限群組") {
+		t.Errorf("personal chat reply = %q", text)
+	}
+	if enabled, _ := h.store.Enabled(context.Background(), "U1"); enabled {
+		t.Error("personal chat was enabled")
+	}
+}
@@ -0,0 +1,261 @@
+package leaderboard
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+)
+
+// WeekFormat is the layout of week keys: the Monday (Asia/Taipei) the week starts on.
+const WeekFormat = "2006-01-02"
+
+// trackedModules are the modules whose queries are counted. Course search
+// matches both titles and teacher names, so it covers both kinds of ranking.
+var trackedModules = []string{"course"}
+
+// Entry is one ranked search term.
+type Entry struct {
+	Term  string // Query text after the module keyword (e.g., "資料結構")
+	Count int64  // Times the term was queried in the group that week
+}
+
+// Store persists opted-in groups and their weekly query counts in SQLite.
+//
+// Counts live in their own file (not the cache DB) so they survive snapshot
+// hot-swaps and cache rebuilds.
+type Store struct {
+	*storage.AuxStore
+}
+
+// Open opens (or creates) the leaderboard database at path.
+func Open(ctx context.Context, path string) (*Store, error) {
+	db, err := storage.OpenAux(ctx, path, "leaderboard", initSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Store{AuxStore: storage.NewAuxStore(db)}, nil
+}
+
+func initSchema(ctx context.Context, db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS leaderboard_groups (
+		group_id TEXT PRIMARY KEY,
+		enabled_at INTEGER NOT NULL,
+		last_posted_week TEXT NOT NULL DEFAULT ''
+	) STRICT;
+	CREATE TABLE IF NOT EXISTS leaderboard_counts (
+		group_id TEXT NOT NULL,
+		week TEXT NOT NULL,
+		term TEXT NOT NULL,
+		count INTEGER NOT NULL,
+		PRIMARY KEY (group_id, week, term)
+	) STRICT;
+	CREATE INDEX IF NOT EXISTS idx_leaderboard_counts_week ON leaderboard_counts(week);
+	`
+
+	if _, err := db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("create leaderboard tables: %w", err)
+	}
+
+	return nil
+}
+
+// WeekKey returns the key of the week containing t.
+func WeekKey(t time.Time) string {
+	return weekStart(t).Format(WeekFormat)
+}
+
+// weekStart returns Monday 00:00 (Asia/Taipei) of the week containing t.
+func weekStart(t time.Time) time.Time {
+	t = t.In(lineutil.GetTaipeiLocation())
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-daysSinceMonday, 0, 0, 0, 0, t.Location())
+}
+
+// queryTerm extracts the ranked term from a keyword query ("課程 資料結構" →
+// "資料結構"). Bare keywords and UID lookups have no term.
+func queryTerm(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) < 2 {
+		return ""
+	}
+	return strings.Join(fields[1:], " ")
+}
+
+// RecordGroupQuery counts a query for the group's current week. Does nothing
+// unless the group opted in and the module is tracked.
+func (s *Store) RecordGroupQuery(ctx context.Context, groupID, module, query string) error {
+	db, err := s.Conn()
+	if err != nil {
+		return err
+	}
+	term := queryTerm(query)
+	if term == "" || !slices.Contains(trackedModules, module) {
+		return nil
+	}
+
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO leaderboard_counts (group_id, week, term, count)
+		SELECT ?, ?, ?, 1
+		WHERE EXISTS (SELECT 1 FROM leaderboard_groups WHERE group_id = ?)
+		ON CONFLICT(group_id, week, term) DO UPDATE SET count = count + 1
+	`, groupID, WeekKey(time.Now()), term, groupID)
+	if err != nil {
+		return fmt.Errorf("count group query: %w", err)
+	}
+	return nil
+}
+
+// Enable opts the group in. Returns false if it was already enabled.
+// The current week counts as posted, so the first summary covers this week.
+func (s *Store) Enable(ctx context.Context, groupID string) (bool, error) {
+	db, err := s.Conn()
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	result, err := db.ExecContext(ctx, `
+		INSERT OR IGNORE INTO leaderboard_groups (group_id, enabled_at, last_posted_week)
+		VALUES (?, ?, ?)
+	`, groupID, now.Unix(), WeekKey(now))
+	if err != nil {
+		return false, fmt.Errorf("enable group: %w", err)
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// Disable opts the group out and deletes its counts.
+func (s *Store) Disable(ctx context.Context, groupID string) error {
+	db, err := s.Conn()
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM leaderboard_groups WHERE group_id = ?", groupID); err != nil {
+		return fmt.Errorf("disable group: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM leaderboard_counts WHERE group_id = ?", groupID); err != nil {
+		return fmt.Errorf("delete group counts: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit disable: %w", err)
+	}
+	return nil
+}
+
+// Enabled reports whether the group opted in.
+func (s *Store) Enabled(ctx context.Context, groupID string) (bool, error) {
+	db, err := s.Conn()
+	if err != nil {
+		return false, err
+	}
+
+	var enabled bool
+	if err := db.QueryRowContext(ctx,
+		"SELECT EXISTS(SELECT 1 FROM leaderboard_groups WHERE group_id = ?)", groupID,
+	).Scan(&enabled); err != nil {
+		return false, fmt.Errorf("check group: %w", err)
+	}
+	return enabled, nil
+}
+
+// Top returns the group's most-queried terms for the week, highest first.
+func (s *Store) Top(ctx context.Context, groupID, week string, limit int) ([]Entry, error) {
+	db, err := s.Conn()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT term, count
+		FROM leaderboard_counts
+		WHERE group_id = ? AND week = ?
+		ORDER BY count DESC, term
+		LIMIT ?
+	`, groupID, week, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query top terms: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var entries []Entry
+	for rows.Next() {
+		var e Entry
+		if err := rows.Scan(&e.Term, &e.Count); err != nil {
+			return nil, fmt.Errorf("scan top term: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// DueGroups returns opted-in groups whose summary for week has not been posted.
+func (s *Store) DueGroups(ctx context.Context, week string) ([]string, error) {
+	db, err := s.Conn()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx,
+		"SELECT group_id FROM leaderboard_groups WHERE last_posted_week < ? ORDER BY group_id", week)
+	if err != nil {
+		return nil, fmt.Errorf("query due groups: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var groups []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan due group: %w", err)
+		}
+		groups = append(groups, id)
+	}
+	return groups, rows.Err()
+}
+
+// MarkPosted records that the group's summary for week went out.
+func (s *Store) MarkPosted(ctx context.Context, groupID, week string) error {
+	db, err := s.Conn()
+	if err != nil {
+		return err
+	}
+
+	if _, err := db.ExecContext(ctx,
+		"UPDATE leaderboard_groups SET last_posted_week = ? WHERE group_id = ?", week, groupID,
+	); err != nil {
+		return fmt.Errorf("mark posted: %w", err)
+	}
+	return nil
+}
+
+// Prune deletes counts for weeks before the given week key.
+// Returns the number of rows deleted.
+func (s *Store) Prune(ctx context.Context, before string) (int64, error) {
+	db, err := s.Conn()
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := db.ExecContext(ctx, "DELETE FROM leaderboard_counts WHERE week < ?", before)
+	if err != nil {
+		return 0, fmt.Errorf("prune leaderboard: %w", err)
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,110 @@
+package leaderboard
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/moduletest"
+)
+
+func TestWeekKey(t *testing.T) {
+	t.Parallel()
+	tz := lineutil.GetTaipeiLocation()
+
+	tests := []struct {
+		name string
+		t    time.Time
+		want string
+	}{
+		{"monday midnight", time.Date(2025, 3, 3, 0, 0, 0, 0, tz), "2025-03-03"},
+		{"sunday night", time.Date(2025, 3, 9, 23, 59, 0, 0, tz), "2025-03-03"},
+		{"utc sunday is taipei monday", time.Date(2025, 3, 9, 17, 0, 0, 0, time.UTC), "2025-03-10"},
+		{"across month", time.Date(2025, 3, 1, 12, 0, 0, 0, tz), "2025-02-24"},
+	}
+	for _, tt := range tests {
+		if got := WeekKey(tt.t); got != tt.want {
+			t.Errorf("%s: WeekKey(%v) = %q, want %q", tt.name, tt.t, got, tt.want)
+		}
+	}
+}
+
+func TestQueryTerm(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"課程 資料結構", "資料結構"},
+		{"找課 機器 學習", "機器 學習"},
+		{"課程", ""},
+		{"1131U0001", ""},
+	}
+	for _, tt := range tests {
+		if got := queryTerm(tt.query); got != tt.want {
+			t.Errorf("queryTerm(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestStore_RecordGroupQuery(t *testing.T) {
+	t.Parallel()
+	store := moduletest.OpenStore(t, Open)
+	ctx := context.Background()
+
+	if _, err := store.Enable(ctx, "C1"); err != nil {
+		t.Fatalf("Enable() error = %v", err)
+	}
+	for _, q := range []struct{ group, module, query string }{
+		{"C1", "course", "課程 資料結構"},
+		{"C1", "course", "找課 資料結構"},
+		{"C1", "course", "課程 微積分"},
+		{"C1", "id", "學號 王小明"},      // untracked module
+		{"C2", "course", "課程 資料結構"}, // group not opted in
+	} {
+		if err := store.RecordGroupQuery(ctx, q.group, q.module, q.query); err != nil {
+			t.Fatalf("RecordGroupQuery(%+v) error = %v", q, err)
+		}
+	}
+
+	week := WeekKey(time.Now())
+	top, err := store.Top(ctx, "C1", week, TopN)
+	if err != nil {
+		t.Fatalf("Top() error = %v", err)
+	}
+	want := []Entry{{Term: "資料結構", Count: 2}, {Term: "微積分", Count: 1}}
+	if len(top) != len(want) || top[0] != want[0] || top[1] != want[1] {
+		t.Errorf("Top(C1) = %+v, want %+v", top, want)
+	}
+	if top, _ := store.Top(ctx, "C2", week, TopN); len(top) != 0 {
+		t.Errorf("Top(C2) = %+v, want empty for a group that did not opt in", top)
+	}
+}
+
+func TestStore_EnableDisable(t *testing.T) {
+	t.Parallel()
+	store := moduletest.OpenStore(t, Open)
+	ctx := context.Background()
+
+	if added, err := store.Enable(ctx, "C1"); err != nil || !added {
+		t.Fatalf("Enable() = %v, %v; want true, nil", added, err)
+	}
+	if added, _ := store.Enable(ctx, "C1"); added {
+		t.Error("second Enable() = true, want false")
+	}
+	if err := store.RecordGroupQuery(ctx, "C1", "course", "課程 資料結構"); err != nil {
+		t.Fatalf("RecordGroupQuery() error = %v", err)
+	}
+
+	if err := store.Disable(ctx, "C1"); err != nil {
+		t.Fatalf("Disable() error = %v", err)
+	}
+	if enabled, _ := store.Enabled(ctx, "C1"); enabled {
+		t.Error("Enabled() after Disable = true, want false")
+	}
+	if top, _ := store.Top(ctx, "C1", WeekKey(time.Now()), TopN); len(top) != 0 {
+		t.Errorf("Top() after Disable = %+v, want counts deleted", top)
+	}
+}
@@ -0,0 +1,137 @@
+package leaderboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
+	"github.com/garyellow/ntpu-linebot-go/internal/lineapi"
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/sticker"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+// TopN is the number of terms shown in a summary.
+const TopN = 5
+
+// Pusher sends push messages (implemented by *lineapi.Client).
+type Pusher interface {
+	Push(ctx context.Context, req *messaging_api.PushMessageRequest) error
+}
+
+// Poster pushes the weekly summary to opted-in groups.
+type Poster struct {
+	store          *Store
+	pusher         Pusher
+	logger         *logger.Logger
+	stickerManager *sticker.Manager
+}
+
+// NewPoster creates a weekly summary poster.
+func NewPoster(store *Store, pusher Pusher, logger *logger.Logger, stickerManager *sticker.Manager) *Poster {
+	return &Poster{
+		store:          store,
+		pusher:         pusher,
+		logger:         logger,
+		stickerManager: stickerManager,
+	}
+}
+
+// Run posts due summaries and prunes old counts every interval until ctx is done.
+func (p *Poster) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			now := time.Now()
+			if n, err := p.PostDue(ctx, now); err != nil {
+				p.logger.WithModule(ModuleName).WithError(err).Warn("Failed to post weekly leaderboards")
+			} else if n > 0 {
+				p.logger.WithModule(ModuleName).WithField("groups", n).Info("Posted weekly leaderboards")
+			}
+			if _, err := p.store.Prune(ctx, WeekKey(now.Add(-config.LeaderboardRetention))); err != nil {
+				p.logger.WithModule(ModuleName).WithError(err).Warn("Failed to prune leaderboard counts")
+			}
+		}
+	}
+}
+
+// PostDue pushes last week's summary to every opted-in group that has not
+// received it yet, once now is past the weekly post time. Groups with no
+// queries last week are marked posted without a message; only a used-up push
+// quota leaves groups due for a retry.
+// Returns the number of summaries pushed.
+func (p *Poster) PostDue(ctx context.Context, now time.Time) (int, error) {
+	if now.Before(postTime(now)) {
+		return 0, nil
+	}
+	thisWeek := WeekKey(now)
+	lastWeek := WeekKey(weekStart(now).AddDate(0, 0, -7))
+
+	groups, err := p.store.DueGroups(ctx, thisWeek)
+	if err != nil {
+		return 0, err
+	}
+
+	posted := 0
+	for _, groupID := range groups {
+		top, err := p.store.Top(ctx, groupID, lastWeek, TopN)
+		if err != nil {
+			return posted, err
+		}
+		if len(top) > 0 {
+			err := p.pusher.Push(ctx, &messaging_api.PushMessageRequest{
+				To:       groupID,
+				Messages: []messaging_api.MessageInterface{p.buildSummaryMessage(top)},
+			})
+			if errors.Is(err, lineapi.ErrQuotaExhausted) {
+				return posted, err // Retried next interval; the quota won't recover mid-loop
+			}
+			// Other failures (e.g., the bot left the group) would repeat every
+			// interval, so the week is skipped for this group
+			if err != nil {
+				p.logger.WithModule(ModuleName).WithError(err).WithField("group_id", groupID).
+					Warn("Failed to push weekly leaderboard")
+			} else {
+				posted++
+			}
+		}
+		if err := p.store.MarkPosted(ctx, groupID, thisWeek); err != nil {
+			return posted, err
+		}
+	}
+	return posted, nil
+}
+
+// postTime returns when the summary for the week containing now goes out.
+func postTime(now time.Time) time.Time {
+	daysAfterMonday := (int(config.LeaderboardPostWeekday) + 6) % 7
+	return weekStart(now).AddDate(0, 0, daysAfterMonday).Add(config.LeaderboardPostHour * time.Hour)
+}
+
+// buildSummaryMessage formats the weekly post.
+func (p *Poster) buildSummaryMessage(top []Entry) *messaging_api.TextMessageV2 {
+	return lineutil.NewTextMessageWithConsistentSender(
+		formatRanking("📊 本群上週最常查的課："+top[0].Term, top)+"\n\n💡 標記我並輸入「"+disableKeyword+"」可停止每週統計",
+		lineutil.GetSender(senderName, p.stickerManager),
+	)
+}
+
+// formatRanking renders a title followed by numbered terms and counts.
+func formatRanking(title string, top []Entry) string {
+	var b strings.Builder
+	b.WriteString(title)
+	b.WriteString("\n")
+	for i, e := range top {
+		fmt.Fprintf(&b, "\n%d. %s（%d 次）", i+1, e.Term, e.Count)
+	}
+	return b.String()
+}
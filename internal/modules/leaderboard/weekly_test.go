@@ -0,0 +1,104 @@
+package leaderboard
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/lineapi"
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/moduletest"
+	"github.com/garyellow/ntpu-linebot-go/internal/sticker"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+type fakePusher struct {
+	err  error
+	sent []*messaging_api.PushMessageRequest
+}
+
+func (p *fakePusher) Push(_ context.Context, req *messaging_api.PushMessageRequest) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.sent = append(p.sent, req)
+	return nil
+}
+
+// seedLastWeek opts groups in and counts a query for each in the week before now.
+func seedLastWeek(t *testing.T, store *Store, now time.Time, groups ...string) {
+	t.Helper()
+	ctx := context.Background()
+	lastWeek := WeekKey(weekStart(now).AddDate(0, 0, -7))
+	db, err := store.Conn()
+	if err != nil {
+		t.Fatalf("Conn() error = %v", err)
+	}
+	for _, g := range groups {
+		if _, err := db.ExecContext(ctx,
+			"INSERT INTO leaderboard_groups (group_id, enabled_at, last_posted_week) VALUES (?, 0, ?)", g, lastWeek,
+		); err != nil {
+			t.Fatalf("seed group: %v", err)
+		}
+		if _, err := db.ExecContext(ctx,
+			"INSERT INTO leaderboard_counts (group_id, week, term, count) VALUES (?, ?, '資料結構', 3)", g, lastWeek,
+		); err != nil {
+			t.Fatalf("seed counts: %v", err)
+		}
+	}
+}
+
+func TestPoster_PostDue(t *testing.T) {
+	t.Parallel()
+	tz := lineutil.GetTaipeiLocation()
+	monday := time.Date(2025, 3, 10, 0, 0, 0, 0, tz)
+	log := logger.New("error")
+
+	store := moduletest.OpenStore(t, Open)
+	seedLastWeek(t, store, monday, "C1", "C2")
+	pusher := &fakePusher{}
+	poster := NewPoster(store, pusher, log, sticker.NewManager(nil, nil, log))
+	ctx := context.Background()
+
+	// Before the post hour nothing goes out
+	if n, err := poster.PostDue(ctx, monday.Add(8*time.Hour)); err != nil || n != 0 {
+		t.Fatalf("PostDue(08:00) = %d, %v; want 0, nil", n, err)
+	}
+
+	if n, err := poster.PostDue(ctx, monday.Add(9*time.Hour)); err != nil || n != 2 {
+		t.Fatalf("PostDue(09:00) = %d, %v; want 2, nil", n, err)
+	}
+	msg, ok := pusher.sent[0].Messages[0].(*messaging_api.TextMessageV2)
+	if !ok || !strings.HasPrefix(msg.Text, "📊 本群上週最常查的課：資料結構") {
+		t.Errorf("summary = %+v", pusher.sent[0].Messages[0])
+	}
+
+	// Each group is posted once per week
+	if n, err := poster.PostDue(ctx, monday.Add(10*time.Hour)); err != nil || n != 0 {
+		t.Errorf("PostDue(10:00) = %d, %v; want 0, nil", n, err)
+	}
+}
+
+func TestPoster_PostDue_QuotaExhausted(t *testing.T) {
+	t.Parallel()
+	tz := lineutil.GetTaipeiLocation()
+	monday := time.Date(2025, 3, 10, 9, 0, 0, 0, tz)
+	log := logger.New("error")
+
+	store := moduletest.OpenStore(t, Open)
+	seedLastWeek(t, store, monday, "C1")
+	pusher := &fakePusher{err: fmt.Errorf("push: %w", lineapi.ErrQuotaExhausted)}
+	poster := NewPoster(store, pusher, log, sticker.NewManager(nil, nil, log))
+
+	if _, err := poster.PostDue(context.Background(), monday); err == nil {
+		t.Fatal("PostDue() error = nil, want quota error")
+	}
+	// Group stays due so the next check retries
+	groups, err := store.DueGroups(context.Background(), WeekKey(monday))
+	if err != nil || len(groups) != 1 {
+		t.Errorf("DueGroups() = %v, %v; want [C1]", groups, err)
+	}
+}
@@ -0,0 +1,130 @@
+package moduletest
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+// wireMessage is the part of a message's JSON the helpers inspect. Reading
+// the JSON instead of the SDK types covers every message type alike.
+type wireMessage struct {
+	Type     string `json:"type"`
+	Text     string `json:"text"`
+	Contents struct {
+		Type     string            `json:"type"`
+		Contents []json.RawMessage `json:"contents"`
+	} `json:"contents"`
+	QuickReply *struct {
+		Items []struct {
+			Action struct {
+				Label string `json:"label"`
+			} `json:"action"`
+		} `json:"items"`
+	} `json:"quickReply"`
+}
+
+func decode(t testing.TB, msgs []messaging_api.MessageInterface) []wireMessage {
+	t.Helper()
+	data, err := json.Marshal(msgs)
+	if err != nil {
+		t.Fatalf("moduletest: marshal messages: %v", err)
+	}
+	var wire []wireMessage
+	if err := json.Unmarshal(data, &wire); err != nil {
+		t.Fatalf("moduletest: decode messages: %v", err)
+	}
+	return wire
+}
+
+// Texts returns the text of each text message in msgs.
+func Texts(t testing.TB, msgs []messaging_api.MessageInterface) []string {
+	t.Helper()
+	var texts []string
+	for _, m := range decode(t, msgs) {
+		if m.Type == "text" || m.Type == "textV2" {
+			texts = append(texts, m.Text)
+		}
+	}
+	return texts
+}
+
+// Text returns the text of the only message in msgs, failing the test unless
+// msgs is a single text message.
+func Text(t testing.TB, msgs []messaging_api.MessageInterface) string {
+	t.Helper()
+	wire := decode(t, msgs)
+	if len(wire) != 1 || (wire[0].Type != "text" && wire[0].Type != "textV2") {
+		t.Fatalf("got %d messages, want 1 text message", len(wire))
+	}
+	return wire[0].Text
+}
+
+// BubbleCount returns the number of Flex bubbles in msgs, counting each
+// bubble of a carousel.
+func BubbleCount(t testing.TB, msgs []messaging_api.MessageInterface) int {
+	t.Helper()
+	n := 0
+	for _, m := range decode(t, msgs) {
+		if m.Type != "flex" {
+			continue
+		}
+		switch m.Contents.Type {
+		case "bubble":
+			n++
+		case "carousel":
+			n += len(m.Contents.Contents)
+		}
+	}
+	return n
+}
+
+// QuickReplyLabels returns the quick reply labels of the last message, the
+// only one whose quick reply LINE shows.
+func QuickReplyLabels(t testing.TB, msgs []messaging_api.MessageInterface) []string {
+	t.Helper()
+	wire := decode(t, msgs)
+	if len(wire) == 0 || wire[len(wire)-1].QuickReply == nil {
+		return nil
+	}
+	var labels []string
+	for _, item := range wire[len(wire)-1].QuickReply.Items {
+		labels = append(labels, item.Action.Label)
+	}
+	return labels
+}
+
+// AssertTextContains fails the test unless a text message contains substr.
+func AssertTextContains(t testing.TB, msgs []messaging_api.MessageInterface, substr string) {
+	t.Helper()
+	texts := Texts(t, msgs)
+	for _, text := range texts {
+		if strings.Contains(text, substr) {
+			return
+		}
+	}
+	t.Errorf("no text message contains %q; texts: %q", substr, texts)
+}
+
+// AssertBubbleCount fails the test unless msgs hold want Flex bubbles.
+func AssertBubbleCount(t testing.TB, msgs []messaging_api.MessageInterface, want int) {
+	t.Helper()
+	if got := BubbleCount(t, msgs); got != want {
+		t.Errorf("bubble count = %d, want %d", got, want)
+	}
+}
+
+// AssertQuickReply fails the test unless the last message offers a quick
+// reply with each of labels.
+func AssertQuickReply(t testing.TB, msgs []messaging_api.MessageInterface, labels ...string) {
+	t.Helper()
+	got := QuickReplyLabels(t, msgs)
+	for _, label := range labels {
+		if !slices.Contains(got, label) {
+			t.Errorf("quick reply %q missing; labels: %q", label, got)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// Package moduletest wires module handlers for unit tests: an SQLite cache in
+// a temp directory, a scraper pointed at canned pages, discarded logs, fresh
+// metrics and a fixed sticker, plus helpers to inspect the reply messages.
+//
+//	kit := moduletest.New(t, moduletest.Options{
+//		Fixtures: map[string]string{"/pls/dev_stud/course_query_all.queryByKeyword": html},
+//	})
+//	h := id.NewHandler(kit.DB, kit.Scraper, kit.Metrics, kit.Logger, kit.Stickers, nil, nil, false)
+//	moduletest.AssertTextContains(t, h.HandleMessage(ctx, "學號 412345678"), "412345678")
+package moduletest
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/metrics"
+	"github.com/garyellow/ntpu-linebot-go/internal/scraper"
+	"github.com/garyellow/ntpu-linebot-go/internal/sticker"
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// StickerURL is the sender icon of every reply built with a Kit.
+const StickerURL = "https://example.com/sticker.png"
+
+// defaultCacheTTL matches the TTL the module tests have always used.
+const defaultCacheTTL = 168 * time.Hour
+
+// Options configures a Kit.
+type Options struct {
+	// Fixtures maps URL paths to the HTML served for them on every scraper
+	// domain (lms, sea). Other paths return 404, which the scraper does not
+	// retry, so tests never reach the real NTPU sites.
+	Fixtures map[string]string
+	// CacheTTL is the cache TTL of the database; defaults to 168h.
+	CacheTTL time.Duration
+}
+
+// Kit holds the dependencies module handlers are constructed with.
+type Kit struct {
+	DB       *storage.DB
+	Scraper  *scraper.Client
+	Metrics  *metrics.Metrics
+	Logger   *logger.Logger
+	Stickers *sticker.Manager
+	// Server serves Options.Fixtures; its URL is every scraper base URL.
+	Server *httptest.Server
+}
+
+// New creates a Kit whose resources are released when the test ends.
+func New(t testing.TB, opts Options) *Kit {
+	t.Helper()
+	ctx := context.Background()
+
+	ttl := opts.CacheTTL
+	if ttl <= 0 {
+		ttl = defaultCacheTTL
+	}
+	// A file per test keeps parallel tests apart; t.TempDir removes it
+	db, err := storage.New(ctx, filepath.Join(t.TempDir(), "test.db"), ttl)
+	if err != nil {
+		t.Fatalf("moduletest: create database: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close(context.Background()) })
+
+	server := httptest.NewServer(fixtureHandler(opts.Fixtures))
+	t.Cleanup(server.Close)
+	client := scraper.NewClient(5*time.Second, 0, map[string][]string{
+		"lms": {server.URL},
+		"sea": {server.URL},
+	})
+
+	log := logger.NewWithWriter("debug", io.Discard)
+
+	// A cached sticker keeps LoadStickers off the network and sender icons stable
+	if err := db.SaveSticker(ctx, &storage.Sticker{URL: StickerURL, Source: "fallback"}); err != nil {
+		t.Fatalf("moduletest: save sticker: %v", err)
+	}
+	stickers := sticker.NewManager(db, client, log)
+	if err := stickers.LoadStickers(ctx); err != nil {
+		t.Fatalf("moduletest: load stickers: %v", err)
+	}
+
+	return &Kit{
+		DB:       db,
+		Scraper:  client,
+		Metrics:  metrics.New(prometheus.NewRegistry()),
+		Logger:   log,
+		Stickers: stickers,
+		Server:   server,
+	}
+}
+
+// fixtureHandler serves fixtures by path for any method.
+func fixtureHandler(fixtures map[string]string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := fixtures[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = io.WriteString(w, body)
+	})
+}
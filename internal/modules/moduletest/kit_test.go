@@ -0,0 +1,93 @@
+package moduletest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+	kit := New(t, Options{Fixtures: map[string]string{
+		"/fixture": `<html><body><p id="x">fixture page</p></body></html>`,
+	}})
+	ctx := context.Background()
+
+	doc, err := kit.Scraper.GetDocument(ctx, kit.Server.URL+"/fixture")
+	if err != nil {
+		t.Fatalf("GetDocument() error = %v", err)
+	}
+	if got := doc.Find("#x").Text(); got != "fixture page" {
+		t.Errorf("fixture text = %q, want %q", got, "fixture page")
+	}
+	if _, err := kit.Scraper.GetDocument(ctx, kit.Server.URL+"/missing"); err == nil {
+		t.Error("GetDocument() of a path without fixture succeeded, want error")
+	}
+	if got := kit.Scraper.GetBaseURLs("sea"); len(got) != 1 || got[0] != kit.Server.URL {
+		t.Errorf("sea base URLs = %v, want [%s]", got, kit.Server.URL)
+	}
+
+	if got := kit.Stickers.GetRandomSticker(); got != StickerURL {
+		t.Errorf("GetRandomSticker() = %q, want %q", got, StickerURL)
+	}
+	if err := kit.DB.Ping(ctx); err != nil {
+		t.Errorf("DB.Ping() error = %v", err)
+	}
+}
+
+func TestAssertions(t *testing.T) {
+	t.Parallel()
+	sender := &messaging_api.Sender{Name: "test", IconUrl: StickerURL}
+	text := lineutil.NewTextMessageWithConsistentSender("查無「資工系」的成員資料", sender)
+	text.QuickReply = lineutil.NewQuickReply([]lineutil.QuickReplyItem{
+		lineutil.QuickReplyContactAction(),
+		lineutil.QuickReplyEmergencyAction(),
+	})
+	bubble := *lineutil.NewFlexBubble(nil, nil, lineutil.NewFlexBox("vertical", lineutil.NewFlexText("x").FlexText), nil).FlexBubble
+	bubbles := make([]messaging_api.FlexBubble, 12)
+	for i := range bubbles {
+		bubbles[i] = bubble
+	}
+
+	msgs := append(lineutil.BuildCarouselMessages("結果", bubbles, sender), text)
+
+	if got := Texts(t, msgs); len(got) != 1 {
+		t.Errorf("Texts() = %q, want one text", got)
+	}
+	if got := Text(t, msgs[len(msgs)-1:]); got != "查無「資工系」的成員資料" {
+		t.Errorf("Text() = %q", got)
+	}
+	AssertTextContains(t, msgs, "資工系")
+	AssertBubbleCount(t, msgs, 12)
+	AssertBubbleCount(t, []messaging_api.MessageInterface{
+		lineutil.NewFlexMessage("one", lineutil.NewFlexBubble(nil, nil, nil, nil).FlexBubble),
+	}, 1)
+	AssertQuickReply(t, msgs, "📞 聯絡", "🚨 緊急")
+
+	if got := QuickReplyLabels(t, msgs[:1]); got != nil {
+		t.Errorf("QuickReplyLabels() of a carousel = %q, want none", got)
+	}
+
+	// The assertions must also fail when they should
+	for name, assert := range map[string]func(testing.TB){
+		"AssertTextContains": func(tb testing.TB) { AssertTextContains(tb, msgs, "電機系") },
+		"AssertBubbleCount":  func(tb testing.TB) { AssertBubbleCount(tb, msgs, 3) },
+		"AssertQuickReply":   func(tb testing.TB) { AssertQuickReply(tb, msgs, "📚 課程") },
+	} {
+		rec := &errorRecorder{TB: t}
+		assert(rec)
+		if !rec.failed {
+			t.Errorf("%s on mismatching messages did not fail", name)
+		}
+	}
+}
+
+// errorRecorder records Errorf calls instead of failing the test.
+type errorRecorder struct {
+	testing.TB
+	failed bool
+}
+
+func (r *errorRecorder) Errorf(string, ...any) { r.failed = true }
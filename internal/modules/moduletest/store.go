@@ -0,0 +1,30 @@
+package moduletest
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/ctxutil"
+)
+
+// OpenStore opens a feature store (see storage.OpenAux) in a temp directory
+// and closes it when the test ends:
+//
+//	store := moduletest.OpenStore(t, history.Open)
+func OpenStore[S interface{ Close() error }](t testing.TB, open func(ctx context.Context, path string) (S, error)) S {
+	t.Helper()
+	store, err := open(context.Background(), filepath.Join(t.TempDir(), "store.db"))
+	if err != nil {
+		t.Fatalf("moduletest: open store: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+// ChatContext returns a context carrying the sender and chat of a message,
+// as the webhook sets them. A 1:1 chat's ID is the user ID.
+func ChatContext(userID, chatID string) context.Context {
+	ctx := ctxutil.WithUserID(context.Background(), userID)
+	return ctxutil.WithChatID(ctx, chatID)
+}
@@ -0,0 +1,219 @@
+// Package news implements the department announcement module for the LINE bot.
+// It scrapes the announcement list of each department website (URLs come from
+// NTPU_DEPT_NEWS_URLS), caches the newest posts in SQLite and replies with a
+// list bubble linking to them.
+package news
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/bot"
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/scraper"
+	"github.com/garyellow/ntpu-linebot-go/internal/scraper/ntpu"
+	"github.com/garyellow/ntpu-linebot-go/internal/sticker"
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+// Module constants
+const (
+	ModuleName = "news"
+	senderName = "公告小幫手"
+
+	// MaxShown is the number of announcements in one reply.
+	MaxShown = 5
+
+	// PostbackActionDept shows a department's announcements. Params: code.
+	PostbackActionDept = "dept"
+)
+
+// newsRegex matches "{department}公告", e.g. "資工系公告", "資訊工程學系公告" or "85公告".
+var newsRegex = regexp.MustCompile(`^(\S+)公告$`)
+
+// Handler handles department announcement requests.
+type Handler struct {
+	db             *storage.DB
+	scraper        *scraper.Client
+	urls           map[string]string // Announcement list URL per department code (config.DeptNewsURLs)
+	stickerManager *sticker.Manager
+	postbacks      *bot.PostbackRouter
+}
+
+// NewHandler creates a new department announcement handler.
+// urls maps department codes to announcement list URLs; the
+// config.DeptNewsDefaultKey entry is a template for the other departments.
+func NewHandler(
+	db *storage.DB,
+	scraper *scraper.Client,
+	urls map[string]string,
+	stickerManager *sticker.Manager,
+) *Handler {
+	h := &Handler{
+		db:             db,
+		scraper:        scraper,
+		urls:           urls,
+		stickerManager: stickerManager,
+	}
+	h.postbacks = bot.NewPostbackRouter(ModuleName).
+		Handle(PostbackActionDept, func(ctx context.Context, pb *bot.Postback) []messaging_api.MessageInterface {
+			code := ntpu.BaseDepartmentCode(pb.Get("code"))
+			if _, ok := ntpu.DepartmentNames[code]; !ok {
+				return []messaging_api.MessageInterface{}
+			}
+			return h.handleDepartment(ctx, code)
+		})
+	return h
+}
+
+// DeptPostback returns the postback data showing a department's announcements.
+func DeptPostback(deptCode string) string {
+	return bot.NewPostback(ModuleName, PostbackActionDept).With("code", deptCode).String()
+}
+
+// Name returns the module name
+func (h *Handler) Name() string {
+	return ModuleName
+}
+
+// CanHandle returns true for "{department}公告" naming a known department.
+func (h *Handler) CanHandle(text string) bool {
+	_, ok := parseDepartment(strings.TrimSpace(text))
+	return ok
+}
+
+// HandleMessage replies with the newest announcements of the department.
+//
+//	資工系公告
+func (h *Handler) HandleMessage(ctx context.Context, text string) []messaging_api.MessageInterface {
+	code, ok := parseDepartment(strings.TrimSpace(text))
+	if !ok {
+		return []messaging_api.MessageInterface{}
+	}
+	return h.handleDepartment(ctx, code)
+}
+
+// HandlePostback handles postback events for the news module.
+func (h *Handler) HandlePostback(ctx context.Context, data string) []messaging_api.MessageInterface {
+	return h.postbacks.Dispatch(ctx, data)
+}
+
+// handleDepartment serves cached announcements, scraping the department
+// website when none are cached or they expired.
+func (h *Handler) handleDepartment(ctx context.Context, code string) []messaging_api.MessageInterface {
+	log := logger.FromContext(ctx).WithField("dept_code", code)
+	sender := lineutil.GetSender(senderName, h.stickerManager)
+	name := departmentName(code)
+	retryText := name + "公告"
+
+	pageURL, ok := h.pageURL(code)
+	if !ok {
+		msg := lineutil.NewTextMessageWithConsistentSender("📰 尚未設定"+name+"的公告來源\n\n💡 請聯絡管理員設定系網公告網址", sender)
+		msg.QuickReply = lineutil.NewQuickReply(lineutil.QuickReplyMainNavCompact())
+		return []messaging_api.MessageInterface{msg}
+	}
+
+	news, err := h.db.GetDepartmentNews(ctx, code)
+	if err != nil {
+		log.WithError(err).ErrorContext(ctx, "Failed to get cached department news")
+		return []messaging_api.MessageInterface{
+			lineutil.ErrorMessageWithQuickReply("查詢系上公告時發生問題", sender, retryText),
+		}
+	}
+	if len(news) == 0 {
+		news, err = ntpu.ScrapeDepartmentNews(ctx, h.scraper, code, pageURL)
+		if err != nil {
+			log.WithError(err).WithField("url", pageURL).WarnContext(ctx, "Failed to scrape department news")
+			return []messaging_api.MessageInterface{
+				lineutil.ErrorMessageWithQuickReply("暫時無法取得"+name+"公告", sender, retryText),
+			}
+		}
+		// A failed save only costs a re-scrape on the next request
+		if err := h.db.SaveDepartmentNews(ctx, code, news); err != nil {
+			log.WithError(err).WarnContext(ctx, "Failed to cache department news")
+		}
+	}
+
+	msg := buildNewsMessage(name, pageURL, news)
+	msg.Sender = sender
+	msg.QuickReply = lineutil.NewQuickReply(lineutil.QuickReplyMainNavCompact())
+	return []messaging_api.MessageInterface{msg}
+}
+
+// pageURL returns the announcement list URL of a department: its own entry,
+// else the default template with the code filled in.
+func (h *Handler) pageURL(code string) (string, bool) {
+	if u, ok := h.urls[code]; ok {
+		return u, true
+	}
+	if tmpl, ok := h.urls[config.DeptNewsDefaultKey]; ok {
+		return strings.ReplaceAll(tmpl, config.DeptNewsCodePlaceholder, code), true
+	}
+	return "", false
+}
+
+// parseDepartment returns the department code named by "{department}公告".
+// Short names (資工), full names (資訊工程學系) and codes (85) are accepted;
+// law groups map to the law department, which runs the website.
+func parseDepartment(text string) (string, bool) {
+	m := newsRegex.FindStringSubmatch(text)
+	if m == nil {
+		return "", false
+	}
+	code, ok := ntpu.LookupDepartment(m[1])
+	if !ok {
+		return "", false
+	}
+	return ntpu.BaseDepartmentCode(code), true
+}
+
+// departmentName returns the display name of a department code, e.g. "資工系".
+func departmentName(code string) string {
+	return ntpu.DepartmentNames[code] + "系"
+}
+
+// buildNewsMessage renders the announcement list bubble: one row per post
+// (title and date, tapping opens the post) and a link to the full list.
+func buildNewsMessage(name, pageURL string, news []storage.DepartmentNews) *messaging_api.FlexMessage {
+	header := lineutil.NewColoredHeader(lineutil.ColoredHeaderInfo{
+		Title: "📰 " + name + "公告",
+		Color: lineutil.ColorHeaderInfo,
+	})
+
+	body := lineutil.NewBodyContentBuilder()
+	for i, n := range news {
+		if i == MaxShown {
+			break
+		}
+		row := lineutil.NewFlexBox("vertical",
+			lineutil.NewFlexText(n.Title).WithSize("sm").WithWrap(true).WithMaxLines(2).FlexText,
+		)
+		if n.Date != "" {
+			row.Contents = append(row.Contents, lineutil.NewFlexText("📅 "+n.Date).
+				WithSize("xs").
+				WithColor(lineutil.ColorSubtext).
+				WithMargin("xs").FlexText)
+		}
+		row.Action = lineutil.NewURIAction(lineutil.TruncateRunes(n.Title, 20), n.URL)
+		if i > 0 {
+			row.WithMargin("lg")
+		}
+		body.AddComponent(row.FlexBox)
+	}
+
+	footer := lineutil.NewButtonFooter([]*lineutil.FlexButton{
+		lineutil.NewFlexButton(lineutil.NewURIAction("🔗 查看全部公告", pageURL)).
+			WithStyle("primary").WithColor(lineutil.ColorButtonExternal).WithHeight("sm"),
+	})
+
+	bubble := lineutil.NewFlexBubble(header, nil, body.Build(), footer)
+	altText := lineutil.FormatLabel(name+"公告", news[0].Title, 400)
+	return lineutil.NewFlexMessage(altText, bubble.FlexBubble)
+}
+
+// Ensure Handler implements bot.Handler
+var _ bot.Handler = (*Handler)(nil)
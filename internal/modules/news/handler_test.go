@@ -0,0 +1,115 @@
+package news
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/moduletest"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+const listPage = `<div class="mtitle"><i class="mdate">2025-04-01</i><a href="/p/406-1065-3.php" title="期中考公告">期中考...</a></div>
+<div class="mtitle"><i class="mdate">2025-03-20</i><a href="/p/406-1065-2.php">專題說明會</a></div>`
+
+func TestCanHandle(t *testing.T) {
+	t.Parallel()
+	h := NewHandler(nil, nil, nil, nil)
+
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"資工系公告", true},
+		{"資工公告", true},
+		{"資訊工程學系公告", true},
+		{"85公告", true},
+		{" 法學系公告 ", true},
+		{"外星系公告", false},
+		{"公告", false},
+		{"資工系 公告", false},
+		{"系 資工", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+			if got := h.CanHandle(tt.input); got != tt.want {
+				t.Errorf("CanHandle(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDepartment(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"資工系公告", "85"},
+		{"資訊工程學系公告", "85"},
+		{"財法公告", "71"}, // Law groups share the law department's site
+		{"社工系公告", "744"},
+	}
+	for _, tt := range tests {
+		if got, _ := parseDepartment(tt.input); got != tt.want {
+			t.Errorf("parseDepartment(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestPageURL(t *testing.T) {
+	t.Parallel()
+	h := NewHandler(nil, nil, map[string]string{
+		"85": "https://www.csie.ntpu.edu.tw/p/403-1065-1.php",
+		"*":  "https://news.example.com/{code}/list",
+	}, nil)
+
+	if got, _ := h.pageURL("85"); got != "https://www.csie.ntpu.edu.tw/p/403-1065-1.php" {
+		t.Errorf("pageURL(85) = %q, want the department's own entry", got)
+	}
+	if got, _ := h.pageURL("79"); got != "https://news.example.com/79/list" {
+		t.Errorf("pageURL(79) = %q, want the default template with the code", got)
+	}
+	if _, ok := NewHandler(nil, nil, map[string]string{"85": "https://x"}, nil).pageURL("79"); ok {
+		t.Error("pageURL(79) ok = true without a default template")
+	}
+}
+
+func TestHandleMessage(t *testing.T) {
+	t.Parallel()
+	kit := moduletest.New(t, moduletest.Options{
+		Fixtures: map[string]string{"/p/403-1065-1.php": listPage},
+	})
+	h := NewHandler(kit.DB, kit.Scraper, map[string]string{
+		"85": kit.Server.URL + "/p/403-1065-1.php",
+	}, kit.Stickers)
+	ctx := context.Background()
+
+	msgs := h.HandleMessage(ctx, "資工系公告")
+	moduletest.AssertBubbleCount(t, msgs, 1)
+	if flex, ok := msgs[0].(*messaging_api.FlexMessage); !ok || !strings.Contains(flex.AltText, "期中考公告") {
+		t.Errorf("reply = %+v, want a bubble of the newest announcement", msgs[0])
+	}
+
+	news, err := kit.DB.GetDepartmentNews(ctx, "85")
+	if err != nil || len(news) != 2 {
+		t.Fatalf("GetDepartmentNews() = %+v, %v, want the 2 scraped announcements cached", news, err)
+	}
+
+	t.Run("postback", func(t *testing.T) {
+		t.Parallel()
+		moduletest.AssertBubbleCount(t, h.HandlePostback(ctx, DeptPostback("85")), 1)
+	})
+
+	t.Run("no URL configured", func(t *testing.T) {
+		t.Parallel()
+		moduletest.AssertTextContains(t, h.HandleMessage(ctx, "企管系公告"), "尚未設定企管系的公告來源")
+	})
+
+	t.Run("scrape failure", func(t *testing.T) {
+		t.Parallel()
+		h := NewHandler(kit.DB, kit.Scraper, map[string]string{"*": kit.Server.URL + "/missing/{code}"}, kit.Stickers)
+		moduletest.AssertTextContains(t, h.HandleMessage(ctx, "統計系公告"), "暫時無法取得統計系公告")
+	})
+}
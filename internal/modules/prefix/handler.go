@@ -0,0 +1,130 @@
+// Package prefix implements the group trigger prefix module for the LINE bot.
+// A group that sets a prefix (e.g. 「!」 with 設定前綴 !) only has messages
+// starting with it matched against keywords, so ordinary conversation such as
+// 「明天課程要帶什麼」 doesn't trigger a search. Mentioning the bot always
+// works without the prefix. 取消前綴 goes back to matching every message.
+package prefix
+
+import (
+	"context"
+	"strings"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/bot"
+	"github.com/garyellow/ntpu-linebot-go/internal/ctxutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/sticker"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+// Module constants
+const (
+	ModuleName = "prefix"
+	senderName = "群組設定小幫手"
+)
+
+// Commands (exact match after sanitization, mention and prefix removal)
+const (
+	setKeyword   = "設定前綴"
+	clearKeyword = "取消前綴"
+	showKeyword  = "前綴"
+)
+
+// Handler answers the trigger prefix commands in group chats.
+type Handler struct {
+	bot.NoPostbacks // Commands are plain text
+
+	store          *Store
+	logger         *logger.Logger
+	stickerManager *sticker.Manager
+}
+
+// NewHandler creates a new prefix handler.
+func NewHandler(store *Store, logger *logger.Logger, stickerManager *sticker.Manager) *Handler {
+	return &Handler{
+		store:          store,
+		logger:         logger,
+		stickerManager: stickerManager,
+	}
+}
+
+// Name returns the module name
+func (h *Handler) Name() string {
+	return ModuleName
+}
+
+// CanHandle returns true for the prefix commands. The prefix itself is
+// punctuation, which sanitization removes, so "設定前綴 !" arrives as "設定前綴".
+func (h *Handler) CanHandle(text string) bool {
+	text = strings.TrimSpace(text)
+	return text == setKeyword || strings.HasPrefix(text, setKeyword+" ") ||
+		text == clearKeyword || text == showKeyword
+}
+
+// HandleMessage runs a prefix command for the current group.
+func (h *Handler) HandleMessage(ctx context.Context, text string) []messaging_api.MessageInterface {
+	log := logger.FromContext(ctx)
+	sender := lineutil.GetSender(senderName, h.stickerManager)
+	text = strings.TrimSpace(text)
+
+	// Personal chats have chat ID == user ID; rooms count as groups
+	groupID := ctxutil.GetChatID(ctx)
+	if groupID == "" || groupID == ctxutil.GetUserID(ctx) {
+		return lineutil.TextReply(sender, "👥 觸發前綴僅限群組使用\n\n1 對 1 聊天中每則訊息都會直接查詢")
+	}
+
+	switch text {
+	case clearKeyword:
+		cleared, err := h.store.ClearPrefix(ctx, groupID)
+		if err != nil {
+			log.WithError(err).ErrorContext(ctx, "Failed to clear group trigger prefix")
+			return lineutil.TextReply(sender, "❌ 取消前綴失敗，請稍後再試")
+		}
+		if !cleared {
+			return lineutil.TextReply(sender, "ℹ️ 本群沒有設定觸發前綴")
+		}
+		return lineutil.TextReply(sender, "✅ 已取消觸發前綴\n\n群組訊息會直接比對關鍵字，例如「課程 微積分」")
+
+	case showKeyword:
+		current, err := h.store.TriggerPrefix(ctx, groupID)
+		if err != nil {
+			log.WithError(err).ErrorContext(ctx, "Failed to load group trigger prefix")
+			return lineutil.TextReply(sender, "❌ 讀取前綴失敗，請稍後再試")
+		}
+		if current == "" {
+			return lineutil.TextReply(sender, "ℹ️ 本群沒有設定觸發前綴\n\n"+setUsage())
+		}
+		return lineutil.TextReply(sender, "🔤 本群的觸發前綴是「"+current+"」\n\n"+
+			"例如「"+current+"課程 微積分」；標記我則不需前綴")
+	}
+
+	// 設定前綴: the prefix is punctuation, so read it from the raw text
+	arg := ctxutil.GetRawText(ctx)
+	if i := strings.Index(arg, setKeyword); i >= 0 {
+		arg = arg[i+len(setKeyword):]
+	}
+	arg = strings.TrimSpace(arg)
+	if arg == "" {
+		return lineutil.TextReply(sender, setUsage())
+	}
+	normalized, ok := NormalizePrefix(arg)
+	if !ok {
+		return lineutil.TextReply(sender, "❌ 前綴「"+arg+"」無效\n\n"+setUsage())
+	}
+	if err := h.store.SetPrefix(ctx, groupID, normalized); err != nil {
+		log.WithError(err).ErrorContext(ctx, "Failed to set group trigger prefix")
+		return lineutil.TextReply(sender, "❌ 設定前綴失敗，請稍後再試")
+	}
+	return lineutil.TextReply(sender, "✅ 已設定觸發前綴「"+normalized+"」\n\n"+
+		"• 以「"+normalized+"」開頭的訊息才會查詢，例如「"+normalized+"課程 微積分」\n"+
+		"• 標記我的訊息不需前綴\n"+
+		"• 輸入「"+normalized+clearKeyword+"」可取消")
+}
+
+// setUsage explains the 設定前綴 command.
+func setUsage() string {
+	return "輸入「" + setKeyword + " !」設定前綴（1～3 個符號，全形半形皆可），之後以「!」開頭的訊息才會查詢"
+}
+
+// Ensure Handler implements bot.Handler
+var _ bot.Handler = (*Handler)(nil)
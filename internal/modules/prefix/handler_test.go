@@ -0,0 +1,75 @@
+package prefix
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/ctxutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/moduletest"
+	"github.com/garyellow/ntpu-linebot-go/internal/sticker"
+)
+
+func newTestHandler(t *testing.T) *Handler {
+	t.Helper()
+	log := logger.New("error")
+	return NewHandler(moduletest.OpenStore(t, Open), log, sticker.NewManager(nil, nil, log))
+}
+
+func TestHandler_CanHandle(t *testing.T) {
+	t.Parallel()
+	h := newTestHandler(t)
+
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"設定前綴", true},
+		{"設定前綴 ab", true},
+		{"取消前綴", true},
+		{"前綴", true},
+		{"設定前綴語", false},
+		{"前綴 課程", false},
+		{"課程 前綴", false},
+	}
+	for _, tt := range tests {
+		if got := h.CanHandle(tt.input); got != tt.want {
+			t.Errorf("CanHandle(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestHandler_GroupFlow(t *testing.T) {
+	t.Parallel()
+	h := newTestHandler(t)
+
+	if text := moduletest.Text(t, h.HandleMessage(ctxutil.WithRawText(moduletest.ChatContext("U1", "C1"), "前綴"), "前綴")); !strings.Contains(text, "沒有設定") {
+		t.Errorf("show before set = %q", text)
+	}
+	if text := moduletest.Text(t, h.HandleMessage(ctxutil.WithRawText(moduletest.ChatContext("U1", "C1"), "設定前綴 ！"), "設定前綴")); !strings.Contains(text, "已設定觸發前綴「!」") {
+		t.Errorf("set = %q", text)
+	}
+	if got, _ := h.store.TriggerPrefix(context.Background(), "C1"); got != "!" {
+		t.Errorf("stored prefix = %q, want %q", got, "!")
+	}
+	if text := moduletest.Text(t, h.HandleMessage(ctxutil.WithRawText(moduletest.ChatContext("U1", "C1"), "設定前綴 ab"), "設定前綴 ab")); !strings.Contains(text, "無效") {
+		t.Errorf("invalid set = %q", text)
+	}
+	if text := moduletest.Text(t, h.HandleMessage(ctxutil.WithRawText(moduletest.ChatContext("U1", "C1"), "取消前綴"), "取消前綴")); !strings.Contains(text, "已取消") {
+		t.Errorf("clear = %q", text)
+	}
+}
+
+func TestHandler_RefusesPersonalChat(t *testing.T) {
+	t.Parallel()
+	h := newTestHandler(t)
+
+	text := moduletest.Text(t, h.HandleMessage(ctxutil.WithRawText(moduletest.ChatContext("U1", "U1"), "設定前綴 !"), "設定前綴"))
+	if !strings.Contains(text, "僅限群組") {
+		t.Errorf("personal chat reply = %q", text)
+	}
+	if got, _ := h.store.TriggerPrefix(context.Background(), "U1"); got != "" {
+		t.Errorf("personal chat prefix = %q, want empty", got)
+	}
+}
@@ -0,0 +1,121 @@
+package prefix
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/width"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+)
+
+// MaxPrefixLength is the longest trigger prefix, in characters.
+const MaxPrefixLength = 3
+
+// Store persists each group's trigger prefix in SQLite.
+//
+// Prefixes live in their own file (not the cache DB) so they survive
+// snapshot hot-swaps and cache rebuilds.
+type Store struct {
+	*storage.AuxStore
+}
+
+// Open opens (or creates) the prefix database at path.
+func Open(ctx context.Context, path string) (*Store, error) {
+	db, err := storage.OpenAux(ctx, path, "prefix", initSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Store{AuxStore: storage.NewAuxStore(db)}, nil
+}
+
+func initSchema(ctx context.Context, db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS group_prefixes (
+		group_id TEXT PRIMARY KEY,
+		prefix TEXT NOT NULL,
+		updated_at INTEGER NOT NULL
+	) STRICT;
+	`
+
+	if _, err := db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("create prefix table: %w", err)
+	}
+
+	return nil
+}
+
+// NormalizePrefix folds full-width characters to half-width ("！" → "!") and
+// reports whether the result is a valid trigger prefix: 1 to MaxPrefixLength
+// punctuation or symbol characters, so it cannot start an ordinary word.
+func NormalizePrefix(prefix string) (string, bool) {
+	prefix = width.Fold.String(prefix)
+	if n := utf8.RuneCountInString(prefix); n == 0 || n > MaxPrefixLength {
+		return prefix, false
+	}
+	for _, r := range prefix {
+		if !unicode.IsPunct(r) && !unicode.IsSymbol(r) {
+			return prefix, false
+		}
+	}
+	return prefix, true
+}
+
+// TriggerPrefix returns the group's prefix, or "" if it has none.
+func (s *Store) TriggerPrefix(ctx context.Context, groupID string) (string, error) {
+	db, err := s.Conn()
+	if err != nil {
+		return "", err
+	}
+
+	var prefix string
+	err = db.QueryRowContext(ctx,
+		"SELECT prefix FROM group_prefixes WHERE group_id = ?", groupID,
+	).Scan(&prefix)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("get prefix: %w", err)
+	}
+	return prefix, nil
+}
+
+// SetPrefix sets (or replaces) the group's prefix.
+func (s *Store) SetPrefix(ctx context.Context, groupID, prefix string) error {
+	db, err := s.Conn()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO group_prefixes (group_id, prefix, updated_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(group_id) DO UPDATE SET prefix = excluded.prefix, updated_at = excluded.updated_at
+	`, groupID, prefix, time.Now().Unix())
+	if err != nil {
+		return fmt.Errorf("set prefix: %w", err)
+	}
+	return nil
+}
+
+// ClearPrefix removes the group's prefix. Returns false if it had none.
+func (s *Store) ClearPrefix(ctx context.Context, groupID string) (bool, error) {
+	db, err := s.Conn()
+	if err != nil {
+		return false, err
+	}
+
+	result, err := db.ExecContext(ctx, "DELETE FROM group_prefixes WHERE group_id = ?", groupID)
+	if err != nil {
+		return false, fmt.Errorf("clear prefix: %w", err)
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
@@ -0,0 +1,78 @@
+package prefix
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/moduletest"
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+)
+
+func TestNormalizePrefix(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input  string
+		want   string
+		wantOK bool
+	}{
+		{"!", "!", true},
+		{"！", "!", true},
+		{"//", "//", true},
+		{"#", "#", true},
+		{"", "", false},
+		{"!!!!", "!!!!", false},
+		{"a", "a", false},
+		{"課", "課", false},
+		{"!1", "!1", false},
+	}
+	for _, tt := range tests {
+		got, ok := NormalizePrefix(tt.input)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("NormalizePrefix(%q) = (%q, %v), want (%q, %v)", tt.input, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestStore_Prefix(t *testing.T) {
+	t.Parallel()
+	store := moduletest.OpenStore(t, Open)
+	ctx := context.Background()
+
+	if got, err := store.TriggerPrefix(ctx, "C1"); err != nil || got != "" {
+		t.Fatalf("TriggerPrefix() before set = (%q, %v), want empty", got, err)
+	}
+	if err := store.SetPrefix(ctx, "C1", "!"); err != nil {
+		t.Fatalf("SetPrefix() error = %v", err)
+	}
+	if err := store.SetPrefix(ctx, "C1", "/"); err != nil {
+		t.Fatalf("SetPrefix() replace error = %v", err)
+	}
+	if got, _ := store.TriggerPrefix(ctx, "C1"); got != "/" {
+		t.Errorf("TriggerPrefix() = %q, want %q", got, "/")
+	}
+	if got, _ := store.TriggerPrefix(ctx, "C2"); got != "" {
+		t.Errorf("TriggerPrefix() other group = %q, want empty", got)
+	}
+
+	if cleared, err := store.ClearPrefix(ctx, "C1"); err != nil || !cleared {
+		t.Fatalf("ClearPrefix() = (%v, %v), want (true, nil)", cleared, err)
+	}
+	if cleared, _ := store.ClearPrefix(ctx, "C1"); cleared {
+		t.Error("ClearPrefix() twice = true, want false")
+	}
+	if got, _ := store.TriggerPrefix(ctx, "C1"); got != "" {
+		t.Errorf("TriggerPrefix() after clear = %q, want empty", got)
+	}
+}
+
+func TestStore_Closed(t *testing.T) {
+	t.Parallel()
+	store := moduletest.OpenStore(t, Open)
+	_ = store.Close()
+
+	if _, err := store.TriggerPrefix(context.Background(), "C1"); !errors.Is(err, storage.ErrDatabaseClosed) {
+		t.Errorf("TriggerPrefix() after close error = %v, want storage.ErrDatabaseClosed", err)
+	}
+}
@@ -0,0 +1,112 @@
+package privacy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// Eraser deletes everything one store keeps about a user. Satisfied by the
+// per-user stores (history, account, role, and the in-memory session store).
+type Eraser interface {
+	EraseUser(ctx context.Context, userID string) (int64, error)
+}
+
+// Target is one store in the cascade.
+type Target struct {
+	Label  string // Shown to the user, e.g. "查詢紀錄"
+	Eraser Eraser
+}
+
+// Result is the outcome of erasing one target.
+type Result struct {
+	Label string
+	Count int64 // Rows or entries deleted
+	Err   error
+}
+
+// Receipt summarizes one erasure. It carries the hashed user ID, never the
+// raw one, so it can be logged and shown as a reference number after the
+// data it refers to is gone.
+type Receipt struct {
+	UserHash string
+	Results  []Result
+}
+
+// Total returns the number of rows deleted across all targets.
+func (r Receipt) Total() int64 {
+	var n int64
+	for _, res := range r.Results {
+		n += res.Count
+	}
+	return n
+}
+
+// Err joins the errors of the targets that failed, or returns nil.
+func (r Receipt) Err() error {
+	var errs []error
+	for _, res := range r.Results {
+		if res.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", res.Label, res.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Cascade erases a user from every registered store.
+type Cascade struct {
+	targets []Target
+}
+
+// NewCascade creates a cascade over targets; targets with a nil Eraser
+// (disabled features) are skipped.
+func NewCascade(targets ...Target) *Cascade {
+	c := &Cascade{targets: make([]Target, 0, len(targets))}
+	for _, t := range targets {
+		if t.Eraser != nil {
+			c.targets = append(c.targets, t)
+		}
+	}
+	return c
+}
+
+// Add registers a target created after the cascade, such as the processor's
+// caches. Like NewCascade it skips a nil Eraser. Call it during startup only:
+// Add is not safe to run alongside Erase.
+func (c *Cascade) Add(t Target) {
+	if t.Eraser != nil {
+		c.targets = append(c.targets, t)
+	}
+}
+
+// Labels returns the labels of the registered targets in order.
+func (c *Cascade) Labels() []string {
+	labels := make([]string, len(c.targets))
+	for i, t := range c.targets {
+		labels[i] = t.Label
+	}
+	return labels
+}
+
+// Erase deletes userID from every target. A failing target does not stop
+// the others; check Receipt.Err.
+func (c *Cascade) Erase(ctx context.Context, userID string) Receipt {
+	receipt := Receipt{
+		UserHash: UserHash(userID),
+		Results:  make([]Result, len(c.targets)),
+	}
+	for i, t := range c.targets {
+		n, err := t.Eraser.EraseUser(ctx, userID)
+		receipt.Results[i] = Result{Label: t.Label, Count: n, Err: err}
+	}
+	return receipt
+}
+
+// UserHash returns the first 16 hex digits of the SHA-256 of userID: a stable
+// reference for an erasure that does not reveal who asked for it.
+func UserHash(userID string) string {
+	sum := sha256.Sum256([]byte(userID))
+	return hex.EncodeToString(sum[:8])
+}
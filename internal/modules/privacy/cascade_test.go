@@ -0,0 +1,65 @@
+package privacy
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type fakeEraser struct {
+	n   int64
+	err error
+	got []string
+}
+
+func (f *fakeEraser) EraseUser(_ context.Context, userID string) (int64, error) {
+	f.got = append(f.got, userID)
+	return f.n, f.err
+}
+
+func TestCascade_Erase(t *testing.T) {
+	t.Parallel()
+	history := &fakeEraser{n: 3}
+	failing := &fakeEraser{err: errors.New("db down")}
+	sessions := &fakeEraser{n: 1}
+
+	c := NewCascade(
+		Target{Label: "查詢紀錄", Eraser: history},
+		Target{Label: "帳號綁定"}, // disabled feature
+		Target{Label: "身分", Eraser: failing},
+		Target{Label: "對話脈絡", Eraser: sessions},
+	)
+	if got := c.Labels(); len(got) != 3 || got[1] != "身分" {
+		t.Fatalf("Labels() = %v, want disabled target skipped", got)
+	}
+	caches := &fakeEraser{n: 0}
+	c.Add(Target{Label: "近期訊息暫存", Eraser: caches})
+	c.Add(Target{Label: "除錯錄製紀錄"}) // recorder off
+	if got := c.Labels(); len(got) != 4 || got[3] != "近期訊息暫存" {
+		t.Fatalf("Labels() after Add = %v, want the added target last", got)
+	}
+
+	receipt := c.Erase(context.Background(), "U1")
+	if len(history.got) != 1 || len(sessions.got) != 1 || sessions.got[0] != "U1" || len(caches.got) != 1 {
+		t.Error("every target should be erased, even after a failure")
+	}
+	if receipt.Total() != 4 {
+		t.Errorf("Total() = %d, want 4", receipt.Total())
+	}
+	if err := receipt.Err(); err == nil || !errors.Is(err, failing.err) {
+		t.Errorf("Err() = %v, want the failing target's error", err)
+	}
+	if receipt.UserHash != UserHash("U1") || receipt.UserHash == "U1" {
+		t.Errorf("UserHash = %q, want the hashed user ID", receipt.UserHash)
+	}
+}
+
+func TestUserHash(t *testing.T) {
+	t.Parallel()
+	if got := UserHash("U1"); len(got) != 16 || got != UserHash("U1") {
+		t.Errorf("UserHash() = %q, want stable 16 hex digits", got)
+	}
+	if UserHash("U1") == UserHash("U2") {
+		t.Error("UserHash() should differ between users")
+	}
+}
@@ -0,0 +1,151 @@
+// Package privacy implements personal data deletion for the LINE bot.
+// 刪除我的資料 asks for confirmation, then erases the user from every store
+// that keeps per-user data (see Cascade) and replies with what was deleted
+// and a reference number derived from the hashed user ID.
+package privacy
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/bot"
+	"github.com/garyellow/ntpu-linebot-go/internal/ctxutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/sticker"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+// Module constants
+const (
+	ModuleName = "privacy"
+	senderName = "隱私小幫手"
+)
+
+// Postback actions of the confirmation template
+const (
+	PostbackActionErase  = "erase"
+	PostbackActionCancel = "cancel"
+)
+
+// directChatOnly answers the command in group chats, where the confirmation
+// would be visible to everyone.
+const directChatOnly = "🔒 刪除資料僅限與本帳號的 1 對 1 聊天使用"
+
+// eraseKeywords start the deletion (exact match after sanitization).
+var eraseKeywords = []string{"刪除我的資料", "刪除個人資料", "刪除個資"}
+
+// Handler answers the data deletion command.
+type Handler struct {
+	cascade        *Cascade
+	stickerManager *sticker.Manager
+}
+
+// NewHandler creates a new privacy handler that erases users through cascade.
+func NewHandler(cascade *Cascade, stickerManager *sticker.Manager) *Handler {
+	return &Handler{
+		cascade:        cascade,
+		stickerManager: stickerManager,
+	}
+}
+
+// Name returns the module name
+func (h *Handler) Name() string {
+	return ModuleName
+}
+
+// CanHandle returns true for the deletion command.
+func (h *Handler) CanHandle(text string) bool {
+	return slices.Contains(eraseKeywords, strings.TrimSpace(text))
+}
+
+// HandleMessage asks the user to confirm the deletion.
+func (h *Handler) HandleMessage(ctx context.Context, _ string) []messaging_api.MessageInterface {
+	sender := lineutil.GetSender(senderName, h.stickerManager)
+	if !isDirectChat(ctx) {
+		return lineutil.TextReply(sender, directChatOnly)
+	}
+
+	text := "確定要刪除本帳號保存的下列資料嗎？此操作無法復原"
+	if labels := h.cascade.Labels(); len(labels) > 0 {
+		text += "\n\n將刪除：" + strings.Join(labels, "、")
+	}
+	msg := lineutil.NewConfirmTemplate("確認刪除我的資料", text,
+		lineutil.NewPostbackActionWithDisplayText("🗑️ 刪除", "確認刪除", bot.NewPostback(ModuleName, PostbackActionErase).String()),
+		lineutil.NewPostbackActionWithDisplayText("取消", "取消", bot.NewPostback(ModuleName, PostbackActionCancel).String()),
+	)
+	return []messaging_api.MessageInterface{msg}
+}
+
+// HandlePostback runs or cancels the confirmed deletion.
+func (h *Handler) HandlePostback(ctx context.Context, data string) []messaging_api.MessageInterface {
+	return bot.NewPostbackRouter(ModuleName).
+		Handle(PostbackActionErase, h.postbackErase).
+		Handle(PostbackActionCancel, h.postbackCancel).
+		Dispatch(ctx, data)
+}
+
+func (h *Handler) postbackCancel(_ context.Context, _ *bot.Postback) []messaging_api.MessageInterface {
+	return lineutil.TextReply(lineutil.GetSender(senderName, h.stickerManager), "👌 已取消，資料未刪除")
+}
+
+func (h *Handler) postbackErase(ctx context.Context, _ *bot.Postback) []messaging_api.MessageInterface {
+	sender := lineutil.GetSender(senderName, h.stickerManager)
+	if !isDirectChat(ctx) {
+		return lineutil.TextReply(sender, directChatOnly)
+	}
+
+	receipt := h.cascade.Erase(ctx, ctxutil.GetUserID(ctx))
+
+	// The audit line must not carry the raw ID it records as erased
+	auditCtx := ctxutil.WithChatID(ctxutil.WithUserID(ctx, ""), "")
+	log := logger.FromContext(ctx).
+		WithField("user_hash", receipt.UserHash).
+		WithField("deleted", receipt.Total())
+	if err := receipt.Err(); err != nil {
+		log.WithError(err).ErrorContext(auditCtx, "User data erasure incomplete")
+	} else {
+		log.InfoContext(auditCtx, "User data erased")
+	}
+
+	return lineutil.TextReply(sender, formatReceipt(receipt))
+}
+
+// retentionNote names what the deletion does not reach, so the receipt does
+// not read as a promise that nothing about the user remains anywhere.
+const retentionNote = "系統日誌會依保存期限自動清除；LINE 聊天紀錄請在 LINE 內自行刪除"
+
+// formatReceipt lists what was deleted per store, what it does not cover,
+// and the reference number.
+func formatReceipt(receipt Receipt) string {
+	var b strings.Builder
+	if receipt.Err() != nil {
+		b.WriteString("⚠️ 部分資料刪除失敗，請稍後再輸入「" + eraseKeywords[0] + "」重試")
+	} else {
+		b.WriteString("🗑️ 已刪除下列資料")
+	}
+	for _, res := range receipt.Results {
+		if res.Err != nil {
+			fmt.Fprintf(&b, "\n• %s：刪除失敗", res.Label)
+			continue
+		}
+		fmt.Fprintf(&b, "\n• %s：%d 筆", res.Label, res.Count)
+	}
+	if len(receipt.Results) == 0 {
+		b.WriteString("\n本帳號目前沒有可刪除的資料")
+	}
+	b.WriteString("\n\n" + retentionNote)
+	b.WriteString("\n\n刪除編號：" + receipt.UserHash)
+	return b.String()
+}
+
+// isDirectChat reports whether the current event comes from a 1:1 chat.
+func isDirectChat(ctx context.Context) bool {
+	userID := ctxutil.GetUserID(ctx)
+	return userID != "" && ctxutil.GetChatID(ctx) == userID
+}
+
+// Ensure Handler implements bot.Handler
+var _ bot.Handler = (*Handler)(nil)
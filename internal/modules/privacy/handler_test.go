@@ -0,0 +1,76 @@
+package privacy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/bot"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/moduletest"
+	"github.com/garyellow/ntpu-linebot-go/internal/sticker"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+const userID = "U0123456789abcdef0123456789abcdef"
+
+func TestHandler_CanHandle(t *testing.T) {
+	t.Parallel()
+	h := NewHandler(NewCascade(), nil)
+
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"刪除我的資料", true},
+		{" 刪除個資 ", true},
+		{"刪除我的資料吧", false},
+		{"清除我的紀錄", false},
+	}
+	for _, tt := range tests {
+		if got := h.CanHandle(tt.input); got != tt.want {
+			t.Errorf("CanHandle(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestHandler_EraseFlow(t *testing.T) {
+	t.Parallel()
+	history := &fakeEraser{n: 2}
+	h := NewHandler(NewCascade(Target{Label: "查詢紀錄", Eraser: history}),
+		sticker.NewManager(nil, nil, logger.New("error")))
+	direct := moduletest.ChatContext(userID, userID)
+
+	if got := moduletest.Text(t, h.HandleMessage(moduletest.ChatContext(userID, "C1"), "刪除我的資料")); !strings.Contains(got, "1 對 1") {
+		t.Errorf("group reply = %q, want 1:1 only notice", got)
+	}
+	if got := moduletest.Text(t, h.HandlePostback(moduletest.ChatContext(userID, "C1"), bot.NewPostback(ModuleName, PostbackActionErase).String())); !strings.Contains(got, "1 對 1") {
+		t.Errorf("group erase = %q, want 1:1 only notice", got)
+	}
+
+	msgs := h.HandleMessage(direct, "刪除我的資料")
+	tmpl, ok := msgs[0].(*messaging_api.TemplateMessage)
+	if !ok {
+		t.Fatalf("confirmation is %T, want *TemplateMessage", msgs[0])
+	}
+	if confirm, ok := tmpl.Template.(*messaging_api.ConfirmTemplate); !ok || !strings.Contains(confirm.Text, "查詢紀錄") {
+		t.Errorf("confirmation = %+v, want the targets listed", tmpl.Template)
+	}
+	if len(history.got) != 0 {
+		t.Fatal("data erased before confirmation")
+	}
+
+	if got := moduletest.Text(t, h.HandlePostback(direct, bot.NewPostback(ModuleName, PostbackActionCancel).String())); !strings.Contains(got, "已取消") || len(history.got) != 0 {
+		t.Errorf("cancel = %q, want nothing erased", got)
+	}
+
+	got := moduletest.Text(t, h.HandlePostback(direct, bot.NewPostback(ModuleName, PostbackActionErase).String()))
+	if len(history.got) != 1 || history.got[0] != userID {
+		t.Fatalf("erased %v, want the current user", history.got)
+	}
+	if !strings.Contains(got, "查詢紀錄：2 筆") || !strings.Contains(got, UserHash(userID)) || strings.Contains(got, userID) {
+		t.Errorf("receipt = %q, want counts and the hashed reference only", got)
+	}
+	if strings.Contains(got, "已刪除您的資料") || !strings.Contains(got, "系統日誌") {
+		t.Errorf("receipt = %q, want it to name what is not erased", got)
+	}
+}
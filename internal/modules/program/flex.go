@@ -8,6 +8,7 @@ import (
 	"github.com/garyellow/ntpu-linebot-go/internal/bot"
 	"github.com/garyellow/ntpu-linebot-go/internal/data"
 	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/course"
 	"github.com/garyellow/ntpu-linebot-go/internal/storage"
 	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
 )
@@ -255,6 +256,8 @@ func (h *Handler) formatProgramSearchResponse(programs []storage.Program) []mess
 //	│ [📋 學程資訊]            │  <- Footer button (row 1)
 //	├──────────────────────────┤
 //	│ [📚 查看課程]            │  <- Footer button (row 2, only if >0 courses)
+//	├──────────────────────────┤
+//	│ [📤 分享]                │  <- Footer button (row 3, only if share links are enabled)
 //	└──────────────────────────┘
 func (h *Handler) buildProgramBubble(program storage.Program) *lineutil.FlexBubble {
 	// Get category label info (emoji, label, color based on category)
@@ -275,16 +278,16 @@ func (h *Handler) buildProgramBubble(program storage.Program) *lineutil.FlexBubb
 
 	// Course count info
 	totalCourses := program.RequiredCount + program.ElectiveCount
-	body.AddComponent(lineutil.NewInfoRow("📚", "課程數量", fmt.Sprintf("%d 門", totalCourses), lineutil.DefaultInfoRowStyle()).FlexBox)
+	body.AddComponent(lineutil.NewInfoRow(lineutil.Icon(lineutil.IconCourse), "課程數量", fmt.Sprintf("%d 門", totalCourses), lineutil.DefaultInfoRowStyle()).FlexBox)
 
 	// Required courses count
 	if program.RequiredCount > 0 {
-		body.AddInfoRow("✅", "必修", fmt.Sprintf("%d 門", program.RequiredCount), lineutil.DefaultInfoRowStyle())
+		body.AddInfoRow(lineutil.Icon(lineutil.IconRequired), "必修", fmt.Sprintf("%d 門", program.RequiredCount), lineutil.DefaultInfoRowStyle())
 	}
 
 	// Elective courses count
 	if program.ElectiveCount > 0 {
-		body.AddInfoRow("📝", "選修", fmt.Sprintf("%d 門", program.ElectiveCount), lineutil.DefaultInfoRowStyle())
+		body.AddInfoRow(lineutil.Icon(lineutil.IconElective), "選修", fmt.Sprintf("%d 門", program.ElectiveCount), lineutil.DefaultInfoRowStyle())
 	}
 
 	// 0 courses warning
@@ -293,7 +296,7 @@ func (h *Handler) buildProgramBubble(program storage.Program) *lineutil.FlexBubb
 		// Enable wrapping for this warning message to prevent truncation
 		warningStyle := lineutil.DefaultInfoRowStyle()
 		warningStyle.Wrap = true
-		body.AddInfoRow("⚠️", "注意", "近 2 學期無課程資料，請點擊「學程資訊」至網頁確認", warningStyle)
+		body.AddInfoRow(lineutil.Icon(lineutil.IconWarning), "注意", "近 2 學期無課程資料，請點擊「學程資訊」至網頁確認", warningStyle)
 	}
 
 	// Build footer buttons - using rows for vertical stacking
@@ -329,6 +332,11 @@ func (h *Handler) buildProgramBubble(program storage.Program) *lineutil.FlexBubb
 		footerRows = append(footerRows, viewCoursesBtn)
 	}
 
+	// Row 3: Share button - only if share links are enabled
+	if shareBtn := h.sharer.Button("學程 " + program.Name); shareBtn != nil {
+		footerRows = append(footerRows, shareBtn)
+	}
+
 	// Pass as slice of slices to NewButtonFooter
 	// Each element in footerRows becomes a separate row with one button
 	rows := make([][]*lineutil.FlexButton, 0, len(footerRows))
@@ -430,8 +438,9 @@ func (h *Handler) buildProgramCourseBubble(pc storage.ProgramCourse, isRequired
 	// Header: Course title with colored background
 	// WARNING: Do NOT truncate course title here.
 	header := lineutil.NewColoredHeader(lineutil.ColoredHeaderInfo{
-		Title: lineutil.FormatCourseTitleWithUID(pc.Course.Title, pc.Course.UID),
-		Color: headerColor,
+		Title:    lineutil.FormatCourseTitleWithUID(pc.Course.Title, pc.Course.UID),
+		Subtitle: pc.Course.TitleEn,
+		Color:    headerColor,
 	})
 
 	// Build body contents
@@ -446,20 +455,20 @@ func (h *Handler) buildProgramCourseBubble(pc storage.ProgramCourse, isRequired
 
 	// Semester info - first row (no separator between label and first row)
 	semesterText := lineutil.FormatSemester(pc.Course.Year, pc.Course.Term)
-	firstInfoRow := lineutil.NewInfoRow("📅", "開課學期", semesterText, lineutil.DefaultInfoRowStyle())
+	firstInfoRow := lineutil.NewInfoRow(lineutil.Icon(lineutil.IconSemester), "開課學期", semesterText, lineutil.DefaultInfoRowStyle())
 	body.AddComponent(firstInfoRow.FlexBox)
 
 	// Teacher info - use multi-line style for better readability
 	if len(pc.Course.Teachers) > 0 {
 		teacherNames := strings.Join(pc.Course.Teachers, "、")
-		body.AddInfoRow("👨‍🏫", "授課教師", teacherNames, lineutil.CarouselInfoRowStyleMultiLine())
+		body.AddInfoRow(lineutil.Icon(lineutil.IconTeacher), "授課教師", teacherNames, lineutil.CarouselInfoRowStyleMultiLine())
 	}
 
 	// Time info - use multi-line style for better readability
 	if len(pc.Course.Times) > 0 {
 		formattedTimes := lineutil.FormatCourseTimes(pc.Course.Times)
 		timeStr := strings.Join(formattedTimes, "、")
-		body.AddInfoRow("⏰", "上課時間", timeStr, lineutil.CarouselInfoRowStyleMultiLine())
+		body.AddInfoRow(lineutil.Icon(lineutil.IconTime), "上課時間", timeStr, lineutil.CarouselInfoRowStyleMultiLine())
 	}
 
 	// Note: Location info is omitted for program course bubbles to keep display compact
@@ -475,7 +484,7 @@ func (h *Handler) buildProgramCourseBubble(pc storage.ProgramCourse, isRequired
 		lineutil.NewPostbackActionWithDisplayText(
 			"📄 詳細資訊",
 			displayText,
-			"course:"+pc.Course.UID,
+			course.UIDPostback(pc.Course.UID),
 		),
 	).WithStyle("primary").WithColor(headerColor).WithHeight("sm")
 
@@ -602,15 +611,15 @@ func (h *Handler) buildNoProgramsFoundBubble(courseName string) *lineutil.FlexBu
 	}).FlexBox)
 
 	// Course name info
-	body.AddInfoRow("📚", "課程", courseName, lineutil.DefaultInfoRowStyle())
+	body.AddInfoRow(lineutil.Icon(lineutil.IconCourse), "課程", courseName, lineutil.DefaultInfoRowStyle())
 
 	// Message with wrapping
 	msgStyle := lineutil.DefaultInfoRowStyle()
 	msgStyle.Wrap = true
-	body.AddInfoRow("📝", "說明", "目前沒有相關學程資料，可能是因為該課程尚未被任何學程認列", msgStyle)
+	body.AddInfoRow(lineutil.Icon(lineutil.IconNote), "說明", "目前沒有相關學程資料，可能是因為該課程尚未被任何學程認列", msgStyle)
 
 	// Hint
-	body.AddInfoRow("💡", "提示", "可至學程列表頁面查詢最新學程資訊", msgStyle)
+	body.AddInfoRow(lineutil.Icon(lineutil.IconTip), "提示", "可至學程列表頁面查詢最新學程資訊", msgStyle)
 
 	// Footer: Link to LMS program list
 	detailBtn := lineutil.NewFlexButton(
@@ -14,8 +14,10 @@ import (
 	domerrors "github.com/garyellow/ntpu-linebot-go/internal/errors"
 	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
 	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/membudget"
 	"github.com/garyellow/ntpu-linebot-go/internal/metrics"
 	"github.com/garyellow/ntpu-linebot-go/internal/modules/course"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/share"
 	"github.com/garyellow/ntpu-linebot-go/internal/sticker"
 	"github.com/garyellow/ntpu-linebot-go/internal/storage"
 	"github.com/garyellow/ntpu-linebot-go/internal/stringutil"
@@ -34,6 +36,7 @@ type Handler struct {
 	stickerManager *sticker.Manager
 	semesterCache  *course.SemesterCache // Shared cache (from course module)
 	programCache   *ListCache            // Short-TTL cache for GetAllPrograms results
+	sharer         *share.Linker         // 分享 button links (nil = disabled)
 
 	// matchers contains all pattern-handler pairs sorted by priority.
 	// Shared by CanHandle and HandleMessage for consistent routing.
@@ -100,6 +103,7 @@ var (
 
 // NewHandler creates a new program handler.
 // semesterCache should be shared from course module for consistent semester data.
+// sharer is optional (nil = no 分享 button).
 // Initializes and sorts matchers by priority during construction.
 func NewHandler(
 	db *storage.DB,
@@ -107,6 +111,7 @@ func NewHandler(
 	logger *logger.Logger,
 	stickerManager *sticker.Manager,
 	semesterCache *course.SemesterCache,
+	sharer *share.Linker,
 ) *Handler {
 	h := &Handler{
 		db:             db,
@@ -115,6 +120,7 @@ func NewHandler(
 		stickerManager: stickerManager,
 		semesterCache:  semesterCache,
 		programCache:   NewListCache(0),
+		sharer:         sharer,
 	}
 
 	// Initialize Pattern-Action Table
@@ -123,6 +129,11 @@ func NewHandler(
 	return h
 }
 
+// SetBudget charges the program lists cached for fuzzy matching to b.
+func (h *Handler) SetBudget(b *membudget.Budget) {
+	h.programCache.SetBudget(b)
+}
+
 // initializeMatchers sets up the Pattern-Action Table.
 // All pattern matching logic is defined here in one place.
 // Matchers are automatically sorted by priority after initialization.
@@ -162,11 +173,7 @@ func (h *Handler) DispatchIntent(ctx context.Context, intent string, params map[
 	// Validate parameters first (before logging) to support testing with nil dependencies
 	switch intent {
 	case IntentList:
-		if h.logger != nil {
-			h.logger.WithModule(ModuleName).
-				WithField("intent", IntentList).
-				DebugContext(ctx, "Dispatching program intent")
-		}
+		logger.FromContext(ctx).DebugContext(ctx, "Dispatching program intent")
 		return h.handleProgramList(ctx), nil
 
 	case IntentSearch:
@@ -174,12 +181,9 @@ func (h *Handler) DispatchIntent(ctx context.Context, intent string, params map[
 		if !ok || query == "" {
 			return nil, fmt.Errorf("%w: query", domerrors.ErrMissingParameter)
 		}
-		if h.logger != nil {
-			h.logger.WithModule(ModuleName).
-				WithField("intent", IntentSearch).
-				WithField("query", query).
-				DebugContext(ctx, "Dispatching program intent")
-		}
+		logger.FromContext(ctx).
+			WithField("query", query).
+			DebugContext(ctx, "Dispatching program intent")
 		return h.handleProgramSearch(ctx, query), nil
 
 	case IntentCourses:
@@ -187,12 +191,9 @@ func (h *Handler) DispatchIntent(ctx context.Context, intent string, params map[
 		if !ok || programName == "" {
 			return nil, fmt.Errorf("%w: programName", domerrors.ErrMissingParameter)
 		}
-		if h.logger != nil {
-			h.logger.WithModule(ModuleName).
-				WithField("intent", IntentCourses).
-				WithField("program_name", programName).
-				DebugContext(ctx, "Dispatching program intent")
-		}
+		logger.FromContext(ctx).
+			WithField("program_name", programName).
+			DebugContext(ctx, "Dispatching program intent")
 		return h.handleProgramCourses(ctx, programName), nil
 
 	default:
@@ -220,7 +221,7 @@ func (h *Handler) CanHandle(text string) bool {
 // HandleMessage finds the matching pattern and executes its handler.
 // Returns empty slice if no pattern matches (fallback to NLU).
 func (h *Handler) HandleMessage(ctx context.Context, text string) []messaging_api.MessageInterface {
-	log := h.logger.WithModule(ModuleName)
+	log := logger.FromContext(ctx)
 	text = strings.TrimSpace(text)
 
 	log.DebugContext(ctx, "Handling program message")
@@ -268,7 +269,7 @@ func (h *Handler) HandleMessage(ctx context.Context, text string) []messaging_ap
 // Postback format: "program:{action}:{data}" where action is "courses".
 // Returns nil if postback is not for this module.
 func (h *Handler) HandlePostback(ctx context.Context, data string) []messaging_api.MessageInterface {
-	log := h.logger.WithModule(ModuleName)
+	log := logger.FromContext(ctx)
 
 	// Check if postback is for this module
 	if !strings.HasPrefix(data, PostbackPrefix) {
@@ -340,7 +341,7 @@ func (h *Handler) handleSearchPattern(ctx context.Context, text string, matches
 
 // handleProgramList retrieves and displays all programs.
 func (h *Handler) handleProgramList(ctx context.Context) []messaging_api.MessageInterface {
-	log := h.logger.WithModule(ModuleName)
+	log := logger.FromContext(ctx)
 	sender := lineutil.GetSender(senderName, h.stickerManager)
 
 	log.WithField("query_type", "list").
@@ -376,7 +377,7 @@ func (h *Handler) handleProgramList(ctx context.Context) []messaging_api.Message
 		return []messaging_api.MessageInterface{msg}
 	}
 
-	h.metrics.RecordCacheHit(ModuleName)
+	h.metrics.RecordCacheHit(ctx, ModuleName)
 	log.WithField("count", len(programs)).
 		DebugContext(ctx, "Program list loaded")
 
@@ -394,7 +395,7 @@ func (h *Handler) handleProgramList(ctx context.Context) []messaging_api.Message
 
 // handleProgramSearch searches programs by name using 2-tier matching.
 func (h *Handler) handleProgramSearch(ctx context.Context, searchTerm string) []messaging_api.MessageInterface {
-	log := h.logger.WithModule(ModuleName)
+	log := logger.FromContext(ctx)
 	sender := lineutil.GetSender(senderName, h.stickerManager)
 
 	log.WithField("search_term", searchTerm).
@@ -436,15 +437,17 @@ func (h *Handler) handleProgramSearch(ctx context.Context, searchTerm string) []
 		}
 
 		// Add fuzzy matches that weren't found by SQL LIKE
+		query := stringutil.NewRuneQuery(searchTerm)
 		for _, p := range allPrograms {
-			if !foundNames[p.Name] && stringutil.ContainsAllRunes(p.Name, searchTerm) {
+			if !foundNames[p.Name] && query.Match(p.Name) {
 				programs = append(programs, p)
 			}
 		}
 	}
 
 	if len(programs) == 0 {
-		h.metrics.RecordCacheMiss(ModuleName)
+		h.metrics.RecordCacheMiss(ctx, ModuleName)
+		h.metrics.RecordZeroResults(ModuleName)
 		msg := lineutil.NewTextMessageWithConsistentSender(
 			fmt.Sprintf("🔍 查無「%s」相關學程\n\n💡 建議\n• 使用「學程列表」查看所有學程\n• 嘗試其他關鍵字", searchTerm),
 			sender,
@@ -453,7 +456,7 @@ func (h *Handler) handleProgramSearch(ctx context.Context, searchTerm string) []
 		return []messaging_api.MessageInterface{msg}
 	}
 
-	h.metrics.RecordCacheHit(ModuleName)
+	h.metrics.RecordCacheHit(ctx, ModuleName)
 	log.WithField("count", len(programs)).
 		WithField("search_term", searchTerm).
 		DebugContext(ctx, "Program search results loaded")
@@ -478,7 +481,7 @@ func (h *Handler) handleProgramSearch(ctx context.Context, searchTerm string) []
 // handleProgramCourses retrieves and displays courses for a specific program.
 // Courses are filtered to the most recent 2 semesters (consistent with smart search).
 func (h *Handler) handleProgramCourses(ctx context.Context, programName string) []messaging_api.MessageInterface {
-	log := h.logger.WithModule(ModuleName)
+	log := logger.FromContext(ctx)
 	sender := lineutil.GetSender(senderName, h.stickerManager)
 
 	log.WithField("program_name", programName).
@@ -513,7 +516,7 @@ func (h *Handler) handleProgramCourses(ctx context.Context, programName string)
 	// Do NOT attempt fuzzy search or auto-correction to avoid incorrect program matching
 	// e.g., Searching "大數據" should NOT auto-match to "大數據分析學程" without explicit user selection
 	if len(programCourses) == 0 {
-		h.metrics.RecordCacheMiss(ModuleName)
+		h.metrics.RecordCacheMiss(ctx, ModuleName)
 		msg := lineutil.NewTextMessageWithConsistentSender(
 			fmt.Sprintf("📭 「%s」在近 2 學期沒有課程資料\n\n💡 可能原因：\n• 該學程可能在本學期未開設相關課程\n• 學程名稱可能有誤，請嘗試「學程列表」查看正確名稱", programName),
 			sender,
@@ -522,7 +525,7 @@ func (h *Handler) handleProgramCourses(ctx context.Context, programName string)
 		return []messaging_api.MessageInterface{msg}
 	}
 
-	h.metrics.RecordCacheHit(ModuleName)
+	h.metrics.RecordCacheHit(ctx, ModuleName)
 	log.WithField("count", len(programCourses)).
 		WithField("program_name", programName).
 		DebugContext(ctx, "Program courses loaded")
@@ -557,7 +560,7 @@ func (h *Handler) handleProgramCourses(ctx context.Context, programName string)
 // handleCourseProgramsList shows all programs that a course belongs to.
 // This is triggered from the "更多學程" button on course detail pages.
 func (h *Handler) handleCourseProgramsList(ctx context.Context, courseUID string) []messaging_api.MessageInterface {
-	log := h.logger.WithModule(ModuleName)
+	log := logger.FromContext(ctx)
 	sender := lineutil.GetSender(senderName, h.stickerManager)
 
 	log.WithField("course_uid", courseUID).
@@ -595,7 +598,7 @@ func (h *Handler) handleCourseProgramsList(ctx context.Context, courseUID string
 		return []messaging_api.MessageInterface{msg}
 	}
 
-	h.metrics.RecordCacheHit(ModuleName)
+	h.metrics.RecordCacheHit(ctx, ModuleName)
 	log.WithField("count", len(programs)).
 		WithField("course_uid", courseUID).
 		DebugContext(ctx, "Course programs loaded")
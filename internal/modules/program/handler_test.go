@@ -43,7 +43,7 @@ func setupTestHandler(t *testing.T) *Handler {
 	// In production, this comes from course.Handler.GetSemesterCache()
 	var semesterCache *course.SemesterCache
 
-	return NewHandler(db, m, log, stickerMgr, semesterCache)
+	return NewHandler(db, m, log, stickerMgr, semesterCache, nil)
 }
 
 // TestCanHandle verifies keyword pattern matching for program queries
@@ -568,7 +568,7 @@ func TestNewHandler_NilSemesterCache(t *testing.T) {
 	stickerMgr := sticker.NewManager(db, nil, log)
 
 	// Create handler with nil semester cache (should not panic)
-	h := NewHandler(db, m, log, stickerMgr, nil)
+	h := NewHandler(db, m, log, stickerMgr, nil, nil)
 	if h == nil {
 		t.Fatal("Expected non-nil handler")
 	}
@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/garyellow/ntpu-linebot-go/internal/membudget"
 	"github.com/garyellow/ntpu-linebot-go/internal/storage"
 )
 
@@ -24,6 +25,7 @@ type ListCache struct {
 	mu      sync.RWMutex
 	ttl     time.Duration
 	entries map[string]programListCacheEntry
+	budget  *membudget.Budget // Optional: charged per program list
 }
 
 // NewListCache creates a short-lived in-memory cache for program lists.
@@ -38,6 +40,21 @@ func NewListCache(ttl time.Duration) *ListCache {
 	}
 }
 
+// SetBudget charges each cached program list to b, which drops the least
+// recently used lists when over its limit.
+func (c *ListCache) SetBudget(b *membudget.Budget) {
+	c.budget = b
+	b.Register(membudget.ConsumerProgramLists, c.evict)
+}
+
+// evict drops a program list evicted by the memory budget; the next Get
+// reloads it from SQLite.
+func (c *ListCache) evict(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
+
 // Get returns all programs for the given semester filter from memory when fresh,
 // otherwise reloads from SQLite.
 // Expired entries are lazily deleted on read to keep the map bounded.
@@ -53,14 +70,20 @@ func (c *ListCache) Get(ctx context.Context, db *storage.DB, years, terms []int)
 	c.mu.RUnlock()
 	if ok {
 		if time.Since(entry.fetchedAt) < c.ttl {
+			c.budget.Touch(membudget.ConsumerProgramLists, key)
 			return clonePrograms(entry.programs), nil
 		}
 		// Lazy eviction: re-check under write lock to avoid deleting a freshly refreshed entry.
 		c.mu.Lock()
-		if e, still := c.entries[key]; still && time.Since(e.fetchedAt) >= c.ttl {
+		e, still := c.entries[key]
+		expired := still && time.Since(e.fetchedAt) >= c.ttl
+		if expired {
 			delete(c.entries, key)
 		}
 		c.mu.Unlock()
+		if expired {
+			c.budget.Release(membudget.ConsumerProgramLists, key)
+		}
 	}
 
 	programs, err := db.GetAllPrograms(ctx, years, terms)
@@ -74,6 +97,8 @@ func (c *ListCache) Get(ctx context.Context, db *storage.DB, years, terms []int)
 		fetchedAt: time.Now(),
 	}
 	c.mu.Unlock()
+	// Charged after unlocking: the budget may call back into evict.
+	c.budget.Charge(membudget.ConsumerProgramLists, key, membudget.Estimate(programs))
 
 	return clonePrograms(programs), nil
 }
@@ -0,0 +1,194 @@
+// Package role implements staff verification for the LINE bot. A user asks
+// to be verified with 教職員驗證; a chat admin (NTPU_ADMIN_USER_IDS) reviews
+// pending requests with 驗證申請 and grants the role with 授權教職員. Verified
+// staff see staff-only contact fields such as personal mobile numbers (see
+// contact.RoleLookup).
+package role
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/bot"
+	"github.com/garyellow/ntpu-linebot-go/internal/ctxutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/sticker"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+// Module constants
+const (
+	ModuleName = "role"
+	senderName = "身分小幫手"
+)
+
+// Commands (after sanitization). The user commands take no argument except
+// 教職員驗證, which takes a short self-description.
+const (
+	requestKeyword = "教職員驗證"
+	whoamiKeyword  = "我的身分"
+	listKeyword    = "驗證申請"
+	grantKeyword   = "授權教職員"
+	revokeKeyword  = "撤銷身分"
+)
+
+// maxListedRequests keeps one grant button per request within Quick Reply limits.
+const maxListedRequests = 10
+
+// userIDRegex matches a LINE user ID.
+var userIDRegex = regexp.MustCompile(`^U[0-9a-f]{32}$`)
+
+// Handler answers the verification commands.
+type Handler struct {
+	bot.NoPostbacks // Commands are plain text
+
+	store          *Store
+	adminUserIDs   []string
+	stickerManager *sticker.Manager
+}
+
+// NewHandler creates a new role handler. adminUserIDs may grant and revoke roles.
+func NewHandler(store *Store, adminUserIDs []string, stickerManager *sticker.Manager) *Handler {
+	return &Handler{
+		store:          store,
+		adminUserIDs:   adminUserIDs,
+		stickerManager: stickerManager,
+	}
+}
+
+// Name returns the module name
+func (h *Handler) Name() string {
+	return ModuleName
+}
+
+// CanHandle returns true for the verification commands.
+func (h *Handler) CanHandle(text string) bool {
+	command, _ := splitCommand(text)
+	switch command {
+	case requestKeyword, grantKeyword, revokeKeyword:
+		return true
+	case whoamiKeyword, listKeyword:
+		return strings.TrimSpace(text) == command
+	}
+	return false
+}
+
+// HandleMessage runs a verification command for the current user.
+func (h *Handler) HandleMessage(ctx context.Context, text string) []messaging_api.MessageInterface {
+	log := logger.FromContext(ctx)
+	sender := lineutil.GetSender(senderName, h.stickerManager)
+	command, arg := splitCommand(text)
+
+	// Verification is personal; never show or change roles from a group chat
+	userID := ctxutil.GetUserID(ctx)
+	if userID == "" || ctxutil.GetChatID(ctx) != userID {
+		return lineutil.TextReply(sender, "🔒 身分驗證僅限與本帳號的 1 對 1 聊天使用")
+	}
+
+	switch command {
+	case requestKeyword:
+		return h.handleRequest(ctx, userID, arg, sender)
+
+	case whoamiKeyword:
+		role, err := h.store.Role(ctx, userID)
+		if err != nil {
+			log.WithError(err).ErrorContext(ctx, "Failed to load role")
+			return lineutil.TextReply(sender, "❌ 讀取身分失敗，請稍後再試")
+		}
+		return lineutil.TextReply(sender, "🪪 目前身分："+role.Label())
+	}
+
+	if !slices.Contains(h.adminUserIDs, userID) {
+		return lineutil.TextReply(sender, "🔒 此指令僅限管理員使用")
+	}
+
+	switch command {
+	case listKeyword:
+		return h.handleList(ctx, sender)
+
+	case grantKeyword:
+		if !userIDRegex.MatchString(arg) {
+			return lineutil.TextReply(sender, "用法：「"+grantKeyword+" <LINE user ID>」\n\n可輸入「"+listKeyword+"」查看申請")
+		}
+		if err := h.store.Grant(ctx, arg, Staff, userID); err != nil {
+			log.WithError(err).ErrorContext(ctx, "Failed to grant role")
+			return lineutil.TextReply(sender, "❌ 授權失敗，請稍後再試")
+		}
+		log.WithField("target_user", arg).InfoContext(ctx, "Staff role granted")
+		return lineutil.TextReply(sender, "✅ 已授權教職員身分\n"+arg)
+
+	default: // revokeKeyword
+		if !userIDRegex.MatchString(arg) {
+			return lineutil.TextReply(sender, "用法：「"+revokeKeyword+" <LINE user ID>」")
+		}
+		revoked, err := h.store.Revoke(ctx, arg)
+		if err != nil {
+			log.WithError(err).ErrorContext(ctx, "Failed to revoke role")
+			return lineutil.TextReply(sender, "❌ 撤銷失敗，請稍後再試")
+		}
+		if !revoked {
+			return lineutil.TextReply(sender, "ℹ️ 此使用者沒有身分或申請\n"+arg)
+		}
+		log.WithField("target_user", arg).InfoContext(ctx, "Role revoked")
+		return lineutil.TextReply(sender, "🗑️ 已撤銷身分與申請\n"+arg)
+	}
+}
+
+// handleRequest records a verification request.
+func (h *Handler) handleRequest(ctx context.Context, userID, note string, sender *messaging_api.Sender) []messaging_api.MessageInterface {
+	log := logger.FromContext(ctx)
+
+	role, err := h.store.Role(ctx, userID)
+	if err != nil {
+		log.WithError(err).ErrorContext(ctx, "Failed to load role")
+		return lineutil.TextReply(sender, "❌ 讀取身分失敗，請稍後再試")
+	}
+	if role == Staff {
+		return lineutil.TextReply(sender, "✅ 您已通過教職員驗證")
+	}
+	if note == "" {
+		return lineutil.TextReply(sender, "請附上單位與姓名，例如「"+requestKeyword+" 資工系 王小明」\n\n管理員確認後即可查看教職員專屬的聯絡資訊")
+	}
+
+	if err := h.store.RequestRole(ctx, userID, Staff, note); err != nil {
+		log.WithError(err).ErrorContext(ctx, "Failed to request role")
+		return lineutil.TextReply(sender, "❌ 送出申請失敗，請稍後再試")
+	}
+	return lineutil.TextReply(sender, "📨 已送出教職員驗證申請\n\n管理員確認後即可查看教職員專屬的聯絡資訊，通過後輸入「"+whoamiKeyword+"」會顯示教職員")
+}
+
+// handleList shows pending requests with a Quick Reply button to grant each.
+func (h *Handler) handleList(ctx context.Context, sender *messaging_api.Sender) []messaging_api.MessageInterface {
+	requests, err := h.store.Requests(ctx, maxListedRequests)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).ErrorContext(ctx, "Failed to list role requests")
+		return lineutil.TextReply(sender, "❌ 讀取申請失敗，請稍後再試")
+	}
+	if len(requests) == 0 {
+		return lineutil.TextReply(sender, "ℹ️ 目前沒有待審核的申請")
+	}
+
+	var b strings.Builder
+	b.WriteString("📋 待審核的教職員驗證申請")
+	items := make([]lineutil.QuickReplyItem, 0, len(requests))
+	for i, r := range requests {
+		fmt.Fprintf(&b, "\n\n%d. %s\n%s\n%s", i+1, r.Note, r.UserID, lineutil.FormatCacheTime(r.RequestedAt.Unix()))
+		items = append(items, lineutil.QuickReplyItem{
+			Action: lineutil.NewMessageAction(fmt.Sprintf("✅ 授權 %d", i+1), grantKeyword+" "+r.UserID),
+		})
+	}
+	return lineutil.TextReply(sender, b.String(), items...)
+}
+
+// splitCommand splits text into the command keyword and its trimmed argument.
+func splitCommand(text string) (string, string) {
+	command, arg, _ := strings.Cut(strings.TrimSpace(text), " ")
+	return command, strings.TrimSpace(arg)
+}
+
+// Ensure Handler implements bot.Handler
+var _ bot.Handler = (*Handler)(nil)
@@ -0,0 +1,85 @@
+package role
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/moduletest"
+	"github.com/garyellow/ntpu-linebot-go/internal/sticker"
+)
+
+const (
+	adminID = "Uaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	staffID = "U0123456789abcdef0123456789abcdef"
+)
+
+func newTestHandler(t *testing.T) *Handler {
+	t.Helper()
+	return NewHandler(moduletest.OpenStore(t, Open), []string{adminID}, sticker.NewManager(nil, nil, logger.New("error")))
+}
+
+func TestHandler_CanHandle(t *testing.T) {
+	t.Parallel()
+	h := newTestHandler(t)
+
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"教職員驗證", true},
+		{"教職員驗證 資工系 王小明", true},
+		{"我的身分", true},
+		{"驗證申請", true},
+		{"授權教職員 " + staffID, true},
+		{"撤銷身分 " + staffID, true},
+		{"我的身分證", false},
+		{"驗證申請 abc", false},
+		{"教職員", false},
+	}
+	for _, tt := range tests {
+		if got := h.CanHandle(tt.input); got != tt.want {
+			t.Errorf("CanHandle(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestHandler_VerificationFlow(t *testing.T) {
+	t.Parallel()
+	h := newTestHandler(t)
+	staff := moduletest.ChatContext(staffID, staffID)
+	admin := moduletest.ChatContext(adminID, adminID)
+
+	if got := moduletest.Text(t, h.HandleMessage(moduletest.ChatContext(staffID, "C1"), "我的身分")); !strings.Contains(got, "1 對 1") {
+		t.Errorf("group reply = %q, want 1:1 only notice", got)
+	}
+	if got := moduletest.Text(t, h.HandleMessage(staff, "教職員驗證")); !strings.Contains(got, "單位與姓名") {
+		t.Errorf("request without note = %q, want usage", got)
+	}
+	if got := moduletest.Text(t, h.HandleMessage(staff, "教職員驗證 資工系 王小明")); !strings.Contains(got, "已送出") {
+		t.Errorf("request reply = %q, want submitted", got)
+	}
+	if got := moduletest.Text(t, h.HandleMessage(staff, "授權教職員 "+staffID)); !strings.Contains(got, "僅限管理員") {
+		t.Errorf("non-admin grant = %q, want refused", got)
+	}
+
+	msgs := h.HandleMessage(admin, "驗證申請")
+	if got := moduletest.Text(t, msgs); !strings.Contains(got, "資工系 王小明") || !strings.Contains(got, staffID) {
+		t.Errorf("request list = %q, want the pending request", got)
+	}
+	if got := moduletest.Text(t, h.HandleMessage(admin, "授權教職員 not-an-id")); !strings.Contains(got, "用法") {
+		t.Errorf("grant with bad ID = %q, want usage", got)
+	}
+	if got := moduletest.Text(t, h.HandleMessage(admin, "授權教職員 "+staffID)); !strings.Contains(got, "已授權") {
+		t.Errorf("grant reply = %q, want granted", got)
+	}
+	if got := moduletest.Text(t, h.HandleMessage(staff, "我的身分")); !strings.Contains(got, "教職員") {
+		t.Errorf("whoami after grant = %q, want staff", got)
+	}
+	if got := moduletest.Text(t, h.HandleMessage(admin, "撤銷身分 "+staffID)); !strings.Contains(got, "已撤銷") {
+		t.Errorf("revoke reply = %q, want revoked", got)
+	}
+	if got := moduletest.Text(t, h.HandleMessage(staff, "我的身分")); !strings.Contains(got, "一般使用者") {
+		t.Errorf("whoami after revoke = %q, want regular user", got)
+	}
+}
@@ -0,0 +1,218 @@
+package role
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+)
+
+// Role is a verified user role. The zero value means no role.
+type Role string
+
+// Roles that can be granted.
+const (
+	Staff Role = "staff" // Verified faculty or staff member
+)
+
+// Label returns the role's display name.
+func (r Role) Label() string {
+	switch r {
+	case Staff:
+		return "教職員"
+	default:
+		return "一般使用者"
+	}
+}
+
+// maxNoteLength caps the self-description attached to a verification request.
+const maxNoteLength = 100
+
+// Request is a pending verification request.
+type Request struct {
+	UserID      string
+	Role        Role
+	Note        string // What the user wrote about themselves, e.g. "資工系 王小明"
+	RequestedAt time.Time
+}
+
+// Store persists granted roles and pending verification requests in SQLite.
+//
+// Roles live in their own file (not the cache DB) so they survive snapshot
+// hot-swaps and cache rebuilds.
+type Store struct {
+	*storage.AuxStore
+}
+
+// Open opens (or creates) the role database at path.
+func Open(ctx context.Context, path string) (*Store, error) {
+	db, err := storage.OpenAux(ctx, path, "role", initSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Store{AuxStore: storage.NewAuxStore(db)}, nil
+}
+
+func initSchema(ctx context.Context, db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS user_roles (
+		user_id TEXT PRIMARY KEY,
+		role TEXT NOT NULL,
+		granted_by TEXT NOT NULL,
+		granted_at INTEGER NOT NULL
+	) STRICT;
+	CREATE TABLE IF NOT EXISTS role_requests (
+		user_id TEXT PRIMARY KEY,
+		role TEXT NOT NULL,
+		note TEXT NOT NULL,
+		requested_at INTEGER NOT NULL
+	) STRICT;
+	`
+
+	if _, err := db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("create role tables: %w", err)
+	}
+
+	return nil
+}
+
+// Role returns the user's granted role, or "" if the user has none.
+func (s *Store) Role(ctx context.Context, userID string) (Role, error) {
+	db, err := s.Conn()
+	if err != nil {
+		return "", err
+	}
+
+	var role string
+	err = db.QueryRowContext(ctx,
+		"SELECT role FROM user_roles WHERE user_id = ?", userID,
+	).Scan(&role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("get role: %w", err)
+	}
+	return Role(role), nil
+}
+
+// RequestRole records (or replaces) the user's verification request.
+// note is truncated to maxNoteLength characters.
+func (s *Store) RequestRole(ctx context.Context, userID string, role Role, note string) error {
+	db, err := s.Conn()
+	if err != nil {
+		return err
+	}
+
+	if runes := []rune(note); len(runes) > maxNoteLength {
+		note = string(runes[:maxNoteLength])
+	}
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO role_requests (user_id, role, note, requested_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(user_id) DO UPDATE SET role = excluded.role, note = excluded.note, requested_at = excluded.requested_at
+	`, userID, string(role), note, time.Now().Unix())
+	if err != nil {
+		return fmt.Errorf("request role: %w", err)
+	}
+	return nil
+}
+
+// Requests returns up to limit pending requests, oldest first.
+func (s *Store) Requests(ctx context.Context, limit int) ([]Request, error) {
+	db, err := s.Conn()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx,
+		"SELECT user_id, role, note, requested_at FROM role_requests ORDER BY requested_at, user_id LIMIT ?", limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list role requests: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var requests []Request
+	for rows.Next() {
+		var r Request
+		var role string
+		var requestedAt int64
+		if err := rows.Scan(&r.UserID, &role, &r.Note, &requestedAt); err != nil {
+			return nil, fmt.Errorf("scan role request: %w", err)
+		}
+		r.Role = Role(role)
+		r.RequestedAt = time.Unix(requestedAt, 0)
+		requests = append(requests, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list role requests: %w", err)
+	}
+	return requests, nil
+}
+
+// Grant gives the user a role and clears the user's pending request.
+func (s *Store) Grant(ctx context.Context, userID string, role Role, grantedBy string) error {
+	db, err := s.Conn()
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO user_roles (user_id, role, granted_by, granted_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(user_id) DO UPDATE SET role = excluded.role, granted_by = excluded.granted_by, granted_at = excluded.granted_at
+	`, userID, string(role), grantedBy, time.Now().Unix()); err != nil {
+		return fmt.Errorf("grant role: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM role_requests WHERE user_id = ?", userID); err != nil {
+		return fmt.Errorf("clear role request: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit role grant: %w", err)
+	}
+	return nil
+}
+
+// Revoke removes the user's role and pending request. Returns false if the
+// user had neither.
+func (s *Store) Revoke(ctx context.Context, userID string) (bool, error) {
+	n, err := s.EraseUser(ctx, userID)
+	return n > 0, err
+}
+
+// EraseUser deletes the user's role and pending request.
+// Returns the number of rows deleted.
+func (s *Store) EraseUser(ctx context.Context, userID string) (int64, error) {
+	db, err := s.Conn()
+	if err != nil {
+		return 0, err
+	}
+
+	var n int64
+	for _, query := range []string{
+		"DELETE FROM user_roles WHERE user_id = ?",
+		"DELETE FROM role_requests WHERE user_id = ?",
+	} {
+		result, err := db.ExecContext(ctx, query, userID)
+		if err != nil {
+			return 0, fmt.Errorf("erase role: %w", err)
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("erase role: %w", err)
+		}
+		n += affected
+	}
+	return n, nil
+}
@@ -0,0 +1,73 @@
+package role
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/moduletest"
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+)
+
+func TestStore_RequestAndGrant(t *testing.T) {
+	t.Parallel()
+	store := moduletest.OpenStore(t, Open)
+	ctx := context.Background()
+
+	if got, err := store.Role(ctx, "U1"); err != nil || got != "" {
+		t.Fatalf("Role() before grant = (%q, %v), want empty", got, err)
+	}
+	if err := store.RequestRole(ctx, "U1", Staff, "資工系 王小明"); err != nil {
+		t.Fatalf("RequestRole() error = %v", err)
+	}
+	if err := store.RequestRole(ctx, "U2", Staff, strings.Repeat("長", maxNoteLength+10)); err != nil {
+		t.Fatalf("RequestRole() error = %v", err)
+	}
+
+	requests, err := store.Requests(ctx, 10)
+	if err != nil || len(requests) != 2 {
+		t.Fatalf("Requests() = (%v, %v), want 2 requests", requests, err)
+	}
+	for _, r := range requests {
+		if r.UserID == "U2" && len([]rune(r.Note)) != maxNoteLength {
+			t.Errorf("note length = %d, want %d", len([]rune(r.Note)), maxNoteLength)
+		}
+	}
+
+	if err := store.Grant(ctx, "U1", Staff, "Uadmin"); err != nil {
+		t.Fatalf("Grant() error = %v", err)
+	}
+	if got, _ := store.Role(ctx, "U1"); got != Staff {
+		t.Errorf("Role() after grant = %q, want %q", got, Staff)
+	}
+	if requests, _ := store.Requests(ctx, 10); len(requests) != 1 || requests[0].UserID != "U2" {
+		t.Errorf("Requests() after grant = %v, want only U2", requests)
+	}
+
+	revoked, err := store.Revoke(ctx, "U1")
+	if err != nil || !revoked {
+		t.Fatalf("Revoke() = (%v, %v), want true", revoked, err)
+	}
+	if revoked, _ := store.Revoke(ctx, "U1"); revoked {
+		t.Error("Revoke() twice = true, want false")
+	}
+	if got, _ := store.Role(ctx, "U1"); got != "" {
+		t.Errorf("Role() after revoke = %q, want empty", got)
+	}
+
+	if n, err := store.EraseUser(ctx, "U2"); err != nil || n != 1 {
+		t.Errorf("EraseUser(U2) = (%d, %v), want the pending request deleted", n, err)
+	}
+}
+
+func TestStore_Closed(t *testing.T) {
+	t.Parallel()
+	store := moduletest.OpenStore(t, Open)
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if _, err := store.Role(context.Background(), "U1"); !errors.Is(err, storage.ErrDatabaseClosed) {
+		t.Errorf("Role() after Close error = %v, want storage.ErrDatabaseClosed", err)
+	}
+}
@@ -0,0 +1,142 @@
+// Package share implements the share module for the LINE bot.
+// Detail bubbles carry a 分享 button; tapping it replies with a deep link that
+// opens the bot with the same query prefilled, a button to forward it to other
+// chats, and (when a public base URL is configured) a printable QR code.
+package share
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/bot"
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/sticker"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+// Module constants
+const (
+	ModuleName = "share"
+	senderName = "分享小幫手"
+)
+
+// Handler replies to 分享 postbacks. It has no text keywords.
+type Handler struct {
+	linker         *Linker
+	baseURL        string // public HTTPS origin for QR images; empty = no QR image
+	stickerManager *sticker.Manager
+}
+
+// NewHandler creates a new share handler.
+// baseURL is optional; without it the reply omits the QR image.
+func NewHandler(linker *Linker, baseURL string, stickerManager *sticker.Manager) *Handler {
+	return &Handler{
+		linker:         linker,
+		baseURL:        baseURL,
+		stickerManager: stickerManager,
+	}
+}
+
+// Name returns the module name
+func (h *Handler) Name() string {
+	return ModuleName
+}
+
+// CanHandle always returns false; sharing is reached only through postbacks.
+func (h *Handler) CanHandle(string) bool {
+	return false
+}
+
+// HandleMessage is unused because CanHandle never matches.
+func (h *Handler) HandleMessage(context.Context, string) []messaging_api.MessageInterface {
+	return []messaging_api.MessageInterface{}
+}
+
+// HandlePostback handles "share:link$v1$q={query}".
+func (h *Handler) HandlePostback(ctx context.Context, data string) []messaging_api.MessageInterface {
+	pb, err := bot.DecodePostback(data)
+	if err != nil || pb.Action != actionLink {
+		logger.FromContext(ctx).WithField("data", data).WarnContext(ctx, "Unknown share postback")
+		return []messaging_api.MessageInterface{}
+	}
+	query := pb.Get("q")
+	if err := ValidateQuery(query); err != nil {
+		logger.FromContext(ctx).WithError(err).WarnContext(ctx, "Invalid share query")
+		return []messaging_api.MessageInterface{}
+	}
+
+	sender := lineutil.GetSender(senderName, h.stickerManager)
+	quickReply := lineutil.NewQuickReply(lineutil.QuickReplyMainNavCompact())
+
+	msg := h.buildShareMessage(query, sender)
+	if h.baseURL == "" {
+		msg.QuickReply = quickReply
+		return []messaging_api.MessageInterface{msg}
+	}
+
+	// The QR image is a separate message so it can be saved or printed on its own.
+	qrURL := h.baseURL + "/share/qr.png?q=" + url.QueryEscape(query)
+	img := &messaging_api.ImageMessage{
+		OriginalContentUrl: qrURL,
+		PreviewImageUrl:    qrURL,
+		QuickReply:         quickReply,
+	}
+	img.Sender = sender
+	return []messaging_api.MessageInterface{msg, img}
+}
+
+// buildShareMessage creates the share link bubble.
+//
+// Layout (Colored Header pattern):
+//
+//	┌──────────────────────────┐
+//	│   📤 分享查詢            │  <- Colored header (sky blue)
+//	├──────────────────────────┤
+//	│ 🔍 查詢內容  課程 ...    │
+//	│ 🔗 連結      https://... │
+//	│ 💡 好友點開連結即可查詢   │
+//	├──────────────────────────┤
+//	│ [📤 傳給好友][📋 複製連結]│
+//	└──────────────────────────┘
+func (h *Handler) buildShareMessage(query string, sender *messaging_api.Sender) *messaging_api.FlexMessage {
+	link := h.linker.DeepLink(query)
+
+	header := lineutil.NewColoredHeader(lineutil.ColoredHeaderInfo{
+		Title: "📤 分享查詢",
+		Color: lineutil.ColorHeaderInfo,
+	})
+
+	body := lineutil.NewBodyContentBuilder()
+	wrapStyle := lineutil.DefaultInfoRowStyle()
+	wrapStyle.Wrap = true
+	body.AddInfoRow(lineutil.Icon(lineutil.IconSearch), "查詢內容", query, wrapStyle)
+	linkStyle := wrapStyle
+	linkStyle.ValueSize = "xs"
+	body.AddInfoRow(lineutil.Icon(lineutil.IconLink), "連結", link, linkStyle)
+	body.AddComponent(lineutil.NewFlexText("💡 好友點開連結會開啟本帳號聊天並帶入查詢，送出即可看到相同結果").
+		WithSize("xs").WithColor(lineutil.ColorSubtext).WithWrap(true).WithMargin("md").FlexText)
+
+	shareText := "在 NTPU 小工具查「" + query + "」：\n" + link
+	footer := lineutil.NewButtonFooter([]*lineutil.FlexButton{
+		lineutil.NewFlexButton(
+			lineutil.NewURIAction("📤 傳給好友", "https://line.me/R/share?text="+escape(shareText)),
+		).WithStyle("primary").WithColor(lineutil.ColorButtonExternal).WithHeight("sm"),
+		lineutil.NewFlexButton(
+			lineutil.NewClipboardAction("📋 複製連結", link),
+		).WithStyle("secondary").WithHeight("sm"),
+	})
+
+	bubble := lineutil.NewFlexBubble(header, nil, body.Build(), footer)
+	msg := lineutil.NewFlexMessage(lineutil.FormatLabel("分享", query, 400), bubble.FlexBubble)
+	msg.Sender = sender
+	return msg
+}
+
+// QRCode returns the PNG QR code for a shared query (served by the HTTP route).
+func (h *Handler) QRCode(query string) ([]byte, error) {
+	return h.linker.QRCode(query)
+}
+
+// Ensure Handler implements bot.Handler
+var _ bot.Handler = (*Handler)(nil)
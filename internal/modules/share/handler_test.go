@@ -0,0 +1,81 @@
+package share
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/sticker"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+func newTestHandler(baseURL string) *Handler {
+	log := logger.New("error")
+	return NewHandler(NewLinker("@123abcde"), baseURL, sticker.NewManager(nil, nil, log))
+}
+
+func TestHandlePostback(t *testing.T) {
+	t.Parallel()
+
+	action, ok := NewLinker("@123abcde").Button("課程 1131U0001").Action.(*messaging_api.PostbackAction)
+	if !ok {
+		t.Fatal("expected a postback button")
+	}
+
+	tests := []struct {
+		name     string
+		baseURL  string
+		wantMsgs int
+	}{
+		{"without base URL", "", 1},
+		{"with base URL", "https://bot.example.com", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			msgs := newTestHandler(tt.baseURL).HandlePostback(context.Background(), action.Data)
+			if len(msgs) != tt.wantMsgs {
+				t.Fatalf("HandlePostback() returned %d messages, want %d", len(msgs), tt.wantMsgs)
+			}
+			if _, ok := msgs[0].(*messaging_api.FlexMessage); !ok {
+				t.Errorf("first message = %T, want *FlexMessage", msgs[0])
+			}
+			if tt.wantMsgs < 2 {
+				return
+			}
+			img, ok := msgs[1].(*messaging_api.ImageMessage)
+			if !ok {
+				t.Fatalf("second message = %T, want *ImageMessage", msgs[1])
+			}
+			if !strings.HasPrefix(img.OriginalContentUrl, "https://bot.example.com/share/qr.png?q=") {
+				t.Errorf("QR URL = %q", img.OriginalContentUrl)
+			}
+		})
+	}
+}
+
+func TestHandlePostback_Invalid(t *testing.T) {
+	t.Parallel()
+	h := newTestHandler("https://bot.example.com")
+
+	for _, data := range []string{
+		"share:unknown$v1$q=x",
+		"share:link$v1$q=",
+		"share:link",
+	} {
+		if msgs := h.HandlePostback(context.Background(), data); len(msgs) != 0 {
+			t.Errorf("HandlePostback(%q) returned %d messages, want 0", data, len(msgs))
+		}
+	}
+}
+
+func TestCanHandle(t *testing.T) {
+	t.Parallel()
+	h := newTestHandler("")
+	for _, text := range []string{"分享", "share", "課程 1131U0001"} {
+		if h.CanHandle(text) {
+			t.Errorf("CanHandle(%q) = true, want false", text)
+		}
+	}
+}
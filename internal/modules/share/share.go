@@ -0,0 +1,92 @@
+package share
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/bot"
+	domerrors "github.com/garyellow/ntpu-linebot-go/internal/errors"
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	// MaxQueryLength bounds shared queries (in runes); bot queries are short,
+	// and the limit keeps QR codes scannable and the public QR route cheap.
+	MaxQueryLength = 100
+
+	// qrSize is the QR image edge length in pixels (LINE shows it at ~240px;
+	// the larger source prints cleanly).
+	qrSize = 512
+
+	// actionLink is the postback action that asks for a share link.
+	actionLink = "link"
+)
+
+// Linker builds share links for one bot account. A nil *Linker is valid and
+// disables sharing, so modules can hold one unconditionally.
+type Linker struct {
+	botID string // basic ID, e.g. @123abcde
+}
+
+// NewLinker creates a linker for the bot with the given basic ID.
+func NewLinker(botID string) *Linker {
+	return &Linker{botID: botID}
+}
+
+// DeepLink returns a link that opens a chat with the bot and prefills query
+// in the message box. It is the https form of line://oaMessage/{id}/?{text},
+// which also works outside the LINE app (e.g., scanned from a printed QR code).
+func (l *Linker) DeepLink(query string) string {
+	return "https://line.me/R/oaMessage/" + escape(l.botID) + "/?" + escape(query)
+}
+
+// Button returns the 分享 footer button for a detail bubble, or nil when
+// sharing is disabled or the query cannot be shared.
+func (l *Linker) Button(query string) *lineutil.FlexButton {
+	if l == nil || ValidateQuery(query) != nil {
+		return nil
+	}
+	data, err := bot.NewPostback(ModuleName, actionLink).With("q", query).Encode()
+	if err != nil {
+		return nil
+	}
+	return lineutil.NewFlexButton(
+		lineutil.NewPostbackActionWithDisplayText("📤 分享", "分享「"+lineutil.TruncateRunes(query, 30)+"」", data),
+	).WithStyle("secondary").WithHeight("sm")
+}
+
+// QRCode returns a PNG QR code encoding the deep link for query.
+func (l *Linker) QRCode(query string) ([]byte, error) {
+	if err := ValidateQuery(query); err != nil {
+		return nil, err
+	}
+	png, err := qrcode.Encode(l.DeepLink(query), qrcode.Medium, qrSize)
+	if err != nil {
+		return nil, fmt.Errorf("encode qr code: %w", err)
+	}
+	return png, nil
+}
+
+// ValidateQuery checks that a query is non-empty, short, and free of control
+// characters. Returns an error wrapping domerrors.ErrInvalidInput otherwise.
+func ValidateQuery(query string) error {
+	if strings.TrimSpace(query) == "" {
+		return fmt.Errorf("%w: empty query", domerrors.ErrInvalidInput)
+	}
+	if n := utf8.RuneCountInString(query); n > MaxQueryLength {
+		return fmt.Errorf("%w: query has %d characters (max %d)", domerrors.ErrInvalidInput, n, MaxQueryLength)
+	}
+	if strings.ContainsFunc(query, unicode.IsControl) {
+		return fmt.Errorf("%w: query contains control characters", domerrors.ErrInvalidInput)
+	}
+	return nil
+}
+
+// escape percent-encodes s for LINE URL schemes, which expect %20 for spaces.
+func escape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
@@ -0,0 +1,114 @@
+package share
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/bot"
+	domerrors "github.com/garyellow/ntpu-linebot-go/internal/errors"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+func TestDeepLink(t *testing.T) {
+	t.Parallel()
+	l := NewLinker("@123abcde")
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"課程 1131U0001", "https://line.me/R/oaMessage/%40123abcde/?%E8%AA%B2%E7%A8%8B%201131U0001"},
+		{"a+b&c", "https://line.me/R/oaMessage/%40123abcde/?a%2Bb%26c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			t.Parallel()
+			if got := l.DeepLink(tt.query); got != tt.want {
+				t.Errorf("DeepLink(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateQuery(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		query   string
+		wantErr bool
+	}{
+		{"course query", "課程 1131U0001", false},
+		{"max length", strings.Repeat("課", MaxQueryLength), false},
+		{"empty", "", true},
+		{"whitespace", "   ", true},
+		{"too long", strings.Repeat("課", MaxQueryLength+1), true},
+		{"newline", "課程\n微積分", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := ValidateQuery(tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateQuery(%q) error = %v, wantErr %v", tt.query, err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, domerrors.ErrInvalidInput) {
+				t.Errorf("error %v does not wrap ErrInvalidInput", err)
+			}
+		})
+	}
+}
+
+func TestButton(t *testing.T) {
+	t.Parallel()
+
+	var disabled *Linker
+	if btn := disabled.Button("課程 1131U0001"); btn != nil {
+		t.Error("nil Linker should not produce a button")
+	}
+
+	l := NewLinker("@123abcde")
+	if btn := l.Button(""); btn != nil {
+		t.Error("empty query should not produce a button")
+	}
+
+	btn := l.Button("課程 1131U0001")
+	if btn == nil {
+		t.Fatal("Button() = nil")
+	}
+	action, ok := btn.Action.(*messaging_api.PostbackAction)
+	if !ok {
+		t.Fatalf("Button action = %T, want *PostbackAction", btn.Action)
+	}
+	pb, err := bot.DecodePostback(action.Data)
+	if err != nil {
+		t.Fatalf("DecodePostback(%q) error: %v", action.Data, err)
+	}
+	if pb.Module != ModuleName || pb.Action != actionLink || pb.Get("q") != "課程 1131U0001" {
+		t.Errorf("postback = %+v, want share link for the query", pb)
+	}
+}
+
+func TestQRCode(t *testing.T) {
+	t.Parallel()
+	l := NewLinker("@123abcde")
+
+	data, err := l.QRCode("課程 1131U0001")
+	if err != nil {
+		t.Fatalf("QRCode() error: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("QRCode() is not a PNG: %v", err)
+	}
+	if w := img.Bounds().Dx(); w != qrSize {
+		t.Errorf("QR width = %d, want %d", w, qrSize)
+	}
+
+	if _, err := l.QRCode(""); !errors.Is(err, domerrors.ErrInvalidInput) {
+		t.Errorf("QRCode(\"\") error = %v, want ErrInvalidInput", err)
+	}
+}
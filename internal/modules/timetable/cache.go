@@ -0,0 +1,65 @@
+package timetable
+
+import (
+	"container/list"
+	"sync"
+)
+
+// Cache is a bounded in-memory LRU of rendered images keyed by content hash.
+// It is safe for concurrent use.
+type Cache struct {
+	mu         sync.Mutex
+	maxEntries int
+	order      *list.List // front = most recently used
+	entries    map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key string
+	img []byte
+}
+
+// NewCache creates a cache holding at most maxEntries images (minimum 1).
+func NewCache(maxEntries int) *Cache {
+	return &Cache{
+		maxEntries: max(1, maxEntries),
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Get returns the image for key and marks it as recently used.
+func (c *Cache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*cacheEntry).img, true
+}
+
+// Put stores an image, evicting the least recently used entry when full.
+func (c *Cache) Put(key string, img []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		el.Value.(*cacheEntry).img = img
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, img: img})
+	if c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// Len returns the number of cached images.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
@@ -0,0 +1,236 @@
+// Package timetable implements the timetable image module for the LINE bot.
+// It draws a weekly grid for a list of course UIDs and replies with an image
+// message; the image itself is served by the app's /timetable route.
+package timetable
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/bot"
+	domerrors "github.com/garyellow/ntpu-linebot-go/internal/errors"
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/scraper/ntpu"
+	"github.com/garyellow/ntpu-linebot-go/internal/sticker"
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+// Module constants
+const (
+	ModuleName = "timetable"
+	senderName = "課表小幫手"
+
+	// MaxCourses bounds one timetable so images stay legible and cheap to draw.
+	MaxCourses = 20
+
+	// imageCacheSize is the number of rendered PNGs kept in memory (~100 KB each).
+	imageCacheSize = 256
+)
+
+var (
+	timetableKeywords = []string{"課表", "timetable"}
+	timetableRegex    = bot.BuildKeywordRegex(timetableKeywords)
+)
+
+// Handler handles timetable image requests.
+type Handler struct {
+	db             *storage.DB
+	renderer       *Renderer
+	baseURL        string // public HTTPS origin for image URLs, without trailing slash
+	stickerManager *sticker.Manager
+}
+
+// NewHandler creates a new timetable handler.
+func NewHandler(
+	db *storage.DB,
+	renderer *Renderer,
+	baseURL string,
+	stickerManager *sticker.Manager,
+) *Handler {
+	return &Handler{
+		db:             db,
+		renderer:       renderer,
+		baseURL:        strings.TrimSuffix(baseURL, "/"),
+		stickerManager: stickerManager,
+	}
+}
+
+// Name returns the module name
+func (h *Handler) Name() string {
+	return ModuleName
+}
+
+// CanHandle returns true if the text starts with a timetable keyword.
+func (h *Handler) CanHandle(text string) bool {
+	return timetableRegex.MatchString(strings.TrimSpace(text))
+}
+
+// HandleMessage renders the timetable for the UIDs in the message.
+//
+//	課表 1131U0001 1131U0002
+func (h *Handler) HandleMessage(ctx context.Context, text string) []messaging_api.MessageInterface {
+	log := logger.FromContext(ctx)
+	sender := lineutil.GetSender(senderName, h.stickerManager)
+
+	uids := ParseUIDs(text)
+	if len(uids) == 0 {
+		return []messaging_api.MessageInterface{h.usageMessage(sender)}
+	}
+	if len(uids) > MaxCourses {
+		msg := lineutil.NewTextMessageWithConsistentSender(
+			fmt.Sprintf("⚠️ 一次最多排入 %d 門課程，目前輸入了 %d 門", MaxCourses, len(uids)), sender)
+		msg.QuickReply = lineutil.NewQuickReply(lineutil.QuickReplyMainNavCompact())
+		return []messaging_api.MessageInterface{msg}
+	}
+
+	courses, missing, err := h.loadCourses(ctx, uids)
+	if err != nil {
+		log.WithError(err).ErrorContext(ctx, "Failed to load timetable courses")
+		return []messaging_api.MessageInterface{
+			lineutil.ErrorMessageWithQuickReply("查詢課程時發生問題", sender, text),
+		}
+	}
+	if len(courses) == 0 {
+		msg := lineutil.NewTextMessageWithConsistentSender(
+			fmt.Sprintf("🔍 查無課程：%s\n\n💡 請確認課程編號（例如 1131U0001）是否正確", strings.Join(missing, "、")), sender)
+		msg.QuickReply = lineutil.NewQuickReply(lineutil.QuickReplyCourseNav(false))
+		return []messaging_api.MessageInterface{msg}
+	}
+
+	key, _, err := h.renderer.Render(courses)
+	if errors.Is(err, ErrNoMeetings) {
+		msg := lineutil.NewTextMessageWithConsistentSender("📅 這些課程沒有固定的上課時間，無法排出課表", sender)
+		msg.QuickReply = lineutil.NewQuickReply(lineutil.QuickReplyCourseNav(false))
+		return []messaging_api.MessageInterface{msg}
+	}
+	if err != nil {
+		log.WithError(err).ErrorContext(ctx, "Failed to render timetable")
+		return []messaging_api.MessageInterface{
+			lineutil.ErrorMessageWithQuickReply("產生課表圖片時發生問題", sender, text),
+		}
+	}
+
+	log.WithField("courses", len(courses)).
+		WithField("key", key).
+		DebugContext(ctx, "Timetable rendered")
+
+	imageURL := h.ImageURL(key, courses)
+	img := &messaging_api.ImageMessage{
+		OriginalContentUrl: imageURL,
+		PreviewImageUrl:    imageURL,
+	}
+	img.Sender = sender
+	img.QuickReply = lineutil.NewQuickReply(lineutil.QuickReplyCourseNav(false))
+
+	if len(missing) > 0 {
+		note := lineutil.NewTextMessageWithConsistentSender(
+			fmt.Sprintf("⚠️ 查無以下課程，未排入課表：%s", strings.Join(missing, "、")), sender)
+		return []messaging_api.MessageInterface{note, img}
+	}
+	return []messaging_api.MessageInterface{img}
+}
+
+// HandlePostback handles postback events for the timetable module.
+// Format: "timetable:{uid} {uid} ..." renders the listed courses.
+func (h *Handler) HandlePostback(ctx context.Context, data string) []messaging_api.MessageInterface {
+	data = strings.TrimPrefix(data, ModuleName+":")
+	if len(ParseUIDs(data)) == 0 {
+		return []messaging_api.MessageInterface{}
+	}
+	return h.HandleMessage(ctx, timetableKeywords[0]+" "+data)
+}
+
+// ImageURL returns the public URL of a rendered timetable. The UIDs travel in
+// the URL so any instance can re-render the image on a cache miss.
+func (h *Handler) ImageURL(key string, courses []storage.Course) string {
+	uids := make([]string, len(courses))
+	for i, c := range courses {
+		uids[i] = c.UID
+	}
+	return fmt.Sprintf("%s/timetable/%s.png?uids=%s", h.baseURL, key, url.QueryEscape(strings.Join(uids, ",")))
+}
+
+// ImagePNG returns the PNG for a timetable link. Images cached on this
+// instance are served by key; otherwise (restart, eviction, another instance
+// handled the message) the timetable is re-rendered from the UIDs.
+// Returns domerrors.ErrNotFound if none of the courses exists anymore.
+func (h *Handler) ImagePNG(ctx context.Context, key string, uids []string) ([]byte, error) {
+	if img, ok := h.renderer.Cached(key); ok {
+		return img, nil
+	}
+	if len(uids) == 0 || len(uids) > MaxCourses {
+		return nil, fmt.Errorf("%w: expected 1-%d course UIDs", domerrors.ErrInvalidInput, MaxCourses)
+	}
+	for _, uid := range uids {
+		if _, _, _, err := ntpu.ParseUID(uid); err != nil {
+			return nil, fmt.Errorf("%w: %w", domerrors.ErrInvalidInput, err)
+		}
+	}
+
+	courses, _, err := h.loadCourses(ctx, uids)
+	if err != nil {
+		return nil, err
+	}
+	if len(courses) == 0 {
+		return nil, domerrors.ErrNotFound
+	}
+	_, img, err := h.renderer.Render(courses)
+	if errors.Is(err, ErrNoMeetings) {
+		return nil, fmt.Errorf("%w: %w", domerrors.ErrNotFound, err)
+	}
+	return img, err
+}
+
+// loadCourses fetches courses by UID, returning the UIDs that were not found.
+func (h *Handler) loadCourses(ctx context.Context, uids []string) ([]storage.Course, []string, error) {
+	courses := make([]storage.Course, 0, len(uids))
+	var missing []string
+	for _, uid := range uids {
+		course, err := h.db.GetCourseByUID(ctx, strings.ToUpper(uid))
+		if err != nil {
+			return nil, nil, fmt.Errorf("get course %s: %w", uid, err)
+		}
+		if course == nil {
+			missing = append(missing, uid)
+			continue
+		}
+		courses = append(courses, *course)
+	}
+	return courses, missing, nil
+}
+
+// usageMessage explains how to request a timetable.
+func (h *Handler) usageMessage(sender *messaging_api.Sender) messaging_api.MessageInterface {
+	msg := lineutil.NewTextMessageWithConsistentSender(
+		"📅 課表圖片\n\n"+
+			"輸入「課表」加上課程編號，以空白分隔：\n"+
+			"課表 1131U0001 1131U0002\n\n"+
+			fmt.Sprintf("💡 最多 %d 門課程，課程編號可從課程查詢結果取得", MaxCourses), sender)
+	msg.QuickReply = lineutil.NewQuickReply(lineutil.QuickReplyCourseNav(false))
+	return msg
+}
+
+// ParseUIDs extracts unique, upper-cased course UIDs from text in order of appearance.
+// Matches that ntpu.ParseUID rejects (e.g. term 3) are skipped.
+func ParseUIDs(text string) []string {
+	var uids []string
+	for _, m := range ntpu.UIDRegex.FindAllString(text, -1) {
+		uid, err := ntpu.NormalizeUID(m)
+		if err != nil {
+			continue
+		}
+		if !slices.Contains(uids, uid) {
+			uids = append(uids, uid)
+		}
+	}
+	return uids
+}
+
+// Ensure Handler implements bot.Handler
+var _ bot.Handler = (*Handler)(nil)
@@ -0,0 +1,173 @@
+package timetable
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image/png"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	domerrors "github.com/garyellow/ntpu-linebot-go/internal/errors"
+	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/sticker"
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+func setupTestHandler(t *testing.T) *Handler {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	db, err := storage.New(context.Background(), filepath.Join(tmpDir, "test.db"), 168*time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close(context.Background()) })
+
+	log := logger.New("info")
+	stickerMgr := sticker.NewManager(db, nil, log)
+
+	err = db.SaveCourse(context.Background(), &storage.Course{
+		UID: "1131U0001", Year: 113, Term: 1, No: "U0001", Title: "Programming",
+		Times: []string{"每週一3~4"}, Locations: []string{"A101"},
+	})
+	if err != nil {
+		t.Fatalf("SaveCourse() error: %v", err)
+	}
+
+	return NewHandler(db, newTestRenderer(t), "https://bot.example.com/", stickerMgr)
+}
+
+func TestCanHandle(t *testing.T) {
+	t.Parallel()
+	h := setupTestHandler(t)
+
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"課表", true},
+		{"課表 1131U0001", true},
+		{"timetable 1131U0001", true},
+		{"  課表  ", true},
+		{"課表查詢", false}, // keyword must be followed by a space
+		{"課程 1131U0001", false},
+		{"1131U0001", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+			if got := h.CanHandle(tt.input); got != tt.want {
+				t.Errorf("CanHandle(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseUIDs(t *testing.T) {
+	t.Parallel()
+
+	got := ParseUIDs("課表 1131u0001, 1131U0002 1131U0001 1122M0003")
+	want := []string{"1131U0001", "1131U0002", "1122M0003"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("ParseUIDs() = %v, want %v", got, want)
+	}
+}
+
+func TestHandleMessage(t *testing.T) {
+	t.Parallel()
+	h := setupTestHandler(t)
+	ctx := context.Background()
+
+	t.Run("usage without UIDs", func(t *testing.T) {
+		t.Parallel()
+		msgs := h.HandleMessage(ctx, "課表")
+		if len(msgs) != 1 {
+			t.Fatalf("got %d messages, want 1", len(msgs))
+		}
+		text, ok := msgs[0].(*messaging_api.TextMessageV2)
+		if !ok || !strings.Contains(text.Text, "課表 1131U0001") {
+			t.Errorf("expected usage text, got %#v", msgs[0])
+		}
+	})
+
+	t.Run("image with missing course note", func(t *testing.T) {
+		t.Parallel()
+		msgs := h.HandleMessage(ctx, "課表 1131U0001 1131U9999")
+		if len(msgs) != 2 {
+			t.Fatalf("got %d messages, want note + image", len(msgs))
+		}
+		if text, ok := msgs[0].(*messaging_api.TextMessageV2); !ok || !strings.Contains(text.Text, "1131U9999") {
+			t.Errorf("first message should list the missing UID, got %#v", msgs[0])
+		}
+		img, ok := msgs[1].(*messaging_api.ImageMessage)
+		if !ok {
+			t.Fatalf("second message should be an image, got %T", msgs[1])
+		}
+		if !strings.HasPrefix(img.OriginalContentUrl, "https://bot.example.com/timetable/") ||
+			!strings.HasSuffix(img.OriginalContentUrl, ".png?uids=1131U0001") {
+			t.Errorf("unexpected image URL %q", img.OriginalContentUrl)
+		}
+		if img.QuickReply == nil {
+			t.Error("image message should carry the quick reply")
+		}
+	})
+
+	t.Run("all courses missing", func(t *testing.T) {
+		t.Parallel()
+		msgs := h.HandleMessage(ctx, "課表 1131U9999")
+		if _, ok := msgs[0].(*messaging_api.TextMessageV2); !ok || len(msgs) != 1 {
+			t.Errorf("expected a single not-found text, got %#v", msgs)
+		}
+	})
+
+	t.Run("too many courses", func(t *testing.T) {
+		t.Parallel()
+		text := "課表"
+		for i := range MaxCourses + 1 {
+			text += fmt.Sprintf(" 1131U%04d", i)
+		}
+		msgs := h.HandleMessage(ctx, text)
+		reply, ok := msgs[0].(*messaging_api.TextMessageV2)
+		if !ok || !strings.Contains(reply.Text, "最多") {
+			t.Errorf("expected limit message, got %#v", msgs[0])
+		}
+	})
+}
+
+func TestImagePNG(t *testing.T) {
+	t.Parallel()
+	h := setupTestHandler(t)
+	ctx := context.Background()
+
+	data, err := h.ImagePNG(ctx, "unknown", []string{"1131U0001"})
+	if err != nil {
+		t.Fatalf("ImagePNG() error: %v", err)
+	}
+	if _, err := png.Decode(bytes.NewReader(data)); err != nil {
+		t.Fatalf("ImagePNG() did not return a PNG: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		uids []string
+		want error
+	}{
+		{"no UIDs", nil, domerrors.ErrInvalidInput},
+		{"malformed UID", []string{"1131U0001; DROP"}, domerrors.ErrInvalidInput},
+		{"unknown course", []string{"1131U9999"}, domerrors.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := h.ImagePNG(ctx, "unknown", tt.uids); !errors.Is(err, tt.want) {
+				t.Errorf("ImagePNG() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
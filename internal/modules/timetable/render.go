@@ -0,0 +1,423 @@
+package timetable
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// layoutVersion is mixed into cache keys so that layout changes invalidate
+// images already cached by clients and the server.
+const layoutVersion = "v1"
+
+// Grid geometry in pixels.
+const (
+	margin         = 24
+	titleHeight    = 56
+	headerHeight   = 44
+	periodColWidth = 96
+	dayColWidth    = 150
+	rowHeight      = 64
+	blockPadding   = 6
+	minLastPeriod  = 9 // always show the daytime periods so grids look alike
+)
+
+// Font sizes in points (72 DPI, so points equal pixels).
+const (
+	titleSize = 22
+	labelSize = 17
+	smallSize = 12
+	blockSize = 15
+)
+
+var (
+	// ErrNoMeetings is returned when none of the courses has a schedulable meeting
+	// (e.g., every time slot is "每週未維護").
+	ErrNoMeetings = errors.New("timetable: no schedulable meetings")
+
+	colorBackground = color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}
+	colorHeaderFill = color.RGBA{0xF1, 0xF3, 0xF5, 0xFF}
+	colorGrid       = color.RGBA{0xDE, 0xE2, 0xE6, 0xFF}
+	colorText       = color.RGBA{0x21, 0x25, 0x29, 0xFF}
+	colorSubtext    = color.RGBA{0x6C, 0x75, 0x7D, 0xFF}
+
+	// blockColors are pastel fills that keep dark text readable.
+	blockColors = []color.RGBA{
+		{0xCF, 0xE2, 0xFF, 0xFF}, // blue
+		{0xD1, 0xF2, 0xDE, 0xFF}, // green
+		{0xFF, 0xE5, 0xB4, 0xFF}, // amber
+		{0xF8, 0xD7, 0xDA, 0xFF}, // rose
+		{0xE2, 0xD9, 0xF3, 0xFF}, // violet
+		{0xCF, 0xF4, 0xFC, 0xFF}, // cyan
+		{0xFF, 0xD8, 0xC2, 0xFF}, // orange
+		{0xE9, 0xEC, 0xB8, 0xFF}, // olive
+	}
+
+	weekdayLabels = map[time.Weekday]string{
+		time.Monday: "一", time.Tuesday: "二", time.Wednesday: "三", time.Thursday: "四",
+		time.Friday: "五", time.Saturday: "六", time.Sunday: "日",
+	}
+)
+
+// Renderer draws weekday × period grid images with one colored block per
+// meeting. Rendered images are cached by a hash of the course content, so
+// repeated requests for the same courses are served without redrawing.
+// It is safe for concurrent use.
+type Renderer struct {
+	font  *opentype.Font
+	cache *Cache
+}
+
+// LoadFont reads a TrueType/OpenType font or font collection (.ttc) from disk.
+// The font must cover CJK characters for course titles to render.
+func LoadFont(path string) (*opentype.Font, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read font: %w", err)
+	}
+	return ParseFont(data)
+}
+
+// ParseFont parses font data, accepting both single fonts and collections
+// (the first font of a collection is used).
+func ParseFont(data []byte) (*opentype.Font, error) {
+	if f, err := opentype.Parse(data); err == nil {
+		return f, nil
+	}
+	collection, err := opentype.ParseCollection(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse font: %w", err)
+	}
+	f, err := collection.Font(0)
+	if err != nil {
+		return nil, fmt.Errorf("parse font collection: %w", err)
+	}
+	return f, nil
+}
+
+// NewRenderer creates a renderer drawing with the given font.
+func NewRenderer(f *opentype.Font) *Renderer {
+	return &Renderer{font: f, cache: NewCache(imageCacheSize)}
+}
+
+// Key returns the content hash identifying the timetable of a set of courses.
+// Order does not matter; any change to a course's title, times, or locations
+// produces a new key.
+func Key(courses []storage.Course) string {
+	sorted := slices.Clone(courses)
+	slices.SortFunc(sorted, func(a, b storage.Course) int { return strings.Compare(a.UID, b.UID) })
+
+	h := sha256.New()
+	h.Write([]byte(layoutVersion))
+	for _, c := range sorted {
+		fmt.Fprintf(h, "\x1e%s\x1f%d\x1f%d\x1f%s\x1f%s\x1f%s",
+			c.UID, c.Year, c.Term, c.Title, strings.Join(c.Times, "\x1d"), strings.Join(c.Locations, "\x1d"))
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// Cached returns a previously rendered image by key.
+func (r *Renderer) Cached(key string) ([]byte, bool) {
+	return r.cache.Get(key)
+}
+
+// Render returns the PNG timetable for the courses along with its cache key.
+// Returns ErrNoMeetings if no course has a parsable weekly meeting.
+func (r *Renderer) Render(courses []storage.Course) (string, []byte, error) {
+	key := Key(courses)
+	if img, ok := r.cache.Get(key); ok {
+		return key, img, nil
+	}
+
+	img, err := r.draw(courses)
+	if err != nil {
+		return "", nil, err
+	}
+	r.cache.Put(key, img)
+	return key, img, nil
+}
+
+// block is one meeting placed on the grid.
+type block struct {
+	course   int // index into the course list (selects the color)
+	meeting  lineutil.CourseMeeting
+	location string
+	lane     int
+	lanes    int
+}
+
+func (r *Renderer) draw(courses []storage.Course) ([]byte, error) {
+	blocks := layoutBlocks(courses)
+	if len(blocks) == 0 {
+		return nil, ErrNoMeetings
+	}
+
+	days := []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+	lastPeriod := minLastPeriod
+	var hasSaturday, hasSunday bool
+	for _, b := range blocks {
+		lastPeriod = max(lastPeriod, b.meeting.EndPeriod)
+		hasSaturday = hasSaturday || b.meeting.Weekday == time.Saturday
+		hasSunday = hasSunday || b.meeting.Weekday == time.Sunday
+	}
+	if hasSaturday || hasSunday {
+		days = append(days, time.Saturday)
+	}
+	if hasSunday {
+		days = append(days, time.Sunday)
+	}
+	dayColumn := make(map[time.Weekday]int, len(days))
+	for i, d := range days {
+		dayColumn[d] = i
+	}
+
+	gridLeft := margin
+	gridTop := margin + titleHeight
+	bodyTop := gridTop + headerHeight
+	width := margin*2 + periodColWidth + dayColWidth*len(days)
+	height := bodyTop + rowHeight*lastPeriod + margin
+
+	faces, err := r.newFaces()
+	if err != nil {
+		return nil, err
+	}
+	defer faces.close()
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	fill(img, img.Bounds(), colorBackground)
+
+	// Title
+	drawText(img, faces.title, colorText, margin, margin+titleSize+8, titleFor(courses))
+
+	// Header row and period column
+	gridRight := width - margin
+	gridBottom := height - margin
+	fill(img, image.Rect(gridLeft, gridTop, gridRight, bodyTop), colorHeaderFill)
+	fill(img, image.Rect(gridLeft, bodyTop, gridLeft+periodColWidth, gridBottom), colorHeaderFill)
+	for i, d := range days {
+		x := gridLeft + periodColWidth + i*dayColWidth
+		drawCentered(img, faces.label, colorText, x, gridTop, dayColWidth, headerHeight, "週"+weekdayLabels[d])
+	}
+	for p := 1; p <= lastPeriod; p++ {
+		y := bodyTop + (p-1)*rowHeight
+		start, end := lineutil.GetPeriodTime(p)
+		drawCentered(img, faces.label, colorText, gridLeft, y+4, periodColWidth, rowHeight/2, fmt.Sprintf("第 %d 節", p))
+		drawCentered(img, faces.small, colorSubtext, gridLeft, y+rowHeight/2-2, periodColWidth, rowHeight/2, start+"-"+end)
+	}
+
+	// Grid lines
+	for p := 0; p <= lastPeriod; p++ {
+		y := bodyTop + p*rowHeight
+		fill(img, image.Rect(gridLeft, y, gridRight, y+1), colorGrid)
+	}
+	fill(img, image.Rect(gridLeft, gridTop, gridRight, gridTop+1), colorGrid)
+	for i := 0; i <= len(days); i++ {
+		x := gridLeft + periodColWidth + i*dayColWidth
+		fill(img, image.Rect(x, gridTop, x+1, gridBottom), colorGrid)
+	}
+	fill(img, image.Rect(gridLeft, gridTop, gridLeft+1, gridBottom), colorGrid)
+
+	// Course blocks
+	for _, b := range blocks {
+		laneWidth := (dayColWidth - 2) / b.lanes
+		x0 := gridLeft + periodColWidth + dayColumn[b.meeting.Weekday]*dayColWidth + 2 + b.lane*laneWidth
+		y0 := bodyTop + (b.meeting.StartPeriod-1)*rowHeight + 2
+		rect := image.Rect(x0, y0, x0+laneWidth-2, bodyTop+b.meeting.EndPeriod*rowHeight-1)
+		fill(img, rect, blockColors[b.course%len(blockColors)])
+		drawBlockText(img, faces, rect, courses[b.course].Title, b.location)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("encode png: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// layoutBlocks parses every course meeting and assigns side-by-side lanes to
+// meetings that overlap on the same day, so conflicts stay visible.
+func layoutBlocks(courses []storage.Course) []block {
+	var blocks []block
+	for i, c := range courses {
+		for j, t := range c.Times {
+			m, ok := lineutil.ParseCourseMeeting(t)
+			if !ok {
+				continue
+			}
+			b := block{course: i, meeting: m}
+			if j < len(c.Locations) {
+				b.location = c.Locations[j]
+			}
+			blocks = append(blocks, b)
+		}
+	}
+
+	slices.SortStableFunc(blocks, func(a, b block) int {
+		if a.meeting.Weekday != b.meeting.Weekday {
+			return int(a.meeting.Weekday) - int(b.meeting.Weekday)
+		}
+		return a.meeting.StartPeriod - b.meeting.StartPeriod
+	})
+
+	// Group overlapping meetings into clusters and give each a lane.
+	for start := 0; start < len(blocks); {
+		end := start
+		clusterEnd := blocks[start].meeting.EndPeriod
+		var laneEnds []int // last period occupied per lane
+		for end < len(blocks) && blocks[end].meeting.Weekday == blocks[start].meeting.Weekday &&
+			blocks[end].meeting.StartPeriod <= clusterEnd {
+			b := &blocks[end]
+			lane := slices.IndexFunc(laneEnds, func(last int) bool { return last < b.meeting.StartPeriod })
+			if lane < 0 {
+				lane = len(laneEnds)
+				laneEnds = append(laneEnds, 0)
+			}
+			laneEnds[lane] = b.meeting.EndPeriod
+			b.lane = lane
+			clusterEnd = max(clusterEnd, b.meeting.EndPeriod)
+			end++
+		}
+		for k := start; k < end; k++ {
+			blocks[k].lanes = len(laneEnds)
+		}
+		start = end
+	}
+	return blocks
+}
+
+// titleFor describes the semester(s) the courses belong to.
+func titleFor(courses []storage.Course) string {
+	year, term := courses[0].Year, courses[0].Term
+	for _, c := range courses[1:] {
+		if c.Year != year || c.Term != term {
+			return "我的課表"
+		}
+	}
+	return fmt.Sprintf("%d 學年度第 %d 學期 課表", year, term)
+}
+
+// faces holds per-render font faces; opentype faces are not safe for
+// concurrent use, so each render creates its own.
+type faces struct {
+	title, label, small, block font.Face
+}
+
+func (r *Renderer) newFaces() (*faces, error) {
+	var f faces
+	for _, spec := range []struct {
+		dst  *font.Face
+		size float64
+	}{
+		{&f.title, titleSize}, {&f.label, labelSize}, {&f.small, smallSize}, {&f.block, blockSize},
+	} {
+		face, err := opentype.NewFace(r.font, &opentype.FaceOptions{Size: spec.size, DPI: 72, Hinting: font.HintingFull})
+		if err != nil {
+			f.close()
+			return nil, fmt.Errorf("create font face: %w", err)
+		}
+		*spec.dst = face
+	}
+	return &f, nil
+}
+
+func (f *faces) close() {
+	for _, face := range []font.Face{f.title, f.label, f.small, f.block} {
+		if face != nil {
+			_ = face.Close()
+		}
+	}
+}
+
+func fill(img *image.RGBA, r image.Rectangle, c color.Color) {
+	draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+// drawText draws s with its baseline at (x, y).
+func drawText(img *image.RGBA, face font.Face, c color.Color, x, y int, s string) {
+	d := font.Drawer{Dst: img, Src: image.NewUniform(c), Face: face, Dot: fixed.P(x, y)}
+	d.DrawString(s)
+}
+
+// drawCentered draws s centered within the box at (x, y) of size w×h.
+func drawCentered(img *image.RGBA, face font.Face, c color.Color, x, y, w, h int, s string) {
+	metrics := face.Metrics()
+	textWidth := font.MeasureString(face, s).Round()
+	textHeight := (metrics.Ascent + metrics.Descent).Round()
+	drawText(img, face, c, x+(w-textWidth)/2, y+(h-textHeight)/2+metrics.Ascent.Round(), s)
+}
+
+// drawBlockText fills a course block with the wrapped title and, space
+// permitting, the location on the last line.
+func drawBlockText(img *image.RGBA, f *faces, rect image.Rectangle, title, location string) {
+	maxWidth := rect.Dx() - blockPadding*2
+	lineHeight := f.block.Metrics().Height.Ceil()
+	smallHeight := f.small.Metrics().Height.Ceil()
+	available := rect.Dy() - blockPadding*2
+
+	var locationLines int
+	if location != "" && available >= lineHeight+smallHeight {
+		locationLines = 1
+		available -= smallHeight
+	}
+	maxLines := max(1, available/lineHeight)
+
+	y := rect.Min.Y + blockPadding + f.block.Metrics().Ascent.Ceil()
+	for _, line := range wrapText(f.block, title, maxWidth, maxLines) {
+		drawText(img, f.block, colorText, rect.Min.X+blockPadding, y, line)
+		y += lineHeight
+	}
+	if locationLines > 0 {
+		y += smallHeight - lineHeight
+		loc := wrapText(f.small, location, maxWidth, 1)
+		drawText(img, f.small, colorSubtext, rect.Min.X+blockPadding, y, loc[0])
+	}
+}
+
+// wrapText breaks s into at most maxLines lines no wider than maxWidth,
+// ending the last line with an ellipsis when text is cut.
+func wrapText(face font.Face, s string, maxWidth, maxLines int) []string {
+	var lines []string
+	var line []rune
+	runes := []rune(s)
+	for i, r := range runes {
+		candidate := append(line, r)
+		if len(line) > 0 && font.MeasureString(face, string(candidate)).Round() > maxWidth {
+			if len(lines) == maxLines-1 {
+				return append(lines, ellipsize(face, string(line)+string(runes[i:]), maxWidth))
+			}
+			lines = append(lines, string(line))
+			line = []rune{r}
+			continue
+		}
+		line = candidate
+	}
+	if len(line) > 0 || len(lines) == 0 {
+		lines = append(lines, string(line))
+	}
+	return lines
+}
+
+// ellipsize shortens s until s+"…" fits within maxWidth.
+func ellipsize(face font.Face, s string, maxWidth int) string {
+	runes := []rune(s)
+	for len(runes) > 0 && font.MeasureString(face, string(runes)+"…").Round() > maxWidth {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
@@ -0,0 +1,130 @@
+package timetable
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"testing"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func newTestRenderer(t *testing.T) *Renderer {
+	t.Helper()
+	f, err := ParseFont(goregular.TTF)
+	if err != nil {
+		t.Fatalf("ParseFont() error: %v", err)
+	}
+	return NewRenderer(f)
+}
+
+func TestKey(t *testing.T) {
+	t.Parallel()
+
+	a := storage.Course{UID: "1131U0001", Year: 113, Term: 1, Title: "Programming", Times: []string{"每週一3~4"}, Locations: []string{"A101"}}
+	b := storage.Course{UID: "1131U0002", Year: 113, Term: 1, Title: "Calculus", Times: []string{"每週二1~2"}}
+
+	if Key([]storage.Course{a, b}) != Key([]storage.Course{b, a}) {
+		t.Error("key must not depend on course order")
+	}
+
+	moved := a
+	moved.Locations = []string{"B202"}
+	if Key([]storage.Course{a, b}) == Key([]storage.Course{moved, b}) {
+		t.Error("key must change when a location changes")
+	}
+}
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+	r := newTestRenderer(t)
+
+	courses := []storage.Course{
+		{UID: "1131U0001", Year: 113, Term: 1, Title: "Introduction to Programming", Times: []string{"每週一3~4", "每週三3~3"}, Locations: []string{"A101", "A102"}},
+		{UID: "1131U0002", Year: 113, Term: 1, Title: "Calculus", Times: []string{"每週一4~5"}, Locations: []string{"B202"}},
+		{UID: "1131U0003", Year: 113, Term: 1, Title: "Night Seminar", Times: []string{"每週六10~12"}},
+	}
+
+	key, data, err := r.Render(courses)
+	if err != nil {
+		t.Fatalf("Render() error: %v", err)
+	}
+	if key != Key(courses) {
+		t.Errorf("Render() key = %q, want %q", key, Key(courses))
+	}
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("output is not a PNG: %v", err)
+	}
+	// Monday–Saturday columns, periods 1–12 (extended past the default 9).
+	wantWidth := margin*2 + periodColWidth + dayColWidth*6
+	wantHeight := margin + titleHeight + headerHeight + rowHeight*12 + margin
+	if b := img.Bounds(); b.Dx() != wantWidth || b.Dy() != wantHeight {
+		t.Errorf("image size = %dx%d, want %dx%d", b.Dx(), b.Dy(), wantWidth, wantHeight)
+	}
+
+	cached, ok := r.Cached(key)
+	if !ok || !bytes.Equal(cached, data) {
+		t.Error("rendered image should be cached under its key")
+	}
+}
+
+func TestRender_NoMeetings(t *testing.T) {
+	t.Parallel()
+	r := newTestRenderer(t)
+
+	_, _, err := r.Render([]storage.Course{{UID: "1131U0001", Year: 113, Term: 1, Times: []string{"每週未維護"}}})
+	if !errors.Is(err, ErrNoMeetings) {
+		t.Errorf("Render() error = %v, want ErrNoMeetings", err)
+	}
+}
+
+func TestLayoutBlocks_Lanes(t *testing.T) {
+	t.Parallel()
+
+	courses := []storage.Course{
+		{Times: []string{"每週一3~4"}},
+		{Times: []string{"每週一4~5"}},
+		{Times: []string{"每週一6~7"}},
+		{Times: []string{"每週二4~5"}},
+	}
+
+	blocks := layoutBlocks(courses)
+	if len(blocks) != 4 {
+		t.Fatalf("got %d blocks, want 4", len(blocks))
+	}
+
+	want := []struct{ lane, lanes int }{
+		{0, 2}, // Mon 3~4 overlaps Mon 4~5
+		{1, 2},
+		{0, 1}, // Mon 6~7 stands alone
+		{0, 1}, // Tue 4~5 is another day
+	}
+	for i, w := range want {
+		if blocks[i].lane != w.lane || blocks[i].lanes != w.lanes {
+			t.Errorf("block[%d] lane %d/%d, want %d/%d", i, blocks[i].lane, blocks[i].lanes, w.lane, w.lanes)
+		}
+	}
+}
+
+func TestCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	t.Parallel()
+
+	c := NewCache(2)
+	c.Put("a", []byte("a"))
+	c.Put("b", []byte("b"))
+	c.Get("a")
+	c.Put("c", []byte("c"))
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("least recently used entry should be evicted")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Error("recently used entry should be kept")
+	}
+	if c.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", c.Len())
+	}
+}
@@ -29,7 +29,6 @@ const (
 type Handler struct {
 	userLimiter    *ratelimit.KeyedLimiter
 	llmLimiter     *ratelimit.KeyedLimiter
-	logger         *logger.Logger
 	stickerManager *sticker.Manager
 
 	// Pre-built quota explanation content (computed once at handler construction).
@@ -58,13 +57,11 @@ func (h *Handler) Name() string {
 func NewHandler(
 	userLimiter *ratelimit.KeyedLimiter,
 	llmLimiter *ratelimit.KeyedLimiter,
-	logger *logger.Logger,
 	stickerManager *sticker.Manager,
 ) *Handler {
 	h := &Handler{
 		userLimiter:    userLimiter,
 		llmLimiter:     llmLimiter,
-		logger:         logger,
 		stickerManager: stickerManager,
 	}
 	h.precomputeQuotaExplanation()
@@ -79,7 +76,7 @@ func (h *Handler) CanHandle(text string) bool {
 
 // HandleMessage processes usage queries and returns a Flex Message with quota status.
 func (h *Handler) HandleMessage(ctx context.Context, text string) []messaging_api.MessageInterface {
-	log := h.logger.WithModule(ModuleName)
+	log := logger.FromContext(ctx)
 
 	// Check for quota explanation request
 	if strings.EqualFold(strings.TrimSpace(text), quotaExplainKeyword) {
@@ -4,12 +4,11 @@ import (
 	"context"
 	"testing"
 
-	"github.com/garyellow/ntpu-linebot-go/internal/logger"
 	"github.com/garyellow/ntpu-linebot-go/internal/ratelimit"
 )
 
 func TestHandler_CanHandle(t *testing.T) {
-	h := NewHandler(nil, nil, logger.New("debug"), nil)
+	h := NewHandler(nil, nil, nil)
 
 	tests := []struct {
 		name     string
@@ -63,7 +62,7 @@ func TestHandler_HandleMessage(t *testing.T) {
 	})
 	defer userLimiter.Stop()
 
-	h := NewHandler(userLimiter, llmLimiter, logger.New("debug"), nil)
+	h := NewHandler(userLimiter, llmLimiter, nil)
 
 	// Basic test - should return a message
 	ctx := context.Background()
@@ -75,7 +74,7 @@ func TestHandler_HandleMessage(t *testing.T) {
 }
 
 func TestHandler_DispatchIntent(t *testing.T) {
-	h := NewHandler(nil, nil, logger.New("debug"), nil)
+	h := NewHandler(nil, nil, nil)
 
 	tests := []struct {
 		name       string
@@ -105,7 +104,7 @@ func TestHandler_DispatchIntent(t *testing.T) {
 }
 
 func TestHandler_HandlePostback(t *testing.T) {
-	h := NewHandler(nil, nil, logger.New("debug"), nil)
+	h := NewHandler(nil, nil, nil)
 
 	tests := []struct {
 		name       string
@@ -0,0 +1,215 @@
+// Package msgtmpl renders user-facing message copy from text/template files.
+//
+// Default templates are embedded in the binary (templates/*.tmpl). A directory
+// of overrides can be supplied at startup (NTPU_TEMPLATE_DIR) so copy edits and
+// translations don't require a rebuild. Override files must use the same names
+// as the embedded defaults; unknown names are rejected to catch typos early.
+//
+// A template may have language variants named name.<lang>.tmpl (e.g.
+// course_help.en.tmpl); TextLang uses the variant when there is one and the
+// base template otherwise.
+//
+// Every template receives the shared variables below merged with call data:
+//   - CurrentYear: current ROC academic year (民國年)
+//   - CourseSystemLaunchYear, LMSLaunchYear
+//   - IDDataYearStart, IDDataYearEnd, IDDataCutoffYear
+//
+// The year variables other than IDDataYearStart come from data.Availability:
+// the first course year, the complete student years, and the year after them.
+//
+// Functions: western (ROC → Western year), add, sub.
+package msgtmpl
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
+	"github.com/garyellow/ntpu-linebot-go/internal/data"
+)
+
+// Template names (file name without .tmpl).
+const (
+	CourseHelp         = "course_help"
+	CourseSmartHelp    = "course_smart_help"
+	CourseExtendedHelp = "course_extended_help"
+	CourseInvalidYear  = "course_invalid_year"
+	IDYearHelp         = "id_year_help"
+	IDYearIncomplete   = "id_year_incomplete"
+	EnglishHelp        = "english_help"
+)
+
+// English is the language code of English template variants.
+const English = "en"
+
+const fileExt = ".tmpl"
+
+// ErrUnknownTemplate is returned when an override or render references a template
+// that has no embedded default.
+var ErrUnknownTemplate = errors.New("unknown message template")
+
+//go:embed templates/*.tmpl
+var defaultFS embed.FS
+
+// Data holds per-call template variables.
+type Data map[string]any
+
+// Store holds parsed message templates.
+// It is safe for concurrent use.
+type Store struct {
+	defaults *template.Template // Embedded templates, used as fallback
+	active   *template.Template // Defaults with overrides applied
+	now      func() time.Time
+}
+
+var defaultStore = sync.OnceValue(func() *Store {
+	s, err := Load("")
+	if err != nil {
+		panic(fmt.Sprintf("msgtmpl: embedded templates: %v", err))
+	}
+	return s
+})
+
+// Default returns a store with only the embedded templates.
+func Default() *Store {
+	return defaultStore()
+}
+
+// Load parses the embedded templates and applies overrides from dir.
+// An empty dir uses the embedded templates only.
+func Load(dir string) (*Store, error) {
+	defaults, err := parseFS(newTemplate(), defaultFS, "templates")
+	if err != nil {
+		return nil, fmt.Errorf("parse embedded templates: %w", err)
+	}
+
+	active := defaults
+	if dir != "" {
+		active, err = defaults.Clone()
+		if err != nil {
+			return nil, fmt.Errorf("clone templates: %w", err)
+		}
+		if _, err := parseFS(active, os.DirFS(dir), "."); err != nil {
+			return nil, fmt.Errorf("parse templates in %s: %w", dir, err)
+		}
+	}
+
+	return &Store{defaults: defaults, active: active, now: time.Now}, nil
+}
+
+// Names returns the names of all known templates in sorted order.
+func (s *Store) Names() []string {
+	names := make([]string, 0, len(s.defaults.Templates()))
+	for _, t := range s.defaults.Templates() {
+		if t.Name() != "" {
+			names = append(names, t.Name())
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Render executes the named template with data merged over the shared variables.
+func (s *Store) Render(name string, data Data) (string, error) {
+	return s.execute(s.active, name, data)
+}
+
+// Text renders the named template, falling back to the embedded default if an
+// override fails to execute (e.g., it references a variable that doesn't exist).
+// Embedded defaults are covered by tests, so the fallback always succeeds.
+func (s *Store) Text(name string, data Data) string {
+	text, err := s.Render(name, data)
+	if err == nil {
+		return text
+	}
+	text, _ = s.execute(s.defaults, name, data)
+	return text
+}
+
+// TextLang renders the lang variant of the named template, or the base
+// template when lang is empty or has no variant.
+func (s *Store) TextLang(name, lang string, data Data) string {
+	if lang != "" && s.defaults.Lookup(name+"."+lang) != nil {
+		name += "." + lang
+	}
+	return s.Text(name, data)
+}
+
+func (s *Store) execute(set *template.Template, name string, data Data) (string, error) {
+	t := set.Lookup(name)
+	if t == nil {
+		return "", fmt.Errorf("%w: %s", ErrUnknownTemplate, name)
+	}
+
+	vars := s.sharedVars()
+	maps.Copy(vars, data)
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, vars); err != nil {
+		return "", fmt.Errorf("render %s: %w", name, err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// sharedVars returns the variables available to every template.
+func (s *Store) sharedVars() Data {
+	completeFrom, completeTo := data.Availability.Students.CompleteYears()
+	return Data{
+		"CurrentYear":            s.now().Year() - 1911,
+		"CourseSystemLaunchYear": data.Availability.Courses.FirstYear(),
+		"LMSLaunchYear":          completeFrom,
+		"IDDataYearStart":        config.IDDataYearStart,
+		"IDDataYearEnd":          completeTo,
+		"IDDataCutoffYear":       completeTo + 1,
+	}
+}
+
+func newTemplate() *template.Template {
+	return template.New("").
+		Option("missingkey=error").
+		Funcs(template.FuncMap{
+			"western": func(rocYear int) int { return rocYear + 1911 },
+			"add":     func(a, b int) int { return a + b },
+			"sub":     func(a, b int) int { return a - b },
+		})
+}
+
+// parseFS adds every *.tmpl file in dir to set, named by file name without extension.
+// When set already has templates, files must replace an existing one.
+func parseFS(set *template.Template, fsys fs.FS, dir string) (*template.Template, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	restrict := len(set.Templates()) > 0
+
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != fileExt {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), fileExt)
+		if restrict && set.Lookup(name) == nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownTemplate, entry.Name())
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if _, err := set.New(name).Parse(string(content)); err != nil {
+			return nil, err
+		}
+	}
+	return set, nil
+}
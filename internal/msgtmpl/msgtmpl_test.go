@@ -0,0 +1,203 @@
+package msgtmpl
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDefault_RendersAllTemplates(t *testing.T) {
+	t.Parallel()
+	s := Default()
+
+	// Superset of variables used by any embedded template
+	data := Data{"SmartSearch": true, "Year": 80, "NLU": true}
+
+	for _, name := range s.Names() {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			text, err := s.Render(name, data)
+			if err != nil {
+				t.Fatalf("Render(%q) error = %v", name, err)
+			}
+			if text == "" || strings.HasSuffix(text, "\n") {
+				t.Errorf("Render(%q) = %q, want non-empty trimmed text", name, text)
+			}
+		})
+	}
+}
+
+func TestRender_Variables(t *testing.T) {
+	t.Parallel()
+	s := Default()
+	s = &Store{defaults: s.defaults, active: s.active, now: func() time.Time {
+		return time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	}}
+
+	tests := []struct {
+		name     string
+		template string
+		data     Data
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "Current year and western conversion",
+			template: CourseInvalidYear,
+			data:     Data{"Year": 80},
+			contains: []string{"無效的學年度：80", "90-114 學年度", "西元 2001-2025 年"},
+		},
+		{
+			name:     "Smart search section shown",
+			template: CourseHelp,
+			data:     Data{"SmartSearch": true},
+			contains: []string{"🔮 智慧搜尋", "線代 王\n\n🔮", "Python 入門\n\n📅"},
+		},
+		{
+			name:     "Smart search section hidden",
+			template: CourseHelp,
+			data:     Data{"SmartSearch": false},
+			contains: []string{"線代 王\n\n📅"},
+			excludes: []string{"🔮"},
+		},
+		{
+			name:     "Smart search disabled",
+			template: CourseSmartHelp,
+			data:     Data{"SmartSearch": false},
+			contains: []string{"智慧搜尋目前未啟用"},
+		},
+		{
+			name:     "Data limits",
+			template: IDYearHelp,
+			contains: []string{"學年 112、學年 110", "94-112 學年度", "113 年不完整、114 年起無資料"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			text, err := s.Render(tt.template, tt.data)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(text, want) {
+					t.Errorf("Render() = %q, want to contain %q", text, want)
+				}
+			}
+			for _, unwanted := range tt.excludes {
+				if strings.Contains(text, unwanted) {
+					t.Errorf("Render() = %q, should not contain %q", text, unwanted)
+				}
+			}
+		})
+	}
+}
+
+func TestRender_MissingVariable(t *testing.T) {
+	t.Parallel()
+	if _, err := Default().Render(CourseInvalidYear, nil); err == nil {
+		t.Error("Render() without Year should fail with missingkey=error")
+	}
+}
+
+func TestRender_Unknown(t *testing.T) {
+	t.Parallel()
+	if _, err := Default().Render("nope", nil); !errors.Is(err, ErrUnknownTemplate) {
+		t.Errorf("Render() error = %v, want ErrUnknownTemplate", err)
+	}
+}
+
+func TestTextLang(t *testing.T) {
+	t.Parallel()
+	s := Default()
+	data := Data{"SmartSearch": false, "NLU": false}
+
+	if got := s.TextLang(CourseHelp, English, data); !strings.HasPrefix(got, "📚 Course search") {
+		t.Errorf("TextLang(%q, en) = %q, want the English variant", CourseHelp, got)
+	}
+	if got := s.TextLang(CourseHelp, "", data); !strings.HasPrefix(got, "📚 課程查詢方式") {
+		t.Errorf("TextLang(%q, \"\") = %q, want the base template", CourseHelp, got)
+	}
+	// No English variant: the base template is used
+	if got := s.TextLang(IDYearIncomplete, English, nil); !strings.Contains(got, "資料不完整") {
+		t.Errorf("TextLang(%q, en) = %q, want the base template", IDYearIncomplete, got)
+	}
+	if got := s.TextLang(EnglishHelp, English, data); strings.Contains(got, "full sentence") {
+		t.Errorf("TextLang(%q) = %q, should not mention sentences without NLU", EnglishHelp, got)
+	}
+}
+
+func TestLoad_Overrides(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	writeFile(t, dir, CourseExtendedHelp+".tmpl", "More semesters ({{.CurrentYear}})\n")
+	writeFile(t, dir, CourseSmartHelp+".tmpl", "Broken {{.Missing}}")
+	writeFile(t, dir, CourseHelp+".en.tmpl", "Courses")
+	writeFile(t, dir, "README.md", "ignored")
+
+	s, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if got := s.Text(CourseExtendedHelp, nil); !strings.HasPrefix(got, "More semesters (") {
+		t.Errorf("Text(%q) = %q, want override", CourseExtendedHelp, got)
+	}
+	// Override fails at render time, so the embedded default is used
+	if got := s.Text(CourseSmartHelp, Data{"SmartSearch": true}); !strings.Contains(got, "智慧搜尋說明") {
+		t.Errorf("Text(%q) = %q, want embedded default", CourseSmartHelp, got)
+	}
+	// Language variants are overridden like any other template
+	if got := s.TextLang(CourseHelp, English, nil); got != "Courses" {
+		t.Errorf("TextLang(%q, en) = %q, want override", CourseHelp, got)
+	}
+	// Templates without an override keep the default
+	if got := s.Text(IDYearIncomplete, nil); !strings.Contains(got, "資料不完整") {
+		t.Errorf("Text(%q) = %q, want embedded default", IDYearIncomplete, got)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		wantErr error
+	}{
+		{"Unknown template name", "course_hlep.tmpl", "typo", ErrUnknownTemplate},
+		{"Unknown language variant", "course_help.fr.tmpl", "Cours", ErrUnknownTemplate},
+		{"Parse error", CourseHelp + ".tmpl", "{{if}}", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			dir := t.TempDir()
+			writeFile(t, dir, tt.file, tt.content)
+
+			_, err := Load(dir)
+			if err == nil {
+				t.Fatal("Load() error = nil, want error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Load() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Load() with missing directory should fail")
+	}
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+}
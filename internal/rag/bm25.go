@@ -10,6 +10,8 @@ import (
 	"sync"
 
 	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/membudget"
+	"github.com/garyellow/ntpu-linebot-go/internal/semester"
 	"github.com/garyellow/ntpu-linebot-go/internal/storage"
 	"github.com/garyellow/ntpu-linebot-go/internal/stringutil"
 	"github.com/garyellow/ntpu-linebot-go/internal/syllabus"
@@ -44,10 +46,7 @@ type SearchResult struct {
 }
 
 // SemesterKey uniquely identifies a semester for indexing.
-type SemesterKey struct {
-	Year int
-	Term int
-}
+type SemesterKey = semester.Semester
 
 // semesterIndex holds BM25 index for a single semester.
 // Each semester has its own IDF calculation, ensuring independent relevance scoring.
@@ -69,6 +68,7 @@ type semesterIndex struct {
 // Optimization Notes:
 //   - Does NOT store raw text corpus in memory (significant savings)
 //   - Loads syllabi semester-by-semester during initialization to minimize peak memory
+//   - With a memory budget (SetBudget), least recently searched semesters are evicted
 //
 // Uses an in-house BM25 Okapi engine (internal/rag/engine.go) with inverted index;
 // documents are tokenized exactly once at index build time, so queries involve
@@ -79,6 +79,7 @@ type BM25Index struct {
 
 	seg         *stringutil.Segmenter // Chinese word segmenter (shared)
 	logger      *logger.Logger
+	budget      *membudget.Budget // Optional: charged per semester
 	mu          sync.RWMutex
 	initialized bool
 }
@@ -114,6 +115,46 @@ func NewBM25Index(log *logger.Logger, seg *stringutil.Segmenter) *BM25Index {
 	}
 }
 
+// SetBudget charges each semester's index to b, which evicts the least
+// recently searched semesters when over its limit. Call it before Initialize.
+func (idx *BM25Index) SetBudget(b *membudget.Budget) {
+	idx.budget = b
+	b.Register(membudget.ConsumerBM25, idx.evictSemester)
+}
+
+// evictSemester drops a semester evicted by the memory budget. Searches of
+// that semester find nothing until the next rebuild.
+func (idx *BM25Index) evictSemester(key string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for sem := range idx.semesterIndexes {
+		if sem.String() != key {
+			continue
+		}
+		delete(idx.semesterIndexes, sem)
+		idx.allSemesters = slices.DeleteFunc(idx.allSemesters, func(s SemesterKey) bool { return s == sem })
+		idx.logger.WithField("semester", key).Warn("BM25 semester evicted by the memory budget")
+		return
+	}
+}
+
+// chargeBudget replaces the budget charges of the old semesters with those
+// of the new index. Oldest semesters are charged first, so they are the
+// first evicted.
+func (idx *BM25Index) chargeBudget(old, current map[SemesterKey]*semesterIndex, semesters []SemesterKey) {
+	if idx.budget == nil {
+		return
+	}
+	for sem := range old {
+		if _, ok := current[sem]; !ok {
+			idx.budget.Release(membudget.ConsumerBM25, sem.String())
+		}
+	}
+	for _, sem := range slices.Backward(semesters) {
+		idx.budget.Charge(membudget.ConsumerBM25, sem.String(), membudget.Estimate(current[sem]))
+	}
+}
+
 // Initialize builds BM25 indexes from the database.
 //
 // Concurrency design:
@@ -187,21 +228,18 @@ func (idx *BM25Index) Initialize(ctx context.Context, db *storage.DB) error {
 	}
 
 	// Sort semesters (newest first)
-	slices.SortFunc(newSemesters, func(a, b SemesterKey) int {
-		if a.Year != b.Year {
-			return b.Year - a.Year // Descending by year
-		}
-		return b.Term - a.Term // Descending by term
-	})
+	semester.SortNewestFirst(newSemesters)
 
 	// ── Atomic swap phase (brief lock, O(1)) ──────────────────────────────────
 	// Replaces the live index in one pointer swap; readers see either the old
 	// or the new index atomically — never a partial rebuild state.
 	idx.mu.Lock()
+	oldIndexes := idx.semesterIndexes
 	idx.semesterIndexes = newIndexes
 	idx.allSemesters = newSemesters
 	idx.initialized = true
 	idx.mu.Unlock()
+	idx.chargeBudget(oldIndexes, newIndexes, newSemesters)
 
 	// Persist newly-tokenized entries so future restarts hit the cache.
 	// Done after the swap so a save failure does not block the live index.
@@ -444,6 +482,7 @@ func (idx *BM25Index) SearchCourses(_ context.Context, query string, topN int) (
 		if semIdx == nil {
 			continue
 		}
+		idx.budget.Touch(membudget.ConsumerBM25, sem.String())
 
 		// Search this semester's index
 		semResults := semIdx.search(query, topN, idx.Tokenize)
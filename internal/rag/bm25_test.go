@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/garyellow/ntpu-linebot-go/internal/logger"
+	"github.com/garyellow/ntpu-linebot-go/internal/membudget"
 	"github.com/garyellow/ntpu-linebot-go/internal/storage"
 	"github.com/garyellow/ntpu-linebot-go/internal/stringutil"
 )
@@ -331,6 +332,39 @@ func TestBM25Index_SearchCourses_NewestTwoSemesters(t *testing.T) {
 	}
 }
 
+func TestBM25Index_MemoryBudgetEvictsOldestSemester(t *testing.T) {
+	log := logger.New("debug")
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	syllabi := []*storage.Syllabus{
+		{UID: "1141U0001", Title: "雲端運算", Year: 114, Term: 1, Objectives: "雲端"},
+		{UID: "1132U0001", Title: "雲端服務", Year: 113, Term: 2, Objectives: "雲端"},
+	}
+	if err := db.SaveSyllabusBatch(ctx, syllabi); err != nil {
+		t.Fatalf("SaveSyllabusBatch failed: %v", err)
+	}
+
+	// A one-byte budget holds only the most recently charged semester.
+	budget := membudget.New(1)
+	idx := NewBM25Index(log, newTestSegmenter())
+	idx.SetBudget(budget)
+	if err := idx.Initialize(ctx, db); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+
+	results, err := idx.SearchCourses(ctx, "雲端", 10)
+	if err != nil {
+		t.Fatalf("SearchCourses() error = %v", err)
+	}
+	if len(results) != 1 || results[0].UID != "1141U0001" {
+		t.Errorf("SearchCourses() = %+v, want only the newest semester's course", results)
+	}
+	if budget.Used(membudget.ConsumerBM25) <= 0 {
+		t.Error("newest semester is not charged to the budget")
+	}
+}
+
 func TestBM25Index_SearchCourses_EmptyQuery(t *testing.T) {
 	log := logger.New("debug")
 	db := setupTestDB(t)
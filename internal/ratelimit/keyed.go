@@ -16,6 +16,8 @@ const (
 	MetricTypeUser MetricType = iota
 	// MetricTypeLLM reports to ntpu_llm_rate_limiter_users
 	MetricTypeLLM
+	// MetricTypeNone reports drops only (no active-key gauge)
+	MetricTypeNone
 )
 
 // KeyedConfig configures a KeyedLimiter instance.
@@ -95,9 +97,11 @@ func NewKeyedLimiter(cfg KeyedConfig) *KeyedLimiter {
 			cfg.Metrics.RecordRateLimiterDrop(cfg.Name)
 		}
 		kl.onUpdate = func(count int) {
-			if cfg.MetricType == MetricTypeLLM {
+			switch cfg.MetricType {
+			case MetricTypeLLM:
 				cfg.Metrics.SetLLMRateLimiterUsers(count)
-			} else {
+			case MetricTypeNone:
+			default:
 				cfg.Metrics.SetRateLimiterUsers(count)
 			}
 		}
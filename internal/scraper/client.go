@@ -6,6 +6,7 @@ package scraper
 import (
 	"compress/gzip"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -17,7 +18,6 @@ import (
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
-	"github.com/corpix/uarand"
 	"github.com/garyellow/ntpu-linebot-go/internal/ratelimit"
 	"golang.org/x/text/encoding/traditionalchinese"
 	"golang.org/x/text/transform"
@@ -29,6 +29,9 @@ type Client struct {
 	maxRetries     int
 	baseURLs       map[string][]string           // Base URLs for failover by domain
 	domainLimiters map[string]*ratelimit.Limiter // Per-domain rate limiters
+	driftReporter  DriftReporter                 // Optional: notified of parser drift
+	jar            *sessionJar                   // Session cookies per domain
+	userAgents     []string                      // Optional pool; empty = generated browser UAs
 	mu             sync.RWMutex
 }
 
@@ -42,8 +45,27 @@ const DefaultDomainRPS = 5.0
 // baseURLs: map of domain to list of base URLs for failover
 //
 // Each domain gets an independent rate limiter (burst: 3, refill: 5/sec)
-// to prevent overwhelming any single server.
+// to prevent overwhelming any single server, and its own cookie jar so a
+// redirect to a login page renews only that domain's session.
 func NewClient(timeout time.Duration, maxRetries int, baseURLs map[string][]string) *Client {
+	c, _ := NewClientWithOptions(timeout, maxRetries, baseURLs, Options{}) // Zero options never fail
+	return c
+}
+
+// NewClientWithOptions creates a scraper client like NewClient, with proxies
+// and a bound source address from opts. Returns an error for an invalid
+// proxy URL or local address.
+func NewClientWithOptions(timeout time.Duration, maxRetries int, baseURLs map[string][]string, opts Options) (*Client, error) {
+	hosts := sourceHosts(baseURLs)
+	proxy, err := opts.proxyFunc(hosts)
+	if err != nil {
+		return nil, err
+	}
+	dialer, err := opts.dialer()
+	if err != nil {
+		return nil, err
+	}
+
 	// Create per-domain rate limiters
 	domainLimiters := make(map[string]*ratelimit.Limiter, len(baseURLs))
 	for domain := range baseURLs {
@@ -51,10 +73,16 @@ func NewClient(timeout time.Duration, maxRetries int, baseURLs map[string][]stri
 		domainLimiters[domain] = ratelimit.New(3, DefaultDomainRPS)
 	}
 
+	jar := newSessionJar(hosts)
+
 	return &Client{
 		httpClient: &http.Client{
-			Timeout: timeout,
+			Timeout:       timeout,
+			Jar:           jar,
+			CheckRedirect: checkRedirect,
 			Transport: &http.Transport{
+				Proxy:                 proxy,
+				DialContext:           dialer.DialContext,
 				MaxIdleConns:          100,
 				MaxIdleConnsPerHost:   10,
 				IdleConnTimeout:       90 * time.Second,
@@ -65,7 +93,8 @@ func NewClient(timeout time.Duration, maxRetries int, baseURLs map[string][]stri
 		maxRetries:     maxRetries,
 		baseURLs:       baseURLs,
 		domainLimiters: domainLimiters,
-	}
+		jar:            jar,
+	}, nil
 }
 
 // GetDocument performs a GET request and parses the response as HTML.
@@ -86,6 +115,61 @@ func (c *Client) GetDocument(ctx context.Context, reqURL string) (*goquery.Docum
 	return doc, nil
 }
 
+// GetJSON performs a GET request and decodes the JSON response body into v.
+// Used for third-party JSON APIs; retry and rate limiting match GetDocument.
+func (c *Client) GetJSON(ctx context.Context, reqURL string, v any) error {
+	resp, err := c.doRequest(ctx, "GET", reqURL, "")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var reader io.Reader = resp.Body
+	if resp.Header.Get("Content-Encoding") == "gzip" {
+		gzipReader, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to decompress gzip: %w", err)
+		}
+		defer func() { _ = gzipReader.Close() }()
+		reader = gzipReader
+	}
+
+	if err := json.NewDecoder(reader).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode JSON: %w", err)
+	}
+	return nil
+}
+
+// GetBytes performs a GET request and returns the response body, failing
+// when it exceeds maxBytes. Used for images; retry and rate limiting match
+// GetDocument.
+func (c *Client) GetBytes(ctx context.Context, reqURL string, maxBytes int64) ([]byte, error) {
+	resp, err := c.doRequest(ctx, "GET", reqURL, "")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var reader io.Reader = resp.Body
+	if resp.Header.Get("Content-Encoding") == "gzip" {
+		gzipReader, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress gzip: %w", err)
+		}
+		defer func() { _ = gzipReader.Close() }()
+		reader = gzipReader
+	}
+
+	body, err := io.ReadAll(io.LimitReader(reader, maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body: %w", err)
+	}
+	if int64(len(body)) > maxBytes {
+		return nil, fmt.Errorf("response from %s exceeds %d bytes", reqURL, maxBytes)
+	}
+	return body, nil
+}
+
 // PostFormDocument performs a POST request with form data and parses the response as HTML.
 func (c *Client) PostFormDocument(ctx context.Context, postURL string, formData url.Values) (*goquery.Document, error) {
 	return c.PostFormDocumentRaw(ctx, postURL, formData.Encode())
@@ -154,6 +238,14 @@ func (c *Client) doRequest(ctx context.Context, method, reqURL, body string) (*h
 			return lastErr
 		}
 
+		// A redirect to a login page means the session expired: renew it and retry
+		if isLoginRedirect(resp) {
+			_ = resp.Body.Close()
+			c.renewSession(ctx, reqURL)
+			lastErr = fmt.Errorf("session expired for %s: redirected to login", reqURL)
+			return lastErr
+		}
+
 		// Handle non-success status codes
 		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 			_ = resp.Body.Close()
@@ -259,11 +351,6 @@ func (c *Client) processResponseToDocument(resp *http.Response) (*goquery.Docume
 	return doc, nil
 }
 
-// randomUserAgent returns a random user agent string
-func (c *Client) randomUserAgent() string {
-	return uarand.GetRandom()
-}
-
 // TryFailoverURLs attempts to use alternative base URLs when primary URL fails.
 // Returns the working URL or error if all URLs failed.
 // Uses HEAD requests for quick availability checks.
@@ -4,9 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/garyellow/ntpu-linebot-go/internal/faults"
 	"github.com/garyellow/ntpu-linebot-go/internal/ratelimit"
 )
 
@@ -176,3 +179,101 @@ func TestWaitForDomain_ContextCanceled(t *testing.T) {
 		t.Errorf("expected context.Canceled, got %v", err)
 	}
 }
+
+func TestGetJSON(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/posts":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprint(w, `[{"id":1},{"id":2}]`)
+		case "/html":
+			_, _ = fmt.Fprint(w, "<html></html>")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(5*time.Second, 0, map[string][]string{})
+	ctx := context.Background()
+
+	var posts []struct {
+		ID int `json:"id"`
+	}
+	if err := client.GetJSON(ctx, server.URL+"/posts", &posts); err != nil {
+		t.Fatalf("GetJSON() error = %v", err)
+	}
+	if len(posts) != 2 || posts[1].ID != 2 {
+		t.Errorf("GetJSON() decoded %+v, want two posts", posts)
+	}
+
+	if err := client.GetJSON(ctx, server.URL+"/html", &posts); err == nil {
+		t.Error("GetJSON() on HTML should fail to decode")
+	}
+	if err := client.GetJSON(ctx, server.URL+"/missing", &posts); err == nil {
+		t.Error("GetJSON() on 404 should fail")
+	}
+}
+
+func TestGetBytes(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/logo.png" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("0123456789"))
+	}))
+	defer server.Close()
+
+	client := NewClient(5*time.Second, 0, map[string][]string{})
+	ctx := context.Background()
+
+	body, err := client.GetBytes(ctx, server.URL+"/logo.png", 10)
+	if err != nil || string(body) != "0123456789" {
+		t.Fatalf("GetBytes() = %q, %v, want the whole body", body, err)
+	}
+	if _, err := client.GetBytes(ctx, server.URL+"/logo.png", 9); err == nil {
+		t.Error("GetBytes() over maxBytes should fail")
+	}
+	if _, err := client.GetBytes(ctx, server.URL+"/missing", 10); err == nil {
+		t.Error("GetBytes() on 404 should fail")
+	}
+}
+
+func TestSetFaults(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("<html><body><table id=\"list\"></table></body></html>"))
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	timeouts, err := faults.New(map[string]string{faults.ScraperTimeout: "1"})
+	if err != nil {
+		t.Fatalf("faults.New() error = %v", err)
+	}
+	client := NewClient(5*time.Second, 0, map[string][]string{})
+	client.SetFaults(timeouts)
+	if _, err := client.GetDocument(ctx, server.URL); !errors.Is(err, faults.ErrInjected) {
+		t.Errorf("GetDocument() error = %v, want an injected timeout", err)
+	}
+
+	garbled, err := faults.New(map[string]string{faults.ScraperGarbled: "1"})
+	if err != nil {
+		t.Fatalf("faults.New() error = %v", err)
+	}
+	client = NewClient(5*time.Second, 0, map[string][]string{})
+	client.SetFaults(garbled)
+	doc, err := client.GetDocument(ctx, server.URL)
+	if err != nil {
+		t.Fatalf("GetDocument() error = %v", err)
+	}
+	if doc.Find("table#list").Length() != 0 {
+		t.Error("GetDocument() returned the upstream page, want the garbled one")
+	}
+}
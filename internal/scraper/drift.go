@@ -0,0 +1,40 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrParserDrift means a page no longer has the structure its parser expects,
+// usually because the school redesigned it. The parser's (empty or partial)
+// result must not be cached.
+var ErrParserDrift = errors.New("page structure changed")
+
+// DriftReporter is notified when a parser detects drift, e.g., to record a
+// metric and alert admins. Implementations must be safe for concurrent use.
+type DriftReporter interface {
+	ReportDrift(ctx context.Context, parser, detail string)
+}
+
+// SetDriftReporter sets the reporter notified by Drift. Nil disables reporting.
+func (c *Client) SetDriftReporter(r DriftReporter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.driftReporter = r
+}
+
+// Drift reports that parser found an unexpected page structure and returns an
+// error wrapping ErrParserDrift for the parser to return instead of its result.
+// parser is a short stable name (e.g., "course_list"); detail describes what
+// did not match.
+func (c *Client) Drift(ctx context.Context, parser, detail string) error {
+	c.mu.RLock()
+	r := c.driftReporter
+	c.mu.RUnlock()
+
+	if r != nil {
+		r.ReportDrift(ctx, parser, detail)
+	}
+	return fmt.Errorf("%w: %s: %s", ErrParserDrift, parser, detail)
+}
@@ -0,0 +1,44 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingReporter struct {
+	mu      sync.Mutex
+	reports []string
+}
+
+func (r *recordingReporter) ReportDrift(_ context.Context, parser, detail string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reports = append(r.reports, parser+": "+detail)
+}
+
+func TestDrift(t *testing.T) {
+	t.Parallel()
+
+	client := NewClient(5*time.Second, 0, map[string][]string{})
+	ctx := context.Background()
+
+	// Without a reporter, Drift still returns the sentinel
+	err := client.Drift(ctx, "course_list", "no titles in 3 rows")
+	if !errors.Is(err, ErrParserDrift) {
+		t.Fatalf("Drift() = %v, want ErrParserDrift", err)
+	}
+	if IsNetworkError(err) {
+		t.Error("drift should not be treated as a network error")
+	}
+
+	reporter := &recordingReporter{}
+	client.SetDriftReporter(reporter)
+	_ = client.Drift(ctx, "contact_list", "no department links")
+
+	if len(reporter.reports) != 1 || reporter.reports[0] != "contact_list: no department links" {
+		t.Errorf("reports = %v, want one contact_list report", reporter.reports)
+	}
+}
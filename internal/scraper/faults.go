@@ -0,0 +1,10 @@
+package scraper
+
+import "github.com/garyellow/ntpu-linebot-go/internal/faults"
+
+// SetFaults makes requests fail with the injector's scraper_timeout and
+// scraper_garbled faults, for resilience testing. Call it before the client
+// is shared; a nil injector leaves requests alone.
+func (c *Client) SetFaults(inj *faults.Injector) {
+	c.httpClient.Transport = inj.Transport(c.httpClient.Transport)
+}
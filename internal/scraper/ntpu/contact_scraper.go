@@ -20,9 +20,19 @@ const (
 	contactSearchPath  = "/pls/ld/CAMPUS_DIR_M.pq"
 	administrativePath = "/pls/ld/CAMPUS_DIR_M.p1?kind=1"
 	academicPath       = "/pls/ld/CAMPUS_DIR_M.p1?kind=2"
+	adjunctPath        = "/pls/ld/CAMPUS_DIR_M.p1?kind=3"
 
 	// Phone constants for Sanxia campus (assumed default)
 	sanxiaNormalPhone = "0286741111"
+
+	// Selectors the contact parsers rely on
+	contactOrgSelector    = "div.alert.alert-info.mt-0.mb-0"
+	contactMemberSelector = "div.w100 tbody tr"
+	contactDeptSelector   = "div.card-header"
+
+	// Parser names in drift reports
+	contactListParser      = "contact_list"
+	contactDirectoryParser = "contact_directory"
 )
 
 // seaCache is a package-level helper for SEA URL caching.
@@ -63,7 +73,7 @@ func ScrapeContacts(ctx context.Context, client *scraper.Client, searchTerm stri
 		return nil, fmt.Errorf("failed to fetch contacts: %w", err)
 	}
 
-	return parseContactsPage(doc), nil
+	return parseContacts(ctx, client, doc)
 }
 
 // encodeToBig5 encodes a string to Big5 encoding
@@ -92,13 +102,50 @@ func BuildContactSearchURL(searchTerm string) string {
 	return fmt.Sprintf("%s%s?q=%s", defaultSEAURL, contactSearchPath, encodedTerm)
 }
 
+// parseContacts parses a contact page, reporting drift instead of returning
+// an empty or organization-only list when sections no longer parse.
+func parseContacts(ctx context.Context, client *scraper.Client, doc *goquery.Document) ([]*storage.Contact, error) {
+	contacts := parseContactsPage(doc)
+	if detail := contactDrift(doc, contacts); detail != "" {
+		return nil, client.Drift(ctx, contactListParser, detail)
+	}
+	return contacts, nil
+}
+
+// contactDrift checks a contact page against what parseContactsPage relies on.
+// Returns what does not match, or "" when the page looks as expected.
+// A page without organization sections (no search match) is not drift.
+func contactDrift(doc *goquery.Document, contacts []*storage.Contact) string {
+	named, members := 0, 0
+	for _, c := range contacts {
+		switch {
+		case c.Type == "individual":
+			members++
+		case c.Name != "":
+			named++
+		}
+	}
+
+	memberRows := doc.Find(contactMemberSelector).FilterFunction(func(_ int, tr *goquery.Selection) bool {
+		return tr.Find("td").Length() >= 2
+	}).Length()
+
+	if orgs := doc.Find(contactOrgSelector).Length(); orgs > 0 && named == 0 {
+		return fmt.Sprintf("no organization names in %d sections", orgs)
+	}
+	if memberRows > 0 && members == 0 {
+		return fmt.Sprintf("no members in %d table rows", memberRows)
+	}
+	return ""
+}
+
 // parseContactsPage parses contact information from the search results page
 func parseContactsPage(doc *goquery.Document) []*storage.Contact {
 	contacts := make([]*storage.Contact, 0)
 	cachedAt := time.Now().Unix()
 
 	// Find all organization sections: <div class="alert alert-info mt-0 mb-0">
-	doc.Find("div.alert.alert-info.mt-0.mb-0").Each(func(i int, orgDiv *goquery.Selection) {
+	doc.Find(contactOrgSelector).Each(func(i int, orgDiv *goquery.Selection) {
 		// Extract organization information
 		orgLinks := orgDiv.Find("a.lang.lang-zh-Hant.mx-2")
 
@@ -238,6 +285,41 @@ func ScrapeAcademicContacts(ctx context.Context, client *scraper.Client) ([]*sto
 	return scrapeContactPages(ctx, client, contactBaseURL, url)
 }
 
+// ScrapeAdjunctContacts scrapes the part-time (兼任) faculty listing, which
+// the academic directory omits. Only individuals are returned, flagged
+// PartTime; their departments come from the administrative and academic
+// listings. Supports automatic URL failover across multiple SEA endpoints
+func ScrapeAdjunctContacts(ctx context.Context, client *scraper.Client) ([]*storage.Contact, error) {
+	// Check context before starting
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("context canceled before scraping adjunct contacts: %w", err)
+	}
+
+	contactBaseURL, err := seaCache(ctx, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working SEA URL: %w", err)
+	}
+	contacts, err := scrapeContactPages(ctx, client, contactBaseURL, contactBaseURL+adjunctPath)
+	if err != nil {
+		return nil, err
+	}
+	return adjunctsOnly(contacts), nil
+}
+
+// adjunctsOnly keeps the individuals of a part-time faculty listing and flags
+// them PartTime.
+func adjunctsOnly(contacts []*storage.Contact) []*storage.Contact {
+	adjuncts := make([]*storage.Contact, 0, len(contacts))
+	for _, c := range contacts {
+		if c.Type != "individual" {
+			continue
+		}
+		c.PartTime = true
+		adjuncts = append(adjuncts, c)
+	}
+	return adjuncts
+}
+
 // scrapeContactPages scrapes contact information from department listing pages
 func scrapeContactPages(ctx context.Context, client *scraper.Client, contactBaseURL, url string) ([]*storage.Contact, error) {
 	// Check context before starting
@@ -250,21 +332,28 @@ func scrapeContactPages(ctx context.Context, client *scraper.Client, contactBase
 		return nil, fmt.Errorf("failed to fetch contact pages: %w", err)
 	}
 
+	// The directory always lists departments; none means the page changed
+	depts := doc.Find(contactDeptSelector)
+	if depts.Length() == 0 {
+		return nil, client.Drift(ctx, contactDirectoryParser, "no department sections")
+	}
+
 	allContacts := make([]*storage.Contact, 0)
 	var scrapeErrors []string
 	var successCount int
+	var driftErr error
 
 	// Find all department links: <div class="card-header">
-	doc.Find("div.card-header").Each(func(i int, s *goquery.Selection) {
+	depts.EachWithBreak(func(i int, s *goquery.Selection) bool {
 		// Check context cancellation within loop
 		if ctx.Err() != nil {
-			return
+			return false
 		}
 
 		link := s.Find("a")
 		href, exists := link.Attr("href")
 		if !exists {
-			return
+			return true
 		}
 
 		deptURL := fmt.Sprintf("%s/pls/ld/%s", contactBaseURL, href)
@@ -274,15 +363,25 @@ func scrapeContactPages(ctx context.Context, client *scraper.Client, contactBase
 		if err != nil {
 			// Record error but continue with other departments
 			scrapeErrors = append(scrapeErrors, fmt.Sprintf("dept %s: %v", href, err))
-			return
+			return true
 		}
 
 		// Parse contacts from department page
-		contacts := parseContactsPage(deptDoc)
+		contacts, err := parseContacts(ctx, client, deptDoc)
+		if err != nil {
+			driftErr = err
+			return false
+		}
 		allContacts = append(allContacts, contacts...)
 		successCount++
+		return true
 	})
 
+	// Drift on one department page means the rest are likely off too
+	if driftErr != nil {
+		return nil, driftErr
+	}
+
 	// Check if context was canceled during processing
 	if err := ctx.Err(); err != nil {
 		return allContacts, fmt.Errorf("context canceled during contact scraping (partial results: %d departments): %w", successCount, err)
@@ -292,6 +391,9 @@ func scrapeContactPages(ctx context.Context, client *scraper.Client, contactBase
 	if len(allContacts) == 0 && len(scrapeErrors) > 0 {
 		return nil, fmt.Errorf("all department requests failed (%d errors): %v", len(scrapeErrors), scrapeErrors)
 	}
+	if len(allContacts) == 0 {
+		return nil, client.Drift(ctx, contactDirectoryParser, fmt.Sprintf("no contacts in %d department sections", depts.Length()))
+	}
 
 	return allContacts, nil
 }
@@ -4,6 +4,8 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+
+	"github.com/PuerkitoBio/goquery"
 )
 
 // TestEncodeToBig5 tests the Big5 encoding function
@@ -183,3 +185,57 @@ func TestBuildContactSearchURL(t *testing.T) {
 		})
 	}
 }
+
+func TestContactDrift(t *testing.T) {
+	t.Parallel()
+	org := `<div class="alert alert-info mt-0 mb-0"><a class="lang lang-zh-Hant mx-2">資訊工程學系</a></div>`
+	members := `<div class="w100"><table><tbody><tr>` +
+		`<td><span class="lang-zh-Hant">王小明</span></td><td>助理</td><td><span>12345</span></td><td></td><td><span>a</span></td>` +
+		`</tr></tbody></table></div>`
+	renamed := `<div class="alert alert-info mt-0 mb-0"><span class="org">資訊工程學系</span></div>`
+	narrow := `<div class="w100"><table><tbody><tr><td>王小明</td><td>助理</td></tr></tbody></table></div>`
+
+	tests := []struct {
+		name      string
+		body      string
+		wantDrift bool
+	}{
+		{"no match", `<p>查無資料</p>`, false},
+		{"organization with members", org + members, false},
+		{"organization without members", org, false},
+		{"organization name moved", renamed, true},
+		{"member rows no longer parse", org + narrow, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader("<html><body>" + tt.body + "</body></html>"))
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+			if got := contactDrift(doc, parseContactsPage(doc)); (got != "") != tt.wantDrift {
+				t.Errorf("contactDrift() = %q, want drift %v", got, tt.wantDrift)
+			}
+		})
+	}
+}
+
+func TestAdjunctsOnly(t *testing.T) {
+	t.Parallel()
+	org := `<div class="alert alert-info mt-0 mb-0"><a class="lang lang-zh-Hant mx-2">資訊工程學系</a></div>`
+	members := `<div class="w100"><table><tbody><tr>` +
+		`<td><span class="lang-zh-Hant">陳小美</span></td><td>兼任助理教授</td><td><span>12345</span></td><td></td><td><span>a</span></td>` +
+		`</tr></tbody></table></div>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader("<html><body>" + org + members + "</body></html>"))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	got := adjunctsOnly(parseContactsPage(doc))
+	if len(got) != 1 {
+		t.Fatalf("adjunctsOnly() returned %d contacts, want the 1 individual", len(got))
+	}
+	if c := got[0]; c.Name != "陳小美" || c.Organization != "資訊工程學系" || !c.PartTime {
+		t.Errorf("adjunctsOnly()[0] = %+v, want part-time 陳小美 of 資訊工程學系", c)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/garyellow/ntpu-linebot-go/internal/scraper"
@@ -25,6 +26,12 @@ const (
 	seaUserFacingURL = "https://sea.cc.ntpu.edu.tw"
 )
 
+// minCourseColumns is the number of cells parseCoursesPage reads from a result row.
+const minCourseColumns = 14
+
+// courseListParser names course result pages in drift reports.
+const courseListParser = "course_list"
+
 // allEducationCodes contains education level codes (U=大學部, M=碩士班, N=碩士在職專班, P=博士班)
 var allEducationCodes = []string{"U", "M", "N", "P"}
 
@@ -80,7 +87,10 @@ func ProbeCoursesExist(ctx context.Context, client *scraper.Client, year, term i
 	}
 
 	// Parse the page to check if any courses exist
-	courses := parseCoursesPage(ctx, doc, year, term)
+	courses, err := parseCourses(ctx, client, doc, year, term)
+	if err != nil {
+		return false, err
+	}
 	return len(courses) > 0, nil
 }
 
@@ -129,7 +139,7 @@ func ScrapeCourses(ctx context.Context, client *scraper.Client, year, term int,
 			return nil, fmt.Errorf("failed to fetch courses: %w", err)
 		}
 
-		return parseCoursesPage(ctx, doc, year, term), nil
+		return parseCourses(ctx, client, doc, year, term)
 	}
 
 	// Otherwise, use GET to queryByKeyword and iterate through all education codes
@@ -166,9 +176,11 @@ func ScrapeCourses(ctx context.Context, client *scraper.Client, year, term int,
 			continue
 		}
 
-		if newCourses := parseCoursesPage(ctx, doc, year, term); len(newCourses) > 0 {
-			courses = append(courses, newCourses...)
+		newCourses, err := parseCourses(ctx, client, doc, year, term)
+		if err != nil {
+			return nil, err // Drift: a partial semester must not be cached
 		}
+		courses = append(courses, newCourses...)
 	}
 
 	if len(courses) == 0 && lastErr != nil {
@@ -216,11 +228,11 @@ func ScrapeCoursesByTeacher(ctx context.Context, client *scraper.Client, year, t
 		return nil, fmt.Errorf("failed to fetch courses by teacher: %w", err)
 	}
 
-	return parseCoursesPage(ctx, doc, year, term), nil
+	return parseCourses(ctx, client, doc, year, term)
 }
 
 // ScrapeCourseByUID scrapes a specific course by its UID (year+term+no)
-// Example UID: 11312U123 (year=113, term=1, no=2U123)
+// Example UID: 1131U0001 (year=113, term=1, no=U0001); see ParseUID
 // Supports automatic URL failover across multiple SEA endpoints
 func ScrapeCourseByUID(ctx context.Context, client *scraper.Client, uid string) (*storage.Course, error) {
 	// Check context before starting
@@ -228,8 +240,9 @@ func ScrapeCourseByUID(ctx context.Context, client *scraper.Client, uid string)
 		return nil, fmt.Errorf("context canceled before scraping course: %w", err)
 	}
 
-	if len(uid) < 5 {
-		return nil, fmt.Errorf("invalid course UID: %s", uid)
+	year, term, no, err := ParseUID(uid)
+	if err != nil {
+		return nil, err
 	}
 
 	// Get working base URL with failover support
@@ -238,22 +251,6 @@ func ScrapeCourseByUID(ctx context.Context, client *scraper.Client, uid string)
 		return nil, fmt.Errorf("failed to get working SEA URL: %w", err)
 	}
 
-	// Parse UID
-	isOver99 := len(uid) >= 9
-
-	var year, term int
-	var no string
-
-	if isOver99 {
-		year, _ = strconv.Atoi(uid[:3])
-		term, _ = strconv.Atoi(uid[3:4])
-		no = uid[4:]
-	} else {
-		year, _ = strconv.Atoi(uid[:2])
-		term, _ = strconv.Atoi(uid[2:3])
-		no = uid[3:]
-	}
-
 	// Build query URL
 	queryURL := fmt.Sprintf("%s%s?qYear=%d&qTerm=%d&courseno=%s&seq1=A&seq2=M",
 		courseBaseURL, courseQueryByKeywordPath, year, term, no)
@@ -263,7 +260,10 @@ func ScrapeCourseByUID(ctx context.Context, client *scraper.Client, uid string)
 		return nil, fmt.Errorf("failed to fetch course: %w", err)
 	}
 
-	courses := parseCoursesPage(ctx, doc, year, term)
+	courses, err := parseCourses(ctx, client, doc, year, term)
+	if err != nil {
+		return nil, err
+	}
 	if len(courses) == 0 {
 		return nil, fmt.Errorf("course not found: %s", uid)
 	}
@@ -271,6 +271,43 @@ func ScrapeCourseByUID(ctx context.Context, client *scraper.Client, uid string)
 	return courses[0], nil
 }
 
+// parseCourses parses a course result page, reporting drift instead of
+// returning an empty list when the page structure changed.
+func parseCourses(ctx context.Context, client *scraper.Client, doc *goquery.Document, year, term int) ([]*storage.Course, error) {
+	courses := parseCoursesPage(ctx, doc, year, term)
+	if detail := courseDrift(doc, len(courses)); detail != "" {
+		return nil, client.Drift(ctx, courseListParser, detail)
+	}
+	return courses, nil
+}
+
+// courseDrift checks a course result page against what parseCoursesPage
+// relies on, given how many courses it parsed. Returns what does not match,
+// or "" when the page looks as expected.
+// Rows with fewer than two cells (e.g., a "查無資料" row) are not results, so
+// a search that legitimately finds nothing is not drift.
+func courseDrift(doc *goquery.Document, parsed int) string {
+	rows, wide := 0, 0
+	doc.Find("table tbody tr").Each(func(_ int, tr *goquery.Selection) {
+		n := tr.Find("td").Length()
+		if n >= 2 {
+			rows++
+		}
+		if n >= minCourseColumns {
+			wide++
+		}
+	})
+
+	switch {
+	case rows == 0 || parsed > 0:
+		return ""
+	case wide == 0:
+		return fmt.Sprintf("%d result rows but none has %d cells", rows, minCourseColumns)
+	default:
+		return fmt.Sprintf("no course titles in %d rows", wide)
+	}
+}
+
 // parseCoursesPage extracts course information from a search result page
 // When term=0, extracts term from each row (field 2); otherwise uses the provided term value
 func parseCoursesPage(ctx context.Context, doc *goquery.Document, year, term int) []*storage.Course {
@@ -286,7 +323,7 @@ func parseCoursesPage(ctx context.Context, doc *goquery.Document, year, term int
 	// Parse each course row in tbody
 	table.Find("tbody tr").Each(func(i int, tr *goquery.Selection) {
 		tds := tr.Find("td")
-		if tds.Length() < 14 {
+		if tds.Length() < minCourseColumns {
 			return
 		}
 
@@ -311,6 +348,7 @@ func parseCoursesPage(ctx context.Context, doc *goquery.Document, year, term int
 
 		// Extract title, detail URL, note, location (field 7)
 		title, detailURL, note, location := parseTitleField(tds.Eq(7))
+		titleEn := parseEnglishTitle(tds.Eq(7))
 
 		// Skip courses without a title (parsing error or invalid data)
 		if title == "" {
@@ -333,7 +371,7 @@ func parseCoursesPage(ctx context.Context, doc *goquery.Document, year, term int
 		}
 
 		// Generate UID
-		uid := fmt.Sprintf("%d%d%s", year, rowTerm, no)
+		uid := FormatUID(year, rowTerm, no)
 
 		// Build full detail URL with show_info=all for complete syllabus data
 		// Original detailURL format: "?g_serial=U3556&g_year=114&g_term=1"
@@ -349,6 +387,7 @@ func parseCoursesPage(ctx context.Context, doc *goquery.Document, year, term int
 			Term:           rowTerm,
 			No:             no,
 			Title:          title,
+			TitleEn:        titleEn,
 			Teachers:       teachers,
 			TeacherURLs:    teacherURLs,
 			Times:          times,
@@ -399,6 +438,29 @@ func parseTitleField(td *goquery.Selection) (title, detailURL, note, location st
 	return
 }
 
+// parseEnglishTitle returns the English title the title field shows as plain
+// text after the course link (e.g. "<a>資料結構</a><br>Data Structures"), or
+// "" when the field has none. The link and the <font> note are skipped.
+func parseEnglishTitle(td *goquery.Selection) string {
+	rest := td.Clone()
+	rest.Find("a, font").Remove()
+	text := strings.Join(strings.Fields(rest.Text()), " ")
+
+	hasLatin := false
+	for _, r := range text {
+		if unicode.Is(unicode.Han, r) {
+			return ""
+		}
+		if r < unicode.MaxASCII && unicode.IsLetter(r) {
+			hasLatin = true
+		}
+	}
+	if !hasLatin {
+		return ""
+	}
+	return text
+}
+
 // parseTeacherField parses the teacher field to extract teacher names and URLs
 // URLs are hard-coded to domain for user-facing display
 func parseTeacherField(td *goquery.Selection) (teachers []string, teacherURLs []string) {
@@ -120,6 +120,62 @@ func TestParseMajorAndTypeFields(t *testing.T) {
 	}
 }
 
+func TestParseEnglishTitle(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		cell string
+		want string
+	}{
+		{"english after link", `<a href="?g_serial=U0001">資料結構</a><br>Data  Structures`, "Data Structures"},
+		{"note skipped", `<a href="#">資料結構</a><br>Data Structures<br><font>備註：英語授課</font>`, "Data Structures"},
+		{"no english", `<a href="#">資料結構</a><br><font>備註：限本系</font>`, ""},
+		{"chinese text", `<a href="#">資料結構</a><br>（停開）`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader("<table><tr><td>" + tt.cell + "</td></tr></table>"))
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+			if got := parseEnglishTitle(doc.Find("td").First()); got != tt.want {
+				t.Errorf("parseEnglishTitle() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 // Note: UID parsing logic is tested in the course handler module.
 // Scraper tests focus on format validation and regex patterns only.
 // Course name extraction uses standard library strings.TrimSpace - no need to test stdlib.
+
+func TestCourseDrift(t *testing.T) {
+	t.Parallel()
+	wideRow := "<tr>" + strings.Repeat("<td>x</td>", minCourseColumns) + "</tr>"
+
+	tests := []struct {
+		name      string
+		body      string
+		parsed    int
+		wantDrift bool
+	}{
+		{"no table", `<p>系統維護中</p>`, 0, false},
+		{"no results row", `<table><tbody><tr><td colspan="14">查無資料</td></tr></tbody></table>`, 0, false},
+		{"parsed courses", `<table><tbody>` + wideRow + `</tbody></table>`, 1, false},
+		{"narrow rows", `<table><tbody><tr><td>U0001</td><td>程式設計</td></tr></tbody></table>`, 0, true},
+		{"rows without titles", `<table><tbody>` + wideRow + wideRow + `</tbody></table>`, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader("<html><body>" + tt.body + "</body></html>"))
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+			if got := courseDrift(doc, tt.parsed); (got != "") != tt.wantDrift {
+				t.Errorf("courseDrift() = %q, want drift %v", got, tt.wantDrift)
+			}
+		})
+	}
+}
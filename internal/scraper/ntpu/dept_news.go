@@ -0,0 +1,80 @@
+package ntpu
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/garyellow/ntpu-linebot-go/internal/scraper"
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+)
+
+const (
+	// MaxDepartmentNews is the number of newest announcements kept per department.
+	MaxDepartmentNews = 10
+
+	// Selectors of the announcement list on department sites. Department
+	// sites run the school's RPAGE CMS, which renders each list row as
+	// .mtitle with the post link and an .mdate date.
+	deptNewsItemSelector = ".mtitle"
+	deptNewsDateSelector = ".mdate"
+
+	// deptNewsParser names announcement list pages in drift reports
+	deptNewsParser = "department_news"
+)
+
+// ScrapeDepartmentNews scrapes the newest announcements (at most
+// MaxDepartmentNews) from a department website's announcement list page.
+// A page without list rows is reported as drift: the configured URL points at
+// another page or the site's layout changed.
+func ScrapeDepartmentNews(ctx context.Context, client *scraper.Client, deptCode, pageURL string) ([]storage.DepartmentNews, error) {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid announcement URL for department %s: %w", deptCode, err)
+	}
+
+	doc, err := client.GetDocument(ctx, pageURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch announcements of department %s: %w", deptCode, err)
+	}
+
+	news := parseDepartmentNews(doc, base, deptCode)
+	if len(news) == 0 {
+		rows := doc.Find(deptNewsItemSelector).Length()
+		return nil, client.Drift(ctx, deptNewsParser, fmt.Sprintf("no announcements in %d list rows of %s", rows, base.Host))
+	}
+	return news, nil
+}
+
+// parseDepartmentNews parses the announcement rows of a list page, resolving
+// links against base. Rows without a title or link are skipped.
+func parseDepartmentNews(doc *goquery.Document, base *url.URL, deptCode string) []storage.DepartmentNews {
+	var news []storage.DepartmentNews
+	doc.Find(deptNewsItemSelector).EachWithBreak(func(_ int, row *goquery.Selection) bool {
+		link := row.Find("a[href]").First()
+		title := strings.TrimSpace(link.AttrOr("title", ""))
+		if title == "" {
+			title = strings.TrimSpace(link.Text())
+		}
+		href, err := base.Parse(strings.TrimSpace(link.AttrOr("href", "")))
+		if title == "" || err != nil || href.String() == base.String() {
+			return true
+		}
+
+		date := strings.TrimSpace(row.Find(deptNewsDateSelector).First().Text())
+		if date == "" {
+			date = strings.TrimSpace(row.Parent().Find(deptNewsDateSelector).First().Text())
+		}
+
+		news = append(news, storage.DepartmentNews{
+			DeptCode: deptCode,
+			Title:    title,
+			URL:      href.String(),
+			Date:     date,
+		})
+		return len(news) < MaxDepartmentNews
+	})
+	return news
+}
@@ -0,0 +1,48 @@
+package ntpu
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestParseDepartmentNews(t *testing.T) {
+	t.Parallel()
+	html := `<div class="d-txt"><div class="mtitle">
+		<i class="mdate before">2025-04-01</i>
+		<a href="/p/406-1065-3,r5.php" title="期中考公告">期中考...</a>
+	</div></div>
+	<div class="d-txt"><div class="mtitle"><a href="https://www.csie.ntpu.edu.tw/p/406-1065-2,r5.php">專題說明會</a></div>
+		<i class="mdate after">2025-03-20</i></div>
+	<div class="mtitle"><a href="#">  </a></div>
+	<div class="mtitle">No link</div>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+	base, _ := url.Parse("https://www.csie.ntpu.edu.tw/p/403-1065-1.php")
+
+	news := parseDepartmentNews(doc, base, "85")
+	if len(news) != 2 {
+		t.Fatalf("parseDepartmentNews() = %+v, want 2 announcements", news)
+	}
+	if n := news[0]; n.Title != "期中考公告" || n.URL != "https://www.csie.ntpu.edu.tw/p/406-1065-3,r5.php" || n.Date != "2025-04-01" || n.DeptCode != "85" {
+		t.Errorf("news[0] = %+v, want the full title, absolute URL and date", n)
+	}
+	if n := news[1]; n.Title != "專題說明會" || n.Date != "2025-03-20" {
+		t.Errorf("news[1] = %+v, want the link text and the date outside .mtitle", n)
+	}
+}
+
+func TestParseDepartmentNews_Limit(t *testing.T) {
+	t.Parallel()
+	html := strings.Repeat(`<div class="mtitle"><a href="/p/1.php">公告</a></div>`, MaxDepartmentNews+5)
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html))
+	base, _ := url.Parse("https://www.csie.ntpu.edu.tw/")
+
+	if news := parseDepartmentNews(doc, base, "85"); len(news) != MaxDepartmentNews {
+		t.Errorf("parseDepartmentNews() returned %d announcements, want %d", len(news), MaxDepartmentNews)
+	}
+}
@@ -113,6 +113,9 @@ var PhDDepartmentCodes = map[string]string{
 // DepartmentNames provides reverse mappings: code -> name
 var DepartmentNames = reverseMap(DepartmentCodes)
 
+// FullDepartmentNames provides reverse mappings for full department names: code -> name.
+var FullDepartmentNames = reverseMap(FullDepartmentCodes)
+
 // MasterDepartmentNames provides reverse mappings for master degree programs: code -> name.
 var MasterDepartmentNames = reverseMap(MasterDepartmentCodes)
 
@@ -128,6 +131,41 @@ func reverseMap(m map[string]string) map[string]string {
 	return result
 }
 
+// LookupDepartment returns the undergraduate department code named by name.
+// Short names (資工), full names (資訊工程學系), either with a 系 suffix
+// (資工系), and codes (85) are accepted.
+func LookupDepartment(name string) (string, bool) {
+	// Try each way of splitting off the 學系/系 suffix: "法學系" is 法學 + 系,
+	// not 法 + 學系.
+	for _, n := range []string{name, strings.TrimSuffix(name, "學系"), strings.TrimSuffix(name, "系")} {
+		for _, code := range []string{
+			DepartmentCodes[n],
+			FullDepartmentCodes[n+"學系"],
+			FullDepartmentCodes[n],
+		} {
+			if code != "" {
+				return code, true
+			}
+		}
+		if _, ok := DepartmentNames[n]; ok {
+			return n, true
+		}
+	}
+	return "", false
+}
+
+// BaseDepartmentCode maps a department group code to its department: the law
+// groups (712/714/716) belong to the law department (71). Other codes are
+// returned unchanged.
+func BaseDepartmentCode(code string) string {
+	if len(code) == 3 {
+		if _, ok := DepartmentNames[code[:2]]; ok {
+			return code[:2]
+		}
+	}
+	return code
+}
+
 // IsLawDepartment returns true if the department code belongs to Law School (71x).
 // Used to determine if "組" should be used instead of "系" in display text.
 func IsLawDepartment(deptCode string) bool {
@@ -175,6 +213,11 @@ func GetDegreeTypeName(studentID string) string {
 
 const (
 	studentSearchPath = "/portfolio/search.php"
+
+	// studentEntrySelector matches one search result: <div class="bloglistTitle">
+	studentEntrySelector = "div.bloglistTitle"
+	// studentListParser names student search pages in drift reports.
+	studentListParser = "student_list"
 )
 
 // UndergradDeptCodes contains undergraduate department codes for scraping.
@@ -295,7 +338,11 @@ func ScrapeStudentsByYear(ctx context.Context, client *scraper.Client, year int,
 	})
 
 	// Parse first page
-	students = append(students, parseStudentPage(doc, year)...)
+	firstPage, err := parseStudents(ctx, client, doc, year)
+	if err != nil {
+		return nil, err
+	}
+	students = append(students, firstPage...)
 
 	// Fetch and parse remaining pages
 	for page := 2; page <= totalPages; page++ {
@@ -311,19 +358,33 @@ func ScrapeStudentsByYear(ctx context.Context, client *scraper.Client, year int,
 			return students, fmt.Errorf("failed to fetch page %d: %w", page, err)
 		}
 
-		students = append(students, parseStudentPage(doc, year)...)
+		pageStudents, err := parseStudents(ctx, client, doc, year)
+		if err != nil {
+			return nil, err // Drift: partial results must not be cached
+		}
+		students = append(students, pageStudents...)
 	}
 
 	return students, nil
 }
 
+// parseStudents parses a student search result page, reporting drift instead
+// of returning an empty list when result entries no longer parse.
+func parseStudents(ctx context.Context, client *scraper.Client, doc *goquery.Document, year int) ([]*storage.Student, error) {
+	students := parseStudentPage(doc, year)
+	if entries := doc.Find(studentEntrySelector).Length(); entries > 0 && len(students) == 0 {
+		return nil, client.Drift(ctx, studentListParser, fmt.Sprintf("no student links in %d result entries", entries))
+	}
+	return students, nil
+}
+
 // parseStudentPage extracts student information from a search result page
 func parseStudentPage(doc *goquery.Document, year int) []*storage.Student {
 	students := make([]*storage.Student, 0)
 	cachedAt := time.Now().Unix()
 
 	// Find all student entries: <div class="bloglistTitle">
-	doc.Find("div.bloglistTitle").Each(func(i int, s *goquery.Selection) {
+	doc.Find(studentEntrySelector).Each(func(i int, s *goquery.Selection) {
 		// Get student name from <a> tag
 		name := strings.TrimSpace(s.Find("a").Text())
 
@@ -380,7 +441,7 @@ func ScrapeStudentByID(ctx context.Context, client *scraper.Client, studentID st
 
 	// Find the student entry
 	var student *storage.Student
-	doc.Find("div.bloglistTitle").Each(func(i int, s *goquery.Selection) {
+	doc.Find(studentEntrySelector).Each(func(i int, s *goquery.Selection) {
 		if i > 0 {
 			return // Only take the first match
 		}
@@ -404,6 +465,9 @@ func ScrapeStudentByID(ctx context.Context, client *scraper.Client, studentID st
 	})
 
 	if student == nil {
+		if entries := doc.Find(studentEntrySelector).Length(); entries > 0 {
+			return nil, client.Drift(ctx, studentListParser, fmt.Sprintf("no student name in %d result entries", entries))
+		}
 		return nil, fmt.Errorf("student not found: %s", studentID)
 	}
 
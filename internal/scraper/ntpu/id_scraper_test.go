@@ -56,6 +56,39 @@ func TestDepartmentCodeMappings(t *testing.T) {
 	}
 }
 
+func TestLookupDepartment(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		want string
+		ok   bool
+	}{
+		{"資工", "85", true},
+		{"資工系", "85", true},
+		{"資訊工程學系", "85", true},
+		{"資訊工程", "85", true},
+		{"85", "85", true},
+		{"法學系", "712", true},
+		{"社學系", "742", true},
+		{"資", "", false},
+		{"99", "", false},
+	}
+	for _, tt := range tests {
+		if got, ok := LookupDepartment(tt.name); got != tt.want || ok != tt.ok {
+			t.Errorf("LookupDepartment(%q) = (%q, %v), want (%q, %v)", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestBaseDepartmentCode(t *testing.T) {
+	t.Parallel()
+	for code, want := range map[string]string{"712": "71", "716": "71", "71": "71", "742": "742", "85": "85"} {
+		if got := BaseDepartmentCode(code); got != want {
+			t.Errorf("BaseDepartmentCode(%q) = %q, want %q", code, got, want)
+		}
+	}
+}
+
 // TestExtractYear tests the year extraction logic
 func TestExtractYear(t *testing.T) {
 	t.Parallel()
@@ -40,6 +40,9 @@ const (
 	lmsCourseID = "28286"
 	maxPages    = 10 // Safety limit to prevent infinite loops
 
+	// programListParser names program folder pages in drift reports
+	programListParser = "program_list"
+
 	// User-facing URLs use domain (not IP) for better UX
 	lmsUserFacingURL = "https://lms.ntpu.edu.tw"
 )
@@ -56,6 +59,7 @@ func ScrapePrograms(ctx context.Context, client *scraper.Client) ([]ProgramInfo,
 
 	seen := make(map[string]bool) // Track by cid to avoid duplicates
 	var programs []ProgramInfo
+	failed := 0
 
 	for _, folder := range programFolders {
 		folderPrograms, err := scrapeFolderAllPages(ctx, client, baseURL, folder, seen)
@@ -65,11 +69,17 @@ func ScrapePrograms(ctx context.Context, client *scraper.Client) ([]ProgramInfo,
 				"folder_id", folder.ID,
 				"category", folder.Category,
 				"error", err)
+			failed++
 			continue
 		}
 		programs = append(programs, folderPrograms...)
 	}
 
+	// Every folder lists programs; none from the folders that loaded means the page changed
+	if len(programs) == 0 && failed < len(programFolders) {
+		return nil, client.Drift(ctx, programListParser, fmt.Sprintf("no programs in %d folders", len(programFolders)-failed))
+	}
+
 	return programs, nil
 }
 
@@ -0,0 +1,55 @@
+package ntpu
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/semester"
+)
+
+// ErrInvalidUID is returned by ParseUID for strings that are not course UIDs.
+var ErrInvalidUID = errors.New("invalid course UID")
+
+// UIDRegex finds course UIDs in free text: {year}{term}{no}, e.g. 1131U0001
+// or 991U0001. Matches are case-insensitive; pass them through ParseUID to
+// validate the term and normalize the case.
+var UIDRegex = regexp.MustCompile(`(?i)\d{3,4}[umnp]\d{4}`)
+
+// fullUIDRegex matches a whole string that is shaped like a UID.
+var fullUIDRegex = regexp.MustCompile(`(?i)^\d{3,4}[umnp]\d{4}$`)
+
+// ParseUID splits a course UID into its year, term and course number.
+//
+// The year has no fixed width: UIDs from ROC year 100 on have a three-digit
+// year ("1131U0001" is 113-1, U0001), earlier ones a two-digit year
+// ("991U0001" is 99-1, U0001). The course number is always the last five
+// characters, so the year is whatever precedes the one-digit term.
+// Lowercase input is accepted; the returned course number is uppercase.
+func ParseUID(uid string) (year, term int, no string, err error) {
+	if !fullUIDRegex.MatchString(uid) {
+		return 0, 0, "", fmt.Errorf("%w: %q", ErrInvalidUID, uid)
+	}
+	uid = strings.ToUpper(uid)
+	sem, err := semester.FromUID(uid)
+	if err != nil {
+		return 0, 0, "", fmt.Errorf("%w: %q", ErrInvalidUID, uid)
+	}
+	return sem.Year, sem.Term, uid[len(uid)-5:], nil
+}
+
+// FormatUID builds the UID for a course number in a semester; it is the
+// inverse of ParseUID.
+func FormatUID(year, term int, no string) string {
+	return fmt.Sprintf("%d%d%s", year, term, strings.ToUpper(no))
+}
+
+// NormalizeUID validates uid and returns it in canonical (uppercase) form.
+func NormalizeUID(uid string) (string, error) {
+	year, term, no, err := ParseUID(uid)
+	if err != nil {
+		return "", err
+	}
+	return FormatUID(year, term, no), nil
+}
@@ -0,0 +1,105 @@
+package ntpu
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseUID(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		uid      string
+		wantYear int
+		wantTerm int
+		wantNo   string
+		wantErr  bool
+	}{
+		// Three-digit years (ROC 100+)
+		{"1131U0001", 113, 1, "U0001", false},
+		{"1122M0003", 112, 2, "M0003", false},
+		{"1001N1234", 100, 1, "N1234", false},
+		{"1152P9999", 115, 2, "P9999", false},
+		{"1131u0001", 113, 1, "U0001", false}, // Lowercase
+
+		// Two-digit years (before ROC 100)
+		{"991U0001", 99, 1, "U0001", false},
+		{"982M0002", 98, 2, "M0002", false},
+
+		// Invalid term
+		{"1133U0001", 0, 0, "", true},
+		{"1130U0001", 0, 0, "", true},
+		{"993U0001", 0, 0, "", true},
+
+		// Malformed
+		{"", 0, 0, "", true},
+		{"U0001", 0, 0, "", true},
+		{"11U0001", 0, 0, "", true},     // No room for a year and a term
+		{"11311U0001", 0, 0, "", true},  // Five digits before the course number
+		{"1131X0001", 0, 0, "", true},   // Unknown education code
+		{"1131U001", 0, 0, "", true},    // Short course number
+		{"1131U00012", 0, 0, "", true},  // Long course number
+		{" 1131U0001", 0, 0, "", true},  // Surrounding space
+		{"課程1131U0001", 0, 0, "", true}, // Embedded in text
+	}
+	for _, tt := range tests {
+		t.Run(tt.uid, func(t *testing.T) {
+			t.Parallel()
+			year, term, no, err := ParseUID(tt.uid)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseUID(%q) error = %v, wantErr %v", tt.uid, err, tt.wantErr)
+			}
+			if err != nil {
+				if !errors.Is(err, ErrInvalidUID) {
+					t.Errorf("ParseUID(%q) error = %v, want ErrInvalidUID", tt.uid, err)
+				}
+				return
+			}
+			if year != tt.wantYear || term != tt.wantTerm || no != tt.wantNo {
+				t.Errorf("ParseUID(%q) = (%d, %d, %q), want (%d, %d, %q)",
+					tt.uid, year, term, no, tt.wantYear, tt.wantTerm, tt.wantNo)
+			}
+		})
+	}
+}
+
+func TestFormatUID(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		year, term int
+		no         string
+		want       string
+	}{
+		{113, 1, "U0001", "1131U0001"},
+		{99, 2, "m0002", "992M0002"},
+	}
+	for _, tt := range tests {
+		got := FormatUID(tt.year, tt.term, tt.no)
+		if got != tt.want {
+			t.Errorf("FormatUID(%d, %d, %q) = %q, want %q", tt.year, tt.term, tt.no, got, tt.want)
+		}
+		// Round trip
+		year, term, no, err := ParseUID(got)
+		if err != nil || year != tt.year || term != tt.term || no != strings.ToUpper(tt.no) {
+			t.Errorf("ParseUID(FormatUID(...)) = (%d, %d, %q, %v)", year, term, no, err)
+		}
+	}
+}
+
+func TestNormalizeUID(t *testing.T) {
+	t.Parallel()
+	if got, err := NormalizeUID("1131u0001"); err != nil || got != "1131U0001" {
+		t.Errorf("NormalizeUID(1131u0001) = %q, %v", got, err)
+	}
+	if _, err := NormalizeUID("1133U0001"); !errors.Is(err, ErrInvalidUID) {
+		t.Errorf("NormalizeUID(1133U0001) error = %v, want ErrInvalidUID", err)
+	}
+}
+
+func TestUIDRegex(t *testing.T) {
+	t.Parallel()
+	got := UIDRegex.FindAllString("課表 1131u0001, 991M0002 和 U0003", -1)
+	if len(got) != 2 || got[0] != "1131u0001" || got[1] != "991M0002" {
+		t.Errorf("UIDRegex.FindAllString() = %v", got)
+	}
+}
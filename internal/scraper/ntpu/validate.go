@@ -0,0 +1,87 @@
+package ntpu
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+)
+
+// ErrInvalidRecord is returned by the Validate functions for scraped records
+// that must not be cached: a parse that went wrong (page layout drift, a
+// truncated response) would otherwise be served until the cache expires.
+var ErrInvalidRecord = errors.New("invalid scraped record")
+
+// ValidateStudent checks that the ID is 8 or 9 digits, the name is set, and
+// the year matches the admission year carried in the ID and lies within the
+// years NTPU has ID data for.
+func ValidateStudent(s *storage.Student) error {
+	if (len(s.ID) != 8 && len(s.ID) != 9) || strings.Trim(s.ID, "0123456789") != "" {
+		return fmt.Errorf("%w: student ID %q", ErrInvalidRecord, s.ID)
+	}
+	if strings.TrimSpace(s.Name) == "" {
+		return fmt.Errorf("%w: student %s has no name", ErrInvalidRecord, s.ID)
+	}
+	if s.Year != ExtractYear(s.ID) || s.Year < config.NTPUFoundedYear || s.Year > config.IDDataCutoffYear {
+		return fmt.Errorf("%w: student %s has year %d", ErrInvalidRecord, s.ID, s.Year)
+	}
+	return nil
+}
+
+// ValidateCourse checks that the UID agrees with the year, term and course
+// number, the year lies between NTPU's founding and next year, and the title
+// is set.
+func ValidateCourse(c *storage.Course) error {
+	year, term, no, err := ParseUID(c.UID)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidRecord, err)
+	}
+	if year != c.Year || term != c.Term || !strings.EqualFold(no, c.No) {
+		return fmt.Errorf("%w: course %s has semester %d-%d and number %q",
+			ErrInvalidRecord, c.UID, c.Year, c.Term, c.No)
+	}
+	if maxYear := time.Now().Year() - 1911 + 1; c.Year < config.NTPUFoundedYear || c.Year > maxYear {
+		return fmt.Errorf("%w: course %s has year %d", ErrInvalidRecord, c.UID, c.Year)
+	}
+	if strings.TrimSpace(c.Title) == "" {
+		return fmt.Errorf("%w: course %s has no title", ErrInvalidRecord, c.UID)
+	}
+	return nil
+}
+
+// ValidateContact checks that the contact has a UID, a known type, a name,
+// and at least one way to reach it: extension, phone, email, website or
+// location.
+func ValidateContact(c *storage.Contact) error {
+	if c.UID == "" {
+		return fmt.Errorf("%w: contact %q has no UID", ErrInvalidRecord, c.Name)
+	}
+	if c.Type != "individual" && c.Type != "organization" {
+		return fmt.Errorf("%w: contact %s has type %q", ErrInvalidRecord, c.UID, c.Type)
+	}
+	if strings.TrimSpace(c.Name) == "" {
+		return fmt.Errorf("%w: contact %s has no name", ErrInvalidRecord, c.UID)
+	}
+	if c.Extension == "" && c.Phone == "" && c.Email == "" && c.Website == "" && c.Location == "" {
+		return fmt.Errorf("%w: contact %s (%s) has no reachable field", ErrInvalidRecord, c.UID, c.Name)
+	}
+	return nil
+}
+
+// FilterValid returns the records validate accepts, in order, and the errors
+// for the ones it rejects. The input slice is not modified.
+func FilterValid[T any](records []*T, validate func(*T) error) ([]*T, []error) {
+	valid := make([]*T, 0, len(records))
+	var rejected []error
+	for _, r := range records {
+		if err := validate(r); err != nil {
+			rejected = append(rejected, err)
+			continue
+		}
+		valid = append(valid, r)
+	}
+	return valid, rejected
+}
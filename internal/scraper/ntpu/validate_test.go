@@ -0,0 +1,119 @@
+package ntpu
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/storage"
+)
+
+func TestValidateStudent(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		student storage.Student
+		wantErr bool
+	}{
+		{"valid 9-digit ID", storage.Student{ID: "411247001", Name: "王小明", Year: 112}, false},
+		{"valid 8-digit ID", storage.Student{ID: "49947001", Name: "李小華", Year: 99}, false},
+		{"short ID", storage.Student{ID: "4112470", Name: "王小明", Year: 112}, true},
+		{"non-digit ID", storage.Student{ID: "41124700A", Name: "王小明", Year: 112}, true},
+		{"empty name", storage.Student{ID: "411247001", Name: " ", Year: 112}, true},
+		{"year contradicts ID", storage.Student{ID: "411247001", Name: "王小明", Year: 111}, true},
+		{"year after cutoff", storage.Student{ID: "412047001", Name: "王小明", Year: 120}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := ValidateStudent(&tt.student)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateStudent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidRecord) {
+				t.Errorf("ValidateStudent() error = %v, want ErrInvalidRecord", err)
+			}
+		})
+	}
+}
+
+func TestValidateCourse(t *testing.T) {
+	t.Parallel()
+	valid := storage.Course{UID: "1131U0001", Year: 113, Term: 1, No: "U0001", Title: "微積分"}
+	tests := []struct {
+		name    string
+		modify  func(c *storage.Course)
+		wantErr bool
+	}{
+		{"valid", func(*storage.Course) {}, false},
+		{"two-digit year", func(c *storage.Course) { c.UID, c.Year, c.No = "991U0001", 99, "U0001" }, false},
+		{"malformed UID", func(c *storage.Course) { c.UID = "U0001" }, true},
+		{"term mismatch", func(c *storage.Course) { c.Term = 2 }, true},
+		{"year mismatch", func(c *storage.Course) { c.Year = 112 }, true},
+		{"number mismatch", func(c *storage.Course) { c.No = "U0002" }, true},
+		{"year before founding", func(c *storage.Course) { c.UID, c.Year = "801U0001", 80 }, true},
+		{"year far ahead", func(c *storage.Course) { c.UID, c.Year = "9991U0001", 999 }, true},
+		{"empty title", func(c *storage.Course) { c.Title = "" }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			c := valid
+			tt.modify(&c)
+			err := ValidateCourse(&c)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateCourse(%+v) error = %v, wantErr %v", c, err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidRecord) {
+				t.Errorf("ValidateCourse() error = %v, want ErrInvalidRecord", err)
+			}
+		})
+	}
+}
+
+func TestValidateContact(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		contact storage.Contact
+		wantErr bool
+	}{
+		{"individual with extension", storage.Contact{UID: "c1", Type: "individual", Name: "王小明", Extension: "12345"}, false},
+		{"organization with website", storage.Contact{UID: "o1", Type: "organization", Name: "資訊中心", Website: "https://example.com"}, false},
+		{"organization with location", storage.Contact{UID: "o2", Type: "organization", Name: "教務處", Location: "行政大樓"}, false},
+		{"no reachable field", storage.Contact{UID: "c2", Type: "individual", Name: "王小明", Title: "組長"}, true},
+		{"no UID", storage.Contact{Type: "individual", Name: "王小明", Email: "a@example.com"}, true},
+		{"unknown type", storage.Contact{UID: "c3", Type: "", Name: "王小明", Email: "a@example.com"}, true},
+		{"no name", storage.Contact{UID: "c4", Type: "individual", Email: "a@example.com"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := ValidateContact(&tt.contact)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateContact() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidRecord) {
+				t.Errorf("ValidateContact() error = %v, want ErrInvalidRecord", err)
+			}
+		})
+	}
+}
+
+func TestFilterValid(t *testing.T) {
+	t.Parallel()
+	courses := []*storage.Course{
+		{UID: "1131U0001", Year: 113, Term: 1, No: "U0001", Title: "微積分"},
+		{UID: "1131U0002", Year: 113, Term: 1, No: "U0002"}, // No title
+		{UID: "1131U0003", Year: 113, Term: 1, No: "U0003", Title: "線性代數"},
+	}
+	valid, rejected := FilterValid(courses, ValidateCourse)
+	if len(valid) != 2 || valid[0] != courses[0] || valid[1] != courses[2] {
+		t.Errorf("FilterValid() valid = %v, want the first and third course", valid)
+	}
+	if len(rejected) != 1 || !errors.Is(rejected[0], ErrInvalidRecord) {
+		t.Errorf("FilterValid() rejected = %v, want one ErrInvalidRecord", rejected)
+	}
+	if len(courses) != 3 {
+		t.Error("FilterValid() modified the input")
+	}
+}
@@ -0,0 +1,144 @@
+package scraper
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"regexp"
+	"sync"
+
+	"github.com/corpix/uarand"
+)
+
+// maxRedirects matches the default http.Client redirect limit.
+const maxRedirects = 10
+
+// loginPathPattern matches redirect targets that mean the session expired
+// (e.g., /login.php, /sso/signin).
+var loginPathPattern = regexp.MustCompile(`(?i)log_?in|logon|sign_?in`)
+
+// isLoginRedirect reports whether resp redirects to a login page.
+func isLoginRedirect(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther, http.StatusTemporaryRedirect:
+	default:
+		return false
+	}
+	loc, err := resp.Location()
+	return err == nil && loginPathPattern.MatchString(loc.Path)
+}
+
+// checkRedirect follows redirects like the default policy but stops at login
+// pages, so doRequest sees the redirect and can renew the session.
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if loginPathPattern.MatchString(req.URL.Path) {
+		return http.ErrUseLastResponse
+	}
+	if len(via) >= maxRedirects {
+		return errors.New("stopped after 10 redirects")
+	}
+	return nil
+}
+
+// sessionJar keeps one cookie jar per source (a baseURLs domain such as
+// "lms"), so one source's session can be dropped without touching the others.
+// Hosts outside baseURLs share the "" jar. Safe for concurrent use.
+type sessionJar struct {
+	hosts map[string]string // host -> source
+
+	mu   sync.Mutex
+	jars map[string]*cookiejar.Jar
+}
+
+// newSessionJar creates a jar for hosts mapped to sources (see sourceHosts).
+func newSessionJar(hosts map[string]string) *sessionJar {
+	return &sessionJar{hosts: hosts, jars: make(map[string]*cookiejar.Jar)}
+}
+
+// source returns the source a URL belongs to.
+func (j *sessionJar) source(u *url.URL) string {
+	return j.hosts[u.Hostname()]
+}
+
+// jar returns the jar of source, creating it on first use.
+func (j *sessionJar) jar(source string) *cookiejar.Jar {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	jar, ok := j.jars[source]
+	if !ok {
+		jar, _ = cookiejar.New(nil) // Never fails without options
+		j.jars[source] = jar
+	}
+	return jar
+}
+
+// reset drops every cookie of source.
+func (j *sessionJar) reset(source string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	delete(j.jars, source)
+}
+
+// SetCookies implements http.CookieJar.
+func (j *sessionJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.jar(j.source(u)).SetCookies(u, cookies)
+}
+
+// Cookies implements http.CookieJar.
+func (j *sessionJar) Cookies(u *url.URL) []*http.Cookie {
+	return j.jar(j.source(u)).Cookies(u)
+}
+
+// renewSession drops the cookies of reqURL's source and loads the host's
+// landing page to get a fresh session. Errors are ignored: the retried
+// request reports them.
+func (c *Client) renewSession(ctx context.Context, reqURL string) {
+	u, err := url.Parse(reqURL)
+	if err != nil {
+		return
+	}
+	c.jar.reset(c.jar.source(u))
+
+	landing := &url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/"}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, landing.String(), http.NoBody)
+	if err != nil {
+		return
+	}
+	req.Header.Set("User-Agent", c.randomUserAgent())
+
+	resp, err := c.httpClient.Do(req) //nolint:gosec // G704: host comes from the request being retried
+	if err != nil {
+		return
+	}
+	_ = resp.Body.Close()
+}
+
+// SetUserAgents sets the user agents requests pick from at random.
+// An empty pool uses generated browser user agents.
+func (c *Client) SetUserAgents(agents []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.userAgents = append([]string(nil), agents...)
+}
+
+// randomUserAgent returns a random user agent from the configured pool,
+// or a generated browser user agent when the pool is empty.
+func (c *Client) randomUserAgent() string {
+	c.mu.RLock()
+	agents := c.userAgents
+	c.mu.RUnlock()
+
+	if len(agents) == 0 {
+		return uarand.GetRandom()
+	}
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(len(agents))))
+	if err != nil {
+		return agents[0]
+	}
+	return agents[n.Int64()]
+}
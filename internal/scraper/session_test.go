@@ -0,0 +1,143 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestIsLoginRedirect(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		status   int
+		location string
+		want     bool
+	}{
+		{"login page", http.StatusFound, "/login.php?next=/portfolio", true},
+		{"sso signin", http.StatusSeeOther, "https://sso.ntpu.edu.tw/SignIn", true},
+		{"other redirect", http.StatusFound, "/portfolio/search.php", false},
+		{"ok response", http.StatusOK, "/login.php", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := httptest.NewRequest(http.MethodGet, "http://lms.example/portfolio", nil)
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{"Location": {tt.location}}, Request: req}
+			if got := isLoginRedirect(resp); got != tt.want {
+				t.Errorf("isLoginRedirect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_RenewsSessionOnLoginRedirect(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	sessions := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			mu.Lock()
+			sessions++
+			sid := fmt.Sprintf("s%d", sessions)
+			mu.Unlock()
+			http.SetCookie(w, &http.Cookie{Name: "sid", Value: sid, Path: "/"})
+		case "/login.php":
+			_, _ = fmt.Fprint(w, "<html>請登入</html>")
+		case "/data":
+			if _, err := r.Cookie("sid"); err != nil {
+				http.Redirect(w, r, "/login.php", http.StatusFound)
+				return
+			}
+			_, _ = fmt.Fprint(w, "<html><p id=ok>ok</p></html>")
+		}
+	}))
+	defer server.Close()
+
+	sessionCount := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return sessions
+	}
+
+	client := NewClient(5*time.Second, 2, map[string][]string{"lms": {server.URL}})
+	doc, err := client.GetDocument(context.Background(), server.URL+"/data")
+	if err != nil {
+		t.Fatalf("GetDocument() error = %v", err)
+	}
+	if doc.Find("#ok").Length() != 1 {
+		t.Error("GetDocument() returned the login page instead of the data page")
+	}
+	if got := sessionCount(); got != 1 {
+		t.Errorf("sessions = %d, want 1", got)
+	}
+
+	// The session cookie is reused afterwards
+	if _, err := client.GetDocument(context.Background(), server.URL+"/data"); err != nil {
+		t.Fatalf("second GetDocument() error = %v", err)
+	}
+	if got := sessionCount(); got != 1 {
+		t.Errorf("sessions after reuse = %d, want 1", got)
+	}
+}
+
+func TestSessionJar_ResetIsPerSource(t *testing.T) {
+	t.Parallel()
+
+	jar := newSessionJar(sourceHosts(map[string][]string{
+		"lms": {"https://lms.example"},
+		"sea": {"https://sea.example"},
+	}))
+	lms, _ := url.Parse("https://lms.example/portfolio")
+	sea, _ := url.Parse("https://sea.example/pls")
+	jar.SetCookies(lms, []*http.Cookie{{Name: "sid", Value: "a"}})
+	jar.SetCookies(sea, []*http.Cookie{{Name: "sid", Value: "b"}})
+
+	jar.reset("lms")
+
+	if got := jar.Cookies(lms); len(got) != 0 {
+		t.Errorf("lms cookies after reset = %v, want none", got)
+	}
+	if got := jar.Cookies(sea); len(got) != 1 {
+		t.Errorf("sea cookies after lms reset = %v, want one", got)
+	}
+}
+
+func TestClient_UserAgentPool(t *testing.T) {
+	t.Parallel()
+
+	pool := []string{"agent-a", "agent-b"}
+	var mu sync.Mutex
+	var seen []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen = append(seen, r.UserAgent())
+		mu.Unlock()
+		_, _ = fmt.Fprint(w, "<html></html>")
+	}))
+	defer server.Close()
+
+	client := NewClient(5*time.Second, 0, map[string][]string{})
+	client.SetUserAgents(pool)
+	for range 5 {
+		if _, err := client.GetDocument(context.Background(), server.URL); err != nil {
+			t.Fatalf("GetDocument() error = %v", err)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for _, ua := range seen {
+		if !slices.Contains(pool, ua) {
+			t.Errorf("User-Agent %q is not from the pool", ua)
+		}
+	}
+}
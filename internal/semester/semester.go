@@ -0,0 +1,152 @@
+// Package semester provides the NTPU academic semester type shared by the
+// course, warmup and RAG code: arithmetic, UID parsing and formatting.
+//
+// Years are ROC years (AD - 1911). Term 1 is the fall semester (上學期,
+// September - January) and term 2 the spring semester (下學期, February - June).
+package semester
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// rocOffset converts AD years to ROC years.
+const rocOffset = 1911
+
+// ErrInvalidUID is returned when a course UID has no valid year-term prefix.
+var ErrInvalidUID = errors.New("invalid course UID")
+
+// Semester represents an academic semester with year and term.
+type Semester struct {
+	Year int // ROC year (e.g., 113)
+	Term int // 1 (Fall/上學期) or 2 (Spring/下學期)
+}
+
+// Prev returns the semester before s: term 2 → term 1 of the same year,
+// term 1 → term 2 of the previous year.
+func (s Semester) Prev() Semester {
+	if s.Term == 1 {
+		return Semester{Year: s.Year - 1, Term: 2}
+	}
+	return Semester{Year: s.Year, Term: 1}
+}
+
+// Next returns the semester after s.
+func (s Semester) Next() Semester {
+	if s.Term == 1 {
+		return Semester{Year: s.Year, Term: 2}
+	}
+	return Semester{Year: s.Year + 1, Term: 1}
+}
+
+// RangeBack returns n semesters starting at s and going backwards (newest first).
+func (s Semester) RangeBack(n int) []Semester {
+	if n <= 0 {
+		return nil
+	}
+	semesters := make([]Semester, n)
+	for i := range n {
+		semesters[i] = s
+		s = s.Prev()
+	}
+	return semesters
+}
+
+// Compare returns -1, 0 or +1 as s is older than, equal to or newer than other.
+func (s Semester) Compare(other Semester) int {
+	if s.Year != other.Year {
+		if s.Year < other.Year {
+			return -1
+		}
+		return 1
+	}
+	if s.Term != other.Term {
+		if s.Term < other.Term {
+			return -1
+		}
+		return 1
+	}
+	return 0
+}
+
+// String returns the compact form, e.g. "113-2".
+func (s Semester) String() string {
+	return fmt.Sprintf("%d-%d", s.Year, s.Term)
+}
+
+// Label returns the display form, e.g. "113 學年度 下學期".
+func (s Semester) Label() string {
+	termStr := "上學期"
+	if s.Term == 2 {
+		termStr = "下學期"
+	}
+	return fmt.Sprintf("%d 學年度 %s", s.Year, termStr)
+}
+
+// FromUID parses the semester prefix of a course UID.
+// UIDs are {year}{term}{no}: "1131U0001" is 113-1; UIDs from years before 100
+// have a two-digit year ("991U0001" is 99-1).
+func FromUID(uid string) (Semester, error) {
+	yearLen := 3
+	if len(uid) < 9 {
+		yearLen = 2
+	}
+	if len(uid) < yearLen+2 {
+		return Semester{}, fmt.Errorf("%w: %q", ErrInvalidUID, uid)
+	}
+
+	year, err := strconv.Atoi(uid[:yearLen])
+	if err != nil {
+		return Semester{}, fmt.Errorf("%w: %q", ErrInvalidUID, uid)
+	}
+	term := int(uid[yearLen] - '0')
+	if term != 1 && term != 2 {
+		return Semester{}, fmt.Errorf("%w: %q", ErrInvalidUID, uid)
+	}
+	return Semester{Year: year, Term: term}, nil
+}
+
+// Current estimates the semester in progress at now from the Taiwan academic
+// calendar. Summer break (July - August) counts as the spring semester that
+// just ended, and January as the fall semester that just ended.
+func Current(now time.Time) Semester {
+	year := now.Year() - rocOffset
+	switch month := now.Month(); {
+	case month >= time.February && month <= time.August:
+		// Spring semester belongs to the academic year that started last calendar year
+		return Semester{Year: year - 1, Term: 2}
+	case month >= time.September:
+		return Semester{Year: year, Term: 1}
+	default:
+		// January: fall semester of the previous academic year
+		return Semester{Year: year - 1, Term: 1}
+	}
+}
+
+// ProbeStart returns the newest semester that might have data at now: the
+// current ROC year, term 2. Probing backwards from it finds the newest
+// semester with published courses even before the calendar reaches it.
+func ProbeStart(now time.Time) Semester {
+	return Semester{Year: now.Year() - rocOffset, Term: 2}
+}
+
+// SortNewestFirst sorts semesters in place, newest first.
+func SortNewestFirst(semesters []Semester) {
+	slices.SortFunc(semesters, func(a, b Semester) int {
+		return b.Compare(a)
+	})
+}
+
+// Split returns parallel years and terms slices for semesters.
+func Split(semesters []Semester) (years, terms []int) {
+	years = make([]int, len(semesters))
+	terms = make([]int, len(semesters))
+	for i, s := range semesters {
+		years[i] = s.Year
+		terms[i] = s.Term
+	}
+	return years, terms
+}
@@ -0,0 +1,132 @@
+package semester
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestPrevNext(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		sem, prev, next Semester
+	}{
+		{Semester{113, 1}, Semester{112, 2}, Semester{113, 2}},
+		{Semester{113, 2}, Semester{113, 1}, Semester{114, 1}},
+	}
+	for _, tt := range tests {
+		if got := tt.sem.Prev(); got != tt.prev {
+			t.Errorf("%v.Prev() = %v, want %v", tt.sem, got, tt.prev)
+		}
+		if got := tt.sem.Next(); got != tt.next {
+			t.Errorf("%v.Next() = %v, want %v", tt.sem, got, tt.next)
+		}
+		if got := tt.sem.Next().Prev(); got != tt.sem {
+			t.Errorf("%v.Next().Prev() = %v", tt.sem, got)
+		}
+	}
+}
+
+func TestRangeBack(t *testing.T) {
+	t.Parallel()
+	got := Semester{114, 1}.RangeBack(4)
+	want := []Semester{{114, 1}, {113, 2}, {113, 1}, {112, 2}}
+	if !slices.Equal(got, want) {
+		t.Errorf("RangeBack(4) = %v, want %v", got, want)
+	}
+	if got := (Semester{114, 1}).RangeBack(0); got != nil {
+		t.Errorf("RangeBack(0) = %v, want nil", got)
+	}
+}
+
+func TestCompareAndSort(t *testing.T) {
+	t.Parallel()
+	if c := (Semester{113, 2}).Compare(Semester{114, 1}); c != -1 {
+		t.Errorf("113-2 vs 114-1 = %d, want -1", c)
+	}
+	if c := (Semester{113, 2}).Compare(Semester{113, 1}); c != 1 {
+		t.Errorf("113-2 vs 113-1 = %d, want 1", c)
+	}
+	if c := (Semester{113, 2}).Compare(Semester{113, 2}); c != 0 {
+		t.Errorf("113-2 vs 113-2 = %d, want 0", c)
+	}
+
+	sems := []Semester{{112, 2}, {114, 1}, {113, 1}, {113, 2}}
+	SortNewestFirst(sems)
+	want := []Semester{{114, 1}, {113, 2}, {113, 1}, {112, 2}}
+	if !slices.Equal(sems, want) {
+		t.Errorf("SortNewestFirst() = %v, want %v", sems, want)
+	}
+
+	years, terms := Split(sems)
+	if !slices.Equal(years, []int{114, 113, 113, 112}) || !slices.Equal(terms, []int{1, 2, 1, 2}) {
+		t.Errorf("Split() = %v, %v", years, terms)
+	}
+}
+
+func TestFormatting(t *testing.T) {
+	t.Parallel()
+	if got := (Semester{113, 1}).String(); got != "113-1" {
+		t.Errorf("String() = %q, want 113-1", got)
+	}
+	if got := (Semester{113, 1}).Label(); got != "113 學年度 上學期" {
+		t.Errorf("Label() = %q", got)
+	}
+	if got := (Semester{113, 2}).Label(); got != "113 學年度 下學期" {
+		t.Errorf("Label() = %q", got)
+	}
+}
+
+func TestFromUID(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		uid     string
+		want    Semester
+		wantErr bool
+	}{
+		{"1131U0001", Semester{113, 1}, false},
+		{"1122M0003", Semester{112, 2}, false},
+		{"991U0001", Semester{99, 1}, false},
+		{"1133U0001", Semester{}, true},
+		{"abcdU0001", Semester{}, true},
+		{"11", Semester{}, true},
+		{"", Semester{}, true},
+	}
+	for _, tt := range tests {
+		got, err := FromUID(tt.uid)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("FromUID(%q) error = %v, wantErr %v", tt.uid, err, tt.wantErr)
+			continue
+		}
+		if err != nil && !errors.Is(err, ErrInvalidUID) {
+			t.Errorf("FromUID(%q) error = %v, want ErrInvalidUID", tt.uid, err)
+		}
+		if got != tt.want {
+			t.Errorf("FromUID(%q) = %v, want %v", tt.uid, got, tt.want)
+		}
+	}
+}
+
+func TestCurrent(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		month time.Month
+		want  Semester
+	}{
+		{time.January, Semester{113, 1}},
+		{time.February, Semester{113, 2}},
+		{time.July, Semester{113, 2}},
+		{time.September, Semester{114, 1}},
+		{time.December, Semester{114, 1}},
+	}
+	for _, tt := range tests {
+		now := time.Date(2025, tt.month, 15, 12, 0, 0, 0, time.UTC)
+		if got := Current(now); got != tt.want {
+			t.Errorf("Current(%s 2025) = %v, want %v", tt.month, got, tt.want)
+		}
+	}
+	if got := ProbeStart(time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)); got != (Semester{115, 2}) {
+		t.Errorf("ProbeStart(Jan 2026) = %v, want 115-2", got)
+	}
+}
@@ -0,0 +1,95 @@
+package session
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Answer records a keyword query the bot just answered in a group chat.
+type Answer struct {
+	Module     string // Module that replied
+	UserID     string // Asker, so 刪除我的資料 can drop the answers they asked for
+	Query      string // Sanitized query text as the asker typed it
+	QuoteToken string // Quote token of the asker's message, to point back at it
+	Time       time.Time
+}
+
+// Answers remembers the queries recently answered in each group chat, so an
+// identical question asked moments later gets a short pointer instead of a
+// second copy of the same reply.
+type Answers struct {
+	mu      sync.Mutex
+	entries map[string]Answer // chat ID + normalized query → answer
+	ttl     time.Duration
+}
+
+// NewAnswers creates an answer cache whose entries expire after ttl.
+func NewAnswers(ttl time.Duration) *Answers {
+	return &Answers{
+		entries: make(map[string]Answer),
+		ttl:     ttl,
+	}
+}
+
+// answerKey keys a query per chat, ignoring case and spacing.
+func answerKey(chatID, query string) string {
+	return chatID + "\x00" + strings.ToLower(strings.Join(strings.Fields(query), " "))
+}
+
+// Record remembers that query was answered in chatID.
+func (a *Answers) Record(chatID, query string, answer Answer) {
+	if chatID == "" || strings.TrimSpace(query) == "" {
+		return
+	}
+	answer.Query = query
+	answer.Time = time.Now()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.entries[answerKey(chatID, query)] = answer
+}
+
+// Recent returns the answer to query in chatID if it is younger than the TTL.
+func (a *Answers) Recent(chatID, query string) (Answer, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	answer, ok := a.entries[answerKey(chatID, query)]
+	if !ok || time.Since(answer.Time) >= a.ttl {
+		return Answer{}, false
+	}
+	return answer, true
+}
+
+// EraseUser drops the answers to questions userID asked, expired ones included.
+// Returns the number of answers dropped; ctx is unused (the cache is in memory).
+func (a *Answers) EraseUser(_ context.Context, userID string) (int64, error) {
+	if userID == "" {
+		return 0, nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var n int64
+	for key, answer := range a.entries {
+		if answer.UserID == userID {
+			delete(a.entries, key)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// Cleanup removes expired answers. Call periodically to prevent memory growth.
+func (a *Answers) Cleanup() {
+	cutoff := time.Now().Add(-a.ttl)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for key, answer := range a.entries {
+		if !answer.Time.After(cutoff) {
+			delete(a.entries, key)
+		}
+	}
+}
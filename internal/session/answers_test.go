@@ -0,0 +1,90 @@
+package session
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAnswersRecordAndRecent(t *testing.T) {
+	t.Parallel()
+	a := NewAnswers(time.Minute)
+
+	a.Record("C1", "課程 微積分", Answer{Module: "course", QuoteToken: "q1"})
+
+	tests := []struct {
+		name   string
+		chatID string
+		query  string
+		want   bool
+	}{
+		{"same query", "C1", "課程 微積分", true},
+		{"extra spaces", "C1", "課程  微積分 ", true},
+		{"other group", "C2", "課程 微積分", false},
+		{"other query", "C1", "課程 線性代數", false},
+	}
+	for _, tt := range tests {
+		got, ok := a.Recent(tt.chatID, tt.query)
+		if ok != tt.want {
+			t.Errorf("%s: Recent(%q, %q) ok = %v, want %v", tt.name, tt.chatID, tt.query, ok, tt.want)
+		}
+		if ok && (got.Module != "course" || got.QuoteToken != "q1" || got.Query != "課程 微積分") {
+			t.Errorf("%s: Recent() = %+v", tt.name, got)
+		}
+	}
+}
+
+func TestAnswersCaseInsensitive(t *testing.T) {
+	t.Parallel()
+	a := NewAnswers(time.Minute)
+
+	a.Record("C1", "course Calculus", Answer{Module: "course"})
+	if _, ok := a.Recent("C1", "COURSE calculus"); !ok {
+		t.Error("Recent() with different case = false, want true")
+	}
+}
+
+func TestAnswersIgnoresEmpty(t *testing.T) {
+	t.Parallel()
+	a := NewAnswers(time.Minute)
+
+	a.Record("", "課程 微積分", Answer{Module: "course"})
+	a.Record("C1", " ", Answer{Module: "course"})
+	if len(a.entries) != 0 {
+		t.Errorf("entries = %d, want 0", len(a.entries))
+	}
+}
+
+func TestAnswersExpiry(t *testing.T) {
+	t.Parallel()
+	a := NewAnswers(50 * time.Millisecond)
+
+	a.Record("C1", "課程 微積分", Answer{Module: "course"})
+	time.Sleep(60 * time.Millisecond)
+
+	if _, ok := a.Recent("C1", "課程 微積分"); ok {
+		t.Error("Recent() after TTL = true, want false")
+	}
+	a.Cleanup()
+	if len(a.entries) != 0 {
+		t.Errorf("entries after Cleanup = %d, want 0", len(a.entries))
+	}
+}
+
+func TestAnswers_EraseUser(t *testing.T) {
+	t.Parallel()
+	a := NewAnswers(time.Minute)
+	a.Record("C1", "課程 微積分", Answer{Module: "course", UserID: "U1"})
+	a.Record("C2", "課程 微積分", Answer{Module: "course", UserID: "U1"})
+	a.Record("C1", "課程 線性代數", Answer{Module: "course", UserID: "U2"})
+
+	if n, err := a.EraseUser(context.Background(), "U1"); err != nil || n != 2 {
+		t.Errorf("EraseUser() = (%d, %v), want 2 answers", n, err)
+	}
+	if _, ok := a.Recent("C1", "課程 微積分"); ok {
+		t.Error("Recent() still returns an erased answer")
+	}
+	if _, ok := a.Recent("C1", "課程 線性代數"); !ok {
+		t.Error("EraseUser() dropped another user's answer")
+	}
+}
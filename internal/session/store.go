@@ -1,8 +1,10 @@
 // Package session provides lightweight in-memory per-user conversation context.
-// It tracks recent intents to help NLU disambiguation without persistent storage.
+// It tracks recent intents to help NLU disambiguation without persistent storage,
+// and recent group answers so duplicate questions aren't answered twice.
 package session
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"sync"
@@ -124,6 +126,19 @@ func (s *Store) FormatContext(userID string) string {
 	return "[前文：" + strings.Join(parts, " → ") + "]"
 }
 
+// EraseUser drops the user's session, expired intents included.
+// Returns the number of intents dropped; ctx is unused (the store is in memory).
+func (s *Store) EraseUser(_ context.Context, userID string) (int64, error) {
+	val, ok := s.sessions.LoadAndDelete(userID)
+	if !ok {
+		return 0, nil
+	}
+	sess, _ := val.(*userSession)
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	return int64(len(sess.intents)), nil
+}
+
 // Cleanup removes expired sessions. Call periodically to prevent memory growth.
 func (s *Store) Cleanup() {
 	cutoff := time.Now().Add(-s.ttl)
@@ -1,6 +1,7 @@
 package session
 
 import (
+	"context"
 	"testing"
 	"time"
 )
@@ -133,6 +134,28 @@ func TestCleanup(t *testing.T) {
 	}
 }
 
+func TestEraseUser(t *testing.T) {
+	t.Parallel()
+	s := NewStore(3, 5*time.Minute)
+
+	s.Record("user1", Intent{Module: "course"})
+	s.Record("user1", Intent{Module: "contact"})
+	s.Record("user2", Intent{Module: "id"})
+
+	if n, err := s.EraseUser(context.Background(), "user1"); err != nil || n != 2 {
+		t.Errorf("EraseUser() = (%d, %v), want 2 intents", n, err)
+	}
+	if s.GetRecentIntents("user1") != nil {
+		t.Error("user1 should have been erased")
+	}
+	if len(s.GetRecentIntents("user2")) != 1 {
+		t.Error("user2 should be untouched")
+	}
+	if n, _ := s.EraseUser(context.Background(), "user1"); n != 0 {
+		t.Errorf("EraseUser() twice = %d, want 0", n)
+	}
+}
+
 func TestConcurrentAccess(t *testing.T) {
 	t.Parallel()
 	s := NewStore(5, 5*time.Minute)
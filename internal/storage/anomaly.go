@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
+)
+
+// Anomaly check names, used as keys of AnomalyReport and metric labels.
+const (
+	AnomalyCourseNoTeachers = "course_no_teachers" // Courses whose teachers array is empty
+	AnomalyStudentBadYear   = "student_bad_year"   // Students whose year is out of range or contradicts the ID
+	AnomalySyllabusOrphan   = "syllabus_orphan"    // Syllabi without a course in either course table
+	AnomalyHistoricalInHot  = "historical_in_hot"  // historical_courses rows for a semester cached in courses
+	AnomalyOrphanLink       = "orphan_link"        // Course link rows (teachers, sections, programs, ...) without a course
+)
+
+// AnomalyChecks lists the check names in the order they run.
+var AnomalyChecks = []string{
+	AnomalyCourseNoTeachers,
+	AnomalyStudentBadYear,
+	AnomalyHistoricalInHot,
+	AnomalySyllabusOrphan,
+	AnomalyOrphanLink,
+}
+
+// AnomalyReport counts the rows each check found (or removed, when repairing).
+type AnomalyReport map[string]int64
+
+// Total returns the number of rows across all checks.
+func (r AnomalyReport) Total() int64 {
+	var total int64
+	for _, n := range r {
+		total += n
+	}
+	return total
+}
+
+// anomalyCheck selects the anomalous rows of one table. FindAnomalies
+// limits where to the DB's tenant; subqueries match the row's tenant.
+type anomalyCheck struct {
+	name  string
+	table string
+	where string
+	// repair deletes the rows; the next refresh or lookup scrapes them again.
+	// Report-only checks flag rows a scrape cannot fix.
+	repair bool
+}
+
+// courseExists matches link rows of table whose course_uid is cached in
+// either course table.
+func courseExists(table string) string {
+	return `(course_uid IN (SELECT uid FROM courses WHERE tenant = ` + table + `.tenant) OR ` +
+		`course_uid IN (SELECT uid FROM historical_courses WHERE tenant = ` + table + `.tenant))`
+}
+
+// anomalyChecks runs in order: checks that delete courses come before the
+// orphan checks, so a repair does not leave new orphans behind.
+var anomalyChecks = []anomalyCheck{
+	// NTPU lists some courses without a teacher, so these are only reported
+	{AnomalyCourseNoTeachers, "courses", `teachers IS NULL OR teachers IN ('', '[]')`, false},
+	{AnomalyCourseNoTeachers, "historical_courses", `teachers IS NULL OR teachers IN ('', '[]')`, false},
+	// Student IDs carry the admission year: 2 digits in 8-digit IDs, 3 in 9-digit ones (see ntpu.ExtractYear)
+	{AnomalyStudentBadYear, "students", fmt.Sprintf(
+		`year IS NULL OR year < %d OR year > %d OR length(id) NOT IN (8, 9) OR year != CAST(substr(id, 2, length(id) - 6) AS INTEGER)`,
+		config.NTPUFoundedYear, config.IDDataCutoffYear), true},
+	{AnomalyHistoricalInHot, "historical_courses",
+		`EXISTS (SELECT 1 FROM courses c WHERE c.tenant = historical_courses.tenant AND c.year = historical_courses.year AND c.term = historical_courses.term)`, true},
+	{AnomalySyllabusOrphan, "syllabi",
+		`uid NOT IN (SELECT uid FROM courses WHERE tenant = syllabi.tenant) AND uid NOT IN (SELECT uid FROM historical_courses WHERE tenant = syllabi.tenant)`, true},
+	{AnomalyOrphanLink, "course_teachers", "NOT " + courseExists("course_teachers"), true},
+	{AnomalyOrphanLink, "course_sections", "NOT " + courseExists("course_sections"), true},
+	{AnomalyOrphanLink, "course_programs", "NOT " + courseExists("course_programs"), true},
+	{AnomalyOrphanLink, "course_majors", "NOT " + courseExists("course_majors"), true},
+	{AnomalyOrphanLink, "course_prerequisites", "NOT " + courseExists("course_prerequisites"), true},
+}
+
+// FindAnomalies counts cache rows that are well-formed SQLite but wrong as
+// data (CheckIntegrity covers file corruption). With repair set, it deletes
+// the repairable ones in a single transaction and reports the rows removed
+// for those checks instead.
+func (db *DB) FindAnomalies(ctx context.Context, repair bool) (AnomalyReport, error) {
+	report := make(AnomalyReport, len(AnomalyChecks))
+	for _, name := range AnomalyChecks {
+		report[name] = 0
+	}
+
+	tenant := db.Tenant()
+	run := func(ctx context.Context) error {
+		for _, c := range anomalyChecks {
+			where := " WHERE tenant = ? AND (" + c.where + ")"
+			if repair && c.repair {
+				result, err := db.writeConn(ctx).ExecContext(ctx, "DELETE FROM "+c.table+where, tenant)
+				if err != nil {
+					return fmt.Errorf("repair %s in %s: %w", c.name, c.table, err)
+				}
+				n, err := result.RowsAffected()
+				if err != nil {
+					return fmt.Errorf("repair %s in %s: %w", c.name, c.table, err)
+				}
+				report[c.name] += n
+				continue
+			}
+
+			var n int64
+			if err := db.Reader().QueryRowContext(ctx, "SELECT COUNT(*) FROM "+c.table+where, tenant).Scan(&n); err != nil {
+				return fmt.Errorf("check %s in %s: %w", c.name, c.table, err)
+			}
+			report[c.name] += n
+		}
+		return nil
+	}
+
+	if repair {
+		if err := db.WithTx(ctx, run); err != nil {
+			return nil, err
+		}
+		return report, nil
+	}
+	if err := run(ctx); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFindAnomalies(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	ctx := context.Background()
+	now := time.Now().Unix()
+
+	seed := []struct {
+		query string
+		args  []any
+	}{
+		// Healthy rows
+		{`INSERT INTO students (id, name, year, department, cached_at) VALUES (?, ?, ?, ?, ?)`, []any{"410571074", "王小明", 105, "資工系", now}},
+		{`INSERT INTO courses (uid, year, term, title, teachers, cached_at) VALUES (?, ?, ?, ?, ?, ?)`, []any{"1131U0001", 113, 1, "資料結構", `["王教授"]`, now}},
+		{`INSERT INTO course_sections (course_uid, year, term, section_key, cached_at) VALUES (?, ?, ?, ?, ?)`, []any{"1131U0001", 113, 1, "k", now}},
+		// Anomalies
+		{`INSERT INTO students (id, name, year, department, cached_at) VALUES (?, ?, ?, ?, ?)`, []any{"410571075", "年份不符", 106, "資工系", now}},
+		{`INSERT INTO students (id, name, year, department, cached_at) VALUES (?, ?, ?, ?, ?)`, []any{"4105710", "學號過短", 105, "資工系", now}},
+		{`INSERT INTO courses (uid, year, term, title, teachers, cached_at) VALUES (?, ?, ?, ?, ?, ?)`, []any{"1131U0002", 113, 1, "未定教師", `[]`, now}},
+		{`INSERT INTO historical_courses (uid, year, term, title, teachers, cached_at) VALUES (?, ?, ?, ?, ?, ?)`, []any{"1131U0003", 113, 1, "冷熱重疊", `["李教授"]`, now}},
+		{`INSERT INTO syllabi (uid, year, term, title, content_hash, cached_at) VALUES (?, ?, ?, ?, ?, ?)`, []any{"1121U0009", 112, 1, "孤兒課綱", "hash", now}},
+		{`INSERT INTO course_sections (course_uid, year, term, section_key, cached_at) VALUES (?, ?, ?, ?, ?)`, []any{"1121U0009", 112, 1, "k", now}},
+		{`INSERT INTO course_prerequisites (course_uid, statement, titles, cached_at) VALUES (?, ?, ?, ?)`, []any{"1121U0009", "微積分", `[]`, now}},
+	}
+	for _, s := range seed {
+		if _, err := db.Writer().ExecContext(ctx, s.query, s.args...); err != nil {
+			t.Fatalf("seed %v: %v", s.args, err)
+		}
+	}
+
+	want := AnomalyReport{
+		AnomalyCourseNoTeachers: 1,
+		AnomalyStudentBadYear:   2,
+		AnomalyHistoricalInHot:  1,
+		AnomalySyllabusOrphan:   1,
+		AnomalyOrphanLink:       2,
+	}
+
+	report, err := db.FindAnomalies(ctx, false)
+	if err != nil {
+		t.Fatalf("FindAnomalies(check) error = %v", err)
+	}
+	for _, check := range AnomalyChecks {
+		if report[check] != want[check] {
+			t.Errorf("FindAnomalies(check)[%s] = %d, want %d", check, report[check], want[check])
+		}
+	}
+
+	// Repair removes everything but the report-only check
+	if _, err := db.FindAnomalies(ctx, true); err != nil {
+		t.Fatalf("FindAnomalies(repair) error = %v", err)
+	}
+	report, err = db.FindAnomalies(ctx, false)
+	if err != nil {
+		t.Fatalf("FindAnomalies(after repair) error = %v", err)
+	}
+	if report.Total() != want[AnomalyCourseNoTeachers] {
+		t.Errorf("FindAnomalies(after repair) = %v, want only %s left", report, AnomalyCourseNoTeachers)
+	}
+	if got, err := db.CountStudents(ctx); err != nil || got != 1 {
+		t.Errorf("CountStudents() = %d, %v; want the healthy student kept", got, err)
+	}
+}
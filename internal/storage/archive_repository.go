@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrCatalogArchived is returned by ArchiveCourseCatalog for a semester whose
+// catalog is already archived. Archives are immutable.
+var ErrCatalogArchived = errors.New("course catalog already archived")
+
+// ErrCatalogNotReady is returned by ArchiveCourseCatalog when no course of the
+// semester was cached at or after the requested time.
+var ErrCatalogNotReady = errors.New("no course catalog cached since the cutoff")
+
+// courseArchiveTable describes course_archive, which shares the courses
+// layout; its cached_at is the time the row was cached before archiving.
+var courseArchiveTable = newCourseTable("course_archive", "archived course")
+
+// ArchiveCourseCatalog copies the cached courses of a semester into
+// course_archive, the frozen catalog historical queries prefer over the live
+// site. Only rows cached at or after since are copied, so a catalog refreshed
+// before 加退選 ended is never archived as final. It returns the number of
+// courses archived, ErrCatalogArchived if the semester already has an
+// archive, or ErrCatalogNotReady if no course qualifies.
+func (db *DB) ArchiveCourseCatalog(ctx context.Context, year, term int, since time.Time) (int, error) {
+	var archived int64
+	tenant := db.Tenant()
+	err := db.WithTx(ctx, func(ctx context.Context) error {
+		result, err := db.writeConn(ctx).ExecContext(ctx,
+			`INSERT OR IGNORE INTO course_archive_semesters (tenant, year, term, course_count, archived_at) VALUES (?, ?, ?, 0, ?)`,
+			tenant, year, term, time.Now().Unix())
+		if err != nil {
+			return err
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return ErrCatalogArchived
+		}
+
+		columns := "tenant, uid, year, term, no, title, teachers, teacher_urls, times, locations, detail_url, note, title_en, cached_at"
+		result, err = db.writeConn(ctx).ExecContext(ctx,
+			`INSERT INTO course_archive (`+columns+`) SELECT `+columns+` FROM courses WHERE tenant = ? AND year = ? AND term = ? AND cached_at >= ?`,
+			tenant, year, term, since.Unix())
+		if err != nil {
+			return err
+		}
+		if archived, err = result.RowsAffected(); err != nil {
+			return err
+		}
+		if archived == 0 {
+			return ErrCatalogNotReady
+		}
+
+		_, err = db.writeConn(ctx).ExecContext(ctx,
+			`UPDATE course_archive_semesters SET course_count = ? WHERE tenant = ? AND year = ? AND term = ?`, archived, tenant, year, term)
+		return err
+	})
+	if errors.Is(err, ErrCatalogArchived) || errors.Is(err, ErrCatalogNotReady) {
+		return 0, err
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to archive course catalog %d-%d: %w", year, term, err)
+	}
+	return int(archived), nil
+}
+
+// IsCourseCatalogArchived reports whether the semester's catalog is archived.
+func (db *DB) IsCourseCatalogArchived(ctx context.Context, year, term int) (bool, error) {
+	var n int
+	err := db.Reader().QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM course_archive_semesters WHERE tenant = ? AND year = ? AND term = ?`, db.Tenant(), year, term).Scan(&n)
+	if err != nil {
+		return false, fmt.Errorf("failed to check course archive: %w", err)
+	}
+	return n > 0, nil
+}
+
+// GetArchivedCoursesByYear returns the archived courses of both semesters of
+// year, ordered by term and course number. Archives never expire.
+func (db *DB) GetArchivedCoursesByYear(ctx context.Context, year int) ([]Course, error) {
+	courses, err := queryEntities(ctx, db, courseArchiveTable, `WHERE year = ? ORDER BY term, no`, year)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get archived courses: %w", err)
+	}
+	return courses, nil
+}
@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestArchiveCourseCatalog(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := setupTestDB(t)
+
+	cutoff := time.Now().Add(-24 * time.Hour)
+	if err := db.SaveCoursesBatch(ctx, []*Course{
+		{UID: "1131U0001", Year: 113, Term: 1, No: "U0001", Title: "微積分", Teachers: []string{"王老師"}},
+		{UID: "1131U0002", Year: 113, Term: 1, No: "U0002", Title: "統計學",
+			CachedAt: cutoff.Add(-time.Hour).Unix()}, // Cached before the cutoff: not final
+		{UID: "1132U0001", Year: 113, Term: 2, No: "U0001", Title: "線性代數"},
+	}); err != nil {
+		t.Fatalf("SaveCoursesBatch() error = %v", err)
+	}
+
+	if _, err := db.ArchiveCourseCatalog(ctx, 113, 1, time.Now().Add(time.Hour)); !errors.Is(err, ErrCatalogNotReady) {
+		t.Fatalf("ArchiveCourseCatalog() before a refresh = %v, want ErrCatalogNotReady", err)
+	}
+	if ok, _ := db.IsCourseCatalogArchived(ctx, 113, 1); ok {
+		t.Fatal("a catalog that was not ready must not be marked archived")
+	}
+
+	n, err := db.ArchiveCourseCatalog(ctx, 113, 1, cutoff)
+	if err != nil || n != 1 {
+		t.Fatalf("ArchiveCourseCatalog() = (%d, %v), want (1, nil)", n, err)
+	}
+	if _, err := db.ArchiveCourseCatalog(ctx, 113, 1, cutoff); !errors.Is(err, ErrCatalogArchived) {
+		t.Errorf("second ArchiveCourseCatalog() = %v, want ErrCatalogArchived", err)
+	}
+	if ok, err := db.IsCourseCatalogArchived(ctx, 113, 1); err != nil || !ok {
+		t.Errorf("IsCourseCatalogArchived() = (%v, %v), want true", ok, err)
+	}
+
+	// The archive is unaffected by later changes to the live cache
+	if err := db.SaveCourse(ctx, &Course{UID: "1131U0001", Year: 113, Term: 1, No: "U0001", Title: "微積分（停開）"}); err != nil {
+		t.Fatalf("SaveCourse() error = %v", err)
+	}
+	archived, err := db.GetArchivedCoursesByYear(ctx, 113)
+	if err != nil {
+		t.Fatalf("GetArchivedCoursesByYear() error = %v", err)
+	}
+	if len(archived) != 1 || archived[0].Title != "微積分" || archived[0].Teachers[0] != "王老師" {
+		t.Errorf("GetArchivedCoursesByYear() = %+v, want the frozen 微積分", archived)
+	}
+}
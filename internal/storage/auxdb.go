@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
+)
+
+// OpenAux opens the SQLite file of a feature store kept apart from the cache
+// (history, accounts, analytics, ...), so clearing the cache never loses it.
+// The store gets a single writer connection with WAL and the shared busy
+// timeout, and initSchema runs before it is returned. name labels errors,
+// e.g. "history".
+func OpenAux(ctx context.Context, path, name string, initSchema func(context.Context, *sql.DB) error) (*sql.DB, error) {
+	if dir := filepath.Dir(path); dir != "" && dir != "." {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return nil, fmt.Errorf("create %s directory: %w", name, err)
+		}
+	}
+
+	db, err := sql.Open("sqlite", path+"?_txlock=immediate")
+	if err != nil {
+		return nil, fmt.Errorf("open %s db: %w", name, err)
+	}
+	// Feature stores write a few rows per event; one writer avoids SQLITE_BUSY
+	db.SetMaxOpenConns(1)
+
+	busyTimeoutMs := int(config.DatabaseBusyTimeout.Milliseconds())
+	for _, pragma := range []string{
+		"PRAGMA journal_mode=WAL",
+		fmt.Sprintf("PRAGMA busy_timeout=%d", busyTimeoutMs),
+	} {
+		if _, err := db.ExecContext(ctx, pragma); err != nil {
+			_ = db.Close() // Best effort cleanup
+			return nil, fmt.Errorf("configure %s db: %w", name, err)
+		}
+	}
+
+	if err := initSchema(ctx, db); err != nil {
+		_ = db.Close() // Best effort cleanup
+		return nil, err
+	}
+	return db, nil
+}
+
+// AuxStore holds an OpenAux database for the feature store that embeds it.
+// Shutdown may close the store while handlers are still using it, so, like
+// DB, the closed state is guarded by a mutex and later calls get
+// ErrDatabaseClosed.
+type AuxStore struct {
+	mu     sync.RWMutex
+	db     *sql.DB
+	closed bool
+}
+
+// NewAuxStore wraps a database opened with OpenAux.
+func NewAuxStore(db *sql.DB) *AuxStore {
+	return &AuxStore{db: db}
+}
+
+// Conn returns the database, or ErrDatabaseClosed once Close has run.
+func (a *AuxStore) Conn() (*sql.DB, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		return nil, ErrDatabaseClosed
+	}
+	return a.db, nil
+}
+
+// Close closes the database. Close is idempotent: later calls return nil.
+func (a *AuxStore) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closed {
+		return nil
+	}
+	a.closed = true
+	return a.db.Close()
+}
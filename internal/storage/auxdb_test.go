@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpenAux(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "nested", "feature.db")
+
+	db, err := OpenAux(ctx, path, "feature", func(ctx context.Context, db *sql.DB) error {
+		_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS items (id INTEGER PRIMARY KEY) STRICT`)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("OpenAux() error = %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	var mode string
+	if err := db.QueryRowContext(ctx, "PRAGMA journal_mode").Scan(&mode); err != nil || mode != "wal" {
+		t.Errorf("journal_mode = %q (%v), want wal", mode, err)
+	}
+	if _, err := db.ExecContext(ctx, "INSERT INTO items (id) VALUES (1)"); err != nil {
+		t.Errorf("schema not applied: %v", err)
+	}
+
+	schemaErr := errors.New("bad schema")
+	if _, err := OpenAux(ctx, path, "feature", func(context.Context, *sql.DB) error { return schemaErr }); !errors.Is(err, schemaErr) {
+		t.Errorf("OpenAux() error = %v, want the schema error", err)
+	}
+}
+
+func TestAuxStore_Close(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db, err := OpenAux(ctx, filepath.Join(t.TempDir(), "feature.db"), "feature", func(context.Context, *sql.DB) error { return nil })
+	if err != nil {
+		t.Fatalf("OpenAux() error = %v", err)
+	}
+	store := NewAuxStore(db)
+
+	if conn, err := store.Conn(); err != nil || conn != db {
+		t.Fatalf("Conn() = (%v, %v), want the database", conn, err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Errorf("second Close() error = %v, want nil", err)
+	}
+	if _, err := store.Conn(); !errors.Is(err, ErrDatabaseClosed) {
+		t.Errorf("Conn() after Close error = %v, want ErrDatabaseClosed", err)
+	}
+}
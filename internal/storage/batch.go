@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// BatchPolicy decides what ExecBatchContext keeps when a batch stops early
+// because a row failed or the context was canceled.
+type BatchPolicy int
+
+const (
+	// BatchAllOrNothing rolls the whole batch back (default).
+	BatchAllOrNothing BatchPolicy = iota
+	// BatchBestEffort commits the rows written before the batch stopped. Use it
+	// for long cache refreshes where a partial write beats redoing the scrape.
+	BatchBestEffort
+)
+
+// ErrBatchPartial is returned with the stopping error when BatchBestEffort
+// committed only part of a batch.
+var ErrBatchPartial = errors.New("batch partially written")
+
+type batchPolicyKey struct{}
+
+// WithBatchPolicy returns a context whose batch writes use policy.
+func WithBatchPolicy(ctx context.Context, policy BatchPolicy) context.Context {
+	return context.WithValue(ctx, batchPolicyKey{}, policy)
+}
+
+// batchPolicyFrom returns the policy set by WithBatchPolicy, or BatchAllOrNothing.
+func batchPolicyFrom(ctx context.Context) BatchPolicy {
+	if policy, ok := ctx.Value(batchPolicyKey{}).(BatchPolicy); ok {
+		return policy
+	}
+	return BatchAllOrNothing
+}
+
+// Batch writes rows with one prepared statement inside ExecBatchContext.
+type Batch struct {
+	db    *DB
+	ctx   context.Context // Caller's context, checked before every row
+	txCtx context.Context // Context the transaction runs under
+	stmt  *sql.Stmt
+	rows  int
+}
+
+// Exec writes one row. Once the caller's context is done it returns the
+// context's error without writing, so loops stop at the next row.
+func (b *Batch) Exec(args ...any) error {
+	if err := b.ctx.Err(); err != nil {
+		return err
+	}
+	if _, err := b.stmt.ExecContext(b.txCtx, args...); err != nil {
+		b.db.reportError(b.ctx, OpWrite, err)
+		return err
+	}
+	b.rows++
+	return nil
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestExecBatchContext_CancelMidBatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		policy      BatchPolicy
+		wantRows    int
+		wantPartial bool
+	}{
+		{name: "all or nothing", policy: BatchAllOrNothing, wantRows: 0},
+		{name: "best effort", policy: BatchBestEffort, wantRows: 2, wantPartial: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			db := setupTestDB(t)
+
+			ctx, cancel := context.WithCancel(WithBatchPolicy(context.Background(), tt.policy))
+			defer cancel()
+
+			written := 0
+			query := `INSERT INTO stickers (url, source, cached_at) VALUES (?, 'fallback', 1)`
+			err := db.ExecBatchContext(ctx, query, func(b *Batch) error {
+				for i := range 5 {
+					if i == 2 {
+						cancel() // e.g., the webhook deadline passes mid-batch
+					}
+					if err := b.Exec(fmt.Sprintf("https://example.com/%d.png", i)); err != nil {
+						return err
+					}
+					written++
+				}
+				return nil
+			})
+
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("ExecBatchContext() error = %v, want context.Canceled", err)
+			}
+			if got := errors.Is(err, ErrBatchPartial); got != tt.wantPartial {
+				t.Errorf("errors.Is(err, ErrBatchPartial) = %v, want %v", got, tt.wantPartial)
+			}
+			if written != 2 {
+				t.Errorf("rows attempted after cancel: wrote %d, want 2", written)
+			}
+			if got, err := db.CountStickers(context.Background()); err != nil || got != tt.wantRows {
+				t.Errorf("CountStickers() = %d, %v; want %d", got, err, tt.wantRows)
+			}
+		})
+	}
+}
+
+func TestExecBatchContext_RowErrorBestEffort(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	ctx := WithBatchPolicy(context.Background(), BatchBestEffort)
+
+	query := `INSERT INTO stickers (url, source, cached_at) VALUES (?, ?, 1)`
+	err := db.ExecBatchContext(ctx, query, func(b *Batch) error {
+		for _, source := range []string{"fallback", "invalid", "fallback"} {
+			if err := b.Exec("https://example.com/"+source+".png", source); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	if !errors.Is(err, ErrBatchPartial) {
+		t.Fatalf("ExecBatchContext() error = %v, want ErrBatchPartial", err)
+	}
+	if got, _ := db.CountStickers(context.Background()); got != 1 {
+		t.Errorf("CountStickers() = %d, want 1 (rows before the failure)", got)
+	}
+}
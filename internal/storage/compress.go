@@ -0,0 +1,143 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// compressedTextPrefix marks a zstd-compressed, base64-encoded text column.
+// STRICT TEXT columns cannot hold raw bytes, and values without the prefix are
+// plain text, so rows written with compression off always stay readable.
+const compressedTextPrefix = "zstd:"
+
+// EncodeAll and DecodeAll are safe for concurrent use; options are static, so
+// construction never fails.
+var (
+	textEncoder, _ = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedBetterCompression))
+	textDecoder, _ = zstd.NewReader(nil)
+)
+
+// compressText compresses s, or returns s unchanged when compression would not
+// make it shorter (short or empty texts).
+func compressText(s string) string {
+	if s == "" {
+		return s
+	}
+	encoded := compressedTextPrefix + base64.StdEncoding.EncodeToString(textEncoder.EncodeAll([]byte(s), nil))
+	if len(encoded) >= len(s) {
+		return s
+	}
+	return encoded
+}
+
+// decompressText reverses compressText. Plain text is returned as is.
+func decompressText(s string) (string, error) {
+	encoded, ok := strings.CutPrefix(s, compressedTextPrefix)
+	if !ok {
+		return s, nil
+	}
+	compressed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("decode compressed text: %w", err)
+	}
+	plain, err := textDecoder.DecodeAll(compressed, nil)
+	if err != nil {
+		return "", fmt.Errorf("decompress text: %w", err)
+	}
+	return string(plain), nil
+}
+
+// SetSyllabusCompression enables zstd compression of syllabus text columns
+// (objectives, outline, schedule) for future writes. Reads handle both forms,
+// so it can be toggled at any time; SyncSyllabusCompression converts old rows.
+func (db *DB) SetSyllabusCompression(enabled bool) {
+	db.compressSyllabi.Store(enabled)
+}
+
+// syllabusColumn returns the stored form of a syllabus text column.
+func (db *DB) syllabusColumn(s string) string {
+	if db.compressSyllabi.Load() {
+		return compressText(s)
+	}
+	return s
+}
+
+// syllabusFields decodes the stored syllabus text columns into syllabus.
+func syllabusFields(syllabus *Syllabus, objectives, outline, schedule sql.NullString) error {
+	var err error
+	if syllabus.Objectives, err = decompressText(objectives.String); err != nil {
+		return fmt.Errorf("syllabus %s objectives: %w", syllabus.UID, err)
+	}
+	if syllabus.Outline, err = decompressText(outline.String); err != nil {
+		return fmt.Errorf("syllabus %s outline: %w", syllabus.UID, err)
+	}
+	if syllabus.Schedule, err = decompressText(schedule.String); err != nil {
+		return fmt.Errorf("syllabus %s schedule: %w", syllabus.UID, err)
+	}
+	return nil
+}
+
+// SyncSyllabusCompression rewrites syllabus rows stored in the other form
+// than the current SetSyllabusCompression setting (e.g., rows cached before
+// compression was enabled). cached_at is left unchanged. Returns the number
+// of rewritten rows.
+func (db *DB) SyncSyllabusCompression(ctx context.Context) (int, error) {
+	tenant := db.Tenant()
+	rows, err := db.Reader().QueryContext(ctx, `SELECT uid, objectives, outline, schedule FROM syllabi WHERE tenant = ?`, tenant)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query syllabi for compression: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	type update struct {
+		uid                           string
+		objectives, outline, schedule string
+	}
+	var updates []update
+	for rows.Next() {
+		var uid string
+		var objectives, outline, schedule sql.NullString
+		if err := rows.Scan(&uid, &objectives, &outline, &schedule); err != nil {
+			return 0, fmt.Errorf("failed to scan syllabus for compression: %w", err)
+		}
+		syllabus := &Syllabus{UID: uid}
+		if err := syllabusFields(syllabus, objectives, outline, schedule); err != nil {
+			return 0, err
+		}
+		u := update{
+			uid:        uid,
+			objectives: db.syllabusColumn(syllabus.Objectives),
+			outline:    db.syllabusColumn(syllabus.Outline),
+			schedule:   db.syllabusColumn(syllabus.Schedule),
+		}
+		if u.objectives != objectives.String || u.outline != outline.String || u.schedule != schedule.String {
+			updates = append(updates, u)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to iterate syllabi for compression: %w", err)
+	}
+	_ = rows.Close() // Release the reader before writing
+
+	if len(updates) == 0 {
+		return 0, nil
+	}
+	query := `UPDATE syllabi SET objectives = ?, outline = ?, schedule = ? WHERE tenant = ? AND uid = ?`
+	err = db.ExecBatchContext(ctx, query, func(b *Batch) error {
+		for _, u := range updates {
+			if err := b.Exec(u.objectives, u.outline, u.schedule, tenant, u.uid); err != nil {
+				return fmt.Errorf("failed to rewrite syllabus %s: %w", u.uid, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(updates), nil
+}
@@ -0,0 +1,136 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCompressText(t *testing.T) {
+	t.Parallel()
+
+	long := strings.Repeat("培養學生程式設計能力 Develop programming skills\n", 20)
+	tests := []struct {
+		name           string
+		input          string
+		wantCompressed bool
+	}{
+		{"empty", "", false},
+		{"short", "第1週：課程介紹", false},
+		{"long", long, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			stored := compressText(tt.input)
+			if got := strings.HasPrefix(stored, compressedTextPrefix); got != tt.wantCompressed {
+				t.Errorf("compressed = %v, want %v", got, tt.wantCompressed)
+			}
+			plain, err := decompressText(stored)
+			if err != nil {
+				t.Fatalf("decompressText() error = %v", err)
+			}
+			if plain != tt.input {
+				t.Errorf("round trip = %q, want %q", plain, tt.input)
+			}
+		})
+	}
+}
+
+func TestDecompressText_Corrupt(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []string{compressedTextPrefix + "not base64!", compressedTextPrefix + "AAAA"} {
+		if _, err := decompressText(s); err == nil {
+			t.Errorf("decompressText(%q) error = nil, want error", s)
+		}
+	}
+}
+
+func TestSyllabusCompression(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	long := strings.Repeat("變數、迴圈、函式 Variables, loops, functions\n", 30)
+	plain := &Syllabus{UID: "1131U0001", Year: 113, Term: 1, Title: "程式設計", Outline: long, ContentHash: "a"}
+	if err := db.SaveSyllabus(ctx, plain); err != nil {
+		t.Fatalf("SaveSyllabus failed: %v", err)
+	}
+
+	db.SetSyllabusCompression(true)
+	compressed := &Syllabus{UID: "1131U0002", Year: 113, Term: 1, Title: "資料結構", Objectives: long, Schedule: "第1週", ContentHash: "b"}
+	if err := db.SaveSyllabusBatch(ctx, []*Syllabus{compressed}); err != nil {
+		t.Fatalf("SaveSyllabusBatch failed: %v", err)
+	}
+
+	var stored string
+	if err := db.Reader().QueryRowContext(ctx, `SELECT objectives FROM syllabi WHERE uid = ?`, compressed.UID).Scan(&stored); err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	if !strings.HasPrefix(stored, compressedTextPrefix) || len(stored) >= len(long) {
+		t.Errorf("stored objectives not compressed (len %d)", len(stored))
+	}
+
+	// Reads are transparent for both forms
+	for _, want := range []*Syllabus{plain, compressed} {
+		got, err := db.GetSyllabusByUID(ctx, want.UID)
+		if err != nil {
+			t.Fatalf("GetSyllabusByUID(%s) failed: %v", want.UID, err)
+		}
+		if got.Objectives != want.Objectives || got.Outline != want.Outline || got.Schedule != want.Schedule {
+			t.Errorf("GetSyllabusByUID(%s) content mismatch", want.UID)
+		}
+	}
+
+	// The plain row is rewritten once, then nothing is left to convert
+	rewritten, err := db.SyncSyllabusCompression(ctx)
+	if err != nil || rewritten != 1 {
+		t.Fatalf("SyncSyllabusCompression() = %d, %v; want 1, nil", rewritten, err)
+	}
+	if rewritten, err := db.SyncSyllabusCompression(ctx); err != nil || rewritten != 0 {
+		t.Errorf("second SyncSyllabusCompression() = %d, %v; want 0, nil", rewritten, err)
+	}
+
+	all, err := db.GetAllSyllabi(ctx)
+	if err != nil || len(all) != 2 {
+		t.Fatalf("GetAllSyllabi() = %d syllabi, %v", len(all), err)
+	}
+	for _, s := range all {
+		if strings.HasPrefix(s.Objectives+s.Outline, compressedTextPrefix) {
+			t.Errorf("GetAllSyllabi returned compressed text for %s", s.UID)
+		}
+	}
+
+	// Turning compression off converts back
+	db.SetSyllabusCompression(false)
+	if rewritten, err := db.SyncSyllabusCompression(ctx); err != nil || rewritten != 2 {
+		t.Errorf("SyncSyllabusCompression() after disabling = %d, %v; want 2, nil", rewritten, err)
+	}
+}
+
+func TestVacuum(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	syllabi := make([]*Syllabus, 200)
+	for i := range syllabi {
+		syllabi[i] = &Syllabus{UID: fmt.Sprintf("1131U%04d", i), Year: 113, Term: 1, Title: "課程", Outline: strings.Repeat("大綱", 500), ContentHash: "h"}
+	}
+	if err := db.SaveSyllabusBatch(ctx, syllabi); err != nil {
+		t.Fatalf("SaveSyllabusBatch failed: %v", err)
+	}
+	if _, err := db.ExecContext(ctx, `DELETE FROM syllabi`); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+
+	stats, err := db.Vacuum(ctx)
+	if err != nil {
+		t.Fatalf("Vacuum() error = %v", err)
+	}
+	if stats.BytesBefore <= 0 || stats.BytesAfter >= stats.BytesBefore {
+		t.Errorf("Vacuum() = %+v, want a smaller file after", stats)
+	}
+}
@@ -11,9 +11,11 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/garyellow/ntpu-linebot-go/internal/config"
+	"github.com/garyellow/ntpu-linebot-go/internal/faults"
 	_ "modernc.org/sqlite" // SQLite driver for database/sql
 )
 
@@ -30,6 +32,21 @@ type DB struct {
 	path     string
 	cacheTTL time.Duration
 	closed   bool
+
+	tenant          atomic.Pointer[string] // See SetTenant
+	compressSyllabi atomic.Bool            // See SetSyllabusCompression
+	replicated      atomic.Bool            // See SetReplicated
+	analyzeQueries  atomic.Bool            // See SetQueryAnalysis
+	courseWrites    atomic.Uint64          // See CourseWrites
+
+	// planHook receives the plan of every entity query regardless of its
+	// duration; tests use it to check that key queries use indexes.
+	planHook func(query string, plan []PlanStep)
+
+	errorReporter func(op string)  // See SetErrorReporter
+	ttlOverride   TTLOverride      // See SetTTLOverride
+	ttls          config.TTLPolicy // See SetTTLs
+	faults        *faults.Injector // See SetFaults
 }
 
 // New creates a new database with read/write separation and initializes the schema.
@@ -224,34 +241,26 @@ func (db *DB) Path() string {
 	return db.path
 }
 
-// ExecContext executes a write query with context on the writer connection
+// ExecContext executes a write query with context on the writer connection,
+// or inside the transaction started by WithTx.
 func (db *DB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
 	db.mu.RLock()
 	closed := db.closed
-	writer := db.writer
 	db.mu.RUnlock()
 	if closed {
 		return nil, ErrDatabaseClosed
 	}
-	return writer.ExecContext(ctx, query, args...)
+	return db.writeConn(ctx).ExecContext(ctx, query, args...)
 }
 
-// GetCacheTTL returns the configured cache TTL
+// GetCacheTTL returns the cache TTL passed to New, used by tables without a
+// per-table TTL (see TableTTL).
 func (db *DB) GetCacheTTL() time.Duration {
 	db.mu.RLock()
 	defer db.mu.RUnlock()
 	return db.cacheTTL
 }
 
-// getTTLTimestamp returns the Unix timestamp for TTL cutoff (entries older than this are expired)
-// This is a helper method to avoid repeating the same calculation across repository methods
-func (db *DB) getTTLTimestamp() int64 {
-	db.mu.RLock()
-	cacheTTL := db.cacheTTL
-	db.mu.RUnlock()
-	return time.Now().Unix() - int64(cacheTTL.Seconds())
-}
-
 // Ping verifies the database connections are alive by pinging both writer and reader connections.
 func (db *DB) Ping(ctx context.Context) error {
 	db.mu.RLock()
@@ -264,26 +273,62 @@ func (db *DB) Ping(ctx context.Context) error {
 	)
 }
 
+// CheckWritable commits a write to cache_meta, catching what Ping does not:
+// a read-only file or volume, or a full disk.
+func (db *DB) CheckWritable(ctx context.Context) error {
+	if _, err := db.ExecContext(ctx,
+		`INSERT INTO cache_meta (key, value) VALUES ('write_check', ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value`,
+		time.Now().UTC().Format(time.RFC3339)); err != nil {
+		return fmt.Errorf("write cache_meta: %w", err)
+	}
+	return nil
+}
+
 // ExecBatchContext executes a batch of operations within a single transaction with context support.
 // This is a generic helper that reduces lock contention during warmup.
-// The execFn receives the prepared statement and should execute it for each item.
+// The execFn receives a Batch and should call Exec for each item; Exec fails
+// once ctx is canceled, so the loop stops between rows.
+//
+// When the batch stops early, the policy set by WithBatchPolicy decides the
+// outcome: BatchAllOrNothing (default) rolls everything back, BatchBestEffort
+// commits the rows already written and returns the error wrapped with
+// ErrBatchPartial. Inside WithTx the batch joins that transaction instead.
 //
 // Example:
 //
-//	err := db.ExecBatchContext(ctx, "INSERT INTO t (a,b) VALUES (?,?)", func(stmt *sql.Stmt) error {
+//	err := db.ExecBatchContext(ctx, "INSERT INTO t (a,b) VALUES (?,?)", func(b *Batch) error {
 //	    for _, item := range items {
-//	        if _, err := stmt.ExecContext(ctx, item.A, item.B); err != nil {
+//	        if err := b.Exec(item.A, item.B); err != nil {
 //	            return err
 //	        }
 //	    }
 //	    return nil
 //	})
-func (db *DB) ExecBatchContext(ctx context.Context, query string, execFn func(stmt *sql.Stmt) error) error {
+func (db *DB) ExecBatchContext(ctx context.Context, query string, execFn func(b *Batch) error) error {
+	if tx := txFrom(ctx); tx != nil {
+		// Inside WithTx: the outer transaction commits or rolls back
+		stmt, err := tx.PrepareContext(ctx, query)
+		if err != nil {
+			return fmt.Errorf("failed to prepare statement: %w", err)
+		}
+		defer func() { _ = stmt.Close() }()
+		return execFn(&Batch{db: db, ctx: ctx, txCtx: ctx, stmt: stmt})
+	}
+
 	db.mu.RLock()
 	writer := db.writer
 	db.mu.RUnlock()
 
-	tx, err := writer.BeginTx(ctx, nil)
+	policy := batchPolicyFrom(ctx)
+	txCtx := ctx
+	if policy == BatchBestEffort {
+		// database/sql rolls a transaction back when its context ends; keep it
+		// alive so the rows written before a cancellation can be committed
+		txCtx = context.WithoutCancel(ctx)
+	}
+
+	tx, err := writer.BeginTx(txCtx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -295,21 +340,29 @@ func (db *DB) ExecBatchContext(ctx context.Context, query string, execFn func(st
 		}
 	}()
 
-	stmt, err := tx.PrepareContext(ctx, query)
+	stmt, err := tx.PrepareContext(txCtx, query)
 	if err != nil {
 		return fmt.Errorf("failed to prepare statement: %w", err)
 	}
 	defer func() { _ = stmt.Close() }()
 
-	if err := execFn(stmt); err != nil {
-		return err
+	batch := &Batch{db: db, ctx: ctx, txCtx: txCtx, stmt: stmt}
+	execErr := execFn(batch)
+	if execErr == nil {
+		execErr = ctx.Err() // Canceled after the last row
+	}
+	if execErr != nil && (policy != BatchBestEffort || batch.rows == 0) {
+		return execErr
 	}
 
 	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		return fmt.Errorf("failed to commit transaction: %w", errors.Join(err, execErr))
 	}
 	committed = true
 
+	if execErr != nil {
+		return fmt.Errorf("%w after %d rows: %w", ErrBatchPartial, batch.rows, execErr)
+	}
 	return nil
 }
 
@@ -361,7 +414,7 @@ func (db *DB) CreateSnapshot(ctx context.Context, destPath string) error {
 		return fmt.Errorf("create snapshot: %w", err)
 	}
 
-	if _, err := db.ExecContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+	if err := db.checkpoint(ctx); err != nil {
 		return fmt.Errorf("create snapshot: wal checkpoint: %w", err)
 	}
 
@@ -374,6 +427,63 @@ func (db *DB) CreateSnapshot(ctx context.Context, destPath string) error {
 	return nil
 }
 
+// SetReplicated marks the database file as streamed by Litestream, which
+// keeps a read transaction on the WAL and runs its own checkpoints. The DB
+// then only checkpoints in PASSIVE mode: a TRUNCATE checkpoint would wait on
+// that reader for the busy timeout, fail, and stall the cleanup job.
+func (db *DB) SetReplicated(enabled bool) {
+	db.replicated.Store(enabled)
+}
+
+// checkpoint copies WAL frames into the database file. It truncates the WAL
+// unless the file is replicated (see SetReplicated).
+func (db *DB) checkpoint(ctx context.Context) error {
+	mode := "TRUNCATE"
+	if db.replicated.Load() {
+		mode = "PASSIVE"
+	}
+	_, err := db.ExecContext(ctx, "PRAGMA wal_checkpoint("+mode+")")
+	return err
+}
+
+// VacuumStats reports the database size around a Vacuum.
+type VacuumStats struct {
+	BytesBefore int64
+	BytesAfter  int64
+}
+
+// Vacuum rebuilds the database file to reclaim free pages and checkpoints
+// the WAL. Sizes are page_count × page_size of the main database file.
+func (db *DB) Vacuum(ctx context.Context) (VacuumStats, error) {
+	var stats VacuumStats
+	var err error
+	if stats.BytesBefore, err = db.fileSize(ctx); err != nil {
+		return stats, err
+	}
+	if _, err := db.ExecContext(ctx, "VACUUM"); err != nil {
+		return stats, fmt.Errorf("failed to vacuum: %w", err)
+	}
+	if err := db.checkpoint(ctx); err != nil {
+		return stats, fmt.Errorf("failed to checkpoint wal after vacuum: %w", err)
+	}
+	if stats.BytesAfter, err = db.fileSize(ctx); err != nil {
+		return stats, err
+	}
+	return stats, nil
+}
+
+// fileSize returns the size of the main database file in bytes.
+func (db *DB) fileSize(ctx context.Context) (int64, error) {
+	var pageCount, pageSize int64
+	if err := db.Writer().QueryRowContext(ctx, "PRAGMA page_count").Scan(&pageCount); err != nil {
+		return 0, fmt.Errorf("failed to read page count: %w", err)
+	}
+	if err := db.Writer().QueryRowContext(ctx, "PRAGMA page_size").Scan(&pageSize); err != nil {
+		return 0, fmt.Errorf("failed to read page size: %w", err)
+	}
+	return pageCount * pageSize, nil
+}
+
 // CheckIntegrity runs PRAGMA quick_check on the database.
 // Returns nil if the database is OK, or an error describing the corruption.
 // Uses quick_check(1) for O(N) performance instead of integrity_check's O(N log N),
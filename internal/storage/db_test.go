@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -103,6 +104,29 @@ func TestPing_DatabaseConnectivity(t *testing.T) {
 	}
 }
 
+func TestCheckWritable(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db, err := New(ctx, filepath.Join(t.TempDir(), "test.db"), 168*time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+
+	// A second check updates the row the first wrote
+	for range 2 {
+		if err := db.CheckWritable(ctx); err != nil {
+			t.Fatalf("CheckWritable() on a healthy database error = %v", err)
+		}
+	}
+
+	if err := db.Close(ctx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := db.CheckWritable(ctx); err == nil {
+		t.Error("CheckWritable() on a closed database succeeded")
+	}
+}
+
 // TestClose_CleanShutdown tests clean database shutdown
 func TestClose_CleanShutdown(t *testing.T) {
 	t.Parallel()
@@ -181,3 +205,63 @@ func TestCheckIntegrity_HealthyDatabase(t *testing.T) {
 }
 
 // setupTestDB helper is defined in repository_test.go
+
+func TestVerifyIndexes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		alter   string
+		wantErr bool
+	}{
+		{name: "fresh schema"},
+		{name: "dropped index", alter: `DROP INDEX idx_students_year_id`, wantErr: true},
+		{name: "same name, other columns", alter: `DROP INDEX idx_contacts_extension;
+			CREATE INDEX idx_contacts_extension ON contacts(name)`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			db := setupTestDB(t)
+			ctx := context.Background()
+
+			if tt.alter != "" {
+				if _, err := db.Writer().ExecContext(ctx, tt.alter); err != nil {
+					t.Fatalf("alter schema: %v", err)
+				}
+			}
+
+			err := verifyIndexes(ctx, db.Writer())
+			if got := errors.Is(err, ErrMissingIndex); got != tt.wantErr {
+				t.Errorf("verifyIndexes() error = %v, want ErrMissingIndex: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestInitSchema_AddsTitleEnToOldTables(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	// Databases created before title_en existed lack the column
+	if _, err := db.Writer().ExecContext(ctx, `ALTER TABLE courses DROP COLUMN title_en`); err != nil {
+		t.Fatalf("drop column: %v", err)
+	}
+	if err := InitSchema(ctx, db.Writer()); err != nil {
+		t.Fatalf("InitSchema() error = %v", err)
+	}
+	if err := InitSchema(ctx, db.Writer()); err != nil {
+		t.Fatalf("second InitSchema() error = %v", err)
+	}
+
+	course := &Course{UID: "1131U0001", Year: 113, Term: 1, No: "U0001", Title: "資料結構", TitleEn: "Data Structures"}
+	if err := db.SaveCourse(ctx, course); err != nil {
+		t.Fatalf("SaveCourse() error = %v", err)
+	}
+	got, err := db.GetCourseByUID(ctx, course.UID)
+	if err != nil || got.TitleEn != course.TitleEn {
+		t.Errorf("GetCourseByUID() = (%+v, %v), want TitleEn %q", got, err, course.TitleEn)
+	}
+}
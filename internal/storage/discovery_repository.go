@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Syllabus richness weighting for GetRandomCourse: every course gets weight 1,
+// plus 1 per syllabusWeightUnit characters of syllabus, up to maxSyllabusWeight.
+const (
+	syllabusWeightUnit = 300
+	maxSyllabusWeight  = 10
+)
+
+// GetRandomCourse picks a random non-expired course of a semester, weighted toward
+// courses with richer syllabi (objectives, outline and schedule; compressed columns
+// weigh by their shorter stored length). eduCode filters by
+// the course number prefix (U, M, N or P; "" = any). major filters by 應修系級 prefix
+// (e.g., "資工系"; "" = any). Returns nil if no course matches.
+func (db *DB) GetRandomCourse(ctx context.Context, year, term int, eduCode, major string) (*Course, error) {
+	if len(major) > 100 {
+		return nil, errors.New("search term too long")
+	}
+
+	query := `SELECT c.uid,
+			COALESCE(length(s.objectives), 0) + COALESCE(length(s.outline), 0) + COALESCE(length(s.schedule), 0)
+		FROM courses c
+		LEFT JOIN syllabi s ON s.tenant = c.tenant AND s.uid = c.uid
+		WHERE c.tenant = ? AND c.year = ? AND c.term = ? AND c.cached_at > ?
+			AND c.no LIKE ? ESCAPE '\'`
+	tenant := db.Tenant()
+	args := []any{tenant, year, term, db.getTTLTimestamp(TableCourses), sanitizeSearchTerm(eduCode) + "%"}
+	if major != "" {
+		query += `
+			AND c.uid IN (SELECT course_uid FROM course_majors WHERE tenant = ? AND major LIKE ? ESCAPE '\')`
+		args = append(args, tenant, sanitizeSearchTerm(major)+"%")
+	}
+
+	rows, err := db.Reader().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query random course candidates: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var uids []string
+	var weights []int
+	total := 0
+	for rows.Next() {
+		var uid string
+		var syllabusLen int
+		if err := rows.Scan(&uid, &syllabusLen); err != nil {
+			return nil, fmt.Errorf("failed to scan random course candidate: %w", err)
+		}
+		weight := 1 + min(syllabusLen/syllabusWeightUnit, maxSyllabusWeight-1)
+		uids = append(uids, uid)
+		weights = append(weights, weight)
+		total += weight
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate random course candidates: %w", err)
+	}
+	if len(uids) == 0 {
+		return nil, nil
+	}
+
+	// Use crypto/rand.Int for statistically uniform random selection
+	pickBig, err := rand.Int(rand.Reader, big.NewInt(int64(total)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to pick random course: %w", err)
+	}
+	pick := int(pickBig.Int64())
+	for i, weight := range weights {
+		if pick < weight {
+			return db.GetCourseByUID(ctx, uids[i])
+		}
+		pick -= weight
+	}
+	return db.GetCourseByUID(ctx, uids[len(uids)-1])
+}
@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestGetRandomCourse(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	courses := []*Course{
+		{UID: "1131U0001", Year: 113, Term: 1, No: "U0001", Title: "程式設計",
+			RawProgramReqs: []RawProgramReq{{Name: "資工系1", CourseType: "必"}}},
+		{UID: "1131U0002", Year: 113, Term: 1, No: "U0002", Title: "經濟學",
+			RawProgramReqs: []RawProgramReq{{Name: "經濟系1", CourseType: "必"}}},
+		{UID: "1131M0001", Year: 113, Term: 1, No: "M0001", Title: "機器學習",
+			RawProgramReqs: []RawProgramReq{{Name: "資工所1", CourseType: "選"}}},
+		{UID: "1122U0003", Year: 112, Term: 2, No: "U0003", Title: "計算機概論",
+			RawProgramReqs: []RawProgramReq{{Name: "資工系1", CourseType: "必"}}},
+	}
+	if err := db.SaveCoursesBatch(ctx, courses); err != nil {
+		t.Fatalf("SaveCoursesBatch failed: %v", err)
+	}
+	if err := db.SaveCourseMajorsBatch(ctx, courses); err != nil {
+		t.Fatalf("SaveCourseMajorsBatch failed: %v", err)
+	}
+	if err := db.SaveSyllabusBatch(ctx, []*Syllabus{
+		{UID: "1131U0001", Year: 113, Term: 1, Title: "程式設計", Objectives: strings.Repeat("學", 3000), ContentHash: "h1"},
+	}); err != nil {
+		t.Fatalf("SaveSyllabusBatch failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		eduCode  string
+		major    string
+		wantUIDs []string // Any of these; nil = no course
+	}{
+		{"any", "", "", []string{"1131U0001", "1131U0002", "1131M0001"}},
+		{"undergraduate", "U", "", []string{"1131U0001", "1131U0002"}},
+		{"master", "M", "", []string{"1131M0001"}},
+		{"department", "U", "經濟系", []string{"1131U0002"}},
+		{"no match", "P", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := db.GetRandomCourse(ctx, 113, 1, tt.eduCode, tt.major)
+			if err != nil {
+				t.Fatalf("GetRandomCourse failed: %v", err)
+			}
+			if tt.wantUIDs == nil {
+				if got != nil {
+					t.Errorf("GetRandomCourse() = %s, want nil", got.UID)
+				}
+				return
+			}
+			if got == nil || !slices.Contains(tt.wantUIDs, got.UID) {
+				t.Errorf("GetRandomCourse() = %+v, want one of %v", got, tt.wantUIDs)
+			}
+		})
+	}
+
+	// The course with a rich syllabus weighs 10 against 1 for U0002
+	rich := 0
+	for range 200 {
+		got, err := db.GetRandomCourse(ctx, 113, 1, "U", "")
+		if err != nil {
+			t.Fatalf("GetRandomCourse failed: %v", err)
+		}
+		if got.UID == "1131U0001" {
+			rich++
+		}
+	}
+	if rich < 140 {
+		t.Errorf("rich-syllabus course picked %d/200 times, want a clear majority", rich)
+	}
+}
@@ -0,0 +1,22 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/faults"
+)
+
+// SetFaults makes entity reads and writes fail with SQLITE_BUSY when the
+// injector's storage_busy fault fires, for resilience testing. Call it
+// before the DB is shared; nil disables injection.
+func (db *DB) SetFaults(inj *faults.Injector) {
+	db.faults = inj
+}
+
+// injectedFault returns the error of a fired storage_busy fault, or nil.
+func (db *DB) injectedFault() error {
+	if !db.faults.Fire(faults.StorageBusy) {
+		return nil
+	}
+	return fmt.Errorf("%w: database is locked (5) (SQLITE_BUSY)", faults.ErrInjected)
+}
@@ -0,0 +1,33 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/faults"
+)
+
+func TestSetFaults(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	inj, err := faults.New(map[string]string{faults.StorageBusy: "1"})
+	if err != nil {
+		t.Fatalf("faults.New() error = %v", err)
+	}
+	db.SetFaults(inj)
+
+	if _, err := db.GetStudentByID(ctx, "412345678"); !errors.Is(err, faults.ErrInjected) {
+		t.Errorf("GetStudentByID() error = %v, want an injected fault", err)
+	}
+	if _, err := db.ExecContext(ctx, "DELETE FROM students"); !errors.Is(err, faults.ErrInjected) {
+		t.Errorf("ExecContext() error = %v, want an injected fault", err)
+	}
+
+	db.SetFaults(nil)
+	if _, err := db.GetStudentByID(ctx, "412345678"); err != nil {
+		t.Errorf("GetStudentByID() without faults error = %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Course flags: constraints the course system only states in the free-text
+// 備註 (note), parsed by CourseNoteFlags.
+const (
+	CourseFlagEnglish  = "english"   // 英語授課
+	CourseFlagRemote   = "remote"    // 遠距
+	CourseFlagDeptOnly = "dept_only" // 限本系
+)
+
+// courseFlagKeywords lists the note phrases of each flag, in display order.
+var courseFlagKeywords = []struct {
+	flag     string
+	keywords []string
+}{
+	{CourseFlagEnglish, []string{"英語授課", "英文授課", "全英語", "全英文", "EMI"}},
+	{CourseFlagRemote, []string{"遠距", "線上授課", "線上課程"}},
+	{CourseFlagDeptOnly, []string{"限本系"}},
+}
+
+// CourseNoteFlags returns the flags stated in a course note, in display
+// order. A phrase right after 不 or 非 (不限本系, 非遠距) is a negation and
+// does not set the flag.
+func CourseNoteFlags(note string) []string {
+	var flags []string
+	for _, fk := range courseFlagKeywords {
+		for _, kw := range fk.keywords {
+			if containsAffirmed(note, kw) {
+				flags = append(flags, fk.flag)
+				break
+			}
+		}
+	}
+	return flags
+}
+
+// CourseFlagForKeyword returns the flag a search word names (e.g., 英語授課,
+// 遠距, 限本系), matching the note phrases case-insensitively.
+func CourseFlagForKeyword(word string) (string, bool) {
+	for _, fk := range courseFlagKeywords {
+		for _, kw := range fk.keywords {
+			if strings.EqualFold(word, kw) {
+				return fk.flag, true
+			}
+		}
+	}
+	return "", false
+}
+
+// containsAffirmed reports whether s contains keyword (case-insensitively)
+// at least once not directly preceded by a negation.
+func containsAffirmed(s, keyword string) bool {
+	s, keyword = strings.ToUpper(s), strings.ToUpper(keyword)
+	for i := 0; ; {
+		j := strings.Index(s[i:], keyword)
+		if j < 0 {
+			return false
+		}
+		j += i
+		if !strings.HasSuffix(s[:j], "不") && !strings.HasSuffix(s[:j], "非") {
+			return true
+		}
+		i = j + len(keyword)
+	}
+}
+
+// SaveCourseFlagsBatch replaces the flags of each course with the ones parsed
+// from its note.
+func (db *DB) SaveCourseFlagsBatch(ctx context.Context, courses []*Course) error {
+	if len(courses) == 0 {
+		return nil
+	}
+
+	tenant := db.Tenant()
+	if err := db.ExecBatchContext(ctx, `DELETE FROM course_flags WHERE tenant = ? AND course_uid = ?`, func(b *Batch) error {
+		for _, course := range courses {
+			if err := b.Exec(tenant, course.UID); err != nil {
+				return fmt.Errorf("failed to clear flags of course %s: %w", course.UID, err)
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO course_flags (tenant, course_uid, flag, cached_at)
+		VALUES (?, ?, ?, ?)
+	`
+	now := time.Now().Unix()
+	return db.ExecBatchContext(ctx, query, func(b *Batch) error {
+		for _, course := range courses {
+			for _, flag := range CourseNoteFlags(course.Note) {
+				if err := b.Exec(tenant, course.UID, flag, now); err != nil {
+					return fmt.Errorf("failed to save flag %s for course %s: %w", flag, course.UID, err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// GetCoursesByFlags returns the non-expired courses of a semester that have
+// every flag, ordered by course number. flags must not repeat.
+func (db *DB) GetCoursesByFlags(ctx context.Context, year, term int, flags []string) ([]Course, error) {
+	if len(flags) == 0 {
+		return nil, errors.New("at least one flag is required")
+	}
+
+	query := `SELECT c.uid, c.year, c.term, c.no, c.title, c.teachers, c.teacher_urls, c.times, c.locations, c.detail_url, c.note, c.title_en, c.cached_at
+		FROM courses c
+		WHERE c.tenant = ? AND c.year = ? AND c.term = ? AND c.cached_at > ?
+			AND (SELECT COUNT(*) FROM course_flags f
+				WHERE f.tenant = c.tenant AND f.course_uid = c.uid AND f.flag IN (?` + strings.Repeat(", ?", len(flags)-1) + `)) = ?
+		ORDER BY c.no`
+
+	args := []any{db.Tenant(), year, term, db.getTTLTimestamp(TableCourses)}
+	for _, flag := range flags {
+		args = append(args, flag)
+	}
+	args = append(args, len(flags))
+
+	rows, err := db.Reader().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get courses by flags: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanCourses(rows)
+}
+
+// DeleteExpiredCourseFlags removes course flags older than the specified TTL.
+// Returns the number of deleted entries.
+func (db *DB) DeleteExpiredCourseFlags(ctx context.Context, ttl time.Duration) (int64, error) {
+	return db.deleteExpiredRows(ctx, TableCourseFlags, ttl)
+}
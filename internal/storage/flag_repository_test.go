@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestCourseNoteFlags(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		note string
+		want []string
+	}{
+		{"", nil},
+		{"本課程以英語授課", []string{CourseFlagEnglish}},
+		{"全英語授課；同步遠距教學", []string{CourseFlagEnglish, CourseFlagRemote}},
+		{"EMI course", []string{CourseFlagEnglish}},
+		{"emi", []string{CourseFlagEnglish}},
+		{"限本系生修習", []string{CourseFlagDeptOnly}},
+		{"不限本系", nil},
+		{"非遠距課程", nil},
+		{"不限本系；限本系大三優先", []string{CourseFlagDeptOnly}},
+		{"需自備筆電", nil},
+	}
+	for _, tt := range tests {
+		if got := CourseNoteFlags(tt.note); !slices.Equal(got, tt.want) {
+			t.Errorf("CourseNoteFlags(%q) = %v, want %v", tt.note, got, tt.want)
+		}
+	}
+}
+
+func TestCourseFlagForKeyword(t *testing.T) {
+	t.Parallel()
+	for word, want := range map[string]string{"英語授課": CourseFlagEnglish, "emi": CourseFlagEnglish, "遠距": CourseFlagRemote, "限本系": CourseFlagDeptOnly} {
+		if got, ok := CourseFlagForKeyword(word); !ok || got != want {
+			t.Errorf("CourseFlagForKeyword(%q) = %q, %v; want %q", word, got, ok, want)
+		}
+	}
+	if _, ok := CourseFlagForKeyword("管理"); ok {
+		t.Error("CourseFlagForKeyword(管理) matched")
+	}
+}
+
+func TestGetCoursesByFlags(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	courses := []*Course{
+		{UID: "1141U0001", Year: 114, Term: 1, No: "U0001", Title: "管理學", Note: "英語授課"},
+		{UID: "1141U0002", Year: 114, Term: 1, No: "U0002", Title: "行銷管理", Note: "英語授課；遠距教學"},
+		{UID: "1141U0003", Year: 114, Term: 1, No: "U0003", Title: "會計學", Note: "不限本系"},
+		{UID: "1142U0004", Year: 114, Term: 2, No: "U0004", Title: "財務管理", Note: "英語授課"},
+	}
+	if err := db.SaveCoursesBatch(ctx, courses); err != nil {
+		t.Fatalf("SaveCoursesBatch() error = %v", err)
+	}
+
+	uids := func(cs []Course) []string {
+		var out []string
+		for _, c := range cs {
+			out = append(out, c.UID)
+		}
+		return out
+	}
+	got, err := db.GetCoursesByFlags(ctx, 114, 1, []string{CourseFlagEnglish})
+	if err != nil || !slices.Equal(uids(got), []string{"1141U0001", "1141U0002"}) {
+		t.Errorf("GetCoursesByFlags(english) = %v, %v", uids(got), err)
+	}
+	got, err = db.GetCoursesByFlags(ctx, 114, 1, []string{CourseFlagEnglish, CourseFlagRemote})
+	if err != nil || !slices.Equal(uids(got), []string{"1141U0002"}) {
+		t.Errorf("GetCoursesByFlags(english, remote) = %v, %v", uids(got), err)
+	}
+
+	// Saving again replaces the flags
+	courses[1].Note = "實體授課"
+	if err := db.SaveCourse(ctx, courses[1]); err != nil {
+		t.Fatalf("SaveCourse() error = %v", err)
+	}
+	got, err = db.GetCoursesByFlags(ctx, 114, 1, []string{CourseFlagRemote})
+	if err != nil || len(got) != 0 {
+		t.Errorf("GetCoursesByFlags(remote) after note change = %v, %v; want none", uids(got), err)
+	}
+
+	if _, err := db.GetCoursesByFlags(ctx, 114, 1, nil); err == nil {
+		t.Error("GetCoursesByFlags() without flags succeeded")
+	}
+}
@@ -0,0 +1,183 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SaveCourseMajorsBatch stores the 應修系級 entries (RawProgramReqs) of each course.
+// Entries are upserted; rows a course no longer lists expire via DeleteExpiredCourseMajors.
+// Courses without RawProgramReqs are skipped.
+func (db *DB) SaveCourseMajorsBatch(ctx context.Context, courses []*Course) error {
+	if len(courses) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO course_majors (tenant, course_uid, major, course_type, cached_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(tenant, course_uid, major) DO UPDATE SET
+			course_type = excluded.course_type,
+			cached_at = excluded.cached_at
+	`
+
+	now, tenant := time.Now().Unix(), db.Tenant()
+	return db.ExecBatchContext(ctx, query, func(b *Batch) error {
+		for _, course := range courses {
+			for _, req := range course.RawProgramReqs {
+				if err := b.Exec(tenant, course.UID, req.Name, req.CourseType, now); err != nil {
+					return fmt.Errorf("failed to save major %s for course %s: %w", req.Name, course.UID, err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// GetCoursesByMajor retrieves courses of a semester whose 應修系級 starts with major
+// (e.g., "資工系" matches "資工系1" through "資工系4"), ordered by course number.
+// Only returns non-expired cache entries based on configured TTL.
+func (db *DB) GetCoursesByMajor(ctx context.Context, year, term int, major string) ([]Course, error) {
+	if major == "" {
+		return nil, errors.New("major is required")
+	}
+	if len(major) > 100 {
+		return nil, errors.New("search term too long")
+	}
+
+	ttlTimestamp := db.getTTLTimestamp(TableCourses)
+	query := `SELECT uid, year, term, no, title, teachers, teacher_urls, times, locations, detail_url, note, title_en, cached_at
+		FROM courses
+		WHERE tenant = ? AND year = ? AND term = ? AND cached_at > ?
+			AND uid IN (SELECT course_uid FROM course_majors WHERE tenant = ? AND major LIKE ? ESCAPE '\')
+		ORDER BY no`
+
+	tenant := db.Tenant()
+	rows, err := db.Reader().QueryContext(ctx, query, tenant, year, term, ttlTimestamp, tenant, sanitizeSearchTerm(major)+"%")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get courses by major: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanCourses(rows)
+}
+
+// GetCoursesByMajors retrieves courses of a semester whose 應修系級 is exactly
+// one of majors, ordered by course number. Use it when a prefix would also
+// match other departments (e.g., "資工系" and "資工系碩1").
+// Only returns non-expired cache entries based on configured TTL.
+func (db *DB) GetCoursesByMajors(ctx context.Context, year, term int, majors []string) ([]Course, error) {
+	if len(majors) == 0 {
+		return nil, nil
+	}
+
+	placeholders := strings.Repeat("?,", len(majors))
+	placeholders = placeholders[:len(placeholders)-1]
+	tenant := db.Tenant()
+	args := []any{tenant, year, term, db.getTTLTimestamp(TableCourses), tenant}
+	for _, m := range majors {
+		args = append(args, m)
+	}
+
+	query := `SELECT uid, year, term, no, title, teachers, teacher_urls, times, locations, detail_url, note, title_en, cached_at
+		FROM courses
+		WHERE tenant = ? AND year = ? AND term = ? AND cached_at > ?
+			AND uid IN (SELECT course_uid FROM course_majors WHERE tenant = ? AND major IN (` + placeholders + `))
+		ORDER BY no`
+
+	rows, err := db.Reader().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get courses by majors: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanCourses(rows)
+}
+
+// GetCoursesByDepartments retrieves courses of a semester offered to any of
+// the departments (short names such as "資工", matching 應修系級 "資工系…"),
+// ordered by course number. A non-empty courseType ("必" or "選") keeps only
+// courses of that requirement type for one of those departments.
+// Only returns non-expired cache entries based on configured TTL.
+func (db *DB) GetCoursesByDepartments(ctx context.Context, year, term int, departments []string, courseType string) ([]Course, error) {
+	if len(departments) == 0 {
+		return nil, nil
+	}
+
+	conds := make([]string, 0, len(departments))
+	tenant := db.Tenant()
+	args := []any{tenant, year, term, db.getTTLTimestamp(TableCourses), tenant}
+	for _, d := range departments {
+		conds = append(conds, `major LIKE ? ESCAPE '\'`)
+		args = append(args, sanitizeSearchTerm(d)+"系%")
+	}
+	typeCond := ""
+	if courseType != "" {
+		typeCond = " AND course_type = ?"
+		args = append(args, courseType)
+	}
+
+	query := `SELECT uid, year, term, no, title, teachers, teacher_urls, times, locations, detail_url, note, title_en, cached_at
+		FROM courses
+		WHERE tenant = ? AND year = ? AND term = ? AND cached_at > ?
+			AND uid IN (SELECT course_uid FROM course_majors WHERE tenant = ? AND (` + strings.Join(conds, " OR ") + `)` + typeCond + `)
+		ORDER BY no`
+
+	rows, err := db.Reader().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get courses by departments: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanCourses(rows)
+}
+
+// GetMajorsBySemester returns the distinct 應修系級 values (e.g., "資工系1") of a
+// semester's non-expired courses, sorted.
+func (db *DB) GetMajorsBySemester(ctx context.Context, year, term int) ([]string, error) {
+	query := `SELECT DISTINCT m.major
+		FROM course_majors m
+		JOIN courses c ON c.tenant = m.tenant AND c.uid = m.course_uid
+		WHERE c.tenant = ? AND c.year = ? AND c.term = ? AND c.cached_at > ?
+		ORDER BY m.major`
+
+	rows, err := db.Reader().QueryContext(ctx, query, db.Tenant(), year, term, db.getTTLTimestamp(TableCourseMajors))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get majors: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var majors []string
+	for rows.Next() {
+		var major string
+		if err := rows.Scan(&major); err != nil {
+			return nil, fmt.Errorf("failed to scan major: %w", err)
+		}
+		majors = append(majors, major)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate majors: %w", err)
+	}
+	return majors, nil
+}
+
+// DeleteExpiredCourseMajors removes course-major relationships older than the specified TTL.
+// Returns the number of deleted entries.
+func (db *DB) DeleteExpiredCourseMajors(ctx context.Context, ttl time.Duration) (int64, error) {
+	query := `DELETE FROM course_majors WHERE tenant = ? AND cached_at < ?`
+	expiryTime := time.Now().Add(-ttl).Unix()
+
+	result, err := db.writeConn(ctx).ExecContext(ctx, query, db.Tenant(), expiryTime)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired course majors: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected for course majors: %w", err)
+	}
+	return rowsAffected, nil
+}
@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestGetCoursesByMajor(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	courses := []*Course{
+		{UID: "1131U0002", Year: 113, Term: 1, No: "U0002", Title: "資料結構",
+			RawProgramReqs: []RawProgramReq{{Name: "資工系2", CourseType: "必"}}},
+		{UID: "1131U0001", Year: 113, Term: 1, No: "U0001", Title: "程式設計",
+			RawProgramReqs: []RawProgramReq{{Name: "資工系1", CourseType: "必"}, {Name: "通訊系1", CourseType: "選"}}},
+		{UID: "1131U0003", Year: 113, Term: 1, No: "U0003", Title: "經濟學",
+			RawProgramReqs: []RawProgramReq{{Name: "經濟系1", CourseType: "必"}}},
+		{UID: "1122U0001", Year: 112, Term: 2, No: "U0001", Title: "計算機概論",
+			RawProgramReqs: []RawProgramReq{{Name: "資工系1", CourseType: "必"}}},
+	}
+	if err := db.SaveCoursesBatch(ctx, courses); err != nil {
+		t.Fatalf("SaveCoursesBatch failed: %v", err)
+	}
+	if err := db.SaveCourseMajorsBatch(ctx, courses); err != nil {
+		t.Fatalf("SaveCourseMajorsBatch failed: %v", err)
+	}
+	// Saving again must upsert, not fail on the primary key
+	if err := db.SaveCourseMajorsBatch(ctx, courses); err != nil {
+		t.Fatalf("SaveCourseMajorsBatch (second run) failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		major    string
+		wantUIDs []string
+	}{
+		{"department prefix matches all grades", "資工系", []string{"1131U0001", "1131U0002"}},
+		{"exact grade", "資工系2", []string{"1131U0002"}},
+		{"shared course", "通訊系", []string{"1131U0001"}},
+		{"LIKE wildcards are literal", "%", nil},
+		{"unknown department", "法律系", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := db.GetCoursesByMajor(ctx, 113, 1, tt.major)
+			if err != nil {
+				t.Fatalf("GetCoursesByMajor(%q) error: %v", tt.major, err)
+			}
+			if len(got) != len(tt.wantUIDs) {
+				t.Fatalf("GetCoursesByMajor(%q) returned %d courses, want %d", tt.major, len(got), len(tt.wantUIDs))
+			}
+			for i, uid := range tt.wantUIDs {
+				if got[i].UID != uid {
+					t.Errorf("course[%d].UID = %q, want %q", i, got[i].UID, uid)
+				}
+			}
+		})
+	}
+
+	exact, err := db.GetCoursesByMajors(ctx, 113, 1, []string{"資工系1", "經濟系1"})
+	if err != nil {
+		t.Fatalf("GetCoursesByMajors error: %v", err)
+	}
+	if len(exact) != 2 || exact[0].UID != "1131U0001" || exact[1].UID != "1131U0003" {
+		t.Errorf("GetCoursesByMajors returned %+v, want U0001 and U0003", exact)
+	}
+
+	deptTests := []struct {
+		departments []string
+		courseType  string
+		wantUIDs    []string
+	}{
+		{[]string{"資工", "經濟"}, "", []string{"1131U0001", "1131U0002", "1131U0003"}},
+		{[]string{"通訊"}, "必", nil},
+		{[]string{"通訊"}, "選", []string{"1131U0001"}},
+		{[]string{"資工", "通訊"}, "必", []string{"1131U0001", "1131U0002"}},
+	}
+	for _, tt := range deptTests {
+		got, err := db.GetCoursesByDepartments(ctx, 113, 1, tt.departments, tt.courseType)
+		if err != nil {
+			t.Fatalf("GetCoursesByDepartments(%v, %q) error: %v", tt.departments, tt.courseType, err)
+		}
+		gotUIDs := make([]string, 0, len(got))
+		for _, c := range got {
+			gotUIDs = append(gotUIDs, c.UID)
+		}
+		if !slices.Equal(gotUIDs, tt.wantUIDs) && len(gotUIDs)+len(tt.wantUIDs) > 0 {
+			t.Errorf("GetCoursesByDepartments(%v, %q) = %v, want %v", tt.departments, tt.courseType, gotUIDs, tt.wantUIDs)
+		}
+	}
+
+	if _, err := db.GetCoursesByMajor(ctx, 113, 1, ""); err == nil {
+		t.Error("expected error for empty major")
+	}
+
+	majors, err := db.GetMajorsBySemester(ctx, 113, 1)
+	if err != nil {
+		t.Fatalf("GetMajorsBySemester error: %v", err)
+	}
+	if want := []string{"經濟系1", "資工系1", "資工系2", "通訊系1"}; !slices.Equal(majors, want) {
+		t.Errorf("GetMajorsBySemester = %v, want %v", majors, want)
+	}
+}
@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// LookupMissCourseUID is the lookup-miss kind of course UIDs that the course
+// system has no course for.
+const LookupMissCourseUID = "course_uid"
+
+// SaveLookupMiss records that scraping found nothing for key, so repeated
+// queries for it (typos, courses that were never offered) are answered from
+// the negative cache instead of re-scraping until the Negative TTL expires.
+func (db *DB) SaveLookupMiss(ctx context.Context, kind, key string) error {
+	query := `
+		INSERT INTO lookup_misses (tenant, kind, key, cached_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(tenant, kind, key) DO UPDATE SET cached_at = excluded.cached_at
+	`
+	if _, err := db.writeConn(ctx).ExecContext(ctx, query, db.Tenant(), kind, key, time.Now().Unix()); err != nil {
+		return fmt.Errorf("failed to save lookup miss %s %s: %w", kind, key, err)
+	}
+	return nil
+}
+
+// IsLookupMiss reports whether a lookup of key found nothing within the
+// Negative TTL.
+func (db *DB) IsLookupMiss(ctx context.Context, kind, key string) (bool, error) {
+	query := `SELECT 1 FROM lookup_misses WHERE tenant = ? AND kind = ? AND key = ? AND cached_at > ?`
+
+	var one int
+	err := db.Reader().QueryRowContext(ctx, query, db.Tenant(), kind, key, db.getTTLTimestamp(TableLookupMisses)).Scan(&one)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get lookup miss: %w", err)
+	}
+	return true, nil
+}
+
+// DeleteExpiredLookupMisses removes misses older than the specified TTL.
+// Returns the number of deleted entries.
+func (db *DB) DeleteExpiredLookupMisses(ctx context.Context, ttl time.Duration) (int64, error) {
+	return db.deleteExpiredRows(ctx, TableLookupMisses, ttl)
+}
@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
+)
+
+func TestLookupMisses(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	if miss, err := db.IsLookupMiss(ctx, LookupMissCourseUID, "1131U9999"); err != nil || miss {
+		t.Fatalf("IsLookupMiss() before save = %v, %v; want false", miss, err)
+	}
+	if err := db.SaveLookupMiss(ctx, LookupMissCourseUID, "1131U9999"); err != nil {
+		t.Fatalf("SaveLookupMiss() error = %v", err)
+	}
+	if miss, err := db.IsLookupMiss(ctx, LookupMissCourseUID, "1131U9999"); err != nil || !miss {
+		t.Errorf("IsLookupMiss() after save = %v, %v; want true", miss, err)
+	}
+	if miss, err := db.IsLookupMiss(ctx, "other", "1131U9999"); err != nil || miss {
+		t.Errorf("IsLookupMiss() for another kind = %v, %v; want false", miss, err)
+	}
+
+	// Misses expire with the Negative TTL, not the table default
+	if _, err := db.ExecContext(ctx, `UPDATE lookup_misses SET cached_at = ?`, time.Now().Add(-time.Hour).Unix()); err != nil {
+		t.Fatalf("age miss: %v", err)
+	}
+	db.SetTTLs(config.TTLPolicy{Negative: 10 * time.Minute})
+	if miss, err := db.IsLookupMiss(ctx, LookupMissCourseUID, "1131U9999"); err != nil || miss {
+		t.Errorf("IsLookupMiss() after Negative TTL = %v, %v; want false", miss, err)
+	}
+	deleted, err := db.DeleteExpiredLookupMisses(ctx, 10*time.Minute)
+	if err != nil || deleted != 1 {
+		t.Errorf("DeleteExpiredLookupMisses() = %d, %v; want 1", deleted, err)
+	}
+}
@@ -29,6 +29,7 @@ type Contact struct {
 	Website      string `json:"website,omitzero"`
 	Location     string `json:"location,omitzero"`
 	Superior     string `json:"superior,omitzero"`
+	PartTime     bool   `json:"part_time,omitzero"` // From the part-time (兼任) faculty listing
 	CachedAt     int64  `json:"cached_at"`
 }
 
@@ -39,6 +40,23 @@ type ProgramRequirement struct {
 	CourseType  string `json:"course_type"`  // Requirement type: "必" (required), "選" (elective), etc.
 }
 
+// Prerequisites is the 先修課程 statement of a course syllabus.
+type Prerequisites struct {
+	CourseUID string   `json:"course_uid"`
+	Statement string   `json:"statement"` // Statement as written (e.g., "微積分、線性代數")
+	Titles    []string `json:"titles"`    // Course titles parsed from Statement, for searching
+	CachedAt  int64    `json:"cached_at"` // Unix timestamp when cached
+}
+
+// DepartmentNews is one announcement from a department website's news list.
+type DepartmentNews struct {
+	DeptCode string `json:"dept_code"` // Department code (e.g., "85")
+	Title    string `json:"title"`
+	URL      string `json:"url"`
+	Date     string `json:"date,omitzero"` // Date as shown on the site (e.g., "2025-03-01")
+	CachedAt int64  `json:"cached_at"`     // Unix timestamp when cached
+}
+
 // RawProgramReq represents a program requirement from the course list page.
 // Contains potentially abbreviated name and the required/elective type.
 // This is a transient type used during warmup for matching with full program names.
@@ -54,6 +72,7 @@ type Course struct {
 	Term        int      `json:"term"`
 	No          string   `json:"no"`
 	Title       string   `json:"title"`
+	TitleEn     string   `json:"title_en,omitzero"` // English title from the course list, when given
 	Teachers    []string `json:"teachers"`
 	TeacherURLs []string `json:"teacher_urls,omitzero"`
 	Times       []string `json:"times"`
@@ -68,6 +87,27 @@ type Course struct {
 	RawProgramReqs []RawProgramReq `json:"-"`
 }
 
+// Teacher represents a course teacher identified by the course system's timetable URL.
+// Courses reference teachers through the course_teachers join table.
+type Teacher struct {
+	ID         string          `json:"id"`                  // Stable ID (see TeacherID)
+	Name       string          `json:"name"`                // Display name (e.g., "王小明")
+	URL        string          `json:"url,omitzero"`        // Course-system timetable URL
+	Department string          `json:"department,omitzero"` // Most common 應修系級 department (e.g., "資工系")
+	Profile    *TeacherProfile `json:"profile,omitzero"`    // Contact directory entry, when unambiguous
+	CachedAt   int64           `json:"cached_at"`
+}
+
+// TeacherProfile is the contact directory entry linked to a teacher.
+type TeacherProfile struct {
+	Organization string `json:"organization,omitzero"`
+	Title        string `json:"title,omitzero"`
+	Email        string `json:"email,omitzero"`
+	Extension    string `json:"extension,omitzero"`
+	Website      string `json:"website,omitzero"`
+	PartTime     bool   `json:"part_time,omitzero"`
+}
+
 // Program represents an academic program (學程) with course statistics.
 // Used for displaying program list with course counts and LMS detail URL.
 type Program struct {
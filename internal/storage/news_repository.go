@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// SaveDepartmentNews replaces the stored announcements of a department with
+// news, in display order (newest first).
+func (db *DB) SaveDepartmentNews(ctx context.Context, deptCode string, news []DepartmentNews) error {
+	tenant := db.Tenant()
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		conn := db.writeConn(ctx)
+		if _, err := conn.ExecContext(ctx, `DELETE FROM department_news WHERE tenant = ? AND dept_code = ?`, tenant, deptCode); err != nil {
+			return fmt.Errorf("failed to clear news for department %s: %w", deptCode, err)
+		}
+
+		now := time.Now().Unix()
+		for i, n := range news {
+			if _, err := conn.ExecContext(ctx,
+				`INSERT INTO department_news (tenant, dept_code, position, title, url, date, cached_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+				tenant, deptCode, i, n.Title, n.URL, n.Date, now,
+			); err != nil {
+				return fmt.Errorf("failed to save news for department %s: %w", deptCode, err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetDepartmentNews returns the non-expired announcements of a department in
+// display order. An empty result means none are cached (or they expired).
+func (db *DB) GetDepartmentNews(ctx context.Context, deptCode string) ([]DepartmentNews, error) {
+	query := `SELECT dept_code, title, url, date, cached_at
+		FROM department_news
+		WHERE tenant = ? AND dept_code = ? AND cached_at > ?
+		ORDER BY position`
+
+	rows, err := db.Reader().QueryContext(ctx, query, db.Tenant(), deptCode, db.getTTLTimestamp(TableDepartmentNews))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get department news: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var news []DepartmentNews
+	for rows.Next() {
+		var n DepartmentNews
+		if err := rows.Scan(&n.DeptCode, &n.Title, &n.URL, &n.Date, &n.CachedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan department news: %w", err)
+		}
+		news = append(news, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate department news: %w", err)
+	}
+	return news, nil
+}
+
+// DeleteExpiredDepartmentNews removes announcements older than the specified TTL.
+// Returns the number of deleted entries.
+func (db *DB) DeleteExpiredDepartmentNews(ctx context.Context, ttl time.Duration) (int64, error) {
+	return db.deleteExpiredRows(ctx, TableDepartmentNews, ttl)
+}
@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDepartmentNews(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	if got, err := db.GetDepartmentNews(ctx, "85"); err != nil || len(got) != 0 {
+		t.Fatalf("GetDepartmentNews(missing) = %+v, %v, want none", got, err)
+	}
+
+	if err := db.SaveDepartmentNews(ctx, "85", []DepartmentNews{{Title: "舊公告", URL: "https://example.edu/1"}}); err != nil {
+		t.Fatalf("SaveDepartmentNews failed: %v", err)
+	}
+	// Saving again must replace the whole list
+	news := []DepartmentNews{
+		{Title: "期中考公告", URL: "https://example.edu/3", Date: "2025-04-01"},
+		{Title: "專題說明會", URL: "https://example.edu/2", Date: "2025-03-20"},
+	}
+	if err := db.SaveDepartmentNews(ctx, "85", news); err != nil {
+		t.Fatalf("SaveDepartmentNews (second run) failed: %v", err)
+	}
+	if err := db.SaveDepartmentNews(ctx, "83", []DepartmentNews{{Title: "統計系公告", URL: "https://example.edu/s"}}); err != nil {
+		t.Fatalf("SaveDepartmentNews (other department) failed: %v", err)
+	}
+
+	got, err := db.GetDepartmentNews(ctx, "85")
+	if err != nil {
+		t.Fatalf("GetDepartmentNews failed: %v", err)
+	}
+	if len(got) != 2 || got[0].Title != "期中考公告" || got[1].Date != "2025-03-20" || got[0].DeptCode != "85" {
+		t.Errorf("GetDepartmentNews = %+v, want the second list in order", got)
+	}
+
+	if _, err := db.DeleteExpiredDepartmentNews(ctx, 0); err != nil {
+		t.Fatalf("DeleteExpiredDepartmentNews failed: %v", err)
+	}
+}
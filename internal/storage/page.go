@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strconv"
+
+	domerrors "github.com/garyellow/ntpu-linebot-go/internal/errors"
+)
+
+// Search page sizes. Every search method pages the same way: the zero Page
+// is the first DefaultSearchLimit rows, and no page is larger than
+// MaxSearchLimit, so one request cannot load a whole table. Callers that
+// show results page by page (the LIFF API) use the default; chat replies
+// that merge several searches ask for MaxSearchLimit.
+const (
+	DefaultSearchLimit = 50
+	MaxSearchLimit     = 500
+)
+
+// Page selects a window of search results, by offset or by the cursor of
+// the previous page.
+//
+//	res, _ := db.SearchCoursesByTitlePage(ctx, "程式", storage.Page{Limit: 20})
+//	next, _ := db.SearchCoursesByTitlePage(ctx, "程式", storage.Page{Limit: 20, Cursor: res.NextCursor})
+type Page struct {
+	Limit  int    // Rows per page; 0 means DefaultSearchLimit, capped at MaxSearchLimit
+	Offset int    // Rows to skip; ignored when Cursor is set
+	Cursor string // NextCursor of the previous page
+}
+
+// SearchResult is one page of a search.
+type SearchResult[T any] struct {
+	Items      []T    // Rows of this page, never nil
+	Offset     int    // Position of Items[0] among all matches
+	TotalCount int    // Matches across all pages
+	NextCursor string // Cursor of the following page; empty on the last page
+}
+
+// window returns the LIMIT and OFFSET of the page. A malformed cursor or a
+// negative limit or offset is domerrors.ErrInvalidInput.
+func (p Page) window() (limit, offset int, err error) {
+	if p.Limit < 0 || p.Offset < 0 {
+		return 0, 0, fmt.Errorf("%w: negative page limit or offset", domerrors.ErrInvalidInput)
+	}
+	limit = p.Limit
+	if limit == 0 {
+		limit = DefaultSearchLimit
+	}
+	limit = min(limit, MaxSearchLimit)
+
+	offset = p.Offset
+	if p.Cursor != "" {
+		if offset, err = decodeCursor(p.Cursor); err != nil {
+			return 0, 0, err
+		}
+	}
+	return limit, offset, nil
+}
+
+// newSearchResult wraps the rows fetched at offset, setting NextCursor when
+// rows remain after them.
+func newSearchResult[T any](items []T, total, offset int) SearchResult[T] {
+	if items == nil {
+		items = []T{}
+	}
+	res := SearchResult[T]{Items: items, Offset: offset, TotalCount: total}
+	if next := offset + len(items); len(items) > 0 && next < total {
+		res.NextCursor = encodeCursor(next)
+	}
+	return res
+}
+
+// PageOf returns the page of items, a result list filtered in memory, so it
+// pages with the same cursors as the SQL searches.
+func PageOf[T any](items []T, page Page) (SearchResult[T], error) {
+	limit, offset, err := page.window()
+	if err != nil {
+		return SearchResult[T]{}, err
+	}
+	start := min(offset, len(items))
+	end := min(start+limit, len(items))
+	return newSearchResult(slices.Clone(items[start:end]), len(items), start), nil
+}
+
+// Cursors are opaque to callers. They carry the offset of the next page, so
+// rows saved or expired between two requests may shift a page by a few rows;
+// searches are read from a cache that changes only on refresh.
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("o" + strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil && len(raw) > 1 && raw[0] == 'o' {
+		if offset, convErr := strconv.Atoi(string(raw[1:])); convErr == nil && offset >= 0 {
+			return offset, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: invalid page cursor %q", domerrors.ErrInvalidInput, cursor)
+}
@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	domerrors "github.com/garyellow/ntpu-linebot-go/internal/errors"
+)
+
+func TestPageWindow(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		page       Page
+		wantLimit  int
+		wantOffset int
+		wantErr    bool
+	}{
+		{"zero page", Page{}, DefaultSearchLimit, 0, false},
+		{"limit and offset", Page{Limit: 20, Offset: 40}, 20, 40, false},
+		{"limit capped", Page{Limit: MaxSearchLimit + 1}, MaxSearchLimit, 0, false},
+		{"cursor overrides offset", Page{Limit: 10, Offset: 5, Cursor: encodeCursor(30)}, 10, 30, false},
+		{"negative limit", Page{Limit: -1}, 0, 0, true},
+		{"negative offset", Page{Offset: -1}, 0, 0, true},
+		{"malformed cursor", Page{Cursor: "not a cursor"}, 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			limit, offset, err := tt.page.window()
+			if tt.wantErr {
+				if !errors.Is(err, domerrors.ErrInvalidInput) {
+					t.Errorf("window() error = %v, want ErrInvalidInput", err)
+				}
+				return
+			}
+			if err != nil || limit != tt.wantLimit || offset != tt.wantOffset {
+				t.Errorf("window() = %d, %d, %v; want %d, %d", limit, offset, err, tt.wantLimit, tt.wantOffset)
+			}
+		})
+	}
+}
+
+func TestSearchPaging(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	defer func() { _ = db.Close(context.Background()) }()
+	ctx := context.Background()
+
+	for i := range 5 {
+		course := &Course{UID: fmt.Sprintf("1131U000%d", i), Year: 113, Term: 1, No: fmt.Sprintf("U000%d", i), Title: "程式設計"}
+		if err := db.SaveCourse(ctx, course); err != nil {
+			t.Fatalf("SaveCourse() error: %v", err)
+		}
+	}
+
+	// Follow cursors of 2-row pages: 2 + 2 + 1
+	seen := map[string]bool{}
+	page := Page{Limit: 2}
+	for pages := 1; ; pages++ {
+		res, err := db.SearchCoursesByTitlePage(ctx, "程式", page)
+		if err != nil {
+			t.Fatalf("SearchCoursesByTitlePage() error: %v", err)
+		}
+		if res.TotalCount != 5 {
+			t.Errorf("TotalCount = %d, want 5", res.TotalCount)
+		}
+		for _, c := range res.Items {
+			if seen[c.UID] {
+				t.Errorf("course %s returned on two pages", c.UID)
+			}
+			seen[c.UID] = true
+		}
+		if res.NextCursor == "" {
+			if pages != 3 {
+				t.Errorf("got %d pages, want 3", pages)
+			}
+			break
+		}
+		page.Cursor = res.NextCursor
+	}
+	if len(seen) != 5 {
+		t.Errorf("paged through %d courses, want 5", len(seen))
+	}
+
+	// Past the end: no rows, still the total
+	res, err := db.SearchCoursesByTitlePage(ctx, "程式", Page{Offset: 10})
+	if err != nil || len(res.Items) != 0 || res.TotalCount != 5 || res.NextCursor != "" {
+		t.Errorf("SearchCoursesByTitlePage(offset 10) = %+v, %v; want no rows of 5", res, err)
+	}
+
+	if _, err := db.SearchContactsByNamePage(ctx, "王", Page{Cursor: "bad"}); !errors.Is(err, domerrors.ErrInvalidInput) {
+		t.Errorf("SearchContactsByNamePage(bad cursor) error = %v, want ErrInvalidInput", err)
+	}
+}
+
+func TestSearchLimits(t *testing.T) {
+	t.Parallel()
+	// A zero Page must not already be the largest one
+	if DefaultSearchLimit >= MaxSearchLimit {
+		t.Errorf("DefaultSearchLimit = %d, want it below MaxSearchLimit (%d)", DefaultSearchLimit, MaxSearchLimit)
+	}
+}
+
+func TestPageOf(t *testing.T) {
+	t.Parallel()
+	items := []int{1, 2, 3, 4, 5}
+
+	first, err := PageOf(items, Page{Limit: 2})
+	if err != nil || len(first.Items) != 2 || first.TotalCount != 5 || first.NextCursor == "" {
+		t.Fatalf("PageOf(first) = %+v, %v; want 2 of 5 with a cursor", first, err)
+	}
+	first.Items[0] = 99 // Pages are copies
+	if items[0] != 1 {
+		t.Error("PageOf() returned a view of the input")
+	}
+
+	last, err := PageOf(items, Page{Limit: 3, Cursor: first.NextCursor})
+	if err != nil || len(last.Items) != 3 || last.Offset != 2 || last.Items[0] != 3 || last.NextCursor != "" {
+		t.Errorf("PageOf(next) = %+v, %v; want items 3-5 and no cursor", last, err)
+	}
+	if res, err := PageOf(items, Page{Offset: 9}); err != nil || len(res.Items) != 0 || res.Items == nil {
+		t.Errorf("PageOf(past the end) = %+v, %v; want an empty page", res, err)
+	}
+	if _, err := PageOf(items, Page{Cursor: "bad"}); !errors.Is(err, domerrors.ErrInvalidInput) {
+		t.Errorf("PageOf(bad cursor) error = %v, want ErrInvalidInput", err)
+	}
+}
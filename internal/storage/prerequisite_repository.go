@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SaveCoursePrerequisites stores the 先修課程 statement of a course and the titles
+// parsed from it, replacing any earlier statement.
+func (db *DB) SaveCoursePrerequisites(ctx context.Context, courseUID, statement string, titles []string) error {
+	if titles == nil {
+		titles = []string{}
+	}
+	titlesJSON, err := json.Marshal(titles)
+	if err != nil {
+		return fmt.Errorf("failed to marshal prerequisite titles: %w", err)
+	}
+
+	query := `
+		INSERT INTO course_prerequisites (tenant, course_uid, statement, titles, cached_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(tenant, course_uid) DO UPDATE SET
+			statement = excluded.statement,
+			titles = excluded.titles,
+			cached_at = excluded.cached_at
+	`
+	if _, err := db.writeConn(ctx).ExecContext(ctx, query, db.Tenant(), courseUID, statement, string(titlesJSON), time.Now().Unix()); err != nil {
+		return fmt.Errorf("failed to save prerequisites for course %s: %w", courseUID, err)
+	}
+	return nil
+}
+
+// GetCoursePrerequisites returns the non-expired 先修課程 of a course, or nil if none.
+func (db *DB) GetCoursePrerequisites(ctx context.Context, courseUID string) (*Prerequisites, error) {
+	query := `SELECT course_uid, statement, titles, cached_at
+		FROM course_prerequisites
+		WHERE tenant = ? AND course_uid = ? AND cached_at > ?`
+
+	var p Prerequisites
+	var titlesJSON string
+	err := db.Reader().QueryRowContext(ctx, query, db.Tenant(), courseUID, db.getTTLTimestamp(TableCoursePrerequisites)).
+		Scan(&p.CourseUID, &p.Statement, &titlesJSON, &p.CachedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prerequisites: %w", err)
+	}
+	if err := json.Unmarshal([]byte(titlesJSON), &p.Titles); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal prerequisite titles: %w", err)
+	}
+	return &p, nil
+}
+
+// DeleteExpiredCoursePrerequisites removes prerequisites older than the specified TTL.
+// Returns the number of deleted entries.
+func (db *DB) DeleteExpiredCoursePrerequisites(ctx context.Context, ttl time.Duration) (int64, error) {
+	query := `DELETE FROM course_prerequisites WHERE tenant = ? AND cached_at < ?`
+	expiryTime := time.Now().Add(-ttl).Unix()
+
+	result, err := db.writeConn(ctx).ExecContext(ctx, query, db.Tenant(), expiryTime)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired course prerequisites: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected for course prerequisites: %w", err)
+	}
+	return rowsAffected, nil
+}
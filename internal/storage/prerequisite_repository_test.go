@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestCoursePrerequisites(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	if got, err := db.GetCoursePrerequisites(ctx, "1131U0001"); err != nil || got != nil {
+		t.Fatalf("GetCoursePrerequisites(missing) = %+v, %v, want nil, nil", got, err)
+	}
+
+	if err := db.SaveCoursePrerequisites(ctx, "1131U0001", "微積分", []string{"微積分"}); err != nil {
+		t.Fatalf("SaveCoursePrerequisites failed: %v", err)
+	}
+	// Saving again must replace the statement
+	if err := db.SaveCoursePrerequisites(ctx, "1131U0001", "微積分、線性代數", []string{"微積分", "線性代數"}); err != nil {
+		t.Fatalf("SaveCoursePrerequisites (second run) failed: %v", err)
+	}
+
+	got, err := db.GetCoursePrerequisites(ctx, "1131U0001")
+	if err != nil {
+		t.Fatalf("GetCoursePrerequisites failed: %v", err)
+	}
+	if got == nil || got.Statement != "微積分、線性代數" || !slices.Equal(got.Titles, []string{"微積分", "線性代數"}) {
+		t.Errorf("GetCoursePrerequisites = %+v, want both titles", got)
+	}
+
+	if _, err := db.DeleteExpiredCoursePrerequisites(ctx, 0); err != nil {
+		t.Fatalf("DeleteExpiredCoursePrerequisites failed: %v", err)
+	}
+}
@@ -35,41 +35,28 @@ func (db *DB) SaveCoursePrograms(ctx context.Context, courseUID string, programs
 		return nil
 	}
 
-	tx, err := db.Writer().BeginTx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("begin transaction: %w", err)
-	}
-	defer func() { _ = tx.Rollback() }()
-
-	// Delete existing relationships for this course
-	_, err = tx.ExecContext(ctx, "DELETE FROM course_programs WHERE course_uid = ?", courseUID)
-	if err != nil {
-		return fmt.Errorf("delete existing course programs: %w", err)
-	}
-
-	// Insert new relationships
-	cachedAt := time.Now().Unix()
-	stmt, err := tx.PrepareContext(ctx, `
-		INSERT OR REPLACE INTO course_programs (course_uid, program_name, course_type, cached_at)
-		VALUES (?, ?, ?, ?)
-	`)
-	if err != nil {
-		return fmt.Errorf("prepare insert statement: %w", err)
-	}
-	defer func() { _ = stmt.Close() }()
-
-	for _, p := range programs {
-		_, err = stmt.ExecContext(ctx, courseUID, p.ProgramName, p.CourseType, cachedAt)
+	cachedAt, tenant := time.Now().Unix(), db.Tenant()
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		// Delete existing relationships for this course
+		_, err := db.writeConn(ctx).ExecContext(ctx, "DELETE FROM course_programs WHERE tenant = ? AND course_uid = ?", tenant, courseUID)
 		if err != nil {
-			return fmt.Errorf("insert course program: %w", err)
+			return fmt.Errorf("delete existing course programs: %w", err)
 		}
-	}
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("commit transaction: %w", err)
-	}
-
-	return nil
+		// Insert new relationships
+		query := `
+			INSERT OR REPLACE INTO course_programs (tenant, course_uid, program_name, course_type, cached_at)
+			VALUES (?, ?, ?, ?, ?)
+		`
+		return db.ExecBatchContext(ctx, query, func(b *Batch) error {
+			for _, p := range programs {
+				if err := b.Exec(tenant, courseUID, p.ProgramName, p.CourseType, cachedAt); err != nil {
+					return fmt.Errorf("insert course program: %w", err)
+				}
+			}
+			return nil
+		})
+	})
 }
 
 // SyncPrograms synchronizes program metadata (name + category + URL) from static data.
@@ -79,34 +66,19 @@ func (db *DB) SyncPrograms(ctx context.Context, programs []struct{ Name, Categor
 		return nil
 	}
 
-	tx, err := db.Writer().BeginTx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("begin transaction: %w", err)
-	}
-	defer func() { _ = tx.Rollback() }()
-
-	cachedAt := time.Now().Unix()
-	stmt, err := tx.PrepareContext(ctx, `
-		INSERT OR REPLACE INTO programs (name, category, url, cached_at)
-		VALUES (?, ?, ?, ?)
-	`)
-	if err != nil {
-		return fmt.Errorf("prepare statement: %w", err)
-	}
-	defer func() { _ = stmt.Close() }()
-
-	for _, p := range programs {
-		_, err = stmt.ExecContext(ctx, p.Name, p.Category, p.URL, cachedAt)
-		if err != nil {
-			return fmt.Errorf("insert program %s (%s): %w", p.Name, p.Category, err)
+	cachedAt, tenant := time.Now().Unix(), db.Tenant()
+	query := `
+		INSERT OR REPLACE INTO programs (tenant, name, category, url, cached_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+	return db.ExecBatchContext(ctx, query, func(b *Batch) error {
+		for _, p := range programs {
+			if err := b.Exec(tenant, p.Name, p.Category, p.URL, cachedAt); err != nil {
+				return fmt.Errorf("insert program %s (%s): %w", p.Name, p.Category, err)
+			}
 		}
-	}
-
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("commit transaction: %w", err)
-	}
-
-	return nil
+		return nil
+	})
 }
 
 // GetAllPrograms returns all unique program names with course statistics and LMS URLs.
@@ -127,6 +99,7 @@ func (db *DB) GetAllPrograms(ctx context.Context, years, terms []int) ([]Program
 		for range 3 {
 			args = append(args, semesterArgs...)
 		}
+		args = append(args, db.Tenant())
 
 		// Query flipped: Select from programs table first (Source of Truth)
 		// Note: course_programs is joined via LEFT JOIN, so programs without courses still appear.
@@ -141,8 +114,9 @@ func (db *DB) GetAllPrograms(ctx context.Context, years, terms []int) ([]Program
 				SUM(CASE WHEN (` + semesterCond + `) THEN 1 ELSE 0 END) as total_count,
 				COALESCE(p.cached_at, 0) as cached_at
 			FROM programs p
-			LEFT JOIN course_programs cp ON p.name = cp.program_name
-			LEFT JOIN courses c ON cp.course_uid = c.uid
+			LEFT JOIN course_programs cp ON cp.tenant = p.tenant AND p.name = cp.program_name
+			LEFT JOIN courses c ON c.tenant = cp.tenant AND cp.course_uid = c.uid
+			WHERE p.tenant = ?
 			GROUP BY p.name, p.category
 			ORDER BY p.name`
 	} else {
@@ -157,9 +131,11 @@ func (db *DB) GetAllPrograms(ctx context.Context, years, terms []int) ([]Program
 				COUNT(cp.course_uid) as total_count,
 				COALESCE(p.cached_at, 0) as cached_at
 			FROM programs p
-			LEFT JOIN course_programs cp ON p.name = cp.program_name
+			LEFT JOIN course_programs cp ON cp.tenant = p.tenant AND p.name = cp.program_name
+			WHERE p.tenant = ?
 			GROUP BY p.name, p.category
 			ORDER BY p.name`
+		args = []any{db.Tenant()}
 	}
 	rows, err := db.Reader().QueryContext(ctx, query, args...)
 	if err != nil {
@@ -197,7 +173,7 @@ func (db *DB) GetProgramByName(ctx context.Context, name string, years, terms []
 		for range 3 {
 			args = append(args, semesterArgs...)
 		}
-		args = append(args, name)
+		args = append(args, db.Tenant(), name)
 
 		query = `
 			SELECT
@@ -209,13 +185,13 @@ func (db *DB) GetProgramByName(ctx context.Context, name string, years, terms []
 				SUM(CASE WHEN (` + semesterCond + `) THEN 1 ELSE 0 END) as total_count,
 				COALESCE(p.cached_at, 0) as cached_at
 			FROM programs p
-			LEFT JOIN course_programs cp ON p.name = cp.program_name
-			LEFT JOIN courses c ON cp.course_uid = c.uid
-			WHERE p.name = ?
+			LEFT JOIN course_programs cp ON cp.tenant = p.tenant AND p.name = cp.program_name
+			LEFT JOIN courses c ON c.tenant = cp.tenant AND cp.course_uid = c.uid
+			WHERE p.tenant = ? AND p.name = ?
 			GROUP BY p.name, p.category`
 	} else {
 		// No semester filter - count all courses
-		args = []any{name}
+		args = []any{db.Tenant(), name}
 		query = `
 			SELECT
 				p.name,
@@ -226,8 +202,8 @@ func (db *DB) GetProgramByName(ctx context.Context, name string, years, terms []
 				COUNT(cp.course_uid) as total_count,
 				COALESCE(p.cached_at, 0) as cached_at
 			FROM programs p
-			LEFT JOIN course_programs cp ON p.name = cp.program_name
-			WHERE p.name = ?
+			LEFT JOIN course_programs cp ON cp.tenant = p.tenant AND p.name = cp.program_name
+			WHERE p.tenant = ? AND p.name = ?
 			GROUP BY p.name, p.category`
 	}
 
@@ -243,13 +219,24 @@ func (db *DB) GetProgramByName(ctx context.Context, name string, years, terms []
 	return &prog, nil
 }
 
-// SearchPrograms searches for programs by name using fuzzy matching.
-// If years and terms are provided (non-empty, equal length), statistics are limited to those semesters.
-// Returns programs where name contains the search term, including URL from programs table.
+// SearchPrograms searches for programs by name using fuzzy matching
+// (first MaxSearchLimit results, see SearchProgramsPage).
 func (db *DB) SearchPrograms(ctx context.Context, searchTerm string, years, terms []int) ([]Program, error) {
+	res, err := db.SearchProgramsPage(ctx, searchTerm, years, terms, Page{Limit: MaxSearchLimit})
+	return res.Items, err
+}
+
+// SearchProgramsPage returns one page of the programs whose name contains
+// the search term, including URL from programs table.
+// If years and terms are provided (non-empty, equal length), statistics are limited to those semesters.
+func (db *DB) SearchProgramsPage(ctx context.Context, searchTerm string, years, terms []int, page Page) (SearchResult[Program], error) {
 	// Validate input
 	if len(searchTerm) > 100 {
-		return nil, errors.New("search term too long")
+		return SearchResult[Program]{}, errors.New("search term too long")
+	}
+	limit, offset, err := page.window()
+	if err != nil {
+		return SearchResult[Program]{}, err
 	}
 
 	// Sanitize search term to prevent SQL LIKE special character issues
@@ -265,7 +252,7 @@ func (db *DB) SearchPrograms(ctx context.Context, searchTerm string, years, term
 			args = append(args, semesterArgs...)
 		}
 		// Search term comes LAST for the WHERE clause
-		args = append(args, "%"+sanitized+"%")
+		args = append(args, db.Tenant(), "%"+sanitized+"%")
 
 		query = `
 			SELECT
@@ -277,11 +264,12 @@ func (db *DB) SearchPrograms(ctx context.Context, searchTerm string, years, term
 				SUM(CASE WHEN (` + semesterCond + `) THEN 1 ELSE 0 END) as total_count,
 				COALESCE(p.cached_at, 0) as cached_at
 			FROM programs p
-			LEFT JOIN course_programs cp ON p.name = cp.program_name
-			LEFT JOIN courses c ON cp.course_uid = c.uid
-			WHERE p.name LIKE ? ESCAPE '\'
+			LEFT JOIN course_programs cp ON cp.tenant = p.tenant AND p.name = cp.program_name
+			LEFT JOIN courses c ON c.tenant = cp.tenant AND cp.course_uid = c.uid
+			WHERE p.tenant = ? AND p.name LIKE ? ESCAPE '\'
 			GROUP BY p.name, p.category
-			ORDER BY p.name`
+			ORDER BY p.name
+			LIMIT ? OFFSET ?`
 	} else {
 		// No semester filter (legacy behavior)
 		query = `
@@ -294,16 +282,23 @@ func (db *DB) SearchPrograms(ctx context.Context, searchTerm string, years, term
 				COUNT(cp.course_uid) as total_count,
 				COALESCE(p.cached_at, 0) as cached_at
 			FROM programs p
-			LEFT JOIN course_programs cp ON p.name = cp.program_name
-			WHERE p.name LIKE ? ESCAPE '\'
+			LEFT JOIN course_programs cp ON cp.tenant = p.tenant AND p.name = cp.program_name
+			WHERE p.tenant = ? AND p.name LIKE ? ESCAPE '\'
 			GROUP BY p.name, p.category
-			ORDER BY p.name`
-		args = []any{"%" + sanitized + "%"}
+			ORDER BY p.name
+			LIMIT ? OFFSET ?`
+		args = []any{db.Tenant(), "%" + sanitized + "%"}
+	}
+	args = append(args, limit, offset)
+
+	total, err := countWhere(ctx, db, "programs p", `WHERE p.name LIKE ? ESCAPE '\'`, "%"+sanitized+"%")
+	if err != nil {
+		return SearchResult[Program]{}, fmt.Errorf("count programs: %w", err)
 	}
 
 	rows, err := db.Reader().QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("search programs: %w", err)
+		return SearchResult[Program]{}, fmt.Errorf("search programs: %w", err)
 	}
 	defer func() { _ = rows.Close() }()
 
@@ -311,16 +306,16 @@ func (db *DB) SearchPrograms(ctx context.Context, searchTerm string, years, term
 	for rows.Next() {
 		var p Program
 		if err := rows.Scan(&p.Name, &p.Category, &p.URL, &p.RequiredCount, &p.ElectiveCount, &p.TotalCount, &p.CachedAt); err != nil {
-			return nil, fmt.Errorf("scan program: %w", err)
+			return SearchResult[Program]{}, fmt.Errorf("scan program: %w", err)
 		}
 		programs = append(programs, p)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("iterate programs: %w", err)
+		return SearchResult[Program]{}, fmt.Errorf("iterate programs: %w", err)
 	}
 
-	return programs, nil
+	return newSearchResult(programs, total, offset), nil
 }
 
 // GetProgramCourses returns courses for a given program, optionally filtered by semesters.
@@ -331,22 +326,22 @@ func (db *DB) GetProgramCourses(ctx context.Context, programName string, years,
 	// Build query with optional semester filter
 	var query string
 	var args []any
-	ttlTimestamp := db.getTTLTimestamp()
+	ttlTimestamp := db.getTTLTimestamp(TableCoursePrograms)
 
 	if semesterCond, semesterArgs, ok := buildSemesterConditions(years, terms); ok {
 		// Program name first, then semester args
-		args = append(args, programName)
+		args = append(args, db.Tenant(), programName)
 		args = append(args, semesterArgs...)
 		args = append(args, ttlTimestamp, ttlTimestamp)
 
 		query = `
 			SELECT
 				c.uid, c.year, c.term, c.no, c.title, c.teachers, c.teacher_urls,
-				c.times, c.locations, c.detail_url, c.note, c.cached_at,
+				c.times, c.locations, c.detail_url, c.note, c.title_en, c.cached_at,
 				cp.course_type
 			FROM course_programs cp
-			JOIN courses c ON cp.course_uid = c.uid
-			WHERE cp.program_name = ? AND (` + semesterCond + `) AND c.cached_at > ? AND cp.cached_at > ?
+			JOIN courses c ON c.tenant = cp.tenant AND cp.course_uid = c.uid
+			WHERE cp.tenant = ? AND cp.program_name = ? AND (` + semesterCond + `) AND c.cached_at > ? AND cp.cached_at > ?
 			ORDER BY
 				CASE WHEN cp.course_type = '必' THEN 0 ELSE 1 END,
 				c.year DESC,
@@ -356,16 +351,16 @@ func (db *DB) GetProgramCourses(ctx context.Context, programName string, years,
 		query = `
 			SELECT
 				c.uid, c.year, c.term, c.no, c.title, c.teachers, c.teacher_urls,
-				c.times, c.locations, c.detail_url, c.note, c.cached_at,
+				c.times, c.locations, c.detail_url, c.note, c.title_en, c.cached_at,
 				cp.course_type
 			FROM course_programs cp
-			JOIN courses c ON cp.course_uid = c.uid
-			WHERE cp.program_name = ? AND c.cached_at > ? AND cp.cached_at > ?
+			JOIN courses c ON c.tenant = cp.tenant AND cp.course_uid = c.uid
+			WHERE cp.tenant = ? AND cp.program_name = ? AND c.cached_at > ? AND cp.cached_at > ?
 			ORDER BY
 				CASE WHEN cp.course_type = '必' THEN 0 ELSE 1 END,
 				c.year DESC,
 				c.term DESC`
-		args = []any{programName, ttlTimestamp, ttlTimestamp}
+		args = []any{db.Tenant(), programName, ttlTimestamp, ttlTimestamp}
 	}
 
 	rows, err := db.Reader().QueryContext(ctx, query, args...)
@@ -378,12 +373,12 @@ func (db *DB) GetProgramCourses(ctx context.Context, programName string, years,
 	for rows.Next() {
 		var pc ProgramCourse
 		var teachers, teacherURLs, times, locations string
-		var detailURL, note sql.NullString
+		var detailURL, note, titleEn sql.NullString
 
 		err := rows.Scan(
 			&pc.Course.UID, &pc.Course.Year, &pc.Course.Term, &pc.Course.No,
 			&pc.Course.Title, &teachers, &teacherURLs, &times, &locations,
-			&detailURL, &note, &pc.Course.CachedAt,
+			&detailURL, &note, &titleEn, &pc.Course.CachedAt,
 			&pc.CourseType,
 		)
 		if err != nil {
@@ -393,6 +388,7 @@ func (db *DB) GetProgramCourses(ctx context.Context, programName string, years,
 		// Handle nullable fields
 		pc.Course.DetailURL = detailURL.String
 		pc.Course.Note = note.String
+		pc.Course.TitleEn = titleEn.String
 
 		// Parse JSON arrays
 		pc.Course.Teachers = parseJSONArray(teachers)
@@ -417,14 +413,14 @@ func (db *DB) GetCoursePrograms(ctx context.Context, courseUID string) ([]Progra
 	query := `
 		SELECT program_name, course_type
 		FROM course_programs
-		WHERE course_uid = ? AND cached_at > ?
+		WHERE tenant = ? AND course_uid = ? AND cached_at > ?
 		ORDER BY
 			CASE WHEN course_type = '必' THEN 0 ELSE 1 END,
 			program_name
 	`
-	ttlTimestamp := db.getTTLTimestamp()
+	ttlTimestamp := db.getTTLTimestamp(TableCoursePrograms)
 
-	rows, err := db.Reader().QueryContext(ctx, query, courseUID, ttlTimestamp)
+	rows, err := db.Reader().QueryContext(ctx, query, db.Tenant(), courseUID, ttlTimestamp)
 	if err != nil {
 		return nil, fmt.Errorf("query course programs: %w", err)
 	}
@@ -449,10 +445,10 @@ func (db *DB) GetCoursePrograms(ctx context.Context, courseUID string) ([]Progra
 // DeleteExpiredCoursePrograms removes course-program relationships older than the specified TTL.
 // Returns the number of deleted entries.
 func (db *DB) DeleteExpiredCoursePrograms(ctx context.Context, ttl time.Duration) (int64, error) {
-	query := `DELETE FROM course_programs WHERE cached_at < ?`
+	query := `DELETE FROM course_programs WHERE tenant = ? AND cached_at < ?`
 	expiryTime := time.Now().Add(-ttl).Unix()
 
-	result, err := db.Writer().ExecContext(ctx, query, expiryTime)
+	result, err := db.writeConn(ctx).ExecContext(ctx, query, db.Tenant(), expiryTime)
 	if err != nil {
 		return 0, fmt.Errorf("failed to delete expired course programs: %w", err)
 	}
@@ -467,10 +463,10 @@ func (db *DB) DeleteExpiredCoursePrograms(ctx context.Context, ttl time.Duration
 // DeleteExpiredPrograms removes programs older than the specified TTL.
 // Returns the number of deleted entries.
 func (db *DB) DeleteExpiredPrograms(ctx context.Context, ttl time.Duration) (int64, error) {
-	query := `DELETE FROM programs WHERE cached_at < ?`
+	query := `DELETE FROM programs WHERE tenant = ? AND cached_at < ?`
 	expiryTime := time.Now().Add(-ttl).Unix()
 
-	result, err := db.Writer().ExecContext(ctx, query, expiryTime)
+	result, err := db.writeConn(ctx).ExecContext(ctx, query, db.Tenant(), expiryTime)
 	if err != nil {
 		return 0, fmt.Errorf("failed to delete expired programs: %w", err)
 	}
@@ -485,7 +481,7 @@ func (db *DB) DeleteExpiredPrograms(ctx context.Context, ttl time.Duration) (int
 // CountPrograms returns the total number of programs in the database.
 func (db *DB) CountPrograms(ctx context.Context) (int, error) {
 	var count int
-	err := db.Reader().QueryRowContext(ctx, "SELECT COUNT(*) FROM programs").Scan(&count)
+	err := db.Reader().QueryRowContext(ctx, "SELECT COUNT(*) FROM programs WHERE tenant = ?", db.Tenant()).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("count programs: %w", err)
 	}
@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
+)
+
+// PlanStep is one row of EXPLAIN QUERY PLAN output.
+type PlanStep struct {
+	ID     int
+	Parent int
+	Detail string // e.g., "SEARCH students USING INDEX idx_students_year_dept (year=? AND department=?)"
+}
+
+// SetQueryAnalysis enables EXPLAIN QUERY PLAN capture for queries slower
+// than config.SlowQueryThreshold. Meant for debugging: each slow query costs
+// a second round trip to explain it.
+func (db *DB) SetQueryAnalysis(enabled bool) {
+	db.analyzeQueries.Store(enabled)
+}
+
+// ExplainQueryPlan returns SQLite's plan for query without running it.
+func (db *DB) ExplainQueryPlan(ctx context.Context, query string, args ...any) ([]PlanStep, error) {
+	rows, err := db.Reader().QueryContext(ctx, "EXPLAIN QUERY PLAN "+query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("explain query plan: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var plan []PlanStep
+	for rows.Next() {
+		var step PlanStep
+		var notUsed int
+		if err := rows.Scan(&step.ID, &step.Parent, &notUsed, &step.Detail); err != nil {
+			return nil, fmt.Errorf("scan query plan: %w", err)
+		}
+		plan = append(plan, step)
+	}
+	return plan, rows.Err()
+}
+
+// MissingIndexHints returns a hint for every plan step that makes a full
+// pass over a table or index, or sorts through a temporary B-tree: the shapes
+// an index fixes. Unavoidable scans (e.g., LIKE '%term%') show up here too.
+func MissingIndexHints(plan []PlanStep) []string {
+	var hints []string
+	for _, step := range plan {
+		switch {
+		case step.Detail == "SCAN CONSTANT ROW":
+		case strings.HasPrefix(step.Detail, "SCAN "):
+			table, index, usingIndex := strings.Cut(strings.TrimPrefix(step.Detail, "SCAN "), " USING ")
+			if usingIndex {
+				hints = append(hints, "full pass over "+table+" "+strings.ToLower(index)+"; index the columns it filters on")
+			} else {
+				hints = append(hints, "full scan of "+table+"; index the columns it filters on")
+			}
+		case strings.HasPrefix(step.Detail, "USE TEMP B-TREE FOR "):
+			hints = append(hints, "temporary B-tree "+strings.ToLower(strings.TrimPrefix(step.Detail, "USE TEMP B-TREE "))+"; index the sort columns")
+		}
+	}
+	return hints
+}
+
+// analyzeQuery logs the plan and missing-index hints of a query that took
+// longer than config.SlowQueryThreshold since start, when SetQueryAnalysis
+// is on. The helpers in table.go call it for every entity query.
+func (db *DB) analyzeQuery(ctx context.Context, query string, args []any, start time.Time) {
+	if db.planHook != nil {
+		if plan, err := db.ExplainQueryPlan(ctx, query, args...); err == nil {
+			db.planHook(query, plan)
+		}
+	}
+
+	duration := time.Since(start)
+	if !db.analyzeQueries.Load() || duration <= config.SlowQueryThreshold {
+		return
+	}
+
+	plan, err := db.ExplainQueryPlan(ctx, query, args...)
+	if err != nil {
+		slog.DebugContext(ctx, "Failed to explain slow query", "query", query, "error", err)
+		return
+	}
+	details := make([]string, len(plan))
+	for i, step := range plan {
+		details[i] = step.Detail
+	}
+	slog.WarnContext(ctx, "Slow query plan",
+		"query", query,
+		"duration_ms", duration.Milliseconds(),
+		"plan", details,
+		"hints", MissingIndexHints(plan))
+}
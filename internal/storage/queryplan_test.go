@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	domerrors "github.com/garyellow/ntpu-linebot-go/internal/errors"
+)
+
+func TestMissingIndexHints(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		detail string
+		want   int
+	}{
+		{"index search", "SEARCH students USING INDEX idx_students_year_dept (year=? AND department=?)", 0},
+		{"primary key", "SEARCH courses USING INDEX sqlite_autoindex_courses_1 (uid=?)", 0},
+		{"constant row", "SCAN CONSTANT ROW", 0},
+		{"full scan", "SCAN contacts", 1},
+		{"full index pass", "SCAN courses USING INDEX sqlite_autoindex_courses_1", 1},
+		{"temp b-tree", "USE TEMP B-TREE FOR ORDER BY", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := MissingIndexHints([]PlanStep{{Detail: tt.detail}}); len(got) != tt.want {
+				t.Errorf("MissingIndexHints(%q) = %v, want %d hints", tt.detail, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestKeyQueriesUseIndexes runs the lookups behind the bot's hot paths on a
+// cache about the size of a production one and fails if SQLite plans a full
+// pass over a table or index for any of them.
+func TestKeyQueriesUseIndexes(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	ctx := context.Background()
+	now := time.Now().Unix()
+
+	// 12 admission years of students and 4 semesters of courses, each batch
+	// stamped with one cached_at like a warmup run
+	students := make([]*Student, 0, 6000)
+	for i := range 6000 {
+		year := 101 + i%12
+		students = append(students, &Student{
+			ID:         fmt.Sprintf("4%d%05d", year, i),
+			Name:       fmt.Sprintf("學生%d", i),
+			Department: fmt.Sprintf("系%d", i%40),
+			Year:       year,
+			CachedAt:   now - int64(year),
+		})
+	}
+	if err := db.SaveStudentsBatch(ctx, students); err != nil {
+		t.Fatalf("SaveStudentsBatch() error = %v", err)
+	}
+	courses := make([]*Course, 0, 8000)
+	for i := range 8000 {
+		year, term := 112+i%2, 1+(i/2)%2
+		courses = append(courses, &Course{
+			UID:      fmt.Sprintf("%d%dU%04d", year, term, i),
+			Year:     year,
+			Term:     term,
+			No:       fmt.Sprintf("U%04d", i),
+			Title:    fmt.Sprintf("課程%d", i),
+			Teachers: []string{fmt.Sprintf("教師%d", i%500)},
+		})
+	}
+	if err := db.SaveCoursesBatch(ctx, courses); err != nil {
+		t.Fatalf("SaveCoursesBatch() error = %v", err)
+	}
+	if _, err := db.Writer().ExecContext(ctx, "ANALYZE"); err != nil {
+		t.Fatalf("ANALYZE error = %v", err)
+	}
+
+	var scans []string
+	db.planHook = func(query string, plan []PlanStep) {
+		for _, step := range plan {
+			if strings.HasPrefix(step.Detail, "SCAN ") {
+				scans = append(scans, step.Detail+" in "+strings.Join(strings.Fields(query), " "))
+			}
+		}
+	}
+
+	queries := []struct {
+		name string
+		run  func() error
+	}{
+		{"GetStudentByID", func() error { _, err := db.GetStudentByID(ctx, students[0].ID); return err }},
+		{"GetStudentsByDepartment", func() error { _, err := db.GetStudentsByDepartment(ctx, "系1", 105); return err }},
+		{"GetCourseByUID", func() error { _, err := db.GetCourseByUID(ctx, courses[0].UID); return err }},
+		{"GetCoursesByYearTerm", func() error { _, err := db.GetCoursesByYearTerm(ctx, 113, 1); return err }},
+		{"GetCoursesByYearTermPaginated", func() error { _, err := db.GetCoursesByYearTermPaginated(ctx, 113, 1, 100, 200); return err }},
+		{"GetContactsByOrganization", func() error { _, err := db.GetContactsByOrganization(ctx, "資訊工程學系"); return err }},
+		{"GetSyllabusByUID", func() error { _, err := db.GetSyllabusByUID(ctx, courses[0].UID); return err }},
+		{"GetSyllabiByYearTerm", func() error { _, err := db.GetSyllabiByYearTerm(ctx, 113, 1); return err }},
+	}
+	for _, q := range queries {
+		// Only the plan matters; lookups of rows the fixture lacks may miss
+		if err := q.run(); err != nil && !errors.Is(err, domerrors.ErrNotFound) {
+			t.Fatalf("%s() error = %v", q.name, err)
+		}
+	}
+
+	if len(scans) > 0 {
+		slices.Sort(scans)
+		t.Errorf("key queries plan full passes:\n%s", strings.Join(scans, "\n"))
+	}
+}
@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// Operations passed to the function set by SetErrorReporter.
+const (
+	OpRead  = "read"
+	OpWrite = "write"
+)
+
+// SetErrorReporter sets fn to be called with OpRead or OpWrite whenever a
+// query fails, e.g., to count DB errors for alerting. Failures caused by the
+// caller's context ending are not reported. Call it before the DB is shared;
+// nil disables reporting.
+func (db *DB) SetErrorReporter(fn func(op string)) {
+	db.errorReporter = fn
+}
+
+// reportError passes a failed query to the error reporter, if any.
+func (db *DB) reportError(ctx context.Context, op string, err error) {
+	if err == nil || db.errorReporter == nil || ctx.Err() != nil || errors.Is(err, sql.ErrNoRows) {
+		return
+	}
+	db.errorReporter(op)
+}
+
+// reportingConn reports the failed writes of a writeConn.
+type reportingConn struct {
+	conn writeConn
+	db   *DB
+}
+
+func (c reportingConn) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	if err := c.db.injectedFault(); err != nil {
+		c.db.reportError(ctx, OpWrite, err)
+		return nil, err
+	}
+	result, err := c.conn.ExecContext(ctx, query, args...)
+	c.db.reportError(ctx, OpWrite, err)
+	return result, err
+}
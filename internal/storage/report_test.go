@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestSetErrorReporter(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	reported := make(map[string]int)
+	db.SetErrorReporter(func(op string) {
+		mu.Lock()
+		defer mu.Unlock()
+		reported[op]++
+	})
+
+	if _, err := db.GetStudentByID(ctx, "412345678"); err != nil {
+		t.Fatalf("GetStudentByID() error = %v", err)
+	}
+	if _, err := db.ExecContext(ctx, "INSERT INTO no_such_table VALUES (1)"); err == nil {
+		t.Fatal("ExecContext() into a missing table succeeded")
+	}
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, _ = db.ExecContext(canceled, "INSERT INTO no_such_table VALUES (1)")
+
+	if _, err := db.Writer().ExecContext(ctx, "DROP TABLE students"); err != nil {
+		t.Fatalf("DROP TABLE error = %v", err)
+	}
+	if _, err := db.GetStudentByID(ctx, "412345678"); err == nil {
+		t.Fatal("GetStudentByID() on a dropped table succeeded")
+	}
+
+	// A miss is not an error, and the canceled write is the caller's doing
+	want := map[string]int{OpRead: 1, OpWrite: 1}
+	for op, n := range want {
+		if reported[op] != n {
+			t.Errorf("reported[%q] = %d, want %d (all: %v)", op, reported[op], n, reported)
+		}
+	}
+}
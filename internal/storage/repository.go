@@ -7,34 +7,43 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
 	domerrors "github.com/garyellow/ntpu-linebot-go/internal/errors"
 )
 
+// studentTable describes the students table.
+var studentTable = &entityTable[Student]{
+	name:    TableStudents,
+	label:   "student",
+	columns: []string{"id", "name", "department", "year"},
+	scan: func(s scanner) (Student, error) {
+		var student Student
+		err := s.Scan(&student.ID, &student.Name, &student.Department, &student.Year, &student.CachedAt)
+		return student, err
+	},
+	args: func(_ *DB, student *Student) ([]any, error) {
+		return []any{student.ID, student.Name, student.Department, student.Year}, nil
+	},
+	id:       func(student *Student) string { return student.ID },
+	cachedAt: func(student *Student) int64 { return student.CachedAt },
+}
+
 // SaveStudent inserts or updates a student record
 func (db *DB) SaveStudent(ctx context.Context, student *Student) error {
-	query := `
-		INSERT INTO students (id, name, department, year, cached_at)
-		VALUES (?, ?, ?, ?, ?)
-		ON CONFLICT(id) DO UPDATE SET
-			name = excluded.name,
-			department = excluded.department,
-			year = excluded.year,
-			cached_at = excluded.cached_at
-	`
 	start := time.Now()
-	_, err := db.Writer().ExecContext(ctx, query, student.ID, student.Name, student.Department, student.Year, time.Now().Unix())
-	if err != nil {
+	if err := saveEntity(ctx, db, studentTable, student); err != nil {
 		slog.ErrorContext(ctx, "Failed to save student",
 			"student_id", student.ID,
 			"error", err)
-		return fmt.Errorf("failed to save student: %w", err)
+		return err
 	}
 
-	// Warn on slow queries (>100ms)
-	if duration := time.Since(start); duration > 100*time.Millisecond {
+	// Warn on slow queries
+	if duration := time.Since(start); duration > config.SlowQueryThreshold {
 		slog.WarnContext(ctx, "Slow database operation",
 			"operation", "SaveStudent",
 			"duration_ms", duration.Milliseconds(),
@@ -50,35 +59,9 @@ func (db *DB) SaveStudentsBatch(ctx context.Context, students []*Student) error
 		return nil
 	}
 
-	query := `
-		INSERT INTO students (id, name, department, year, cached_at)
-		VALUES (?, ?, ?, ?, ?)
-		ON CONFLICT(id) DO UPDATE SET
-			name = excluded.name,
-			department = excluded.department,
-			year = excluded.year,
-			cached_at = excluded.cached_at
-	`
-
 	start := time.Now()
-	now := time.Now().Unix()
-	err := db.ExecBatchContext(ctx, query, func(stmt *sql.Stmt) error {
-		for _, student := range students {
-			cachedAt := student.CachedAt
-			if cachedAt == 0 {
-				cachedAt = now
-			}
-			if _, err := stmt.ExecContext(ctx, student.ID, student.Name, student.Department, student.Year, cachedAt); err != nil {
-				slog.ErrorContext(ctx, "Failed to save student in batch",
-					"student_id", student.ID,
-					"error", err)
-				return fmt.Errorf("failed to save student %s: %w", student.ID, err)
-			}
-		}
-		return nil
-	})
-
-	if err != nil {
+	if err := saveEntities(ctx, db, studentTable, students); err != nil {
+		slog.ErrorContext(ctx, "Failed to save students in batch", "error", err)
 		return err
 	}
 
@@ -102,42 +85,42 @@ func (db *DB) SaveStudentsBatch(ctx context.Context, students []*Student) error
 // GetStudentByID retrieves a student by ID.
 // Student data never expires; it is updated only when the cache is rebuilt (typically on startup).
 func (db *DB) GetStudentByID(ctx context.Context, id string) (*Student, error) {
-	query := `SELECT id, name, department, year, cached_at FROM students WHERE id = ?`
-
-	var student Student
-	err := db.Reader().QueryRowContext(ctx, query, id).Scan(
-		&student.ID,
-		&student.Name,
-		&student.Department,
-		&student.Year,
-		&student.CachedAt,
-	)
-
-	if err == sql.ErrNoRows {
-		return nil, nil
-	}
+	student, err := getEntity(ctx, db, studentTable, id)
 	if err != nil {
 		slog.ErrorContext(ctx, "Failed to query student",
 			"student_id", id,
 			"error", err)
-		return nil, fmt.Errorf("query student: %w", err)
+		return nil, err
 	}
-
-	return &student, nil
+	return student, nil
 }
 
+// StudentSearchLimit is the page size of SearchStudentsByName: the id module
+// lists up to 4 messages of 100 students.
+const StudentSearchLimit = 400
+
 // SearchStudentsByName searches students by partial name match using SQL filtering.
-// optimization: Uses dynamic LIKE clauses for character-set matching to avoid loading all students into memory.
-// Returns both the total count (up to limit) and results.
+// Returns the first StudentSearchLimit results and the total count of matches.
 func (db *DB) SearchStudentsByName(ctx context.Context, name string) (*StudentSearchResult, error) {
+	res, err := db.SearchStudentsByNamePage(ctx, name, Page{Limit: StudentSearchLimit})
+	if err != nil {
+		return nil, err
+	}
+	return &StudentSearchResult{Students: res.Items, TotalCount: res.TotalCount}, nil
+}
+
+// SearchStudentsByNamePage returns one page of the students whose name
+// contains every character of name, newest year first.
+// optimization: Uses dynamic LIKE clauses for character-set matching to avoid loading all students into memory.
+func (db *DB) SearchStudentsByNamePage(ctx context.Context, name string, page Page) (SearchResult[Student], error) {
 	if len(name) > 100 {
-		return nil, errors.New("search term too long")
+		return SearchResult[Student]{}, errors.New("search term too long")
 	}
 
 	start := time.Now()
 	runes := []rune(name)
 	if len(runes) == 0 {
-		return &StudentSearchResult{Students: []Student{}, TotalCount: 0}, nil
+		return newSearchResult[Student](nil, 0, 0), nil
 	}
 
 	// limit matching characters to prevent excessive SQL generation for very long strings
@@ -146,8 +129,8 @@ func (db *DB) SearchStudentsByName(ctx context.Context, name string) (*StudentSe
 	}
 
 	// Build dynamic query
-	// Base query
-	query := `SELECT id, name, department, year, cached_at FROM students WHERE 1=1`
+	// Base clause
+	clause := `WHERE 1=1`
 	args := make([]interface{}, 0, len(runes))
 
 	// Add LIKE clause for each character to match "contains all characters" (order independent)
@@ -158,266 +141,182 @@ func (db *DB) SearchStudentsByName(ctx context.Context, name string) (*StudentSe
 		whereClauses.WriteString(` AND name LIKE ? ESCAPE '\'`)
 		args = append(args, "%"+sanitizeSearchTerm(string(r))+"%")
 	}
-	query += whereClauses.String()
+	clause += whereClauses.String()
 
-	// Add ordering and limit
-	query += ` ORDER BY year DESC, id DESC LIMIT 401` // Fetch 401 to check if we hit limit (though UI limits to 400)
-
-	rows, err := db.Reader().QueryContext(ctx, query, args...)
+	res, err := searchEntities(ctx, db, studentTable, clause, `ORDER BY year DESC, id DESC`, page, args...)
 	if err != nil {
 		slog.ErrorContext(ctx, "Failed to search students",
 			"search_term", name,
 			"error", err)
-		return nil, fmt.Errorf("query students: %w", err)
-	}
-	defer func() { _ = rows.Close() }()
-
-	matchedStudents := make([]Student, 0, 400)
-	for rows.Next() {
-		var student Student
-		if err := rows.Scan(&student.ID, &student.Name, &student.Department, &student.Year, &student.CachedAt); err != nil {
-			return nil, fmt.Errorf("scan student: %w", err)
-		}
-		matchedStudents = append(matchedStudents, student)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-
-	totalCount := len(matchedStudents)
-
-	// If we fetched more than 400, cap it
-	if len(matchedStudents) > 400 {
-		matchedStudents = matchedStudents[:400]
+		return SearchResult[Student]{}, fmt.Errorf("query students: %w", err)
 	}
 
 	// Warn on slow queries
-	if duration := time.Since(start); duration > 100*time.Millisecond {
+	if duration := time.Since(start); duration > config.SlowQueryThreshold {
 		slog.WarnContext(ctx, "Slow database query",
 			"operation", "SearchStudentsByName",
 			"duration_ms", duration.Milliseconds(),
 			"search_term", name,
-			"result_count", len(matchedStudents),
-			"total_count", totalCount)
+			"result_count", len(res.Items),
+			"total_count", res.TotalCount)
 	}
 
-	return &StudentSearchResult{
-		Students:   matchedStudents,
-		TotalCount: totalCount,
-	}, nil
+	return res, nil
 }
 
 // GetCoursesByYearTermPaginated retrieves courses by year and term with pagination.
 // Only returns non-expired cache entries based on configured TTL.
 func (db *DB) GetCoursesByYearTermPaginated(ctx context.Context, year, term, limit, offset int) ([]Course, error) {
 	// Add TTL filter to prevent returning stale data
-	ttlTimestamp := db.getTTLTimestamp()
-	query := `SELECT uid, year, term, no, title, teachers, teacher_urls, times, locations, detail_url, note, cached_at
-              FROM courses
-              WHERE year = ? AND term = ? AND cached_at > ?
-              ORDER BY uid ASC
-              LIMIT ? OFFSET ?`
-
-	rows, err := db.Reader().QueryContext(ctx, query, year, term, ttlTimestamp, limit, offset)
+	courses, err := queryEntities(ctx, db, courseTable,
+		`WHERE year = ? AND term = ? AND cached_at > ? ORDER BY uid ASC LIMIT ? OFFSET ?`,
+		year, term, db.getTTLTimestamp(TableCourses), limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get courses paginated: %w", err)
 	}
-	defer func() { _ = rows.Close() }()
-
-	return scanCourses(rows)
+	return courses, nil
 }
 
 // GetStudentsByDepartment retrieves students by year and department.
 // Student data never expires; it is updated only when the cache is rebuilt (typically on startup).
 func (db *DB) GetStudentsByDepartment(ctx context.Context, dept string, year int) ([]Student, error) {
-	query := `SELECT id, name, department, year, cached_at FROM students WHERE year = ? AND department = ?`
-
-	rows, err := db.Reader().QueryContext(ctx, query, year, dept)
+	students, err := queryEntities(ctx, db, studentTable, `WHERE year = ? AND department = ?`, year, dept)
 	if err != nil {
 		return nil, fmt.Errorf("query students: %w", err)
 	}
-	defer func() { _ = rows.Close() }()
+	return students, nil
+}
 
-	var students []Student
-	for rows.Next() {
-		var student Student
-		if err := rows.Scan(&student.ID, &student.Name, &student.Department, &student.Year, &student.CachedAt); err != nil {
-			return nil, fmt.Errorf("scan student: %w", err)
-		}
-		students = append(students, student)
+// GetAllStudents retrieves every student, ordered by ID.
+// Student data never expires; it is updated only when the cache is rebuilt (typically on startup).
+func (db *DB) GetAllStudents(ctx context.Context) ([]Student, error) {
+	students, err := queryEntities(ctx, db, studentTable, `ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all students: %w", err)
 	}
+	return students, nil
+}
 
-	return students, rows.Err()
+// ForEachStudent calls fn with every student, ordered by ID, without
+// loading them all into memory. It stops at the first error from fn.
+func (db *DB) ForEachStudent(ctx context.Context, fn func(*Student) error) error {
+	if err := forEachEntity(ctx, db, studentTable, `ORDER BY id`, fn); err != nil {
+		return fmt.Errorf("failed to iterate students: %w", err)
+	}
+	return nil
 }
 
 // CountStudents returns the total number of students.
 // Student data never expires; it is updated only when the cache is rebuilt (typically on startup).
 func (db *DB) CountStudents(ctx context.Context) (int, error) {
-	query := `SELECT COUNT(*) FROM students`
-
-	var count int
-	err := db.Reader().QueryRowContext(ctx, query).Scan(&count)
-	if err != nil {
-		return 0, fmt.Errorf("failed to count students: %w", err)
-	}
-	return count, nil
+	return db.countRows(ctx, TableStudents, false)
 }
 
 // ContactRepository provides CRUD operations for contacts table
 
+// contactTable describes the contacts table.
+var contactTable = &entityTable[Contact]{
+	name:  TableContacts,
+	label: "contact",
+	columns: []string{"uid", "type", "name", "name_en", "title", "organization", "extension",
+		"phone", "email", "website", "location", "superior", "part_time"},
+	scan: func(s scanner) (Contact, error) {
+		var contact Contact
+		var nameEn, title, org, extension, phone, email, website, location, superior sql.NullString
+		var partTime sql.NullBool
+		if err := s.Scan(
+			&contact.UID,
+			&contact.Type,
+			&contact.Name,
+			&nameEn,
+			&title,
+			&org,
+			&extension,
+			&phone,
+			&email,
+			&website,
+			&location,
+			&superior,
+			&partTime,
+			&contact.CachedAt,
+		); err != nil {
+			return contact, err
+		}
+		contact.NameEn = nameEn.String
+		contact.Title = title.String
+		contact.Organization = org.String
+		contact.Extension = extension.String
+		contact.Phone = phone.String
+		contact.Email = email.String
+		contact.Website = website.String
+		contact.Location = location.String
+		contact.Superior = superior.String
+		contact.PartTime = partTime.Bool
+		return contact, nil
+	},
+	args: func(_ *DB, contact *Contact) ([]any, error) {
+		return []any{
+			contact.UID,
+			contact.Type,
+			contact.Name,
+			nullString(contact.NameEn),
+			nullString(contact.Title),
+			nullString(contact.Organization),
+			nullString(contact.Extension),
+			nullString(contact.Phone),
+			nullString(contact.Email),
+			nullString(contact.Website),
+			nullString(contact.Location),
+			nullString(contact.Superior),
+			contact.PartTime,
+		}, nil
+	},
+	id:       func(contact *Contact) string { return contact.UID },
+	cachedAt: func(contact *Contact) int64 { return contact.CachedAt },
+}
+
 // SaveContact inserts or updates a contact record
 func (db *DB) SaveContact(ctx context.Context, contact *Contact) error {
-	query := `
-		INSERT INTO contacts (uid, type, name, name_en, title, organization, extension, phone, email, website, location, superior, cached_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-		ON CONFLICT(uid) DO UPDATE SET
-			type = excluded.type,
-			name = excluded.name,
-			name_en = excluded.name_en,
-			title = excluded.title,
-			organization = excluded.organization,
-			extension = excluded.extension,
-			phone = excluded.phone,
-			email = excluded.email,
-			website = excluded.website,
-			location = excluded.location,
-			superior = excluded.superior,
-			cached_at = excluded.cached_at
-	`
-	_, err := db.Writer().ExecContext(ctx, query,
-		contact.UID,
-		contact.Type,
-		contact.Name,
-		nullString(contact.NameEn),
-		nullString(contact.Title),
-		nullString(contact.Organization),
-		nullString(contact.Extension),
-		nullString(contact.Phone),
-		nullString(contact.Email),
-		nullString(contact.Website),
-		nullString(contact.Location),
-		nullString(contact.Superior),
-		time.Now().Unix(),
-	)
-	if err != nil {
-		return fmt.Errorf("failed to save contact: %w", err)
-	}
-	return nil
+	return saveEntity(ctx, db, contactTable, contact)
 }
 
 // SaveContactsBatch inserts or updates multiple contact records in a single transaction
 // This reduces lock contention during warmup by batching writes
 func (db *DB) SaveContactsBatch(ctx context.Context, contacts []*Contact) error {
-	if len(contacts) == 0 {
-		return nil
-	}
-
-	query := `
-		INSERT INTO contacts (uid, type, name, name_en, title, organization, extension, phone, email, website, location, superior, cached_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-		ON CONFLICT(uid) DO UPDATE SET
-			type = excluded.type,
-			name = excluded.name,
-			name_en = excluded.name_en,
-			title = excluded.title,
-			organization = excluded.organization,
-			extension = excluded.extension,
-			phone = excluded.phone,
-			email = excluded.email,
-			website = excluded.website,
-			location = excluded.location,
-			superior = excluded.superior,
-			cached_at = excluded.cached_at
-	`
-
-	now := time.Now().Unix()
-	return db.ExecBatchContext(ctx, query, func(stmt *sql.Stmt) error {
-		for _, contact := range contacts {
-			cachedAt := contact.CachedAt
-			if cachedAt == 0 {
-				cachedAt = now
-			}
-			_, err := stmt.ExecContext(ctx,
-				contact.UID,
-				contact.Type,
-				contact.Name,
-				nullString(contact.NameEn),
-				nullString(contact.Title),
-				nullString(contact.Organization),
-				nullString(contact.Extension),
-				nullString(contact.Phone),
-				nullString(contact.Email),
-				nullString(contact.Website),
-				nullString(contact.Location),
-				nullString(contact.Superior),
-				cachedAt,
-			)
-			if err != nil {
-				return fmt.Errorf("failed to save contact %s: %w", contact.UID, err)
-			}
-		}
-		return nil
-	})
+	return saveEntities(ctx, db, contactTable, contacts)
 }
 
 // GetContactByUID retrieves a contact by UID and validates cache freshness
 func (db *DB) GetContactByUID(ctx context.Context, uid string) (*Contact, error) {
-	query := `SELECT uid, type, name, name_en, title, organization, extension, phone, email, website, location, superior, cached_at FROM contacts WHERE uid = ?`
-
-	var contact Contact
-	var nameEn, title, org, extension, phone, email, website, location, superior sql.NullString
-
-	err := db.Reader().QueryRowContext(ctx, query, uid).Scan(
-		&contact.UID,
-		&contact.Type,
-		&contact.Name,
-		&nameEn,
-		&title,
-		&org,
-		&extension,
-		&phone,
-		&email,
-		&website,
-		&location,
-		&superior,
-		&contact.CachedAt,
-	)
-
-	if err == sql.ErrNoRows {
-		return nil, nil
-	}
-	if err != nil {
-		return nil, fmt.Errorf("failed to get contact by UID: %w", err)
+	contact, err := getEntity(ctx, db, contactTable, uid)
+	if err != nil || contact == nil {
+		return nil, err
 	}
 
-	contact.NameEn = nameEn.String
-	contact.Title = title.String
-	contact.Organization = org.String
-	contact.Extension = extension.String
-	contact.Phone = phone.String
-	contact.Email = email.String
-	contact.Website = website.String
-	contact.Location = location.String
-	contact.Superior = superior.String
-
 	// Check TTL using configured cache duration
-	ttl := int64(db.cacheTTL.Seconds())
-	if contact.CachedAt+ttl <= time.Now().Unix() {
+	if !db.isFresh(TableContacts, contact.CachedAt) {
 		return nil, nil // Cache expired
 	}
 
-	return &contact, nil
+	return contact, nil
+}
+
+// SearchContactsByName searches contacts by partial name or title match
+// (first MaxSearchLimit results, see SearchContactsByNamePage).
+func (db *DB) SearchContactsByName(ctx context.Context, name string) ([]Contact, error) {
+	res, err := db.SearchContactsByNamePage(ctx, name, Page{Limit: MaxSearchLimit})
+	return res.Items, err
 }
 
-// SearchContactsByName searches contacts by partial name or title match (max 500 results)
+// SearchContactsByNamePage returns one page of the contacts whose name or
+// title contains name.
 // SQL searches in: name, title fields only
 // Note: The calling code may perform additional fuzzy matching on more fields (name, title, organization, superior)
 // Only returns non-expired cache entries based on configured TTL
-func (db *DB) SearchContactsByName(ctx context.Context, name string) ([]Contact, error) {
+func (db *DB) SearchContactsByNamePage(ctx context.Context, name string, page Page) (SearchResult[Contact], error) {
 	// Validate input
 	if len(name) > 100 {
-		return nil, errors.New("search term too long")
+		return SearchResult[Contact]{}, errors.New("search term too long")
 	}
 
 	// Sanitize search term to prevent SQL LIKE special character issues
@@ -425,93 +324,74 @@ func (db *DB) SearchContactsByName(ctx context.Context, name string) ([]Contact,
 
 	// Add TTL filter to prevent returning stale data
 	// Search in name and title fields
-	ttlTimestamp := db.getTTLTimestamp()
-	query := `SELECT uid, type, name, name_en, title, organization, superior, extension, phone, email, website, location, cached_at
-		FROM contacts
-		WHERE (name LIKE ? ESCAPE '\' OR title LIKE ? ESCAPE '\') AND cached_at > ?
-		ORDER BY type, name LIMIT 500`
-
 	likePattern := "%" + sanitized + "%"
-	rows, err := db.Reader().QueryContext(ctx, query, likePattern, likePattern, ttlTimestamp)
+	res, err := searchEntities(ctx, db, contactTable,
+		`WHERE (name LIKE ? ESCAPE '\' OR title LIKE ? ESCAPE '\') AND cached_at > ?`,
+		`ORDER BY type, name, uid`, page,
+		likePattern, likePattern, db.getTTLTimestamp(TableContacts))
 	if err != nil {
-		return nil, fmt.Errorf("failed to search contacts by name: %w", err)
-	}
-	defer func() { _ = rows.Close() }()
-
-	var contacts []Contact
-	for rows.Next() {
-		var contact Contact
-		var nameEn, title, org, superior, extension, phone, email, website, location sql.NullString
-
-		if err := rows.Scan(&contact.UID, &contact.Type, &contact.Name, &nameEn, &title, &org, &superior, &extension, &phone, &email, &website, &location, &contact.CachedAt); err != nil {
-			return nil, fmt.Errorf("failed to scan contact row: %w", err)
-		}
-
-		contact.NameEn = nameEn.String
-		contact.Title = title.String
-		contact.Organization = org.String
-		contact.Superior = superior.String
-		contact.Extension = extension.String
-		contact.Phone = phone.String
-		contact.Email = email.String
-		contact.Website = website.String
-		contact.Location = location.String
-
-		contacts = append(contacts, contact)
+		return SearchResult[Contact]{}, fmt.Errorf("failed to search contacts by name: %w", err)
 	}
-
-	return contacts, nil
+	return res, nil
 }
 
 // GetContactsByOrganization retrieves contacts by organization
 // Only returns non-expired cache entries based on configured TTL
 func (db *DB) GetContactsByOrganization(ctx context.Context, org string) ([]Contact, error) {
 	// Add TTL filter to prevent returning stale data
-	ttlTimestamp := db.getTTLTimestamp()
-	query := `SELECT uid, type, name, name_en, title, organization, superior, extension, phone, email, cached_at FROM contacts WHERE organization = ? AND cached_at > ?`
-
-	rows, err := db.Reader().QueryContext(ctx, query, org, ttlTimestamp)
+	contacts, err := queryEntities(ctx, db, contactTable,
+		`WHERE organization = ? AND cached_at > ?`, org, db.getTTLTimestamp(TableContacts))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get contacts by organization: %w", err)
 	}
-	defer func() { _ = rows.Close() }()
-
-	var contacts []Contact
-	for rows.Next() {
-		var contact Contact
-		var nameEn, title, org, superior, extension, phone, email sql.NullString
+	return contacts, nil
+}
 
-		if err := rows.Scan(&contact.UID, &contact.Type, &contact.Name, &nameEn, &title, &org, &superior, &extension, &phone, &email, &contact.CachedAt); err != nil {
-			return nil, fmt.Errorf("failed to scan contact row: %w", err)
-		}
+// SearchContactsByExtension retrieves contacts whose extension starts with ext
+// (first MaxSearchLimit results, see SearchContactsByExtensionPage).
+func (db *DB) SearchContactsByExtension(ctx context.Context, ext string) ([]Contact, error) {
+	res, err := db.SearchContactsByExtensionPage(ctx, ext, Page{Limit: MaxSearchLimit})
+	return res.Items, err
+}
 
-		contact.NameEn = nameEn.String
-		contact.Title = title.String
-		contact.Organization = org.String
-		contact.Superior = superior.String
-		contact.Extension = extension.String
-		contact.Phone = phone.String
-		contact.Email = email.String
+// SearchContactsByExtensionPage returns one page of the contacts whose
+// extension starts with ext (the scraped field may carry trailing text after the number).
+// Only returns non-expired cache entries based on configured TTL.
+func (db *DB) SearchContactsByExtensionPage(ctx context.Context, ext string, page Page) (SearchResult[Contact], error) {
+	if len(ext) > 100 {
+		return SearchResult[Contact]{}, errors.New("search term too long")
+	}
 
-		contacts = append(contacts, contact)
+	res, err := searchEntities(ctx, db, contactTable,
+		`WHERE extension LIKE ? ESCAPE '\' AND cached_at > ?`,
+		`ORDER BY type, name, uid`, page,
+		sanitizeSearchTerm(ext)+"%", db.getTTLTimestamp(TableContacts))
+	if err != nil {
+		return SearchResult[Contact]{}, fmt.Errorf("failed to search contacts by extension: %w", err)
 	}
+	return res, nil
+}
 
-	return contacts, nil
+// SearchContactsFuzzy searches contacts using SQL-level character-set matching
+// (first MaxSearchLimit results, see SearchContactsFuzzyPage).
+func (db *DB) SearchContactsFuzzy(ctx context.Context, term string) ([]Contact, error) {
+	res, err := db.SearchContactsFuzzyPage(ctx, term, Page{Limit: MaxSearchLimit})
+	return res.Items, err
 }
 
-// SearchContactsFuzzy searches contacts using SQL-level character-set matching.
+// SearchContactsFuzzyPage returns one page of a character-set contact search.
 // Optimization: Uses dynamic LIKE clauses for character matching instead of loading all contacts.
 // Searches in: name, title, organization, superior fields.
 // Each character in the search term must appear in at least one of the searched fields.
-// Only returns non-expired cache entries based on configured TTL (max 500 results).
-func (db *DB) SearchContactsFuzzy(ctx context.Context, term string) ([]Contact, error) {
+// Only returns non-expired cache entries based on configured TTL.
+func (db *DB) SearchContactsFuzzyPage(ctx context.Context, term string, page Page) (SearchResult[Contact], error) {
 	if len(term) > 100 {
-		return nil, errors.New("search term too long")
+		return SearchResult[Contact]{}, errors.New("search term too long")
 	}
 
 	runes := []rune(term)
 	if len(runes) == 0 {
-		return []Contact{}, nil
+		return newSearchResult[Contact](nil, 0, 0), nil
 	}
 
 	// Limit matching characters to prevent excessive SQL generation
@@ -519,13 +399,10 @@ func (db *DB) SearchContactsFuzzy(ctx context.Context, term string) ([]Contact,
 		runes = runes[:10]
 	}
 
-	ttlTimestamp := db.getTTLTimestamp()
-
-	// Build dynamic query with LIKE clauses for each character
+	// Build dynamic clause with LIKE clauses for each character
 	// Each character must appear in at least one of the searchable fields
-	query := `SELECT uid, type, name, name_en, title, organization, extension, phone, email, website, location, superior, cached_at
-		FROM contacts WHERE cached_at > ?`
-	args := []interface{}{ttlTimestamp}
+	clause := `WHERE cached_at > ?`
+	args := []interface{}{db.getTTLTimestamp(TableContacts)}
 
 	var whereClauses strings.Builder
 	for _, r := range runes {
@@ -533,117 +410,94 @@ func (db *DB) SearchContactsFuzzy(ctx context.Context, term string) ([]Contact,
 		whereClauses.WriteString(` AND (name LIKE ? ESCAPE '\' OR title LIKE ? ESCAPE '\' OR organization LIKE ? ESCAPE '\' OR superior LIKE ? ESCAPE '\')`)
 		args = append(args, pattern, pattern, pattern, pattern)
 	}
-	query += whereClauses.String()
-
-	query += ` ORDER BY type, name LIMIT 500`
+	clause += whereClauses.String()
 
-	rows, err := db.Reader().QueryContext(ctx, query, args...)
+	res, err := searchEntities(ctx, db, contactTable, clause, `ORDER BY type, name, uid`, page, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fuzzy search contacts: %w", err)
-	}
-	defer func() { _ = rows.Close() }()
-
-	var contacts []Contact
-	for rows.Next() {
-		var contact Contact
-		var nameEn, title, org, extension, phone, email, website, location, superior sql.NullString
-
-		if err := rows.Scan(&contact.UID, &contact.Type, &contact.Name, &nameEn, &title, &org, &extension, &phone, &email, &website, &location, &superior, &contact.CachedAt); err != nil {
-			return nil, fmt.Errorf("failed to scan contact row: %w", err)
-		}
-
-		contact.NameEn = nameEn.String
-		contact.Title = title.String
-		contact.Organization = org.String
-		contact.Extension = extension.String
-		contact.Phone = phone.String
-		contact.Email = email.String
-		contact.Website = website.String
-		contact.Location = location.String
-		contact.Superior = superior.String
-
-		contacts = append(contacts, contact)
+		return SearchResult[Contact]{}, fmt.Errorf("failed to fuzzy search contacts: %w", err)
 	}
-
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("rows error: %w", err)
-	}
-
-	return contacts, nil
+	return res, nil
 }
 
 // DeleteExpiredContacts removes contacts older than the specified TTL
 // Returns the number of deleted entries
 func (db *DB) DeleteExpiredContacts(ctx context.Context, ttl time.Duration) (int64, error) {
-	query := `DELETE FROM contacts WHERE cached_at < ?`
-	expiryTime := time.Now().Add(-ttl).Unix()
+	return db.deleteExpiredRows(ctx, TableContacts, ttl)
+}
 
-	result, err := db.Writer().ExecContext(ctx, query, expiryTime)
+// GetAllContacts retrieves every contact, ordered by UID
+// Only returns non-expired cache entries based on configured TTL
+func (db *DB) GetAllContacts(ctx context.Context) ([]Contact, error) {
+	contacts, err := queryEntities(ctx, db, contactTable,
+		`WHERE cached_at > ? ORDER BY uid`, db.getTTLTimestamp(TableContacts))
 	if err != nil {
-		return 0, fmt.Errorf("failed to delete expired contacts: %w", err)
+		return nil, fmt.Errorf("failed to get all contacts: %w", err)
 	}
+	return contacts, nil
+}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return 0, fmt.Errorf("failed to get rows affected for contacts: %w", err)
+// ForEachContact calls fn with every non-expired contact, ordered by UID,
+// without loading them all into memory. It stops at the first error from fn.
+func (db *DB) ForEachContact(ctx context.Context, fn func(*Contact) error) error {
+	if err := forEachEntity(ctx, db, contactTable,
+		`WHERE cached_at > ? ORDER BY uid`, fn, db.getTTLTimestamp(TableContacts)); err != nil {
+		return fmt.Errorf("failed to iterate contacts: %w", err)
 	}
-	return rowsAffected, nil
+	return nil
 }
 
 // CountContacts returns the total number of contacts
 func (db *DB) CountContacts(ctx context.Context) (int, error) {
-	ttlTimestamp := db.getTTLTimestamp()
-	query := `SELECT COUNT(*) FROM contacts WHERE cached_at > ?`
-
-	var count int
-	err := db.Reader().QueryRowContext(ctx, query, ttlTimestamp).Scan(&count)
-	if err != nil {
-		return 0, fmt.Errorf("failed to count contacts: %w", err)
-	}
-	return count, nil
+	return db.countRows(ctx, TableContacts, true)
 }
 
 // CourseRepository provides CRUD operations for courses table
 
-// SaveCourse inserts or updates a course record (serializes arrays as JSON)
-func (db *DB) SaveCourse(ctx context.Context, course *Course) error {
+// courseTable and historicalCourseTable describe the courses and
+// historical_courses tables, which share one layout.
+var (
+	courseTable           = newCourseTable(TableCourses, "course")
+	historicalCourseTable = newCourseTable(TableHistoricalCourses, "historical course")
+)
+
+// newCourseTable describes a table with the courses layout. Array fields are
+// stored as JSON.
+func newCourseTable(name, label string) *entityTable[Course] {
+	return &entityTable[Course]{
+		name:  name,
+		label: label,
+		columns: []string{"uid", "year", "term", "no", "title", "teachers", "teacher_urls",
+			"times", "locations", "detail_url", "note", "title_en"},
+		scan:     scanCourse,
+		args:     courseArgs,
+		id:       func(course *Course) string { return course.UID },
+		cachedAt: func(course *Course) int64 { return course.CachedAt },
+	}
+}
+
+// courseArgs returns the column values of course, serializing arrays as JSON.
+func courseArgs(_ *DB, course *Course) ([]any, error) {
 	teachersJSON, err := json.Marshal(course.Teachers)
 	if err != nil {
-		return fmt.Errorf("failed to marshal teachers: %w", err)
+		return nil, fmt.Errorf("marshal teachers: %w", err)
 	}
 
 	teacherURLsJSON, err := json.Marshal(course.TeacherURLs)
 	if err != nil {
-		return fmt.Errorf("failed to marshal teacher URLs: %w", err)
+		return nil, fmt.Errorf("marshal teacher URLs: %w", err)
 	}
 
 	timesJSON, err := json.Marshal(course.Times)
 	if err != nil {
-		return fmt.Errorf("failed to marshal times: %w", err)
+		return nil, fmt.Errorf("marshal times: %w", err)
 	}
 
 	locationsJSON, err := json.Marshal(course.Locations)
 	if err != nil {
-		return fmt.Errorf("failed to marshal locations: %w", err)
+		return nil, fmt.Errorf("marshal locations: %w", err)
 	}
 
-	query := `
-		INSERT INTO courses (uid, year, term, no, title, teachers, teacher_urls, times, locations, detail_url, note, cached_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-		ON CONFLICT(uid) DO UPDATE SET
-			year = excluded.year,
-			term = excluded.term,
-			no = excluded.no,
-			title = excluded.title,
-			teachers = excluded.teachers,
-			teacher_urls = excluded.teacher_urls,
-			times = excluded.times,
-			locations = excluded.locations,
-			detail_url = excluded.detail_url,
-			note = excluded.note,
-			cached_at = excluded.cached_at
-	`
-	_, err = db.Writer().ExecContext(ctx, query,
+	return []any{
 		course.UID,
 		course.Year,
 		course.Term,
@@ -655,97 +509,17 @@ func (db *DB) SaveCourse(ctx context.Context, course *Course) error {
 		string(locationsJSON),
 		nullString(course.DetailURL),
 		nullString(course.Note),
-		time.Now().Unix(),
-	)
-	if err != nil {
-		return fmt.Errorf("failed to save course: %w", err)
-	}
-	return nil
-}
-
-// SaveCoursesBatch inserts or updates multiple course records in a single transaction
-// This reduces lock contention during warmup by batching writes
-func (db *DB) SaveCoursesBatch(ctx context.Context, courses []*Course) error {
-	if len(courses) == 0 {
-		return nil
-	}
-
-	query := `
-		INSERT INTO courses (uid, year, term, no, title, teachers, teacher_urls, times, locations, detail_url, note, cached_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-		ON CONFLICT(uid) DO UPDATE SET
-			year = excluded.year,
-			term = excluded.term,
-			no = excluded.no,
-			title = excluded.title,
-			teachers = excluded.teachers,
-			teacher_urls = excluded.teacher_urls,
-			times = excluded.times,
-			locations = excluded.locations,
-			detail_url = excluded.detail_url,
-			note = excluded.note,
-			cached_at = excluded.cached_at
-	`
-
-	now := time.Now().Unix()
-	return db.ExecBatchContext(ctx, query, func(stmt *sql.Stmt) error {
-		for _, course := range courses {
-			cachedAt := course.CachedAt
-			if cachedAt == 0 {
-				cachedAt = now
-			}
-
-			teachersJSON, err := json.Marshal(course.Teachers)
-			if err != nil {
-				return fmt.Errorf("failed to marshal teachers for course %s: %w", course.UID, err)
-			}
-
-			teacherURLsJSON, err := json.Marshal(course.TeacherURLs)
-			if err != nil {
-				return fmt.Errorf("failed to marshal teacher URLs for course %s: %w", course.UID, err)
-			}
-
-			timesJSON, err := json.Marshal(course.Times)
-			if err != nil {
-				return fmt.Errorf("failed to marshal times for course %s: %w", course.UID, err)
-			}
-
-			locationsJSON, err := json.Marshal(course.Locations)
-			if err != nil {
-				return fmt.Errorf("failed to marshal locations for course %s: %w", course.UID, err)
-			}
-
-			_, err = stmt.ExecContext(ctx,
-				course.UID,
-				course.Year,
-				course.Term,
-				course.No,
-				course.Title,
-				string(teachersJSON),
-				string(teacherURLsJSON),
-				string(timesJSON),
-				string(locationsJSON),
-				nullString(course.DetailURL),
-				nullString(course.Note),
-				cachedAt,
-			)
-			if err != nil {
-				return fmt.Errorf("failed to save course %s: %w", course.UID, err)
-			}
-		}
-		return nil
-	})
+		nullString(course.TitleEn),
+	}, nil
 }
 
-// GetCourseByUID retrieves a course by UID and validates cache freshness
-func (db *DB) GetCourseByUID(ctx context.Context, uid string) (*Course, error) {
-	query := `SELECT uid, year, term, no, title, teachers, teacher_urls, times, locations, detail_url, note, cached_at FROM courses WHERE uid = ?`
-
+// scanCourse scans one row with the courses columns, deserializing arrays.
+func scanCourse(s scanner) (Course, error) {
 	var course Course
 	var teachersJSON, teacherURLsJSON, timesJSON, locationsJSON string
-	var detailURL, note sql.NullString
+	var detailURL, note, titleEn sql.NullString
 
-	err := db.Reader().QueryRowContext(ctx, query, uid).Scan(
+	if err := s.Scan(
 		&course.UID,
 		&course.Year,
 		&course.Term,
@@ -757,102 +531,205 @@ func (db *DB) GetCourseByUID(ctx context.Context, uid string) (*Course, error) {
 		&locationsJSON,
 		&detailURL,
 		&note,
+		&titleEn,
 		&course.CachedAt,
-	)
-
-	if err == sql.ErrNoRows {
-		return nil, nil
-	}
-	if err != nil {
-		return nil, fmt.Errorf("failed to get course by UID: %w", err)
+	); err != nil {
+		return course, err
 	}
 
 	course.DetailURL = detailURL.String
 	course.Note = note.String
+	course.TitleEn = titleEn.String
 
 	// Deserialize JSON arrays
 	if err := json.Unmarshal([]byte(teachersJSON), &course.Teachers); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal teachers: %w", err)
+		return course, fmt.Errorf("failed to unmarshal teachers: %w", err)
 	}
 	if err := json.Unmarshal([]byte(teacherURLsJSON), &course.TeacherURLs); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal teacher URLs: %w", err)
+		return course, fmt.Errorf("failed to unmarshal teacher URLs: %w", err)
 	}
 	if err := json.Unmarshal([]byte(timesJSON), &course.Times); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal times: %w", err)
+		return course, fmt.Errorf("failed to unmarshal times: %w", err)
 	}
 	if err := json.Unmarshal([]byte(locationsJSON), &course.Locations); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal locations: %w", err)
+		return course, fmt.Errorf("failed to unmarshal locations: %w", err)
 	}
+	return course, nil
+}
 
-	// Check TTL using configured cache duration
-	ttl := int64(db.cacheTTL.Seconds())
-	if course.CachedAt+ttl <= time.Now().Unix() {
+// CourseWrites returns a counter bumped by every write to the courses table
+// (saves and expiry cleanup), so results derived from courses in memory can
+// tell they may be stale by comparing it with the value they were built at.
+func (db *DB) CourseWrites() uint64 {
+	return db.courseWrites.Load()
+}
+
+// SaveCourse inserts or updates a course record (serializes arrays as JSON),
+// links its teachers in course_teachers and stores its note flags, all in one
+// transaction.
+func (db *DB) SaveCourse(ctx context.Context, course *Course) error {
+	defer db.courseWrites.Add(1)
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		if err := saveEntity(ctx, db, courseTable, course); err != nil {
+			return err
+		}
+		if err := db.SaveCourseTeachersBatch(ctx, []*Course{course}); err != nil {
+			return err
+		}
+		if err := db.SaveCourseFlagsBatch(ctx, []*Course{course}); err != nil {
+			return err
+		}
+		return db.SaveCourseSectionsBatch(ctx, []*Course{course})
+	})
+}
+
+// SaveCoursesBatch inserts or updates multiple course records in a single transaction
+// This reduces lock contention during warmup by batching writes
+// Teacher links, note flags and section keys are saved in the same transaction
+// via SaveCourseTeachersBatch, SaveCourseFlagsBatch and SaveCourseSectionsBatch.
+func (db *DB) SaveCoursesBatch(ctx context.Context, courses []*Course) error {
+	if len(courses) == 0 {
+		return nil
+	}
+
+	defer db.courseWrites.Add(1)
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		if err := saveEntities(ctx, db, courseTable, courses); err != nil {
+			return err
+		}
+		if err := db.SaveCourseTeachersBatch(ctx, courses); err != nil {
+			return err
+		}
+		if err := db.SaveCourseFlagsBatch(ctx, courses); err != nil {
+			return err
+		}
+		return db.SaveCourseSectionsBatch(ctx, courses)
+	})
+}
+
+// GetCourseByUID retrieves a course by UID and validates cache freshness
+func (db *DB) GetCourseByUID(ctx context.Context, uid string) (*Course, error) {
+	course, err := getEntity(ctx, db, courseTable, uid)
+	if err != nil || course == nil {
+		return nil, err
+	}
+
+	// Check TTL using configured cache duration (and TTL policy)
+	if course.CachedAt <= db.lookupTTLTimestamp(TableCourses) {
 		return nil, nil // Cache expired
 	}
 
-	return &course, nil
+	return course, nil
 }
 
-// SearchCoursesByTitle searches courses by partial title match (max 500 results)
-// Only returns non-expired cache entries based on configured TTL
+// GetCoursesByUIDs retrieves the non-expired courses with the given UIDs in
+// the order of uids. UIDs without a fresh row are skipped.
+func (db *DB) GetCoursesByUIDs(ctx context.Context, uids []string) ([]Course, error) {
+	if len(uids) == 0 {
+		return nil, nil
+	}
+
+	placeholders := strings.Repeat("?,", len(uids))
+	placeholders = placeholders[:len(placeholders)-1]
+	args := make([]any, 0, len(uids)+1)
+	for _, uid := range uids {
+		args = append(args, uid)
+	}
+	args = append(args, db.getTTLTimestamp(TableCourses))
+
+	courses, err := queryEntities(ctx, db, courseTable,
+		`WHERE uid IN (`+placeholders+`) AND cached_at > ?`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get courses by UIDs: %w", err)
+	}
+
+	order := make(map[string]int, len(uids))
+	for i, uid := range uids {
+		order[uid] = i
+	}
+	slices.SortFunc(courses, func(a, b Course) int { return order[a.UID] - order[b.UID] })
+	return courses, nil
+}
+
+// SearchCoursesByTitle searches courses by partial match of the title or the
+// English title (first MaxSearchLimit results, see SearchCoursesByTitlePage).
 func (db *DB) SearchCoursesByTitle(ctx context.Context, title string) ([]Course, error) {
+	res, err := db.SearchCoursesByTitlePage(ctx, title, Page{Limit: MaxSearchLimit})
+	return res.Items, err
+}
+
+// SearchCoursesByTitlePage returns one page of the courses whose title or
+// English title contains title (LIKE ignores ASCII case), newest semester first.
+// Only returns non-expired cache entries based on configured TTL
+func (db *DB) SearchCoursesByTitlePage(ctx context.Context, title string, page Page) (SearchResult[Course], error) {
 	// Validate input
 	if len(title) > 100 {
-		return nil, errors.New("search term too long")
+		return SearchResult[Course]{}, errors.New("search term too long")
 	}
 
 	// Sanitize search term to prevent SQL LIKE special character issues
 	sanitized := sanitizeSearchTerm(title)
 
 	// Add TTL filter to prevent returning stale data
-	ttlTimestamp := db.getTTLTimestamp()
-	query := `SELECT uid, year, term, no, title, teachers, teacher_urls, times, locations, detail_url, note, cached_at FROM courses WHERE title LIKE ? ESCAPE '\' AND cached_at > ? ORDER BY year DESC, term DESC LIMIT 500`
-
-	rows, err := db.Reader().QueryContext(ctx, query, "%"+sanitized+"%", ttlTimestamp)
+	res, err := searchEntities(ctx, db, courseTable,
+		`WHERE (title LIKE ? ESCAPE '\' OR title_en LIKE ? ESCAPE '\') AND cached_at > ?`,
+		`ORDER BY year DESC, term DESC, uid`, page,
+		"%"+sanitized+"%", "%"+sanitized+"%", db.lookupTTLTimestamp(TableCourses))
 	if err != nil {
-		return nil, fmt.Errorf("failed to search courses by title: %w", err)
+		return SearchResult[Course]{}, fmt.Errorf("failed to search courses by title: %w", err)
 	}
-	defer func() { _ = rows.Close() }()
+	return res, nil
+}
 
-	return scanCourses(rows)
+// SearchCoursesByTeacher searches courses by teacher name
+// (first MaxSearchLimit results, see SearchCoursesByTeacherPage).
+func (db *DB) SearchCoursesByTeacher(ctx context.Context, teacher string) ([]Course, error) {
+	res, err := db.SearchCoursesByTeacherPage(ctx, teacher, Page{Limit: MaxSearchLimit})
+	return res.Items, err
 }
 
-// SearchCoursesByTeacher searches courses by teacher name (max 500 results)
+// SearchCoursesByTeacherPage returns one page of the courses whose teachers
+// contain teacher, newest semester first.
 // Only returns non-expired cache entries based on configured TTL
-func (db *DB) SearchCoursesByTeacher(ctx context.Context, teacher string) ([]Course, error) {
+func (db *DB) SearchCoursesByTeacherPage(ctx context.Context, teacher string, page Page) (SearchResult[Course], error) {
 	// Validate input
 	if len(teacher) > 100 {
-		return nil, errors.New("search term too long")
+		return SearchResult[Course]{}, errors.New("search term too long")
 	}
 
 	// Sanitize search term to prevent SQL LIKE special character issues
 	sanitized := sanitizeSearchTerm(teacher)
 
 	// Add TTL filter to prevent returning stale data
-	ttlTimestamp := db.getTTLTimestamp()
-	query := `SELECT uid, year, term, no, title, teachers, teacher_urls, times, locations, detail_url, note, cached_at FROM courses WHERE teachers LIKE ? ESCAPE '\' AND cached_at > ? ORDER BY year DESC, term DESC LIMIT 500`
-
-	rows, err := db.Reader().QueryContext(ctx, query, "%"+sanitized+"%", ttlTimestamp)
+	res, err := searchEntities(ctx, db, courseTable,
+		`WHERE teachers LIKE ? ESCAPE '\' AND cached_at > ?`,
+		`ORDER BY year DESC, term DESC, uid`, page,
+		"%"+sanitized+"%", db.lookupTTLTimestamp(TableCourses))
 	if err != nil {
-		return nil, fmt.Errorf("failed to search courses by teacher: %w", err)
+		return SearchResult[Course]{}, fmt.Errorf("failed to search courses by teacher: %w", err)
 	}
-	defer func() { _ = rows.Close() }()
+	return res, nil
+}
 
-	return scanCourses(rows)
+// SearchCoursesByTeacherFuzzy searches courses using SQL-level character-set matching on teacher names
+// (first MaxSearchLimit results, see SearchCoursesByTeacherFuzzyPage).
+func (db *DB) SearchCoursesByTeacherFuzzy(ctx context.Context, teacherName string) ([]Course, error) {
+	res, err := db.SearchCoursesByTeacherFuzzyPage(ctx, teacherName, Page{Limit: MaxSearchLimit})
+	return res.Items, err
 }
 
-// SearchCoursesByTeacherFuzzy searches courses using SQL-level character-set matching on teacher names.
+// SearchCoursesByTeacherFuzzyPage returns one page of a character-set search on teacher names.
 // Optimization: Uses dynamic LIKE clauses for character matching instead of loading all courses into memory.
 // Each character in the search term must appear in the teachers JSON field.
-// Only returns non-expired cache entries based on configured TTL (max 500 results).
-func (db *DB) SearchCoursesByTeacherFuzzy(ctx context.Context, teacherName string) ([]Course, error) {
+// Only returns non-expired cache entries based on configured TTL.
+func (db *DB) SearchCoursesByTeacherFuzzyPage(ctx context.Context, teacherName string, page Page) (SearchResult[Course], error) {
 	if len(teacherName) > 100 {
-		return nil, errors.New("search term too long")
+		return SearchResult[Course]{}, errors.New("search term too long")
 	}
 
 	runes := []rune(teacherName)
 	if len(runes) == 0 {
-		return []Course{}, nil
+		return newSearchResult[Course](nil, 0, 0), nil
 	}
 
 	// Limit matching characters to prevent excessive SQL generation (Chinese names typically 2-4 chars)
@@ -860,13 +737,10 @@ func (db *DB) SearchCoursesByTeacherFuzzy(ctx context.Context, teacherName strin
 		runes = runes[:10]
 	}
 
-	ttlTimestamp := db.getTTLTimestamp()
-
-	// Build dynamic query with LIKE clauses for each character
+	// Build dynamic clause with LIKE clauses for each character
 	// Each character must appear in the teachers JSON field
-	query := `SELECT uid, year, term, no, title, teachers, teacher_urls, times, locations, detail_url, note, cached_at
-		FROM courses WHERE cached_at > ?`
-	args := []interface{}{ttlTimestamp}
+	clause := `WHERE cached_at > ?`
+	args := []interface{}{db.lookupTTLTimestamp(TableCourses)}
 
 	var whereClauses strings.Builder
 	for _, r := range runes {
@@ -874,48 +748,40 @@ func (db *DB) SearchCoursesByTeacherFuzzy(ctx context.Context, teacherName strin
 		whereClauses.WriteString(` AND teachers LIKE ? ESCAPE '\'`)
 		args = append(args, pattern)
 	}
-	query += whereClauses.String()
-
-	query += ` ORDER BY year DESC, term DESC LIMIT 500`
+	clause += whereClauses.String()
 
-	rows, err := db.Reader().QueryContext(ctx, query, args...)
+	res, err := searchEntities(ctx, db, courseTable, clause, `ORDER BY year DESC, term DESC, uid`, page, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fuzzy search courses by teacher: %w", err)
+		return SearchResult[Course]{}, fmt.Errorf("failed to fuzzy search courses by teacher: %w", err)
 	}
-	defer func() { _ = rows.Close() }()
-
-	return scanCourses(rows)
+	return res, nil
 }
 
 // GetCoursesByYearTerm retrieves courses by year and term
 // Only returns non-expired cache entries based on configured TTL
 func (db *DB) GetCoursesByYearTerm(ctx context.Context, year, term int) ([]Course, error) {
 	// Add TTL filter to prevent returning stale data
-	ttlTimestamp := db.getTTLTimestamp()
-	query := `SELECT uid, year, term, no, title, teachers, teacher_urls, times, locations, detail_url, note, cached_at FROM courses WHERE year = ? AND term = ? AND cached_at > ?`
-
-	rows, err := db.Reader().QueryContext(ctx, query, year, term, ttlTimestamp)
+	courses, err := queryEntities(ctx, db, courseTable,
+		`WHERE year = ? AND term = ? AND cached_at > ?`, year, term, db.getTTLTimestamp(TableCourses))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get courses by year and term: %w", err)
 	}
-	defer func() { _ = rows.Close() }()
-
-	return scanCourses(rows)
+	return courses, nil
 }
 
 // GetDistinctRecentSemesters retrieves the most recent 2 distinct semesters (year, term pairs)
 // from courses that have cached data. Returns semesters ordered by year DESC, term DESC.
 // Used by syllabus warmup to determine which semesters need BM25 indexing.
 func (db *DB) GetDistinctRecentSemesters(ctx context.Context, limit int) ([]struct{ Year, Term int }, error) {
-	ttlTimestamp := db.getTTLTimestamp()
+	ttlTimestamp := db.getTTLTimestamp(TableCourses)
 
 	query := `SELECT DISTINCT year, term
 		FROM courses
-		WHERE cached_at > ?
+		WHERE tenant = ? AND cached_at > ?
 		ORDER BY year DESC, term DESC
 		LIMIT ?`
 
-	rows, err := db.Reader().QueryContext(ctx, query, ttlTimestamp, limit)
+	rows, err := db.Reader().QueryContext(ctx, query, db.Tenant(), ttlTimestamp, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get distinct recent semesters: %w", err)
 	}
@@ -942,61 +808,77 @@ func (db *DB) GetDistinctRecentSemesters(ctx context.Context, limit int) ([]stru
 // Only returns non-expired cache entries based on configured TTL (7-day cache for courses)
 // Returns ALL courses with valid cache entries, regardless of which semesters are currently cached
 func (db *DB) GetCoursesByRecentSemesters(ctx context.Context) ([]Course, error) {
-	ttlTimestamp := db.getTTLTimestamp()
+	// Get all courses from recent semesters ordered by semester (year DESC, term DESC)
+	// This returns all courses with cached_at > TTL threshold, typically from the 4 most recent semesters
+	courses, err := queryEntities(ctx, db, courseTable,
+		`WHERE cached_at > ? ORDER BY year DESC, term DESC`, db.getTTLTimestamp(TableCourses))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get courses by recent semesters: %w", err)
+	}
+	return courses, nil
+}
 
-	// Get all courses from recent semesters ordered by semester (year DESC, term DESC)
-	// This returns all courses with cached_at > TTL threshold, typically from the 4 most recent semesters
-	query := `SELECT uid, year, term, no, title, teachers, teacher_urls, times, locations, detail_url, note, cached_at
-		FROM courses WHERE cached_at > ? ORDER BY year DESC, term DESC`
+// CourseFilter selects the courses visited by ForEachCourse.
+// Zero fields match every course.
+type CourseFilter struct {
+	Year   int      // Academic year (ROC)
+	Term   int      // 1 or 2
+	Majors []string // Exact 應修系級 values (e.g., "資工系1"), not prefixes
+}
 
-	rows, err := db.Reader().QueryContext(ctx, query, ttlTimestamp)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get courses by recent semesters: %w", err)
+// ForEachCourse calls fn with every non-expired course matching f, newest
+// semester first and by course number within a semester, without loading
+// them all into memory. It stops at the first error from fn.
+func (db *DB) ForEachCourse(ctx context.Context, f CourseFilter, fn func(*Course) error) error {
+	where := []string{"cached_at > ?"}
+	args := []any{db.getTTLTimestamp(TableCourses)}
+	if f.Year != 0 {
+		where = append(where, "year = ?")
+		args = append(args, f.Year)
+	}
+	if f.Term != 0 {
+		where = append(where, "term = ?")
+		args = append(args, f.Term)
+	}
+	if len(f.Majors) > 0 {
+		placeholders := strings.Repeat("?,", len(f.Majors))
+		where = append(where, `uid IN (SELECT course_uid FROM course_majors WHERE tenant = ? AND major IN (`+placeholders[:len(placeholders)-1]+`))`)
+		args = append(args, db.Tenant())
+		for _, m := range f.Majors {
+			args = append(args, m)
+		}
 	}
-	defer func() { _ = rows.Close() }()
 
-	return scanCourses(rows)
+	clause := "WHERE " + strings.Join(where, " AND ") + " ORDER BY year DESC, term DESC, no"
+	if err := forEachEntity(ctx, db, courseTable, clause, fn, args...); err != nil {
+		return fmt.Errorf("failed to iterate courses: %w", err)
+	}
+	return nil
 }
 
 // DeleteExpiredCourses removes courses older than the specified TTL
 // Returns the number of deleted entries
 func (db *DB) DeleteExpiredCourses(ctx context.Context, ttl time.Duration) (int64, error) {
-	query := `DELETE FROM courses WHERE cached_at < ?`
-	expiryTime := time.Now().Add(-ttl).Unix()
-
-	result, err := db.Writer().ExecContext(ctx, query, expiryTime)
-	if err != nil {
-		return 0, fmt.Errorf("failed to delete expired courses: %w", err)
-	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return 0, fmt.Errorf("failed to get rows affected for courses: %w", err)
+	deleted, err := db.deleteExpiredRows(ctx, TableCourses, ttl)
+	if deleted > 0 {
+		db.courseWrites.Add(1)
 	}
-	return rowsAffected, nil
+	return deleted, err
 }
 
 // CountCourses returns the total number of courses
 func (db *DB) CountCourses(ctx context.Context) (int, error) {
-	ttlTimestamp := db.getTTLTimestamp()
-	query := `SELECT COUNT(*) FROM courses WHERE cached_at > ?`
-
-	var count int
-	err := db.Reader().QueryRowContext(ctx, query, ttlTimestamp).Scan(&count)
-	if err != nil {
-		return 0, fmt.Errorf("failed to count courses: %w", err)
-	}
-	return count, nil
+	return db.countRows(ctx, TableCourses, true)
 }
 
 // CountCoursesBySemester returns the number of courses for a specific semester
 // Returns 0 if no courses found (not an error)
 func (db *DB) CountCoursesBySemester(ctx context.Context, year, term int) (int, error) {
-	ttlTimestamp := db.getTTLTimestamp()
-	query := `SELECT COUNT(*) FROM courses WHERE year = ? AND term = ? AND cached_at > ?`
+	ttlTimestamp := db.getTTLTimestamp(TableCourses)
+	query := `SELECT COUNT(*) FROM courses WHERE tenant = ? AND year = ? AND term = ? AND cached_at > ?`
 
 	var count int
-	err := db.Reader().QueryRowContext(ctx, query, year, term, ttlTimestamp).Scan(&count)
+	err := db.Reader().QueryRowContext(ctx, query, db.Tenant(), year, term, ttlTimestamp).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count courses by semester: %w", err)
 	}
@@ -1015,110 +897,47 @@ func nullString(s string) sql.NullString {
 
 // scanCourses is a helper to scan multiple course rows
 func scanCourses(rows *sql.Rows) ([]Course, error) {
-	var courses []Course
-
-	for rows.Next() {
-		var course Course
-		var teachersJSON, teacherURLsJSON, timesJSON, locationsJSON string
-		var detailURL, note sql.NullString
-
-		if err := rows.Scan(
-			&course.UID,
-			&course.Year,
-			&course.Term,
-			&course.No,
-			&course.Title,
-			&teachersJSON,
-			&teacherURLsJSON,
-			&timesJSON,
-			&locationsJSON,
-			&detailURL,
-			&note,
-			&course.CachedAt,
-		); err != nil {
-			return nil, fmt.Errorf("failed to scan course row: %w", err)
-		}
-
-		course.DetailURL = detailURL.String
-		course.Note = note.String
-
-		// Deserialize JSON arrays
-		if err := json.Unmarshal([]byte(teachersJSON), &course.Teachers); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal teachers: %w", err)
-		}
-		if err := json.Unmarshal([]byte(teacherURLsJSON), &course.TeacherURLs); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal teacher URLs: %w", err)
-		}
-		if err := json.Unmarshal([]byte(timesJSON), &course.Times); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal times: %w", err)
-		}
-		if err := json.Unmarshal([]byte(locationsJSON), &course.Locations); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal locations: %w", err)
-		}
-
-		courses = append(courses, course)
-	}
-
-	return courses, nil
+	return scanAll(rows, courseTable)
 }
 
 // StickerRepository provides CRUD operations for stickers table
 
+// stickerTable describes the stickers table.
+var stickerTable = &entityTable[Sticker]{
+	name:    tableStickers,
+	shared:  true,
+	label:   "sticker",
+	columns: []string{"url", "source"},
+	scan: func(s scanner) (Sticker, error) {
+		var sticker Sticker
+		err := s.Scan(&sticker.URL, &sticker.Source, &sticker.CachedAt)
+		return sticker, err
+	},
+	args: func(_ *DB, sticker *Sticker) ([]any, error) {
+		return []any{sticker.URL, sticker.Source}, nil
+	},
+	id: func(sticker *Sticker) string { return sticker.URL },
+}
+
 // SaveSticker inserts or updates a sticker record
 func (db *DB) SaveSticker(ctx context.Context, sticker *Sticker) error {
-	query := `
-		INSERT INTO stickers (url, source, cached_at)
-		VALUES (?, ?, ?)
-		ON CONFLICT(url) DO UPDATE SET
-			source = excluded.source,
-			cached_at = excluded.cached_at
-	`
-	_, err := db.Writer().ExecContext(ctx, query,
-		sticker.URL,
-		sticker.Source,
-		time.Now().Unix(),
-	)
-	if err != nil {
-		return fmt.Errorf("failed to save sticker: %w", err)
-	}
-	return nil
+	return saveEntity(ctx, db, stickerTable, sticker)
 }
 
 // GetAllStickers retrieves all stickers from database.
 // Sticker data never expires; it is loaded on startup and updated only by explicit refresh.
 func (db *DB) GetAllStickers(ctx context.Context) ([]Sticker, error) {
-	query := `SELECT url, source, cached_at FROM stickers`
-
-	rows, err := db.Reader().QueryContext(ctx, query)
+	stickers, err := queryEntities(ctx, db, stickerTable, "")
 	if err != nil {
 		return nil, fmt.Errorf("failed to get all stickers: %w", err)
 	}
-	defer func() { _ = rows.Close() }()
-
-	var stickers []Sticker
-
-	for rows.Next() {
-		var sticker Sticker
-		if err := rows.Scan(&sticker.URL, &sticker.Source, &sticker.CachedAt); err != nil {
-			return nil, fmt.Errorf("failed to scan sticker row: %w", err)
-		}
-		stickers = append(stickers, sticker)
-	}
-
 	return stickers, nil
 }
 
 // CountStickers returns the total number of stickers.
 // Sticker data never expires; it is loaded on startup and updated only by explicit refresh.
 func (db *DB) CountStickers(ctx context.Context) (int, error) {
-	query := `SELECT COUNT(*) FROM stickers`
-
-	var count int
-	err := db.Reader().QueryRowContext(ctx, query).Scan(&count)
-	if err != nil {
-		return 0, fmt.Errorf("failed to count stickers: %w", err)
-	}
-	return count, nil
+	return db.countRows(ctx, tableStickers, false)
 }
 
 // GetStickerStats returns statistics about sticker sources
@@ -1149,202 +968,75 @@ func (db *DB) GetStickerStats(ctx context.Context) (map[string]int, error) {
 
 // SaveHistoricalCourse inserts or updates a historical course record
 func (db *DB) SaveHistoricalCourse(ctx context.Context, course *Course) error {
-	teachersJSON, err := json.Marshal(course.Teachers)
-	if err != nil {
-		return fmt.Errorf("failed to marshal teachers: %w", err)
-	}
-
-	teacherURLsJSON, err := json.Marshal(course.TeacherURLs)
-	if err != nil {
-		return fmt.Errorf("failed to marshal teacher URLs: %w", err)
-	}
-
-	timesJSON, err := json.Marshal(course.Times)
-	if err != nil {
-		return fmt.Errorf("failed to marshal times: %w", err)
-	}
-
-	locationsJSON, err := json.Marshal(course.Locations)
-	if err != nil {
-		return fmt.Errorf("failed to marshal locations: %w", err)
-	}
-
-	query := `
-		INSERT INTO historical_courses (uid, year, term, no, title, teachers, teacher_urls, times, locations, detail_url, note, cached_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-		ON CONFLICT(uid) DO UPDATE SET
-			year = excluded.year,
-			term = excluded.term,
-			no = excluded.no,
-			title = excluded.title,
-			teachers = excluded.teachers,
-			teacher_urls = excluded.teacher_urls,
-			times = excluded.times,
-			locations = excluded.locations,
-			detail_url = excluded.detail_url,
-			note = excluded.note,
-			cached_at = excluded.cached_at
-	`
-	_, err = db.Writer().ExecContext(ctx, query,
-		course.UID,
-		course.Year,
-		course.Term,
-		course.No,
-		course.Title,
-		string(teachersJSON),
-		string(teacherURLsJSON),
-		string(timesJSON),
-		string(locationsJSON),
-		nullString(course.DetailURL),
-		nullString(course.Note),
-		time.Now().Unix(),
-	)
-	if err != nil {
-		return fmt.Errorf("failed to save historical course: %w", err)
-	}
-	return nil
+	return saveEntity(ctx, db, historicalCourseTable, course)
 }
 
 // SaveHistoricalCoursesBatch inserts or updates multiple historical course records in a single transaction
 func (db *DB) SaveHistoricalCoursesBatch(ctx context.Context, courses []*Course) error {
-	if len(courses) == 0 {
-		return nil
-	}
-
-	query := `
-		INSERT INTO historical_courses (uid, year, term, no, title, teachers, teacher_urls, times, locations, detail_url, note, cached_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-		ON CONFLICT(uid) DO UPDATE SET
-			year = excluded.year,
-			term = excluded.term,
-			no = excluded.no,
-			title = excluded.title,
-			teachers = excluded.teachers,
-			teacher_urls = excluded.teacher_urls,
-			times = excluded.times,
-			locations = excluded.locations,
-			detail_url = excluded.detail_url,
-			note = excluded.note,
-			cached_at = excluded.cached_at
-	`
-
-	now := time.Now().Unix()
-	return db.ExecBatchContext(ctx, query, func(stmt *sql.Stmt) error {
-		for _, course := range courses {
-			cachedAt := course.CachedAt
-			if cachedAt == 0 {
-				cachedAt = now
-			}
-
-			teachersJSON, err := json.Marshal(course.Teachers)
-			if err != nil {
-				return fmt.Errorf("failed to marshal teachers for course %s: %w", course.UID, err)
-			}
-
-			teacherURLsJSON, err := json.Marshal(course.TeacherURLs)
-			if err != nil {
-				return fmt.Errorf("failed to marshal teacher URLs for course %s: %w", course.UID, err)
-			}
-
-			timesJSON, err := json.Marshal(course.Times)
-			if err != nil {
-				return fmt.Errorf("failed to marshal times for course %s: %w", course.UID, err)
-			}
-
-			locationsJSON, err := json.Marshal(course.Locations)
-			if err != nil {
-				return fmt.Errorf("failed to marshal locations for course %s: %w", course.UID, err)
-			}
+	return saveEntities(ctx, db, historicalCourseTable, courses)
+}
 
-			_, err = stmt.ExecContext(ctx,
-				course.UID,
-				course.Year,
-				course.Term,
-				course.No,
-				course.Title,
-				string(teachersJSON),
-				string(teacherURLsJSON),
-				string(timesJSON),
-				string(locationsJSON),
-				nullString(course.DetailURL),
-				nullString(course.Note),
-				cachedAt,
-			)
-			if err != nil {
-				return fmt.Errorf("failed to save historical course %s: %w", course.UID, err)
-			}
-		}
-		return nil
-	})
+// SearchHistoricalCoursesByYearAndTitle searches historical courses by year and partial title
+// or English title match (first MaxSearchLimit results, see SearchHistoricalCoursesByYearAndTitlePage).
+func (db *DB) SearchHistoricalCoursesByYearAndTitle(ctx context.Context, year int, title string) ([]Course, error) {
+	res, err := db.SearchHistoricalCoursesByYearAndTitlePage(ctx, year, title, Page{Limit: MaxSearchLimit})
+	return res.Items, err
 }
 
-// SearchHistoricalCoursesByYearAndTitle searches historical courses by year and partial title match
+// SearchHistoricalCoursesByYearAndTitlePage returns one page of the
+// historical courses of year whose title or English title contains title.
 // Only returns non-expired cache entries based on configured TTL
-func (db *DB) SearchHistoricalCoursesByYearAndTitle(ctx context.Context, year int, title string) ([]Course, error) {
+func (db *DB) SearchHistoricalCoursesByYearAndTitlePage(ctx context.Context, year int, title string, page Page) (SearchResult[Course], error) {
 	// Validate input
 	if len(title) > 100 {
-		return nil, errors.New("search term too long")
+		return SearchResult[Course]{}, errors.New("search term too long")
 	}
 
 	// Sanitize search term
 	sanitized := sanitizeSearchTerm(title)
 
-	ttlTimestamp := db.getTTLTimestamp()
-	query := `SELECT uid, year, term, no, title, teachers, teacher_urls, times, locations, detail_url, note, cached_at
-		FROM historical_courses WHERE year = ? AND title LIKE ? ESCAPE '\' AND cached_at > ?
-		ORDER BY term DESC LIMIT 500`
-
-	rows, err := db.Reader().QueryContext(ctx, query, year, "%"+sanitized+"%", ttlTimestamp)
+	res, err := searchEntities(ctx, db, historicalCourseTable,
+		`WHERE year = ? AND (title LIKE ? ESCAPE '\' OR title_en LIKE ? ESCAPE '\') AND cached_at > ?`,
+		`ORDER BY term DESC, uid`, page,
+		year, "%"+sanitized+"%", "%"+sanitized+"%", db.getTTLTimestamp(TableHistoricalCourses))
 	if err != nil {
-		return nil, fmt.Errorf("failed to search historical courses: %w", err)
+		return SearchResult[Course]{}, fmt.Errorf("failed to search historical courses: %w", err)
 	}
-	defer func() { _ = rows.Close() }()
-
-	return scanCourses(rows)
+	return res, nil
 }
 
 // SearchHistoricalCoursesByYear searches historical courses by year only
-// Returns all courses for the specified year (both semesters)
-// Only returns non-expired cache entries based on configured TTL
+// (first MaxSearchLimit results, see SearchHistoricalCoursesByYearPage).
 func (db *DB) SearchHistoricalCoursesByYear(ctx context.Context, year int) ([]Course, error) {
-	ttlTimestamp := db.getTTLTimestamp()
-
-	query := `SELECT uid, year, term, no, title, teachers, teacher_urls, times, locations, detail_url, note, cached_at
-		FROM historical_courses WHERE year = ? AND cached_at > ?
-		ORDER BY term DESC, title LIMIT 500`
+	res, err := db.SearchHistoricalCoursesByYearPage(ctx, year, Page{Limit: MaxSearchLimit})
+	return res.Items, err
+}
 
-	rows, err := db.Reader().QueryContext(ctx, query, year, ttlTimestamp)
+// SearchHistoricalCoursesByYearPage returns one page of the historical
+// courses of year (both semesters).
+// Only returns non-expired cache entries based on configured TTL
+func (db *DB) SearchHistoricalCoursesByYearPage(ctx context.Context, year int, page Page) (SearchResult[Course], error) {
+	res, err := searchEntities(ctx, db, historicalCourseTable,
+		`WHERE year = ? AND cached_at > ?`,
+		`ORDER BY term DESC, title, uid`, page,
+		year, db.getTTLTimestamp(TableHistoricalCourses))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get historical courses by year: %w", err)
+		return SearchResult[Course]{}, fmt.Errorf("failed to get historical courses by year: %w", err)
 	}
-	defer func() { _ = rows.Close() }()
-
-	return scanCourses(rows)
+	return res, nil
 }
 
 // DeleteExpiredHistoricalCourses removes historical courses older than the specified TTL
 // Returns the number of deleted entries
 func (db *DB) DeleteExpiredHistoricalCourses(ctx context.Context, ttl time.Duration) (int64, error) {
-	query := `DELETE FROM historical_courses WHERE cached_at < ?`
-	expiryTime := time.Now().Add(-ttl).Unix()
-
-	result, err := db.Writer().ExecContext(ctx, query, expiryTime)
-	if err != nil {
-		return 0, fmt.Errorf("failed to delete expired historical courses: %w", err)
-	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return 0, fmt.Errorf("failed to get rows affected for historical courses: %w", err)
-	}
-	return rowsAffected, nil
+	return db.deleteExpiredRows(ctx, TableHistoricalCourses, ttl)
 }
 
 // DeleteHistoricalCoursesByYearTerm deletes historical courses for a specific year and term.
 // This is used by warmup to clean up cold storage when data is promoted to hot storage.
 func (db *DB) DeleteHistoricalCoursesByYearTerm(ctx context.Context, year, term int) error {
-	query := `DELETE FROM historical_courses WHERE year = ? AND term = ?`
-	_, err := db.Writer().ExecContext(ctx, query, year, term)
+	query := `DELETE FROM historical_courses WHERE tenant = ? AND year = ? AND term = ?`
+	_, err := db.writeConn(ctx).ExecContext(ctx, query, db.Tenant(), year, term)
 	if err != nil {
 		return fmt.Errorf("failed to delete historical courses for year %d term %d: %w", year, term, err)
 	}
@@ -1353,94 +1045,83 @@ func (db *DB) DeleteHistoricalCoursesByYearTerm(ctx context.Context, year, term
 
 // CountHistoricalCourses returns the total number of historical courses
 func (db *DB) CountHistoricalCourses(ctx context.Context) (int, error) {
-	ttlTimestamp := db.getTTLTimestamp()
-	query := `SELECT COUNT(*) FROM historical_courses WHERE cached_at > ?`
-
-	var count int
-	err := db.Reader().QueryRowContext(ctx, query, ttlTimestamp).Scan(&count)
-	if err != nil {
-		return 0, fmt.Errorf("failed to count historical courses: %w", err)
-	}
-	return count, nil
+	return db.countRows(ctx, TableHistoricalCourses, true)
 }
 
 // ==================== Syllabi Repository Methods ====================
 
-// SaveSyllabus inserts or updates a syllabus record
-func (db *DB) SaveSyllabus(ctx context.Context, syllabus *Syllabus) error {
-	query := `
-		INSERT INTO syllabi (uid, year, term, title, teachers, objectives, outline, schedule, content_hash, cached_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-		ON CONFLICT(uid) DO UPDATE SET
-			year = excluded.year,
-			term = excluded.term,
-			title = excluded.title,
-			teachers = excluded.teachers,
-			objectives = excluded.objectives,
-			outline = excluded.outline,
-			schedule = excluded.schedule,
-			content_hash = excluded.content_hash,
-			cached_at = excluded.cached_at
-	`
-
-	teachersJSON, err := json.Marshal(syllabus.Teachers)
-	if err != nil {
-		return fmt.Errorf("failed to marshal teachers: %w", err)
-	}
+// syllabusTable describes the syllabi table. Text columns may be compressed
+// (see SetSyllabusCompression).
+var syllabusTable = &entityTable[Syllabus]{
+	name:  TableSyllabi,
+	label: "syllabus",
+	columns: []string{"uid", "year", "term", "title", "teachers", "objectives", "outline",
+		"schedule", "content_hash"},
+	scan: func(s scanner) (Syllabus, error) {
+		var syllabus Syllabus
+		var teachersJSON string
+		var objectives, outline, schedule sql.NullString
+		if err := s.Scan(
+			&syllabus.UID,
+			&syllabus.Year,
+			&syllabus.Term,
+			&syllabus.Title,
+			&teachersJSON,
+			&objectives,
+			&outline,
+			&schedule,
+			&syllabus.ContentHash,
+			&syllabus.CachedAt,
+		); err != nil {
+			return syllabus, err
+		}
 
-	_, err = db.Writer().ExecContext(ctx, query,
-		syllabus.UID,
-		syllabus.Year,
-		syllabus.Term,
-		syllabus.Title,
-		string(teachersJSON),
-		syllabus.Objectives,
-		syllabus.Outline,
-		syllabus.Schedule,
-		syllabus.ContentHash,
-		time.Now().Unix(),
-	)
-	if err != nil {
-		return fmt.Errorf("failed to save syllabus: %w", err)
-	}
-	return nil
+		if err := json.Unmarshal([]byte(teachersJSON), &syllabus.Teachers); err != nil {
+			syllabus.Teachers = []string{}
+		}
+		err := syllabusFields(&syllabus, objectives, outline, schedule)
+		return syllabus, err
+	},
+	args: func(db *DB, syllabus *Syllabus) ([]any, error) {
+		teachersJSON, err := json.Marshal(syllabus.Teachers)
+		if err != nil {
+			return nil, fmt.Errorf("marshal teachers: %w", err)
+		}
+		return []any{
+			syllabus.UID,
+			syllabus.Year,
+			syllabus.Term,
+			syllabus.Title,
+			string(teachersJSON),
+			db.syllabusColumn(syllabus.Objectives),
+			db.syllabusColumn(syllabus.Outline),
+			db.syllabusColumn(syllabus.Schedule),
+			syllabus.ContentHash,
+		}, nil
+	},
+	id: func(syllabus *Syllabus) string { return syllabus.UID },
 }
 
-// SaveSyllabusBatch inserts or updates multiple syllabus records in a single transaction
-func (db *DB) SaveSyllabusBatch(ctx context.Context, syllabi []*Syllabus) error {
-	if len(syllabi) == 0 {
+// syllabusPointers returns pointers to the elements of syllabi.
+func syllabusPointers(syllabi []Syllabus) []*Syllabus {
+	if syllabi == nil {
 		return nil
 	}
+	result := make([]*Syllabus, len(syllabi))
+	for i := range syllabi {
+		result[i] = &syllabi[i]
+	}
+	return result
+}
 
-	query := `
-		INSERT INTO syllabi (uid, year, term, title, teachers, objectives, outline, schedule, content_hash, cached_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-		ON CONFLICT(uid) DO UPDATE SET
-			year = excluded.year,
-			term = excluded.term,
-			title = excluded.title,
-			teachers = excluded.teachers,
-			objectives = excluded.objectives,
-			outline = excluded.outline,
-			schedule = excluded.schedule,
-			content_hash = excluded.content_hash,
-			cached_at = excluded.cached_at
-	`
-
-	cachedAt := time.Now().Unix()
-	return db.ExecBatchContext(ctx, query, func(stmt *sql.Stmt) error {
-		for _, syllabus := range syllabi {
-			teachersJSON, err := json.Marshal(syllabus.Teachers)
-			if err != nil {
-				return fmt.Errorf("failed to marshal teachers for %s: %w", syllabus.UID, err)
-			}
+// SaveSyllabus inserts or updates a syllabus record
+func (db *DB) SaveSyllabus(ctx context.Context, syllabus *Syllabus) error {
+	return saveEntity(ctx, db, syllabusTable, syllabus)
+}
 
-			if _, err := stmt.ExecContext(ctx, syllabus.UID, syllabus.Year, syllabus.Term, syllabus.Title, string(teachersJSON), syllabus.Objectives, syllabus.Outline, syllabus.Schedule, syllabus.ContentHash, cachedAt); err != nil {
-				return fmt.Errorf("failed to save syllabus %s: %w", syllabus.UID, err)
-			}
-		}
-		return nil
-	})
+// SaveSyllabusBatch inserts or updates multiple syllabus records in a single transaction
+func (db *DB) SaveSyllabusBatch(ctx context.Context, syllabi []*Syllabus) error {
+	return saveEntities(ctx, db, syllabusTable, syllabi)
 }
 
 // TouchSyllabiBatch updates cached_at for the given syllabus UIDs.
@@ -1450,11 +1131,11 @@ func (db *DB) TouchSyllabiBatch(ctx context.Context, uids []string) error {
 		return nil
 	}
 
-	query := `UPDATE syllabi SET cached_at = ? WHERE uid = ?`
-	cachedAt := time.Now().Unix()
-	return db.ExecBatchContext(ctx, query, func(stmt *sql.Stmt) error {
+	query := `UPDATE syllabi SET cached_at = ? WHERE tenant = ? AND uid = ?`
+	cachedAt, tenant := time.Now().Unix(), db.Tenant()
+	return db.ExecBatchContext(ctx, query, func(b *Batch) error {
 		for _, uid := range uids {
-			if _, err := stmt.ExecContext(ctx, cachedAt, uid); err != nil {
+			if err := b.Exec(cachedAt, tenant, uid); err != nil {
 				return fmt.Errorf("failed to touch syllabus %s: %w", uid, err)
 			}
 		}
@@ -1465,10 +1146,10 @@ func (db *DB) TouchSyllabiBatch(ctx context.Context, uids []string) error {
 // GetSyllabusContentHash retrieves the content hash for a syllabus
 // Used for incremental update detection - returns empty string if not found
 func (db *DB) GetSyllabusContentHash(ctx context.Context, uid string) (string, error) {
-	query := `SELECT content_hash FROM syllabi WHERE uid = ?`
+	query := `SELECT content_hash FROM syllabi WHERE tenant = ? AND uid = ?`
 
 	var hash string
-	err := db.Reader().QueryRowContext(ctx, query, uid).Scan(&hash)
+	err := db.Reader().QueryRowContext(ctx, query, db.Tenant(), uid).Scan(&hash)
 	if err == sql.ErrNoRows {
 		return "", nil
 	}
@@ -1480,99 +1161,55 @@ func (db *DB) GetSyllabusContentHash(ctx context.Context, uid string) (string, e
 
 // GetSyllabusByUID retrieves a syllabus by its UID
 func (db *DB) GetSyllabusByUID(ctx context.Context, uid string) (*Syllabus, error) {
-	query := `SELECT uid, year, term, title, teachers, objectives, outline, schedule, content_hash, cached_at FROM syllabi WHERE uid = ?`
-
-	var teachersJSON string
-	var objectives, outline, schedule sql.NullString
-	syllabus := &Syllabus{}
-	err := db.Reader().QueryRowContext(ctx, query, uid).Scan(
-		&syllabus.UID,
-		&syllabus.Year,
-		&syllabus.Term,
-		&syllabus.Title,
-		&teachersJSON,
-		&objectives,
-		&outline,
-		&schedule,
-		&syllabus.ContentHash,
-		&syllabus.CachedAt,
-	)
-	if err == sql.ErrNoRows {
-		return nil, domerrors.ErrNotFound
-	}
+	syllabus, err := getEntity(ctx, db, syllabusTable, uid)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get syllabus: %w", err)
+		return nil, err
 	}
 
 	// Check TTL using configured cache duration
-	ttl := int64(db.cacheTTL.Seconds())
-	if syllabus.CachedAt+ttl <= time.Now().Unix() {
+	if syllabus == nil || !db.isFresh(TableSyllabi, syllabus.CachedAt) {
 		return nil, domerrors.ErrNotFound
 	}
 
-	if err := json.Unmarshal([]byte(teachersJSON), &syllabus.Teachers); err != nil {
-		syllabus.Teachers = []string{}
-	}
-	syllabus.Objectives = objectives.String
-	syllabus.Outline = outline.String
-	syllabus.Schedule = schedule.String
-
 	return syllabus, nil
 }
 
+// GetPreviousSyllabus retrieves the newest syllabus of course number no
+// (e.g. "U3009") from a semester before year/term. Course numbers are reused
+// for the same course across semesters, so this is the same course's earlier
+// syllabus. Returns ErrNotFound when no earlier syllabus is cached.
+func (db *DB) GetPreviousSyllabus(ctx context.Context, no string, year, term int) (*Syllabus, error) {
+	syllabi, err := queryEntities(ctx, db, syllabusTable,
+		`WHERE uid = CAST(year AS TEXT) || CAST(term AS TEXT) || ?
+		AND (year < ? OR (year = ? AND term < ?)) AND cached_at > ?
+		ORDER BY year DESC, term DESC LIMIT 1`,
+		no, year, year, term, db.getTTLTimestamp(TableSyllabi))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query previous syllabus: %w", err)
+	}
+	if len(syllabi) == 0 {
+		return nil, domerrors.ErrNotFound
+	}
+	return &syllabi[0], nil
+}
+
 // GetAllSyllabi retrieves all syllabi from the database
 // Used for loading into BM25 index on startup
 func (db *DB) GetAllSyllabi(ctx context.Context) ([]*Syllabus, error) {
-	ttlTimestamp := db.getTTLTimestamp()
-	query := `SELECT uid, year, term, title, teachers, objectives, outline, schedule, content_hash, cached_at
-		FROM syllabi WHERE cached_at > ?`
-
-	rows, err := db.Reader().QueryContext(ctx, query, ttlTimestamp)
+	syllabi, err := queryEntities(ctx, db, syllabusTable, `WHERE cached_at > ?`, db.getTTLTimestamp(TableSyllabi))
 	if err != nil {
 		return nil, fmt.Errorf("failed to query syllabi: %w", err)
 	}
-	defer func() { _ = rows.Close() }()
-
-	var syllabi []*Syllabus
-	for rows.Next() {
-		var teachersJSON string
-		var objectives, outline, schedule sql.NullString
-		syllabus := &Syllabus{}
-		if err := rows.Scan(
-			&syllabus.UID,
-			&syllabus.Year,
-			&syllabus.Term,
-			&syllabus.Title,
-			&teachersJSON,
-			&objectives,
-			&outline,
-			&schedule,
-			&syllabus.ContentHash,
-			&syllabus.CachedAt,
-		); err != nil {
-			return nil, fmt.Errorf("failed to scan syllabus: %w", err)
-		}
-
-		if err := json.Unmarshal([]byte(teachersJSON), &syllabus.Teachers); err != nil {
-			syllabus.Teachers = []string{}
-		}
-		syllabus.Objectives = objectives.String
-		syllabus.Outline = outline.String
-		syllabus.Schedule = schedule.String
-
-		syllabi = append(syllabi, syllabus)
-	}
-
-	return syllabi, rows.Err()
+	return syllabusPointers(syllabi), nil
 }
 
 // GetDistinctSemesters retrieves all distinct semesters (year, term pairs) from the syllabi table.
 // Used for chunked loading of the BM25 index to reduce memory usage.
 func (db *DB) GetDistinctSemesters(ctx context.Context) ([]struct{ Year, Term int }, error) {
-	ttlTimestamp := db.getTTLTimestamp()
-	query := `SELECT DISTINCT year, term FROM syllabi WHERE cached_at > ? ORDER BY year DESC, term DESC`
+	ttlTimestamp := db.getTTLTimestamp(TableSyllabi)
+	query := `SELECT DISTINCT year, term FROM syllabi WHERE tenant = ? AND cached_at > ? ORDER BY year DESC, term DESC`
 
-	rows, err := db.Reader().QueryContext(ctx, query, ttlTimestamp)
+	rows, err := db.Reader().QueryContext(ctx, query, db.Tenant(), ttlTimestamp)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get distinct semesters from syllabi: %w", err)
 	}
@@ -1596,60 +1233,17 @@ func (db *DB) GetDistinctSemesters(ctx context.Context) ([]struct{ Year, Term in
 
 // GetSyllabiByYearTerm retrieves all syllabi for a specific year and term
 func (db *DB) GetSyllabiByYearTerm(ctx context.Context, year, term int) ([]*Syllabus, error) {
-	ttlTimestamp := db.getTTLTimestamp()
-	query := `SELECT uid, year, term, title, teachers, objectives, outline, schedule, content_hash, cached_at
-		FROM syllabi WHERE year = ? AND term = ? AND cached_at > ?`
-
-	rows, err := db.Reader().QueryContext(ctx, query, year, term, ttlTimestamp)
+	syllabi, err := queryEntities(ctx, db, syllabusTable,
+		`WHERE year = ? AND term = ? AND cached_at > ?`, year, term, db.getTTLTimestamp(TableSyllabi))
 	if err != nil {
 		return nil, fmt.Errorf("failed to query syllabi: %w", err)
 	}
-	defer func() { _ = rows.Close() }()
-
-	var syllabi []*Syllabus
-	for rows.Next() {
-		var teachersJSON string
-		var objectives, outline, schedule sql.NullString
-		syllabus := &Syllabus{}
-		if err := rows.Scan(
-			&syllabus.UID,
-			&syllabus.Year,
-			&syllabus.Term,
-			&syllabus.Title,
-			&teachersJSON,
-			&objectives,
-			&outline,
-			&schedule,
-			&syllabus.ContentHash,
-			&syllabus.CachedAt,
-		); err != nil {
-			return nil, fmt.Errorf("failed to scan syllabus: %w", err)
-		}
-
-		if err := json.Unmarshal([]byte(teachersJSON), &syllabus.Teachers); err != nil {
-			syllabus.Teachers = []string{}
-		}
-		syllabus.Objectives = objectives.String
-		syllabus.Outline = outline.String
-		syllabus.Schedule = schedule.String
-
-		syllabi = append(syllabi, syllabus)
-	}
-
-	return syllabi, rows.Err()
+	return syllabusPointers(syllabi), nil
 }
 
 // CountSyllabi returns the total number of syllabi
 func (db *DB) CountSyllabi(ctx context.Context) (int, error) {
-	ttlTimestamp := db.getTTLTimestamp()
-	query := `SELECT COUNT(*) FROM syllabi WHERE cached_at > ?`
-
-	var count int
-	err := db.Reader().QueryRowContext(ctx, query, ttlTimestamp).Scan(&count)
-	if err != nil {
-		return 0, fmt.Errorf("failed to count syllabi: %w", err)
-	}
-	return count, nil
+	return db.countRows(ctx, TableSyllabi, true)
 }
 
 // ==================== Syllabus Token Cache Repository Methods ====================
@@ -1672,13 +1266,14 @@ func (db *DB) GetSyllabusTokensBatch(ctx context.Context, uids []string) (map[st
 	query := fmt.Sprintf(`
 		SELECT st.uid, st.tokens
 		FROM   syllabus_tokens st
-		JOIN   syllabi s ON s.uid = st.uid AND s.content_hash = st.content_hash
-		WHERE  st.uid IN (%s)
+		JOIN   syllabi s ON s.tenant = st.tenant AND s.uid = st.uid AND s.content_hash = st.content_hash
+		WHERE  st.tenant = ? AND st.uid IN (%s)
 	`, placeholders)
 
-	args := make([]any, len(uids))
-	for i, uid := range uids {
-		args[i] = uid
+	args := make([]any, 0, len(uids)+1)
+	args = append(args, db.Tenant())
+	for _, uid := range uids {
+		args = append(args, uid)
 	}
 
 	rows, err := db.Reader().QueryContext(ctx, query, args...)
@@ -1703,20 +1298,20 @@ func (db *DB) GetSyllabusTokensBatch(ctx context.Context, uids []string) (map[st
 
 // SaveSyllabusTokensBatch persists pre-tokenized tokens for multiple syllabi in a single
 // transaction. Uses INSERT OR REPLACE so a re-tokenized entry safely overwrites an old row
-// with the same (uid, content_hash).
+// with the same (tenant, uid, content_hash).
 func (db *DB) SaveSyllabusTokensBatch(ctx context.Context, entries []SyllabusTokenEntry) error {
 	if len(entries) == 0 {
 		return nil
 	}
 
 	query := `
-		INSERT OR REPLACE INTO syllabus_tokens (uid, content_hash, tokens, created_at)
-		VALUES (?, ?, ?, ?)
+		INSERT OR REPLACE INTO syllabus_tokens (tenant, uid, content_hash, tokens, created_at)
+		VALUES (?, ?, ?, ?, ?)
 	`
-	now := time.Now().Unix()
-	return db.ExecBatchContext(ctx, query, func(stmt *sql.Stmt) error {
+	now, tenant := time.Now().Unix(), db.Tenant()
+	return db.ExecBatchContext(ctx, query, func(b *Batch) error {
 		for _, e := range entries {
-			if _, err := stmt.ExecContext(ctx, e.UID, e.ContentHash, strings.Join(e.Tokens, " "), now); err != nil {
+			if err := b.Exec(tenant, e.UID, e.ContentHash, strings.Join(e.Tokens, " "), now); err != nil {
 				return fmt.Errorf("save syllabus tokens for %s: %w", e.UID, err)
 			}
 		}
@@ -1730,13 +1325,14 @@ func (db *DB) SaveSyllabusTokensBatch(ctx context.Context, entries []SyllabusTok
 func (db *DB) DeleteStaleSyllabusTokens(ctx context.Context) (int64, error) {
 	query := `
 		DELETE FROM syllabus_tokens
-		WHERE NOT EXISTS (
+		WHERE tenant = ? AND NOT EXISTS (
 			SELECT 1 FROM syllabi
-			WHERE  syllabi.uid          = syllabus_tokens.uid
+			WHERE  syllabi.tenant       = syllabus_tokens.tenant
+			  AND  syllabi.uid          = syllabus_tokens.uid
 			  AND  syllabi.content_hash = syllabus_tokens.content_hash
 		)
 	`
-	result, err := db.Writer().ExecContext(ctx, query)
+	result, err := db.writeConn(ctx).ExecContext(ctx, query, db.Tenant())
 	if err != nil {
 		return 0, fmt.Errorf("delete stale syllabus tokens: %w", err)
 	}
@@ -1749,17 +1345,5 @@ func (db *DB) DeleteStaleSyllabusTokens(ctx context.Context) (int64, error) {
 
 // DeleteExpiredSyllabi removes syllabi older than the specified TTL
 func (db *DB) DeleteExpiredSyllabi(ctx context.Context, ttl time.Duration) (int64, error) {
-	query := `DELETE FROM syllabi WHERE cached_at < ?`
-	expiryTime := time.Now().Add(-ttl).Unix()
-
-	result, err := db.Writer().ExecContext(ctx, query, expiryTime)
-	if err != nil {
-		return 0, fmt.Errorf("failed to delete expired syllabi: %w", err)
-	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return 0, fmt.Errorf("failed to get rows affected for syllabi: %w", err)
-	}
-	return rowsAffected, nil
+	return db.deleteExpiredRows(ctx, TableSyllabi, ttl)
 }
@@ -162,6 +162,11 @@ func TestSaveStudentsBatch(t *testing.T) {
 		t.Fatalf("SaveStudentsBatch failed: %v", err)
 	}
 
+	all, err := db.GetAllStudents(ctx)
+	if err != nil || len(all) != len(students) || all[0].ID != "41247001" {
+		t.Errorf("GetAllStudents() = (%v, %v), want all %d ordered by ID", all, err, len(students))
+	}
+
 	// Verify all students were saved
 	for _, student := range students {
 		retrieved, err := db.GetStudentByID(ctx, student.ID)
@@ -185,7 +190,7 @@ func TestSaveContactsBatch(t *testing.T) {
 
 	contacts := []*Contact{
 		{UID: "85", Type: "individual", Name: "陳大華", Organization: "資工系"},
-		{UID: "87", Type: "individual", Name: "陳小明", Organization: "電機系"},
+		{UID: "87", Type: "individual", Name: "陳小明", Organization: "電機系", PartTime: true},
 		{UID: "86", Type: "organization", Name: "資訊工程學系", Organization: "電機資訊學院"},
 	}
 
@@ -195,6 +200,11 @@ func TestSaveContactsBatch(t *testing.T) {
 		t.Fatalf("SaveContactsBatch failed: %v", err)
 	}
 
+	all, err := db.GetAllContacts(ctx)
+	if err != nil || len(all) != len(contacts) || all[0].UID != "85" {
+		t.Errorf("GetAllContacts() = (%v, %v), want all %d ordered by UID", all, err, len(contacts))
+	}
+
 	// Verify all contacts were saved
 	for _, contact := range contacts {
 		retrieved, err := db.GetContactByUID(ctx, contact.UID)
@@ -207,6 +217,9 @@ func TestSaveContactsBatch(t *testing.T) {
 		if retrieved != nil && retrieved.Name != contact.Name {
 			t.Errorf("Expected name %s, got %s", contact.Name, retrieved.Name)
 		}
+		if retrieved != nil && retrieved.PartTime != contact.PartTime {
+			t.Errorf("Expected part-time %v for %s, got %v", contact.PartTime, contact.UID, retrieved.PartTime)
+		}
 	}
 }
 
@@ -256,6 +269,59 @@ func TestSaveCoursesBatch(t *testing.T) {
 	}
 }
 
+func TestGetCoursesByUIDs(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	writes := db.CourseWrites()
+	if err := db.SaveCoursesBatch(ctx, []*Course{
+		{UID: "1121U0001", Year: 112, Term: 1, No: "U0001", Title: "計算機概論"},
+		{UID: "1121U0002", Year: 112, Term: 1, No: "U0002", Title: "程式設計"},
+		{UID: "1121U0003", Year: 112, Term: 1, No: "U0003", Title: "資料結構"},
+	}); err != nil {
+		t.Fatalf("SaveCoursesBatch failed: %v", err)
+	}
+	if db.CourseWrites() == writes {
+		t.Error("CourseWrites() unchanged after SaveCoursesBatch")
+	}
+
+	courses, err := db.GetCoursesByUIDs(ctx, []string{"1121U0003", "1121U9999", "1121U0001"})
+	if err != nil {
+		t.Fatalf("GetCoursesByUIDs failed: %v", err)
+	}
+	var uids []string
+	for _, c := range courses {
+		uids = append(uids, c.UID)
+	}
+	if want := "1121U0003,1121U0001"; strings.Join(uids, ",") != want {
+		t.Errorf("GetCoursesByUIDs() = %v, want %s in the requested order", uids, want)
+	}
+}
+
+func TestSearchCoursesByTitle_English(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close(context.Background()) }()
+	ctx := context.Background()
+
+	if err := db.SaveCoursesBatch(ctx, []*Course{
+		{UID: "1131U0001", Year: 113, Term: 1, No: "U0001", Title: "資料結構", TitleEn: "Data Structures"},
+		{UID: "1131U0002", Year: 113, Term: 1, No: "U0002", Title: "程式設計"},
+	}); err != nil {
+		t.Fatalf("SaveCoursesBatch failed: %v", err)
+	}
+
+	for _, term := range []string{"data structure", "DATA", "資料"} {
+		courses, err := db.SearchCoursesByTitle(ctx, term)
+		if err != nil {
+			t.Fatalf("SearchCoursesByTitle(%q) failed: %v", term, err)
+		}
+		if len(courses) != 1 || courses[0].UID != "1131U0001" || courses[0].TitleEn != "Data Structures" {
+			t.Errorf("SearchCoursesByTitle(%q) = %+v, want only 資料結構 with its English title", term, courses)
+		}
+	}
+}
+
 // TestSearchContactsByName tests core contact search (critical for directory lookup)
 func TestSearchContactsByName(t *testing.T) {
 	db := setupTestDB(t)
@@ -284,6 +350,44 @@ func TestSearchContactsByName(t *testing.T) {
 	}
 }
 
+// TestSearchContactsByExtension tests reverse lookup by extension number
+func TestSearchContactsByExtension(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close(context.Background()) }()
+	ctx := context.Background()
+
+	contacts := []*Contact{
+		{UID: "c1", Type: "individual", Name: "陳大華", Organization: "資工系", Extension: "67890"},
+		{UID: "c2", Type: "individual", Name: "陳小明", Organization: "電機系", Extension: "67891"},
+		{UID: "c3", Type: "organization", Name: "資訊工程學系", Organization: "工學院", Extension: "67890"},
+		{UID: "c4", Type: "individual", Name: "林小美", Organization: "資工系"},
+	}
+	for _, c := range contacts {
+		if err := db.SaveContact(ctx, c); err != nil {
+			t.Fatalf("SaveContact failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		ext  string
+		want int
+	}{
+		{"67890", 2},
+		{"6789", 3},
+		{"12345", 0},
+		{"%", 0}, // LIKE wildcards are escaped
+	}
+	for _, tt := range tests {
+		results, err := db.SearchContactsByExtension(ctx, tt.ext)
+		if err != nil {
+			t.Fatalf("SearchContactsByExtension(%q) failed: %v", tt.ext, err)
+		}
+		if len(results) != tt.want {
+			t.Errorf("SearchContactsByExtension(%q) = %d contacts, want %d", tt.ext, len(results), tt.want)
+		}
+	}
+}
+
 // TestSearchContactsFuzzy tests SQL-based character-set matching for contacts
 func TestSearchContactsFuzzy(t *testing.T) {
 	db := setupTestDB(t)
@@ -1404,6 +1508,50 @@ func TestSaveSyllabusBatch(t *testing.T) {
 	}
 }
 
+func TestGetPreviousSyllabus(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close(context.Background()) }()
+	ctx := context.Background()
+
+	syllabi := []*Syllabus{
+		{UID: "1121U0001", Year: 112, Term: 1, Title: "程式設計", Objectives: "112-1", ContentHash: "h1"},
+		{UID: "1131U0001", Year: 113, Term: 1, Title: "程式設計", Objectives: "113-1", ContentHash: "h2"},
+		{UID: "1132U0001", Year: 113, Term: 2, Title: "程式設計", Objectives: "113-2", ContentHash: "h3"},
+		{UID: "1131U00012", Year: 113, Term: 1, Title: "其他課程", ContentHash: "h4"},
+	}
+	if err := db.SaveSyllabusBatch(ctx, syllabi); err != nil {
+		t.Fatalf("SaveSyllabusBatch failed: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		year, term int
+		wantUID    string
+	}{
+		{"previous term", 113, 2, "1131U0001"},
+		{"previous year", 113, 1, "1121U0001"},
+		{"later semester", 114, 1, "1132U0001"},
+		{"none earlier", 112, 1, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := db.GetPreviousSyllabus(ctx, "U0001", tt.year, tt.term)
+			if tt.wantUID == "" {
+				if err != domerrors.ErrNotFound {
+					t.Errorf("GetPreviousSyllabus() error = %v, want ErrNotFound", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetPreviousSyllabus() error = %v", err)
+			}
+			if got.UID != tt.wantUID {
+				t.Errorf("GetPreviousSyllabus() UID = %s, want %s", got.UID, tt.wantUID)
+			}
+		})
+	}
+}
+
 func TestSaveSyllabusBatch_Empty(t *testing.T) {
 	db := setupTestDB(t)
 	defer func() { _ = db.Close(context.Background()) }()
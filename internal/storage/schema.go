@@ -3,11 +3,38 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 )
 
-// InitSchema creates all necessary tables and indexes.
-// Note: WAL mode is configured in db.go's configureConnection function.
+// ErrMissingIndex is returned when a required index is absent or covers the wrong columns.
+var ErrMissingIndex = errors.New("required index missing")
+
+// requiredIndexes are the indexes the search and lookup queries depend on.
+// Every index is created with CREATE INDEX IF NOT EXISTS, which also adds it
+// to databases created by older versions; verifyIndexes then asserts the
+// result, because a same-named index on other columns would be kept silently.
+var requiredIndexes = []struct {
+	table   string
+	name    string
+	columns []string
+}{
+	{TableStudents, "idx_students_name", []string{"tenant", "name"}},
+	{TableStudents, "idx_students_year_id", []string{"tenant", "year", "id"}},
+	{TableStudents, "idx_students_year_dept", []string{"tenant", "year", "department"}},
+	{TableContacts, "idx_contacts_name", []string{"tenant", "name"}},
+	{TableContacts, "idx_contacts_extension", []string{"tenant", "extension"}},
+	{TableContacts, "idx_contacts_organization", []string{"tenant", "organization"}},
+	{TableCourses, "idx_courses_title", []string{"tenant", "title"}},
+	{TableCourses, "idx_courses_year_term", []string{"tenant", "year", "term"}},
+	{TableCourses, "idx_courses_year_term_uid", []string{"tenant", "year", "term", "uid"}},
+	{TableSyllabi, "idx_syllabi_year_term", []string{"tenant", "year", "term"}},
+}
+
+// InitSchema creates all necessary tables and indexes, then verifies the
+// required indexes. Note: WAL mode is configured in db.go's configureConnection function.
 //
 // STRICT mode: Tables use SQLite STRICT mode for type enforcement.
 // CREATE TABLE IF NOT EXISTS with STRICT only applies when the table is first created.
@@ -39,6 +66,11 @@ func InitSchema(ctx context.Context, db *sql.DB) error {
 		return err
 	}
 
+	// Create course_archive tables for the frozen catalog of each semester
+	if err := createCourseArchiveTables(ctx, db); err != nil {
+		return err
+	}
+
 	// Create programs table for academic program metadata (學程)
 	if err := createProgramsTable(ctx, db); err != nil {
 		return err
@@ -49,40 +81,131 @@ func InitSchema(ctx context.Context, db *sql.DB) error {
 		return err
 	}
 
+	// Create course_majors table for course-department relationships (應修系級)
+	if err := createCourseMajorsTable(ctx, db); err != nil {
+		return err
+	}
+
+	// Create teachers and course_teachers tables (normalized teacher identities)
+	if err := createTeachersTable(ctx, db); err != nil {
+		return err
+	}
+
+	// Create course_sections table for grouping sections of the same course
+	if err := createCourseSectionsTable(ctx, db); err != nil {
+		return err
+	}
+
+	// Create course_flags table for the flags parsed from course notes
+	if err := createCourseFlagsTable(ctx, db); err != nil {
+		return err
+	}
+
+	// Create course_prerequisites table for syllabus 先修課程 statements
+	if err := createCoursePrerequisitesTable(ctx, db); err != nil {
+		return err
+	}
+
 	// Create syllabi table for course syllabus smart search (BM25 index)
 	if err := createSyllabiTable(ctx, db); err != nil {
 		return err
 	}
 
 	// Create syllabus_tokens table to cache pre-tokenized BM25 index tokens
-	return createSyllabusTokensTable(ctx, db)
+	if err := createSyllabusTokensTable(ctx, db); err != nil {
+		return err
+	}
+
+	// Create lookup_misses table to remember lookups that found nothing
+	if err := createLookupMissesTable(ctx, db); err != nil {
+		return err
+	}
+
+	// Create department_news table for department website announcements
+	if err := createDepartmentNewsTable(ctx, db); err != nil {
+		return err
+	}
+
+	// Create cache_meta table for per-file settings
+	if err := createCacheMetaTable(ctx, db); err != nil {
+		return err
+	}
+
+	return verifyIndexes(ctx, db)
+}
+
+// verifyIndexes checks that every required index exists with its columns in order.
+func verifyIndexes(ctx context.Context, db *sql.DB) error {
+	var missing []string
+	for _, idx := range requiredIndexes {
+		rows, err := db.QueryContext(ctx, `SELECT name FROM pragma_index_info(?) ORDER BY seqno`, idx.name)
+		if err != nil {
+			return fmt.Errorf("read index %s: %w", idx.name, err)
+		}
+		var columns []string
+		for rows.Next() {
+			var column string
+			if err := rows.Scan(&column); err != nil {
+				_ = rows.Close()
+				return fmt.Errorf("read index %s: %w", idx.name, err)
+			}
+			columns = append(columns, column)
+		}
+		_ = rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("read index %s: %w", idx.name, err)
+		}
+
+		if !slices.Equal(columns, idx.columns) {
+			missing = append(missing, fmt.Sprintf("%s on %s(%s)", idx.name, idx.table, strings.Join(idx.columns, ", ")))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrMissingIndex, strings.Join(missing, "; "))
+	}
+	return nil
+}
+
+func createCacheMetaTable(ctx context.Context, db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS cache_meta (
+		key TEXT PRIMARY KEY,
+		value TEXT NOT NULL
+	) STRICT;
+	`
+
+	if _, err := db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("create cache_meta table: %w", err)
+	}
+
+	return nil
 }
 
 func createStudentsTable(ctx context.Context, db *sql.DB) error {
 	query := `
 	CREATE TABLE IF NOT EXISTS students (
-		id TEXT PRIMARY KEY,
+		tenant TEXT NOT NULL DEFAULT 'ntpu',
+		id TEXT NOT NULL,
 		name TEXT NOT NULL,
 		year INTEGER,
 		department TEXT,
-		cached_at INTEGER NOT NULL
+		cached_at INTEGER NOT NULL,
+		PRIMARY KEY (tenant, id)
 	) STRICT;
-	CREATE INDEX IF NOT EXISTS idx_students_name ON students(name);
-	CREATE INDEX IF NOT EXISTS idx_students_year_dept ON students(year, department);
-	CREATE INDEX IF NOT EXISTS idx_students_cached_at ON students(cached_at);
+	CREATE INDEX IF NOT EXISTS idx_students_name ON students(tenant, name);
+	CREATE INDEX IF NOT EXISTS idx_students_year_id ON students(tenant, year, id);
+	CREATE INDEX IF NOT EXISTS idx_students_year_dept ON students(tenant, year, department);
+	CREATE INDEX IF NOT EXISTS idx_students_cached_at ON students(tenant, cached_at);
 	`
 
-	if _, err := db.ExecContext(ctx, query); err != nil {
-		return fmt.Errorf("create students table: %w", err)
-	}
-
-	return nil
+	return createTenantTable(ctx, db, TableStudents, query)
 }
 
 func createContactsTable(ctx context.Context, db *sql.DB) error {
 	query := `
 	CREATE TABLE IF NOT EXISTS contacts (
-		uid TEXT PRIMARY KEY,
+		tenant TEXT NOT NULL DEFAULT 'ntpu',
+		uid TEXT NOT NULL,
 		type TEXT CHECK(type IN ('individual', 'organization')) NOT NULL,
 		name TEXT NOT NULL,
 		name_en TEXT,
@@ -94,25 +217,30 @@ func createContactsTable(ctx context.Context, db *sql.DB) error {
 		website TEXT,
 		location TEXT,
 		superior TEXT,
-		cached_at INTEGER NOT NULL
+		part_time INTEGER,
+		cached_at INTEGER NOT NULL,
+		PRIMARY KEY (tenant, uid)
 	) STRICT;
-	CREATE INDEX IF NOT EXISTS idx_contacts_name ON contacts(name);
-	CREATE INDEX IF NOT EXISTS idx_contacts_type ON contacts(type);
-	CREATE INDEX IF NOT EXISTS idx_contacts_organization ON contacts(organization);
-	CREATE INDEX IF NOT EXISTS idx_contacts_cached_at ON contacts(cached_at);
+	CREATE INDEX IF NOT EXISTS idx_contacts_name ON contacts(tenant, name);
+	CREATE INDEX IF NOT EXISTS idx_contacts_type ON contacts(tenant, type);
+	CREATE INDEX IF NOT EXISTS idx_contacts_organization ON contacts(tenant, organization);
+	CREATE INDEX IF NOT EXISTS idx_contacts_extension ON contacts(tenant, extension);
+	CREATE INDEX IF NOT EXISTS idx_contacts_cached_at ON contacts(tenant, cached_at);
 	`
 
-	if _, err := db.ExecContext(ctx, query); err != nil {
-		return fmt.Errorf("create contacts table: %w", err)
+	if err := createTenantTable(ctx, db, TableContacts, query); err != nil {
+		return err
 	}
 
-	return nil
+	// part_time was added with the part-time faculty listing
+	return addColumnIfMissing(ctx, db, "contacts", "part_time", "INTEGER")
 }
 
 func createCoursesTable(ctx context.Context, db *sql.DB) error {
 	query := `
 	CREATE TABLE IF NOT EXISTS courses (
-		uid TEXT PRIMARY KEY,
+		tenant TEXT NOT NULL DEFAULT 'ntpu',
+		uid TEXT NOT NULL,
 		year INTEGER NOT NULL,
 		term INTEGER NOT NULL,
 		no TEXT,
@@ -123,19 +251,23 @@ func createCoursesTable(ctx context.Context, db *sql.DB) error {
 		locations TEXT,
 		detail_url TEXT,
 		note TEXT,
-		cached_at INTEGER NOT NULL
+		title_en TEXT,
+		cached_at INTEGER NOT NULL,
+		PRIMARY KEY (tenant, uid)
 	) STRICT;
-	CREATE INDEX IF NOT EXISTS idx_courses_title ON courses(title);
-	CREATE INDEX IF NOT EXISTS idx_courses_year_term ON courses(year, term);
-	CREATE INDEX IF NOT EXISTS idx_courses_teachers ON courses(teachers);
-	CREATE INDEX IF NOT EXISTS idx_courses_cached_at ON courses(cached_at);
+	CREATE INDEX IF NOT EXISTS idx_courses_title ON courses(tenant, title);
+	CREATE INDEX IF NOT EXISTS idx_courses_year_term ON courses(tenant, year, term);
+	CREATE INDEX IF NOT EXISTS idx_courses_year_term_uid ON courses(tenant, year, term, uid);
+	CREATE INDEX IF NOT EXISTS idx_courses_teachers ON courses(tenant, teachers);
+	CREATE INDEX IF NOT EXISTS idx_courses_cached_at ON courses(tenant, cached_at);
 	`
 
-	if _, err := db.ExecContext(ctx, query); err != nil {
-		return fmt.Errorf("create courses table: %w", err)
+	if err := createTenantTable(ctx, db, TableCourses, query); err != nil {
+		return err
 	}
 
-	return nil
+	// title_en was added after the first release
+	return addColumnIfMissing(ctx, db, "courses", "title_en", "TEXT")
 }
 
 func createStickersTable(ctx context.Context, db *sql.DB) error {
@@ -162,7 +294,8 @@ func createStickersTable(ctx context.Context, db *sql.DB) error {
 func createHistoricalCoursesTable(ctx context.Context, db *sql.DB) error {
 	query := `
 	CREATE TABLE IF NOT EXISTS historical_courses (
-		uid TEXT PRIMARY KEY,
+		tenant TEXT NOT NULL DEFAULT 'ntpu',
+		uid TEXT NOT NULL,
 		year INTEGER NOT NULL,
 		term INTEGER NOT NULL,
 		no TEXT,
@@ -173,19 +306,63 @@ func createHistoricalCoursesTable(ctx context.Context, db *sql.DB) error {
 		locations TEXT,
 		detail_url TEXT,
 		note TEXT,
-		cached_at INTEGER NOT NULL
+		title_en TEXT,
+		cached_at INTEGER NOT NULL,
+		PRIMARY KEY (tenant, uid)
 	) STRICT;
-	CREATE INDEX IF NOT EXISTS idx_historical_courses_title ON historical_courses(title);
-	CREATE INDEX IF NOT EXISTS idx_historical_courses_year_term ON historical_courses(year, term);
-	CREATE INDEX IF NOT EXISTS idx_historical_courses_teachers ON historical_courses(teachers);
-	CREATE INDEX IF NOT EXISTS idx_historical_courses_cached_at ON historical_courses(cached_at);
+	CREATE INDEX IF NOT EXISTS idx_historical_courses_title ON historical_courses(tenant, title);
+	CREATE INDEX IF NOT EXISTS idx_historical_courses_year_term ON historical_courses(tenant, year, term);
+	CREATE INDEX IF NOT EXISTS idx_historical_courses_teachers ON historical_courses(tenant, teachers);
+	CREATE INDEX IF NOT EXISTS idx_historical_courses_cached_at ON historical_courses(tenant, cached_at);
 	`
 
-	if _, err := db.ExecContext(ctx, query); err != nil {
-		return fmt.Errorf("create historical_courses table: %w", err)
+	if err := createTenantTable(ctx, db, TableHistoricalCourses, query); err != nil {
+		return err
 	}
 
-	return nil
+	// title_en was added after the first release
+	return addColumnIfMissing(ctx, db, "historical_courses", "title_en", "TEXT")
+}
+
+// createCourseArchiveTables creates course_archive, an immutable copy of each
+// semester's catalog taken after 加退選 ends, and course_archive_semesters,
+// which records the archived semesters. Rows are never updated or expired.
+func createCourseArchiveTables(ctx context.Context, db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS course_archive (
+		tenant TEXT NOT NULL DEFAULT 'ntpu',
+		uid TEXT NOT NULL,
+		year INTEGER NOT NULL,
+		term INTEGER NOT NULL,
+		no TEXT,
+		title TEXT NOT NULL,
+		teachers TEXT,
+		teacher_urls TEXT,
+		times TEXT,
+		locations TEXT,
+		detail_url TEXT,
+		note TEXT,
+		title_en TEXT,
+		cached_at INTEGER NOT NULL,
+		PRIMARY KEY (tenant, uid)
+	) STRICT;
+	CREATE INDEX IF NOT EXISTS idx_course_archive_year_term ON course_archive(tenant, year, term);
+	`
+	if err := createTenantTable(ctx, db, "course_archive", query); err != nil {
+		return err
+	}
+
+	query = `
+	CREATE TABLE IF NOT EXISTS course_archive_semesters (
+		tenant TEXT NOT NULL DEFAULT 'ntpu',
+		year INTEGER NOT NULL,
+		term INTEGER NOT NULL,
+		course_count INTEGER NOT NULL,
+		archived_at INTEGER NOT NULL,
+		PRIMARY KEY (tenant, year, term)
+	) STRICT;
+	`
+	return createTenantTable(ctx, db, "course_archive_semesters", query)
 }
 
 // createSyllabiTable creates table for course syllabus search content.
@@ -193,7 +370,8 @@ func createHistoricalCoursesTable(ctx context.Context, db *sql.DB) error {
 func createSyllabiTable(ctx context.Context, db *sql.DB) error {
 	query := `
 	CREATE TABLE IF NOT EXISTS syllabi (
-		uid TEXT PRIMARY KEY,
+		tenant TEXT NOT NULL DEFAULT 'ntpu',
+		uid TEXT NOT NULL,
 		year INTEGER NOT NULL,
 		term INTEGER NOT NULL,
 		title TEXT NOT NULL,
@@ -202,40 +380,34 @@ func createSyllabiTable(ctx context.Context, db *sql.DB) error {
 		outline TEXT,
 		schedule TEXT,
 		content_hash TEXT NOT NULL,
-		cached_at INTEGER NOT NULL
+		cached_at INTEGER NOT NULL,
+		PRIMARY KEY (tenant, uid)
 	) STRICT;
-	CREATE INDEX IF NOT EXISTS idx_syllabi_year_term ON syllabi(year, term);
-	CREATE INDEX IF NOT EXISTS idx_syllabi_content_hash ON syllabi(content_hash);
-	CREATE INDEX IF NOT EXISTS idx_syllabi_cached_at ON syllabi(cached_at);
+	CREATE INDEX IF NOT EXISTS idx_syllabi_year_term ON syllabi(tenant, year, term);
+	CREATE INDEX IF NOT EXISTS idx_syllabi_content_hash ON syllabi(tenant, content_hash);
+	CREATE INDEX IF NOT EXISTS idx_syllabi_cached_at ON syllabi(tenant, cached_at);
 	`
 
-	if _, err := db.ExecContext(ctx, query); err != nil {
-		return fmt.Errorf("create syllabi table: %w", err)
-	}
-
-	return nil
+	return createTenantTable(ctx, db, TableSyllabi, query)
 }
 
 // createSyllabusTokensTable stores pre-tokenized BM25 index tokens per syllabus.
-// The (uid, content_hash) primary key ties each token row to a specific content version:
+// The (tenant, uid, content_hash) primary key ties each token row to a specific content version:
 // when syllabus content changes its hash changes, automatically making the old tokens unreachable
 // via the JOIN in GetSyllabusTokensBatch — no explicit invalidation needed.
 func createSyllabusTokensTable(ctx context.Context, db *sql.DB) error {
 	query := `
 	CREATE TABLE IF NOT EXISTS syllabus_tokens (
+		tenant       TEXT    NOT NULL DEFAULT 'ntpu',
 		uid          TEXT    NOT NULL,
 		content_hash TEXT    NOT NULL,
 		tokens       TEXT    NOT NULL,
 		created_at   INTEGER NOT NULL,
-		PRIMARY KEY (uid, content_hash)
+		PRIMARY KEY (tenant, uid, content_hash)
 	) STRICT;
 	`
 
-	if _, err := db.ExecContext(ctx, query); err != nil {
-		return fmt.Errorf("create syllabus_tokens table: %w", err)
-	}
-
-	return nil
+	return createTenantTable(ctx, db, "syllabus_tokens", query)
 }
 
 // createProgramsTable creates table for academic program metadata (學程).
@@ -244,19 +416,17 @@ func createSyllabusTokensTable(ctx context.Context, db *sql.DB) error {
 func createProgramsTable(ctx context.Context, db *sql.DB) error {
 	query := `
 	CREATE TABLE IF NOT EXISTS programs (
-		name TEXT PRIMARY KEY,
+		tenant TEXT NOT NULL DEFAULT 'ntpu',
+		name TEXT NOT NULL,
 		category TEXT NOT NULL,
 		url TEXT NOT NULL,
-		cached_at INTEGER NOT NULL DEFAULT 0
+		cached_at INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (tenant, name)
 	) STRICT;
-	CREATE INDEX IF NOT EXISTS idx_programs_cached_at ON programs(cached_at);
+	CREATE INDEX IF NOT EXISTS idx_programs_cached_at ON programs(tenant, cached_at);
 	`
 
-	if _, err := db.ExecContext(ctx, query); err != nil {
-		return fmt.Errorf("create programs table: %w", err)
-	}
-
-	return nil
+	return createTenantTable(ctx, db, TablePrograms, query)
 }
 
 // createCourseProgramsTable creates table for course-program relationships (學程).
@@ -265,21 +435,268 @@ func createProgramsTable(ctx context.Context, db *sql.DB) error {
 func createCourseProgramsTable(ctx context.Context, db *sql.DB) error {
 	query := `
 	CREATE TABLE IF NOT EXISTS course_programs (
+		tenant TEXT NOT NULL DEFAULT 'ntpu',
 		course_uid TEXT NOT NULL,
 		program_name TEXT NOT NULL,
 		course_type TEXT NOT NULL,
 		cached_at INTEGER NOT NULL,
-		PRIMARY KEY (course_uid, program_name)
+		PRIMARY KEY (tenant, course_uid, program_name)
+	) STRICT;
+	CREATE INDEX IF NOT EXISTS idx_course_programs_program ON course_programs(tenant, program_name);
+	CREATE INDEX IF NOT EXISTS idx_course_programs_course ON course_programs(tenant, course_uid);
+	CREATE INDEX IF NOT EXISTS idx_course_programs_type ON course_programs(tenant, course_type);
+	CREATE INDEX IF NOT EXISTS idx_course_programs_cached_at ON course_programs(tenant, cached_at);
+	`
+
+	return createTenantTable(ctx, db, TableCoursePrograms, query)
+}
+
+// createCourseMajorsTable creates table for course-department relationships (應修系級).
+// Majors are stored as listed on the course list page (e.g., "資工系3"), so a
+// department prefix such as "資工系" matches every grade of that department.
+func createCourseMajorsTable(ctx context.Context, db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS course_majors (
+		tenant TEXT NOT NULL DEFAULT 'ntpu',
+		course_uid TEXT NOT NULL,
+		major TEXT NOT NULL,
+		course_type TEXT NOT NULL,
+		cached_at INTEGER NOT NULL,
+		PRIMARY KEY (tenant, course_uid, major)
+	) STRICT;
+	CREATE INDEX IF NOT EXISTS idx_course_majors_major ON course_majors(tenant, major);
+	CREATE INDEX IF NOT EXISTS idx_course_majors_cached_at ON course_majors(tenant, cached_at);
+	`
+
+	return createTenantTable(ctx, db, TableCourseMajors, query)
+}
+
+// createTeachersTable creates the teachers table and its course_teachers join table.
+// Teacher IDs are derived from the course system's timetable URL, so two teachers
+// sharing a name (e.g., 「王小明」 in two departments) get distinct rows.
+func createTeachersTable(ctx context.Context, db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS teachers (
+		tenant TEXT NOT NULL DEFAULT 'ntpu',
+		id TEXT NOT NULL,
+		name TEXT NOT NULL,
+		url TEXT,
+		department TEXT,
+		profile TEXT,
+		cached_at INTEGER NOT NULL,
+		PRIMARY KEY (tenant, id)
+	) STRICT;
+	CREATE INDEX IF NOT EXISTS idx_teachers_name ON teachers(tenant, name);
+	CREATE INDEX IF NOT EXISTS idx_teachers_cached_at ON teachers(tenant, cached_at);
+	`
+	if err := createTenantTable(ctx, db, TableTeachers, query); err != nil {
+		return err
+	}
+
+	query = `
+	CREATE TABLE IF NOT EXISTS course_teachers (
+		tenant TEXT NOT NULL DEFAULT 'ntpu',
+		course_uid TEXT NOT NULL,
+		teacher_id TEXT NOT NULL,
+		position INTEGER NOT NULL,
+		cached_at INTEGER NOT NULL,
+		PRIMARY KEY (tenant, course_uid, teacher_id)
+	) STRICT;
+	CREATE INDEX IF NOT EXISTS idx_course_teachers_teacher ON course_teachers(tenant, teacher_id);
+	CREATE INDEX IF NOT EXISTS idx_course_teachers_cached_at ON course_teachers(tenant, cached_at);
+	`
+	return createTenantTable(ctx, db, TableCourseTeachers, query)
+}
+
+func createCourseSectionsTable(ctx context.Context, db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS course_sections (
+		tenant TEXT NOT NULL DEFAULT 'ntpu',
+		course_uid TEXT NOT NULL,
+		year INTEGER NOT NULL,
+		term INTEGER NOT NULL,
+		section_key TEXT NOT NULL,
+		cached_at INTEGER NOT NULL,
+		PRIMARY KEY (tenant, course_uid)
+	) STRICT;
+	CREATE INDEX IF NOT EXISTS idx_course_sections_key ON course_sections(tenant, year, term, section_key);
+	CREATE INDEX IF NOT EXISTS idx_course_sections_cached_at ON course_sections(tenant, cached_at);
+	`
+
+	return createTenantTable(ctx, db, TableCourseSections, query)
+}
+
+func createCourseFlagsTable(ctx context.Context, db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS course_flags (
+		tenant TEXT NOT NULL DEFAULT 'ntpu',
+		course_uid TEXT NOT NULL,
+		flag TEXT NOT NULL,
+		cached_at INTEGER NOT NULL,
+		PRIMARY KEY (tenant, course_uid, flag)
+	) STRICT;
+	CREATE INDEX IF NOT EXISTS idx_course_flags_cached_at ON course_flags(tenant, cached_at);
+	`
+
+	return createTenantTable(ctx, db, TableCourseFlags, query)
+}
+
+func createCoursePrerequisitesTable(ctx context.Context, db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS course_prerequisites (
+		tenant TEXT NOT NULL DEFAULT 'ntpu',
+		course_uid TEXT NOT NULL,
+		statement TEXT NOT NULL,
+		titles TEXT NOT NULL,
+		cached_at INTEGER NOT NULL,
+		PRIMARY KEY (tenant, course_uid)
+	) STRICT;
+	CREATE INDEX IF NOT EXISTS idx_course_prerequisites_cached_at ON course_prerequisites(tenant, cached_at);
+	`
+
+	return createTenantTable(ctx, db, TableCoursePrerequisites, query)
+}
+
+func createLookupMissesTable(ctx context.Context, db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS lookup_misses (
+		tenant TEXT NOT NULL DEFAULT 'ntpu',
+		kind TEXT NOT NULL,
+		key TEXT NOT NULL,
+		cached_at INTEGER NOT NULL,
+		PRIMARY KEY (tenant, kind, key)
+	) STRICT;
+	CREATE INDEX IF NOT EXISTS idx_lookup_misses_cached_at ON lookup_misses(tenant, cached_at);
+	`
+
+	return createTenantTable(ctx, db, TableLookupMisses, query)
+}
+
+func createDepartmentNewsTable(ctx context.Context, db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS department_news (
+		tenant TEXT NOT NULL DEFAULT 'ntpu',
+		dept_code TEXT NOT NULL,
+		position INTEGER NOT NULL,
+		title TEXT NOT NULL,
+		url TEXT NOT NULL,
+		date TEXT NOT NULL,
+		cached_at INTEGER NOT NULL,
+		PRIMARY KEY (tenant, dept_code, position)
 	) STRICT;
-	CREATE INDEX IF NOT EXISTS idx_course_programs_program ON course_programs(program_name);
-	CREATE INDEX IF NOT EXISTS idx_course_programs_course ON course_programs(course_uid);
-	CREATE INDEX IF NOT EXISTS idx_course_programs_type ON course_programs(course_type);
-	CREATE INDEX IF NOT EXISTS idx_course_programs_cached_at ON course_programs(cached_at);
+	CREATE INDEX IF NOT EXISTS idx_department_news_cached_at ON department_news(tenant, cached_at);
 	`
 
+	return createTenantTable(ctx, db, TableDepartmentNews, query)
+}
+
+// addColumnIfMissing adds column to a table created by an older version.
+// CREATE TABLE IF NOT EXISTS leaves existing tables untouched, so columns
+// added later need this to reach databases (and downloaded snapshots) that
+// predate them. decl must allow NULL, as existing rows get no value.
+func addColumnIfMissing(ctx context.Context, db *sql.DB, table, column, decl string) error {
+	columns, err := tableColumns(ctx, db, table)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(columns, column) {
+		return nil
+	}
+
+	if _, err := db.ExecContext(ctx, "ALTER TABLE "+table+" ADD COLUMN "+column+" "+decl); err != nil {
+		return fmt.Errorf("add %s.%s column: %w", table, column, err)
+	}
+	return nil
+}
+
+// createTenantTable runs query, the CREATE TABLE and CREATE INDEX statements
+// of a tenant-scoped table. A table created before tenants existed cannot
+// gain a tenant-first primary key with ALTER TABLE, so it is rebuilt: renamed
+// aside with its indexes dropped, created anew by query, and its rows copied
+// over as DefaultTenant. Each step is safe to repeat after a crash.
+func createTenantTable(ctx context.Context, db *sql.DB, table, query string) error {
+	pretenant := table + "_pretenant"
+
+	columns, err := tableColumns(ctx, db, table)
+	if err != nil {
+		return err
+	}
+	if len(columns) > 0 && !slices.Contains(columns, "tenant") {
+		if _, err := db.ExecContext(ctx, "ALTER TABLE "+table+" RENAME TO "+pretenant); err != nil {
+			return fmt.Errorf("rename %s for tenant rebuild: %w", table, err)
+		}
+		// The renamed table keeps its index names, which query would skip
+		if err := dropIndexes(ctx, db, pretenant); err != nil {
+			return err
+		}
+	}
+
 	if _, err := db.ExecContext(ctx, query); err != nil {
-		return fmt.Errorf("create course_programs table: %w", err)
+		return fmt.Errorf("create %s table: %w", table, err)
 	}
 
+	old, err := tableColumns(ctx, db, pretenant)
+	if err != nil || len(old) == 0 {
+		return err
+	}
+	list := strings.Join(old, ", ")
+	if _, err := db.ExecContext(ctx,
+		"INSERT OR IGNORE INTO "+table+" ("+list+") SELECT "+list+" FROM "+pretenant); err != nil {
+		return fmt.Errorf("copy %s rows for tenant rebuild: %w", table, err)
+	}
+	if _, err := db.ExecContext(ctx, "DROP TABLE "+pretenant); err != nil {
+		return fmt.Errorf("drop %s: %w", pretenant, err)
+	}
+	return nil
+}
+
+// tableColumns returns the column names of table, or none if it does not exist.
+func tableColumns(ctx context.Context, db *sql.DB, table string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, "SELECT name FROM pragma_table_info(?)", table)
+	if err != nil {
+		return nil, fmt.Errorf("inspect %s columns: %w", table, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var columns []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("inspect %s columns: %w", table, err)
+		}
+		columns = append(columns, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("inspect %s columns: %w", table, err)
+	}
+	return columns, nil
+}
+
+// dropIndexes drops the explicitly created indexes of table.
+func dropIndexes(ctx context.Context, db *sql.DB, table string) error {
+	rows, err := db.QueryContext(ctx,
+		`SELECT name FROM sqlite_master WHERE type = 'index' AND tbl_name = ? AND sql IS NOT NULL`, table)
+	if err != nil {
+		return fmt.Errorf("list %s indexes: %w", table, err)
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			_ = rows.Close()
+			return fmt.Errorf("list %s indexes: %w", table, err)
+		}
+		names = append(names, name)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("list %s indexes: %w", table, err)
+	}
+
+	for _, name := range names {
+		if _, err := db.ExecContext(ctx, "DROP INDEX "+name); err != nil {
+			return fmt.Errorf("drop index %s: %w", name, err)
+		}
+	}
 	return nil
 }
@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// SectionKey returns the key grouping sections of the same course within a
+// semester: the same title taught by the same teachers under different course
+// numbers (multiple sections, or one course cross-listed by departments).
+// Titles are compared ignoring case, spaces and full-width forms; teacher order
+// does not matter.
+func SectionKey(title string, teachers []string) string {
+	var b strings.Builder
+	for _, r := range title {
+		switch {
+		case r >= '！' && r <= '～':
+			r -= '！' - '!' // Full-width ASCII to half-width
+		case unicode.IsSpace(r):
+			continue
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+
+	names := make([]string, 0, len(teachers))
+	for _, name := range teachers {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	sum := sha256.Sum256([]byte(b.String() + "|" + strings.Join(names, "、")))
+	return "s" + hex.EncodeToString(sum[:6])
+}
+
+// SaveCourseSectionsBatch stores the section key of each course.
+func (db *DB) SaveCourseSectionsBatch(ctx context.Context, courses []*Course) error {
+	if len(courses) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO course_sections (tenant, course_uid, year, term, section_key, cached_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(tenant, course_uid) DO UPDATE SET
+			year = excluded.year,
+			term = excluded.term,
+			section_key = excluded.section_key,
+			cached_at = excluded.cached_at
+	`
+
+	now, tenant := time.Now().Unix(), db.Tenant()
+	return db.ExecBatchContext(ctx, query, func(b *Batch) error {
+		for _, course := range courses {
+			key := SectionKey(course.Title, course.Teachers)
+			if err := b.Exec(tenant, course.UID, course.Year, course.Term, key, now); err != nil {
+				return fmt.Errorf("failed to save section key for course %s: %w", course.UID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetCourseSections returns the non-expired courses of a semester sharing a
+// section key, ordered by course number.
+func (db *DB) GetCourseSections(ctx context.Context, year, term int, key string) ([]Course, error) {
+	if key == "" {
+		return nil, errors.New("section key is required")
+	}
+
+	query := `SELECT c.uid, c.year, c.term, c.no, c.title, c.teachers, c.teacher_urls, c.times, c.locations, c.detail_url, c.note, c.title_en, c.cached_at
+		FROM courses c
+		JOIN course_sections s ON s.tenant = c.tenant AND s.course_uid = c.uid
+		WHERE s.tenant = ? AND s.year = ? AND s.term = ? AND s.section_key = ? AND c.cached_at > ?
+		ORDER BY c.no`
+
+	rows, err := db.Reader().QueryContext(ctx, query, db.Tenant(), year, term, key, db.getTTLTimestamp(TableCourseSections))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get course sections: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanCourses(rows)
+}
+
+// DeleteExpiredCourseSections removes section keys older than the specified TTL.
+// Returns the number of deleted entries.
+func (db *DB) DeleteExpiredCourseSections(ctx context.Context, ttl time.Duration) (int64, error) {
+	query := `DELETE FROM course_sections WHERE tenant = ? AND cached_at < ?`
+	expiryTime := time.Now().Add(-ttl).Unix()
+
+	result, err := db.writeConn(ctx).ExecContext(ctx, query, db.Tenant(), expiryTime)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired course sections: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected for course sections: %w", err)
+	}
+	return rowsAffected, nil
+}
@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSectionKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		a, b     Course
+		wantSame bool
+	}{
+		{"same title and teachers", Course{Title: "微積分", Teachers: []string{"王小明"}}, Course{Title: "微積分", Teachers: []string{"王小明"}}, true},
+		{"teacher order ignored", Course{Title: "專題", Teachers: []string{"王小明", "李大華"}}, Course{Title: "專題", Teachers: []string{"李大華", "王小明"}}, true},
+		{"full-width and spaces ignored", Course{Title: "Ｃ++ 程式設計", Teachers: []string{"王小明"}}, Course{Title: "c++程式設計", Teachers: []string{"王小明"}}, true},
+		{"different teacher", Course{Title: "微積分", Teachers: []string{"王小明"}}, Course{Title: "微積分", Teachers: []string{"李大華"}}, false},
+		{"different title", Course{Title: "微積分(一)", Teachers: []string{"王小明"}}, Course{Title: "微積分(二)", Teachers: []string{"王小明"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			keyA, keyB := SectionKey(tt.a.Title, tt.a.Teachers), SectionKey(tt.b.Title, tt.b.Teachers)
+			if (keyA == keyB) != tt.wantSame {
+				t.Errorf("SectionKey equal = %v, want %v (%s vs %s)", keyA == keyB, tt.wantSame, keyA, keyB)
+			}
+		})
+	}
+}
+
+func TestGetCourseSections(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	courses := []*Course{
+		{UID: "1131U0002", Year: 113, Term: 1, No: "U0002", Title: "微積分", Teachers: []string{"王小明"}},
+		{UID: "1131U0001", Year: 113, Term: 1, No: "U0001", Title: "微積分", Teachers: []string{"王小明"}},
+		{UID: "1131U0003", Year: 113, Term: 1, No: "U0003", Title: "微積分", Teachers: []string{"李大華"}},
+		{UID: "1122U0001", Year: 112, Term: 2, No: "U0001", Title: "微積分", Teachers: []string{"王小明"}},
+	}
+	if err := db.SaveCoursesBatch(ctx, courses); err != nil {
+		t.Fatalf("SaveCoursesBatch failed: %v", err)
+	}
+	// Saving the same course again must upsert
+	if err := db.SaveCourse(ctx, courses[0]); err != nil {
+		t.Fatalf("SaveCourse failed: %v", err)
+	}
+
+	got, err := db.GetCourseSections(ctx, 113, 1, SectionKey("微積分", []string{"王小明"}))
+	if err != nil {
+		t.Fatalf("GetCourseSections failed: %v", err)
+	}
+	if len(got) != 2 || got[0].UID != "1131U0001" || got[1].UID != "1131U0002" {
+		t.Errorf("GetCourseSections returned %+v, want U0001 and U0002", got)
+	}
+
+	if _, err := db.GetCourseSections(ctx, 113, 1, ""); err == nil {
+		t.Error("expected error for empty section key")
+	}
+	if _, err := db.DeleteExpiredCourseSections(ctx, 0); err != nil {
+		t.Fatalf("DeleteExpiredCourseSections failed: %v", err)
+	}
+}
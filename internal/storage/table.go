@@ -0,0 +1,306 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// scanner is implemented by *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// entityTable describes a cached entity table (one row per entity, keyed by
+// an ID, stamped with cached_at) for the generic helpers below. A new entity
+// needs a table description, a scan function and an args function; Save,
+// SaveBatch, GetByID, query, DeleteExpired and Count come from the helpers.
+// The helpers read and write only the rows of the DB's tenant.
+type entityTable[T any] struct {
+	name    string   // Table name
+	label   string   // Singular noun for error messages (e.g., "historical course")
+	columns []string // Columns except tenant and cached_at, primary key first
+	shared  bool     // No tenant column: rows are shared by every tenant
+
+	// scan reads one row selected by selectQuery (columns, then cached_at).
+	scan func(s scanner) (T, error)
+	// args returns the values of columns for row, in order. db supplies
+	// per-database settings such as SetSyllabusCompression.
+	args func(db *DB, row *T) ([]any, error)
+	// id returns the primary key of row, for error messages.
+	id func(row *T) string
+	// cachedAt returns a preset cached_at used by batch saves (0 means now).
+	// Nil stamps every batch row with the current time.
+	cachedAt func(row *T) int64
+}
+
+// selectQuery returns "SELECT <columns>, cached_at FROM <table>".
+func (t *entityTable[T]) selectQuery() string {
+	return "SELECT " + strings.Join(t.columns, ", ") + ", cached_at FROM " + t.name
+}
+
+// upsertQuery returns an INSERT that updates every column on a key conflict.
+// Its arguments are those of insertArgs.
+func (t *entityTable[T]) upsertQuery() string {
+	columns, key := t.columns, t.columns[0]
+	if !t.shared {
+		columns, key = append([]string{"tenant"}, columns...), "tenant, "+key
+	}
+	var b strings.Builder
+	b.WriteString("INSERT INTO " + t.name + " (" + strings.Join(columns, ", ") + ", cached_at)\n")
+	b.WriteString("VALUES (" + strings.Repeat("?, ", len(columns)) + "?)\n")
+	b.WriteString("ON CONFLICT(" + key + ") DO UPDATE SET\n")
+	for _, col := range t.columns[1:] {
+		b.WriteString("\t" + col + " = excluded." + col + ",\n")
+	}
+	b.WriteString("\tcached_at = excluded.cached_at")
+	return b.String()
+}
+
+// insertArgs returns the arguments of upsertQuery for row.
+func (t *entityTable[T]) insertArgs(db *DB, row *T, cachedAt int64) ([]any, error) {
+	args, err := t.args(db, row)
+	if err != nil {
+		return nil, err
+	}
+	if !t.shared {
+		args = append([]any{db.Tenant()}, args...)
+	}
+	return append(args, cachedAt), nil
+}
+
+// scope restricts clause to the DB's tenant, unless the table is shared.
+func (t *entityTable[T]) scope(db *DB, clause string, args []any) (string, []any) {
+	if t.shared {
+		return clause, args
+	}
+	return db.scopeClause(clause, args)
+}
+
+// saveEntity inserts or updates row, stamped with the current time.
+func saveEntity[T any](ctx context.Context, db *DB, t *entityTable[T], row *T) error {
+	args, err := t.insertArgs(db, row, time.Now().Unix())
+	if err != nil {
+		return fmt.Errorf("failed to save %s: %w", t.label, err)
+	}
+	if _, err := db.writeConn(ctx).ExecContext(ctx, t.upsertQuery(), args...); err != nil {
+		return fmt.Errorf("failed to save %s: %w", t.label, err)
+	}
+	return nil
+}
+
+// saveEntities inserts or updates rows in a single transaction, which reduces
+// lock contention during warmup.
+func saveEntities[T any](ctx context.Context, db *DB, t *entityTable[T], rows []*T) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	now := time.Now().Unix()
+	return db.ExecBatchContext(ctx, t.upsertQuery(), func(b *Batch) error {
+		for _, row := range rows {
+			cachedAt := now
+			if t.cachedAt != nil {
+				if preset := t.cachedAt(row); preset != 0 {
+					cachedAt = preset
+				}
+			}
+			args, err := t.insertArgs(db, row, cachedAt)
+			if err != nil {
+				return fmt.Errorf("failed to save %s %s: %w", t.label, t.id(row), err)
+			}
+			if err := b.Exec(args...); err != nil {
+				return fmt.Errorf("failed to save %s %s: %w", t.label, t.id(row), err)
+			}
+		}
+		return nil
+	})
+}
+
+// getEntity returns the row with primary key id regardless of age, or nil if
+// there is none.
+func getEntity[T any](ctx context.Context, db *DB, t *entityTable[T], id string) (*T, error) {
+	clause, args := t.scope(db, "WHERE "+t.columns[0]+" = ?", []any{id})
+	query := t.selectQuery() + " " + clause
+	defer db.analyzeQuery(ctx, query, args, time.Now())
+	if err := db.injectedFault(); err != nil {
+		db.reportError(ctx, OpRead, err)
+		return nil, fmt.Errorf("failed to get %s: %w", t.label, err)
+	}
+	row, err := t.scan(db.Reader().QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		db.reportError(ctx, OpRead, err)
+		return nil, fmt.Errorf("failed to get %s: %w", t.label, err)
+	}
+	return &row, nil
+}
+
+// queryEntities runs selectQuery followed by clause (WHERE, ORDER BY, LIMIT).
+// A WHERE condition with a top-level OR must be parenthesized; see scopeClause.
+func queryEntities[T any](ctx context.Context, db *DB, t *entityTable[T], clause string, args ...any) ([]T, error) {
+	clause, args = t.scope(db, clause, args)
+	query := t.selectQuery() + " " + clause
+	defer db.analyzeQuery(ctx, query, args, time.Now())
+	if err := db.injectedFault(); err != nil {
+		db.reportError(ctx, OpRead, err)
+		return nil, err
+	}
+	rows, err := db.Reader().QueryContext(ctx, query, args...)
+	if err != nil {
+		db.reportError(ctx, OpRead, err)
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	result, err := scanAll(rows, t)
+	db.reportError(ctx, OpRead, err)
+	return result, err
+}
+
+// forEachEntity runs selectQuery followed by clause and calls fn with each
+// row as it is scanned, so callers can stream tables too large to hold in
+// memory. It stops at the first error from fn and returns it unwrapped.
+// fn runs while a reader connection is held; it should not block on other
+// database work.
+func forEachEntity[T any](ctx context.Context, db *DB, t *entityTable[T], clause string, fn func(*T) error, args ...any) error {
+	clause, args = t.scope(db, clause, args)
+	query := t.selectQuery() + " " + clause
+	defer db.analyzeQuery(ctx, query, args, time.Now())
+	if err := db.injectedFault(); err != nil {
+		db.reportError(ctx, OpRead, err)
+		return err
+	}
+	rows, err := db.Reader().QueryContext(ctx, query, args...)
+	if err != nil {
+		db.reportError(ctx, OpRead, err)
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		row, err := t.scan(rows)
+		if err != nil {
+			return fmt.Errorf("failed to scan %s row: %w", t.label, err)
+		}
+		if err := fn(&row); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		db.reportError(ctx, OpRead, err)
+		return err
+	}
+	return nil
+}
+
+// searchEntities runs one page of a search: where filters the rows (args
+// fill its placeholders) and order sorts them. The total count comes from a
+// COUNT(*) over the same filter.
+func searchEntities[T any](ctx context.Context, db *DB, t *entityTable[T], where, order string, page Page, args ...any) (SearchResult[T], error) {
+	limit, offset, err := page.window()
+	if err != nil {
+		return SearchResult[T]{}, err
+	}
+
+	countQuery, countArgs := t.scope(db, where, args)
+	total, err := countRowsWhere(ctx, db, t.name, countQuery, countArgs...)
+	if err != nil {
+		return SearchResult[T]{}, err
+	}
+	if offset >= total {
+		return newSearchResult[T](nil, total, offset), nil
+	}
+
+	items, err := queryEntities(ctx, db, t, where+" "+order+" LIMIT ? OFFSET ?", slices.Concat(args, []any{limit, offset})...)
+	if err != nil {
+		return SearchResult[T]{}, err
+	}
+	return newSearchResult(items, total, offset), nil
+}
+
+// countWhere counts the DB's tenant's rows of from (a tenant-scoped table,
+// optionally aliased) matching where.
+func countWhere(ctx context.Context, db *DB, from, where string, args ...any) (int, error) {
+	where, args = db.scopeClause(where, args)
+	return countRowsWhere(ctx, db, from, where, args...)
+}
+
+// countRowsWhere counts the rows of from matching where, of every tenant.
+func countRowsWhere(ctx context.Context, db *DB, from, where string, args ...any) (int, error) {
+	query := "SELECT COUNT(*) FROM " + from + " " + where
+	defer db.analyzeQuery(ctx, query, args, time.Now())
+	var total int
+	if err := db.Reader().QueryRowContext(ctx, query, args...).Scan(&total); err != nil {
+		db.reportError(ctx, OpRead, err)
+		return 0, err
+	}
+	return total, nil
+}
+
+// scanAll scans every remaining row with t.scan.
+func scanAll[T any](rows *sql.Rows, t *entityTable[T]) ([]T, error) {
+	var result []T
+	for rows.Next() {
+		row, err := t.scan(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan %s row: %w", t.label, err)
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// deleteExpiredRows removes the DB's tenant's rows of table cached before
+// now minus ttl and returns the number removed.
+func (db *DB) deleteExpiredRows(ctx context.Context, table string, ttl time.Duration) (int64, error) {
+	expiryTime := time.Now().Add(-ttl).Unix()
+
+	result, err := db.writeConn(ctx).ExecContext(ctx,
+		"DELETE FROM "+table+" WHERE tenant = ? AND cached_at < ?", db.Tenant(), expiryTime)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired %s: %w", table, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected for %s: %w", table, err)
+	}
+	return rowsAffected, nil
+}
+
+// countRows counts the DB's tenant's rows of table, or every row of the
+// shared stickers table; only unexpired ones when fresh is set.
+func (db *DB) countRows(ctx context.Context, table string, fresh bool) (int, error) {
+	var where []string
+	var args []any
+	if table != tableStickers {
+		where = append(where, "tenant = ?")
+		args = append(args, db.Tenant())
+	}
+	if fresh {
+		where = append(where, "cached_at > ?")
+		args = append(args, db.getTTLTimestamp(table))
+	}
+	query := "SELECT COUNT(*) FROM " + table
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+
+	var count int
+	if err := db.Reader().QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count %s: %w", table, err)
+	}
+	return count, nil
+}
+
+// isFresh reports whether a row of table cached at cachedAt is within the table's TTL.
+func (db *DB) isFresh(table string, cachedAt int64) bool {
+	return cachedAt > db.getTTLTimestamp(table)
+}
@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestEntityTableUpsertQuery(t *testing.T) {
+	t.Parallel()
+
+	want := "INSERT INTO stickers (url, source, cached_at)\n" +
+		"VALUES (?, ?, ?)\n" +
+		"ON CONFLICT(url) DO UPDATE SET\n" +
+		"\tsource = excluded.source,\n" +
+		"\tcached_at = excluded.cached_at"
+	if got := stickerTable.upsertQuery(); got != want {
+		t.Errorf("upsertQuery() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestSaveEntities_PresetCachedAt(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := setupTestDB(t)
+
+	stale := time.Now().Add(-30 * 24 * time.Hour).Unix()
+	contacts := []*Contact{
+		{UID: "fresh", Type: "individual", Name: "王小明"},
+		{UID: "stale", Type: "individual", Name: "李小華", CachedAt: stale},
+	}
+	if err := db.SaveContactsBatch(ctx, contacts); err != nil {
+		t.Fatalf("SaveContactsBatch() error = %v", err)
+	}
+
+	if got, err := db.GetContactByUID(ctx, "fresh"); err != nil || got == nil || got.Name != "王小明" {
+		t.Errorf("GetContactByUID(fresh) = %+v, %v", got, err)
+	}
+	// A preset cached_at is kept, so the stale row reads as expired
+	if got, err := db.GetContactByUID(ctx, "stale"); err != nil || got != nil {
+		t.Errorf("GetContactByUID(stale) = %+v, %v; want nil", got, err)
+	}
+	if n, err := db.DeleteExpiredContacts(ctx, 7*24*time.Hour); err != nil || n != 1 {
+		t.Errorf("DeleteExpiredContacts() = %d, %v; want 1", n, err)
+	}
+}
+
+func TestForEachCourse(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := setupTestDB(t)
+
+	courses := []*Course{
+		{UID: "1131U0002", Year: 113, Term: 1, No: "U0002", Title: "資料結構",
+			RawProgramReqs: []RawProgramReq{{Name: "資工系2", CourseType: "必"}}},
+		{UID: "1131U0001", Year: 113, Term: 1, No: "U0001", Title: "經濟學",
+			RawProgramReqs: []RawProgramReq{{Name: "經濟系1", CourseType: "必"}}},
+		{UID: "1132U0001", Year: 113, Term: 2, No: "U0001", Title: "程式設計",
+			RawProgramReqs: []RawProgramReq{{Name: "資工系1", CourseType: "必"}}},
+		{UID: "1132U0002", Year: 113, Term: 2, No: "U0002", Title: "機器學習",
+			RawProgramReqs: []RawProgramReq{{Name: "資工系碩1", CourseType: "選"}}},
+	}
+	if err := db.SaveCoursesBatch(ctx, courses); err != nil {
+		t.Fatalf("SaveCoursesBatch() error = %v", err)
+	}
+	if err := db.SaveCourseMajorsBatch(ctx, courses); err != nil {
+		t.Fatalf("SaveCourseMajorsBatch() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		filter CourseFilter
+		want   []string
+	}{
+		{"all, newest semester first", CourseFilter{}, []string{"1132U0001", "1132U0002", "1131U0001", "1131U0002"}},
+		{"semester", CourseFilter{Year: 113, Term: 1}, []string{"1131U0001", "1131U0002"}},
+		{"exact majors", CourseFilter{Majors: []string{"資工系1", "資工系2"}}, []string{"1132U0001", "1131U0002"}},
+		{"graduate major", CourseFilter{Majors: []string{"資工系碩1"}}, []string{"1132U0002"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var got []string
+			err := db.ForEachCourse(ctx, tt.filter, func(c *Course) error {
+				got = append(got, c.UID)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("ForEachCourse() error = %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ForEachCourse() visited %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// An error from fn stops the iteration and is returned
+	errStop := errors.New("stop")
+	visited := 0
+	err := db.ForEachCourse(ctx, CourseFilter{}, func(*Course) error {
+		visited++
+		return errStop
+	})
+	if !errors.Is(err, errStop) || visited != 1 {
+		t.Errorf("ForEachCourse() = %v after %d rows, want errStop after 1", err, visited)
+	}
+}
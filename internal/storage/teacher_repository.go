@@ -0,0 +1,278 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TeacherID returns the stable ID of a teacher. The course system links each
+// teacher to a timetable URL whose query identifies the teacher, so the ID is
+// derived from the name plus that query (semester parameters removed). Without
+// a URL it falls back to the name alone.
+func TeacherID(name, teacherURL string) string {
+	key := name
+	if u, err := url.Parse(teacherURL); err == nil && teacherURL != "" {
+		params := u.Query()
+		for k := range params {
+			lower := strings.ToLower(k)
+			if strings.Contains(lower, "year") || strings.Contains(lower, "term") || strings.Contains(lower, "seme") {
+				params.Del(k)
+			}
+		}
+		if query := params.Encode(); query != "" {
+			key = name + "?" + query
+		}
+	}
+	sum := sha256.Sum256([]byte(key))
+	return "t" + hex.EncodeToString(sum[:6])
+}
+
+// courseTeachers returns the teachers of a course in listed order, without duplicates.
+// URLs are paired by position only when every teacher has one; the scraper skips
+// links without a query, which would otherwise shift the pairing.
+func courseTeachers(course *Course) []Teacher {
+	pairURLs := len(course.TeacherURLs) == len(course.Teachers)
+	teachers := make([]Teacher, 0, len(course.Teachers))
+	seen := make(map[string]bool, len(course.Teachers))
+	for i, name := range course.Teachers {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		var teacherURL string
+		if pairURLs {
+			teacherURL = course.TeacherURLs[i]
+		}
+		id := TeacherID(name, teacherURL)
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		teachers = append(teachers, Teacher{ID: id, Name: name, URL: teacherURL})
+	}
+	return teachers
+}
+
+// SaveCourseTeachersBatch upserts the teachers of each course and links them in
+// course_teachers. Department and profile are kept; RefreshTeacherProfiles fills them.
+// Links a course no longer lists expire via DeleteExpiredTeachers.
+func (db *DB) SaveCourseTeachersBatch(ctx context.Context, courses []*Course) error {
+	if len(courses) == 0 {
+		return nil
+	}
+
+	now, tenant := time.Now().Unix(), db.Tenant()
+	teacherQuery := `
+		INSERT INTO teachers (tenant, id, name, url, cached_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(tenant, id) DO UPDATE SET
+			name = excluded.name,
+			url = COALESCE(excluded.url, teachers.url),
+			cached_at = excluded.cached_at
+	`
+	if err := db.ExecBatchContext(ctx, teacherQuery, func(b *Batch) error {
+		for _, course := range courses {
+			for _, t := range courseTeachers(course) {
+				if err := b.Exec(tenant, t.ID, t.Name, nullString(t.URL), now); err != nil {
+					return fmt.Errorf("failed to save teacher %s for course %s: %w", t.Name, course.UID, err)
+				}
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	linkQuery := `
+		INSERT INTO course_teachers (tenant, course_uid, teacher_id, position, cached_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(tenant, course_uid, teacher_id) DO UPDATE SET
+			position = excluded.position,
+			cached_at = excluded.cached_at
+	`
+	return db.ExecBatchContext(ctx, linkQuery, func(b *Batch) error {
+		for _, course := range courses {
+			for i, t := range courseTeachers(course) {
+				if err := b.Exec(tenant, course.UID, t.ID, i, now); err != nil {
+					return fmt.Errorf("failed to link teacher %s to course %s: %w", t.Name, course.UID, err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// RefreshTeacherProfiles derives each teacher's department from the 應修系級 of
+// their courses (most frequent, grade digits removed, e.g., "資工系3" → "資工系")
+// and links the contact directory entry when exactly one individual has the name.
+// Full-time entries take precedence: a part-time (兼任) entry is linked only
+// when no full-time individual has the name.
+// Run after course majors and contacts are refreshed.
+func (db *DB) RefreshTeacherProfiles(ctx context.Context) error {
+	departmentQuery := `
+		UPDATE teachers SET department = (
+			SELECT rtrim(m.major, '0123456789') AS dept
+			FROM course_teachers ct
+			JOIN course_majors m ON m.tenant = ct.tenant AND m.course_uid = ct.course_uid
+			WHERE ct.tenant = teachers.tenant AND ct.teacher_id = teachers.id
+			GROUP BY dept
+			ORDER BY COUNT(*) DESC, dept
+			LIMIT 1
+		)
+		WHERE tenant = ?`
+	tenant := db.Tenant()
+	if _, err := db.writeConn(ctx).ExecContext(ctx, departmentQuery, tenant); err != nil {
+		return fmt.Errorf("failed to refresh teacher departments: %w", err)
+	}
+
+	profileQuery := `
+		UPDATE teachers SET profile = (
+			SELECT json_object(
+				'organization', c.organization, 'title', c.title, 'email', c.email,
+				'extension', c.extension, 'website', c.website,
+				'part_time', json(CASE WHEN c.part_time THEN 'true' ELSE 'false' END))
+			FROM contacts c
+			WHERE c.tenant = teachers.tenant AND c.type = 'individual' AND c.name = teachers.name
+			ORDER BY COALESCE(c.part_time, 0)
+			LIMIT 1
+		)
+		WHERE tenant = ? AND (
+			SELECT CASE WHEN SUM(NOT COALESCE(c.part_time, 0)) > 0 THEN SUM(NOT COALESCE(c.part_time, 0)) ELSE COUNT(*) END
+			FROM contacts c
+			WHERE c.tenant = teachers.tenant AND c.type = 'individual' AND c.name = teachers.name
+		) = 1`
+	if _, err := db.writeConn(ctx).ExecContext(ctx, profileQuery, tenant); err != nil {
+		return fmt.Errorf("failed to refresh teacher profiles: %w", err)
+	}
+	return nil
+}
+
+// GetTeachersByName returns the non-expired teachers with exactly this name,
+// ordered by department. More than one result means the name is ambiguous.
+func (db *DB) GetTeachersByName(ctx context.Context, name string) ([]Teacher, error) {
+	if name == "" {
+		return nil, errors.New("teacher name is required")
+	}
+	if len(name) > 100 {
+		return nil, errors.New("search term too long")
+	}
+
+	query := `SELECT id, name, url, department, profile, cached_at
+		FROM teachers
+		WHERE tenant = ? AND name = ? AND cached_at > ?
+		ORDER BY department, id`
+
+	rows, err := db.Reader().QueryContext(ctx, query, db.Tenant(), name, db.getTTLTimestamp(TableTeachers))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get teachers by name: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var teachers []Teacher
+	for rows.Next() {
+		t, err := scanTeacher(rows)
+		if err != nil {
+			return nil, err
+		}
+		teachers = append(teachers, *t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate teachers: %w", err)
+	}
+	return teachers, nil
+}
+
+// GetTeacherByID returns a non-expired teacher, or nil if not found.
+func (db *DB) GetTeacherByID(ctx context.Context, id string) (*Teacher, error) {
+	query := `SELECT id, name, url, department, profile, cached_at
+		FROM teachers
+		WHERE tenant = ? AND id = ? AND cached_at > ?`
+
+	t, err := scanTeacher(db.Reader().QueryRowContext(ctx, query, db.Tenant(), id, db.getTTLTimestamp(TableTeachers)))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// GetCoursesByTeacherID returns the non-expired courses linked to a teacher,
+// newest semester first.
+func (db *DB) GetCoursesByTeacherID(ctx context.Context, id string) ([]Course, error) {
+	if id == "" {
+		return nil, errors.New("teacher ID is required")
+	}
+
+	query := `SELECT c.uid, c.year, c.term, c.no, c.title, c.teachers, c.teacher_urls, c.times, c.locations, c.detail_url, c.note, c.title_en, c.cached_at
+		FROM courses c
+		JOIN course_teachers ct ON ct.tenant = c.tenant AND ct.course_uid = c.uid
+		WHERE ct.tenant = ? AND ct.teacher_id = ? AND c.cached_at > ?
+		ORDER BY c.year DESC, c.term DESC, c.no`
+
+	rows, err := db.Reader().QueryContext(ctx, query, db.Tenant(), id, db.getTTLTimestamp(TableCourses))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get courses by teacher: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanCourses(rows)
+}
+
+// DeleteExpiredTeachers removes course-teacher links and teachers older than the
+// specified TTL. Returns the number of deleted entries.
+func (db *DB) DeleteExpiredTeachers(ctx context.Context, ttl time.Duration) (int64, error) {
+	expiryTime := time.Now().Add(-ttl).Unix()
+
+	var total int64
+	for _, query := range []string{
+		`DELETE FROM course_teachers WHERE tenant = ? AND cached_at < ?`,
+		`DELETE FROM teachers WHERE tenant = ? AND cached_at < ?`,
+	} {
+		result, err := db.writeConn(ctx).ExecContext(ctx, query, db.Tenant(), expiryTime)
+		if err != nil {
+			return total, fmt.Errorf("failed to delete expired teachers: %w", err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return total, fmt.Errorf("failed to get rows affected for teachers: %w", err)
+		}
+		total += rowsAffected
+	}
+	return total, nil
+}
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTeacher(row rowScanner) (*Teacher, error) {
+	var t Teacher
+	var teacherURL, department, profile sql.NullString
+	if err := row.Scan(&t.ID, &t.Name, &teacherURL, &department, &profile, &t.CachedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan teacher row: %w", err)
+	}
+	t.URL = teacherURL.String
+	t.Department = department.String
+	if profile.Valid && profile.String != "" {
+		var p TeacherProfile
+		if err := json.Unmarshal([]byte(profile.String), &p); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal teacher profile: %w", err)
+		}
+		t.Profile = &p
+	}
+	return &t, nil
+}
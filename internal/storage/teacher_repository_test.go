@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTeacherID(t *testing.T) {
+	t.Parallel()
+
+	const base = "https://sea.cc.ntpu.edu.tw/pls/faculty/tec_course_table.s_table?"
+	tests := []struct {
+		name       string
+		a, b       [2]string // name, URL
+		wantSameID bool
+	}{
+		{"same teacher across semesters", [2]string{"王小明", base + "teacher=A01&year=113&term=1"}, [2]string{"王小明", base + "term=2&teacher=A01&year=113"}, true},
+		{"same name different teacher", [2]string{"王小明", base + "teacher=A01"}, [2]string{"王小明", base + "teacher=B02"}, false},
+		{"name only", [2]string{"王小明", ""}, [2]string{"王小明", ""}, true},
+		{"name only vs URL", [2]string{"王小明", ""}, [2]string{"王小明", base + "teacher=A01"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			idA, idB := TeacherID(tt.a[0], tt.a[1]), TeacherID(tt.b[0], tt.b[1])
+			if (idA == idB) != tt.wantSameID {
+				t.Errorf("TeacherID equal = %v, want %v (%s vs %s)", idA == idB, tt.wantSameID, idA, idB)
+			}
+		})
+	}
+}
+
+func TestCourseTeachers(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	const base = "https://sea.cc.ntpu.edu.tw/pls/faculty/tec_course_table.s_table?teacher="
+	courses := []*Course{
+		{UID: "1131U0001", Year: 113, Term: 1, No: "U0001", Title: "程式設計",
+			Teachers: []string{"王小明"}, TeacherURLs: []string{base + "A01"},
+			RawProgramReqs: []RawProgramReq{{Name: "資工系1", CourseType: "必"}}},
+		{UID: "1131U0002", Year: 113, Term: 1, No: "U0002", Title: "資料結構",
+			Teachers: []string{"王小明", "李大華"}, TeacherURLs: []string{base + "A01", base + "C03"},
+			RawProgramReqs: []RawProgramReq{{Name: "資工系2", CourseType: "必"}}},
+		{UID: "1131U0003", Year: 113, Term: 1, No: "U0003", Title: "經濟學",
+			Teachers: []string{"王小明"}, TeacherURLs: []string{base + "B02"},
+			RawProgramReqs: []RawProgramReq{{Name: "經濟系1", CourseType: "必"}}},
+		{UID: "1131U0004", Year: 113, Term: 1, No: "U0004", Title: "資訊倫理",
+			Teachers: []string{"陳小美"}, TeacherURLs: []string{base + "D04"},
+			RawProgramReqs: []RawProgramReq{{Name: "資工系1", CourseType: "選"}}},
+	}
+	if err := db.SaveCoursesBatch(ctx, courses); err != nil {
+		t.Fatalf("SaveCoursesBatch failed: %v", err)
+	}
+	if err := db.SaveCourseMajorsBatch(ctx, courses); err != nil {
+		t.Fatalf("SaveCourseMajorsBatch failed: %v", err)
+	}
+	if err := db.SaveContactsBatch(ctx, []*Contact{
+		{UID: "c1", Type: "individual", Name: "李大華", Organization: "資訊工程學系", Title: "教授"},
+		// A part-time entry with a full-time namesake is not linked
+		{UID: "c2", Type: "individual", Name: "李大華", Organization: "通識教育中心", Title: "兼任講師", PartTime: true},
+		{UID: "c3", Type: "individual", Name: "陳小美", Organization: "資訊工程學系", Title: "兼任助理教授", PartTime: true},
+	}); err != nil {
+		t.Fatalf("SaveContactsBatch failed: %v", err)
+	}
+	// Saving the same course again must upsert
+	if err := db.SaveCourse(ctx, courses[0]); err != nil {
+		t.Fatalf("SaveCourse failed: %v", err)
+	}
+	if err := db.RefreshTeacherProfiles(ctx); err != nil {
+		t.Fatalf("RefreshTeacherProfiles failed: %v", err)
+	}
+
+	wang, err := db.GetTeachersByName(ctx, "王小明")
+	if err != nil {
+		t.Fatalf("GetTeachersByName failed: %v", err)
+	}
+	if len(wang) != 2 {
+		t.Fatalf("GetTeachersByName(王小明) returned %d teachers, want 2", len(wang))
+	}
+	if wang[0].Department != "經濟系" || wang[1].Department != "資工系" {
+		t.Errorf("departments = %q, %q, want 經濟系, 資工系", wang[0].Department, wang[1].Department)
+	}
+
+	csWang := TeacherID("王小明", base+"A01")
+	got, err := db.GetCoursesByTeacherID(ctx, csWang)
+	if err != nil {
+		t.Fatalf("GetCoursesByTeacherID failed: %v", err)
+	}
+	if len(got) != 2 || got[0].UID != "1131U0001" || got[1].UID != "1131U0002" {
+		t.Errorf("GetCoursesByTeacherID returned %+v, want U0001 and U0002", got)
+	}
+
+	lee, err := db.GetTeacherByID(ctx, TeacherID("李大華", base+"C03"))
+	if err != nil {
+		t.Fatalf("GetTeacherByID failed: %v", err)
+	}
+	if lee == nil || lee.Profile == nil || lee.Profile.Title != "教授" || lee.Profile.PartTime {
+		t.Errorf("GetTeacherByID(李大華) = %+v, want profile with title 教授", lee)
+	}
+	chen, err := db.GetTeacherByID(ctx, TeacherID("陳小美", base+"D04"))
+	if err != nil {
+		t.Fatalf("GetTeacherByID failed: %v", err)
+	}
+	if chen == nil || chen.Profile == nil || !chen.Profile.PartTime || chen.Profile.Title != "兼任助理教授" {
+		t.Errorf("GetTeacherByID(陳小美) = %+v, want part-time profile", chen)
+	}
+	if missing, err := db.GetTeacherByID(ctx, "tmissing"); err != nil || missing != nil {
+		t.Errorf("GetTeacherByID(missing) = %+v, %v, want nil, nil", missing, err)
+	}
+
+	if _, err := db.DeleteExpiredTeachers(ctx, 0); err != nil {
+		t.Fatalf("DeleteExpiredTeachers failed: %v", err)
+	}
+	if _, err := db.GetTeachersByName(ctx, ""); err == nil {
+		t.Error("expected error for empty teacher name")
+	}
+}
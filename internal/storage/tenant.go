@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"strings"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
+)
+
+// DefaultTenant is the tenant of rows saved before tenants existed, and of a
+// DB whose tenant was never set.
+const DefaultTenant = config.DefaultTenant
+
+// Every cache table except stickers has a tenant column leading its primary
+// key and indexes, so one database can hold several schools' data: student
+// IDs and course UIDs are school-issued and may collide across tenants.
+// Repository methods read and write only the rows of the DB's tenant.
+
+// tableStickers is the one cache table shared by every tenant: stickers are
+// not school data.
+const tableStickers = "stickers"
+
+// SetTenant sets the tenant whose rows the repository methods read and
+// write (DefaultTenant if empty). Call it before the DB is shared;
+// SwapConnections keeps the tenant.
+func (db *DB) SetTenant(tenant string) {
+	if tenant == "" {
+		tenant = DefaultTenant
+	}
+	db.tenant.Store(&tenant)
+}
+
+// Tenant returns the tenant set by SetTenant, or DefaultTenant.
+func (db *DB) Tenant() string {
+	if tenant := db.tenant.Load(); tenant != nil {
+		return *tenant
+	}
+	return DefaultTenant
+}
+
+// scopeClause restricts clause (empty, or starting with WHERE, ORDER BY or
+// LIMIT) of a single-table query to the DB's tenant, returning it with the
+// tenant prepended to args. The tenant condition is ANDed in front of a
+// WHERE condition, so one with a top-level OR must be parenthesized.
+func (db *DB) scopeClause(clause string, args []any) (string, []any) {
+	args = append([]any{db.Tenant()}, args...)
+	trimmed := strings.TrimSpace(clause)
+	if rest, ok := strings.CutPrefix(trimmed, "WHERE "); ok {
+		return "WHERE tenant = ? AND " + rest, args
+	}
+	return strings.TrimSpace("WHERE tenant = ? " + trimmed), args
+}
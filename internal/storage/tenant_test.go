@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTenant_ScopesRows(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := setupTestDB(t)
+
+	if got := db.Tenant(); got != DefaultTenant {
+		t.Errorf("Tenant() = %q, want %q", got, DefaultTenant)
+	}
+
+	// Student IDs and course UIDs are school-issued, so tenants may reuse them
+	save := func(name, title string) {
+		t.Helper()
+		if err := db.SaveStudent(ctx, &Student{ID: "41247001", Name: name, Year: 112, Department: "資工"}); err != nil {
+			t.Fatalf("SaveStudent() error = %v", err)
+		}
+		course := &Course{UID: "1131U0001", Year: 113, Term: 1, No: "U0001", Title: title,
+			RawProgramReqs: []RawProgramReq{{Name: "資工系1", CourseType: "必"}}}
+		if err := db.SaveCourse(ctx, course); err != nil {
+			t.Fatalf("SaveCourse() error = %v", err)
+		}
+		if err := db.SaveCourseMajorsBatch(ctx, []*Course{course}); err != nil {
+			t.Fatalf("SaveCourseMajorsBatch() error = %v", err)
+		}
+	}
+	check := func(name, title string) {
+		t.Helper()
+		student, err := db.GetStudentByID(ctx, "41247001")
+		if err != nil || student == nil || student.Name != name {
+			t.Errorf("%s: GetStudentByID() = (%+v, %v), want name %q", db.Tenant(), student, err, name)
+		}
+		if n, err := db.CountStudents(ctx); err != nil || n != 1 {
+			t.Errorf("%s: CountStudents() = (%d, %v), want 1", db.Tenant(), n, err)
+		}
+		courses, err := db.GetCoursesByMajor(ctx, 113, 1, "資工系")
+		if err != nil || len(courses) != 1 || courses[0].Title != title {
+			t.Errorf("%s: GetCoursesByMajor() = (%+v, %v), want one course %q", db.Tenant(), courses, err, title)
+		}
+	}
+
+	save("王小明", "資料結構")
+
+	db.SetTenant("nccu")
+	if student, err := db.GetStudentByID(ctx, "41247001"); err != nil || student != nil {
+		t.Errorf("nccu: GetStudentByID() before save = (%+v, %v), want nil", student, err)
+	}
+	save("陳大文", "計算機概論")
+	check("陳大文", "計算機概論")
+
+	db.SetTenant("")
+	if got := db.Tenant(); got != DefaultTenant {
+		t.Errorf("Tenant() after SetTenant(\"\") = %q, want %q", got, DefaultTenant)
+	}
+	check("王小明", "資料結構")
+}
+
+func TestInitSchema_RebuildsPreTenantTables(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	// Databases created before tenants existed key students by id alone
+	for _, stmt := range []string{
+		`DROP TABLE students`,
+		`CREATE TABLE students (id TEXT PRIMARY KEY, name TEXT NOT NULL, year INTEGER, department TEXT, cached_at INTEGER NOT NULL) STRICT`,
+		`CREATE INDEX idx_students_name ON students(name)`,
+		`INSERT INTO students (id, name, year, department, cached_at) VALUES ('41247001', '王小明', 112, '資工', unixepoch())`,
+	} {
+		if _, err := db.Writer().ExecContext(ctx, stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	if err := InitSchema(ctx, db.Writer()); err != nil {
+		t.Fatalf("InitSchema() error = %v", err)
+	}
+	if err := InitSchema(ctx, db.Writer()); err != nil {
+		t.Fatalf("second InitSchema() error = %v", err)
+	}
+	if err := verifyIndexes(ctx, db.Writer()); err != nil {
+		t.Errorf("verifyIndexes() error = %v", err)
+	}
+
+	student, err := db.GetStudentByID(ctx, "41247001")
+	if err != nil || student == nil || student.Name != "王小明" {
+		t.Errorf("GetStudentByID() = (%+v, %v), want the pre-tenant row", student, err)
+	}
+
+	db.SetTenant("nccu")
+	if err := db.SaveStudent(ctx, &Student{ID: "41247001", Name: "陳大文", Year: 112}); err != nil {
+		t.Errorf("SaveStudent() for another tenant error = %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
+)
+
+// Cache table names, as passed to TableTTL, a TTLOverride and the
+// DeleteExpired helpers.
+const (
+	TableStudents            = "students"
+	TableContacts            = "contacts"
+	TableCourses             = "courses"
+	TableHistoricalCourses   = "historical_courses"
+	TablePrograms            = "programs"
+	TableCoursePrograms      = "course_programs"
+	TableCourseMajors        = "course_majors"
+	TableTeachers            = "teachers"
+	TableCourseTeachers      = "course_teachers"
+	TableCourseSections      = "course_sections"
+	TableCourseFlags         = "course_flags"
+	TableCoursePrerequisites = "course_prerequisites"
+	TableSyllabi             = "syllabi"
+	TableDepartmentNews      = "department_news"
+	TableLookupMisses        = "lookup_misses" // negative cache; see SaveLookupMiss
+)
+
+// SetTTLs sets the per-table cache TTLs. Zero fields keep the TTL passed to
+// New. Call it before the DB is shared; SwapConnections keeps the policy.
+func (db *DB) SetTTLs(policy config.TTLPolicy) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.ttls = policy
+}
+
+// TableTTL returns the cache TTL of table: the per-table TTL from SetTTLs,
+// or the TTL passed to New for tables without one (students, programs).
+func (db *DB) TableTTL(table string) time.Duration {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var ttl time.Duration
+	switch table {
+	case TableContacts:
+		ttl = db.ttls.Contacts
+	case TableCourses, TableHistoricalCourses, TableCoursePrograms, TableCourseMajors,
+		TableTeachers, TableCourseTeachers, TableCourseSections, TableCourseFlags:
+		ttl = db.ttls.Courses
+	case TableSyllabi, TableCoursePrerequisites:
+		ttl = db.ttls.Syllabi
+	case TableLookupMisses:
+		ttl = db.ttls.Negative
+	case TableDepartmentNews:
+		ttl = db.ttls.News
+	}
+	if ttl <= 0 {
+		return db.cacheTTL
+	}
+	return ttl
+}
+
+// getTTLTimestamp returns the Unix timestamp for the TTL cutoff of table
+// (entries cached at or before it are expired).
+func (db *DB) getTTLTimestamp(table string) int64 {
+	return time.Now().Unix() - int64(db.TableTTL(table).Seconds())
+}
+
+// TTLOverride returns the cache TTL for table at now, given the configured TTL.
+// It lets a caller shorten freshness while a table's source changes often,
+// without touching the stored rows; return ttl to keep the configured value.
+type TTLOverride func(table string, now time.Time, ttl time.Duration) time.Duration
+
+// SetTTLOverride installs override for the lookups that fall back to scraping
+// on a miss: GetCourseByUID and the course title/teacher searches. List
+// queries used by warmup-backed features keep the configured TTL, since the
+// daily refresh is what updates them. Call it before the DB is shared; nil
+// removes the override.
+func (db *DB) SetTTLOverride(override TTLOverride) {
+	db.ttlOverride = override
+}
+
+// lookupTTLTimestamp returns the TTL cutoff for table after applying the override.
+func (db *DB) lookupTTLTimestamp(table string) int64 {
+	if db.ttlOverride == nil {
+		return db.getTTLTimestamp(table)
+	}
+	now := time.Now()
+	ttl := db.ttlOverride(table, now, db.TableTTL(table))
+	return now.Unix() - int64(ttl.Seconds())
+}
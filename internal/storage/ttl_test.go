@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/garyellow/ntpu-linebot-go/internal/config"
+)
+
+func TestSetTTLOverride(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	course := &Course{UID: "1141U0001", Year: 114, Term: 1, No: "U0001", Title: "微積分", Teachers: []string{"王教授"}}
+	if err := db.SaveCourse(ctx, course); err != nil {
+		t.Fatalf("SaveCourse() error = %v", err)
+	}
+	contact := &Contact{UID: "c1", Type: "individual", Name: "王教授", Extension: "12345"}
+	if err := db.SaveContact(ctx, contact); err != nil {
+		t.Fatalf("SaveContact() error = %v", err)
+	}
+
+	var tables []string
+	db.SetTTLOverride(func(table string, _ time.Time, ttl time.Duration) time.Duration {
+		tables = append(tables, table)
+		if table == TableCourses {
+			return 0 // Everything cached so far is expired
+		}
+		return ttl
+	})
+
+	if got, err := db.GetCourseByUID(ctx, course.UID); err != nil || got != nil {
+		t.Errorf("GetCourseByUID() = %v, %v; want expired", got, err)
+	}
+	if got, err := db.SearchCoursesByTitle(ctx, "微積分"); err != nil || len(got) != 0 {
+		t.Errorf("SearchCoursesByTitle() = %d courses, %v; want none", len(got), err)
+	}
+	if got, err := db.SearchCoursesByTeacher(ctx, "王教授"); err != nil || len(got) != 0 {
+		t.Errorf("SearchCoursesByTeacher() = %d courses, %v; want none", len(got), err)
+	}
+	// List queries keep the configured TTL
+	if got, err := db.GetCoursesByYearTerm(ctx, 114, 1); err != nil || len(got) != 1 {
+		t.Errorf("GetCoursesByYearTerm() = %d courses, %v; want 1", len(got), err)
+	}
+	if got, err := db.SearchContactsByName(ctx, "王教授"); err != nil || len(got) != 1 {
+		t.Errorf("SearchContactsByName() = %d contacts, %v; want 1", len(got), err)
+	}
+	for _, table := range tables {
+		if table != TableCourses {
+			t.Errorf("override called for table %q", table)
+		}
+	}
+
+	db.SetTTLOverride(nil)
+	if got, err := db.GetCourseByUID(ctx, course.UID); err != nil || got == nil {
+		t.Errorf("GetCourseByUID() without override = %v, %v; want the course", got, err)
+	}
+}
+
+func TestSetTTLs(t *testing.T) {
+	t.Parallel()
+	db := setupTestDB(t) // 168h default
+	ctx := context.Background()
+
+	db.SetTTLs(config.TTLPolicy{Contacts: 720 * time.Hour, Courses: 24 * time.Hour})
+	tests := []struct {
+		table string
+		want  time.Duration
+	}{
+		{"contacts", 720 * time.Hour},
+		{TableCourses, 24 * time.Hour},
+		{"course_sections", 24 * time.Hour},
+		{"syllabi", 168 * time.Hour}, // Zero falls back to the default
+		{"students", 168 * time.Hour},
+	}
+	for _, tt := range tests {
+		if got := db.TableTTL(tt.table); got != tt.want {
+			t.Errorf("TableTTL(%q) = %v, want %v", tt.table, got, tt.want)
+		}
+	}
+
+	// A 2-day-old course is expired, a 2-day-old contact is not
+	cachedAt := time.Now().Add(-48 * time.Hour).Unix()
+	course := &Course{UID: "1141U0001", Year: 114, Term: 1, No: "U0001", Title: "微積分"}
+	if err := db.SaveCourse(ctx, course); err != nil {
+		t.Fatalf("SaveCourse() error = %v", err)
+	}
+	if _, err := db.ExecContext(ctx, `UPDATE courses SET cached_at = ?`, cachedAt); err != nil {
+		t.Fatalf("age course: %v", err)
+	}
+	contact := &Contact{UID: "c1", Type: "individual", Name: "王教授", Extension: "12345"}
+	if err := db.SaveContact(ctx, contact); err != nil {
+		t.Fatalf("SaveContact() error = %v", err)
+	}
+	if _, err := db.ExecContext(ctx, `UPDATE contacts SET cached_at = ?`, cachedAt); err != nil {
+		t.Fatalf("age contact: %v", err)
+	}
+
+	if got, err := db.GetCourseByUID(ctx, course.UID); err != nil || got != nil {
+		t.Errorf("GetCourseByUID() = %v, %v; want expired", got, err)
+	}
+	if got, err := db.GetContactByUID(ctx, contact.UID); err != nil || got == nil {
+		t.Errorf("GetContactByUID() = %v, %v; want the contact", got, err)
+	}
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// writeConn is the part of *sql.DB and *sql.Tx the repositories write through.
+type writeConn interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+type txKey struct{}
+
+// WithTx runs fn in a single write transaction so related rows (a course and
+// its teachers, sections, programs and prerequisites) are saved together or
+// not at all. Repository writes made with the ctx passed to fn join the
+// transaction; it commits when fn returns nil and rolls back otherwise.
+//
+// Calls nest: a WithTx inside fn joins the outer transaction. Reads still go
+// to the reader pool and do not see the uncommitted rows, and batch policies
+// do not apply inside a transaction (the outer call decides).
+func (db *DB) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if txFrom(ctx) != nil {
+		return fn(ctx)
+	}
+
+	tx, err := db.Writer().BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }() // No-op after Commit
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// writeConn returns the transaction started by WithTx, or the writer.
+// Failed writes through it are passed to the error reporter.
+func (db *DB) writeConn(ctx context.Context) writeConn {
+	if tx := txFrom(ctx); tx != nil {
+		return reportingConn{conn: tx, db: db}
+	}
+	return reportingConn{conn: db.Writer(), db: db}
+}
+
+// txFrom returns the transaction started by WithTx, or nil.
+func txFrom(ctx context.Context) *sql.Tx {
+	tx, _ := ctx.Value(txKey{}).(*sql.Tx)
+	return tx
+}
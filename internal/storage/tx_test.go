@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestWithTx(t *testing.T) {
+	t.Parallel()
+
+	errAbort := errors.New("abort")
+	tests := []struct {
+		name     string
+		fnErr    error
+		wantErr  error
+		wantRows int
+	}{
+		{name: "commit", wantRows: 1},
+		{name: "rollback on error", fnErr: errAbort, wantErr: errAbort, wantRows: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			db := setupTestDB(t)
+			ctx := context.Background()
+
+			course := &Course{
+				UID:      "1131U0001",
+				Year:     113,
+				Term:     1,
+				No:       "U0001",
+				Title:    "資料結構",
+				Teachers: []string{"王教授"},
+			}
+			programs := []ProgramRequirement{{ProgramName: "資訊學程", CourseType: "必"}}
+
+			err := db.WithTx(ctx, func(ctx context.Context) error {
+				if err := db.SaveCourse(ctx, course); err != nil { // Nested WithTx joins
+					return err
+				}
+				if err := db.SaveCoursePrograms(ctx, course.UID, programs); err != nil {
+					return err
+				}
+				return tt.fnErr
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("WithTx() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if got, err := db.CountCourses(ctx); err != nil || got != tt.wantRows {
+				t.Errorf("CountCourses() = %d, %v; want %d", got, err, tt.wantRows)
+			}
+			got, err := db.GetCoursePrograms(ctx, course.UID)
+			if err != nil {
+				t.Fatalf("GetCoursePrograms() error = %v", err)
+			}
+			if len(got) != tt.wantRows {
+				t.Errorf("GetCoursePrograms() = %d rows, want %d", len(got), tt.wantRows)
+			}
+		})
+	}
+}
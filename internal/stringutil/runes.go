@@ -0,0 +1,119 @@
+package stringutil
+
+import "unicode"
+
+// RuneSignature is a 64-bit Bloom signature of the runes in a string: each
+// lowercased rune sets one bit. A string can only contain every rune of a
+// query if its signature covers the query's, so comparing signatures rules
+// out most candidates of a fuzzy search without reading their runes.
+//
+// Signatures are meant to be computed once per cached name or title and kept
+// alongside it, see RuneQuery.MatchSigned.
+type RuneSignature uint64
+
+// NewRuneSignature returns the signature of s.
+func NewRuneSignature(s string) RuneSignature {
+	var sig RuneSignature
+	for _, r := range s {
+		sig |= runeBit(unicode.ToLower(r))
+	}
+	return sig
+}
+
+// Covers reports whether every bit of q is set in sig, i.e., whether the
+// string of sig may contain every rune of the string of q.
+func (sig RuneSignature) Covers(q RuneSignature) bool {
+	return sig&q == q
+}
+
+// runeBit maps a rune to one of 64 bits with a multiplicative hash, so
+// neighboring CJK code points spread over the whole signature.
+func runeBit(r rune) RuneSignature {
+	return 1 << (uint32(r) * 0x9E3779B1 >> 26)
+}
+
+// maxStackRunes is the number of distinct query runes counted without
+// allocating; longer queries are rare (search terms are a few characters).
+const maxStackRunes = 16
+
+// RuneQuery is a search term prepared for ContainsAllRunes matching against
+// many candidates: its runes are lowercased and counted once, not per call.
+type RuneQuery struct {
+	runes []rune // Distinct lowercased runes
+	need  []int  // Required occurrences of each rune
+	total int
+	sig   RuneSignature
+}
+
+// NewRuneQuery prepares chars for matching.
+func NewRuneQuery(chars string) RuneQuery {
+	return newRuneQuery(chars, nil, nil)
+}
+
+// newRuneQuery prepares chars in the given buffers, which lets
+// ContainsAllRunes keep a one-off query on the stack.
+func newRuneQuery(chars string, runes []rune, need []int) RuneQuery {
+	q := RuneQuery{runes: runes[:0], need: need[:0]}
+	for _, r := range chars {
+		r = unicode.ToLower(r)
+		q.total++
+		q.sig |= runeBit(r)
+		if i := q.index(r); i >= 0 {
+			q.need[i]++
+			continue
+		}
+		q.runes = append(q.runes, r)
+		q.need = append(q.need, 1)
+	}
+	return q
+}
+
+// Signature returns the signature of the query's runes.
+func (q RuneQuery) Signature() RuneSignature {
+	return q.sig
+}
+
+// Match reports whether s contains every rune of the query, as
+// ContainsAllRunes(s, chars) does.
+func (q RuneQuery) Match(s string) bool {
+	if q.total == 0 {
+		return true
+	}
+	if len(s) == 0 {
+		return false
+	}
+
+	var stack [maxStackRunes]int
+	have := stack[:]
+	if len(q.runes) > maxStackRunes {
+		have = make([]int, len(q.runes))
+	}
+	missing := q.total
+	for _, r := range s {
+		i := q.index(unicode.ToLower(r))
+		if i < 0 || have[i] >= q.need[i] {
+			continue
+		}
+		have[i]++
+		if missing--; missing == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchSigned is Match for a string whose signature is known; sig must be
+// NewRuneSignature(s). Strings whose signature does not cover the query's
+// are rejected without reading them.
+func (q RuneQuery) MatchSigned(s string, sig RuneSignature) bool {
+	return sig.Covers(q.sig) && q.Match(s)
+}
+
+func (q RuneQuery) index(r rune) int {
+	for i, qr := range q.runes {
+		if qr == r {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,125 @@
+package stringutil
+
+import (
+	"math/rand/v2"
+	"strings"
+	"testing"
+)
+
+func TestRuneQuery_MatchSigned(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		s     string
+		chars string
+		want  bool
+	}{
+		{"Empty chars", "王小明", "", true},
+		{"Empty s", "", "王", false},
+		{"Non-contiguous", "王小明", "明王", true},
+		{"Missing rune", "王小明", "王大", false},
+		{"Duplicate runes", "程程式設計", "程程", true},
+		{"Duplicate runes - not enough", "程式設計", "程程", false},
+		{"Case insensitive", "Machine Learning", "ML", true},
+		{"Many distinct runes", "abcdefghijklmnopqrstuvwxyz", "zyxwvutsrqponmlkjihgfe", true},
+		{"Many distinct runes - missing", "abcdefghijklmnopqrstuvwxy", "zyxwvutsrqponmlkjihgfe", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			q := NewRuneQuery(tt.chars)
+			if got := q.Match(tt.s); got != tt.want {
+				t.Errorf("Match(%q) for %q = %v, want %v", tt.s, tt.chars, got, tt.want)
+			}
+			if got := q.MatchSigned(tt.s, NewRuneSignature(tt.s)); got != tt.want {
+				t.Errorf("MatchSigned(%q) for %q = %v, want %v", tt.s, tt.chars, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRuneQuery_SignatureNeverRejectsMatch checks the Bloom property on the
+// benchmark corpus: a string that contains the query is never ruled out by
+// its signature.
+func TestRuneQuery_SignatureNeverRejectsMatch(t *testing.T) {
+	t.Parallel()
+	names := fuzzyCorpus(3000, 3, surnameRunes+givenNameRunes)
+	queries := []string{"王明", "陳", "林美", "華志", "大小中"}
+	for _, query := range queries {
+		q := NewRuneQuery(query)
+		for _, name := range names {
+			if q.Match(name) && !NewRuneSignature(name).Covers(q.Signature()) {
+				t.Fatalf("signature of %q does not cover %q", name, query)
+			}
+		}
+	}
+}
+
+// Rune pools for the benchmark corpus: common surnames and given-name
+// characters, and characters of course titles.
+const (
+	surnameRunes   = "陳林黃張李王吳劉蔡楊許鄭謝郭洪曾邱廖賴周"
+	givenNameRunes = "明華志偉文玉美淑惠芳家宏俊雅婷怡君佳豪建宇"
+	courseRunes    = "資訊工程管理經濟統計會計財務金融法律政治社會學導論概論研究方法專題實務應用分析設計系統網路程式語言數位智慧人工機器線性代數微積分"
+)
+
+// fuzzyCorpus returns n deterministic strings of 2 to maxLen+1 runes from pool.
+func fuzzyCorpus(n, maxLen int, pool string) []string {
+	runes := []rune(pool)
+	rng := rand.New(rand.NewPCG(1, 2))
+	corpus := make([]string, n)
+	for i := range corpus {
+		var b strings.Builder
+		for range 2 + rng.IntN(maxLen) {
+			b.WriteRune(runes[rng.IntN(len(runes))])
+		}
+		corpus[i] = b.String()
+	}
+	return corpus
+}
+
+// BenchmarkFuzzyMatch compares matching one query against every cached name
+// or title: ContainsAllRunes per candidate (the former per-query loop), a
+// prepared RuneQuery, and a RuneQuery with precomputed signatures.
+func BenchmarkFuzzyMatch(b *testing.B) {
+	corpora := []struct {
+		name   string
+		corpus []string
+		query  string
+	}{
+		{"3000_students", fuzzyCorpus(3000, 2, surnameRunes+givenNameRunes), "王明"},
+		{"5000_courses", fuzzyCorpus(5000, 8, courseRunes), "線代"},
+	}
+
+	for _, c := range corpora {
+		sigs := make([]RuneSignature, len(c.corpus))
+		for i, s := range c.corpus {
+			sigs[i] = NewRuneSignature(s)
+		}
+
+		b.Run(c.name+"/ContainsAllRunes", func(b *testing.B) {
+			for b.Loop() {
+				for _, s := range c.corpus {
+					_ = ContainsAllRunes(s, c.query)
+				}
+			}
+		})
+		b.Run(c.name+"/RuneQuery", func(b *testing.B) {
+			for b.Loop() {
+				q := NewRuneQuery(c.query)
+				for _, s := range c.corpus {
+					_ = q.Match(s)
+				}
+			}
+		})
+		b.Run(c.name+"/RuneQuery_signatures", func(b *testing.B) {
+			for b.Loop() {
+				q := NewRuneQuery(c.query)
+				for i, s := range c.corpus {
+					_ = q.MatchSigned(s, sigs[i])
+				}
+			}
+		})
+	}
+}
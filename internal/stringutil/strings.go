@@ -58,9 +58,10 @@ func IsNumeric(s string) bool {
 	return true
 }
 
-// ContainsAllRunes checks if s contains all runes from chars (case-insensitive for ASCII).
+// ContainsAllRunes checks if s contains all runes from chars (case-insensitive).
 // Counts character occurrences: "aa" requires at least 2 'a's in s.
 // Supports non-contiguous character matching: "明王" matches "王小明".
+// To match one term against many strings, prepare it once with NewRuneQuery.
 //
 // Example:
 //
@@ -68,34 +69,7 @@ func IsNumeric(s string) bool {
 //	ContainsAllRunes("王小明", "王明") returns true
 //	ContainsAllRunes("王小明", "明王") returns true
 func ContainsAllRunes(s, chars string) bool {
-	if chars == "" {
-		return true
-	}
-	if s == "" {
-		return false
-	}
-
-	// Convert to lowercase for case-insensitive matching (for ASCII)
-	sLower := strings.ToLower(s)
-	charsLower := strings.ToLower(chars)
-
-	// Build a map counting rune occurrences in s
-	runeCount := make(map[rune]int)
-	for _, r := range sLower {
-		runeCount[r]++
-	}
-
-	// Build a map counting required rune occurrences in chars
-	requiredCount := make(map[rune]int)
-	for _, r := range charsLower {
-		requiredCount[r]++
-	}
-
-	// Check if s has at least as many of each rune as required
-	for r, required := range requiredCount {
-		if runeCount[r] < required {
-			return false
-		}
-	}
-	return true
+	var runes [maxStackRunes]rune
+	var need [maxStackRunes]int
+	return newRuneQuery(chars, runes[:], need[:]).Match(s)
 }
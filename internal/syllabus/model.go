@@ -17,10 +17,12 @@ func ComputeContentHash(content string) string {
 
 // Fields represents parsed syllabus content ready for indexing.
 // All fields contain unified CN+EN text extracted from course pages.
+// Prerequisites is shown on the course detail page and not indexed.
 type Fields struct {
-	Objectives string // Teaching objectives (教學目標)
-	Outline    string // Course outline (內容綱要)
-	Schedule   string // Weekly schedule (教學預定進度)
+	Objectives    string // Teaching objectives (教學目標)
+	Outline       string // Course outline (內容綱要)
+	Schedule      string // Weekly schedule (教學預定進度)
+	Prerequisites string // Prerequisite statement (先修課程), "" when none
 }
 
 // ContentForIndexing returns a single document string for BM25 search indexing.
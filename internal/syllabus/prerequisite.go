@@ -0,0 +1,82 @@
+package syllabus
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxPrerequisiteTitles caps the titles kept from one statement.
+const maxPrerequisiteTitles = 5
+
+// maxPrerequisiteTitleRunes drops list items that read like sentences
+// ("具備基本程式設計能力者") rather than course titles.
+const maxPrerequisiteTitleRunes = 16
+
+var (
+	// rePrerequisiteLabel matches an English label left before the statement
+	// (e.g., "(Prerequisites)：" after the 先修課程 prefix is removed).
+	rePrerequisiteLabel = regexp.MustCompile(`(?i)^[\s(（]*prerequisites?[)）]?\s*[:：]?\s*`)
+	// reQuotedTitle matches titles in 「」, 『』 or 《》.
+	reQuotedTitle = regexp.MustCompile(`[「『《]([^」』》]+)[」』》]`)
+	// rePrerequisiteSeparators splits a list of titles.
+	rePrerequisiteSeparators = regexp.MustCompile(`[、，,；;／/\n]|及|和|與|或`)
+	// reNoPrerequisite matches statements meaning "no prerequisites".
+	reNoPrerequisite = regexp.MustCompile(`(?i)^(無|無先修課程|無先修|不限|無限制|none|nil|n/?a|-+)[。.]?$`)
+)
+
+// Affixes stripped from list items, e.g., "修畢微積分者" → "微積分".
+var (
+	prerequisitePrefixes = []string{"曾修過", "已修過", "已修畢", "修習過", "修過", "修畢", "曾修", "需修過", "須修過"}
+	prerequisiteSuffixes = []string{"或同等程度", "以上", "課程", "者"}
+)
+
+// cleanPrerequisiteStatement removes a leftover English label and returns ""
+// for statements meaning "no prerequisites".
+func cleanPrerequisiteStatement(s string) string {
+	s = strings.TrimSpace(rePrerequisiteLabel.ReplaceAllString(s, ""))
+	if reNoPrerequisite.MatchString(s) {
+		return ""
+	}
+	return s
+}
+
+// ParsePrerequisiteTitles extracts searchable course titles from a 先修課程
+// statement. Quoted titles (「微積分」) win; otherwise the statement is split on
+// list separators and items that look like sentences are dropped.
+// Returns at most maxPrerequisiteTitles titles, without duplicates.
+func ParsePrerequisiteTitles(statement string) []string {
+	var candidates []string
+	if quoted := reQuotedTitle.FindAllStringSubmatch(statement, -1); len(quoted) > 0 {
+		for _, m := range quoted {
+			candidates = append(candidates, m[1])
+		}
+	} else {
+		candidates = rePrerequisiteSeparators.Split(statement, -1)
+	}
+
+	var titles []string
+	for _, c := range candidates {
+		title := strings.Trim(strings.TrimSpace(c), "。.")
+		for _, p := range prerequisitePrefixes {
+			title = strings.TrimPrefix(title, p)
+		}
+		for _, s := range prerequisiteSuffixes {
+			title = strings.TrimSuffix(title, s)
+		}
+		title = strings.TrimSpace(title)
+
+		n := utf8.RuneCountInString(title)
+		if n < 2 || n > maxPrerequisiteTitleRunes || reNoPrerequisite.MatchString(title) {
+			continue
+		}
+		if !slices.Contains(titles, title) {
+			titles = append(titles, title)
+		}
+		if len(titles) == maxPrerequisiteTitles {
+			break
+		}
+	}
+	return titles
+}
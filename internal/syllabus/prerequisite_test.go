@@ -0,0 +1,59 @@
+package syllabus
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCleanPrerequisiteStatement(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"微積分", "微積分"},
+		{"(Prerequisites)：微積分", "微積分"},
+		{"無", ""},
+		{"無先修課程。", ""},
+		{"None", ""},
+		{"N/A", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+			if got := cleanPrerequisiteStatement(tt.input); got != tt.want {
+				t.Errorf("cleanPrerequisiteStatement(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePrerequisiteTitles(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		statement string
+		want      []string
+	}{
+		{"single title", "微積分", []string{"微積分"}},
+		{"list", "微積分、線性代數", []string{"微積分", "線性代數"}},
+		{"conjunction", "計算機概論及程式設計", []string{"計算機概論", "程式設計"}},
+		{"affixes", "修畢統計學者", []string{"統計學"}},
+		{"quoted titles win", "建議先修「資料結構」與「演算法」，具備程式基礎", []string{"資料結構", "演算法"}},
+		{"sentence dropped", "具備基本程式設計能力並熟悉至少一種程式語言者", nil},
+		{"none", "無", nil},
+		{"duplicates", "微積分，微積分", []string{"微積分"}},
+		{"capped", "一一、二二、三三、四四、五五、六六", []string{"一一", "二二", "三三", "四四", "五五"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := ParsePrerequisiteTitles(tt.statement); !slices.Equal(got, tt.want) {
+				t.Errorf("ParsePrerequisiteTitles(%q) = %q, want %q", tt.statement, got, tt.want)
+			}
+		})
+	}
+}
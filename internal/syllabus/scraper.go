@@ -238,10 +238,21 @@ func parseSyllabusPage(doc *goquery.Document) *Fields {
 		return strings.Join(items, "\n")
 	}
 
+	// findPrerequisites extracts the 先修課程 statement (single field, CN or EN label)
+	findPrerequisites := func() string {
+		for _, prefix := range []string{"先修課程", "先修科目", "Prerequisite"} {
+			if content := findContentByPrefix(prefix); content != "" {
+				return content
+			}
+		}
+		return ""
+	}
+
 	// Parse all fields
 	fields.Objectives = cleanContent(findObjectives())
 	fields.Outline = cleanContent(findOutline())
 	fields.Schedule = cleanContent(findSchedule())
+	fields.Prerequisites = cleanPrerequisiteStatement(cleanContent(findPrerequisites()))
 
 	return fields
 }
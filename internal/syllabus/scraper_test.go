@@ -75,6 +75,7 @@ func TestParseSyllabusPage(t *testing.T) {
 		wantObjectives string
 		wantOutline    string
 		wantSchedule   string
+		wantPrereqs    string
 		wantEmpty      bool
 	}{
 		{
@@ -299,6 +300,27 @@ func TestParseSyllabusPage(t *testing.T) {
 			</body></html>`,
 			wantObjectives: "Span內容Div內容",
 		},
+		{
+			name: "prerequisites with English label",
+			html: `<html><body>
+				<table>
+					<tr><td>教學目標：<span class="font-c13">資料結構與演算法</span></td></tr>
+					<tr><td>先修課程(Prerequisites)：<span class="font-c13">計算機概論、程式設計</span></td></tr>
+				</table>
+			</body></html>`,
+			wantObjectives: "資料結構與演算法",
+			wantPrereqs:    "計算機概論、程式設計",
+		},
+		{
+			name: "prerequisites fallback without font-c13",
+			html: `<html><body>
+				<table>
+					<tr><td>教學目標：資料結構與演算法</td></tr>
+					<tr><td>先修課程：微積分</td></tr>
+				</table>
+			</body></html>`,
+			wantPrereqs: "微積分",
+		},
 		{
 			name: "schedule with only 3 columns (no method column)",
 			html: `<html><body>
@@ -341,6 +363,9 @@ func TestParseSyllabusPage(t *testing.T) {
 			if tt.wantSchedule != "" && !strings.Contains(fields.Schedule, tt.wantSchedule) {
 				t.Errorf("Schedule = %q, want to contain %q", fields.Schedule, tt.wantSchedule)
 			}
+			if fields.Prerequisites != tt.wantPrereqs {
+				t.Errorf("Prerequisites = %q, want %q", fields.Prerequisites, tt.wantPrereqs)
+			}
 		})
 	}
 }
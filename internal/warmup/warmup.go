@@ -18,8 +18,8 @@ import (
 	"sync/atomic"
 	"time"
 
-	"github.com/garyellow/ntpu-linebot-go/internal/config"
 	"github.com/garyellow/ntpu-linebot-go/internal/data"
+	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
 	"github.com/garyellow/ntpu-linebot-go/internal/logger"
 	"github.com/garyellow/ntpu-linebot-go/internal/metrics"
 	"github.com/garyellow/ntpu-linebot-go/internal/modules/course"
@@ -39,6 +39,7 @@ type Stats struct {
 	Courses  atomic.Int64
 	Programs atomic.Int64
 	Syllabi  atomic.Int64
+	Rejected atomic.Int64 // Scraped records (students included) that failed validation and were not cached
 }
 
 // Options configures refresh behavior
@@ -72,6 +73,13 @@ func Run(ctx context.Context, db *storage.DB, client *scraper.Client, log *logge
 
 	hasSyllabus := opts.HasLLMKey
 
+	// A refresh canceled mid-batch (shutdown, lease loss) keeps the rows it
+	// already scraped instead of rolling them back
+	ctx = storage.WithBatchPolicy(ctx, storage.BatchBestEffort)
+
+	// errgroup cancels its context once Wait returns; keep the caller's
+	// context for the post-warmup steps
+	parentCtx := ctx
 	g, ctx := errgroup.WithContext(ctx)
 
 	// Channel to pass raw program requirements from course warmup to syllabus warmup
@@ -112,7 +120,7 @@ func Run(ctx context.Context, db *storage.DB, client *scraper.Client, log *logge
 
 	if opts.WarmID {
 		g.Go(func() error {
-			if err := warmupIDModule(ctx, db, client, log, opts.Metrics); err != nil {
+			if err := warmupIDModule(ctx, db, client, log, stats, opts.Metrics); err != nil {
 				log.WithError(err).Error("ID module warmup failed")
 				return fmt.Errorf("id module: %w", err)
 			}
@@ -151,40 +159,54 @@ func Run(ctx context.Context, db *storage.DB, client *scraper.Client, log *logge
 		return stats, fmt.Errorf("warmup: %w", err)
 	}
 
+	// Teacher departments and profiles join course majors with contacts,
+	// so they can only be derived once both modules are refreshed
+	if err := db.RefreshTeacherProfiles(parentCtx); err != nil {
+		log.WithError(err).Warn("Failed to refresh teacher profiles (non-critical)")
+	}
+
 	log.WithField("duration_ms", time.Since(startTime).Milliseconds()).
 		WithField("contacts", stats.Contacts.Load()).
 		WithField("courses", stats.Courses.Load()).
 		WithField("programs", stats.Programs.Load()).
 		WithField("syllabi", stats.Syllabi.Load()).
+		WithField("rejected", stats.Rejected.Load()).
 		Info("Data refresh completed")
 
 	return stats, nil
 }
 
-// resetCache deletes all cached data
+// resetCache deletes all cached data of the DB's tenant
 func resetCache(ctx context.Context, db *storage.DB) error {
 	tables := []string{
 		"students",
 		"contacts",
 		"courses",
 		"historical_courses",
+		// course_archive is kept: archived catalogs are immutable
 		"programs",
 		"course_programs",
+		"course_teachers",
+		"teachers",
+		"course_sections",
+		"course_flags",
+		"course_prerequisites",
 		"syllabi",
-		"stickers",
+		"department_news",
 	}
 	for _, table := range tables {
-		query := fmt.Sprintf("DELETE FROM %s", table)
-		if _, err := db.ExecContext(ctx, query); err != nil {
+		query := fmt.Sprintf("DELETE FROM %s WHERE tenant = ?", table)
+		if _, err := db.ExecContext(ctx, query, db.Tenant()); err != nil {
 			return fmt.Errorf("failed to delete from %s: %w", table, err)
 		}
 	}
-	// Run VACUUM to reclaim space
-	if _, err := db.ExecContext(ctx, "VACUUM"); err != nil {
-		return fmt.Errorf("failed to vacuum: %w", err)
+	// Stickers are shared by every tenant
+	if _, err := db.ExecContext(ctx, "DELETE FROM stickers"); err != nil {
+		return fmt.Errorf("failed to delete from stickers: %w", err)
 	}
-	if _, err := db.ExecContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
-		return fmt.Errorf("failed to checkpoint wal after vacuum: %w", err)
+	// Run VACUUM to reclaim space
+	if _, err := db.Vacuum(ctx); err != nil {
+		return err
 	}
 	if _, err := db.ExecContext(ctx, "PRAGMA optimize"); err != nil {
 		return fmt.Errorf("failed to optimize: %w", err)
@@ -228,9 +250,54 @@ func warmupProgramModule(ctx context.Context, db *storage.DB, client *scraper.Cl
 	return nil
 }
 
+// studentSaveBatchSize is how many scraped students warmupIDModule buffers
+// before committing them in one transaction.
+const studentSaveBatchSize = 500
+
+// studentType is a degree type scraped by warmupIDModule.
+type studentType struct {
+	prefix string
+	depts  []string
+	name   string
+}
+
+// studentTask is one student list page: a department's students of one
+// degree type and admission year.
+type studentTask struct {
+	studentType
+	year int
+	dept string
+}
+
+// studentWarmupOrder lists the tasks round-robin: every department of every
+// degree type gets a year before any department gets the next (older) one,
+// and degree types alternate department by department within a year. An
+// interrupted warmup then leaves every department equally fresh instead of
+// finishing the first departments and never reaching the last.
+func studentWarmupOrder(types []studentType, fromYear int) []studentTask {
+	var tasks []studentTask
+	for year := fromYear; year > 100; year-- {
+		for i := 0; ; i++ {
+			added := false
+			for _, st := range types {
+				if i < len(st.depts) {
+					tasks = append(tasks, studentTask{studentType: st, year: year, dept: st.depts[i]})
+					added = true
+				}
+			}
+			if !added {
+				break
+			}
+		}
+	}
+	return tasks
+}
+
 // warmupIDModule warms student ID cache (sequential execution).
-// Scrapes undergraduate (prefix 4), master's (prefix 7), and PhD (prefix 8) students.
-func warmupIDModule(ctx context.Context, db *storage.DB, client *scraper.Client, log *logger.Logger, m *metrics.Metrics) (retErr error) {
+// Scrapes undergraduate (prefix 4), master's (prefix 7), and PhD (prefix 8)
+// students in studentWarmupOrder, committing every studentSaveBatchSize
+// students so an interrupted warmup keeps what it scraped.
+func warmupIDModule(ctx context.Context, db *storage.DB, client *scraper.Client, log *logger.Logger, stats *Stats, m *metrics.Metrics) (retErr error) {
 	startTime := time.Now()
 	defer func() {
 		if m != nil {
@@ -242,26 +309,16 @@ func warmupIDModule(ctx context.Context, db *storage.DB, client *scraper.Client,
 		}
 	}()
 
-	// Warmup range: 101-112 (數位學苑 2.0 已無 113+ 完整資料)
+	// Warmup range: 101-112 (數位學苑 2.0 已無 113+ 完整資料, see data.Availability)
 	currentYear := time.Now().Year() - 1911
-	fromYear := min(config.IDDataYearEnd, currentYear)
+	fromYear := data.Availability.Students.LatestCompleteYear(currentYear)
 
-	// Define student types with their respective department codes
-	studentTypes := []struct {
-		prefix string
-		depts  []string
-		name   string
-	}{
+	tasks := studentWarmupOrder([]studentType{
 		{ntpu.StudentTypeUndergrad, ntpu.UndergradDeptCodes, "學士班"},
 		{ntpu.StudentTypeMaster, ntpu.MasterDeptCodes, "碩士班"},
 		{ntpu.StudentTypePhD, ntpu.PhDDeptCodes, "博士班"},
-	}
-
-	// Calculate total tasks
-	var totalTasks int
-	for _, st := range studentTypes {
-		totalTasks += (fromYear - 100) * len(st.depts)
-	}
+	}, fromYear)
+	totalTasks := len(tasks)
 	log.WithField("tasks", totalTasks).
 		WithField("student_types", []string{"undergrad", "masters", "phd"}).
 		Info("Starting ID module warmup")
@@ -271,64 +328,70 @@ func warmupIDModule(ctx context.Context, db *storage.DB, client *scraper.Client,
 	var studentCount int64
 	var errs []error
 
-	for _, st := range studentTypes {
-		log.WithField("type", st.name).WithField("dept_count", len(st.depts)).Info("Warming up student type")
-
-		for year := fromYear; year > 100; year-- {
-			for _, dept := range st.depts {
-				select {
-				case <-ctx.Done():
-					log.WithField("completed", completed).
-						WithField("errors", errorCount).
-						Warn("ID module warmup canceled")
-					return fmt.Errorf("canceled: %w", ctx.Err())
-				default:
-				}
-
-				students, err := ntpu.ScrapeStudentsByYear(ctx, client, year, dept, st.prefix)
-				if err != nil {
-					log.WithError(err).
-						WithField("year", year).
-						WithField("dept", dept).
-						WithField("type", st.name).
-						Warn("Failed to scrape students")
-					errs = append(errs, fmt.Errorf("scrape year=%d dept=%s type=%s: %w", year, dept, st.name, err))
-					errorCount++
-					continue
-				}
+	// Scraped students wait here until the next commit
+	pending := make([]*storage.Student, 0, studentSaveBatchSize)
+	flush := func() {
+		if len(pending) == 0 {
+			return
+		}
+		// Commit even when ctx is canceled, so an interrupted warmup keeps the batch
+		if err := db.SaveStudentsBatch(context.WithoutCancel(ctx), pending); err != nil {
+			log.WithError(err).
+				WithField("count", len(pending)).
+				Warn("Failed to save student batch")
+			errs = append(errs, fmt.Errorf("save %d students: %w", len(pending), err))
+			errorCount++
+		} else {
+			studentCount += int64(len(pending))
+		}
+		pending = pending[:0]
+	}
 
-				// Save to database
-				if err := db.SaveStudentsBatch(ctx, students); err != nil {
-					log.WithError(err).
-						WithField("year", year).
-						WithField("dept", dept).
-						WithField("type", st.name).
-						WithField("count", len(students)).
-						Warn("Failed to save student batch")
-					errs = append(errs, fmt.Errorf("save year=%d dept=%s type=%s: %w", year, dept, st.name, err))
-					errorCount++
-					continue
-				}
+	for _, task := range tasks {
+		select {
+		case <-ctx.Done():
+			flush()
+			log.WithField("completed", completed).
+				WithField("students", studentCount).
+				WithField("errors", errorCount).
+				Warn("ID module warmup canceled")
+			return fmt.Errorf("canceled: %w", ctx.Err())
+		default:
+		}
 
-				studentCount += int64(len(students))
-				completed++
+		students, err := ntpu.ScrapeStudentsByYear(ctx, client, task.year, task.dept, task.prefix)
+		if err != nil {
+			log.WithError(err).
+				WithField("year", task.year).
+				WithField("dept", task.dept).
+				WithField("type", task.name).
+				Warn("Failed to scrape students")
+			errs = append(errs, fmt.Errorf("scrape year=%d dept=%s type=%s: %w", task.year, task.dept, task.name, err))
+			errorCount++
+			continue
+		}
 
-				// Report progress every 5% or at completion
-				progressInterval := max(totalTasks/20, 1) // ~5% intervals, minimum 1
-				if completed%progressInterval == 0 || completed == totalTasks {
-					elapsed := time.Since(startTime)
-					avgTimePerTask := elapsed / time.Duration(completed)
-					estimatedRemaining := avgTimePerTask * time.Duration(totalTasks-completed)
-					log.WithField("progress", fmt.Sprintf("%d/%d (%.0f%%)", completed, totalTasks, float64(completed)*100/float64(totalTasks))).
-						WithField("students", studentCount).
-						WithField("type", st.name).
-						WithField("elapsed_minutes", int(elapsed.Minutes())).
-						WithField("estimated_remaining_minutes", int(estimatedRemaining.Minutes())).
-						Info("ID module progress")
-				}
-			}
+		pending = append(pending, dropInvalid(students, ntpu.ValidateStudent, "student", log, stats)...)
+		if len(pending) >= studentSaveBatchSize {
+			flush()
+		}
+		completed++
+
+		// Report progress every 5% or at completion
+		progressInterval := max(totalTasks/20, 1) // ~5% intervals, minimum 1
+		if completed%progressInterval == 0 || completed == totalTasks {
+			elapsed := time.Since(startTime)
+			avgTimePerTask := elapsed / time.Duration(completed)
+			estimatedRemaining := avgTimePerTask * time.Duration(totalTasks-completed)
+			log.WithField("progress", fmt.Sprintf("%d/%d (%.0f%%)", completed, totalTasks, float64(completed)*100/float64(totalTasks))).
+				WithField("students", studentCount).
+				WithField("year", task.year).
+				WithField("elapsed_minutes", int(elapsed.Minutes())).
+				WithField("estimated_remaining_minutes", int(estimatedRemaining.Minutes())).
+				Info("ID module progress")
 		}
 	}
+	flush()
 
 	if errorCount > 0 {
 		return errors.Join(errs...)
@@ -336,6 +399,41 @@ func warmupIDModule(ctx context.Context, db *storage.DB, client *scraper.Client,
 	return nil
 }
 
+// archiveFinalCatalogs freezes the catalog of each semester whose 加退選 has
+// ended (data.EnrollmentPeriods) into the course archive, once the courses
+// table holds a copy refreshed after the last 加退選 day. Semesters already
+// archived are skipped; failures are logged and retried by the next refresh.
+func archiveFinalCatalogs(ctx context.Context, db *storage.DB, log *logger.Logger, now time.Time) {
+	loc := lineutil.GetTaipeiLocation()
+	today := now.In(loc).Format(time.DateOnly)
+	for _, p := range data.EnrollmentPeriods {
+		if p.End >= today {
+			continue
+		}
+		end, err := time.ParseInLocation(time.DateOnly, p.End, loc)
+		if err != nil {
+			log.WithError(err).WithField("end", p.End).Warn("Invalid enrollment period end")
+			continue
+		}
+
+		count, err := db.ArchiveCourseCatalog(ctx, p.Year, p.Term, end.AddDate(0, 0, 1))
+		switch {
+		case errors.Is(err, storage.ErrCatalogArchived), errors.Is(err, storage.ErrCatalogNotReady):
+			continue
+		case err != nil:
+			log.WithError(err).
+				WithField("year", p.Year).
+				WithField("term", p.Term).
+				Warn("Failed to archive course catalog")
+		default:
+			log.WithField("year", p.Year).
+				WithField("term", p.Term).
+				WithField("count", count).
+				Info("Course catalog archived")
+		}
+	}
+}
+
 // warmupContactModule warms contact cache (allows partial success).
 func warmupContactModule(ctx context.Context, db *storage.DB, client *scraper.Client, log *logger.Logger, stats *Stats, m *metrics.Metrics) (retErr error) {
 	startTime := time.Now()
@@ -351,46 +449,46 @@ func warmupContactModule(ctx context.Context, db *storage.DB, client *scraper.Cl
 
 	log.Info("Starting contact module warmup")
 
-	var errs []error
-
-	adminContacts, err := ntpu.ScrapeAdministrativeContacts(ctx, client)
-	if err != nil {
-		log.WithError(err).Warn("Failed to scrape administrative contacts, continuing anyway")
-		errs = append(errs, fmt.Errorf("administrative contacts: %w", err))
-	} else {
-		if err := db.SaveContactsBatch(ctx, adminContacts); err != nil {
-			log.WithError(err).Warn("Failed to save administrative contacts batch")
-			errs = append(errs, fmt.Errorf("save administrative contacts: %w", err))
-		} else {
-			stats.Contacts.Add(int64(len(adminContacts)))
-			log.WithField("count", len(adminContacts)).Info("Administrative contacts cached")
-		}
+	// Adjuncts are scraped last: the part-time listing flags individuals the
+	// academic directory may also list, and the later save wins
+	sources := []struct {
+		name   string
+		scrape func(context.Context, *scraper.Client) ([]*storage.Contact, error)
+	}{
+		{"administrative", ntpu.ScrapeAdministrativeContacts},
+		{"academic", ntpu.ScrapeAcademicContacts},
+		{"adjunct", ntpu.ScrapeAdjunctContacts},
 	}
 
-	academicContacts, err := ntpu.ScrapeAcademicContacts(ctx, client)
-	if err != nil {
-		log.WithError(err).Warn("Failed to scrape academic contacts, continuing anyway")
-		errs = append(errs, fmt.Errorf("academic contacts: %w", err))
-	} else {
+	var errs []error
+	for _, src := range sources {
+		srcLog := log.WithField("source", src.name)
+		contacts, err := src.scrape(ctx, client)
+		if err != nil {
+			srcLog.WithError(err).Warn("Failed to scrape contacts, continuing anyway")
+			errs = append(errs, fmt.Errorf("%s contacts: %w", src.name, err))
+			continue
+		}
 		// Save using batch operation to reduce lock contention
-		if err := db.SaveContactsBatch(ctx, academicContacts); err != nil {
-			log.WithError(err).Warn("Failed to save academic contacts batch")
-			errs = append(errs, fmt.Errorf("save academic contacts: %w", err))
-		} else {
-			stats.Contacts.Add(int64(len(academicContacts)))
-			log.WithField("count", len(academicContacts)).Info("Academic contacts cached")
+		contacts = dropInvalid(contacts, ntpu.ValidateContact, "contact", log, stats)
+		if err := db.SaveContactsBatch(ctx, contacts); err != nil {
+			srcLog.WithError(err).Warn("Failed to save contacts batch")
+			errs = append(errs, fmt.Errorf("save %s contacts: %w", src.name, err))
+			continue
 		}
+		stats.Contacts.Add(int64(len(contacts)))
+		srcLog.WithField("count", len(contacts)).Info("Contacts cached")
 	}
 
-	// Return error only if both failed
+	// Return error only if every source failed
 	// This allows the warmup to succeed with partial data (e.g., only academic or only administrative)
-	if len(errs) == 2 {
-		return fmt.Errorf("both contact sources failed: %w", errors.Join(errs[0], errs[1]))
+	if len(errs) == len(sources) {
+		return fmt.Errorf("all contact sources failed: %w", errors.Join(errs...))
 	}
 
 	// Log partial success details
-	if len(errs) == 1 {
-		log.WithField("failed_source_error", errs[0]).Info("Contact module completed with partial success")
+	if len(errs) > 0 {
+		log.WithField("failed_source_error", errors.Join(errs...)).Info("Contact module completed with partial success")
 	}
 
 	return nil
@@ -451,6 +549,7 @@ func warmupCourseModule(ctx context.Context, db *storage.DB, client *scraper.Cli
 				Warn("Failed to scrape courses for semester")
 			continue
 		}
+		courses = dropInvalid(courses, ntpu.ValidateCourse, "course", log, stats)
 
 		// Collect raw program requirements for syllabus warmup (dual-source fusion)
 		// This enables accurate program names + correct required/elective types
@@ -470,6 +569,14 @@ func warmupCourseModule(ctx context.Context, db *storage.DB, client *scraper.Cli
 			continue
 		}
 
+		// Department requirements (應修系級) back the department CSV export
+		if err := db.SaveCourseMajorsBatch(ctx, courses); err != nil {
+			log.WithError(err).
+				WithField("year", sem.Year).
+				WithField("term", sem.Term).
+				Warn("Failed to save course majors (non-critical)")
+		}
+
 		// Cleanup potential cold data to ensure strict partitioning
 		// If we successfully saved to 'courses' (Hot), we must remove from 'historical_courses' (Cold)
 		if err := db.DeleteHistoricalCoursesByYearTerm(ctx, sem.Year, sem.Term); err != nil {
@@ -488,6 +595,8 @@ func warmupCourseModule(ctx context.Context, db *storage.DB, client *scraper.Cli
 			Info("Courses cached for semester")
 	}
 
+	archiveFinalCatalogs(ctx, db, log, time.Now())
+
 	// Update shared semester cache after successful warmup
 	if semesterCache != nil {
 		semesterCache.Update(semesters)
@@ -518,7 +627,7 @@ func probeSemestersWithData(ctx context.Context, client *scraper.Client, log *lo
 	// Generate probe sequence
 	probeSequence := course.GenerateProbeSequence(startYear, startTerm, maxProbes)
 
-	log.WithField("start", fmt.Sprintf("%d-%d", startYear, startTerm)).
+	log.WithField("start", course.Semester{Year: startYear, Term: startTerm}.String()).
 		WithField("max_probes", maxProbes).
 		Info("Starting semester probing")
 
@@ -574,6 +683,24 @@ func probeSemesterHasData(ctx context.Context, client *scraper.Client, year, ter
 	return ntpu.ProbeCoursesExist(ctx, client, year, term)
 }
 
+// dropInvalid removes the records validate rejects so malformed parses never
+// reach the cache, logging them and counting them in stats.Rejected.
+func dropInvalid[T any](records []*T, validate func(*T) error, kind string, log *logger.Logger, stats *Stats) []*T {
+	valid, rejected := ntpu.FilterValid(records, validate)
+	if len(rejected) == 0 {
+		return valid
+	}
+	for _, err := range rejected {
+		log.WithError(err).WithField("kind", kind).Debug("Rejected scraped record")
+	}
+	log.WithField("kind", kind).
+		WithField("rejected", len(rejected)).
+		WithField("kept", len(valid)).
+		Warn("Dropped scraped records that failed validation")
+	stats.Rejected.Add(int64(len(rejected)))
+	return valid
+}
+
 // formatSemesters formats semester list for logging
 // Example: [{113 2} {113 1} {112 2} {112 1}] -> "113-2, 113-1, 112-2, 112-1"
 func formatSemesters(semesters []course.Semester) string {
@@ -582,9 +709,9 @@ func formatSemesters(semesters []course.Semester) string {
 	}
 
 	var result strings.Builder
-	fmt.Fprintf(&result, "%d-%d", semesters[0].Year, semesters[0].Term)
+	result.WriteString(semesters[0].String())
 	for i := 1; i < len(semesters); i++ {
-		fmt.Fprintf(&result, ", %d-%d", semesters[i].Year, semesters[i].Term)
+		result.WriteString(", " + semesters[i].String())
 	}
 	return result.String()
 }
@@ -704,11 +831,20 @@ func warmupSyllabusModule(ctx context.Context, db *storage.DB, client *scraper.C
 					continue
 				}
 
-				// Save program requirements to database (always, even if syllabus is empty)
-				if len(result.Programs) > 0 {
+				// Save program requirements (always, even if syllabus is empty) and
+				// 先修課程 for the detail page together, so a course never keeps
+				// one half of its detail page from the previous scrape
+				err = db.WithTx(ctx, func(ctx context.Context) error {
 					if err := db.SaveCoursePrograms(ctx, course.UID, result.Programs); err != nil {
-						log.WithError(err).WithField("uid", course.UID).Debug("Failed to save course programs")
+						return err
+					}
+					if prereqs := result.Fields.Prerequisites; prereqs != "" {
+						return db.SaveCoursePrerequisites(ctx, course.UID, prereqs, syllabus.ParsePrerequisiteTitles(prereqs))
 					}
+					return nil
+				})
+				if err != nil {
+					log.WithError(err).WithField("uid", course.UID).Debug("Failed to save course programs and prerequisites")
 				}
 
 				// Skip empty syllabi for indexing (but programs were already saved above)
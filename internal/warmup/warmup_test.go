@@ -1,6 +1,8 @@
 package warmup
 
 import (
+	"fmt"
+	"slices"
 	"testing"
 )
 
@@ -154,3 +156,24 @@ func TestOptions(t *testing.T) {
 		})
 	}
 }
+
+func TestStudentWarmupOrder(t *testing.T) {
+	t.Parallel()
+
+	tasks := studentWarmupOrder([]studentType{
+		{prefix: "4", depts: []string{"71", "72", "73"}, name: "學士班"},
+		{prefix: "7", depts: []string{"71"}, name: "碩士班"},
+	}, 102)
+
+	var got []string
+	for _, task := range tasks {
+		got = append(got, fmt.Sprintf("%d-%s%s", task.year, task.prefix, task.dept))
+	}
+	want := []string{
+		"102-471", "102-771", "102-472", "102-473",
+		"101-471", "101-771", "101-472", "101-473",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("studentWarmupOrder() = %v, want %v", got, want)
+	}
+}
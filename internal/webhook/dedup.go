@@ -0,0 +1,51 @@
+package webhook
+
+import (
+	"sync"
+	"time"
+)
+
+// eventSet remembers webhook event IDs for a while, so events LINE
+// redelivers after they were already handled are processed only once.
+type eventSet struct {
+	mu        sync.Mutex
+	seen      map[string]time.Time // event ID → first seen
+	ttl       time.Duration
+	lastPrune time.Time
+}
+
+func newEventSet(ttl time.Duration) *eventSet {
+	return &eventSet{
+		seen:      make(map[string]time.Time),
+		ttl:       ttl,
+		lastPrune: time.Now(),
+	}
+}
+
+// firstSeen records id and reports whether it was not seen within the TTL.
+// An empty ID (events without one) is always reported as new.
+func (s *eventSet) firstSeen(id string) bool {
+	if id == "" {
+		return true
+	}
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Expired IDs are swept at most once per TTL
+	if now.Sub(s.lastPrune) >= s.ttl {
+		for key, at := range s.seen {
+			if now.Sub(at) >= s.ttl {
+				delete(s.seen, key)
+			}
+		}
+		s.lastPrune = now
+	}
+
+	if at, ok := s.seen[id]; ok && now.Sub(at) < s.ttl {
+		return false
+	}
+	s.seen[id] = now
+	return true
+}
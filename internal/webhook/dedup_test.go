@@ -0,0 +1,42 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEventSet_FirstSeen(t *testing.T) {
+	t.Parallel()
+	s := newEventSet(time.Minute)
+
+	if !s.firstSeen("01H") {
+		t.Error("firstSeen(new) = false, want true")
+	}
+	if s.firstSeen("01H") {
+		t.Error("firstSeen(redelivered) = true, want false")
+	}
+	if !s.firstSeen("01J") {
+		t.Error("firstSeen(other) = false, want true")
+	}
+	if !s.firstSeen("") || !s.firstSeen("") {
+		t.Error("firstSeen(\"\") = false, want true")
+	}
+}
+
+func TestEventSet_Expiry(t *testing.T) {
+	t.Parallel()
+	s := newEventSet(50 * time.Millisecond)
+
+	s.firstSeen("01H")
+	time.Sleep(60 * time.Millisecond)
+
+	if !s.firstSeen("01J") {
+		t.Error("firstSeen(other) = false, want true")
+	}
+	if len(s.seen) != 1 {
+		t.Errorf("seen = %d entries after prune, want 1", len(s.seen))
+	}
+	if !s.firstSeen("01H") {
+		t.Error("firstSeen(expired) = false, want true")
+	}
+}
@@ -4,19 +4,22 @@ package webhook
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
-	"strings"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/garyellow/ntpu-linebot-go/internal/bot"
 	"github.com/garyellow/ntpu-linebot-go/internal/config"
 	"github.com/garyellow/ntpu-linebot-go/internal/ctxutil"
+	"github.com/garyellow/ntpu-linebot-go/internal/lineapi"
 	"github.com/garyellow/ntpu-linebot-go/internal/lineutil"
 	"github.com/garyellow/ntpu-linebot-go/internal/logger"
 	"github.com/garyellow/ntpu-linebot-go/internal/metrics"
+	"github.com/garyellow/ntpu-linebot-go/internal/modules/privacy"
 	"github.com/garyellow/ntpu-linebot-go/internal/ratelimit"
 	"github.com/garyellow/ntpu-linebot-go/internal/sticker"
 	"github.com/gin-gonic/gin"
@@ -24,21 +27,28 @@ import (
 	"github.com/line/line-bot-sdk-go/v8/linebot/webhook"
 )
 
+// webhookPool labels the async batch goroutines in pool metrics.
+const webhookPool = "webhook"
+
 // Handler handles LINE webhook events
 type Handler struct {
 	channelSecret  string
-	client         *messaging_api.MessagingApiAPI
+	client         *lineapi.Client
 	metrics        *metrics.Metrics
 	logger         *logger.Logger
 	processor      *bot.Processor
 	rateLimiter    *ratelimit.Limiter // Global rate limiter for API calls
 	stickerManager *sticker.Manager   // Sticker manager for avatar URLs
+	recorder       *Recorder          // Optional: records events and replies for cmd/replay
+	handledEvents  *eventSet          // Event IDs already processed, to skip redeliveries
+	banner         string             // Optional: leads every reply (degraded mode notice)
 	wg             sync.WaitGroup     // WaitGroup for async event processing
 
 	// LINE API constraints (from config.BotConfig)
 	maxMessagesPerReply int
 	maxEventsPerWebhook int
 	minReplyTokenLength int
+	replyTokenTTL       time.Duration // Reply tokens older than this use push fallback
 }
 
 // HandlerConfig holds configuration for creating a new Handler
@@ -50,13 +60,24 @@ type HandlerConfig struct {
 	Logger         *logger.Logger
 	Processor      *bot.Processor
 	StickerManager *sticker.Manager
+	LineClient     *lineapi.Client // Optional: created from ChannelToken when nil
+	RecordFile     string          // Optional: JSON Lines file to record events and replies to
+	Banner         string          // Optional: text message sent ahead of every reply
 }
 
 // NewHandler creates a new webhook handler.
 func NewHandler(cfg HandlerConfig) (*Handler, error) {
-	client, err := messaging_api.NewMessagingApiAPI(cfg.ChannelToken)
-	if err != nil {
-		return nil, fmt.Errorf("create messaging API client: %w", err)
+	client := cfg.LineClient
+	if client == nil {
+		var err error
+		client, err = lineapi.New(lineapi.Config{
+			ChannelToken: cfg.ChannelToken,
+			Metrics:      cfg.Metrics,
+			Logger:       cfg.Logger,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("line client: %w", err)
+		}
 	}
 
 	h := &Handler{
@@ -69,16 +90,37 @@ func NewHandler(cfg HandlerConfig) (*Handler, error) {
 		maxMessagesPerReply: cfg.BotConfig.MaxMessagesPerReply,
 		maxEventsPerWebhook: cfg.BotConfig.MaxEventsPerWebhook,
 		minReplyTokenLength: cfg.BotConfig.MinReplyTokenLength,
+		replyTokenTTL:       config.ReplyTokenTTL,
+		handledEvents:       newEventSet(config.WebhookEventDedupTTL),
+		banner:              cfg.Banner,
 	}
 
 	h.rateLimiter = ratelimit.New(cfg.BotConfig.GlobalRateRPS, cfg.BotConfig.GlobalRateRPS)
 
+	if cfg.RecordFile != "" {
+		recorder, err := NewRecorder(cfg.RecordFile)
+		if err != nil {
+			return nil, err
+		}
+		h.recorder = recorder
+	}
+
 	return h, nil
 }
 
+// Recorder returns the event recorder, or nil when recording is off.
+func (h *Handler) Recorder() *Recorder {
+	return h.recorder
+}
+
 // Handle is the Gin handler for the webhook endpoint
 func (h *Handler) Handle(c *gin.Context) {
 	reqCtx := c.Request.Context()
+	var rawEvents []json.RawMessage
+	if h.recorder != nil {
+		rawEvents = readRawEvents(c.Request)
+	}
+
 	// 1. Parse request
 	cb, err := webhook.ParseRequest(h.channelSecret, c.Request)
 	if err != nil {
@@ -108,33 +150,44 @@ func (h *Handler) Handle(c *gin.Context) {
 			WarnContext(reqCtx, "Too many events in webhook batch, truncating")
 		cb.Events = cb.Events[:h.maxEventsPerWebhook] // Limit to prevent DoS
 	}
+	if len(rawEvents) != len(cb.Events) {
+		rawEvents = nil // Only record when events and raw JSON line up
+	}
 
 	// Copy events to avoid race condition after HTTP response completes
 	events := make([]webhook.EventInterface, len(cb.Events))
 	copy(events, cb.Events)
 
 	// Process events asynchronously using WaitGroup.Go (Go 1.26)
+	h.metrics.PoolStarted(webhookPool)
 	h.wg.Go(func() {
 		defer func() {
 			if r := recover(); r != nil {
 				h.logger.WithField("panic", r).Error("Panic in async event processing")
 			}
+			h.metrics.PoolFinished(webhookPool)
 		}()
 
 		processingCtx := context.Background()
-		for _, event := range events {
-			h.processEvent(processingCtx, event, start)
+		for i, event := range events {
+			var raw json.RawMessage
+			if rawEvents != nil {
+				raw = rawEvents[i]
+			}
+			h.processEvent(processingCtx, event, raw, start)
 		}
 	})
 }
 
-// processEvent handles a single webhook event asynchronously
-func (h *Handler) processEvent(ctx context.Context, event webhook.EventInterface, webhookStart time.Time) {
+// processEvent handles a single webhook event asynchronously.
+// raw is the event JSON to record, or nil when not recording.
+func (h *Handler) processEvent(ctx context.Context, event webhook.EventInterface, raw json.RawMessage, webhookStart time.Time) {
 	eventStart := time.Now()
 	var messages []messaging_api.MessageInterface
 	var eventType string
 	var err error
 
+	ctx = ctxutil.WithEventTrace(ctx)
 	eventID, eventTimestamp, isRedelivery := extractEventMeta(event)
 	if eventID != "" {
 		ctx = ctxutil.WithEventID(ctx, eventID)
@@ -151,11 +204,26 @@ func (h *Handler) processEvent(ctx context.Context, event webhook.EventInterface
 		log = log.WithField("event_timestamp_ms", eventTimestamp)
 	}
 
-	// Show loading animation only when response is expected
-	// Skip for group chats without @mention or stickers in groups (no response)
+	// LINE redelivers events it thinks were lost; one handled here already
+	// got its reply (and the redelivered reply token is stale anyway)
+	if !h.handledEvents.firstSeen(eventID) {
+		log.WithField("event_id", eventID).InfoContext(ctx, "Skipping redelivered event that was already handled")
+		return
+	}
+
+	// Allow handlers to show the loading animation before slow operations
+	// (scraping, smart search, NLU). Fast cache hits reply without it.
+	// LINE only supports the animation in 1:1 chats.
 	if h.shouldShowLoading(event) {
-		if loadErr := h.showLoadingAnimation(event); loadErr != nil {
-			log.WithError(loadErr).WarnContext(ctx, "Failed to show loading animation")
+		if chatID := h.getChatID(event); isUserChat(event) && chatID != "" {
+			var once sync.Once
+			ctx = ctxutil.WithLoadingIndicator(ctx, func(ctx context.Context) {
+				once.Do(func() {
+					if loadErr := h.showLoadingAnimation(ctx, chatID); loadErr != nil {
+						log.WithError(loadErr).WarnContext(ctx, "Failed to show loading animation")
+					}
+				})
+			})
 		}
 	}
 
@@ -172,6 +240,9 @@ func (h *Handler) processEvent(ctx context.Context, event webhook.EventInterface
 	case webhook.JoinEvent:
 		eventType = "join"
 		messages, err = h.processor.ProcessJoin(ctx, e)
+	case webhook.AccountLinkEvent:
+		eventType = "account_link"
+		messages, err = h.processor.ProcessAccountLink(ctx, e)
 	default:
 		// Unsupported event type, skip
 		log.WithField("event_type", fmt.Sprintf("%T", e)).DebugContext(ctx, "Unsupported event type")
@@ -189,6 +260,19 @@ func (h *Handler) processEvent(ctx context.Context, event webhook.EventInterface
 	h.metrics.RecordWebhook(eventType, status, durationSeconds)
 
 	if len(messages) > 0 && err == nil {
+		if h.banner != "" {
+			messages = slices.Insert(messages, 0, h.bannerMessage())
+		}
+
+		// LINE rejects the whole reply if any message breaks a payload limit
+		var adjustments []lineutil.Adjustment
+		messages, adjustments = lineutil.FitMessages(messages)
+		for _, a := range adjustments {
+			log.WithField("rule", a.Rule).
+				WithField("detail", a.Detail).
+				WarnContext(ctx, "Reply adjusted to fit LINE limits")
+		}
+
 		// LINE API restriction: max messages per reply
 		if len(messages) > h.maxMessagesPerReply {
 			log.WithField("message_count", len(messages)).
@@ -204,45 +288,29 @@ func (h *Handler) processEvent(ctx context.Context, event webhook.EventInterface
 			messages = append(messages, msg)
 		}
 
-		replyToken := h.getReplyToken(event)
-		if replyToken == "" {
-			replyStatus = "skipped_empty_token"
-			h.metrics.RecordLineReplySkipped(replyStatus)
-			log.DebugContext(ctx, "Empty reply token, skipping reply")
-		} else if len(replyToken) < h.minReplyTokenLength {
-			replyStatus = "skipped_invalid_token"
-			h.metrics.RecordLineReplySkipped(replyStatus)
-			log.DebugContext(ctx, "Invalid reply token format")
-		} else {
-			// Check global rate limit
-			if !h.rateLimiter.Allow() {
-				log.WarnContext(ctx, "Global rate limit exceeded, waiting")
-				h.metrics.RecordRateLimiterDrop("global")
-				h.rateLimiter.WaitSimple()
-			}
+		replyStatus = h.sendReply(ctx, log, event, messages, webhookStart)
+	}
 
-			replyStart := time.Now()
-			if _, err := h.client.ReplyMessage(
-				&messaging_api.ReplyMessageRequest{
-					ReplyToken: replyToken,
-					Messages:   messages,
-				},
-			); err != nil {
-				errMsg := err.Error()
-				if strings.Contains(errMsg, "Invalid reply token") {
-					replyStatus = "invalid_token"
-					log.WithError(err).DebugContext(ctx, "Reply token already used or invalid")
-				} else if strings.Contains(errMsg, "rate limit") {
-					replyStatus = "rate_limited"
-					log.WithError(err).ErrorContext(ctx, "Rate limit exceeded")
-				} else {
-					replyStatus = "error"
-					log.WithError(err).ErrorContext(ctx, "Failed to send reply")
-				}
-			} else {
-				replyStatus = "success"
-			}
-			h.metrics.RecordLineReply(replyStatus, time.Since(replyStart).Seconds())
+	// End to end from receipt, so alerts see how close each module gets to the reply token deadline
+	trace := ctxutil.GetEventTrace(ctx)
+	module, intent := trace.Module()
+	if module == "" {
+		module = "none"
+	}
+	replied := 0
+	if err == nil {
+		replied = len(messages)
+	}
+	h.metrics.RecordWebhookEvent(module, intent, trace.Cache(), replied, time.Since(webhookStart).Seconds())
+
+	// The deletion request itself must not outlive the data it erased
+	if raw != nil && module != privacy.ModuleName {
+		var recorded []messaging_api.MessageInterface
+		if err == nil {
+			recorded = messages
+		}
+		if recErr := h.recorder.Record(raw, module, recorded); recErr != nil {
+			log.WithError(recErr).WarnContext(ctx, "Failed to record webhook event")
 		}
 	}
 
@@ -256,6 +324,107 @@ func (h *Handler) processEvent(ctx context.Context, event webhook.EventInterface
 		DebugContext(ctx, "Event processed")
 }
 
+// bannerMessage builds the banner text message.
+func (h *Handler) bannerMessage() messaging_api.MessageInterface {
+	sender := lineutil.GetSender("NTPU 小工具", h.stickerManager)
+	return lineutil.NewTextMessageWithConsistentSender(h.banner, sender)
+}
+
+// sendReply delivers messages for an event and returns the reply status.
+// Reply tokens are only valid for a short window after LINE issues them.
+// When processing outlived that window, 1:1 chats fall back to a push message
+// instead of sending a reply LINE would reject with an opaque 400.
+// Group and room chats are skipped, since pushing there costs quota for every member.
+func (h *Handler) sendReply(ctx context.Context, log *logger.Logger, event webhook.EventInterface, messages []messaging_api.MessageInterface, receivedAt time.Time) string {
+	replyToken := h.getReplyToken(event)
+	if replyToken == "" {
+		h.metrics.RecordLineReplySkipped("skipped_empty_token")
+		log.DebugContext(ctx, "Empty reply token, skipping reply")
+		return "skipped_empty_token"
+	}
+	if len(replyToken) < h.minReplyTokenLength {
+		h.metrics.RecordLineReplySkipped("skipped_invalid_token")
+		log.DebugContext(ctx, "Invalid reply token format")
+		return "skipped_invalid_token"
+	}
+
+	// Check global rate limit
+	if !h.rateLimiter.Allow() {
+		log.WarnContext(ctx, "Global rate limit exceeded, waiting")
+		h.metrics.RecordRateLimiterDrop("global")
+		h.rateLimiter.WaitSimple()
+	}
+
+	if tokenAge := time.Since(replyTokenIssuedAt(event, receivedAt)); tokenAge > h.replyTokenTTL {
+		log = log.WithField("reply_token_age_ms", tokenAge.Milliseconds())
+		userID := h.getPushTarget(event)
+		if userID == "" {
+			h.metrics.RecordLineReplySkipped("skipped_expired_token")
+			log.WarnContext(ctx, "Reply token expired in group chat, skipping reply")
+			return "skipped_expired_token"
+		}
+		return h.pushFallback(ctx, log, userID, messages)
+	}
+
+	replyStatus := "success"
+	replyStart := time.Now()
+	// 429/5xx responses are retried inside the client
+	if err := h.client.Reply(ctx,
+		&messaging_api.ReplyMessageRequest{
+			ReplyToken: replyToken,
+			Messages:   messages,
+		},
+	); err != nil {
+		switch {
+		case errors.Is(err, lineapi.ErrInvalidReplyToken):
+			replyStatus = "invalid_token"
+			log.WithError(err).DebugContext(ctx, "Reply token already used or invalid")
+		case errors.Is(err, lineapi.ErrRateLimited):
+			replyStatus = "rate_limited"
+			log.WithError(err).ErrorContext(ctx, "Rate limit exceeded")
+		default:
+			replyStatus = "error"
+			log.WithError(err).ErrorContext(ctx, "Failed to send reply")
+		}
+	}
+	h.metrics.RecordLineReply(replyStatus, time.Since(replyStart).Seconds())
+	return replyStatus
+}
+
+// pushFallback sends messages with the push API after the reply token expired.
+func (h *Handler) pushFallback(ctx context.Context, log *logger.Logger, userID string, messages []messaging_api.MessageInterface) string {
+	replyStatus := "push_fallback"
+	pushStart := time.Now()
+	if err := h.client.Push(ctx, &messaging_api.PushMessageRequest{
+		To:       userID,
+		Messages: messages,
+	}); err != nil {
+		if errors.Is(err, lineapi.ErrQuotaExhausted) {
+			replyStatus = "push_quota_exhausted"
+			log.WithError(err).WarnContext(ctx, "Reply token expired and push quota exhausted")
+		} else {
+			replyStatus = "push_error"
+			log.WithError(err).ErrorContext(ctx, "Failed to push after reply token expired")
+		}
+	} else {
+		log.InfoContext(ctx, "Reply token expired, delivered via push")
+	}
+	h.metrics.RecordLineReply(replyStatus, time.Since(pushStart).Seconds())
+	return replyStatus
+}
+
+// replyTokenIssuedAt returns when the event's reply token was issued: the
+// event timestamp, which counts LINE's delivery delay, bounded by receivedAt
+// in case the timestamp is missing or our clock is behind LINE's.
+func replyTokenIssuedAt(event webhook.EventInterface, receivedAt time.Time) time.Time {
+	if _, ms, _ := extractEventMeta(event); ms > 0 {
+		if t := time.UnixMilli(ms); t.Before(receivedAt) {
+			return t
+		}
+	}
+	return receivedAt
+}
+
 func extractEventMeta(event webhook.EventInterface) (string, int64, *bool) {
 	switch e := event.(type) {
 	case webhook.MessageEvent:
@@ -266,6 +435,8 @@ func extractEventMeta(event webhook.EventInterface) (string, int64, *bool) {
 		return e.WebhookEventId, e.Timestamp, boolPtr(e.DeliveryContext)
 	case webhook.JoinEvent:
 		return e.WebhookEventId, e.Timestamp, boolPtr(e.DeliveryContext)
+	case webhook.AccountLinkEvent:
+		return e.WebhookEventId, e.Timestamp, boolPtr(e.DeliveryContext)
 	default:
 		return "", 0, nil
 	}
@@ -305,7 +476,8 @@ func boolPtr(ctx *webhook.DeliveryContext) *bool {
 	return &val
 }
 
-// shouldShowLoading determines if loading animation should be shown for an event.
+// shouldShowLoading determines if loading animation may be shown for an event.
+// Handlers decide whether to actually show it via lineutil.ShowLoading.
 // Returns false for events that won't result in a response:
 // - Group/Room text messages without @Bot mention
 // - Sticker messages in group/room chats
@@ -359,14 +531,28 @@ func (h *Handler) shouldShowLoadingForMessage(e webhook.MessageEvent) bool {
 	return false
 }
 
-// showLoadingAnimation shows a loading circle animation.
-// Uses LINE API maximum of 60 seconds to match webhook processing timeout.
-func (h *Handler) showLoadingAnimation(event webhook.EventInterface) error {
-	chatID := h.getChatID(event)
-	if chatID == "" {
-		return nil
+// isUserChat reports whether the event comes from a 1:1 chat.
+// The loading animation API rejects group and room IDs.
+func isUserChat(event webhook.EventInterface) bool {
+	var source webhook.SourceInterface
+	switch e := event.(type) {
+	case webhook.MessageEvent:
+		source = e.Source
+	case webhook.PostbackEvent:
+		source = e.Source
+	case webhook.FollowEvent:
+		source = e.Source
+	case webhook.JoinEvent:
+		source = e.Source
 	}
+	_, ok := source.(webhook.UserSource)
+	return ok
+}
 
+// showLoadingAnimation shows a loading circle animation in a 1:1 chat.
+// Uses LINE API maximum of 60 seconds to match webhook processing timeout.
+// The animation disappears as soon as the reply arrives.
+func (h *Handler) showLoadingAnimation(ctx context.Context, chatID string) error {
 	// LINE API: loadingSeconds must be 5-60 seconds, multiple of 5.
 	// Set to max (60s) to align with bot operation timeout (config.WebhookProcessing)
 	// applied via ctxutil.PreserveTracing() for individual bot operations.
@@ -377,7 +563,7 @@ func (h *Handler) showLoadingAnimation(event webhook.EventInterface) error {
 		LoadingSeconds: loadingSeconds,
 	}
 
-	if _, err := h.client.ShowLoadingAnimation(req); err != nil {
+	if err := h.client.ShowLoadingAnimation(ctx, req); err != nil {
 		return fmt.Errorf("failed to show loading animation: %w", err)
 	}
 
@@ -395,11 +581,37 @@ func (h *Handler) getReplyToken(event webhook.EventInterface) string {
 		return e.ReplyToken
 	case webhook.JoinEvent:
 		return e.ReplyToken
+	case webhook.AccountLinkEvent:
+		return e.ReplyToken
 	default:
 		return ""
 	}
 }
 
+// getPushTarget returns the user ID for push fallback.
+// Only 1:1 chats are eligible; returns empty string for group and room sources.
+func (h *Handler) getPushTarget(event webhook.EventInterface) string {
+	var source webhook.SourceInterface
+
+	switch e := event.(type) {
+	case webhook.MessageEvent:
+		source = e.Source
+	case webhook.PostbackEvent:
+		source = e.Source
+	case webhook.FollowEvent:
+		source = e.Source
+	case webhook.AccountLinkEvent:
+		source = e.Source
+	default:
+		return ""
+	}
+
+	if s, ok := source.(webhook.UserSource); ok {
+		return s.UserId
+	}
+	return ""
+}
+
 // getChatID extracts chat ID from event
 func (h *Handler) getChatID(event webhook.EventInterface) string {
 	var source webhook.SourceInterface
@@ -413,6 +625,8 @@ func (h *Handler) getChatID(event webhook.EventInterface) string {
 		source = e.Source
 	case webhook.JoinEvent:
 		source = e.Source
+	case webhook.AccountLinkEvent:
+		source = e.Source
 	default:
 		return ""
 	}
@@ -420,8 +634,8 @@ func (h *Handler) getChatID(event webhook.EventInterface) string {
 	return bot.GetChatID(source)
 }
 
-// Shutdown waits for all async event processing to complete.
-// It returns an error if the context is canceled before completion.
+// Shutdown waits for all async event processing to complete, then closes
+// the recorder. It returns an error if the context is canceled before completion.
 func (h *Handler) Shutdown(ctx context.Context) error {
 	c := make(chan struct{})
 	go func() {
@@ -431,6 +645,9 @@ func (h *Handler) Shutdown(ctx context.Context) error {
 
 	select {
 	case <-c:
+		if h.recorder != nil {
+			return h.recorder.Close()
+		}
 		return nil
 	case <-ctx.Done():
 		return ctx.Err()
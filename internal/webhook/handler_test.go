@@ -6,12 +6,14 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/garyellow/ntpu-linebot-go/internal/config"
 
 	"github.com/garyellow/ntpu-linebot-go/internal/bot"
+	"github.com/garyellow/ntpu-linebot-go/internal/lineapi"
 	"github.com/garyellow/ntpu-linebot-go/internal/logger"
 	"github.com/garyellow/ntpu-linebot-go/internal/metrics"
 	"github.com/garyellow/ntpu-linebot-go/internal/modules/contact"
@@ -22,6 +24,7 @@ import (
 	"github.com/garyellow/ntpu-linebot-go/internal/sticker"
 	"github.com/garyellow/ntpu-linebot-go/internal/storage"
 	"github.com/gin-gonic/gin"
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
 	"github.com/line/line-bot-sdk-go/v8/linebot/webhook"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -53,9 +56,9 @@ func setupTestHandler(t *testing.T) *Handler {
 
 	stickerManager := sticker.NewManager(db, scraperClient, log)
 
-	idHandler := id.NewHandler(db, scraperClient, m, log, stickerManager, nil)
-	contactHandler := contact.NewHandler(db, scraperClient, m, log, stickerManager, 100, nil, nil)
-	courseHandler := course.NewHandler(db, scraperClient, m, log, stickerManager, nil, nil, nil, nil, nil, nil)
+	idHandler := id.NewHandler(db, scraperClient, m, log, stickerManager, nil, nil, false)
+	contactHandler := contact.NewHandler(db, scraperClient, m, log, stickerManager, 100, nil, nil, nil)
+	courseHandler := course.NewHandler(db, scraperClient, m, log, stickerManager, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 
 	botRegistry := bot.NewRegistry()
 	botRegistry.Register(contactHandler)
@@ -526,3 +529,171 @@ func TestShouldShowLoading(t *testing.T) {
 		})
 	}
 }
+
+// TestIsUserChat tests that the loading indicator is limited to 1:1 chats
+func TestIsUserChat(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		event    webhook.EventInterface
+		expected bool
+	}{
+		{"message - personal", webhook.MessageEvent{Source: webhook.UserSource{UserId: "U123"}}, true},
+		{"message - group", webhook.MessageEvent{Source: webhook.GroupSource{GroupId: "G123"}}, false},
+		{"message - room", webhook.MessageEvent{Source: webhook.RoomSource{RoomId: "R123"}}, false},
+		{"postback - personal", webhook.PostbackEvent{Source: webhook.UserSource{UserId: "U123"}}, true},
+		{"join - group", webhook.JoinEvent{Source: webhook.GroupSource{GroupId: "G123"}}, false},
+		{"unfollow", webhook.UnfollowEvent{Source: webhook.UserSource{UserId: "U123"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := isUserChat(tt.event); got != tt.expected {
+				t.Errorf("isUserChat() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+// ==================== Reply Token Expiry Tests ====================
+
+// TestGetPushTarget tests that only 1:1 chats are eligible for push fallback
+func TestGetPushTarget(t *testing.T) {
+	t.Parallel()
+	handler := setupTestHandler(t)
+
+	tests := []struct {
+		name     string
+		event    webhook.EventInterface
+		expected string
+	}{
+		{
+			name:     "message event - personal chat",
+			event:    webhook.MessageEvent{Source: webhook.UserSource{UserId: "U123"}},
+			expected: "U123",
+		},
+		{
+			name:     "postback event - personal chat",
+			event:    webhook.PostbackEvent{Source: webhook.UserSource{UserId: "U123"}},
+			expected: "U123",
+		},
+		{
+			name:     "message event - group",
+			event:    webhook.MessageEvent{Source: webhook.GroupSource{GroupId: "G123", UserId: "U123"}},
+			expected: "",
+		},
+		{
+			name:     "message event - room",
+			event:    webhook.MessageEvent{Source: webhook.RoomSource{RoomId: "R123", UserId: "U123"}},
+			expected: "",
+		},
+		{
+			name:     "join event",
+			event:    webhook.JoinEvent{Source: webhook.GroupSource{GroupId: "G123"}},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := handler.getPushTarget(tt.event); got != tt.expected {
+				t.Errorf("getPushTarget() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestSendReply_ExpiredToken tests the push fallback when processing outlives the reply token
+func TestSendReply_ExpiredToken(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		event      webhook.EventInterface
+		receivedAt time.Time
+		wantStatus string
+		wantPath   string
+	}{
+		{
+			name:       "fresh token uses reply",
+			event:      webhook.MessageEvent{ReplyToken: "reply_token_123", Source: webhook.UserSource{UserId: "U123"}},
+			receivedAt: time.Now(),
+			wantStatus: "success",
+			wantPath:   "/v2/bot/message/reply",
+		},
+		{
+			name:       "expired token in personal chat uses push",
+			event:      webhook.MessageEvent{ReplyToken: "reply_token_123", Source: webhook.UserSource{UserId: "U123"}},
+			receivedAt: time.Now().Add(-2 * config.ReplyTokenTTL),
+			wantStatus: "push_fallback",
+			wantPath:   "/v2/bot/message/push",
+		},
+		{
+			name: "token issued long before the webhook arrived uses push",
+			event: webhook.MessageEvent{ReplyToken: "reply_token_123", Source: webhook.UserSource{UserId: "U123"},
+				Timestamp: time.Now().Add(-2 * config.ReplyTokenTTL).UnixMilli()},
+			receivedAt: time.Now(),
+			wantStatus: "push_fallback",
+			wantPath:   "/v2/bot/message/push",
+		},
+		{
+			name: "event timestamp ahead of our clock uses receive time",
+			event: webhook.MessageEvent{ReplyToken: "reply_token_123", Source: webhook.UserSource{UserId: "U123"},
+				Timestamp: time.Now().Add(time.Minute).UnixMilli()},
+			receivedAt: time.Now().Add(-2 * config.ReplyTokenTTL),
+			wantStatus: "push_fallback",
+			wantPath:   "/v2/bot/message/push",
+		},
+		{
+			name:       "expired token in group is skipped",
+			event:      webhook.MessageEvent{ReplyToken: "reply_token_123", Source: webhook.GroupSource{GroupId: "G123"}},
+			receivedAt: time.Now().Add(-2 * config.ReplyTokenTTL),
+			wantStatus: "skipped_expired_token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			handler := setupTestHandler(t)
+
+			var (
+				mu      sync.Mutex
+				gotPath string
+			)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				gotPath = r.URL.Path
+				mu.Unlock()
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte("{}"))
+			}))
+			t.Cleanup(server.Close)
+
+			client, err := lineapi.New(lineapi.Config{
+				ChannelToken: "test_channel_token",
+				Metrics:      handler.metrics,
+				Logger:       handler.logger,
+				Options:      []messaging_api.MessagingApiAPIOption{messaging_api.WithEndpoint(server.URL)},
+			})
+			if err != nil {
+				t.Fatalf("lineapi.New() error = %v", err)
+			}
+			handler.client = client
+
+			messages := []messaging_api.MessageInterface{&messaging_api.TextMessage{Text: "hi"}}
+			status := handler.sendReply(context.Background(), handler.logger, tt.event, messages, tt.receivedAt)
+			if status != tt.wantStatus {
+				t.Errorf("sendReply() = %q, expected %q", status, tt.wantStatus)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if gotPath != tt.wantPath {
+				t.Errorf("LINE API path = %q, expected %q", gotPath, tt.wantPath)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package webhook
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+// Recording is one webhook event and the reply the bot produced for it,
+// stored one per line by Recorder and read back by cmd/replay.
+type Recording struct {
+	RecordedAt time.Time       `json:"recorded_at"`
+	Module     string          `json:"module,omitempty"` // Module that handled the event ("none" if unmatched)
+	Event      json.RawMessage `json:"event"`            // Event JSON as received from LINE
+	Messages   json.RawMessage `json:"messages"`         // Reply messages; null if the bot did not reply
+}
+
+// Recorder appends Recordings to a JSON Lines file. Recordings contain the
+// raw user messages and IDs, so only enable it on instances whose traffic
+// may be kept (e.g., a staging channel). Safe for concurrent use.
+type Recorder struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+// NewRecorder opens path for appending, creating it if needed.
+func NewRecorder(path string) (*Recorder, error) {
+	f, err := openRecordFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{path: path, file: f}, nil
+}
+
+func openRecordFile(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600) //nolint:gosec // G304: path comes from configuration
+	if err != nil {
+		return nil, fmt.Errorf("open record file: %w", err)
+	}
+	return f, nil
+}
+
+// Record appends an event and its reply messages.
+func (r *Recorder) Record(event json.RawMessage, module string, messages []messaging_api.MessageInterface) error {
+	rec := Recording{RecordedAt: time.Now(), Module: module, Event: event, Messages: json.RawMessage("null")}
+	if len(messages) > 0 {
+		data, err := json.Marshal(messages)
+		if err != nil {
+			return fmt.Errorf("marshal messages: %w", err)
+		}
+		rec.Messages = data
+	}
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("marshal recording: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, err = r.file.Write(append(line, '\n'))
+	return err
+}
+
+// EraseUser rewrites the record file without the events userID sent and
+// returns how many were removed. It makes the recorder a privacy.Eraser.
+func (r *Recorder) EraseUser(ctx context.Context, userID string) (int64, error) {
+	if userID == "" {
+		return 0, nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	src, err := os.Open(r.path)
+	if err != nil {
+		return 0, fmt.Errorf("open record file: %w", err)
+	}
+	defer func() { _ = src.Close() }()
+	tmpPath := r.path + ".tmp"
+	dst, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600) //nolint:gosec // G304: path comes from configuration
+	if err != nil {
+		return 0, fmt.Errorf("create record file: %w", err)
+	}
+	removed, err := copyRecordingsExcept(ctx, dst, src, userID)
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return 0, err
+	}
+	if removed == 0 {
+		_ = os.Remove(tmpPath)
+		return 0, nil
+	}
+
+	// Swap the files, then move the append handle over to the new one
+	if err := os.Rename(tmpPath, r.path); err != nil {
+		_ = os.Remove(tmpPath)
+		return 0, fmt.Errorf("replace record file: %w", err)
+	}
+	f, err := openRecordFile(r.path)
+	if err != nil {
+		return removed, err
+	}
+	_ = r.file.Close()
+	r.file = f
+	return removed, nil
+}
+
+// copyRecordingsExcept copies the lines of src to dst, skipping recordings of
+// events sent by userID, and returns how many it skipped.
+func copyRecordingsExcept(ctx context.Context, dst io.Writer, src io.Reader, userID string) (int64, error) {
+	var removed int64
+	reader := bufio.NewReader(src)
+	for {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			var rec struct {
+				Event struct {
+					Source struct {
+						UserID string `json:"userId"`
+					} `json:"source"`
+				} `json:"event"`
+			}
+			if json.Unmarshal(line, &rec) == nil && rec.Event.Source.UserID == userID {
+				removed++
+			} else if _, werr := dst.Write(line); werr != nil {
+				return 0, fmt.Errorf("write record file: %w", werr)
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return removed, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("read record file: %w", err)
+		}
+	}
+}
+
+// Close closes the record file.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
+
+// ReadRecordings reads the Recordings written by a Recorder.
+func ReadRecordings(r io.Reader) ([]Recording, error) {
+	var recs []Recording
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20) // Flex replies can be far larger than the default 64 KiB
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var rec Recording
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		recs = append(recs, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return recs, nil
+}
+
+// readRawEvents buffers the request body so webhook.ParseRequest can still
+// read it, and returns the raw JSON of each event. The SDK event types do not
+// round-trip through JSON, so the recorder stores these instead.
+func readRawEvents(req *http.Request) []json.RawMessage {
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return nil // A truncated body fails signature validation
+	}
+	var cb struct {
+		Events []json.RawMessage `json:"events"`
+	}
+	if err := json.Unmarshal(body, &cb); err != nil {
+		return nil
+	}
+	return cb.Events
+}
@@ -0,0 +1,111 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/line/line-bot-sdk-go/v8/linebot/messaging_api"
+)
+
+func TestRecorder_RoundTrip(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "webhook.jsonl")
+	rec, err := NewRecorder(path)
+	if err != nil {
+		t.Fatalf("NewRecorder() error = %v", err)
+	}
+
+	event := []byte(`{"type":"message","replyToken":"abc","message":{"type":"text","text":"課程 微積分"}}`)
+	reply := []messaging_api.MessageInterface{&messaging_api.TextMessage{Text: "找到 1 門課程"}}
+	if err := rec.Record(event, "course", reply); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if err := rec.Record(event, "none", nil); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	recs, err := ReadRecordings(f)
+	if err != nil {
+		t.Fatalf("ReadRecordings() error = %v", err)
+	}
+	if len(recs) != 2 {
+		t.Fatalf("ReadRecordings() = %d recordings, want 2", len(recs))
+	}
+	if recs[0].Module != "course" || string(recs[0].Event) != string(event) {
+		t.Errorf("recording = %+v, want course event", recs[0])
+	}
+	if !strings.Contains(string(recs[0].Messages), `"type":"text"`) {
+		t.Errorf("Messages = %s, want typed message JSON", recs[0].Messages)
+	}
+	if string(recs[1].Messages) != "null" {
+		t.Errorf("Messages without reply = %s, want null", recs[1].Messages)
+	}
+}
+
+func TestRecorder_EraseUser(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "webhook.jsonl")
+	rec, err := NewRecorder(path)
+	if err != nil {
+		t.Fatalf("NewRecorder() error = %v", err)
+	}
+	defer func() { _ = rec.Close() }()
+
+	for _, user := range []string{"U1", "U2", "U1"} {
+		event := []byte(`{"type":"message","source":{"type":"user","userId":"` + user + `"},"message":{"type":"text","text":"課程 微積分"}}`)
+		if err := rec.Record(event, "course", nil); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+	if n, err := rec.EraseUser(context.Background(), "U1"); err != nil || n != 2 {
+		t.Fatalf("EraseUser() = (%d, %v), want 2 recordings", n, err)
+	}
+	// Later recordings append to the rewritten file
+	if err := rec.Record([]byte(`{"type":"follow","source":{"type":"user","userId":"U3"}}`), "none", nil); err != nil {
+		t.Fatalf("Record() after EraseUser error = %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	recs, err := ReadRecordings(f)
+	if err != nil {
+		t.Fatalf("ReadRecordings() error = %v", err)
+	}
+	if len(recs) != 2 || !strings.Contains(string(recs[0].Event), `"U2"`) || !strings.Contains(string(recs[1].Event), `"U3"`) {
+		t.Errorf("recordings after EraseUser = %+v, want U2 then U3", recs)
+	}
+}
+
+func TestReadRawEvents(t *testing.T) {
+	t.Parallel()
+	body := `{"destination":"U1","events":[{"type":"follow","replyToken":"a"},{"type":"unfollow"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+
+	events := readRawEvents(req)
+	if len(events) != 2 || string(events[1]) != `{"type":"unfollow"}` {
+		t.Errorf("readRawEvents() = %s, want both raw events", events)
+	}
+
+	// The body is still readable for signature validation
+	buf := new(strings.Builder)
+	if _, err := io.Copy(buf, req.Body); err != nil || buf.String() != body {
+		t.Errorf("body after readRawEvents() = %q, %v", buf.String(), err)
+	}
+}